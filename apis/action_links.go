@@ -0,0 +1,177 @@
+package apis
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+// bindActionLinksApi registers the signed action links api endpoints.
+func bindActionLinksApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/action-links")
+	sub.POST("", actionLinkCreate).Bind(RequireSuperuserAuth())
+	sub.POST("/inspect", actionLinkInspect)
+	sub.POST("/confirm", actionLinkConfirm)
+}
+
+func actionLinkCreate(e *core.RequestEvent) error {
+	if e.Get(core.RequestEventKeyAPIKey) != nil {
+		return e.ForbiddenError("The action links cannot be generated with an API key.", nil)
+	}
+
+	form := new(actionLinkCreateForm)
+	if err := e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err := form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	collection, err := e.App.FindCachedCollectionByNameOrId(form.Collection)
+	if err != nil || collection.IsView() {
+		return e.NotFoundError("Missing or invalid collection context.", err)
+	}
+
+	record, err := e.App.FindRecordById(collection, form.RecordId)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	if form.Action == core.ActionVerify && !collection.IsAuth() {
+		return e.BadRequestError("The verify action is supported only for auth collection records.", nil)
+	}
+
+	token, err := e.App.NewActionToken(form.Action, record, form.Data, time.Duration(form.Duration)*time.Second)
+	if err != nil {
+		return e.InternalServerError("Failed to generate the action token.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{"token": token})
+}
+
+func actionLinkInspect(e *core.RequestEvent) error {
+	token, err := findActionToken(e)
+	if err != nil {
+		return err
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"action":         token.Action,
+		"collectionId":   token.Record.Collection().Id,
+		"collectionName": token.Record.Collection().Name,
+		"recordId":       token.Record.Id,
+		"expires":        token.Expires.UTC().Format(time.RFC3339),
+	})
+}
+
+func actionLinkConfirm(e *core.RequestEvent) error {
+	token, err := findActionToken(e)
+	if err != nil {
+		return err
+	}
+
+	event := new(core.ActionLinkRequestEvent)
+	event.RequestEvent = e
+	event.Collection = token.Record.Collection()
+	event.Record = token.Record
+	event.Action = token.Action
+	event.Data = token.Data
+
+	err = e.App.ConsumeActionToken(token, func() error {
+		return e.App.OnActionLinkRequest().Trigger(event, func(e *core.ActionLinkRequestEvent) error {
+			switch e.Action {
+			case core.ActionVerify:
+				if !e.Record.Collection().IsAuth() {
+					return e.BadRequestError("The verify action is supported only for auth collection records.", nil)
+				}
+
+				if e.Record.Verified() {
+					return e.NoContent(http.StatusNoContent)
+				}
+
+				e.Record.SetVerified(true)
+			case core.ActionUpdate:
+				for name, value := range e.Data {
+					if e.Record.Collection().Fields.GetByName(name) == nil {
+						return e.BadRequestError("Invalid action field "+name+".", nil)
+					}
+					e.Record.Set(name, value)
+				}
+			default:
+				return e.BadRequestError("Unsupported action.", nil)
+			}
+
+			if err := e.App.Save(e.Record); err != nil {
+				return firstApiError(err, e.BadRequestError("Failed to execute the action.", err))
+			}
+
+			return e.NoContent(http.StatusNoContent)
+		})
+	})
+	if errors.Is(err, core.ErrActionTokenUsed) {
+		return e.BadRequestError("The action link has already been used.", err)
+	}
+
+	return err
+}
+
+// findActionToken loads and verifies the submitted action token.
+func findActionToken(e *core.RequestEvent) (*core.ActionToken, error) {
+	form := new(actionLinkTokenForm)
+	if err := e.BindBody(form); err != nil {
+		return nil, firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err := form.validate(); err != nil {
+		return nil, firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	token, err := e.App.FindActionToken(form.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrActionTokenUsed):
+			return nil, e.BadRequestError("The action link has already been used.", err)
+		case errors.Is(err, core.ErrInvalidActionToken):
+			return nil, e.BadRequestError("Invalid or expired action link.", err)
+		default:
+			return nil, e.InternalServerError("", err)
+		}
+	}
+
+	return token, nil
+}
+
+// -------------------------------------------------------------------
+
+type actionLinkCreateForm struct {
+	Data       map[string]any `form:"data" json:"data"`
+	Action     string         `form:"action" json:"action"`
+	Collection string         `form:"collection" json:"collection"`
+	RecordId   string         `form:"recordId" json:"recordId"`
+
+	// Duration is the optional token duration in seconds
+	// (default to the [core.ActionLinksConfig.Duration]).
+	Duration int64 `form:"duration" json:"duration"`
+}
+
+func (form *actionLinkCreateForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Action, validation.Required, validation.Length(1, 100)),
+		validation.Field(&form.Collection, validation.Required),
+		validation.Field(&form.RecordId, validation.Required),
+		validation.Field(&form.Duration, validation.Min(0), validation.Max(94670856)), // ~3y max
+	)
+}
+
+type actionLinkTokenForm struct {
+	Token string `form:"token" json:"token"`
+}
+
+func (form *actionLinkTokenForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Token, validation.Required),
+	)
+}
@@ -0,0 +1,152 @@
+package apis
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+const adminUIExtensionsStoreKey = "__pbAdminUIExtensions__"
+
+var adminUIExtensionIdRegex = regexp.MustCompile(`^[\w\-]+$`)
+
+// AdminUIExtension defines a custom admin UI page with its own sidebar menu entry.
+//
+// The page content is rendered by a JS (ES) module that must default
+// export a mount function with the following signature:
+//
+//	export default function (el, ctx) {
+//		// el  - the page container DOM element
+//		// ctx - { id, client } (client is the admin UI PocketBase JS SDK instance)
+//		//
+//		// optionally return a cleanup function that will be called on page leave
+//	}
+type AdminUIExtension struct {
+	// Id is the unique url safe extension identifier
+	// (the page is available at "/_/#/ext/{id}").
+	Id string `json:"id"`
+
+	// Label is the page title and menu entry tooltip.
+	Label string `json:"label"`
+
+	// Icon is an optional Remix icon class name of the menu entry
+	// (default to "ri-puzzle-line").
+	Icon string `json:"icon"`
+
+	// Module is the url of the JS module that renders the page.
+	//
+	// Note that because of the admin UI Content-Security-Policy the module
+	// must be served from the same origin (eg. "/my-route/index.js").
+	//
+	// It could be left empty if Assets is set, in which case
+	// it defaults to the Assets "index.js" file.
+	Module string `json:"module"`
+
+	// Assets is an optional file system with the bundled extension
+	// files that are served publicly at "/api/adminui/extensions/{id}/{path...}".
+	//
+	// Note that these files are accessible also without superuser
+	// authorization so they shouldn't contain sensitive data
+	// (use regular superuser only routes for loading the page data).
+	Assets fs.FS `json:"-"`
+}
+
+// RegisterAdminUIExtension registers a new admin UI page extension.
+//
+// Example:
+//
+//	//go:embed all:dealers_ui
+//	var dealersUI embed.FS
+//
+//	apis.RegisterAdminUIExtension(app, apis.AdminUIExtension{
+//		Id:     "dealers",
+//		Label:  "Dealers",
+//		Icon:   "ri-store-2-line",
+//		Assets: apis.MustSubFS(dealersUI, "dealers_ui"),
+//	})
+func RegisterAdminUIExtension(app core.App, ext AdminUIExtension) error {
+	if !adminUIExtensionIdRegex.MatchString(ext.Id) {
+		return errors.New("the admin UI extension id must be non-empty and contain only letters, digits, underscores and dashes")
+	}
+
+	if ext.Label == "" {
+		ext.Label = ext.Id
+	}
+
+	if ext.Icon == "" {
+		ext.Icon = "ri-puzzle-line"
+	}
+
+	if ext.Module == "" {
+		if ext.Assets == nil {
+			return errors.New("the admin UI extension requires either Module or Assets")
+		}
+		ext.Module = "/api/adminui/extensions/" + ext.Id + "/index.js"
+	}
+
+	registry := adminUIExtensionsRegistry(app)
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if slices.ContainsFunc(registry.items, func(item AdminUIExtension) bool { return item.Id == ext.Id }) {
+		return errors.New("admin UI extension " + ext.Id + " is already registered")
+	}
+
+	registry.items = append(registry.items, ext)
+
+	return nil
+}
+
+// AdminUIExtensions returns a list with all registered admin UI extensions
+// (in the order of their registration).
+func AdminUIExtensions(app core.App) []AdminUIExtension {
+	registry := adminUIExtensionsRegistry(app)
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	return slices.Clone(registry.items)
+}
+
+type adminUIRegistry struct {
+	mu    sync.RWMutex
+	items []AdminUIExtension
+}
+
+func adminUIExtensionsRegistry(app core.App) *adminUIRegistry {
+	return app.Store().GetOrSet(adminUIExtensionsStoreKey, func() any {
+		return &adminUIRegistry{}
+	}).(*adminUIRegistry)
+}
+
+// -------------------------------------------------------------------
+
+// bindAdminUIApi registers the admin UI extensions api endpoints.
+func bindAdminUIApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/adminui/extensions")
+	sub.GET("", adminUIExtensionsList).Bind(RequireSuperuserAuth())
+	sub.GET("/{id}/{path...}", adminUIExtensionAssets)
+}
+
+func adminUIExtensionsList(e *core.RequestEvent) error {
+	return e.JSON(http.StatusOK, AdminUIExtensions(e.App))
+}
+
+func adminUIExtensionAssets(e *core.RequestEvent) error {
+	id := e.Request.PathValue("id")
+
+	for _, ext := range AdminUIExtensions(e.App) {
+		if ext.Id == id && ext.Assets != nil {
+			return Static(ext.Assets, false)(e)
+		}
+	}
+
+	return e.NotFoundError("", nil)
+}
@@ -1,7 +1,6 @@
 package apis
 
 import (
-	"errors"
 	"fmt"
 	"io/fs"
 	"net/http"
@@ -38,13 +37,23 @@ func NewRouter(app core.App) (*router.Router[*core.RequestEvent], error) {
 	bindSettingsApi(app, apiGroup)
 	bindCollectionApi(app, apiGroup)
 	bindRecordCrudApi(app, apiGroup)
+	bindModelCrudApi(app, apiGroup)
 	bindRecordAuthApi(app, apiGroup)
+	bindRecordAPIKeysApi(app, apiGroup)
+	bindOIDCApi(app, apiGroup)
+	bindRecordSessionsApi(app, apiGroup)
+	bindRecordExternalAuthsApi(app, apiGroup)
+	bindActionLinksApi(app, apiGroup)
+	bindDelegatedTokensApi(app, apiGroup)
 	bindLogsApi(app, apiGroup)
 	bindBackupApi(app, apiGroup)
 	bindFileApi(app, apiGroup)
 	bindBatchApi(app, apiGroup)
 	bindRealtimeApi(app, apiGroup)
 	bindHealthApi(app, apiGroup)
+	bindQuotaApi(app, apiGroup)
+	bindMetricsApi(app, apiGroup)
+	bindAdminUIApi(app, apiGroup)
 
 	return pbRouter, nil
 }
@@ -83,86 +92,6 @@ func MustSubFS(fsys fs.FS, dir string) fs.FS {
 	return sub
 }
 
-// Static is a handler function to serve static directory content from fsys.
-//
-// If a file resource is missing and indexFallback is set, the request
-// will be forwarded to the base index.html (useful for SPA with pretty urls).
-//
-// NB! Expects the route to have a "{path...}" wildcard parameter.
-//
-// Special redirects:
-//   - if "path" is a file that ends in index.html, it is redirected to its non-index.html version (eg. /test/index.html -> /test/)
-//   - if "path" is a directory that has index.html, the index.html file is rendered,
-//     otherwise if missing - returns 404 or fallback to the root index.html if indexFallback is set
-//
-// Example:
-//
-//	fsys := os.DirFS("./pb_public")
-//	router.GET("/files/{path...}", apis.Static(fsys, false))
-func Static(fsys fs.FS, indexFallback bool) func(*core.RequestEvent) error {
-	if fsys == nil {
-		panic("Static: the provided fs.FS argument is nil")
-	}
-
-	return func(e *core.RequestEvent) error {
-		// disable the activity logger to avoid flooding with messages
-		//
-		// note: errors are still logged
-		if e.Get(requestEventKeySkipSuccessActivityLog) == nil {
-			e.Set(requestEventKeySkipSuccessActivityLog, true)
-		}
-
-		filename := e.Request.PathValue(StaticWildcardParam)
-		filename = filepath.ToSlash(filepath.Clean(strings.TrimPrefix(filename, "/")))
-
-		// eagerly check for directory traversal
-		//
-		// note: this is just out of an abundance of caution because the fs.FS implementation could be non-std,
-		// but usually shouldn't be necessary since os.DirFS.Open is expected to fail if the filename starts with dots
-		if len(filename) > 2 && filename[0] == '.' && filename[1] == '.' && (filename[2] == '/' || filename[2] == '\\') {
-			if indexFallback && filename != router.IndexPage {
-				return e.FileFS(fsys, router.IndexPage)
-			}
-			return router.ErrFileNotFound
-		}
-
-		fi, err := fs.Stat(fsys, filename)
-		if err != nil {
-			if indexFallback && filename != router.IndexPage {
-				return e.FileFS(fsys, router.IndexPage)
-			}
-			return router.ErrFileNotFound
-		}
-
-		if fi.IsDir() {
-			// redirect to a canonical dir url, aka. with trailing slash
-			if !strings.HasSuffix(e.Request.URL.Path, "/") {
-				return e.Redirect(http.StatusMovedPermanently, safeRedirectPath(e.Request.URL.Path+"/"))
-			}
-		} else {
-			urlPath := e.Request.URL.Path
-			if strings.HasSuffix(urlPath, "/") {
-				// redirect to a non-trailing slash file route
-				urlPath = strings.TrimRight(urlPath, "/")
-				if len(urlPath) > 0 {
-					return e.Redirect(http.StatusMovedPermanently, safeRedirectPath(urlPath))
-				}
-			} else if stripped, ok := strings.CutSuffix(urlPath, router.IndexPage); ok {
-				// redirect without the index.html
-				return e.Redirect(http.StatusMovedPermanently, safeRedirectPath(stripped))
-			}
-		}
-
-		fileErr := e.FileFS(fsys, filename)
-
-		if fileErr != nil && indexFallback && filename != router.IndexPage && errors.Is(fileErr, router.ErrFileNotFound) {
-			return e.FileFS(fsys, router.IndexPage)
-		}
-
-		return fileErr
-	}
-}
-
 // safeRedirectPath normalizes the path string by replacing all beginning slashes
 // (`\\`, `//`, `\/`) with a single forward slash to prevent open redirect attacks
 func safeRedirectPath(path string) string {
@@ -19,7 +19,6 @@ import (
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/router"
 	"github.com/pocketbase/pocketbase/tools/types"
-	"github.com/spf13/cast"
 )
 
 func bindBatchApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
@@ -36,28 +35,8 @@ type BatchActionHandlerFunc func(app core.App, ir *core.InternalRequest, params
 // Note: when adding new routes make sure that their middlewares are inlined!
 var ValidBatchActions = map[*regexp.Regexp]BatchActionHandlerFunc{
 	// "upsert" handler
-	regexp.MustCompile(`^PUT /api/collections/(?P<collection>[^\/\?]+)/records(?P<query>\?.*)?$`): func(app core.App, ir *core.InternalRequest, params map[string]string, next func(any) error) HandleFunc {
-		var id string
-		if len(ir.Body) > 0 && ir.Body["id"] != "" {
-			id = cast.ToString(ir.Body["id"])
-		}
-		if id != "" {
-			_, err := app.FindRecordById(params["collection"], id)
-			if err == nil {
-				// update
-				// ---
-				params["id"] = id // required for the path value
-				ir.Method = "PATCH"
-				ir.URL = "/api/collections/" + params["collection"] + "/records/" + id + params["query"]
-				return recordUpdate(next)
-			}
-		}
-
-		// create
-		// ---
-		ir.Method = "POST"
-		ir.URL = "/api/collections/" + params["collection"] + "/records" + params["query"]
-		return recordCreate(next)
+	regexp.MustCompile(`^PUT /api/collections/(?P<collection>[^\/\?]+)/records(\?.*)?$`): func(app core.App, ir *core.InternalRequest, params map[string]string, next func(any) error) HandleFunc {
+		return recordUpsert(next)
 	},
 	regexp.MustCompile(`^POST /api/collections/(?P<collection>[^\/\?]+)/records(\?.*)?$`): func(app core.App, ir *core.InternalRequest, params map[string]string, next func(any) error) HandleFunc {
 		return recordCreate(next)
@@ -171,6 +150,7 @@ type batchProcessor struct {
 	baseEvent   *core.RequestEvent
 	infoContext string
 	results     []*BatchRequestResult
+	recordIds   []string // the record ids of the already processed batch requests
 	failedIndex int
 	errCh       chan error
 	stopCh      chan struct{}
@@ -178,6 +158,7 @@ type batchProcessor struct {
 
 func (p *batchProcessor) Process(batch []*core.InternalRequest, timeout time.Duration) error {
 	p.results = make([]*BatchRequestResult, 0, len(batch))
+	p.recordIds = make([]string, 0, len(batch))
 
 	if p.stopCh != nil {
 		close(p.stopCh)
@@ -241,12 +222,23 @@ func (p *batchProcessor) process(activeApp core.App, batch []*core.InternalReque
 			return nil
 		}
 
+		err := resolveBatchRefs(batch[0], p.recordIds)
+		if err != nil {
+			return err
+		}
+
 		result, err := processInternalRequest(
 			activeApp,
 			p.baseEvent,
 			batch[0],
 			p.infoContext,
-			func(_ any) error {
+			func(data any) error {
+				var recordId string
+				if record, ok := data.(*core.Record); ok && record != nil {
+					recordId = record.Id
+				}
+				p.recordIds = append(p.recordIds, recordId)
+
 				if len(batch) == 1 {
 					return nil
 				}
@@ -272,6 +264,80 @@ func (p *batchProcessor) process(activeApp core.App, batch []*core.InternalReque
 	}
 }
 
+// batchRefRegex matches a "@batch.N.id" reference to the record id of
+// the N-th (zero based) batch request.
+var batchRefRegex = regexp.MustCompile(`@batch\.(\d+)\.id\b`)
+
+// resolveBatchRefs replaces the "@batch.N.id" references in the URL and in
+// the body string values of the provided internal request with the
+// record id of the related already processed batch request.
+//
+// This allows for example to create a record and to use its id
+// as relation value in a later batch request.
+func resolveBatchRefs(ir *core.InternalRequest, recordIds []string) error {
+	var resolveErr error
+
+	replace := func(str string) string {
+		return batchRefRegex.ReplaceAllStringFunc(str, func(match string) string {
+			index, _ := strconv.Atoi(batchRefRegex.FindStringSubmatch(match)[1])
+			if index >= len(recordIds) || recordIds[index] == "" {
+				if resolveErr == nil {
+					resolveErr = router.NewBadRequestError("Invalid or not yet processed batch reference "+match+".", nil)
+				}
+				return match
+			}
+
+			return recordIds[index]
+		})
+	}
+
+	var resolveValue func(v any) any
+	resolveValue = func(v any) any {
+		switch val := v.(type) {
+		case string:
+			return replace(val)
+		case []any:
+			for i, item := range val {
+				val[i] = resolveValue(item)
+			}
+		case []string:
+			for i, item := range val {
+				val[i] = replace(item)
+			}
+		case map[string]any:
+			for k, item := range val {
+				val[k] = resolveValue(item)
+			}
+		}
+
+		return v
+	}
+
+	ir.URL = replace(ir.URL)
+
+	for k, v := range ir.Body {
+		ir.Body[k] = resolveValue(v)
+	}
+
+	return resolveErr
+}
+
+// ProcessInternalRequest executes a single record internal request
+// (any of the [ValidBatchActions]) with the auth state of the base
+// request event and returns its result.
+//
+// It could be used for applying record changes with the regular API rules checks,
+// e.g. from within a custom route handler:
+//
+//	result, err := apis.ProcessInternalRequest(e.App, e, &core.InternalRequest{
+//		Method: http.MethodPatch,
+//		URL:    "/api/collections/posts/records/RECORD_ID",
+//		Body:   map[string]any{"title": "new title"},
+//	})
+func ProcessInternalRequest(activeApp core.App, baseEvent *core.RequestEvent, ir *core.InternalRequest) (*BatchRequestResult, error) {
+	return processInternalRequest(activeApp, baseEvent, ir, core.RequestInfoContextBatch, nil)
+}
+
 func processInternalRequest(
 	activeApp core.App,
 	baseEvent *core.RequestEvent,
@@ -20,6 +20,8 @@ func bindCollectionApi(app core.App, rg *router.RouterGroup[*core.RequestEvent])
 	subGroup.PATCH("/{collection}", collectionUpdate)
 	subGroup.DELETE("/{collection}", collectionDelete)
 	subGroup.DELETE("/{collection}/truncate", collectionTruncate)
+	subGroup.POST("/{collection}/rules/evaluate", collectionRuleEval)
+	subGroup.GET("/{collection}/changes", collectionChanges)
 	subGroup.PUT("/import", collectionsImport)
 	subGroup.GET("/meta/scaffolds", collectionScaffolds)
 }
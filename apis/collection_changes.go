@@ -0,0 +1,70 @@
+package apis
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	defaultChangesLimit = 100
+	maxChangesLimit     = 1000
+)
+
+// collectionChanges returns the ordered captured record changes of a
+// collection with sequence number greater than the "after" query parameter.
+//
+// Consumers could continue reading the changes by submitting the
+// returned "lastSeq" as "after" on the next request.
+func collectionChanges(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("", err)
+	}
+
+	if collection.IsView() || !collection.CaptureChanges {
+		return e.BadRequestError("The collection doesn't capture changes.", nil)
+	}
+
+	query := e.Request.URL.Query()
+
+	var afterSeq int64
+	if raw := query.Get("after"); raw != "" {
+		afterSeq, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || afterSeq < 0 {
+			return e.BadRequestError("Invalid after sequence number.", err)
+		}
+	}
+
+	limit := defaultChangesLimit
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return e.BadRequestError("Invalid limit value.", err)
+		}
+		limit = min(limit, maxChangesLimit)
+	}
+
+	// fetch one extra item to check whether there are more changes
+	changes, err := core.FindRecordChanges(e.App, collection, afterSeq, limit+1)
+	if err != nil {
+		return e.InternalServerError("Failed to load the collection changes.", err)
+	}
+
+	hasMore := len(changes) > limit
+	if hasMore {
+		changes = changes[:limit]
+	}
+
+	lastSeq := afterSeq
+	if len(changes) > 0 {
+		lastSeq = changes[len(changes)-1].Seq
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"items":   changes,
+		"lastSeq": lastSeq,
+		"hasMore": hasMore,
+	})
+}
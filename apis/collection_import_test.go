@@ -12,7 +12,7 @@ import (
 func TestCollectionsImport(t *testing.T) {
 	t.Parallel()
 
-	totalCollections := 16
+	totalCollections := 19
 
 	scenarios := []tests.ApiScenario{
 		{
@@ -0,0 +1,425 @@
+package apis
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// collectionRuleEval evaluates a collection rule expression against
+// a simulated request and a target record.
+//
+// The target record could be either an existing record (specified with its id)
+// or a new one created from the simulated request body (e.g. for the create rule)
+// that is only temporary saved for the duration of the evaluation.
+func collectionRuleEval(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("", err)
+	}
+
+	form := &collectionRuleEvalForm{collection: collection}
+
+	err = e.BindBody(form)
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+
+	form.Method = strings.ToUpper(form.Method)
+	if form.Method == "" {
+		form.Method = http.MethodGet
+	}
+
+	err = form.validate()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	rule := form.resolveRule()
+
+	requestInfo := &core.RequestInfo{
+		Context: core.RequestInfoContextDefault,
+		Method:  form.Method,
+		Query:   map[string]string{},
+		Headers: map[string]string{},
+		Body:    form.Body,
+	}
+	if requestInfo.Body == nil {
+		requestInfo.Body = map[string]any{}
+	}
+	for k, v := range form.Query {
+		requestInfo.Query[k] = v
+	}
+	for k, v := range form.Headers {
+		requestInfo.Headers[inflector.Snakecase(k)] = v
+	}
+
+	if form.Auth != nil {
+		authCollection, err := e.App.FindCachedCollectionByNameOrId(form.Auth.Collection)
+		if err != nil || !authCollection.IsAuth() {
+			return e.BadRequestError("", validation.Errors{"auth": validation.Errors{"collection": validation.NewError(
+				"validation_invalid_auth_collection",
+				"Missing or invalid auth collection.",
+			)}})
+		}
+
+		requestInfo.Auth, err = e.App.FindRecordById(authCollection, form.Auth.Id)
+		if err != nil {
+			return e.BadRequestError("", validation.Errors{"auth": validation.Errors{"id": validation.NewError(
+				"validation_invalid_auth_record",
+				"Missing or invalid auth record.",
+			)}})
+		}
+	}
+
+	result := &ruleEvalResult{
+		Rule:    rule,
+		Clauses: []*ruleEvalClause{},
+	}
+
+	evalFunc := func(txApp core.App, record *core.Record) error {
+		return evalRule(txApp, record, requestInfo, rule, result)
+	}
+
+	if form.Record != "" {
+		record, err := e.App.FindRecordById(collection, form.Record)
+		if err != nil {
+			return e.BadRequestError("", validation.Errors{"record": validation.NewError(
+				"validation_invalid_record",
+				"Missing or invalid target record.",
+			)})
+		}
+
+		err = evalFunc(e.App, record)
+		if err != nil {
+			return e.BadRequestError("Failed to evaluate the rule.", err)
+		}
+	} else {
+		// temporary create the target record from the request body
+		record := core.NewRecord(collection)
+
+		upsert := forms.NewRecordUpsert(e.App, record)
+		upsert.GrantSuperuserAccess()
+		upsert.Load(requestInfo.Body)
+
+		var evalErr error
+		err = upsert.DrySubmit(func(txApp core.App, drySavedRecord *core.Record) error {
+			evalErr = evalFunc(txApp, drySavedRecord)
+			return nil
+		})
+		if err != nil {
+			return firstApiError(err, e.BadRequestError("Failed to create the target record from the request body.", err))
+		}
+		if evalErr != nil {
+			return e.BadRequestError("Failed to evaluate the rule.", evalErr)
+		}
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// -------------------------------------------------------------------
+
+type ruleEvalResult struct {
+	Rule         *string           `json:"rule"`
+	Reason       string            `json:"reason,omitempty"`
+	FailedClause string            `json:"failedClause,omitempty"`
+	Clauses      []*ruleEvalClause `json:"clauses"`
+	Passed       bool              `json:"passed"`
+}
+
+type ruleEvalClause struct {
+	Join       string `json:"join,omitempty"`
+	Expression string `json:"expression"`
+	Passed     bool   `json:"passed"`
+}
+
+// evalRule evaluates the rule and each of its top level clauses against
+// the provided record and writes the evaluation result in result.
+func evalRule(app core.App, record *core.Record, requestInfo *core.RequestInfo, rule *string, result *ruleEvalResult) error {
+	if rule != nil && *rule != "" {
+		passed, err := evalRuleExpr(app, record, requestInfo, *rule)
+		if err != nil {
+			return err
+		}
+		result.Passed = passed
+
+		for _, clause := range splitRuleClauses(*rule) {
+			clause.Passed, err = evalRuleExpr(app, record, requestInfo, clause.Expression)
+			if err != nil {
+				return err
+			}
+
+			if !clause.Passed && !result.Passed && result.FailedClause == "" {
+				result.FailedClause = clause.Expression
+			}
+
+			result.Clauses = append(result.Clauses, clause)
+		}
+	}
+
+	// apply the special rule values semantic
+	switch {
+	case requestInfo.HasSuperuserAuth():
+		result.Passed = true
+		result.Reason = "Superusers can access everything."
+	case rule == nil:
+		result.Passed = false
+		result.Reason = "Only superusers can perform this action."
+	case *rule == "":
+		result.Passed = true
+		result.Reason = "Everyone can perform this action."
+	}
+
+	return nil
+}
+
+func evalRuleExpr(app core.App, record *core.Record, requestInfo *core.RequestInfo, rule string) (bool, error) {
+	var exists bool
+
+	query := app.RecordQuery(record.Collection()).
+		Select("(1)").
+		AndWhere(dbx.HashExp{record.Collection().Name + ".id": record.Id})
+
+	resolver := core.NewRecordFieldResolver(app, record.Collection(), requestInfo, true)
+	expr, err := search.FilterData(rule).BuildExpr(resolver)
+	if err != nil {
+		return false, err
+	}
+	resolver.UpdateQuery(query)
+
+	err = query.AndWhere(expr).Limit(1).Row(&exists)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// splitRuleClauses splits the rule into its top level "&&" and "||" clauses.
+//
+// If the entire rule is wrapped in parenthesis, the clauses of the inner expression are returned.
+func splitRuleClauses(rule string) []*ruleEvalClause {
+	rs := []rune(rule)
+
+	clauses := []*ruleEvalClause{}
+
+	var join string
+	var depth int
+	var start int
+
+	appendClause := func(end int) {
+		expr := strings.TrimSpace(stripRuleComments(string(rs[start:end])))
+		if expr != "" {
+			clauses = append(clauses, &ruleEvalClause{Join: join, Expression: expr})
+		}
+	}
+
+	for i := 0; i < len(rs); i++ {
+		switch ch := rs[i]; {
+		case ch == '\'' || ch == '"' || ch == '`':
+			// skip quoted text
+			for j := i + 1; j < len(rs); j++ {
+				if rs[j] == '\\' {
+					j++
+					continue
+				}
+				if rs[j] == ch {
+					i = j
+					break
+				}
+			}
+		case ch == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			// skip line comment
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case depth == 0 && i+1 < len(rs) && (ch == '&' && rs[i+1] == '&' || ch == '|' && rs[i+1] == '|'):
+			appendClause(i)
+			join = string(rs[i : i+2])
+			start = i + 2
+			i++
+		}
+	}
+	appendClause(len(rs))
+
+	// unwrap "(...)"
+	if len(clauses) == 1 {
+		expr := clauses[0].Expression
+		if isParenWrapped(expr) {
+			inner := splitRuleClauses(expr[1 : len(expr)-1])
+			if len(inner) > 1 {
+				return inner
+			}
+		}
+	}
+
+	return clauses
+}
+
+// isParenWrapped checks whether the entire expression is enclosed
+// in a single parenthesis group, eg. "(a = 1 && b = 2)".
+func isParenWrapped(expr string) bool {
+	rs := []rune(expr)
+
+	if len(rs) < 2 || rs[0] != '(' || rs[len(rs)-1] != ')' {
+		return false
+	}
+
+	var depth int
+
+	for i := 0; i < len(rs); i++ {
+		switch ch := rs[i]; ch {
+		case '\'', '"', '`':
+			for j := i + 1; j < len(rs); j++ {
+				if rs[j] == '\\' {
+					j++
+					continue
+				}
+				if rs[j] == ch {
+					i = j
+					break
+				}
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i != len(rs)-1 {
+				return false
+			}
+		}
+	}
+
+	return depth == 0
+}
+
+// stripRuleComments removes the "//" line comments from the expression
+// (quoted text is preserved as it is).
+func stripRuleComments(expr string) string {
+	if !strings.Contains(expr, "//") {
+		return expr // fast path
+	}
+
+	rs := []rune(expr)
+
+	var sb strings.Builder
+
+	for i := 0; i < len(rs); i++ {
+		ch := rs[i]
+
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := i + 1
+			for ; end < len(rs); end++ {
+				if rs[end] == '\\' {
+					end++
+					continue
+				}
+				if rs[end] == ch {
+					break
+				}
+			}
+			end = min(end+1, len(rs))
+			sb.WriteString(string(rs[i:end]))
+			i = end - 1
+		case ch == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+			sb.WriteRune(' ')
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+
+	return sb.String()
+}
+
+// -------------------------------------------------------------------
+
+var ruleEvalTypes = []string{"listRule", "viewRule", "createRule", "updateRule", "deleteRule", "authRule", "manageRule"}
+
+type collectionRuleEvalAuth struct {
+	Collection string `form:"collection" json:"collection"`
+	Id         string `form:"id" json:"id"`
+}
+
+type collectionRuleEvalForm struct {
+	collection *core.Collection
+
+	// Rule is the rule expression to evaluate.
+	// If not set, the collection rule of RuleType is used.
+	Rule     *string                 `form:"rule" json:"rule"`
+	RuleType string                  `form:"ruleType" json:"ruleType"`
+	Record   string                  `form:"record" json:"record"`
+	Auth     *collectionRuleEvalAuth `form:"auth" json:"auth"`
+	Method   string                  `form:"method" json:"method"`
+	Query    map[string]string       `form:"query" json:"query"`
+	Headers  map[string]string       `form:"headers" json:"headers"`
+	Body     map[string]any          `form:"body" json:"body"`
+}
+
+func (form *collectionRuleEvalForm) validate() error {
+	ruleTypes := make([]any, 0, len(ruleEvalTypes))
+	for _, t := range ruleEvalTypes {
+		if !form.collection.IsAuth() && (t == "authRule" || t == "manageRule") {
+			continue
+		}
+		ruleTypes = append(ruleTypes, t)
+	}
+
+	return validation.ValidateStruct(form,
+		validation.Field(&form.RuleType, validation.When(form.Rule == nil, validation.Required), validation.In(ruleTypes...)),
+		validation.Field(&form.Method, validation.In(http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete)),
+		validation.Field(&form.Auth, validation.By(func(value any) error {
+			v, _ := value.(*collectionRuleEvalAuth)
+			if v == nil {
+				return nil
+			}
+
+			return validation.ValidateStruct(v,
+				validation.Field(&v.Collection, validation.Required),
+				validation.Field(&v.Id, validation.Required),
+			)
+		})),
+	)
+}
+
+// resolveRule returns the explicit form rule or the collection rule of the form rule type.
+func (form *collectionRuleEvalForm) resolveRule() *string {
+	if form.Rule != nil {
+		return form.Rule
+	}
+
+	switch form.RuleType {
+	case "listRule":
+		return form.collection.ListRule
+	case "viewRule":
+		return form.collection.ViewRule
+	case "createRule":
+		return form.collection.CreateRule
+	case "updateRule":
+		return form.collection.UpdateRule
+	case "deleteRule":
+		return form.collection.DeleteRule
+	case "authRule":
+		return form.collection.AuthRule
+	case "manageRule":
+		return form.collection.ManageRule
+	}
+
+	return nil
+}
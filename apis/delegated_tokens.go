@@ -0,0 +1,115 @@
+package apis
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+// maxDelegatedTokenScopes is the max number of scopes of a single delegated token.
+const maxDelegatedTokenScopes = 50
+
+var delegatedTokenValueRegex = regexp.MustCompile(`^[\w\.\-:/]+$`)
+
+// bindDelegatedTokensApi registers the delegated auth tokens api endpoints.
+func bindDelegatedTokensApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/tokens")
+	sub.POST("/delegate", delegatedTokenCreate).Bind(RequireAuth())
+	sub.POST("/verify", delegatedTokenVerify)
+}
+
+func delegatedTokenCreate(e *core.RequestEvent) error {
+	if e.Get(core.RequestEventKeyAPIKey) != nil {
+		return e.ForbiddenError("The delegated token cannot be generated with an API key.", nil)
+	}
+
+	form := new(delegatedTokenCreateForm)
+	if err := e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err := form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	token, err := e.Auth.NewDelegatedToken(form.Audience, form.Scopes, time.Duration(form.Duration)*time.Second)
+	if err != nil {
+		return e.InternalServerError("Failed to generate the delegated token.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{"token": token})
+}
+
+// delegatedTokenVerify allows third-party services that don't have
+// access to the token secrets to verify a delegated token issued for them.
+func delegatedTokenVerify(e *core.RequestEvent) error {
+	form := new(delegatedTokenVerifyForm)
+	if err := e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err := form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	token, err := e.App.FindDelegatedToken(form.Token, form.Audience)
+	if err != nil {
+		return e.BadRequestError("Invalid or expired delegated token.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"collectionId":   token.Record.Collection().Id,
+		"collectionName": token.Record.Collection().Name,
+		"recordId":       token.Record.Id,
+		"audience":       token.Audience,
+		"scopes":         token.Scopes,
+		"expires":        token.Expires.UTC().Format(time.RFC3339),
+	})
+}
+
+// -------------------------------------------------------------------
+
+type delegatedTokenCreateForm struct {
+	Audience string   `form:"audience" json:"audience"`
+	Scopes   []string `form:"scopes" json:"scopes"`
+
+	// Duration is the optional token duration in seconds
+	// (default to [core.DefaultDelegatedTokenDuration] and
+	// capped to the auth collection token duration).
+	Duration int64 `form:"duration" json:"duration"`
+}
+
+func (form *delegatedTokenCreateForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Audience,
+			validation.Required,
+			validation.Length(1, 255),
+			validation.Match(delegatedTokenValueRegex),
+		),
+		validation.Field(
+			&form.Scopes,
+			validation.Length(0, maxDelegatedTokenScopes),
+			validation.Each(
+				validation.Required,
+				validation.Length(1, 100),
+				validation.Match(delegatedTokenValueRegex),
+			),
+		),
+		validation.Field(&form.Duration, validation.Min(0), validation.Max(94670856)), // ~3y max
+	)
+}
+
+type delegatedTokenVerifyForm struct {
+	Token    string `form:"token" json:"token"`
+	Audience string `form:"audience" json:"audience"`
+}
+
+func (form *delegatedTokenVerifyForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Token, validation.Required),
+		validation.Field(&form.Audience, validation.Required, validation.Length(1, 255)),
+	)
+}
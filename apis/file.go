@@ -7,7 +7,9 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/pocketbase/pocketbase/core"
@@ -64,6 +66,11 @@ func (api *fileApi) fileToken(e *core.RequestEvent) error {
 		return e.UnauthorizedError("Missing auth context.", nil)
 	}
 
+	// the file token is not bound to the API key scopes
+	if e.Get(core.RequestEventKeyAPIKey) != nil {
+		return e.ForbiddenError("The file token cannot be generated with an API key.", nil)
+	}
+
 	token, err := e.Auth.NewFileToken()
 	if err != nil {
 		return e.InternalServerError("Failed to generate file token", err)
@@ -146,8 +153,11 @@ func (api *fileApi) download(e *core.RequestEvent) error {
 	servedName := filename
 
 	// check for valid thumb size param
-	thumbSize := e.Request.URL.Query().Get("thumb")
-	if thumbSize != "" && (list.ExistInSlice(thumbSize, defaultThumbSizes) || list.ExistInSlice(thumbSize, fileField.Thumbs)) {
+	thumbSize, thumbFormat, err := resolveThumb(fileField, e.Request.URL.Query().Get("thumb"))
+	if err != nil {
+		return e.BadRequestError("Invalid or unsupported thumb.", err)
+	}
+	if thumbSize != "" {
 		// extract the original file meta attributes and check it existence
 		oAttrs, oAttrsErr := fsys.Attributes(originalPath)
 		if oAttrsErr != nil {
@@ -158,6 +168,9 @@ func (api *fileApi) download(e *core.RequestEvent) error {
 		if list.ExistInSlice(oAttrs.ContentType, imageContentTypes) {
 			// add thumb size as file suffix
 			servedName = thumbSize + "_" + filename
+			if thumbFormat != "" && !hasThumbFormatExt(filename, thumbFormat) {
+				servedName += "." + thumbFormat
+			}
 			servedPath = baseFilesPath + "/thumbs_" + filename + "/" + servedName
 
 			// create a new thumb if it doesn't exist
@@ -200,6 +213,46 @@ func (api *fileApi) download(e *core.RequestEvent) error {
 	})
 }
 
+// resolveThumb returns the thumb size and the optional thumb format
+// for the specified "thumb" query parameter value.
+//
+// For file fields with configured thumb presets only the preset names
+// are allowed and any other value results in an error.
+//
+// For backward compatibility, if the file field doesn't have thumb presets
+// the param is checked against the default and the file field Thumbs sizes
+// (non-supported sizes are ignored and fallback to the original file).
+func resolveThumb(fileField *core.FileField, param string) (string, string, error) {
+	if param == "" {
+		return "", "", nil
+	}
+
+	if len(fileField.ThumbPresets) > 0 {
+		preset := fileField.FindThumbPreset(param)
+		if preset == nil {
+			return "", "", errors.New("invalid or unsupported thumb preset " + param)
+		}
+
+		return preset.ThumbSize(), preset.Format, nil
+	}
+
+	if list.ExistInSlice(param, defaultThumbSizes) || list.ExistInSlice(param, fileField.Thumbs) {
+		return param, "", nil
+	}
+
+	return "", "", nil
+}
+
+// hasThumbFormatExt checks whether the filename extension already matches the thumb format.
+func hasThumbFormatExt(filename string, format string) bool {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	if ext == "jpeg" {
+		ext = "jpg"
+	}
+
+	return ext == format
+}
+
 func (api *fileApi) createThumb(
 	e *core.RequestEvent,
 	fsys *filesystem.System,
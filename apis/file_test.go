@@ -257,6 +257,62 @@ func TestFileDownload(t *testing.T) {
 				"OnFileDownloadRequest": 1,
 			},
 		},
+		{
+			Name:            "existing image - thumb preset",
+			Method:          http.MethodGet,
+			URL:             "/api/files/_pb_users_auth_/4q1xlclmfloku33/300_1SEi6Q6U72.png?thumb=card",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{string(testThumbCropCenter)},
+			ExpectedEvents: map[string]int{
+				"*":                     0,
+				"OnFileDownloadRequest": 1,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				setAvatarThumbPresets(t, app, core.ThumbPreset{Name: "card", Size: "70x50"})
+			},
+		},
+		{
+			Name:           "existing image - thumb preset with different format",
+			Method:         http.MethodGet,
+			URL:            "/api/files/_pb_users_auth_/4q1xlclmfloku33/300_1SEi6Q6U72.png?thumb=hero",
+			ExpectedStatus: 200,
+			// jpeg magic number
+			ExpectedContent: []string{string([]byte{0xff, 0xd8, 0xff})},
+			ExpectedEvents: map[string]int{
+				"*":                     0,
+				"OnFileDownloadRequest": 1,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				setAvatarThumbPresets(t, app, core.ThumbPreset{Name: "hero", Size: "70x0", Format: "jpg"})
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if ct := res.Header.Get("Content-Type"); ct != "image/jpeg" {
+					t.Fatalf("Expected image/jpeg Content-Type, got %q", ct)
+				}
+			},
+		},
+		{
+			Name:            "existing image - missing thumb preset",
+			Method:          http.MethodGet,
+			URL:             "/api/files/_pb_users_auth_/4q1xlclmfloku33/300_1SEi6Q6U72.png?thumb=missing",
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				setAvatarThumbPresets(t, app, core.ThumbPreset{Name: "card", Size: "70x50"})
+			},
+		},
+		{
+			Name:            "existing image - free-form thumb size with configured thumb presets",
+			Method:          http.MethodGet,
+			URL:             "/api/files/_pb_users_auth_/4q1xlclmfloku33/300_1SEi6Q6U72.png?thumb=70x50",
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				setAvatarThumbPresets(t, app, core.ThumbPreset{Name: "card", Size: "70x50"})
+			},
+		},
 		{
 			Name:            "existing non image file - thumb parameter should be ignored",
 			Method:          http.MethodGet,
@@ -428,6 +484,19 @@ func TestFileDownload(t *testing.T) {
 	}
 }
 
+func setAvatarThumbPresets(t testing.TB, app *tests.TestApp, presets ...core.ThumbPreset) {
+	collection, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection.Fields.GetByName("avatar").(*core.FileField).ThumbPresets = presets
+
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestConcurrentThumbsGeneration(t *testing.T) {
 	t.Parallel()
 
@@ -28,6 +28,8 @@ func loadInstaller(app core.App, dashboardURL string) error {
 		return err
 	}
 
+	installerRecord.SetTokenSigner(app.TokenSigner())
+
 	token, err := installerRecord.NewStaticAuthToken(30 * time.Minute)
 	if err != nil {
 		return err
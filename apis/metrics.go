@@ -0,0 +1,83 @@
+package apis
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+const (
+	defaultSlowHooksThreshold = 100 * time.Millisecond
+	defaultSlowHooksLimit     = 20
+)
+
+// bindMetricsApi registers the metrics api endpoints.
+func bindMetricsApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/metrics").Bind(RequireSuperuserAuth(), SkipSuccessActivityLog())
+	sub.GET("/hooks", metricsHooksList)
+	sub.GET("/hooks/slow", metricsSlowHooks)
+	sub.DELETE("/hooks", metricsHooksReset)
+}
+
+// metricsHooksList returns the execution stats of all triggered hook handlers.
+func metricsHooksList(e *core.RequestEvent) error {
+	return e.JSON(http.StatusOK, map[string]any{
+		"enabled": hook.DefaultMetrics.Enabled(),
+		"items":   hook.DefaultMetrics.Stats(),
+	})
+}
+
+// metricsSlowHooks returns the hook handlers with max execution
+// time >= the "threshold" query parameter (in ms), sorted by their
+// max execution time (slowest first).
+func metricsSlowHooks(e *core.RequestEvent) error {
+	query := e.Request.URL.Query()
+
+	threshold := defaultSlowHooksThreshold
+	if raw := query.Get("threshold"); raw != "" {
+		ms, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || ms < 0 {
+			return e.BadRequestError("Invalid threshold value.", err)
+		}
+		threshold = time.Duration(ms) * time.Millisecond
+	}
+
+	limit := defaultSlowHooksLimit
+	if raw := query.Get("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			return e.BadRequestError("Invalid limit value.", err)
+		}
+		limit = v
+	}
+
+	items := slices.DeleteFunc(hook.DefaultMetrics.Stats(), func(s *hook.HandlerStats) bool {
+		return s.MaxTime < threshold
+	})
+
+	slices.SortStableFunc(items, func(a, b *hook.HandlerStats) int {
+		return cmp.Compare(b.MaxTime, a.MaxTime)
+	})
+
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"threshold": threshold.Milliseconds(),
+		"items":     items,
+	})
+}
+
+// metricsHooksReset clears the collected hook handlers stats.
+func metricsHooksReset(e *core.RequestEvent) error {
+	hook.DefaultMetrics.Reset()
+
+	return e.NoContent(http.StatusNoContent)
+}
@@ -11,11 +11,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/router"
 	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/spf13/cast"
 )
 
@@ -45,6 +47,9 @@ const (
 	DefaultSecurityHeadersMiddlewarePriority = DefaultRateLimitMiddlewarePriority - 10
 	DefaultSecurityHeadersMiddlewareId       = "pbSecurityHeaders"
 
+	DefaultErrorHandlerMiddlewarePriority = DefaultRateLimitMiddlewarePriority - 1
+	DefaultErrorHandlerMiddlewareId       = "pbErrorHandler"
+
 	DefaultRequireGuestOnlyMiddlewareId                 = "pbRequireGuestOnly"
 	DefaultRequireAuthMiddlewareId                      = "pbRequireAuth"
 	DefaultRequireSuperuserAuthMiddlewareId             = "pbRequireSuperuserAuth"
@@ -96,6 +101,10 @@ func requireAuth(optCollectionNames ...string) func(*core.RequestEvent) error {
 			return e.ForbiddenError("The authorized record is not allowed to perform this action.", nil)
 		}
 
+		if err := applyAuthSession(e); err != nil {
+			return err
+		}
+
 		return e.Next()
 	}
 }
@@ -174,6 +183,10 @@ func RequireSameCollectionContextAuth(collectionPathParam string) *hook.Handler[
 //   - missing, invalid or expired token
 //   - e.Auth is already loaded by another middleware
 //
+// Tokens with [core.APIKeyPrefix] are resolved as API keys and the
+// request is rejected with 403 if the key doesn't have the required scope
+// (the loaded [core.APIKey] is stored under [core.RequestEventKeyAPIKey]).
+//
 // This middleware is registered by default for all routes.
 //
 // Note: We don't throw an error on invalid or expired token to allow
@@ -193,6 +206,10 @@ func loadAuthToken() *hook.Handler[*core.RequestEvent] {
 				return e.Next()
 			}
 
+			if strings.HasPrefix(token, core.APIKeyPrefix) {
+				return loadAuthAPIKey(e, token)
+			}
+
 			record, err := e.App.FindAuthRecordByToken(token, core.TokenTypeAuth)
 			if err != nil {
 				e.App.Logger().Debug("loadAuthToken failure", "error", err)
@@ -205,6 +222,49 @@ func loadAuthToken() *hook.Handler[*core.RequestEvent] {
 	}
 }
 
+// apiKeyLastUsedThreshold is the minimum interval between two API key "lastUsed" updates.
+const apiKeyLastUsedThreshold = 1 * time.Minute
+
+func loadAuthAPIKey(e *core.RequestEvent, plainKey string) error {
+	record, apiKey, err := e.App.FindAuthRecordByAPIKey(plainKey)
+	if err != nil {
+		e.App.Logger().Debug("loadAuthToken API key failure", "error", err)
+		return e.Next()
+	}
+
+	access := core.APIKeyScopeWrite
+	switch e.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		access = core.APIKeyScopeRead
+	}
+
+	var collection *core.Collection
+	if param := e.Request.PathValue("collection"); param != "" {
+		collection, _ = e.App.FindCachedCollectionByNameOrId(param)
+	}
+
+	if !apiKey.HasScope(collection, access) {
+		return e.ForbiddenError(fmt.Sprintf("The API key doesn't have the required %q scope.", access), nil)
+	}
+
+	e.Auth = record
+	e.Set(core.RequestEventKeyAPIKey, apiKey)
+
+	// note: direct db update to avoid triggering the model hooks on every request
+	if time.Since(apiKey.LastUsed().Time()) > apiKeyLastUsedThreshold {
+		_, err = e.App.NonconcurrentDB().Update(
+			core.CollectionNameAPIKeys,
+			dbx.Params{"lastUsed": types.NowDateTime().String()},
+			dbx.HashExp{"id": apiKey.Id},
+		).Execute()
+		if err != nil {
+			e.App.Logger().Debug("Failed to update the API key lastUsed date", "error", err, "apiKeyId", apiKey.Id)
+		}
+	}
+
+	return e.Next()
+}
+
 func getAuthTokenFromRequest(e *core.RequestEvent) string {
 	token := e.Request.Header.Get("Authorization")
 	if token != "" {
@@ -277,6 +337,33 @@ func panicRecover() *hook.Handler[*core.RequestEvent] {
 	}
 }
 
+// HandleErrors defines a middleware that passes the errors returned
+// by the next handlers in the chain (including the rate limit and auth
+// middlewares) to the provided fn, allowing custom error responses
+// for a specific route or group of routes.
+//
+// The error returned by fn (if any) is handled by the default router error handler.
+//
+// Example:
+//
+//	g := se.Router.Group("/api/myapp", apis.HandleErrors(func(e *core.RequestEvent, err error) error {
+//	    return e.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+//	}))
+func HandleErrors(fn func(e *core.RequestEvent, err error) error) *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Id:       DefaultErrorHandlerMiddlewareId,
+		Priority: DefaultErrorHandlerMiddlewarePriority,
+		Func: func(e *core.RequestEvent) error {
+			err := e.Next()
+			if err != nil {
+				return fn(e, err)
+			}
+
+			return nil
+		},
+	}
+}
+
 // securityHeaders middleware adds common security headers to the response.
 //
 // This middleware is registered by default for all routes.
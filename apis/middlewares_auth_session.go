@@ -0,0 +1,79 @@
+package apis
+
+import (
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+// RenewedAuthTokenHeader is the name of the response header with the
+// renewed auth token when the auth collection sliding session renewal is enabled.
+const RenewedAuthTokenHeader = "X-Auth-Token"
+
+// applyAuthSession applies the auth collection session options
+// (max lifetime, forced rotation and sliding renewal) to the
+// refreshable auth token of the current request.
+//
+// Static (non-refreshable) tokens and auth records that were not
+// loaded from the request Authorization header are not affected.
+func applyAuthSession(e *core.RequestEvent) error {
+	session := e.Auth.Collection().AuthSession
+	if !session.SlidingRenewal && session.MaxLifetime <= 0 && session.RotationInterval <= 0 {
+		return nil
+	}
+
+	claims, err := security.ParseUnverifiedJWT(getAuthTokenFromRequest(e))
+	if err != nil ||
+		cast.ToString(claims[core.TokenClaimId]) != e.Auth.Id ||
+		cast.ToString(claims[core.TokenClaimType]) != core.TokenTypeAuth ||
+		!cast.ToBool(claims[core.TokenClaimRefreshable]) {
+		return nil
+	}
+
+	issuedAt, sessionStart := core.AuthTokenSessionTimes(claims, e.Auth.Collection())
+
+	now := time.Now()
+
+	if session.MaxLifetime > 0 && now.Sub(sessionStart) >= session.MaxLifetimeTime() {
+		return e.UnauthorizedError("The auth session has expired.", nil)
+	}
+
+	age := now.Sub(issuedAt)
+
+	rotationDue := session.RotationInterval > 0 && age >= session.RotationIntervalTime()
+	if rotationDue && !session.SlidingRenewal {
+		return e.UnauthorizedError("The auth token must be rotated.", nil)
+	}
+
+	if !session.SlidingRenewal {
+		return nil
+	}
+
+	renewAfter := e.Auth.Collection().AuthToken.DurationTime() / 2
+	if session.RotationInterval > 0 {
+		renewAfter = min(renewAfter, session.RotationIntervalTime()/2)
+	}
+
+	if age < renewAfter {
+		return nil
+	}
+
+	e.Auth.SetTokenSigner(e.App.TokenSigner())
+
+	token, err := newSessionAuthToken(e, e.Auth, claims)
+	if err != nil {
+		if rotationDue {
+			return e.UnauthorizedError("Failed to rotate the auth token.", err)
+		}
+
+		e.App.Logger().Debug("Failed to renew the auth token", "error", err)
+
+		return nil
+	}
+
+	e.Response.Header().Set(RenewedAuthTokenHeader, token)
+
+	return nil
+}
@@ -0,0 +1,261 @@
+package apis_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+// newTestSessionToken generates a new "test@example.com" users auth token
+// with the specified issue and session start times.
+func newTestSessionToken(t testing.TB, issuedAt time.Time, sessionStart time.Time, refreshable bool) string {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := core.HS256TokenSigner{}.Sign(
+		jwt.MapClaims{
+			core.TokenClaimType:         core.TokenTypeAuth,
+			core.TokenClaimId:           user.Id,
+			core.TokenClaimCollectionId: user.Collection().Id,
+			core.TokenClaimRefreshable:  refreshable,
+			core.TokenClaimIssuedAt:     issuedAt.Unix(),
+			core.TokenClaimSessionStart: sessionStart.Unix(),
+		},
+		user.TokenKey()+user.Collection().AuthToken.Secret,
+		time.Hour,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func setTestAuthSession(t testing.TB, app *tests.TestApp, session core.AuthSessionConfig) {
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users.AuthSession = session
+
+	if err := app.Save(users); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequireAuthSession(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	// note: the users collection auth token duration is less than 16 days
+	freshToken := newTestSessionToken(t, now, now, true)
+	oldToken := newTestSessionToken(t, now.Add(-8*24*time.Hour), now.Add(-8*24*time.Hour), true)
+	rotationDueToken := newTestSessionToken(t, now.Add(-2*time.Minute), now.Add(-2*time.Minute), true)
+	longSessionToken := newTestSessionToken(t, now.Add(-time.Minute), now.Add(-2*time.Hour), true)
+	staticToken := newTestSessionToken(t, now.Add(-2*time.Minute), now.Add(-2*time.Hour), false)
+
+	expectHeader := func(expected bool, expectedSessionStart time.Time) func(t testing.TB, app *tests.TestApp, res *http.Response) {
+		return func(t testing.TB, app *tests.TestApp, res *http.Response) {
+			token := res.Header.Get(apis.RenewedAuthTokenHeader)
+			if (token != "") != expected {
+				t.Fatalf("Expected renewed token header %v, got %q", expected, token)
+			}
+
+			if token == "" {
+				return
+			}
+
+			record, err := app.FindAuthRecordByToken(token, core.TokenTypeAuth)
+			if err != nil {
+				t.Fatalf("Expected valid renewed token, got %v", err)
+			}
+
+			claims, _ := security.ParseUnverifiedJWT(token)
+			_, sessionStart := core.AuthTokenSessionTimes(claims, record.Collection())
+			if sessionStart.Unix() != expectedSessionStart.Unix() {
+				t.Fatalf("Expected the session start %d to be preserved, got %d", expectedSessionStart.Unix(), sessionStart.Unix())
+			}
+		}
+	}
+
+	scenarios := []struct {
+		name           string
+		token          string
+		session        core.AuthSessionConfig
+		expectedStatus int
+		afterTestFunc  func(t testing.TB, app *tests.TestApp, res *http.Response)
+	}{
+		{
+			"no session options",
+			oldToken,
+			core.AuthSessionConfig{},
+			200,
+			expectHeader(false, time.Time{}),
+		},
+		{
+			"reached max lifetime",
+			longSessionToken,
+			core.AuthSessionConfig{MaxLifetime: 3600},
+			401,
+			nil,
+		},
+		{
+			"rotation due without sliding renewal",
+			rotationDueToken,
+			core.AuthSessionConfig{RotationInterval: 60},
+			401,
+			nil,
+		},
+		{
+			"static token with rotation due",
+			staticToken,
+			core.AuthSessionConfig{RotationInterval: 60, MaxLifetime: 3600},
+			200,
+			expectHeader(false, time.Time{}),
+		},
+		{
+			"sliding renewal with fresh token",
+			freshToken,
+			core.AuthSessionConfig{SlidingRenewal: true},
+			200,
+			expectHeader(false, time.Time{}),
+		},
+		{
+			"sliding renewal with token older than half of its duration",
+			oldToken,
+			core.AuthSessionConfig{SlidingRenewal: true},
+			200,
+			expectHeader(true, now.Add(-8*24*time.Hour)),
+		},
+		{
+			"sliding renewal with rotation due",
+			rotationDueToken,
+			core.AuthSessionConfig{SlidingRenewal: true, RotationInterval: 60},
+			200,
+			expectHeader(true, now.Add(-2*time.Minute)),
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := tests.ApiScenario{
+			Name:   s.name,
+			Method: http.MethodGet,
+			URL:    "/my/test",
+			Headers: map[string]string{
+				"Authorization": s.token,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				setTestAuthSession(t, app, s.session)
+
+				e.Router.GET("/my/test", func(e *core.RequestEvent) error {
+					return e.String(200, "test123")
+				}).Bind(apis.RequireAuth())
+			},
+			AfterTestFunc:  s.afterTestFunc,
+			ExpectedStatus: s.expectedStatus,
+			ExpectedEvents: map[string]int{"*": 0},
+		}
+
+		if s.expectedStatus == 200 {
+			scenario.ExpectedContent = []string{"test123"}
+		} else {
+			scenario.ExpectedContent = []string{`"data":{}`}
+		}
+
+		scenario.Test(t)
+	}
+}
+
+func TestRecordAuthRefreshSession(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	sessionStart := now.Add(-30 * time.Minute)
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:   "reached max lifetime",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-refresh",
+			Headers: map[string]string{
+				"Authorization": newTestSessionToken(t, now.Add(-time.Minute), now.Add(-2*time.Hour), true),
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				setTestAuthSession(t, app, core.AuthSessionConfig{MaxLifetime: 3600})
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"*":                          0,
+				"OnRecordAuthRefreshRequest": 1,
+			},
+		},
+		{
+			Name:   "within max lifetime",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-refresh",
+			Headers: map[string]string{
+				"Authorization": newTestSessionToken(t, now.Add(-time.Minute), sessionStart, true),
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				setTestAuthSession(t, app, core.AuthSessionConfig{MaxLifetime: 3600})
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				body, err := io.ReadAll(res.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				data := struct {
+					Token string `json:"token"`
+				}{}
+				if err := json.Unmarshal(body, &data); err != nil {
+					t.Fatal(err)
+				}
+
+				claims, _ := security.ParseUnverifiedJWT(data.Token)
+
+				if v := cast.ToInt64(claims[core.TokenClaimSessionStart]); v != sessionStart.Unix() {
+					t.Fatalf("Expected the session start %d to be preserved, got %d", sessionStart.Unix(), v)
+				}
+
+				// the token expiration should be capped to the session max lifetime
+				if v := cast.ToInt64(claims["exp"]); v > sessionStart.Add(time.Hour).Unix() {
+					t.Fatalf("Expected exp to be capped to %d, got %d", sessionStart.Add(time.Hour).Unix(), v)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":`,
+				`"record":`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                          0,
+				"OnRecordAuthRefreshRequest": 1,
+				"OnRecordAuthRequest":        1,
+				"OnRecordEnrich":             1,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
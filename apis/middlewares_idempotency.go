@@ -0,0 +1,411 @@
+package apis
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+const (
+	DefaultIdempotencyMiddlewareId       = "pbIdempotency"
+	DefaultIdempotencyMiddlewarePriority = DefaultBodyLimitMiddlewarePriority + 10
+)
+
+const (
+	// IdempotencyKeyHeader is the name of the client request header with the idempotency key.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotencyReplayedHeader is the name of the response header
+	// that is set when the response is replayed from the cache.
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+const (
+	idempotencyStoreKey = "__pbIdempotencyStore__"
+	idempotencyCronKey  = "__pbIdempotencyCleanup__"
+
+	idempotencyMaxKeyLength = 255
+)
+
+// IdempotencyConfig defines the config for the Idempotency middleware.
+type IdempotencyConfig struct {
+	// TTL specifies how long the first response is kept for replay.
+	// Optional. Default value 24h.
+	TTL time.Duration
+
+	// MaxResponseSize specifies the max response body size in bytes
+	// that could be cached (larger responses are not replayed).
+	// Optional. Default value 64KB.
+	MaxResponseSize int
+
+	// MaxEntries specifies the max number of cached responses at a time
+	// (new requests exceeding the limit are processed without caching).
+	// Optional. Default value 1000.
+	MaxEntries int
+
+	// MaxClientEntries specifies the max number of cached responses
+	// per client (aka. per auth record or per IP for the guests).
+	//
+	// When the limit is reached, the oldest completed response
+	// of the client is evicted to make room for the new one.
+	// Optional. Default value 20.
+	MaxClientEntries int
+}
+
+// Idempotency returns a middleware that honors the client "Idempotency-Key"
+// request header by caching the first successful response and replaying it
+// for the retried requests with the same key.
+//
+// This middleware is registered by default for the record create and
+// the auth record routes.
+func Idempotency() *hook.Handler[*core.RequestEvent] {
+	return IdempotencyWithConfig(IdempotencyConfig{})
+}
+
+// IdempotencyWithConfig returns a middleware that honors the client "Idempotency-Key"
+// request header by caching the first successful response and replaying it
+// for the retried requests with the same key.
+//
+// The keys are scoped to the request auth record (or the client IP for the guests), method and path.
+//
+// Only the 2xx responses are cached, aka. a failed request could be retried with the same key.
+// A retried request with the same key but a different body results
+// in 422 error, and a request whose original is still being processed results in 409 error.
+func IdempotencyWithConfig(config IdempotencyConfig) *hook.Handler[*core.RequestEvent] {
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+
+	if config.MaxResponseSize <= 0 {
+		config.MaxResponseSize = 64 << 10
+	}
+
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 1000
+	}
+
+	if config.MaxClientEntries <= 0 {
+		config.MaxClientEntries = 20
+	}
+
+	return &hook.Handler[*core.RequestEvent]{
+		Id:       DefaultIdempotencyMiddlewareId,
+		Priority: DefaultIdempotencyMiddlewarePriority,
+		Func: func(e *core.RequestEvent) error {
+			key := e.Request.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				return e.Next()
+			}
+
+			if len(key) > idempotencyMaxKeyLength {
+				return e.BadRequestError("The "+IdempotencyKeyHeader+" header value is too long.", nil)
+			}
+
+			fingerprint, err := idempotencyFingerprint(e)
+			if err != nil {
+				return firstApiError(err, e.BadRequestError("Failed to read the request body.", err))
+			}
+
+			var client string
+			if e.Auth != nil {
+				client = "auth:" + e.Auth.Collection().Id + ":" + e.Auth.Id
+			} else {
+				client = "ip:" + e.RealIP()
+			}
+			storeKey := client + "|" + e.Request.Method + " " + e.Request.URL.Path + "|" + key
+
+			s := e.App.Store().GetOrSet(idempotencyStoreKey, func() any {
+				return initIdempotencyStore(e.App)
+			}).(*idempotencyStore)
+
+			entry, exists, ok := s.acquire(client, storeKey, fingerprint, config.TTL, config.MaxEntries, config.MaxClientEntries)
+			if !ok {
+				e.App.Logger().Debug(
+					"The idempotency store is full",
+					"maxEntries", config.MaxEntries,
+					"maxClientEntries", config.MaxClientEntries,
+				)
+				return e.Next()
+			}
+
+			if exists {
+				switch {
+				case entry.fingerprint != fingerprint:
+					return router.NewApiError(
+						http.StatusUnprocessableEntity,
+						"The "+IdempotencyKeyHeader+" header value was already used with a different request payload.",
+						nil,
+					)
+				case !entry.completed:
+					return router.NewApiError(
+						http.StatusConflict,
+						"A request with the same "+IdempotencyKeyHeader+" header value is still being processed.",
+						nil,
+					)
+				default:
+					return entry.replay(e)
+				}
+			}
+
+			rw := &idempotencyResponseWriter{ResponseWriter: e.Response, limit: config.MaxResponseSize}
+
+			var cached bool
+
+			originalResponse := e.Response
+			e.Response = rw
+			defer func() {
+				e.Response = originalResponse
+
+				// release the key so that the request could be retried
+				if !cached {
+					s.remove(storeKey, entry)
+				}
+			}()
+
+			err = e.Next()
+
+			status := e.Status()
+			if err == nil && !rw.overflow && status >= 200 && status <= 299 {
+				s.complete(entry, status, rw.Header().Clone(), rw.buf.Bytes())
+				cached = true
+			}
+
+			return err
+		},
+	}
+}
+
+// idempotencyFingerprint returns a hash of the request method, url and body.
+func idempotencyFingerprint(e *core.RequestEvent) (string, error) {
+	h := sha256.New()
+
+	h.Write([]byte(e.Request.Method + " " + e.Request.URL.String() + "\n"))
+
+	if e.Request.Body != nil {
+		if _, err := io.Copy(h, e.Request.Body); err != nil {
+			return "", err
+		}
+
+		if rr, ok := e.Request.Body.(router.Rereader); ok {
+			rr.Reread()
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func initIdempotencyStore(app core.App) *idempotencyStore {
+	app.Cron().Add(idempotencyCronKey, "4 * * * *", func() {
+		s, ok := app.Store().Get(idempotencyStoreKey).(*idempotencyStore)
+		if !ok {
+			return
+		}
+		s.clean()
+	})
+
+	return &idempotencyStore{
+		entries: map[string]*idempotencyEntry{},
+		clients: map[string]int{},
+	}
+}
+
+// -------------------------------------------------------------------
+
+type idempotencyEntry struct {
+	expiresAt   time.Time
+	client      string
+	header      http.Header
+	fingerprint string
+	body        []byte
+	status      int
+	completed   bool
+}
+
+func (entry *idempotencyEntry) replay(e *core.RequestEvent) error {
+	for k, v := range entry.header {
+		e.Response.Header()[k] = v
+	}
+	e.Response.Header().Set(IdempotencyReplayedHeader, "true")
+
+	return e.Blob(entry.status, entry.header.Get("Content-Type"), entry.body)
+}
+
+type idempotencyStore struct {
+	entries map[string]*idempotencyEntry
+	clients map[string]int // number of entries per client
+	mu      sync.Mutex
+}
+
+// acquire returns the existing non-expired entry with the specified key
+// or registers a new pending one.
+//
+// If the client has reached maxClientEntries, its oldest completed entry
+// is evicted before registering the new one.
+//
+// Returns ok false if a new entry cannot be registered because the store
+// is full or all of the client entries are still pending.
+func (s *idempotencyStore) acquire(
+	client string,
+	key string,
+	fingerprint string,
+	ttl time.Duration,
+	maxEntries int,
+	maxClientEntries int,
+) (entry *idempotencyEntry, exists bool, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	entry, exists = s.entries[key]
+	if exists && now.After(entry.expiresAt) {
+		s.delete(key)
+		exists = false
+	}
+
+	if exists {
+		// return a copy to prevent races with the concurrent complete
+		snapshot := *entry
+		return &snapshot, true, true
+	}
+
+	if s.clients[client] >= maxClientEntries && !s.evictOldest(client, now) {
+		return nil, false, false
+	}
+
+	if len(s.entries) >= maxEntries {
+		return nil, false, false
+	}
+
+	entry = &idempotencyEntry{
+		client:      client,
+		fingerprint: fingerprint,
+		expiresAt:   now.Add(ttl),
+	}
+	s.entries[key] = entry
+	s.clients[client]++
+
+	return entry, false, true
+}
+
+func (s *idempotencyStore) complete(entry *idempotencyEntry, status int, header http.Header, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.status = status
+	entry.header = header
+	entry.body = body
+	entry.completed = true
+}
+
+func (s *idempotencyStore) remove(key string, entry *idempotencyEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries[key] == entry {
+		s.delete(key)
+	}
+}
+
+// clean removes the expired entries.
+func (s *idempotencyStore) clean() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	for k, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			s.delete(k)
+		}
+	}
+}
+
+// evictOldest removes the oldest completed or expired entry of the specified client.
+//
+// Returns false if there is no entry to evict
+// (aka. all of the client entries are still pending).
+//
+// Note: it must be called with the store lock held.
+func (s *idempotencyStore) evictOldest(client string, now time.Time) bool {
+	var oldestKey string
+	var oldest *idempotencyEntry
+
+	for k, entry := range s.entries {
+		if entry.client != client || (!entry.completed && !now.After(entry.expiresAt)) {
+			continue
+		}
+
+		if oldest == nil || entry.expiresAt.Before(oldest.expiresAt) {
+			oldestKey = k
+			oldest = entry
+		}
+	}
+
+	if oldest == nil {
+		return false
+	}
+
+	s.delete(oldestKey)
+
+	return true
+}
+
+// delete removes the entry with the specified key and updates its client counter.
+//
+// Note: it must be called with the store lock held.
+func (s *idempotencyStore) delete(key string) {
+	entry, ok := s.entries[key]
+	if !ok {
+		return
+	}
+
+	delete(s.entries, key)
+
+	s.clients[entry.client]--
+	if s.clients[entry.client] <= 0 {
+		delete(s.clients, entry.client)
+	}
+}
+
+// -------------------------------------------------------------------
+
+var (
+	_ http.ResponseWriter = (*idempotencyResponseWriter)(nil)
+	_ router.RWUnwrapper  = (*idempotencyResponseWriter)(nil)
+)
+
+// idempotencyResponseWriter captures a copy of the written response body.
+type idempotencyResponseWriter struct {
+	http.ResponseWriter
+
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (rw *idempotencyResponseWriter) Write(b []byte) (int, error) {
+	if !rw.overflow {
+		if rw.buf.Len()+len(b) > rw.limit {
+			rw.overflow = true
+			rw.buf.Reset()
+		} else {
+			rw.buf.Write(b)
+		}
+	}
+
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWritter instance (usually used by [http.ResponseController]).
+func (rw *idempotencyResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,283 @@
+package apis_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	pbRouter, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	inc := func(name string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[name]++
+		return calls[name]
+	}
+
+	pbRouter.POST("/ok", func(e *core.RequestEvent) error {
+		n := inc("ok")
+		e.Response.Header().Set("X-Test", strconv.Itoa(n))
+		return e.String(201, "ok"+strconv.Itoa(n))
+	}).Bind(apis.Idempotency())
+
+	pbRouter.POST("/fail", func(e *core.RequestEvent) error {
+		n := inc("fail")
+		if n == 1 {
+			return e.BadRequestError("fail", nil)
+		}
+		return e.String(200, "fail"+strconv.Itoa(n))
+	}).Bind(apis.Idempotency())
+
+	pbRouter.POST("/large", func(e *core.RequestEvent) error {
+		n := inc("large")
+		return e.String(200, strings.Repeat("a", 20)+strconv.Itoa(n))
+	}).Bind(apis.IdempotencyWithConfig(apis.IdempotencyConfig{MaxResponseSize: 10}))
+
+	mux, err := pbRouter.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(url string, key string, body string) *http.Response {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(apis.IdempotencyKeyHeader, key)
+		}
+		mux.ServeHTTP(rec, req)
+		return rec.Result()
+	}
+
+	scenarios := []struct {
+		name             string
+		url              string
+		key              string
+		body             string
+		expectedStatus   int
+		expectedBody     string
+		expectedReplayed bool
+	}{
+		{"no key (1)", "/ok", "", "", 201, "ok1", false},
+		{"no key (2)", "/ok", "", "", 201, "ok2", false},
+		{"too long key", "/ok", strings.Repeat("a", 256), "", 400, "", false},
+		{"first request", "/ok", "k1", "a", 201, "ok3", false},
+		{"retry with the same key", "/ok", "k1", "a", 201, "ok3", true},
+		{"retry with the same key and different body", "/ok", "k1", "b", 422, "", false},
+		{"new key", "/ok", "k2", "a", 201, "ok4", false},
+		{"failed responses are not cached (1)", "/fail", "k1", "", 400, "", false},
+		{"failed responses are not cached (2)", "/fail", "k1", "", 200, "fail2", false},
+		{"failed responses are not cached (3)", "/fail", "k1", "", 200, "fail2", true},
+		{"large responses are not cached (1)", "/large", "k1", "", 200, strings.Repeat("a", 20) + "1", false},
+		{"large responses are not cached (2)", "/large", "k1", "", 200, strings.Repeat("a", 20) + "2", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			res := send(s.url, s.key, s.body)
+			defer res.Body.Close()
+
+			if res.StatusCode != s.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", s.expectedStatus, res.StatusCode)
+			}
+
+			if s.expectedBody != "" {
+				body, _ := io.ReadAll(res.Body)
+				if str := string(body); str != s.expectedBody {
+					t.Fatalf("Expected body %q, got %q", s.expectedBody, str)
+				}
+			}
+
+			replayed := res.Header.Get(apis.IdempotencyReplayedHeader) == "true"
+			if replayed != s.expectedReplayed {
+				t.Fatalf("Expected replayed %v, got %v", s.expectedReplayed, replayed)
+			}
+
+			if replayed && s.url == "/ok" && res.Header.Get("X-Test") == "" {
+				t.Fatal("Expected the original response headers to be replayed")
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareInProgress(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	pbRouter, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	pbRouter.POST("/slow", func(e *core.RequestEvent) error {
+		close(started)
+		<-release
+		return e.String(200, "slow")
+	}).Bind(apis.Idempotency())
+
+	mux, err := pbRouter.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest("POST", "/slow", nil)
+		req.Header.Set(apis.IdempotencyKeyHeader, "test")
+		return req
+	}
+
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, newRequest())
+		done <- rec.Code
+	}()
+
+	<-started
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, newRequest())
+	if rec.Code != 409 {
+		t.Fatalf("Expected status 409 for the concurrent request, got %d", rec.Code)
+	}
+
+	close(release)
+
+	if code := <-done; code != 200 {
+		t.Fatalf("Expected status 200 for the original request, got %d", code)
+	}
+}
+
+func TestIdempotencyMiddlewareClientEviction(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	pbRouter, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var calls int
+
+	pbRouter.POST("/ok", func(e *core.RequestEvent) error {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+
+		return e.String(200, "ok"+strconv.Itoa(n))
+	}).Bind(apis.IdempotencyWithConfig(apis.IdempotencyConfig{MaxClientEntries: 2}))
+
+	mux, err := pbRouter.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name             string
+		ip               string
+		key              string
+		expectedBody     string
+		expectedReplayed bool
+	}{
+		{"client1 k1", "1.1.1.1", "k1", "ok1", false},
+		{"client1 k2", "1.1.1.1", "k2", "ok2", false},
+		{"client1 k3 (evicts k1)", "1.1.1.1", "k3", "ok3", false},
+		{"client1 k3 retry", "1.1.1.1", "k3", "ok3", true},
+		{"client1 k2 retry", "1.1.1.1", "k2", "ok2", true},
+		{"client2 k2 (different client scope)", "2.2.2.2", "k2", "ok4", false},
+		{"client2 k2 retry", "2.2.2.2", "k2", "ok4", true},
+		{"client1 k1 retry (evicted, evicts k2)", "1.1.1.1", "k1", "ok5", false},
+		{"client1 k2 retry (evicted)", "1.1.1.1", "k2", "ok6", false},
+		{"client2 k2 retry (not affected by the client1 evictions)", "2.2.2.2", "k2", "ok4", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/ok", nil)
+			req.RemoteAddr = s.ip + ":1234"
+			req.Header.Set(apis.IdempotencyKeyHeader, s.key)
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != 200 {
+				t.Fatalf("Expected status 200, got %d", rec.Code)
+			}
+
+			if body := rec.Body.String(); body != s.expectedBody {
+				t.Fatalf("Expected body %q, got %q", s.expectedBody, body)
+			}
+
+			replayed := rec.Header().Get(apis.IdempotencyReplayedHeader) == "true"
+			if replayed != s.expectedReplayed {
+				t.Fatalf("Expected replayed %v, got %v", s.expectedReplayed, replayed)
+			}
+		})
+	}
+}
+
+func TestIdempotencyRecordCreate(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	pbRouter, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux, err := pbRouter.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var bodies []string
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/collections/demo2/records", strings.NewReader(`{"title":"idempotent"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(apis.IdempotencyKeyHeader, "test")
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != 200 {
+			t.Fatalf("[%d] Expected status 200, got %d (%s)", i, rec.Code, rec.Body.String())
+		}
+
+		bodies = append(bodies, rec.Body.String())
+	}
+
+	if bodies[0] != bodies[1] {
+		t.Fatalf("Expected the same response bodies, got\n%s\n%s", bodies[0], bodies[1])
+	}
+
+	total, err := app.CountRecords("demo2", dbx.HashExp{"title": "idempotent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 1 {
+		t.Fatalf("Expected only 1 created record, got %d", total)
+	}
+}
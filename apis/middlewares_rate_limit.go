@@ -1,6 +1,7 @@
 package apis
 
 import (
+	"strconv"
 	"sync"
 	"time"
 
@@ -48,6 +49,40 @@ func rateLimit() *hook.Handler[*core.RequestEvent] {
 	}
 }
 
+// RateLimit defines a rate limit middleware that checks the request
+// against the first matching rate limit rule for the provided tags
+// (with fallback to the default path labels).
+//
+// It has the same id as the global rate limit middleware and therefore
+// replaces it for the routes (or groups) it is bound to.
+//
+// Example:
+//
+//	g := se.Router.Group("/api/myapp", apis.RateLimit("myapp"))
+func RateLimit(tags ...string) *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Id:       DefaultRateLimitMiddlewareId,
+		Priority: DefaultRateLimitMiddlewarePriority,
+		Func: func(e *core.RequestEvent) error {
+			if skipRateLimit(e) {
+				return e.Next()
+			}
+
+			labels := append(append([]string{}, tags...), defaultRateLimitLabels(e)...)
+
+			rule, ok := e.App.Settings().RateLimits.FindRateLimitRule(labels, defaultRateLimitAudience(e)...)
+			if ok {
+				err := checkRateLimit(e, rule.Label+rule.Audience, rule)
+				if err != nil {
+					return err
+				}
+			}
+
+			return e.Next()
+		},
+	}
+}
+
 // collectionPathRateLimit defines a rate limit middleware for the internal collection handlers.
 func collectionPathRateLimit(collectionPathParam string, baseTags ...string) *hook.Handler[*core.RequestEvent] {
 	if collectionPathParam == "" {
@@ -153,7 +188,9 @@ func checkRateLimit(e *core.RequestEvent, rtId string, rule core.RateLimitRule)
 	}
 
 	rt := rateLimiters.GetOrSet(rtId, func() *rateLimiter {
-		return newRateLimiter(rule.MaxRequests, rule.Duration, rule.Duration+1800)
+		rt := newRateLimiter(rule.MaxRequests, rule.Duration, rule.Duration+1800)
+		rt.rule = rule
+		return rt
 	})
 	if rt == nil {
 		e.App.Logger().Warn("Failed to retrieve app rate limiter", "id", rtId)
@@ -166,7 +203,21 @@ func checkRateLimit(e *core.RequestEvent, rtId string, rule core.RateLimitRule)
 		return nil
 	}
 
-	if !rt.isAllowed(key) {
+	allowed := rt.isAllowed(key)
+
+	if client, ok := rt.getClient(key); ok {
+		remaining, reset := client.status(time.Now().Unix())
+
+		header := e.Response.Header()
+		header.Set("X-RateLimit-Limit", strconv.Itoa(rule.MaxRequests))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		if !allowed {
+			header.Set("Retry-After", strconv.FormatInt(reset, 10))
+		}
+	}
+
+	if !allowed {
 		return e.TooManyRequestsError("", nil)
 	}
 
@@ -240,6 +291,9 @@ func newRateLimiter(maxAllowed int, intervalInSec int64, minDeleteIntervalInSec
 type rateLimiter struct {
 	clients map[string]*fixedWindow
 
+	// rule is the settings rule the limiter was created for
+	rule core.RateLimitRule
+
 	maxAllowed        int
 	interval          int64
 	minDeleteInterval int64
@@ -248,7 +302,6 @@ type rateLimiter struct {
 	sync.RWMutex
 }
 
-//nolint:unused
 func (rt *rateLimiter) getClient(key string) (*fixedWindow, bool) {
 	rt.RLock()
 	client, ok := rt.clients[key]
@@ -330,6 +383,20 @@ func (l *fixedWindow) hasExpired(relativeNow int64, minElapsed int64) bool {
 	return relativeNow-l.lastConsume > minElapsed
 }
 
+// status returns the remaining window allowance and the seconds
+// until the window reset (relative to the provided unix time).
+func (l *fixedWindow) status(relativeNow int64) (int, int64) {
+	l.Lock()
+	defer l.Unlock()
+
+	reset := l.lastConsume + l.interval - relativeNow
+	if reset <= 0 {
+		return l.maxAllowed, 0
+	}
+
+	return l.available, reset
+}
+
 // consume decrease the current window allowance with 1 (if not exhausted already).
 //
 // It returns false if the allowance has been already exhausted and the user
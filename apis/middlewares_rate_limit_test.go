@@ -1,7 +1,9 @@
 package apis_test
 
 import (
+	"fmt"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -157,3 +159,148 @@ func TestDefaultRateLimitMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestRateLimitHeaders(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().RateLimits.Enabled = true
+	app.Settings().RateLimits.Rules = []core.RateLimitRule{
+		{
+			Label:       "/rate/",
+			MaxRequests: 2,
+			Duration:    10,
+		},
+	}
+
+	pbRouter, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbRouter.GET("/norate", func(e *core.RequestEvent) error {
+		return e.String(200, "norate")
+	})
+	pbRouter.GET("/rate/a", func(e *core.RequestEvent) error {
+		return e.String(200, "a")
+	})
+
+	mux, err := pbRouter.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		url               string
+		expectedStatus    int
+		expectedLimit     string
+		expectedRemaining string
+		expectReset       bool
+		expectRetryAfter  bool
+	}{
+		{"/norate", 200, "", "", false, false},
+		{"/rate/a", 200, "2", "1", true, false},
+		{"/rate/a", 200, "2", "0", true, false},
+		{"/rate/a", 429, "2", "0", true, true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.url), func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", s.url, nil)
+
+			mux.ServeHTTP(rec, req)
+
+			result := rec.Result()
+
+			if result.StatusCode != s.expectedStatus {
+				t.Fatalf("Expected response status %d, got %d", s.expectedStatus, result.StatusCode)
+			}
+
+			headers := map[string]string{
+				"X-RateLimit-Limit":     s.expectedLimit,
+				"X-RateLimit-Remaining": s.expectedRemaining,
+			}
+			for name, expected := range headers {
+				if v := result.Header.Get(name); v != expected {
+					t.Fatalf("Expected %s header %q, got %q", name, expected, v)
+				}
+			}
+
+			// the exact value depends on the elapsed time since the first request
+			reset, _ := strconv.Atoi(result.Header.Get("X-RateLimit-Reset"))
+			if s.expectReset != (reset > 0 && reset <= 10) {
+				t.Fatalf("Expected X-RateLimit-Reset header %v, got %q", s.expectReset, result.Header.Get("X-RateLimit-Reset"))
+			}
+
+			retryAfter := result.Header.Get("Retry-After")
+			if s.expectRetryAfter != (retryAfter != "") {
+				t.Fatalf("Expected Retry-After header %v, got %q", s.expectRetryAfter, retryAfter)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().RateLimits.Enabled = true
+	app.Settings().RateLimits.Rules = []core.RateLimitRule{
+		{
+			Label:       "myapp",
+			MaxRequests: 1,
+			Duration:    10,
+		},
+		{
+			Label:       "/",
+			MaxRequests: 100,
+			Duration:    10,
+		},
+	}
+
+	pbRouter, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := pbRouter.Group("/api/myapp", apis.RateLimit("myapp"))
+	g.GET("/a", func(e *core.RequestEvent) error {
+		return e.String(200, "a")
+	})
+	g.GET("/b", func(e *core.RequestEvent) error {
+		return e.String(200, "b")
+	})
+	pbRouter.GET("/other", func(e *core.RequestEvent) error {
+		return e.String(200, "other")
+	})
+
+	mux, err := pbRouter.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		url            string
+		expectedStatus int
+	}{
+		{"/api/myapp/a", 200},
+		{"/api/myapp/b", 429}, // shared group limiter
+		{"/other", 200},
+		{"/other", 200},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.url), func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", s.url, nil)
+
+			mux.ServeHTTP(rec, req)
+
+			result := rec.Result()
+
+			if result.StatusCode != s.expectedStatus {
+				t.Fatalf("Expected response status %d, got %d", s.expectedStatus, result.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package apis
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// bindModelCrudApi registers the superuser only CRUD api endpoints
+// of the custom models registered with [core.ModelConfig.AdminAPI].
+func bindModelCrudApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/models/{table}").Bind(RequireSuperuserAuth())
+	sub.GET("", modelsList)
+	sub.GET("/{id}", modelView)
+	sub.POST("", modelCreate)
+	sub.PATCH("/{id}", modelUpdate)
+	sub.DELETE("/{id}", modelDelete)
+}
+
+func findAdminModel(e *core.RequestEvent) (*core.RegisteredModel, error) {
+	m := core.FindRegisteredModel(e.App, e.Request.PathValue("table"))
+	if m == nil || !m.AdminAPI {
+		return nil, e.NotFoundError("Missing model context.", nil)
+	}
+
+	return m, nil
+}
+
+func findAdminModelById(e *core.RequestEvent, m *core.RegisteredModel) (core.Model, error) {
+	id := e.Request.PathValue("id")
+	if id == "" {
+		return nil, e.NotFoundError("", nil)
+	}
+
+	model := m.New()
+
+	err := e.App.ModelQuery(model).
+		WithContext(e.Request.Context()).
+		AndWhere(dbx.HashExp{m.Table + ".id": id}).
+		Limit(1).
+		One(model)
+	if err != nil {
+		return nil, e.NotFoundError("", err)
+	}
+
+	return model, nil
+}
+
+func modelsList(e *core.RequestEvent) error {
+	m, err := findAdminModel(e)
+	if err != nil {
+		return err
+	}
+
+	// []*T of the registered model type
+	items := reflect.New(reflect.SliceOf(reflect.TypeOf(m.New()))).Interface()
+
+	result, err := search.NewProvider(search.NewSimpleFieldResolver(m.ColumnNames()...)).
+		Query(e.App.ModelQuery(m.New()).WithContext(e.Request.Context())).
+		ParseAndExec(e.Request.URL.Query().Encode(), items)
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+func modelView(e *core.RequestEvent) error {
+	m, err := findAdminModel(e)
+	if err != nil {
+		return err
+	}
+
+	model, err := findAdminModelById(e, m)
+	if err != nil {
+		return err
+	}
+
+	return e.JSON(http.StatusOK, model)
+}
+
+func modelCreate(e *core.RequestEvent) error {
+	m, err := findAdminModel(e)
+	if err != nil {
+		return err
+	}
+
+	model := m.New()
+
+	if err := e.BindBody(model); err != nil {
+		return e.BadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	if err := e.App.SaveWithContext(e.Request.Context(), model); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to create model.", err))
+	}
+
+	return e.JSON(http.StatusOK, model)
+}
+
+func modelUpdate(e *core.RequestEvent) error {
+	m, err := findAdminModel(e)
+	if err != nil {
+		return err
+	}
+
+	model, err := findAdminModelById(e, m)
+	if err != nil {
+		return err
+	}
+
+	pk := model.PK()
+
+	if err := e.BindBody(model); err != nil {
+		return e.BadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	// the model id is not allowed to be changed
+	if model.PK() != pk {
+		return e.BadRequestError("The model id cannot be changed.", nil)
+	}
+
+	if err := e.App.SaveWithContext(e.Request.Context(), model); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to update model.", err))
+	}
+
+	return e.JSON(http.StatusOK, model)
+}
+
+func modelDelete(e *core.RequestEvent) error {
+	m, err := findAdminModel(e)
+	if err != nil {
+		return err
+	}
+
+	model, err := findAdminModelById(e, m)
+	if err != nil {
+		return err
+	}
+
+	if err := e.App.DeleteWithContext(e.Request.Context(), model); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to delete model.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
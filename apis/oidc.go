@@ -0,0 +1,629 @@
+package apis
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+const (
+	oidcCodesStoreKey      string = "@oidcCodes"
+	oidcSigningKeyStoreKey string = "@oidcSigningKey"
+
+	oidcCodeDuration = 5 * time.Minute
+	oidcMaxPending   = 1000
+
+	// oidcAccessTokenType is the JWT "typ" header of the issued access tokens
+	// (see RFC 9068) used to distinguish them from the ID tokens.
+	oidcAccessTokenType = "at+jwt"
+)
+
+// oidcPendingCode holds the data of an issued authorization code
+// waiting to be exchanged with the token endpoint.
+type oidcPendingCode struct {
+	clientId      string
+	redirectURI   string
+	collectionId  string
+	recordId      string
+	scopes        []string
+	nonce         string
+	codeChallenge string
+	authTime      time.Time
+}
+
+// bindOIDCApi registers the OpenID Connect provider endpoints
+// and the corresponding handlers.
+//
+// The issuer identifier is "{AppURL}/api/oidc".
+func bindOIDCApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/oidc")
+
+	sub.GET("/.well-known/openid-configuration", oidcDiscovery).BindFunc(checkOIDCEnabled)
+	sub.GET("/jwks", oidcJWKS).BindFunc(checkOIDCEnabled)
+	sub.GET("/authorize", oidcAuthorize).BindFunc(checkOIDCEnabled)
+	sub.POST("/authorize", oidcAuthorizeSubmit).BindFunc(checkOIDCEnabled).Bind(RequireAuth())
+	sub.POST("/token", oidcToken).BindFunc(checkOIDCEnabled)
+	sub.GET("/userinfo", oidcUserinfo).BindFunc(checkOIDCEnabled)
+	sub.POST("/userinfo", oidcUserinfo).BindFunc(checkOIDCEnabled)
+
+	sub.POST("/clients", oidcClientCreate).Bind(RequireSuperuserAuth())
+}
+
+func checkOIDCEnabled(e *core.RequestEvent) error {
+	if !e.App.Settings().OIDC.Enabled {
+		return e.NotFoundError("The OIDC provider is not enabled.", nil)
+	}
+
+	return e.Next()
+}
+
+func oidcIssuer(app core.App) string {
+	return strings.TrimRight(app.Settings().Meta.AppURL, "/") + "/api/oidc"
+}
+
+func oidcDiscovery(e *core.RequestEvent) error {
+	issuer := oidcIssuer(e.App)
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"userinfo_endpoint":                     issuer + "/userinfo",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"scopes_supported":                      []string{core.OIDCScopeOpenId, core.OIDCScopeEmail, core.OIDCScopeProfile},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported":                      []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "email", "email_verified", "name", "updated_at"},
+	})
+}
+
+func oidcJWKS(e *core.RequestEvent) error {
+	key, err := oidcSigningKey(e.App)
+	if err != nil {
+		return e.InternalServerError("Failed to load the OIDC signing key.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"keys": []map[string]any{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": jwt.SigningMethodRS256.Alg(),
+			"kid": oidcKeyId(&key.PublicKey),
+			"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+		}},
+	})
+}
+
+// oidcAuthorize validates the authorization request and forwards it
+// to the configured app login page.
+func oidcAuthorize(e *core.RequestEvent) error {
+	query := e.Request.URL.Query()
+
+	req := &oidcAuthorizeRequest{
+		ClientId:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		ResponseType:        query.Get("response_type"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		Nonce:               query.Get("nonce"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+	}
+
+	client, err := req.loadClient(e)
+	if err != nil {
+		return err
+	}
+
+	if code, description := req.validate(client); code != "" {
+		return e.Redirect(http.StatusSeeOther, req.errorRedirectURL(code, description))
+	}
+
+	loginURL, err := url.Parse(e.App.Settings().OIDC.LoginURL)
+	if err != nil {
+		return e.InternalServerError("Invalid OIDC login page url.", err)
+	}
+
+	loginQuery := loginURL.Query()
+	for k, v := range query {
+		loginQuery[k] = v
+	}
+	loginURL.RawQuery = loginQuery.Encode()
+
+	return e.Redirect(http.StatusSeeOther, loginURL.String())
+}
+
+// oidcAuthorizeSubmit issues an authorization code for the current auth record
+// and returns the client redirect url that the login page should navigate to.
+func oidcAuthorizeSubmit(e *core.RequestEvent) error {
+	if e.Get(core.RequestEventKeyAPIKey) != nil {
+		return e.ForbiddenError("The OIDC authorization cannot be granted with an API key.", nil)
+	}
+
+	req := &oidcAuthorizeRequest{}
+	if err := e.BindBody(req); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+
+	client, err := req.loadClient(e)
+	if err != nil {
+		return err
+	}
+
+	if code, description := req.validate(client); code != "" {
+		return e.JSON(http.StatusOK, map[string]string{
+			"redirectURL": req.errorRedirectURL(code, description),
+		})
+	}
+
+	if e.Auth.Collection().Id != client.CollectionRef() {
+		return e.ForbiddenError("The authenticated record doesn't belong to the OAuth2 client collection.", nil)
+	}
+
+	code := security.RandomString(40)
+
+	codes := oidcCodesStore(e.App)
+	ok := codes.SetIfLessThanLimit(code, oidcPendingCode{
+		clientId:      client.Id,
+		redirectURI:   req.RedirectURI,
+		collectionId:  e.Auth.Collection().Id,
+		recordId:      e.Auth.Id,
+		scopes:        req.scopes(),
+		nonce:         req.Nonce,
+		codeChallenge: req.CodeChallenge,
+		authTime:      time.Now(),
+	}, oidcMaxPending)
+	if !ok {
+		return e.TooManyRequestsError("Too many pending OIDC authorization codes. Please try again later.", nil)
+	}
+	time.AfterFunc(oidcCodeDuration, func() {
+		codes.Remove(code)
+	})
+
+	params := url.Values{}
+	params.Set("code", code)
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+
+	return e.JSON(http.StatusOK, map[string]string{
+		"redirectURL": appendQuery(req.RedirectURI, params),
+	})
+}
+
+// oidcToken exchanges an authorization code for ID and access tokens.
+//
+// Errors are returned in the OAuth2 token error response format (RFC 6749 Section 5.2).
+func oidcToken(e *core.RequestEvent) error {
+	e.Response.Header().Set("Cache-Control", "no-store")
+
+	if err := e.Request.ParseForm(); err != nil {
+		return oidcTokenError(e, http.StatusBadRequest, "invalid_request", "Failed to parse the request body.")
+	}
+	form := e.Request.PostForm
+
+	if form.Get("grant_type") != "authorization_code" {
+		return oidcTokenError(e, http.StatusBadRequest, "unsupported_grant_type", "Only the authorization_code grant type is supported.")
+	}
+
+	clientId, clientSecret, hasBasicAuth := e.Request.BasicAuth()
+	if hasBasicAuth {
+		// the credentials are form-urlencoded before being base64 encoded
+		clientId, _ = url.QueryUnescape(clientId)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientId = form.Get("client_id")
+		clientSecret = form.Get("client_secret")
+	}
+
+	client, err := e.App.FindOAuthClientById(clientId)
+	if err != nil || (!client.IsPublic() && !client.ValidateSecret(clientSecret)) {
+		return oidcTokenError(e, http.StatusUnauthorized, "invalid_client", "Missing or invalid client credentials.")
+	}
+
+	// consume the one-time code
+	code := form.Get("code")
+	codes := oidcCodesStore(e.App)
+	pending, ok := codes.GetOk(code)
+	codes.Remove(code)
+	if !ok || pending.clientId != client.Id || pending.redirectURI != form.Get("redirect_uri") {
+		return oidcTokenError(e, http.StatusBadRequest, "invalid_grant", "Missing, expired or invalid authorization code.")
+	}
+
+	if pending.codeChallenge != "" {
+		hash := sha256.Sum256([]byte(form.Get("code_verifier")))
+		challenge := base64.RawURLEncoding.EncodeToString(hash[:])
+		if subtle.ConstantTimeCompare([]byte(challenge), []byte(pending.codeChallenge)) != 1 {
+			return oidcTokenError(e, http.StatusBadRequest, "invalid_grant", "Invalid PKCE code verifier.")
+		}
+	}
+
+	record, err := e.App.FindRecordById(pending.collectionId, pending.recordId)
+	if err != nil {
+		return oidcTokenError(e, http.StatusBadRequest, "invalid_grant", "The authorized record no longer exists.")
+	}
+
+	key, err := oidcSigningKey(e.App)
+	if err != nil {
+		e.App.Logger().Error("Failed to load the OIDC signing key", "error", err)
+		return oidcTokenError(e, http.StatusInternalServerError, "server_error", "Failed to issue the tokens.")
+	}
+
+	issuer := oidcIssuer(e.App)
+	duration := time.Duration(e.App.Settings().OIDC.TokenDuration) * time.Second
+	now := time.Now()
+
+	idClaims := jwt.MapClaims{
+		"iss":       issuer,
+		"aud":       client.Id,
+		"iat":       now.Unix(),
+		"exp":       now.Add(duration).Unix(),
+		"auth_time": pending.authTime.Unix(),
+	}
+	if pending.nonce != "" {
+		idClaims["nonce"] = pending.nonce
+	}
+	for k, v := range oidcUserClaims(record, pending.scopes) {
+		idClaims[k] = v
+	}
+
+	idToken, err := oidcSignToken(key, "", idClaims)
+	if err != nil {
+		e.App.Logger().Error("Failed to sign the OIDC ID token", "error", err)
+		return oidcTokenError(e, http.StatusInternalServerError, "server_error", "Failed to issue the tokens.")
+	}
+
+	accessToken, err := oidcSignToken(key, oidcAccessTokenType, jwt.MapClaims{
+		"iss":       issuer,
+		"sub":       record.Id,
+		"aud":       client.Id,
+		"client_id": client.Id,
+		"scope":     strings.Join(pending.scopes, " "),
+		"iat":       now.Unix(),
+		"exp":       now.Add(duration).Unix(),
+	})
+	if err != nil {
+		e.App.Logger().Error("Failed to sign the OIDC access token", "error", err)
+		return oidcTokenError(e, http.StatusInternalServerError, "server_error", "Failed to issue the tokens.")
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int64(duration.Seconds()),
+		"id_token":     idToken,
+		"scope":        strings.Join(pending.scopes, " "),
+	})
+}
+
+// oidcUserinfo returns the claims of the access token subject.
+func oidcUserinfo(e *core.RequestEvent) error {
+	unauthorized := func(err error) error {
+		e.Response.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		return e.UnauthorizedError("Missing or invalid OIDC access token.", err)
+	}
+
+	token, ok := strings.CutPrefix(e.Request.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return unauthorized(nil)
+	}
+
+	key, err := oidcSigningKey(e.App)
+	if err != nil {
+		return e.InternalServerError("Failed to load the OIDC signing key.", err)
+	}
+
+	parsed, err := jwt.Parse(
+		token,
+		func(t *jwt.Token) (any, error) {
+			if t.Header["typ"] != oidcAccessTokenType {
+				return nil, errors.New("not an access token")
+			}
+			return &key.PublicKey, nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+	)
+	if err != nil {
+		return unauthorized(err)
+	}
+
+	claims, _ := parsed.Claims.(jwt.MapClaims)
+	if !claims.VerifyIssuer(oidcIssuer(e.App), true) {
+		return unauthorized(errors.New("invalid issuer"))
+	}
+
+	clientId, _ := claims["client_id"].(string)
+	client, err := e.App.FindOAuthClientById(clientId)
+	if err != nil {
+		return unauthorized(err)
+	}
+
+	recordId, _ := claims["sub"].(string)
+	record, err := e.App.FindRecordById(client.CollectionRef(), recordId)
+	if err != nil {
+		return unauthorized(err)
+	}
+
+	scope, _ := claims["scope"].(string)
+
+	return e.JSON(http.StatusOK, oidcUserClaims(record, strings.Fields(scope)))
+}
+
+// oidcClientCreate registers a new OAuth2 client.
+//
+// The plain client secret (if any) is returned only once and it is not stored.
+func oidcClientCreate(e *core.RequestEvent) error {
+	form := &oidcClientCreateForm{}
+	if err := e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err := form.validate(e.App); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	collection, err := e.App.FindCachedCollectionByNameOrId(form.Collection)
+	if err != nil {
+		return e.BadRequestError("Missing or invalid auth collection.", err)
+	}
+
+	var plainSecret string
+	if !form.Public {
+		plainSecret = core.GenerateOAuthClientSecret()
+	}
+
+	client := core.NewOAuthClient(e.App)
+	client.SetCollectionRef(collection.Id)
+	client.SetName(form.Name)
+	client.SetRedirectURIs(form.RedirectURIs)
+	client.SetSecret(plainSecret)
+
+	if err := e.App.Save(client); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to create the OAuth2 client.", err))
+	}
+
+	e.Response.Header().Set("Cache-Control", "no-store")
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"clientId":     client.Id,
+		"clientSecret": plainSecret,
+		"client":       client,
+	})
+}
+
+// -------------------------------------------------------------------
+
+type oidcAuthorizeRequest struct {
+	ClientId            string `form:"client_id" json:"client_id"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	ResponseType        string `form:"response_type" json:"response_type"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	Nonce               string `form:"nonce" json:"nonce"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+}
+
+// loadClient loads the request client and checks its redirect uri.
+//
+// Note that these errors must not be redirected back to the client.
+func (req *oidcAuthorizeRequest) loadClient(e *core.RequestEvent) (*core.OAuthClient, error) {
+	client, err := e.App.FindOAuthClientById(req.ClientId)
+	if err != nil {
+		return nil, e.BadRequestError("Missing or invalid OAuth2 client.", err)
+	}
+
+	if !client.HasRedirectURI(req.RedirectURI) {
+		return nil, e.BadRequestError("The redirect_uri doesn't match with any of the registered client redirect uris.", nil)
+	}
+
+	return client, nil
+}
+
+// validate checks the remaining authorization request parameters and
+// returns the related OAuth2 error code and description on failure.
+func (req *oidcAuthorizeRequest) validate(client *core.OAuthClient) (string, string) {
+	if req.ResponseType != "code" {
+		return "unsupported_response_type", "Only the code response type is supported."
+	}
+
+	if !slices.Contains(strings.Fields(req.Scope), core.OIDCScopeOpenId) {
+		return "invalid_scope", "The openid scope is required."
+	}
+
+	if len(req.State) > 500 || len(req.Nonce) > 500 {
+		return "invalid_request", "The state and nonce parameters must be no more than 500 characters."
+	}
+
+	if req.CodeChallenge != "" && req.CodeChallengeMethod != "S256" {
+		return "invalid_request", "Only the S256 code challenge method is supported."
+	}
+
+	if req.CodeChallenge == "" && client.IsPublic() {
+		return "invalid_request", "Public clients must use PKCE."
+	}
+
+	return "", ""
+}
+
+// scopes returns the supported request scopes.
+func (req *oidcAuthorizeRequest) scopes() []string {
+	supported := []string{core.OIDCScopeOpenId, core.OIDCScopeEmail, core.OIDCScopeProfile}
+
+	result := []string{}
+	for _, scope := range strings.Fields(req.Scope) {
+		if slices.Contains(supported, scope) && !slices.Contains(result, scope) {
+			result = append(result, scope)
+		}
+	}
+
+	return result
+}
+
+func (req *oidcAuthorizeRequest) errorRedirectURL(code string, description string) string {
+	params := url.Values{}
+	params.Set("error", code)
+	params.Set("error_description", description)
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+
+	return appendQuery(req.RedirectURI, params)
+}
+
+type oidcClientCreateForm struct {
+	Collection   string   `form:"collection" json:"collection"`
+	Name         string   `form:"name" json:"name"`
+	RedirectURIs []string `form:"redirectURIs" json:"redirectURIs"`
+	Public       bool     `form:"public" json:"public"`
+}
+
+func (form *oidcClientCreateForm) validate(app core.App) error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Collection, validation.Required, validation.By(func(value any) error {
+			v, _ := value.(string)
+
+			collection, err := app.FindCachedCollectionByNameOrId(v)
+			if err != nil || !collection.IsAuth() {
+				return validation.NewError("validation_invalid_auth_collection", "Missing or invalid auth collection.")
+			}
+
+			return nil
+		})),
+		validation.Field(&form.Name, validation.Required, validation.Length(1, 255)),
+		// the redirect uris are validated with the model hook
+	)
+}
+
+// -------------------------------------------------------------------
+
+func oidcTokenError(e *core.RequestEvent, status int, code string, description string) error {
+	return e.JSON(status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// oidcUserClaims returns the standard OIDC claims of the provided
+// auth record based on the granted scopes.
+func oidcUserClaims(record *core.Record, scopes []string) map[string]any {
+	claims := map[string]any{"sub": record.Id}
+
+	if slices.Contains(scopes, core.OIDCScopeEmail) && record.Email() != "" {
+		claims["email"] = record.Email()
+		claims["email_verified"] = record.Verified()
+	}
+
+	if slices.Contains(scopes, core.OIDCScopeProfile) {
+		if name := record.GetString("name"); name != "" {
+			claims["name"] = name
+		}
+
+		if updated := record.GetDateTime("updated"); !updated.IsZero() {
+			claims["updated_at"] = updated.Time().Unix()
+		}
+	}
+
+	return claims
+}
+
+func oidcSignToken(key *rsa.PrivateKey, typ string, claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = oidcKeyId(&key.PublicKey)
+	if typ != "" {
+		token.Header["typ"] = typ
+	}
+
+	return token.SignedString(key)
+}
+
+// oidcKeyId returns a stable key identifier of the provided public key.
+func oidcKeyId(key *rsa.PublicKey) string {
+	return security.SHA256(key.N.String())[:16]
+}
+
+// oidcSigningKeyMu guards the signing key generation.
+var oidcSigningKeyMu sync.Mutex
+
+type oidcParsedSigningKey struct {
+	pem string
+	key *rsa.PrivateKey
+}
+
+// oidcSigningKey returns the parsed configured OIDC signing key.
+//
+// If the app settings doesn't have a signing key, a new one is generated and persisted.
+func oidcSigningKey(app core.App) (*rsa.PrivateKey, error) {
+	oidcSigningKeyMu.Lock()
+	defer oidcSigningKeyMu.Unlock()
+
+	pemKey := app.Settings().OIDC.SigningKey
+
+	if pemKey == "" {
+		generated, err := core.GenerateOIDCSigningKey()
+		if err != nil {
+			return nil, err
+		}
+
+		settings, err := app.Settings().Clone()
+		if err != nil {
+			return nil, err
+		}
+		settings.OIDC.SigningKey = generated
+
+		if err := app.Save(settings); err != nil {
+			return nil, err
+		}
+
+		pemKey = generated
+	}
+
+	// reuse the previously parsed key
+	if cached, ok := app.Store().Get(oidcSigningKeyStoreKey).(*oidcParsedSigningKey); ok && cached.pem == pemKey {
+		return cached.key, nil
+	}
+
+	key, err := core.ParseOIDCSigningKey(pemKey)
+	if err != nil {
+		return nil, err
+	}
+
+	app.Store().Set(oidcSigningKeyStoreKey, &oidcParsedSigningKey{pem: pemKey, key: key})
+
+	return key, nil
+}
+
+func oidcCodesStore(app core.App) *store.Store[oidcPendingCode] {
+	return app.Store().GetOrSet(oidcCodesStoreKey, func() any {
+		return store.New[oidcPendingCode](nil)
+	}).(*store.Store[oidcPendingCode])
+}
+
+// appendQuery appends the provided params to the rawURL query string.
+func appendQuery(rawURL string, params url.Values) string {
+	if strings.Contains(rawURL, "?") {
+		return rawURL + "&" + params.Encode()
+	}
+
+	return rawURL + "?" + params.Encode()
+}
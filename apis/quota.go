@@ -0,0 +1,93 @@
+package apis
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+// bindQuotaApi registers the quota api endpoint.
+func bindQuotaApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	subGroup := rg.Group("/quota").Bind(RequireAuth())
+	subGroup.GET("", quotaView)
+}
+
+// RequestsQuota defines the current client state of a single rate limit rule.
+type RequestsQuota struct {
+	Label     string `json:"label"`
+	Audience  string `json:"audience"`
+	Limit     int    `json:"limit"`
+	Remaining int    `json:"remaining"`
+
+	// Reset is the number of seconds until the quota reset.
+	Reset int64 `json:"reset"`
+}
+
+// quotaView returns the rate limit quotas of the current authenticated client.
+//
+// Only the rate limit rules that were already applied for the client are returned.
+func quotaView(e *core.RequestEvent) error {
+	resp := struct {
+		Requests []*RequestsQuota `json:"requests"`
+	}{
+		Requests: []*RequestsQuota{},
+	}
+
+	rateLimiters, ok := e.App.Store().Get(rateLimitersStoreKey).(*store.Store[*rateLimiter])
+	if skipRateLimit(e) || !ok || rateLimiters == nil {
+		return e.JSON(http.StatusOK, resp)
+	}
+
+	key := e.RealIP()
+	now := time.Now().Unix()
+
+	// group the limiters by their rule
+	// (e.g. in case of a "*:create" collection rule there is a separate limiter for each collection)
+	quotas := map[string]*RequestsQuota{}
+	for _, rt := range rateLimiters.GetAll() {
+		if rt.rule.Audience == core.RateLimitRuleAudienceGuest {
+			continue
+		}
+
+		client, ok := rt.getClient(key)
+		if !ok {
+			continue
+		}
+
+		remaining, reset := client.status(now)
+
+		ruleKey := rt.rule.Label + rt.rule.Audience
+
+		// keep the most restrictive state
+		existing, ok := quotas[ruleKey]
+		if ok && existing.Remaining <= remaining {
+			continue
+		}
+
+		quotas[ruleKey] = &RequestsQuota{
+			Label:     rt.rule.Label,
+			Audience:  rt.rule.Audience,
+			Limit:     rt.rule.MaxRequests,
+			Remaining: remaining,
+			Reset:     reset,
+		}
+	}
+
+	for _, q := range quotas {
+		resp.Requests = append(resp.Requests, q)
+	}
+
+	slices.SortFunc(resp.Requests, func(a, b *RequestsQuota) int {
+		if c := strings.Compare(a.Label, b.Label); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Audience, b.Audience)
+	})
+
+	return e.JSON(http.StatusOK, resp)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,11 +28,18 @@ const clientsChunkSize = 150
 // RealtimeClientAuthKey is the name of the realtime client store key that holds its auth state.
 const RealtimeClientAuthKey = "auth"
 
+// Realtime connection transport types.
+const (
+	RealtimeTransportSSE  = "sse"
+	RealtimeTransportPoll = "poll"
+)
+
 // bindRealtimeApi registers the realtime api endpoints.
 func bindRealtimeApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
 	sub := rg.Group("/realtime")
 	sub.GET("", realtimeConnect).Bind(SkipSuccessActivityLog())
 	sub.POST("", realtimeSetSubscriptions)
+	sub.GET("/poll", realtimePoll).Bind(SkipSuccessActivityLog())
 
 	bindRealtimeEvents(app)
 }
@@ -63,6 +71,7 @@ func realtimeConnect(e *core.RequestEvent) error {
 	connectEvent := new(core.RealtimeConnectRequestEvent)
 	connectEvent.RequestEvent = e
 	connectEvent.Client = subscriptions.NewDefaultClient()
+	connectEvent.Transport = RealtimeTransportSSE
 	connectEvent.IdleTimeout = 5 * time.Minute
 
 	return e.App.OnRealtimeConnectRequest().Trigger(connectEvent, func(ce *core.RealtimeConnectRequestEvent) error {
@@ -83,6 +92,9 @@ func realtimeConnect(e *core.RequestEvent) error {
 			Data: []byte(`{"clientId":"` + ce.Client.Id() + `"}`),
 		}
 		connectMsgErr := ce.App.OnRealtimeMessageSend().Trigger(connectMsgEvent, func(me *core.RealtimeMessageEvent) error {
+			if ce.RetryInterval > 0 {
+				me.Response.Write([]byte("retry:" + strconv.FormatInt(ce.RetryInterval.Milliseconds(), 10) + "\n"))
+			}
 			me.Response.Write([]byte("id:" + me.Client.Id() + "\n"))
 			me.Response.Write([]byte("event:" + me.Message.Name + "\n"))
 			me.Response.Write([]byte("data:"))
@@ -103,10 +115,42 @@ func realtimeConnect(e *core.RequestEvent) error {
 		idleTimer := time.NewTimer(ce.IdleTimeout)
 		defer idleTimer.Stop()
 
+		// optional heartbeat to prevent proxies from closing the "silent" connection
+		var heartbeatCh <-chan time.Time
+		if ce.HeartbeatInterval > 0 {
+			heartbeat := time.NewTicker(ce.HeartbeatInterval)
+			defer heartbeat.Stop()
+			heartbeatCh = heartbeat.C
+		}
+
+		// optional max connection lifetime
+		var lifetimeCh <-chan time.Time
+		if ce.MaxLifetime > 0 {
+			lifetimeTimer := time.NewTimer(ce.MaxLifetime)
+			defer lifetimeTimer.Stop()
+			lifetimeCh = lifetimeTimer.C
+		}
+
 		for {
 			select {
 			case <-idleTimer.C:
 				cancelRequest()
+			case <-heartbeatCh:
+				ce.Response.Write([]byte(":ping\n\n"))
+				if err := ce.Flush(); err != nil {
+					ce.App.Logger().Debug(
+						"Realtime connection closed (failed to deliver heartbeat)",
+						slog.String("clientId", ce.Client.Id()),
+						slog.String("error", err.Error()),
+					)
+					return nil
+				}
+			case <-lifetimeCh:
+				ce.App.Logger().Debug(
+					"Realtime connection closed (max lifetime reached)",
+					slog.String("clientId", ce.Client.Id()),
+				)
+				return nil
 			case msg, ok := <-ce.Client.Channel():
 				if !ok {
 					// channel is closed
@@ -201,6 +245,7 @@ func realtimeSetSubscriptions(e *core.RequestEvent) error {
 	return e.App.OnRealtimeSubscribeRequest().Trigger(event, func(e *core.RealtimeSubscribeRequestEvent) error {
 		// update auth state
 		e.Client.Set(RealtimeClientAuthKey, e.Auth)
+		e.Client.Set(RealtimeClientAuthExpiryKey, realtimeAuthTokenExpiry(e.RequestEvent))
 
 		// unsubscribe from any previous existing subscriptions
 		e.Client.Unsubscribe()
@@ -232,6 +277,7 @@ func realtimeUpdateClientsAuth(app core.App, newAuthRecord *core.Record) error {
 					clientAuth.Id == newAuthRecord.Id &&
 					clientAuth.Collection().Name == newAuthRecord.Collection().Name {
 					client.Set(RealtimeClientAuthKey, newAuthRecord)
+					realtimeRevalidateClient(app, client, RealtimeRevokeReasonAuthChange, nil)
 				}
 			}
 
@@ -256,6 +302,8 @@ func realtimeUnsetClientsAuthState(app core.App, authModel core.Model) error {
 					clientAuth.Id == authModel.PK() &&
 					clientAuth.Collection().Name == authModel.TableName() {
 					client.Unset(RealtimeClientAuthKey)
+					client.Unset(RealtimeClientAuthExpiryKey)
+					realtimeRevalidateClient(app, client, RealtimeRevokeReasonAuthChange, nil)
 				}
 			}
 
@@ -267,12 +315,36 @@ func realtimeUnsetClientsAuthState(app core.App, authModel core.Model) error {
 }
 
 func bindRealtimeEvents(app core.App) {
+	// periodically unset the clients expired auth state
+	app.Cron().Add("__pbRealtimeAuthExpiry__", "* * * * *", func() {
+		if err := realtimeRevokeExpiredClientsAuth(app); err != nil {
+			app.Logger().Warn("Failed to revoke the realtime clients expired auth", slog.String("error", err.Error()))
+		}
+	})
+
+	// revalidate the collection subscriptions in case of rules change
+	// (note: uses the main app instance because e.App could be an already committed transaction)
+	app.OnCollectionAfterUpdateSuccess().Bind(&hook.Handler[*core.CollectionEvent]{
+		Func: func(e *core.CollectionEvent) error {
+			if err := realtimeRevalidateCollectionClients(app, e.Collection); err != nil {
+				app.Logger().Warn(
+					"Failed to revalidate the collection realtime subscriptions",
+					slog.String("collectionName", e.Collection.Name),
+					slog.String("error", err.Error()),
+				)
+			}
+
+			return e.Next()
+		},
+		Priority: -99,
+	})
+
 	// update the clients that has auth record association
 	app.OnModelAfterUpdateSuccess().Bind(&hook.Handler[*core.ModelEvent]{
 		Func: func(e *core.ModelEvent) error {
 			authRecord := realtimeResolveRecord(e.App, e.Model, core.CollectionTypeAuth)
 			if authRecord != nil {
-				if err := realtimeUpdateClientsAuth(e.App, authRecord); err != nil {
+				if err := realtimeUpdateClientsAuth(app, authRecord); err != nil {
 					app.Logger().Warn(
 						"Failed to update client(s) associated to the updated auth record",
 						slog.Any("id", authRecord.Id),
@@ -293,7 +365,7 @@ func bindRealtimeEvents(app core.App) {
 		Func: func(e *core.ModelEvent) error {
 			collection := realtimeResolveRecordCollection(e.App, e.Model)
 			if collection != nil && collection.IsAuth() {
-				if err := realtimeUnsetClientsAuthState(e.App, e.Model); err != nil {
+				if err := realtimeUnsetClientsAuthState(app, e.Model); err != nil {
 					app.Logger().Warn(
 						"Failed to remove client(s) associated to the deleted auth model",
 						slog.Any("id", e.Model.PK()),
@@ -499,7 +571,7 @@ func realtimeBroadcastRecord(app core.App, action string, record *core.Record, d
 						continue
 					}
 
-					clientAuth, _ = client.Get(RealtimeClientAuthKey).(*core.Record)
+					clientAuth = realtimeClientAuth(app, client)
 
 					for sub, options := range subs {
 						// create a clean record copy without expand and unknown fields
@@ -516,6 +588,12 @@ func realtimeBroadcastRecord(app core.App, action string, record *core.Record, d
 						}
 
 						if !realtimeCanAccessRecord(app, cleanRecord, requestInfo, rule) {
+							// revoke the single record subscription if the record is no longer accessible
+							if action == "update" && strings.HasSuffix(prefix, "/"+record.Id+"?") {
+								if ok, _ := app.CanAccessRecord(cleanRecord, requestInfo, rule); !ok {
+									realtimeRevokeSubscriptions(app, client, RealtimeRevokeReasonAccessChange, sub)
+								}
+							}
 							continue
 						}
 
@@ -0,0 +1,262 @@
+package apis
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+// realtimeClientPollKey is the name of the realtime client store key
+// that holds the long-polling transport state.
+const realtimeClientPollKey = "@poll"
+
+// realtimePollMaxQueue is the max number of messages that could be queued
+// between two poll requests (older messages are discarded on overflow).
+const realtimePollMaxQueue = 500
+
+// realtimePollMessage is the JSON representation of a single polled realtime message.
+type realtimePollMessage struct {
+	Name string          `json:"name"`
+	Data json.RawMessage `json:"data"`
+}
+
+// realtimePollResponse is the JSON response of a single poll request.
+type realtimePollResponse struct {
+	ClientId string                `json:"clientId"`
+	Messages []realtimePollMessage `json:"messages"`
+}
+
+// realtimePoll handles the long-polling realtime transport fallback
+// for environments where the SSE responses are buffered (e.g. some proxies).
+//
+// A request without "clientId" query parameter creates a new client
+// and returns the PB_CONNECT message. Subsequent requests with the
+// "clientId" query parameter wait for and return the queued client messages.
+//
+// The client subscriptions are managed with the regular POST /api/realtime endpoint.
+func realtimePoll(e *core.RequestEvent) error {
+	e.Response.Header().Set("Cache-Control", "no-store")
+
+	clientId := e.Request.URL.Query().Get("clientId")
+	if clientId == "" {
+		return realtimePollConnect(e)
+	}
+
+	client, err := e.App.SubscriptionsBroker().ClientById(clientId)
+	if err != nil {
+		return e.NotFoundError("Missing or invalid client id.", err)
+	}
+
+	state, _ := client.Get(realtimeClientPollKey).(*realtimePollState)
+	if state == nil {
+		return e.BadRequestError("The client is not a long-polling one.", nil)
+	}
+
+	clientAuth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
+	if clientAuth != nil && !isSameAuth(clientAuth, e.Auth) {
+		return e.ForbiddenError("The current and the previous request authorization don't match.", nil)
+	}
+
+	return realtimePollSend(e, client, state.wait(e.Request.Context()))
+}
+
+func realtimePollConnect(e *core.RequestEvent) error {
+	connectEvent := new(core.RealtimeConnectRequestEvent)
+	connectEvent.RequestEvent = e
+	connectEvent.Client = subscriptions.NewDefaultClient()
+	connectEvent.Transport = RealtimeTransportPoll
+	connectEvent.IdleTimeout = 5 * time.Minute
+	connectEvent.PollTimeout = 25 * time.Second
+
+	// note: unlike the SSE transport, the finalizer returns right after
+	// the client registration and the client lives until it becomes idle
+	return e.App.OnRealtimeConnectRequest().Trigger(connectEvent, func(ce *core.RealtimeConnectRequestEvent) error {
+		state := newRealtimePollState(ce.PollTimeout)
+
+		ce.Client.Set(realtimeClientPollKey, state)
+
+		ce.App.SubscriptionsBroker().Register(ce.Client)
+
+		go state.run(ce.App, ce.Client, ce.IdleTimeout, ce.MaxLifetime)
+
+		ce.App.Logger().Debug(
+			"Realtime long-polling connection established.",
+			slog.String("clientId", ce.Client.Id()),
+		)
+
+		return realtimePollSend(ce.RequestEvent, ce.Client, []subscriptions.Message{{
+			Name: "PB_CONNECT",
+			Data: []byte(`{"clientId":"` + ce.Client.Id() + `"}`),
+		}})
+	})
+}
+
+// realtimePollSend triggers the OnRealtimeMessageSend hook for each
+// of the specified messages and writes the delivered ones as JSON response.
+func realtimePollSend(e *core.RequestEvent, client subscriptions.Client, messages []subscriptions.Message) error {
+	result := realtimePollResponse{
+		ClientId: client.Id(),
+		Messages: make([]realtimePollMessage, 0, len(messages)),
+	}
+
+	for _, msg := range messages {
+		msgEvent := new(core.RealtimeMessageEvent)
+		msgEvent.RequestEvent = e
+		msgEvent.Client = client
+		msgEvent.Message = &msg
+		err := e.App.OnRealtimeMessageSend().Trigger(msgEvent, func(me *core.RealtimeMessageEvent) error {
+			data := json.RawMessage(me.Message.Data)
+			if !json.Valid(data) {
+				// wrap non-JSON data as string
+				data, _ = json.Marshal(string(me.Message.Data))
+			}
+
+			result.Messages = append(result.Messages, realtimePollMessage{
+				Name: me.Message.Name,
+				Data: data,
+			})
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// -------------------------------------------------------------------
+
+// realtimePollState holds the queued messages of a long-polling client.
+type realtimePollState struct {
+	mu       sync.Mutex
+	queue    []subscriptions.Message
+	timeout  time.Duration
+	notifyCh chan struct{}
+	touchCh  chan struct{}
+	doneCh   chan struct{}
+}
+
+func newRealtimePollState(timeout time.Duration) *realtimePollState {
+	return &realtimePollState{
+		timeout:  timeout,
+		notifyCh: make(chan struct{}, 1),
+		touchCh:  make(chan struct{}, 1),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// run moves the client channel messages into the poll queue until the
+// client is discarded, becomes idle or reaches its max lifetime.
+func (s *realtimePollState) run(app core.App, client subscriptions.Client, idleTimeout time.Duration, maxLifetime time.Duration) {
+	defer func() {
+		close(s.doneCh)
+		app.SubscriptionsBroker().Unregister(client.Id())
+	}()
+
+	idleTimer := time.NewTimer(idleTimeout)
+	defer idleTimer.Stop()
+
+	var lifetimeCh <-chan time.Time
+	if maxLifetime > 0 {
+		lifetimeTimer := time.NewTimer(maxLifetime)
+		defer lifetimeTimer.Stop()
+		lifetimeCh = lifetimeTimer.C
+	}
+
+	for {
+		select {
+		case msg, ok := <-client.Channel():
+			if !ok {
+				app.Logger().Debug(
+					"Realtime long-polling connection closed (closed channel)",
+					slog.String("clientId", client.Id()),
+				)
+				return
+			}
+			s.push(msg)
+		case <-s.touchCh:
+			idleTimer.Stop()
+			idleTimer.Reset(idleTimeout)
+		case <-idleTimer.C:
+			app.Logger().Debug(
+				"Realtime long-polling connection closed (idle timeout)",
+				slog.String("clientId", client.Id()),
+			)
+			return
+		case <-lifetimeCh:
+			app.Logger().Debug(
+				"Realtime long-polling connection closed (max lifetime reached)",
+				slog.String("clientId", client.Id()),
+			)
+			return
+		}
+	}
+}
+
+// push appends a new message to the queue and notifies the waiting poll request (if any).
+func (s *realtimePollState) push(msg subscriptions.Message) {
+	s.mu.Lock()
+	s.queue = append(s.queue, msg)
+	if len(s.queue) > realtimePollMaxQueue {
+		s.queue = s.queue[len(s.queue)-realtimePollMaxQueue:]
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// drain returns and clears the queued messages.
+func (s *realtimePollState) drain() []subscriptions.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := s.queue
+	s.queue = nil
+
+	return messages
+}
+
+// touch resets the client idle timer.
+func (s *realtimePollState) touch() {
+	select {
+	case s.touchCh <- struct{}{}:
+	default:
+	}
+}
+
+// wait blocks until there are queued messages, the poll timeout
+// is reached, the client is discarded or ctx is done.
+func (s *realtimePollState) wait(ctx context.Context) []subscriptions.Message {
+	s.touch()
+	defer s.touch()
+
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+
+	for {
+		if messages := s.drain(); len(messages) > 0 {
+			return messages
+		}
+
+		select {
+		case <-s.notifyCh:
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return nil
+		case <-s.doneCh:
+			return s.drain()
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package apis
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"github.com/spf13/cast"
+	"golang.org/x/sync/errgroup"
+)
+
+// RealtimeClientAuthExpiryKey is the name of the realtime client store key
+// that holds the expiration time of its auth token.
+const RealtimeClientAuthExpiryKey = "authExpiry"
+
+// RealtimeRevokeMessageName is the name of the realtime message that is sent
+// to the client when some of its subscriptions are no longer authorized
+// and were removed by the server.
+const RealtimeRevokeMessageName = "PB_UNSUBSCRIBE"
+
+// Realtime subscriptions revoke reasons.
+const (
+	RealtimeRevokeReasonRuleChange   = "rule_change"
+	RealtimeRevokeReasonAccessChange = "access_change"
+	RealtimeRevokeReasonAuthChange   = "auth_change"
+	RealtimeRevokeReasonAuthExpired  = "auth_expired"
+)
+
+// realtimeRevokeData represents the [RealtimeRevokeMessageName] message data.
+type realtimeRevokeData struct {
+	Reason        string   `json:"reason"`
+	Subscriptions []string `json:"subscriptions"`
+}
+
+// realtimeAuthTokenExpiry returns the expiration time of the request auth token
+// (or zero time if there is no auth token or it doesn't have "exp" claim).
+func realtimeAuthTokenExpiry(e *core.RequestEvent) time.Time {
+	if e.Auth == nil {
+		return time.Time{}
+	}
+
+	claims, _ := security.ParseUnverifiedJWT(getAuthTokenFromRequest(e))
+
+	exp := cast.ToInt64(claims["exp"])
+	if exp <= 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(exp, 0)
+}
+
+// realtimeClientAuth returns the client auth record.
+//
+// If the client auth token has expired, the client auth state is
+// unset and its no longer authorized subscriptions are revoked.
+func realtimeClientAuth(app core.App, client subscriptions.Client) *core.Record {
+	auth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
+	if auth == nil {
+		return nil
+	}
+
+	exp, _ := client.Get(RealtimeClientAuthExpiryKey).(time.Time)
+	if exp.IsZero() || time.Now().Before(exp) {
+		return auth
+	}
+
+	client.Unset(RealtimeClientAuthKey)
+	client.Unset(RealtimeClientAuthExpiryKey)
+
+	realtimeRevalidateClient(app, client, RealtimeRevokeReasonAuthExpired, nil)
+
+	return nil
+}
+
+// realtimeRevokeExpiredClientsAuth unsets the auth state of all clients with expired auth token.
+func realtimeRevokeExpiredClientsAuth(app core.App) error {
+	chunks := app.SubscriptionsBroker().ChunkedClients(clientsChunkSize)
+
+	group := new(errgroup.Group)
+
+	for _, chunk := range chunks {
+		group.Go(func() error {
+			for _, client := range chunk {
+				realtimeClientAuth(app, client)
+			}
+
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+// realtimeRevalidateCollectionClients revalidates the subscriptions
+// of all clients subscribed to the specified collection topics.
+func realtimeRevalidateCollectionClients(app core.App, collection *core.Collection) error {
+	chunks := app.SubscriptionsBroker().ChunkedClients(clientsChunkSize)
+
+	group := new(errgroup.Group)
+
+	for _, chunk := range chunks {
+		group.Go(func() error {
+			for _, client := range chunk {
+				realtimeRevalidateClient(app, client, RealtimeRevokeReasonRuleChange, collection)
+			}
+
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+// realtimeRevalidateClient checks the client subscriptions against
+// the current collection rules and revokes the ones that no longer pass.
+//
+// Wildcard topics are revoked only if the collection list rule is
+// superusers only. Single record topics are revoked if the record
+// view rule is no longer satisfied.
+//
+// If optCollection is set, only the topics of this collection are checked.
+func realtimeRevalidateClient(
+	app core.App,
+	client subscriptions.Client,
+	reason string,
+	optCollection *core.Collection,
+) {
+	subs := client.Subscriptions()
+	if len(subs) == 0 {
+		return
+	}
+
+	auth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
+
+	var revoked []string
+
+	for sub, options := range subs {
+		topic, _, _ := strings.Cut(sub, "?")
+
+		collectionIdentifier, recordId, _ := strings.Cut(topic, "/")
+
+		var collection *core.Collection
+		if optCollection != nil {
+			if collectionIdentifier != optCollection.Name && collectionIdentifier != optCollection.Id {
+				continue
+			}
+
+			// note: the collections cache may not be refreshed yet
+			collection = optCollection
+		} else {
+			var err error
+			collection, err = app.FindCachedCollectionByNameOrId(collectionIdentifier)
+			if err != nil {
+				continue // custom non-collection topic
+			}
+		}
+
+		requestInfo := &core.RequestInfo{
+			Context: core.RequestInfoContextRealtime,
+			Method:  "GET",
+			Query:   options.Query,
+			Headers: options.Headers,
+			Auth:    auth,
+		}
+
+		if requestInfo.HasSuperuserAuth() {
+			continue
+		}
+
+		if recordId == "" || recordId == "*" {
+			if collection.ListRule == nil {
+				revoked = append(revoked, sub)
+			}
+			continue
+		}
+
+		record, err := app.FindRecordById(collection, recordId)
+		if err != nil {
+			continue // missing or deleted record (the delete event is handled separately)
+		}
+
+		if ok, _ := app.CanAccessRecord(record, requestInfo, collection.ViewRule); !ok {
+			revoked = append(revoked, sub)
+		}
+	}
+
+	realtimeRevokeSubscriptions(app, client, reason, revoked...)
+}
+
+// realtimeRevokeSubscriptions removes the specified client subscriptions
+// and notifies the client with a [RealtimeRevokeMessageName] message.
+func realtimeRevokeSubscriptions(app core.App, client subscriptions.Client, reason string, subs ...string) {
+	if len(subs) == 0 {
+		return
+	}
+
+	client.Unsubscribe(subs...)
+
+	app.Logger().Debug(
+		"Realtime subscriptions revoked.",
+		slog.String("clientId", client.Id()),
+		slog.String("reason", reason),
+		slog.Any("subscriptions", subs),
+	)
+
+	data, err := json.Marshal(realtimeRevokeData{Reason: reason, Subscriptions: subs})
+	if err != nil {
+		return
+	}
+
+	routine.FireAndForget(func() {
+		client.Send(subscriptions.Message{
+			Name: RealtimeRevokeMessageName,
+			Data: data,
+		})
+	})
+}
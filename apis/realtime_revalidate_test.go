@@ -0,0 +1,272 @@
+package apis_test
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+type realtimeRevokeMessage struct {
+	Reason        string   `json:"reason"`
+	Subscriptions []string `json:"subscriptions"`
+}
+
+func waitRealtimeRevokeMessage(t *testing.T, client subscriptions.Client) realtimeRevokeMessage {
+	t.Helper()
+
+	for {
+		select {
+		case msg := <-client.Channel():
+			if msg.Name != apis.RealtimeRevokeMessageName {
+				continue // other broadcasted message
+			}
+
+			data := realtimeRevokeMessage{}
+			if err := json.Unmarshal(msg.Data, &data); err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(data.Subscriptions)
+
+			return data
+		case <-time.After(1 * time.Second):
+			t.Fatal("Expected a revoke message")
+			return realtimeRevokeMessage{}
+		}
+	}
+}
+
+func TestRealtimeRevokeOnRuleChange(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	// init realtime handlers
+	apis.NewRouter(testApp)
+
+	client := subscriptions.NewDefaultClient()
+	client.Subscribe(
+		"demo2/*",
+		"demo2/0yxhwia2amd8gec",
+		`demo2/0yxhwia2amd8gec?options={"query":{"a":"b"}}`,
+		"demo4/*",
+		"custom",
+	)
+	testApp.SubscriptionsBroker().Register(client)
+
+	demo2, err := testApp.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	demo2.ListRule = nil
+	demo2.ViewRule = types.Pointer("@request.auth.id != ''")
+	if err := testApp.Save(demo2); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := waitRealtimeRevokeMessage(t, client)
+
+	if msg.Reason != apis.RealtimeRevokeReasonRuleChange {
+		t.Fatalf("Expected reason %q, got %q", apis.RealtimeRevokeReasonRuleChange, msg.Reason)
+	}
+
+	expectedRevoked := []string{
+		"demo2/*",
+		"demo2/0yxhwia2amd8gec",
+		`demo2/0yxhwia2amd8gec?options={"query":{"a":"b"}}`,
+	}
+	if !slices.Equal(msg.Subscriptions, expectedRevoked) {
+		t.Fatalf("Expected revoked subscriptions %v, got %v", expectedRevoked, msg.Subscriptions)
+	}
+
+	for _, sub := range expectedRevoked {
+		if client.HasSubscription(sub) {
+			t.Fatalf("Expected subscription %q to be removed", sub)
+		}
+	}
+
+	for _, sub := range []string{"demo4/*", "custom"} {
+		if !client.HasSubscription(sub) {
+			t.Fatalf("Expected subscription %q to remain", sub)
+		}
+	}
+}
+
+func TestRealtimeRevokeOnRuleChangeSuperuser(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	// init realtime handlers
+	apis.NewRouter(testApp)
+
+	superuser, err := testApp.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := subscriptions.NewDefaultClient()
+	client.Set(apis.RealtimeClientAuthKey, superuser)
+	client.Subscribe("demo2/*", "demo2/0yxhwia2amd8gec")
+	testApp.SubscriptionsBroker().Register(client)
+
+	demo2, err := testApp.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	demo2.ListRule = nil
+	demo2.ViewRule = nil
+	if err := testApp.Save(demo2); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := len(client.Subscriptions()); total != 2 {
+		t.Fatalf("Expected the superuser subscriptions to remain, got %v", client.Subscriptions())
+	}
+}
+
+func TestRealtimeRevokeOnRecordAccessChange(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	// init realtime handlers
+	apis.NewRouter(testApp)
+
+	demo2, err := testApp.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	demo2.ViewRule = types.Pointer("active = true")
+	if err := testApp.Save(demo2); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := testApp.FindRecordById(demo2, "0yxhwia2amd8gec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Set("active", true)
+	if err := testApp.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	client := subscriptions.NewDefaultClient()
+	client.Subscribe("demo2/0yxhwia2amd8gec", `demo2/0yxhwia2amd8gec?options={"query":{"filter":"title='missing'"}}`, "demo2/*")
+	testApp.SubscriptionsBroker().Register(client)
+
+	record.Set("active", false)
+	if err := testApp.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// each revoked subscription is sent separately
+	revoked := []string{}
+	for i := 0; i < 2; i++ {
+		msg := waitRealtimeRevokeMessage(t, client)
+		if msg.Reason != apis.RealtimeRevokeReasonAccessChange {
+			t.Fatalf("Expected reason %q, got %q", apis.RealtimeRevokeReasonAccessChange, msg.Reason)
+		}
+		revoked = append(revoked, msg.Subscriptions...)
+	}
+	slices.Sort(revoked)
+
+	expectedRevoked := []string{"demo2/0yxhwia2amd8gec", `demo2/0yxhwia2amd8gec?options={"query":{"filter":"title='missing'"}}`}
+	if !slices.Equal(revoked, expectedRevoked) {
+		t.Fatalf("Expected revoked subscriptions %v, got %v", expectedRevoked, revoked)
+	}
+
+	if !client.HasSubscription("demo2/*") {
+		t.Fatal("Expected the wildcard subscription to remain")
+	}
+}
+
+func TestRealtimeRevokeOnAuthExpiry(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	// init realtime handlers
+	apis.NewRouter(testApp)
+
+	user, err := testApp.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := subscriptions.NewDefaultClient()
+	client.Set(apis.RealtimeClientAuthKey, user)
+	client.Set(apis.RealtimeClientAuthExpiryKey, time.Now().Add(-1*time.Second))
+	client.Subscribe("users/"+user.Id, "demo2/*")
+	testApp.SubscriptionsBroker().Register(client)
+
+	// trigger a broadcast
+	record, err := testApp.FindRecordById("demo2", "0yxhwia2amd8gec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Set("title", "changed")
+	if err := testApp.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := waitRealtimeRevokeMessage(t, client)
+
+	if msg.Reason != apis.RealtimeRevokeReasonAuthExpired {
+		t.Fatalf("Expected reason %q, got %q", apis.RealtimeRevokeReasonAuthExpired, msg.Reason)
+	}
+
+	if !slices.Equal(msg.Subscriptions, []string{"users/" + user.Id}) {
+		t.Fatalf("Expected only the users subscription to be revoked, got %v", msg.Subscriptions)
+	}
+
+	if auth := client.Get(apis.RealtimeClientAuthKey); auth != nil {
+		t.Fatalf("Expected the client auth state to be unset, got %v", auth)
+	}
+
+	if !client.HasSubscription("demo2/*") {
+		t.Fatal("Expected the demo2 subscription to remain")
+	}
+}
+
+func TestRealtimeRevokeOnAuthDelete(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	// init realtime handlers
+	apis.NewRouter(testApp)
+
+	demo2, err := testApp.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	demo2.ViewRule = types.Pointer("@request.auth.id != ''")
+	if err := testApp.Save(demo2); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := testApp.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := subscriptions.NewDefaultClient()
+	client.Set(apis.RealtimeClientAuthKey, user)
+	client.Subscribe("demo2/0yxhwia2amd8gec")
+	testApp.SubscriptionsBroker().Register(client)
+
+	if err := testApp.Delete(user); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := waitRealtimeRevokeMessage(t, client)
+	if msg.Reason != apis.RealtimeRevokeReasonAuthChange {
+		t.Fatalf("Expected reason %q, got %q", apis.RealtimeRevokeReasonAuthChange, msg.Reason)
+	}
+
+	if !slices.Equal(msg.Subscriptions, []string{"demo2/0yxhwia2amd8gec"}) {
+		t.Fatalf("Expected the demo2 subscription to be revoked, got %v", msg.Subscriptions)
+	}
+}
@@ -86,6 +86,93 @@ func TestRealtimeConnect(t *testing.T) {
 				}
 			},
 		},
+		{
+			Name:           "retry interval",
+			Method:         http.MethodGet,
+			URL:            "/api/realtime",
+			Timeout:        100 * time.Millisecond,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				"retry:3000\nid:",
+				`event:PB_CONNECT`,
+			},
+			NotExpectedContent: []string{
+				`:ping`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                        0,
+				"OnRealtimeConnectRequest": 1,
+				"OnRealtimeMessageSend":    1,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.OnRealtimeConnectRequest().BindFunc(func(e *core.RealtimeConnectRequestEvent) error {
+					if e.Transport != apis.RealtimeTransportSSE {
+						t.Errorf("Expected transport %q, got %q", apis.RealtimeTransportSSE, e.Transport)
+					}
+					e.RetryInterval = 3 * time.Second
+					return e.Next()
+				})
+			},
+		},
+		{
+			Name:           "heartbeat",
+			Method:         http.MethodGet,
+			URL:            "/api/realtime",
+			Timeout:        100 * time.Millisecond,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`event:PB_CONNECT`,
+				":ping\n\n",
+			},
+			NotExpectedContent: []string{
+				`retry:`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                        0,
+				"OnRealtimeConnectRequest": 1,
+				"OnRealtimeMessageSend":    1,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.OnRealtimeConnectRequest().BindFunc(func(e *core.RealtimeConnectRequestEvent) error {
+					e.HeartbeatInterval = 10 * time.Millisecond
+					return e.Next()
+				})
+			},
+		},
+		{
+			Name:           "max lifetime",
+			Method:         http.MethodGet,
+			URL:            "/api/realtime",
+			Timeout:        5 * time.Second,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`event:PB_CONNECT`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                        0,
+				"OnRealtimeConnectRequest": 1,
+				"OnRealtimeMessageSend":    1,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.OnRealtimeConnectRequest().BindFunc(func(e *core.RealtimeConnectRequestEvent) error {
+					start := time.Now()
+					e.MaxLifetime = 10 * time.Millisecond
+
+					err := e.Next()
+
+					if d := time.Since(start); d > time.Second {
+						t.Errorf("Expected the connection to be closed after the max lifetime, got %v", d)
+					}
+
+					return err
+				})
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if len(app.SubscriptionsBroker().Clients()) != 0 {
+					t.Errorf("Expected the subscribers to be removed after connection close, found %d", len(app.SubscriptionsBroker().Clients()))
+				}
+			},
+		},
 	}
 
 	for _, scenario := range scenarios {
@@ -325,6 +412,10 @@ func TestRealtimeSubscribe(t *testing.T) {
 				if authRecord == nil {
 					t.Errorf("Expected regular user auth record, got %v", authRecord)
 				}
+				authExpiry, _ := client.Get(apis.RealtimeClientAuthExpiryKey).(time.Time)
+				if !authExpiry.Equal(time.Unix(2524604461, 0)) {
+					t.Errorf("Expected the auth token expiry to be stored, got %v", authExpiry)
+				}
 				resetClient()
 			},
 		},
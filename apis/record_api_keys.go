@@ -0,0 +1,191 @@
+package apis
+
+import (
+	"net/http"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// bindRecordAPIKeysApi registers the auth record API keys management
+// endpoints and the corresponding handlers.
+//
+// The endpoints manage only the API keys of the current auth record
+// and cannot be accessed with another API key.
+func bindRecordAPIKeysApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/collections/{collection}/api-keys")
+
+	sub.GET("", recordAPIKeysList).Bind(
+		collectionPathRateLimit("", "listAPIKeys"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.POST("", recordAPIKeyCreate).Bind(
+		collectionPathRateLimit("", "createAPIKey"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.GET("/{id}", recordAPIKeyView).Bind(
+		collectionPathRateLimit("", "viewAPIKey"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.DELETE("/{id}", recordAPIKeyDelete).Bind(
+		collectionPathRateLimit("", "deleteAPIKey"),
+		RequireSameCollectionContextAuth(""),
+	)
+}
+
+func recordAPIKeysList(e *core.RequestEvent) error {
+	if err := checkAPIKeysManageAccess(e); err != nil {
+		return err
+	}
+
+	apiKeys, err := e.App.FindAllAPIKeysByRecord(e.Auth)
+	if err != nil {
+		return e.InternalServerError("Failed to fetch the API keys.", err)
+	}
+
+	return e.JSON(http.StatusOK, apiKeys)
+}
+
+func recordAPIKeyView(e *core.RequestEvent) error {
+	if err := checkAPIKeysManageAccess(e); err != nil {
+		return err
+	}
+
+	apiKey, err := findOwnAPIKey(e)
+	if err != nil {
+		return err
+	}
+
+	return e.JSON(http.StatusOK, apiKey)
+}
+
+func recordAPIKeyCreate(e *core.RequestEvent) error {
+	if err := checkAPIKeysManageAccess(e); err != nil {
+		return err
+	}
+
+	form := &createAPIKeyForm{}
+	if err := e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err := form.validate(e.App); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	plainKey := core.GenerateAPIKey()
+
+	apiKey := core.NewAPIKey(e.App)
+	apiKey.SetCollectionRef(e.Auth.Collection().Id)
+	apiKey.SetRecordRef(e.Auth.Id)
+	apiKey.SetName(form.Name)
+	apiKey.SetScopes(form.Scopes)
+	apiKey.SetExpires(form.Expires)
+	apiKey.SetKey(plainKey)
+
+	if err := e.App.Save(apiKey); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to create the API key.", err))
+	}
+
+	// the plain key is returned only once and it is not stored
+	e.Response.Header().Set("Cache-Control", "no-store")
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"key":    plainKey,
+		"apiKey": apiKey,
+	})
+}
+
+func recordAPIKeyDelete(e *core.RequestEvent) error {
+	if err := checkAPIKeysManageAccess(e); err != nil {
+		return err
+	}
+
+	apiKey, err := findOwnAPIKey(e)
+	if err != nil {
+		return err
+	}
+
+	if err := e.App.Delete(apiKey); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to revoke the API key.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// checkAPIKeysManageAccess prevents managing the API keys with another API key.
+func checkAPIKeysManageAccess(e *core.RequestEvent) error {
+	if e.Get(core.RequestEventKeyAPIKey) != nil {
+		return e.ForbiddenError("The API keys cannot be managed with an API key.", nil)
+	}
+
+	return nil
+}
+
+func findOwnAPIKey(e *core.RequestEvent) (*core.APIKey, error) {
+	apiKey, err := e.App.FindAPIKeyById(e.Request.PathValue("id"))
+	if err != nil || apiKey.RecordRef() != e.Auth.Id || apiKey.CollectionRef() != e.Auth.Collection().Id {
+		return nil, e.NotFoundError("", err)
+	}
+
+	return apiKey, nil
+}
+
+// -------------------------------------------------------------------
+
+type createAPIKeyForm struct {
+	Name    string         `form:"name" json:"name"`
+	Scopes  []string       `form:"scopes" json:"scopes"`
+	Expires types.DateTime `form:"expires" json:"expires"`
+}
+
+func (form *createAPIKeyForm) validate(app core.App) error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(
+			&form.Scopes,
+			validation.Required,
+			validation.Length(1, 100),
+			validation.Each(validation.By(checkAPIKeyScope(app))),
+		),
+		validation.Field(&form.Expires, validation.By(checkAPIKeyExpires)),
+	)
+}
+
+func checkAPIKeyScope(app core.App) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+
+		prefix, access, ok := strings.Cut(v, ":")
+		if !ok {
+			access = prefix
+		}
+
+		if access != core.APIKeyScopeRead && access != core.APIKeyScopeWrite {
+			return validation.NewError("validation_invalid_api_key_scope", "Invalid scope - must be read, write or in the format collection:read, collection:write.")
+		}
+
+		if ok {
+			if _, err := app.FindCachedCollectionByNameOrId(prefix); err != nil {
+				return validation.NewError("validation_invalid_api_key_scope_collection", "Missing or invalid scope collection.")
+			}
+		}
+
+		return nil
+	}
+}
+
+func checkAPIKeyExpires(value any) error {
+	v, _ := value.(types.DateTime)
+	if v.IsZero() {
+		return nil // never expires
+	}
+
+	if v.Time().Before(types.NowDateTime().Time()) {
+		return validation.NewError("validation_invalid_api_key_expires", "The expiration date must be in the future.")
+	}
+
+	return nil
+}
@@ -30,21 +30,68 @@ func bindRecordAuthApi(app core.App, rg *router.RouterGroup[*core.RequestEvent])
 
 	sub.POST("/auth-with-password", recordAuthWithPassword).Bind(
 		collectionPathRateLimit("", "authWithPassword", "auth"),
+		Idempotency(),
 	)
 
 	sub.POST("/auth-with-oauth2", recordAuthWithOAuth2).Bind(
 		collectionPathRateLimit("", "authWithOAuth2", "auth"),
+		Idempotency(),
+	)
+
+	sub.GET("/saml/metadata", recordSAMLMetadata).Bind(
+		collectionPathRateLimit("", "samlMetadata"),
+	)
+	sub.GET("/saml/login", recordSAMLLogin).Bind(
+		collectionPathRateLimit("", "samlLogin"),
+	)
+	sub.POST("/saml/acs", recordSAMLAssertionConsumer).Bind(
+		collectionPathRateLimit("", "samlACS"),
+		SkipSuccessActivityLog(), // skip success log as it could contain sensitive information
+	)
+	sub.POST("/auth-with-saml", recordAuthWithSAML).Bind(
+		collectionPathRateLimit("", "authWithSAML", "auth"),
+		Idempotency(),
 	)
 
 	sub.POST("/request-otp", recordRequestOTP).Bind(
 		collectionPathRateLimit("", "requestOTP"),
+		Idempotency(),
 	)
 	sub.POST("/auth-with-otp", recordAuthWithOTP).Bind(
 		collectionPathRateLimit("", "authWithOTP", "auth"),
+		Idempotency(),
+	)
+
+	sub.POST("/request-sms-otp", recordRequestSMSOTP).Bind(
+		collectionPathRateLimit("", "requestSMSOTP"),
+		Idempotency(),
+	)
+	sub.POST("/auth-with-sms-otp", recordAuthWithSMSOTP).Bind(
+		collectionPathRateLimit("", "authWithSMSOTP", "auth"),
+		Idempotency(),
+	)
+
+	sub.POST("/request-magic-link", recordRequestMagicLink).Bind(
+		collectionPathRateLimit("", "requestMagicLink"),
+		Idempotency(),
+	)
+	sub.POST("/auth-with-magic-link", recordAuthWithMagicLink).Bind(
+		collectionPathRateLimit("", "authWithMagicLink", "auth"),
+		Idempotency(),
+	)
+
+	sub.POST("/auth-as-guest", recordAuthAsGuest).Bind(
+		collectionPathRateLimit("", "authAsGuest", "auth"),
+		Idempotency(),
+	)
+	sub.POST("/upgrade-guest", recordUpgradeGuest).Bind(
+		collectionPathRateLimit("", "upgradeGuest"),
+		RequireSameCollectionContextAuth(""),
 	)
 
 	sub.POST("/request-password-reset", recordRequestPasswordReset).Bind(
 		collectionPathRateLimit("", "requestPasswordReset"),
+		Idempotency(),
 	)
 	sub.POST("/confirm-password-reset", recordConfirmPasswordReset).Bind(
 		collectionPathRateLimit("", "confirmPasswordReset"),
@@ -52,6 +99,7 @@ func bindRecordAuthApi(app core.App, rg *router.RouterGroup[*core.RequestEvent])
 
 	sub.POST("/request-verification", recordRequestVerification).Bind(
 		collectionPathRateLimit("", "requestVerification"),
+		Idempotency(),
 	)
 	sub.POST("/confirm-verification", recordConfirmVerification).Bind(
 		collectionPathRateLimit("", "confirmVerification"),
@@ -60,6 +108,7 @@ func bindRecordAuthApi(app core.App, rg *router.RouterGroup[*core.RequestEvent])
 	sub.POST("/request-email-change", recordRequestEmailChange).Bind(
 		collectionPathRateLimit("", "requestEmailChange"),
 		RequireSameCollectionContextAuth(""),
+		Idempotency(),
 	)
 	sub.POST("/confirm-email-change", recordConfirmEmailChange).Bind(
 		collectionPathRateLimit("", "confirmEmailChange"),
@@ -0,0 +1,36 @@
+package apis
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func recordAuthAsGuest(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.GuestAuth.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow guest authentication.", nil)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set(collection.GuestAuth.AnonymousField, true)
+	// the password is never shared and it is replaced on upgrade
+	record.SetPassword(security.RandomString(30))
+
+	event := new(core.RecordAuthAsGuestRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Record = record
+
+	return e.App.OnRecordAuthAsGuestRequest().Trigger(event, func(e *core.RecordAuthAsGuestRequestEvent) error {
+		if err := e.App.Save(e.Record); err != nil {
+			return firstApiError(err, e.BadRequestError("Failed to create the guest auth record.", err))
+		}
+
+		// note: guest auth is not a MFA method
+		return RecordAuthResponse(e.RequestEvent, e.Record, "", nil)
+	})
+}
@@ -14,6 +14,11 @@ func recordAuthImpersonate(e *core.RequestEvent) error {
 		return e.ForbiddenError("", nil)
 	}
 
+	// prevent exchanging a scoped API key for an unrestricted token
+	if e.Get(core.RequestEventKeyAPIKey) != nil {
+		return e.ForbiddenError("The impersonate token cannot be generated with an API key.", nil)
+	}
+
 	collection, err := findAuthCollection(e)
 	if err != nil {
 		return err
@@ -0,0 +1,115 @@
+package apis
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/mails"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func recordRequestMagicLink(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.MagicLink.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow magic link authentication.", nil)
+	}
+
+	form := &requestMagicLinkForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	record, err := e.App.FindAuthRecordByEmail(collection, form.Email)
+
+	// ignore not found errors to allow custom record find implementations
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return e.InternalServerError("", err)
+	}
+
+	event := new(core.RecordRequestMagicLinkRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Record = record
+
+	originalApp := e.App
+
+	return e.App.OnRecordRequestMagicLinkRequest().Trigger(event, func(e *core.RecordRequestMagicLinkRequestEvent) error {
+		if e.Record == nil {
+			// write a dummy 204 response as a very rudimentary user emails enumeration protection
+			e.NoContent(http.StatusNoContent)
+
+			return fmt.Errorf("failed to fetch %s record with email %s: %w", collection.Name, form.Email, err)
+		}
+
+		// limit the new magic link creations for a single user
+		if !e.App.IsDev() {
+			otps, err := e.App.FindAllOTPsByRecord(e.Record)
+			if err != nil {
+				return firstApiError(err, e.InternalServerError("Failed to fetch previous record OTPs.", err))
+			}
+
+			totalRecent := 0
+			for _, existingOTP := range otps {
+				if !existingOTP.HasExpired(collection.MagicLinkToken.DurationTime()) {
+					totalRecent++
+				}
+				if totalRecent > 9 {
+					e.App.Logger().Warn(
+						"Too many magic link requests - skipping the new link",
+						"email", form.Email,
+						"recordId", e.Record.Id,
+					)
+					return e.NoContent(http.StatusNoContent)
+				}
+			}
+		}
+
+		otp := core.NewOTP(e.App)
+		otp.SetCollectionRef(e.Record.Collection().Id)
+		otp.SetRecordRef(e.Record.Id)
+		// the magic link OTP password is never entered by the user and the OTP
+		// is used only to ensure that the emailed link could be redeemed only once
+		otp.SetPassword(security.RandomString(30))
+		err = e.App.Save(otp)
+		if err != nil {
+			return err
+		}
+
+		// send the magic link email
+		// (in the background as a very basic timing attacks and emails enumeration protection)
+		// ---
+		routine.FireAndForget(func() {
+			err = mails.SendRecordMagicLink(originalApp, e.Record, otp.Id)
+			if err != nil {
+				originalApp.Logger().Error("Failed to send magic link email", "error", errors.Join(err, originalApp.Delete(otp)))
+			}
+		})
+
+		return e.NoContent(http.StatusNoContent)
+	})
+}
+
+// -------------------------------------------------------------------
+
+type requestMagicLinkForm struct {
+	Email string `form:"email" json:"email"`
+}
+
+func (form requestMagicLinkForm) validate() error {
+	return validation.ValidateStruct(&form,
+		validation.Field(&form.Email, validation.Required, validation.Length(1, 255), is.EmailFormat),
+	)
+}
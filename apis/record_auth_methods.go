@@ -21,6 +21,10 @@ type mfaResponse struct {
 	Duration int64 `json:"duration"` // in seconds
 }
 
+type guestResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
 type passwordResponse struct {
 	IdentityFields []string `json:"identityFields"`
 	Enabled        bool     `json:"enabled"`
@@ -31,6 +35,10 @@ type oauth2Response struct {
 	Enabled   bool           `json:"enabled"`
 }
 
+type samlResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
 type providerInfo struct {
 	Name        string `json:"name"`
 	DisplayName string `json:"displayName"`
@@ -50,10 +58,14 @@ type providerInfo struct {
 }
 
 type authMethodsResponse struct {
-	Password passwordResponse `json:"password"`
-	OAuth2   oauth2Response   `json:"oauth2"`
-	MFA      mfaResponse      `json:"mfa"`
-	OTP      otpResponse      `json:"otp"`
+	Password  passwordResponse `json:"password"`
+	OAuth2    oauth2Response   `json:"oauth2"`
+	SAML      samlResponse     `json:"saml"`
+	MFA       mfaResponse      `json:"mfa"`
+	OTP       otpResponse      `json:"otp"`
+	SMSOTP    otpResponse      `json:"smsOTP"`
+	MagicLink otpResponse      `json:"magicLink"`
+	Guest     guestResponse    `json:"guest"`
 
 	// legacy fields
 	// @todo remove after dropping v0.22 support
@@ -85,12 +97,24 @@ func recordAuthMethods(e *core.RequestEvent) error {
 		OAuth2: oauth2Response{
 			Providers: make([]providerInfo, 0, len(collection.OAuth2.Providers)),
 		},
+		SAML: samlResponse{
+			Enabled: collection.SAML.Enabled,
+		},
 		OTP: otpResponse{
 			Enabled: collection.OTP.Enabled,
 		},
+		SMSOTP: otpResponse{
+			Enabled: collection.SMSOTP.Enabled,
+		},
+		MagicLink: otpResponse{
+			Enabled: collection.MagicLink.Enabled,
+		},
 		MFA: mfaResponse{
 			Enabled: collection.MFA.Enabled,
 		},
+		Guest: guestResponse{
+			Enabled: collection.GuestAuth.Enabled,
+		},
 	}
 
 	if collection.PasswordAuth.Enabled {
@@ -102,6 +126,14 @@ func recordAuthMethods(e *core.RequestEvent) error {
 		result.OTP.Duration = collection.OTP.Duration
 	}
 
+	if collection.SMSOTP.Enabled {
+		result.SMSOTP.Duration = collection.SMSOTP.Duration
+	}
+
+	if collection.MagicLink.Enabled {
+		result.MagicLink.Duration = collection.MagicLinkToken.Duration
+	}
+
 	if collection.MFA.Enabled {
 		result.MFA.Duration = collection.MFA.Duration
 	}
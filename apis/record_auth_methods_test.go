@@ -36,8 +36,11 @@ func TestRecordAuthMethodsList(t *testing.T) {
 			ExpectedContent: []string{
 				`"password":{"identityFields":[],"enabled":false}`,
 				`"oauth2":{"providers":[],"enabled":false}`,
+				`"saml":{"enabled":false}`,
 				`"mfa":{"enabled":false,"duration":0}`,
 				`"otp":{"enabled":false,"duration":0}`,
+				`"magicLink":{"enabled":false,"duration":0}`,
+				`"guest":{"enabled":false}`,
 			},
 			ExpectedEvents: map[string]int{"*": 0},
 		},
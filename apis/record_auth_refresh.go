@@ -1,6 +1,8 @@
 package apis
 
 import (
+	"errors"
+
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/spf13/cast"
@@ -12,6 +14,10 @@ func recordAuthRefresh(e *core.RequestEvent) error {
 		return e.NotFoundError("Missing auth record context.", nil)
 	}
 
+	if e.Get(core.RequestEventKeyAPIKey) != nil {
+		return e.ForbiddenError("The auth token cannot be refreshed with an API key.", nil)
+	}
+
 	currentToken := getAuthTokenFromRequest(e)
 	claims, _ := security.ParseUnverifiedJWT(currentToken)
 	if v, ok := claims[core.TokenClaimRefreshable]; !ok || !cast.ToBool(v) {
@@ -24,6 +30,21 @@ func recordAuthRefresh(e *core.RequestEvent) error {
 	event.Record = record
 
 	return e.App.OnRecordAuthRefreshRequest().Trigger(event, func(e *core.RecordAuthRefreshRequestEvent) error {
-		return RecordAuthResponse(e.RequestEvent, e.Record, "", nil)
+		e.Record.SetTokenSigner(e.App.TokenSigner())
+
+		// preserve the auth session start (and its server-side session if tracked)
+		// so that the refresh can't extend the session beyond its max lifetime
+		token, err := newSessionAuthToken(e.RequestEvent, e.Record, claims)
+		if err != nil {
+			if errors.Is(err, core.ErrAuthSessionExpired) {
+				return e.UnauthorizedError("The auth session has expired.", err)
+			}
+			if errors.Is(err, core.ErrAuthSessionRevoked) {
+				return e.UnauthorizedError("The auth session has been revoked.", err)
+			}
+			return e.InternalServerError("Failed to create auth token.", err)
+		}
+
+		return recordAuthResponse(e.RequestEvent, e.Record, token, "", nil)
 	})
 }
@@ -0,0 +1,121 @@
+package apis
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func recordRequestSMSOTP(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.SMSOTP.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow SMS OTP authentication.", nil)
+	}
+
+	form := &createSMSOTPForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	record, err := e.App.FindFirstRecordByData(collection, collection.SMSOTP.PhoneField, form.Phone)
+
+	// ignore not found errors to allow custom record find implementations
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return e.InternalServerError("", err)
+	}
+
+	event := new(core.RecordRequestSMSOTPRequestEvent)
+	event.RequestEvent = e
+	event.Password = security.RandomStringWithAlphabet(collection.SMSOTP.Length, "1234567890")
+	event.Collection = collection
+	event.Record = record
+
+	originalApp := e.App
+
+	return e.App.OnRecordRequestSMSOTPRequest().Trigger(event, func(e *core.RecordRequestSMSOTPRequestEvent) error {
+		if e.Record == nil {
+			// write a dummy 200 response as a very rudimentary user phones enumeration protection
+			e.JSON(http.StatusOK, map[string]string{
+				"otpId": core.GenerateDefaultRandomId(),
+			})
+
+			return fmt.Errorf("failed to fetch %s record with phone %s: %w", collection.Name, form.Phone, err)
+		}
+
+		phone := e.Record.GetString(collection.SMSOTP.PhoneField)
+
+		// limit the new SMS OTP creations for a single user
+		otps, err := e.App.FindAllOTPsByRecord(e.Record)
+		if err != nil {
+			return firstApiError(err, e.InternalServerError("Failed to fetch previous record OTPs.", err))
+		}
+
+		totalRecent := 0
+		for _, existingOTP := range otps {
+			if existingOTP.SentTo() == phone && !existingOTP.HasExpired(collection.SMSOTP.DurationTime()) {
+				totalRecent++
+			}
+		}
+		if totalRecent >= collection.SMSOTP.MaxRequests {
+			return e.TooManyRequestsError("Too many SMS OTP requests, please try again later.", nil)
+		}
+
+		otp := core.NewOTP(e.App)
+		otp.SetCollectionRef(e.Record.Collection().Id)
+		otp.SetRecordRef(e.Record.Id)
+		otp.SetSentTo(phone)
+		otp.SetPassword(e.Password)
+		err = e.App.Save(otp)
+		if err != nil {
+			return err
+		}
+
+		message := &core.SMSMessage{
+			To: phone,
+			Body: collection.SMSOTP.ResolveMessage(map[string]any{
+				core.EmailPlaceholderAppName: originalApp.Settings().Meta.AppName,
+				core.EmailPlaceholderOTP:     e.Password,
+			}),
+		}
+
+		// send the OTP SMS
+		// (in the background as a very basic timing attacks and phones enumeration protection)
+		routine.FireAndForget(func() {
+			err := originalApp.SendSMS(context.Background(), message)
+			if err != nil {
+				originalApp.Logger().Error("Failed to send OTP SMS", "error", errors.Join(err, originalApp.Delete(otp)))
+			}
+		})
+
+		return e.JSON(http.StatusOK, map[string]string{
+			"otpId": otp.Id,
+		})
+	})
+}
+
+// -------------------------------------------------------------------
+
+type createSMSOTPForm struct {
+	Phone string `form:"phone" json:"phone"`
+}
+
+func (form createSMSOTPForm) validate() error {
+	return validation.ValidateStruct(&form,
+		validation.Field(&form.Phone, validation.Required, validation.Length(1, 50), is.E164),
+	)
+}
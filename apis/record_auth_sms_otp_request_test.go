@@ -0,0 +1,296 @@
+package apis_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const testSMSOTPPhone = "+359888123456"
+
+// enableUsersSMSOTP enables the users collection SMS OTP auth
+// and sets the test@example.com user phone to testSMSOTPPhone.
+func enableUsersSMSOTP(t testing.TB, app core.App) *core.Record {
+	usersCol, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	usersCol.Fields.Add(&core.TextField{Name: "phone"})
+	usersCol.AddIndex("idx_users_phone", true, "phone", "phone != ''")
+	usersCol.SMSOTP.Enabled = true
+	usersCol.SMSOTP.PhoneField = "phone"
+	usersCol.SMSOTP.Duration = 180
+	usersCol.SMSOTP.Length = 6
+	usersCol.SMSOTP.MaxRequests = 3
+	usersCol.SMSOTP.MessageTemplate = "{APP_NAME} code: {OTP}"
+	if err := app.Save(usersCol); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail(usersCol, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user.Set("phone", testSMSOTPPhone)
+	if err := app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
+
+type testSMSSender struct {
+	mu       sync.Mutex
+	messages []*core.SMSMessage
+}
+
+func (s *testSMSSender) Send(ctx context.Context, message *core.SMSMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, message)
+
+	return nil
+}
+
+func (s *testSMSSender) Messages() []*core.SMSMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.messages
+}
+
+func TestRecordRequestSMSOTP(t *testing.T) {
+	t.Parallel()
+
+	sender := &testSMSSender{}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "not an auth collection",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/demo1/request-sms-otp",
+			Body:            strings.NewReader(`{"phone":"` + testSMSOTPPhone + `"}`),
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "auth collection with disabled sms otp",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/request-sms-otp",
+			Body:            strings.NewReader(`{"phone":"` + testSMSOTPPhone + `"}`),
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "empty body",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/request-sms-otp",
+			Body:   strings.NewReader(``),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableUsersSMSOTP(t, app)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{"phone":{"code":"validation_required","message":"Cannot be blank."}}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "invalid phone",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/request-sms-otp",
+			Body:   strings.NewReader(`{"phone":"abc"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableUsersSMSOTP(t, app)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"phone":{"code":"validation_is_e164_number"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "missing auth record",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/request-sms-otp",
+			Body:   strings.NewReader(`{"phone":"+359888000000"}`),
+			Delay:  100 * time.Millisecond,
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableUsersSMSOTP(t, app)
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"otpId":"`, // some fake random generated string
+			},
+			ExpectedEvents: map[string]int{
+				"*":                            0,
+				"OnRecordRequestSMSOTPRequest": 1,
+			},
+		},
+		{
+			Name:   "existing auth record",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/request-sms-otp",
+			Body:   strings.NewReader(`{"phone":"` + testSMSOTPPhone + `"}`),
+			Delay:  100 * time.Millisecond,
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableUsersSMSOTP(t, app)
+
+				app.OnSMSSend().BindFunc(func(e *core.SMSSendEvent) error {
+					e.Sender = sender
+					return e.Next()
+				})
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"otpId":"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                            0,
+				"OnRecordRequestSMSOTPRequest": 1,
+				"OnSMSSend":                    1,
+				"OnModelValidate":              1,
+				"OnModelCreate":                1,
+				"OnModelCreateExecute":         1,
+				"OnModelAfterCreateSuccess":    1,
+				"OnRecordValidate":             1,
+				"OnRecordCreate":               1,
+				"OnRecordCreateExecute":        1,
+				"OnRecordAfterCreateSuccess":   1,
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				messages := sender.Messages()
+				if len(messages) != 1 {
+					t.Fatalf("Expected 1 SMS, got %d", len(messages))
+				}
+
+				if messages[0].To != testSMSOTPPhone {
+					t.Fatalf("Expected the SMS to be sent to %q, got %q", testSMSOTPPhone, messages[0].To)
+				}
+
+				if !strings.HasPrefix(messages[0].Body, app.Settings().Meta.AppName+" code: ") {
+					t.Fatalf("Unexpected SMS body %q", messages[0].Body)
+				}
+
+				otps, err := app.FindRecordsByFilter(core.CollectionNameOTPs, "sentTo={:phone}", "", 0, 0, map[string]any{"phone": testSMSOTPPhone})
+				if err != nil || len(otps) != 1 {
+					t.Fatalf("Expected to find 1 OTP with sentTo %q, found %d (%v)", testSMSOTPPhone, len(otps), err)
+				}
+			},
+		},
+		{
+			Name:   "existing auth record with failed SMS send",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/request-sms-otp",
+			Body:   strings.NewReader(`{"phone":"` + testSMSOTPPhone + `"}`),
+			Delay:  100 * time.Millisecond,
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableUsersSMSOTP(t, app)
+				// no SMS sender
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"otpId":"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                            0,
+				"OnRecordRequestSMSOTPRequest": 1,
+				"OnSMSSend":                    1,
+				"OnModelValidate":              1,
+				"OnModelCreate":                1,
+				"OnModelCreateExecute":         1,
+				"OnModelAfterCreateSuccess":    1,
+				"OnRecordValidate":             1,
+				"OnRecordCreate":               1,
+				"OnRecordCreateExecute":        1,
+				"OnRecordAfterCreateSuccess":   1,
+				// OTP delete
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				total, err := app.CountRecords(core.CollectionNameOTPs)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if total != 0 {
+					t.Fatalf("Expected the OTP to be deleted, found %d", total)
+				}
+			},
+		},
+		{
+			Name:   "existing auth record with max non-expired requests",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/request-sms-otp",
+			Body:   strings.NewReader(`{"phone":"` + testSMSOTPPhone + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				user := enableUsersSMSOTP(t, app)
+
+				// insert 3 non-expired, 2 expired and 2 email OTPs
+				for i := 0; i < 7; i++ {
+					otp := core.NewOTP(app)
+					otp.Id = "otp_" + strconv.Itoa(i)
+					otp.SetCollectionRef(user.Collection().Id)
+					otp.SetRecordRef(user.Id)
+					otp.SetPassword("123456")
+					if i < 5 {
+						otp.SetSentTo(testSMSOTPPhone)
+					} else {
+						otp.SetSentTo(user.Email())
+					}
+					if i >= 3 && i < 5 {
+						expiredDate := types.NowDateTime().AddDate(-3, 0, 0)
+						otp.SetRaw("created", expiredDate)
+						otp.SetRaw("updated", expiredDate)
+					}
+					if err := app.SaveNoValidate(otp); err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"*":                            0,
+				"OnRecordRequestSMSOTPRequest": 1,
+			},
+		},
+
+		// rate limit checks
+		// -----------------------------------------------------------
+		{
+			Name:   "RateLimit rule - users:requestSMSOTP",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/request-sms-otp",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 100, Label: "*:requestSMSOTP"},
+					{MaxRequests: 0, Label: "users:requestSMSOTP"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,90 @@
+package apis
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/core/validators"
+)
+
+func recordUpgradeGuest(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.PasswordAuth.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow password authentication.", nil)
+	}
+
+	if e.Auth == nil || e.Auth.Collection().Id != collection.Id {
+		return e.NotFoundError("Missing auth record context.", nil)
+	}
+
+	if e.Get(core.RequestEventKeyAPIKey) != nil {
+		return e.ForbiddenError("The guest account cannot be upgraded with an API key.", nil)
+	}
+
+	if !e.Auth.IsGuest() {
+		return e.BadRequestError("The auth record is not a guest.", nil)
+	}
+
+	form := new(recordUpgradeGuestForm)
+	form.collection = collection
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	// load a fresh copy so that the auth state is not mutated on failure
+	record, err := e.App.FindRecordById(collection, e.Auth.Id)
+	if err != nil {
+		return e.NotFoundError("Missing auth record context.", err)
+	}
+
+	event := new(core.RecordGuestUpgradeRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Record = record
+	event.Email = form.Email
+	event.Password = form.Password
+
+	return e.App.OnRecordGuestUpgradeRequest().Trigger(event, func(e *core.RecordGuestUpgradeRequestEvent) error {
+		e.Record.SetEmail(e.Email)
+		e.Record.SetPassword(e.Password) // also invalidates the previous guest tokens
+		e.Record.SetVerified(false)
+		e.Record.Set(e.Collection.GuestAuth.AnonymousField, false)
+
+		if err := e.App.Save(e.Record); err != nil {
+			return firstApiError(err, e.BadRequestError("Failed to upgrade the guest auth record.", err))
+		}
+
+		return RecordAuthResponse(e.RequestEvent, e.Record, "", nil)
+	})
+}
+
+// -------------------------------------------------------------------
+
+type recordUpgradeGuestForm struct {
+	collection *core.Collection
+
+	Email           string `form:"email" json:"email"`
+	Password        string `form:"password" json:"password"`
+	PasswordConfirm string `form:"passwordConfirm" json:"passwordConfirm"`
+}
+
+func (form *recordUpgradeGuestForm) validate() error {
+	min := 1
+	passField, ok := form.collection.Fields.GetByName(core.FieldNamePassword).(*core.PasswordField)
+	if ok && passField != nil && passField.Min > 0 {
+		min = passField.Min
+	}
+
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Email, validation.Required, validation.Length(1, 255), is.EmailFormat),
+		validation.Field(&form.Password, validation.Required, validation.Length(min, 255)),
+		validation.Field(&form.PasswordConfirm, validation.Required, validation.By(validators.Equal(form.Password))),
+	)
+}
@@ -0,0 +1,110 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func recordAuthWithMagicLink(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.MagicLink.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow magic link authentication.", nil)
+	}
+
+	form := &authWithMagicLinkForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	event := new(core.RecordAuthWithMagicLinkRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+
+	// extra validations
+	// (note: returns a generic 400 as a very basic tokens enumeration protection)
+	// ---
+	event.Record, err = e.App.FindAuthRecordByToken(form.Token, core.TokenTypeMagicLink)
+	if err != nil {
+		return e.BadRequestError("Invalid or expired magic link", err)
+	}
+
+	if event.Record.Collection().Id != collection.Id {
+		return e.BadRequestError("Invalid or expired magic link", errors.New("the magic link is for a different collection"))
+	}
+
+	// already verified by FindAuthRecordByToken
+	claims, _ := security.ParseUnverifiedJWT(form.Token)
+
+	email, _ := claims[core.TokenClaimEmail].(string)
+	if email == "" || email != event.Record.Email() {
+		return e.BadRequestError("Invalid or expired magic link", errors.New("the magic link email doesn't match with the record one"))
+	}
+
+	otpId, _ := claims[core.TokenClaimOTPId].(string)
+	event.OTP, err = e.App.FindOTPById(otpId)
+	if err != nil {
+		return e.BadRequestError("Invalid or expired magic link", fmt.Errorf("missing or already used magic link OTP: %w", err))
+	}
+
+	if event.OTP.CollectionRef() != collection.Id || event.OTP.RecordRef() != event.Record.Id {
+		return e.BadRequestError("Invalid or expired magic link", errors.New("the magic link OTP is for a different record"))
+	}
+
+	if event.OTP.HasExpired(collection.MagicLinkToken.DurationTime()) {
+		return e.BadRequestError("Invalid or expired magic link", errors.New("the magic link OTP is expired"))
+	}
+	// ---
+
+	return e.App.OnRecordAuthWithMagicLinkRequest().Trigger(event, func(e *core.RecordAuthWithMagicLinkRequestEvent) error {
+		// the magic link was delivered to the record email address so we can mark it as verified
+		//
+		// note: don't wait for success auth response (it could fail because of MFA) and because we already validated the token above
+		if !e.Record.Verified() && e.Record.Email() == email {
+			e.Record.SetVerified(true)
+			err = e.App.Save(e.Record)
+			if err != nil {
+				e.App.Logger().Error("Failed to update record verified state after successful magic link validation",
+					"error", err,
+					"otpId", e.OTP.Id,
+					"recordId", e.Record.Id,
+				)
+			}
+		}
+
+		// try to delete the used otp so that the link can't be reused
+		err = e.App.Delete(e.OTP)
+		if err != nil {
+			e.App.Logger().Error("Failed to delete used magic link OTP", "error", err, "otpId", e.OTP.Id)
+		}
+
+		err = RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodMagicLink, nil)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// -------------------------------------------------------------------
+
+type authWithMagicLinkForm struct {
+	Token string `form:"token" json:"token"`
+}
+
+func (form *authWithMagicLinkForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Token, validation.Required),
+	)
+}
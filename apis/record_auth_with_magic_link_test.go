@@ -0,0 +1,360 @@
+package apis_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestRecordAuthWithMagicLink(t *testing.T) {
+	t.Parallel()
+
+	otpId := strings.Repeat("a", 15)
+
+	// generate the magic link tokens with the test data signing keys
+	app, _ := tests.NewTestApp()
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Collection().MagicLinkToken.Duration = 300
+	token, err := user.NewMagicLinkToken(otpId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authToken, err := user.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Cleanup()
+
+	stubOTP := func(t testing.TB, app core.App, recordEmail string) *core.OTP {
+		record, err := app.FindAuthRecordByEmail("users", recordEmail)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		otp := core.NewOTP(app)
+		otp.Id = otpId
+		otp.SetCollectionRef(record.Collection().Id)
+		otp.SetRecordRef(record.Id)
+		otp.SetPassword("123456")
+		if err := app.Save(otp); err != nil {
+			t.Fatal(err)
+		}
+
+		return otp
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "not an auth collection",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/demo1/auth-with-magic-link",
+			Body:            strings.NewReader(`{"token":"` + token + `"}`),
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "auth collection with disabled magic link",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/auth-with-magic-link",
+			Body:            strings.NewReader(`{"token":"` + token + `"}`),
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "empty body",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-magic-link",
+			Body:   strings.NewReader(``),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableMagicLink(t, app)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{"token":{"code":"validation_required","message":"Cannot be blank."}}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "invalid token",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-magic-link",
+			Body:   strings.NewReader(`{"token":"invalid"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableMagicLink(t, app)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "auth token instead of magic link token",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-magic-link",
+			Body:   strings.NewReader(`{"token":"` + authToken + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableMagicLink(t, app)
+				stubOTP(t, app, "test@example.com")
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "missing or already used OTP",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-magic-link",
+			Body:   strings.NewReader(`{"token":"` + token + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableMagicLink(t, app)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "OTP for a different record",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-magic-link",
+			Body:   strings.NewReader(`{"token":"` + token + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableMagicLink(t, app)
+				stubOTP(t, app, "test2@example.com")
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "expired OTP",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-magic-link",
+			Body:   strings.NewReader(`{"token":"` + token + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableMagicLink(t, app)
+
+				otp := stubOTP(t, app, "test@example.com")
+
+				expiredDate := types.NowDateTime().Add(-10 * 60 * 1e9)
+				otp.SetRaw("created", expiredDate)
+				otp.SetRaw("updated", expiredDate)
+				if err := app.SaveNoValidate(otp); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "changed record email",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-magic-link",
+			Body:   strings.NewReader(`{"token":"` + token + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableMagicLink(t, app)
+				stubOTP(t, app, "test@example.com")
+
+				user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// directly update the email to preserve the current token key
+				_, err = app.DB().Update("users", dbx.Params{"email": "changed@example.com"}, dbx.HashExp{"id": user.Id}).Execute()
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "valid magic link (enabled MFA)",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-magic-link",
+			Body:   strings.NewReader(`{"token":"` + token + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableMagicLink(t, app)
+				stubOTP(t, app, "test@example.com")
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"mfaId":"`},
+			ExpectedEvents: map[string]int{
+				"*":                                0,
+				"OnRecordAuthWithMagicLinkRequest": 1,
+				"OnRecordAuthRequest":              1,
+				// ---
+				"OnModelValidate":           2, // +1 because of the verified user update
+				"OnModelCreate":             1, // mfa record
+				"OnModelCreateExecute":      1,
+				"OnModelAfterCreateSuccess": 1,
+				"OnModelDelete":             1, // otp delete
+				"OnModelDeleteExecute":      1,
+				"OnModelAfterDeleteSuccess": 1,
+				"OnModelUpdate":             1, // user verified update
+				"OnModelUpdateExecute":      1,
+				"OnModelAfterUpdateSuccess": 1,
+				// ---
+				"OnRecordValidate":           2,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+				"OnRecordUpdate":             1,
+				"OnRecordUpdateExecute":      1,
+				"OnRecordAfterUpdateSuccess": 1,
+			},
+		},
+		{
+			Name:   "valid magic link (disabled MFA)",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-magic-link",
+			Body:   strings.NewReader(`{"token":"` + token + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				usersCol := enableMagicLink(t, app)
+
+				usersCol.MFA.Enabled = false
+				if err := app.Save(usersCol); err != nil {
+					t.Fatal(err)
+				}
+
+				user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// ensure that the user is unverified
+				user.SetVerified(false)
+				if err = app.Save(user); err != nil {
+					t.Fatal(err)
+				}
+
+				stubOTP(t, app, "test@example.com")
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"record":{`,
+				`"email":"test@example.com"`,
+			},
+			NotExpectedContent: []string{
+				`"meta":`,
+				// hidden fields
+				`"tokenKey"`,
+				`"password"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                                0,
+				"OnRecordAuthWithMagicLinkRequest": 1,
+				"OnRecordAuthRequest":              1,
+				"OnRecordEnrich":                   1,
+				// ---
+				"OnModelValidate": 2, // +1 because of the verified user update
+				// authOrigin create
+				"OnModelCreate":             1,
+				"OnModelCreateExecute":      1,
+				"OnModelAfterCreateSuccess": 1,
+				// OTP delete
+				"OnModelDelete":             1,
+				"OnModelDeleteExecute":      1,
+				"OnModelAfterDeleteSuccess": 1,
+				// user verified update
+				"OnModelUpdate":             1,
+				"OnModelUpdateExecute":      1,
+				"OnModelAfterUpdateSuccess": 1,
+				// ---
+				"OnRecordValidate":           2,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+				"OnRecordUpdate":             1,
+				"OnRecordUpdateExecute":      1,
+				"OnRecordAfterUpdateSuccess": 1,
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !user.Verified() {
+					t.Fatal("Expected the user to be marked as verified")
+				}
+
+				if _, err := app.FindOTPById(otpId); err == nil {
+					t.Fatal("Expected the magic link OTP to be deleted")
+				}
+			},
+		},
+
+		// rate limit checks
+		// -----------------------------------------------------------
+		{
+			Name:   "RateLimit rule - users:authWithMagicLink",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-magic-link",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 100, Label: "*:authWithMagicLink"},
+					{MaxRequests: 100, Label: "users:auth"},
+					{MaxRequests: 0, Label: "users:authWithMagicLink"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "RateLimit rule - *:authWithMagicLink",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-magic-link",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 100, Label: "*:auth"},
+					{MaxRequests: 0, Label: "*:authWithMagicLink"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "RateLimit rule - users:auth",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-magic-link",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 100, Label: "*:authWithMagicLink"},
+					{MaxRequests: 0, Label: "users:auth"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -53,39 +53,12 @@ func recordAuthWithOAuth2(e *core.RequestEvent) error {
 	// exchange token for OAuth2 user info and locate existing ExternalAuth rel
 	// ---------------------------------------------------------------
 
-	// load provider configuration
-	providerConfig, ok := collection.OAuth2.GetProviderConfig(form.Provider)
-	if !ok {
-		return e.InternalServerError("Missing or invalid provider config.", nil)
-	}
-
-	provider, err := providerConfig.InitProvider()
-	if err != nil {
-		return firstApiError(err, e.InternalServerError("Failed to init provider "+form.Provider, err))
-	}
-
 	ctx, cancel := context.WithTimeout(e.Request.Context(), 30*time.Second)
 	defer cancel()
 
-	provider.SetContext(ctx)
-	provider.SetRedirectURL(form.RedirectURL)
-
-	var opts []oauth2.AuthCodeOption
-
-	if provider.PKCE() {
-		opts = append(opts, oauth2.SetAuthURLParam("code_verifier", form.CodeVerifier))
-	}
-
-	// fetch token
-	token, err := provider.FetchToken(form.Code, opts...)
+	provider, authUser, err := oauth2FetchAuthUser(ctx, e, collection, form.Provider, form.Code, form.CodeVerifier, form.RedirectURL)
 	if err != nil {
-		return firstApiError(err, e.BadRequestError("Failed to fetch OAuth2 token.", err))
-	}
-
-	// fetch external auth user
-	authUser, err := provider.FetchAuthUser(token)
-	if err != nil {
-		return firstApiError(err, e.BadRequestError("Failed to fetch OAuth2 user.", err))
+		return err
 	}
 
 	var authRecord *core.Record
@@ -146,6 +119,54 @@ func recordAuthWithOAuth2(e *core.RequestEvent) error {
 	})
 }
 
+// oauth2FetchAuthUser exchanges the OAuth2 authorization code
+// and fetches the external auth user of the specified collection provider.
+//
+// The provided ctx is assigned to the initialized provider client.
+func oauth2FetchAuthUser(
+	ctx context.Context,
+	e *core.RequestEvent,
+	collection *core.Collection,
+	providerName string,
+	code string,
+	codeVerifier string,
+	redirectURL string,
+) (auth.Provider, *auth.AuthUser, error) {
+	// load provider configuration
+	providerConfig, ok := collection.OAuth2.GetProviderConfig(providerName)
+	if !ok {
+		return nil, nil, e.InternalServerError("Missing or invalid provider config.", nil)
+	}
+
+	provider, err := providerConfig.InitProvider()
+	if err != nil {
+		return nil, nil, firstApiError(err, e.InternalServerError("Failed to init provider "+providerName, err))
+	}
+
+	provider.SetContext(ctx)
+	provider.SetRedirectURL(redirectURL)
+
+	var opts []oauth2.AuthCodeOption
+
+	if provider.PKCE() {
+		opts = append(opts, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
+	}
+
+	// fetch token
+	token, err := provider.FetchToken(code, opts...)
+	if err != nil {
+		return nil, nil, firstApiError(err, e.BadRequestError("Failed to fetch OAuth2 token.", err))
+	}
+
+	// fetch external auth user
+	authUser, err := provider.FetchAuthUser(token)
+	if err != nil {
+		return nil, nil, firstApiError(err, e.BadRequestError("Failed to fetch OAuth2 user.", err))
+	}
+
+	return provider, authUser, nil
+}
+
 // -------------------------------------------------------------------
 
 type recordOAuth2LoginForm struct {
@@ -311,6 +332,12 @@ func oauth2Submit(e *core.RecordAuthWithOAuth2RequestEvent, optExternalAuth *cor
 				needUpdate = true
 			}
 
+			// upgrade the logged guest record to a regular one
+			if isLoggedAuthRecord && e.Record.IsGuest() {
+				e.Record.Set(e.Collection.GuestAuth.AnonymousField, false)
+				needUpdate = true
+			}
+
 			if needUpdate {
 				if err := txApp.Save(e.Record); err != nil {
 					return err
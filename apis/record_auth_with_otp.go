@@ -51,15 +51,32 @@ func recordAuthWithOTP(e *core.RequestEvent) error {
 		return e.BadRequestError("Invalid or expired OTP", fmt.Errorf("missing auth record: %w", err))
 	}
 
+	// ensure that the OTP wasn't sent through another channel (e.g. SMS)
+	// (OTPs without sentTo are allowed for backward compatibility and custom OTP delivery)
+	if sentTo := event.OTP.SentTo(); sentTo != "" && sentTo != event.Record.Email() {
+		return e.BadRequestError("Invalid or expired OTP", errors.New("the OTP was not sent to the record email"))
+	}
+
 	// since otps are usually simple digit numbers, enforce an extra rate limit rule as basic enumaration protection
 	err = checkRateLimit(e, "@pb_otp_"+event.Record.Id, core.RateLimitRule{MaxRequests: 5, Duration: 180})
 	if err != nil {
 		return e.TooManyRequestsError("Too many attempts, please try again later with a new OTP.", nil)
 	}
 
+	if err = checkAuthLockout(e, collection, event.Record.Id); err != nil {
+		return err
+	}
+
 	if !event.OTP.ValidatePassword(form.Password) {
+		lockoutErr := e.App.RegisterFailedAuthAttempt(collection, event.Record, core.MFAMethodOTP, event.Record.Id, e.RealIP())
+		if lockoutErr != nil {
+			e.App.Logger().Error("Failed to register failed auth attempt", "error", lockoutErr)
+		}
+
 		return e.BadRequestError("Invalid or expired OTP", errors.New("incorrect password"))
 	}
+
+	e.App.ResetAuthAttempts(collection, event.Record.Id, e.RealIP())
 	// ---
 
 	return e.App.OnRecordAuthWithOTPRequest().Trigger(event, func(e *core.RecordAuthWithOTPRequestEvent) error {
@@ -60,6 +60,10 @@ func recordAuthWithPassword(e *core.RequestEvent) error {
 		return e.InternalServerError("", foundErr)
 	}
 
+	if err = checkAuthLockout(e, collection, form.Identity); err != nil {
+		return err
+	}
+
 	event := new(core.RecordAuthWithPasswordRequestEvent)
 	event.RequestEvent = e
 	event.Collection = collection
@@ -70,9 +74,16 @@ func recordAuthWithPassword(e *core.RequestEvent) error {
 
 	return e.App.OnRecordAuthWithPasswordRequest().Trigger(event, func(e *core.RecordAuthWithPasswordRequestEvent) error {
 		if e.Record == nil || !e.Record.ValidatePassword(e.Password) {
+			lockoutErr := e.App.RegisterFailedAuthAttempt(e.Collection, e.Record, core.MFAMethodPassword, e.Identity, e.RealIP())
+			if lockoutErr != nil {
+				e.App.Logger().Error("Failed to register failed auth attempt", "error", lockoutErr)
+			}
+
 			return e.BadRequestError("Failed to authenticate.", errors.New("invalid login credentials"))
 		}
 
+		e.App.ResetAuthAttempts(e.Collection, e.Identity, e.RealIP())
+
 		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodPassword, nil)
 	})
 }
@@ -124,9 +124,49 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordAfterCreateSuccess":  1,
 				"OnRecordValidate":            1,
 				"OnMailerSend":                1,
+				"OnRecordNewDeviceLogin":      1,
 				"OnMailerRecordAuthAlertSend": 1,
 			},
 		},
+		{
+			Name:   "valid identity field and valid password with skipped new device login alert",
+			Method: http.MethodPost,
+			URL:    "/api/collections/clients/auth-with-password",
+			Body: strings.NewReader(`{
+				"identity":"test@example.com",
+				"password":"1234567890"
+			}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.OnRecordNewDeviceLogin("clients").BindFunc(func(e *core.RecordNewDeviceLoginEvent) error {
+					if e.AuthMethod != core.MFAMethodPassword || e.AuthOrigin == nil || !e.AuthOrigin.IsNew() {
+						t.Fatalf("Unexpected new device login event data %v", e)
+					}
+
+					return nil // skip the default alert email
+				})
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"email":"test@example.com"`,
+				`"token":`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                               0,
+				"OnRecordAuthWithPasswordRequest": 1,
+				"OnRecordAuthRequest":             1,
+				"OnRecordEnrich":                  1,
+				// authOrigin track
+				"OnModelCreate":              1,
+				"OnModelCreateExecute":       1,
+				"OnModelAfterCreateSuccess":  1,
+				"OnModelValidate":            1,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnRecordValidate":           1,
+				"OnRecordNewDeviceLogin":     1,
+			},
+		},
 		{
 			Name:   "valid identity field (username) and valid password",
 			Method: http.MethodPost,
@@ -161,6 +201,7 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordAfterCreateSuccess":  1,
 				"OnRecordValidate":            1,
 				"OnMailerSend":                1,
+				"OnRecordNewDeviceLogin":      1,
 				"OnMailerRecordAuthAlertSend": 1,
 			},
 		},
@@ -215,6 +256,7 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordAfterCreateSuccess":  1,
 				"OnRecordValidate":            1,
 				"OnMailerSend":                1,
+				"OnRecordNewDeviceLogin":      1,
 				"OnMailerRecordAuthAlertSend": 1,
 			},
 		},
@@ -270,6 +312,7 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordAfterCreateSuccess":  1,
 				"OnRecordValidate":            1,
 				"OnMailerSend":                1,
+				"OnRecordNewDeviceLogin":      1,
 				"OnMailerRecordAuthAlertSend": 1,
 			},
 		},
@@ -365,6 +408,7 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordAfterCreateSuccess":  1,
 				"OnRecordValidate":            1,
 				"OnMailerSend":                0, // disabled auth email alerts
+				"OnRecordNewDeviceLogin":      0,
 				"OnMailerRecordAuthAlertSend": 0,
 				// mfa delete
 				"OnModelDelete":              1,
@@ -421,6 +465,7 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordAfterCreateSuccess":  1,
 				"OnRecordValidate":            1,
 				"OnMailerSend":                0, // disabled auth email alerts
+				"OnRecordNewDeviceLogin":      0,
 				"OnMailerRecordAuthAlertSend": 0,
 			},
 			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
@@ -440,6 +485,69 @@ func TestRecordAuthWithPassword(t *testing.T) {
 			},
 		},
 
+		// auth lockout checks
+		// -----------------------------------------------------------
+		{
+			Name:   "invalid password with reached auth lockout max attempts",
+			Method: http.MethodPost,
+			URL:    "/api/collections/clients/auth-with-password",
+			Body: strings.NewReader(`{
+				"identity":"test@example.com",
+				"password":"invalid"
+			}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().AuthLockout = core.AuthLockoutConfig{Enabled: true, MaxAttempts: 1, Window: 60, Duration: 60}
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				collection, err := app.FindCollectionByNameOrId("clients")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if remaining := app.AuthLockoutRemaining(collection, "test@example.com", "192.0.2.1"); remaining <= 0 {
+					t.Fatalf("Expected the identity and IP pair to be locked, got %v", remaining)
+				}
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"*":                               0,
+				"OnRecordAuthWithPasswordRequest": 1,
+				"OnRecordAuthLockout":             1,
+			},
+		},
+		{
+			Name:   "valid password with active auth lockout",
+			Method: http.MethodPost,
+			URL:    "/api/collections/clients/auth-with-password",
+			Body: strings.NewReader(`{
+				"identity":"test@example.com",
+				"password":"1234567890"
+			}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().AuthLockout = core.AuthLockoutConfig{Enabled: true, MaxAttempts: 1, Window: 60, Duration: 60}
+
+				collection, err := app.FindCollectionByNameOrId("clients")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				err = app.RegisterFailedAuthAttempt(collection, nil, core.MFAMethodPassword, "TEST@example.com", "192.0.2.1")
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				retryAfter := res.Header.Get("Retry-After")
+				if retryAfter != "60" && retryAfter != "59" {
+					t.Fatalf("Expected Retry-After header ~60, got %q", retryAfter)
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+
 		// rate limit checks
 		// -----------------------------------------------------------
 		{
@@ -0,0 +1,520 @@
+package apis
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/saml"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/store"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+const (
+	samlSubscriptionTopic   string = "@saml"
+	samlRedirectFailurePath string = "../../../../_/#/auth/oauth2-redirect-failure"
+	samlRedirectSuccessPath string = "../../../../_/#/auth/oauth2-redirect-success"
+
+	samlRequestsStoreKey string = "@samlRequests"
+	samlCodesStoreKey    string = "@samlCodes"
+
+	samlRequestDuration = 10 * time.Minute
+	samlCodeDuration    = 5 * time.Minute
+	samlMaxPending      = 1000
+)
+
+// samlPendingRequest holds the data of an AuthnRequest waiting for an IdP response.
+type samlPendingRequest struct {
+	collectionId string
+	state        string
+}
+
+// samlPendingCode holds the data of a verified SAML assertion waiting
+// to be exchanged for an auth token.
+type samlPendingCode struct {
+	collectionId string
+	assertion    *saml.Assertion
+}
+
+type samlRedirectData struct {
+	State string `json:"state"`
+	Code  string `json:"code"`
+	Error string `json:"error,omitempty"`
+}
+
+// recordSAMLMetadata returns the collection SAML service provider metadata XML.
+func recordSAMLMetadata(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.SAML.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow SAML authentication.", nil)
+	}
+
+	sp, err := samlServiceProvider(e.App, collection)
+	if err != nil {
+		return e.InternalServerError("Failed to load the SAML configuration.", err)
+	}
+
+	metadata, err := sp.Metadata()
+	if err != nil {
+		return e.InternalServerError("Failed to generate the SAML metadata.", err)
+	}
+
+	return e.Blob(http.StatusOK, "application/samlmetadata+xml", metadata)
+}
+
+// recordSAMLLogin initializes a new SP AuthnRequest and redirects to the IdP.
+//
+// The "state" query parameter must be the id of a realtime client
+// subscribed to the "@saml" topic.
+func recordSAMLLogin(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.SAML.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow SAML authentication.", nil)
+	}
+
+	state := e.Request.URL.Query().Get("state")
+	if state == "" {
+		return e.BadRequestError("Missing state parameter.", nil)
+	}
+
+	client, err := e.App.SubscriptionsBroker().ClientById(state)
+	if err != nil || client.IsDiscarded() || !client.HasSubscription(samlSubscriptionTopic) {
+		return e.BadRequestError("Missing or invalid SAML subscription client.", err)
+	}
+
+	sp, err := samlServiceProvider(e.App, collection)
+	if err != nil {
+		return e.InternalServerError("Failed to load the SAML configuration.", err)
+	}
+
+	requestId := saml.NewRequestId()
+
+	requests := samlRequestsStore(e.App)
+	ok := requests.SetIfLessThanLimit(requestId, samlPendingRequest{
+		collectionId: collection.Id,
+		state:        state,
+	}, samlMaxPending)
+	if !ok {
+		return e.TooManyRequestsError("Too many pending SAML requests. Please try again later.", nil)
+	}
+	time.AfterFunc(samlRequestDuration, func() {
+		requests.Remove(requestId)
+	})
+
+	if sp.IdP.SSOBinding == saml.BindingHTTPPOST {
+		return e.HTML(http.StatusOK, sp.AuthnRequestForm(requestId, state))
+	}
+
+	redirectURL, err := sp.AuthnRequestURL(requestId, state)
+	if err != nil {
+		return e.InternalServerError("Failed to generate the SAML AuthnRequest.", err)
+	}
+
+	return e.Redirect(http.StatusTemporaryRedirect, redirectURL)
+}
+
+// recordSAMLAssertionConsumer handles the IdP HTTP-POST binding response
+// and notifies the "@saml" realtime subscriber with a short-lived
+// one-time code that could be exchanged for an auth token.
+func recordSAMLAssertionConsumer(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.SAML.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow SAML authentication.", nil)
+	}
+
+	state := e.Request.FormValue("RelayState")
+
+	failure := func(msg string, args ...any) error {
+		e.App.Logger().Debug(msg, args...)
+		samlNotifySubscriber(e.App, samlRedirectData{State: state, Error: "SAML authentication failed"})
+		return e.Redirect(http.StatusSeeOther, samlRedirectFailurePath)
+	}
+
+	sp, err := samlServiceProvider(e.App, collection)
+	if err != nil {
+		return failure("Failed to load the SAML configuration", "error", err)
+	}
+
+	assertion, err := sp.ParseResponse(e.Request.FormValue("SAMLResponse"))
+	if err != nil {
+		return failure("Invalid SAML response", "error", err)
+	}
+
+	// consume the related AuthnRequest
+	// (IdP-initiated responses are not allowed and it also prevents replays)
+	requests := samlRequestsStore(e.App)
+	pending, ok := requests.GetOk(assertion.InResponseTo)
+	if !ok {
+		return failure("Missing or expired SAML AuthnRequest", "inResponseTo", assertion.InResponseTo)
+	}
+	requests.Remove(assertion.InResponseTo)
+
+	if pending.collectionId != collection.Id || pending.state != state {
+		return failure("The SAML response doesn't match with the AuthnRequest collection or state", "inResponseTo", assertion.InResponseTo)
+	}
+
+	code := security.RandomString(40)
+
+	codes := samlCodesStore(e.App)
+	ok = codes.SetIfLessThanLimit(code, samlPendingCode{
+		collectionId: collection.Id,
+		assertion:    assertion,
+	}, samlMaxPending)
+	if !ok {
+		return failure("Too many pending SAML codes")
+	}
+	time.AfterFunc(samlCodeDuration, func() {
+		codes.Remove(code)
+	})
+
+	if !samlNotifySubscriber(e.App, samlRedirectData{State: state, Code: code}) {
+		codes.Remove(code)
+		return failure("Missing or invalid SAML subscription client", "clientId", state)
+	}
+
+	return e.Redirect(http.StatusSeeOther, samlRedirectSuccessPath)
+}
+
+// samlNotifySubscriber sends the specified data to the "@saml"
+// realtime subscriber with id data.State.
+//
+// Returns false if the subscriber is missing.
+func samlNotifySubscriber(app core.App, data samlRedirectData) bool {
+	if data.State == "" {
+		return false
+	}
+
+	client, err := app.SubscriptionsBroker().ClientById(data.State)
+	if err != nil || client.IsDiscarded() || !client.HasSubscription(samlSubscriptionTopic) {
+		return false
+	}
+	defer client.Unsubscribe(samlSubscriptionTopic)
+
+	encodedData, err := json.Marshal(data)
+	if err != nil {
+		app.Logger().Debug("Failed to marshalize SAML redirect data", "error", err)
+		return false
+	}
+
+	client.Send(subscriptions.Message{
+		Name: samlSubscriptionTopic,
+		Data: encodedData,
+	})
+
+	return true
+}
+
+// -------------------------------------------------------------------
+
+func recordAuthWithSAML(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.SAML.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow SAML authentication.", nil)
+	}
+
+	var fallbackAuthRecord *core.Record
+	if e.Auth != nil && e.Auth.Collection().Id == collection.Id {
+		fallbackAuthRecord = e.Auth
+	}
+
+	form := new(recordSAMLLoginForm)
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+
+	// consume the one-time code
+	codes := samlCodesStore(e.App)
+	pending, ok := codes.GetOk(form.Code)
+	if !ok || pending.collectionId != collection.Id {
+		return e.BadRequestError("Invalid or expired SAML code.", nil)
+	}
+	codes.Remove(form.Code)
+
+	assertion := pending.assertion
+	email := samlAssertionEmail(collection, assertion)
+
+	// locate existing ExternalAuth rel
+	// ---------------------------------------------------------------
+
+	var authRecord *core.Record
+
+	externalAuthRel, err := e.App.FindFirstExternalAuthByExpr(dbx.HashExp{
+		"collectionRef": collection.Id,
+		"provider":      core.ExternalAuthProviderSAML,
+		"providerId":    assertion.NameId,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return e.InternalServerError("Failed SAML relation check.", err)
+	}
+
+	switch {
+	case err == nil && externalAuthRel != nil:
+		authRecord, err = e.App.FindRecordById(collection, externalAuthRel.RecordRef())
+		if err != nil {
+			return err
+		}
+	case fallbackAuthRecord != nil:
+		// fallback to the logged auth record (if any)
+		authRecord = fallbackAuthRecord
+	case email != "":
+		// look for an existing auth record by the assertion email
+		authRecord, err = e.App.FindAuthRecordByEmail(collection.Id, email)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return e.InternalServerError("Failed SAML auth record check.", err)
+		}
+	}
+
+	// ---------------------------------------------------------------
+
+	event := new(core.RecordAuthWithSAMLRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.SAMLUser = assertion
+	event.CreateData = form.CreateData
+	event.Record = authRecord
+	event.IsNewRecord = authRecord == nil
+
+	return e.App.OnRecordAuthWithSAMLRequest().Trigger(event, func(e *core.RecordAuthWithSAMLRequestEvent) error {
+		if err := samlSubmit(e, externalAuthRel, email); err != nil {
+			return firstApiError(err, e.BadRequestError("Failed to authenticate.", err))
+		}
+
+		meta := struct {
+			*saml.Assertion
+			IsNew bool `json:"isNew"`
+		}{
+			Assertion: e.SAMLUser,
+			IsNew:     e.IsNewRecord,
+		}
+
+		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodSAML, meta)
+	})
+}
+
+// -------------------------------------------------------------------
+
+type recordSAMLLoginForm struct {
+	// Additional data that will be used for creating a new auth record
+	// if an existing SAML linked account doesn't exist.
+	CreateData map[string]any `form:"createData" json:"createData"`
+
+	// The one-time code sent to the "@saml" realtime subscriber.
+	Code string `form:"code" json:"code"`
+}
+
+func (form *recordSAMLLoginForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Code, validation.Required, validation.Length(0, 100)),
+	)
+}
+
+// samlAssertionEmail returns the assertion email address
+// (either from the attribute mapped to the "email" field or from the
+// NameID if it is a valid email address).
+func samlAssertionEmail(collection *core.Collection, assertion *saml.Assertion) string {
+	var email string
+
+	if attr := collection.SAML.MappedFields[core.FieldNameEmail]; attr != "" {
+		email = assertion.Attribute(attr)
+	} else if assertion.NameIdFormat == saml.NameIdFormatEmail || strings.Contains(assertion.NameId, "@") {
+		email = assertion.NameId
+	}
+
+	email = strings.TrimSpace(email)
+
+	if is.EmailFormat.Validate(email) != nil {
+		return ""
+	}
+
+	return email
+}
+
+func samlSubmit(e *core.RecordAuthWithSAMLRequestEvent, optExternalAuth *core.ExternalAuth, email string) error {
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		if e.Record == nil {
+			// extra check to prevent creating a superuser record via
+			// SAML in case the method is used by another action
+			if e.Collection.Name == core.CollectionNameSuperusers {
+				return errors.New("superusers are not allowed to sign-up with SAML")
+			}
+
+			payload := maps.Clone(e.CreateData)
+			if payload == nil {
+				payload = map[string]any{}
+			}
+
+			// map the assertion attributes (unless the field was explicitly submitted as part of CreateData)
+			for name, attr := range e.Collection.SAML.MappedFields {
+				if _, ok := payload[name]; ok {
+					continue
+				}
+
+				values := e.SAMLUser.Attributes[attr]
+				if len(values) == 0 {
+					continue
+				}
+
+				if mv, ok := e.Collection.Fields.GetByName(name).(core.MultiValuer); ok && mv.IsMultiple() {
+					payload[name] = values
+				} else {
+					payload[name] = values[0]
+				}
+			}
+
+			payload[core.FieldNameEmail] = email
+
+			// set a random password if none is set
+			if v, _ := payload[core.FieldNamePassword].(string); v == "" {
+				payload[core.FieldNamePassword] = security.RandomString(30)
+				payload[core.FieldNamePassword+"Confirm"] = payload[core.FieldNamePassword]
+			}
+
+			createdRecord, err := sendSAMLRecordCreateRequest(txApp, e, payload)
+			if err != nil {
+				return err
+			}
+
+			e.Record = createdRecord
+
+			if e.Record.Email() == email && !e.Record.Verified() {
+				// mark as verified as long as it matches the SAML data (even if the email is empty)
+				e.Record.SetVerified(true)
+				if err := txApp.Save(e.Record); err != nil {
+					return err
+				}
+			}
+		} else {
+			var needUpdate bool
+
+			isLoggedAuthRecord := e.Auth != nil &&
+				e.Auth.Id == e.Record.Id &&
+				e.Auth.Collection().Id == e.Record.Collection().Id
+
+			// set random password for users with unverified email
+			// (this is in case a malicious actor has registered previously with the user email)
+			if !isLoggedAuthRecord && e.Record.Email() != "" && !e.Record.Verified() {
+				e.Record.SetPassword(security.RandomString(30))
+				needUpdate = true
+			}
+
+			// update the existing auth record empty email if the assertion has one
+			if e.Record.Email() == "" && email != "" {
+				e.Record.SetEmail(email)
+				needUpdate = true
+			}
+
+			// update the existing auth record verified state
+			// (only if the auth record doesn't have an email or the auth record email match with the assertion one)
+			if !e.Record.Verified() && (e.Record.Email() == "" || e.Record.Email() == email) {
+				e.Record.SetVerified(true)
+				needUpdate = true
+			}
+
+			if needUpdate {
+				if err := txApp.Save(e.Record); err != nil {
+					return err
+				}
+			}
+		}
+
+		// create ExternalAuth relation if missing
+		if optExternalAuth == nil {
+			optExternalAuth = core.NewExternalAuth(txApp)
+			optExternalAuth.SetCollectionRef(e.Record.Collection().Id)
+			optExternalAuth.SetRecordRef(e.Record.Id)
+			optExternalAuth.SetProvider(core.ExternalAuthProviderSAML)
+			optExternalAuth.SetProviderId(e.SAMLUser.NameId)
+
+			if err := txApp.Save(optExternalAuth); err != nil {
+				return fmt.Errorf("failed to save linked rel: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func sendSAMLRecordCreateRequest(txApp core.App, e *core.RecordAuthWithSAMLRequestEvent, payload map[string]any) (*core.Record, error) {
+	ir := &core.InternalRequest{
+		Method: http.MethodPost,
+		URL:    "/api/collections/" + e.Collection.Name + "/records",
+		Body:   payload,
+	}
+
+	var createdRecord *core.Record
+	response, err := processInternalRequest(txApp, e.RequestEvent, ir, core.RequestInfoContextSAML, func(data any) error {
+		createdRecord, _ = data.(*core.Record)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Status != http.StatusOK || createdRecord == nil {
+		return nil, errors.New("failed to create SAML auth record")
+	}
+
+	return createdRecord, nil
+}
+
+// -------------------------------------------------------------------
+
+// samlServiceProvider initializes a new SAML service provider from the collection options.
+//
+// The SP entity id and ACS url are based on the application url and the collection id.
+func samlServiceProvider(app core.App, collection *core.Collection) (*saml.ServiceProvider, error) {
+	idp, err := collection.SAML.IdP()
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := strings.TrimRight(app.Settings().Meta.AppURL, "/") + "/api/collections/" + collection.Id + "/saml"
+
+	return &saml.ServiceProvider{
+		IdP:      idp,
+		EntityId: baseURL + "/metadata",
+		ACSURL:   baseURL + "/acs",
+	}, nil
+}
+
+func samlRequestsStore(app core.App) *store.Store[samlPendingRequest] {
+	return app.Store().GetOrSet(samlRequestsStoreKey, func() any {
+		return store.New[samlPendingRequest](nil)
+	}).(*store.Store[samlPendingRequest])
+}
+
+func samlCodesStore(app core.App) *store.Store[samlPendingCode] {
+	return app.Store().GetOrSet(samlCodesStoreKey, func() any {
+		return store.New[samlPendingCode](nil)
+	}).(*store.Store[samlPendingCode])
+}
@@ -0,0 +1,534 @@
+package apis_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+const samlTestIdPEntityId = "https://idp.example.com/metadata"
+
+type samlTestIdP struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newSAMLTestIdP(t testing.TB) *samlTestIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &samlTestIdP{key: key, cert: cert}
+}
+
+func (idp *samlTestIdP) metadata() string {
+	return `<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="` + samlTestIdPEntityId + `">` +
+		`<md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">` +
+		`<md:KeyDescriptor use="signing"><ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:X509Data>` +
+		`<ds:X509Certificate>` + base64.StdEncoding.EncodeToString(idp.cert.Raw) + `</ds:X509Certificate>` +
+		`</ds:X509Data></ds:KeyInfo></md:KeyDescriptor>` +
+		`<md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/sso"/>` +
+		`</md:IDPSSODescriptor>` +
+		`</md:EntityDescriptor>`
+}
+
+// response returns a new base64 encoded SAMLResponse with a signed assertion.
+//
+// note: the assertion and the SignedInfo are constructed directly in
+// their exclusive canonical form so that they could be signed as they are.
+func (idp *samlTestIdP) response(t testing.TB, spBaseURL string, requestId string, nameId string) string {
+	now := time.Now().UTC()
+	notBefore := now.Add(-time.Minute).Format("2006-01-02T15:04:05Z")
+	notOnOrAfter := now.Add(5 * time.Minute).Format("2006-01-02T15:04:05Z")
+
+	issuer := `<saml:Issuer>` + samlTestIdPEntityId + `</saml:Issuer>`
+
+	body := `<saml:Subject>` +
+		`<saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress">` + nameId + `</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">` +
+		`<saml:SubjectConfirmationData InResponseTo="` + requestId + `" NotOnOrAfter="` + notOnOrAfter + `" Recipient="` + spBaseURL + `/acs"></saml:SubjectConfirmationData>` +
+		`</saml:SubjectConfirmation>` +
+		`</saml:Subject>` +
+		`<saml:Conditions NotBefore="` + notBefore + `" NotOnOrAfter="` + notOnOrAfter + `">` +
+		`<saml:AudienceRestriction><saml:Audience>` + spBaseURL + `/metadata</saml:Audience></saml:AudienceRestriction>` +
+		`</saml:Conditions>` +
+		`<saml:AttributeStatement>` +
+		`<saml:Attribute Name="displayName"><saml:AttributeValue>John Doe</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement>`
+
+	assertionStart := `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="a1" IssueInstant="` + now.Format("2006-01-02T15:04:05Z") + `" Version="2.0">`
+
+	digest := sha256.Sum256([]byte(assertionStart + issuer + body + `</saml:Assertion>`))
+
+	signedInfo := `<ds:SignedInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` +
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"></ds:SignatureMethod>` +
+		`<ds:Reference URI="#a1">` +
+		`<ds:Transforms>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"></ds:Transform>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:Transform>` +
+		`</ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference>` +
+		`</ds:SignedInfo>`
+
+	hashed := sha256.Sum256([]byte(signedInfo))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" ID="r1" Version="2.0" Destination="` + spBaseURL + `/acs" InResponseTo="` + requestId + `">` +
+		`<saml:Issuer xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion">` + samlTestIdPEntityId + `</saml:Issuer>` +
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>` +
+		assertionStart + issuer +
+		`<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` + signedInfo +
+		`<ds:SignatureValue>` + base64.StdEncoding.EncodeToString(signature) + `</ds:SignatureValue>` +
+		`</ds:Signature>` +
+		body +
+		`</saml:Assertion>` +
+		`</samlp:Response>`
+
+	return base64.StdEncoding.EncodeToString([]byte(response))
+}
+
+func enableSAML(t testing.TB, app core.App, idp *samlTestIdP) *core.Collection {
+	collection, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection.MFA.Enabled = false
+	collection.SAML.Enabled = true
+	collection.SAML.IdPMetadata = idp.metadata()
+	collection.SAML.MappedFields = map[string]string{"name": "displayName"}
+
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	return collection
+}
+
+func registerSAMLClient(app core.App) subscriptions.Client {
+	client := subscriptions.NewDefaultClient()
+	client.Subscribe("@saml")
+	app.SubscriptionsBroker().Register(client)
+	return client
+}
+
+func TestRecordSAMLMetadata(t *testing.T) {
+	t.Parallel()
+
+	idp := newSAMLTestIdP(t)
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "missing collection",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/missing/saml/metadata",
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "disabled SAML",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/users/saml/metadata",
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "enabled SAML",
+			Method: http.MethodGet,
+			URL:    "/api/collections/users/saml/metadata",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().Meta.AppURL = "https://example.com"
+				enableSAML(t, app, idp)
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`entityID="https://example.com/api/collections/_pb_users_auth_/saml/metadata"`,
+				`Location="https://example.com/api/collections/_pb_users_auth_/saml/acs"`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordSAMLLogin(t *testing.T) {
+	t.Parallel()
+
+	idp := newSAMLTestIdP(t)
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "disabled SAML",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/users/saml/login?state=test",
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "missing state",
+			Method: http.MethodGet,
+			URL:    "/api/collections/users/saml/login",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableSAML(t, app, idp)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "missing subscription client",
+			Method: http.MethodGet,
+			URL:    "/api/collections/users/saml/login?state=missing",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableSAML(t, app, idp)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordSAMLAssertionConsumerFailure(t *testing.T) {
+	t.Parallel()
+
+	idp := newSAMLTestIdP(t)
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "disabled SAML",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/saml/acs",
+			Body:            strings.NewReader("SAMLResponse=test&RelayState=test"),
+			Headers:         map[string]string{"content-type": "application/x-www-form-urlencoded"},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:    "invalid SAMLResponse",
+			Method:  http.MethodPost,
+			URL:     "/api/collections/users/saml/acs",
+			Body:    strings.NewReader("SAMLResponse=test&RelayState=test"),
+			Headers: map[string]string{"content-type": "application/x-www-form-urlencoded"},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableSAML(t, app, idp)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if loc := res.Header.Get("Location"); loc != "../../../../_/#/auth/oauth2-redirect-failure" {
+					t.Fatalf("Expected failure redirect, got %q", loc)
+				}
+			},
+			ExpectedStatus: 303,
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+		{
+			Name:   "valid signature but unknown AuthnRequest",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/saml/acs",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().Meta.AppURL = "https://example.com"
+				enableSAML(t, app, idp)
+			},
+			Body: strings.NewReader(url.Values{
+				"SAMLResponse": {idp.response(t, "https://example.com/api/collections/_pb_users_auth_/saml", "id123", "test@example.com")},
+				"RelayState":   {"test"},
+			}.Encode()),
+			Headers: map[string]string{"content-type": "application/x-www-form-urlencoded"},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if loc := res.Header.Get("Location"); loc != "../../../../_/#/auth/oauth2-redirect-failure" {
+					t.Fatalf("Expected failure redirect, got %q", loc)
+				}
+			},
+			ExpectedStatus: 303,
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordAuthWithSAMLValidation(t *testing.T) {
+	t.Parallel()
+
+	idp := newSAMLTestIdP(t)
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "disabled SAML",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/auth-with-saml",
+			Body:            strings.NewReader(`{"code":"test"}`),
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "missing code",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-saml",
+			Body:   strings.NewReader(`{}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableSAML(t, app, idp)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"code":{"code":"validation_required"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "invalid code",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-saml",
+			Body:   strings.NewReader(`{"code":"test"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableSAML(t, app, idp)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordAuthWithSAMLFlow(t *testing.T) {
+	t.Parallel()
+
+	idp := newSAMLTestIdP(t)
+
+	scenarios := []struct {
+		name          string
+		nameId        string
+		expectedIsNew bool
+	}{
+		{"existing record by email", "test@example.com", false},
+		{"new record", "new@example.com", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			app.Settings().Meta.AppURL = "https://example.com"
+
+			collection := enableSAML(t, app, idp)
+
+			client, err := tests.NewTestClient(app)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			realtimeClient := registerSAMLClient(app)
+
+			// login
+			// ---
+			res := client.Send(http.MethodGet, "/api/collections/users/saml/login?state="+realtimeClient.Id(), nil, nil)
+			if res.StatusCode != http.StatusTemporaryRedirect {
+				t.Fatalf("Expected login redirect, got %d", res.StatusCode)
+			}
+
+			loc, err := url.Parse(res.Header.Get("Location"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v := loc.Query().Get("RelayState"); v != realtimeClient.Id() {
+				t.Fatalf("Expected RelayState %q, got %q", realtimeClient.Id(), v)
+			}
+
+			requestId := samlRequestId(t, loc.Query().Get("SAMLRequest"))
+
+			// acs
+			// ---
+			messages := make(chan subscriptions.Message, 1)
+			go func() {
+				messages <- <-realtimeClient.Channel()
+			}()
+
+			form := url.Values{
+				"SAMLResponse": {idp.response(t, "https://example.com/api/collections/"+collection.Id+"/saml", requestId, s.nameId)},
+				"RelayState":   {realtimeClient.Id()},
+			}
+			res = client.Send(http.MethodPost, "/api/collections/users/saml/acs", strings.NewReader(form.Encode()), map[string]string{
+				"content-type": "application/x-www-form-urlencoded",
+			})
+			if loc := res.Header.Get("Location"); loc != "../../../../_/#/auth/oauth2-redirect-success" {
+				t.Fatalf("Expected success redirect, got %q", loc)
+			}
+
+			var msg subscriptions.Message
+			select {
+			case msg = <-messages:
+			case <-time.After(time.Second):
+				t.Fatal("Missing @saml realtime message")
+			}
+
+			if msg.Name != "@saml" {
+				t.Fatalf("Expected @saml message, got %q", msg.Name)
+			}
+
+			msgData := struct {
+				State string `json:"state"`
+				Code  string `json:"code"`
+			}{}
+			if err := json.Unmarshal(msg.Data, &msgData); err != nil {
+				t.Fatal(err)
+			}
+
+			if msgData.State != realtimeClient.Id() || msgData.Code == "" {
+				t.Fatalf("Invalid message data %s", msg.Data)
+			}
+
+			// replay
+			// ---
+			res = client.Send(http.MethodPost, "/api/collections/users/saml/acs", strings.NewReader(form.Encode()), map[string]string{
+				"content-type": "application/x-www-form-urlencoded",
+			})
+			if loc := res.Header.Get("Location"); loc != "../../../../_/#/auth/oauth2-redirect-failure" {
+				t.Fatalf("Expected replay failure redirect, got %q", loc)
+			}
+
+			// auth
+			// ---
+			result := struct {
+				Token  string         `json:"token"`
+				Record map[string]any `json:"record"`
+				Meta   struct {
+					NameId string `json:"nameId"`
+					IsNew  bool   `json:"isNew"`
+				} `json:"meta"`
+			}{}
+
+			res, err = client.SendJSON(http.MethodPost, "/api/collections/users/auth-with-saml", map[string]any{"code": msgData.Code}, &result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("Expected auth status 200, got %d", res.StatusCode)
+			}
+
+			if result.Token == "" {
+				t.Fatal("Expected auth token")
+			}
+
+			if result.Meta.NameId != s.nameId || result.Meta.IsNew != s.expectedIsNew {
+				t.Fatalf("Invalid meta %+v", result.Meta)
+			}
+
+			record, err := app.FindAuthRecordByEmail(collection, s.nameId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !record.Verified() {
+				t.Fatal("Expected the record to be verified")
+			}
+
+			if s.expectedIsNew && record.GetString("name") != "John Doe" {
+				t.Fatalf("Expected the mapped name attribute, got %q", record.GetString("name"))
+			}
+
+			externalAuths, err := app.FindAllExternalAuthsByRecord(record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var linked bool
+			for _, ea := range externalAuths {
+				if ea.Provider() == core.ExternalAuthProviderSAML && ea.ProviderId() == s.nameId {
+					linked = true
+				}
+			}
+			if !linked {
+				t.Fatal("Expected SAML external auth to be created")
+			}
+
+			// the code can be used only once
+			res = client.Send(http.MethodPost, "/api/collections/users/auth-with-saml", strings.NewReader(`{"code":"`+msgData.Code+`"}`), nil)
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("Expected code reuse status 400, got %d", res.StatusCode)
+			}
+		})
+	}
+}
+
+var samlRequestIdRegex = regexp.MustCompile(`ID="([^"]+)"`)
+
+func samlRequestId(t testing.TB, encoded string) string {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xml, err := io.ReadAll(flate.NewReader(bytes.NewReader(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	match := samlRequestIdRegex.FindSubmatch(xml)
+	if len(match) != 2 {
+		t.Fatalf("Missing AuthnRequest ID in %s", xml)
+	}
+
+	return string(match[1])
+}
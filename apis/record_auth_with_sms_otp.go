@@ -0,0 +1,90 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func recordAuthWithSMSOTP(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.SMSOTP.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow SMS OTP authentication.", nil)
+	}
+
+	form := &authWithOTPForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	event := new(core.RecordAuthWithSMSOTPRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+
+	// extra validations
+	// (note: returns a generic 400 as a very basic OTPs enumeration protection)
+	// ---
+	event.OTP, err = e.App.FindOTPById(form.OTPId)
+	if err != nil {
+		return e.BadRequestError("Invalid or expired OTP", err)
+	}
+
+	if event.OTP.CollectionRef() != collection.Id {
+		return e.BadRequestError("Invalid or expired OTP", errors.New("the OTP is for a different collection"))
+	}
+
+	if event.OTP.HasExpired(collection.SMSOTP.DurationTime()) {
+		return e.BadRequestError("Invalid or expired OTP", errors.New("the OTP is expired"))
+	}
+
+	event.Record, err = e.App.FindRecordById(event.OTP.CollectionRef(), event.OTP.RecordRef())
+	if err != nil {
+		return e.BadRequestError("Invalid or expired OTP", fmt.Errorf("missing auth record: %w", err))
+	}
+
+	// ensure that the OTP was sent to the current record phone number
+	phone := event.Record.GetString(collection.SMSOTP.PhoneField)
+	if phone == "" || event.OTP.SentTo() != phone {
+		return e.BadRequestError("Invalid or expired OTP", errors.New("the OTP was not sent to the record phone number"))
+	}
+
+	// since otps are usually simple digit numbers, enforce an extra rate limit rule as basic enumaration protection
+	err = checkRateLimit(e, "@pb_sms_otp_"+event.Record.Id, core.RateLimitRule{MaxRequests: 5, Duration: 180})
+	if err != nil {
+		return e.TooManyRequestsError("Too many attempts, please try again later with a new OTP.", nil)
+	}
+
+	if err = checkAuthLockout(e, collection, event.Record.Id); err != nil {
+		return err
+	}
+
+	if !event.OTP.ValidatePassword(form.Password) {
+		lockoutErr := e.App.RegisterFailedAuthAttempt(collection, event.Record, core.MFAMethodSMSOTP, event.Record.Id, e.RealIP())
+		if lockoutErr != nil {
+			e.App.Logger().Error("Failed to register failed auth attempt", "error", lockoutErr)
+		}
+
+		return e.BadRequestError("Invalid or expired OTP", errors.New("incorrect password"))
+	}
+
+	e.App.ResetAuthAttempts(collection, event.Record.Id, e.RealIP())
+	// ---
+
+	return e.App.OnRecordAuthWithSMSOTPRequest().Trigger(event, func(e *core.RecordAuthWithSMSOTPRequestEvent) error {
+		// try to delete the used otp
+		err = e.App.Delete(e.OTP)
+		if err != nil {
+			e.App.Logger().Error("Failed to delete used OTP", "error", err, "otpId", e.OTP.Id)
+		}
+
+		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodSMSOTP, nil)
+	})
+}
@@ -0,0 +1,204 @@
+package apis_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestRecordAuthWithSMSOTP(t *testing.T) {
+	t.Parallel()
+
+	otpId := strings.Repeat("a", 15)
+
+	createOTP := func(t testing.TB, app core.App, user *core.Record, sentTo string) *core.OTP {
+		otp := core.NewOTP(app)
+		otp.Id = otpId
+		otp.SetCollectionRef(user.Collection().Id)
+		otp.SetRecordRef(user.Id)
+		otp.SetPassword("123456")
+		otp.SetSentTo(sentTo)
+		if err := app.Save(otp); err != nil {
+			t.Fatal(err)
+		}
+		return otp
+	}
+
+	validBody := `{"otpId":"` + otpId + `","password":"123456"}`
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "not an auth collection",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/demo1/auth-with-sms-otp",
+			Body:            strings.NewReader(validBody),
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "auth collection with disabled sms otp",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/auth-with-sms-otp",
+			Body:            strings.NewReader(validBody),
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "empty body",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-sms-otp",
+			Body:   strings.NewReader(``),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableUsersSMSOTP(t, app)
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"otpId":{"code":"validation_required"`,
+				`"password":{"code":"validation_required"`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+		{
+			Name:   "missing otp",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-sms-otp",
+			Body:   strings.NewReader(validBody),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableUsersSMSOTP(t, app)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "email otp (sentTo != phone)",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-sms-otp",
+			Body:   strings.NewReader(validBody),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				user := enableUsersSMSOTP(t, app)
+				createOTP(t, app, user, user.Email())
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "otp with wrong password",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-sms-otp",
+			Body:   strings.NewReader(`{"otpId":"` + otpId + `","password":"654321"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				user := enableUsersSMSOTP(t, app)
+				createOTP(t, app, user, testSMSOTPPhone)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "expired otp with valid password",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-sms-otp",
+			Body:   strings.NewReader(validBody),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				user := enableUsersSMSOTP(t, app)
+				otp := createOTP(t, app, user, testSMSOTPPhone)
+
+				expiredDate := types.NowDateTime().Add(-181e9) // 181s
+				otp.SetRaw("created", expiredDate)
+				otp.SetRaw("updated", expiredDate)
+				if err := app.SaveNoValidate(otp); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "valid otp with valid password (disabled MFA)",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-sms-otp",
+			Body:   strings.NewReader(validBody),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				user := enableUsersSMSOTP(t, app)
+
+				user.Collection().MFA.Enabled = false
+				if err := app.Save(user.Collection()); err != nil {
+					t.Fatal(err)
+				}
+
+				createOTP(t, app, user, testSMSOTPPhone)
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"record":{`,
+				`"email":"test@example.com"`,
+			},
+			NotExpectedContent: []string{
+				`"meta":`,
+				`"tokenKey"`,
+				`"password"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                             0,
+				"OnRecordAuthWithSMSOTPRequest": 1,
+				"OnRecordAuthRequest":           1,
+				"OnRecordEnrich":                1,
+				// ---
+				"OnModelValidate":           1,
+				"OnModelCreate":             1, // authOrigin
+				"OnModelCreateExecute":      1,
+				"OnModelAfterCreateSuccess": 1,
+				"OnModelDelete":             1, // otp delete
+				"OnModelDeleteExecute":      1,
+				"OnModelAfterDeleteSuccess": 1,
+				// ---
+				"OnRecordValidate":           1,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if _, err := app.FindOTPById(otpId); err == nil {
+					t.Fatal("Expected the used OTP to be deleted")
+				}
+			},
+		},
+
+		// rate limit checks
+		// -----------------------------------------------------------
+		{
+			Name:   "RateLimit rule - users:authWithSMSOTP",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-sms-otp",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 100, Label: "*:authWithSMSOTP"},
+					{MaxRequests: 100, Label: "users:auth"},
+					{MaxRequests: 0, Label: "users:authWithSMSOTP"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
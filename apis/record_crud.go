@@ -2,13 +2,18 @@ package apis
 
 import (
 	cryptoRand "crypto/rand"
+	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
 	"math/big"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/forms"
@@ -25,10 +30,16 @@ import (
 func bindRecordCrudApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
 	subGroup := rg.Group("/collections/{collection}/records").Unbind(DefaultRateLimitMiddlewareId)
 	subGroup.GET("", recordsList)
+	subGroup.GET("/count", recordsCount)
+	subGroup.POST("/similar", recordsSimilar)
 	subGroup.GET("/{id}", recordView)
-	subGroup.POST("", recordCreate(nil)).Bind(dynamicCollectionBodyLimit(""))
+	subGroup.POST("", recordCreate(nil)).Bind(dynamicCollectionBodyLimit(""), Idempotency())
+	subGroup.PUT("", recordUpsert(nil)).Bind(dynamicCollectionBodyLimit(""))
 	subGroup.PATCH("/{id}", recordUpdate(nil)).Bind(dynamicCollectionBodyLimit(""))
 	subGroup.DELETE("/{id}", recordDelete(nil))
+	subGroup.POST("/{id}/reveal/{field}", recordSecretReveal)
+
+	rg.GET("/collections/{collection}/aggregate", recordsAggregate).Unbind(DefaultRateLimitMiddlewareId)
 }
 
 func recordsList(e *core.RequestEvent) error {
@@ -47,29 +58,21 @@ func recordsList(e *core.RequestEvent) error {
 		return firstApiError(err, e.BadRequestError("", err))
 	}
 
-	if collection.ListRule == nil && !requestInfo.HasSuperuserAuth() {
-		return e.ForbiddenError("Only superusers can perform this action.", nil)
-	}
-
-	// forbid users and guests to query special filter/sort fields
-	err = checkForSuperuserOnlyRuleFields(requestInfo)
+	searchProvider, searchColumns, err := newRecordsSearchProvider(e, collection, requestInfo)
 	if err != nil {
 		return err
 	}
 
-	fieldsResolver := core.NewRecordFieldResolver(
-		e.App,
-		collection,
-		requestInfo,
-		// hidden fields are searchable only by superusers
-		requestInfo.HasSuperuserAuth(),
-	)
+	if isDebugQuery(e) {
+		if !requestInfo.HasSuperuserAuth() {
+			return e.ForbiddenError("Only superusers can use the "+DebugQueryParam+" query parameter.", nil)
+		}
 
-	searchProvider := search.NewProvider(fieldsResolver).
-		Query(e.App.RecordQuery(collection))
+		return recordsListDebug(e, collection, requestInfo, searchProvider)
+	}
 
-	if !requestInfo.HasSuperuserAuth() && collection.ListRule != nil {
-		searchProvider.AddFilter(search.FilterData(*collection.ListRule))
+	if isStreamRequest(e) {
+		return recordsListStream(e, collection, searchProvider)
 	}
 
 	records := []*core.Record{}
@@ -79,6 +82,11 @@ func recordsList(e *core.RequestEvent) error {
 		return firstApiError(err, e.BadRequestError("", err))
 	}
 
+	err = loadRecordsSearchSnippets(e, collection, searchColumns, records)
+	if err != nil {
+		return e.BadRequestError("Failed to load the search snippets.", err)
+	}
+
 	event := new(core.RecordsListRequestEvent)
 	event.RequestEvent = e
 	event.Collection = collection
@@ -103,7 +111,7 @@ func recordsList(e *core.RequestEvent) error {
 		// are not client-side filterable and in the few places where they need to be compared against an external value, a constant time check is used.
 		if !e.HasSuperuserAuth() &&
 			(collection.ListRule != nil && *collection.ListRule != "") &&
-			(requestInfo.Query["filter"] != "") &&
+			(requestInfo.Query["filter"] != "" || requestInfo.Query[SearchQueryParam] != "") &&
 			len(e.Records) == 0 &&
 			checkRateLimit(e.RequestEvent, "@pb_list_timing_check_"+collection.Id, listTimingRateLimitRule) != nil {
 			e.App.Logger().Debug("Randomized throttle because of too many failed searches", "collectionId", collection.Id)
@@ -116,6 +124,50 @@ func recordsList(e *core.RequestEvent) error {
 
 var listTimingRateLimitRule = core.RateLimitRule{MaxRequests: 3, Duration: 3}
 
+// newRecordsSearchProvider creates a new records search provider with the
+// access checks and filters of the records list API (List API rule,
+// superusers only filter fields, full-text search, etc.).
+//
+// It is used also by the records count and aggregate APIs so that
+// their results always match with the ones of the records list.
+//
+// The client filter and sort query parameters are not applied.
+func newRecordsSearchProvider(e *core.RequestEvent, collection *core.Collection, requestInfo *core.RequestInfo) (*search.Provider, []string, error) {
+	if collection.ListRule == nil && !requestInfo.HasSuperuserAuth() {
+		return nil, nil, e.ForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	// forbid users and guests to query special filter/sort fields
+	err := checkForSuperuserOnlyRuleFields(requestInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fieldsResolver := core.NewRecordFieldResolver(
+		e.App,
+		collection,
+		requestInfo,
+		// hidden fields are searchable only by superusers
+		requestInfo.HasSuperuserAuth(),
+	)
+
+	// cancel the queries when the client disconnects
+	query := e.App.RecordQuery(collection).WithContext(e.Request.Context())
+
+	searchColumns, err := applyRecordsSearch(e, collection, requestInfo, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	searchProvider := search.NewProvider(fieldsResolver).Query(query)
+
+	if !requestInfo.HasSuperuserAuth() && collection.ListRule != nil {
+		searchProvider.AddFilter(search.FilterData(*collection.ListRule))
+	}
+
+	return searchProvider, searchColumns, nil
+}
+
 func randomizedThrottle(softMax int64) {
 	var timeout int64
 	randRange, err := cryptoRand.Int(cryptoRand.Reader, big.NewInt(softMax))
@@ -166,9 +218,13 @@ func recordView(e *core.RequestEvent) error {
 		return nil
 	}
 
-	record, fetchErr := e.App.FindRecordById(collection, recordId, ruleFunc)
+	record, fetchErr := e.App.WithContext(e.Request.Context()).FindRecordById(collection, recordId, ruleFunc)
 	if fetchErr != nil || record == nil {
-		return firstApiError(err, e.NotFoundError("", fetchErr))
+		// fallback to the collection slug fields (if any)
+		record, fetchErr = findRecordBySlug(e.App.WithContext(e.Request.Context()), collection, recordId, ruleFunc)
+		if fetchErr != nil || record == nil {
+			return firstApiError(err, e.NotFoundError("", fetchErr))
+		}
 	}
 
 	event := new(core.RecordRequestEvent)
@@ -185,6 +241,64 @@ func recordView(e *core.RequestEvent) error {
 	})
 }
 
+// findRecordBySlug returns the first record which value of one of the
+// collection slug text fields (aka. with SlugSource) matches the provided slug.
+func findRecordBySlug(
+	app core.App,
+	collection *core.Collection,
+	slug string,
+	optFilters ...func(q *dbx.SelectQuery) error,
+) (*core.Record, error) {
+	for _, field := range collection.Fields {
+		text, ok := field.(*core.TextField)
+		if !ok || text.SlugSource == "" {
+			continue
+		}
+
+		query := app.RecordQuery(collection).
+			AndWhere(dbx.HashExp{collection.Name + "." + text.Name: slug}).
+			Limit(1)
+
+		for _, filter := range optFilters {
+			if filter == nil {
+				continue
+			}
+			if err := filter(query); err != nil {
+				return nil, err
+			}
+		}
+
+		record := &core.Record{}
+		err := query.One(record)
+		if err == nil {
+			return record, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+// DryRunQueryParam is the name of the query parameter that switches the
+// record create and update endpoints to validation-only mode.
+const DryRunQueryParam = "dryRun"
+
+// IsDryRun reports whether the request is a validation-only (aka. dry-run) request.
+//
+// Dry-run requests are checked against the collection API rules and
+// the record validators (including the unique constraints) but
+// nothing is persisted and on success a 204 response is returned.
+//
+// Hooks with side effects (e.g. sending an email from OnRecordCreateRequest)
+// may want to check it and skip the related action.
+func IsDryRun(e *core.RequestEvent) bool {
+	v, _ := strconv.ParseBool(e.Request.URL.Query().Get(DryRunQueryParam))
+
+	return v
+}
+
 func recordCreate(optFinalizer func(data any) error) func(e *core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
 		collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
@@ -213,7 +327,7 @@ func recordCreate(optFinalizer func(data any) error) func(e *core.RequestEvent)
 
 		record := core.NewRecord(collection)
 
-		data, err := recordDataFromRequest(e, record)
+		data, remoteFiles, err := recordDataFromRequest(e, record)
 		if err != nil {
 			return firstApiError(err, e.BadRequestError("Failed to read the submitted data.", err))
 		}
@@ -223,6 +337,7 @@ func recordCreate(optFinalizer func(data any) error) func(e *core.RequestEvent)
 		requestInfo.Body = data
 
 		form := forms.NewRecordUpsert(e.App, record)
+		form.SetContext(e.Request.Context())
 		if hasSuperuserAuth {
 			form.GrantSuperuserAccess()
 		}
@@ -289,7 +404,34 @@ func recordCreate(optFinalizer func(data any) error) func(e *core.RequestEvent)
 				}
 			}
 
-			err := form.Submit()
+			if IsDryRun(e.RequestEvent) {
+				err := form.Validate()
+				if err != nil {
+					return firstApiError(err, e.BadRequestError("Failed to create record.", err))
+				}
+
+				err = e.NoContent(http.StatusNoContent)
+				if err != nil {
+					return err
+				}
+
+				if optFinalizer != nil {
+					isOptFinalizerCalled = true
+					err = optFinalizer(e.Record)
+					if err != nil {
+						return firstApiError(err, e.InternalServerError("", err))
+					}
+				}
+
+				return nil
+			}
+
+			err := loadRemoteFiles(e.RequestEvent, form, e.Record, remoteFiles)
+			if err != nil {
+				return err
+			}
+
+			err = form.Submit()
 			if err != nil {
 				return firstApiError(err, e.BadRequestError("Failed to create record.", err))
 			}
@@ -367,7 +509,7 @@ func recordUpdate(optFinalizer func(data any) error) func(e *core.RequestEvent)
 			return firstApiError(err, e.NotFoundError("", err))
 		}
 
-		data, err := recordDataFromRequest(e, record)
+		data, remoteFiles, err := recordDataFromRequest(e, record)
 		if err != nil {
 			return firstApiError(err, e.BadRequestError("Failed to read the submitted data.", err))
 		}
@@ -390,12 +532,13 @@ func recordUpdate(optFinalizer func(data any) error) func(e *core.RequestEvent)
 		}
 
 		// refetch with access checks
-		record, err = e.App.FindRecordById(collection, recordId, ruleFunc)
+		record, err = e.App.WithContext(e.Request.Context()).FindRecordById(collection, recordId, ruleFunc)
 		if err != nil {
 			return firstApiError(err, e.NotFoundError("", err))
 		}
 
 		form := forms.NewRecordUpsert(e.App, record)
+		form.SetContext(e.Request.Context())
 		if hasSuperuserAuth {
 			form.GrantSuperuserAccess()
 		}
@@ -415,7 +558,34 @@ func recordUpdate(optFinalizer func(data any) error) func(e *core.RequestEvent)
 				form.GrantManagerAccess()
 			}
 
-			err := form.Submit()
+			if IsDryRun(e.RequestEvent) {
+				err := form.Validate()
+				if err != nil {
+					return firstApiError(err, e.BadRequestError("Failed to update record.", err))
+				}
+
+				err = e.NoContent(http.StatusNoContent)
+				if err != nil {
+					return err
+				}
+
+				if optFinalizer != nil {
+					isOptFinalizerCalled = true
+					err = optFinalizer(e.Record)
+					if err != nil {
+						return firstApiError(err, e.InternalServerError("", fmt.Errorf("update optFinalizer error: %w", err)))
+					}
+				}
+
+				return nil
+			}
+
+			err := loadRemoteFiles(e.RequestEvent, form, e.Record, remoteFiles)
+			if err != nil {
+				return err
+			}
+
+			err = form.Submit()
 			if err != nil {
 				return firstApiError(err, e.BadRequestError("Failed to update record.", err))
 			}
@@ -498,7 +668,7 @@ func recordDelete(optFinalizer func(data any) error) func(e *core.RequestEvent)
 			return nil
 		}
 
-		record, err := e.App.FindRecordById(collection, recordId, ruleFunc)
+		record, err := e.App.WithContext(e.Request.Context()).FindRecordById(collection, recordId, ruleFunc)
 		if err != nil || record == nil {
 			return e.NotFoundError("", err)
 		}
@@ -511,7 +681,7 @@ func recordDelete(optFinalizer func(data any) error) func(e *core.RequestEvent)
 		event.Record = record
 
 		hookErr := e.App.OnRecordDeleteRequest().Trigger(event, func(e *core.RecordRequestEvent) error {
-			if err := e.App.Delete(e.Record); err != nil {
+			if err := e.App.DeleteWithContext(e.Request.Context(), e.Record); err != nil {
 				return firstApiError(err, e.BadRequestError("Failed to delete record. Make sure that the record is not part of a required relation reference.", err))
 			}
 
@@ -547,19 +717,24 @@ func recordDelete(optFinalizer func(data any) error) func(e *core.RequestEvent)
 
 // -------------------------------------------------------------------
 
-func recordDataFromRequest(e *core.RequestEvent, record *core.Record) (map[string]any, error) {
+// recordDataFromRequest returns the resolved record data of the current request
+// and the not downloaded yet remote file field values (see [loadRemoteFiles]).
+func recordDataFromRequest(e *core.RequestEvent, record *core.Record) (map[string]any, map[string]any, error) {
 	info, err := e.RequestInfo()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	// the remote file urls are downloaded separately after the API rule checks
+	body, remoteFiles := extractRemoteFiles(record.Collection(), info.Body, info.HasSuperuserAuth())
+
 	// resolve regular fields
-	result := record.ReplaceModifiers(info.Body)
+	result := record.ReplaceModifiers(body)
 
 	// resolve uploaded files
 	uploadedFiles, err := extractUploadedFiles(e, record.Collection(), "")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(uploadedFiles) > 0 {
 		for k, files := range uploadedFiles {
@@ -567,11 +742,11 @@ func recordDataFromRequest(e *core.RequestEvent, record *core.Record) (map[strin
 
 			// if not remove/prepend/append -> merge with the submitted
 			// info.Body values to prevent accidental old files deletion
-			if info.Body[k] != nil &&
+			if body[k] != nil &&
 				!strings.HasPrefix(k, "+") &&
 				!strings.HasSuffix(k, "+") &&
 				!strings.HasSuffix(k, "-") {
-				existing := list.ToUniqueStringSlice(info.Body[k])
+				existing := list.ToUniqueStringSlice(body[k])
 				for _, name := range existing {
 					uploaded = append(uploaded, name)
 				}
@@ -603,7 +778,73 @@ func recordDataFromRequest(e *core.RequestEvent, record *core.Record) (map[strin
 		}
 	}
 
-	return result, nil
+	if record.IsNew() {
+		if err := resolveDefaultValueFields(info, record, result); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	resolveAuthRelationFields(info, record, result)
+
+	return result, remoteFiles, nil
+}
+
+// resolveDefaultValueFields sets the not submitted record fields
+// with DefaultValue expression to their resolved default value.
+func resolveDefaultValueFields(info *core.RequestInfo, record *core.Record, data map[string]any) error {
+	for _, f := range record.Collection().Fields {
+		getter, ok := f.(core.DefaultValueGetter)
+		if !ok || getter.GetDefaultValue() == "" {
+			continue
+		}
+
+		name := f.GetName()
+
+		if _, ok := data[name]; ok {
+			continue // explicitly submitted
+		}
+
+		value, err := core.ResolveFieldDefaultValue(getter.GetDefaultValue(), info)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %q default value: %w", name, err)
+		}
+
+		if value != nil {
+			data[name] = value
+		}
+	}
+
+	return nil
+}
+
+// resolveAuthRelationFields sets the record relation fields with enabled
+// AuthOnCreate/AuthOnUpdate option to the request auth record id.
+//
+// Superusers can still explicitly submit a different field value.
+func resolveAuthRelationFields(info *core.RequestInfo, record *core.Record, data map[string]any) {
+	isNew := record.IsNew()
+	isSuperuser := info.HasSuperuserAuth()
+
+	for _, f := range record.Collection().Fields {
+		rel, ok := f.(*core.RelationField)
+		if !ok || (!rel.AuthOnCreate && !rel.AuthOnUpdate) {
+			continue
+		}
+
+		name := rel.GetName()
+
+		if _, ok := data[name]; ok && isSuperuser {
+			continue
+		}
+
+		if (isNew || rel.AuthOnUpdate) && info.Auth != nil && info.Auth.Collection().Id == rel.CollectionId {
+			data[name] = info.Auth.Id
+			continue
+		}
+
+		// ignore the submitted value
+		delete(data, name)
+	}
 }
 
 func extractUploadedFiles(re *core.RequestEvent, collection *core.Collection, prefix string) (map[string][]*filesystem.File, error) {
@@ -644,3 +885,184 @@ func extractUploadedFiles(re *core.RequestEvent, collection *core.Collection, pr
 
 	return result, nil
 }
+
+// remoteFileURLKey is the name of the submitted file object key
+// that holds the url of a remote file to download, e.g.:
+//
+//	{"avatar": {"url": "https://example.com/avatar.png"}}
+const remoteFileURLKey = "url"
+
+// extractRemoteFiles returns a shallow copy of the provided request body
+// data without the file fields {"url": "..."} values and a separate map with
+// the extracted values.
+//
+// The hidden file fields are skipped if allowHidden is not set.
+func extractRemoteFiles(collection *core.Collection, data map[string]any, allowHidden bool) (map[string]any, map[string]any) {
+	var body, remoteFiles map[string]any
+
+	for _, field := range collection.Fields {
+		fileField, ok := field.(*core.FileField)
+		if !ok || (fileField.Hidden && !allowHidden) {
+			continue
+		}
+
+		baseKey := fileField.GetName()
+
+		keys := []string{
+			baseKey,
+			// prepend and append modifiers
+			"+" + baseKey,
+			baseKey + "+",
+		}
+
+		for _, k := range keys {
+			raw, ok := data[k]
+			if !ok || !hasRemoteFileValue(raw) {
+				continue
+			}
+
+			if body == nil {
+				body = maps.Clone(data)
+				remoteFiles = map[string]any{}
+			}
+			delete(body, k)
+			remoteFiles[k] = raw
+		}
+	}
+
+	if body == nil {
+		return data, nil // no remote files
+	}
+
+	return body, remoteFiles
+}
+
+// loadRemoteFiles downloads the provided remote file field values
+// (see [extractRemoteFiles]) and loads them into the upsert form.
+//
+// It is expected to be called only after the API rule checks and never for
+// dry run requests so that no outgoing requests are made for unauthorized
+// or not persisted submissions.
+func loadRemoteFiles(e *core.RequestEvent, form *forms.RecordUpsert, record *core.Record, remoteFiles map[string]any) error {
+	if len(remoteFiles) == 0 {
+		return nil
+	}
+
+	resolved, err := resolveRemoteFiles(e, record.Collection(), remoteFiles)
+	if err != nil {
+		return err
+	}
+
+	form.Load(record.ReplaceModifiers(resolved))
+
+	return nil
+}
+
+// resolveRemoteFiles returns a shallow copy of the provided request body
+// data with the file fields {"url": "..."} values replaced with the
+// downloaded remote files.
+//
+// The remote files are fetched with the related field max size limit
+// and are not allowed to point to loopback, private or other non-public addresses.
+func resolveRemoteFiles(re *core.RequestEvent, collection *core.Collection, data map[string]any) (map[string]any, error) {
+	var result map[string]any
+
+	for _, field := range collection.Fields {
+		fileField, ok := field.(*core.FileField)
+		if !ok {
+			continue
+		}
+
+		baseKey := fileField.GetName()
+
+		keys := []string{
+			baseKey,
+			// prepend and append modifiers
+			"+" + baseKey,
+			baseKey + "+",
+		}
+
+		for _, k := range keys {
+			raw, ok := data[k]
+			if !ok || !hasRemoteFileValue(raw) {
+				continue
+			}
+
+			resolved, err := downloadRemoteFiles(re, fileField, raw)
+			if err != nil {
+				return nil, re.BadRequestError("Failed to fetch the remote file.", validation.Errors{
+					k: validation.NewError("validation_invalid_remote_file", "Failed to fetch the remote file."),
+				})
+			}
+
+			if result == nil {
+				result = maps.Clone(data)
+			}
+			result[k] = resolved
+		}
+	}
+
+	if result == nil {
+		return data, nil // nothing to resolve
+	}
+
+	return result, nil
+}
+
+func hasRemoteFileValue(raw any) bool {
+	switch v := raw.(type) {
+	case map[string]any:
+		return true
+	case []any:
+		for _, item := range v {
+			if _, ok := item.(map[string]any); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func downloadRemoteFiles(re *core.RequestEvent, field *core.FileField, raw any) ([]any, error) {
+	items, ok := raw.([]any)
+	if !ok {
+		items = []any{raw}
+	}
+
+	maxSize := field.MaxSize
+	if maxSize <= 0 {
+		maxSize = core.DefaultFileFieldMaxSize
+	}
+
+	result := make([]any, 0, len(items))
+
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			result = append(result, item)
+			continue
+		}
+
+		rawURL, _ := obj[remoteFileURLKey].(string)
+		if rawURL == "" {
+			return nil, errors.New("missing or invalid remote file url")
+		}
+
+		file, err := filesystem.NewFileFromRemoteURL(re.Request.Context(), rawURL, filesystem.RemoteFileOptions{
+			MaxSize: maxSize,
+		})
+		if err != nil {
+			re.App.Logger().Debug(
+				"Failed to fetch remote record file",
+				slog.String("field", field.GetName()),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+
+		result = append(result, file)
+	}
+
+	return result, nil
+}
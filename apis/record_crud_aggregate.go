@@ -0,0 +1,301 @@
+package apis
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+const (
+	// GroupByQueryParam is the name of the records aggregate query parameter
+	// with the comma separated list of fields to group the results by.
+	GroupByQueryParam = "groupBy"
+
+	// SumQueryParam, AvgQueryParam, MinQueryParam and MaxQueryParam are the names
+	// of the records aggregate query parameters with the comma separated list
+	// of fields to apply the related aggregate function to.
+	SumQueryParam = "sum"
+	AvgQueryParam = "avg"
+	MinQueryParam = "min"
+	MaxQueryParam = "max"
+)
+
+// maxAggregateGroups is the max number of returned aggregate groups.
+const maxAggregateGroups = 1000
+
+// maxAggregateFields is the max number of fields per aggregate query parameter.
+const maxAggregateFields = 10
+
+var (
+	aggregateGroupByFieldTypes = []string{
+		core.FieldTypeText,
+		core.FieldTypeNumber,
+		core.FieldTypeBool,
+		core.FieldTypeURL,
+		core.FieldTypeSelect,
+		core.FieldTypeRelation,
+		core.FieldTypeDate,
+		core.FieldTypeAutodate,
+	}
+
+	aggregateNumberFieldTypes = []string{
+		core.FieldTypeNumber,
+	}
+
+	aggregateMinMaxFieldTypes = []string{
+		core.FieldTypeNumber,
+		core.FieldTypeDate,
+		core.FieldTypeAutodate,
+	}
+)
+
+type recordsAggregateItem struct {
+	Group map[string]any `json:"group"`
+	Count int64          `json:"count"`
+	Sum   map[string]any `json:"sum,omitempty"`
+	Avg   map[string]any `json:"avg,omitempty"`
+	Min   map[string]any `json:"min,omitempty"`
+	Max   map[string]any `json:"max,omitempty"`
+}
+
+type recordsAggregateResult struct {
+	Items []*recordsAggregateItem `json:"items"`
+}
+
+// recordsAggregate returns the count, sum, avg, min and max aggregates
+// of the collection records matching the List API rule and the optional
+// "filter" query parameter, optionally grouped by one or more fields.
+//
+// The email and the hidden fields cannot be used for grouping because
+// their values are returned as group keys.
+//
+// Example:
+//
+//	GET /api/collections/orders/aggregate?groupBy=status&sum=total&avg=total&max=created
+func recordsAggregate(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	err = checkCollectionRateLimit(e, collection, "list")
+	if err != nil {
+		return err
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	searchProvider, _, err := newRecordsSearchProvider(e, collection, requestInfo)
+	if err != nil {
+		return err
+	}
+
+	query := e.Request.URL.Query()
+
+	// hidden fields are aggregatable only by superusers
+	allowHidden := requestInfo.HasSuperuserAuth()
+
+	// the grouped values are returned as they are so the hidden
+	// fields are not allowed regardless of the request auth
+	groupBy, err := aggregateFields(collection, query.Get(GroupByQueryParam), aggregateGroupByFieldTypes, false)
+	if err != nil {
+		return e.BadRequestError("Invalid "+GroupByQueryParam+" fields.", err)
+	}
+
+	fns := []struct {
+		name   string
+		param  string
+		types  []string
+		fields []core.Field
+	}{
+		{"SUM", SumQueryParam, aggregateNumberFieldTypes, nil},
+		{"AVG", AvgQueryParam, aggregateNumberFieldTypes, nil},
+		{"MIN", MinQueryParam, aggregateMinMaxFieldTypes, nil},
+		{"MAX", MaxQueryParam, aggregateMinMaxFieldTypes, nil},
+	}
+	for i, fn := range fns {
+		fns[i].fields, err = aggregateFields(collection, query.Get(fn.param), fn.types, allowHidden)
+		if err != nil {
+			return e.BadRequestError("Invalid "+fn.param+" fields.", err)
+		}
+	}
+
+	filter := query.Get(search.FilterQueryParam)
+	if filter != "" {
+		searchProvider.AddFilter(search.FilterData(filter))
+	}
+
+	// the filtered records ids are selected in a subquery to avoid
+	// aggregating duplicated rows because of the resolver joins
+	_, idsQuery, err := searchProvider.BuildQueries()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+	idsQuery.Select("[[" + collection.Name + "." + core.FieldNameId + "]]")
+	builtIdsQuery := idsQuery.Build()
+
+	selects := make([]string, 0, len(groupBy)+5)
+	groupColumns := make([]string, len(groupBy))
+	for i, f := range groupBy {
+		groupColumns[i] = "[[" + f.GetName() + "]]"
+		selects = append(selects, groupColumns[i])
+	}
+	selects = append(selects, "COUNT(*)")
+	for _, fn := range fns {
+		for _, f := range fn.fields {
+			selects = append(selects, fn.name+"([["+f.GetName()+"]])")
+		}
+	}
+
+	var sql strings.Builder
+	sql.WriteString("SELECT ")
+	sql.WriteString(strings.Join(selects, ", "))
+	sql.WriteString(" FROM {{" + collection.Name + "}}")
+	sql.WriteString(" WHERE [[" + core.FieldNameId + "]] IN (" + builtIdsQuery.SQL() + ")")
+	if len(groupColumns) > 0 {
+		sql.WriteString(" GROUP BY " + strings.Join(groupColumns, ", "))
+		sql.WriteString(" ORDER BY " + strings.Join(groupColumns, ", "))
+	}
+	sql.WriteString(fmt.Sprintf(" LIMIT %d", maxAggregateGroups))
+
+	rows, err := e.App.DB().NewQuery(sql.String()).
+		Bind(builtIdsQuery.Params()).
+		WithContext(e.Request.Context()).
+		Rows()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to aggregate the records.", err))
+	}
+	defer rows.Close()
+
+	dummy := core.NewRecord(collection)
+
+	result := &recordsAggregateResult{Items: []*recordsAggregateItem{}}
+
+	for rows.Next() {
+		values := make([]any, len(selects))
+		pointers := make([]any, len(selects))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return firstApiError(err, e.BadRequestError("Failed to aggregate the records.", err))
+		}
+
+		item := &recordsAggregateItem{Group: map[string]any{}}
+
+		pos := 0
+		for _, f := range groupBy {
+			item.Group[f.GetName()] = aggregateValue(dummy, f, values[pos])
+			pos++
+		}
+
+		item.Count, _ = values[pos].(int64)
+		pos++
+
+		for _, fn := range fns {
+			if len(fn.fields) == 0 {
+				continue
+			}
+
+			m := make(map[string]any, len(fn.fields))
+			for _, f := range fn.fields {
+				if fn.name == "SUM" || fn.name == "AVG" {
+					m[f.GetName()] = values[pos] // keep the raw numeric value
+				} else {
+					m[f.GetName()] = aggregateValue(dummy, f, values[pos])
+				}
+				pos++
+			}
+
+			switch fn.name {
+			case "SUM":
+				item.Sum = m
+			case "AVG":
+				item.Avg = m
+			case "MIN":
+				item.Min = m
+			case "MAX":
+				item.Max = m
+			}
+		}
+
+		result.Items = append(result.Items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to aggregate the records.", err))
+	}
+
+	// same as in the records list
+	if !requestInfo.HasSuperuserAuth() &&
+		(collection.ListRule != nil && *collection.ListRule != "") &&
+		filter != "" &&
+		len(result.Items) == 0 &&
+		checkRateLimit(e, "@pb_list_timing_check_"+collection.Id, listTimingRateLimitRule) != nil {
+		e.App.Logger().Debug("Randomized throttle because of too many failed searches", "collectionId", collection.Id)
+		randomizedThrottle(100)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// aggregateFields parses the comma separated field names and
+// returns their related collection fields.
+//
+// Returns an error if some of the fields is missing, hidden
+// (if allowHidden is not set) or its type is not one of allowedTypes.
+func aggregateFields(collection *core.Collection, rawNames string, allowedTypes []string, allowHidden bool) ([]core.Field, error) {
+	names := strings.Split(rawNames, ",")
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+	}
+	names = list.NonzeroUniques(names)
+
+	if len(names) > maxAggregateFields {
+		return nil, fmt.Errorf("max %d fields are allowed", maxAggregateFields)
+	}
+
+	fields := make([]core.Field, 0, len(names))
+
+	for _, name := range names {
+		field := collection.Fields.GetByName(name)
+		if field == nil || (field.GetHidden() && !allowHidden) {
+			return nil, fmt.Errorf("missing or invalid field %q", name)
+		}
+
+		if !slices.Contains(allowedTypes, field.Type()) {
+			return nil, fmt.Errorf("field %q type is not supported", name)
+		}
+
+		if m, ok := field.(core.MultiValuer); ok && m.IsMultiple() {
+			return nil, fmt.Errorf("multiple values field %q is not supported", name)
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// aggregateValue normalizes the raw db value of the provided field.
+func aggregateValue(record *core.Record, field core.Field, raw any) any {
+	if raw == nil {
+		return nil
+	}
+
+	v, err := field.PrepareValue(record, raw)
+	if err != nil {
+		return raw
+	}
+
+	return v
+}
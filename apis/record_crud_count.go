@@ -0,0 +1,102 @@
+package apis
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// EstimateQueryParam is the name of the records count query parameter
+// that enables the fast estimated count mode.
+const EstimateQueryParam = "estimate"
+
+type recordsCountResult struct {
+	Count int `json:"count"`
+
+	// Estimated indicates that Count is an upper bound
+	// and not the exact number of the matching records.
+	Estimated bool `json:"estimated"`
+}
+
+// recordsCount returns the total number of the collection records
+// matching the List API rule and the optional "filter" query parameter.
+//
+// The request goes through the same access checks and query
+// modifiers as the records list so that both always match.
+//
+// With a truthy EstimateQueryParam and no filter to apply, the count is
+// estimated from the last table rowid instead of scanning the whole table.
+// The estimate is only an upper bound of the exact count (the rowids of the
+// deleted records are not reused) and it is not available for view collections
+// or searched records (aka. exact count fallback).
+func recordsCount(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	err = checkCollectionRateLimit(e, collection, "list")
+	if err != nil {
+		return err
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	searchProvider, _, err := newRecordsSearchProvider(e, collection, requestInfo)
+	if err != nil {
+		return err
+	}
+
+	query := e.Request.URL.Query()
+
+	filter := query.Get(search.FilterQueryParam)
+
+	hasRuleFilter := !requestInfo.HasSuperuserAuth() && collection.ListRule != nil && *collection.ListRule != ""
+
+	estimate, _ := strconv.ParseBool(query.Get(EstimateQueryParam))
+	if estimate && filter == "" && !hasRuleFilter && !collection.IsView() && query.Get(SearchQueryParam) == "" {
+		result := &recordsCountResult{Estimated: true}
+
+		err = e.App.DB().Select("COALESCE(MAX([[_rowid_]]), 0)").
+			From(collection.Name).
+			WithContext(e.Request.Context()).
+			Row(&result.Count)
+		if err != nil {
+			return e.BadRequestError("Failed to estimate the records count.", err)
+		}
+
+		return e.JSON(http.StatusOK, result)
+	}
+
+	if filter != "" {
+		searchProvider.AddFilter(search.FilterData(filter))
+	}
+
+	_, countQuery, err := searchProvider.BuildQueries()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	result := &recordsCountResult{}
+
+	err = countQuery.Row(&result.Count)
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	// same as in the records list
+	if hasRuleFilter &&
+		filter != "" &&
+		result.Count == 0 &&
+		checkRateLimit(e, "@pb_list_timing_check_"+collection.Id, listTimingRateLimitRule) != nil {
+		e.App.Logger().Debug("Randomized throttle because of too many failed searches", "collectionId", collection.Id)
+		randomizedThrottle(100)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,129 @@
+package apis
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// DebugQueryParam is the name of the records list query parameter
+// that returns the generated SQL queries instead of the found records.
+const DebugQueryParam = "debugQuery"
+
+type debugQueryResult struct {
+	ListRule  *debugQueryClause `json:"listRule"`
+	Params    dbx.Params        `json:"params"`
+	SQL       string            `json:"sql"`
+	CountSQL  string            `json:"countSql"`
+	QueryPlan []debugQueryPlan  `json:"queryPlan"`
+	ExecTime  float64           `json:"execTime"` // in ms
+}
+
+type debugQueryClause struct {
+	Params dbx.Params `json:"params"`
+	Rule   string     `json:"rule"`
+	SQL    string     `json:"sql"`
+}
+
+type debugQueryPlan struct {
+	Detail string `json:"detail"`
+	Id     int    `json:"id"`
+	Parent int    `json:"parent"`
+}
+
+// isDebugQuery reports whether the request has a truthy DebugQueryParam.
+func isDebugQuery(e *core.RequestEvent) bool {
+	v, _ := strconv.ParseBool(e.Request.URL.Query().Get(DebugQueryParam))
+
+	return v
+}
+
+// recordsListDebug returns the generated SQL of the records list search provider,
+// the collection List API rule clause and the query plan of the models query.
+//
+// Note that the List API rule is not part of the generated SQL because
+// the debug mode is available only for superusers.
+func recordsListDebug(
+	e *core.RequestEvent,
+	collection *core.Collection,
+	requestInfo *core.RequestInfo,
+	searchProvider *search.Provider,
+) error {
+	if err := searchProvider.Parse(e.Request.URL.Query().Encode()); err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	modelsQuery, countQuery, err := searchProvider.BuildQueries()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	builtModelsQuery := modelsQuery.Build()
+
+	result := &debugQueryResult{
+		SQL:       builtModelsQuery.SQL(),
+		Params:    builtModelsQuery.Params(),
+		CountSQL:  countQuery.Build().SQL(),
+		QueryPlan: []debugQueryPlan{},
+	}
+
+	if collection.ListRule != nil && *collection.ListRule != "" {
+		clause, err := debugRuleClause(e.App, collection, requestInfo, *collection.ListRule)
+		if err != nil {
+			return firstApiError(err, e.BadRequestError("Failed to build the collection List API rule.", err))
+		}
+		result.ListRule = clause
+	}
+
+	planRows := []dbx.NullStringMap{}
+	err = e.App.DB().NewQuery("EXPLAIN QUERY PLAN " + builtModelsQuery.SQL()).
+		Bind(builtModelsQuery.Params()).
+		All(&planRows)
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to explain the query.", err))
+	}
+	for _, row := range planRows {
+		id, _ := strconv.Atoi(row["id"].String)
+		parent, _ := strconv.Atoi(row["parent"].String)
+		result.QueryPlan = append(result.QueryPlan, debugQueryPlan{
+			Id:     id,
+			Parent: parent,
+			Detail: row["detail"].String,
+		})
+	}
+
+	start := time.Now()
+	if err := modelsQuery.All(&[]dbx.NullStringMap{}); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to execute the query.", err))
+	}
+	result.ExecTime = float64(time.Since(start).Microseconds()) / 1000
+
+	return e.JSON(http.StatusOK, result)
+}
+
+func debugRuleClause(app core.App, collection *core.Collection, requestInfo *core.RequestInfo, rule string) (*debugQueryClause, error) {
+	resolver := core.NewRecordFieldResolver(app, collection, requestInfo, true)
+
+	expr, err := search.FilterData(rule).BuildExpr(resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	// wrap in a query to include the resolver joins
+	q := app.RecordQuery(collection).Select("id").AndWhere(expr)
+	if err := resolver.UpdateQuery(q); err != nil {
+		return nil, err
+	}
+
+	built := q.Build()
+
+	return &debugQueryClause{
+		Rule:   rule,
+		SQL:    built.SQL(),
+		Params: built.Params(),
+	}, nil
+}
@@ -0,0 +1,134 @@
+package apis
+
+import (
+	"iter"
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// StreamContentType is the records list Accept header content type
+// that streams the found records as newline delimited JSON.
+const StreamContentType = "application/x-ndjson"
+
+// streamBatchSize is the number of records that are loaded, enriched
+// and sent to the client at once by the records list stream.
+const streamBatchSize = 500
+
+// isStreamRequest reports whether the request prefers the StreamContentType.
+func isStreamRequest(e *core.RequestEvent) bool {
+	accept := e.Request.Header.Get("Accept")
+	if accept == "" {
+		return false
+	}
+
+	return router.NegotiateContentType(accept, "application/json", StreamContentType) == StreamContentType
+}
+
+// recordsListStream writes all records matching the search provider
+// filter and sort as newline delimited JSON (one record per line).
+//
+// The page and perPage query parameters are ignored and the records are
+// loaded in batches of streamBatchSize without the total count query.
+// The OnRecordsListRequest hook is triggered for each batch.
+//
+// Note that once the first batch is sent the response status cannot
+// be changed and any following error only interrupts the stream.
+func recordsListStream(
+	e *core.RequestEvent,
+	collection *core.Collection,
+	searchProvider *search.Provider,
+) error {
+	if err := searchProvider.Parse(e.Request.URL.Query().Encode()); err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	// ensure stable order between the batches
+	searchProvider.AddSort(search.SortField{Name: "id", Direction: search.SortAsc})
+
+	modelsQuery, _, err := searchProvider.BuildQueries()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	// loadBatch returns the enriched records of the specified batch
+	// and whether there are more records to load.
+	loadBatch := func(page int) ([]*core.Record, bool, error) {
+		records := []*core.Record{}
+
+		q := *modelsQuery // shallow clone
+		err := q.Limit(streamBatchSize).Offset(int64(page-1) * streamBatchSize).All(&records)
+		if err != nil {
+			return nil, false, err
+		}
+
+		hasMore := len(records) == streamBatchSize
+
+		event := new(core.RecordsListRequestEvent)
+		event.RequestEvent = e
+		event.Collection = collection
+		event.Records = records
+		event.Result = &search.Result{
+			Page:       page,
+			PerPage:    streamBatchSize,
+			TotalItems: -1,
+			TotalPages: -1,
+			Items:      records,
+		}
+
+		var result []*core.Record
+
+		err = e.App.OnRecordsListRequest().Trigger(event, func(e *core.RecordsListRequestEvent) error {
+			if err := EnrichRecords(e.RequestEvent, e.Records); err != nil {
+				return err
+			}
+
+			result = e.Records
+
+			return nil
+		})
+
+		return result, hasMore, err
+	}
+
+	// load the first batch before sending the response headers
+	// so that the query errors could be still returned as regular error response
+	firstBatch, hasMore, err := loadBatch(1)
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	items := iter.Seq2[any, error](func(yield func(any, error) bool) {
+		batch := firstBatch
+
+		for page := 1; ; page++ {
+			if page > 1 {
+				var err error
+				batch, hasMore, err = loadBatch(page)
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+			}
+
+			for _, record := range batch {
+				if !yield(record, nil) {
+					return
+				}
+			}
+
+			if !hasMore {
+				return
+			}
+		}
+	})
+
+	err = e.StreamJSONLines(http.StatusOK, items)
+	if err != nil {
+		e.App.Logger().Debug("Records list stream interrupted", "collectionId", collection.Id, "error", err)
+	}
+
+	return nil
+}
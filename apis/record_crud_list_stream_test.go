@@ -0,0 +1,191 @@
+package apis_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRecordsListStream(t *testing.T) {
+	t.Parallel()
+
+	streamHeaders := map[string]string{"Accept": "application/x-ndjson"}
+
+	checkLines := func(expectedTitles ...string) func(t testing.TB, app *tests.TestApp, res *http.Response) {
+		return func(t testing.TB, app *tests.TestApp, res *http.Response) {
+			if v := res.Header.Get("Content-Type"); v != "application/x-ndjson" {
+				t.Fatalf("Expected application/x-ndjson content type, got %q", v)
+			}
+
+			titles := []string{}
+
+			scanner := bufio.NewScanner(res.Body)
+			for scanner.Scan() {
+				item := map[string]any{}
+				if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+					t.Fatalf("Failed to unmarshal line %q: %v", scanner.Text(), err)
+				}
+				titles = append(titles, fmt.Sprint(item["title"]))
+			}
+
+			if !slices.Equal(titles, expectedTitles) {
+				t.Fatalf("Expected titles\n%v\ngot\n%v", expectedTitles, titles)
+			}
+		}
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest with superusers only list rule",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/demo1/records",
+			Headers:         streamHeaders,
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "invalid filter",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/demo2/records?filter=missing=1",
+			Headers:         streamHeaders,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:           "json preferred over ndjson",
+			Method:         http.MethodGet,
+			URL:            "/api/collections/demo2/records",
+			Headers:        map[string]string{"Accept": "application/json, application/x-ndjson;q=0.5"},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"items":[{`,
+				`"totalItems":3`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                    0,
+				"OnRecordsListRequest": 1,
+				"OnRecordEnrich":       3,
+			},
+		},
+		{
+			Name:           "guest with empty list rule",
+			Method:         http.MethodGet,
+			URL:            "/api/collections/demo2/records?perPage=1",
+			Headers:        streamHeaders,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"id":"llvuca81nly1qls"`,
+				`"id":"achvryl401bhse3"`,
+				`"id":"0yxhwia2amd8gec"`,
+			},
+			NotExpectedContent: []string{
+				`"items"`,
+				`"totalItems"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                    0,
+				"OnRecordsListRequest": 1,
+				"OnRecordEnrich":       3,
+			},
+		},
+		{
+			Name:           "guest with filter and sort",
+			Method:         http.MethodGet,
+			URL:            "/api/collections/demo2/records?filter=title!='test1'&sort=-title",
+			Headers:        streamHeaders,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"id":"achvryl401bhse3"`,
+				`"id":"0yxhwia2amd8gec"`,
+			},
+			NotExpectedContent: []string{
+				`"id":"llvuca81nly1qls"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                    0,
+				"OnRecordsListRequest": 1,
+				"OnRecordEnrich":       2,
+			},
+			AfterTestFunc: checkLines("test3", "test2"),
+		},
+		{
+			Name:           "no matching records",
+			Method:         http.MethodGet,
+			URL:            "/api/collections/demo2/records?filter=title='missing'",
+			Headers:        streamHeaders,
+			ExpectedStatus: 200,
+			ExpectedEvents: map[string]int{
+				"*":                    0,
+				"OnRecordsListRequest": 1,
+			},
+			AfterTestFunc: checkLines(),
+		},
+		{
+			Name:    "multiple batches",
+			Method:  http.MethodGet,
+			URL:     "/api/collections/demo2/records?filter=title~'batch'&sort=title",
+			Headers: streamHeaders,
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				for i := 0; i < 600; i++ {
+					_, err := app.DB().Insert("demo2", dbx.Params{
+						"id":    fmt.Sprintf("batch%010d", i),
+						"title": fmt.Sprintf("batch%03d", i),
+					}).Execute()
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"title":"batch599"`},
+			ExpectedEvents: map[string]int{
+				"*":                    0,
+				"OnRecordsListRequest": 2,
+				"OnRecordEnrich":       600,
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				expected := make([]string, 600)
+				for i := range expected {
+					expected[i] = fmt.Sprintf("batch%03d", i)
+				}
+				checkLines(expected...)(t, app, res)
+			},
+		},
+		{
+			Name:    "OnRecordsListRequest modifying the batch records",
+			Method:  http.MethodGet,
+			URL:     "/api/collections/demo2/records",
+			Headers: streamHeaders,
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.OnRecordsListRequest("demo2").BindFunc(func(e *core.RecordsListRequestEvent) error {
+					e.Records = e.Records[:1]
+					return e.Next()
+				})
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"id":"0yxhwia2amd8gec"`},
+			NotExpectedContent: []string{
+				`"id":"llvuca81nly1qls"`,
+				`"id":"achvryl401bhse3"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                    0,
+				"OnRecordsListRequest": 1,
+				"OnRecordEnrich":       1,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
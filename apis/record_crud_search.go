@@ -0,0 +1,99 @@
+package apis
+
+import (
+	"strconv"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+const (
+	// SearchQueryParam is the name of the records list query parameter
+	// with the full-text search term.
+	SearchQueryParam = "search"
+
+	// SearchSnippetQueryParam is the name of the records list query parameter
+	// that enables the highlighted search snippet for each returned record.
+	SearchSnippetQueryParam = "snippet"
+)
+
+// applyRecordsSearch applies the full-text SearchQueryParam term (if any)
+// to the provided collection records query.
+//
+// If no explicit sort is specified, the records are ordered by their relevance.
+//
+// Returns the search columns that are accessible for the current request
+// (hidden fields are searchable only by superusers).
+func applyRecordsSearch(e *core.RequestEvent, collection *core.Collection, requestInfo *core.RequestInfo, query *dbx.SelectQuery) ([]string, error) {
+	term := e.Request.URL.Query().Get(SearchQueryParam)
+	if term == "" {
+		return nil, nil
+	}
+
+	if !collection.IsSearchable() {
+		return nil, e.BadRequestError("The collection doesn't support full-text search.", nil)
+	}
+
+	columns := make([]string, 0, len(collection.SearchFields))
+	for _, name := range collection.SearchFields {
+		field := collection.Fields.GetByName(name)
+		if field != nil && (!field.GetHidden() || requestInfo.HasSuperuserAuth()) {
+			columns = append(columns, name)
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, e.BadRequestError("The collection doesn't support full-text search.", nil)
+	}
+
+	orderByRank := e.Request.URL.Query().Get(search.SortQueryParam) == ""
+
+	err := core.ApplyRecordSearch(query, collection, term, orderByRank, columns...)
+	if err != nil {
+		return nil, e.BadRequestError("Invalid search term.", err)
+	}
+
+	return columns, nil
+}
+
+// loadRecordsSearchSnippets assigns the highlighted full-text search
+// snippet as custom data to each of the provided records
+// (only if SearchSnippetQueryParam is truthy).
+func loadRecordsSearchSnippets(e *core.RequestEvent, collection *core.Collection, columns []string, records []*core.Record) error {
+	if len(columns) == 0 || len(records) == 0 {
+		return nil
+	}
+
+	// skip to avoid overwriting a regular field with the same name
+	if collection.Fields.GetByName(core.RecordSearchSnippetKey) != nil {
+		return nil
+	}
+
+	if ok, _ := strconv.ParseBool(e.Request.URL.Query().Get(SearchSnippetQueryParam)); !ok {
+		return nil
+	}
+
+	ids := make([]string, len(records))
+	for i, r := range records {
+		ids[i] = r.Id
+	}
+
+	snippets, err := core.FindRecordSearchSnippets(
+		e.App,
+		collection,
+		e.Request.URL.Query().Get(SearchQueryParam),
+		ids,
+		columns...,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		r.WithCustomData(true)
+		r.SetRaw(core.RecordSearchSnippetKey, snippets[r.Id])
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package apis
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/spf13/cast"
+)
+
+// UpsertByQueryParam is the name of the records upsert query parameter
+// with the name of the unique field used for finding the existing record
+// (default to "id").
+const UpsertByQueryParam = "upsertBy"
+
+// upsertExcludedAuthFields lists the auth collection system fields
+// that cannot be used as upsert key.
+var upsertExcludedAuthFields = []string{
+	core.FieldNameEmail,
+	core.FieldNameEmailVisibility,
+	core.FieldNameVerified,
+	core.FieldNameTokenKey,
+	core.FieldNamePassword,
+}
+
+// recordUpsert updates the existing record matching the submitted
+// unique key field value or creates a new one if there is no such record.
+//
+// The regular create or update API rule is evaluated depending on
+// whether the record exists.
+//
+// The lookup is not wrapped in a transaction with the write because the
+// key field is required to be unique and in case of concurrent requests
+// with the same new key value only one of them will be able to create
+// the record (the others will fail with a validation error).
+//
+// The auth collection system fields (email, tokenKey, etc.) are not
+// allowed as upsert key to prevent using the endpoint as existence oracle.
+//
+// Example:
+//
+//	PUT /api/collections/products/records?upsertBy=sku
+//	{"sku": "ABC-1", "title": "..."}
+func recordUpsert(optFinalizer func(data any) error) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+		if err != nil || collection == nil {
+			return e.NotFoundError("Missing collection context.", err)
+		}
+
+		if collection.IsView() {
+			return e.BadRequestError("Unsupported collection type.", nil)
+		}
+
+		requestInfo, err := e.RequestInfo()
+		if err != nil {
+			return firstApiError(err, e.BadRequestError("", err))
+		}
+
+		keyField := core.FieldNameId
+		if v := e.Request.URL.Query().Get(UpsertByQueryParam); v != "" {
+			keyField = v
+		}
+
+		if keyField != core.FieldNameId {
+			field := collection.Fields.GetByName(keyField)
+			if field == nil ||
+				(collection.IsAuth() && slices.Contains(upsertExcludedAuthFields, keyField)) ||
+				(field.GetHidden() && !requestInfo.HasSuperuserAuth()) ||
+				!dbutils.HasSingleColumnUniqueIndex(keyField, collection.Indexes) {
+				return e.BadRequestError("The "+UpsertByQueryParam+" field must be an existing field with single column unique index.", nil)
+			}
+		}
+
+		keyValue, err := cast.ToStringE(requestInfo.Body[keyField])
+		if err != nil {
+			return e.BadRequestError("Invalid "+keyField+" value.", err)
+		}
+
+		if keyValue != "" {
+			existing, err := e.App.FindFirstRecordByData(collection, keyField, keyValue)
+			if err == nil {
+				e.Request.SetPathValue("id", existing.Id)
+				return recordUpdate(optFinalizer)(e)
+			}
+
+			if !errors.Is(err, sql.ErrNoRows) {
+				return firstApiError(err, e.BadRequestError("Failed to find the existing record.", err))
+			}
+		}
+
+		return recordCreate(optFinalizer)(e)
+	}
+}
@@ -0,0 +1,230 @@
+package apis
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+// bindRecordExternalAuthsApi registers the auth record linked
+// external auths (aka. OAuth2 identities) management endpoints
+// and the corresponding handlers.
+func bindRecordExternalAuthsApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/collections/{collection}/records/{id}/external-auths")
+
+	sub.GET("", recordExternalAuthsList).Bind(
+		collectionPathRateLimit("", "listExternalAuths"),
+		RequireSuperuserOrOwnerAuth("id"),
+	)
+	sub.POST("", recordExternalAuthLink).Bind(
+		collectionPathRateLimit("", "linkExternalAuth"),
+		RequireSuperuserOrOwnerAuth("id"),
+	)
+	sub.DELETE("/{provider}", recordExternalAuthUnlink).Bind(
+		collectionPathRateLimit("", "unlinkExternalAuth"),
+		RequireSuperuserOrOwnerAuth("id"),
+	)
+}
+
+func recordExternalAuthsList(e *core.RequestEvent) error {
+	record, err := findExternalAuthsOwner(e)
+	if err != nil {
+		return err
+	}
+
+	externalAuths, err := e.App.FindAllExternalAuthsByRecord(record)
+	if err != nil {
+		return e.InternalServerError("Failed to fetch the linked external auths.", err)
+	}
+
+	return e.JSON(http.StatusOK, externalAuths)
+}
+
+func recordExternalAuthLink(e *core.RequestEvent) error {
+	record, err := findExternalAuthsOwner(e)
+	if err != nil {
+		return err
+	}
+
+	collection := record.Collection()
+
+	if !collection.OAuth2.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow OAuth2 authentication.", nil)
+	}
+
+	form := new(recordExternalAuthLinkForm)
+	form.collection = collection
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+
+	ctx, cancel := context.WithTimeout(e.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	_, authUser, err := oauth2FetchAuthUser(ctx, e, collection, form.Provider, form.Code, form.CodeVerifier, form.RedirectURL)
+	if err != nil {
+		return err
+	}
+
+	existing, err := e.App.FindFirstExternalAuthByExpr(dbx.HashExp{
+		"collectionRef": collection.Id,
+		"provider":      form.Provider,
+		"providerId":    authUser.Id,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return e.InternalServerError("Failed OAuth2 relation check.", err)
+	}
+
+	if existing != nil {
+		if existing.RecordRef() != record.Id {
+			return e.BadRequestError("The OAuth2 account is already linked to another auth record.", nil)
+		}
+
+		// already linked
+		return e.JSON(http.StatusOK, existing)
+	}
+
+	_, err = e.App.FindFirstExternalAuthByExpr(dbx.HashExp{
+		"collectionRef": collection.Id,
+		"recordRef":     record.Id,
+		"provider":      form.Provider,
+	})
+	if err == nil {
+		return e.BadRequestError("The auth record already has a linked account for the specified provider. Unlink it first and try again.", nil)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return e.InternalServerError("Failed OAuth2 relation check.", err)
+	}
+
+	externalAuth := core.NewExternalAuth(e.App)
+	externalAuth.SetCollectionRef(collection.Id)
+	externalAuth.SetRecordRef(record.Id)
+	externalAuth.SetProvider(form.Provider)
+	externalAuth.SetProviderId(authUser.Id)
+
+	if err := e.App.Save(externalAuth); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to link the OAuth2 account.", err))
+	}
+
+	return e.JSON(http.StatusOK, externalAuth)
+}
+
+func recordExternalAuthUnlink(e *core.RequestEvent) error {
+	record, err := findExternalAuthsOwner(e)
+	if err != nil {
+		return err
+	}
+
+	externalAuths, err := e.App.FindAllExternalAuthsByRecord(record)
+	if err != nil {
+		return e.InternalServerError("Failed to fetch the linked external auths.", err)
+	}
+
+	provider := e.Request.PathValue("provider")
+
+	var externalAuth *core.ExternalAuth
+	for _, ea := range externalAuths {
+		if ea.Provider() == provider {
+			externalAuth = ea
+			break
+		}
+	}
+	if externalAuth == nil {
+		return e.NotFoundError("", nil)
+	}
+
+	// prevent locking out the auth record
+	if len(externalAuths) == 1 && !hasNonOAuth2Credential(record) {
+		return e.BadRequestError("The last linked OAuth2 account cannot be unlinked because the auth record doesn't have other sign-in credentials.", nil)
+	}
+
+	if err := e.App.Delete(externalAuth); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to unlink the OAuth2 account.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// findExternalAuthsOwner loads the auth record from the current request path.
+func findExternalAuthsOwner(e *core.RequestEvent) (*core.Record, error) {
+	if e.Get(core.RequestEventKeyAPIKey) != nil {
+		return nil, e.ForbiddenError("The linked external auths cannot be managed with an API key.", nil)
+	}
+
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || !collection.IsAuth() {
+		return nil, e.NotFoundError("Missing or invalid auth collection context.", err)
+	}
+
+	record, err := e.App.FindRecordById(collection, e.Request.PathValue("id"))
+	if err != nil {
+		return nil, e.NotFoundError("", err)
+	}
+
+	return record, nil
+}
+
+// hasNonOAuth2Credential checks whether the auth record
+// could still sign-in without a linked OAuth2 account.
+//
+// Because the OAuth2 created records have a random password, the password
+// is considered usable only if the record has an email that could be
+// used to reset it (the same applies for the email based OTP and magic link).
+func hasNonOAuth2Credential(record *core.Record) bool {
+	if record.Email() == "" {
+		return false
+	}
+
+	collection := record.Collection()
+
+	return collection.PasswordAuth.Enabled || collection.OTP.Enabled || collection.MagicLink.Enabled
+}
+
+// -------------------------------------------------------------------
+
+type recordExternalAuthLinkForm struct {
+	collection *core.Collection
+
+	// The name of the OAuth2 client provider (eg. "google")
+	Provider string `form:"provider" json:"provider"`
+
+	// The authorization code returned from the initial request.
+	Code string `form:"code" json:"code"`
+
+	// The optional PKCE code verifier as part of the code_challenge sent with the initial request.
+	CodeVerifier string `form:"codeVerifier" json:"codeVerifier"`
+
+	// The redirect url sent with the initial request.
+	RedirectURL string `form:"redirectURL" json:"redirectURL"`
+}
+
+func (form *recordExternalAuthLinkForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Provider, validation.Required, validation.Length(0, 100), validation.By(form.checkProviderName)),
+		validation.Field(&form.Code, validation.Required),
+		validation.Field(&form.RedirectURL, validation.Required),
+	)
+}
+
+func (form *recordExternalAuthLinkForm) checkProviderName(value any) error {
+	name, _ := value.(string)
+
+	_, ok := form.collection.OAuth2.GetProviderConfig(name)
+	if !ok {
+		return validation.NewError("validation_invalid_provider", "Provider with name {{.name}} is missing or is not enabled.").
+			SetParams(map[string]any{"name": name})
+	}
+
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/pocketbase/dbx"
@@ -31,12 +33,31 @@ var ErrMFA = errors.New("mfa required")
 // Set authMethod to empty string if you want to ignore the MFA checks and the login alerts
 // (can be also adjusted additionally via the OnRecordAuthRequest hook).
 func RecordAuthResponse(e *core.RequestEvent, authRecord *core.Record, authMethod string, meta any) error {
-	token, tokenErr := authRecord.NewAuthToken()
+	authRecord.SetTokenSigner(e.App.TokenSigner())
+
+	if !authRecord.Collection().AuthSession.Track {
+		token, tokenErr := authRecord.NewAuthToken()
+		if tokenErr != nil {
+			return e.InternalServerError("Failed to create auth token.", tokenErr)
+		}
+
+		return recordAuthResponse(e, authRecord, token, authMethod, meta)
+	}
+
+	token, session, tokenErr := newTrackedAuthToken(e, authRecord)
 	if tokenErr != nil {
 		return e.InternalServerError("Failed to create auth token.", tokenErr)
 	}
 
-	return recordAuthResponse(e, authRecord, token, authMethod, meta)
+	err := recordAuthResponse(e, authRecord, token, authMethod, meta)
+	if err != nil {
+		// the auth hasn't completed (e.g. MFA is required) -> discard the session
+		if deleteErr := e.App.Delete(session); deleteErr != nil {
+			e.App.Logger().Debug("Failed to delete the incomplete auth session", "error", deleteErr, "sessionId", session.Id)
+		}
+	}
+
+	return err
 }
 
 func recordAuthResponse(e *core.RequestEvent, authRecord *core.Record, token string, authMethod string, meta any) error {
@@ -109,7 +130,7 @@ func recordAuthResponse(e *core.RequestEvent, authRecord *core.Record, token str
 		}
 
 		if e.AuthMethod != "" && authRecord.Collection().AuthAlert.Enabled {
-			if err = authAlert(e.RequestEvent, e.Record); err != nil {
+			if err = authAlert(e.RequestEvent, e.Record, e.AuthMethod); err != nil {
 				e.App.Logger().Warn("[recordAuthResponse] Failed to send login alert", "error", err)
 			}
 		}
@@ -239,6 +260,20 @@ func checkMFA(e *core.RequestEvent, authRecord *core.Record, currentAuthMethod s
 	return "", nil
 }
 
+// checkAuthLockout returns 429 error if the collection identity and
+// current client IP pair is temporary locked because of too many
+// failed auth attempts (see [core.Settings.AuthLockout]).
+func checkAuthLockout(e *core.RequestEvent, collection *core.Collection, identity string) error {
+	remaining := e.App.AuthLockoutRemaining(collection, identity, e.RealIP())
+	if remaining <= 0 {
+		return nil
+	}
+
+	e.Response.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(remaining.Seconds())), 10))
+
+	return e.TooManyRequestsError("Too many failed attempts, please try again later.", nil)
+}
+
 // EnrichRecord parses the request context and enrich the provided record:
 //   - expands relations (if defaultExpands and/or ?expand query param is set)
 //   - ensures that the emails of the auth record and its expanded auth relations
@@ -251,6 +286,7 @@ func EnrichRecord(e *core.RequestEvent, record *core.Record, defaultExpands ...s
 //   - expands relations (if defaultExpands and/or ?expand query param is set)
 //   - ensures that the emails of the auth records and their expanded auth relations
 //     are visible only for the current logged superuser, record owner or record with manage access
+//   - triggers the [core.App.OnRecordEnrichResponse] hook
 //
 // Note: Expects all records to be from the same collection!
 func EnrichRecords(e *core.RequestEvent, records []*core.Record, defaultExpands ...string) error {
@@ -263,7 +299,7 @@ func EnrichRecords(e *core.RequestEvent, records []*core.Record, defaultExpands
 		return err
 	}
 
-	return triggerRecordEnrichHooks(e.App, info, records, func() error {
+	err = triggerRecordEnrichHooks(e.App, info, records, func() error {
 		expands := defaultExpands
 		if param := info.Query[expandQueryParam]; param != "" {
 			expands = append(expands, strings.Split(param, ",")...)
@@ -277,6 +313,16 @@ func EnrichRecords(e *core.RequestEvent, records []*core.Record, defaultExpands
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	event := new(core.RecordEnrichResponseEvent)
+	event.RequestEvent = e
+	event.Collection = records[0].Collection()
+	event.Records = records
+
+	return e.App.OnRecordEnrichResponse().Trigger(event)
 }
 
 type iterator[T any] struct {
@@ -571,7 +617,7 @@ func firstApiError(errs ...error) *router.ApiError {
 
 const maxAuthOrigins = 5
 
-func authAlert(e *core.RequestEvent, authRecord *core.Record) error {
+func authAlert(e *core.RequestEvent, authRecord *core.Record, authMethod string) error {
 	// generating fingerprint
 	// ---
 	userAgent := e.Request.UserAgent()
@@ -602,9 +648,23 @@ func authAlert(e *core.RequestEvent, authRecord *core.Record) error {
 		currentOrigin.SetFingerprint(fingerprint)
 	}
 
-	// send email alert for the new origin auth (skip first login)
-	if !isFirstLogin && currentOrigin.IsNew() && authRecord.Email() != "" {
-		if err := mails.SendRecordAuthAlert(e.App, authRecord); err != nil {
+	// send alert for the new origin auth (skip first login)
+	if !isFirstLogin && currentOrigin.IsNew() {
+		event := new(core.RecordNewDeviceLoginEvent)
+		event.RequestEvent = e
+		event.Collection = authRecord.Collection()
+		event.Record = authRecord
+		event.AuthOrigin = currentOrigin
+		event.AuthMethod = authMethod
+
+		err := e.App.OnRecordNewDeviceLogin().Trigger(event, func(e *core.RecordNewDeviceLoginEvent) error {
+			if e.Record.Email() == "" {
+				return nil
+			}
+
+			return mails.SendRecordAuthAlert(e.App, e.Record)
+		})
+		if err != nil {
 			return err
 		}
 	}
@@ -14,6 +14,7 @@ import (
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/router"
 	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
 )
 
 func TestEnrichRecords(t *testing.T) {
@@ -263,6 +264,104 @@ func TestEnrichRecords(t *testing.T) {
 	}
 }
 
+func TestEnrichRecordsResponseHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	records, err := app.FindRecordsByIds("demo5", []string{"la4y2w4o98acwuj", "qjeql998mtp1azp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	superuser, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	app.OnRecordEnrichResponse("demo5").BindFunc(func(e *core.RecordEnrichResponseEvent) error {
+		calls++
+
+		if e.Collection.Name != "demo5" {
+			t.Fatalf("Expected demo5 collection, got %q", e.Collection.Name)
+		}
+
+		if len(e.Records) != 2 {
+			t.Fatalf("Expected 2 records, got %d", len(e.Records))
+		}
+
+		var expanded int
+		for _, r := range e.Records {
+			if len(r.Expand()) > 0 {
+				expanded++
+			}
+
+			r.Hide("select_one")
+			r.WithCustomData(true)
+			r.Set("computed", "auth_"+cast.ToString(e.Auth != nil))
+		}
+
+		// the relations should be already expanded
+		if expanded == 0 {
+			t.Fatal("Expected the records to be expanded")
+		}
+
+		return e.Next()
+	})
+
+	var otherCalls int
+	app.OnRecordEnrichResponse("demo1").BindFunc(func(e *core.RecordEnrichResponseEvent) error {
+		otherCalls++
+		return e.Next()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/?expand=rel_one", nil)
+	rec := httptest.NewRecorder()
+
+	requestEvent := new(core.RequestEvent)
+	requestEvent.App = app
+	requestEvent.Request = req
+	requestEvent.Response = rec
+	requestEvent.Auth = superuser
+
+	if err := apis.EnrichRecords(requestEvent, records); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("Expected the hook to be called once, got %d", calls)
+	}
+
+	if otherCalls != 0 {
+		t.Fatalf("Expected the demo1 hook to not be called, got %d", otherCalls)
+	}
+
+	raw, err := json.Marshal(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawStr := string(raw)
+
+	if strings.Contains(rawStr, `"select_one"`) {
+		t.Fatalf("Expected select_one to be hidden, got %s", rawStr)
+	}
+
+	if strings.Count(rawStr, `"computed":"auth_true"`) != 2 {
+		t.Fatalf("Expected the computed prop for both records, got %s", rawStr)
+	}
+
+	// hook error
+	app.OnRecordEnrichResponse().BindFunc(func(e *core.RecordEnrichResponseEvent) error {
+		return errors.New("test")
+	})
+
+	if err := apis.EnrichRecords(requestEvent, records); err == nil {
+		t.Fatal("Expected the hook error to be returned")
+	}
+}
+
 func TestRecordAuthResponseAuthRuleCheck(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
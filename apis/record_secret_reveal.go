@@ -0,0 +1,69 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// recordSecretReveal returns the plain value of a single record secret field.
+//
+// Only superusers and the record owners (see [core.SecretField.IsOwner])
+// are allowed to reveal the field value and every successful reveal is logged.
+func recordSecretReveal(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	err = checkCollectionRateLimit(e, collection, "reveal")
+	if err != nil {
+		return err
+	}
+
+	field, ok := collection.Fields.GetByName(e.Request.PathValue("field")).(*core.SecretField)
+	if !ok {
+		return e.NotFoundError("Missing or invalid secret field.", nil)
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	if requestInfo.Auth == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	record, err := e.App.FindRecordById(collection, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	isSuperuser := requestInfo.HasSuperuserAuth()
+
+	if !isSuperuser && (field.Hidden || !field.IsOwner(record, requestInfo.Auth)) {
+		return e.ForbiddenError("Only superusers and the record owners can reveal the field value.", nil)
+	}
+
+	value, err := field.Reveal(e.App, record)
+	if err != nil {
+		return e.BadRequestError("Failed to reveal the field value.", err)
+	}
+
+	e.App.Logger().Info(
+		"Secret field value revealed",
+		"type", "secretReveal",
+		"collectionId", collection.Id,
+		"recordId", record.Id,
+		"field", field.Name,
+		"authId", requestInfo.Auth.Id,
+		"authCollectionId", requestInfo.Auth.Collection().Id,
+		"superuser", isSuperuser,
+		"ip", e.RealIP(),
+	)
+
+	e.Response.Header().Set("Cache-Control", "no-store")
+
+	return e.JSON(http.StatusOK, map[string]string{"value": value})
+}
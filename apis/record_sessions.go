@@ -0,0 +1,157 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+// sessionUserAgentMaxLength is the max stored session user agent length.
+const sessionUserAgentMaxLength = 500
+
+// bindRecordSessionsApi registers the auth record sessions management
+// endpoints and the corresponding handlers.
+//
+// The sessions are registered only for the auth collections with
+// enabled [core.AuthSessionConfig.Track] option.
+func bindRecordSessionsApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/collections/{collection}/records/{id}/sessions")
+
+	sub.GET("", recordSessionsList).Bind(
+		collectionPathRateLimit("", "listSessions"),
+		RequireSuperuserOrOwnerAuth("id"),
+	)
+	sub.DELETE("", recordSessionsRevokeAll).Bind(
+		collectionPathRateLimit("", "revokeSessions"),
+		RequireSuperuserOrOwnerAuth("id"),
+	)
+	sub.DELETE("/{sessionId}", recordSessionRevoke).Bind(
+		collectionPathRateLimit("", "revokeSession"),
+		RequireSuperuserOrOwnerAuth("id"),
+	)
+}
+
+func recordSessionsList(e *core.RequestEvent) error {
+	record, err := findSessionsOwner(e)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := e.App.FindAllSessionsByRecord(record)
+	if err != nil {
+		return e.InternalServerError("Failed to fetch the auth sessions.", err)
+	}
+
+	return e.JSON(http.StatusOK, sessions)
+}
+
+func recordSessionsRevokeAll(e *core.RequestEvent) error {
+	record, err := findSessionsOwner(e)
+	if err != nil {
+		return err
+	}
+
+	if err := e.App.DeleteAllSessionsByRecord(record); err != nil {
+		return e.BadRequestError("Failed to revoke the auth sessions.", err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+func recordSessionRevoke(e *core.RequestEvent) error {
+	record, err := findSessionsOwner(e)
+	if err != nil {
+		return err
+	}
+
+	session, err := e.App.FindSessionById(e.Request.PathValue("sessionId"))
+	if err != nil || session.RecordRef() != record.Id || session.CollectionRef() != record.Collection().Id {
+		return e.NotFoundError("", err)
+	}
+
+	if err := e.App.Delete(session); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to revoke the auth session.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// findSessionsOwner loads the auth record from the current request path.
+func findSessionsOwner(e *core.RequestEvent) (*core.Record, error) {
+	if e.Get(core.RequestEventKeyAPIKey) != nil {
+		return nil, e.ForbiddenError("The auth sessions cannot be managed with an API key.", nil)
+	}
+
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || !collection.IsAuth() {
+		return nil, e.NotFoundError("Missing or invalid auth collection context.", err)
+	}
+
+	record, err := e.App.FindRecordById(collection, e.Request.PathValue("id"))
+	if err != nil {
+		return nil, e.NotFoundError("", err)
+	}
+
+	return record, nil
+}
+
+// -------------------------------------------------------------------
+
+// newTrackedAuthToken registers a new server-side session for the
+// provided auth record and returns an auth token linked to it.
+func newTrackedAuthToken(e *core.RequestEvent, authRecord *core.Record) (string, *core.Session, error) {
+	userAgent := e.Request.UserAgent()
+	if len(userAgent) > sessionUserAgentMaxLength {
+		userAgent = userAgent[:sessionUserAgentMaxLength]
+	}
+
+	now := types.NowDateTime()
+
+	session := core.NewSession(e.App)
+	session.SetCollectionRef(authRecord.Collection().Id)
+	session.SetRecordRef(authRecord.Id)
+	session.SetUserAgent(userAgent)
+	session.SetIP(e.RealIP())
+	session.SetLastActive(now)
+
+	if err := e.App.Save(session); err != nil {
+		return "", nil, err
+	}
+
+	token, err := authRecord.NewTrackedAuthToken(session)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, session, nil
+}
+
+// newSessionAuthToken generates a new auth token that continues the
+// auth session of the provided (already validated) token claims.
+//
+// If the auth record collection has enabled session tracking, the
+// new token is linked to the same server-side session.
+func newSessionAuthToken(e *core.RequestEvent, authRecord *core.Record, claims jwt.MapClaims) (string, error) {
+	_, sessionStart := core.AuthTokenSessionTimes(claims, authRecord.Collection())
+
+	sessionId := cast.ToString(claims[core.TokenClaimSessionId])
+	if !authRecord.Collection().AuthSession.Track || sessionId == "" {
+		return authRecord.NewSessionAuthToken(sessionStart)
+	}
+
+	session, err := e.App.FindSessionById(sessionId)
+	if err != nil {
+		return "", core.ErrAuthSessionRevoked
+	}
+
+	session.SetLastActive(types.NowDateTime())
+	if err := e.App.Save(session); err != nil {
+		return "", err
+	}
+
+	return authRecord.NewTrackedAuthToken(session)
+}
@@ -0,0 +1,242 @@
+package apis
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+const (
+	// DefaultSimilarRecordsLimit is the default number of the returned similar records.
+	DefaultSimilarRecordsLimit = 10
+
+	// MaxSimilarRecordsLimit is the max allowed number of the returned similar records.
+	MaxSimilarRecordsLimit = 100
+)
+
+// SimilarRecordItem defines a single similar records search result item.
+type SimilarRecordItem struct {
+	Score  float64      `json:"score"`
+	Record *core.Record `json:"record"`
+}
+
+// recordsSimilar handles the k-nearest neighbors search of a collection vector field.
+//
+// The vector to compare with could be either submitted directly (e.g. a query embedding
+// for semantic search) or taken from an existing record (e.g. for recommendations).
+//
+// The results are filtered with the collection ListRule and the optional "filter" query parameter.
+//
+// Note that the search performs a full scan of the (filtered) collection records
+// and it is intended for small to medium sized collections.
+func recordsSimilar(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	err = checkCollectionRateLimit(e, collection, "list")
+	if err != nil {
+		return err
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	if collection.ListRule == nil && !requestInfo.HasSuperuserAuth() {
+		return e.ForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	// forbid users and guests to query special filter fields
+	err = checkForSuperuserOnlyRuleFields(requestInfo)
+	if err != nil {
+		return err
+	}
+
+	form := &similarRecordsForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(collection, requestInfo.HasSuperuserAuth()); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	filters := []search.FilterData{}
+	if !requestInfo.HasSuperuserAuth() && collection.ListRule != nil && *collection.ListRule != "" {
+		filters = append(filters, search.FilterData(*collection.ListRule))
+	}
+	if filter := e.Request.URL.Query().Get(search.FilterQueryParam); filter != "" {
+		if len(filter) > search.MaxFilterLength {
+			return e.BadRequestError("", search.ErrFilterLengthLimit)
+		}
+		filters = append(filters, search.FilterData(filter))
+	}
+
+	// applies the filters to the provided query
+	applyFilters := func(q *dbx.SelectQuery) error {
+		fieldsResolver := core.NewRecordFieldResolver(
+			e.App,
+			collection,
+			requestInfo,
+			// hidden fields are searchable only by superusers
+			requestInfo.HasSuperuserAuth(),
+		)
+
+		for _, f := range filters {
+			expr, err := f.BuildExpr(fieldsResolver)
+			if err != nil {
+				return err
+			}
+			q.AndWhere(expr)
+		}
+
+		return fieldsResolver.UpdateQuery(q)
+	}
+
+	vector := form.Vector
+	if form.RecordId != "" {
+		// the source record must be accessible with the same list constraints
+		sourceQuery := e.App.RecordQuery(collection).AndWhere(dbx.HashExp{collection.Name + ".id": form.RecordId})
+		if err := applyFilters(sourceQuery); err != nil {
+			return e.BadRequestError("", err)
+		}
+
+		source := &core.Record{}
+		if err := sourceQuery.Limit(1).One(source); err != nil {
+			return e.NotFoundError("Missing or inaccessible source record.", err)
+		}
+
+		vector, err = core.ParseVector(source.GetRaw(form.Field))
+		if err != nil || len(vector) == 0 {
+			return e.BadRequestError("The source record doesn't have a vector value.", err)
+		}
+	}
+
+	encodedVector, err := json.Marshal(vector)
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	scoreExpr := "vector_similarity([[" + inflector.Columnify(collection.Name) + "." + inflector.Columnify(form.Field) + "]], {:vector})"
+
+	query := e.App.DB().
+		Select("[["+inflector.Columnify(collection.Name)+".id]] AS [[id]]", scoreExpr+" AS [[score]]").
+		From(collection.Name).
+		AndWhere(dbx.NewExp(scoreExpr+" >= {:minScore}", dbx.Params{"vector": string(encodedVector), "minScore": *form.MinScore})).
+		OrderBy("[[score]] DESC").
+		Limit(int64(form.Limit))
+
+	if form.RecordId != "" {
+		query.AndWhere(dbx.Not(dbx.HashExp{collection.Name + ".id": form.RecordId}))
+	}
+
+	if err := applyFilters(query); err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	rows := []struct {
+		Id    string  `db:"id"`
+		Score float64 `db:"score"`
+	}{}
+	if err := query.All(&rows); err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.Id
+	}
+
+	records, err := e.App.FindRecordsByIds(collection, ids)
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	// restore the similarity order
+	slices.SortFunc(records, func(a, b *core.Record) int {
+		return slices.Index(ids, a.Id) - slices.Index(ids, b.Id)
+	})
+
+	if err := EnrichRecords(e, records); err != nil {
+		return firstApiError(err, e.InternalServerError("Failed to enrich records", err))
+	}
+
+	items := make([]*SimilarRecordItem, 0, len(records))
+	for _, r := range records {
+		items = append(items, &SimilarRecordItem{
+			Score:  rows[slices.Index(ids, r.Id)].Score,
+			Record: r,
+		})
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+// -------------------------------------------------------------------
+
+type similarRecordsForm struct {
+	// Field is the name of the collection vector field to search.
+	Field string `form:"field" json:"field"`
+
+	// Vector is the vector to compare the records with.
+	Vector []float64 `form:"vector" json:"vector"`
+
+	// RecordId is the id of an existing collection record whose Field
+	// value to compare the other records with (the record itself is excluded).
+	RecordId string `form:"recordId" json:"recordId"`
+
+	// Limit specifies the max number of returned records
+	// (default to [DefaultSimilarRecordsLimit]).
+	Limit int `form:"limit" json:"limit"`
+
+	// MinScore specifies the min cosine similarity of the returned records
+	// (default to -1, aka. no min restriction).
+	MinScore *float64 `form:"minScore" json:"minScore"`
+}
+
+func (form *similarRecordsForm) validate(collection *core.Collection, allowHidden bool) error {
+	if form.Limit == 0 {
+		form.Limit = DefaultSimilarRecordsLimit
+	}
+
+	if form.MinScore == nil {
+		minScore := -1.0
+		form.MinScore = &minScore
+	}
+
+	var dimensions int
+
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Field, validation.Required, validation.By(func(value any) error {
+			field, ok := collection.Fields.GetByName(form.Field).(*core.VectorField)
+			if !ok || (field.Hidden && !allowHidden) {
+				return validation.NewError("validation_invalid_vector_field", "Missing or invalid vector field.")
+			}
+			dimensions = field.Dimensions
+			return nil
+		})),
+		validation.Field(&form.Vector,
+			validation.When(form.RecordId == "", validation.Required),
+			validation.When(form.RecordId != "", validation.Empty),
+			validation.By(func(value any) error {
+				if len(form.Vector) > 0 && dimensions > 0 && len(form.Vector) != dimensions {
+					return validation.NewError(
+						"validation_invalid_vector_dimensions",
+						"The vector must have exactly {{.dimensions}} elements.",
+					).SetParams(map[string]any{"dimensions": dimensions})
+				}
+				return nil
+			}),
+		),
+		validation.Field(&form.Limit, validation.Min(1), validation.Max(MaxSimilarRecordsLimit)),
+		validation.Field(&form.MinScore, validation.Min(-1.0), validation.Max(1.0)),
+	)
+}
@@ -73,8 +73,9 @@ func Serve(app core.App, config ServeConfig) error {
 	}
 
 	pbRouter.Bind(CORS(CORSConfig{
-		AllowOrigins: config.AllowedOrigins,
-		AllowMethods: []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
+		AllowOrigins:  config.AllowedOrigins,
+		AllowMethods:  []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
+		ExposeHeaders: []string{RenewedAuthTokenHeader},
 	}))
 
 	pbRouter.GET("/_/{path...}", Static(ui.DistDirFS, false)).
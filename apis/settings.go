@@ -14,6 +14,8 @@ func bindSettingsApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
 	subGroup := rg.Group("/settings").Bind(RequireSuperuserAuth())
 	subGroup.GET("", settingsList)
 	subGroup.PATCH("", settingsSet)
+	subGroup.POST("/export", settingsExport)
+	subGroup.POST("/import", settingsImport)
 	subGroup.POST("/test/s3", settingsTestS3)
 	subGroup.POST("/test/email", settingsTestEmail)
 	subGroup.POST("/apple/generate-client-secret", settingsGenerateAppleClientSecret)
@@ -69,6 +71,67 @@ func settingsSet(e *core.RequestEvent) error {
 	})
 }
 
+func settingsExport(e *core.RequestEvent) error {
+	data := struct {
+		EncryptKey string `json:"encryptKey" form:"encryptKey"`
+	}{}
+
+	if err := e.BindBody(&data); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	export, err := e.App.Settings().Export(data.EncryptKey)
+	if err != nil {
+		return e.BadRequestError("Failed to export the app settings.", err)
+	}
+
+	return e.JSON(http.StatusOK, export)
+}
+
+func settingsImport(e *core.RequestEvent) error {
+	data := struct {
+		EncryptKey string               `json:"encryptKey" form:"encryptKey"`
+		Export     *core.SettingsExport `json:"export" form:"export"`
+	}{}
+
+	if err := e.BindBody(&data); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	event := new(core.SettingsUpdateRequestEvent)
+	event.RequestEvent = e
+
+	if clone, err := e.App.Settings().Clone(); err == nil {
+		event.OldSettings = clone
+	} else {
+		return e.BadRequestError("", err)
+	}
+
+	if clone, err := e.App.Settings().Clone(); err == nil {
+		event.NewSettings = clone
+	} else {
+		return e.BadRequestError("", err)
+	}
+
+	if err := event.NewSettings.Import(data.Export, data.EncryptKey); err != nil {
+		return e.BadRequestError("Failed to import the app settings.", err)
+	}
+
+	return e.App.OnSettingsUpdateRequest().Trigger(event, func(e *core.SettingsUpdateRequestEvent) error {
+		err := e.App.Save(e.NewSettings)
+		if err != nil {
+			return e.BadRequestError("An error occurred while saving the new settings.", err)
+		}
+
+		appSettings, err := e.App.Settings().Clone()
+		if err != nil {
+			return e.InternalServerError("Failed to clone app settings.", err)
+		}
+
+		return e.JSON(http.StatusOK, appSettings)
+	})
+}
+
 func settingsTestS3(e *core.RequestEvent) error {
 	form := forms.NewTestS3Filesystem(e.App)
 
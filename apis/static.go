@@ -0,0 +1,444 @@
+package apis
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+// StaticConfig defines the config for the [StaticWithConfig] handler.
+type StaticConfig struct {
+	// FS is the file system to serve the static content from (required).
+	FS fs.FS
+
+	// IndexFallback specifies whether to forward the requests for
+	// the missing files to the base index.html (useful for SPA with pretty urls).
+	IndexFallback bool
+
+	// NotFoundPage is an optional file from FS (e.g. "404.html")
+	// that is rendered with 404 status code for the missing files.
+	//
+	// Note that IndexFallback takes precedence and the page will be
+	// rendered only if the fallback index.html is also missing.
+	NotFoundPage string
+
+	// Precompressed enables serving the precompressed ".br" and ".gz"
+	// file variants (if exist) based on the request Accept-Encoding header
+	// (e.g. "app.js.br" will be served instead of "app.js").
+	Precompressed bool
+
+	// Headers specifies a list of custom response headers applied
+	// to the served files matching the rule pattern.
+	//
+	// The rules are applied in the order they are registered, so a later
+	// matching rule can override the headers of an earlier one.
+	Headers []StaticHeadersRule
+}
+
+// StaticHeadersRule defines a set of response headers for the static files matching Pattern.
+//
+// Example:
+//
+//	apis.StaticHeadersRule{
+//		Pattern: "assets/*",
+//		Headers: map[string]string{"Cache-Control": "public, max-age=31536000, immutable"},
+//	}
+type StaticHeadersRule struct {
+	// Pattern is a [path.Match] pattern matched against the served file path
+	// relative to the FS root (e.g. "assets/*").
+	//
+	// Patterns without "/" are also matched against the file base name
+	// (e.g. "*.html" matches both "index.html" and "docs/index.html").
+	Pattern string
+
+	// Headers is the list of response headers to set.
+	Headers map[string]string
+}
+
+// match checks whether the provided slash separated filename matches the rule pattern.
+func (r StaticHeadersRule) match(filename string) bool {
+	if ok, _ := path.Match(r.Pattern, filename); ok {
+		return true
+	}
+
+	if !strings.Contains(r.Pattern, "/") {
+		ok, _ := path.Match(r.Pattern, path.Base(filename))
+		return ok
+	}
+
+	return false
+}
+
+// StaticMount defines a single [StaticMounts] mount point.
+type StaticMount struct {
+	// Prefix is the mount path prefix relative to the route "{path...}"
+	// wildcard parameter (e.g. "docs" or "app/admin").
+	//
+	// An empty prefix matches all paths.
+	Prefix string
+
+	StaticConfig
+}
+
+// Static is a handler function to serve static directory content from fsys.
+//
+// If a file resource is missing and indexFallback is set, the request
+// will be forwarded to the base index.html (useful for SPA with pretty urls).
+//
+// NB! Expects the route to have a "{path...}" wildcard parameter.
+//
+// Special redirects:
+//   - if "path" is a file that ends in index.html, it is redirected to its non-index.html version (eg. /test/index.html -> /test/)
+//   - if "path" is a directory that has index.html, the index.html file is rendered,
+//     otherwise if missing - returns 404 or fallback to the root index.html if indexFallback is set
+//
+// Example:
+//
+//	fsys := os.DirFS("./pb_public")
+//	router.GET("/files/{path...}", apis.Static(fsys, false))
+func Static(fsys fs.FS, indexFallback bool) func(*core.RequestEvent) error {
+	if fsys == nil {
+		panic("Static: the provided fs.FS argument is nil")
+	}
+
+	return StaticWithConfig(StaticConfig{
+		FS:            fsys,
+		IndexFallback: indexFallback,
+	})
+}
+
+// StaticWithConfig is similar to [Static] but allows further customizations
+// like custom 404 page, precompressed assets and custom response headers.
+//
+// NB! Expects the route to have a "{path...}" wildcard parameter.
+//
+// Example:
+//
+//	router.GET("/{path...}", apis.StaticWithConfig(apis.StaticConfig{
+//		FS:            os.DirFS("./pb_public"),
+//		NotFoundPage:  "404.html",
+//		Precompressed: true,
+//		Headers: []apis.StaticHeadersRule{
+//			{Pattern: "*.html", Headers: map[string]string{"Cache-Control": "no-cache"}},
+//			{Pattern: "assets/*", Headers: map[string]string{"Cache-Control": "public, max-age=31536000, immutable"}},
+//		},
+//	}))
+func StaticWithConfig(config StaticConfig) func(*core.RequestEvent) error {
+	if config.FS == nil {
+		panic("StaticWithConfig: the provided config.FS is nil")
+	}
+
+	for _, rule := range config.Headers {
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			panic("StaticWithConfig: invalid headers rule pattern " + strconv.Quote(rule.Pattern))
+		}
+	}
+
+	if config.NotFoundPage != "" {
+		config.NotFoundPage = filepath.ToSlash(filepath.Clean(strings.TrimPrefix(config.NotFoundPage, "/")))
+	}
+
+	return func(e *core.RequestEvent) error {
+		// disable the activity logger to avoid flooding with messages
+		//
+		// note: errors are still logged
+		if e.Get(requestEventKeySkipSuccessActivityLog) == nil {
+			e.Set(requestEventKeySkipSuccessActivityLog, true)
+		}
+
+		filename := e.Request.PathValue(StaticWildcardParam)
+		filename = filepath.ToSlash(filepath.Clean(strings.TrimPrefix(filename, "/")))
+
+		// eagerly check for directory traversal
+		//
+		// note: this is just out of an abundance of caution because the fs.FS implementation could be non-std,
+		// but usually shouldn't be necessary since os.DirFS.Open is expected to fail if the filename starts with dots
+		if len(filename) > 2 && filename[0] == '.' && filename[1] == '.' && (filename[2] == '/' || filename[2] == '\\') {
+			return config.serveNotFound(e, filename)
+		}
+
+		fi, err := fs.Stat(config.FS, filename)
+		if err != nil {
+			return config.serveNotFound(e, filename)
+		}
+
+		if fi.IsDir() {
+			// redirect to a canonical dir url, aka. with trailing slash
+			if !strings.HasSuffix(e.Request.URL.Path, "/") {
+				return e.Redirect(http.StatusMovedPermanently, safeRedirectPath(e.Request.URL.Path+"/"))
+			}
+		} else {
+			urlPath := e.Request.URL.Path
+			if strings.HasSuffix(urlPath, "/") {
+				// redirect to a non-trailing slash file route
+				urlPath = strings.TrimRight(urlPath, "/")
+				if len(urlPath) > 0 {
+					return e.Redirect(http.StatusMovedPermanently, safeRedirectPath(urlPath))
+				}
+			} else if stripped, ok := strings.CutSuffix(urlPath, router.IndexPage); ok {
+				// redirect without the index.html
+				return e.Redirect(http.StatusMovedPermanently, safeRedirectPath(stripped))
+			}
+		}
+
+		fileErr := config.serveFile(e, filename, http.StatusOK)
+		if fileErr != nil && errors.Is(fileErr, router.ErrFileNotFound) {
+			return config.serveNotFound(e, filename)
+		}
+
+		return fileErr
+	}
+}
+
+// StaticMounts is a handler function to serve static content from
+// multiple mount points, each with its own [StaticConfig]
+// (e.g. SPA index fallback only for a specific path).
+//
+// The request is handled by the mount with the longest matching prefix
+// and the mount prefix is trimmed from the "{path...}" wildcard parameter.
+//
+// NB! Expects the route to have a "{path...}" wildcard parameter.
+//
+// Example:
+//
+//	router.GET("/{path...}", apis.StaticMounts(
+//		apis.StaticMount{Prefix: "", StaticConfig: apis.StaticConfig{FS: os.DirFS("./pb_public"), NotFoundPage: "404.html"}},
+//		apis.StaticMount{Prefix: "app", StaticConfig: apis.StaticConfig{FS: os.DirFS("./app/dist"), IndexFallback: true}},
+//	))
+func StaticMounts(mounts ...StaticMount) func(*core.RequestEvent) error {
+	type mountHandler struct {
+		prefix  string
+		handler func(*core.RequestEvent) error
+	}
+
+	handlers := make([]mountHandler, 0, len(mounts))
+	for _, m := range mounts {
+		prefix := strings.Trim(path.Clean("/"+filepath.ToSlash(m.Prefix)), "/")
+
+		if slices.ContainsFunc(handlers, func(h mountHandler) bool { return h.prefix == prefix }) {
+			panic("StaticMounts: duplicated mount prefix " + strconv.Quote(m.Prefix))
+		}
+
+		handlers = append(handlers, mountHandler{
+			prefix:  prefix,
+			handler: StaticWithConfig(m.StaticConfig),
+		})
+	}
+
+	// sort by the longest prefix first
+	slices.SortStableFunc(handlers, func(a, b mountHandler) int {
+		return len(b.prefix) - len(a.prefix)
+	})
+
+	return func(e *core.RequestEvent) error {
+		// note: the path is cleaned to resolve any dot segments before matching the prefix
+		filename := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(e.Request.PathValue(StaticWildcardParam))), "/")
+
+		for _, h := range handlers {
+			var rest string
+			if h.prefix == "" {
+				rest = filename
+			} else if filename == h.prefix {
+				rest = ""
+			} else if after, ok := strings.CutPrefix(filename, h.prefix+"/"); ok {
+				rest = after
+			} else {
+				continue
+			}
+
+			e.Request.SetPathValue(StaticWildcardParam, rest)
+
+			return h.handler(e)
+		}
+
+		return router.ErrFileNotFound
+	}
+}
+
+// serveNotFound handles the missing file response by either
+// forwarding to the base index.html, rendering the custom NotFoundPage
+// or returning a regular 404 error.
+func (c *StaticConfig) serveNotFound(e *core.RequestEvent, filename string) error {
+	if c.IndexFallback && filename != router.IndexPage {
+		err := c.serveFile(e, router.IndexPage, http.StatusOK)
+		if err == nil || !errors.Is(err, router.ErrFileNotFound) {
+			return err
+		}
+	}
+
+	if c.NotFoundPage != "" && filename != c.NotFoundPage {
+		err := c.serveFile(e, c.NotFoundPage, http.StatusNotFound)
+		if err == nil || !errors.Is(err, router.ErrFileNotFound) {
+			return err
+		}
+	}
+
+	return router.ErrFileNotFound
+}
+
+// serveFile writes the specified FS file (or its directory index.html)
+// to the response with the configured custom headers and encoding.
+func (c *StaticConfig) serveFile(e *core.RequestEvent, filename string, status int) error {
+	f, err := c.FS.Open(filename)
+	if err != nil {
+		return router.ErrFileNotFound
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// if it is a directory try to open its index.html file
+	if fi.IsDir() {
+		filename = path.Join(filename, router.IndexPage)
+
+		f, err = c.FS.Open(filename)
+		if err != nil {
+			return router.ErrFileNotFound
+		}
+		defer f.Close()
+
+		fi, err = f.Stat()
+		if err != nil {
+			return err
+		}
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		return errors.New("[StaticWithConfig] file does not implement io.ReadSeeker")
+	}
+	modTime := fi.ModTime()
+
+	header := e.Response.Header()
+
+	for _, rule := range c.Headers {
+		if rule.match(filename) {
+			for k, v := range rule.Headers {
+				header.Set(k, v)
+			}
+		}
+	}
+
+	if c.Precompressed {
+		header.Add("Vary", "Accept-Encoding")
+
+		// the compressed variant is served only if the original content type can be resolved
+		// because otherwise the content type will be sniffed from the compressed bytes
+		contentType := mime.TypeByExtension(path.Ext(filename))
+		if contentType != "" {
+			for _, encoding := range acceptedStaticEncodings(e.Request.Header.Get("Accept-Encoding")) {
+				cf, cfi, err := openPrecompressed(c.FS, filename+staticEncodingExts[encoding])
+				if err != nil {
+					continue
+				}
+				defer cf.Close()
+
+				header.Set("Content-Type", contentType)
+				header.Set("Content-Encoding", encoding)
+				content = cf
+				modTime = cfi.ModTime()
+				break
+			}
+		}
+	}
+
+	if status == http.StatusOK {
+		http.ServeContent(e.Response, e.Request, fi.Name(), modTime, content)
+		return nil
+	}
+
+	// custom status (e.g. the 404 page)
+	if header.Get("Content-Type") == "" {
+		contentType := mime.TypeByExtension(path.Ext(filename))
+		if contentType == "" {
+			// sniff the first 512 bytes similar to http.ServeContent
+			var buf [512]byte
+			n, _ := io.ReadFull(content, buf[:])
+			contentType = http.DetectContentType(buf[:n])
+			if _, err := content.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		header.Set("Content-Type", contentType)
+	}
+
+	e.Response.WriteHeader(status)
+
+	if e.Request.Method == http.MethodHead {
+		return nil
+	}
+
+	_, err = io.Copy(e.Response, content)
+
+	return err
+}
+
+var staticEncodingExts = map[string]string{
+	"br":   ".br",
+	"gzip": ".gz",
+}
+
+// acceptedStaticEncodings returns the supported precompressed encodings
+// from the provided Accept-Encoding header value in order of preference.
+func acceptedStaticEncodings(acceptEncoding string) []string {
+	result := make([]string, 0, 2)
+
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+
+		accepted[name] = true
+	}
+
+	// prefer brotli since it usually has better compression ratio
+	for _, encoding := range []string{"br", "gzip"} {
+		if accepted[encoding] {
+			result = append(result, encoding)
+		}
+	}
+
+	return result
+}
+
+// openPrecompressed opens the specified precompressed file variant
+// and ensures that it is a regular seekable file.
+func openPrecompressed(fsys fs.FS, filename string) (io.ReadSeekCloser, fs.FileInfo, error) {
+	f, err := fsys.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() {
+		f.Close()
+		return nil, nil, router.ErrFileNotFound
+	}
+
+	rsc, ok := f.(io.ReadSeekCloser)
+	if !ok {
+		f.Close()
+		return nil, nil, router.ErrFileNotFound
+	}
+
+	return rsc, fi, nil
+}
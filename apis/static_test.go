@@ -0,0 +1,391 @@
+package apis_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+func TestStaticWithConfig(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	fsys := fstest.MapFS{
+		"index.html":         {Data: []byte("root index.html")},
+		"404.html":           {Data: []byte("custom 404")},
+		"app.js":             {Data: []byte("plain js")},
+		"app.js.br":          {Data: []byte("br js")},
+		"app.js.gz":          {Data: []byte("gz js")},
+		"style.css":          {Data: []byte("plain css")},
+		"style.css.gz":       {Data: []byte("gz css")},
+		"unknown":            {Data: []byte("plain unknown")},
+		"unknown.gz":         {Data: []byte("gz unknown")},
+		"assets/logo.svg":    {Data: []byte("<svg></svg>")},
+		"docs/index.html":    {Data: []byte("docs index.html")},
+		"docs/page.html":     {Data: []byte("docs page.html")},
+		"sub/nested/file.js": {Data: []byte("nested js")},
+	}
+
+	headers := []apis.StaticHeadersRule{
+		{Pattern: "*.html", Headers: map[string]string{"Cache-Control": "no-cache"}},
+		{Pattern: "assets/*", Headers: map[string]string{"Cache-Control": "public, max-age=31536000, immutable", "X-Test": "assets"}},
+		{Pattern: "docs/page.html", Headers: map[string]string{"Cache-Control": "max-age=60"}},
+	}
+
+	scenarios := []struct {
+		name            string
+		config          apis.StaticConfig
+		path            string
+		method          string
+		acceptEncoding  string
+		expectError     bool
+		expectedStatus  int
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		{
+			name:           "missing file without fallbacks",
+			config:         apis.StaticConfig{FS: fsys},
+			path:           "missing.txt",
+			expectError:    true,
+			expectedStatus: 404,
+		},
+		{
+			name:           "missing file with not found page",
+			config:         apis.StaticConfig{FS: fsys, NotFoundPage: "/404.html"},
+			path:           "missing.txt",
+			expectedStatus: 404,
+			expectedBody:   "custom 404",
+			expectedHeaders: map[string]string{
+				"Content-Type": "text/html; charset=utf-8",
+			},
+		},
+		{
+			name:           "missing file with not found page (HEAD)",
+			config:         apis.StaticConfig{FS: fsys, NotFoundPage: "404.html"},
+			path:           "missing.txt",
+			method:         http.MethodHead,
+			expectedStatus: 404,
+			expectedBody:   "",
+		},
+		{
+			name:           "missing file with missing not found page",
+			config:         apis.StaticConfig{FS: fsys, NotFoundPage: "missing.html"},
+			path:           "missing.txt",
+			expectError:    true,
+			expectedStatus: 404,
+		},
+		{
+			name:           "missing file with index fallback and not found page",
+			config:         apis.StaticConfig{FS: fsys, IndexFallback: true, NotFoundPage: "404.html"},
+			path:           "missing.txt",
+			expectedStatus: 200,
+			expectedBody:   "root index.html",
+		},
+		{
+			name:           "directory traversal with not found page",
+			config:         apis.StaticConfig{FS: fsys, NotFoundPage: "404.html"},
+			path:           "../index.html",
+			expectedStatus: 404,
+			expectedBody:   "custom 404",
+		},
+		{
+			name:           "existing file with not found page",
+			config:         apis.StaticConfig{FS: fsys, NotFoundPage: "404.html"},
+			path:           "app.js",
+			expectedStatus: 200,
+			expectedBody:   "plain js",
+		},
+		{
+			name:           "precompressed disabled",
+			config:         apis.StaticConfig{FS: fsys},
+			path:           "app.js",
+			acceptEncoding: "gzip, br",
+			expectedStatus: 200,
+			expectedBody:   "plain js",
+			expectedHeaders: map[string]string{
+				"Content-Encoding": "",
+				"Vary":             "",
+			},
+		},
+		{
+			name:           "precompressed without accept encoding",
+			config:         apis.StaticConfig{FS: fsys, Precompressed: true},
+			path:           "app.js",
+			expectedStatus: 200,
+			expectedBody:   "plain js",
+			expectedHeaders: map[string]string{
+				"Content-Encoding": "",
+				"Vary":             "Accept-Encoding",
+			},
+		},
+		{
+			name:           "precompressed with br preference",
+			config:         apis.StaticConfig{FS: fsys, Precompressed: true},
+			path:           "app.js",
+			acceptEncoding: "gzip, deflate, br",
+			expectedStatus: 200,
+			expectedBody:   "br js",
+			expectedHeaders: map[string]string{
+				"Content-Encoding": "br",
+				"Content-Type":     "text/javascript; charset=utf-8",
+				"Vary":             "Accept-Encoding",
+			},
+		},
+		{
+			name:           "precompressed with disabled br",
+			config:         apis.StaticConfig{FS: fsys, Precompressed: true},
+			path:           "app.js",
+			acceptEncoding: "gzip, br;q=0",
+			expectedStatus: 200,
+			expectedBody:   "gz js",
+			expectedHeaders: map[string]string{
+				"Content-Encoding": "gzip",
+			},
+		},
+		{
+			name:           "precompressed with missing br variant",
+			config:         apis.StaticConfig{FS: fsys, Precompressed: true},
+			path:           "style.css",
+			acceptEncoding: "br, gzip",
+			expectedStatus: 200,
+			expectedBody:   "gz css",
+			expectedHeaders: map[string]string{
+				"Content-Encoding": "gzip",
+				"Content-Type":     "text/css; charset=utf-8",
+			},
+		},
+		{
+			name:           "precompressed with unknown content type",
+			config:         apis.StaticConfig{FS: fsys, Precompressed: true},
+			path:           "unknown",
+			acceptEncoding: "gzip",
+			expectedStatus: 200,
+			expectedBody:   "plain unknown",
+			expectedHeaders: map[string]string{
+				"Content-Encoding": "",
+			},
+		},
+		{
+			name:           "headers rule matching base name",
+			config:         apis.StaticConfig{FS: fsys, Headers: headers},
+			path:           "docs/",
+			expectedStatus: 200,
+			expectedBody:   "docs index.html",
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache",
+			},
+		},
+		{
+			name:           "headers rule overwrite",
+			config:         apis.StaticConfig{FS: fsys, Headers: headers},
+			path:           "docs/page.html",
+			expectedStatus: 200,
+			expectedBody:   "docs page.html",
+			expectedHeaders: map[string]string{
+				"Cache-Control": "max-age=60",
+			},
+		},
+		{
+			name:           "headers rule with dir pattern",
+			config:         apis.StaticConfig{FS: fsys, Headers: headers},
+			path:           "assets/logo.svg",
+			expectedStatus: 200,
+			expectedBody:   "<svg></svg>",
+			expectedHeaders: map[string]string{
+				"Cache-Control": "public, max-age=31536000, immutable",
+				"X-Test":        "assets",
+			},
+		},
+		{
+			name:           "headers rule for the index fallback",
+			config:         apis.StaticConfig{FS: fsys, IndexFallback: true, Headers: headers},
+			path:           "assets/missing.svg",
+			expectedStatus: 200,
+			expectedBody:   "root index.html",
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache",
+				"X-Test":        "",
+			},
+		},
+		{
+			name:           "no matching headers rule",
+			config:         apis.StaticConfig{FS: fsys, Headers: headers},
+			path:           "sub/nested/file.js",
+			expectedStatus: 200,
+			expectedBody:   "nested js",
+			expectedHeaders: map[string]string{
+				"Cache-Control": "",
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			method := s.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(method, "/"+s.path, nil)
+			req.SetPathValue(apis.StaticWildcardParam, s.path)
+			if s.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", s.acceptEncoding)
+			}
+
+			rec := httptest.NewRecorder()
+
+			e := new(core.RequestEvent)
+			e.App = app
+			e.Request = req
+			e.Response = rec
+
+			err := apis.StaticWithConfig(s.config)(e)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				apiErr := router.ToApiError(err)
+				if apiErr.Status != s.expectedStatus {
+					t.Fatalf("Expected status code %d, got %d", s.expectedStatus, apiErr.Status)
+				}
+				return
+			}
+
+			if rec.Code != s.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d", s.expectedStatus, rec.Code)
+			}
+
+			if body := rec.Body.String(); body != s.expectedBody {
+				t.Fatalf("Expected body %q, got %q", s.expectedBody, body)
+			}
+
+			for k, v := range s.expectedHeaders {
+				if h := rec.Header().Get(k); h != v {
+					t.Fatalf("Expected %q header %q, got %q", k, v, h)
+				}
+			}
+		})
+	}
+}
+
+func TestStaticWithConfigInvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	if !hasPanicked(func() {
+		apis.StaticWithConfig(apis.StaticConfig{
+			FS:      fstest.MapFS{},
+			Headers: []apis.StaticHeadersRule{{Pattern: "[invalid"}},
+		})
+	}) {
+		t.Fatal("Expected to panic")
+	}
+}
+
+func TestStaticMounts(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	rootFS := fstest.MapFS{
+		"index.html": {Data: []byte("root index.html")},
+		"404.html":   {Data: []byte("root 404")},
+		"about.html": {Data: []byte("root about")},
+		"app/test":   {Data: []byte("root app test")},
+	}
+
+	appFS := fstest.MapFS{
+		"index.html": {Data: []byte("app index.html")},
+		"test":       {Data: []byte("app test")},
+	}
+
+	adminFS := fstest.MapFS{
+		"index.html": {Data: []byte("admin index.html")},
+	}
+
+	handler := apis.StaticMounts(
+		apis.StaticMount{Prefix: "", StaticConfig: apis.StaticConfig{FS: rootFS, NotFoundPage: "404.html"}},
+		apis.StaticMount{Prefix: "/app/", StaticConfig: apis.StaticConfig{FS: appFS, IndexFallback: true}},
+		apis.StaticMount{Prefix: "app/admin", StaticConfig: apis.StaticConfig{FS: adminFS}},
+	)
+
+	scenarios := []struct {
+		path           string
+		expectError    bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"", false, 200, "root index.html"},
+		{"about.html", false, 200, "root about"},
+		{"missing", false, 404, "root 404"},
+		{"application", false, 404, "root 404"},
+		{"app", false, 301, ""},
+		{"app/", false, 200, "app index.html"},
+		{"app/test", false, 200, "app test"},
+		{"app/missing/page", false, 200, "app index.html"},
+		{"app/../about.html", false, 200, "root about"},
+		{"app/admin/", false, 200, "admin index.html"},
+		{"app/admin/missing", true, 404, ""},
+	}
+
+	for _, s := range scenarios {
+		t.Run(fmt.Sprintf("%q", s.path), func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/"+s.path, nil)
+			req.SetPathValue(apis.StaticWildcardParam, s.path)
+
+			rec := httptest.NewRecorder()
+
+			e := new(core.RequestEvent)
+			e.App = app
+			e.Request = req
+			e.Response = rec
+
+			err := handler(e)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				apiErr := router.ToApiError(err)
+				if apiErr.Status != s.expectedStatus {
+					t.Fatalf("Expected status code %d, got %d", s.expectedStatus, apiErr.Status)
+				}
+				return
+			}
+
+			if rec.Code != s.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d", s.expectedStatus, rec.Code)
+			}
+
+			if body := rec.Body.String(); body != s.expectedBody {
+				t.Fatalf("Expected body %q, got %q", s.expectedBody, body)
+			}
+		})
+	}
+
+	t.Run("duplicated prefix", func(t *testing.T) {
+		if !hasPanicked(func() {
+			apis.StaticMounts(
+				apis.StaticMount{Prefix: "app", StaticConfig: apis.StaticConfig{FS: appFS}},
+				apis.StaticMount{Prefix: "/app/", StaticConfig: apis.StaticConfig{FS: appFS}},
+			)
+		}) {
+			t.Fatal("Expected to panic")
+		}
+	})
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fatih/color"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// relationsFixNullify removes the dangling ids from the relation field value.
+	relationsFixNullify = "nullify"
+
+	// relationsFixDelete deletes the records with dangling relation ids.
+	relationsFixDelete = "delete"
+)
+
+// NewDBCommand creates and returns new command for running
+// various database maintenance tasks.
+func NewDBCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "db",
+		Short: "Database maintenance helpers",
+	}
+
+	command.AddCommand(dbCheckRelationsCommand(app))
+	command.AddCommand(dbImportSchemaCommand(app))
+
+	return command
+}
+
+func dbCheckRelationsCommand(app core.App) *cobra.Command {
+	var fix string
+
+	command := &cobra.Command{
+		Use:          "check-relations",
+		Example:      "db check-relations --fix=nullify",
+		Short:        "Reports (and optionally fixes) relation fields pointing to missing records",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if fix != "" && fix != relationsFixNullify && fix != relationsFixDelete {
+				return fmt.Errorf("Invalid --fix value %q (must be %q or %q).", fix, relationsFixNullify, relationsFixDelete)
+			}
+
+			orphans, err := findOrphanRelations(app)
+			if err != nil {
+				return fmt.Errorf("Failed to check the relation fields: %w.", err)
+			}
+
+			if len(orphans) == 0 {
+				color.Green("No dangling relation ids were found.")
+				return nil
+			}
+
+			out := command.OutOrStdout()
+			var lastCollection string
+			for _, o := range orphans {
+				if o.collection.Name != lastCollection {
+					lastCollection = o.collection.Name
+					fmt.Fprintf(out, "%s\n", o.collection.Name)
+				}
+				fmt.Fprintf(out, "  %s.%s -> %v\n", o.recordId, o.field.Name, o.ids)
+			}
+			color.Yellow("Found %d record(s) with dangling relation ids.", len(orphans))
+
+			if fix == "" && isInteractive() {
+				prompt := &survey.Select{
+					Message: "How do you want to fix them?",
+					Options: []string{"skip", relationsFixNullify, relationsFixDelete},
+					Default: "skip",
+				}
+				survey.AskOne(prompt, &fix)
+				if fix == "skip" {
+					fix = ""
+				}
+			}
+
+			if fix == "" {
+				return nil
+			}
+
+			if err := fixOrphanRelations(app, orphans, fix); err != nil {
+				return fmt.Errorf("Failed to fix the dangling relation ids: %w.", err)
+			}
+
+			color.Green("Successfully fixed %d record(s) (%s).", len(orphans), fix)
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(
+		&fix,
+		"fix",
+		"",
+		"fix the dangling relation ids by removing them from the field value (nullify) or by deleting their records (delete)",
+	)
+
+	return command
+}
+
+// isInteractive reports whether the stdin is attached to a terminal.
+func isInteractive() bool {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// -------------------------------------------------------------------
+
+type orphanRelation struct {
+	collection *core.Collection
+	field      *core.RelationField
+	recordId   string
+	ids        []string
+}
+
+// findOrphanRelations scans all non-view collection relation fields
+// and returns the ones that refer to missing records.
+func findOrphanRelations(app core.App) ([]*orphanRelation, error) {
+	collections, err := app.FindAllCollections(core.CollectionTypeBase, core.CollectionTypeAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(collections, func(a, b *core.Collection) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	result := []*orphanRelation{}
+
+	for _, collection := range collections {
+		for _, f := range collection.Fields {
+			field, ok := f.(*core.RelationField)
+			if !ok {
+				continue
+			}
+
+			target, err := app.FindCachedCollectionByNameOrId(field.CollectionId)
+			if err != nil {
+				color.Yellow("Skipping %s.%s because its related collection %q is missing.", collection.Name, field.Name, field.CollectionId)
+				continue
+			}
+
+			rows := []struct {
+				RecordId string `db:"recordId"`
+				RelId    string `db:"relId"`
+			}{}
+
+			err = app.DB().Select("r.id as recordId", "je.value as relId").
+				From(collection.Name+" r").
+				InnerJoin(dbutils.JSONEach("r."+field.Name)+" je", nil).
+				AndWhere(dbx.NewExp("je.value != ''")).
+				AndWhere(dbx.NewExp("je.value NOT IN (SELECT [[id]] FROM {{" + target.Name + "}})")).
+				OrderBy("r.id ASC").
+				All(&rows)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", collection.Name, field.Name, err)
+			}
+
+			var last *orphanRelation
+			for _, row := range rows {
+				if last == nil || last.recordId != row.RecordId {
+					last = &orphanRelation{
+						collection: collection,
+						field:      field,
+						recordId:   row.RecordId,
+					}
+					result = append(result, last)
+				}
+				last.ids = append(last.ids, row.RelId)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// fixOrphanRelations either removes the dangling ids from the records
+// relation field value or deletes the records (depending on the fix mode).
+func fixOrphanRelations(app core.App, orphans []*orphanRelation, fix string) error {
+	return app.RunInTransaction(func(txApp core.App) error {
+		for _, o := range orphans {
+			record, err := txApp.FindRecordById(o.collection, o.recordId)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					continue // already deleted (e.g. by a cascade delete)
+				}
+				return err
+			}
+
+			switch fix {
+			case relationsFixDelete:
+				err = txApp.Delete(record)
+			default:
+				record.Set(o.field.Name+"-", o.ids)
+				// the related records are missing and the validation will
+				// most likely fail anyway (e.g. because of a required field)
+				err = txApp.SaveNoValidate(record)
+			}
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", o.collection.Name, o.recordId, err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/schemaimport"
+	"github.com/spf13/cobra"
+)
+
+// schemaRelationMaxSelect is the max select option of the imported multiple relation fields.
+const schemaRelationMaxSelect = 999
+
+func dbImportSchemaCommand(app core.App) *cobra.Command {
+	var format string
+	var dryRun bool
+
+	command := &cobra.Command{
+		Use:          "import-schema [file]",
+		Example:      "db import-schema schema.prisma --dry-run",
+		Short:        "Creates collections from a Postgres/MySQL schema dump, Prisma schema or JSON Schema document",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("Failed to read the schema file: %w.", err)
+			}
+
+			if format == "" {
+				format = schemaimport.DetectFormat(args[0], data)
+				if format == "" {
+					return errors.New("Failed to detect the schema format - please specify it with the --format flag.")
+				}
+			}
+
+			tables, err := schemaimport.Parse(format, data)
+			if err != nil {
+				return fmt.Errorf("Failed to parse the %s schema: %w.", format, err)
+			}
+
+			imported, warnings := schemaTablesToCollections(app, tables)
+			for _, w := range warnings {
+				color.Yellow(w)
+			}
+
+			if len(imported) == 0 {
+				return errors.New("There are no new collections to import.")
+			}
+
+			if dryRun {
+				collections := make([]*core.Collection, len(imported))
+				for i, item := range imported {
+					item.applyRelations()
+					collections[i] = item.collection
+				}
+
+				raw, err := json.MarshalIndent(collections, "", "  ")
+				if err != nil {
+					return err
+				}
+
+				_, err = fmt.Fprintln(command.OutOrStdout(), string(raw))
+				return err
+			}
+
+			// save the collections in 2 passes because the relation
+			// fields require their related collection to exist
+			err = app.RunInTransaction(func(txApp core.App) error {
+				for _, item := range imported {
+					if err := txApp.Save(item.collection); err != nil {
+						return fmt.Errorf("%s: %w", item.collection.Name, err)
+					}
+				}
+
+				for _, item := range imported {
+					if !item.applyRelations() {
+						continue
+					}
+
+					if err := txApp.Save(item.collection); err != nil {
+						return fmt.Errorf("%s: %w", item.collection.Name, err)
+					}
+				}
+
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("Failed to import the collections: %w.", err)
+			}
+
+			color.Green("Successfully imported %d collection(s).", len(imported))
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(
+		&format,
+		"format",
+		"",
+		"the schema format - sql, prisma or jsonschema (default to autodetect)",
+	)
+
+	command.Flags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"print the converted collections as JSON without saving them",
+	)
+
+	return command
+}
+
+// -------------------------------------------------------------------
+
+type schemaRelation struct {
+	pos    int
+	field  *core.RelationField
+	unique bool
+}
+
+type schemaCollection struct {
+	collection *core.Collection
+	relations  []*schemaRelation
+}
+
+// applyRelations adds the deferred relation fields (and their indexes)
+// to the collection and reports whether there were any.
+func (sc *schemaCollection) applyRelations() bool {
+	for _, rel := range sc.relations {
+		sc.collection.Fields.AddAt(rel.pos, rel.field)
+		if rel.unique {
+			addSchemaUniqueIndex(sc.collection, rel.field.Name)
+		}
+	}
+
+	applied := len(sc.relations) > 0
+	sc.relations = nil
+
+	return applied
+}
+
+var schemaInvalidNameCharsRegex = regexp.MustCompile(`\W+`)
+
+// normalizeSchemaName replaces the invalid collection/field name characters.
+func normalizeSchemaName(name string) string {
+	return strings.Trim(schemaInvalidNameCharsRegex.ReplaceAllString(name, "_"), "_")
+}
+
+func addSchemaUniqueIndex(collection *core.Collection, fieldName string) {
+	collection.AddIndex(
+		fmt.Sprintf("idx_%s_%s", collection.Name, fieldName),
+		true,
+		fmt.Sprintf("`%s`", fieldName),
+		"",
+	)
+}
+
+// schemaTablesToCollections converts the parsed schema tables into new
+// base collections using a best-effort column to field type mapping.
+//
+// Tables with the same name as an already existing collection are skipped.
+func schemaTablesToCollections(app core.App, tables []*schemaimport.Table) ([]*schemaCollection, []string) {
+	warnings := []string{}
+
+	// resolve the related collection ids upfront
+	ids := map[string]string{}
+	names := map[string]string{}
+	existing := map[string]bool{}
+	for _, t := range tables {
+		name := normalizeSchemaName(t.Name)
+		if name == "" {
+			continue
+		}
+
+		key := strings.ToLower(t.Name)
+		names[key] = name
+
+		if c, err := app.FindCollectionByNameOrId(name); err == nil {
+			ids[key] = c.Id
+			existing[key] = true
+		} else {
+			ids[key] = core.NewBaseCollection(name).Id
+		}
+	}
+
+	findRelatedId := func(ref string) string {
+		if id, ok := ids[strings.ToLower(ref)]; ok {
+			return id
+		}
+
+		if c, err := app.FindCollectionByNameOrId(normalizeSchemaName(ref)); err == nil {
+			return c.Id
+		}
+
+		return ""
+	}
+
+	result := make([]*schemaCollection, 0, len(tables))
+
+	for _, t := range tables {
+		key := strings.ToLower(t.Name)
+
+		name, ok := names[key]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("Skipping table %q because of its invalid name.", t.Name))
+			continue
+		}
+
+		if existing[key] {
+			warnings = append(warnings, fmt.Sprintf("Skipping table %q because collection %q already exists.", t.Name, name))
+			continue
+		}
+
+		if hasSchemaCollection(result, name) {
+			warnings = append(warnings, fmt.Sprintf("Skipping table %q because of a duplicated collection name.", t.Name))
+			continue
+		}
+
+		item := &schemaCollection{collection: core.NewBaseCollection(name)}
+
+		for _, c := range t.Columns {
+			if c.Primary {
+				continue // replaced by the collection id field
+			}
+
+			fieldName := normalizeSchemaName(c.Name)
+			if err := core.DefaultFieldNameValidationRule(fieldName); err != nil || fieldName == core.FieldNameId {
+				warnings = append(warnings, fmt.Sprintf("Skipping %s.%s because of its invalid or reserved name.", t.Name, c.Name))
+				continue
+			}
+
+			if item.collection.Fields.GetByName(fieldName) != nil || hasSchemaRelation(item.relations, fieldName) {
+				warnings = append(warnings, fmt.Sprintf("Skipping %s.%s because of a duplicated field name.", t.Name, c.Name))
+				continue
+			}
+
+			if c.Kind == schemaimport.KindRelation {
+				if relatedId := findRelatedId(c.Ref); relatedId != "" {
+					maxSelect := 1
+					if c.Multiple {
+						maxSelect = schemaRelationMaxSelect
+					}
+
+					item.relations = append(item.relations, &schemaRelation{
+						pos:    len(item.collection.Fields) + len(item.relations),
+						unique: c.Unique,
+						field: &core.RelationField{
+							Name:         fieldName,
+							CollectionId: relatedId,
+							Required:     c.Required,
+							MaxSelect:    maxSelect,
+						},
+					})
+					continue
+				}
+
+				warnings = append(warnings, fmt.Sprintf("Importing %s.%s as text field because its related table %q is missing.", t.Name, c.Name, c.Ref))
+			}
+
+			item.collection.Fields.Add(schemaColumnToField(fieldName, c))
+
+			if c.Unique {
+				addSchemaUniqueIndex(item.collection, fieldName)
+			}
+		}
+
+		for _, autodate := range []string{"created", "updated"} {
+			if item.collection.Fields.GetByName(autodate) != nil || hasSchemaRelation(item.relations, autodate) {
+				continue
+			}
+
+			item.collection.Fields.Add(&core.AutodateField{
+				Name:     autodate,
+				OnCreate: true,
+				OnUpdate: autodate == "updated",
+			})
+		}
+
+		result = append(result, item)
+	}
+
+	return result, warnings
+}
+
+// schemaColumnToField maps a non-relation schema column to its closest collection field.
+//
+// Note that the number and bool fields are never marked as required
+// because their required validator doesn't allow zero values.
+func schemaColumnToField(name string, c *schemaimport.Column) core.Field {
+	switch c.Kind {
+	case schemaimport.KindNumber:
+		return &core.NumberField{Name: name, OnlyInt: c.Integer}
+	case schemaimport.KindBool:
+		return &core.BoolField{Name: name}
+	case schemaimport.KindDate:
+		return &core.DateField{Name: name, Required: c.Required}
+	case schemaimport.KindJSON:
+		return &core.JSONField{Name: name}
+	case schemaimport.KindEmail:
+		return &core.EmailField{Name: name, Required: c.Required}
+	case schemaimport.KindURL:
+		return &core.URLField{Name: name, Required: c.Required}
+	case schemaimport.KindSelect:
+		if len(c.Values) > 0 {
+			maxSelect := 1
+			if c.Multiple {
+				maxSelect = len(c.Values)
+			}
+			return &core.SelectField{Name: name, Values: c.Values, MaxSelect: maxSelect, Required: c.Required}
+		}
+	}
+
+	return &core.TextField{Name: name, Required: c.Required}
+}
+
+func hasSchemaCollection(items []*schemaCollection, name string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item.collection.Name, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasSchemaRelation(relations []*schemaRelation, name string) bool {
+	for _, rel := range relations {
+		if rel.field.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,262 @@
+package cmd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/cmd"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestDBCheckRelationsCommand(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name   string
+		args   []string
+		verify func(t *testing.T, app core.App, record *core.Record)
+	}{
+		{
+			"report only",
+			[]string{"check-relations"},
+			func(t *testing.T, app core.App, record *core.Record) {
+				fresh, err := app.FindRecordById(record.Collection(), record.Id)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if v := fresh.GetString("rel_one"); v != "missing1" {
+					t.Fatalf("Expected rel_one to be unchanged, got %q", v)
+				}
+
+				if v := fresh.GetStringSlice("rel_many"); !slices.Contains(v, "missing2") {
+					t.Fatalf("Expected rel_many to be unchanged, got %v", v)
+				}
+			},
+		},
+		{
+			"fix with nullify",
+			[]string{"check-relations", "--fix=nullify"},
+			func(t *testing.T, app core.App, record *core.Record) {
+				fresh, err := app.FindRecordById(record.Collection(), record.Id)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if v := fresh.GetString("rel_one"); v != "" {
+					t.Fatalf("Expected empty rel_one, got %q", v)
+				}
+
+				relMany := fresh.GetStringSlice("rel_many")
+				if slices.Contains(relMany, "missing2") {
+					t.Fatalf("Expected the dangling rel_many id to be removed, got %v", relMany)
+				}
+
+				if len(relMany) != len(record.GetStringSlice("rel_many")) {
+					t.Fatalf("Expected the existing rel_many ids to be preserved, got %v", relMany)
+				}
+			},
+		},
+		{
+			"fix with delete",
+			[]string{"check-relations", "--fix=delete"},
+			func(t *testing.T, app core.App, record *core.Record) {
+				_, err := app.FindRecordById(record.Collection(), record.Id)
+				if err == nil {
+					t.Fatal("Expected the record to be deleted")
+				}
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			record, err := app.FindFirstRecordByFilter("demo5", "rel_one != ''")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = app.DB().Update(
+				"demo5",
+				dbx.Params{
+					"rel_one":  "missing1",
+					"rel_many": `["` + strings.Join(append(record.GetStringSlice("rel_many"), "missing2"), `","`) + `"]`,
+				},
+				dbx.HashExp{"id": record.Id},
+			).Execute()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+
+			command := cmd.NewDBCommand(app)
+			command.SetOut(&out)
+			command.SetArgs(s.args)
+
+			if err := command.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			expectedOutput := []string{
+				"demo5\n",
+				record.Id + ".rel_one -> [missing1]",
+				record.Id + ".rel_many -> [missing2]",
+			}
+			for _, str := range expectedOutput {
+				if !strings.Contains(out.String(), str) {
+					t.Fatalf("Expected %q in the report:\n%s", str, out.String())
+				}
+			}
+
+			s.verify(t, app, record)
+		})
+	}
+}
+
+func TestDBCheckRelationsCommandInvalidFix(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	command := cmd.NewDBCommand(app)
+	command.SetArgs([]string{"check-relations", "--fix=invalid"})
+
+	if err := command.Execute(); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
+
+func TestDBImportSchemaCommand(t *testing.T) {
+	t.Parallel()
+
+	schema := `
+		CREATE TABLE users (id serial PRIMARY KEY, email text NOT NULL);
+		CREATE TABLE authors (
+			id serial PRIMARY KEY,
+			name varchar(100) NOT NULL,
+			email varchar(255) UNIQUE,
+			age int NOT NULL
+		);
+		CREATE TABLE books (
+			id serial PRIMARY KEY,
+			title text NOT NULL,
+			author_id int NOT NULL REFERENCES authors(id),
+			owner_id int REFERENCES users(id),
+			published date,
+			"invalid-name!" text
+		);
+	`
+
+	file := filepath.Join(t.TempDir(), "schema.sql")
+	if err := os.WriteFile(file, []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("dry-run", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		var out bytes.Buffer
+
+		command := cmd.NewDBCommand(app)
+		command.SetOut(&out)
+		command.SetArgs([]string{"import-schema", file, "--dry-run"})
+
+		if err := command.Execute(); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, str := range []string{`"name": "authors"`, `"name": "books"`, `"name": "author_id"`, `"name": "invalid_name"`} {
+			if !strings.Contains(out.String(), str) {
+				t.Fatalf("Expected %q in the output:\n%s", str, out.String())
+			}
+		}
+
+		if strings.Contains(out.String(), `"name": "users"`) {
+			t.Fatalf("Didn't expect the existing users collection to be exported:\n%s", out.String())
+		}
+
+		if _, err := app.FindCollectionByNameOrId("authors"); err == nil {
+			t.Fatal("Expected the authors collection to not be saved")
+		}
+	})
+
+	t.Run("import", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		command := cmd.NewDBCommand(app)
+		command.SetArgs([]string{"import-schema", file})
+
+		if err := command.Execute(); err != nil {
+			t.Fatal(err)
+		}
+
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		authors, err := app.FindCollectionByNameOrId("authors")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		books, err := app.FindCollectionByNameOrId("books")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedAuthorsFields := map[string]string{
+			"id":      core.FieldTypeText,
+			"name":    core.FieldTypeText,
+			"email":   core.FieldTypeText,
+			"age":     core.FieldTypeNumber,
+			"created": core.FieldTypeAutodate,
+			"updated": core.FieldTypeAutodate,
+		}
+		if len(authors.Fields) != len(expectedAuthorsFields) {
+			t.Fatalf("Expected %d authors fields, got %v", len(expectedAuthorsFields), authors.Fields.FieldNames())
+		}
+		for name, typ := range expectedAuthorsFields {
+			f := authors.Fields.GetByName(name)
+			if f == nil || f.Type() != typ {
+				t.Fatalf("Expected authors field %q of type %q, got %v", name, typ, f)
+			}
+		}
+
+		if !authors.Fields.GetByName("name").(*core.TextField).Required {
+			t.Fatal("Expected authors.name to be required")
+		}
+
+		if len(authors.Indexes) != 1 || !strings.Contains(authors.Indexes[0], "UNIQUE") {
+			t.Fatalf("Expected a single unique authors index, got %v", authors.Indexes)
+		}
+
+		expectedBooksFields := []string{"id", "title", "author_id", "owner_id", "published", "invalid_name", "created", "updated"}
+		if names := books.Fields.FieldNames(); !slices.Equal(names, expectedBooksFields) {
+			t.Fatalf("Expected books fields %v, got %v", expectedBooksFields, names)
+		}
+
+		authorRel, _ := books.Fields.GetByName("author_id").(*core.RelationField)
+		if authorRel == nil || authorRel.CollectionId != authors.Id || !authorRel.Required {
+			t.Fatalf("Expected required author_id relation to authors, got %v", authorRel)
+		}
+
+		ownerRel, _ := books.Fields.GetByName("owner_id").(*core.RelationField)
+		if ownerRel == nil || ownerRel.CollectionId != users.Id {
+			t.Fatalf("Expected owner_id relation to the existing users collection, got %v", ownerRel)
+		}
+	})
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// NewDiagnoseCommand creates and returns new command for generating
+// a diagnostics bundle archive that could be shared with support requests.
+//
+// The archive contains the app version and runtime info, the app settings
+// (with the secrets redacted), the db integrity check results, the data dir
+// disk usage, the pending migrations and the most recent error logs.
+func NewDiagnoseCommand(app core.App) *cobra.Command {
+	var output string
+	var logsLimit int
+
+	command := &cobra.Command{
+		Use:          "diagnose",
+		Short:        "Generates a diagnostics bundle archive for support requests",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if output == "" {
+				output = fmt.Sprintf("pb_diagnostics_%s.zip", time.Now().UTC().Format("20060102150405"))
+			}
+
+			if err := createDiagnosticsArchive(app, output, command.Root().Version, logsLimit); err != nil {
+				return fmt.Errorf("Failed to create diagnostics archive: %w.", err)
+			}
+
+			color.Green("Successfully created diagnostics archive %q.", output)
+			color.Yellow("Please review its content before sharing it.")
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(
+		&output,
+		"output",
+		"o",
+		"",
+		"the archive destination path (default to pb_diagnostics_{timestamp}.zip)",
+	)
+
+	command.Flags().IntVar(
+		&logsLimit,
+		"logs",
+		100,
+		"the max number of the most recent error logs to include",
+	)
+
+	return command
+}
+
+func createDiagnosticsArchive(app core.App, output string, version string, logsLimit int) (err error) {
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(f)
+
+	defer func() {
+		err = errors.Join(err, zw.Close(), f.Close())
+		if err != nil {
+			os.Remove(output)
+		}
+	}()
+
+	entries := []struct {
+		name string
+		data func() (any, error)
+	}{
+		{"info.json", func() (any, error) { return diagnoseInfo(app, version), nil }},
+		{"settings.json", func() (any, error) { return app.Settings(), nil }},
+		{"integrity.json", func() (any, error) { return diagnoseIntegrity(app), nil }},
+		{"disk.json", func() (any, error) { return diagnoseDiskUsage(app.DataDir()) }},
+		{"migrations.json", func() (any, error) { return diagnoseMigrations(app) }},
+		{"logs.json", func() (any, error) { return diagnoseErrorLogs(app, logsLimit) }},
+	}
+
+	for _, entry := range entries {
+		data, dataErr := entry.data()
+		if dataErr != nil {
+			// store the error in the archive instead of failing the entire bundle
+			data = map[string]string{"error": dataErr.Error()}
+		}
+
+		raw, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		w, err := zw.Create(entry.name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := w.Write(raw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func diagnoseInfo(app core.App, version string) map[string]any {
+	return map[string]any{
+		"version":   version,
+		"goVersion": runtime.Version(),
+		"os":        runtime.GOOS,
+		"arch":      runtime.GOARCH,
+		"numCPU":    runtime.NumCPU(),
+		"isDev":     app.IsDev(),
+		"dataDir":   app.DataDir(),
+		"generated": time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func diagnoseIntegrity(app core.App) map[string]any {
+	check := func(db dbx.Builder) any {
+		result := []string{}
+
+		if err := db.NewQuery("PRAGMA integrity_check").Column(&result); err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+
+		return result
+	}
+
+	return map[string]any{
+		"data":      check(app.DB()),
+		"auxiliary": check(app.AuxDB()),
+	}
+}
+
+// diagnoseDiskUsage returns the total size in bytes of the data dir
+// and of each of its top level entries.
+func diagnoseDiskUsage(dataDir string) (map[string]any, error) {
+	entries := map[string]int64{}
+	var total int64
+
+	err := filepath.WalkDir(dataDir, func(path string, d fs.DirEntry, err error) error {
+		// ignore files deleted during the walk (e.g. temp or db journal files)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dataDir, path)
+		if err != nil {
+			return err
+		}
+
+		top, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+
+		entries[top] += info.Size()
+		total += info.Size()
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"total":   total,
+		"entries": entries,
+	}, nil
+}
+
+func diagnoseMigrations(app core.App) (map[string]any, error) {
+	system, err := core.NewMigrationsRunner(app, core.SystemMigrations).Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := core.NewMigrationsRunner(app, core.AppMigrations).Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"pendingSystem": system,
+		"pendingApp":    local,
+	}, nil
+}
+
+func diagnoseErrorLogs(app core.App, limit int) ([]*core.Log, error) {
+	logs := []*core.Log{}
+
+	if limit <= 0 {
+		return logs, nil
+	}
+
+	err := app.LogQuery().
+		AndWhere(dbx.NewExp("level >= {:level}", dbx.Params{"level": int(slog.LevelError)})).
+		OrderBy("created DESC").
+		Limit(int64(limit)).
+		All(&logs)
+
+	return logs, err
+}
@@ -0,0 +1,154 @@
+package cmd_test
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/cmd"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestDiagnoseCommand(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().SMTP.Password = "test_smtp_password"
+	app.Settings().S3.Secret = "test_s3_secret"
+
+	// mock error and info logs
+	logs := []*core.Log{
+		{BaseModel: core.BaseModel{Id: "test_error_log"}, Message: "test_error", Level: 8, Created: types.NowDateTime()},
+		{BaseModel: core.BaseModel{Id: "test_info_log"}, Message: "test_info", Level: 0, Created: types.NowDateTime()},
+	}
+	for _, l := range logs {
+		if err := app.AuxSave(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := filepath.Join(t.TempDir(), "diagnostics.zip")
+
+	command := cmd.NewDiagnoseCommand(app)
+	command.SetArgs([]string{"-o", output})
+
+	if err := command.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.OpenReader(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	files := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		raw, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !json.Valid(raw) {
+			t.Fatalf("Expected %s to be valid json, got %s", f.Name, raw)
+		}
+
+		files[f.Name] = string(raw)
+	}
+
+	expectations := map[string][]string{
+		"info.json":       {`"goVersion":`, `"dataDir":`},
+		"settings.json":   {`"smtp":`, `"s3":`},
+		"integrity.json":  {`"data": [`, `"auxiliary": [`, `"ok"`},
+		"disk.json":       {`"total":`, `"data.db":`, `"auxiliary.db":`},
+		"migrations.json": {`"pendingSystem": []`, `"pendingApp": []`},
+		"logs.json":       {`"test_error"`},
+	}
+
+	for name, parts := range expectations {
+		content, ok := files[name]
+		if !ok {
+			t.Fatalf("Missing archive file %s", name)
+		}
+
+		for _, part := range parts {
+			if !strings.Contains(content, part) {
+				t.Errorf("Expected %s to contain %s, got\n%s", name, part, content)
+			}
+		}
+	}
+
+	if len(files) != len(expectations) {
+		t.Fatalf("Expected %d archive files, got %d", len(expectations), len(files))
+	}
+
+	// secrets and non-error logs
+	notExpected := []string{"test_smtp_password", "test_s3_secret", "test_info"}
+	for name, content := range files {
+		for _, part := range notExpected {
+			if strings.Contains(content, part) {
+				t.Errorf("Didn't expect %s to contain %s", name, part)
+			}
+		}
+	}
+}
+
+func TestDiagnoseCommandWithoutLogs(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := app.AuxSave(&core.Log{BaseModel: core.BaseModel{Id: "test_error_log"}, Message: "test_error", Level: 8}); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(t.TempDir(), "diagnostics.zip")
+
+	command := cmd.NewDiagnoseCommand(app)
+	command.SetArgs([]string{"-o", output, "--logs", "0"})
+
+	if err := command.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.OpenReader(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.Name != "logs.json" {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+
+		raw, _ := io.ReadAll(rc)
+		if string(raw) != "[]" {
+			t.Fatalf("Expected empty logs, got %s", raw)
+		}
+
+		return
+	}
+
+	t.Fatal("Missing logs.json")
+}
@@ -0,0 +1,499 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// fakeBatchSize is the number of generated records saved in a single transaction.
+	fakeBatchSize = 500
+
+	// fakeMaxAttempts is the max number of attempts to generate a valid
+	// record (e.g. in case of a unique constraint violation).
+	fakeMaxAttempts = 10
+
+	// fakeMaxRelIds is the max number of loaded related collection ids.
+	fakeMaxRelIds = 1000
+)
+
+// NewFakeCommand creates and returns new command for populating a
+// collection with random schema aware records (e.g. for staging
+// databases, load tests and benchmarks).
+func NewFakeCommand(app core.App) *cobra.Command {
+	var collectionNameOrId string
+	var count int
+	var seed uint64
+
+	command := &cobra.Command{
+		Use:          "fake",
+		Example:      "fake --collection=posts --count=10000",
+		Short:        "Generates random records for the specified collection",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if count <= 0 {
+				return errors.New("The --count flag must be a positive number.")
+			}
+
+			collection, err := app.FindCollectionByNameOrId(collectionNameOrId)
+			if err != nil {
+				return fmt.Errorf("Missing or invalid collection %q.", collectionNameOrId)
+			}
+
+			if collection.IsView() {
+				return errors.New("Records cannot be generated for view collections.")
+			}
+
+			if seed == 0 {
+				seed = rand.Uint64()
+			}
+
+			f := newFaker(collection, seed)
+
+			for generated := 0; generated < count; {
+				batch := min(fakeBatchSize, count-generated)
+
+				err := app.RunInTransaction(func(txApp core.App) error {
+					for i := 0; i < batch; i++ {
+						if err := f.create(txApp); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+				if err != nil {
+					return fmt.Errorf("Failed to generate %q record: %w", collection.Name, err)
+				}
+
+				generated += batch
+			}
+
+			color.Green("Successfully generated %d %q records (seed %d).", count, collection.Name, seed)
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(
+		&collectionNameOrId,
+		"collection",
+		"",
+		"the name or id of the collection to populate",
+	)
+	command.MarkFlagRequired("collection")
+
+	command.Flags().IntVar(
+		&count,
+		"count",
+		10,
+		"the number of records to generate",
+	)
+
+	command.Flags().Uint64Var(
+		&seed,
+		"seed",
+		0,
+		"optional seed of the field values generator (default to random)",
+	)
+
+	return command
+}
+
+// -------------------------------------------------------------------
+
+var fakeWords = strings.Fields(`lorem ipsum dolor sit amet consectetur adipiscing elit sed do
+	eiusmod tempor incididunt ut labore et dolore magna aliqua enim ad minim veniam quis nostrud
+	exercitation ullamco laboris nisi aliquip ex ea commodo consequat duis aute irure in
+	reprehenderit voluptate velit esse cillum fugiat nulla pariatur excepteur sint occaecat
+	cupidatat non proident sunt culpa qui officia deserunt mollit anim id est laborum`)
+
+var fakeFirstNames = strings.Fields(`james mary robert patricia john jennifer michael linda david
+	elizabeth william barbara richard susan joseph jessica thomas sarah charles karen maria lucas`)
+
+var fakeLastNames = strings.Fields(`smith johnson williams brown jones garcia miller davis rodriguez
+	martinez hernandez lopez gonzalez wilson anderson thomas taylor moore jackson martin lee`)
+
+var fakeDomains = []string{"example.com", "example.org", "example.net"}
+
+// 1x1 transparent png
+var fakePNG, _ = base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII=")
+
+type faker struct {
+	collection   *core.Collection
+	rnd          *rand.Rand
+	uniqueFields map[string]bool
+	relIds       map[string][]string
+}
+
+func newFaker(collection *core.Collection, seed uint64) *faker {
+	f := &faker{
+		collection:   collection,
+		rnd:          rand.New(rand.NewPCG(seed, seed)),
+		uniqueFields: map[string]bool{},
+		relIds:       map[string][]string{},
+	}
+
+	// single column unique indexes
+	for _, raw := range collection.Indexes {
+		index := dbutils.ParseIndex(raw)
+		if index.Unique && len(index.Columns) == 1 {
+			f.uniqueFields[index.Columns[0].Name] = true
+		}
+	}
+
+	return f
+}
+
+// create generates and persists a single new collection record.
+func (f *faker) create(app core.App) error {
+	var err error
+
+	for attempt := 0; attempt < fakeMaxAttempts; attempt++ {
+		record := core.NewRecord(f.collection)
+
+		for _, field := range f.collection.Fields {
+			if err := f.fill(app, record, field); err != nil {
+				return err
+			}
+		}
+
+		err = app.Save(record)
+		if err == nil {
+			if ids, ok := f.relIds[f.collection.Id]; ok && len(ids) < fakeMaxRelIds {
+				f.relIds[f.collection.Id] = append(ids, record.Id)
+			}
+			return nil
+		}
+	}
+
+	return err
+}
+
+// fill sets a random value for the specified record field.
+func (f *faker) fill(app core.App, record *core.Record, field core.Field) error {
+	name := field.GetName()
+	unique := f.uniqueFields[name]
+
+	switch field := field.(type) {
+	case *core.TextField:
+		if field.PrimaryKey || field.AutogeneratePattern != "" || field.AutogenerateStrategy != "" {
+			return nil // autogenerated
+		}
+		record.Set(name, f.text(field, unique))
+	case *core.EditorField:
+		record.Set(name, "<p>"+f.sentences(1+f.rnd.IntN(3))+"</p>")
+	case *core.NumberField:
+		record.Set(name, f.number(field))
+	case *core.BoolField:
+		record.Set(name, field.Required || f.rnd.IntN(2) == 1)
+	case *core.EmailField:
+		record.Set(name, f.firstName()+"."+f.lastName()+"."+f.suffix()+"@"+f.domain(field.OnlyDomains, field.ExceptDomains))
+	case *core.URLField:
+		record.Set(name, "https://"+f.domain(field.OnlyDomains, field.ExceptDomains)+"/"+f.slug())
+	case *core.DateField:
+		record.Set(name, f.date(field))
+	case *core.SelectField:
+		if unique && !field.Required {
+			return nil
+		}
+		record.Set(name, f.pick(field.Values, 1, max(1, field.MaxSelect)))
+	case *core.RelationField:
+		if unique && !field.Required {
+			return nil
+		}
+		ids, err := f.relationIds(app, field.CollectionId)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			if field.Required || field.MinSelect > 0 {
+				return fmt.Errorf("missing %q related records", name)
+			}
+			return nil
+		}
+		record.Set(name, f.pick(ids, max(1, field.MinSelect), max(1, field.MaxSelect)))
+	case *core.JSONField:
+		record.Set(name, map[string]any{
+			"id":     f.rnd.IntN(1000),
+			"label":  f.words(1 + f.rnd.IntN(3)),
+			"active": f.rnd.IntN(2) == 1,
+		})
+	case *core.FileField:
+		// generate files only for the required fields to avoid
+		// unnecessary populating the storage
+		if !field.Required {
+			return nil
+		}
+		file, err := f.file(field)
+		if err != nil {
+			return err
+		}
+		record.Set(name, file)
+	case *core.PasswordField:
+		if field.Pattern != "" {
+			password, err := security.RandomStringByRegex(field.Pattern)
+			if err != nil {
+				return err
+			}
+			record.Set(name, password)
+		} else {
+			record.Set(name, security.RandomString(max(12, field.Min)))
+		}
+	case *core.VectorField:
+		vector := make([]float64, field.Dimensions)
+		for i := range vector {
+			vector[i] = f.rnd.Float64()*2 - 1
+		}
+		record.Set(name, vector)
+	case *core.GeoPointField:
+		record.Set(name, types.GeoPoint{
+			Lat: f.rnd.Float64()*180 - 90,
+			Lon: f.rnd.Float64()*360 - 180,
+		})
+	}
+
+	return nil
+}
+
+func (f *faker) text(field *core.TextField, unique bool) string {
+	if field.Pattern != "" {
+		if v, err := security.RandomStringByRegex(field.Pattern); err == nil {
+			return v
+		}
+	}
+
+	name := strings.ToLower(field.Name)
+
+	var v string
+	switch {
+	case strings.Contains(name, "name"):
+		v = capitalize(f.firstName()) + " " + capitalize(f.lastName())
+	case strings.Contains(name, "title"):
+		v = capitalize(f.words(3 + f.rnd.IntN(4)))
+	case strings.Contains(name, "slug"):
+		v = f.slug()
+	case strings.Contains(name, "phone"):
+		v = "+1555" + strconv.Itoa(1000000+f.rnd.IntN(9000000))
+	case strings.Contains(name, "description"), strings.Contains(name, "content"),
+		strings.Contains(name, "body"), strings.Contains(name, "summary"), strings.Contains(name, "bio"):
+		v = f.sentences(1 + f.rnd.IntN(3))
+	default:
+		v = f.words(1 + f.rnd.IntN(3))
+	}
+
+	maxLength := field.Max
+	if maxLength <= 0 {
+		maxLength = 5000
+	}
+
+	if unique {
+		suffix := "-" + f.suffix()
+		v = truncate(v, maxLength-len(suffix)) + suffix
+	}
+
+	if length := utf8.RuneCountInString(v); length < field.Min {
+		v += strings.Repeat("x", field.Min-length)
+	}
+
+	return truncate(v, maxLength)
+}
+
+func (f *faker) number(field *core.NumberField) float64 {
+	minValue := 0.0
+	if field.Min != nil {
+		minValue = *field.Min
+	}
+
+	maxValue := minValue + 1000
+	if field.Max != nil {
+		maxValue = *field.Max
+	}
+
+	if field.OnlyInt {
+		low, high := int64(minValue), int64(maxValue)
+		if float64(low) < minValue {
+			low++
+		}
+		if high <= low {
+			return float64(low)
+		}
+		return float64(low + f.rnd.Int64N(high-low+1))
+	}
+
+	v := minValue + f.rnd.Float64()*(maxValue-minValue)
+
+	return float64(int64(v*100)) / 100
+}
+
+func (f *faker) date(field *core.DateField) types.DateTime {
+	end := time.Now()
+	if !field.Max.IsZero() {
+		end = field.Max.Time()
+	}
+
+	start := end.AddDate(-1, 0, 0)
+	if !field.Min.IsZero() {
+		start = field.Min.Time()
+	}
+
+	var offset time.Duration
+	if diff := end.Sub(start); diff > 0 {
+		offset = time.Duration(f.rnd.Int64N(int64(diff)))
+	}
+
+	dt, _ := types.ParseDateTime(start.Add(offset))
+
+	return dt
+}
+
+func (f *faker) file(field *core.FileField) (*filesystem.File, error) {
+	if len(field.MimeTypes) == 0 || slices.Contains(field.MimeTypes, "text/plain") {
+		return filesystem.NewFileFromBytes([]byte(f.sentences(1)), "fake.txt")
+	}
+
+	if slices.Contains(field.MimeTypes, "image/png") {
+		return filesystem.NewFileFromBytes(fakePNG, "fake.png")
+	}
+
+	return nil, fmt.Errorf("unsupported %q file mime types", field.Name)
+}
+
+// relationIds returns the cached ids of the specified related collection.
+func (f *faker) relationIds(app core.App, collectionId string) ([]string, error) {
+	if ids, ok := f.relIds[collectionId]; ok {
+		return ids, nil
+	}
+
+	collection, err := app.FindCachedCollectionByNameOrId(collectionId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	err = app.RecordQuery(collection).
+		Select("id").
+		OrderBy("RANDOM()").
+		Limit(fakeMaxRelIds).
+		Column(&ids)
+	if err != nil {
+		return nil, err
+	}
+
+	f.relIds[collectionId] = ids
+
+	return ids, nil
+}
+
+// pick returns minSelect-maxSelect random values from the provided list
+// (returns a single value if maxSelect is 1).
+func (f *faker) pick(values []string, minSelect int, maxSelect int) any {
+	if len(values) == 0 {
+		return nil
+	}
+
+	if maxSelect <= 1 {
+		return values[f.rnd.IntN(len(values))]
+	}
+
+	total := min(len(values), minSelect+f.rnd.IntN(max(1, maxSelect-minSelect+1)))
+
+	shuffled := slices.Clone(values)
+	f.rnd.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return shuffled[:total]
+}
+
+func (f *faker) words(total int) string {
+	words := make([]string, total)
+	for i := range words {
+		words[i] = fakeWords[f.rnd.IntN(len(fakeWords))]
+	}
+
+	return strings.Join(words, " ")
+}
+
+func (f *faker) sentences(total int) string {
+	sentences := make([]string, total)
+	for i := range sentences {
+		sentences[i] = capitalize(f.words(5+f.rnd.IntN(10))) + "."
+	}
+
+	return strings.Join(sentences, " ")
+}
+
+func (f *faker) slug() string {
+	return strings.ReplaceAll(f.words(2+f.rnd.IntN(3)), " ", "-") + "-" + f.suffix()
+}
+
+func (f *faker) firstName() string {
+	return fakeFirstNames[f.rnd.IntN(len(fakeFirstNames))]
+}
+
+func (f *faker) lastName() string {
+	return fakeLastNames[f.rnd.IntN(len(fakeLastNames))]
+}
+
+// suffix returns a short random string (usually used to minimize
+// the chance of unique constraint violations).
+func (f *faker) suffix() string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	b := make([]byte, 8)
+	for i := range b {
+		b[i] = alphabet[f.rnd.IntN(len(alphabet))]
+	}
+
+	return string(b)
+}
+
+func (f *faker) domain(onlyDomains []string, exceptDomains []string) string {
+	if len(onlyDomains) > 0 {
+		return onlyDomains[f.rnd.IntN(len(onlyDomains))]
+	}
+
+	for _, d := range fakeDomains {
+		if !slices.Contains(exceptDomains, d) {
+			return d
+		}
+	}
+
+	return "fake-" + f.suffix() + ".com"
+}
+
+func capitalize(str string) string {
+	if str == "" {
+		return str
+	}
+
+	return strings.ToUpper(str[:1]) + str[1:]
+}
+
+func truncate(str string, maxRunes int) string {
+	if maxRunes <= 0 {
+		return ""
+	}
+
+	if utf8.RuneCountInString(str) <= maxRunes {
+		return str
+	}
+
+	return string([]rune(str)[:maxRunes])
+}
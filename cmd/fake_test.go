@@ -0,0 +1,59 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/cmd"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestFakeCommand(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name        string
+		args        []string
+		collection  string
+		expectError bool
+		expectTotal int
+	}{
+		{"missing collection flag", []string{}, "", true, 0},
+		{"missing collection", []string{"--collection", "missing"}, "", true, 0},
+		{"view collection", []string{"--collection", "view1"}, "", true, 0},
+		{"invalid count", []string{"--collection", "demo1", "--count", "0"}, "demo1", true, 3},
+		{"base collection with relations", []string{"--collection", "demo1", "--count", "30", "--seed", "1"}, "demo1", false, 33},
+		{"base collection with unique field", []string{"--collection", "demo2", "--count", "50"}, "demo2", false, 53},
+		{"base collection with required relations", []string{"--collection", "demo4", "--count", "5"}, "demo4", false, 7},
+		{"auth collection", []string{"--collection", "clients", "--count", "5"}, "clients", false, 7},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			command := cmd.NewFakeCommand(app)
+			command.SetArgs(s.args)
+
+			err := command.Execute()
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if s.collection == "" {
+				return
+			}
+
+			total, err := app.CountRecords(s.collection)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if int(total) != s.expectTotal {
+				t.Fatalf("Expected %d %q records, got %d", s.expectTotal, s.collection, total)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// NewSettingsCommand creates and returns new command for exporting
+// and importing the app settings between environments.
+func NewSettingsCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "settings",
+		Short: "Export or import the app settings",
+	}
+
+	command.AddCommand(settingsExportCommand(app))
+	command.AddCommand(settingsImportCommand(app))
+
+	return command
+}
+
+func settingsExportCommand(app core.App) *cobra.Command {
+	var encryptKey string
+	var output string
+
+	command := &cobra.Command{
+		Use:          "export",
+		Example:      "settings export --encrypt-key=12345678901234567890123456789012 -o settings.json",
+		Short:        "Exports the app settings (the secrets are included only if --encrypt-key is set)",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			export, err := app.Settings().Export(encryptKey)
+			if err != nil {
+				return fmt.Errorf("Failed to export settings: %w.", err)
+			}
+
+			raw, err := json.MarshalIndent(export, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				_, err = fmt.Fprintln(command.OutOrStdout(), string(raw))
+				return err
+			}
+
+			if err := os.WriteFile(output, raw, 0600); err != nil {
+				return fmt.Errorf("Failed to write the settings export: %w.", err)
+			}
+
+			color.Green("Successfully exported the app settings to %q.", output)
+			if encryptKey == "" {
+				color.Yellow("The settings secrets were not included because --encrypt-key is not set.")
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(
+		&encryptKey,
+		"encrypt-key",
+		"",
+		"32 characters key used to encrypt and include the settings secrets in the export",
+	)
+
+	command.Flags().StringVarP(
+		&output,
+		"output",
+		"o",
+		"",
+		"the export destination file path (default to stdout)",
+	)
+
+	return command
+}
+
+func settingsImportCommand(app core.App) *cobra.Command {
+	var encryptKey string
+
+	command := &cobra.Command{
+		Use:          "import",
+		Example:      "settings import settings.json --encrypt-key=12345678901234567890123456789012",
+		Short:        "Imports the app settings from a settings export file",
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Missing settings export file path argument.")
+			}
+
+			raw, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("Failed to read the settings export: %w.", err)
+			}
+
+			export := &core.SettingsExport{}
+			if err := json.Unmarshal(raw, export); err != nil {
+				return fmt.Errorf("Failed to parse the settings export: %w.", err)
+			}
+
+			settings, err := app.Settings().Clone()
+			if err != nil {
+				return err
+			}
+
+			if err := settings.Import(export, encryptKey); err != nil {
+				return fmt.Errorf("Failed to import settings: %w.", err)
+			}
+
+			if err := app.Save(settings); err != nil {
+				return fmt.Errorf("Failed to save the imported settings: %w.", err)
+			}
+
+			color.Green("Successfully imported the app settings from %q.", args[0])
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(
+		&encryptKey,
+		"encrypt-key",
+		"",
+		"the 32 characters key used to encrypt the settings export secrets",
+	)
+
+	return command
+}
@@ -0,0 +1,60 @@
+package cmd_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/cmd"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestSettingsExportImportCommands(t *testing.T) {
+	t.Parallel()
+
+	key := "abcdefghijklmnopqrstuvwxyz123456"
+
+	source, _ := tests.NewTestApp()
+	defer source.Cleanup()
+
+	source.Settings().Meta.AppName = "exported_test"
+	source.Settings().SMTP.Password = "smtp_password"
+
+	output := filepath.Join(t.TempDir(), "settings.json")
+
+	exportCommand := cmd.NewSettingsCommand(source)
+	exportCommand.SetArgs([]string{"export", "--encrypt-key", key, "-o", output})
+	if err := exportCommand.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	target, _ := tests.NewTestApp()
+	defer target.Cleanup()
+
+	// missing file argument
+	importCommand := cmd.NewSettingsCommand(target)
+	importCommand.SetArgs([]string{"import"})
+	if err := importCommand.Execute(); err == nil {
+		t.Fatal("Expected missing argument error")
+	}
+
+	// missing encryption key
+	importCommand = cmd.NewSettingsCommand(target)
+	importCommand.SetArgs([]string{"import", output})
+	if err := importCommand.Execute(); err == nil {
+		t.Fatal("Expected missing encryption key error")
+	}
+
+	importCommand = cmd.NewSettingsCommand(target)
+	importCommand.SetArgs([]string{"import", output, "--encrypt-key", key})
+	if err := importCommand.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if target.Settings().Meta.AppName != "exported_test" {
+		t.Fatalf("Expected app name %q, got %q", "exported_test", target.Settings().Meta.AppName)
+	}
+
+	if target.Settings().SMTP.Password != "smtp_password" {
+		t.Fatalf("Expected SMTP password %q, got %q", "smtp_password", target.Settings().SMTP.Password)
+	}
+}
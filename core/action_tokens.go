@@ -0,0 +1,217 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+// Builtin action link actions.
+const (
+	// ActionVerify marks the auth record as verified.
+	ActionVerify = "verify"
+
+	// ActionUpdate updates the record with the token data
+	// (e.g. {"newsletter": false} for an unsubscribe link).
+	ActionUpdate = "update"
+)
+
+const actionTokenUsedCacheKeyPrefix = "@actionTokenUsed_"
+
+// Common action token related errors
+var (
+	ErrInvalidActionToken = errors.New("invalid or expired action token")
+	ErrActionTokenUsed    = errors.New("the action token has already been used")
+)
+
+// ActionToken is a verified signed single-use action token
+// (see [App.NewActionToken] and [App.FindActionToken]).
+type ActionToken struct {
+	// Record is the action token target record.
+	Record *Record
+
+	// Data is the optional action specific data signed with the token.
+	Data map[string]any
+
+	// Expires is the action token expiration time.
+	Expires time.Time
+
+	// Action is the name of the action to execute (e.g. "verify", "unsubscribe").
+	Action string
+
+	// Nonce is the unique token identifier used for the replay protection.
+	Nonce string
+}
+
+// NewActionToken generates and returns a new signed single-use token
+// for executing the specified action on the provided record without login
+// (e.g. for confirmation, unsubscribe or approval email/SMS links).
+//
+// The optional data is signed together with the token and it is
+// available to the action handlers (see [App.OnActionLinkRequest]).
+//
+// Zero or negative duration will fallback to [ActionLinksConfig.Duration].
+func (app *BaseApp) NewActionToken(action string, record *Record, data map[string]any, duration time.Duration) (string, error) {
+	if action == "" {
+		return "", errors.New("missing action name")
+	}
+
+	if record == nil || record.Id == "" {
+		return "", errors.New("missing action record")
+	}
+
+	secret, err := app.actionTokensSecret()
+	if err != nil {
+		return "", err
+	}
+
+	if duration <= 0 {
+		duration = app.Settings().ActionLinks.DurationTime()
+	}
+
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	claims := jwt.MapClaims{
+		TokenClaimType:         TokenTypeAction,
+		TokenClaimId:           record.Id,
+		TokenClaimCollectionId: record.Collection().Id,
+		TokenClaimAction:       action,
+		TokenClaimData:         data,
+		TokenClaimNonce:        security.RandomString(30),
+	}
+
+	return app.TokenSigner().Sign(claims, secret+record.Collection().Id, duration)
+}
+
+// FindActionToken verifies the provided signed action token
+// and returns its loaded data.
+//
+// Returns [ErrInvalidActionToken] if the token is invalid, expired or its
+// record is missing and [ErrActionTokenUsed] if the token was already used.
+func (app *BaseApp) FindActionToken(token string) (*ActionToken, error) {
+	unverified, err := security.ParseUnverifiedJWT(token)
+	if err != nil || cast.ToString(unverified[TokenClaimType]) != TokenTypeAction {
+		return nil, ErrInvalidActionToken
+	}
+
+	collection, err := app.FindCachedCollectionByNameOrId(cast.ToString(unverified[TokenClaimCollectionId]))
+	if err != nil {
+		return nil, ErrInvalidActionToken
+	}
+
+	// no tokens could have been issued without a secret
+	secret := app.Settings().ActionLinks.Secret
+	if secret == "" {
+		return nil, ErrInvalidActionToken
+	}
+
+	claims, err := app.TokenSigner().Verify(token, secret+collection.Id)
+	if err != nil {
+		return nil, ErrInvalidActionToken
+	}
+
+	result := &ActionToken{
+		Action:  cast.ToString(claims[TokenClaimAction]),
+		Nonce:   cast.ToString(claims[TokenClaimNonce]),
+		Data:    cast.ToStringMap(claims[TokenClaimData]),
+		Expires: time.Unix(cast.ToInt64(claims["exp"]), 0),
+	}
+	if result.Action == "" || result.Nonce == "" {
+		return nil, ErrInvalidActionToken
+	}
+
+	result.Record, err = app.FindRecordById(collection, cast.ToString(claims[TokenClaimId]))
+	if err != nil {
+		return nil, ErrInvalidActionToken
+	}
+
+	used, err := app.isActionTokenUsed(result.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if used {
+		return nil, ErrActionTokenUsed
+	}
+
+	return result, nil
+}
+
+// actionTokensInFlight stores the nonces of the currently executing action tokens.
+var actionTokensInFlight sync.Map
+
+// ConsumeActionToken executes fn and marks the action token as used
+// if fn completes without error.
+//
+// Returns [ErrActionTokenUsed] if the token was already used
+// or it is currently being consumed by another request.
+//
+// Note that the used tokens are tracked in [App.Cache] until their expiration,
+// meaning that the replay protection across multiple app instances
+// requires a shared (e.g. Redis) cache store.
+func (app *BaseApp) ConsumeActionToken(token *ActionToken, fn func() error) error {
+	if _, loaded := actionTokensInFlight.LoadOrStore(token.Nonce, struct{}{}); loaded {
+		return ErrActionTokenUsed
+	}
+	defer actionTokensInFlight.Delete(token.Nonce)
+
+	used, err := app.isActionTokenUsed(token.Nonce)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrActionTokenUsed
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	// keep the used mark a little longer to account for clock skew
+	ttl := time.Until(token.Expires) + time.Minute
+
+	return app.Cache().Set(actionTokenUsedCacheKeyPrefix+token.Nonce, []byte{1}, ttl)
+}
+
+func (app *BaseApp) isActionTokenUsed(nonce string) (bool, error) {
+	_, found, err := app.Cache().Get(actionTokenUsedCacheKeyPrefix + nonce)
+
+	return found, err
+}
+
+// actionTokensSecretMu guards the action tokens secret generation.
+var actionTokensSecretMu sync.Mutex
+
+// actionTokensSecret returns the configured action tokens secret.
+//
+// If the app settings doesn't have a secret, a new one is generated and persisted.
+func (app *BaseApp) actionTokensSecret() (string, error) {
+	if secret := app.Settings().ActionLinks.Secret; secret != "" {
+		return secret, nil
+	}
+
+	actionTokensSecretMu.Lock()
+	defer actionTokensSecretMu.Unlock()
+
+	// check again in case it was generated while waiting for the lock
+	if secret := app.Settings().ActionLinks.Secret; secret != "" {
+		return secret, nil
+	}
+
+	settings, err := app.Settings().Clone()
+	if err != nil {
+		return "", err
+	}
+	settings.ActionLinks.Secret = security.RandomString(50)
+
+	if err := app.Save(settings); err != nil {
+		return "", err
+	}
+
+	return settings.ActionLinks.Secret, nil
+}
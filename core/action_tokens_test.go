@@ -0,0 +1,216 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestNewActionToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.NewActionToken("", record, nil, 0); err == nil {
+		t.Fatal("Expected error for missing action")
+	}
+
+	if _, err := app.NewActionToken("test", nil, nil, 0); err == nil {
+		t.Fatal("Expected error for missing record")
+	}
+
+	if app.Settings().ActionLinks.Secret != "" {
+		t.Fatal("Expected empty action links secret")
+	}
+
+	token, err := app.NewActionToken("test", record, map[string]any{"a": 123}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the secret must be generated and persisted
+	secret := app.Settings().ActionLinks.Secret
+	if len(secret) != 50 {
+		t.Fatalf("Expected persisted generated secret, got %q", secret)
+	}
+
+	actionToken, err := app.FindActionToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actionToken.Action != "test" {
+		t.Fatalf("Expected action %q, got %q", "test", actionToken.Action)
+	}
+
+	if actionToken.Record.Id != record.Id {
+		t.Fatalf("Expected record %q, got %q", record.Id, actionToken.Record.Id)
+	}
+
+	if v := actionToken.Data["a"]; v != float64(123) {
+		t.Fatalf("Expected data a = 123, got %v", v)
+	}
+
+	if actionToken.Nonce == "" {
+		t.Fatal("Expected non-empty nonce")
+	}
+
+	// default duration
+	expires := time.Now().Add(app.Settings().ActionLinks.DurationTime())
+	if diff := expires.Sub(actionToken.Expires); diff < 0 || diff > 5*time.Second {
+		t.Fatalf("Expected expires ~%v, got %v", expires, actionToken.Expires)
+	}
+
+	// the secret must be reused
+	if _, err := app.NewActionToken("test", record, nil, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if app.Settings().ActionLinks.Secret != secret {
+		t.Fatal("Expected the action links secret to be reused")
+	}
+}
+
+func TestFindActionToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validToken, err := app.NewActionToken("test", record, nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiredToken, err := security.NewJWT(jwt.MapClaims{
+		core.TokenClaimType:         core.TokenTypeAction,
+		core.TokenClaimId:           record.Id,
+		core.TokenClaimCollectionId: record.Collection().Id,
+		core.TokenClaimAction:       "test",
+		core.TokenClaimNonce:        "test",
+	}, app.Settings().ActionLinks.Secret+record.Collection().Id, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// other token type
+	authRecord, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authToken, err := authRecord.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	missingRecord := record.Clone()
+	missingRecord.Id = "missing"
+	missingRecordToken, err := app.NewActionToken("test", missingRecord, nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// change the secret
+	otherApp, _ := tests.NewTestApp()
+	defer otherApp.Cleanup()
+	otherToken, err := otherApp.NewActionToken("test", record, nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name          string
+		token         string
+		expectedError error
+	}{
+		{"empty", "", core.ErrInvalidActionToken},
+		{"invalid", "invalid", core.ErrInvalidActionToken},
+		{"auth token", authToken, core.ErrInvalidActionToken},
+		{"different secret", otherToken, core.ErrInvalidActionToken},
+		{"missing record", missingRecordToken, core.ErrInvalidActionToken},
+		{"expired", expiredToken, core.ErrInvalidActionToken},
+		{"valid", validToken, nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			_, err := app.FindActionToken(s.token)
+			if !errors.Is(err, s.expectedError) {
+				t.Fatalf("Expected error %v, got %v", s.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestConsumeActionToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := app.NewActionToken("test", record, nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := app.FindActionToken(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// failed execution
+	fnErr := errors.New("test")
+	if err := app.ConsumeActionToken(token, func() error { return fnErr }); !errors.Is(err, fnErr) {
+		t.Fatalf("Expected fn error, got %v", err)
+	}
+
+	// concurrent execution
+	var nestedErr error
+	err = app.ConsumeActionToken(token, func() error {
+		nestedErr = app.ConsumeActionToken(token, func() error { return nil })
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(nestedErr, core.ErrActionTokenUsed) {
+		t.Fatalf("Expected ErrActionTokenUsed for the concurrent execution, got %v", nestedErr)
+	}
+
+	// replay
+	calls := 0
+	err = app.ConsumeActionToken(token, func() error {
+		calls++
+		return nil
+	})
+	if !errors.Is(err, core.ErrActionTokenUsed) {
+		t.Fatalf("Expected ErrActionTokenUsed, got %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("Expected fn to not be called, got %d calls", calls)
+	}
+
+	if _, err := app.FindActionToken(raw); !errors.Is(err, core.ErrActionTokenUsed) {
+		t.Fatalf("Expected ErrActionTokenUsed on find, got %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameAPIKeys = "_apiKeys"
+
+// APIKeyPrefix is the prefix of all generated plain API keys.
+//
+// It is used by the auth middleware to distinguish the API keys from the regular auth tokens.
+const APIKeyPrefix = "pbk_"
+
+// Supported API key access scopes.
+//
+// A scope could be also restricted to a single collection by
+// prefixing it with the collection name or id, e.g. "posts:read".
+const (
+	// APIKeyScopeRead allows the safe (GET, HEAD, OPTIONS) requests.
+	APIKeyScopeRead = "read"
+
+	// APIKeyScopeWrite allows all other non-safe requests.
+	APIKeyScopeWrite = "write"
+)
+
+var (
+	_ Model        = (*APIKey)(nil)
+	_ PreValidator = (*APIKey)(nil)
+	_ RecordProxy  = (*APIKey)(nil)
+)
+
+// APIKey defines a Record proxy for working with the apiKeys collection.
+type APIKey struct {
+	*Record
+}
+
+// NewAPIKey instantiates and returns a new blank *APIKey model.
+//
+// Example usage:
+//
+//	plainKey := core.GenerateAPIKey()
+//
+//	apiKey := core.NewAPIKey(app)
+//	apiKey.SetRecordRef(user.Id)
+//	apiKey.SetCollectionRef(user.Collection().Id)
+//	apiKey.SetName("CI deploy")
+//	apiKey.SetScopes([]string{"posts:read"})
+//	apiKey.SetKey(plainKey)
+//	app.Save(apiKey)
+func NewAPIKey(app App) *APIKey {
+	m := &APIKey{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameAPIKeys)
+	if err != nil {
+		// this is just to make tests easier since apiKeys is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on APIKey.PreValidate())
+		c = NewBaseCollection("__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// GenerateAPIKey generates a new random plain API key.
+func GenerateAPIKey() string {
+	return APIKeyPrefix + security.RandomString(40)
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *APIKey) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameAPIKeys {
+		return errors.New("missing or invalid apiKey ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *APIKey) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *APIKey) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *APIKey) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *APIKey) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *APIKey) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *APIKey) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Name returns the "name" record field value.
+func (m *APIKey) Name() string {
+	return m.GetString("name")
+}
+
+// SetName updates the "name" record field value.
+func (m *APIKey) SetName(name string) {
+	m.Set("name", name)
+}
+
+// KeyHash returns the "keyHash" record field value.
+func (m *APIKey) KeyHash() string {
+	return m.GetString("keyHash")
+}
+
+// SetKey hashes the provided plain API key and updates
+// the "keyHash" and "hint" record field values.
+//
+// Note that the plain key itself is not stored.
+func (m *APIKey) SetKey(plainKey string) {
+	m.Set("keyHash", security.SHA256(plainKey))
+
+	hint := plainKey
+	if len(hint) > 4 {
+		hint = hint[len(hint)-4:]
+	}
+	m.Set("hint", hint)
+}
+
+// Hint returns the "hint" record field value (the last 4 characters of the plain key).
+func (m *APIKey) Hint() string {
+	return m.GetString("hint")
+}
+
+// Scopes returns the "scopes" record field value.
+func (m *APIKey) Scopes() []string {
+	scopes := []string{}
+	_ = m.UnmarshalJSONField("scopes", &scopes)
+	return scopes
+}
+
+// SetScopes updates the "scopes" record field value.
+func (m *APIKey) SetScopes(scopes []string) {
+	m.Set("scopes", scopes)
+}
+
+// Expires returns the "expires" record field value.
+func (m *APIKey) Expires() types.DateTime {
+	return m.GetDateTime("expires")
+}
+
+// SetExpires updates the "expires" record field value.
+//
+// Zero value means that the API key never expires.
+func (m *APIKey) SetExpires(date types.DateTime) {
+	m.Set("expires", date)
+}
+
+// LastUsed returns the "lastUsed" record field value.
+func (m *APIKey) LastUsed() types.DateTime {
+	return m.GetDateTime("lastUsed")
+}
+
+// SetLastUsed updates the "lastUsed" record field value.
+func (m *APIKey) SetLastUsed(date types.DateTime) {
+	m.Set("lastUsed", date)
+}
+
+// Created returns the "created" record field value.
+func (m *APIKey) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *APIKey) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+// HasExpired checks if the API key has a non-zero expiration date in the past.
+func (m *APIKey) HasExpired() bool {
+	expires := m.Expires()
+
+	return !expires.IsZero() && expires.Time().Before(types.NowDateTime().Time())
+}
+
+// HasScope checks whether the API key allows the specified access scope
+// (read or write) for the provided collection.
+//
+// If collection is nil, only the global (non-prefixed) scopes are checked.
+func (m *APIKey) HasScope(collection *Collection, access string) bool {
+	for _, scope := range m.Scopes() {
+		prefix, scopeAccess, ok := strings.Cut(scope, ":")
+		if !ok {
+			if scope == access {
+				return true
+			}
+			continue
+		}
+
+		if collection != nil && scopeAccess == access && (prefix == collection.Name || prefix == collection.Id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (app *BaseApp) registerAPIKeyHooks() {
+	recordRefHooks[*APIKey](app, CollectionNameAPIKeys, CollectionTypeAuth)
+}
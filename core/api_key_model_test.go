@@ -0,0 +1,414 @@
+package core_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKey := core.NewAPIKey(app)
+
+	if apiKey.Collection().Name != core.CollectionNameAPIKeys {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameAPIKeys, apiKey.Collection().Name)
+	}
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	key1 := core.GenerateAPIKey()
+	key2 := core.GenerateAPIKey()
+
+	if !strings.HasPrefix(key1, core.APIKeyPrefix) {
+		t.Fatalf("Expected key with %q prefix, got %q", core.APIKeyPrefix, key1)
+	}
+
+	if len(key1) != len(core.APIKeyPrefix)+40 {
+		t.Fatalf("Expected key with length %d, got %d", len(core.APIKeyPrefix)+40, len(key1))
+	}
+
+	if key1 == key2 {
+		t.Fatalf("Expected different keys, got %q", key1)
+	}
+}
+
+func TestAPIKeyProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	apiKey := core.APIKey{}
+	apiKey.SetProxyRecord(record)
+
+	if apiKey.ProxyRecord() == nil || apiKey.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, apiKey.ProxyRecord())
+	}
+}
+
+func TestAPIKeyStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKey := core.NewAPIKey(app)
+
+	scenarios := []struct {
+		field  string
+		setter func(string)
+		getter func() string
+	}{
+		{"recordRef", apiKey.SetRecordRef, apiKey.RecordRef},
+		{"collectionRef", apiKey.SetCollectionRef, apiKey.CollectionRef},
+		{"name", apiKey.SetName, apiKey.Name},
+	}
+
+	for _, s := range scenarios {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", s.field, i, testValue), func(t *testing.T) {
+				s.setter(testValue)
+
+				if v := s.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := apiKey.GetString(s.field); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestAPIKeySetKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		key          string
+		expectedHint string
+	}{
+		{"", ""},
+		{"abc", "abc"},
+		{"pbk_123456789", "6789"},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%q", i, s.key), func(t *testing.T) {
+			apiKey := core.NewAPIKey(app)
+			apiKey.SetKey(s.key)
+
+			if v := apiKey.KeyHash(); v != security.SHA256(s.key) {
+				t.Fatalf("Expected key hash %q, got %q", security.SHA256(s.key), v)
+			}
+
+			if v := apiKey.Hint(); v != s.expectedHint {
+				t.Fatalf("Expected hint %q, got %q", s.expectedHint, v)
+			}
+		})
+	}
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKey := core.NewAPIKey(app)
+
+	if v := apiKey.Scopes(); len(v) != 0 {
+		t.Fatalf("Expected no scopes, got %v", v)
+	}
+
+	testValues := [][]string{
+		{"read"},
+		{"demo1:read", "write"},
+		{},
+	}
+
+	for i, testValue := range testValues {
+		t.Run(fmt.Sprintf("%d_%v", i, testValue), func(t *testing.T) {
+			apiKey.SetScopes(testValue)
+
+			if v := apiKey.Scopes(); !slices.Equal(v, testValue) {
+				t.Fatalf("Expected scopes %v, got %v", testValue, v)
+			}
+		})
+	}
+}
+
+func TestAPIKeyDates(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKey := core.NewAPIKey(app)
+
+	now := types.NowDateTime()
+
+	apiKey.SetExpires(now)
+	if v := apiKey.Expires(); v.String() != now.String() {
+		t.Fatalf("Expected expires %q, got %q", now.String(), v.String())
+	}
+
+	apiKey.SetLastUsed(now)
+	if v := apiKey.LastUsed(); v.String() != now.String() {
+		t.Fatalf("Expected lastUsed %q, got %q", now.String(), v.String())
+	}
+
+	apiKey.SetRaw("created", now)
+	if v := apiKey.Created(); v.String() != now.String() {
+		t.Fatalf("Expected created %q, got %q", now.String(), v.String())
+	}
+
+	apiKey.SetRaw("updated", now)
+	if v := apiKey.Updated(); v.String() != now.String() {
+		t.Fatalf("Expected updated %q, got %q", now.String(), v.String())
+	}
+}
+
+func TestAPIKeyHasExpired(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		expires  types.DateTime
+		expected bool
+	}{
+		{types.DateTime{}, false},
+		{types.NowDateTime().Add(-1 * time.Minute), true},
+		{types.NowDateTime().Add(1 * time.Minute), false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.expires.String()), func(t *testing.T) {
+			apiKey := core.NewAPIKey(app)
+			apiKey.SetExpires(s.expires)
+
+			if v := apiKey.HasExpired(); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHasScope(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo2, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name       string
+		scopes     []string
+		collection *core.Collection
+		access     string
+		expected   bool
+	}{
+		{"no scopes", nil, nil, core.APIKeyScopeRead, false},
+		{"global read (nil collection)", []string{"read"}, nil, core.APIKeyScopeRead, true},
+		{"global read (with collection)", []string{"read"}, demo1, core.APIKeyScopeRead, true},
+		{"global read (write access)", []string{"read"}, demo1, core.APIKeyScopeWrite, false},
+		{"collection read by name", []string{"demo1:read"}, demo1, core.APIKeyScopeRead, true},
+		{"collection read by id", []string{demo1.Id + ":read"}, demo1, core.APIKeyScopeRead, true},
+		{"collection read (nil collection)", []string{"demo1:read"}, nil, core.APIKeyScopeRead, false},
+		{"collection read (different collection)", []string{"demo1:read"}, demo2, core.APIKeyScopeRead, false},
+		{"collection read (write access)", []string{"demo1:read"}, demo1, core.APIKeyScopeWrite, false},
+		{"multiple scopes", []string{"demo1:read", "demo2:write"}, demo2, core.APIKeyScopeWrite, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			apiKey := core.NewAPIKey(app)
+			apiKey.SetScopes(s.scopes)
+
+			if v := apiKey.HasScope(s.collection, s.access); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestAPIKeyPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKeysCol, err := app.FindCollectionByNameOrId(core.CollectionNameAPIKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		apiKey := &core.APIKey{}
+
+		if err := app.Validate(apiKey); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-APIKey collection", func(t *testing.T) {
+		apiKey := &core.APIKey{}
+		apiKey.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		apiKey.SetRecordRef(user.Id)
+		apiKey.SetCollectionRef(user.Collection().Id)
+		apiKey.SetName("test")
+		apiKey.SetKey("test")
+
+		if err := app.Validate(apiKey); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("APIKey collection", func(t *testing.T) {
+		apiKey := &core.APIKey{}
+		apiKey.SetProxyRecord(core.NewRecord(apiKeysCol))
+		apiKey.SetRecordRef(user.Id)
+		apiKey.SetCollectionRef(user.Collection().Id)
+		apiKey.SetName("test")
+		apiKey.SetKey("test")
+
+		if err := app.Validate(apiKey); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestAPIKeyValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		apiKey       func() *core.APIKey
+		expectErrors []string
+	}{
+		{
+			"empty",
+			func() *core.APIKey {
+				return core.NewAPIKey(app)
+			},
+			[]string{"collectionRef", "recordRef", "name", "keyHash"},
+		},
+		{
+			"non-auth collection",
+			func() *core.APIKey {
+				apiKey := core.NewAPIKey(app)
+				apiKey.SetCollectionRef(demo1.Collection().Id)
+				apiKey.SetRecordRef(demo1.Id)
+				apiKey.SetName("test")
+				apiKey.SetKey("test")
+				return apiKey
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record id",
+			func() *core.APIKey {
+				apiKey := core.NewAPIKey(app)
+				apiKey.SetCollectionRef(user.Collection().Id)
+				apiKey.SetRecordRef("missing")
+				apiKey.SetName("test")
+				apiKey.SetKey("test")
+				return apiKey
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"valid ref",
+			func() *core.APIKey {
+				apiKey := core.NewAPIKey(app)
+				apiKey.SetCollectionRef(user.Collection().Id)
+				apiKey.SetRecordRef(user.Id)
+				apiKey.SetName("test")
+				apiKey.SetKey("test")
+				return apiKey
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.apiKey())
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestAPIKeyRecordRefDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiKey := core.NewAPIKey(app)
+	apiKey.SetCollectionRef(user.Collection().Id)
+	apiKey.SetRecordRef(user.Id)
+	apiKey.SetName("test")
+	apiKey.SetKey(core.GenerateAPIKey())
+	if err := app.Save(apiKey); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindAPIKeyById(apiKey.Id); err == nil {
+		t.Fatal("Expected the API key to be deleted together with its owner record")
+	}
+}
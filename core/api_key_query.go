@@ -0,0 +1,111 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// FindAllAPIKeysByRecord returns all APIKey models linked to the provided auth record.
+func (app *BaseApp) FindAllAPIKeysByRecord(authRecord *Record) ([]*APIKey, error) {
+	result := []*APIKey{}
+
+	err := app.RecordQuery(CollectionNameAPIKeys).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		OrderBy("created DESC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAPIKeyById returns a single APIKey model by its id.
+func (app *BaseApp) FindAPIKeyById(id string) (*APIKey, error) {
+	result := &APIKey{}
+
+	err := app.RecordQuery(CollectionNameAPIKeys).
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAPIKeyByKey returns a single APIKey model by its plain key value.
+//
+// Note that the returned model could be expired (see [APIKey.HasExpired]).
+func (app *BaseApp) FindAPIKeyByKey(plainKey string) (*APIKey, error) {
+	if plainKey == "" {
+		return nil, errors.New("empty API key")
+	}
+
+	result := &APIKey{}
+
+	err := app.RecordQuery(CollectionNameAPIKeys).
+		AndWhere(dbx.HashExp{"keyHash": security.SHA256(plainKey)}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAuthRecordByAPIKey returns the auth record owning the provided
+// non-expired plain API key together with the related APIKey model.
+func (app *BaseApp) FindAuthRecordByAPIKey(plainKey string) (*Record, *APIKey, error) {
+	apiKey, err := app.FindAPIKeyByKey(plainKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if apiKey.HasExpired() {
+		return nil, nil, errors.New("the API key has expired")
+	}
+
+	record, err := app.FindRecordById(apiKey.CollectionRef(), apiKey.RecordRef())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !record.Collection().IsAuth() {
+		return nil, nil, errors.New("the API key owner must be an auth record")
+	}
+
+	return record, apiKey, nil
+}
+
+// DeleteAllAPIKeysByRecord deletes all APIKey models associated with the provided record.
+//
+// Returns a combined error with the failed deletes.
+func (app *BaseApp) DeleteAllAPIKeysByRecord(authRecord *Record) error {
+	models, err := app.FindAllAPIKeysByRecord(authRecord)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, m := range models {
+		if err := app.Delete(m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
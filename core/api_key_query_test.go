@@ -0,0 +1,258 @@
+package core_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func stubAPIKey(t testing.TB, app core.App, owner *core.Record, plainKey string, expires types.DateTime) *core.APIKey {
+	apiKey := core.NewAPIKey(app)
+	apiKey.SetCollectionRef(owner.Collection().Id)
+	apiKey.SetRecordRef(owner.Id)
+	apiKey.SetName("test")
+	apiKey.SetScopes([]string{core.APIKeyScopeRead})
+	apiKey.SetExpires(expires)
+	apiKey.SetKey(plainKey)
+
+	if err := app.Save(apiKey); err != nil {
+		t.Fatal(err)
+	}
+
+	return apiKey
+}
+
+func TestFindAllAPIKeysByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	superuser, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key1 := stubAPIKey(t, app, user1, "pbk_user1_key1", types.DateTime{})
+	key2 := stubAPIKey(t, app, user1, "pbk_user1_key2", types.DateTime{})
+	key3 := stubAPIKey(t, app, superuser, "pbk_superuser_key1", types.DateTime{})
+
+	scenarios := []struct {
+		record   *core.Record
+		expected []string
+	}{
+		{user1, []string{key1.Id, key2.Id}},
+		{user2, nil},
+		{superuser, []string{key3.Id}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.record.Collection().Name+"_"+s.record.Id, func(t *testing.T) {
+			result, err := app.FindAllAPIKeysByRecord(s.record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != len(s.expected) {
+				t.Fatalf("Expected total API keys %d, got %d", len(s.expected), len(result))
+			}
+
+			for _, apiKey := range result {
+				if !slices.Contains(s.expected, apiKey.Id) {
+					t.Fatalf("Unexpected API key %q", apiKey.Id)
+				}
+			}
+		})
+	}
+}
+
+func TestFindAPIKeyById(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiKey := stubAPIKey(t, app, user, "pbk_test", types.DateTime{})
+
+	scenarios := []struct {
+		id          string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{apiKey.Id, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.id, func(t *testing.T) {
+			result, err := app.FindAPIKeyById(s.id)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Id != s.id {
+				t.Fatalf("Expected record with id %q, got %q", s.id, result.Id)
+			}
+		})
+	}
+}
+
+func TestFindAPIKeyByKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiKey := stubAPIKey(t, app, user, "pbk_test", types.DateTime{})
+
+	scenarios := []struct {
+		key         string
+		expectError bool
+	}{
+		{"", true},
+		{"pbk_missing", true},
+		{apiKey.KeyHash(), true},
+		{"pbk_test", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.key, func(t *testing.T) {
+			result, err := app.FindAPIKeyByKey(s.key)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Id != apiKey.Id {
+				t.Fatalf("Expected record with id %q, got %q", apiKey.Id, result.Id)
+			}
+		})
+	}
+}
+
+func TestFindAuthRecordByAPIKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stubAPIKey(t, app, user, "pbk_valid", types.NowDateTime().Add(1*time.Hour))
+	stubAPIKey(t, app, user, "pbk_expired", types.NowDateTime().Add(-1*time.Hour))
+
+	scenarios := []struct {
+		key         string
+		expectError bool
+	}{
+		{"", true},
+		{"pbk_missing", true},
+		{"pbk_expired", true},
+		{"pbk_valid", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.key, func(t *testing.T) {
+			record, apiKey, err := app.FindAuthRecordByAPIKey(s.key)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if record.Id != user.Id {
+				t.Fatalf("Expected record with id %q, got %q", user.Id, record.Id)
+			}
+
+			if apiKey.RecordRef() != user.Id {
+				t.Fatalf("Expected API key with recordRef %q, got %q", user.Id, apiKey.RecordRef())
+			}
+		})
+	}
+}
+
+func TestDeleteAllAPIKeysByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stubAPIKey(t, app, user1, "pbk_user1_key1", types.DateTime{})
+	stubAPIKey(t, app, user1, "pbk_user1_key2", types.DateTime{})
+	stubAPIKey(t, app, user2, "pbk_user2_key1", types.DateTime{})
+
+	if err := app.DeleteAllAPIKeysByRecord(user1); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		record        *core.Record
+		expectedTotal int
+	}{
+		{user1, 0},
+		{user2, 1},
+	}
+
+	for _, s := range scenarios {
+		result, err := app.FindAllAPIKeysByRecord(s.record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result) != s.expectedTotal {
+			t.Fatalf("[%s] Expected %d API keys, got %d", s.record.Id, s.expectedTotal, len(result))
+		}
+	}
+}
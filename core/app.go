@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/ai"
 	"github.com/pocketbase/pocketbase/tools/cron"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
@@ -79,10 +80,77 @@ type App interface {
 	// SubscriptionsBroker returns the app realtime subscriptions broker instance.
 	SubscriptionsBroker() *subscriptions.Broker
 
+	// Cache returns the app cache store.
+	Cache() Cache
+
+	// Context returns the context associated with the current app instance
+	// (default to [context.Background]).
+	//
+	// It is used as default context by the db operations without explicit
+	// context argument (Save, Delete, Validate, etc.) and their model hooks.
+	Context() context.Context
+
+	// WithContext returns a shallow copy of the current app instance
+	// bound to the provided context (e.g. the request context).
+	//
+	// All db queries, model operations and transactions executed with the
+	// returned app instance are limited by the context, aka. they are
+	// cancelled when the context deadline expires or the client disconnects.
+	//
+	// Note that the returned instance shares the same hooks, settings, store, etc.
+	// with the original app and it should not be used outside of the context
+	// lifetime (e.g. in background goroutines).
+	WithContext(ctx context.Context) App
+
+	// TokenSigner returns the signer used for generating
+	// and verifying the record tokens.
+	TokenSigner() TokenSigner
+
+	// AuthLockoutRemaining returns the remaining lockout duration of the
+	// auth collection identity and IP pair (0 if not locked).
+	AuthLockoutRemaining(collection *Collection, identity string, ip string) time.Duration
+
+	// RegisterFailedAuthAttempt registers a failed password or OTP auth
+	// attempt and temporary locks the identity and IP pair if the
+	// configured [Settings.AuthLockout] threshold is reached.
+	RegisterFailedAuthAttempt(collection *Collection, record *Record, authMethod string, identity string, ip string) error
+
+	// ResetAuthAttempts clears the failed auth attempts and
+	// lockouts of the auth collection identity and IP pair.
+	ResetAuthAttempts(collection *Collection, identity string, ip string)
+
+	// NewActionToken generates a new signed single-use token for
+	// executing the specified action on the record without login.
+	NewActionToken(action string, record *Record, data map[string]any, duration time.Duration) (string, error)
+
+	// FindActionToken verifies the signed action token and returns its loaded data.
+	FindActionToken(token string) (*ActionToken, error)
+
+	// ConsumeActionToken executes fn and marks the action token
+	// as used if fn completes without error.
+	ConsumeActionToken(token *ActionToken, fn func() error) error
+
+	// FindDelegatedToken verifies the delegated auth record token
+	// issued for the specified audience and returns its loaded data.
+	FindDelegatedToken(token string, audience string) (*DelegatedToken, error)
+
+	// TemplateRenderer returns the registered server-side templates renderer
+	// used by [RequestEvent.Render] (nil if not set).
+	TemplateRenderer() TemplateRenderer
+
 	// NewMailClient creates and returns a new SMTP or Sendmail client
 	// based on the current app settings.
 	NewMailClient() mailer.Mailer
 
+	// SendSMS sends the provided SMS message using the registered
+	// SMS sender (see [BaseAppConfig.SMSSender] and [App.OnSMSSend]).
+	SendSMS(ctx context.Context, message *SMSMessage) error
+
+	// Notify sends the provided push notification to the devices of the
+	// specified record using the registered notifier
+	// (see [BaseAppConfig.Notifier] and [App.OnRecordNotify]).
+	Notify(record *Record, notification *Notification) error
+
 	// NewFilesystem creates a new local or S3 filesystem instance
 	// for managing regular app files (ex. record uploads)
 	// based on the current app settings.
@@ -98,6 +166,12 @@ type App interface {
 	// after you are done working with it.
 	NewBackupsFilesystem() (*filesystem.System, error)
 
+	// NewAIClient creates a new OpenAI compatible embeddings and
+	// chat completions client based on the current app settings.
+	//
+	// Returns an error if the AI integration is not enabled.
+	NewAIClient() (*ai.Client, error)
+
 	// ReloadSettings reinitializes and reloads the stored application settings.
 	ReloadSettings() error
 
@@ -471,6 +545,47 @@ type App interface {
 
 	// ---------------------------------------------------------------
 
+	// FindAllAPIKeysByRecord returns all APIKey models linked to the provided auth record.
+	FindAllAPIKeysByRecord(authRecord *Record) ([]*APIKey, error)
+
+	// FindAPIKeyById returns a single APIKey model by its id.
+	FindAPIKeyById(id string) (*APIKey, error)
+
+	// FindAPIKeyByKey returns a single APIKey model by its plain key value.
+	//
+	// Note that the returned model could be expired (see [APIKey.HasExpired]).
+	FindAPIKeyByKey(plainKey string) (*APIKey, error)
+
+	// FindAuthRecordByAPIKey returns the auth record owning the provided
+	// non-expired plain API key together with the related APIKey model.
+	FindAuthRecordByAPIKey(plainKey string) (*Record, *APIKey, error)
+
+	// DeleteAllAPIKeysByRecord deletes all APIKey models associated with the provided record.
+	//
+	// Returns a combined error with the failed deletes.
+	DeleteAllAPIKeysByRecord(authRecord *Record) error
+
+	// ---------------------------------------------------------------
+
+	// FindAllSessionsByRecord returns all Session models linked to the provided auth record (in DESC order).
+	FindAllSessionsByRecord(authRecord *Record) ([]*Session, error)
+
+	// FindSessionById returns a single Session model by its id.
+	FindSessionById(id string) (*Session, error)
+
+	// DeleteAllSessionsByRecord deletes all Session models associated with
+	// the provided record (aka. revokes all of its tracked auth tokens).
+	//
+	// Returns a combined error with the failed deletes.
+	DeleteAllSessionsByRecord(authRecord *Record) error
+
+	// ---------------------------------------------------------------
+
+	// FindOAuthClientById returns a single OAuthClient model by its id (aka. client_id).
+	FindOAuthClientById(id string) (*OAuthClient, error)
+
+	// ---------------------------------------------------------------
+
 	// FindAllAuthOriginsByRecord returns all AuthOrigin models linked to the provided auth record (in DESC order).
 	FindAllAuthOriginsByRecord(authRecord *Record) ([]*AuthOrigin, error)
 
@@ -933,6 +1048,38 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordEnrich(tags ...string) *hook.TaggedHook[*RecordEnrichEvent]
 
+	// OnRecordEnrichResponse is triggered once per builtin Record(s) API response
+	// (or when [apis.EnrichRecords] is invoked) after the records were enriched,
+	// aka. after the [OnRecordEnrich] hooks, the relations expand and the
+	// visibility flags resolve, and right before the records serialization.
+	//
+	// Unlike [OnRecordEnrich] it has access to the full request event and all
+	// response records at once which makes it suitable for adding computed
+	// props with a single batch query or for stripping fields based on the
+	// request auth state without overriding the CRUD endpoints. For example:
+	//
+	//  app.OnRecordEnrichResponse("posts").BindFunc(func(e *core.RecordEnrichResponseEvent) error {
+	//      counts := map[string]int{} // e.g. load the comments count for all e.Records with a single query
+	//
+	//      for _, r := range e.Records {
+	//          if e.Auth == nil {
+	//              r.Hide("internalNotes")
+	//          }
+	//
+	//          r.WithCustomData(true) // for security requires explicitly allowing it
+	//          r.Set("commentsCount", counts[r.Id])
+	//      }
+	//
+	//      return e.Next()
+	//  })
+	//
+	// Note that the expanded relation records are not part of the event Records list.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordEnrichResponse(tags ...string) *hook.TaggedHook[*RecordEnrichResponseEvent]
+
 	// OnRecordValidate is a Record proxy model hook of [OnModelValidate].
 	//
 	// If the optional "tags" list (Collection ids or names) is specified,
@@ -940,6 +1087,24 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordValidate(tags ...string) *hook.TaggedHook[*RecordEvent]
 
+	// OnRecordContentModeration hook is triggered during the Record
+	// validation (after the fields validation) with the new or changed
+	// text/editor field values and the new uploaded files of the record.
+	//
+	// Could be used to check the submitted content with an external
+	// moderation service and to set the event Outcome (e.g. [ModerationOutcomeBlock]).
+	//
+	// The default hook action returns a validation error if the
+	// Outcome is [ModerationOutcomeBlock].
+	//
+	// Note that the hook is not triggered if there is no new content
+	// to moderate or when the record is saved without validations.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordContentModeration(tags ...string) *hook.TaggedHook[*RecordContentModerationEvent]
+
 	// ---------------------------------------------------------------
 
 	// OnRecordCreate is a Record proxy model hook of [OnModelCreate].
@@ -1030,6 +1195,18 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordAfterDeleteError(tags ...string) *hook.TaggedHook[*RecordErrorEvent]
 
+	// OnRecordChange hook is triggered after each successfully persisted
+	// Record create, update or delete of a collection with enabled
+	// CaptureChanges option.
+	//
+	// The hook is triggered after the change data capture entry is stored
+	// and, if the change is part of a transaction, after the transaction commit.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordChange(tags ...string) *hook.TaggedHook[*RecordChangeEvent]
+
 	// ---------------------------------------------------------------
 	// Collection models event hooks
 	// ---------------------------------------------------------------
@@ -1186,6 +1363,41 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnMailerRecordOTPSend(tags ...string) *hook.TaggedHook[*MailerRecordEvent]
 
+	// OnMailerRecordMagicLinkSend hook is triggered when sending a
+	// passwordless magic link email to an auth record, allowing you
+	// to intercept and customize the email message that is being sent.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnMailerRecordMagicLinkSend(tags ...string) *hook.TaggedHook[*MailerRecordEvent]
+
+	// ---------------------------------------------------------------
+	// SMS event hooks
+	// ---------------------------------------------------------------
+
+	// OnSMSSend hook is triggered every time when a new SMS message
+	// is being send using [App.SendSMS].
+	//
+	// It allows intercepting the SMS message or to use a custom SMS gateway
+	// client by replacing the event Sender (e.g. e.Sender = mygateway.New(...)).
+	OnSMSSend() *hook.Hook[*SMSSendEvent]
+
+	// ---------------------------------------------------------------
+	// Notification event hooks
+	// ---------------------------------------------------------------
+
+	// OnRecordNotify hook is triggered every time when a new push
+	// notification is being send to a record devices using [App.Notify].
+	//
+	// It allows intercepting the notification payload or to use a custom
+	// delivery client by replacing the event Notifier.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordNotify(tags ...string) *hook.TaggedHook[*RecordNotifyEvent]
+
 	// ---------------------------------------------------------------
 	// Realtime API event hooks
 	// ---------------------------------------------------------------
@@ -1256,6 +1468,19 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordAuthRequest(tags ...string) *hook.TaggedHook[*RecordAuthRequestEvent]
 
+	// OnRecordNewDeviceLogin hook is triggered when a record successfully
+	// authenticates from an unseen device/IP fingerprint
+	// (requires the collection AuthAlert option to be enabled).
+	//
+	// The default hook action sends the new login alert email.
+	// Could be used to send the alert via another channel (e.g. SMS)
+	// or to skip the alert by not calling e.Next().
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordNewDeviceLogin(tags ...string) *hook.TaggedHook[*RecordNewDeviceLoginEvent]
+
 	// OnRecordAuthWithPasswordRequest hook is triggered on each
 	// Record auth with password API request.
 	//
@@ -1281,6 +1506,41 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordAuthWithOAuth2Request(tags ...string) *hook.TaggedHook[*RecordAuthWithOAuth2RequestEvent]
 
+	// OnRecordAuthWithSAMLRequest hook is triggered on each Record
+	// SAML sign-in/sign-up API request (after the IdP assertion
+	// verification and before the external auth linking).
+	//
+	// If [RecordAuthWithSAMLRequestEvent.Record] is not set, then the SAML
+	// request will try to create a new auth Record.
+	//
+	// To assign or link a different existing record model you can
+	// change the [RecordAuthWithSAMLRequestEvent.Record] field.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthWithSAMLRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithSAMLRequestEvent]
+
+	// OnRecordAuthAsGuestRequest hook is triggered on each Record
+	// auth-as-guest API request (right before creating the anonymous auth record).
+	//
+	// Could be used to prefill additional [RecordAuthAsGuestRequestEvent.Record]
+	// fields or to reject the guest sign-up (e.g. with a captcha check).
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthAsGuestRequest(tags ...string) *hook.TaggedHook[*RecordAuthAsGuestRequestEvent]
+
+	// OnRecordGuestUpgradeRequest hook is triggered on each Record
+	// guest upgrade API request (right before assigning the submitted
+	// email/password to the anonymous auth record).
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordGuestUpgradeRequest(tags ...string) *hook.TaggedHook[*RecordGuestUpgradeRequestEvent]
+
 	// OnRecordAuthRefreshRequest hook is triggered on each Record
 	// auth refresh API request (right before generating a new auth token).
 	//
@@ -1377,6 +1637,72 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordAuthWithOTPRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithOTPRequestEvent]
 
+	// OnRecordRequestMagicLinkRequest hook is triggered on each Record
+	// request magic link API request.
+	//
+	// [RecordRequestMagicLinkRequestEvent.Record] could be nil if no matching identity is found, allowing
+	// you to manually create or locate a different Record model (by reassigning [RecordRequestMagicLinkRequestEvent.Record]).
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordRequestMagicLinkRequest(tags ...string) *hook.TaggedHook[*RecordRequestMagicLinkRequestEvent]
+
+	// OnRecordAuthWithMagicLinkRequest hook is triggered on each Record
+	// auth with magic link API request.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthWithMagicLinkRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithMagicLinkRequestEvent]
+
+	// OnRecordRequestSMSOTPRequest hook is triggered on each Record
+	// request SMS OTP API request.
+	//
+	// [RecordRequestSMSOTPRequestEvent.Record] could be nil if no matching identity is found, allowing
+	// you to manually create or locate a different Record model (by reassigning [RecordRequestSMSOTPRequestEvent.Record]).
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordRequestSMSOTPRequest(tags ...string) *hook.TaggedHook[*RecordRequestSMSOTPRequestEvent]
+
+	// OnRecordAuthWithSMSOTPRequest hook is triggered on each Record
+	// auth with SMS OTP API request.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthWithSMSOTPRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithSMSOTPRequestEvent]
+
+	// OnRecordAuthLockout hook is triggered when an auth collection identity
+	// and IP pair reaches the max failed password or OTP auth attempts
+	// (see [Settings.AuthLockout]).
+	//
+	// Could be used to alert on abuse or to change the lockout duration.
+	// The lockout is applied only if e.Next() is called.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthLockout(tags ...string) *hook.TaggedHook[*RecordAuthLockoutEvent]
+
+	// OnActionLinkRequest hook is triggered on each signed action link
+	// execution API request (see [App.NewActionToken]).
+	//
+	// Could be used to implement custom actions (e.g. "approve") by
+	// handling them without calling e.Next() or to additionally validate
+	// the builtin ones. The builtin [ActionVerify] and [ActionUpdate]
+	// actions are executed on e.Next() and all other actions are rejected.
+	//
+	// The action token is marked as used only if the hook chain
+	// completes without error.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnActionLinkRequest(tags ...string) *hook.TaggedHook[*ActionLinkRequestEvent]
+
 	// ---------------------------------------------------------------
 	// Record CRUD API event hooks
 	// ---------------------------------------------------------------
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// authLockoutStoreKey is the app store key of the failed auth attempts tracker.
+const authLockoutStoreKey = "@authLockout"
+
+// authLockoutMaxEntries is the number of tracked identity+IP pairs
+// after which the expired entries are pruned.
+const authLockoutMaxEntries = 10000
+
+type authAttempts struct {
+	failures    int
+	windowStart time.Time
+	lockouts    int // consecutive lockouts (used for the backoff)
+	lockedUntil time.Time
+}
+
+type authLockoutTracker struct {
+	mu      sync.Mutex
+	entries map[string]*authAttempts
+}
+
+func (app *BaseApp) authLockoutTracker() *authLockoutTracker {
+	return app.Store().GetOrSet(authLockoutStoreKey, func() any {
+		return &authLockoutTracker{entries: map[string]*authAttempts{}}
+	}).(*authLockoutTracker)
+}
+
+func authLockoutKey(collection *Collection, identity string, ip string) string {
+	return collection.Id + "|" + strings.ToLower(identity) + "|" + ip
+}
+
+// AuthLockoutRemaining returns the remaining lockout duration of the
+// collection identity and IP pair (returns 0 if the pair is not locked).
+//
+// The lockout is applied only if [AuthLockoutConfig.Enabled] is set.
+func (app *BaseApp) AuthLockoutRemaining(collection *Collection, identity string, ip string) time.Duration {
+	if !app.Settings().AuthLockout.Enabled {
+		return 0
+	}
+
+	tracker := app.authLockoutTracker()
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	entry, ok := tracker.entries[authLockoutKey(collection, identity, ip)]
+	if !ok {
+		return 0
+	}
+
+	remaining := time.Until(entry.lockedUntil)
+	if remaining < 0 {
+		return 0
+	}
+
+	return remaining
+}
+
+// RegisterFailedAuthAttempt registers a failed auth attempt (e.g. invalid password or OTP)
+// for the collection identity and IP pair.
+//
+// If the failed attempts within [AuthLockoutConfig.Window] reach [AuthLockoutConfig.MaxAttempts],
+// the [App.OnRecordAuthLockout] hook is triggered and the pair is temporary locked.
+//
+// record could be nil in case the identity doesn't match with an existing auth record.
+func (app *BaseApp) RegisterFailedAuthAttempt(collection *Collection, record *Record, authMethod string, identity string, ip string) error {
+	config := app.Settings().AuthLockout
+	if !config.Enabled {
+		return nil
+	}
+
+	key := authLockoutKey(collection, identity, ip)
+
+	tracker := app.authLockoutTracker()
+
+	tracker.mu.Lock()
+
+	now := time.Now()
+
+	if len(tracker.entries) >= authLockoutMaxEntries {
+		tracker.prune(now, config)
+	}
+
+	entry, ok := tracker.entries[key]
+	if !ok {
+		entry = &authAttempts{windowStart: now}
+		tracker.entries[key] = entry
+	}
+
+	if now.Sub(entry.windowStart) > config.WindowDuration() {
+		entry.failures = 0
+		entry.windowStart = now
+	}
+
+	entry.failures++
+
+	if entry.failures < config.MaxAttempts {
+		tracker.mu.Unlock()
+		return nil
+	}
+
+	attempts := entry.failures
+	duration := config.LockoutDuration(entry.lockouts + 1)
+
+	// reset the counter to prevent triggering the lockout multiple times
+	entry.failures = 0
+	entry.windowStart = now
+
+	tracker.mu.Unlock()
+
+	event := new(RecordAuthLockoutEvent)
+	event.App = app
+	event.Collection = collection
+	event.Record = record
+	event.AuthMethod = authMethod
+	event.Identity = identity
+	event.IP = ip
+	event.Attempts = attempts
+	event.Duration = duration
+
+	return app.OnRecordAuthLockout().Trigger(event, func(e *RecordAuthLockoutEvent) error {
+		tracker.mu.Lock()
+		defer tracker.mu.Unlock()
+
+		entry, ok := tracker.entries[key]
+		if !ok {
+			entry = &authAttempts{windowStart: now}
+			tracker.entries[key] = entry
+		}
+
+		entry.lockouts++
+		entry.lockedUntil = time.Now().Add(e.Duration)
+
+		return nil
+	})
+}
+
+// ResetAuthAttempts clears the tracked failed auth attempts and
+// lockouts of the collection identity and IP pair
+// (usually called after successful authentication).
+func (app *BaseApp) ResetAuthAttempts(collection *Collection, identity string, ip string) {
+	tracker := app.authLockoutTracker()
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	delete(tracker.entries, authLockoutKey(collection, identity, ip))
+}
+
+// prune removes the idle entries (aka. without active lockout and with expired window).
+//
+// Note that the caller is expected to hold the tracker lock.
+func (t *authLockoutTracker) prune(now time.Time, config AuthLockoutConfig) {
+	window := config.WindowDuration()
+
+	// keep the lockouts history for the max lockout duration to preserve the backoff
+	history := max(window, config.LockoutDuration(math.MaxInt32))
+
+	for key, entry := range t.entries {
+		if now.Sub(entry.windowStart) > window && now.Sub(entry.lockedUntil) > history {
+			delete(t.entries, key)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestAuthLockoutDisabled(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Settings().AuthLockout.Enabled = false
+	app.Settings().AuthLockout.MaxAttempts = 1
+
+	var hookCalls int
+	app.OnRecordAuthLockout().BindFunc(func(e *core.RecordAuthLockoutEvent) error {
+		hookCalls++
+		return e.Next()
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := app.RegisterFailedAuthAttempt(collection, nil, core.MFAMethodPassword, "test@example.com", "1.2.3.4"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if hookCalls != 0 {
+		t.Fatalf("Expected no hook calls, got %d", hookCalls)
+	}
+
+	if remaining := app.AuthLockoutRemaining(collection, "test@example.com", "1.2.3.4"); remaining != 0 {
+		t.Fatalf("Expected no lockout, got %v", remaining)
+	}
+}
+
+func TestAuthLockout(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := app.FindAuthRecordByEmail(collection, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Settings().AuthLockout = core.AuthLockoutConfig{
+		Enabled:     true,
+		MaxAttempts: 3,
+		Window:      60,
+		Duration:    10,
+		Backoff:     true,
+		MaxDuration: 100,
+	}
+
+	var events []*core.RecordAuthLockoutEvent
+	app.OnRecordAuthLockout().BindFunc(func(e *core.RecordAuthLockoutEvent) error {
+		events = append(events, e)
+		return e.Next()
+	})
+
+	register := func(identity, ip string) {
+		if err := app.RegisterFailedAuthAttempt(collection, record, core.MFAMethodPassword, identity, ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	register("test@example.com", "1.2.3.4")
+	register("TEST@example.com", "1.2.3.4")
+
+	if len(events) != 0 {
+		t.Fatalf("Expected no lockout events before reaching the max attempts, got %d", len(events))
+	}
+
+	if remaining := app.AuthLockoutRemaining(collection, "test@example.com", "1.2.3.4"); remaining != 0 {
+		t.Fatalf("Expected no lockout before reaching the max attempts, got %v", remaining)
+	}
+
+	register("test@example.com", "1.2.3.4")
+
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 lockout event, got %d", len(events))
+	}
+
+	e := events[0]
+	if e.Collection.Id != collection.Id ||
+		e.Record != record ||
+		e.AuthMethod != core.MFAMethodPassword ||
+		e.Identity != "test@example.com" ||
+		e.IP != "1.2.3.4" ||
+		e.Attempts != 3 ||
+		e.Duration != 10*time.Second {
+		t.Fatalf("Unexpected lockout event data %#v", e)
+	}
+
+	remaining := app.AuthLockoutRemaining(collection, "Test@Example.com", "1.2.3.4")
+	if remaining <= 0 || remaining > 10*time.Second {
+		t.Fatalf("Expected lockout between 0 and 10s, got %v", remaining)
+	}
+
+	// different IP
+	if remaining := app.AuthLockoutRemaining(collection, "test@example.com", "1.2.3.5"); remaining != 0 {
+		t.Fatalf("Expected no lockout for different IP, got %v", remaining)
+	}
+
+	// consecutive lockout with backoff
+	for i := 0; i < 3; i++ {
+		register("test@example.com", "1.2.3.4")
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 lockout events, got %d", len(events))
+	}
+
+	if events[1].Duration != 20*time.Second {
+		t.Fatalf("Expected backoff lockout duration %v, got %v", 20*time.Second, events[1].Duration)
+	}
+
+	app.ResetAuthAttempts(collection, "test@example.com", "1.2.3.4")
+
+	if remaining := app.AuthLockoutRemaining(collection, "test@example.com", "1.2.3.4"); remaining != 0 {
+		t.Fatalf("Expected no lockout after reset, got %v", remaining)
+	}
+}
+
+func TestAuthLockoutHookInterruption(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Settings().AuthLockout = core.AuthLockoutConfig{
+		Enabled:     true,
+		MaxAttempts: 1,
+		Window:      60,
+		Duration:    10,
+	}
+
+	app.OnRecordAuthLockout().BindFunc(func(e *core.RecordAuthLockoutEvent) error {
+		if e.IP == "1.2.3.4" {
+			return nil // skip the lockout
+		}
+
+		e.Duration = time.Hour
+
+		return e.Next()
+	})
+
+	if err := app.RegisterFailedAuthAttempt(collection, nil, core.MFAMethodOTP, "abc", "1.2.3.4"); err != nil {
+		t.Fatal(err)
+	}
+
+	if remaining := app.AuthLockoutRemaining(collection, "abc", "1.2.3.4"); remaining != 0 {
+		t.Fatalf("Expected no lockout, got %v", remaining)
+	}
+
+	if err := app.RegisterFailedAuthAttempt(collection, nil, core.MFAMethodOTP, "abc", "1.2.3.5"); err != nil {
+		t.Fatal(err)
+	}
+
+	if remaining := app.AuthLockoutRemaining(collection, "abc", "1.2.3.5"); remaining <= 10*time.Second {
+		t.Fatalf("Expected the hook modified lockout duration, got %v", remaining)
+	}
+}
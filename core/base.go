@@ -17,6 +17,7 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/ai"
 	"github.com/pocketbase/pocketbase/tools/cron"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
@@ -34,6 +35,7 @@ const (
 	DefaultAuxMaxOpenConns  int           = 20
 	DefaultAuxMaxIdleConns  int           = 3
 	DefaultQueryTimeout     time.Duration = 30 * time.Second
+	DefaultWriteQueueSize   int           = 1000
 
 	LocalStorageDirName       string = "storage"
 	LocalBackupsDirName       string = "backups"
@@ -50,6 +52,12 @@ type FilesManager interface {
 // DBConnectFunc defines a database connection initialization function.
 type DBConnectFunc func(dbPath string) (*dbx.DB, error)
 
+// FilesystemFactoryFunc defines a filesystem initialization function.
+type FilesystemFactoryFunc func(app App) (*filesystem.System, error)
+
+// MailClientFactoryFunc defines a mail client initialization function.
+type MailClientFactoryFunc func(app App) mailer.Mailer
+
 // BaseAppConfig defines a BaseApp configuration option
 type BaseAppConfig struct {
 	DBConnect        DBConnectFunc
@@ -60,7 +68,19 @@ type BaseAppConfig struct {
 	DataMaxIdleConns int
 	AuxMaxOpenConns  int
 	AuxMaxIdleConns  int
+	WriteQueueSize   int // max pending writers per db (negative for no limit)
 	IsDev            bool
+
+	// optional custom infrastructure implementations
+	// (if not set the builtin settings based implementations are used)
+	NewFilesystem        FilesystemFactoryFunc
+	NewBackupsFilesystem FilesystemFactoryFunc
+	NewMailClient        MailClientFactoryFunc
+	SMSSender            SMSSender
+	Notifier             Notifier
+	TokenSigner          TokenSigner
+	Cache                Cache
+	TemplateRenderer     TemplateRenderer
 }
 
 // ensures that the BaseApp implements the App interface.
@@ -70,15 +90,19 @@ var _ App = (*BaseApp)(nil)
 type BaseApp struct {
 	config              *BaseAppConfig
 	txInfo              *txAppInfo
+	ctx                 context.Context
 	store               *store.Store[any]
 	cron                *cron.Cron
 	settings            *Settings
+	cache               *settingsCache
 	subscriptionsBroker *subscriptions.Broker
 	logger              *slog.Logger
 	concurrentDB        dbx.Builder
 	nonconcurrentDB     dbx.Builder
 	auxConcurrentDB     dbx.Builder
 	auxNonconcurrentDB  dbx.Builder
+	dataWriteQueue      *writeQueue
+	auxWriteQueue       *writeQueue
 
 	// app event hooks
 	onBootstrap     *hook.Hook[*BootstrapEvent]
@@ -104,7 +128,9 @@ type BaseApp struct {
 
 	// db record hooks
 	onRecordEnrich             *hook.Hook[*RecordEnrichEvent]
+	onRecordEnrichResponse     *hook.Hook[*RecordEnrichResponseEvent]
 	onRecordValidate           *hook.Hook[*RecordEvent]
+	onRecordContentModeration  *hook.Hook[*RecordContentModerationEvent]
 	onRecordCreate             *hook.Hook[*RecordEvent]
 	onRecordCreateExecute      *hook.Hook[*RecordEvent]
 	onRecordAfterCreateSuccess *hook.Hook[*RecordEvent]
@@ -117,6 +143,7 @@ type BaseApp struct {
 	onRecordDeleteExecute      *hook.Hook[*RecordEvent]
 	onRecordAfterDeleteSuccess *hook.Hook[*RecordEvent]
 	onRecordAfterDeleteError   *hook.Hook[*RecordErrorEvent]
+	onRecordChange             *hook.Hook[*RecordChangeEvent]
 
 	// db collection hooks
 	onCollectionValidate           *hook.Hook[*CollectionEvent]
@@ -139,8 +166,15 @@ type BaseApp struct {
 	onMailerRecordVerificationSend  *hook.Hook[*MailerRecordEvent]
 	onMailerRecordEmailChangeSend   *hook.Hook[*MailerRecordEvent]
 	onMailerRecordOTPSend           *hook.Hook[*MailerRecordEvent]
+	onMailerRecordMagicLinkSend     *hook.Hook[*MailerRecordEvent]
 	onMailerRecordAuthAlertSend     *hook.Hook[*MailerRecordEvent]
 
+	// sms hooks
+	onSMSSend *hook.Hook[*SMSSendEvent]
+
+	// notification hooks
+	onRecordNotify *hook.Hook[*RecordNotifyEvent]
+
 	// realtime api event hooks
 	onRealtimeConnectRequest   *hook.Hook[*RealtimeConnectRequestEvent]
 	onRealtimeMessageSend      *hook.Hook[*RealtimeMessageEvent]
@@ -157,8 +191,12 @@ type BaseApp struct {
 
 	// record auth API event hooks
 	onRecordAuthRequest                 *hook.Hook[*RecordAuthRequestEvent]
+	onRecordNewDeviceLogin              *hook.Hook[*RecordNewDeviceLoginEvent]
 	onRecordAuthWithPasswordRequest     *hook.Hook[*RecordAuthWithPasswordRequestEvent]
 	onRecordAuthWithOAuth2Request       *hook.Hook[*RecordAuthWithOAuth2RequestEvent]
+	onRecordAuthWithSAMLRequest         *hook.Hook[*RecordAuthWithSAMLRequestEvent]
+	onRecordAuthAsGuestRequest          *hook.Hook[*RecordAuthAsGuestRequestEvent]
+	onRecordGuestUpgradeRequest         *hook.Hook[*RecordGuestUpgradeRequestEvent]
 	onRecordAuthRefreshRequest          *hook.Hook[*RecordAuthRefreshRequestEvent]
 	onRecordRequestPasswordResetRequest *hook.Hook[*RecordRequestPasswordResetRequestEvent]
 	onRecordConfirmPasswordResetRequest *hook.Hook[*RecordConfirmPasswordResetRequestEvent]
@@ -168,6 +206,12 @@ type BaseApp struct {
 	onRecordConfirmEmailChangeRequest   *hook.Hook[*RecordConfirmEmailChangeRequestEvent]
 	onRecordRequestOTPRequest           *hook.Hook[*RecordCreateOTPRequestEvent]
 	onRecordAuthWithOTPRequest          *hook.Hook[*RecordAuthWithOTPRequestEvent]
+	onRecordRequestMagicLinkRequest     *hook.Hook[*RecordRequestMagicLinkRequestEvent]
+	onRecordAuthWithMagicLinkRequest    *hook.Hook[*RecordAuthWithMagicLinkRequestEvent]
+	onRecordRequestSMSOTPRequest        *hook.Hook[*RecordRequestSMSOTPRequestEvent]
+	onRecordAuthWithSMSOTPRequest       *hook.Hook[*RecordAuthWithSMSOTPRequestEvent]
+	onRecordAuthLockout                 *hook.Hook[*RecordAuthLockoutEvent]
+	onActionLinkRequest                 *hook.Hook[*ActionLinkRequestEvent]
 
 	// record crud API event hooks
 	onRecordsListRequest  *hook.Hook[*RecordsListRequestEvent]
@@ -194,6 +238,7 @@ type BaseApp struct {
 func NewBaseApp(config BaseAppConfig) *BaseApp {
 	app := &BaseApp{
 		settings:            newDefaultSettings(),
+		cache:               newSettingsCache(),
 		store:               store.New[any](nil),
 		cron:                cron.New(),
 		subscriptionsBroker: subscriptions.NewBroker(),
@@ -219,6 +264,15 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 	if app.config.QueryTimeout <= 0 {
 		app.config.QueryTimeout = DefaultQueryTimeout
 	}
+	if app.config.WriteQueueSize == 0 {
+		app.config.WriteQueueSize = DefaultWriteQueueSize
+	}
+	if app.config.TokenSigner == nil {
+		app.config.TokenSigner = HS256TokenSigner{}
+	}
+
+	app.dataWriteQueue = newWriteQueue(app.config.WriteQueueSize)
+	app.auxWriteQueue = newWriteQueue(app.config.WriteQueueSize)
 
 	app.initHooks()
 	app.registerBaseHooks()
@@ -252,7 +306,9 @@ func (app *BaseApp) initHooks() {
 
 	// db record hooks
 	app.onRecordEnrich = &hook.Hook[*RecordEnrichEvent]{}
+	app.onRecordEnrichResponse = &hook.Hook[*RecordEnrichResponseEvent]{}
 	app.onRecordValidate = &hook.Hook[*RecordEvent]{}
+	app.onRecordContentModeration = &hook.Hook[*RecordContentModerationEvent]{}
 	app.onRecordCreate = &hook.Hook[*RecordEvent]{}
 	app.onRecordCreateExecute = &hook.Hook[*RecordEvent]{}
 	app.onRecordAfterCreateSuccess = &hook.Hook[*RecordEvent]{}
@@ -265,6 +321,7 @@ func (app *BaseApp) initHooks() {
 	app.onRecordDeleteExecute = &hook.Hook[*RecordEvent]{}
 	app.onRecordAfterDeleteSuccess = &hook.Hook[*RecordEvent]{}
 	app.onRecordAfterDeleteError = &hook.Hook[*RecordErrorEvent]{}
+	app.onRecordChange = &hook.Hook[*RecordChangeEvent]{}
 
 	// db collection hooks
 	app.onCollectionValidate = &hook.Hook[*CollectionEvent]{}
@@ -287,8 +344,15 @@ func (app *BaseApp) initHooks() {
 	app.onMailerRecordVerificationSend = &hook.Hook[*MailerRecordEvent]{}
 	app.onMailerRecordEmailChangeSend = &hook.Hook[*MailerRecordEvent]{}
 	app.onMailerRecordOTPSend = &hook.Hook[*MailerRecordEvent]{}
+	app.onMailerRecordMagicLinkSend = &hook.Hook[*MailerRecordEvent]{}
 	app.onMailerRecordAuthAlertSend = &hook.Hook[*MailerRecordEvent]{}
 
+	// sms hooks
+	app.onSMSSend = &hook.Hook[*SMSSendEvent]{}
+
+	// notification hooks
+	app.onRecordNotify = &hook.Hook[*RecordNotifyEvent]{}
+
 	// realtime API event hooks
 	app.onRealtimeConnectRequest = &hook.Hook[*RealtimeConnectRequestEvent]{}
 	app.onRealtimeMessageSend = &hook.Hook[*RealtimeMessageEvent]{}
@@ -305,8 +369,12 @@ func (app *BaseApp) initHooks() {
 
 	// record auth API event hooks
 	app.onRecordAuthRequest = &hook.Hook[*RecordAuthRequestEvent]{}
+	app.onRecordNewDeviceLogin = &hook.Hook[*RecordNewDeviceLoginEvent]{}
 	app.onRecordAuthWithPasswordRequest = &hook.Hook[*RecordAuthWithPasswordRequestEvent]{}
 	app.onRecordAuthWithOAuth2Request = &hook.Hook[*RecordAuthWithOAuth2RequestEvent]{}
+	app.onRecordAuthWithSAMLRequest = &hook.Hook[*RecordAuthWithSAMLRequestEvent]{}
+	app.onRecordAuthAsGuestRequest = &hook.Hook[*RecordAuthAsGuestRequestEvent]{}
+	app.onRecordGuestUpgradeRequest = &hook.Hook[*RecordGuestUpgradeRequestEvent]{}
 	app.onRecordAuthRefreshRequest = &hook.Hook[*RecordAuthRefreshRequestEvent]{}
 	app.onRecordRequestPasswordResetRequest = &hook.Hook[*RecordRequestPasswordResetRequestEvent]{}
 	app.onRecordConfirmPasswordResetRequest = &hook.Hook[*RecordConfirmPasswordResetRequestEvent]{}
@@ -316,6 +384,12 @@ func (app *BaseApp) initHooks() {
 	app.onRecordConfirmEmailChangeRequest = &hook.Hook[*RecordConfirmEmailChangeRequestEvent]{}
 	app.onRecordRequestOTPRequest = &hook.Hook[*RecordCreateOTPRequestEvent]{}
 	app.onRecordAuthWithOTPRequest = &hook.Hook[*RecordAuthWithOTPRequestEvent]{}
+	app.onRecordRequestMagicLinkRequest = &hook.Hook[*RecordRequestMagicLinkRequestEvent]{}
+	app.onRecordAuthWithMagicLinkRequest = &hook.Hook[*RecordAuthWithMagicLinkRequestEvent]{}
+	app.onRecordRequestSMSOTPRequest = &hook.Hook[*RecordRequestSMSOTPRequestEvent]{}
+	app.onRecordAuthWithSMSOTPRequest = &hook.Hook[*RecordAuthWithSMSOTPRequestEvent]{}
+	app.onRecordAuthLockout = &hook.Hook[*RecordAuthLockoutEvent]{}
+	app.onActionLinkRequest = &hook.Hook[*ActionLinkRequestEvent]{}
 
 	// record crud API event hooks
 	app.onRecordsListRequest = &hook.Hook[*RecordsListRequestEvent]{}
@@ -414,6 +488,10 @@ func (app *BaseApp) Bootstrap() error {
 			return err
 		}
 
+		if err := syncRegisteredModels(app); err != nil {
+			return err
+		}
+
 		// try to cleanup the pb_data temp directory (if any)
 		_ = os.RemoveAll(filepath.Join(app.DataDir(), LocalTempDirName))
 
@@ -459,6 +537,11 @@ func (app *BaseApp) ResetBootstrapState() error {
 		*db = nil
 	}
 
+	// revert to the default in-memory cache and close any open Redis connections
+	if err := app.cache.refresh(CacheConfig{}); err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -541,18 +624,75 @@ func (app *BaseApp) Cron() *cron.Cron {
 	return app.cron
 }
 
+// Context returns the context associated with the current app instance
+// (default to [context.Background]).
+func (app *BaseApp) Context() context.Context {
+	if app.ctx == nil {
+		return context.Background()
+	}
+
+	return app.ctx
+}
+
+// WithContext returns a shallow copy of the current app instance
+// bound to the provided context.
+//
+// All db queries, model operations and transactions executed with the
+// returned app instance are limited by the context.
+func (app *BaseApp) WithContext(ctx context.Context) App {
+	clone := *app
+	clone.ctx = ctx
+
+	// note: the transaction builders are already bound to their own context
+	dbs := []*dbx.Builder{
+		&clone.concurrentDB,
+		&clone.nonconcurrentDB,
+		&clone.auxConcurrentDB,
+		&clone.auxNonconcurrentDB,
+	}
+	for _, db := range dbs {
+		if v, ok := (*db).(*dbx.DB); ok {
+			*db = v.WithContext(ctx)
+		}
+	}
+
+	return &clone
+}
+
 // SubscriptionsBroker returns the app realtime subscriptions broker instance.
 func (app *BaseApp) SubscriptionsBroker() *subscriptions.Broker {
 	return app.subscriptionsBroker
 }
 
+// Cache returns the app cache store.
+//
+// If [BaseAppConfig.Cache] is not set, it fallbacks to the Redis
+// cache store (if enabled in the app settings) or to an in-memory cache.
+func (app *BaseApp) Cache() Cache {
+	if app.config.Cache != nil {
+		return app.config.Cache
+	}
+
+	return app.cache
+}
+
+// TokenSigner returns the app record tokens signer.
+//
+// Default to [HS256TokenSigner] if [BaseAppConfig.TokenSigner] is not set.
+func (app *BaseApp) TokenSigner() TokenSigner {
+	return app.config.TokenSigner
+}
+
 // NewMailClient creates and returns a new SMTP or Sendmail client
-// based on the current app settings.
+// based on the current app settings
+// (or the [BaseAppConfig.NewMailClient] one if set).
 func (app *BaseApp) NewMailClient() mailer.Mailer {
 	var client mailer.Mailer
 
 	// init mailer client
-	if app.Settings().SMTP.Enabled {
+	if app.config.NewMailClient != nil {
+		client = app.config.NewMailClient(app)
+	} else if app.Settings().SMTP.Enabled {
 		client = &mailer.SMTPClient{
 			Host:       app.Settings().SMTP.Host,
 			Port:       app.Settings().SMTP.Port,
@@ -631,11 +771,16 @@ func (app *BaseApp) NewMailClient() mailer.Mailer {
 
 // NewFilesystem creates a new local or S3 filesystem instance
 // for managing regular app files (ex. record uploads)
-// based on the current app settings.
+// based on the current app settings
+// (or the [BaseAppConfig.NewFilesystem] one if set).
 //
 // NB! Make sure to call Close() on the returned result
 // after you are done working with it.
 func (app *BaseApp) NewFilesystem() (*filesystem.System, error) {
+	if app.config.NewFilesystem != nil {
+		return app.config.NewFilesystem(app)
+	}
+
 	if app.settings != nil && app.settings.S3.Enabled {
 		return filesystem.NewS3(
 			app.settings.S3.Bucket,
@@ -652,11 +797,16 @@ func (app *BaseApp) NewFilesystem() (*filesystem.System, error) {
 }
 
 // NewFilesystem creates a new local or S3 filesystem instance
-// for managing app backups based on the current app settings.
+// for managing app backups based on the current app settings
+// (or the [BaseAppConfig.NewBackupsFilesystem] one if set).
 //
 // NB! Make sure to call Close() on the returned result
 // after you are done working with it.
 func (app *BaseApp) NewBackupsFilesystem() (*filesystem.System, error) {
+	if app.config.NewBackupsFilesystem != nil {
+		return app.config.NewBackupsFilesystem(app)
+	}
+
 	if app.settings != nil && app.settings.Backups.S3.Enabled {
 		return filesystem.NewS3(
 			app.settings.Backups.S3.Bucket,
@@ -672,6 +822,23 @@ func (app *BaseApp) NewBackupsFilesystem() (*filesystem.System, error) {
 	return filesystem.NewLocal(filepath.Join(app.DataDir(), LocalBackupsDirName))
 }
 
+// NewAIClient creates a new OpenAI compatible embeddings and
+// chat completions client based on the current app settings.
+//
+// Returns an error if the AI integration is not enabled.
+func (app *BaseApp) NewAIClient() (*ai.Client, error) {
+	if app.settings == nil || !app.settings.AI.Enabled {
+		return nil, errors.New("the AI integration is not enabled")
+	}
+
+	return &ai.Client{
+		BaseURL:         app.settings.AI.BaseURL,
+		APIKey:          app.settings.AI.APIKey,
+		CompletionModel: app.settings.AI.CompletionModel,
+		EmbeddingModel:  app.settings.AI.EmbeddingModel,
+	}, nil
+}
+
 // Restart restarts (aka. replaces) the current running application process.
 //
 // NB! It relies on execve which is supported only on UNIX based systems.
@@ -807,10 +974,18 @@ func (app *BaseApp) OnRecordEnrich(tags ...string) *hook.TaggedHook[*RecordEnric
 	return hook.NewTaggedHook(app.onRecordEnrich, tags...)
 }
 
+func (app *BaseApp) OnRecordEnrichResponse(tags ...string) *hook.TaggedHook[*RecordEnrichResponseEvent] {
+	return hook.NewTaggedHook(app.onRecordEnrichResponse, tags...)
+}
+
 func (app *BaseApp) OnRecordValidate(tags ...string) *hook.TaggedHook[*RecordEvent] {
 	return hook.NewTaggedHook(app.onRecordValidate, tags...)
 }
 
+func (app *BaseApp) OnRecordContentModeration(tags ...string) *hook.TaggedHook[*RecordContentModerationEvent] {
+	return hook.NewTaggedHook(app.onRecordContentModeration, tags...)
+}
+
 func (app *BaseApp) OnRecordCreate(tags ...string) *hook.TaggedHook[*RecordEvent] {
 	return hook.NewTaggedHook(app.onRecordCreate, tags...)
 }
@@ -859,6 +1034,10 @@ func (app *BaseApp) OnRecordAfterDeleteError(tags ...string) *hook.TaggedHook[*R
 	return hook.NewTaggedHook(app.onRecordAfterDeleteError, tags...)
 }
 
+func (app *BaseApp) OnRecordChange(tags ...string) *hook.TaggedHook[*RecordChangeEvent] {
+	return hook.NewTaggedHook(app.onRecordChange, tags...)
+}
+
 func (app *BaseApp) OnCollectionValidate(tags ...string) *hook.TaggedHook[*CollectionEvent] {
 	return hook.NewTaggedHook(app.onCollectionValidate, tags...)
 }
@@ -935,6 +1114,26 @@ func (app *BaseApp) OnMailerRecordOTPSend(tags ...string) *hook.TaggedHook[*Mail
 	return hook.NewTaggedHook(app.onMailerRecordOTPSend, tags...)
 }
 
+func (app *BaseApp) OnMailerRecordMagicLinkSend(tags ...string) *hook.TaggedHook[*MailerRecordEvent] {
+	return hook.NewTaggedHook(app.onMailerRecordMagicLinkSend, tags...)
+}
+
+// -------------------------------------------------------------------
+// SMS event hooks
+// -------------------------------------------------------------------
+
+func (app *BaseApp) OnSMSSend() *hook.Hook[*SMSSendEvent] {
+	return app.onSMSSend
+}
+
+// -------------------------------------------------------------------
+// Notification event hooks
+// -------------------------------------------------------------------
+
+func (app *BaseApp) OnRecordNotify(tags ...string) *hook.TaggedHook[*RecordNotifyEvent] {
+	return hook.NewTaggedHook(app.onRecordNotify, tags...)
+}
+
 func (app *BaseApp) OnMailerRecordAuthAlertSend(tags ...string) *hook.TaggedHook[*MailerRecordEvent] {
 	return hook.NewTaggedHook(app.onMailerRecordAuthAlertSend, tags...)
 }
@@ -991,6 +1190,10 @@ func (app *BaseApp) OnRecordAuthRequest(tags ...string) *hook.TaggedHook[*Record
 	return hook.NewTaggedHook(app.onRecordAuthRequest, tags...)
 }
 
+func (app *BaseApp) OnRecordNewDeviceLogin(tags ...string) *hook.TaggedHook[*RecordNewDeviceLoginEvent] {
+	return hook.NewTaggedHook(app.onRecordNewDeviceLogin, tags...)
+}
+
 func (app *BaseApp) OnRecordAuthWithPasswordRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithPasswordRequestEvent] {
 	return hook.NewTaggedHook(app.onRecordAuthWithPasswordRequest, tags...)
 }
@@ -999,6 +1202,18 @@ func (app *BaseApp) OnRecordAuthWithOAuth2Request(tags ...string) *hook.TaggedHo
 	return hook.NewTaggedHook(app.onRecordAuthWithOAuth2Request, tags...)
 }
 
+func (app *BaseApp) OnRecordAuthWithSAMLRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithSAMLRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthWithSAMLRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAuthAsGuestRequest(tags ...string) *hook.TaggedHook[*RecordAuthAsGuestRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthAsGuestRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordGuestUpgradeRequest(tags ...string) *hook.TaggedHook[*RecordGuestUpgradeRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordGuestUpgradeRequest, tags...)
+}
+
 func (app *BaseApp) OnRecordAuthRefreshRequest(tags ...string) *hook.TaggedHook[*RecordAuthRefreshRequestEvent] {
 	return hook.NewTaggedHook(app.onRecordAuthRefreshRequest, tags...)
 }
@@ -1035,6 +1250,30 @@ func (app *BaseApp) OnRecordAuthWithOTPRequest(tags ...string) *hook.TaggedHook[
 	return hook.NewTaggedHook(app.onRecordAuthWithOTPRequest, tags...)
 }
 
+func (app *BaseApp) OnRecordRequestMagicLinkRequest(tags ...string) *hook.TaggedHook[*RecordRequestMagicLinkRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordRequestMagicLinkRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAuthWithMagicLinkRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithMagicLinkRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthWithMagicLinkRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordRequestSMSOTPRequest(tags ...string) *hook.TaggedHook[*RecordRequestSMSOTPRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordRequestSMSOTPRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAuthWithSMSOTPRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithSMSOTPRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthWithSMSOTPRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAuthLockout(tags ...string) *hook.TaggedHook[*RecordAuthLockoutEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthLockout, tags...)
+}
+
+func (app *BaseApp) OnActionLinkRequest(tags ...string) *hook.TaggedHook[*ActionLinkRequestEvent] {
+	return hook.NewTaggedHook(app.onActionLinkRequest, tags...)
+}
+
 // -------------------------------------------------------------------
 // Record CRUD API event hooks
 // -------------------------------------------------------------------
@@ -1199,6 +1438,23 @@ func (app *BaseApp) registerBaseHooks() {
 		return nil
 	}
 
+	// switch the app cache store on settings change
+	app.OnSettingsReload().Bind(&hook.Handler[*SettingsReloadEvent]{
+		Id: "__pbCacheOnSettingsReload__",
+		Func: func(e *SettingsReloadEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			if err := app.cache.refresh(e.App.Settings().Cache); err != nil {
+				e.App.Logger().Warn("Failed to close the previous app cache store", "error", err)
+			}
+
+			return nil
+		},
+		Priority: -999,
+	})
+
 	// try to delete the storage files from deleted Collection, Records, etc. model
 	app.OnModelAfterDeleteSuccess().Bind(&hook.Handler[*ModelEvent]{
 		Id: "__pbFilesManagerDelete__",
@@ -1257,10 +1513,15 @@ func (app *BaseApp) registerBaseHooks() {
 	app.registerAutobackupHooks()
 	app.registerCollectionHooks()
 	app.registerRecordHooks()
+	app.registerRecordSearchHooks()
+	app.registerRecordChangeHooks()
 	app.registerSuperuserHooks()
 	app.registerExternalAuthHooks()
 	app.registerMFAHooks()
 	app.registerOTPHooks()
+	app.registerAPIKeyHooks()
+	app.registerSessionHooks()
+	app.registerOAuthClientHooks()
 	app.registerAuthOriginHooks()
 }
 
@@ -1286,13 +1547,10 @@ func getLoggerMinLevel(app App) slog.Level {
 }
 
 func (app *BaseApp) initLogger() error {
-	duration := 3 * time.Second
-	ticker := time.NewTicker(duration)
-	done := make(chan bool)
-
 	handler := logger.NewBatchHandler(logger.BatchOptions{
-		Level:     getLoggerMinLevel(app),
-		BatchSize: 200,
+		Level:         getLoggerMinLevel(app),
+		BatchSize:     200,
+		FlushInterval: 3 * time.Second,
 		BeforeAddFunc: func(ctx context.Context, log *logger.Log) bool {
 			if app.IsDev() {
 				printLog(log)
@@ -1303,8 +1561,6 @@ func (app *BaseApp) initLogger() error {
 				}
 			}
 
-			ticker.Reset(duration)
-
 			return app.Settings().Logs.MaxDays > 0
 		},
 		WriteFunc: func(ctx context.Context, logs []*logger.Log) error {
@@ -1336,30 +1592,17 @@ func (app *BaseApp) initLogger() error {
 		},
 	})
 
-	go func() {
-		ctx := context.Background()
-
-		for {
-			select {
-			case <-done:
-				return
-			case <-ticker.C:
-				handler.WriteAll(ctx)
-			}
-		}
-	}()
-
 	app.logger = slog.New(handler)
 
-	// write all remaining logs before ticker.Stop to avoid races with ResetBootstrap user calls
+	// stop the background flush and write all remaining logs
+	// before the app resources are released to avoid races with ResetBootstrap user calls
 	app.OnTerminate().Bind(&hook.Handler[*TerminateEvent]{
 		Id: "__pbAppLoggerOnTerminate__",
 		Func: func(e *TerminateEvent) error {
-			handler.WriteAll(context.Background())
-
-			ticker.Stop()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
 
-			done <- true
+			handler.Close(ctx)
 
 			return e.Next()
 		},
@@ -3,6 +3,7 @@ package core_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log/slog"
 	"os"
 	"testing"
@@ -13,8 +14,10 @@ import (
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/logger"
 	"github.com/pocketbase/pocketbase/tools/mailer"
+	"gocloud.dev/blob/memblob"
 )
 
 func TestNewBaseApp(t *testing.T) {
@@ -54,6 +57,78 @@ func TestNewBaseApp(t *testing.T) {
 	if app.Cron() == nil {
 		t.Fatal("expected Cron to be set, got nil")
 	}
+
+	if app.Cache() == nil {
+		t.Fatal("expected Cache to be set, got nil")
+	}
+
+	if _, ok := app.TokenSigner().(core.HS256TokenSigner); !ok {
+		t.Fatalf("expected the default HS256TokenSigner, got %T", app.TokenSigner())
+	}
+}
+
+func TestNewBaseAppWithCustomInfrastructure(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	cache := core.NewMemoryCache()
+	signer := core.HS256TokenSigner{}
+
+	var filesystemCalls, backupsFilesystemCalls, mailClientCalls int
+
+	app := core.NewBaseApp(core.BaseAppConfig{
+		DataDir: testDataDir,
+		NewFilesystem: func(app core.App) (*filesystem.System, error) {
+			filesystemCalls++
+			return filesystem.NewFromBucket(memblob.OpenBucket(nil)), nil
+		},
+		NewBackupsFilesystem: func(app core.App) (*filesystem.System, error) {
+			backupsFilesystemCalls++
+			return nil, errors.New("test")
+		},
+		NewMailClient: func(app core.App) mailer.Mailer {
+			mailClientCalls++
+			return &mailer.Sendmail{}
+		},
+		TokenSigner: signer,
+		Cache:       cache,
+	})
+	defer app.ResetBootstrapState()
+
+	if app.Cache() != cache {
+		t.Fatal("Expected the custom cache")
+	}
+
+	if app.TokenSigner() != signer {
+		t.Fatal("Expected the custom token signer")
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+	if filesystemCalls != 1 {
+		t.Fatalf("Expected 1 NewFilesystem call, got %d", filesystemCalls)
+	}
+
+	if _, err := app.NewBackupsFilesystem(); err == nil {
+		t.Fatal("Expected the custom NewBackupsFilesystem error")
+	}
+	if backupsFilesystemCalls != 1 {
+		t.Fatalf("Expected 1 NewBackupsFilesystem call, got %d", backupsFilesystemCalls)
+	}
+
+	client, ok := app.NewMailClient().(*mailer.Sendmail)
+	if !ok {
+		t.Fatalf("Expected the custom mail client, got %T", client)
+	}
+	if mailClientCalls != 1 {
+		t.Fatalf("Expected 1 NewMailClient call, got %d", mailClientCalls)
+	}
+	if client.OnSend() == nil || client.OnSend().Length() == 0 {
+		t.Fatal("Expected OnSend hook to be registered")
+	}
 }
 
 func TestBaseAppBootstrap(t *testing.T) {
@@ -154,6 +229,65 @@ func TestNewBaseAppIsTransactional(t *testing.T) {
 	})
 }
 
+func TestBaseAppWithContext(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if app.Context() != context.Background() {
+		t.Fatal("Expected the default app context to be context.Background()")
+	}
+
+	type ctxKey struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "test"))
+
+	ctxApp := app.WithContext(ctx)
+
+	if ctxApp.Context() != ctx {
+		t.Fatal("Expected the bound app context")
+	}
+
+	// the context should be passed to the model hooks
+	var hookCtxValue any
+	app.OnModelValidate().BindFunc(func(e *core.ModelEvent) error {
+		hookCtxValue = e.Context.Value(ctxKey{})
+		return e.Next()
+	})
+
+	record, err := ctxApp.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ctxApp.Validate(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if hookCtxValue != "test" {
+		t.Fatalf("Expected the model hook context value %q, got %v", "test", hookCtxValue)
+	}
+
+	cancel()
+
+	// the queries of the bound app should be cancelled
+	if _, err := ctxApp.FindRecordById("demo1", "84nmscqy84lsi1t"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+
+	err = ctxApp.RunInTransaction(func(txApp core.App) error {
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled transaction error, got %v", err)
+	}
+
+	// the original app shouldn't be affected
+	if _, err := app.FindRecordById("demo1", "84nmscqy84lsi1t"); err != nil {
+		t.Fatalf("Expected the original app query to succeed, got %v", err)
+	}
+}
+
 func TestBaseAppNewMailClient(t *testing.T) {
 	const testDataDir = "./pb_base_app_test_data_dir/"
 	defer os.RemoveAll(testDataDir)
@@ -243,6 +377,40 @@ func TestBaseAppNewBackupsFilesystem(t *testing.T) {
 	}
 }
 
+func TestBaseAppNewAIClient(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	app := core.NewBaseApp(core.BaseAppConfig{
+		DataDir: testDataDir,
+	})
+	defer app.ResetBootstrapState()
+
+	// disabled
+	client, err := app.NewAIClient()
+	if err == nil {
+		t.Fatalf("Expected error for disabled AI integration, got client %v", client)
+	}
+
+	app.Settings().AI.Enabled = true
+	app.Settings().AI.BaseURL = "http://localhost:11434/v1"
+	app.Settings().AI.APIKey = "test_key"
+	app.Settings().AI.CompletionModel = "test_completion"
+	app.Settings().AI.EmbeddingModel = "test_embedding"
+
+	client, err = app.NewAIClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.BaseURL != "http://localhost:11434/v1" ||
+		client.APIKey != "test_key" ||
+		client.CompletionModel != "test_completion" ||
+		client.EmbeddingModel != "test_embedding" {
+		t.Fatalf("The client doesn't match the app settings: %#v", client)
+	}
+}
+
 func TestBaseAppLoggerWrites(t *testing.T) {
 	t.Parallel()
 
@@ -290,9 +458,18 @@ func TestBaseAppLoggerWrites(t *testing.T) {
 			t.Fatalf("Expected no logs, got %d", total)
 		}
 
-		// should trigger batch write
+		// should trigger (async) batch write
 		app.Logger().Error("test")
 
+		// wait for the background flush
+		for i := 0; i < 50 && totalLogs(app, t) != logsThreshold; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if total := totalLogs(app, t); total != logsThreshold {
+			t.Fatalf("Expected %d logs, got %d", logsThreshold, total)
+		}
+
 		// should be added for the next batch write
 		app.Logger().Error("test")
 
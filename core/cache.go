@@ -0,0 +1,233 @@
+package core
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/redis"
+)
+
+// Cache defines a minimal key-value app cache store interface.
+//
+// Unlike [App.Store], the cache values are raw bytes so that
+// the implementations could be backed by an external service.
+type Cache interface {
+	// Get returns the cached value of the specified key and
+	// whether it was found.
+	Get(key string) ([]byte, bool, error)
+
+	// Set stores the value under the specified key.
+	//
+	// The key expires after ttl (0 or negative value means no expiration).
+	Set(key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the specified key from the cache.
+	//
+	// Deleting a missing key is not an error.
+	Delete(key string) error
+}
+
+// memoryCacheSweepInterval is the min interval between two
+// expired items sweeps of the in-memory cache.
+const memoryCacheSweepInterval = 1 * time.Minute
+
+// NewMemoryCache creates a new in-memory [Cache] implementation.
+//
+// This is the default [App.Cache] implementation.
+//
+// The expired items are removed lazily on Get and periodically on Set
+// (at most once per minute) so that the never read expired items don't
+// accumulate in memory.
+func NewMemoryCache() Cache {
+	return &memoryCache{items: map[string]memoryCacheItem{}}
+}
+
+type memoryCacheItem struct {
+	expires time.Time
+	value   []byte
+}
+
+func (item memoryCacheItem) hasExpired(now time.Time) bool {
+	return !item.expires.IsZero() && !now.Before(item.expires)
+}
+
+type memoryCache struct {
+	mu        sync.RWMutex
+	items     map[string]memoryCacheItem
+	lastSweep time.Time
+}
+
+func (c *memoryCache) Get(key string) ([]byte, bool, error) {
+	c.mu.RLock()
+	item, ok := c.items[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	if item.hasExpired(time.Now()) {
+		c.mu.Lock()
+		// recheck in case the item was replaced in the meantime
+		if current, ok := c.items[key]; ok && current.hasExpired(time.Now()) {
+			delete(c.items, key)
+		}
+		c.mu.Unlock()
+
+		return nil, false, nil
+	}
+
+	return slices.Clone(item.value), true, nil
+}
+
+func (c *memoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	now := time.Now()
+
+	item := memoryCacheItem{value: slices.Clone(value)}
+
+	if ttl > 0 {
+		item.expires = now.Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.lastSweep) >= memoryCacheSweepInterval {
+		c.sweep(now)
+	}
+
+	c.items[key] = item
+
+	return nil
+}
+
+func (c *memoryCache) Delete(key string) error {
+	c.mu.Lock()
+	delete(c.items, key)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// sweep removes all expired items.
+//
+// It is expected to be called with a write lock.
+func (c *memoryCache) sweep(now time.Time) {
+	for key, item := range c.items {
+		if item.hasExpired(now) {
+			delete(c.items, key)
+		}
+	}
+
+	c.lastSweep = now
+}
+
+// NewRedisCache creates a new Redis backed [Cache] implementation.
+//
+// All cache keys are prefixed with the provided keyPrefix
+// (could be used to share the same Redis db between multiple apps).
+//
+// The returned cache implements [io.Closer] and it is expected
+// to be closed once no longer needed.
+func NewRedisCache(client *redis.Client, keyPrefix string) Cache {
+	return &redisCache{client: client, prefix: keyPrefix}
+}
+
+type redisCache struct {
+	client *redis.Client
+	prefix string
+}
+
+func (c *redisCache) Get(key string) ([]byte, bool, error) {
+	return c.client.Get(context.Background(), c.prefix+key)
+}
+
+func (c *redisCache) Set(key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(context.Background(), c.prefix+key, value, ttl)
+}
+
+func (c *redisCache) Delete(key string) error {
+	return c.client.Del(context.Background(), c.prefix+key)
+}
+
+// Close implements the [io.Closer] interface.
+func (c *redisCache) Close() error {
+	return c.client.Close()
+}
+
+// settingsCache is a [Cache] proxy that switches between the in-memory
+// and the Redis cache store based on the app settings.
+type settingsCache struct {
+	mu     sync.RWMutex
+	cache  Cache
+	config CacheConfig
+}
+
+func newSettingsCache() *settingsCache {
+	return &settingsCache{cache: NewMemoryCache()}
+}
+
+func (c *settingsCache) current() Cache {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cache
+}
+
+func (c *settingsCache) Get(key string) ([]byte, bool, error) {
+	return c.current().Get(key)
+}
+
+func (c *settingsCache) Set(key string, value []byte, ttl time.Duration) error {
+	return c.current().Set(key, value, ttl)
+}
+
+func (c *settingsCache) Delete(key string) error {
+	return c.current().Delete(key)
+}
+
+// refresh replaces the underlying cache store if the cache settings have changed.
+func (c *settingsCache) refresh(config CacheConfig) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.config == config {
+		return nil // no changes
+	}
+
+	old := c.cache
+
+	if config.RedisEnabled {
+		c.cache = NewRedisCache(redis.New(redis.Options{
+			Addr:     config.RedisAddr,
+			Username: config.RedisUsername,
+			Password: config.RedisPassword,
+			DB:       config.RedisDB,
+		}), config.KeyPrefix)
+	} else if c.config.RedisEnabled {
+		c.cache = NewMemoryCache()
+	}
+
+	c.config = config
+
+	if closer, ok := old.(io.Closer); ok && old != c.cache {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// Close implements the [io.Closer] interface.
+func (c *settingsCache) Close() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if closer, ok := c.cache.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheSweep(t *testing.T) {
+	t.Parallel()
+
+	cache := NewMemoryCache().(*memoryCache)
+
+	cache.Set("a", []byte("a"), time.Millisecond)
+	cache.Set("b", []byte("b"), time.Hour)
+	cache.Set("c", []byte("c"), 0)
+
+	time.Sleep(5 * time.Millisecond)
+
+	// no sweep before the sweep interval
+	cache.Set("d", []byte("d"), 0)
+	if total := len(cache.items); total != 4 {
+		t.Fatalf("Expected 4 items before the sweep, got %d", total)
+	}
+
+	cache.mu.Lock()
+	cache.lastSweep = cache.lastSweep.Add(-memoryCacheSweepInterval)
+	cache.mu.Unlock()
+
+	cache.Set("e", []byte("e"), 0)
+
+	if _, ok := cache.items["a"]; ok {
+		t.Fatal("Expected the expired item to be swept")
+	}
+
+	for _, key := range []string{"b", "c", "d", "e"} {
+		if _, ok := cache.items[key]; !ok {
+			t.Fatalf("Expected item %q to remain", key)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestMemoryCache(t *testing.T) {
+	t.Parallel()
+
+	cache := core.NewMemoryCache()
+
+	if _, ok, err := cache.Get("missing"); ok || err != nil {
+		t.Fatalf("Expected missing key, got ok %v (%v)", ok, err)
+	}
+
+	value := []byte("test")
+	if err := cache.Set("a", value, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// the stored value shouldn't be affected by the source slice changes
+	value[0] = 'x'
+
+	v, ok, err := cache.Get("a")
+	if err != nil || !ok {
+		t.Fatalf("Expected existing key, got ok %v (%v)", ok, err)
+	}
+	if string(v) != "test" {
+		t.Fatalf("Expected value %q, got %q", "test", v)
+	}
+
+	if err := cache.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, _ := cache.Get("a"); ok {
+		t.Fatal("Expected the key to be deleted")
+	}
+
+	// deleting a missing key
+	if err := cache.Delete("missing"); err != nil {
+		t.Fatalf("Expected no error for missing key, got %v", err)
+	}
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	cache := core.NewMemoryCache()
+
+	if err := cache.Set("a", []byte("a"), 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cache.Set("b", []byte("b"), -1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, _ := cache.Get("a"); !ok {
+		t.Fatal("Expected the key to exist before its expiration")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if _, ok, _ := cache.Get("a"); ok {
+		t.Fatal("Expected the key to be expired")
+	}
+
+	if _, ok, _ := cache.Get("b"); !ok {
+		t.Fatal("Expected the key without ttl to exist")
+	}
+}
+
+func TestAppCacheSettings(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := app.Cache().Set("test", []byte("test"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// enable Redis with unreachable address
+	app.Settings().Cache.RedisEnabled = true
+	app.Settings().Cache.RedisAddr = "127.0.0.1:1"
+	if err := app.Save(app.Settings()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := app.Cache().Get("test"); err == nil {
+		t.Fatal("Expected Redis connection error, got nil")
+	}
+
+	// revert back to the in-memory cache
+	app.Settings().Cache.RedisEnabled = false
+	if err := app.Save(app.Settings()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := app.Cache().Get("test"); ok || err != nil {
+		t.Fatalf("Expected a new empty in-memory cache, got ok %v (%v)", ok, err)
+	}
+}
+
+func TestAppCacheCustom(t *testing.T) {
+	t.Parallel()
+
+	custom := core.NewMemoryCache()
+
+	app := core.NewBaseApp(core.BaseAppConfig{Cache: custom})
+
+	if app.Cache() != custom {
+		t.Fatal("Expected the custom cache store")
+	}
+}
@@ -0,0 +1,26 @@
+package core
+
+// StoreKeyFollowerNode is the app store key used by the leader election
+// mechanisms (e.g. plugins/cluster) to mark the current app instance
+// as a follower node in a multi-instance setup.
+const StoreKeyFollowerNode = "@followerNode"
+
+// IsLeaderNode reports whether the current app instance is allowed to run
+// the singleton background tasks like the cron jobs and the automigrate snapshots.
+//
+// It always returns true unless the app instance was explicitly marked
+// as follower with [SetLeaderNode].
+func IsLeaderNode(app App) bool {
+	follower, _ := app.Store().Get(StoreKeyFollowerNode).(bool)
+
+	return !follower
+}
+
+// SetLeaderNode marks the current app instance as leader or follower node.
+func SetLeaderNode(app App, leader bool) {
+	if leader {
+		app.Store().Remove(StoreKeyFollowerNode)
+	} else {
+		app.Store().Set(StoreKeyFollowerNode, true)
+	}
+}
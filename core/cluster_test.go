@@ -0,0 +1,33 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestLeaderNode(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if !core.IsLeaderNode(app) {
+		t.Fatal("Expected the app to be leader by default")
+	}
+
+	core.SetLeaderNode(app, false)
+	if core.IsLeaderNode(app) {
+		t.Fatal("Expected the app to be follower")
+	}
+
+	core.SetLeaderNode(app, true)
+	if !core.IsLeaderNode(app) {
+		t.Fatal("Expected the app to be leader again")
+	}
+
+	if app.Store().Has(core.StoreKeyFollowerNode) {
+		t.Fatal("Expected the follower store key to be removed")
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -339,6 +340,7 @@ type baseCollection struct {
 // Collection defines the table, fields and various options related to a set of records.
 type Collection struct {
 	baseCollection
+	collectionBaseOptions
 	collectionAuthOptions
 	collectionViewOptions
 }
@@ -469,10 +471,13 @@ func (m *Collection) unmarshalRawOptions() error {
 	case CollectionTypeView:
 		return json.Unmarshal(raw, &m.collectionViewOptions)
 	case CollectionTypeAuth:
+		if err := json.Unmarshal(raw, &m.collectionBaseOptions); err != nil {
+			return err
+		}
 		return json.Unmarshal(raw, &m.collectionAuthOptions)
 	}
 
-	return nil
+	return json.Unmarshal(raw, &m.collectionBaseOptions)
 }
 
 // UnmarshalJSON implements the [json.Unmarshaler] interface.
@@ -515,8 +520,9 @@ func (m Collection) MarshalJSON() ([]byte, error) {
 	case CollectionTypeAuth:
 		alias := struct {
 			baseCollection
+			collectionBaseOptions
 			collectionAuthOptions
-		}{m.baseCollection, m.collectionAuthOptions}
+		}{m.baseCollection, m.collectionBaseOptions, m.collectionAuthOptions}
 
 		// ensure that it is always returned as array
 		if alias.OAuth2.Providers == nil {
@@ -529,13 +535,17 @@ func (m Collection) MarshalJSON() ([]byte, error) {
 		alias.PasswordResetToken.Secret = ""
 		alias.EmailChangeToken.Secret = ""
 		alias.VerificationToken.Secret = ""
+		alias.MagicLinkToken.Secret = ""
 		for i := range alias.OAuth2.Providers {
 			alias.OAuth2.Providers[i].ClientSecret = ""
 		}
 
 		return json.Marshal(alias)
 	default:
-		return json.Marshal(m.baseCollection)
+		return json.Marshal(struct {
+			baseCollection
+			collectionBaseOptions
+		}{m.baseCollection, m.collectionBaseOptions})
 	}
 }
 
@@ -572,7 +582,16 @@ func (m *Collection) DBExport(app App) (map[string]any, error) {
 			return nil, err
 		}
 	case CollectionTypeAuth:
-		if raw, err := types.ParseJSONRaw(m.collectionAuthOptions); err == nil {
+		if raw, err := types.ParseJSONRaw(struct {
+			collectionBaseOptions
+			collectionAuthOptions
+		}{m.collectionBaseOptions, m.collectionAuthOptions}); err == nil {
+			result["options"] = raw
+		} else {
+			return nil, err
+		}
+	case CollectionTypeBase:
+		if raw, err := types.ParseJSONRaw(m.collectionBaseOptions); err == nil {
 			result["options"] = raw
 		} else {
 			return nil, err
@@ -631,6 +650,45 @@ func (m *Collection) RemoveIndex(name string) {
 	}
 }
 
+// SetIdStrategy changes the collection id field to use the specified
+// [AutogenerateStrategies] generator for the new records ids
+// (eg. [AutogenerateStrategyUUIDv7] or [AutogenerateStrategyULID]).
+//
+// The id field min, max and pattern are updated with the strategy settings.
+//
+// Set an empty strategy to restore the default random 15 characters ids.
+//
+// Note that the existing records ids are not changed, so this
+// is usually meant to be called before the collection creation.
+func (m *Collection) SetIdStrategy(strategy string) error {
+	field, _ := m.Fields.GetByName(FieldNameId).(*TextField)
+	if field == nil {
+		return errors.New("missing collection id field")
+	}
+
+	if strategy == "" {
+		field.AutogenerateStrategy = ""
+		field.AutogeneratePattern = `[a-z0-9]{15}`
+		field.Min = 15
+		field.Max = 15
+		field.Pattern = `^[a-z0-9]+$`
+		return nil
+	}
+
+	s, ok := AutogenerateStrategies[strategy]
+	if !ok {
+		return fmt.Errorf("unknown autogenerate strategy %q", strategy)
+	}
+
+	field.AutogenerateStrategy = strategy
+	field.AutogeneratePattern = ""
+	field.Min = s.Min
+	field.Max = s.Max
+	field.Pattern = s.Pattern
+
+	return nil
+}
+
 // delete hook
 // -------------------------------------------------------------------
 
@@ -675,6 +733,19 @@ func onCollectionDeleteExecute(e *CollectionEvent) error {
 			if err := txApp.DeleteTable(e.Collection.Name); err != nil {
 				return err
 			}
+
+			if err := txApp.DeleteTable(e.Collection.SearchTableName()); err != nil {
+				return err
+			}
+
+			if txApp.HasTable(RecordChangesTableName) {
+				_, err := txApp.DB().Delete(RecordChangesTableName, dbx.HashExp{
+					"collectionRef": e.Collection.Id,
+				}).Execute()
+				if err != nil {
+					return err
+				}
+			}
 		}
 
 		if !e.Collection.disableIntegrityChecks {
@@ -768,6 +839,7 @@ func onCollectionSave(e *CollectionEvent) error {
 
 	if e.Collection.IsAuth() {
 		e.Collection.unsetMissingOAuth2MappedFields()
+		e.Collection.unsetMissingSAMLMappedFields()
 	}
 
 	e.Collection.updateGeneratedIdIfExists(e.App)
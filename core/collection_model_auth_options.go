@@ -9,6 +9,7 @@ import (
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/pocketbase/pocketbase/tools/auth"
 	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/saml"
 	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/spf13/cast"
@@ -44,6 +45,18 @@ func (m *Collection) unsetMissingOAuth2MappedFields() {
 	}
 }
 
+func (m *Collection) unsetMissingSAMLMappedFields() {
+	if !m.IsAuth() {
+		return
+	}
+
+	for name := range m.SAML.MappedFields {
+		if m.Fields.GetByName(name) == nil {
+			delete(m.SAML.MappedFields, name)
+		}
+	}
+}
+
 func (m *Collection) setDefaultAuthOptions() {
 	m.collectionAuthOptions = collectionAuthOptions{
 		VerificationTemplate:       defaultVerificationTemplate,
@@ -68,6 +81,17 @@ func (m *Collection) setDefaultAuthOptions() {
 			Length:        8,
 			EmailTemplate: defaultOTPTemplate,
 		},
+		SMSOTP: SMSOTPConfig{
+			Enabled:         false,
+			Duration:        180, // 3min
+			Length:          6,
+			MaxRequests:     3,
+			MessageTemplate: defaultSMSOTPTemplate,
+		},
+		MagicLink: MagicLinkConfig{
+			Enabled:       false,
+			EmailTemplate: defaultMagicLinkTemplate,
+		},
 		AuthToken: TokenConfig{
 			Secret:   security.RandomString(50),
 			Duration: 604800, // 7 days
@@ -88,6 +112,10 @@ func (m *Collection) setDefaultAuthOptions() {
 			Secret:   security.RandomString(50),
 			Duration: 180, // 3min
 		},
+		MagicLinkToken: TokenConfig{
+			Secret:   security.RandomString(50),
+			Duration: 600, // 10min
+		},
 	}
 }
 
@@ -122,6 +150,9 @@ type collectionAuthOptions struct {
 	// and which OAuth2 providers are allowed.
 	OAuth2 OAuth2Config `form:"oauth2" json:"oauth2"`
 
+	// SAML defines options related to the SAML 2.0 Single Sign-On authentication.
+	SAML SAMLConfig `form:"saml" json:"saml"`
+
 	// PasswordAuth defines options related to the collection password authentication.
 	PasswordAuth PasswordAuthConfig `form:"passwordAuth" json:"passwordAuth"`
 
@@ -131,6 +162,19 @@ type collectionAuthOptions struct {
 	// OTP defines options related to the One-time password authentication (OTP).
 	OTP OTPConfig `form:"otp" json:"otp"`
 
+	// SMSOTP defines options related to the SMS One-time password authentication.
+	SMSOTP SMSOTPConfig `form:"smsOTP" json:"smsOTP"`
+
+	// MagicLink defines options related to the passwordless email magic link authentication.
+	MagicLink MagicLinkConfig `form:"magicLink" json:"magicLink"`
+
+	// GuestAuth defines options related to the anonymous (aka. guest) authentication.
+	GuestAuth GuestAuthConfig `form:"guestAuth" json:"guestAuth"`
+
+	// AuthSession defines options related to the auth token sessions
+	// (sliding renewal, max lifetime and forced rotation).
+	AuthSession AuthSessionConfig `form:"authSession" json:"authSession"`
+
 	// Various token configurations
 	// ---
 	AuthToken          TokenConfig `form:"authToken" json:"authToken"`
@@ -138,6 +182,7 @@ type collectionAuthOptions struct {
 	EmailChangeToken   TokenConfig `form:"emailChangeToken" json:"emailChangeToken"`
 	VerificationToken  TokenConfig `form:"verificationToken" json:"verificationToken"`
 	FileToken          TokenConfig `form:"fileToken" json:"fileToken"`
+	MagicLinkToken     TokenConfig `form:"magicLinkToken" json:"magicLinkToken"`
 
 	// Default email templates
 	// ---
@@ -162,13 +207,19 @@ func (o *collectionAuthOptions) validate(cv *collectionValidator) error {
 		validation.Field(&o.AuthAlert),
 		validation.Field(&o.PasswordAuth),
 		validation.Field(&o.OAuth2),
+		validation.Field(&o.SAML),
 		validation.Field(&o.OTP),
+		validation.Field(&o.SMSOTP),
+		validation.Field(&o.MagicLink),
+		validation.Field(&o.GuestAuth),
 		validation.Field(&o.MFA),
+		validation.Field(&o.AuthSession),
 		validation.Field(&o.AuthToken),
 		validation.Field(&o.PasswordResetToken),
 		validation.Field(&o.EmailChangeToken),
 		validation.Field(&o.VerificationToken),
 		validation.Field(&o.FileToken),
+		validation.Field(&o.MagicLinkToken),
 		validation.Field(&o.VerificationTemplate, validation.Required),
 		validation.Field(&o.ResetPasswordTemplate, validation.Required),
 		validation.Field(&o.ConfirmEmailChangeTemplate, validation.Required),
@@ -189,9 +240,18 @@ func (o *collectionAuthOptions) validate(cv *collectionValidator) error {
 		if o.OAuth2.Enabled {
 			authsEnabled++
 		}
+		if o.SAML.Enabled {
+			authsEnabled++
+		}
 		if o.OTP.Enabled {
 			authsEnabled++
 		}
+		if o.SMSOTP.Enabled {
+			authsEnabled++
+		}
+		if o.MagicLink.Enabled {
+			authsEnabled++
+		}
 		if authsEnabled < 2 {
 			return validation.Errors{
 				"mfa": validation.Errors{
@@ -231,6 +291,50 @@ func (o *collectionAuthOptions) validate(cv *collectionValidator) error {
 		}
 	}
 
+	// ensure that the SMS OTP phone field is an existing unique text field
+	if o.SMSOTP.Enabled {
+		err = validation.Validate(o.SMSOTP.PhoneField, validation.By(cv.checkSMSOTPPhoneField))
+		if err != nil {
+			return validation.Errors{
+				"smsOTP": validation.Errors{
+					"phoneField": err,
+				},
+			}
+		}
+	}
+
+	// ensure that the guest records could be flagged and created without an email
+	if o.GuestAuth.Enabled {
+		err = validation.Validate(o.GuestAuth.AnonymousField, validation.By(cv.checkGuestAnonymousField))
+		if err != nil {
+			return validation.Errors{
+				"guestAuth": validation.Errors{
+					"anonymousField": err,
+				},
+			}
+		}
+
+		if email, _ := cv.new.Fields.GetByName(FieldNameEmail).(*EmailField); email != nil && email.Required {
+			return validation.Errors{
+				"guestAuth": validation.Errors{
+					"enabled": validation.NewError("validation_guest_auth_required_email", "Guest auth requires the email field to be optional."),
+				},
+			}
+		}
+	}
+
+	// ensure that the SAML attributes are mapped only to existing non-system fields
+	if o.SAML.Enabled {
+		err = validation.Validate(o.SAML.MappedFields, validation.By(cv.checkSAMLMappedFields))
+		if err != nil {
+			return validation.Errors{
+				"saml": validation.Errors{
+					"mappedFields": err,
+				},
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -342,6 +446,177 @@ func (c OTPConfig) DurationTime() time.Duration {
 
 // -------------------------------------------------------------------
 
+type SMSOTPConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// PhoneField specifies the name of the auth collection text field
+	// that holds the record phone number (usually in E.164 format).
+	//
+	// The field must have a UNIQUE index.
+	PhoneField string `form:"phoneField" json:"phoneField"`
+
+	// Duration specifies how long the OTP to be valid (in seconds)
+	Duration int64 `form:"duration" json:"duration"`
+
+	// Length specifies the auto generated password length.
+	Length int `form:"length" json:"length"`
+
+	// MaxRequests specifies the max allowed SMS OTP requests
+	// for a single auth record within the OTP Duration.
+	MaxRequests int `form:"maxRequests" json:"maxRequests"`
+
+	// MessageTemplate is the SMS message that will be send to the auth record.
+	//
+	// It must contain the [core.EmailPlaceholderOTP] placeholder and in addition
+	// you can also make use of [core.EmailPlaceholderAppName].
+	MessageTemplate string `form:"messageTemplate" json:"messageTemplate"`
+}
+
+// Validate makes SMSOTPConfig validatable by implementing [validation.Validatable] interface.
+func (c SMSOTPConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.PhoneField, validation.When(c.Enabled, validation.Required)),
+		validation.Field(&c.Duration, validation.When(c.Enabled, validation.Required, validation.Min(10), validation.Max(86400))),
+		validation.Field(&c.Length, validation.When(c.Enabled, validation.Required, validation.Min(4), validation.Max(16))),
+		validation.Field(&c.MaxRequests, validation.When(c.Enabled, validation.Required, validation.Min(1))),
+		validation.Field(
+			&c.MessageTemplate,
+			validation.When(c.Enabled, validation.Required),
+			validation.Length(0, 500),
+			validation.When(c.MessageTemplate != "", validation.By(checkSMSOTPMessageTemplate)),
+		),
+	)
+}
+
+// DurationTime returns the current Duration as [time.Duration].
+func (c SMSOTPConfig) DurationTime() time.Duration {
+	return time.Duration(c.Duration) * time.Second
+}
+
+// ResolveMessage replaces the placeholder parameters in the
+// current message template and returns the ready-to-use message.
+func (c SMSOTPConfig) ResolveMessage(placeholders map[string]any) string {
+	message := c.MessageTemplate
+
+	for k, v := range placeholders {
+		message = strings.ReplaceAll(message, k, cast.ToString(v))
+	}
+
+	return message
+}
+
+func checkSMSOTPMessageTemplate(value any) error {
+	v, _ := value.(string)
+
+	if !strings.Contains(v, EmailPlaceholderOTP) {
+		return validation.NewError("validation_missing_otp_placeholder", "The message template must contain the {{.placeholder}} placeholder.").
+			SetParams(map[string]any{"placeholder": EmailPlaceholderOTP})
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type MagicLinkConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// EmailTemplate is the default magic link email template that will be send to the auth record.
+	//
+	// In addition to the system placeholders you can also make use of
+	// [core.EmailPlaceholderToken] that should be submitted by your client
+	// app to the auth-with-magic-link endpoint.
+	EmailTemplate EmailTemplate `form:"emailTemplate" json:"emailTemplate"`
+}
+
+// Validate makes MagicLinkConfig validatable by implementing [validation.Validatable] interface.
+func (c MagicLinkConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.EmailTemplate, validation.Skip.When(!c.Enabled)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type GuestAuthConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// AnonymousField specifies the name of the auth collection bool field
+	// that is used to flag the guest (aka. anonymous) auth records.
+	//
+	// The field is unset once the guest record is upgraded
+	// with email/password or OAuth2 credentials.
+	AnonymousField string `form:"anonymousField" json:"anonymousField"`
+}
+
+// Validate makes GuestAuthConfig validatable by implementing [validation.Validatable] interface.
+func (c GuestAuthConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.AnonymousField, validation.When(c.Enabled, validation.Required)),
+	)
+}
+
+type AuthSessionConfig struct {
+	// SlidingRenewal specifies whether to automatically renew the auth
+	// token on activity.
+	//
+	// When enabled, the requests protected with [apis.RequireAuth] that
+	// are authenticated with a refreshable auth token older than half
+	// of its duration (or older than RotationInterval if set) return a new
+	// auth token in the "X-Auth-Token" response header.
+	SlidingRenewal bool `form:"slidingRenewal" json:"slidingRenewal"`
+
+	// MaxLifetime specifies the max duration of an auth session since
+	// the initial authentication (in seconds).
+	//
+	// Once it is reached the auth token can no longer be renewed or refreshed
+	// and the user has to authenticate again.
+	//
+	// Set it to 0 to allow extending the session indefinitely.
+	MaxLifetime int64 `form:"maxLifetime" json:"maxLifetime"`
+
+	// RotationInterval specifies the max age of a refreshable auth token (in seconds)
+	// after which it must be rotated (aka. exchanged for a new one with auth-refresh
+	// or with the sliding renewal).
+	//
+	// Older tokens are rejected by the requests protected with [apis.RequireAuth]
+	// unless SlidingRenewal is enabled in which case the token is renewed.
+	//
+	// Set it to 0 to disable the forced rotation.
+	RotationInterval int64 `form:"rotationInterval" json:"rotationInterval"`
+
+	// Track specifies whether to register the auth sessions server-side
+	// (in the _sessions system collection).
+	//
+	// When enabled, the refreshable auth tokens are valid only as long as
+	// their related Session model exists, allowing the sessions to be
+	// listed and individually revoked.
+	//
+	// Note that the already issued untracked refreshable tokens are
+	// rejected once the option is enabled.
+	Track bool `form:"track" json:"track"`
+}
+
+// Validate makes AuthSessionConfig validatable by implementing [validation.Validatable] interface.
+func (c AuthSessionConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxLifetime, validation.Min(int64(0)), validation.Max(int64(94670856))), // ~3y max
+		validation.Field(&c.RotationInterval, validation.Min(int64(0)), validation.When(c.RotationInterval > 0, validation.Min(int64(10)))),
+	)
+}
+
+// MaxLifetimeTime returns the current MaxLifetime as [time.Duration].
+func (c AuthSessionConfig) MaxLifetimeTime() time.Duration {
+	return time.Duration(c.MaxLifetime) * time.Second
+}
+
+// RotationIntervalTime returns the current RotationInterval as [time.Duration].
+func (c AuthSessionConfig) RotationIntervalTime() time.Duration {
+	return time.Duration(c.RotationInterval) * time.Second
+}
+
+// -------------------------------------------------------------------
+
 type MFAConfig struct {
 	Enabled bool `form:"enabled" json:"enabled"`
 
@@ -394,6 +669,53 @@ func (c PasswordAuthConfig) Validate() error {
 
 // -------------------------------------------------------------------
 
+type SAMLConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// IdPMetadata is the raw XML metadata document of the SAML Identity Provider (IdP).
+	IdPMetadata string `form:"idpMetadata" json:"idpMetadata"`
+
+	// MappedFields specifies which SAML assertion attribute to assign
+	// to a collection field on auth record create
+	// (the map key is the field name and the value is the attribute name or friendly name).
+	//
+	// The attribute mapped to the "email" field is also used to
+	// lookup an existing auth record with the same email address.
+	MappedFields map[string]string `form:"mappedFields" json:"mappedFields"`
+}
+
+// Validate makes SAMLConfig validatable by implementing [validation.Validatable] interface.
+func (c SAMLConfig) Validate() error {
+	if !c.Enabled {
+		return nil // no need to validate
+	}
+
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.IdPMetadata, validation.Required, validation.By(checkSAMLIdPMetadata)),
+	)
+}
+
+// IdP parses and returns the configured IdP metadata.
+func (c SAMLConfig) IdP() (*saml.IdPMetadata, error) {
+	return saml.ParseIdPMetadata([]byte(c.IdPMetadata))
+}
+
+func checkSAMLIdPMetadata(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if _, err := saml.ParseIdPMetadata([]byte(v)); err != nil {
+		return validation.NewError("validation_invalid_idp_metadata", "Invalid IdP metadata: {{.error}}.").
+			SetParams(map[string]any{"error": err.Error()})
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
 type OAuth2KnownFields struct {
 	Id        string `form:"id" json:"id"`
 	Name      string `form:"name" json:"name"`
@@ -2,8 +2,14 @@ package core_test
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"strings"
 	"testing"
 	"time"
@@ -288,6 +294,162 @@ func TestCollectionAuthOptionsValidate(t *testing.T) {
 			},
 			expectedErrors: []string{"fileToken"},
 		},
+		{
+			name: "trigger magicLinkToken validations",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.MagicLinkToken.Secret = ""
+				return c, nil
+			},
+			expectedErrors: []string{"magicLinkToken"},
+		},
+
+		// smsOTP
+		{
+			name: "trigger smsOTP validations",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.SMSOTP.Enabled = true
+				return c, nil
+			},
+			expectedErrors: []string{"smsOTP"},
+		},
+		{
+			name: "smsOTP with non-text phone field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.Fields.Add(&core.NumberField{Name: "phone"})
+				c.AddIndex("phone_idx", true, "phone", "")
+				c.SMSOTP.Enabled = true
+				c.SMSOTP.PhoneField = "phone"
+				return c, nil
+			},
+			expectedErrors: []string{"smsOTP"},
+		},
+		{
+			name: "smsOTP with non-unique phone field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.Fields.Add(&core.TextField{Name: "phone"})
+				c.SMSOTP.Enabled = true
+				c.SMSOTP.PhoneField = "phone"
+				return c, nil
+			},
+			expectedErrors: []string{"smsOTP"},
+		},
+		{
+			name: "smsOTP with unique phone field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.Fields.Add(&core.TextField{Name: "phone"})
+				c.AddIndex("phone_idx", true, "phone", "")
+				c.SMSOTP.Enabled = true
+				c.SMSOTP.PhoneField = "phone"
+				return c, nil
+			},
+			expectedErrors: []string{},
+		},
+
+		// guestAuth
+		{
+			name: "trigger guestAuth validations",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.GuestAuth.Enabled = true
+				return c, nil
+			},
+			expectedErrors: []string{"guestAuth"},
+		},
+		{
+			name: "guestAuth with non-bool anonymous field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.Fields.Add(&core.TextField{Name: "anonymous"})
+				c.Fields.GetByName(core.FieldNameEmail).(*core.EmailField).Required = false
+				c.GuestAuth.Enabled = true
+				c.GuestAuth.AnonymousField = "anonymous"
+				return c, nil
+			},
+			expectedErrors: []string{"guestAuth"},
+		},
+		{
+			name: "guestAuth with system anonymous field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.Fields.GetByName(core.FieldNameEmail).(*core.EmailField).Required = false
+				c.GuestAuth.Enabled = true
+				c.GuestAuth.AnonymousField = core.FieldNameVerified
+				return c, nil
+			},
+			expectedErrors: []string{"guestAuth"},
+		},
+		{
+			name: "guestAuth with required email field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.Fields.Add(&core.BoolField{Name: "anonymous"})
+				c.GuestAuth.Enabled = true
+				c.GuestAuth.AnonymousField = "anonymous"
+				return c, nil
+			},
+			expectedErrors: []string{"guestAuth"},
+		},
+		{
+			name: "guestAuth with valid anonymous field and optional email",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.Fields.Add(&core.BoolField{Name: "anonymous"})
+				c.Fields.GetByName(core.FieldNameEmail).(*core.EmailField).Required = false
+				c.GuestAuth.Enabled = true
+				c.GuestAuth.AnonymousField = "anonymous"
+				return c, nil
+			},
+			expectedErrors: []string{},
+		},
+
+		// saml
+		{
+			name: "trigger saml validations",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.SAML.Enabled = true
+				return c, nil
+			},
+			expectedErrors: []string{"saml"},
+		},
+		{
+			name: "saml with system mapped field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.SAML.Enabled = true
+				c.SAML.IdPMetadata = testSAMLIdPMetadata(t)
+				c.SAML.MappedFields = map[string]string{"password": "test"}
+				return c, nil
+			},
+			expectedErrors: []string{"saml"},
+		},
+		{
+			name: "saml with missing mapped field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.SAML.Enabled = true
+				c.SAML.IdPMetadata = testSAMLIdPMetadata(t)
+				c.SAML.MappedFields = map[string]string{"missing": "test"}
+				return c, nil
+			},
+			expectedErrors: []string{"saml"},
+		},
+		{
+			name: "saml with valid mapped fields",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.SAML.Enabled = true
+				c.SAML.IdPMetadata = testSAMLIdPMetadata(t)
+				c.SAML.MappedFields = map[string]string{"email": "mail"}
+				return c, nil
+			},
+			expectedErrors: []string{},
+		},
 
 		// templates
 		{
@@ -613,6 +775,212 @@ func TestOTPConfigDurationTime(t *testing.T) {
 	}
 }
 
+func TestMagicLinkConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.MagicLinkConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.MagicLinkConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.MagicLinkConfig{Enabled: true},
+			[]string{"emailTemplate"},
+		},
+		{
+			"invalid template (triggering EmailTemplate validations)",
+			core.MagicLinkConfig{
+				Enabled:       true,
+				EmailTemplate: core.EmailTemplate{Body: "", Subject: "b"},
+			},
+			[]string{"emailTemplate"},
+		},
+		{
+			"valid data",
+			core.MagicLinkConfig{
+				Enabled:       true,
+				EmailTemplate: core.EmailTemplate{Body: "a", Subject: "b"},
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+func TestSMSOTPConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.SMSOTPConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.SMSOTPConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.SMSOTPConfig{Enabled: true},
+			[]string{"phoneField", "duration", "length", "maxRequests", "messageTemplate"},
+		},
+		{
+			"invalid values",
+			core.SMSOTPConfig{
+				Enabled:         true,
+				PhoneField:      "phone",
+				Duration:        9,
+				Length:          17,
+				MaxRequests:     -1,
+				MessageTemplate: "missing placeholder",
+			},
+			[]string{"duration", "length", "maxRequests", "messageTemplate"},
+		},
+		{
+			"invalid message template (disabled)",
+			core.SMSOTPConfig{MessageTemplate: "missing placeholder"},
+			[]string{"messageTemplate"},
+		},
+		{
+			"valid data",
+			core.SMSOTPConfig{
+				Enabled:         true,
+				PhoneField:      "phone",
+				Duration:        86400,
+				Length:          4,
+				MaxRequests:     1,
+				MessageTemplate: "code: {OTP}",
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestGuestAuthConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.GuestAuthConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.GuestAuthConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.GuestAuthConfig{Enabled: true},
+			[]string{"anonymousField"},
+		},
+		{
+			"valid data",
+			core.GuestAuthConfig{Enabled: true, AnonymousField: "anonymous"},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestSMSOTPConfigDurationTime(t *testing.T) {
+	config := core.SMSOTPConfig{Duration: 123}
+
+	if v := config.DurationTime(); v != 123*time.Second {
+		t.Fatalf("Expected DurationTime %v, got %v", 123*time.Second, v)
+	}
+}
+
+func TestSMSOTPConfigResolveMessage(t *testing.T) {
+	config := core.SMSOTPConfig{MessageTemplate: "{APP_NAME}: {OTP} ({OTP})"}
+
+	result := config.ResolveMessage(map[string]any{
+		core.EmailPlaceholderAppName: "test",
+		core.EmailPlaceholderOTP:     123456,
+	})
+
+	expected := "test: 123456 (123456)"
+	if result != expected {
+		t.Fatalf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestAuthSessionConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.AuthSessionConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value",
+			core.AuthSessionConfig{},
+			[]string{},
+		},
+		{
+			"negative values",
+			core.AuthSessionConfig{MaxLifetime: -1, RotationInterval: -1},
+			[]string{"maxLifetime", "rotationInterval"},
+		},
+		{
+			"invalid rotation interval (< 10)",
+			core.AuthSessionConfig{RotationInterval: 9},
+			[]string{"rotationInterval"},
+		},
+		{
+			"invalid max lifetime (> ~3y)",
+			core.AuthSessionConfig{MaxLifetime: 94670857},
+			[]string{"maxLifetime"},
+		},
+		{
+			"valid data",
+			core.AuthSessionConfig{SlidingRenewal: true, MaxLifetime: 94670856, RotationInterval: 10},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestAuthSessionConfigDurations(t *testing.T) {
+	config := core.AuthSessionConfig{MaxLifetime: 1234, RotationInterval: 56}
+
+	if v := config.MaxLifetimeTime(); v != 1234*time.Second {
+		t.Fatalf("Expected MaxLifetimeTime %v, got %v", 1234*time.Second, v)
+	}
+
+	if v := config.RotationIntervalTime(); v != 56*time.Second {
+		t.Fatalf("Expected RotationIntervalTime %v, got %v", 56*time.Second, v)
+	}
+}
+
 func TestMFAConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		name           string
@@ -712,6 +1080,98 @@ func TestPasswordAuthConfigValidate(t *testing.T) {
 	}
 }
 
+func TestSAMLConfigValidate(t *testing.T) {
+	metadata := testSAMLIdPMetadata(t)
+
+	scenarios := []struct {
+		name           string
+		config         core.SAMLConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.SAMLConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.SAMLConfig{Enabled: true},
+			[]string{"idpMetadata"},
+		},
+		{
+			"invalid metadata (disabled)",
+			core.SAMLConfig{IdPMetadata: "invalid"},
+			[]string{},
+		},
+		{
+			"invalid metadata (enabled)",
+			core.SAMLConfig{Enabled: true, IdPMetadata: "<invalid/>"},
+			[]string{"idpMetadata"},
+		},
+		{
+			"valid data",
+			core.SAMLConfig{Enabled: true, IdPMetadata: metadata},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestSAMLConfigIdP(t *testing.T) {
+	config := core.SAMLConfig{IdPMetadata: testSAMLIdPMetadata(t)}
+
+	idp, err := config.IdP()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if idp.EntityId != "https://idp.example.com" {
+		t.Fatalf("Expected entity id %q, got %q", "https://idp.example.com", idp.EntityId)
+	}
+
+	if idp.SSOURL != "https://idp.example.com/sso" {
+		t.Fatalf("Expected SSO url %q, got %q", "https://idp.example.com/sso", idp.SSOURL)
+	}
+
+	if len(idp.Certificates) != 1 {
+		t.Fatalf("Expected 1 certificate, got %d", len(idp.Certificates))
+	}
+}
+
+func testSAMLIdPMetadata(t testing.TB) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return `<EntityDescriptor xmlns="urn:oasis:names:tc:SAML:2.0:metadata" entityID="https://idp.example.com">` +
+		`<IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">` +
+		`<KeyDescriptor use="signing"><KeyInfo xmlns="http://www.w3.org/2000/09/xmldsig#"><X509Data>` +
+		`<X509Certificate>` + base64.StdEncoding.EncodeToString(cert) + `</X509Certificate>` +
+		`</X509Data></KeyInfo></KeyDescriptor>` +
+		`<SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/sso"/>` +
+		`</IDPSSODescriptor>` +
+		`</EntityDescriptor>`
+}
+
 func TestOAuth2ConfigGetProviderConfig(t *testing.T) {
 	scenarios := []struct {
 		name           string
@@ -9,6 +9,8 @@ const (
 	EmailPlaceholderOTPId   string = "{OTP_ID}"
 )
 
+var defaultSMSOTPTemplate = "Your " + EmailPlaceholderAppName + " verification code is " + EmailPlaceholderOTP + "."
+
 var defaultVerificationTemplate = EmailTemplate{
 	Subject: "Verify your " + EmailPlaceholderAppName + " email",
 	Body: `<p>Hello,</p>
@@ -62,6 +64,20 @@ var defaultOTPTemplate = EmailTemplate{
 </p>`,
 }
 
+var defaultMagicLinkTemplate = EmailTemplate{
+	Subject: "Sign in to " + EmailPlaceholderAppName,
+	Body: `<p>Hello,</p>
+<p>Click on the button below to sign in to your ` + EmailPlaceholderAppName + ` account.</p>
+<p>
+  <a class="btn" href="` + EmailPlaceholderAppURL + "/auth/magic-link/" + EmailPlaceholderToken + `" target="_blank" rel="noopener">Sign in</a>
+</p>
+<p><i>If you didn't ask to sign in, you can ignore this email.</i></p>
+<p>
+  Thanks,<br/>
+  ` + EmailPlaceholderAppName + ` team
+</p>`,
+}
+
 var defaultAuthAlertTemplate = EmailTemplate{
 	Subject: "Login from a new location",
 	Body: `<p>Hello,</p>
@@ -1,11 +1,70 @@
 package core
 
+import (
+	"slices"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
 var _ optionsValidator = (*collectionBaseOptions)(nil)
 
 // collectionBaseOptions defines the options for the "base" type collection.
+//
+// The same options are available also for the "auth" type collection.
 type collectionBaseOptions struct {
+	// SearchFields specifies the names of the text fields that are
+	// indexed in the collection FTS5 full-text search table.
+	//
+	// Leave empty to disable the full-text search for the collection.
+	SearchFields types.JSONArray[string] `form:"searchFields" json:"searchFields"`
+
+	// CaptureChanges enables the change data capture for the collection,
+	// aka. storing the before and after images of each record change
+	// in the _recordChanges table (see [FindRecordChanges]).
+	CaptureChanges bool `form:"captureChanges" json:"captureChanges"`
+}
+
+// searchableFieldTypes lists the field types that could be full-text indexed.
+var searchableFieldTypes = []string{
+	FieldTypeText,
+	FieldTypeEditor,
+	FieldTypeEmail,
+	FieldTypeURL,
 }
 
 func (o *collectionBaseOptions) validate(cv *collectionValidator) error {
+	return validation.ValidateStruct(o,
+		validation.Field(&o.SearchFields, validation.By(cv.checkSearchFields)),
+	)
+}
+
+func (validator *collectionValidator) checkSearchFields(value any) error {
+	names, _ := value.(types.JSONArray[string])
+
+	for i, name := range names {
+		if slices.Contains(names[:i], name) {
+			return validation.NewError(
+				"validation_duplicated_search_field",
+				"Duplicated search field {{.fieldName}}.",
+			).SetParams(map[string]any{"fieldName": name})
+		}
+
+		field := validator.new.Fields.GetByName(name)
+		if field == nil {
+			return validation.NewError(
+				"validation_missing_search_field",
+				"Missing or invalid search field {{.fieldName}}.",
+			).SetParams(map[string]any{"fieldName": name})
+		}
+
+		if !slices.Contains(searchableFieldTypes, field.Type()) {
+			return validation.NewError(
+				"validation_invalid_search_field_type",
+				"The search field {{.fieldName}} must be text, editor, email or url field.",
+			).SetParams(map[string]any{"fieldName": name})
+		}
+	}
+
 	return nil
 }
@@ -46,12 +46,17 @@ func (app *BaseApp) FindAllCollections(collectionTypes ...string) ([]*Collection
 }
 
 // ReloadCachedCollections fetches all collections and caches them into the app store.
+//
+// The API rules of the cached collections are wrapped with the
+// registered collection rule scopes (see [AddCollectionRuleScope]).
 func (app *BaseApp) ReloadCachedCollections() error {
 	collections, err := app.FindAllCollections()
 	if err != nil {
 		return err
 	}
 
+	applyCollectionRuleScopes(app, collections)
+
 	app.Store().Set(StoreKeyCachedCollections, collections)
 
 	return nil
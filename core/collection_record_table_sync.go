@@ -35,6 +35,9 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 
 			// add fields definition
 			for _, field := range fields {
+				if _, ok := field.(ComputedValuer); ok {
+					continue // no db column
+				}
 				cols[field.GetName()] = field.ColumnType(app)
 			}
 
@@ -43,7 +46,11 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 				return err
 			}
 
-			return createCollectionIndexes(txApp, newCollection)
+			if err := createCollectionIndexes(txApp, newCollection); err != nil {
+				return err
+			}
+
+			return syncRecordSearchTable(txApp, newCollection, nil)
 		}
 
 		// update
@@ -83,6 +90,10 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 				continue // exist
 			}
 
+			if _, ok := oldField.(ComputedValuer); ok {
+				continue // no db column
+			}
+
 			_, err := txApp.DB().DropColumn(newTableName, oldField.GetName()).Execute()
 			if err != nil {
 				return fmt.Errorf("failed to drop column %s - %w", oldField.GetName(), err)
@@ -92,6 +103,10 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 		// check for new or renamed columns
 		toRename := map[string]string{}
 		for _, field := range newFields {
+			if _, ok := field.(ComputedValuer); ok {
+				continue // no db column
+			}
+
 			oldField := oldFields.GetById(field.GetId())
 			// Note:
 			// We are using a temporary column name when adding or renaming columns
@@ -133,10 +148,12 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 		}
 
 		if needIndexesUpdate {
-			return createCollectionIndexes(txApp, newCollection)
+			if err := createCollectionIndexes(txApp, newCollection); err != nil {
+				return err
+			}
 		}
 
-		return nil
+		return syncRecordSearchTable(txApp, newCollection, oldCollection)
 	})
 	if txErr != nil {
 		return txErr
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"sync"
+)
+
+// StoreKeyCollectionRuleScopes is the app store key of the registered collection rule scopes.
+const StoreKeyCollectionRuleScopes = "pbAppCollectionRuleScopes"
+
+// CollectionRuleScope defines a function that returns an extra filter
+// expression for the records of the provided collection
+// (or empty string if the collection shouldn't be scoped).
+type CollectionRuleScope func(collection *Collection) string
+
+type collectionRuleScopes struct {
+	mu     sync.RWMutex
+	scopes []CollectionRuleScope
+}
+
+// AddCollectionRuleScope registers a new collection rule scope and
+// reloads the app cached collections.
+//
+// The expression returned by the scope is combined with AND to the List, View,
+// Update and Delete API rules of the cached collections, meaning that it is applied
+// everywhere the rules are evaluated (records list, count, view, expand, realtime, etc.).
+//
+// The superusers only (nil) rules are left unchanged and as with any other
+// API rule the scopes are not applied for the superusers.
+//
+// Note that only the in-memory rules of the cached collections are scoped
+// (the rules stored in the database are not changed).
+//
+// Example:
+//
+//	core.AddCollectionRuleScope(app, func(c *core.Collection) string {
+//		if c.Name != "posts" {
+//			return ""
+//		}
+//		return "archived = false"
+//	})
+func AddCollectionRuleScope(app App, scope CollectionRuleScope) error {
+	registry := app.Store().GetOrSet(StoreKeyCollectionRuleScopes, func() any {
+		return &collectionRuleScopes{}
+	}).(*collectionRuleScopes)
+
+	registry.mu.Lock()
+	registry.scopes = append(registry.scopes, scope)
+	registry.mu.Unlock()
+
+	if !app.IsBootstrapped() {
+		return nil // the scopes will be applied on the initial cache load
+	}
+
+	return app.ReloadCachedCollections()
+}
+
+// applyCollectionRuleScopes wraps the API rules of the provided
+// collections with the app registered collection rule scopes.
+func applyCollectionRuleScopes(app App, collections []*Collection) {
+	registry, _ := app.Store().Get(StoreKeyCollectionRuleScopes).(*collectionRuleScopes)
+	if registry == nil {
+		return
+	}
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	for _, c := range collections {
+		for _, scope := range registry.scopes {
+			expr := scope(c)
+			if expr == "" {
+				continue
+			}
+
+			c.ListRule = scopeRule(c.ListRule, expr)
+			c.ViewRule = scopeRule(c.ViewRule, expr)
+			c.UpdateRule = scopeRule(c.UpdateRule, expr)
+			c.DeleteRule = scopeRule(c.DeleteRule, expr)
+		}
+	}
+}
+
+func scopeRule(rule *string, expr string) *string {
+	if rule == nil {
+		return nil // superusers only
+	}
+
+	if *rule == "" {
+		return &expr
+	}
+
+	scoped := "(" + *rule + ") && (" + expr + ")"
+
+	return &scoped
+}
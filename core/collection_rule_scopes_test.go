@@ -0,0 +1,75 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+func TestAddCollectionRuleScope(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	err := core.AddCollectionRuleScope(app, func(c *core.Collection) string {
+		if c.Name != "demo1" && c.Name != "demo3" {
+			return ""
+		}
+		return "title != 'test'"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindCachedCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo2, err := app.FindCachedCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo3, err := app.FindCachedCollectionByNameOrId("demo3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo3Rule := `@request.auth.id != "" && @request.auth.collectionName != "users"`
+
+	scenarios := []struct {
+		name     string
+		rule     *string
+		expected *string
+	}{
+		{"superusers only rule", demo1.ListRule, nil},
+		{"non-scoped collection", demo2.ListRule, types.Pointer("")},
+		{"scoped list rule", demo3.ListRule, types.Pointer("(" + demo3Rule + ") && (title != 'test')")},
+		{"scoped view rule", demo3.ViewRule, types.Pointer("(" + demo3Rule + ") && (title != 'test')")},
+		{"scoped update rule", demo3.UpdateRule, types.Pointer("(" + demo3Rule + ") && (title != 'test')")},
+		{"scoped delete rule", demo3.DeleteRule, types.Pointer("(" + demo3Rule + ") && (title != 'test')")},
+		{"non-scoped create rule", demo3.CreateRule, types.Pointer(demo3Rule)},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if (s.rule == nil) != (s.expected == nil) || (s.rule != nil && *s.rule != *s.expected) {
+				t.Fatalf("Expected rule %v, got %v", cast.ToString(s.expected), cast.ToString(s.rule))
+			}
+		})
+	}
+
+	// the stored rules must remain unchanged
+	stored, err := app.FindCollectionByNameOrId("demo3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ListRule == nil || *stored.ListRule != demo3Rule {
+		t.Fatalf("Expected the stored ListRule to be unchanged, got %v", cast.ToString(stored.ListRule))
+	}
+}
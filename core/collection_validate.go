@@ -465,6 +465,55 @@ func (cv *collectionValidator) checkFieldsForUniqueIndex(value any) error {
 	return nil
 }
 
+func (cv *collectionValidator) checkSMSOTPPhoneField(value any) error {
+	name, _ := value.(string)
+
+	if _, ok := cv.new.Fields.GetByName(name).(*TextField); !ok {
+		return validation.NewError("validation_invalid_phone_field", "The phone field must be an existing text field.")
+	}
+
+	return cv.checkFieldsForUniqueIndex([]string{name})
+}
+
+func (cv *collectionValidator) checkGuestAnonymousField(value any) error {
+	name, _ := value.(string)
+
+	field, ok := cv.new.Fields.GetByName(name).(*BoolField)
+	if !ok || field.System {
+		return validation.NewError("validation_invalid_anonymous_field", "The anonymous field must be an existing non-system bool field.")
+	}
+
+	return nil
+}
+
+func (cv *collectionValidator) checkSAMLMappedFields(value any) error {
+	mappedFields, _ := value.(map[string]string)
+
+	for name, attribute := range mappedFields {
+		if attribute == "" {
+			return validation.Errors{
+				name: validation.NewError("validation_missing_attribute", "Missing SAML attribute name."),
+			}
+		}
+
+		if name == FieldNameId || name == FieldNamePassword || name == FieldNameTokenKey {
+			return validation.Errors{
+				name: validation.NewError("validation_system_field", "The system field {{.fieldName}} cannot be mapped.").
+					SetParams(map[string]any{"fieldName": name}),
+			}
+		}
+
+		if cv.new.Fields.GetByName(name) == nil {
+			return validation.Errors{
+				name: validation.NewError("validation_missing_field", "Invalid or missing field {{.fieldName}}").
+					SetParams(map[string]any{"fieldName": name}),
+			}
+		}
+	}
+
+	return nil
+}
+
 // note: value could be either *string or string
 func (validator *collectionValidator) checkRule(value any) error {
 	var vStr string
@@ -681,10 +730,13 @@ func (cv *collectionValidator) checkIndexes(value any) error {
 func (validator *collectionValidator) validateOptions() error {
 	switch validator.new.Type {
 	case CollectionTypeAuth:
-		return validator.new.collectionAuthOptions.validate(validator)
+		return validators.JoinValidationErrors(
+			validator.new.collectionBaseOptions.validate(validator),
+			validator.new.collectionAuthOptions.validate(validator),
+		)
 	case CollectionTypeView:
 		return validator.new.collectionViewOptions.validate(validator)
 	}
 
-	return nil
+	return validator.new.collectionBaseOptions.validate(validator)
 }
@@ -889,6 +889,52 @@ func TestCollectionValidate(t *testing.T) {
 			},
 			expectedErrors: []string{},
 		},
+		{
+			name: "with invalid search fields",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewBaseCollection("new_base")
+				c.Fields.Add(
+					&core.TextField{Name: "title"},
+					&core.NumberField{Name: "total"},
+				)
+				c.SearchFields = []string{"title", "total"}
+				return c, nil
+			},
+			expectedErrors: []string{"searchFields"},
+		},
+		{
+			name: "with missing search field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.SearchFields = []string{"email", "missing"}
+				return c, nil
+			},
+			expectedErrors: []string{"searchFields"},
+		},
+		{
+			name: "with duplicated search fields",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewBaseCollection("new_base")
+				c.Fields.Add(&core.TextField{Name: "title"})
+				c.SearchFields = []string{"title", "title"}
+				return c, nil
+			},
+			expectedErrors: []string{"searchFields"},
+		},
+		{
+			name: "with valid search fields",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewBaseCollection("new_base")
+				c.Fields.Add(
+					&core.TextField{Name: "title"},
+					&core.EditorField{Name: "content"},
+					&core.URLField{Name: "link"},
+				)
+				c.SearchFields = []string{"title", "content", "link"}
+				return c, nil
+			},
+			expectedErrors: []string{},
+		},
 	}
 
 	for _, s := range scenarios {
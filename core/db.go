@@ -87,7 +87,7 @@ func (app *BaseApp) modelQuery(db dbx.Builder, m Model) *dbx.SelectQuery {
 
 // Delete deletes the specified model from the regular app database.
 func (app *BaseApp) Delete(model Model) error {
-	return app.DeleteWithContext(context.Background(), model)
+	return app.DeleteWithContext(app.Context(), model)
 }
 
 // Delete deletes the specified model from the regular app database
@@ -98,7 +98,7 @@ func (app *BaseApp) DeleteWithContext(ctx context.Context, model Model) error {
 
 // AuxDelete deletes the specified model from the auxiliary database.
 func (app *BaseApp) AuxDelete(model Model) error {
-	return app.AuxDeleteWithContext(context.Background(), model)
+	return app.AuxDeleteWithContext(app.Context(), model)
 }
 
 // AuxDeleteWithContext deletes the specified model from the auxiliary database
@@ -130,6 +130,12 @@ func (app *BaseApp) delete(ctx context.Context, model Model, isForAuxDB bool) er
 				db = e.App.NonconcurrentDB()
 			}
 
+			release, err := app.acquireWriteQueue(e.Context, db, isForAuxDB)
+			if err != nil {
+				return err
+			}
+			defer release()
+
 			return baseLockRetry(func(attempt int) error {
 				_, err := db.Delete(e.Model.TableName(), dbx.HashExp{
 					idColumn: pk,
@@ -177,7 +183,7 @@ func (app *BaseApp) delete(ctx context.Context, model Model, isForAuxDB bool) er
 //
 // If you don't want to run validations, use [App.SaveNoValidate()].
 func (app *BaseApp) Save(model Model) error {
-	return app.SaveWithContext(context.Background(), model)
+	return app.SaveWithContext(app.Context(), model)
 }
 
 // SaveWithContext is the same as [App.Save()] but allows specifying a context to limit the db execution.
@@ -191,7 +197,7 @@ func (app *BaseApp) SaveWithContext(ctx context.Context, model Model) error {
 //
 // If you want to also run validations before persisting, use [App.Save()].
 func (app *BaseApp) SaveNoValidate(model Model) error {
-	return app.SaveNoValidateWithContext(context.Background(), model)
+	return app.SaveNoValidateWithContext(app.Context(), model)
 }
 
 // SaveNoValidateWithContext is the same as [App.SaveNoValidate()]
@@ -206,7 +212,7 @@ func (app *BaseApp) SaveNoValidateWithContext(ctx context.Context, model Model)
 //
 // If you don't want to run validations, use [App.AuxSaveNoValidate()].
 func (app *BaseApp) AuxSave(model Model) error {
-	return app.AuxSaveWithContext(context.Background(), model)
+	return app.AuxSaveWithContext(app.Context(), model)
 }
 
 // AuxSaveWithContext is the same as [App.AuxSave()] but allows specifying a context to limit the db execution.
@@ -220,7 +226,7 @@ func (app *BaseApp) AuxSaveWithContext(ctx context.Context, model Model) error {
 //
 // If you want to also run validations before persisting, use [App.AuxSave()].
 func (app *BaseApp) AuxSaveNoValidate(model Model) error {
-	return app.AuxSaveNoValidateWithContext(context.Background(), model)
+	return app.AuxSaveNoValidateWithContext(app.Context(), model)
 }
 
 // AuxSaveNoValidateWithContext is the same as [App.AuxSaveNoValidate()]
@@ -233,7 +239,7 @@ func (app *BaseApp) AuxSaveNoValidateWithContext(ctx context.Context, model Mode
 
 // Validate triggers the OnModelValidate hook for the specified model.
 func (app *BaseApp) Validate(model Model) error {
-	return app.ValidateWithContext(context.Background(), model)
+	return app.ValidateWithContext(app.Context(), model)
 }
 
 // ValidateWithContext is the same as Validate but allows specifying the ModelEvent context.
@@ -296,6 +302,12 @@ func (app *BaseApp) create(ctx context.Context, model Model, withValidations boo
 				db = e.App.NonconcurrentDB()
 			}
 
+			release, err := app.acquireWriteQueue(e.Context, db, isForAuxDB)
+			if err != nil {
+				return err
+			}
+			defer release()
+
 			dbErr := baseLockRetry(func(attempt int) error {
 				if m, ok := e.Model.(DBExporter); ok {
 					data, err := m.DBExport(e.App)
@@ -391,6 +403,12 @@ func (app *BaseApp) update(ctx context.Context, model Model, withValidations boo
 				db = e.App.NonconcurrentDB()
 			}
 
+			release, err := app.acquireWriteQueue(e.Context, db, isForAuxDB)
+			if err != nil {
+				return err
+			}
+			defer release()
+
 			return baseLockRetry(func(attempt int) error {
 				if m, ok := e.Model.(DBExporter); ok {
 					data, err := m.DBExport(e.App)
@@ -3,10 +3,24 @@
 package core
 
 import (
+	"database/sql/driver"
+
 	"github.com/pocketbase/dbx"
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
 )
 
+func init() {
+	// register the "regexp" function used by the regexMatch() rule function
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return regexpSQLFunc(args[0], args[1])
+	})
+
+	// register the "vector_similarity" function used by the similarTo() rule function
+	sqlite.MustRegisterDeterministicScalarFunction("vector_similarity", 2, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return vectorSimilaritySQLFunc(args[0], args[1])
+	})
+}
+
 func DefaultDBConnect(dbPath string) (*dbx.DB, error) {
 	// Note: the busy_timeout pragma must be first because
 	// the connection needs to be set to block on busy before WAL mode
@@ -19,12 +19,19 @@ const defaultMaxLockRetries = 12
 
 func execLockRetry(timeout time.Duration, maxRetries int) dbx.ExecHookFunc {
 	return func(q *dbx.Query, op func() error) error {
-		if q.Context() == nil {
-			cancelCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		// apply the default timeout if the query context doesn't have a deadline
+		// (e.g. when the query was created from an app instance bound to a request context)
+		prevCtx := q.Context()
+		baseCtx := prevCtx
+		if baseCtx == nil {
+			baseCtx = context.Background()
+		}
+		if _, ok := baseCtx.Deadline(); !ok {
+			cancelCtx, cancel := context.WithTimeout(baseCtx, timeout)
 			defer func() {
 				cancel()
 				//nolint:staticcheck
-				q.WithContext(nil) // reset
+				q.WithContext(prevCtx) // reset
 			}()
 			q.WithContext(cancelCtx)
 		}
@@ -28,12 +28,23 @@ func (app *BaseApp) runInTransaction(db dbx.Builder, fn func(txApp App) error, i
 		// run as part of the already existing transaction
 		return fn(app)
 	case *dbx.DB:
+		// wait for the write queue turn and hold it for the entire transaction
+		release, err := app.acquireWriteQueue(app.Context(), txOrDB, isForAuxDB)
+		if err != nil {
+			return err
+		}
+		defer release()
+
 		var txApp *BaseApp
 		txErr := txOrDB.Transactional(func(tx *dbx.Tx) error {
 			txApp = app.createTxApp(tx, isForAuxDB)
 			return fn(txApp)
 		})
 
+		// release before the after funcs since they are
+		// allowed to execute writes with the parent app
+		release()
+
 		// execute all after event calls on transaction complete
 		if txApp != nil && txApp.txInfo != nil {
 			afterFuncErr := txApp.txInfo.runAfterFuncs(txErr)
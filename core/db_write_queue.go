@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/pocketbase/dbx"
+)
+
+// ErrWriteQueueFull is returned when a db write couldn't be scheduled
+// because the max allowed number of pending writers has been reached.
+var ErrWriteQueueFull = errors.New("the db write queue is full, please try again later")
+
+// writeQueue is a FIFO scheduler that serializes the writes of a single database.
+//
+// The concurrent writers are processed one at a time in the order of
+// their arrival instead of randomly competing for the single
+// nonconcurrent db connection (and eventually the SQLite write lock).
+type writeQueue struct {
+	mu      sync.Mutex
+	waiters []chan struct{}
+	busy    bool
+	maxSize int
+}
+
+// newWriteQueue creates a new writeQueue that allows up to maxSize
+// pending writers (0 or negative means no limit).
+func newWriteQueue(maxSize int) *writeQueue {
+	return &writeQueue{maxSize: maxSize}
+}
+
+// acquire blocks until it is the caller's turn to write and returns
+// a function that must be called to release the queue for the next writer.
+//
+// Returns ErrWriteQueueFull if the queue is full or the ctx error
+// if the ctx is canceled or its deadline is reached while waiting.
+func (q *writeQueue) acquire(ctx context.Context) (func(), error) {
+	q.mu.Lock()
+
+	if !q.busy && len(q.waiters) == 0 {
+		q.busy = true
+		q.mu.Unlock()
+		return sync.OnceFunc(q.release), nil
+	}
+
+	if q.maxSize > 0 && len(q.waiters) >= q.maxSize {
+		q.mu.Unlock()
+		return nil, ErrWriteQueueFull
+	}
+
+	ready := make(chan struct{})
+	q.waiters = append(q.waiters, ready)
+
+	q.mu.Unlock()
+
+	select {
+	case <-ready:
+		return sync.OnceFunc(q.release), nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		select {
+		case <-ready:
+			// the turn was granted while waiting for the lock
+			// -> pass it directly to the next writer
+			q.next()
+		default:
+			for i, w := range q.waiters {
+				if w == ready {
+					q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+					break
+				}
+			}
+		}
+
+		return nil, fmt.Errorf("failed to wait for the db write queue: %w", ctx.Err())
+	}
+}
+
+// release passes the queue turn to the next waiting writer (if any).
+func (q *writeQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.next()
+}
+
+// next grants the queue turn to the first waiting writer.
+//
+// Must be called with the queue mutex locked.
+func (q *writeQueue) next() {
+	if len(q.waiters) == 0 {
+		q.busy = false
+		return
+	}
+
+	ready := q.waiters[0]
+	q.waiters[0] = nil
+	q.waiters = q.waiters[1:]
+
+	// note: the busy state remains since the turn is handed off directly
+	close(ready)
+}
+
+// pending returns the number of the currently waiting writers.
+func (q *writeQueue) pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.waiters)
+}
+
+// -------------------------------------------------------------------
+
+// acquireWriteQueue waits for the write queue turn of the provided
+// nonconcurrent db and returns a function to release it.
+//
+// Writes executed as part of a transaction are not queued because
+// the transaction itself already holds the write queue turn.
+//
+// If ctx doesn't have a deadline, the app QueryTimeout is applied.
+func (app *BaseApp) acquireWriteQueue(ctx context.Context, db dbx.Builder, isForAuxDB bool) (func(), error) {
+	queue := app.dataWriteQueue
+	if isForAuxDB {
+		queue = app.auxWriteQueue
+	}
+
+	if _, ok := db.(*dbx.DB); !ok || queue == nil {
+		return func() {}, nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, app.config.QueryTimeout)
+		defer cancel()
+	}
+
+	return queue.acquire(ctx)
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// waitPending waits until the queue has the specified number of waiting writers.
+func waitPending(t *testing.T, q *writeQueue, total int) {
+	for i := 0; i < 100; i++ {
+		if q.pending() == total {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("Expected %d pending writers, got %d", total, q.pending())
+}
+
+func TestWriteQueueFIFO(t *testing.T) {
+	t.Parallel()
+
+	q := newWriteQueue(0)
+
+	release, err := q.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	order := []int{}
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			r, err := q.acquire(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer r()
+
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}(i)
+
+		// ensure that the writers are queued in the loop order
+		waitPending(t, q, i+1)
+	}
+
+	release()
+
+	// multiple release calls shouldn't have any effect
+	release()
+
+	wg.Wait()
+
+	expected := []int{0, 1, 2, 3, 4}
+	if !slices.Equal(order, expected) {
+		t.Fatalf("Expected order %v, got %v", expected, order)
+	}
+
+	if q.busy || q.pending() != 0 {
+		t.Fatalf("Expected the queue to be free, got busy %v with %d pending", q.busy, q.pending())
+	}
+}
+
+func TestWriteQueueMaxSize(t *testing.T) {
+	t.Parallel()
+
+	q := newWriteQueue(1)
+
+	release, err := q.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		r, err := q.acquire(context.Background())
+		if err == nil {
+			r()
+		}
+		done <- err
+	}()
+
+	waitPending(t, q, 1)
+
+	_, err = q.acquire(context.Background())
+	if !errors.Is(err, ErrWriteQueueFull) {
+		t.Fatalf("Expected ErrWriteQueueFull, got %v", err)
+	}
+
+	release()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Expected the pending writer to acquire the queue, got %v", err)
+	}
+}
+
+func TestWriteQueueDeadline(t *testing.T) {
+	t.Parallel()
+
+	q := newWriteQueue(0)
+
+	release, err := q.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = q.acquire(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded error, got %v", err)
+	}
+
+	// the timed out writer should be removed from the queue
+	if total := q.pending(); total != 0 {
+		t.Fatalf("Expected no pending writers, got %d", total)
+	}
+
+	release()
+
+	// the queue should be available again
+	release2, err := q.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the queue to be available, got %v", err)
+	}
+	release2()
+}
+
+func TestBaseAppWriteQueueTransaction(t *testing.T) {
+	t.Parallel()
+
+	app := NewBaseApp(BaseAppConfig{
+		DataDir:      t.TempDir(),
+		QueryTimeout: 100 * time.Millisecond,
+	})
+	defer app.ResetBootstrapState()
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	newParam := func(id string) *Param {
+		p := &Param{Value: types.JSONRaw("1")}
+		p.Id = id
+		return p
+	}
+
+	err := app.RunInTransaction(func(txApp App) error {
+		if !app.dataWriteQueue.busy {
+			t.Fatal("Expected the data write queue to be acquired during the transaction")
+		}
+
+		// writes with the tx app shouldn't be queued
+		if err := txApp.Save(newParam("test1")); err != nil {
+			return err
+		}
+
+		// writes with the outer app should wait for the transaction to complete
+		err := app.Save(newParam("test2"))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected DeadlineExceeded error, got %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if app.dataWriteQueue.busy {
+		t.Fatal("Expected the data write queue to be released after the transaction")
+	}
+}
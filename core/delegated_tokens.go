@@ -0,0 +1,131 @@
+package core
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+// DefaultDelegatedTokenDuration is the default delegated token duration.
+const DefaultDelegatedTokenDuration = 15 * time.Minute
+
+// ErrInvalidDelegatedToken is returned when the delegated token is invalid,
+// expired, its auth record is missing or it is issued for another audience.
+var ErrInvalidDelegatedToken = errors.New("invalid or expired delegated token")
+
+// DelegatedToken is a verified restricted auth record token
+// (see [Record.NewDelegatedToken] and [App.FindDelegatedToken]).
+type DelegatedToken struct {
+	// Record is the auth record on whose behalf the token was issued.
+	Record *Record
+
+	// Audience is the identifier of the third-party service
+	// for which the token was issued (e.g. "media-server").
+	Audience string
+
+	// Scopes is the list of the granted token permissions
+	// (their meaning is defined by the audience service).
+	Scopes []string
+
+	// Expires is the delegated token expiration time.
+	Expires time.Time
+}
+
+// HasScope reports whether all of the specified scopes were granted to the token.
+func (t *DelegatedToken) HasScope(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(t.Scopes, scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewDelegatedToken generates and returns a new restricted, non-refreshable
+// auth record token that is meant to be passed to and verified by the
+// specified audience third-party service (e.g. a media server validating
+// a file access on behalf of the record).
+//
+// Delegated tokens are not accepted as regular auth tokens and,
+// similar to the other record tokens, they are invalidated
+// on tokenKey change (e.g. on password change).
+//
+// Zero or negative duration will fallback to [DefaultDelegatedTokenDuration].
+// The duration is capped to the auth collection token duration.
+func (m *Record) NewDelegatedToken(audience string, scopes []string, duration time.Duration) (string, error) {
+	if !m.Collection().IsAuth() {
+		return "", ErrNotAuthRecord
+	}
+
+	if audience == "" {
+		return "", errors.New("missing delegated token audience")
+	}
+
+	key := (m.TokenKey() + m.Collection().AuthToken.Secret)
+	if key == "" {
+		return "", ErrMissingSigningKey
+	}
+
+	if duration <= 0 {
+		duration = DefaultDelegatedTokenDuration
+	}
+
+	if maxDuration := m.Collection().AuthToken.DurationTime(); maxDuration > 0 {
+		duration = min(duration, maxDuration)
+	}
+
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return m.signer().Sign(
+		jwt.MapClaims{
+			TokenClaimType:         TokenTypeDelegated,
+			TokenClaimId:           m.Id,
+			TokenClaimCollectionId: m.Collection().Id,
+			TokenClaimAudience:     audience,
+			TokenClaimScopes:       scopes,
+			TokenClaimIssuedAt:     time.Now().Unix(),
+		},
+		key,
+		duration,
+	)
+}
+
+// FindDelegatedToken verifies the provided delegated auth record
+// token issued for the specified audience and returns its loaded data.
+//
+// Returns [ErrInvalidDelegatedToken] if the token is invalid, expired,
+// its auth record is missing or it is issued for another audience.
+func (app *BaseApp) FindDelegatedToken(token string, audience string) (*DelegatedToken, error) {
+	if audience == "" {
+		return nil, ErrInvalidDelegatedToken
+	}
+
+	record, err := app.FindAuthRecordByToken(token, TokenTypeDelegated)
+	if err != nil {
+		return nil, ErrInvalidDelegatedToken
+	}
+
+	// the token signature was already verified
+	claims, err := security.ParseUnverifiedJWT(token)
+	if err != nil {
+		return nil, ErrInvalidDelegatedToken
+	}
+
+	if cast.ToString(claims[TokenClaimAudience]) != audience {
+		return nil, ErrInvalidDelegatedToken
+	}
+
+	return &DelegatedToken{
+		Record:   record,
+		Audience: audience,
+		Scopes:   cast.ToStringSlice(claims[TokenClaimScopes]),
+		Expires:  time.Unix(cast.ToInt64(claims["exp"]), 0),
+	}, nil
+}
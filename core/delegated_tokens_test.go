@@ -0,0 +1,180 @@
+package core_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestNewDelegatedToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	nonAuth, err := app.FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := nonAuth.NewDelegatedToken("test", nil, 0); !errors.Is(err, core.ErrNotAuthRecord) {
+		t.Fatalf("Expected ErrNotAuthRecord, got %v", err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := user.NewDelegatedToken("", nil, 0); err == nil {
+		t.Fatal("Expected error for missing audience")
+	}
+
+	scenarios := []struct {
+		name             string
+		scopes           []string
+		duration         time.Duration
+		expectedScopes   []string
+		expectedDuration time.Duration
+	}{
+		{
+			"nil scopes and default duration",
+			nil,
+			0,
+			[]string{},
+			core.DefaultDelegatedTokenDuration,
+		},
+		{
+			"custom scopes and duration",
+			[]string{"files:read", "files:write"},
+			time.Hour,
+			[]string{"files:read", "files:write"},
+			time.Hour,
+		},
+		{
+			"duration larger than the auth token duration",
+			nil,
+			100 * 365 * 24 * time.Hour,
+			[]string{},
+			user.Collection().AuthToken.DurationTime(),
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			token, err := user.NewDelegatedToken("media", s.scopes, s.duration)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, _ := security.ParseUnverifiedJWT(token)
+
+			if v := claims[core.TokenClaimType]; v != core.TokenTypeDelegated {
+				t.Fatalf("Expected type %q, got %v", core.TokenTypeDelegated, v)
+			}
+
+			if v := claims[core.TokenClaimAudience]; v != "media" {
+				t.Fatalf("Expected audience %q, got %v", "media", v)
+			}
+
+			delegated, err := app.FindDelegatedToken(token, "media")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if delegated.Record.Id != user.Id {
+				t.Fatalf("Expected record %q, got %q", user.Id, delegated.Record.Id)
+			}
+
+			if !slices.Equal(delegated.Scopes, s.expectedScopes) {
+				t.Fatalf("Expected scopes %v, got %v", s.expectedScopes, delegated.Scopes)
+			}
+
+			expires := time.Now().Add(s.expectedDuration)
+			if diff := expires.Sub(delegated.Expires); diff < 0 || diff > 5*time.Second {
+				t.Fatalf("Expected expires ~%v, got %v", expires, delegated.Expires)
+			}
+		})
+	}
+}
+
+func TestFindDelegatedToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := user.NewDelegatedToken("media", []string{"files:read"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authToken, err := user.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		token       string
+		audience    string
+		expectError bool
+	}{
+		{"empty token", "", "media", true},
+		{"empty audience", token, "", true},
+		{"different audience", token, "other", true},
+		{"regular auth token", authToken, "media", true},
+		{"valid delegated token", token, "media", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			delegated, err := app.FindDelegatedToken(s.token, s.audience)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				if !errors.Is(err, core.ErrInvalidDelegatedToken) {
+					t.Fatalf("Expected ErrInvalidDelegatedToken, got %v", err)
+				}
+				return
+			}
+
+			if !delegated.HasScope("files:read") {
+				t.Fatal("Expected files:read scope")
+			}
+
+			if delegated.HasScope("files:read", "files:write") {
+				t.Fatal("Expected files:write scope to be missing")
+			}
+		})
+	}
+
+	// the delegated token must not be accepted as regular auth token
+	if _, err := app.FindAuthRecordByToken(token, core.TokenTypeAuth); err == nil {
+		t.Fatal("Expected the delegated token to be rejected as auth token")
+	}
+
+	// tokenKey change must invalidate the token
+	user.RefreshTokenKey()
+	if err := app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindDelegatedToken(token, "media"); err == nil {
+		t.Fatal("Expected the delegated token to be invalidated after tokenKey change")
+	}
+}
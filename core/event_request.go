@@ -13,6 +13,7 @@ import (
 // Common request store keys used by the middlewares and api handlers.
 const (
 	RequestEventKeyInfoContext = "infoContext"
+	RequestEventKeyAPIKey      = "apiKey" // the value must be *APIKey
 )
 
 // RequestEvent defines the PocketBase router handler event.
@@ -155,6 +156,7 @@ const (
 	RequestInfoContextRealtime      = "realtime"
 	RequestInfoContextProtectedFile = "protectedFile"
 	RequestInfoContextOAuth2        = "oauth2"
+	RequestInfoContextSAML          = "saml"
 	RequestInfoContextBatch         = "batch"
 )
 
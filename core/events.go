@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/pocketbase/pocketbase/tools/auth"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/mailer"
 	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/saml"
 	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
 	"golang.org/x/crypto/acme/autocert"
@@ -147,6 +149,33 @@ type MailerRecordEvent struct {
 	Meta map[string]any
 }
 
+// -------------------------------------------------------------------
+// SMS events data
+// -------------------------------------------------------------------
+
+type SMSSendEvent struct {
+	hook.Event
+	App     App
+	Context context.Context
+
+	Sender  SMSSender
+	Message *SMSMessage
+}
+
+// -------------------------------------------------------------------
+// Notification events data
+// -------------------------------------------------------------------
+
+type RecordNotifyEvent struct {
+	hook.Event
+	App     App
+	Context context.Context
+	baseRecordEventData
+
+	Notifier     Notifier
+	Notification *Notification
+}
+
 // -------------------------------------------------------------------
 // Model events data
 // -------------------------------------------------------------------
@@ -200,6 +229,37 @@ type RecordErrorEvent struct {
 	Error error
 }
 
+type RecordContentModerationEvent struct {
+	hook.Event
+	App App
+	baseRecordEventData
+	Context context.Context
+
+	// Texts contains the new or changed text and editor field values
+	// of the record (field name -> value).
+	Texts map[string]string
+
+	// Files contains the new uploaded files of the record (field name -> files).
+	Files map[string][]*filesystem.File
+
+	// Outcome is the moderation result and it could be any of the
+	// ModerationOutcome* constants (empty value is the same as [ModerationOutcomeAllow]).
+	Outcome string
+
+	// Reasons is an optional list with the moderation outcome reasons
+	// (e.g. the flagged categories like "hate", "violence", etc.).
+	Reasons []string
+}
+
+type RecordChangeEvent struct {
+	hook.Event
+	App App
+	baseCollectionEventData
+
+	// Change is the captured record change (see [FindRecordChanges]).
+	Change *RecordChange
+}
+
 func syncModelEventWithRecordEvent(me *ModelEvent, re *RecordEvent) {
 	me.App = re.App
 	me.Context = re.Context
@@ -375,8 +435,43 @@ type RealtimeConnectRequestEvent struct {
 
 	Client subscriptions.Client
 
+	// Transport is the realtime connection transport type
+	// ("sse" or "poll" for the long-polling fallback).
+	Transport string
+
+	// IdleTimeout is the max duration of client inactivity before
+	// closing the connection (for the "poll" transport this is the
+	// max duration between two consecutive poll requests).
+	//
 	// note: modifying it after the connect has no effect
 	IdleTimeout time.Duration
+
+	// HeartbeatInterval specifies how often to send an SSE comment
+	// line to keep the connection alive behind proxies and load balancers
+	// (zero or negative value disables the heartbeat).
+	//
+	// The heartbeat doesn't reset the idle timer.
+	//
+	// note: modifying it after the connect has no effect
+	HeartbeatInterval time.Duration
+
+	// RetryInterval specifies the SSE reconnection delay hint that is sent
+	// together with the PB_CONNECT message (zero or negative value omits it).
+	//
+	// note: modifying it after the connect has no effect
+	RetryInterval time.Duration
+
+	// MaxLifetime specifies the max duration of a single connection,
+	// regardless of its activity (zero or negative value means no limit).
+	//
+	// note: modifying it after the connect has no effect
+	MaxLifetime time.Duration
+
+	// PollTimeout specifies how long a single "poll" transport request
+	// waits for new messages before returning an empty response.
+	//
+	// note: modifying it after the connect has no effect
+	PollTimeout time.Duration
 }
 
 type RealtimeMessageEvent struct {
@@ -425,6 +520,16 @@ type RecordEnrichEvent struct {
 	RequestInfo *RequestInfo
 }
 
+type RecordEnrichResponseEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	// Records is the list with the already enriched response records
+	// (all from the same collection).
+	Records []*Record
+}
+
 // -------------------------------------------------------------------
 // Auth Record API events data
 // -------------------------------------------------------------------
@@ -447,6 +552,78 @@ type RecordAuthWithOTPRequestEvent struct {
 	OTP    *OTP
 }
 
+type RecordRequestMagicLinkRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	// Record is the auth record to send the magic link to
+	// (could be nil if no matching identity is found).
+	Record *Record
+}
+
+type RecordAuthWithMagicLinkRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record *Record
+
+	// OTP is the one-time record that the magic link was issued for
+	// (it is deleted on successful auth so that the link can't be reused).
+	OTP *OTP
+}
+
+type RecordRequestSMSOTPRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	// Record is the auth record to send the SMS OTP to
+	// (could be nil if no matching identity is found).
+	Record *Record
+
+	// Password is the generated SMS OTP password.
+	Password string
+}
+
+type RecordAuthWithSMSOTPRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record *Record
+	OTP    *OTP
+}
+
+// RecordAuthLockoutEvent is triggered when an auth collection
+// identity and IP pair reaches the max failed auth attempts.
+type RecordAuthLockoutEvent struct {
+	hook.Event
+	App App
+	baseCollectionEventData
+
+	// Record is the auth record matching the identity
+	// (could be nil if there is no such record).
+	Record *Record
+
+	// AuthMethod is the failed auth method (e.g. "password", "otp").
+	AuthMethod string
+
+	// Identity is the identity used for the failed auth attempts
+	// (e.g. the submitted email/username or the OTP auth record id).
+	Identity string
+
+	// IP is the client IP of the failed auth attempts.
+	IP string
+
+	// Attempts is the number of the failed attempts within the configured window.
+	Attempts int
+
+	// Duration is the lockout duration to apply (could be modified by the hook handlers).
+	Duration time.Duration
+}
+
 type RecordAuthRequestEvent struct {
 	hook.Event
 	*RequestEvent
@@ -458,6 +635,16 @@ type RecordAuthRequestEvent struct {
 	AuthMethod string
 }
 
+type RecordNewDeviceLoginEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record     *Record
+	AuthOrigin *AuthOrigin
+	AuthMethod string
+}
+
 type RecordAuthWithPasswordRequestEvent struct {
 	hook.Event
 	*RequestEvent
@@ -482,6 +669,36 @@ type RecordAuthWithOAuth2RequestEvent struct {
 	IsNewRecord    bool
 }
 
+type RecordAuthWithSAMLRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record      *Record
+	SAMLUser    *saml.Assertion
+	CreateData  map[string]any
+	IsNewRecord bool
+}
+
+type RecordAuthAsGuestRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	// Record is the new (not persisted yet) anonymous auth record.
+	Record *Record
+}
+
+type RecordGuestUpgradeRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record   *Record
+	Email    string
+	Password string
+}
+
 type RecordAuthRefreshRequestEvent struct {
 	hook.Event
 	*RequestEvent
@@ -522,6 +739,23 @@ type RecordConfirmVerificationRequestEvent struct {
 	Record *Record
 }
 
+// ActionLinkRequestEvent is triggered on signed action link execution
+// (see [App.NewActionToken]).
+type ActionLinkRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	// Record is the action token target record.
+	Record *Record
+
+	// Action is the name of the action to execute (e.g. "verify", "unsubscribe").
+	Action string
+
+	// Data is the optional action data signed with the token.
+	Data map[string]any
+}
+
 type RecordRequestEmailChangeRequestEvent struct {
 	hook.Event
 	*RequestEvent
@@ -18,6 +18,9 @@ var (
 
 const CollectionNameExternalAuths = "_externalAuths"
 
+// ExternalAuthProviderSAML is the provider name of the SAML linked ExternalAuth models.
+const ExternalAuthProviderSAML = "saml"
+
 // ExternalAuth defines a Record proxy for working with the externalAuths collection.
 type ExternalAuth struct {
 	*Record
@@ -123,7 +126,8 @@ func (app *BaseApp) registerExternalAuthHooks() {
 
 	app.OnRecordValidate(CollectionNameExternalAuths).Bind(&hook.Handler[*RecordEvent]{
 		Func: func(e *RecordEvent) error {
-			providerNames := make([]any, 0, len(auth.Providers))
+			providerNames := make([]any, 0, len(auth.Providers)+1)
+			providerNames = append(providerNames, ExternalAuthProviderSAML)
 			for name := range auth.Providers {
 				providerNames = append(providerNames, name)
 			}
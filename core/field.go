@@ -158,6 +158,14 @@ type DriverValuer interface {
 	DriverValue(record *Record) (driver.Value, error)
 }
 
+// ComputedValuer defines a field interface for the virtual fields
+// which value is not stored in the database but evaluated at query time.
+type ComputedValuer interface {
+	// ComputedExpr returns the SQL expression of the field value
+	// for the record table with the specified alias.
+	ComputedExpr(app App, tableAlias string) (string, error)
+}
+
 // MultiValuer defines a field interface that every multi-valued (eg. with MaxSelect) field has.
 type MultiValuer interface {
 	// IsMultiple checks whether the field is configured to support multiple or single values.
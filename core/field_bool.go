@@ -16,7 +16,10 @@ func init() {
 
 const FieldTypeBool = "bool"
 
-var _ Field = (*BoolField)(nil)
+var (
+	_ Field              = (*BoolField)(nil)
+	_ DefaultValueGetter = (*BoolField)(nil)
+)
 
 // BoolField defines "bool" type field to store a single true/false value.
 //
@@ -44,6 +47,11 @@ type BoolField struct {
 
 	// Required will require the field value to be always "true".
 	Required bool `form:"required" json:"required"`
+
+	// DefaultValue specifies an optional default value expression that is applied
+	// on record create API request when the field value is not submitted
+	// (see [ResolveFieldDefaultValue] for the supported expressions).
+	DefaultValue string `form:"defaultValue" json:"defaultValue"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -91,6 +99,11 @@ func (f *BoolField) SetHidden(hidden bool) {
 	f.Hidden = hidden
 }
 
+// GetDefaultValue implements [DefaultValueGetter] interface method.
+func (f *BoolField) GetDefaultValue() string {
+	return f.DefaultValue
+}
+
 // ColumnType implements [Field.ColumnType] interface method.
 func (f *BoolField) ColumnType(app App) string {
 	return "BOOLEAN DEFAULT FALSE NOT NULL"
@@ -120,5 +133,6 @@ func (f *BoolField) ValidateSettings(ctx context.Context, app App, collection *C
 	return validation.ValidateStruct(f,
 		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
 		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(&f.DefaultValue, validation.By(checkDefaultValueExpr)),
 	)
 }
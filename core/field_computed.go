@@ -0,0 +1,350 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypeComputed] = func() Field {
+		return &ComputedField{}
+	}
+}
+
+const FieldTypeComputed = "computed"
+
+// Computed field result types.
+const (
+	ComputedResultText   = "text"
+	ComputedResultNumber = "number"
+	ComputedResultBool   = "bool"
+)
+
+var (
+	_ Field          = (*ComputedField)(nil)
+	_ SetterFinder   = (*ComputedField)(nil)
+	_ ComputedValuer = (*ComputedField)(nil)
+)
+
+var (
+	computedSelfRegex      = regexp.MustCompile(`@self\.(\w+)`)
+	computedAggregateRegex = regexp.MustCompile(`(?i)^\s*(count|sum|avg|min|max)\(\s*(\w+)_via_(\w+)(?:\.(\w+))?\s*\)\s*$`)
+)
+
+// ComputedField defines "computed" type read-only field which value
+// is not stored in the database but evaluated at query time from either
+// a SQL expression or a back-relation aggregate.
+//
+// The computed field values could be used in the API rules and
+// filtered and sorted in the records list API as any other field.
+//
+// Note that the field value is resolved only when the record is loaded
+// from the database (aka. a new or just saved record is not refreshed).
+type ComputedField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Expression is a SQL expression of the field value.
+	//
+	// The current record columns could be referenced with the "@self." prefix, e.g.:
+	//
+	//	@self.price * @self.quantity
+	//	(SELECT COUNT(*) FROM orders WHERE orders.customer = @self.id)
+	Expression string `form:"expression" json:"expression"`
+
+	// Aggregate is a back-relation aggregate of the field value
+	// in the format "func(collection_via_relField.targetField)", e.g.:
+	//
+	//	count(orders_via_customer)
+	//	sum(orders_via_customer.total)
+	//
+	// The supported functions are count, sum, avg, min and max.
+	//
+	// Aggregate and Expression are mutually exclusive.
+	Aggregate string `form:"aggregate" json:"aggregate"`
+
+	// Result specifies the type of the field value - "text", "number" or "bool".
+	//
+	// If empty, defaults to "number" for aggregates and "text" for expressions.
+	Result string `form:"result" json:"result"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *ComputedField) Type() string {
+	return FieldTypeComputed
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *ComputedField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *ComputedField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *ComputedField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *ComputedField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *ComputedField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *ComputedField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *ComputedField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *ComputedField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+//
+// It always returns an empty string because the field doesn't have a DB column.
+func (f *ComputedField) ColumnType(app App) string {
+	return ""
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+func (f *ComputedField) PrepareValue(record *Record, raw any) (any, error) {
+	switch f.resultType() {
+	case ComputedResultNumber:
+		return cast.ToFloat64(raw), nil
+	case ComputedResultBool:
+		return cast.ToBool(raw), nil
+	default:
+		return cast.ToString(raw), nil
+	}
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *ComputedField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	return nil // read-only
+}
+
+// FindSetter implements the [SetterFinder] interface.
+func (f *ComputedField) FindSetter(key string) SetterFunc {
+	switch key {
+	case f.Name:
+		// return noopSetter to disallow updating the value with record.Set()
+		return noopSetter
+	default:
+		return nil
+	}
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *ComputedField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	err := validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule), validation.By(func(value any) error {
+			if collection.IsView() {
+				return validation.NewError("validation_computed_view_collection", "Computed fields are not supported for view collections.")
+			}
+			return nil
+		})),
+		validation.Field(&f.Expression,
+			validation.When(f.Aggregate == "", validation.Required),
+			validation.When(f.Aggregate != "", validation.Empty),
+			validation.Length(0, 1000),
+		),
+		validation.Field(&f.Aggregate, validation.By(f.checkAggregate(app, collection))),
+		validation.Field(&f.Result, validation.In(ComputedResultText, ComputedResultNumber, ComputedResultBool)),
+	)
+	if err != nil {
+		return err
+	}
+
+	return f.checkExpression(app, collection)
+}
+
+// ComputedExpr implements the [ComputedValuer] interface.
+func (f *ComputedField) ComputedExpr(app App, tableAlias string) (string, error) {
+	if f.Aggregate == "" {
+		if f.Expression == "" {
+			return "", fmt.Errorf("missing computed field %q expression", f.Name)
+		}
+
+		return "(" + computedSelfRegex.ReplaceAllString(f.Expression, "[["+tableAlias+".$1]]") + ")", nil
+	}
+
+	fn, relCollectionName, relFieldName, target, err := parseComputedAggregate(f.Aggregate)
+	if err != nil {
+		return "", err
+	}
+
+	relCollection, err := app.FindCachedCollectionByNameOrId(relCollectionName)
+	if err != nil {
+		return "", fmt.Errorf("failed to load computed field %q aggregate collection: %w", f.Name, err)
+	}
+
+	relField, ok := relCollection.Fields.GetByName(relFieldName).(*RelationField)
+	if !ok {
+		return "", fmt.Errorf("invalid computed field %q aggregate relation field", f.Name)
+	}
+
+	subAlias := tableAlias + "_" + f.Name + "_agg"
+
+	var value string
+	if target == "" {
+		value = "COUNT(*)"
+	} else {
+		value = fmt.Sprintf("%s([[%s.%s]])", fn, subAlias, target)
+	}
+	if fn == "SUM" {
+		value = "COALESCE(" + value + ", 0)"
+	}
+
+	var where string
+	if relField.IsMultiple() {
+		jeAlias := subAlias + "_je"
+		where = fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s [[%s]] WHERE [[%s.value]] = [[%s.id]])",
+			dbutils.JSONEach(subAlias+"."+relField.Name),
+			jeAlias,
+			jeAlias,
+			tableAlias,
+		)
+	} else {
+		where = fmt.Sprintf("[[%s.%s]] = [[%s.id]]", subAlias, relField.Name, tableAlias)
+	}
+
+	return fmt.Sprintf("(SELECT %s FROM {{%s}} [[%s]] WHERE %s)", value, relCollection.Name, subAlias, where), nil
+}
+
+func (f *ComputedField) resultType() string {
+	if f.Result != "" {
+		return f.Result
+	}
+
+	if f.Aggregate != "" {
+		return ComputedResultNumber
+	}
+
+	return ComputedResultText
+}
+
+func (f *ComputedField) checkAggregate(app App, collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+		if v == "" {
+			return nil
+		}
+
+		fn, relCollectionName, relFieldName, target, err := parseComputedAggregate(v)
+		if err != nil {
+			return validation.NewError("validation_invalid_aggregate", "Invalid aggregate format.")
+		}
+
+		if fn != "COUNT" && target == "" {
+			return validation.NewError("validation_missing_aggregate_target", "Missing aggregate target field.")
+		}
+
+		relCollection, err := app.FindCachedCollectionByNameOrId(relCollectionName)
+		if err != nil {
+			return validation.NewError("validation_missing_aggregate_collection", "Missing aggregate collection.")
+		}
+
+		relField, ok := relCollection.Fields.GetByName(relFieldName).(*RelationField)
+		if !ok || relField.CollectionId != collection.Id {
+			return validation.NewError("validation_invalid_aggregate_relation", "The aggregate field must be a relation to the current collection.")
+		}
+
+		if target != "" {
+			targetField := relCollection.Fields.GetByName(target)
+			if targetField == nil {
+				return validation.NewError("validation_missing_aggregate_target", "Missing aggregate target field.")
+			}
+			if _, ok := targetField.(ComputedValuer); ok {
+				return validation.NewError("validation_invalid_aggregate_target", "The aggregate target field cannot be a computed field.")
+			}
+		}
+
+		return nil
+	}
+}
+
+// checkExpression checks whether the field SQL expression could be
+// executed against a dummy table with the collection columns.
+func (f *ComputedField) checkExpression(app App, collection *Collection) error {
+	const alias = "__pb_computed_check"
+
+	expr, err := f.ComputedExpr(app, alias)
+	if err != nil {
+		return validation.Errors{"expression": validation.NewError("validation_invalid_expression", "Invalid computed expression.")}
+	}
+
+	columns := make([]string, 0, len(collection.Fields))
+	for _, field := range collection.Fields {
+		if _, ok := field.(ComputedValuer); ok {
+			continue
+		}
+		columns = append(columns, "NULL AS [["+field.GetName()+"]]")
+	}
+
+	_, err = app.DB().NewQuery(fmt.Sprintf(
+		"WITH [[%s]] AS (SELECT %s) SELECT %s FROM [[%s]] LIMIT 0",
+		alias,
+		strings.Join(columns, ", "),
+		expr,
+		alias,
+	)).Execute()
+	if err != nil {
+		key := "expression"
+		if f.Aggregate != "" {
+			key = "aggregate"
+		}
+
+		return validation.Errors{key: validation.NewError("validation_invalid_expression", "Invalid computed expression: "+err.Error())}
+	}
+
+	return nil
+}
+
+func parseComputedAggregate(aggregate string) (fn, collection, field, target string, err error) {
+	parts := computedAggregateRegex.FindStringSubmatch(aggregate)
+	if len(parts) != 5 {
+		return "", "", "", "", fmt.Errorf("invalid aggregate %q", aggregate)
+	}
+
+	return strings.ToUpper(parts[1]), parts[2], parts[3], parts[4], nil
+}
@@ -0,0 +1,307 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestComputedFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeComputed)
+}
+
+func TestComputedFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.ComputedField{}
+
+	if v := f.ColumnType(app); v != "" {
+		t.Fatalf("Expected empty column type, got %q", v)
+	}
+}
+
+func TestComputedFieldPrepareValue(t *testing.T) {
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	scenarios := []struct {
+		field    *core.ComputedField
+		raw      any
+		expected any
+	}{
+		{&core.ComputedField{Expression: "1"}, nil, ""},
+		{&core.ComputedField{Expression: "1"}, 123, "123"},
+		{&core.ComputedField{Aggregate: "count(a_via_b)"}, nil, 0.0},
+		{&core.ComputedField{Aggregate: "count(a_via_b)"}, "12.5", 12.5},
+		{&core.ComputedField{Expression: "1", Result: core.ComputedResultNumber}, "3", 3.0},
+		{&core.ComputedField{Expression: "1", Result: core.ComputedResultBool}, "1", true},
+		{&core.ComputedField{Expression: "1", Result: core.ComputedResultBool}, "0", false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			v, err := s.field.PrepareValue(record, s.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v != s.expected {
+				t.Fatalf("Expected %#v, got %#v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestComputedFieldFindSetter(t *testing.T) {
+	field := &core.ComputedField{Name: "test", Expression: "1"}
+
+	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(field)
+
+	record := core.NewRecord(collection)
+	record.Set("test", "abc")
+
+	if v := record.GetString("test"); v != "" {
+		t.Fatalf("Expected the computed field value to be unchanged, got %q", v)
+	}
+}
+
+func TestComputedFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeComputed)
+	testDefaultFieldNameValidation(t, core.FieldTypeComputed)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo4, err := app.FindCollectionByNameOrId("demo4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		collection   func() *core.Collection
+		field        *core.ComputedField
+		expectErrors []string
+	}{
+		{
+			"missing expression and aggregate",
+			func() *core.Collection { return core.NewBaseCollection("test_collection") },
+			&core.ComputedField{Id: "test", Name: "test"},
+			[]string{"expression"},
+		},
+		{
+			"both expression and aggregate",
+			func() *core.Collection { return demo4 },
+			&core.ComputedField{Id: "test", Name: "test", Expression: "1", Aggregate: "count(demo4_via_self_rel_one)"},
+			[]string{"expression"},
+		},
+		{
+			"invalid result",
+			func() *core.Collection { return core.NewBaseCollection("test_collection") },
+			&core.ComputedField{Id: "test", Name: "test", Expression: "1", Result: "invalid"},
+			[]string{"result"},
+		},
+		{
+			"view collection",
+			func() *core.Collection { return core.NewViewCollection("test_collection") },
+			&core.ComputedField{Id: "test", Name: "test", Expression: "1"},
+			[]string{"name"},
+		},
+		{
+			"invalid expression",
+			func() *core.Collection { return core.NewBaseCollection("test_collection") },
+			&core.ComputedField{Id: "test", Name: "test", Expression: "@self.missing + 1"},
+			[]string{"expression"},
+		},
+		{
+			"valid expression",
+			func() *core.Collection {
+				c := core.NewBaseCollection("test_collection")
+				c.Fields.Add(&core.NumberField{Name: "price"}, &core.NumberField{Name: "quantity"})
+				return c
+			},
+			&core.ComputedField{Id: "test", Name: "test", Expression: "@self.price * @self.quantity"},
+			[]string{},
+		},
+		{
+			"invalid aggregate format",
+			func() *core.Collection { return demo4 },
+			&core.ComputedField{Id: "test", Name: "test", Aggregate: "count(demo5)"},
+			[]string{"aggregate"},
+		},
+		{
+			"missing aggregate collection",
+			func() *core.Collection { return demo4 },
+			&core.ComputedField{Id: "test", Name: "test", Aggregate: "count(missing_via_rel)"},
+			[]string{"aggregate"},
+		},
+		{
+			"aggregate field not pointing to the current collection",
+			func() *core.Collection { return demo4 },
+			&core.ComputedField{Id: "test", Name: "test", Aggregate: "count(demo1_via_rel_one)"},
+			[]string{"aggregate"},
+		},
+		{
+			"missing aggregate target",
+			func() *core.Collection { return demo4 },
+			&core.ComputedField{Id: "test", Name: "test", Aggregate: "sum(demo5_via_rel_one)"},
+			[]string{"aggregate"},
+		},
+		{
+			"unknown aggregate target",
+			func() *core.Collection { return demo4 },
+			&core.ComputedField{Id: "test", Name: "test", Aggregate: "sum(demo5_via_rel_one.missing)"},
+			[]string{"aggregate"},
+		},
+		{
+			"valid count aggregate",
+			func() *core.Collection { return demo4 },
+			&core.ComputedField{Id: "test", Name: "test", Aggregate: "count(demo4_via_self_rel_many)"},
+			[]string{},
+		},
+		{
+			"valid sum aggregate",
+			func() *core.Collection { return demo4 },
+			&core.ComputedField{Id: "test", Name: "test", Aggregate: "sum(demo5_via_rel_many.total)"},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := s.field.ValidateSettings(context.Background(), app, s.collection())
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestComputedFieldQuery(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	customers := core.NewBaseCollection("customers")
+	customers.Fields.Add(&core.TextField{Name: "name"})
+	if err := app.Save(customers); err != nil {
+		t.Fatal(err)
+	}
+
+	orders := core.NewBaseCollection("orders")
+	orders.Fields.Add(
+		&core.RelationField{Name: "customer", CollectionId: customers.Id, MaxSelect: 1},
+		&core.RelationField{Name: "referrers", CollectionId: customers.Id, MaxSelect: 5},
+		&core.NumberField{Name: "total"},
+	)
+	if err := app.Save(orders); err != nil {
+		t.Fatal(err)
+	}
+
+	customers.Fields.Add(
+		&core.ComputedField{Name: "orders_count", Aggregate: "count(orders_via_customer)"},
+		&core.ComputedField{Name: "total_spent", Aggregate: "sum(orders_via_customer.total)"},
+		&core.ComputedField{Name: "referrals", Aggregate: "count(orders_via_referrers)"},
+		&core.ComputedField{Name: "label", Expression: "upper(@self.name)"},
+	)
+	if err := app.Save(customers); err != nil {
+		t.Fatal(err)
+	}
+
+	customerIds := map[string]string{}
+	for _, name := range []string{"a", "b", "c"} {
+		record := core.NewRecord(customers)
+		record.Set("name", name)
+		record.Set("total_spent", 1000) // should be ignored
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+		customerIds[name] = record.Id
+	}
+
+	seed := []struct {
+		customer  string
+		referrers []string
+		total     float64
+	}{
+		{"a", nil, 10},
+		{"a", []string{"b"}, 15},
+		{"b", []string{"a", "c"}, 3},
+	}
+	for _, s := range seed {
+		record := core.NewRecord(orders)
+		record.Set("customer", customerIds[s.customer])
+		referrers := make([]string, 0, len(s.referrers))
+		for _, r := range s.referrers {
+			referrers = append(referrers, customerIds[r])
+		}
+		record.Set("referrers", referrers)
+		record.Set("total", s.total)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("find", func(t *testing.T) {
+		record, err := app.FindRecordById(customers, customerIds["a"])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := map[string]any{
+			"orders_count": 2.0,
+			"total_spent":  25.0,
+			"referrals":    1.0,
+			"label":        "A",
+		}
+		for k, v := range expected {
+			if record.Get(k) != v {
+				t.Fatalf("Expected %s %#v, got %#v", k, v, record.Get(k))
+			}
+		}
+	})
+
+	t.Run("filter and sort", func(t *testing.T) {
+		records, err := app.FindRecordsByFilter(customers, "orders_count > 0 && label != 'C'", "-total_spent", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(records) != 2 {
+			t.Fatalf("Expected 2 records, got %d", len(records))
+		}
+
+		if records[0].Id != customerIds["a"] || records[1].Id != customerIds["b"] {
+			t.Fatalf("Unexpected records order %v", records)
+		}
+	})
+
+	t.Run("filter via relation", func(t *testing.T) {
+		records, err := app.FindRecordsByFilter(orders, "customer.total_spent > 20 && referrers.referrals ?= 1", "", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(records) != 1 || records[0].GetFloat("total") != 15 {
+			t.Fatalf("Expected the 15 total order, got %v", records)
+		}
+	})
+
+	t.Run("remove field", func(t *testing.T) {
+		customers.Fields.RemoveByName("total_spent")
+		if err := app.Save(customers); err != nil {
+			t.Fatal(err)
+		}
+
+		record, err := app.FindRecordById(customers, customerIds["a"])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if record.GetFloat("orders_count") != 2 {
+			t.Fatalf("Expected orders_count 2, got %v", record.Get("orders_count"))
+		}
+	})
+}
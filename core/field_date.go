@@ -16,7 +16,10 @@ func init() {
 
 const FieldTypeDate = "date"
 
-var _ Field = (*DateField)(nil)
+var (
+	_ Field              = (*DateField)(nil)
+	_ DefaultValueGetter = (*DateField)(nil)
+)
 
 // DateField defines "date" type field to store a single [types.DateTime] value.
 //
@@ -54,6 +57,11 @@ type DateField struct {
 
 	// Required will require the field value to be non-zero [types.DateTime].
 	Required bool `form:"required" json:"required"`
+
+	// DefaultValue specifies an optional default value expression that is applied
+	// on record create API request when the field value is not submitted
+	// (see [ResolveFieldDefaultValue] for the supported expressions).
+	DefaultValue string `form:"defaultValue" json:"defaultValue"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -101,6 +109,11 @@ func (f *DateField) SetHidden(hidden bool) {
 	f.Hidden = hidden
 }
 
+// GetDefaultValue implements [DefaultValueGetter] interface method.
+func (f *DateField) GetDefaultValue() string {
+	return f.DefaultValue
+}
+
 // ColumnType implements [Field.ColumnType] interface method.
 func (f *DateField) ColumnType(app App) string {
 	return "TEXT DEFAULT '' NOT NULL"
@@ -149,6 +162,7 @@ func (f *DateField) ValidateSettings(ctx context.Context, app App, collection *C
 		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
 		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
 		validation.Field(&f.Max, validation.By(f.checkRange(f.Min, f.Max))),
+		validation.Field(&f.DefaultValue, validation.By(checkDefaultValueExpr)),
 	)
 }
 
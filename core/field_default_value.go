@@ -0,0 +1,85 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// DefaultValueGetter defines an optional field interface for fields
+// that support server-side default value expressions.
+type DefaultValueGetter interface {
+	// GetDefaultValue returns the field default value expression (if any).
+	GetDefaultValue() string
+}
+
+const (
+	defaultValueNow         = "@now"
+	defaultValueAuthPrefix  = "@request.auth."
+	defaultValueExprMaxSize = 1000
+)
+
+// ResolveFieldDefaultValue resolves the provided field default value expression.
+//
+// The following expressions are supported:
+//   - "@now" - the current datetime string
+//   - "@request.auth.*" - the request auth record field value (e.g. "@request.auth.id");
+//     nil is returned for guests or when requestInfo is not set
+//   - JSON serialized literal (e.g. `"draft"`, `10`, `true`, `["a","b"]`)
+func ResolveFieldDefaultValue(expr string, requestInfo *RequestInfo) (any, error) {
+	expr = strings.TrimSpace(expr)
+
+	if expr == defaultValueNow {
+		return types.NowDateTime().String(), nil
+	}
+
+	if authField, ok := strings.CutPrefix(expr, defaultValueAuthPrefix); ok {
+		if requestInfo == nil || requestInfo.Auth == nil {
+			return nil, nil
+		}
+
+		return requestInfo.Auth.Get(authField), nil
+	}
+
+	var result any
+	if err := json.Unmarshal([]byte(expr), &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func checkDefaultValueExpr(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if len(v) > defaultValueExprMaxSize {
+		return validation.ErrLengthOutOfRange.SetParams(map[string]any{"min": 0, "max": defaultValueExprMaxSize})
+	}
+
+	v = strings.TrimSpace(v)
+
+	if v == defaultValueNow {
+		return nil
+	}
+
+	if authField, ok := strings.CutPrefix(v, defaultValueAuthPrefix); ok {
+		if authField == "" || strings.ContainsAny(authField, " .") {
+			return validation.NewError("validation_invalid_default_value_auth_field", "Invalid @request.auth field.")
+		}
+		return nil
+	}
+
+	if !json.Valid([]byte(v)) {
+		return validation.NewError(
+			"validation_invalid_default_value",
+			"The default value must be @now, @request.auth.* or a valid JSON literal (e.g. \"draft\", 10, true).",
+		)
+	}
+
+	return nil
+}
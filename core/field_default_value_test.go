@@ -0,0 +1,104 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestResolveFieldDefaultValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		expr        string
+		info        *core.RequestInfo
+		expected    string
+		expectError bool
+	}{
+		{"invalid JSON", `draft`, nil, `null`, true},
+		{"string literal", ` "draft" `, nil, `"draft"`, false},
+		{"number literal", `10.5`, nil, `10.5`, false},
+		{"bool literal", `true`, nil, `true`, false},
+		{"array literal", `["a","b"]`, nil, `["a","b"]`, false},
+		{"auth field without request info", `@request.auth.id`, nil, `null`, false},
+		{"auth field as guest", `@request.auth.id`, &core.RequestInfo{}, `null`, false},
+		{"auth id", `@request.auth.id`, &core.RequestInfo{Auth: user}, `"4q1xlclmfloku33"`, false},
+		{"auth email", `@request.auth.email`, &core.RequestInfo{Auth: user}, `"test@example.com"`, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := core.ResolveFieldDefaultValue(s.expr, s.info)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			raw, _ := json.Marshal(result)
+			if str := string(raw); str != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, str)
+			}
+		})
+	}
+
+	t.Run("@now", func(t *testing.T) {
+		result, err := core.ResolveFieldDefaultValue("@now", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		str, _ := result.(string)
+		if len(str) != len("2006-01-02 15:04:05.000Z") || !strings.HasSuffix(str, "Z") {
+			t.Fatalf("Expected datetime string, got %v", result)
+		}
+	})
+}
+
+func TestFieldDefaultValueValidateSettings(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		value       string
+		expectError bool
+	}{
+		{"", false},
+		{"@now", false},
+		{"@request.auth.id", false},
+		{`"draft"`, false},
+		{`{"a":1}`, false},
+		{"draft", true},
+		{"@request.auth.", true},
+		{"@request.auth.a.b", true},
+		{`"` + strings.Repeat("a", 1000) + `"`, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.value, func(t *testing.T) {
+			field := &core.TextField{Name: "test", Id: "test_id", DefaultValue: s.value}
+
+			errs, _ := field.ValidateSettings(context.Background(), app, collection).(validation.Errors)
+
+			_, hasErr := errs["defaultValue"]
+
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v", s.expectError, hasErr)
+			}
+		})
+	}
+}
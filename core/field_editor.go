@@ -21,6 +21,7 @@ const DefaultEditorFieldMaxSize int64 = 5 << 20
 var (
 	_ Field                 = (*EditorField)(nil)
 	_ MaxBodySizeCalculator = (*EditorField)(nil)
+	_ DefaultValueGetter    = (*EditorField)(nil)
 )
 
 // EditorField defines "editor" type field to store HTML formatted text.
@@ -61,6 +62,11 @@ type EditorField struct {
 
 	// Required will require the field value to be non-empty string.
 	Required bool `form:"required" json:"required"`
+
+	// DefaultValue specifies an optional default value expression that is applied
+	// on record create API request when the field value is not submitted
+	// (see [ResolveFieldDefaultValue] for the supported expressions).
+	DefaultValue string `form:"defaultValue" json:"defaultValue"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -108,6 +114,11 @@ func (f *EditorField) SetHidden(hidden bool) {
 	f.Hidden = hidden
 }
 
+// GetDefaultValue implements [DefaultValueGetter] interface method.
+func (f *EditorField) GetDefaultValue() string {
+	return f.DefaultValue
+}
+
 // ColumnType implements [Field.ColumnType] interface method.
 func (f *EditorField) ColumnType(app App) string {
 	return "TEXT DEFAULT '' NOT NULL"
@@ -149,6 +160,7 @@ func (f *EditorField) ValidateSettings(ctx context.Context, app App, collection
 		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
 		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
 		validation.Field(&f.MaxSize, validation.Min(0)),
+		validation.Field(&f.DefaultValue, validation.By(checkDefaultValueExpr)),
 	)
 }
 
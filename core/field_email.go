@@ -19,7 +19,10 @@ func init() {
 
 const FieldTypeEmail = "email"
 
-var _ Field = (*EmailField)(nil)
+var (
+	_ Field              = (*EmailField)(nil)
+	_ DefaultValueGetter = (*EmailField)(nil)
+)
 
 // EmailField defines "email" type field for storing a single email string address.
 //
@@ -57,6 +60,11 @@ type EmailField struct {
 
 	// Required will require the field value to be non-empty email string.
 	Required bool `form:"required" json:"required"`
+
+	// DefaultValue specifies an optional default value expression that is applied
+	// on record create API request when the field value is not submitted
+	// (see [ResolveFieldDefaultValue] for the supported expressions).
+	DefaultValue string `form:"defaultValue" json:"defaultValue"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -104,6 +112,11 @@ func (f *EmailField) SetHidden(hidden bool) {
 	f.Hidden = hidden
 }
 
+// GetDefaultValue implements [DefaultValueGetter] interface method.
+func (f *EmailField) GetDefaultValue() string {
+	return f.DefaultValue
+}
+
 // ColumnType implements [Field.ColumnType] interface method.
 func (f *EmailField) ColumnType(app App) string {
 	return "TEXT DEFAULT '' NOT NULL"
@@ -163,5 +176,6 @@ func (f *EmailField) ValidateSettings(ctx context.Context, app App, collection *
 			&f.OnlyDomains,
 			validation.When(len(f.ExceptDomains) > 0, validation.Empty).Else(validation.Each(is.Domain)),
 		),
+		validation.Field(&f.DefaultValue, validation.By(checkDefaultValueExpr)),
 	)
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -121,6 +122,13 @@ type FileField struct {
 	//   - Wx0  (eg. 100x0)    - resize to W width preserving the aspect ratio
 	Thumbs []string `form:"thumbs" json:"thumbs"`
 
+	// ThumbPresets specifies an optional list of named thumb presets for image based files.
+	//
+	// When at least one preset is defined, the "thumb" file query parameter
+	// accepts only the preset names and any other value (including the
+	// [FileField.Thumbs] sizes) is rejected.
+	ThumbPresets []ThumbPreset `form:"thumbPresets" json:"thumbPresets"`
+
 	// Protected will require the users to provide a special file token to access the file.
 	//
 	// Note that by default all files are publicly accessible.
@@ -229,9 +237,51 @@ func (f *FileField) ValidateSettings(ctx context.Context, app App, collection *C
 			validation.NotIn("0x0", "0x0t", "0x0b", "0x0f"),
 			validation.Match(filesystem.ThumbSizeRegex),
 		)),
+		validation.Field(&f.ThumbPresets, validation.By(f.checkThumbPresets)),
 	)
 }
 
+func (f *FileField) checkThumbPresets(value any) error {
+	presets, _ := value.([]ThumbPreset)
+
+	names := make(map[string]struct{}, len(presets))
+
+	errs := validation.Errors{}
+
+	for i, preset := range presets {
+		if err := preset.Validate(); err != nil {
+			errs[strconv.Itoa(i)] = err
+			continue
+		}
+
+		if _, ok := names[preset.Name]; ok {
+			errs[strconv.Itoa(i)] = validation.Errors{
+				"name": validation.NewError("validation_duplicated_thumb_preset", "Duplicated thumb preset name."),
+			}
+			continue
+		}
+
+		names[preset.Name] = struct{}{}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// FindThumbPreset returns the thumb preset with the specified name (or nil if missing).
+func (f *FileField) FindThumbPreset(name string) *ThumbPreset {
+	for i := range f.ThumbPresets {
+		if f.ThumbPresets[i].Name == name {
+			return &f.ThumbPresets[i]
+		}
+	}
+
+	return nil
+}
+
 // ValidateValue implements [Field.ValidateValue] interface method.
 func (f *FileField) ValidateValue(ctx context.Context, app App, record *Record) error {
 	files := f.toSliceValue(record.GetRaw(f.Name))
@@ -813,3 +863,69 @@ func (f *FileField) getFileName(file any) string {
 		return ""
 	}
 }
+
+// -------------------------------------------------------------------
+
+// Thumb preset fit modes.
+const (
+	ThumbFitCenter  = "center"
+	ThumbFitTop     = "top"
+	ThumbFitBottom  = "bottom"
+	ThumbFitContain = "contain"
+)
+
+var thumbPresetNameRegex = regexp.MustCompile(`^[a-zA-Z][\w\-]*$`)
+
+var thumbPresetSizeRegex = regexp.MustCompile(`^\d+x\d+$`)
+
+// ThumbPreset defines a single named file field thumb preset.
+type ThumbPreset struct {
+	// Name is the preset identifier used as "thumb" query parameter value (eg. "card").
+	Name string `form:"name" json:"name"`
+
+	// Size is the thumb size in WxH format (eg. 100x300, 0x300, 100x0).
+	Size string `form:"size" json:"size"`
+
+	// Fit is the thumb fit mode when both width and height are set:
+	//
+	//   - "center" (default) - crop to WxH viewbox (from center)
+	//   - "top"              - crop to WxH viewbox (from top)
+	//   - "bottom"           - crop to WxH viewbox (from bottom)
+	//   - "contain"          - fit inside a WxH viewbox (without cropping)
+	Fit string `form:"fit" json:"fit"`
+
+	// Format is an optional thumb image format ("jpg", "png" or "gif").
+	//
+	// Leave it empty to keep the original file format.
+	Format string `form:"format" json:"format"`
+}
+
+// Validate makes ThumbPreset validatable by implementing [validation.Validatable] interface.
+func (p ThumbPreset) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Name, validation.Required, validation.Length(1, 50), validation.Match(thumbPresetNameRegex)),
+		validation.Field(
+			&p.Size,
+			validation.Required,
+			validation.Match(thumbPresetSizeRegex),
+			validation.NotIn("0x0"),
+		),
+		validation.Field(&p.Fit, validation.In(ThumbFitCenter, ThumbFitTop, ThumbFitBottom, ThumbFitContain)),
+		validation.Field(&p.Format, validation.In("jpg", "png", "gif")),
+	)
+}
+
+// ThumbSize returns the preset size and fit mode as thumb size
+// string in one of the [FileField.Thumbs] formats (eg. 100x300f).
+func (p ThumbPreset) ThumbSize() string {
+	switch p.Fit {
+	case ThumbFitTop:
+		return p.Size + "t"
+	case ThumbFitBottom:
+		return p.Size + "b"
+	case ThumbFitContain:
+		return p.Size + "f"
+	default:
+		return p.Size
+	}
+}
@@ -539,6 +539,52 @@ func TestFileFieldValidateSettings(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"invalid thumb presets",
+			func() *core.FileField {
+				return &core.FileField{
+					Id:   "test",
+					Name: "test",
+					ThumbPresets: []core.ThumbPreset{
+						{Name: "100x100", Size: "100x100"},
+						{Name: "card", Size: "0x0"},
+						{Name: "hero", Size: "100x100f"},
+						{Name: "avatar", Size: "100x100", Fit: "invalid"},
+						{Name: "banner", Size: "100x100", Format: "webp"},
+					},
+				}
+			},
+			[]string{"thumbPresets"},
+		},
+		{
+			"duplicated thumb presets",
+			func() *core.FileField {
+				return &core.FileField{
+					Id:   "test",
+					Name: "test",
+					ThumbPresets: []core.ThumbPreset{
+						{Name: "card", Size: "100x100"},
+						{Name: "card", Size: "200x200"},
+					},
+				}
+			},
+			[]string{"thumbPresets"},
+		},
+		{
+			"valid thumb presets",
+			func() *core.FileField {
+				return &core.FileField{
+					Id:   "test",
+					Name: "test",
+					ThumbPresets: []core.ThumbPreset{
+						{Name: "card", Size: "100x100"},
+						{Name: "hero_wide", Size: "1200x0", Format: "jpg"},
+						{Name: "avatar-sm", Size: "40x40", Fit: core.ThumbFitTop, Format: "png"},
+					},
+				}
+			},
+			[]string{},
+		},
 	}
 
 	for _, s := range scenarios {
@@ -555,6 +601,46 @@ func TestFileFieldValidateSettings(t *testing.T) {
 	}
 }
 
+func TestFileFieldFindThumbPreset(t *testing.T) {
+	field := &core.FileField{
+		ThumbPresets: []core.ThumbPreset{
+			{Name: "card", Size: "100x100"},
+			{Name: "hero", Size: "200x0"},
+		},
+	}
+
+	if preset := field.FindThumbPreset("missing"); preset != nil {
+		t.Fatalf("Expected nil preset, got %v", preset)
+	}
+
+	preset := field.FindThumbPreset("hero")
+	if preset == nil || preset.Size != "200x0" {
+		t.Fatalf("Expected the hero preset, got %v", preset)
+	}
+}
+
+func TestThumbPresetThumbSize(t *testing.T) {
+	scenarios := []struct {
+		preset   core.ThumbPreset
+		expected string
+	}{
+		{core.ThumbPreset{Size: "100x50"}, "100x50"},
+		{core.ThumbPreset{Size: "100x50", Fit: core.ThumbFitCenter}, "100x50"},
+		{core.ThumbPreset{Size: "100x50", Fit: core.ThumbFitTop}, "100x50t"},
+		{core.ThumbPreset{Size: "100x50", Fit: core.ThumbFitBottom}, "100x50b"},
+		{core.ThumbPreset{Size: "100x50", Fit: core.ThumbFitContain}, "100x50f"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.preset.Size+"_"+s.preset.Fit, func(t *testing.T) {
+			result := s.preset.ThumbSize()
+			if result != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, result)
+			}
+		})
+	}
+}
+
 func TestFileFieldCalculateMaxBodySize(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
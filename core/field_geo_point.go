@@ -0,0 +1,216 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"math"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypeGeoPoint] = func() Field {
+		return &GeoPointField{}
+	}
+}
+
+const FieldTypeGeoPoint = "geoPoint"
+
+var (
+	_ Field        = (*GeoPointField)(nil)
+	_ DriverValuer = (*GeoPointField)(nil)
+)
+
+// GeoPointField defines "geoPoint" type field for storing a single
+// geographic coordinate as {"lat":0,"lon":0} JSON object.
+//
+// The geo point records could be filtered and sorted by their distance
+// to another point with the geoDistance() filter and sort function, eg.:
+//
+//	geoDistance(location, 3.12, 101.6) < 5000
+//
+// The respective zero record field value is types.GeoPoint{}.
+type GeoPointField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Required will require the field value to be a non-zero "0,0" coordinate.
+	Required bool `form:"required" json:"required"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *GeoPointField) Type() string {
+	return FieldTypeGeoPoint
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *GeoPointField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *GeoPointField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *GeoPointField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *GeoPointField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *GeoPointField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *GeoPointField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *GeoPointField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *GeoPointField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *GeoPointField) ColumnType(app App) string {
+	return `JSON DEFAULT '{"lat":0,"lon":0}' NOT NULL`
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+//
+// Values that cannot be converted to a geo point are returned as they are
+// so that they could be reported during the field value validation.
+func (f *GeoPointField) PrepareValue(record *Record, raw any) (any, error) {
+	point, err := ParseGeoPoint(raw)
+	if err != nil {
+		return raw, err
+	}
+
+	return point, nil
+}
+
+// DriverValue implements the [DriverValuer] interface.
+func (f *GeoPointField) DriverValue(record *Record) (driver.Value, error) {
+	point, err := ParseGeoPoint(record.GetRaw(f.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	return point.Value()
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *GeoPointField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	point, ok := record.GetRaw(f.Name).(types.GeoPoint)
+	if !ok {
+		return validation.NewError("validation_invalid_geo_point", "Must be a valid geo point object.")
+	}
+
+	if point.IsZero() {
+		if f.Required {
+			return validation.ErrRequired
+		}
+		return nil // nothing to check
+	}
+
+	if math.IsNaN(point.Lat) || point.Lat < -90 || point.Lat > 90 {
+		return validation.NewError("validation_invalid_latitude", "The latitude must be between -90 and 90 degrees.")
+	}
+
+	if math.IsNaN(point.Lon) || point.Lon < -180 || point.Lon > 180 {
+		return validation.NewError("validation_invalid_longitude", "The longitude must be between -180 and 180 degrees.")
+	}
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *GeoPointField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// ParseGeoPoint converts the provided raw value (JSON object string,
+// map with "lat" and "lon" keys, types.GeoPoint, etc.) into a types.GeoPoint.
+//
+// nil and empty string values are converted to the zero geo point.
+func ParseGeoPoint(raw any) (types.GeoPoint, error) {
+	point := types.GeoPoint{}
+
+	switch v := raw.(type) {
+	case nil:
+		return point, nil
+	case types.GeoPoint:
+		return v, nil
+	case *types.GeoPoint:
+		if v != nil {
+			point = *v
+		}
+		return point, nil
+	case string, []byte, types.JSONRaw:
+		err := point.Scan(cast.ToString(v))
+		return point, err
+	case map[string]any:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return point, err
+		}
+		err = point.UnmarshalJSON(encoded)
+		return point, err
+	default:
+		return point, errors.New("unsupported geo point value type")
+	}
+}
+
+// earthRadius is the mean Earth radius in meters used by the geoDistance() calculations.
+const earthRadius = 6371008.8
+
+// GeoDistance returns the great-circle distance in meters between
+// the provided geo points using the haversine formula.
+func GeoDistance(a, b types.GeoPoint) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := (b.Lat - a.Lat) * math.Pi / 180
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
@@ -0,0 +1,224 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestGeoPointFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeGeoPoint)
+}
+
+func TestGeoPointFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.GeoPointField{}
+
+	expected := `JSON DEFAULT '{"lat":0,"lon":0}' NOT NULL`
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestGeoPointFieldPrepareValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.GeoPointField{}
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	scenarios := []struct {
+		raw         any
+		expected    string
+		expectError bool
+	}{
+		{nil, `{"lat":0,"lon":0}`, false},
+		{"", `{"lat":0,"lon":0}`, false},
+		{"null", `{"lat":0,"lon":0}`, false},
+		{`{"lat":3.12,"lon":101.6}`, `{"lat":3.12,"lon":101.6}`, false},
+		{`{"lat":3.12,"lng":101.6}`, `{"lat":3.12,"lon":101.6}`, false},
+		{`{"lat":"a"}`, `"{\"lat\":\"a\"}"`, true},
+		{[]byte(`{"lat":1,"lon":2}`), `{"lat":1,"lon":2}`, false},
+		{types.JSONRaw(`{"lat":1,"lon":2}`), `{"lat":1,"lon":2}`, false},
+		{types.GeoPoint{Lat: 1, Lon: 2}, `{"lat":1,"lon":2}`, false},
+		{&types.GeoPoint{Lat: 1, Lon: 2}, `{"lat":1,"lon":2}`, false},
+		{map[string]any{"lat": 1, "lon": 2}, `{"lat":1,"lon":2}`, false},
+		{123, `123`, true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			v, err := f.PrepareValue(record, s.raw)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr {
+				if _, ok := v.(types.GeoPoint); !ok {
+					t.Fatalf("Expected types.GeoPoint instance, got %T", v)
+				}
+			}
+
+			raw, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(raw) != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, raw)
+			}
+		})
+	}
+}
+
+func TestGeoPointFieldDriverValue(t *testing.T) {
+	scenarios := []struct {
+		raw         any
+		expected    string
+		expectError bool
+	}{
+		{nil, `{"lat":0,"lon":0}`, false},
+		{`{"lat":1.5,"lon":-2}`, `{"lat":1.5,"lon":-2}`, false},
+		{types.GeoPoint{Lat: 1, Lon: 2}, `{"lat":1,"lon":2}`, false},
+		{"invalid", "", true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			f := &core.GeoPointField{Name: "test"}
+
+			record := core.NewRecord(core.NewBaseCollection("test"))
+			record.SetRaw(f.GetName(), s.raw)
+
+			v, err := f.DriverValue(record)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if v != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestGeoPointFieldValidateValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name        string
+		field       *core.GeoPointField
+		raw         any
+		expectError bool
+	}{
+		{
+			"invalid raw value",
+			&core.GeoPointField{Name: "test"},
+			`{"lat":1,"lon":2}`,
+			true,
+		},
+		{
+			"zero field value (not required)",
+			&core.GeoPointField{Name: "test"},
+			types.GeoPoint{},
+			false,
+		},
+		{
+			"zero field value (required)",
+			&core.GeoPointField{Name: "test", Required: true},
+			types.GeoPoint{},
+			true,
+		},
+		{
+			"latitude out of range",
+			&core.GeoPointField{Name: "test"},
+			types.GeoPoint{Lat: 90.1, Lon: 1},
+			true,
+		},
+		{
+			"longitude out of range",
+			&core.GeoPointField{Name: "test"},
+			types.GeoPoint{Lat: 1, Lon: -180.1},
+			true,
+		},
+		{
+			"NaN latitude",
+			&core.GeoPointField{Name: "test"},
+			types.GeoPoint{Lat: math.NaN(), Lon: 1},
+			true,
+		},
+		{
+			"valid point (required)",
+			&core.GeoPointField{Name: "test", Required: true},
+			types.GeoPoint{Lat: -90, Lon: 180},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			record := core.NewRecord(collection)
+			record.SetRaw("test", s.raw)
+
+			err := s.field.ValidateValue(context.Background(), app, record)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestGeoPointFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeGeoPoint)
+	testDefaultFieldNameValidation(t, core.FieldTypeGeoPoint)
+}
+
+func TestGeoDistance(t *testing.T) {
+	scenarios := []struct {
+		a        types.GeoPoint
+		b        types.GeoPoint
+		expected float64 // in meters
+	}{
+		{types.GeoPoint{}, types.GeoPoint{}, 0},
+		{types.GeoPoint{Lat: 3.12, Lon: 101.6}, types.GeoPoint{Lat: 3.12, Lon: 101.6}, 0},
+		// 1 degree along the equator
+		{types.GeoPoint{Lat: 0, Lon: 0}, types.GeoPoint{Lat: 0, Lon: 1}, 111195},
+		// Kuala Lumpur - Singapore
+		{types.GeoPoint{Lat: 3.139, Lon: 101.6869}, types.GeoPoint{Lat: 1.3521, Lon: 103.8198}, 309000},
+		// antipodes
+		{types.GeoPoint{Lat: 0, Lon: 0}, types.GeoPoint{Lat: 0, Lon: 180}, 20015115},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			d := core.GeoDistance(s.a, s.b)
+
+			// 0.5% tolerance
+			if math.Abs(d-s.expected) > s.expected*0.005+0.001 {
+				t.Fatalf("Expected ~%v, got %v", s.expected, d)
+			}
+		})
+	}
+}
@@ -25,6 +25,7 @@ const DefaultJSONFieldMaxSize int64 = 5 << 20
 var (
 	_ Field                 = (*JSONField)(nil)
 	_ MaxBodySizeCalculator = (*JSONField)(nil)
+	_ DefaultValueGetter    = (*JSONField)(nil)
 )
 
 // JSONField defines "json" type field for storing any serialized JSON value.
@@ -59,6 +60,11 @@ type JSONField struct {
 	// Required will require the field value to be non-empty JSON value
 	// (aka. not "null", `""`, "[]", "{}").
 	Required bool `form:"required" json:"required"`
+
+	// DefaultValue specifies an optional default value expression that is applied
+	// on record create API request when the field value is not submitted
+	// (see [ResolveFieldDefaultValue] for the supported expressions).
+	DefaultValue string `form:"defaultValue" json:"defaultValue"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -106,6 +112,11 @@ func (f *JSONField) SetHidden(hidden bool) {
 	f.Hidden = hidden
 }
 
+// GetDefaultValue implements [DefaultValueGetter] interface method.
+func (f *JSONField) GetDefaultValue() string {
+	return f.DefaultValue
+}
+
 // ColumnType implements [Field.ColumnType] interface method.
 func (f *JSONField) ColumnType(app App) string {
 	return "JSON DEFAULT NULL"
@@ -182,6 +193,7 @@ func (f *JSONField) ValidateSettings(ctx context.Context, app App, collection *C
 		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
 		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
 		validation.Field(&f.MaxSize, validation.Min(0)),
+		validation.Field(&f.DefaultValue, validation.By(checkDefaultValueExpr)),
 	)
 }
 
@@ -19,8 +19,9 @@ func init() {
 const FieldTypeNumber = "number"
 
 var (
-	_ Field        = (*NumberField)(nil)
-	_ SetterFinder = (*NumberField)(nil)
+	_ Field              = (*NumberField)(nil)
+	_ SetterFinder       = (*NumberField)(nil)
+	_ DefaultValueGetter = (*NumberField)(nil)
 )
 
 // NumberField defines "number" type field for storing numeric (float64) value.
@@ -69,6 +70,11 @@ type NumberField struct {
 
 	// Required will require the field value to be non-zero.
 	Required bool `form:"required" json:"required"`
+
+	// DefaultValue specifies an optional default value expression that is applied
+	// on record create API request when the field value is not submitted
+	// (see [ResolveFieldDefaultValue] for the supported expressions).
+	DefaultValue string `form:"defaultValue" json:"defaultValue"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -116,6 +122,11 @@ func (f *NumberField) SetHidden(hidden bool) {
 	f.Hidden = hidden
 }
 
+// GetDefaultValue implements [DefaultValueGetter] interface method.
+func (f *NumberField) GetDefaultValue() string {
+	return f.DefaultValue
+}
+
 // ColumnType implements [Field.ColumnType] interface method.
 func (f *NumberField) ColumnType(app App) string {
 	return "NUMERIC DEFAULT 0 NOT NULL"
@@ -175,6 +186,7 @@ func (f *NumberField) ValidateSettings(ctx context.Context, app App, collection
 		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
 		validation.Field(&f.Min, validation.By(f.checkOnlyInt)),
 		validation.Field(&f.Max, maxRules...),
+		validation.Field(&f.DefaultValue, validation.By(checkDefaultValueExpr)),
 	)
 }
 
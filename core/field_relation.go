@@ -19,10 +19,11 @@ func init() {
 const FieldTypeRelation = "relation"
 
 var (
-	_ Field        = (*RelationField)(nil)
-	_ MultiValuer  = (*RelationField)(nil)
-	_ DriverValuer = (*RelationField)(nil)
-	_ SetterFinder = (*RelationField)(nil)
+	_ Field              = (*RelationField)(nil)
+	_ MultiValuer        = (*RelationField)(nil)
+	_ DriverValuer       = (*RelationField)(nil)
+	_ SetterFinder       = (*RelationField)(nil)
+	_ DefaultValueGetter = (*RelationField)(nil)
 )
 
 // RelationField defines "relation" type field for storing single or
@@ -95,6 +96,31 @@ type RelationField struct {
 
 	// Required will require the field value to be non-empty.
 	Required bool `form:"required" json:"required"`
+
+	// DefaultValue specifies an optional default value expression that is applied
+	// on record create API request when the field value is not submitted
+	// (see [ResolveFieldDefaultValue] for the supported expressions).
+	DefaultValue string `form:"defaultValue" json:"defaultValue"`
+
+	// AuthOnCreate automatically sets the field value to the request auth
+	// record id on record create API request (aka. "createdBy").
+	//
+	// The submitted field value from non-superusers is always ignored
+	// and the field is left empty if the request auth record is not
+	// from the related collection.
+	//
+	// Requires single relation to an auth collection.
+	AuthOnCreate bool `form:"authOnCreate" json:"authOnCreate,omitempty"`
+
+	// AuthOnUpdate automatically sets the field value to the request auth
+	// record id on record create and update API requests (aka. "updatedBy").
+	//
+	// The submitted field value from non-superusers is always ignored
+	// and the field is left unchanged if the request auth record is not
+	// from the related collection.
+	//
+	// Requires single relation to an auth collection.
+	AuthOnUpdate bool `form:"authOnUpdate" json:"authOnUpdate,omitempty"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -142,6 +168,11 @@ func (f *RelationField) SetHidden(hidden bool) {
 	f.Hidden = hidden
 }
 
+// GetDefaultValue implements [DefaultValueGetter] interface method.
+func (f *RelationField) GetDefaultValue() string {
+	return f.DefaultValue
+}
+
 // IsMultiple implements [MultiValuer] interface and checks whether the
 // current field options support multiple values.
 func (f *RelationField) IsMultiple() bool {
@@ -240,9 +271,38 @@ func (f *RelationField) ValidateSettings(ctx context.Context, app App, collectio
 		validation.Field(&f.CollectionId, validation.Required, validation.By(f.checkCollectionId(app, collection))),
 		validation.Field(&f.MinSelect, validation.Min(0)),
 		validation.Field(&f.MaxSelect, validation.When(f.MinSelect > 0, validation.Required), validation.Min(f.MinSelect)),
+		validation.Field(&f.AuthOnCreate, validation.By(f.checkAuthOption(app))),
+		validation.Field(&f.AuthOnUpdate, validation.By(f.checkAuthOption(app))),
+		validation.Field(&f.DefaultValue, validation.By(checkDefaultValueExpr)),
 	)
 }
 
+func (f *RelationField) checkAuthOption(app App) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(bool)
+		if !v {
+			return nil // nothing to check
+		}
+
+		if f.IsMultiple() {
+			return validation.NewError(
+				"validation_field_relation_auth_multiple",
+				"The auto auth option requires single relation field (max select <= 1).",
+			)
+		}
+
+		relCollection, _ := app.FindCachedCollectionByNameOrId(f.CollectionId)
+		if relCollection != nil && !relCollection.IsAuth() {
+			return validation.NewError(
+				"validation_field_relation_auth_non_auth_collection",
+				"The auto auth option requires relation to an auth collection.",
+			)
+		}
+
+		return nil
+	}
+}
+
 func (f *RelationField) checkCollectionId(app App, collection *Collection) validation.RuleFunc {
 	return func(value any) error {
 		v, _ := value.(string)
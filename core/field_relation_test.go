@@ -394,6 +394,46 @@ func TestRelationFieldValidateSettings(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"auth options with non-auth collection",
+			func(col *core.Collection) *core.RelationField {
+				return &core.RelationField{
+					Id:           "test",
+					Name:         "test",
+					CollectionId: demo1.Id,
+					AuthOnCreate: true,
+					AuthOnUpdate: true,
+				}
+			},
+			[]string{"authOnCreate", "authOnUpdate"},
+		},
+		{
+			"auth options with multiple relation",
+			func(col *core.Collection) *core.RelationField {
+				return &core.RelationField{
+					Id:           "test",
+					Name:         "test",
+					CollectionId: "_pb_users_auth_",
+					MaxSelect:    2,
+					AuthOnCreate: true,
+					AuthOnUpdate: true,
+				}
+			},
+			[]string{"authOnCreate", "authOnUpdate"},
+		},
+		{
+			"auth options with single auth relation",
+			func(col *core.Collection) *core.RelationField {
+				return &core.RelationField{
+					Id:           "test",
+					Name:         "test",
+					CollectionId: "_pb_users_auth_",
+					AuthOnCreate: true,
+					AuthOnUpdate: true,
+				}
+			},
+			[]string{},
+		},
 		{
 			"base->view",
 			func(col *core.Collection) *core.RelationField {
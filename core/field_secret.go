@@ -0,0 +1,379 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypeSecret] = func() Field {
+		return &SecretField{}
+	}
+}
+
+const FieldTypeSecret = "secret"
+
+// SecretFieldMask is the placeholder returned in place of the non-empty secret field values.
+const SecretFieldMask = "********"
+
+// ErrMissingSecretFieldKey is returned when trying to encrypt or decrypt
+// a secret field value without a valid 32 characters app encryption key.
+var ErrMissingSecretFieldKey = errors.New("secret fields require a 32 characters app encryption key (see the --encryptionEnv flag)")
+
+var (
+	_ Field             = (*SecretField)(nil)
+	_ SetterFinder      = (*SecretField)(nil)
+	_ DriverValuer      = (*SecretField)(nil)
+	_ RecordInterceptor = (*SecretField)(nil)
+)
+
+// SecretField defines "secret" type field for storing sensitive
+// strings (e.g. API tokens) encrypted with the app encryption key.
+//
+// The field value is always serialized as [SecretFieldMask] (or empty string if not set)
+// and the plain value could be retrieved only with [SecretField.Reveal].
+//
+// Setting the [SecretFieldMask] as field value is ignored so that
+// the masked placeholder can be safely submitted back with the other record fields.
+type SecretField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Max specifies the maximum allowed plain string characters.
+	//
+	// If zero, a default limit of 5000 is applied.
+	Max int `form:"max" json:"max"`
+
+	// OwnerField specifies an optional name of a single auth relation field
+	// which record(s) are allowed to reveal the field value (in addition to the superusers).
+	//
+	// For auth collections the record itself is always considered an owner.
+	OwnerField string `form:"ownerField" json:"ownerField"`
+
+	// Required will require the field value to be non-empty string.
+	Required bool `form:"required" json:"required"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *SecretField) Type() string {
+	return FieldTypeSecret
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *SecretField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *SecretField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *SecretField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *SecretField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *SecretField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *SecretField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *SecretField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *SecretField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *SecretField) ColumnType(app App) string {
+	return "TEXT DEFAULT '' NOT NULL"
+}
+
+// DriverValue implements the [DriverValuer] interface.
+func (f *SecretField) DriverValue(record *Record) (driver.Value, error) {
+	sv := f.getSecretValue(record)
+	return sv.Encrypted, sv.LastError
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+func (f *SecretField) PrepareValue(record *Record, raw any) (any, error) {
+	return &SecretFieldValue{
+		Encrypted: cast.ToString(raw),
+	}, nil
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *SecretField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	sv, ok := record.GetRaw(f.Name).(*SecretFieldValue)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	if sv.LastError != nil {
+		return sv.LastError
+	}
+
+	if f.Required && sv.IsEmpty() {
+		return validation.ErrRequired
+	}
+
+	if sv.Plain == "" {
+		return nil // nothing to check
+	}
+
+	maxLength := f.Max
+	if maxLength <= 0 {
+		maxLength = 5000
+	}
+	if len([]rune(sv.Plain)) > maxLength {
+		return validation.NewError("validation_max_text_constraint", "Must be less than {{.max}} character(s)").
+			SetParams(map[string]any{"max": maxLength})
+	}
+
+	if _, err := secretFieldKey(app); err != nil {
+		return validation.NewError("validation_missing_encryption_key", "Missing or invalid app encryption key.")
+	}
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *SecretField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(&f.Max, validation.Min(0)),
+		validation.Field(&f.OwnerField, validation.By(f.checkOwnerField(app, collection))),
+	)
+}
+
+func (f *SecretField) checkOwnerField(app App, collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+		if v == "" {
+			return nil // nothing to check
+		}
+
+		relField, ok := collection.Fields.GetByName(v).(*RelationField)
+		if !ok {
+			return validation.NewError("validation_invalid_owner_field", "The owner field must be an existing relation field.")
+		}
+
+		relCollection, err := app.FindCachedCollectionByNameOrId(relField.CollectionId)
+		if err != nil || !relCollection.IsAuth() {
+			return validation.NewError("validation_invalid_owner_field", "The owner field must be a relation to an auth collection.")
+		}
+
+		return nil
+	}
+}
+
+// IsOwner reports whether the provided auth record is allowed to reveal
+// the record field value as its owner (see [SecretField.OwnerField]).
+func (f *SecretField) IsOwner(record *Record, auth *Record) bool {
+	if auth == nil {
+		return false
+	}
+
+	if record.Collection().IsAuth() && record.Collection().Id == auth.Collection().Id && record.Id == auth.Id {
+		return true
+	}
+
+	if f.OwnerField == "" {
+		return false
+	}
+
+	relField, ok := record.Collection().Fields.GetByName(f.OwnerField).(*RelationField)
+	if !ok || relField.CollectionId != auth.Collection().Id {
+		return false
+	}
+
+	for _, id := range record.GetStringSlice(f.OwnerField) {
+		if id == auth.Id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reveal decrypts and returns the plain record field value.
+func (f *SecretField) Reveal(app App, record *Record) (string, error) {
+	sv := f.getSecretValue(record)
+
+	if sv.LastError != nil {
+		return "", sv.LastError
+	}
+
+	if sv.Plain != "" || sv.Encrypted == "" {
+		return sv.Plain, nil
+	}
+
+	key, err := secretFieldKey(app)
+	if err != nil {
+		return "", err
+	}
+
+	plain, err := security.Decrypt(sv.Encrypted, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %q value: %w", f.Name, err)
+	}
+
+	return string(plain), nil
+}
+
+// Intercept implements the [RecordInterceptor] interface.
+func (f *SecretField) Intercept(
+	ctx context.Context,
+	app App,
+	record *Record,
+	actionName string,
+	actionFunc func() error,
+) error {
+	switch actionName {
+	case InterceptorActionCreateExecute, InterceptorActionUpdateExecute:
+		// encrypt the new plain value right before persisting it
+		sv := f.getSecretValue(record)
+		if sv.Plain != "" && sv.Encrypted == "" {
+			key, err := secretFieldKey(app)
+			if err != nil {
+				return err
+			}
+
+			sv.Encrypted, err = security.Encrypt([]byte(sv.Plain), key)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt %q value: %w", f.Name, err)
+			}
+		}
+	case InterceptorActionAfterCreate, InterceptorActionAfterUpdate:
+		// unset the plain field value after successful create/update
+		f.getSecretValue(record).Plain = ""
+	}
+
+	return actionFunc()
+}
+
+// FindSetter implements the [SetterFinder] interface.
+func (f *SecretField) FindSetter(key string) SetterFunc {
+	switch key {
+	case f.Name:
+		return f.setValue
+	default:
+		return nil
+	}
+}
+
+func (f *SecretField) setValue(record *Record, raw any) {
+	switch v := raw.(type) {
+	case *SecretFieldValue:
+		record.SetRaw(f.Name, v)
+	case SecretFieldValue:
+		record.SetRaw(f.Name, &v)
+	default:
+		plain := cast.ToString(raw)
+		if plain == SecretFieldMask {
+			return // the masked placeholder was submitted back
+		}
+
+		record.SetRaw(f.Name, &SecretFieldValue{Plain: plain})
+	}
+}
+
+func (f *SecretField) getSecretValue(record *Record) *SecretFieldValue {
+	if sv, ok := record.GetRaw(f.Name).(*SecretFieldValue); ok {
+		return sv
+	}
+
+	sv := &SecretFieldValue{}
+	record.SetRaw(f.Name, sv)
+
+	return sv
+}
+
+func secretFieldKey(app App) (string, error) {
+	key := os.Getenv(app.EncryptionEnv())
+	if len(key) != 32 {
+		return "", ErrMissingSecretFieldKey
+	}
+
+	return key, nil
+}
+
+// -------------------------------------------------------------------
+
+var (
+	_ fmt.Stringer   = SecretFieldValue{}
+	_ json.Marshaler = SecretFieldValue{}
+)
+
+// SecretFieldValue defines the raw [SecretField] record value.
+type SecretFieldValue struct {
+	LastError error
+
+	// Encrypted is the encrypted db value.
+	Encrypted string
+
+	// Plain is the new plain value (it is available only until persisted).
+	Plain string
+}
+
+// IsEmpty reports whether the secret value is not set.
+func (sv SecretFieldValue) IsEmpty() bool {
+	return sv.Encrypted == "" && sv.Plain == ""
+}
+
+// String implements the [fmt.Stringer] interface and
+// returns the masked value (or empty string if not set).
+func (sv SecretFieldValue) String() string {
+	if sv.IsEmpty() {
+		return ""
+	}
+
+	return SecretFieldMask
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (sv SecretFieldValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sv.String())
+}
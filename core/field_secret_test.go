@@ -0,0 +1,470 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestSecretFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeSecret)
+}
+
+func TestSecretFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.SecretField{}
+
+	expected := "TEXT DEFAULT '' NOT NULL"
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestSecretFieldPrepareValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.SecretField{}
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	scenarios := []struct {
+		raw      any
+		expected string
+	}{
+		{"", ""},
+		{"test", "test"},
+		{123, "123"},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			v, err := f.PrepareValue(record, s.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sv, ok := v.(*core.SecretFieldValue)
+			if !ok {
+				t.Fatalf("Expected SecretFieldValue instance, got %T", v)
+			}
+
+			if sv.Encrypted != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, sv.Encrypted)
+			}
+		})
+	}
+}
+
+func TestSecretFieldValidateValue(t *testing.T) {
+	t.Setenv("pb_test_env", strings.Repeat("a", 32))
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	appWithoutKey := core.NewBaseApp(core.BaseAppConfig{
+		DataDir:       t.TempDir(),
+		EncryptionEnv: "pb_test_missing_secret_env",
+	})
+
+	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(&core.SecretField{Name: "test"})
+
+	scenarios := []struct {
+		name        string
+		app         core.App
+		field       *core.SecretField
+		record      func() *core.Record
+		expectError bool
+	}{
+		{
+			"invalid raw value",
+			app,
+			&core.SecretField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "abc")
+				return record
+			},
+			true,
+		},
+		{
+			"zero field value (not required)",
+			app,
+			&core.SecretField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", "")
+				return record
+			},
+			false,
+		},
+		{
+			"zero field value (required)",
+			app,
+			&core.SecretField{Name: "test", Required: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", "")
+				return record
+			},
+			true,
+		},
+		{
+			"encrypted value (required)",
+			app,
+			&core.SecretField{Name: "test", Required: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", &core.SecretFieldValue{Encrypted: "abc"})
+				return record
+			},
+			false,
+		},
+		{
+			"plain value > default max",
+			app,
+			&core.SecretField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", strings.Repeat("a", 5001))
+				return record
+			},
+			true,
+		},
+		{
+			"plain value > explicit max",
+			app,
+			&core.SecretField{Name: "test", Max: 2},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", "abc")
+				return record
+			},
+			true,
+		},
+		{
+			"plain value without encryption key",
+			appWithoutKey,
+			&core.SecretField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", "abc")
+				return record
+			},
+			true,
+		},
+		{
+			"valid plain value",
+			app,
+			&core.SecretField{Name: "test", Required: true, Max: 3},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", "abc")
+				return record
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := s.field.ValidateValue(context.Background(), s.app, s.record())
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestSecretFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeSecret)
+	testDefaultFieldNameValidation(t, core.FieldTypeSecret)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		field        func() *core.SecretField
+		expectErrors []string
+	}{
+		{
+			"zero minimal",
+			func() *core.SecretField {
+				return &core.SecretField{Id: "test", Name: "test"}
+			},
+			[]string{},
+		},
+		{
+			"negative max",
+			func() *core.SecretField {
+				return &core.SecretField{Id: "test", Name: "test", Max: -1}
+			},
+			[]string{"max"},
+		},
+		{
+			"missing owner field",
+			func() *core.SecretField {
+				return &core.SecretField{Id: "test", Name: "test", OwnerField: "missing"}
+			},
+			[]string{"ownerField"},
+		},
+		{
+			"non-relation owner field",
+			func() *core.SecretField {
+				return &core.SecretField{Id: "test", Name: "test", OwnerField: "title"}
+			},
+			[]string{"ownerField"},
+		},
+		{
+			"non-auth relation owner field",
+			func() *core.SecretField {
+				return &core.SecretField{Id: "test", Name: "test", OwnerField: "rel_demo1"}
+			},
+			[]string{"ownerField"},
+		},
+		{
+			"auth relation owner field",
+			func() *core.SecretField {
+				return &core.SecretField{Id: "test", Name: "test", OwnerField: "rel_users"}
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			field := s.field()
+
+			collection := core.NewBaseCollection("test_collection")
+			collection.Fields.Add(
+				&core.TextField{Name: "title"},
+				&core.RelationField{Name: "rel_demo1", CollectionId: demo1.Id},
+				&core.RelationField{Name: "rel_users", CollectionId: users.Id},
+				field,
+			)
+
+			errs := field.ValidateSettings(context.Background(), app, collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestSecretFieldFindSetter(t *testing.T) {
+	field := &core.SecretField{Name: "test"}
+
+	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(field)
+
+	t.Run("no match", func(t *testing.T) {
+		if f := field.FindSetter("abc"); f != nil {
+			t.Fatal("Expected nil setter")
+		}
+	})
+
+	t.Run("plain value", func(t *testing.T) {
+		record := core.NewRecord(collection)
+		record.Set("test", "abc")
+
+		sv, ok := record.GetRaw("test").(*core.SecretFieldValue)
+		if !ok {
+			t.Fatalf("Expected SecretFieldValue, got %T", record.GetRaw("test"))
+		}
+
+		if sv.Plain != "abc" || sv.Encrypted != "" {
+			t.Fatalf("Unexpected secret value %#v", sv)
+		}
+	})
+
+	t.Run("masked value", func(t *testing.T) {
+		existing := &core.SecretFieldValue{Encrypted: "abc"}
+
+		record := core.NewRecord(collection)
+		record.SetRaw("test", existing)
+		record.Set("test", core.SecretFieldMask)
+
+		if v := record.GetRaw("test"); v != existing {
+			t.Fatalf("Expected the masked value to be ignored, got %#v", v)
+		}
+	})
+
+	t.Run("SecretFieldValue", func(t *testing.T) {
+		existing := &core.SecretFieldValue{Encrypted: "abc"}
+
+		record := core.NewRecord(collection)
+		record.Set("test", existing)
+
+		if v := record.GetRaw("test"); v != existing {
+			t.Fatalf("Expected the secret value to be set as it is, got %#v", v)
+		}
+	})
+}
+
+func TestSecretFieldValueSerialization(t *testing.T) {
+	scenarios := []struct {
+		value    core.SecretFieldValue
+		expected string
+	}{
+		{core.SecretFieldValue{}, ""},
+		{core.SecretFieldValue{Plain: "abc"}, core.SecretFieldMask},
+		{core.SecretFieldValue{Encrypted: "abc"}, core.SecretFieldMask},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.value), func(t *testing.T) {
+			if v := s.value.String(); v != s.expected {
+				t.Fatalf("Expected String() %q, got %q", s.expected, v)
+			}
+
+			raw, err := json.Marshal(s.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v := string(raw); v != `"`+s.expected+`"` {
+				t.Fatalf("Expected JSON %q, got %q", `"`+s.expected+`"`, v)
+			}
+		})
+	}
+}
+
+func TestSecretFieldEncryptAndReveal(t *testing.T) {
+	t.Setenv("pb_test_env", strings.Repeat("a", 32))
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("secrets_test")
+	collection.Fields.Add(&core.SecretField{Name: "token"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	field := collection.Fields.GetByName("token").(*core.SecretField)
+
+	record := core.NewRecord(collection)
+	record.Set("token", "my_secret_token")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// check the stored db value
+	var stored string
+	err := app.DB().Select("token").From(collection.Name).Where(dbx.HashExp{"id": record.Id}).Row(&stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored == "" || strings.Contains(stored, "my_secret_token") {
+		t.Fatalf("Expected the stored value to be encrypted, got %q", stored)
+	}
+
+	fresh, err := app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := fresh.GetString("token"); v != core.SecretFieldMask {
+		t.Fatalf("Expected GetString to return the mask, got %q", v)
+	}
+
+	raw, err := json.Marshal(fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "my_secret_token") || !strings.Contains(string(raw), `"token":"`+core.SecretFieldMask+`"`) {
+		t.Fatalf("Expected masked JSON value, got %s", raw)
+	}
+
+	revealed, err := field.Reveal(app, fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revealed != "my_secret_token" {
+		t.Fatalf("Expected revealed value %q, got %q", "my_secret_token", revealed)
+	}
+
+	// resubmitting the mask shouldn't change the stored value
+	fresh.Load(map[string]any{"token": core.SecretFieldMask})
+	if err := app.Save(fresh); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh, err = app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revealed, err = field.Reveal(app, fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revealed != "my_secret_token" {
+		t.Fatalf("Expected revealed value after mask resubmit %q, got %q", "my_secret_token", revealed)
+	}
+}
+
+func TestSecretFieldIsOwner(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherUser, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(&core.RelationField{Name: "owner", CollectionId: user.Collection().Id})
+
+	record := core.NewRecord(collection)
+	record.Set("owner", user.Id)
+
+	scenarios := []struct {
+		name     string
+		field    *core.SecretField
+		record   *core.Record
+		auth     *core.Record
+		expected bool
+	}{
+		{"nil auth", &core.SecretField{OwnerField: "owner"}, record, nil, false},
+		{"without owner field", &core.SecretField{}, record, user, false},
+		{"owner field match", &core.SecretField{OwnerField: "owner"}, record, user, true},
+		{"owner field mismatch", &core.SecretField{OwnerField: "owner"}, record, otherUser, false},
+		{"auth record itself", &core.SecretField{}, user, user, true},
+		{"other auth record", &core.SecretField{}, user, otherUser, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if v := s.field.IsOwner(s.record, s.auth); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
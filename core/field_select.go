@@ -19,10 +19,11 @@ func init() {
 const FieldTypeSelect = "select"
 
 var (
-	_ Field        = (*SelectField)(nil)
-	_ MultiValuer  = (*SelectField)(nil)
-	_ DriverValuer = (*SelectField)(nil)
-	_ SetterFinder = (*SelectField)(nil)
+	_ Field              = (*SelectField)(nil)
+	_ MultiValuer        = (*SelectField)(nil)
+	_ DriverValuer       = (*SelectField)(nil)
+	_ SetterFinder       = (*SelectField)(nil)
+	_ DefaultValueGetter = (*SelectField)(nil)
 )
 
 // SelectField defines "select" type field for storing single or
@@ -82,6 +83,11 @@ type SelectField struct {
 
 	// Required will require the field value to be non-empty.
 	Required bool `form:"required" json:"required"`
+
+	// DefaultValue specifies an optional default value expression that is applied
+	// on record create API request when the field value is not submitted
+	// (see [ResolveFieldDefaultValue] for the supported expressions).
+	DefaultValue string `form:"defaultValue" json:"defaultValue"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -129,6 +135,11 @@ func (f *SelectField) SetHidden(hidden bool) {
 	f.Hidden = hidden
 }
 
+// GetDefaultValue implements [DefaultValueGetter] interface method.
+func (f *SelectField) GetDefaultValue() string {
+	return f.DefaultValue
+}
+
 // IsMultiple implements [MultiValuer] interface and checks whether the
 // current field options support multiple values.
 func (f *SelectField) IsMultiple() bool {
@@ -218,6 +229,7 @@ func (f *SelectField) ValidateSettings(ctx context.Context, app App, collection
 		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
 		validation.Field(&f.Values, validation.Required),
 		validation.Field(&f.MaxSelect, validation.Min(0), validation.Max(max)),
+		validation.Field(&f.DefaultValue, validation.By(checkDefaultValueExpr)),
 	)
 }
 
@@ -6,11 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/inflector"
 	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/spf13/cast"
 )
@@ -26,9 +28,10 @@ const FieldTypeText = "text"
 const autogenerateModifier = ":autogenerate"
 
 var (
-	_ Field             = (*TextField)(nil)
-	_ SetterFinder      = (*TextField)(nil)
-	_ RecordInterceptor = (*TextField)(nil)
+	_ Field              = (*TextField)(nil)
+	_ SetterFinder       = (*TextField)(nil)
+	_ RecordInterceptor  = (*TextField)(nil)
+	_ DefaultValueGetter = (*TextField)(nil)
 )
 
 // TextField defines "text" type field for storing any string value.
@@ -37,10 +40,13 @@ var (
 //
 // The following additional setter keys are available:
 //
-// - "fieldName:autogenerate" - autogenerate field value if AutogeneratePattern is set. For example:
+// - "fieldName:autogenerate" - autogenerate field value if AutogenerateStrategy or AutogeneratePattern is set. For example:
 //
 //	record.Set("slug:autogenerate", "") // [random value]
 //	record.Set("slug:autogenerate", "abc-") // abc-[random value]
+//
+// If SlugSource is set, the field value is automatically generated as unique
+// URL-safe slug from the source field value (see [TextField.SlugSource]).
 type TextField struct {
 	// Name (required) is the unique name of the field.
 	Name string `form:"name" json:"name"`
@@ -84,9 +90,34 @@ type TextField struct {
 	// Note: the generated value still needs to satisfy min, max, pattern (if set)
 	AutogeneratePattern string `form:"autogeneratePattern" json:"autogeneratePattern"`
 
+	// AutogenerateStrategy specifies an optional [AutogenerateStrategies]
+	// registry generator name (eg. "uuidv7", "ulid", "snowflake") that will
+	// be used instead of AutogeneratePattern to generate the field value.
+	//
+	// Note: the generated value still needs to satisfy min, max, pattern (if set)
+	AutogenerateStrategy string `form:"autogenerateStrategy" json:"autogenerateStrategy,omitempty"`
+
 	// Required will require the field value to be non-empty string.
 	Required bool `form:"required" json:"required"`
 
+	// DefaultValue specifies an optional default value expression that is applied
+	// on record create API request when the field value is not submitted
+	// (see [ResolveFieldDefaultValue] for the supported expressions).
+	DefaultValue string `form:"defaultValue" json:"defaultValue"`
+
+	// SlugSource specifies an optional name of another collection field
+	// which value will be used to generate a unique URL-safe slug
+	// (eg. "Hello World!" -> "hello-world") when the field value is empty
+	// or when the source value changes.
+	//
+	// In case of a collision with an existing record slug,
+	// a numeric suffix is appended (eg. "hello-world-2").
+	SlugSource string `form:"slugSource" json:"slugSource,omitempty"`
+
+	// SlugImmutable prevents the regeneration and the change of the
+	// field value once it is set (applicable only when SlugSource is set).
+	SlugImmutable bool `form:"slugImmutable" json:"slugImmutable,omitempty"`
+
 	// PrimaryKey will mark the field as primary key.
 	//
 	// A single collection can have only 1 field marked as primary key.
@@ -138,6 +169,11 @@ func (f *TextField) SetHidden(hidden bool) {
 	f.Hidden = hidden
 }
 
+// GetDefaultValue implements [DefaultValueGetter] interface method.
+func (f *TextField) GetDefaultValue() string {
+	return f.DefaultValue
+}
+
 // ColumnType implements [Field.ColumnType] interface method.
 func (f *TextField) ColumnType(app App) string {
 	if f.PrimaryKey {
@@ -203,6 +239,13 @@ func (f *TextField) ValidateValue(ctx context.Context, app App, record *Record)
 		}
 	}
 
+	if f.SlugSource != "" && f.SlugImmutable && !record.IsNew() {
+		oldVal := record.Original().GetString(f.Name)
+		if oldVal != "" && oldVal != newVal {
+			return validation.NewError("validation_slug_immutable", "The slug cannot be changed.")
+		}
+	}
+
 	return f.ValidatePlainValue(newVal)
 }
 
@@ -261,9 +304,31 @@ func (f *TextField) ValidateSettings(ctx context.Context, app App, collection *C
 		validation.Field(&f.Hidden, validation.When(f.PrimaryKey, validation.Empty)),
 		validation.Field(&f.Required, validation.When(f.PrimaryKey, validation.Required)),
 		validation.Field(&f.AutogeneratePattern, validation.By(validators.IsRegex), validation.By(f.checkAutogeneratePattern)),
+		validation.Field(&f.AutogenerateStrategy, validation.By(f.checkAutogenerateStrategy)),
+		validation.Field(&f.DefaultValue, validation.By(checkDefaultValueExpr)),
+		validation.Field(&f.SlugSource,
+			validation.When(f.PrimaryKey, validation.Empty),
+			validation.By(f.checkSlugSource(collection)),
+		),
 	)
 }
 
+func (f *TextField) checkSlugSource(collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+		if v == "" {
+			return nil // nothing to check
+		}
+
+		source := collection.Fields.GetByName(v)
+		if source == nil || source.GetId() == f.Id {
+			return validation.NewError("validation_invalid_slug_source", "The slug source must be another existing collection field.")
+		}
+
+		return nil
+	}
+}
+
 func (f *TextField) checkOtherFieldsForPK(collection *Collection) validation.RuleFunc {
 	return func(value any) error {
 		v, _ := value.(bool)
@@ -286,12 +351,41 @@ func (f *TextField) checkOtherFieldsForPK(collection *Collection) validation.Rul
 	}
 }
 
-func (f *TextField) checkAutogeneratePattern(value any) error {
+func (f *TextField) checkAutogenerateStrategy(value any) error {
 	v, _ := value.(string)
 	if v == "" {
 		return nil // nothing to check
 	}
 
+	strategy, ok := AutogenerateStrategies[v]
+	if !ok || strategy.Generate == nil {
+		return validation.NewError("validation_unknown_autogenerate_strategy", "Unknown autogenerate strategy.")
+	}
+
+	// run 10 tests to check for conflicts with the other field validators
+	for i := 0; i < 10; i++ {
+		generated, err := strategy.Generate()
+		if err != nil {
+			return validation.NewError("validation_invalid_autogenerate_strategy", err.Error())
+		}
+
+		if err := f.ValidatePlainValue(generated); err != nil {
+			return validation.NewError(
+				"validation_invalid_autogenerate_strategy_value",
+				fmt.Sprintf("The selected autogenerate strategy could produce invalid field values, ex.: %q", generated),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (f *TextField) checkAutogeneratePattern(value any) error {
+	v, _ := value.(string)
+	if v == "" || f.AutogenerateStrategy != "" {
+		return nil // nothing to check
+	}
+
 	// run 10 tests to check for conflicts with the other field validators
 	for i := 0; i < 10; i++ {
 		generated, err := security.RandomStringByRegex(v)
@@ -322,18 +416,117 @@ func (f *TextField) Intercept(
 	// set autogenerated value if missing for new records
 	switch actionName {
 	case InterceptorActionValidate, InterceptorActionCreate:
-		if f.AutogeneratePattern != "" && f.hasZeroValue(record) && record.IsNew() {
-			v, err := security.RandomStringByRegex(f.AutogeneratePattern)
+		if f.hasAutogenerate() && f.hasZeroValue(record) && record.IsNew() {
+			v, err := f.autogenerate()
 			if err != nil {
 				return fmt.Errorf("failed to autogenerate %q value: %w", f.Name, err)
 			}
 			record.SetRaw(f.Name, v)
 		}
+
+		if err := f.refreshSlug(app, record); err != nil {
+			return fmt.Errorf("failed to generate %q slug: %w", f.Name, err)
+		}
+	case InterceptorActionUpdate:
+		if err := f.refreshSlug(app, record); err != nil {
+			return fmt.Errorf("failed to generate %q slug: %w", f.Name, err)
+		}
 	}
 
 	return actionFunc()
 }
 
+// maxSlugAttempts is the max number of suffixed slug candidates
+// that are checked for uniqueness before giving up.
+const maxSlugAttempts = 100
+
+// refreshSlug (re)generates the record slug value from the SlugSource field value
+// if the current value is empty or when the source value has changed
+// (the latter is skipped for immutable slugs and explicitly changed slug values).
+func (f *TextField) refreshSlug(app App, record *Record) error {
+	if f.SlugSource == "" {
+		return nil
+	}
+
+	current := record.GetString(f.Name)
+
+	if current != "" {
+		if record.IsNew() || f.SlugImmutable {
+			return nil
+		}
+
+		original := record.Original()
+		if original.GetString(f.Name) != current ||
+			original.GetString(f.SlugSource) == record.GetString(f.SlugSource) {
+			return nil // explicitly changed slug or unchanged source
+		}
+	}
+
+	base := inflector.Slugify(record.GetString(f.SlugSource))
+	if base == "" {
+		return nil // nothing to generate
+	}
+
+	maxLength := f.Max
+	if maxLength <= 0 {
+		maxLength = 5000
+	}
+
+	for i := 1; i <= maxSlugAttempts; i++ {
+		suffix := ""
+		if i > 1 {
+			suffix = "-" + strconv.Itoa(i)
+		}
+
+		candidate := base
+		if len(candidate)+len(suffix) > maxLength {
+			candidate = strings.TrimRight(candidate[:max(0, maxLength-len(suffix))], "-")
+		}
+		candidate += suffix
+
+		if candidate == current {
+			return nil // the record already has this slug
+		}
+
+		var exists bool
+		err := app.RecordQuery(record.Collection()).
+			Select("(1)").
+			AndWhere(dbx.HashExp{f.Name: candidate}).
+			AndWhere(dbx.Not(dbx.HashExp{"id": record.Id})).
+			Limit(1).
+			Row(&exists)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		if !exists {
+			record.SetRaw(f.Name, candidate)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed to find a unique slug for %q after %d attempts", base, maxSlugAttempts)
+}
+
+func (f *TextField) hasAutogenerate() bool {
+	return f.AutogenerateStrategy != "" || f.AutogeneratePattern != ""
+}
+
+// autogenerate generates a new field value using the AutogenerateStrategy
+// generator (if set) or the AutogeneratePattern regex.
+func (f *TextField) autogenerate() (string, error) {
+	if f.AutogenerateStrategy != "" {
+		strategy, ok := AutogenerateStrategies[f.AutogenerateStrategy]
+		if !ok || strategy.Generate == nil {
+			return "", fmt.Errorf("unknown autogenerate strategy %q", f.AutogenerateStrategy)
+		}
+
+		return strategy.Generate()
+	}
+
+	return security.RandomStringByRegex(f.AutogeneratePattern)
+}
+
 func (f *TextField) hasZeroValue(record *Record) bool {
 	v, _ := record.GetRaw(f.Name).(string)
 	return v == ""
@@ -350,8 +543,8 @@ func (f *TextField) FindSetter(key string) SetterFunc {
 		return func(record *Record, raw any) {
 			v := cast.ToString(raw)
 
-			if f.AutogeneratePattern != "" {
-				generated, _ := security.RandomStringByRegex(f.AutogeneratePattern)
+			if f.hasAutogenerate() {
+				generated, _ := f.autogenerate()
 				v += generated
 			}
 
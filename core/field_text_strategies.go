@@ -0,0 +1,71 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/pocketbase/pocketbase/tools/idgen"
+)
+
+// Built-in [TextField.AutogenerateStrategy] values.
+const (
+	AutogenerateStrategyUUIDv7    = "uuidv7"
+	AutogenerateStrategyULID      = "ulid"
+	AutogenerateStrategySnowflake = "snowflake"
+)
+
+// AutogenerateStrategy defines a named [TextField] value generator.
+type AutogenerateStrategy struct {
+	// Generate generates a new unique field value.
+	Generate func() (string, error)
+
+	// Min, Max and Pattern are the text field settings that every
+	// generated value is guaranteed to satisfy.
+	//
+	// They are applied to the id field with [Collection.SetIdStrategy].
+	Min     int
+	Max     int
+	Pattern string
+}
+
+// AutogenerateStrategies is a registry with all available [TextField.AutogenerateStrategy] generators.
+//
+// The snowflake strategy is initialized with node id 0. If you run
+// multiple app instances writing to the same storage, register a new
+// generator with a unique node id for each of them, for example:
+//
+//	snowflake, _ := idgen.NewSnowflake(5)
+//	core.AutogenerateStrategies[core.AutogenerateStrategySnowflake] = core.AutogenerateStrategy{
+//		Generate: func() (string, error) { return snowflake.NextString(), nil },
+//		Min:      19,
+//		Max:      19,
+//		Pattern:  `^\d+$`,
+//	}
+var AutogenerateStrategies = map[string]AutogenerateStrategy{
+	AutogenerateStrategyUUIDv7: {
+		Generate: idgen.UUIDv7,
+		Min:      36,
+		Max:      36,
+		Pattern:  `^[0-9a-f\-]+$`,
+	},
+	AutogenerateStrategyULID: {
+		Generate: func() (string, error) {
+			id, err := idgen.ULID()
+
+			// lowercased for consistency with the default random ids
+			return strings.ToLower(id), err
+		},
+		Min:     26,
+		Max:     26,
+		Pattern: `^[a-z0-9]+$`,
+	},
+	AutogenerateStrategySnowflake: {
+		Generate: func() (string, error) {
+			return defaultSnowflake.NextString(), nil
+		},
+		Min:     19,
+		Max:     19,
+		Pattern: `^\d+$`,
+	},
+}
+
+var defaultSnowflake, _ = idgen.NewSnowflake(0)
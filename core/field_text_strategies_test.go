@@ -0,0 +1,98 @@
+package core_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestAutogenerateStrategies(t *testing.T) {
+	t.Parallel()
+
+	for name, strategy := range core.AutogenerateStrategies {
+		t.Run(name, func(t *testing.T) {
+			pattern := regexp.MustCompile(strategy.Pattern)
+
+			for i := 0; i < 10; i++ {
+				v, err := strategy.Generate()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(v) < strategy.Min || len(v) > strategy.Max {
+					t.Fatalf("Expected value length between %d and %d, got %q", strategy.Min, strategy.Max, v)
+				}
+
+				if !pattern.MatchString(v) {
+					t.Fatalf("Expected value matching %q, got %q", strategy.Pattern, v)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionSetIdStrategy(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := core.NewBaseCollection("test").SetIdStrategy("missing"); err == nil {
+		t.Fatal("Expected unknown strategy error")
+	}
+
+	if err := core.NewViewCollection("test").SetIdStrategy(core.AutogenerateStrategyULID); err == nil {
+		t.Fatal("Expected missing id field error")
+	}
+
+	scenarios := []struct {
+		strategy string
+		pattern  string
+	}{
+		{"", `^[a-z0-9]{15}$`},
+		{core.AutogenerateStrategyUUIDv7, `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[0-9a-f]{4}-[0-9a-f]{12}$`},
+		{core.AutogenerateStrategyULID, `^[0-9a-z]{26}$`},
+		{core.AutogenerateStrategySnowflake, `^\d{19}$`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.strategy, func(t *testing.T) {
+			collection := core.NewBaseCollection("test_" + s.strategy)
+			collection.Fields.Add(&core.TextField{Name: "title"})
+
+			if err := collection.SetIdStrategy(core.AutogenerateStrategySnowflake); err != nil {
+				t.Fatal(err)
+			}
+
+			// reassign to check that the previous strategy settings are replaced
+			if err := collection.SetIdStrategy(s.strategy); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := app.Save(collection); err != nil {
+				t.Fatal(err)
+			}
+
+			var lastId string
+			for i := 0; i < 3; i++ {
+				record := core.NewRecord(collection)
+				record.Set("title", "test")
+
+				if err := app.Save(record); err != nil {
+					t.Fatal(err)
+				}
+
+				if !regexp.MustCompile(s.pattern).MatchString(record.Id) {
+					t.Fatalf("Expected id matching %q, got %q", s.pattern, record.Id)
+				}
+
+				if lastId == record.Id {
+					t.Fatalf("Expected unique ids, got %q twice", record.Id)
+				}
+				lastId = record.Id
+			}
+		})
+	}
+}
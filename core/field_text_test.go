@@ -430,6 +430,89 @@ func TestTextFieldValidateSettings(t *testing.T) {
 			},
 			[]string{"autogeneratePattern"},
 		},
+		{
+			"unknown autogenerateStrategy",
+			func() *core.TextField {
+				return &core.TextField{
+					Id:                   "test2",
+					Name:                 "id",
+					AutogenerateStrategy: "missing",
+				}
+			},
+			[]string{"autogenerateStrategy"},
+		},
+		{
+			"valid autogenerateStrategy",
+			func() *core.TextField {
+				return &core.TextField{
+					Id:                   "test2",
+					Name:                 "id",
+					Max:                  26,
+					Pattern:              `^[a-z0-9]+$`,
+					AutogenerateStrategy: core.AutogenerateStrategyULID,
+				}
+			},
+			[]string{},
+		},
+		{
+			"conflicting max and autogenerateStrategy",
+			func() *core.TextField {
+				return &core.TextField{
+					Id:                   "test2",
+					Name:                 "id",
+					Max:                  15,
+					AutogenerateStrategy: core.AutogenerateStrategyULID,
+				}
+			},
+			[]string{"autogenerateStrategy"},
+		},
+		{
+			"autogenerateStrategy with conflicting autogeneratePattern",
+			func() *core.TextField {
+				return &core.TextField{
+					Id:                   "test2",
+					Name:                 "id",
+					Pattern:              `^\d+$`,
+					AutogeneratePattern:  `[a-z]+`,
+					AutogenerateStrategy: core.AutogenerateStrategySnowflake,
+				}
+			},
+			[]string{}, // the pattern is ignored
+		},
+		{
+			"nonexisting slugSource",
+			func() *core.TextField {
+				return &core.TextField{
+					Id:         "test2",
+					Name:       "slug",
+					SlugSource: "missing",
+				}
+			},
+			[]string{"slugSource"},
+		},
+		{
+			"self slugSource",
+			func() *core.TextField {
+				return &core.TextField{
+					Id:         "test2",
+					Name:       "slug",
+					SlugSource: "slug",
+				}
+			},
+			[]string{"slugSource"},
+		},
+		{
+			"valid slugSource",
+			func() *core.TextField {
+				return &core.TextField{
+					Id:            "test2",
+					Name:          "slug",
+					SlugSource:    "id",
+					SlugImmutable: true,
+				}
+			},
+			[]string{},
+		},
 	}
 
 	for _, s := range scenarios {
@@ -510,8 +593,22 @@ func TestTextFieldAutogenerate(t *testing.T) {
 			},
 			"",
 		},
+		{
+			"autogenerateStrategy",
+			core.InterceptorActionCreate,
+			&core.TextField{Name: "test", AutogeneratePattern: "abc", AutogenerateStrategy: "test"},
+			func() *core.Record {
+				return core.NewRecord(collection)
+			},
+			"strategy",
+		},
 	}
 
+	core.AutogenerateStrategies["test"] = core.AutogenerateStrategy{
+		Generate: func() (string, error) { return "strategy", nil },
+	}
+	defer delete(core.AutogenerateStrategies, "test")
+
 	for _, s := range scenarios {
 		t.Run(s.name, func(t *testing.T) {
 			actionCalls := 0
@@ -537,6 +634,103 @@ func TestTextFieldAutogenerate(t *testing.T) {
 	}
 }
 
+func TestTextFieldSlug(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("slugs_test")
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.TextField{Name: "slug", SlugSource: "title", Max: 12},
+		&core.TextField{Name: "code", SlugSource: "title", SlugImmutable: true},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(data map[string]any) *core.Record {
+		record := core.NewRecord(collection)
+		record.Load(data)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+		return record
+	}
+
+	r1 := create(map[string]any{"title": "Hello, Wörld!"})
+	r2 := create(map[string]any{"title": "hello world"})
+	r3 := create(map[string]any{"title": "HELLO WORLD"})
+	r4 := create(map[string]any{"title": "Hello world", "slug": "custom", "code": "custom"})
+	r5 := create(map[string]any{"title": "Very long title here"})
+
+	scenarios := []struct {
+		record *core.Record
+		slug   string
+		code   string
+	}{
+		{r1, "hello-world", "hello-world"},
+		{r2, "hello-worl-2", "hello-world-2"},
+		{r3, "hello-worl-3", "hello-world-3"},
+		{r4, "custom", "custom"},
+		{r5, "very-long-ti", "very-long-title-here"},
+	}
+
+	for i, s := range scenarios {
+		if v := s.record.GetString("slug"); v != s.slug {
+			t.Errorf("[%d] Expected slug %q, got %q", i, s.slug, v)
+		}
+		if v := s.record.GetString("code"); v != s.code {
+			t.Errorf("[%d] Expected code %q, got %q", i, s.code, v)
+		}
+	}
+
+	reload := func(record *core.Record) *core.Record {
+		fresh, err := app.FindRecordById(collection, record.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fresh
+	}
+
+	t.Run("update source", func(t *testing.T) {
+		r1 := reload(r1)
+		r1.Set("title", "Another title")
+		if err := app.Save(r1); err != nil {
+			t.Fatal(err)
+		}
+
+		if v := r1.GetString("slug"); v != "another-titl" {
+			t.Fatalf("Expected the mutable slug to be regenerated, got %q", v)
+		}
+
+		if v := r1.GetString("code"); v != "hello-world" {
+			t.Fatalf("Expected the immutable slug to remain unchanged, got %q", v)
+		}
+	})
+
+	t.Run("update source with explicit slug", func(t *testing.T) {
+		r2 := reload(r2)
+		r2.Set("title", "Another title")
+		r2.Set("slug", "explicit")
+		if err := app.Save(r2); err != nil {
+			t.Fatal(err)
+		}
+
+		if v := r2.GetString("slug"); v != "explicit" {
+			t.Fatalf("Expected the explicit slug to be preserved, got %q", v)
+		}
+	})
+
+	t.Run("change immutable slug", func(t *testing.T) {
+		r3 := reload(r3)
+		r3.Set("code", "changed")
+
+		err := app.Save(r3)
+
+		tests.TestValidationErrors(t, err, []string{"code"})
+	})
+}
+
 func TestTextFieldFindSetter(t *testing.T) {
 	scenarios := []struct {
 		name      string
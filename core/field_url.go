@@ -19,7 +19,10 @@ func init() {
 
 const FieldTypeURL = "url"
 
-var _ Field = (*URLField)(nil)
+var (
+	_ Field              = (*URLField)(nil)
+	_ DefaultValueGetter = (*URLField)(nil)
+)
 
 // URLField defines "url" type field for storing a single URL string value.
 //
@@ -57,6 +60,11 @@ type URLField struct {
 
 	// Required will require the field value to be non-empty URL string.
 	Required bool `form:"required" json:"required"`
+
+	// DefaultValue specifies an optional default value expression that is applied
+	// on record create API request when the field value is not submitted
+	// (see [ResolveFieldDefaultValue] for the supported expressions).
+	DefaultValue string `form:"defaultValue" json:"defaultValue"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -104,6 +112,11 @@ func (f *URLField) SetHidden(hidden bool) {
 	f.Hidden = hidden
 }
 
+// GetDefaultValue implements [DefaultValueGetter] interface method.
+func (f *URLField) GetDefaultValue() string {
+	return f.DefaultValue
+}
+
 // ColumnType implements [Field.ColumnType] interface method.
 func (f *URLField) ColumnType(app App) string {
 	return "TEXT DEFAULT '' NOT NULL"
@@ -164,5 +177,6 @@ func (f *URLField) ValidateSettings(ctx context.Context, app App, collection *Co
 			&f.OnlyDomains,
 			validation.When(len(f.ExceptDomains) > 0, validation.Empty).Else(validation.Each(is.Domain)),
 		),
+		validation.Field(&f.DefaultValue, validation.By(checkDefaultValueExpr)),
 	)
 }
@@ -0,0 +1,272 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"math"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypeVector] = func() Field {
+		return &VectorField{}
+	}
+}
+
+const FieldTypeVector = "vector"
+
+// MaxVectorDimensions is the max allowed number of vector field dimensions.
+const MaxVectorDimensions = 4096
+
+var (
+	_ Field        = (*VectorField)(nil)
+	_ DriverValuer = (*VectorField)(nil)
+)
+
+// VectorField defines "vector" type field for storing a fixed
+// dimension float array (e.g. a text or image embedding).
+//
+// The vector records could be compared with the similarTo() filter and
+// sort function (or with the "vector_similarity" SQL function) which
+// returns the cosine similarity between the field value and another vector.
+//
+// The respective zero record field value is empty []float64 slice.
+type VectorField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Dimensions (required) specifies the exact number of the vector elements.
+	Dimensions int `form:"dimensions" json:"dimensions"`
+
+	// Required will require the field value to be non-empty vector.
+	Required bool `form:"required" json:"required"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *VectorField) Type() string {
+	return FieldTypeVector
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *VectorField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *VectorField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *VectorField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *VectorField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *VectorField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *VectorField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *VectorField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *VectorField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *VectorField) ColumnType(app App) string {
+	return "JSON DEFAULT '[]' NOT NULL"
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+//
+// Values that cannot be converted to a vector are returned as they are
+// so that they could be reported during the field value validation.
+func (f *VectorField) PrepareValue(record *Record, raw any) (any, error) {
+	vector, err := ParseVector(raw)
+	if err != nil {
+		return raw, err
+	}
+
+	return vector, nil
+}
+
+// DriverValue implements the [DriverValuer] interface.
+func (f *VectorField) DriverValue(record *Record) (driver.Value, error) {
+	vector, err := ParseVector(record.GetRaw(f.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	// serialize as json number array
+	return append(types.JSONArray[float64]{}, vector...), nil
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *VectorField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	vector, ok := record.GetRaw(f.Name).([]float64)
+	if !ok {
+		return validation.NewError("validation_invalid_vector", "Must be a valid array of numbers.")
+	}
+
+	if len(vector) == 0 {
+		if f.Required {
+			return validation.ErrRequired
+		}
+		return nil // nothing to check
+	}
+
+	if len(vector) != f.Dimensions {
+		return validation.NewError(
+			"validation_invalid_vector_dimensions",
+			"The vector must have exactly {{.dimensions}} elements.",
+		).SetParams(map[string]any{"dimensions": f.Dimensions})
+	}
+
+	for _, v := range vector {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return validation.NewError("validation_invalid_vector", "Must be a valid array of numbers.")
+		}
+	}
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *VectorField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(&f.Dimensions, validation.Required, validation.Min(1), validation.Max(MaxVectorDimensions)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// ParseVector converts the provided raw value (JSON array string,
+// []float64, []any, etc.) into a []float64 vector.
+//
+// nil and empty string values are converted to empty vector.
+func ParseVector(raw any) ([]float64, error) {
+	switch v := raw.(type) {
+	case nil:
+		return []float64{}, nil
+	case []float64:
+		return v, nil
+	case string:
+		return parseVectorJSON([]byte(v))
+	case []byte:
+		return parseVectorJSON(v)
+	case types.JSONRaw:
+		return parseVectorJSON(v)
+	case types.JSONArray[float64]:
+		return []float64(v), nil
+	case []any:
+		vector := make([]float64, len(v))
+		for i, item := range v {
+			n, err := cast.ToFloat64E(item)
+			if err != nil {
+				return nil, err
+			}
+			vector[i] = n
+		}
+		return vector, nil
+	default:
+		return nil, errors.New("unsupported vector value type")
+	}
+}
+
+func parseVectorJSON(raw []byte) ([]float64, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return []float64{}, nil
+	}
+
+	vector := []float64{}
+	if err := json.Unmarshal(raw, &vector); err != nil {
+		return nil, err
+	}
+
+	return vector, nil
+}
+
+// CosineSimilarity returns the cosine similarity between the provided
+// vectors in the range [-1, 1].
+//
+// Returns false if the vectors are empty, with different dimensions or
+// any of them has zero magnitude.
+func CosineSimilarity(a, b []float64) (float64, bool) {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0, false
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0, false
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB)), true
+}
+
+// vectorSimilaritySQLFunc implements the "vector_similarity(a, b)" SQL function
+// that returns the cosine similarity between 2 JSON serialized vectors
+// (or NULL if the vectors are invalid or with different dimensions).
+func vectorSimilaritySQLFunc(a any, b any) (driver.Value, error) {
+	va, err := ParseVector(a)
+	if err != nil {
+		return nil, nil
+	}
+
+	vb, err := ParseVector(b)
+	if err != nil {
+		return nil, nil
+	}
+
+	similarity, ok := CosineSimilarity(va, vb)
+	if !ok {
+		return nil, nil
+	}
+
+	return similarity, nil
+}
@@ -0,0 +1,292 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestVectorFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeVector)
+}
+
+func TestVectorFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.VectorField{}
+
+	expected := "JSON DEFAULT '[]' NOT NULL"
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestVectorFieldPrepareValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.VectorField{}
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	scenarios := []struct {
+		raw         any
+		expected    string
+		expectError bool
+	}{
+		{nil, `[]`, false},
+		{"", `[]`, false},
+		{"null", `[]`, false},
+		{"[]", `[]`, false},
+		{"[1,2.5,-3]", `[1,2.5,-3]`, false},
+		{"[1,2", `"[1,2"`, true},
+		{`["a"]`, `"[\"a\"]"`, true},
+		{[]byte("[1,2]"), `[1,2]`, false},
+		{types.JSONRaw("[1,2]"), `[1,2]`, false},
+		{[]float64{1, 2}, `[1,2]`, false},
+		{[]any{1, "2.5", 3.0}, `[1,2.5,3]`, false},
+		{[]any{"a"}, `["a"]`, true},
+		{123, `123`, true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			v, err := f.PrepareValue(record, s.raw)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr {
+				if _, ok := v.([]float64); !ok {
+					t.Fatalf("Expected []float64 instance, got %T", v)
+				}
+			}
+
+			raw, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(raw) != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, raw)
+			}
+		})
+	}
+}
+
+func TestVectorFieldDriverValue(t *testing.T) {
+	scenarios := []struct {
+		raw         any
+		expected    string
+		expectError bool
+	}{
+		{nil, `[]`, false},
+		{"", `[]`, false},
+		{"[1,2]", `[1,2]`, false},
+		{[]float64{0.5, -1}, `[0.5,-1]`, false},
+		{"invalid", "", true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			f := &core.VectorField{Name: "test"}
+
+			record := core.NewRecord(core.NewBaseCollection("test"))
+			record.SetRaw(f.GetName(), s.raw)
+
+			v, err := f.DriverValue(record)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if _, ok := v.(types.JSONArray[float64]); !ok {
+				t.Fatalf("Expected types.JSONArray value, got %T", v)
+			}
+
+			vRaw, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(vRaw) != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, vRaw)
+			}
+		})
+	}
+}
+
+func TestVectorFieldValidateValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name        string
+		field       *core.VectorField
+		raw         any
+		expectError bool
+	}{
+		{
+			"invalid raw value",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			"[1,2]",
+			true,
+		},
+		{
+			"zero field value (not required)",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			[]float64{},
+			false,
+		},
+		{
+			"zero field value (required)",
+			&core.VectorField{Name: "test", Dimensions: 2, Required: true},
+			[]float64{},
+			true,
+		},
+		{
+			"less dimensions",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			[]float64{1},
+			true,
+		},
+		{
+			"more dimensions",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			[]float64{1, 2, 3},
+			true,
+		},
+		{
+			"NaN element",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			[]float64{1, math.NaN()},
+			true,
+		},
+		{
+			"Inf element",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			[]float64{math.Inf(1), 2},
+			true,
+		},
+		{
+			"valid vector (required)",
+			&core.VectorField{Name: "test", Dimensions: 2, Required: true},
+			[]float64{1, 2},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			record := core.NewRecord(collection)
+			record.SetRaw("test", s.raw)
+
+			err := s.field.ValidateValue(context.Background(), app, record)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestVectorFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeVector)
+	testDefaultFieldNameValidation(t, core.FieldTypeVector)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name         string
+		field        func() *core.VectorField
+		expectErrors []string
+	}{
+		{
+			"zero Dimensions",
+			func() *core.VectorField {
+				return &core.VectorField{Id: "test", Name: "test"}
+			},
+			[]string{"dimensions"},
+		},
+		{
+			"negative Dimensions",
+			func() *core.VectorField {
+				return &core.VectorField{Id: "test", Name: "test", Dimensions: -1}
+			},
+			[]string{"dimensions"},
+		},
+		{
+			"> MaxVectorDimensions",
+			func() *core.VectorField {
+				return &core.VectorField{Id: "test", Name: "test", Dimensions: core.MaxVectorDimensions + 1}
+			},
+			[]string{"dimensions"},
+		},
+		{
+			"valid Dimensions",
+			func() *core.VectorField {
+				return &core.VectorField{Id: "test", Name: "test", Dimensions: 3}
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := s.field().ValidateSettings(context.Background(), app, collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	scenarios := []struct {
+		a          []float64
+		b          []float64
+		expected   float64
+		expectedOk bool
+	}{
+		{nil, nil, 0, false},
+		{[]float64{1, 2}, []float64{1}, 0, false},
+		{[]float64{0, 0}, []float64{1, 1}, 0, false},
+		{[]float64{1, 0}, []float64{1, 0}, 1, true},
+		{[]float64{1, 0}, []float64{0, 1}, 0, true},
+		{[]float64{1, 0}, []float64{-2, 0}, -1, true},
+		{[]float64{1, 1}, []float64{1, 0}, 1 / math.Sqrt2, true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%v_%v", i, s.a, s.b), func(t *testing.T) {
+			result, ok := core.CosineSimilarity(s.a, s.b)
+
+			if ok != s.expectedOk {
+				t.Fatalf("Expected ok %v, got %v", s.expectedOk, ok)
+			}
+
+			if math.Abs(result-s.expected) > 1e-9 {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
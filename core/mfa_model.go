@@ -10,9 +10,12 @@ import (
 )
 
 const (
-	MFAMethodPassword = "password"
-	MFAMethodOAuth2   = "oauth2"
-	MFAMethodOTP      = "otp"
+	MFAMethodPassword  = "password"
+	MFAMethodOAuth2    = "oauth2"
+	MFAMethodOTP       = "otp"
+	MFAMethodSMSOTP    = "smsotp"
+	MFAMethodMagicLink = "magiclink"
+	MFAMethodSAML      = "saml"
 )
 
 const CollectionNameMFAs = "_mfas"
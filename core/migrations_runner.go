@@ -230,6 +230,25 @@ func (r *MigrationsRunner) Down(toRevertCount int) ([]string, error) {
 	return reverted, nil
 }
 
+// Pending returns the file names of all runner's migrations that are not applied yet.
+//
+// Note that already applied migrations with [Migration.ReapplyCondition] are not included.
+func (r *MigrationsRunner) Pending() ([]string, error) {
+	if err := r.initMigrationsTable(); err != nil {
+		return nil, err
+	}
+
+	pending := []string{}
+
+	for _, m := range r.migrationsList.Items() {
+		if !r.isMigrationApplied(r.app, m.File) {
+			pending = append(pending, m.File)
+		}
+	}
+
+	return pending, nil
+}
+
 // RemoveMissingAppliedMigrations removes the db entries of all applied migrations
 // that are not listed in the runner's migrations list.
 func (r *MigrationsRunner) RemoveMissingAppliedMigrations() error {
@@ -199,6 +199,39 @@ func TestMigrationsRunnerRemoveMissingAppliedMigrations(t *testing.T) {
 	}
 }
 
+func TestMigrationsRunnerPending(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// mock migrations history
+	_, err := app.DB().Insert(core.DefaultMigrationsTable, dbx.Params{
+		"file":    "2_test",
+		"applied": time.Now().UnixMicro(),
+	}).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := core.MigrationsList{}
+	for i := 1; i <= 3; i++ {
+		l.Register(nil, nil, fmt.Sprintf("%d_test", i))
+	}
+
+	r := core.NewMigrationsRunner(app, l)
+
+	pending, err := r.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := json.Marshal(pending)
+	if expected := `["1_test","3_test"]`; string(raw) != expected {
+		t.Fatalf("Expected pending migrations %s, got %s", expected, raw)
+	}
+}
+
 func isMigrationApplied(app core.App, file string) bool {
 	var exists bool
 
@@ -0,0 +1,500 @@
+package core
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// StoreKeyRegisteredModels is the app store key that holds the registered custom models.
+const StoreKeyRegisteredModels = "@registeredModels"
+
+// ModelConfig defines the registration options of a custom (non-record) model.
+type ModelConfig struct {
+	// New is a factory function that returns a new zero model instance (required).
+	//
+	// The model db table columns are resolved from the exported fields
+	// of the returned struct (and its embedded structs) using the "db" tag
+	// (or the snake_case field name if the tag is missing).
+	New func() Model
+
+	// Indexes is an optional list with raw CREATE INDEX statements
+	// that are created together with the model table.
+	Indexes []string
+
+	// AdminAPI enables the superuser only CRUD endpoints
+	// for the model at "/api/models/{table}".
+	AdminAPI bool
+}
+
+// RegisteredModel defines a single registered custom model.
+type RegisteredModel struct {
+	ModelConfig
+
+	// Table is the model db table name.
+	Table string
+
+	// Columns is the list with the resolved model table column definitions.
+	Columns []*ModelColumn
+}
+
+// ModelColumn defines a single model table column.
+type ModelColumn struct {
+	Name string
+	Type string
+}
+
+type modelsRegistry struct {
+	mu     sync.RWMutex
+	models map[string]*RegisteredModel
+}
+
+// RegisterModel registers a custom non-record model (aka. a Go struct
+// stored in its own system table) to the provided app instance.
+//
+// The registered models:
+//   - have their table automatically created on app bootstrap
+//     (and the missing columns added if the struct was changed; existing columns are never dropped)
+//   - could be queried with the generic helpers like [FindModelById],
+//     [FindAllModels] and [FindModelsByFilter]
+//   - trigger the regular model hooks that could be filtered by the table name
+//     (e.g. app.OnModelCreate("dealers"))
+//   - have an autogenerated id on create if the model embeds [BaseModel] and its Id is empty
+//   - optionally could be managed by the superusers with the "/api/models/{table}" endpoints
+//
+// Example:
+//
+//	type Dealer struct {
+//		core.BaseModel
+//		Name    string         `db:"name" json:"name"`
+//		Region  string         `db:"region" json:"region"`
+//		Created types.DateTime `db:"created" json:"created"`
+//	}
+//
+//	func (m *Dealer) TableName() string {
+//		return "dealers"
+//	}
+//
+//	core.RegisterModel(app, core.ModelConfig{
+//		New:      func() core.Model { return &Dealer{} },
+//		Indexes:  []string{"CREATE INDEX idx_dealers_region ON dealers (region)"},
+//		AdminAPI: true,
+//	})
+func RegisterModel(app App, config ModelConfig) error {
+	if config.New == nil {
+		return errors.New("missing model factory function")
+	}
+
+	model := config.New()
+	if model == nil {
+		return errors.New("the model factory function returned nil")
+	}
+
+	table := model.TableName()
+	if !DefaultIdRegex.MatchString(table) {
+		return fmt.Errorf("invalid model table name %q", table)
+	}
+
+	columns, err := resolveModelColumns(model)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %q model columns: %w", table, err)
+	}
+
+	registered := &RegisteredModel{
+		ModelConfig: config,
+		Table:       table,
+		Columns:     columns,
+	}
+
+	registry := getModelsRegistry(app)
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, ok := registry.models[table]; ok {
+		return fmt.Errorf("model %q is already registered", table)
+	}
+
+	// the app is already bootstrapped -> sync immediately
+	// (otherwise it will be synced as part of the app bootstrap)
+	if app.IsBootstrapped() {
+		if err := syncModelTable(app, registered); err != nil {
+			return err
+		}
+	}
+
+	registry.models[table] = registered
+
+	app.OnModelCreate(table).BindFunc(func(e *ModelEvent) error {
+		setEmptyModelId(e.Model)
+		return e.Next()
+	})
+
+	return nil
+}
+
+// FindRegisteredModel returns the registered custom model with the specified table name.
+//
+// Returns nil if no such model was registered.
+func FindRegisteredModel(app App, table string) *RegisteredModel {
+	registry := getModelsRegistry(app)
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	return registry.models[table]
+}
+
+// RegisteredModels returns all registered custom models sorted by their table name.
+func RegisteredModels(app App) []*RegisteredModel {
+	registry := getModelsRegistry(app)
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	result := make([]*RegisteredModel, 0, len(registry.models))
+	for _, m := range registry.models {
+		result = append(result, m)
+	}
+
+	slices.SortFunc(result, func(a, b *RegisteredModel) int {
+		return strings.Compare(a.Table, b.Table)
+	})
+
+	return result
+}
+
+// ColumnNames returns the names of the registered model table columns.
+func (m *RegisteredModel) ColumnNames() []string {
+	names := make([]string, len(m.Columns))
+	for i, c := range m.Columns {
+		names[i] = c.Name
+	}
+
+	return names
+}
+
+// FindModelById finds a single custom model by its id.
+//
+// Example:
+//
+//	dealer, err := core.FindModelById[Dealer](app, "abc")
+func FindModelById[T any, PT interface {
+	*T
+	Model
+}](app App, id string) (PT, error) {
+	m := PT(new(T))
+
+	err := app.ModelQuery(m).
+		AndWhere(dbx.HashExp{m.TableName() + "." + idColumn: id}).
+		Limit(1).
+		One(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// FindAllModels finds all custom models matching the optional expressions.
+//
+// Example:
+//
+//	dealers, err := core.FindAllModels[Dealer](app, dbx.HashExp{"region": "eu"})
+func FindAllModels[T any, PT interface {
+	*T
+	Model
+}](app App, exprs ...dbx.Expression) ([]PT, error) {
+	result := []PT{}
+
+	q := app.ModelQuery(PT(new(T)))
+
+	for _, expr := range exprs {
+		if expr != nil {
+			q.AndWhere(expr)
+		}
+	}
+
+	err := q.All(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindModelsByFilter returns limit number of custom models matching
+// the provided string filter.
+//
+// The filter and sort expressions could reference only the columns
+// of the model struct (resolved the same way as in [RegisterModel]).
+//
+// NB! Don't put untrusted user input in the filter string as it
+// practically would allow the users to inject their own custom filter.
+//
+// Example:
+//
+//	dealers, err := core.FindModelsByFilter[Dealer](
+//		app,
+//		"region = {:region}",
+//		"-created",
+//		10,
+//		0,
+//		dbx.Params{"region": "eu"},
+//	)
+func FindModelsByFilter[T any, PT interface {
+	*T
+	Model
+}](app App, filter string, sort string, limit int, offset int, params ...dbx.Params) ([]PT, error) {
+	m := PT(new(T))
+
+	columns, err := resolveModelColumns(m)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+
+	resolver := search.NewSimpleFieldResolver(names...)
+
+	q := app.ModelQuery(m)
+
+	if filter != "" {
+		expr, err := search.FilterData(filter).BuildExpr(resolver, params...)
+		if err != nil {
+			return nil, err
+		}
+		q.AndWhere(expr)
+	}
+
+	if sort != "" {
+		for _, sortField := range search.ParseSortFromString(sort) {
+			expr, err := sortField.BuildExpr(resolver)
+			if err != nil {
+				return nil, err
+			}
+			if expr != "" {
+				q.AndOrderBy(expr)
+			}
+		}
+	}
+
+	if offset > 0 {
+		q.Offset(int64(offset))
+	}
+
+	if limit > 0 {
+		q.Limit(int64(limit))
+	}
+
+	result := []PT{}
+
+	if err := q.All(&result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// -------------------------------------------------------------------
+
+func getModelsRegistry(app App) *modelsRegistry {
+	return app.Store().GetOrSet(StoreKeyRegisteredModels, func() any {
+		return &modelsRegistry{models: map[string]*RegisteredModel{}}
+	}).(*modelsRegistry)
+}
+
+// syncRegisteredModels creates or updates the tables of all registered custom models.
+func syncRegisteredModels(app App) error {
+	var errs []error
+
+	for _, m := range RegisteredModels(app) {
+		if err := syncModelTable(app, m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// syncModelTable creates the registered model table (if missing),
+// adds the missing columns and creates the model indexes.
+func syncModelTable(app App, m *RegisteredModel) error {
+	if _, err := app.FindCachedCollectionByNameOrId(m.Table); err == nil {
+		return fmt.Errorf("model table %q conflicts with an existing collection", m.Table)
+	}
+
+	return app.RunInTransaction(func(txApp App) error {
+		if !txApp.HasTable(m.Table) {
+			definitions := make([]string, len(m.Columns))
+			for i, c := range m.Columns {
+				definitions[i] = txApp.DB().QuoteSimpleColumnName(c.Name) + " " + c.Type
+			}
+
+			_, err := txApp.DB().NewQuery(fmt.Sprintf(
+				"CREATE TABLE {{%s}} (%s)",
+				m.Table,
+				strings.Join(definitions, ", "),
+			)).Execute()
+			if err != nil {
+				return fmt.Errorf("failed to create %q model table: %w", m.Table, err)
+			}
+		} else {
+			existing, err := txApp.TableColumns(m.Table)
+			if err != nil {
+				return err
+			}
+
+			for _, c := range m.Columns {
+				if slices.ContainsFunc(existing, func(name string) bool { return strings.EqualFold(name, c.Name) }) {
+					continue
+				}
+
+				_, err := txApp.DB().AddColumn(m.Table, c.Name, c.Type).Execute()
+				if err != nil {
+					return fmt.Errorf("failed to add %q column to %q model table: %w", c.Name, m.Table, err)
+				}
+			}
+		}
+
+		for _, index := range m.Indexes {
+			index = strings.Replace(index, "CREATE INDEX ", "CREATE INDEX IF NOT EXISTS ", 1)
+			index = strings.Replace(index, "CREATE UNIQUE INDEX ", "CREATE UNIQUE INDEX IF NOT EXISTS ", 1)
+
+			if _, err := txApp.DB().NewQuery(index).Execute(); err != nil {
+				return fmt.Errorf("failed to create %q model index: %w", m.Table, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+var (
+	dateTimeType = reflect.TypeOf(types.DateTime{})
+	valuerType   = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// resolveModelColumns resolves the db table column definitions
+// from the exported fields of the provided model struct.
+func resolveModelColumns(model Model) ([]*ModelColumn, error) {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, errors.New("the model must be a struct")
+	}
+
+	columns := []*ModelColumn{}
+
+	if err := appendStructColumns(t, &columns); err != nil {
+		return nil, err
+	}
+
+	idIndex := slices.IndexFunc(columns, func(c *ModelColumn) bool { return c.Name == idColumn })
+	if idIndex == -1 {
+		return nil, errors.New(`missing "id" column (embed core.BaseModel or add a db:"id" field)`)
+	}
+
+	// always use the same id definition as the collections
+	columns[idIndex].Type = "TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL"
+
+	return columns, nil
+}
+
+func appendStructColumns(t reflect.Type, columns *[]*ModelColumn) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if !f.IsExported() {
+			continue
+		}
+
+		tag := f.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		// flatten embedded structs
+		if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			if err := appendStructColumns(ft, columns); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = dbx.DefaultFieldMapFunc(f.Name)
+		}
+
+		if err := validation.Validate(name, validation.Match(DefaultIdRegex)); err != nil {
+			return fmt.Errorf("invalid column name %q", name)
+		}
+
+		if slices.ContainsFunc(*columns, func(c *ModelColumn) bool { return c.Name == name }) {
+			return fmt.Errorf("duplicated column %q", name)
+		}
+
+		*columns = append(*columns, &ModelColumn{Name: name, Type: modelColumnType(ft)})
+	}
+
+	return nil
+}
+
+func modelColumnType(t reflect.Type) string {
+	switch {
+	case t == dateTimeType:
+		return "TEXT DEFAULT '' NOT NULL"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !t.Implements(valuerType):
+		return "BLOB DEFAULT NULL"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "TEXT DEFAULT '' NOT NULL"
+	case reflect.Bool:
+		return "BOOLEAN DEFAULT FALSE NOT NULL"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "INTEGER DEFAULT 0 NOT NULL"
+	case reflect.Float32, reflect.Float64:
+		return "NUMERIC DEFAULT 0 NOT NULL"
+	default:
+		// structs, maps, slices, types.JSONRaw, etc.
+		return "JSON DEFAULT NULL"
+	}
+}
+
+// setEmptyModelId generates a new random id if the model embeds
+// [BaseModel] and its Id is empty.
+func setEmptyModelId(m Model) {
+	v := reflect.Indirect(reflect.ValueOf(m))
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	f := v.FieldByName("Id")
+	if f.IsValid() && f.CanSet() && f.Kind() == reflect.String && f.String() == "" {
+		f.SetString(GenerateDefaultRandomId())
+	}
+}
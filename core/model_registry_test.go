@@ -0,0 +1,245 @@
+package core_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+type testDealer struct {
+	core.BaseModel
+
+	Name     string             `db:"name" json:"name"`
+	Region   string             `db:"region" json:"region"`
+	Priority int                `db:"priority" json:"priority"`
+	Active   bool               `db:"active" json:"active"`
+	Meta     types.JSONMap[any] `db:"meta" json:"meta"`
+	Created  types.DateTime     `db:"created" json:"created"`
+	Ignored  string             `db:"-" json:"ignored"`
+}
+
+func (m *testDealer) TableName() string {
+	return "test_dealers"
+}
+
+type testInvalidModel struct {
+	Name string `db:"name"`
+}
+
+func (m *testInvalidModel) TableName() string { return "test_invalid" }
+func (m *testInvalidModel) PK() any           { return nil }
+func (m *testInvalidModel) LastSavedPK() any  { return nil }
+func (m *testInvalidModel) IsNew() bool       { return true }
+func (m *testInvalidModel) MarkAsNew()        {}
+func (m *testInvalidModel) MarkAsNotNew()     {}
+
+func TestRegisterModel(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name        string
+		config      core.ModelConfig
+		expectError bool
+	}{
+		{"missing factory", core.ModelConfig{}, true},
+		{"missing id column", core.ModelConfig{New: func() core.Model { return &testInvalidModel{} }}, true},
+		{"valid model", core.ModelConfig{
+			New:     func() core.Model { return &testDealer{} },
+			Indexes: []string{"CREATE INDEX idx_test_dealers_region ON test_dealers (region)"},
+		}, false},
+		{"duplicated model", core.ModelConfig{New: func() core.Model { return &testDealer{} }}, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := core.RegisterModel(app, s.config)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+
+	registered := core.FindRegisteredModel(app, "test_dealers")
+	if registered == nil {
+		t.Fatal("Expected the model to be registered")
+	}
+
+	expectedColumns := []string{"id", "name", "region", "priority", "active", "meta", "created"}
+	if !slices.Equal(registered.ColumnNames(), expectedColumns) {
+		t.Fatalf("Expected columns %v, got %v", expectedColumns, registered.ColumnNames())
+	}
+
+	if total := len(core.RegisteredModels(app)); total != 1 {
+		t.Fatalf("Expected 1 registered model, got %d", total)
+	}
+
+	// the app is already bootstrapped so the table should be created immediately
+	columns, err := app.TableColumns("test_dealers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(columns, expectedColumns) {
+		t.Fatalf("Expected table columns %v, got %v", expectedColumns, columns)
+	}
+
+	indexes, err := app.TableIndexes("test_dealers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := indexes["idx_test_dealers_region"]; !ok {
+		t.Fatalf("Expected idx_test_dealers_region index, got %v", indexes)
+	}
+}
+
+func TestRegisterModelCollectionConflict(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	err := core.RegisterModel(app, core.ModelConfig{
+		New: func() core.Model { return &testConflictModel{} },
+	})
+	if err == nil {
+		t.Fatal("Expected collection conflict error")
+	}
+
+	if core.FindRegisteredModel(app, "demo1") != nil {
+		t.Fatal("Expected the conflicting model to not be registered")
+	}
+}
+
+type testConflictModel struct {
+	core.BaseModel
+}
+
+func (m *testConflictModel) TableName() string {
+	return "demo1"
+}
+
+func TestRegisterModelBootstrapSync(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// existing table with missing columns
+	_, err := app.DB().NewQuery("CREATE TABLE test_dealers (id TEXT PRIMARY KEY NOT NULL, name TEXT DEFAULT '' NOT NULL)").Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.ResetBootstrapState(); err != nil {
+		t.Fatal(err)
+	}
+
+	err = core.RegisterModel(app, core.ModelConfig{New: func() core.Model { return &testDealer{} }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	columns, err := app.TableColumns("test_dealers")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedColumns := []string{"id", "name", "region", "priority", "active", "meta", "created"}
+	if !slices.Equal(columns, expectedColumns) {
+		t.Fatalf("Expected table columns %v, got %v", expectedColumns, columns)
+	}
+}
+
+func TestRegisteredModelDAO(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	err := core.RegisterModel(app, core.ModelConfig{New: func() core.Model { return &testDealer{} }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var createCalls int
+	app.OnModelCreate("test_dealers").BindFunc(func(e *core.ModelEvent) error {
+		createCalls++
+		return e.Next()
+	})
+
+	dealers := []*testDealer{
+		{Name: "a", Region: "eu", Priority: 2},
+		{Name: "b", Region: "us", Priority: 1},
+		{Name: "c", Region: "eu", Priority: 3, Active: true},
+	}
+	for _, d := range dealers {
+		if err := app.Save(d); err != nil {
+			t.Fatal(err)
+		}
+		if d.Id == "" {
+			t.Fatal("Expected the model id to be autogenerated")
+		}
+	}
+
+	if createCalls != len(dealers) {
+		t.Fatalf("Expected %d create hook calls, got %d", len(dealers), createCalls)
+	}
+
+	t.Run("FindModelById", func(t *testing.T) {
+		found, err := core.FindModelById[testDealer](app, dealers[1].Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Name != "b" || found.IsNew() {
+			t.Fatalf("Unexpected found model %#v", found)
+		}
+
+		if _, err := core.FindModelById[testDealer](app, "missing"); err == nil {
+			t.Fatal("Expected error for missing model")
+		}
+	})
+
+	t.Run("FindAllModels", func(t *testing.T) {
+		all, err := core.FindAllModels[testDealer](app)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 3 {
+			t.Fatalf("Expected 3 models, got %d", len(all))
+		}
+
+		eu, err := core.FindAllModels[testDealer](app, dbx.HashExp{"region": "eu"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(eu) != 2 {
+			t.Fatalf("Expected 2 models, got %d", len(eu))
+		}
+	})
+
+	t.Run("FindModelsByFilter", func(t *testing.T) {
+		result, err := core.FindModelsByFilter[testDealer](app, "region = {:region}", "-priority", 10, 0, dbx.Params{"region": "eu"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result) != 2 || result[0].Name != "c" || result[1].Name != "a" {
+			t.Fatalf("Unexpected filter result %v", result)
+		}
+
+		if _, err := core.FindModelsByFilter[testDealer](app, "unknown = 1", "", 0, 0); err == nil {
+			t.Fatal("Expected error for unknown filter field")
+		}
+	})
+}
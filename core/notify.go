@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// ErrMissingNotifier is returned by [App.Notify] when there is no
+// registered notifier (see [BaseAppConfig.Notifier] and [App.OnRecordNotify]).
+var ErrMissingNotifier = errors.New("missing notifier")
+
+// Notification defines a single push notification payload.
+type Notification struct {
+	// Title is the notification title.
+	Title string `json:"title"`
+
+	// Body is the notification text content.
+	Body string `json:"body"`
+
+	// Data is an optional custom key-value data delivered with the notification.
+	Data map[string]string `json:"data"`
+
+	// Sound is an optional notification sound name (e.g. "default").
+	Sound string `json:"sound"`
+
+	// Badge is an optional app icon badge number (zero means unchanged).
+	Badge int `json:"badge"`
+
+	// TTL is an optional max time in seconds the notification could be
+	// stored by the push service if the device is offline
+	// (zero means the push service default).
+	TTL int `json:"ttl"`
+}
+
+// Notifier defines a base push notifications delivery interface.
+type Notifier interface {
+	// Notify delivers the notification to the devices of the provided record.
+	Notify(ctx context.Context, record *Record, notification *Notification) error
+}
+
+// NotifierFunc is a function adapter that implements the [Notifier] interface.
+type NotifierFunc func(ctx context.Context, record *Record, notification *Notification) error
+
+// Notify implements [Notifier.Notify] interface method.
+func (f NotifierFunc) Notify(ctx context.Context, record *Record, notification *Notification) error {
+	return f(ctx, record, notification)
+}
+
+// Notify sends the provided push notification to the devices of the
+// specified record using the [BaseAppConfig.Notifier] (or the one set in the
+// [App.OnRecordNotify] hook, e.g. by the push plugin).
+//
+// It returns [ErrMissingNotifier] if no notifier is registered.
+func (app *BaseApp) Notify(record *Record, notification *Notification) error {
+	if record == nil {
+		return errors.New("missing notification record")
+	}
+
+	if notification == nil {
+		return errors.New("missing notification payload")
+	}
+
+	event := new(RecordNotifyEvent)
+	event.App = app
+	event.Context = app.Context()
+	event.Record = record
+	event.Notifier = app.config.Notifier
+	event.Notification = notification
+
+	return app.OnRecordNotify().Trigger(event, func(e *RecordNotifyEvent) error {
+		// print the notification in the console to assist with the debugging
+		if e.App.IsDev() {
+			logDate := new(strings.Builder)
+			log.New(logDate, "", log.LstdFlags).Print()
+
+			notifyLog := new(strings.Builder)
+			notifyLog.WriteString(strings.TrimSpace(logDate.String()))
+			notifyLog.WriteString(" Notification sent\n")
+			fmt.Fprintf(notifyLog, "├─ Record: %v (%v)\n", e.Record.Id, e.Record.Collection().Name)
+			fmt.Fprintf(notifyLog, "├─ Title: %v\n", e.Notification.Title)
+			fmt.Fprintf(notifyLog, "└─ Body: %v\n", e.Notification.Body)
+
+			color.HiBlack("%s", notifyLog.String())
+		}
+
+		if e.Notifier == nil {
+			return ErrMissingNotifier
+		}
+
+		return e.Notifier.Notify(e.Context, e.Record, e.Notification)
+	})
+}
@@ -0,0 +1,104 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNotify(t *testing.T) {
+	t.Parallel()
+
+	notification := &core.Notification{Title: "test", Body: "test_body"}
+
+	t.Run("missing record", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		if err := app.Notify(nil, notification); err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+	})
+
+	t.Run("missing notifier", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = app.Notify(user, notification)
+		if !errors.Is(err, core.ErrMissingNotifier) {
+			t.Fatalf("Expected ErrMissingNotifier, got %v", err)
+		}
+	})
+
+	t.Run("hook notifier", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var notified []*core.Notification
+
+		app.OnRecordNotify("users").BindFunc(func(e *core.RecordNotifyEvent) error {
+			e.Notification.Title += "_changed"
+			e.Notifier = core.NotifierFunc(func(ctx context.Context, r *core.Record, n *core.Notification) error {
+				if r.Id != user.Id {
+					t.Fatalf("Expected record %q, got %q", user.Id, r.Id)
+				}
+				notified = append(notified, n)
+				return nil
+			})
+			return e.Next()
+		})
+
+		if err := app.Notify(user, notification); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(notified) != 1 {
+			t.Fatalf("Expected 1 notification, got %d", len(notified))
+		}
+
+		if notified[0].Title != "test_changed" || notified[0].Body != "test_body" {
+			t.Fatalf("Unexpected notification %#v", notified[0])
+		}
+
+		if app.EventCalls["OnRecordNotify"] != 1 {
+			t.Fatalf("Expected OnRecordNotify to be called once, got %d", app.EventCalls["OnRecordNotify"])
+		}
+	})
+
+	t.Run("notifier error", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedErr := errors.New("test")
+
+		app.OnRecordNotify().BindFunc(func(e *core.RecordNotifyEvent) error {
+			e.Notifier = core.NotifierFunc(func(ctx context.Context, r *core.Record, n *core.Notification) error {
+				return expectedErr
+			})
+			return e.Next()
+		})
+
+		err = app.Notify(user, notification)
+		if !errors.Is(err, expectedErr) {
+			t.Fatalf("Expected error %v, got %v", expectedErr, err)
+		}
+	})
+}
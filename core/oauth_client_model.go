@@ -0,0 +1,243 @@
+package core
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"slices"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameOAuthClients = "_oauthClients"
+
+// Supported OIDC provider scopes.
+const (
+	OIDCScopeOpenId  = "openid"
+	OIDCScopeEmail   = "email"
+	OIDCScopeProfile = "profile"
+)
+
+var (
+	_ Model        = (*OAuthClient)(nil)
+	_ PreValidator = (*OAuthClient)(nil)
+	_ RecordProxy  = (*OAuthClient)(nil)
+)
+
+// OAuthClient defines a Record proxy for working with the oauthClients collection,
+// aka. the external apps registered with the app OIDC provider.
+//
+// The client id is the record id.
+type OAuthClient struct {
+	*Record
+}
+
+// NewOAuthClient instantiates and returns a new blank *OAuthClient model.
+//
+// Example usage:
+//
+//	plainSecret := core.GenerateOAuthClientSecret()
+//
+//	client := core.NewOAuthClient(app)
+//	client.SetCollectionRef(users.Id)
+//	client.SetName("Forum")
+//	client.SetRedirectURIs([]string{"https://forum.example.com/callback"})
+//	client.SetSecret(plainSecret)
+//	app.Save(client)
+func NewOAuthClient(app App) *OAuthClient {
+	m := &OAuthClient{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameOAuthClients)
+	if err != nil {
+		// this is just to make tests easier since oauthClients is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on OAuthClient.PreValidate())
+		c = NewBaseCollection("__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// GenerateOAuthClientSecret generates a new random plain client secret.
+func GenerateOAuthClientSecret() string {
+	return security.RandomString(50)
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *OAuthClient) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameOAuthClients {
+		return errors.New("missing or invalid oauthClient ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *OAuthClient) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *OAuthClient) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value
+// (aka. the auth collection used as identity source).
+func (m *OAuthClient) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *OAuthClient) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// Name returns the "name" record field value.
+func (m *OAuthClient) Name() string {
+	return m.GetString("name")
+}
+
+// SetName updates the "name" record field value.
+func (m *OAuthClient) SetName(name string) {
+	m.Set("name", name)
+}
+
+// SecretHash returns the "secretHash" record field value.
+func (m *OAuthClient) SecretHash() string {
+	return m.GetString("secretHash")
+}
+
+// SetSecret hashes the provided plain client secret and updates the "secretHash" record field value.
+//
+// Set an empty secret to register a public client (e.g. SPA or mobile app)
+// that is required to use PKCE.
+func (m *OAuthClient) SetSecret(plainSecret string) {
+	if plainSecret == "" {
+		m.Set("secretHash", "")
+	} else {
+		m.Set("secretHash", security.SHA256(plainSecret))
+	}
+}
+
+// ValidateSecret checks if the provided plain secret matches with the stored client secret hash.
+func (m *OAuthClient) ValidateSecret(plainSecret string) bool {
+	hash := m.SecretHash()
+	if hash == "" || plainSecret == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(security.SHA256(plainSecret))) == 1
+}
+
+// IsPublic reports whether the client doesn't have a secret
+// (aka. a public client that cannot keep its credentials confidential).
+func (m *OAuthClient) IsPublic() bool {
+	return m.SecretHash() == ""
+}
+
+// RedirectURIs returns the "redirectURIs" record field value.
+func (m *OAuthClient) RedirectURIs() []string {
+	uris := []string{}
+	_ = m.UnmarshalJSONField("redirectURIs", &uris)
+	return uris
+}
+
+// SetRedirectURIs updates the "redirectURIs" record field value.
+func (m *OAuthClient) SetRedirectURIs(uris []string) {
+	m.Set("redirectURIs", uris)
+}
+
+// HasRedirectURI checks whether the provided uri exactly matches
+// with one of the registered client redirect uris.
+func (m *OAuthClient) HasRedirectURI(uri string) bool {
+	return uri != "" && slices.Contains(m.RedirectURIs(), uri)
+}
+
+// Created returns the "created" record field value.
+func (m *OAuthClient) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *OAuthClient) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+func (app *BaseApp) registerOAuthClientHooks() {
+	app.OnRecordValidate(CollectionNameOAuthClients).Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			collectionId := e.Record.GetString("collectionRef")
+			err := validation.Validate(collectionId, validation.Required, validation.By(validateCollectionId(e.App, CollectionTypeAuth)))
+			if err != nil {
+				return validation.Errors{"collectionRef": err}
+			}
+
+			uris := []string{}
+			_ = e.Record.UnmarshalJSONField("redirectURIs", &uris)
+			err = validation.Validate(uris, validation.Required, validation.Length(1, 20), validation.Each(validation.By(checkOAuthClientRedirectURI)))
+			if err != nil {
+				return validation.Errors{"redirectURIs": err}
+			}
+
+			return e.Next()
+		},
+		Priority: 99,
+	})
+
+	// delete on collection ref delete
+	app.OnCollectionDeleteExecute().Bind(&hook.Handler[*CollectionEvent]{
+		Func: func(e *CollectionEvent) error {
+			if !e.Collection.IsAuth() {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				clients, err := txApp.FindAllRecords(CollectionNameOAuthClients, dbx.HashExp{"collectionRef": e.Collection.Id})
+				if err != nil {
+					return err
+				}
+
+				for _, client := range clients {
+					if err := txApp.Delete(client); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+}
+
+// checkOAuthClientRedirectURI checks whether the value is an absolute
+// redirect uri without fragment (custom schemes are allowed for native apps).
+func checkOAuthClientRedirectURI(value any) error {
+	v, _ := value.(string)
+
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" || u.Fragment != "" || (u.Host == "" && u.Opaque == "" && u.Path == "") ||
+		((u.Scheme == "http" || u.Scheme == "https") && u.Host == "") {
+		return validation.NewError("validation_invalid_redirect_uri", "Must be an absolute uri without fragment.")
+	}
+
+	return nil
+}
@@ -0,0 +1,326 @@
+package core_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestNewOAuthClient(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	client := core.NewOAuthClient(app)
+
+	if client.Collection().Name != core.CollectionNameOAuthClients {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameOAuthClients, client.Collection().Name)
+	}
+}
+
+func TestGenerateOAuthClientSecret(t *testing.T) {
+	t.Parallel()
+
+	secret1 := core.GenerateOAuthClientSecret()
+	secret2 := core.GenerateOAuthClientSecret()
+
+	if len(secret1) != 50 {
+		t.Fatalf("Expected secret with length 50, got %d", len(secret1))
+	}
+
+	if secret1 == secret2 {
+		t.Fatalf("Expected different secrets, got %q", secret1)
+	}
+}
+
+func TestOAuthClientProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	client := core.OAuthClient{}
+	client.SetProxyRecord(record)
+
+	if client.ProxyRecord() == nil || client.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, client.ProxyRecord())
+	}
+}
+
+func TestOAuthClientStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	client := core.NewOAuthClient(app)
+
+	scenarios := []struct {
+		field  string
+		setter func(string)
+		getter func() string
+	}{
+		{"collectionRef", client.SetCollectionRef, client.CollectionRef},
+		{"name", client.SetName, client.Name},
+	}
+
+	for _, s := range scenarios {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", s.field, i, testValue), func(t *testing.T) {
+				s.setter(testValue)
+
+				if v := s.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := client.GetString(s.field); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestOAuthClientSecret(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	client := core.NewOAuthClient(app)
+
+	if !client.IsPublic() {
+		t.Fatal("Expected a new client to be public")
+	}
+
+	if client.ValidateSecret("") {
+		t.Fatal("Expected empty secret to be invalid for a public client")
+	}
+
+	client.SetSecret("test")
+
+	if v := client.SecretHash(); v != security.SHA256("test") {
+		t.Fatalf("Expected secret hash %q, got %q", security.SHA256("test"), v)
+	}
+
+	if client.IsPublic() {
+		t.Fatal("Expected a confidential client")
+	}
+
+	scenarios := []struct {
+		secret   string
+		expected bool
+	}{
+		{"", false},
+		{"Test", false},
+		{security.SHA256("test"), false},
+		{"test", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.secret, func(t *testing.T) {
+			if v := client.ValidateSecret(s.secret); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+
+	client.SetSecret("")
+
+	if !client.IsPublic() || client.SecretHash() != "" {
+		t.Fatalf("Expected the secret hash to be cleared, got %q", client.SecretHash())
+	}
+}
+
+func TestOAuthClientRedirectURIs(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	client := core.NewOAuthClient(app)
+
+	if v := client.RedirectURIs(); len(v) != 0 {
+		t.Fatalf("Expected no redirect uris, got %v", v)
+	}
+
+	uris := []string{"https://example.com/callback", "http://localhost:3000/callback"}
+	client.SetRedirectURIs(uris)
+
+	if v := client.RedirectURIs(); !slices.Equal(v, uris) {
+		t.Fatalf("Expected %v, got %v", uris, v)
+	}
+
+	scenarios := []struct {
+		uri      string
+		expected bool
+	}{
+		{"", false},
+		{"https://example.com", false},
+		{"https://example.com/callback/", false},
+		{"https://example.com/callback?a=1", false},
+		{"https://example.com/callback", true},
+		{"http://localhost:3000/callback", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.uri, func(t *testing.T) {
+			if v := client.HasRedirectURI(s.uri); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestOAuthClientPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	clientsCol, err := app.FindCollectionByNameOrId(core.CollectionNameOAuthClients)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		client := &core.OAuthClient{}
+
+		if err := app.Validate(client); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-OAuthClient collection", func(t *testing.T) {
+		client := &core.OAuthClient{}
+		client.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		client.SetCollectionRef(users.Id)
+		client.SetName("test")
+		client.SetRedirectURIs([]string{"https://example.com"})
+
+		if err := app.Validate(client); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("OAuthClient collection", func(t *testing.T) {
+		client := &core.OAuthClient{}
+		client.SetProxyRecord(core.NewRecord(clientsCol))
+		client.SetCollectionRef(users.Id)
+		client.SetName("test")
+		client.SetRedirectURIs([]string{"https://example.com"})
+
+		if err := app.Validate(client); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestOAuthClientValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		client       func() *core.OAuthClient
+		expectErrors []string
+	}{
+		{
+			"empty",
+			func() *core.OAuthClient {
+				return core.NewOAuthClient(app)
+			},
+			[]string{"collectionRef", "name", "redirectURIs"},
+		},
+		{
+			"non-auth collection",
+			func() *core.OAuthClient {
+				client := core.NewOAuthClient(app)
+				client.SetCollectionRef(demo1.Id)
+				client.SetName("test")
+				client.SetRedirectURIs([]string{"https://example.com"})
+				return client
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"invalid redirect uri",
+			func() *core.OAuthClient {
+				client := core.NewOAuthClient(app)
+				client.SetCollectionRef(users.Id)
+				client.SetName("test")
+				client.SetRedirectURIs([]string{"https://example.com", "invalid", "https://example.com#a", "http:///a"})
+				return client
+			},
+			[]string{"redirectURIs"},
+		},
+		{
+			"valid data",
+			func() *core.OAuthClient {
+				client := core.NewOAuthClient(app)
+				client.SetCollectionRef(users.Id)
+				client.SetName("test")
+				client.SetRedirectURIs([]string{"https://example.com", "myapp://callback"})
+				return client
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.client())
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestOAuthClientCollectionRefDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	authCol := core.NewAuthCollection("test_auth")
+	if err := app.Save(authCol); err != nil {
+		t.Fatal(err)
+	}
+
+	client := core.NewOAuthClient(app)
+	client.SetCollectionRef(authCol.Id)
+	client.SetName("test")
+	client.SetRedirectURIs([]string{"https://example.com"})
+	if err := app.Save(client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(authCol); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindOAuthClientById(client.Id); err == nil {
+		t.Fatal("Expected the client to be deleted together with its collection")
+	}
+}
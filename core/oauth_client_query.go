@@ -0,0 +1,21 @@
+package core
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// FindOAuthClientById returns a single OAuthClient model by its id (aka. client_id).
+func (app *BaseApp) FindOAuthClientById(id string) (*OAuthClient, error) {
+	result := &OAuthClient{}
+
+	err := app.RecordQuery(CollectionNameOAuthClients).
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
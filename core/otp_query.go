@@ -90,7 +90,14 @@ func (app *BaseApp) DeleteExpiredOTPs() error {
 
 	// note: perform even if OTP is disabled to ensure that there are no dangling old records
 	for _, collection := range authCollections {
-		minValidDate, err := types.ParseDateTime(time.Now().Add(-1 * collection.OTP.DurationTime()))
+		// the OTPs are also used by the SMS OTP and magic link auth methods
+		duration := max(
+			collection.OTP.DurationTime(),
+			collection.SMSOTP.DurationTime(),
+			collection.MagicLinkToken.DurationTime(),
+		)
+
+		minValidDate, err := types.ParseDateTime(time.Now().Add(-1 * duration))
 		if err != nil {
 			return err
 		}
@@ -296,6 +296,7 @@ func TestDeleteExpiredOTPs(t *testing.T) {
 			t.Fatal(err)
 		}
 		superusers.OTP.Duration = 60
+		superusers.MagicLinkToken.Duration = 60
 		if err := app.Save(superusers); err != nil {
 			t.Fatalf("Failed to mock superusers otp duration: %v", err)
 		}
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+// RecordChangesTableName is the name of the change data capture table.
+const RecordChangesTableName = "_recordChanges"
+
+const (
+	RecordChangeActionCreate = "create"
+	RecordChangeActionUpdate = "update"
+	RecordChangeActionDelete = "delete"
+)
+
+// RecordChange defines a single captured record change
+// of a collection with enabled CaptureChanges option.
+type RecordChange struct {
+	// Seq is the monotonically increasing change sequence number
+	// (it is unique across all collections and it is never reused).
+	Seq int64 `db:"seq" json:"seq"`
+
+	CollectionRef string `db:"collectionRef" json:"collectionId"`
+	RecordRef     string `db:"recordRef" json:"recordId"`
+
+	// Action is one of the RecordChangeAction* constants.
+	Action string `db:"action" json:"action"`
+
+	// Before is the record data before the change (null for create).
+	Before types.JSONRaw `db:"before" json:"before"`
+
+	// After is the record data after the change (null for delete).
+	After types.JSONRaw `db:"after" json:"after"`
+
+	Created types.DateTime `db:"created" json:"created"`
+}
+
+// recordChangeImage returns the serialized record data as it is
+// stored in the change data capture table.
+//
+// All fields, including the hidden ones, are exported with the
+// exception of the auth password and tokenKey fields.
+func recordChangeImage(record *Record) (types.JSONRaw, error) {
+	image := record.Fresh()
+	image.Unhide(record.Collection().Fields.FieldNames()...)
+	image.IgnoreEmailVisibility(true)
+
+	return json.Marshal(image)
+}
+
+// insertRecordChange stores a new change entry for the provided record
+// and returns it populated with its assigned sequence number.
+func insertRecordChange(app App, record *Record, action string, before types.JSONRaw) (*RecordChange, error) {
+	change := &RecordChange{
+		CollectionRef: record.Collection().Id,
+		RecordRef:     record.Id,
+		Action:        action,
+		Before:        before,
+		Created:       types.NowDateTime(),
+	}
+
+	if action != RecordChangeActionDelete {
+		after, err := recordChangeImage(record)
+		if err != nil {
+			return nil, err
+		}
+		change.After = after
+	}
+
+	result, err := app.DB().Insert(RecordChangesTableName, dbx.Params{
+		"collectionRef": change.CollectionRef,
+		"recordRef":     change.RecordRef,
+		"action":        change.Action,
+		"before":        change.Before,
+		"after":         change.After,
+		"created":       change.Created.String(),
+	}).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("failed to store the record change: %w", err)
+	}
+
+	change.Seq, err = result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func (app *BaseApp) registerRecordChangeHooks() {
+	captureHandler := func(action string) func(e *RecordEvent) error {
+		return func(e *RecordEvent) error {
+			collection := e.Record.Collection()
+			if collection.IsView() || !collection.CaptureChanges {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp App) error {
+				e.App = txApp
+
+				// load the current db state as the before image
+				// (the record Original() is blank for a created but not refetched record)
+				var before types.JSONRaw
+				if action != RecordChangeActionCreate {
+					current, err := txApp.FindRecordById(collection, cast.ToString(e.Record.LastSavedPK()))
+					if err != nil {
+						return err
+					}
+
+					before, err = recordChangeImage(current)
+					if err != nil {
+						return err
+					}
+				}
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				change, err := insertRecordChange(txApp, e.Record, action, before)
+				if err != nil {
+					return err
+				}
+
+				// notify the change listeners only after the transaction has completed
+				if baseTxApp, ok := txApp.(*BaseApp); ok && baseTxApp.txInfo != nil {
+					parentApp := baseTxApp.txInfo.parent
+					baseTxApp.txInfo.onAfterFunc(func(txErr error) error {
+						if txErr != nil {
+							return nil
+						}
+
+						return triggerRecordChange(parentApp, collection, change)
+					})
+
+					return nil
+				}
+
+				return triggerRecordChange(txApp, collection, change)
+			})
+			e.App = originalApp
+
+			return txErr
+		}
+	}
+
+	app.OnRecordCreateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func:     captureHandler(RecordChangeActionCreate),
+		Priority: 99,
+	})
+
+	app.OnRecordUpdateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func:     captureHandler(RecordChangeActionUpdate),
+		Priority: 99,
+	})
+
+	app.OnRecordDeleteExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func:     captureHandler(RecordChangeActionDelete),
+		Priority: 99,
+	})
+}
+
+func triggerRecordChange(app App, collection *Collection, change *RecordChange) error {
+	event := &RecordChangeEvent{App: app, Change: change}
+	event.Collection = collection
+
+	return app.OnRecordChange().Trigger(event)
+}
+
+// -------------------------------------------------------------------
+
+// FindRecordChanges returns the captured changes of the specified
+// collection with sequence number greater than afterSeq,
+// ordered by their sequence number.
+//
+// limit <= 0 means no limit.
+func FindRecordChanges(app App, collectionModelOrIdentifier any, afterSeq int64, limit int) ([]*RecordChange, error) {
+	collection, err := getCollectionByModelOrIdentifier(app, collectionModelOrIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []*RecordChange{}
+
+	query := app.DB().
+		Select("*").
+		From(RecordChangesTableName).
+		Where(dbx.HashExp{"collectionRef": collection.Id}).
+		AndWhere(dbx.NewExp("[[seq]] > {:afterSeq}", dbx.Params{"afterSeq": afterSeq})).
+		OrderBy("seq ASC")
+
+	if limit > 0 {
+		query.Limit(int64(limit))
+	}
+
+	if err := query.All(&changes); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// DeleteRecordChanges deletes the captured changes of the specified
+// collection with sequence number less than or equal to untilSeq.
+//
+// Could be used to truncate the already consumed changes.
+func DeleteRecordChanges(app App, collectionModelOrIdentifier any, untilSeq int64) error {
+	collection, err := getCollectionByModelOrIdentifier(app, collectionModelOrIdentifier)
+	if err != nil {
+		return err
+	}
+
+	_, err = app.DB().Delete(RecordChangesTableName, dbx.And(
+		dbx.HashExp{"collectionRef": collection.Id},
+		dbx.NewExp("[[seq]] <= {:untilSeq}", dbx.Params{"untilSeq": untilSeq}),
+	)).Execute()
+
+	return err
+}
@@ -0,0 +1,215 @@
+package core_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRecordChanges(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("articles")
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.TextField{Name: "secret", Hidden: true},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// not captured
+	record := core.NewRecord(collection)
+	record.Set("title", "a")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	collection.CaptureChanges = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	events := []*core.RecordChange{}
+	app.OnRecordChange("articles").BindFunc(func(e *core.RecordChangeEvent) error {
+		events = append(events, e.Change)
+		return e.Next()
+	})
+
+	record.Set("title", "b")
+	record.Set("secret", "s")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	record2 := core.NewRecord(collection)
+	record2.Set("title", "c")
+	if err := app.Save(record2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// rollback
+	app.RunInTransaction(func(txApp core.App) error {
+		record2.Set("title", "d")
+		if err := txApp.Save(record2); err != nil {
+			t.Fatal(err)
+		}
+
+		return errors.New("rollback")
+	})
+
+	changes, err := core.FindRecordChanges(app, collection, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		action   string
+		recordId string
+		before   map[string]any
+		after    map[string]any
+	}{
+		{core.RecordChangeActionUpdate, record.Id, map[string]any{"title": "a", "secret": ""}, map[string]any{"title": "b", "secret": "s"}},
+		{core.RecordChangeActionCreate, record2.Id, nil, map[string]any{"title": "c"}},
+		{core.RecordChangeActionDelete, record.Id, map[string]any{"title": "b", "secret": "s"}, nil},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d", len(expected), len(changes))
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d OnRecordChange calls, got %d", len(expected), len(events))
+	}
+
+	checkImage := func(i int, name string, raw []byte, expected map[string]any) {
+		if expected == nil {
+			if len(raw) != 0 {
+				t.Fatalf("[%d] Expected nil %s image, got %s", i, name, raw)
+			}
+			return
+		}
+
+		image := map[string]any{}
+		if err := json.Unmarshal(raw, &image); err != nil {
+			t.Fatalf("[%d] Failed to unmarshal %s image: %v", i, name, err)
+		}
+
+		for k, v := range expected {
+			if image[k] != v {
+				t.Fatalf("[%d] Expected %s %q to be %v, got %v", i, name, k, v, image[k])
+			}
+		}
+	}
+
+	for i, change := range changes {
+		if i > 0 && change.Seq <= changes[i-1].Seq {
+			t.Fatalf("[%d] Expected monotonic sequence numbers, got %d after %d", i, change.Seq, changes[i-1].Seq)
+		}
+
+		if change.Seq != events[i].Seq {
+			t.Fatalf("[%d] Expected OnRecordChange seq %d, got %d", i, change.Seq, events[i].Seq)
+		}
+
+		if change.Action != expected[i].action {
+			t.Fatalf("[%d] Expected action %q, got %q", i, expected[i].action, change.Action)
+		}
+
+		if change.RecordRef != expected[i].recordId || change.CollectionRef != collection.Id {
+			t.Fatalf("[%d] Unexpected change refs %q, %q", i, change.CollectionRef, change.RecordRef)
+		}
+
+		checkImage(i, "before", change.Before, expected[i].before)
+		checkImage(i, "after", change.After, expected[i].after)
+	}
+
+	// after seq and limit
+	changes, err = core.FindRecordChanges(app, collection, changes[0].Seq, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Action != core.RecordChangeActionCreate {
+		t.Fatalf("Expected only the create change, got %v", changes)
+	}
+
+	// truncate
+	if err := core.DeleteRecordChanges(app, collection, changes[0].Seq); err != nil {
+		t.Fatal(err)
+	}
+	changes, err = core.FindRecordChanges(app, collection, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Action != core.RecordChangeActionDelete {
+		t.Fatalf("Expected only the delete change, got %v", changes)
+	}
+
+	// delete the collection
+	if err := app.Delete(collection); err != nil {
+		t.Fatal(err)
+	}
+	var total int
+	err = app.DB().Select("count(*)").From(core.RecordChangesTableName).Row(&total)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Fatalf("Expected the collection changes to be deleted, got %d", total)
+	}
+}
+
+func TestRecordChangesAuthSecrets(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collection.CaptureChanges = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.SetEmail("changes@example.com")
+	record.SetPassword("1234567890")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := core.FindRecordChanges(app, collection, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 change, got %d", len(changes))
+	}
+
+	image := map[string]any{}
+	if err := json.Unmarshal(changes[0].After, &image); err != nil {
+		t.Fatal(err)
+	}
+
+	if image["email"] != "changes@example.com" {
+		t.Fatalf("Expected the email to be exported, got %v", image["email"])
+	}
+
+	for _, k := range []string{core.FieldNamePassword, core.FieldNameTokenKey} {
+		if _, ok := image[k]; ok {
+			t.Fatalf("Expected %q to not be exported", k)
+		}
+	}
+}
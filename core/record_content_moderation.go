@@ -0,0 +1,87 @@
+package core
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+// Record content moderation outcomes.
+const (
+	// ModerationOutcomeAllow marks the content as safe.
+	ModerationOutcomeAllow = "allow"
+
+	// ModerationOutcomeFlag allows the content but marks it as potentially unsafe.
+	ModerationOutcomeFlag = "flag"
+
+	// ModerationOutcomeReview allows the content to be saved but
+	// queues it for a manual review (e.g. by hiding it with the API rules).
+	ModerationOutcomeReview = "review"
+
+	// ModerationOutcomeBlock rejects the content with a validation error.
+	ModerationOutcomeBlock = "block"
+)
+
+// moderateRecordContent triggers the OnRecordContentModeration hook
+// for the new or changed record text content and uploaded files.
+func moderateRecordContent(e *RecordEvent) error {
+	// nothing is listening
+	if e.App.OnRecordContentModeration().Length() == 0 {
+		return nil
+	}
+
+	event := new(RecordContentModerationEvent)
+	event.App = e.App
+	event.Context = e.Context
+	event.Record = e.Record
+	event.Texts = map[string]string{}
+	event.Files = map[string][]*filesystem.File{}
+
+	original := e.Record.Original()
+
+	for _, f := range e.Record.Collection().Fields {
+		// skip system fields like id, tokenKey, etc.
+		if f.GetSystem() {
+			continue
+		}
+
+		name := f.GetName()
+
+		switch f.Type() {
+		case FieldTypeText, FieldTypeEditor:
+			v := e.Record.GetString(name)
+			if v != "" && (e.Record.IsNew() || v != original.GetString(name)) {
+				event.Texts[name] = v
+			}
+		case FieldTypeFile:
+			if files := e.Record.GetUploadedFiles(name); len(files) > 0 {
+				event.Files[name] = files
+			}
+		}
+	}
+
+	if len(event.Texts) == 0 && len(event.Files) == 0 {
+		return nil // nothing to moderate
+	}
+
+	return e.App.OnRecordContentModeration().Trigger(event, func(e *RecordContentModerationEvent) error {
+		if e.Outcome != ModerationOutcomeBlock {
+			return nil
+		}
+
+		// report the error for each of the moderated fields
+		errs := validation.Errors{}
+		blockedErr := validation.NewError("validation_content_blocked", "The submitted content is not allowed.")
+		for name := range e.Texts {
+			errs[name] = blockedErr
+		}
+		for name := range e.Files {
+			errs[name] = blockedErr
+		}
+
+		if len(errs) == 0 {
+			return blockedErr
+		}
+
+		return errs
+	})
+}
@@ -0,0 +1,169 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestRecordContentModeration(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	var calls int
+	var lastEvent *core.RecordContentModerationEvent
+	outcome := ""
+
+	app.OnRecordContentModeration("demo2", "users").BindFunc(func(e *core.RecordContentModerationEvent) error {
+		calls++
+		lastEvent = e
+		e.Outcome = outcome
+		return e.Next()
+	})
+
+	collection, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("new record", func(t *testing.T) {
+		record := core.NewRecord(collection)
+		record.Set("title", "new")
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+
+		if calls != 1 {
+			t.Fatalf("Expected 1 call, got %d", calls)
+		}
+
+		if len(lastEvent.Texts) != 1 || lastEvent.Texts["title"] != "new" {
+			t.Fatalf("Expected only the title text, got %v", lastEvent.Texts)
+		}
+
+		if len(lastEvent.Files) != 0 {
+			t.Fatalf("Expected no files, got %v", lastEvent.Files)
+		}
+	})
+
+	t.Run("unchanged text", func(t *testing.T) {
+		calls = 0
+
+		record, err := app.FindRecordById(collection, "llvuca81nly1qls")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+
+		if calls != 0 {
+			t.Fatalf("Expected 0 calls, got %d", calls)
+		}
+	})
+
+	t.Run("without validations", func(t *testing.T) {
+		calls = 0
+
+		record, err := app.FindRecordById(collection, "llvuca81nly1qls")
+		if err != nil {
+			t.Fatal(err)
+		}
+		record.Set("title", "no_validate")
+		if err := app.SaveNoValidate(record); err != nil {
+			t.Fatal(err)
+		}
+
+		if calls != 0 {
+			t.Fatalf("Expected 0 calls, got %d", calls)
+		}
+	})
+
+	t.Run("non-matching tag", func(t *testing.T) {
+		calls = 0
+
+		record, err := app.FindRecordById("demo3", "1tmknxy2868d869")
+		if err != nil {
+			t.Fatal(err)
+		}
+		record.Set("title", "changed")
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+
+		if calls != 0 {
+			t.Fatalf("Expected 0 calls, got %d", calls)
+		}
+	})
+
+	t.Run("uploaded file", func(t *testing.T) {
+		calls = 0
+
+		record, err := app.FindRecordById("users", "4q1xlclmfloku33")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := filesystem.NewFileFromBytes([]byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), "test.svg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		record.Set("avatar", f)
+		record.Set("name", "moderated")
+
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+
+		if calls != 1 {
+			t.Fatalf("Expected 1 call, got %d", calls)
+		}
+
+		if len(lastEvent.Files["avatar"]) != 1 {
+			t.Fatalf("Expected 1 avatar file, got %v", lastEvent.Files)
+		}
+
+		if lastEvent.Texts["name"] != "moderated" {
+			t.Fatalf("Expected the changed name text, got %v", lastEvent.Texts)
+		}
+	})
+
+	t.Run("flag outcome", func(t *testing.T) {
+		outcome = core.ModerationOutcomeFlag
+
+		record := core.NewRecord(collection)
+		record.Set("title", "flagged")
+		if err := app.Save(record); err != nil {
+			t.Fatalf("Expected the flagged record to be saved, got %v", err)
+		}
+	})
+
+	t.Run("block outcome", func(t *testing.T) {
+		outcome = core.ModerationOutcomeBlock
+
+		record := core.NewRecord(collection)
+		record.Set("title", "blocked")
+
+		err := app.Save(record)
+
+		var errs validation.Errors
+		if !errors.As(err, &errs) {
+			t.Fatalf("Expected validation errors, got %v", err)
+		}
+
+		var fieldErr validation.Error
+		if !errors.As(errs["title"], &fieldErr) || fieldErr.Code() != "validation_content_blocked" {
+			t.Fatalf("Expected title validation_content_blocked error, got %v", errs)
+		}
+
+		if !record.IsNew() {
+			t.Fatal("Expected the record to not be saved")
+		}
+	})
+}
@@ -129,7 +129,13 @@ func (r *RecordFieldResolver) UpdateQuery(query *dbx.SelectQuery) error {
 //	@request.body.someSelect:each
 //	@request.body.someField:isset
 //	@collection.product.name
+//
+// It also resolves the custom identifiers registered with [RegisterRuleMacro].
 func (r *RecordFieldResolver) Resolve(fieldName string) (*search.ResolverResult, error) {
+	if result, ok, err := r.resolveMacro(fieldName); ok {
+		return result, err
+	}
+
 	return parseAndRun(fieldName, r)
 }
 
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// ensure that `search.FunctionResolver` interface is implemented
+var _ search.FunctionResolver = (*RecordFieldResolver)(nil)
+
+// RuleFunctionContext holds the context of a single rule function call.
+type RuleFunctionContext struct {
+	App         App
+	Collection  *Collection
+	RequestInfo *RequestInfo // could be nil
+}
+
+// RuleFunction defines a custom API rule and filter expression function.
+//
+// The function arguments are already resolved and the returned
+// result should be a valid SQL expression (with its params if any), eg.:
+//
+//	core.RegisterRuleFunction("isEven", func(ctx *core.RuleFunctionContext, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+//		if len(args) != 1 {
+//			return nil, errors.New("isEven() expects exactly 1 argument")
+//		}
+//
+//		return &search.ResolverResult{
+//			Identifier: "(" + args[0].Identifier + " % 2 = 0)",
+//			Params:     args[0].Params,
+//		}, nil
+//	})
+//
+//	// then in a rule: isEven(total) = true
+type RuleFunction func(ctx *RuleFunctionContext, args ...*search.ResolverResult) (*search.ResolverResult, error)
+
+// RuleMacro defines a custom API rule and filter expression identifier
+// (e.g. "@request.auth.groups") that is resolved dynamically.
+//
+// The returned result should be a valid SQL expression (with its params if any).
+type RuleMacro func(ctx *RuleFunctionContext) (*search.ResolverResult, error)
+
+var (
+	ruleFunctionsMu sync.RWMutex
+	ruleFunctions   = map[string]RuleFunction{}
+	ruleMacros      = map[string]RuleMacro{}
+)
+
+// RegisterRuleFunction registers a new global rule function with the specified name
+// (if function with the same name already exist it will be replaced).
+func RegisterRuleFunction(name string, fn RuleFunction) {
+	ruleFunctionsMu.Lock()
+	defer ruleFunctionsMu.Unlock()
+
+	ruleFunctions[name] = fn
+}
+
+// UnregisterRuleFunction removes the rule function with the specified name (if exist).
+func UnregisterRuleFunction(name string) {
+	ruleFunctionsMu.Lock()
+	defer ruleFunctionsMu.Unlock()
+
+	delete(ruleFunctions, name)
+}
+
+// RegisterRuleMacro registers a new global rule macro with the specified identifier name
+// (if macro with the same name already exist it will be replaced).
+//
+// Note that the macros take precedence over the regular identifiers with the same name.
+func RegisterRuleMacro(name string, fn RuleMacro) {
+	ruleFunctionsMu.Lock()
+	defer ruleFunctionsMu.Unlock()
+
+	ruleMacros[name] = fn
+}
+
+// UnregisterRuleMacro removes the rule macro with the specified name (if exist).
+func UnregisterRuleMacro(name string) {
+	ruleFunctionsMu.Lock()
+	defer ruleFunctionsMu.Unlock()
+
+	delete(ruleMacros, name)
+}
+
+// resolveMacro resolves the registered rule macro with the specified name.
+//
+// Returns false if there is no such rule macro.
+func (r *RecordFieldResolver) resolveMacro(name string) (*search.ResolverResult, bool, error) {
+	ruleFunctionsMu.RLock()
+	fn, ok := ruleMacros[name]
+	ruleFunctionsMu.RUnlock()
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	result, err := fn(r.functionContext())
+
+	return result, true, err
+}
+
+func (r *RecordFieldResolver) functionContext() *RuleFunctionContext {
+	return &RuleFunctionContext{
+		App:         r.app,
+		Collection:  r.baseCollection,
+		RequestInfo: r.requestInfo,
+	}
+}
+
+// ResolveFunction implements `search.FunctionResolver` interface.
+func (r *RecordFieldResolver) ResolveFunction(name string, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+	ruleFunctionsMu.RLock()
+	fn, ok := ruleFunctions[name]
+	ruleFunctionsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s()", name)
+	}
+
+	return fn(r.functionContext(), args...)
+}
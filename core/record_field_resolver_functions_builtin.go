@@ -0,0 +1,389 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+// Names of the builtin rule functions.
+const (
+	RuleFunctionLength      = "length"
+	RuleFunctionLower       = "lower"
+	RuleFunctionContains    = "contains"
+	RuleFunctionDateAdd     = "dateAdd"
+	RuleFunctionRegexMatch  = "regexMatch"
+	RuleFunctionSimilarTo   = "similarTo"
+	RuleFunctionGeoDistance = "geoDistance"
+	RuleFunctionDistance    = "@distance"
+)
+
+// maxRegexPatternLength is the max allowed length of a regexMatch() pattern.
+const maxRegexPatternLength = 1000
+
+func init() {
+	// length(value) returns the number of characters of a text value
+	// or the number of elements of a JSON array (e.g. multiple select or relation field), eg.:
+	//	length(tags) > 2
+	RegisterRuleFunction(RuleFunctionLength, lengthRuleFunc)
+
+	// lower(value) returns the lowercased text value, eg.:
+	//	lower(email) = lower(@request.body.email)
+	RegisterRuleFunction(RuleFunctionLower, lowerRuleFunc)
+
+	// contains(value, search) checks whether a text value contains the
+	// search substring or a JSON array contains the search element, eg.:
+	//	contains(tags, "featured") = true
+	RegisterRuleFunction(RuleFunctionContains, containsRuleFunc)
+
+	// dateAdd(date, amount, unit) shifts the date with the specified amount of
+	// "seconds", "minutes", "hours", "days", "months" or "years", eg.:
+	//	dateAdd(created, 7, "days") > @now
+	RegisterRuleFunction(RuleFunctionDateAdd, dateAddRuleFunc)
+
+	// regexMatch(value, pattern) checks whether the value matches
+	// the specified (Go RE2 syntax) regular expression, eg.:
+	//	regexMatch(@request.body.slug, "^[a-z0-9-]+$") = true
+	RegisterRuleFunction(RuleFunctionRegexMatch, regexMatchRuleFunc)
+
+	// similarTo(vectorA, vectorB) returns the cosine similarity between
+	// 2 vectors (e.g. a vector field and a JSON array of numbers), eg.:
+	//	similarTo(embedding, @request.query.vector) > 0.8
+	RegisterRuleFunction(RuleFunctionSimilarTo, similarToRuleFunc)
+
+	// geoDistance(point, lat, lon) returns the distance in meters between
+	// a geo point (e.g. a geoPoint field) and the specified coordinate, eg.:
+	//	geoDistance(location, 3.12, 101.6) < 5000
+	//
+	// The second point could be also specified as single geo point argument, eg.:
+	//	geoDistance(location, @request.auth.location) < 5000
+	RegisterRuleFunction(RuleFunctionGeoDistance, geoDistanceRuleFunc)
+
+	// @distance(...) is an alias of geoDistance() intended to be used as sort helper, eg.:
+	//	sort=@distance(location, 3.12, 101.6)
+	RegisterRuleFunction(RuleFunctionDistance, geoDistanceRuleFunc)
+}
+
+func lengthRuleFunc(ctx *RuleFunctionContext, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+	if len(args) != 1 {
+		return nil, errors.New(RuleFunctionLength + "() expects exactly 1 argument")
+	}
+
+	v := args[0].Identifier
+
+	return &search.ResolverResult{
+		Identifier: fmt.Sprintf(
+			"(CASE WHEN json_valid(%[1]s) AND json_type(%[1]s) = 'array' THEN json_array_length(%[1]s) ELSE LENGTH(COALESCE(%[1]s, '')) END)",
+			v,
+		),
+		Params: args[0].Params,
+	}, nil
+}
+
+func lowerRuleFunc(ctx *RuleFunctionContext, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+	if len(args) != 1 {
+		return nil, errors.New(RuleFunctionLower + "() expects exactly 1 argument")
+	}
+
+	return &search.ResolverResult{
+		Identifier: "LOWER(" + args[0].Identifier + ")",
+		Params:     args[0].Params,
+	}, nil
+}
+
+func containsRuleFunc(ctx *RuleFunctionContext, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+	if len(args) != 2 {
+		return nil, errors.New(RuleFunctionContains + "() expects exactly 2 arguments")
+	}
+
+	v := args[0].Identifier
+	needle := args[1].Identifier
+	alias := "__contains" + security.PseudorandomString(5)
+
+	return &search.ResolverResult{
+		Identifier: fmt.Sprintf(
+			"(CASE WHEN json_valid(%[1]s) AND json_type(%[1]s) = 'array' THEN EXISTS (SELECT 1 FROM json_each(%[1]s) [[%[3]s]] WHERE [[%[3]s.value]] = %[2]s) ELSE INSTR(COALESCE(%[1]s, ''), %[2]s) > 0 END)",
+			v, needle, alias,
+		),
+		Params: search.MergeParams(args...),
+	}, nil
+}
+
+var dateAddUnits = []string{"seconds", "minutes", "hours", "days", "months", "years"}
+
+func dateAddRuleFunc(ctx *RuleFunctionContext, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+	if len(args) != 3 {
+		return nil, errors.New(RuleFunctionDateAdd + "() expects exactly 3 arguments")
+	}
+
+	rawUnit, ok := staticArgValue(args[2])
+	if !ok {
+		return nil, errors.New(RuleFunctionDateAdd + "() expects the unit to be a plain text argument")
+	}
+
+	unit := strings.ToLower(cast.ToString(rawUnit))
+
+	// normalize singular units
+	if !strings.HasSuffix(unit, "s") {
+		unit += "s"
+	}
+
+	valid := false
+	for _, u := range dateAddUnits {
+		if u == unit {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("%s() unsupported unit %q (expected one of %s)", RuleFunctionDateAdd, rawUnit, strings.Join(dateAddUnits, ", "))
+	}
+
+	params := search.MergeParams(args[0], args[1])
+
+	unitPlaceholder := "dateAddUnit" + security.PseudorandomString(5)
+	params[unitPlaceholder] = " " + unit
+
+	return &search.ResolverResult{
+		Identifier: fmt.Sprintf(
+			"strftime('%%Y-%%m-%%d %%H:%%M:%%fZ', %s, CAST(%s AS TEXT) || {:%s})",
+			args[0].Identifier, args[1].Identifier, unitPlaceholder,
+		),
+		Params: params,
+	}, nil
+}
+
+func regexMatchRuleFunc(ctx *RuleFunctionContext, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+	if len(args) != 2 {
+		return nil, errors.New(RuleFunctionRegexMatch + "() expects exactly 2 arguments")
+	}
+
+	rawPattern, ok := staticArgValue(args[1])
+	if !ok {
+		return nil, errors.New(RuleFunctionRegexMatch + "() expects the pattern to be a plain text argument")
+	}
+
+	pattern := cast.ToString(rawPattern)
+	if len(pattern) > maxRegexPatternLength {
+		return nil, fmt.Errorf("%s() pattern must be less than %d characters", RuleFunctionRegexMatch, maxRegexPatternLength)
+	}
+
+	re, err := cachedRegexp(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s() invalid pattern: %w", RuleFunctionRegexMatch, err)
+	}
+
+	// evaluate directly the static values (e.g. @request.* fields)
+	if value, ok := staticArgValue(args[0]); ok || args[0].Identifier == "NULL" {
+		if re.MatchString(cast.ToString(value)) {
+			return &search.ResolverResult{Identifier: "1"}, nil
+		}
+		return &search.ResolverResult{Identifier: "0"}, nil
+	}
+
+	// note: requires the "regexp" SQL function to be registered
+	// (it is available by default with the builtin db driver)
+	return &search.ResolverResult{
+		Identifier: fmt.Sprintf("COALESCE(regexp(%s, COALESCE(%s, '')), 0)", args[1].Identifier, args[0].Identifier),
+		Params:     search.MergeParams(args...),
+	}, nil
+}
+
+func similarToRuleFunc(ctx *RuleFunctionContext, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+	if len(args) != 2 {
+		return nil, errors.New(RuleFunctionSimilarTo + "() expects exactly 2 arguments")
+	}
+
+	params := dbx.Params{}
+	identifiers := make([]string, len(args))
+
+	for i, arg := range args {
+		raw, ok := staticArgValue(arg)
+		if !ok {
+			identifiers[i] = arg.Identifier
+			for k, v := range arg.Params {
+				params[k] = v
+			}
+			continue
+		}
+
+		// normalize and validate early the plain text and @request.* vectors
+		vector, err := ParseVector(raw)
+		if err != nil || len(vector) == 0 {
+			return nil, errors.New(RuleFunctionSimilarTo + "() expects the vector to be a non-empty JSON array of numbers")
+		}
+
+		encoded, err := json.Marshal(vector)
+		if err != nil {
+			return nil, err
+		}
+
+		placeholder := "similarTo" + security.PseudorandomString(5)
+		params[placeholder] = string(encoded)
+		identifiers[i] = "{:" + placeholder + "}"
+	}
+
+	// note: requires the "vector_similarity" SQL function to be registered
+	// (it is available by default with the builtin db driver)
+	return &search.ResolverResult{
+		Identifier: fmt.Sprintf("vector_similarity(%s, %s)", identifiers[0], identifiers[1]),
+		Params:     params,
+	}, nil
+}
+
+func geoDistanceRuleFunc(ctx *RuleFunctionContext, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, errors.New(RuleFunctionGeoDistance + "() expects 2 or 3 arguments")
+	}
+
+	params := dbx.Params{}
+
+	bindNumber := func(n float64) string {
+		placeholder := "geoDistance" + security.PseudorandomString(5)
+		params[placeholder] = n
+		return "{:" + placeholder + "}"
+	}
+
+	// numberArg returns the SQL expression of a single coordinate argument
+	// (the plain text and @request.* values are validated early and bound as params).
+	numberArg := func(arg *search.ResolverResult) (string, error) {
+		raw, ok := staticArgValue(arg)
+		if !ok {
+			maps.Copy(params, arg.Params)
+			return arg.Identifier, nil
+		}
+
+		n, err := cast.ToFloat64E(raw)
+		if err != nil {
+			return "", errors.New(RuleFunctionGeoDistance + "() expects the coordinates to be numbers")
+		}
+
+		return bindNumber(n), nil
+	}
+
+	// pointArg returns the latitude and longitude SQL expressions of a single geo point argument.
+	pointArg := func(arg *search.ResolverResult) (string, string, error) {
+		raw, ok := staticArgValue(arg)
+		if !ok {
+			maps.Copy(params, arg.Params)
+			return "json_extract(" + arg.Identifier + ", '$.lat')", "json_extract(" + arg.Identifier + ", '$.lon')", nil
+		}
+
+		point, err := ParseGeoPoint(raw)
+		if err != nil {
+			return "", "", errors.New(RuleFunctionGeoDistance + "() expects the point to be a valid geo point object")
+		}
+
+		return bindNumber(point.Lat), bindNumber(point.Lon), nil
+	}
+
+	lat1, lon1, err := pointArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var lat2, lon2 string
+	if len(args) == 2 {
+		lat2, lon2, err = pointArg(args[1])
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if lat2, err = numberArg(args[1]); err != nil {
+			return nil, err
+		}
+		if lon2, err = numberArg(args[2]); err != nil {
+			return nil, err
+		}
+	}
+
+	// haversine formula
+	//
+	// note: requires the SQLite math functions
+	// (they are available by default with the builtin db driver)
+	return &search.ResolverResult{
+		Identifier: fmt.Sprintf(
+			"(2 * %[5]v * asin(min(1, sqrt(pow(sin(radians(%[3]s - %[1]s) / 2), 2) + cos(radians(%[1]s)) * cos(radians(%[3]s)) * pow(sin(radians(%[4]s - %[2]s) / 2), 2)))))",
+			lat1, lon1, lat2, lon2, earthRadius,
+		),
+		Params: params,
+	}, nil
+}
+
+// staticArgValue returns the value of a plain text or number function argument
+// (including the resolved @request.* fields).
+func staticArgValue(arg *search.ResolverResult) (any, bool) {
+	if len(arg.Params) != 1 || arg.MultiMatchSubQuery != nil || arg.AfterBuild != nil {
+		return nil, false
+	}
+
+	for k, v := range arg.Params {
+		if arg.Identifier == "{:"+k+"}" {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// -------------------------------------------------------------------
+
+const maxCachedRegexps = 500
+
+var (
+	regexpCacheMu sync.RWMutex
+	regexpCache   = map[string]*regexp.Regexp{}
+)
+
+// cachedRegexp returns the compiled regular expression of the provided pattern.
+func cachedRegexp(pattern string) (*regexp.Regexp, error) {
+	regexpCacheMu.RLock()
+	re, ok := regexpCache[pattern]
+	regexpCacheMu.RUnlock()
+	if ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	regexpCacheMu.Lock()
+	if len(regexpCache) >= maxCachedRegexps {
+		// reset to keep the cache bounded
+		regexpCache = map[string]*regexp.Regexp{}
+	}
+	regexpCache[pattern] = re
+	regexpCacheMu.Unlock()
+
+	return re, nil
+}
+
+// regexpSQLFunc implements the "regexp(pattern, value)" SQL function
+// (it is also used by the SQLite "value REGEXP pattern" operator).
+func regexpSQLFunc(pattern any, value any) (int64, error) {
+	if pattern == nil || value == nil {
+		return 0, nil
+	}
+
+	re, err := cachedRegexp(cast.ToString(pattern))
+	if err != nil {
+		return 0, err
+	}
+
+	if re.MatchString(cast.ToString(value)) {
+		return 1, nil
+	}
+
+	return 0, nil
+}
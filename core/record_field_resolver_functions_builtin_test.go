@@ -0,0 +1,385 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestBuiltinRuleFunctions(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestInfo := &core.RequestInfo{
+		Method: "POST",
+		Body: map[string]any{
+			"email": "TEST@example.com",
+			"slug":  "hello-world",
+			"tags":  []string{"a", "b"},
+		},
+	}
+
+	scenarios := []struct {
+		filter      string
+		expectError bool
+		expected    []string
+	}{
+		// length()
+		{"length() = 1", true, nil},
+		{"length(text, text) = 1", true, nil},
+		{"length(text) = 4", false, []string{"84nmscqy84lsi1t"}},
+		{"length(text) > 4", false, []string{"al1h9ijdeojtsjy", "imy661ixudk5izi"}},
+		{"length(select_many) = 2", false, []string{"84nmscqy84lsi1t"}},
+		{"length(rel_many) = 0", false, []string{"imy661ixudk5izi"}},
+		{"length(@request.body.tags) = 2 && length(@request.body.missing) = 0", false, []string{"84nmscqy84lsi1t", "al1h9ijdeojtsjy", "imy661ixudk5izi"}},
+
+		// lower()
+		{"lower() = ''", true, nil},
+		{"lower(email) = lower(@request.body.email)", false, []string{"84nmscqy84lsi1t"}},
+		{"lower(text) = 'TEST'", false, nil},
+
+		// contains()
+		{"contains(text) = true", true, nil},
+		{"contains(text, 'ipsum') = true", false, []string{"imy661ixudk5izi"}},
+		{"contains(select_many, 'optionB') = true", false, []string{"84nmscqy84lsi1t", "al1h9ijdeojtsjy"}},
+		{"contains(select_many, 'option') = true", false, nil},
+		{"contains(rel_many, '4q1xlclmfloku33') = true", false, []string{"al1h9ijdeojtsjy"}},
+		{"contains(@request.body.tags, 'b') = true && contains(@request.body.email, '@') = true", false, []string{"84nmscqy84lsi1t", "al1h9ijdeojtsjy", "imy661ixudk5izi"}},
+
+		// dateAdd()
+		{"dateAdd(created, 1) > @now", true, nil},
+		{"dateAdd(created, 1, 'weeks') > @now", true, nil},
+		{"dateAdd(created, 1, text) > @now", true, nil},
+		{"dateAdd(datetime, 1, 'day') = '2022-10-02 12:00:00.000Z'", false, []string{"84nmscqy84lsi1t"}},
+		{"dateAdd(datetime, -2, 'hours') = '2022-10-01 10:00:00.000Z'", false, []string{"84nmscqy84lsi1t"}},
+		{"dateAdd(datetime, number, 'seconds') = '2022-10-02 22:17:36.000Z'", false, []string{"84nmscqy84lsi1t"}},
+		{"dateAdd(created, 100, 'years') > @now", false, []string{"84nmscqy84lsi1t", "al1h9ijdeojtsjy", "imy661ixudk5izi"}},
+		{"dateAdd(created, 1, 'seconds') > @now", false, nil},
+
+		// regexMatch()
+		{"regexMatch(text) = true", true, nil},
+		{"regexMatch(text, text) = true", true, nil},
+		{"regexMatch(text, '(') = true", true, nil},
+		{"regexMatch(text, '" + strings.Repeat("a", 1001) + "') = true", true, nil},
+		{"regexMatch(text, '^test[0-9]$') = true", false, []string{"al1h9ijdeojtsjy"}},
+		{"regexMatch(email, '^test') = false", false, []string{"imy661ixudk5izi"}},
+		{"regexMatch(@request.body.slug, '^[a-z0-9-]+$') = true", false, []string{"84nmscqy84lsi1t", "al1h9ijdeojtsjy", "imy661ixudk5izi"}},
+		{"regexMatch(@request.body.email, '^[a-z]+@') = true", false, nil},
+		{"regexMatch(@request.body.missing, '^$') = true", false, []string{"84nmscqy84lsi1t", "al1h9ijdeojtsjy", "imy661ixudk5izi"}},
+
+		// nested
+		{"length(lower(text)) = 4 && contains(lower(email), 'test@') = true", false, []string{"84nmscqy84lsi1t"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter, func(t *testing.T) {
+			resolver := core.NewRecordFieldResolver(app, collection, requestInfo, true)
+
+			expr, err := search.FilterData(s.filter).BuildExpr(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			query := app.RecordQuery(collection).Select(collection.Name + ".id").OrderBy(collection.Name + ".id")
+			resolver.UpdateQuery(query)
+
+			var ids []string
+			if err := query.AndWhere(expr).Distinct(true).Column(&ids); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(ids) != len(s.expected) {
+				t.Fatalf("Expected ids %v, got %v", s.expected, ids)
+			}
+
+			for _, id := range s.expected {
+				found := false
+				for _, v := range ids {
+					if v == id {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Fatalf("Missing expected id %q in %v", id, ids)
+				}
+			}
+		})
+	}
+}
+
+func TestRegexpSQLFunction(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		query    string
+		expected int
+	}{
+		{"SELECT regexp('^a+$', 'aaa')", 1},
+		{"SELECT regexp('^a+$', 'aab')", 0},
+		{"SELECT COALESCE(regexp('^a+$', NULL), 0)", 0},
+		{"SELECT 'abc' REGEXP 'b'", 1},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.query, func(t *testing.T) {
+			var result int
+			if err := app.DB().NewQuery(s.query).Row(&result); err != nil {
+				t.Fatal(err)
+			}
+
+			if result != s.expected {
+				t.Fatalf("Expected %d, got %d", s.expected, result)
+			}
+		})
+	}
+
+	var result int
+	if err := app.DB().NewQuery("SELECT regexp('(', 'a')").Row(&result); err == nil {
+		t.Fatal("Expected invalid pattern error")
+	}
+}
+
+func TestSimilarToRuleFunction(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("vectors")
+	collection.Fields.Add(&core.VectorField{Name: "embedding", Dimensions: 2})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	vectors := map[string][]float64{
+		"a00000000000001": {1, 0},
+		"a00000000000002": {0, 1},
+		"a00000000000003": {1, 1},
+		"a00000000000004": {},
+	}
+	for id, v := range vectors {
+		record := core.NewRecord(collection)
+		record.Id = id
+		record.Set("embedding", v)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	requestInfo := &core.RequestInfo{
+		Method: "GET",
+		Query:  map[string]string{"v": "[1,0]"},
+		Body:   map[string]any{"v": []any{0, 1}},
+	}
+
+	scenarios := []struct {
+		filter      string
+		sort        string
+		expectError bool
+		expected    []string
+	}{
+		{"similarTo(embedding) > 0", "", true, nil},
+		{"similarTo(embedding, 'invalid') > 0", "", true, nil},
+		{"similarTo(embedding, '[]') > 0", "", true, nil},
+		{"similarTo(embedding, '[1,0]') > 0.9", "", false, []string{"a00000000000001"}},
+		{"similarTo(embedding, '[1,0]') > 0.5", "", false, []string{"a00000000000001", "a00000000000003"}},
+		{"similarTo(embedding, '[1,0,0]') > -2", "", false, nil},
+		{"similarTo(embedding, @request.query.v) > 0.9", "", false, []string{"a00000000000001"}},
+		{"similarTo(embedding, @request.body.v) > 0.9", "", false, []string{"a00000000000002"}},
+		{"similarTo(embedding, embedding) > 0.9", "", false, []string{"a00000000000001", "a00000000000002", "a00000000000003"}},
+		{"similarTo(embedding, @request.query.v) >= 0", "-similarTo(embedding, @request.query.v)", false, []string{"a00000000000001", "a00000000000003", "a00000000000002"}},
+		{"similarTo(embedding, '[0,1]') >= 0", "similarTo(embedding,'[0,1]')", false, []string{"a00000000000001", "a00000000000003", "a00000000000002"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter+"_"+s.sort, func(t *testing.T) {
+			resolver := core.NewRecordFieldResolver(app, collection, requestInfo, true)
+
+			expr, err := search.FilterData(s.filter).BuildExpr(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			query := app.RecordQuery(collection).Select(collection.Name + ".id").AndWhere(expr)
+
+			if s.sort != "" {
+				for _, sortField := range search.ParseSortFromString(s.sort) {
+					sortExpr, params, err := sortField.BuildExprWithParams(resolver)
+					if err != nil {
+						t.Fatal(err)
+					}
+					query.AndOrderBy(sortExpr).AndBind(params)
+				}
+			} else {
+				query.OrderBy(collection.Name + ".id")
+			}
+
+			resolver.UpdateQuery(query)
+
+			var ids []string
+			if err := query.Column(&ids); err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Join(ids, ",") != strings.Join(s.expected, ",") {
+				t.Fatalf("Expected ids %v, got %v", s.expected, ids)
+			}
+		})
+	}
+}
+
+func TestVectorSimilaritySQLFunction(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		query    string
+		expected float64
+	}{
+		{"SELECT vector_similarity('[1,0]', '[1,0]')", 1},
+		{"SELECT vector_similarity('[1,0]', '[0,1]')", 0},
+		{"SELECT vector_similarity('[1,0]', '[-1,0]')", -1},
+		{"SELECT COALESCE(vector_similarity('[1,0]', '[1,0,0]'), -2)", -2},
+		{"SELECT COALESCE(vector_similarity('invalid', '[1,0]'), -2)", -2},
+		{"SELECT COALESCE(vector_similarity(NULL, '[1,0]'), -2)", -2},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.query, func(t *testing.T) {
+			var result float64
+			if err := app.DB().NewQuery(s.query).Row(&result); err != nil {
+				t.Fatal(err)
+			}
+
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestGeoDistanceRuleFunction(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("places")
+	collection.Fields.Add(&core.GeoPointField{Name: "location"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	points := map[string]types.GeoPoint{
+		"a00000000000001": {Lat: 3.139, Lon: 101.6869},  // Kuala Lumpur
+		"a00000000000002": {Lat: 3.1073, Lon: 101.6067}, // Petaling Jaya (~9.5km from KL)
+		"a00000000000003": {Lat: 1.3521, Lon: 103.8198}, // Singapore (~309km from KL)
+		"a00000000000004": {},
+	}
+	for id, p := range points {
+		record := core.NewRecord(collection)
+		record.Id = id
+		record.Set("location", p)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	requestInfo := &core.RequestInfo{
+		Method: "GET",
+		Query:  map[string]string{"lat": "3.139", "lon": "101.6869"},
+		Body:   map[string]any{"p": map[string]any{"lat": 1.3521, "lon": 103.8198}},
+	}
+
+	scenarios := []struct {
+		filter      string
+		sort        string
+		expectError bool
+		expected    []string
+	}{
+		{"geoDistance(location) > 0", "", true, nil},
+		{"geoDistance(location, 1, 2, 3) > 0", "", true, nil},
+		{"geoDistance(location, 'a', 101) > 0", "", true, nil},
+		{"geoDistance(location, 'invalid') > 0", "", true, nil},
+		{"geoDistance(location, 3.139, 101.6869) < 1000", "", false, []string{"a00000000000001"}},
+		{"geoDistance(location, 3.139, 101.6869) < 20000", "", false, []string{"a00000000000001", "a00000000000002"}},
+		{"geoDistance(location, 3.139, 101.6869) > 20000", "", false, []string{"a00000000000003", "a00000000000004"}},
+		{"geoDistance(location, @request.query.lat, @request.query.lon) < 20000", "", false, []string{"a00000000000001", "a00000000000002"}},
+		{"geoDistance(location, @request.body.p) < 1000", "", false, []string{"a00000000000003"}},
+		{`geoDistance(location, '{"lat":3.1073,"lon":101.6067}') < 1000`, "", false, []string{"a00000000000002"}},
+		{"geoDistance(location, location) = 0", "", false, []string{"a00000000000001", "a00000000000002", "a00000000000003", "a00000000000004"}},
+		{"geoDistance(location, 1.3521, 103.8198) < 400000", "@distance(location, 1.3521, 103.8198)", false, []string{"a00000000000003", "a00000000000001", "a00000000000002"}},
+		{"geoDistance(location, 1.3521, 103.8198) < 400000", "-geoDistance(location, @request.query.lat, @request.query.lon)", false, []string{"a00000000000003", "a00000000000002", "a00000000000001"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter+"_"+s.sort, func(t *testing.T) {
+			resolver := core.NewRecordFieldResolver(app, collection, requestInfo, true)
+
+			expr, err := search.FilterData(s.filter).BuildExpr(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			query := app.RecordQuery(collection).Select(collection.Name + ".id").AndWhere(expr)
+
+			if s.sort != "" {
+				for _, sortField := range search.ParseSortFromString(s.sort) {
+					sortExpr, params, err := sortField.BuildExprWithParams(resolver)
+					if err != nil {
+						t.Fatal(err)
+					}
+					query.AndOrderBy(sortExpr).AndBind(params)
+				}
+			} else {
+				query.OrderBy(collection.Name + ".id")
+			}
+
+			resolver.UpdateQuery(query)
+
+			var ids []string
+			if err := query.Column(&ids); err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Join(ids, ",") != strings.Join(s.expected, ",") {
+				t.Fatalf("Expected ids %v, got %v", s.expected, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+func TestRecordFieldResolverResolveFunction(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestInfo := &core.RequestInfo{Method: "GET"}
+
+	var calledCtx *core.RuleFunctionContext
+
+	core.RegisterRuleFunction("test.isTitle", func(ctx *core.RuleFunctionContext, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+		calledCtx = ctx
+
+		if len(args) != 2 {
+			return nil, errors.New("expected 2 arguments")
+		}
+
+		return &search.ResolverResult{
+			Identifier: "(" + args[0].Identifier + " = " + args[1].Identifier + ")",
+			Params:     search.MergeParams(args...),
+		}, nil
+	})
+	defer core.UnregisterRuleFunction("test.isTitle")
+
+	scenarios := []struct {
+		name        string
+		rule        string
+		expectError bool
+		expectIds   []string
+	}{
+		{"unknown function", "test.missing(title) = true", true, nil},
+		{"invalid arguments", "test.isTitle(title) = true", true, nil},
+		{"valid function call", "test.isTitle(title, 'test2') = true", false, []string{"achvryl401bhse3"}},
+		{"valid negated function call", "test.isTitle(title, 'test2') = false", false, []string{"0yxhwia2amd8gec", "llvuca81nly1qls"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			calledCtx = nil
+
+			resolver := core.NewRecordFieldResolver(app, collection, requestInfo, true)
+
+			expr, err := search.FilterData(s.rule).BuildExpr(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if calledCtx == nil || calledCtx.Collection != collection || calledCtx.RequestInfo != requestInfo || calledCtx.App != app {
+				t.Fatalf("Invalid function context %v", calledCtx)
+			}
+
+			query := app.RecordQuery(collection).OrderBy("id ASC")
+			resolver.UpdateQuery(query)
+
+			records := []*core.Record{}
+			if err := query.AndWhere(expr).All(&records); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(records) != len(s.expectIds) {
+				t.Fatalf("Expected %d records, got %d", len(s.expectIds), len(records))
+			}
+
+			for i, id := range s.expectIds {
+				if records[i].Id != id {
+					t.Fatalf("[%d] Expected record %q, got %q", i, id, records[i].Id)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordFieldResolverResolveMacro(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	core.RegisterRuleMacro("@request.auth.testTitle", func(ctx *core.RuleFunctionContext) (*search.ResolverResult, error) {
+		if ctx.RequestInfo == nil || ctx.RequestInfo.Auth == nil {
+			return &search.ResolverResult{Identifier: "NULL"}, nil
+		}
+
+		return &search.ResolverResult{
+			Identifier: "{:testTitle}",
+			Params:     map[string]any{"testTitle": "test2"},
+		}, nil
+	})
+	defer core.UnregisterRuleMacro("@request.auth.testTitle")
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		requestInfo *core.RequestInfo
+		expectIds   []string
+	}{
+		{"guest", &core.RequestInfo{}, nil},
+		{"auth", &core.RequestInfo{Auth: user}, []string{"achvryl401bhse3"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			resolver := core.NewRecordFieldResolver(app, collection, s.requestInfo, true)
+
+			expr, err := search.FilterData("title = @request.auth.testTitle").BuildExpr(resolver)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			query := app.RecordQuery(collection).OrderBy("id ASC")
+			resolver.UpdateQuery(query)
+
+			records := []*core.Record{}
+			if err := query.AndWhere(expr).All(&records); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(records) != len(s.expectIds) {
+				t.Fatalf("Expected %d records, got %d", len(s.expectIds), len(records))
+			}
+
+			for i, id := range s.expectIds {
+				if records[i].Id != id {
+					t.Fatalf("[%d] Expected record %q, got %q", i, id, records[i].Id)
+				}
+			}
+		})
+	}
+}
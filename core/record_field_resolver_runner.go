@@ -657,6 +657,30 @@ func (r *runner) processLastProp(collection *Collection, prop string) (*search.R
 		return nil, fmt.Errorf("non-filterable field %q", name)
 	}
 
+	// computed fields
+	// -------------------------------------------------------
+	if cv, ok := field.(ComputedValuer); ok {
+		expr, err := cv.ComputedExpr(r.resolver.app, r.activeTableAlias)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &search.ResolverResult{
+			Identifier: expr,
+		}
+
+		if r.withMultiMatch {
+			expr2, err := cv.ComputedExpr(r.resolver.app, r.multiMatchActiveTableAlias)
+			if err != nil {
+				return nil, err
+			}
+			r.multiMatch.valueIdentifier = expr2
+			result.MultiMatchSubQuery = r.multiMatch
+		}
+
+		return result, nil
+	}
+
 	multvaluer, isMultivaluer := field.(MultiValuer)
 
 	cleanFieldName := inflector.Columnify(field.GetName())
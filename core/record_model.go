@@ -40,6 +40,7 @@ type Record struct {
 	customVisibility *store.Store[bool]
 	data             *store.Store[any]
 	expand           *store.Store[any]
+	tokenSigner      TokenSigner
 
 	BaseModel
 
@@ -572,6 +573,7 @@ func (m *Record) BaseFilesPath() string {
 // a blank record (until PostScan() is invoked).
 func (m *Record) Original() *Record {
 	newRecord := NewRecord(m.collection)
+	newRecord.tokenSigner = m.tokenSigner
 
 	newRecord.originalData = maps.Clone(m.originalData)
 
@@ -1058,6 +1060,10 @@ func (m *Record) dbExport() (map[string]any, error) {
 	result := make(map[string]any, len(fields))
 
 	for _, field := range fields {
+		if _, ok := field.(ComputedValuer); ok {
+			continue // evaluated at query time
+		}
+
 		if f, ok := field.(DriverValuer); ok {
 			v, err := f.DriverValue(m)
 			if err != nil {
@@ -1346,6 +1352,10 @@ func onRecordValidate(e *RecordEvent) error {
 		return errs
 	}
 
+	if err := moderateRecordContent(e); err != nil {
+		return err
+	}
+
 	return e.Next()
 }
 
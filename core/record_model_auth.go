@@ -30,6 +30,14 @@ func (m *Record) SetVerified(verified bool) {
 	m.Set(FieldNameVerified, verified)
 }
 
+// IsGuest reports whether the current auth record is flagged
+// as anonymous (aka. guest) via the collection [GuestAuthConfig.AnonymousField].
+func (m *Record) IsGuest() bool {
+	field := m.Collection().GuestAuth.AnonymousField
+
+	return field != "" && m.GetBool(field)
+}
+
 // TokenKey returns the "tokenKey" record field value (usually available with Auth collections).
 func (m *Record) TokenKey() string {
 	return m.GetString(FieldNameTokenKey)
@@ -50,6 +50,31 @@ func TestRecordVerified(t *testing.T) {
 	}
 }
 
+func TestRecordIsGuest(t *testing.T) {
+	collection := core.NewAuthCollection("test")
+	collection.Fields.Add(&core.BoolField{Name: "anonymous"})
+
+	record := core.NewRecord(collection)
+	record.Set("anonymous", true)
+
+	// no configured anonymous field
+	if record.IsGuest() {
+		t.Fatal("Expected IsGuest false without configured anonymous field")
+	}
+
+	collection.GuestAuth.AnonymousField = "anonymous"
+
+	if !record.IsGuest() {
+		t.Fatal("Expected IsGuest true")
+	}
+
+	record.Set("anonymous", false)
+
+	if record.IsGuest() {
+		t.Fatal("Expected IsGuest false")
+	}
+}
+
 func TestRecordTokenKey(t *testing.T) {
 	record := core.NewRecord(core.NewAuthCollection("test"))
 
@@ -98,6 +98,9 @@ func (app *BaseApp) registerSuperuserHooks() {
 			// force password auth
 			e.Collection.PasswordAuth.Enabled = true
 
+			// superusers can't be anonymous
+			e.Collection.GuestAuth.Enabled = false
+
 			// for superusers we don't allow for now standalone OTP auth and always require to be combined with MFA
 			if e.Collection.OTP.Enabled {
 				e.Collection.MFA.Enabled = true
@@ -36,6 +36,24 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 
 	query := app.DB().Select(app.DB().QuoteSimpleColumnName(tableName) + ".*").From(tableName)
 
+	// select the computed fields values
+	if collection != nil {
+		for _, field := range collection.Fields {
+			cv, ok := field.(ComputedValuer)
+			if !ok {
+				continue
+			}
+
+			expr, err := cv.ComputedExpr(app, tableName)
+			if err != nil {
+				collectionErr = errors.Join(collectionErr, err)
+				continue
+			}
+
+			query.AndSelect(expr + " AS " + app.DB().QuoteSimpleColumnName(field.GetName()))
+		}
+	}
+
 	// in case of an error attach a new context and cancel it immediately with the error
 	if collectionErr != nil {
 		ctx, cancelFunc := context.WithCancelCause(context.Background())
@@ -52,7 +70,7 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 
 				switch v := a.(type) {
 				case *Record:
-					record, err := resolveRecordOneHook(collection, op)
+					record, err := resolveRecordOneHook(app, collection, op)
 					if err != nil {
 						return err
 					}
@@ -61,7 +79,7 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 
 					return nil
 				case RecordProxy:
-					record, err := resolveRecordOneHook(collection, op)
+					record, err := resolveRecordOneHook(app, collection, op)
 					if err != nil {
 						return err
 					}
@@ -79,7 +97,7 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 
 				switch v := sliceA.(type) {
 				case *[]*Record:
-					records, err := resolveRecordAllHook(collection, op)
+					records, err := resolveRecordAllHook(app, collection, op)
 					if err != nil {
 						return err
 					}
@@ -88,7 +106,7 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 
 					return nil
 				case *[]Record:
-					records, err := resolveRecordAllHook(collection, op)
+					records, err := resolveRecordAllHook(app, collection, op)
 					if err != nil {
 						return err
 					}
@@ -102,7 +120,7 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 
 					return nil
 				default: // expects []RecordProxy slice
-					records, err := resolveRecordAllHook(collection, op)
+					records, err := resolveRecordAllHook(app, collection, op)
 					if err != nil {
 						return err
 					}
@@ -163,20 +181,39 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 	})
 }
 
-func resolveRecordOneHook(collection *Collection, op func(dst any) error) (*Record, error) {
+func resolveRecordOneHook(app App, collection *Collection, op func(dst any) error) (*Record, error) {
 	data := dbx.NullStringMap{}
 	if err := op(&data); err != nil {
 		return nil, err
 	}
-	return newRecordFromNullStringMap(collection, data)
+
+	record, err := newRecordFromNullStringMap(collection, data)
+	if err != nil {
+		return nil, err
+	}
+
+	record.SetTokenSigner(app.TokenSigner())
+
+	return record, nil
 }
 
-func resolveRecordAllHook(collection *Collection, op func(dst any) error) ([]*Record, error) {
+func resolveRecordAllHook(app App, collection *Collection, op func(dst any) error) ([]*Record, error) {
 	data := []dbx.NullStringMap{}
 	if err := op(&data); err != nil {
 		return nil, err
 	}
-	return newRecordsFromNullStringMaps(collection, data)
+
+	records, err := newRecordsFromNullStringMaps(collection, data)
+	if err != nil {
+		return nil, err
+	}
+
+	signer := app.TokenSigner()
+	for _, r := range records {
+		r.SetTokenSigner(signer)
+	}
+
+	return records, nil
 }
 
 // dereference returns the underlying value v points to.
@@ -386,10 +423,13 @@ func (app *BaseApp) FindRecordsByFilter(
 
 	if sort != "" {
 		for _, sortField := range search.ParseSortFromString(sort) {
-			expr, err := sortField.BuildExpr(resolver)
+			expr, sortParams, err := sortField.BuildExprWithParams(resolver)
 			if err != nil {
 				return nil, err
 			}
+			if len(sortParams) > 0 {
+				q.AndBind(sortParams)
+			}
 			if expr != "" {
 				q.AndOrderBy(expr)
 			}
@@ -461,7 +501,7 @@ func (app *BaseApp) CountRecords(collectionModelOrIdentifier any, exprs ...dbx.E
 }
 
 // FindAuthRecordByToken finds the auth record associated with the provided JWT
-// (auth, file, verifyEmail, changeEmail, passwordReset types).
+// (auth, file, verifyEmail, changeEmail, passwordReset, delegated types).
 //
 // Optionally specify a list of validTypes to check tokens only from those types.
 //
@@ -500,8 +540,10 @@ func (app *BaseApp) FindAuthRecordByToken(token string, validTypes ...string) (*
 
 	var baseTokenKey string
 	switch tokenType {
-	case TokenTypeAuth:
+	case TokenTypeAuth, TokenTypeDelegated:
 		baseTokenKey = record.Collection().AuthToken.Secret
+	case TokenTypeMagicLink:
+		baseTokenKey = record.Collection().MagicLinkToken.Secret
 	case TokenTypeFile:
 		baseTokenKey = record.Collection().FileToken.Secret
 	case TokenTypeVerification:
@@ -517,11 +559,17 @@ func (app *BaseApp) FindAuthRecordByToken(token string, validTypes ...string) (*
 	secret := record.TokenKey() + baseTokenKey
 
 	// verify token signature
-	_, err = security.ParseJWT(token, secret)
+	_, err = app.TokenSigner().Verify(token, secret)
 	if err != nil {
 		return nil, err
 	}
 
+	if tokenType == TokenTypeAuth && record.Collection().AuthSession.Track {
+		if err := app.checkAuthTokenSession(record, unverifiedClaims); err != nil {
+			return nil, err
+		}
+	}
+
 	return record, nil
 }
 
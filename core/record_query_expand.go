@@ -30,39 +30,97 @@ func (app *BaseApp) ExpandRecord(record *Record, expands []string, optFetchFunc
 // If optFetchFunc is not set, then a default function will be used
 // that returns all relation records.
 //
+// The relations are fetched in batches for the entire records list,
+// aka. the number of fetches depends only on the number of the expand
+// paths segments and not on the number of records.
+// Expand paths with common prefix (eg. "a.b" and "a.c") share the same fetch.
+//
 // Returns a map with the failed expand parameters and their errors.
 func (app *BaseApp) ExpandRecords(records []*Record, expands []string, optFetchFunc ExpandFetchFunc) map[string]error {
 	normalized := normalizeExpands(expands)
 
 	failed := map[string]error{}
 
-	for _, expand := range normalized {
-		if err := app.expandRecords(records, expand, optFetchFunc, 1); err != nil {
-			failed[expand] = err
+	if optFetchFunc == nil {
+		// load a default fetchFunc
+		optFetchFunc = func(relCollection *Collection, relIds []string) ([]*Record, error) {
+			return app.FindRecordsByIds(relCollection.Id, relIds)
+		}
+	}
+
+	for _, node := range newExpandTree(normalized) {
+		if err := app.expandRecords(records, node, optFetchFunc, 1, failed); err != nil {
+			node.markFailed(failed, err)
 		}
 	}
 
 	return failed
 }
 
+// expandNode represents a single expand path segment
+// (eg. "a.b" and "a.c" are represented as node "a" with children "b" and "c").
+type expandNode struct {
+	name     string
+	paths    []string // the full normalized expand paths containing the node
+	children []*expandNode
+}
+
+// markFailed registers the error for all node paths that are not already marked as failed.
+func (node *expandNode) markFailed(failed map[string]error, err error) {
+	for _, path := range node.paths {
+		if _, ok := failed[path]; !ok {
+			failed[path] = err
+		}
+	}
+}
+
+// newExpandTree groups the provided normalized expand paths by their common segments.
+func newExpandTree(paths []string) []*expandNode {
+	root := &expandNode{}
+
+	for _, path := range paths {
+		parent := root
+
+		for _, name := range strings.Split(path, ".") {
+			var node *expandNode
+			for _, child := range parent.children {
+				if child.name == name {
+					node = child
+					break
+				}
+			}
+
+			if node == nil {
+				node = &expandNode{name: name}
+				parent.children = append(parent.children, node)
+			}
+
+			node.paths = append(node.paths, path)
+
+			parent = node
+		}
+	}
+
+	return root.children
+}
+
 // Deprecated
 var indirectExpandRegexOld = regexp.MustCompile(`^(\w+)\((\w+)\)$`)
 
 var indirectExpandRegex = regexp.MustCompile(`^(\w+)_via_(\w+)$`)
 
+// errNestedExpandsFailed is returned when all nested expands of a node has failed
+// (their errors are already registered in the failed map).
+var errNestedExpandsFailed = errors.New("all nested expands failed")
+
+// indirectExpandBatchSize is the max number of records ids to use in a single back-relation query.
+const indirectExpandBatchSize = 500
+
 // notes:
-// - if fetchFunc is nil, dao.FindRecordsByIds will be used
 // - all records are expected to be from the same collection
 // - if maxNestedRels(6) is reached, the function returns nil ignoring the remaining expand path
-func (app *BaseApp) expandRecords(records []*Record, expandPath string, fetchFunc ExpandFetchFunc, recursionLevel int) error {
-	if fetchFunc == nil {
-		// load a default fetchFunc
-		fetchFunc = func(relCollection *Collection, relIds []string) ([]*Record, error) {
-			return app.FindRecordsByIds(relCollection.Id, relIds)
-		}
-	}
-
-	if expandPath == "" || recursionLevel > maxNestedRels || len(records) == 0 {
+func (app *BaseApp) expandRecords(records []*Record, node *expandNode, fetchFunc ExpandFetchFunc, recursionLevel int, failed map[string]error) error {
+	if recursionLevel > maxNestedRels || len(records) == 0 {
 		return nil
 	}
 
@@ -71,12 +129,11 @@ func (app *BaseApp) expandRecords(records []*Record, expandPath string, fetchFun
 	var relField *RelationField
 	var relCollection *Collection
 
-	parts := strings.SplitN(expandPath, ".", 2)
 	var matches []string
 
 	// @todo remove the old syntax support
-	if strings.Contains(parts[0], "(") {
-		matches = indirectExpandRegexOld.FindStringSubmatch(parts[0])
+	if strings.Contains(node.name, "(") {
+		matches = indirectExpandRegexOld.FindStringSubmatch(node.name)
 		if len(matches) == 3 {
 			log.Printf(
 				"%s expand format is deprecated and will be removed in the future. Consider replacing it with %s_via_%s.\n",
@@ -86,7 +143,7 @@ func (app *BaseApp) expandRecords(records []*Record, expandPath string, fetchFun
 			)
 		}
 	} else {
-		matches = indirectExpandRegex.FindStringSubmatch(parts[0])
+		matches = indirectExpandRegex.FindStringSubmatch(node.name)
 	}
 
 	if len(matches) == 3 {
@@ -102,44 +159,13 @@ func (app *BaseApp) expandRecords(records []*Record, expandPath string, fetchFun
 
 		// add the related id(s) as a dynamic relation field value to
 		// allow further expand checks at later stage in a more unified manner
-		prepErr := func() error {
-			q := app.DB().Select("id").
-				From(indirectRel.Name).
-				Limit(1000) // the limit is arbitrary chosen and may change in the future
-
-			if indirectRelField.IsMultiple() {
-				q.AndWhere(dbx.Exists(dbx.NewExp(fmt.Sprintf(
-					"SELECT 1 FROM %s je WHERE je.value = {:id}",
-					dbutils.JSONEach(indirectRelField.Name),
-				))))
-			} else {
-				q.AndWhere(dbx.NewExp("[[" + indirectRelField.Name + "]] = {:id}"))
-			}
-
-			pq := q.Build().Prepare()
-
-			for _, record := range records {
-				var relIds []string
-
-				err := pq.Bind(dbx.Params{"id": record.Id}).Column(&relIds)
-				if err != nil {
-					return errors.Join(err, pq.Close())
-				}
-
-				if len(relIds) > 0 {
-					record.Set(parts[0], relIds)
-				}
-			}
-
-			return pq.Close()
-		}()
-		if prepErr != nil {
-			return prepErr
+		if err := app.loadIndirectRelIds(records, node.name, indirectRel, indirectRelField); err != nil {
+			return err
 		}
 
 		// indirect/back relation
 		relField = &RelationField{
-			Name:         parts[0],
+			Name:         node.name,
 			MaxSelect:    2147483647,
 			CollectionId: indirectRel.Id,
 		}
@@ -149,9 +175,9 @@ func (app *BaseApp) expandRecords(records []*Record, expandPath string, fetchFun
 		relCollection = indirectRel
 	} else {
 		// direct relation
-		relField, _ = mainCollection.Fields.GetByName(parts[0]).(*RelationField)
+		relField, _ = mainCollection.Fields.GetByName(node.name).(*RelationField)
 		if relField == nil {
-			return fmt.Errorf("couldn't find relation field %q in collection %q", parts[0], mainCollection.Name)
+			return fmt.Errorf("couldn't find relation field %q in collection %q", node.name, mainCollection.Name)
 		}
 
 		relCollection, _ = getCollectionByModelOrIdentifier(app, relField.CollectionId)
@@ -162,11 +188,12 @@ func (app *BaseApp) expandRecords(records []*Record, expandPath string, fetchFun
 
 	// ---------------------------------------------------------------
 
-	// extract the id of the relations to expand
+	// extract the unique ids of the relations to expand
 	relIds := make([]string, 0, len(records))
 	for _, record := range records {
 		relIds = append(relIds, record.GetStringSlice(relField.Name)...)
 	}
+	relIds = list.ToUniqueStringSlice(relIds)
 
 	// fetch rels
 	rels, relsErr := fetchFunc(relCollection, relIds)
@@ -175,10 +202,20 @@ func (app *BaseApp) expandRecords(records []*Record, expandPath string, fetchFun
 	}
 
 	// expand nested fields
-	if len(parts) > 1 {
-		err := app.expandRecords(rels, parts[1], fetchFunc, recursionLevel+1)
-		if err != nil {
-			return err
+	if len(node.children) > 0 {
+		var hasNestedSuccess bool
+
+		for _, child := range node.children {
+			err := app.expandRecords(rels, child, fetchFunc, recursionLevel+1, failed)
+			if err != nil {
+				child.markFailed(failed, err)
+			} else {
+				hasNestedSuccess = true
+			}
+		}
+
+		if !hasNestedSuccess {
+			return errNestedExpandsFailed
 		}
 	}
 
@@ -225,7 +262,7 @@ func (app *BaseApp) expandRecords(records []*Record, expandPath string, fetchFun
 		for _, oldExpandedRel := range oldExpandedRels {
 			// find a matching rel record
 			for _, rel := range validRels {
-				if rel.Id != oldExpandedRel.Id {
+				if rel.Id != oldExpandedRel.Id || rel == oldExpandedRel {
 					continue
 				}
 
@@ -246,6 +283,62 @@ func (app *BaseApp) expandRecords(records []*Record, expandPath string, fetchFun
 	return nil
 }
 
+// loadIndirectRelIds loads the back-relation ids of the provided records
+// as dynamic field with the specified name.
+//
+// The ids are fetched in batches with a single query per indirectExpandBatchSize records.
+func (app *BaseApp) loadIndirectRelIds(records []*Record, name string, indirectRel *Collection, indirectRelField *RelationField) error {
+	// the limit is arbitrary chosen and may change in the future
+	const limitPerRecord = 1000
+
+	var ownerExpr string
+	var fromExpr string
+	if indirectRelField.IsMultiple() {
+		ownerExpr = "[[__je.value]]"
+		fromExpr = "{{" + indirectRel.Name + "}}, " + dbutils.JSONEach(indirectRel.Name+"."+indirectRelField.Name) + " {{__je}}"
+	} else {
+		ownerExpr = "[[" + indirectRel.Name + "." + indirectRelField.Name + "]]"
+		fromExpr = "{{" + indirectRel.Name + "}}"
+	}
+
+	for i := 0; i < len(records); i += indirectExpandBatchSize {
+		chunk := records[i:min(i+indirectExpandBatchSize, len(records))]
+
+		ownerIds := make([]any, len(chunk))
+		for j, record := range chunk {
+			ownerIds[j] = record.Id
+		}
+
+		rows := []struct {
+			Id    string `db:"id"`
+			Owner string `db:"owner"`
+		}{}
+
+		err := app.DB().Select(
+			"[["+indirectRel.Name+".id]] as [[id]]",
+			ownerExpr+" as [[owner]]",
+		).From(fromExpr).AndWhere(dbx.In(ownerExpr, ownerIds...)).All(&rows)
+		if err != nil {
+			return err
+		}
+
+		grouped := make(map[string][]string, len(chunk))
+		for _, row := range rows {
+			if len(grouped[row.Owner]) < limitPerRecord {
+				grouped[row.Owner] = append(grouped[row.Owner], row.Id)
+			}
+		}
+
+		for _, record := range chunk {
+			if relIds := grouped[record.Id]; len(relIds) > 0 {
+				record.Set(name, relIds)
+			}
+		}
+	}
+
+	return nil
+}
+
 // normalizeExpands normalizes expand strings and merges self containing paths
 // (eg. ["a.b.c", "a.b", "   test  ", "  ", "test"] -> ["a.b.c", "test"]).
 func normalizeExpands(paths []string) []string {
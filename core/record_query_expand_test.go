@@ -1,11 +1,15 @@
 package core_test
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/list"
@@ -484,3 +488,89 @@ func TestBackRelationExpandSingeVsArrayResult(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandRecordsQueriesCount(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	expands := []string{
+		"demo4_via_rel_many_no_cascade_required.self_rel_many",
+		"demo4_via_rel_many_no_cascade_required.self_rel_one",
+		"demo4_via_rel_one_no_cascade_required",
+	}
+
+	var totalQueries int
+	app.DB().(*dbx.DB).QueryLogFunc = func(ctx context.Context, t time.Duration, sql string, rows *sql.Rows, err error) {
+		totalQueries++
+	}
+
+	scenarios := []struct {
+		name      string
+		recordIds []string
+	}{
+		{"single record", []string{"lcl9d87w22ml6jy"}},
+		{"multiple records", []string{"lcl9d87w22ml6jy", "1tmknxy2868d869", "7nwo8tuiatetxdm", "mk5fmymtx4wsprk"}},
+	}
+
+	var lastQueries int
+
+	for i, s := range scenarios {
+		records, err := app.FindRecordsByIds("demo3", s.recordIds)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var totalFetches int
+
+		totalQueries = 0
+
+		failed := app.ExpandRecords(records, expands, func(c *core.Collection, ids []string) ([]*core.Record, error) {
+			totalFetches++
+			return app.FindRecordsByIds(c.Id, ids)
+		})
+		if len(failed) > 0 {
+			t.Fatalf("[%s] Expected no expand failures, got %v", s.name, failed)
+		}
+
+		// the common "demo4_via_rel_many_no_cascade_required" path segment should be fetched only once
+		if totalFetches != 4 {
+			t.Fatalf("[%s] Expected %d fetches, got %d", s.name, 4, totalFetches)
+		}
+
+		if i > 0 && totalQueries != lastQueries {
+			t.Fatalf("[%s] Expected the queries count to not depend on the number of records (%d vs %d)", s.name, lastQueries, totalQueries)
+		}
+
+		lastQueries = totalQueries
+	}
+}
+
+func BenchmarkExpandRecords(b *testing.B) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	records, err := app.FindAllRecords("demo3")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	expands := []string{
+		"demo4_via_rel_many_no_cascade_required.self_rel_many.rel_many_no_cascade_required",
+		"demo4_via_rel_many_no_cascade_required.self_rel_one",
+		"demo4_via_rel_one_no_cascade_required.rel_one_no_cascade",
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, r := range records {
+			r.SetExpand(nil)
+		}
+
+		if failed := app.ExpandRecords(records, expands, nil); len(failed) > 0 {
+			b.Fatal(failed)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// RecordSearchSnippetKey is the name of the custom record data key that
+// holds the highlighted full-text search snippet (see [FindRecordSearchSnippets]).
+const RecordSearchSnippetKey = "searchSnippet"
+
+const recordSearchTablePrefix = "_fts_"
+
+// SearchTableName returns the name of the collection FTS5 full-text search table.
+//
+// The table name is based on the collection id so that it remains
+// the same in case of a collection rename.
+func (m *Collection) SearchTableName() string {
+	return recordSearchTablePrefix + m.Id
+}
+
+// IsSearchable reports whether the collection has full-text search fields configured.
+func (m *Collection) IsSearchable() bool {
+	return !m.IsView() && len(m.SearchFields) > 0
+}
+
+// syncRecordSearchTable (re)creates the collection full-text search table
+// and reindexes all collection records if the search fields have changed.
+//
+// If the new collection doesn't have any search fields, the search table is dropped.
+func syncRecordSearchTable(app App, newCollection *Collection, oldCollection *Collection) error {
+	tableName := newCollection.SearchTableName()
+
+	if !newCollection.IsSearchable() {
+		return app.DeleteTable(tableName)
+	}
+
+	if oldCollection != nil &&
+		oldCollection.IsSearchable() &&
+		oldCollection.SearchFields.String() == newCollection.SearchFields.String() &&
+		app.HasTable(tableName) {
+		return nil // no changes
+	}
+
+	if err := app.DeleteTable(tableName); err != nil {
+		return err
+	}
+
+	columns := make([]string, 0, len(newCollection.SearchFields)+1)
+	columns = append(columns, "[["+FieldNameId+"]] UNINDEXED")
+	for _, name := range newCollection.SearchFields {
+		columns = append(columns, "[["+name+"]]")
+	}
+
+	_, err := app.DB().NewQuery(fmt.Sprintf(
+		"CREATE VIRTUAL TABLE {{%s}} USING fts5(%s, tokenize='unicode61 remove_diacritics 2')",
+		tableName,
+		strings.Join(columns, ", "),
+	)).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to create the search table: %w", err)
+	}
+
+	// index the existing records
+	columns[0] = "[[" + FieldNameId + "]]"
+	_, err = app.DB().NewQuery(fmt.Sprintf(
+		"INSERT INTO {{%s}} (%s) SELECT %s FROM {{%s}}",
+		tableName,
+		strings.Join(columns, ", "),
+		strings.Join(columns, ", "),
+		newCollection.Name,
+	)).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to index the existing records: %w", err)
+	}
+
+	return nil
+}
+
+// indexRecordSearchData replaces the search table entry of the provided record.
+func indexRecordSearchData(app App, record *Record) error {
+	collection := record.Collection()
+
+	if err := deleteRecordSearchData(app, record); err != nil {
+		return err
+	}
+
+	data := make(dbx.Params, len(collection.SearchFields)+1)
+	data[FieldNameId] = record.Id
+	for _, name := range collection.SearchFields {
+		data[name] = record.GetString(name)
+	}
+
+	_, err := app.DB().Insert(collection.SearchTableName(), data).Execute()
+
+	return err
+}
+
+// deleteRecordSearchData removes the search table entry of the provided record.
+func deleteRecordSearchData(app App, record *Record) error {
+	_, err := app.DB().Delete(record.Collection().SearchTableName(), dbx.HashExp{
+		FieldNameId: record.Id,
+	}).Execute()
+
+	return err
+}
+
+func (app *BaseApp) registerRecordSearchHooks() {
+	indexHandler := func(e *RecordEvent) error {
+		if !e.Record.Collection().IsSearchable() {
+			return e.Next()
+		}
+
+		originalApp := e.App
+		txErr := e.App.RunInTransaction(func(txApp App) error {
+			e.App = txApp
+
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return indexRecordSearchData(txApp, e.Record)
+		})
+		e.App = originalApp
+
+		return txErr
+	}
+
+	app.OnRecordCreateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func:     indexHandler,
+		Priority: 99,
+	})
+
+	app.OnRecordUpdateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func:     indexHandler,
+		Priority: 99,
+	})
+
+	app.OnRecordDeleteExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			if !e.Record.Collection().IsSearchable() {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				return deleteRecordSearchData(txApp, e.Record)
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+}
+
+// -------------------------------------------------------------------
+
+// NormalizeSearchTerm converts the provided plain user search term into
+// a safe FTS5 MATCH expression.
+//
+// Each whitespace separated word is quoted and matched as a separate
+// phrase (all words must match) and the last word is matched as prefix.
+//
+// If columns are specified, the match is restricted only to them.
+//
+// Returns empty string if the term doesn't have any words.
+func NormalizeSearchTerm(term string, columns ...string) string {
+	words := strings.Fields(term)
+	if len(words) == 0 {
+		return ""
+	}
+
+	for i, w := range words {
+		words[i] = `"` + strings.ReplaceAll(w, `"`, `""`) + `"`
+	}
+	words[len(words)-1] += "*"
+
+	expr := strings.Join(words, " ")
+
+	if len(columns) > 0 {
+		quoted := make([]string, len(columns))
+		for i, c := range columns {
+			quoted[i] = `"` + strings.ReplaceAll(c, `"`, `""`) + `"`
+		}
+		expr = "{" + strings.Join(quoted, " ") + "}: (" + expr + ")"
+	}
+
+	return expr
+}
+
+// ApplyRecordSearch modifies the provided collection records query to
+// return only the records matching the full-text search term.
+//
+// If orderByRank is true, the results are also ordered by their relevance.
+//
+// columns could be used to restrict the search only to some of the
+// collection search fields (default to all of them).
+func ApplyRecordSearch(query *dbx.SelectQuery, collection *Collection, term string, orderByRank bool, columns ...string) error {
+	if !collection.IsSearchable() {
+		return errors.New("the collection doesn't support full-text search")
+	}
+
+	for _, c := range columns {
+		if !slices.Contains(collection.SearchFields, c) {
+			return fmt.Errorf("%q is not a collection search field", c)
+		}
+	}
+
+	match := NormalizeSearchTerm(term, columns...)
+	if match == "" {
+		return nil // nothing to search
+	}
+
+	tableName := collection.SearchTableName()
+	placeholder := "search" + security.PseudorandomString(6)
+
+	query.
+		InnerJoin(
+			"{{"+tableName+"}}",
+			dbx.NewExp("[["+tableName+"."+FieldNameId+"]] = [["+collection.Name+"."+FieldNameId+"]]"),
+		).
+		AndWhere(dbx.NewExp(
+			"[["+tableName+"]] MATCH {:"+placeholder+"}",
+			dbx.Params{placeholder: match},
+		))
+
+	if orderByRank {
+		query.AndOrderBy("[[" + tableName + ".rank]] ASC")
+	}
+
+	return nil
+}
+
+// FindRecordSearchSnippets returns the highlighted full-text search
+// snippets of the specified collection records (the matched words are
+// wrapped in <mark></mark>) as "recordId: snippet" map.
+//
+// Records without a match are not included in the result.
+func FindRecordSearchSnippets(app App, collection *Collection, term string, recordIds []string, columns ...string) (map[string]string, error) {
+	result := map[string]string{}
+
+	if !collection.IsSearchable() {
+		return nil, errors.New("the collection doesn't support full-text search")
+	}
+
+	match := NormalizeSearchTerm(term, columns...)
+	if match == "" || len(recordIds) == 0 {
+		return result, nil
+	}
+
+	tableName := collection.SearchTableName()
+
+	rows := []struct {
+		Id      string `db:"id"`
+		Snippet string `db:"snippet"`
+	}{}
+
+	err := app.DB().
+		Select(
+			"[["+tableName+"."+FieldNameId+"]] as [[id]]",
+			"snippet([["+tableName+"]], -1, '<mark>', '</mark>', '...', 16) as [[snippet]]",
+		).
+		From(tableName).
+		Where(dbx.NewExp("[["+tableName+"]] MATCH {:match}", dbx.Params{"match": match})).
+		AndWhere(dbx.In(tableName+"."+FieldNameId, list.ToInterfaceSlice(recordIds)...)).
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.Id] = row.Snippet
+	}
+
+	return result, nil
+}
@@ -0,0 +1,222 @@
+package core_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNormalizeSearchTerm(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		term     string
+		columns  []string
+		expected string
+	}{
+		{"", nil, ""},
+		{"   ", nil, ""},
+		{"test", nil, `"test"*`},
+		{" hello   wor ", nil, `"hello" "wor"*`},
+		{`a"b OR c* NEAR(`, nil, `"a""b" "OR" "c*" "NEAR("*`},
+		{"hello world", []string{"title", `a"b`}, `{"title" "a""b"}: ("hello" "world"*)`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.term, func(t *testing.T) {
+			result := core.NormalizeSearchTerm(s.term, s.columns...)
+			if result != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestRecordSearch(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("articles")
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.EditorField{Name: "content"},
+		&core.TextField{Name: "notes"},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// create some records before enabling the search to check the initial indexing
+	for _, data := range []map[string]any{
+		{"id": "a1aaaaaaaaaaaaa", "title": "Go concurrency patterns", "content": "Channels and goroutines", "notes": "draft"},
+		{"id": "a2aaaaaaaaaaaaa", "title": "SQLite internals", "content": "The FTS5 extension and the query planner", "notes": "concurrency"},
+	} {
+		record := core.NewRecord(collection)
+		record.Load(data)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if app.HasTable(collection.SearchTableName()) {
+		t.Fatal("Expected the search table to not be created")
+	}
+
+	search := func(term string, columns ...string) []string {
+		records := []*core.Record{}
+
+		query := app.RecordQuery(collection)
+		if err := core.ApplyRecordSearch(query, collection, term, true, columns...); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := query.All(&records); err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([]string, len(records))
+		for i, r := range records {
+			ids[i] = r.Id
+		}
+
+		return ids
+	}
+
+	checkIds := func(expected []string, result []string) {
+		t.Helper()
+		if !slices.Equal(expected, result) {
+			t.Fatalf("Expected ids %v, got %v", expected, result)
+		}
+	}
+
+	// enable search
+	collection.SearchFields = []string{"title", "content", "notes"}
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if !app.HasTable(collection.SearchTableName()) {
+		t.Fatal("Expected the search table to be created")
+	}
+
+	checkIds([]string{"a1aaaaaaaaaaaaa", "a2aaaaaaaaaaaaa"}, search("concurrency"))
+	checkIds([]string{"a2aaaaaaaaaaaaa"}, search("fts5 plan")) // last word prefix
+	checkIds([]string{}, search("fts5 goroutines"))            // all words must match
+	checkIds([]string{"a1aaaaaaaaaaaaa"}, search("concurrency", "title", "content"))
+	checkIds([]string{}, search(`"unbalanced OR (`))
+
+	// create
+	record := core.NewRecord(collection)
+	record.Load(map[string]any{"id": "a3aaaaaaaaaaaaa", "title": "Concurrency concurrency everywhere"})
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	checkIds([]string{"a3aaaaaaaaaaaaa", "a1aaaaaaaaaaaaa", "a2aaaaaaaaaaaaa"}, search("concurrency"))
+
+	// update
+	record.Set("title", "Café crème")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	checkIds([]string{"a1aaaaaaaaaaaaa", "a2aaaaaaaaaaaaa"}, search("concurrency"))
+	checkIds([]string{"a3aaaaaaaaaaaaa"}, search("cafe creme")) // diacritics insensitive
+
+	// snippets
+	snippets, err := core.FindRecordSearchSnippets(app, collection, "creme", []string{"a1aaaaaaaaaaaaa", "a3aaaaaaaaaaaaa"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snippets) != 1 || snippets["a3aaaaaaaaaaaaa"] != "Café <mark>crème</mark>" {
+		t.Fatalf("Unexpected snippets %v", snippets)
+	}
+
+	// delete
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+	checkIds([]string{}, search("cafe"))
+
+	// change the search fields
+	collection.SearchFields = []string{"title"}
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	checkIds([]string{"a1aaaaaaaaaaaaa"}, search("concurrency"))
+
+	if err := core.ApplyRecordSearch(app.RecordQuery(collection), collection, "test", true, "notes"); err == nil {
+		t.Fatal("Expected error for non search field column")
+	}
+
+	// rename the collection
+	collection.Name = "articles_new"
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	checkIds([]string{"a1aaaaaaaaaaaaa"}, search("concurrency"))
+
+	// disable search
+	collection.SearchFields = nil
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.HasTable(collection.SearchTableName()) {
+		t.Fatal("Expected the search table to be deleted")
+	}
+
+	if err := core.ApplyRecordSearch(app.RecordQuery(collection), collection, "test", true); err == nil {
+		t.Fatal("Expected error for non searchable collection")
+	}
+
+	// delete the collection
+	collection.SearchFields = []string{"title"}
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Delete(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.HasTable(collection.SearchTableName()) {
+		t.Fatal("Expected the search table to be deleted together with the collection")
+	}
+}
+
+func TestRecordSearchTransactionRollback(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("articles")
+	collection.Fields.Add(&core.TextField{Name: "title"})
+	collection.SearchFields = []string{"title"}
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	app.RunInTransaction(func(txApp core.App) error {
+		record := core.NewRecord(collection)
+		record.Set("title", "rollback")
+		if err := txApp.Save(record); err != nil {
+			t.Fatal(err)
+		}
+
+		return errors.New("rollback")
+	})
+
+	var total int
+	err := app.DB().Select("count(*)").From(collection.SearchTableName()).Row(&total)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 0 {
+		t.Fatalf("Expected no indexed records after the rollback, got %d", total)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
-	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
 )
 
 // Supported record token types
@@ -15,6 +15,9 @@ const (
 	TokenTypeVerification  = "verification"
 	TokenTypePasswordReset = "passwordReset"
 	TokenTypeEmailChange   = "emailChange"
+	TokenTypeMagicLink     = "magicLink"
+	TokenTypeAction        = "action"
+	TokenTypeDelegated     = "delegated"
 )
 
 // List with commonly used record token claims
@@ -25,14 +28,47 @@ const (
 	TokenClaimEmail        = "email"
 	TokenClaimNewEmail     = "newEmail"
 	TokenClaimRefreshable  = "refreshable"
+	TokenClaimOTPId        = "otpId"
+	TokenClaimAction       = "action"
+	TokenClaimData         = "data"
+	TokenClaimNonce        = "nonce"
+	TokenClaimAudience     = "aud"
+	TokenClaimScopes       = "scopes"
+
+	// note: custom claims are used instead of the standard "iat" to
+	// avoid rejecting the tokens due to clock skew between app instances
+	TokenClaimIssuedAt     = "issuedAt"
+	TokenClaimSessionStart = "sessionStart"
+
+	// TokenClaimSessionId is the id of the related Session model
+	// (set only for the auth collections with enabled session tracking).
+	TokenClaimSessionId = "sessionId"
 )
 
 // Common token related errors
 var (
-	ErrNotAuthRecord     = errors.New("not an auth collection record")
-	ErrMissingSigningKey = errors.New("missing or invalid signing key")
+	ErrNotAuthRecord      = errors.New("not an auth collection record")
+	ErrMissingSigningKey  = errors.New("missing or invalid signing key")
+	ErrAuthSessionExpired = errors.New("the auth session max lifetime has been reached")
+	ErrAuthSessionRevoked = errors.New("the auth session is missing or has been revoked")
 )
 
+// SetTokenSigner sets the [TokenSigner] used for generating the record tokens.
+//
+// Records loaded with the app record queries have it set automatically to [App.TokenSigner].
+// If not set or nil, it fallbacks to [HS256TokenSigner].
+func (m *Record) SetTokenSigner(signer TokenSigner) {
+	m.tokenSigner = signer
+}
+
+func (m *Record) signer() TokenSigner {
+	if m.tokenSigner == nil {
+		return HS256TokenSigner{}
+	}
+
+	return m.tokenSigner
+}
+
 // NewStaticAuthToken generates and returns a new static record authentication token.
 //
 // Static auth tokens are similar to the regular auth tokens, but are
@@ -40,15 +76,40 @@ var (
 //
 // Zero or negative duration will fallback to the duration from the auth collection settings.
 func (m *Record) NewStaticAuthToken(duration time.Duration) (string, error) {
-	return m.newAuthToken(duration, false)
+	return m.newAuthToken(duration, false, time.Time{})
 }
 
 // NewAuthToken generates and returns a new record authentication token.
 func (m *Record) NewAuthToken() (string, error) {
-	return m.newAuthToken(0, true)
+	return m.newAuthToken(0, true, time.Time{})
+}
+
+// NewSessionAuthToken generates and returns a new refreshable record
+// authentication token that continues an existing auth session started
+// at sessionStart (e.g. on auth refresh or sliding renewal).
+//
+// The token expiration is capped to the auth collection session max lifetime
+// and [ErrAuthSessionExpired] is returned if it has already been reached.
+func (m *Record) NewSessionAuthToken(sessionStart time.Time) (string, error) {
+	return m.newAuthToken(0, true, sessionStart)
 }
 
-func (m *Record) newAuthToken(duration time.Duration, refreshable bool) (string, error) {
+// NewTrackedAuthToken generates and returns a new refreshable record
+// authentication token linked to the provided server-side session.
+//
+// The auth session start is set to the session created date.
+//
+// The token is valid only as long as the session exists
+// (see [AuthSessionConfig.Track]).
+func (m *Record) NewTrackedAuthToken(session *Session) (string, error) {
+	if session == nil || session.Id == "" {
+		return "", ErrAuthSessionRevoked
+	}
+
+	return m.newAuthToken(0, true, session.Created().Time(), session.Id)
+}
+
+func (m *Record) newAuthToken(duration time.Duration, refreshable bool, sessionStart time.Time, optSessionId ...string) (string, error) {
 	if !m.Collection().IsAuth() {
 		return "", ErrNotAuthRecord
 	}
@@ -58,18 +119,59 @@ func (m *Record) newAuthToken(duration time.Duration, refreshable bool) (string,
 		return "", ErrMissingSigningKey
 	}
 
+	now := time.Now()
+	if sessionStart.IsZero() {
+		sessionStart = now
+	}
+
 	claims := jwt.MapClaims{
 		TokenClaimType:         TokenTypeAuth,
 		TokenClaimId:           m.Id,
 		TokenClaimCollectionId: m.Collection().Id,
 		TokenClaimRefreshable:  refreshable,
+		TokenClaimIssuedAt:     now.Unix(),
+		TokenClaimSessionStart: sessionStart.Unix(),
+	}
+
+	if len(optSessionId) > 0 && optSessionId[0] != "" {
+		claims[TokenClaimSessionId] = optSessionId[0]
 	}
 
 	if duration <= 0 {
 		duration = m.Collection().AuthToken.DurationTime()
 	}
 
-	return security.NewJWT(claims, key, duration)
+	if maxLifetime := m.Collection().AuthSession.MaxLifetimeTime(); refreshable && maxLifetime > 0 {
+		remaining := sessionStart.Add(maxLifetime).Sub(now)
+		if remaining <= 0 {
+			return "", ErrAuthSessionExpired
+		}
+		duration = min(duration, remaining)
+	}
+
+	return m.signer().Sign(claims, key, duration)
+}
+
+// AuthTokenSessionTimes returns the issue and the session start times
+// of the provided auth token claims.
+//
+// For tokens without the session claims (aka. generated with an older app version)
+// the issue time is calculated from the token expiration and the current
+// auth collection token duration.
+func AuthTokenSessionTimes(claims jwt.MapClaims, collection *Collection) (issuedAt time.Time, sessionStart time.Time) {
+	if v, ok := claims[TokenClaimIssuedAt]; ok {
+		issuedAt = time.Unix(cast.ToInt64(v), 0)
+	} else {
+		issuedAt = time.Unix(cast.ToInt64(claims["exp"]), 0).Add(-collection.AuthToken.DurationTime())
+	}
+
+	if v, ok := claims[TokenClaimSessionStart]; ok {
+		sessionStart = time.Unix(cast.ToInt64(v), 0)
+	} else {
+		sessionStart = issuedAt
+	}
+
+	return issuedAt, sessionStart
 }
 
 // NewVerificationToken generates and returns a new record verification token.
@@ -83,7 +185,7 @@ func (m *Record) NewVerificationToken() (string, error) {
 		return "", ErrMissingSigningKey
 	}
 
-	return security.NewJWT(
+	return m.signer().Sign(
 		jwt.MapClaims{
 			TokenClaimType:         TokenTypeVerification,
 			TokenClaimId:           m.Id,
@@ -106,7 +208,7 @@ func (m *Record) NewPasswordResetToken() (string, error) {
 		return "", ErrMissingSigningKey
 	}
 
-	return security.NewJWT(
+	return m.signer().Sign(
 		jwt.MapClaims{
 			TokenClaimType:         TokenTypePasswordReset,
 			TokenClaimId:           m.Id,
@@ -129,7 +231,7 @@ func (m *Record) NewEmailChangeToken(newEmail string) (string, error) {
 		return "", ErrMissingSigningKey
 	}
 
-	return security.NewJWT(
+	return m.signer().Sign(
 		jwt.MapClaims{
 			TokenClaimType:         TokenTypeEmailChange,
 			TokenClaimId:           m.Id,
@@ -142,6 +244,34 @@ func (m *Record) NewEmailChangeToken(newEmail string) (string, error) {
 	)
 }
 
+// NewMagicLinkToken generates and returns a new passwordless
+// magic link auth token linked to the specified OTP.
+//
+// The linked OTP is deleted on successful auth to ensure that the
+// magic link could be used only once.
+func (m *Record) NewMagicLinkToken(otpId string) (string, error) {
+	if !m.Collection().IsAuth() {
+		return "", ErrNotAuthRecord
+	}
+
+	key := (m.TokenKey() + m.Collection().MagicLinkToken.Secret)
+	if key == "" {
+		return "", ErrMissingSigningKey
+	}
+
+	return m.signer().Sign(
+		jwt.MapClaims{
+			TokenClaimType:         TokenTypeMagicLink,
+			TokenClaimId:           m.Id,
+			TokenClaimCollectionId: m.Collection().Id,
+			TokenClaimEmail:        m.Email(),
+			TokenClaimOTPId:        otpId,
+		},
+		key,
+		m.Collection().MagicLinkToken.DurationTime(),
+	)
+}
+
 // NewFileToken generates and returns a new record private file access token.
 func (m *Record) NewFileToken() (string, error) {
 	if !m.Collection().IsAuth() {
@@ -153,7 +283,7 @@ func (m *Record) NewFileToken() (string, error) {
 		return "", ErrMissingSigningKey
 	}
 
-	return security.NewJWT(
+	return m.signer().Sign(
 		jwt.MapClaims{
 			TokenClaimType:         TokenTypeFile,
 			TokenClaimId:           m.Id,
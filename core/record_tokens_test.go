@@ -1,10 +1,12 @@
 package core_test
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/security"
@@ -83,6 +85,136 @@ func TestNewAuthToken(t *testing.T) {
 	})
 }
 
+func TestNewSessionAuthToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user.Collection().AuthSession.MaxLifetime = 3600
+
+	var tolerance int64 = 1 // in sec
+
+	scenarios := []struct {
+		name         string
+		sessionStart time.Time
+		expectError  bool
+		expectedExp  time.Time
+	}{
+		{
+			"zero session start",
+			time.Time{},
+			false,
+			time.Now().Add(time.Hour), // capped to the max lifetime
+		},
+		{
+			"session start in the past",
+			time.Now().Add(-30 * time.Minute),
+			false,
+			time.Now().Add(30 * time.Minute),
+		},
+		{
+			"session with reached max lifetime",
+			time.Now().Add(-2 * time.Hour),
+			true,
+			time.Time{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			token, err := user.NewSessionAuthToken(s.sessionStart)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				if !errors.Is(err, core.ErrAuthSessionExpired) {
+					t.Fatalf("Expected ErrAuthSessionExpired, got %v", err)
+				}
+				return
+			}
+
+			claims, err := security.ParseUnverifiedJWT(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cast.ToBool(claims[core.TokenClaimRefreshable]) {
+				t.Fatal("Expected the session token to be refreshable")
+			}
+
+			exp := cast.ToInt64(claims["exp"])
+			if exp < s.expectedExp.Unix()-tolerance || exp > s.expectedExp.Unix()+tolerance {
+				t.Fatalf("Expected exp ~%d, got %d", s.expectedExp.Unix(), exp)
+			}
+
+			_, sessionStart := core.AuthTokenSessionTimes(claims, user.Collection())
+			expectedStart := s.sessionStart
+			if expectedStart.IsZero() {
+				expectedStart = time.Now()
+			}
+			if diff := sessionStart.Unix() - expectedStart.Unix(); diff < -tolerance || diff > tolerance {
+				t.Fatalf("Expected session start ~%d, got %d", expectedStart.Unix(), sessionStart.Unix())
+			}
+		})
+	}
+}
+
+func TestAuthTokenSessionTimes(t *testing.T) {
+	t.Parallel()
+
+	collection := core.NewAuthCollection("test")
+	collection.AuthToken.Duration = 100
+
+	scenarios := []struct {
+		name                 string
+		claims               jwt.MapClaims
+		expectedIssuedAt     int64
+		expectedSessionStart int64
+	}{
+		{
+			"legacy token (without session claims)",
+			jwt.MapClaims{"exp": 1000},
+			900,
+			900,
+		},
+		{
+			"token with issuedAt only",
+			jwt.MapClaims{"exp": 1000, core.TokenClaimIssuedAt: 950},
+			950,
+			950,
+		},
+		{
+			"token with session claims",
+			jwt.MapClaims{"exp": 1000, core.TokenClaimIssuedAt: 950, core.TokenClaimSessionStart: 500},
+			950,
+			500,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			issuedAt, sessionStart := core.AuthTokenSessionTimes(s.claims, collection)
+
+			if issuedAt.Unix() != s.expectedIssuedAt {
+				t.Fatalf("Expected issuedAt %d, got %d", s.expectedIssuedAt, issuedAt.Unix())
+			}
+
+			if sessionStart.Unix() != s.expectedSessionStart {
+				t.Fatalf("Expected sessionStart %d, got %d", s.expectedSessionStart, sessionStart.Unix())
+			}
+		})
+	}
+}
+
 func TestNewVerificationToken(t *testing.T) {
 	t.Parallel()
 
@@ -115,6 +247,137 @@ func TestNewFileToken(t *testing.T) {
 	}, nil)
 }
 
+func TestNewMagicLinkToken(t *testing.T) {
+	t.Parallel()
+
+	testRecordToken(t, core.TokenTypeMagicLink, func(record *core.Record) (string, error) {
+		record.Collection().MagicLinkToken.Duration = 100
+		return record.NewMagicLinkToken("test_otp_id")
+	}, map[string]any{
+		core.TokenClaimOTPId: "test_otp_id",
+		core.TokenClaimEmail: "test@example.com",
+	})
+}
+
+func TestMagicLinkTokenSecret(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := user.NewMagicLinkToken("test_otp_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a magic link token signed with the auth token secret
+	forged, err := app.TokenSigner().Sign(
+		jwt.MapClaims{
+			core.TokenClaimType:         core.TokenTypeMagicLink,
+			core.TokenClaimId:           user.Id,
+			core.TokenClaimCollectionId: user.Collection().Id,
+			core.TokenClaimEmail:        user.Email(),
+			core.TokenClaimOTPId:        "test_otp_id",
+		},
+		user.TokenKey()+user.Collection().AuthToken.Secret,
+		time.Minute,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindAuthRecordByToken(forged, core.TokenTypeMagicLink); err == nil {
+		t.Fatal("Expected the magic link token signed with the auth token secret to be rejected")
+	}
+
+	collection := user.Collection()
+
+	// changing the auth token secret shouldn't invalidate the magic link token
+	collection.AuthToken.Secret = security.RandomString(50)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.FindAuthRecordByToken(token, core.TokenTypeMagicLink); err != nil {
+		t.Fatalf("Expected the magic link token to remain valid, got %v", err)
+	}
+
+	// changing the magic link token secret should invalidate the magic link token
+	collection.MagicLinkToken.Secret = security.RandomString(50)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.FindAuthRecordByToken(token, core.TokenTypeMagicLink); err == nil {
+		t.Fatal("Expected the magic link token to be invalidated")
+	}
+}
+
+type testTokenSigner struct {
+	core.HS256TokenSigner
+	calls int
+}
+
+func (s *testTokenSigner) Sign(claims jwt.MapClaims, signingKey string, duration time.Duration) (string, error) {
+	s.calls++
+	claims["custom"] = "test"
+	return s.HS256TokenSigner.Sign(claims, signingKey, duration)
+}
+
+func TestRecordSetTokenSigner(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer := &testTokenSigner{}
+	user.SetTokenSigner(signer)
+
+	token, err := user.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// should be inherited by the record copies
+	if _, err := user.Clone().NewFileToken(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := user.Fresh().NewVerificationToken(); err != nil {
+		t.Fatal(err)
+	}
+
+	if signer.calls != 3 {
+		t.Fatalf("Expected 3 Sign calls, got %d", signer.calls)
+	}
+
+	claims, _ := security.ParseUnverifiedJWT(token)
+	if claims["custom"] != "test" {
+		t.Fatalf("Expected the custom claim to be set, got %v", claims)
+	}
+
+	tokenRecord, err := app.FindAuthRecordByToken(token, core.TokenTypeAuth)
+	if err != nil || tokenRecord.Id != user.Id {
+		t.Fatalf("Expected auth record %q, got %v (%v)", user.Id, tokenRecord, err)
+	}
+
+	// reset to the default
+	user.SetTokenSigner(nil)
+	if _, err := user.NewAuthToken(); err != nil {
+		t.Fatal(err)
+	}
+	if signer.calls != 3 {
+		t.Fatalf("Expected the default signer to be used, got %d custom Sign calls", signer.calls)
+	}
+}
+
 func testRecordToken(
 	t *testing.T,
 	tokenType string,
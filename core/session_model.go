@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameSessions = "_sessions"
+
+var (
+	_ Model        = (*Session)(nil)
+	_ PreValidator = (*Session)(nil)
+	_ RecordProxy  = (*Session)(nil)
+)
+
+// Session defines a Record proxy for working with the sessions collection.
+//
+// Sessions are registered only for the auth collections with enabled
+// [AuthSessionConfig.Track] option and their auth tokens are valid
+// only as long as the related Session model exists.
+type Session struct {
+	*Record
+}
+
+// NewSession instantiates and returns a new blank *Session model.
+//
+// Example usage:
+//
+//	session := core.NewSession(app)
+//	session.SetRecordRef(user.Id)
+//	session.SetCollectionRef(user.Collection().Id)
+//	session.SetUserAgent(e.Request.UserAgent())
+//	session.SetIP(e.RealIP())
+//	app.Save(session)
+func NewSession(app App) *Session {
+	m := &Session{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameSessions)
+	if err != nil {
+		// this is just to make tests easier since sessions is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on Session.PreValidate())
+		c = NewBaseCollection("__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *Session) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameSessions {
+		return errors.New("missing or invalid session ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *Session) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *Session) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *Session) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *Session) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *Session) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *Session) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// UserAgent returns the "userAgent" record field value.
+func (m *Session) UserAgent() string {
+	return m.GetString("userAgent")
+}
+
+// SetUserAgent updates the "userAgent" record field value.
+func (m *Session) SetUserAgent(userAgent string) {
+	m.Set("userAgent", userAgent)
+}
+
+// IP returns the "ip" record field value.
+func (m *Session) IP() string {
+	return m.GetString("ip")
+}
+
+// SetIP updates the "ip" record field value.
+func (m *Session) SetIP(ip string) {
+	m.Set("ip", ip)
+}
+
+// LastActive returns the "lastActive" record field value
+// (aka. the last time when the session token was issued or refreshed).
+func (m *Session) LastActive() types.DateTime {
+	return m.GetDateTime("lastActive")
+}
+
+// SetLastActive updates the "lastActive" record field value.
+func (m *Session) SetLastActive(date types.DateTime) {
+	m.Set("lastActive", date)
+}
+
+// Created returns the "created" record field value.
+func (m *Session) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *Session) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+func (app *BaseApp) registerSessionHooks() {
+	recordRefHooks[*Session](app, CollectionNameSessions, CollectionTypeAuth)
+}
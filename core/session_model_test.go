@@ -0,0 +1,236 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestNewSession(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	session := core.NewSession(app)
+
+	if session.Collection().Name != core.CollectionNameSessions {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameSessions, session.Collection().Name)
+	}
+}
+
+func TestSessionProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	session := core.Session{}
+	session.SetProxyRecord(record)
+
+	if session.ProxyRecord() == nil || session.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, session.ProxyRecord())
+	}
+}
+
+func TestSessionStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	session := core.NewSession(app)
+
+	scenarios := []struct {
+		field  string
+		setter func(string)
+		getter func() string
+	}{
+		{"recordRef", session.SetRecordRef, session.RecordRef},
+		{"collectionRef", session.SetCollectionRef, session.CollectionRef},
+		{"userAgent", session.SetUserAgent, session.UserAgent},
+		{"ip", session.SetIP, session.IP},
+	}
+
+	for _, s := range scenarios {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", s.field, i, testValue), func(t *testing.T) {
+				s.setter(testValue)
+
+				if v := s.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := session.GetString(s.field); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestSessionDates(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	session := core.NewSession(app)
+
+	now := types.NowDateTime()
+
+	session.SetLastActive(now)
+	if v := session.LastActive(); v.String() != now.String() {
+		t.Fatalf("Expected lastActive %q, got %q", now.String(), v.String())
+	}
+
+	session.SetRaw("created", now)
+	if v := session.Created(); v.String() != now.String() {
+		t.Fatalf("Expected created %q, got %q", now.String(), v.String())
+	}
+
+	session.SetRaw("updated", now)
+	if v := session.Updated(); v.String() != now.String() {
+		t.Fatalf("Expected updated %q, got %q", now.String(), v.String())
+	}
+}
+
+func TestSessionPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	sessionsCol, err := app.FindCollectionByNameOrId(core.CollectionNameSessions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		session := &core.Session{}
+
+		if err := app.Validate(session); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-Session collection", func(t *testing.T) {
+		session := &core.Session{}
+		session.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		session.SetRecordRef(user.Id)
+		session.SetCollectionRef(user.Collection().Id)
+
+		if err := app.Validate(session); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("Session collection", func(t *testing.T) {
+		session := &core.Session{}
+		session.SetProxyRecord(core.NewRecord(sessionsCol))
+		session.SetRecordRef(user.Id)
+		session.SetCollectionRef(user.Collection().Id)
+
+		if err := app.Validate(session); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestSessionValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		session      func() *core.Session
+		expectErrors []string
+	}{
+		{
+			"empty",
+			func() *core.Session {
+				return core.NewSession(app)
+			},
+			[]string{"collectionRef", "recordRef"},
+		},
+		{
+			"non-auth collection",
+			func() *core.Session {
+				session := core.NewSession(app)
+				session.SetCollectionRef(demo1.Collection().Id)
+				session.SetRecordRef(demo1.Id)
+				return session
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record id",
+			func() *core.Session {
+				session := core.NewSession(app)
+				session.SetCollectionRef(user.Collection().Id)
+				session.SetRecordRef("missing")
+				return session
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"valid ref",
+			func() *core.Session {
+				session := core.NewSession(app)
+				session.SetCollectionRef(user.Collection().Id)
+				session.SetRecordRef(user.Id)
+				return session
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.session())
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestSessionRecordRefDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := stubSession(t, app, user)
+
+	if err := app.Delete(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindSessionById(session.Id); err == nil {
+		t.Fatal("Expected the session to be deleted together with its owner record")
+	}
+}
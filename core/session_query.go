@@ -0,0 +1,88 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/spf13/cast"
+)
+
+// FindAllSessionsByRecord returns all Session models linked to the provided auth record (in DESC order).
+func (app *BaseApp) FindAllSessionsByRecord(authRecord *Record) ([]*Session, error) {
+	result := []*Session{}
+
+	err := app.RecordQuery(CollectionNameSessions).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		OrderBy("created DESC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindSessionById returns a single Session model by its id.
+func (app *BaseApp) FindSessionById(id string) (*Session, error) {
+	result := &Session{}
+
+	err := app.RecordQuery(CollectionNameSessions).
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteAllSessionsByRecord deletes all Session models associated with
+// the provided record (aka. revokes all of its tracked auth tokens).
+//
+// Returns a combined error with the failed deletes.
+func (app *BaseApp) DeleteAllSessionsByRecord(authRecord *Record) error {
+	models, err := app.FindAllSessionsByRecord(authRecord)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, m := range models {
+		if err := app.Delete(m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// checkAuthTokenSession checks whether the auth token claims are linked
+// to an existing server-side session of the provided auth record.
+//
+// Static (non-refreshable) auth tokens without a session id are not tracked.
+func (app *BaseApp) checkAuthTokenSession(record *Record, claims jwt.MapClaims) error {
+	sessionId := cast.ToString(claims[TokenClaimSessionId])
+	if sessionId == "" {
+		if cast.ToBool(claims[TokenClaimRefreshable]) {
+			return ErrAuthSessionRevoked
+		}
+		return nil
+	}
+
+	session, err := app.FindSessionById(sessionId)
+	if err != nil || session.RecordRef() != record.Id || session.CollectionRef() != record.Collection().Id {
+		return ErrAuthSessionRevoked
+	}
+
+	return nil
+}
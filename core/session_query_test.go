@@ -0,0 +1,241 @@
+package core_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func stubSession(t testing.TB, app core.App, owner *core.Record) *core.Session {
+	session := core.NewSession(app)
+	session.SetCollectionRef(owner.Collection().Id)
+	session.SetRecordRef(owner.Id)
+	session.SetUserAgent("test")
+
+	if err := app.Save(session); err != nil {
+		t.Fatal(err)
+	}
+
+	return session
+}
+
+func TestFindAllSessionsByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	superuser, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session1 := stubSession(t, app, user1)
+	session2 := stubSession(t, app, user1)
+	session3 := stubSession(t, app, superuser)
+
+	scenarios := []struct {
+		record   *core.Record
+		expected []string
+	}{
+		{user1, []string{session1.Id, session2.Id}},
+		{user2, nil},
+		{superuser, []string{session3.Id}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.record.Collection().Name+"_"+s.record.Id, func(t *testing.T) {
+			result, err := app.FindAllSessionsByRecord(s.record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != len(s.expected) {
+				t.Fatalf("Expected total sessions %d, got %d", len(s.expected), len(result))
+			}
+
+			for _, session := range result {
+				if !slices.Contains(s.expected, session.Id) {
+					t.Fatalf("Unexpected session %q", session.Id)
+				}
+			}
+		})
+	}
+}
+
+func TestFindSessionById(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := stubSession(t, app, user)
+
+	scenarios := []struct {
+		id          string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{session.Id, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.id, func(t *testing.T) {
+			result, err := app.FindSessionById(s.id)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Id != s.id {
+				t.Fatalf("Expected record with id %q, got %q", s.id, result.Id)
+			}
+		})
+	}
+}
+
+func TestDeleteAllSessionsByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stubSession(t, app, user1)
+	stubSession(t, app, user1)
+	stubSession(t, app, user2)
+
+	if err := app.DeleteAllSessionsByRecord(user1); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		record        *core.Record
+		expectedTotal int
+	}{
+		{user1, 0},
+		{user2, 1},
+	}
+
+	for _, s := range scenarios {
+		result, err := app.FindAllSessionsByRecord(s.record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result) != s.expectedTotal {
+			t.Fatalf("[%s] Expected %d sessions, got %d", s.record.Id, s.expectedTotal, len(result))
+		}
+	}
+}
+
+func TestFindAuthRecordByTokenWithTrackedSession(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	untrackedToken, err := user.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	staticToken, err := user.NewStaticAuthToken(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := stubSession(t, app, user)
+	trackedToken, err := user.NewTrackedAuthToken(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revokedSession := stubSession(t, app, user)
+	revokedToken, err := user.NewTrackedAuthToken(revokedSession)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Delete(revokedSession); err != nil {
+		t.Fatal(err)
+	}
+
+	otherSession := stubSession(t, app, user2)
+	otherSessionToken, err := user.NewTrackedAuthToken(otherSession)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := user.NewTrackedAuthToken(nil); !errors.Is(err, core.ErrAuthSessionRevoked) {
+		t.Fatalf("Expected ErrAuthSessionRevoked for nil session, got %v", err)
+	}
+
+	scenarios := []struct {
+		name          string
+		token         string
+		track         bool
+		expectedError error
+	}{
+		{"untracked token with disabled tracking", untrackedToken, false, nil},
+		{"revoked token with disabled tracking", revokedToken, false, nil},
+		{"untracked token with enabled tracking", untrackedToken, true, core.ErrAuthSessionRevoked},
+		{"static token with enabled tracking", staticToken, true, nil},
+		{"tracked token", trackedToken, true, nil},
+		{"revoked token", revokedToken, true, core.ErrAuthSessionRevoked},
+		{"token with another record session", otherSessionToken, true, core.ErrAuthSessionRevoked},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			user.Collection().AuthSession.Track = s.track
+
+			_, err := app.FindAuthRecordByToken(s.token, core.TokenTypeAuth)
+			if !errors.Is(err, s.expectedError) {
+				t.Fatalf("Expected error %v, got %v", s.expectedError, err)
+			}
+		})
+	}
+}
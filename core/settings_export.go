@@ -0,0 +1,134 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// SettingsExportVersion is the current settings export format version.
+const SettingsExportVersion = 1
+
+// encryptedSecretPrefix is the prefix of the encrypted secret values in the settings export.
+const encryptedSecretPrefix = "enc:"
+
+// SettingsExport defines the settings export document that could be
+// used to transfer the app settings between environments.
+type SettingsExport struct {
+	Version int `json:"version"`
+
+	// Encrypted indicates whether the settings secrets are included
+	// in the export (encrypted with the export key).
+	Encrypted bool `json:"encrypted"`
+
+	Settings json.RawMessage `json:"settings"`
+}
+
+// Export exports the current settings in a format suitable for
+// importing in another app instance with [Settings.Import].
+//
+// If encryptionKey is set, the settings secrets (SMTP password, S3 secret, etc.)
+// are included in the export encrypted with the provided key (must be 32 characters).
+// Otherwise the secrets are omitted from the export.
+func (s *Settings) Export(encryptionKey string) (*SettingsExport, error) {
+	if encryptionKey != "" && len(encryptionKey) != 32 {
+		return nil, errors.New("the encryption key must be exactly 32 characters")
+	}
+
+	s.mu.RLock()
+	copy := s.settings
+	s.mu.RUnlock()
+
+	for _, v := range copy.sensitiveFields() {
+		if *v == "" {
+			continue
+		}
+
+		if encryptionKey == "" {
+			*v = ""
+			continue
+		}
+
+		encrypted, err := security.Encrypt([]byte(*v), encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		*v = encryptedSecretPrefix + encrypted
+	}
+
+	raw, err := json.Marshal(copy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SettingsExport{
+		Version:   SettingsExportVersion,
+		Encrypted: encryptionKey != "",
+		Settings:  raw,
+	}, nil
+}
+
+// Import merges the provided settings export into the current settings.
+//
+// The encrypted secrets are decrypted with the provided encryptionKey
+// (it must be the same key used for the export). Secrets missing in the
+// export are left unchanged.
+//
+// Note that Import only updates the settings model state.
+// To persist the changes you have to call app.Save(settings).
+func (s *Settings) Import(export *SettingsExport, encryptionKey string) error {
+	if export == nil || len(export.Settings) == 0 {
+		return errors.New("missing settings export data")
+	}
+
+	if export.Version != SettingsExportVersion {
+		return fmt.Errorf("unsupported settings export version %d", export.Version)
+	}
+
+	if export.Encrypted && encryptionKey == "" {
+		return errors.New("the settings export has encrypted secrets and requires an encryption key")
+	}
+
+	clone, err := s.Clone()
+	if err != nil {
+		return err
+	}
+
+	// reset the secrets so that we can distinguish the imported from the existing ones
+	current := clone.settings
+	for _, v := range clone.settings.sensitiveFields() {
+		*v = ""
+	}
+
+	if err := json.Unmarshal(export.Settings, &clone.settings); err != nil {
+		return fmt.Errorf("failed to parse the exported settings: %w", err)
+	}
+
+	imported := clone.settings.sensitiveFields()
+	existing := current.sensitiveFields()
+	for i, v := range imported {
+		if *v == "" {
+			*v = *existing[i]
+			continue
+		}
+
+		if !strings.HasPrefix(*v, encryptedSecretPrefix) {
+			continue // plain value
+		}
+
+		if encryptionKey == "" {
+			return errors.New("the settings export has encrypted secrets and requires an encryption key")
+		}
+
+		decrypted, err := security.Decrypt(strings.TrimPrefix(*v, encryptedSecretPrefix), encryptionKey)
+		if err != nil {
+			return errors.New("failed to decrypt the settings secrets (invalid encryption key?)")
+		}
+		*v = string(decrypted)
+	}
+
+	return s.Merge(clone)
+}
@@ -0,0 +1,153 @@
+package core_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+const testExportKey = "abcdefghijklmnopqrstuvwxyz123456"
+
+func TestSettingsExport(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().Meta.AppName = "export_test"
+	app.Settings().SMTP.Password = "smtp_password"
+	app.Settings().S3.Secret = "s3_secret"
+
+	if _, err := app.Settings().Export("invalid"); err == nil {
+		t.Fatal("Expected invalid encryption key error")
+	}
+
+	t.Run("without encryption key", func(t *testing.T) {
+		export, err := app.Settings().Export("")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if export.Encrypted || export.Version != core.SettingsExportVersion {
+			t.Fatalf("Unexpected export %#v", export)
+		}
+
+		raw := string(export.Settings)
+		if !strings.Contains(raw, `"appName":"export_test"`) {
+			t.Fatalf("Expected the exported settings to contain the app name, got %s", raw)
+		}
+		if strings.Contains(raw, "smtp_password") || strings.Contains(raw, "s3_secret") {
+			t.Fatalf("Expected the secrets to be omitted, got %s", raw)
+		}
+	})
+
+	t.Run("with encryption key", func(t *testing.T) {
+		export, err := app.Settings().Export(testExportKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !export.Encrypted {
+			t.Fatal("Expected Encrypted to be true")
+		}
+
+		raw := string(export.Settings)
+		if strings.Contains(raw, "smtp_password") || strings.Contains(raw, "s3_secret") {
+			t.Fatalf("Expected the secrets to be encrypted, got %s", raw)
+		}
+		if !strings.Contains(raw, `"password":"enc:`) || !strings.Contains(raw, `"secret":"enc:`) {
+			t.Fatalf("Expected encrypted secrets, got %s", raw)
+		}
+
+		// the app settings shouldn't be modified
+		if app.Settings().SMTP.Password != "smtp_password" {
+			t.Fatalf("Expected the app settings to remain unchanged, got %q", app.Settings().SMTP.Password)
+		}
+	})
+}
+
+func TestSettingsImport(t *testing.T) {
+	t.Parallel()
+
+	source, _ := tests.NewTestApp()
+	defer source.Cleanup()
+
+	source.Settings().Meta.AppName = "import_test"
+	source.Settings().SMTP.Password = "smtp_password"
+	source.Settings().S3.Secret = "s3_secret"
+
+	encrypted, err := source.Settings().Export(testExportKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := source.Settings().Export("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name          string
+		export        *core.SettingsExport
+		key           string
+		expectError   bool
+		expectedSMTP  string
+		expectedS3    string
+		expectedAIKey string
+	}{
+		{"nil export", nil, "", true, "", "", ""},
+		{"unsupported version", &core.SettingsExport{Version: 99, Settings: json.RawMessage(`{}`)}, "", true, "", "", ""},
+		{"encrypted export without key", encrypted, "", true, "", "", ""},
+		{"encrypted export with invalid key", encrypted, strings.Repeat("a", 32), true, "", "", ""},
+		{"encrypted export with valid key", encrypted, testExportKey, false, "smtp_password", "s3_secret", "existing_ai_key"},
+		{"plain export (existing secrets are preserved)", plain, "", false, "existing_smtp_password", "", "existing_ai_key"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			app.Settings().SMTP.Password = "existing_smtp_password"
+			app.Settings().AI.APIKey = "existing_ai_key"
+
+			settings, err := app.Settings().Clone()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = settings.Import(s.export, s.key)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				if settings.SMTP.Password != "existing_smtp_password" {
+					t.Fatalf("Expected the settings to remain unchanged on error, got %q", settings.SMTP.Password)
+				}
+				return
+			}
+
+			if settings.Meta.AppName != "import_test" {
+				t.Fatalf("Expected app name %q, got %q", "import_test", settings.Meta.AppName)
+			}
+
+			if settings.SMTP.Password != s.expectedSMTP {
+				t.Fatalf("Expected SMTP password %q, got %q", s.expectedSMTP, settings.SMTP.Password)
+			}
+
+			if settings.S3.Secret != s.expectedS3 {
+				t.Fatalf("Expected S3 secret %q, got %q", s.expectedS3, settings.S3.Secret)
+			}
+
+			if settings.AI.APIKey != s.expectedAIKey {
+				t.Fatalf("Expected AI key %q, got %q", s.expectedAIKey, settings.AI.APIKey)
+			}
+		})
+	}
+}
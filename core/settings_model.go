@@ -2,7 +2,11 @@ package core
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"os"
@@ -16,6 +20,7 @@ import (
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/ai"
 	"github.com/pocketbase/pocketbase/tools/cron"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/mailer"
@@ -128,6 +133,11 @@ type settings struct {
 	TrustedProxy TrustedProxyConfig `form:"trustedProxy" json:"trustedProxy"`
 	Batch        BatchConfig        `form:"batch" json:"batch"`
 	Logs         LogsConfig         `form:"logs" json:"logs"`
+	AI           AIConfig           `form:"ai" json:"ai"`
+	Cache        CacheConfig        `form:"cache" json:"cache"`
+	OIDC         OIDCConfig         `form:"oidc" json:"oidc"`
+	AuthLockout  AuthLockoutConfig  `form:"authLockout" json:"authLockout"`
+	ActionLinks  ActionLinksConfig  `form:"actionLinks" json:"actionLinks"`
 }
 
 // Settings defines the PocketBase app settings.
@@ -164,11 +174,32 @@ func newDefaultSettings() *Settings {
 			Backups: BackupsConfig{
 				CronMaxKeep: 3,
 			},
+			AI: AIConfig{
+				Enabled:         false,
+				BaseURL:         ai.DefaultBaseURL,
+				CompletionModel: ai.DefaultCompletionModel,
+				EmbeddingModel:  ai.DefaultEmbeddingModel,
+			},
 			Batch: BatchConfig{
 				Enabled:     false,
 				MaxRequests: 50,
 				Timeout:     3,
 			},
+			OIDC: OIDCConfig{
+				Enabled:       false,
+				TokenDuration: 3600, // 1h
+			},
+			AuthLockout: AuthLockoutConfig{
+				Enabled:     false,
+				MaxAttempts: 5,
+				Window:      900, // 15min
+				Duration:    300, // 5min
+				Backoff:     true,
+				MaxDuration: 86400, // 24h
+			},
+			ActionLinks: ActionLinksConfig{
+				Duration: 604800, // 7d
+			},
 			RateLimits: RateLimitsConfig{
 				Enabled: false, // @todo once tested enough enable by default for new installations
 				Rules: []RateLimitRule{
@@ -287,6 +318,11 @@ func (s *Settings) PostValidate(ctx context.Context, app App) error {
 		validation.Field(&s.Batch),
 		validation.Field(&s.RateLimits),
 		validation.Field(&s.TrustedProxy),
+		validation.Field(&s.AI),
+		validation.Field(&s.Cache),
+		validation.Field(&s.OIDC),
+		validation.Field(&s.AuthLockout),
+		validation.Field(&s.ActionLinks),
 	)
 }
 
@@ -327,14 +363,8 @@ func (s *Settings) MarshalJSON() ([]byte, error) {
 	copy := s.settings
 	s.mu.RUnlock()
 
-	sensitiveFields := []*string{
-		&copy.SMTP.Password,
-		&copy.S3.Secret,
-		&copy.Backups.S3.Secret,
-	}
-
 	// mask all sensitive fields
-	for _, v := range sensitiveFields {
+	for _, v := range copy.sensitiveFields() {
 		if v != nil && *v != "" {
 			*v = ""
 		}
@@ -343,6 +373,20 @@ func (s *Settings) MarshalJSON() ([]byte, error) {
 	return json.Marshal(copy)
 }
 
+// sensitiveFields returns pointers to all secret settings fields
+// (S3 secret, SMTP password, etc.).
+func (s *settings) sensitiveFields() []*string {
+	return []*string{
+		&s.SMTP.Password,
+		&s.S3.Secret,
+		&s.Backups.S3.Secret,
+		&s.AI.APIKey,
+		&s.Cache.RedisPassword,
+		&s.OIDC.SigningKey,
+		&s.ActionLinks.Secret,
+	}
+}
+
 // -------------------------------------------------------------------
 
 type SMTPConfig struct {
@@ -417,6 +461,247 @@ func (c S3Config) Validate() error {
 
 // -------------------------------------------------------------------
 
+type AIConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// BaseURL is the OpenAI compatible API base url
+	// (e.g. "https://api.openai.com/v1", "http://localhost:11434/v1").
+	BaseURL string `form:"baseURL" json:"baseURL"`
+
+	// APIKey is the optional API key sent as Bearer token.
+	APIKey string `form:"apiKey" json:"apiKey,omitempty"`
+
+	// CompletionModel is the default chat completions model.
+	CompletionModel string `form:"completionModel" json:"completionModel"`
+
+	// EmbeddingModel is the default embeddings model.
+	EmbeddingModel string `form:"embeddingModel" json:"embeddingModel"`
+}
+
+// Validate makes AIConfig validatable by implementing [validation.Validatable] interface.
+func (c AIConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.BaseURL, is.URL, validation.When(c.Enabled, validation.Required)),
+		validation.Field(&c.CompletionModel, validation.Length(0, 255)),
+		validation.Field(&c.EmbeddingModel, validation.Length(0, 255)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// CacheConfig defines the settings of the app cache store.
+//
+// Note that it is ignored if the app was created with custom [BaseAppConfig.Cache].
+type CacheConfig struct {
+	// RedisEnabled enables the Redis backed app cache store
+	// (otherwise the in-memory cache store is used).
+	RedisEnabled bool `form:"redisEnabled" json:"redisEnabled"`
+
+	// RedisAddr is the Redis server "host:port" address.
+	RedisAddr string `form:"redisAddr" json:"redisAddr"`
+
+	// RedisUsername is the optional Redis ACL username.
+	RedisUsername string `form:"redisUsername" json:"redisUsername"`
+
+	// RedisPassword is the optional Redis auth password.
+	RedisPassword string `form:"redisPassword" json:"redisPassword,omitempty"`
+
+	// RedisDB is the Redis db number to use.
+	RedisDB int `form:"redisDB" json:"redisDB"`
+
+	// KeyPrefix is an optional prefix for all cache keys
+	// (could be used to share the same Redis db between multiple apps).
+	KeyPrefix string `form:"keyPrefix" json:"keyPrefix"`
+}
+
+// Validate makes CacheConfig validatable by implementing [validation.Validatable] interface.
+func (c CacheConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.RedisAddr, validation.When(c.RedisEnabled, validation.Required), is.DialString),
+		validation.Field(&c.RedisDB, validation.Min(0)),
+		validation.Field(&c.KeyPrefix, validation.Length(0, 100)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// AuthLockoutConfig defines the settings of the auth endpoints brute-force
+// protection (aka. temporary lockout after too many failed password or OTP attempts).
+//
+// The failed attempts are tracked in-memory per auth collection identity and client IP pair.
+type AuthLockoutConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// MaxAttempts is the number of failed attempts within Window after which the identity+IP pair is locked.
+	MaxAttempts int `form:"maxAttempts" json:"maxAttempts"`
+
+	// Window is the failed attempts counting period in seconds.
+	Window int64 `form:"window" json:"window"`
+
+	// Duration is the lockout duration in seconds.
+	Duration int64 `form:"duration" json:"duration"`
+
+	// Backoff exponentially increases (doubles) the lockout
+	// duration for each consecutive lockout of the same identity+IP pair.
+	Backoff bool `form:"backoff" json:"backoff"`
+
+	// MaxDuration is the max lockout duration in seconds when Backoff is enabled.
+	MaxDuration int64 `form:"maxDuration" json:"maxDuration"`
+}
+
+// WindowDuration returns the Window field value as [time.Duration].
+func (c AuthLockoutConfig) WindowDuration() time.Duration {
+	return time.Duration(c.Window) * time.Second
+}
+
+// LockoutDuration returns the lockout duration for the specified consecutive lockout (starting from 1).
+func (c AuthLockoutConfig) LockoutDuration(lockout int) time.Duration {
+	duration := time.Duration(c.Duration) * time.Second
+	maxDuration := time.Duration(c.MaxDuration) * time.Second
+
+	if !c.Backoff || maxDuration <= duration {
+		return duration
+	}
+
+	for i := 1; i < lockout && duration < maxDuration; i++ {
+		duration *= 2
+	}
+
+	return min(duration, maxDuration)
+}
+
+// Validate makes AuthLockoutConfig validatable by implementing [validation.Validatable] interface.
+func (c AuthLockoutConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxAttempts, validation.When(c.Enabled, validation.Required), validation.Min(0)),
+		validation.Field(&c.Window, validation.When(c.Enabled, validation.Required), validation.Min(0)),
+		validation.Field(&c.Duration, validation.When(c.Enabled, validation.Required), validation.Min(0)),
+		validation.Field(&c.MaxDuration, validation.When(c.Enabled && c.Backoff, validation.Required, validation.Min(c.Duration))),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// ActionLinksConfig defines the settings of the signed single-use action links
+// (see [App.NewActionToken]).
+type ActionLinksConfig struct {
+	// Secret is the key used to sign the action tokens.
+	//
+	// If empty, a new random secret is generated and persisted on first use.
+	// Changing the secret invalidates all previously issued action tokens.
+	Secret string `form:"secret" json:"secret,omitempty"`
+
+	// Duration is the default action token duration in seconds.
+	Duration int64 `form:"duration" json:"duration"`
+}
+
+// DurationTime returns the current Duration as [time.Duration].
+func (c ActionLinksConfig) DurationTime() time.Duration {
+	return time.Duration(c.Duration) * time.Second
+}
+
+// Validate makes ActionLinksConfig validatable by implementing [validation.Validatable] interface.
+func (c ActionLinksConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Secret, validation.Length(30, 255)),
+		validation.Field(&c.Duration, validation.Required, validation.Min(10), validation.Max(94670856)), // ~3y max
+	)
+}
+
+// -------------------------------------------------------------------
+
+// OIDCConfig defines the settings of the OpenID Connect provider mode
+// (aka. allowing external apps to "Log in with" the app auth records).
+//
+// The external apps are registered as [OAuthClient] models.
+type OIDCConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// LoginURL is the url of the app login page where the users are
+	// redirected from the authorize endpoint (the authorization request
+	// query parameters are forwarded as they are).
+	//
+	// The login page is expected to authenticate the user and to submit
+	// the same authorization request parameters together with the user
+	// auth token to the "POST /api/oidc/authorize" endpoint.
+	LoginURL string `form:"loginURL" json:"loginURL"`
+
+	// TokenDuration is the issued ID and access tokens duration in seconds.
+	TokenDuration int64 `form:"tokenDuration" json:"tokenDuration"`
+
+	// SigningKey is the PEM encoded RSA private key used to sign the issued tokens.
+	//
+	// If empty, a new key is generated and persisted on first use.
+	SigningKey string `form:"signingKey" json:"signingKey,omitempty"`
+}
+
+// Validate makes OIDCConfig validatable by implementing [validation.Validatable] interface.
+func (c OIDCConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.LoginURL, validation.When(c.Enabled, validation.Required), is.URL),
+		validation.Field(&c.TokenDuration, validation.When(c.Enabled, validation.Required), validation.Min(0)),
+		validation.Field(&c.SigningKey, validation.By(checkOIDCSigningKey)),
+	)
+}
+
+func checkOIDCSigningKey(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // autogenerated
+	}
+
+	if _, err := ParseOIDCSigningKey(v); err != nil {
+		return validation.NewError("validation_invalid_oidc_signing_key", "Invalid PEM encoded RSA private key.")
+	}
+
+	return nil
+}
+
+// GenerateOIDCSigningKey generates a new PEM encoded (PKCS #8) 2048-bit RSA private key.
+func GenerateOIDCSigningKey() (string, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// ParseOIDCSigningKey parses a PEM encoded (PKCS #8 or PKCS #1) RSA private key.
+func ParseOIDCSigningKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("invalid PEM block")
+	}
+
+	rsaKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		var ok bool
+		rsaKey, ok = key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("the private key is not a RSA key")
+		}
+	}
+
+	if rsaKey.N.BitLen() < 2048 {
+		return nil, errors.New("the RSA key must be at least 2048 bits")
+	}
+
+	return rsaKey, nil
+}
+
+// -------------------------------------------------------------------
+
 type BatchConfig struct {
 	Enabled bool `form:"enabled" json:"enabled"`
 
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/tools/httpclient"
+)
+
+// ErrMissingSMSSender is returned by [App.SendSMS] when there is no
+// registered SMS sender (see [BaseAppConfig.SMSSender] and [App.OnSMSSend]).
+var ErrMissingSMSSender = errors.New("missing SMS sender")
+
+// SMSMessage defines a single SMS message.
+type SMSMessage struct {
+	// To is the recipient phone number (usually in E.164 format).
+	To string `json:"to"`
+
+	// Body is the plain text message content.
+	Body string `json:"body"`
+}
+
+// SMSSender defines a base SMS gateway client interface.
+type SMSSender interface {
+	// Send sends the provided SMS message.
+	Send(ctx context.Context, message *SMSMessage) error
+}
+
+var (
+	_ SMSSender = (SMSSenderFunc)(nil)
+	_ SMSSender = (*HTTPSMSSender)(nil)
+)
+
+// SMSSenderFunc is a function adapter that implements the [SMSSender] interface.
+//
+// Example:
+//
+//	app.OnSMSSend().BindFunc(func(e *core.SMSSendEvent) error {
+//	    e.Sender = core.SMSSenderFunc(func(ctx context.Context, message *core.SMSMessage) error {
+//	        // send the message using your SMS gateway...
+//	        return nil
+//	    })
+//	    return e.Next()
+//	})
+type SMSSenderFunc func(ctx context.Context, message *SMSMessage) error
+
+// Send implements [SMSSender.Send] interface method.
+func (f SMSSenderFunc) Send(ctx context.Context, message *SMSMessage) error {
+	return f(ctx, message)
+}
+
+// SendSMS sends the provided SMS message using the
+// [BaseAppConfig.SMSSender] (or the one set in the [App.OnSMSSend] hook).
+//
+// It returns [ErrMissingSMSSender] if no SMS sender is registered.
+func (app *BaseApp) SendSMS(ctx context.Context, message *SMSMessage) error {
+	event := new(SMSSendEvent)
+	event.App = app
+	event.Context = ctx
+	event.Sender = app.config.SMSSender
+	event.Message = message
+
+	return app.OnSMSSend().Trigger(event, func(e *SMSSendEvent) error {
+		// print the message in the console to assist with the debugging
+		if e.App.IsDev() {
+			logDate := new(strings.Builder)
+			log.New(logDate, "", log.LstdFlags).Print()
+
+			smsLog := new(strings.Builder)
+			smsLog.WriteString(strings.TrimSpace(logDate.String()))
+			smsLog.WriteString(" SMS sent\n")
+			fmt.Fprintf(smsLog, "├─ To: %v\n", e.Message.To)
+			fmt.Fprintf(smsLog, "└─ Body: %v\n", e.Message.Body)
+
+			color.HiBlack("%s", smsLog.String())
+		}
+
+		if e.Sender == nil {
+			return ErrMissingSMSSender
+		}
+
+		return e.Sender.Send(e.Context, e.Message)
+	})
+}
+
+// HTTPSMSSender is a [SMSSender] implementation for the SMS gateways
+// with a generic JSON HTTP API, eg.:
+//
+//	POST {URL}
+//	{"to":"+359888123456","message":"Your code is 123456."}
+//
+// Example usage:
+//
+//	sender := &core.HTTPSMSSender{
+//		URL:     "https://sms.example.com/api/send",
+//		Headers: map[string]string{"Authorization": "Bearer ..."},
+//		Client:  httpclient.New(httpclient.Options{MaxRetries: 2, BreakerThreshold: 5}),
+//	}
+//
+//	app.OnSMSSend().BindFunc(func(e *core.SMSSendEvent) error {
+//		e.Sender = sender
+//		return e.Next()
+//	})
+type HTTPSMSSender struct {
+	// Client is an optional HTTP client to use (default to [http.DefaultClient]).
+	//
+	// In tests it could be replaced with [httpclient.Mock].
+	Client httpclient.Client
+
+	// Headers are optional extra request headers (e.g. "Authorization").
+	Headers map[string]string
+
+	// URL is the SMS gateway send endpoint.
+	URL string
+
+	// ToField is the name of the request body phone number field (default to "to").
+	ToField string
+
+	// MessageField is the name of the request body message field (default to "message").
+	MessageField string
+}
+
+// Send implements [SMSSender.Send] interface method.
+func (s *HTTPSMSSender) Send(ctx context.Context, message *SMSMessage) error {
+	if s.URL == "" {
+		return errors.New("missing HTTPSMSSender.URL")
+	}
+
+	toField := s.ToField
+	if toField == "" {
+		toField = "to"
+	}
+
+	messageField := s.MessageField
+	if messageField == "" {
+		messageField = "message"
+	}
+
+	body, err := json.Marshal(map[string]string{
+		toField:      message.To,
+		messageField: message.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		resBody, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("unexpected SMS gateway response status %d: %s", res.StatusCode, resBody)
+	}
+
+	return nil
+}
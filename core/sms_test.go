@@ -0,0 +1,175 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/httpclient"
+)
+
+func TestSendSMS(t *testing.T) {
+	t.Parallel()
+
+	message := &core.SMSMessage{To: "+359888123456", Body: "test"}
+
+	t.Run("missing sender", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		err := app.SendSMS(context.Background(), message)
+		if !errors.Is(err, core.ErrMissingSMSSender) {
+			t.Fatalf("Expected ErrMissingSMSSender, got %v", err)
+		}
+	})
+
+	t.Run("hook sender", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		var sent []*core.SMSMessage
+
+		app.OnSMSSend().BindFunc(func(e *core.SMSSendEvent) error {
+			e.Message.Body += "_changed"
+			e.Sender = core.SMSSenderFunc(func(ctx context.Context, m *core.SMSMessage) error {
+				sent = append(sent, m)
+				return nil
+			})
+			return e.Next()
+		})
+
+		if err := app.SendSMS(context.Background(), message); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(sent) != 1 {
+			t.Fatalf("Expected 1 sent message, got %d", len(sent))
+		}
+
+		if sent[0].To != message.To || sent[0].Body != "test_changed" {
+			t.Fatalf("Unexpected sent message %#v", sent[0])
+		}
+	})
+
+	t.Run("sender error", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		expectedErr := errors.New("test")
+
+		app.OnSMSSend().BindFunc(func(e *core.SMSSendEvent) error {
+			e.Sender = core.SMSSenderFunc(func(ctx context.Context, m *core.SMSMessage) error {
+				return expectedErr
+			})
+			return e.Next()
+		})
+
+		err := app.SendSMS(context.Background(), &core.SMSMessage{})
+		if !errors.Is(err, expectedErr) {
+			t.Fatalf("Expected error %v, got %v", expectedErr, err)
+		}
+	})
+}
+
+func TestHTTPSMSSender(t *testing.T) {
+	t.Parallel()
+
+	const testPhone = "+359888123456"
+
+	scenarios := []struct {
+		name          string
+		sender        *core.HTTPSMSSender
+		status        int
+		expectError   bool
+		expectedBody  map[string]string
+		expectedCalls int
+	}{
+		{
+			"missing url",
+			&core.HTTPSMSSender{},
+			200,
+			true,
+			nil,
+			0,
+		},
+		{
+			"default fields",
+			&core.HTTPSMSSender{URL: "https://sms.example.com/send"},
+			200,
+			false,
+			map[string]string{"to": testPhone, "message": "test"},
+			1,
+		},
+		{
+			"custom fields",
+			&core.HTTPSMSSender{URL: "https://sms.example.com/send", ToField: "msisdn", MessageField: "text"},
+			202,
+			false,
+			map[string]string{"msisdn": testPhone, "text": "test"},
+			1,
+		},
+		{
+			"error response",
+			&core.HTTPSMSSender{URL: "https://sms.example.com/send"},
+			400,
+			true,
+			map[string]string{"to": testPhone, "message": "test"},
+			1,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			mock := httpclient.NewMock(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(s.status)
+			}))
+
+			s.sender.Client = mock
+			s.sender.Headers = map[string]string{"Authorization": "test_token"}
+
+			err := s.sender.Send(context.Background(), &core.SMSMessage{To: testPhone, Body: "test"})
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			requests := mock.Requests()
+			if len(requests) != s.expectedCalls {
+				t.Fatalf("Expected %d requests, got %d", s.expectedCalls, len(requests))
+			}
+
+			if s.expectedCalls == 0 {
+				return
+			}
+
+			r := requests[0]
+
+			if r.URL != s.sender.URL {
+				t.Fatalf("Expected url %q, got %q", s.sender.URL, r.URL)
+			}
+
+			if v := r.Header.Get("Authorization"); v != "test_token" {
+				t.Fatalf("Expected Authorization header %q, got %q", "test_token", v)
+			}
+
+			body := map[string]string{}
+			if err := json.Unmarshal(r.Body, &body); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(body) != len(s.expectedBody) {
+				t.Fatalf("Expected body %v, got %v", s.expectedBody, body)
+			}
+			for k, v := range s.expectedBody {
+				if body[k] != v {
+					t.Fatalf("Expected body %v, got %v", s.expectedBody, body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrMissingTemplateRenderer is returned by [RequestEvent.Render] when there is
+// no registered template renderer (see [BaseAppConfig.TemplateRenderer]).
+var ErrMissingTemplateRenderer = errors.New("missing template renderer")
+
+// TemplateRenderer defines an interface for rendering named server-side templates
+// (see [template.Views] for a file system based implementation with layout and partials support).
+//
+// [template.Views]: https://pkg.go.dev/github.com/pocketbase/pocketbase/tools/template#Views
+type TemplateRenderer interface {
+	// Render renders the template with the specified name and data as the dot object.
+	Render(name string, data any) (string, error)
+}
+
+// TemplateRenderer returns the app registered template renderer
+// (see [BaseAppConfig.TemplateRenderer]).
+//
+// Returns nil if no template renderer is registered.
+func (app *BaseApp) TemplateRenderer() TemplateRenderer {
+	return app.config.TemplateRenderer
+}
+
+// Render renders the named template with the app registered template
+// renderer and writes the result as 200 HTML response.
+//
+// It returns [ErrMissingTemplateRenderer] if no template renderer is registered.
+//
+// Example:
+//
+//	se.Router.GET("/status", func(e *core.RequestEvent) error {
+//		return e.Render("status.html", map[string]any{"ok": true})
+//	})
+func (e *RequestEvent) Render(name string, data any) error {
+	renderer := e.App.TemplateRenderer()
+	if renderer == nil {
+		return ErrMissingTemplateRenderer
+	}
+
+	html, err := renderer.Render(name, data)
+	if err != nil {
+		return err
+	}
+
+	return e.HTML(http.StatusOK, html)
+}
@@ -0,0 +1,82 @@
+package core_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type testTemplateRenderer struct{}
+
+func (r testTemplateRenderer) Render(name string, data any) (string, error) {
+	if name == "error" {
+		return "", errors.New("test_error")
+	}
+
+	return name + ":" + data.(string), nil
+}
+
+func TestRequestEventRender(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name           string
+		renderer       core.TemplateRenderer
+		template       string
+		expectedError  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"missing renderer", nil, "test", core.ErrMissingTemplateRenderer, 0, ""},
+		{"render error", testTemplateRenderer{}, "error", errors.New("test_error"), 0, ""},
+		{"success", testTemplateRenderer{}, "test", nil, 200, "test:data"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app := core.NewBaseApp(core.BaseAppConfig{
+				DataDir:          t.TempDir(),
+				TemplateRenderer: s.renderer,
+			})
+
+			if app.TemplateRenderer() != s.renderer {
+				t.Fatalf("Expected renderer %v, got %v", s.renderer, app.TemplateRenderer())
+			}
+
+			rec := httptest.NewRecorder()
+
+			e := new(core.RequestEvent)
+			e.App = app
+			e.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			e.Response = rec
+
+			err := e.Render(s.template, "data")
+
+			if s.expectedError != nil {
+				if err == nil || err.Error() != s.expectedError.Error() {
+					t.Fatalf("Expected error %v, got %v", s.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if rec.Code != s.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", s.expectedStatus, rec.Code)
+			}
+
+			if body := rec.Body.String(); body != s.expectedBody {
+				t.Fatalf("Expected body %q, got %q", s.expectedBody, body)
+			}
+
+			if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+				t.Fatalf("Expected html content type, got %q", ct)
+			}
+		})
+	}
+}
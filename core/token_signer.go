@@ -0,0 +1,40 @@
+package core
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// TokenSigner defines an interface for signing and verifying the
+// record tokens (auth, file, verification, password reset and email change).
+//
+// The signingKey/verificationKey argument is the record specific secret
+// (record tokenKey + collection token secret). Implementations that use
+// their own keys should still bind it to the token (eg. as a hashed claim)
+// to preserve the tokens invalidation on password or tokenKey change.
+//
+// Note that the generated tokens must be valid JWTs because their claims
+// are also read without verification (eg. to resolve the token auth record).
+type TokenSigner interface {
+	// Sign generates a new signed token with the provided claims and duration.
+	Sign(claims jwt.MapClaims, signingKey string, duration time.Duration) (string, error)
+
+	// Verify verifies the token signature and expiration and returns its claims.
+	Verify(token string, verificationKey string) (jwt.MapClaims, error)
+}
+
+// HS256TokenSigner is the default [TokenSigner] implementation
+// that signs the tokens with HMAC-SHA256.
+type HS256TokenSigner struct{}
+
+// Sign implements [TokenSigner.Sign].
+func (s HS256TokenSigner) Sign(claims jwt.MapClaims, signingKey string, duration time.Duration) (string, error) {
+	return security.NewJWT(claims, signingKey, duration)
+}
+
+// Verify implements [TokenSigner.Verify].
+func (s HS256TokenSigner) Verify(token string, verificationKey string) (jwt.MapClaims, error) {
+	return security.ParseJWT(token, verificationKey)
+}
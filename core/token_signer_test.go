@@ -0,0 +1,55 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func TestHS256TokenSigner(t *testing.T) {
+	t.Parallel()
+
+	signer := core.HS256TokenSigner{}
+
+	token, err := signer.Sign(jwt.MapClaims{"a": "b"}, "test", 1*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		token       string
+		key         string
+		expectError bool
+	}{
+		{"invalid key", token, "invalid", true},
+		{"invalid token", "invalid", "test", true},
+		{"valid token and key", token, "test", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			claims, err := signer.Verify(s.token, s.key)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && claims["a"] != "b" {
+				t.Fatalf("Expected claim a=b, got %v", claims)
+			}
+		})
+	}
+
+	expiredToken, err := signer.Sign(jwt.MapClaims{"a": "b"}, "test", -1*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := signer.Verify(expiredToken, "test"); err == nil {
+		t.Fatal("Expected expired token error")
+	}
+}
@@ -277,6 +277,25 @@ func (form *RecordUpsert) DrySubmit(callback func(txApp core.App, drySavedRecord
 	return manualRollback()
 }
 
+// Validate runs the form and record validations, including the db
+// unique constraints, without persisting the form record.
+//
+// Note that it doesn't handle file uploads/deletes or trigger the app save events.
+func (form *RecordUpsert) Validate() error {
+	err := form.validateFormFields()
+	if err != nil {
+		return err
+	}
+
+	err = form.app.ValidateWithContext(form.ctx, form.record)
+	if err != nil {
+		return err
+	}
+
+	// check for unique index violations
+	return form.DrySubmit(nil)
+}
+
 // Submit validates the form specific validations and attempts to save the form record.
 func (form *RecordUpsert) Submit() error {
 	err := form.validateFormFields()
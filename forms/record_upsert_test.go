@@ -10,6 +10,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/tests"
@@ -759,6 +760,82 @@ func TestRecordUpsertSubmitValidations(t *testing.T) {
 	}
 }
 
+func TestRecordUpsertValidate(t *testing.T) {
+	t.Parallel()
+
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	usersCol, err := testApp.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		data           map[string]any
+		expectedErrors []string
+	}{
+		{
+			"form fields validation failure",
+			map[string]any{
+				"email":           "new@example.com",
+				"password":        "1234567890",
+				"passwordConfirm": "1234567891",
+			},
+			[]string{"passwordConfirm"},
+		},
+		{
+			"record fields validation failure",
+			map[string]any{
+				"email":           "invalid",
+				"password":        "1234567890",
+				"passwordConfirm": "1234567890",
+			},
+			[]string{"email"},
+		},
+		{
+			"unique constraint failure",
+			map[string]any{
+				"email":           "test@example.com",
+				"password":        "1234567890",
+				"passwordConfirm": "1234567890",
+			},
+			[]string{"email"},
+		},
+		{
+			"valid data",
+			map[string]any{
+				"email":           "new@example.com",
+				"password":        "1234567890",
+				"passwordConfirm": "1234567890",
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			record := core.NewRecord(usersCol)
+
+			form := forms.NewRecordUpsert(testApp, record)
+			form.Load(s.data)
+
+			result := form.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+
+			total, err := testApp.CountRecords(usersCol, dbx.HashExp{"email": "new@example.com"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 0 {
+				t.Fatalf("Expected the record to not be persisted, found %d", total)
+			}
+		})
+	}
+}
+
 func TestRecordUpsertSubmitFailure(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
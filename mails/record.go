@@ -115,8 +115,93 @@ func SendRecordOTP(app core.App, authRecord *core.Record, otpId string, pass str
 	})
 }
 
+// SendRecordMagicLink sends a passwordless magic link email to the specified auth record.
+//
+// The magic link token is linked to the OTP with the specified id, which
+// "sentTo" field is also updated to the mail sent To address (if the OTP exists and not already assigned).
+func SendRecordMagicLink(app core.App, authRecord *core.Record, otpId string) error {
+	authRecord.SetTokenSigner(app.TokenSigner())
+
+	token, tokenErr := authRecord.NewMagicLinkToken(otpId)
+	if tokenErr != nil {
+		return tokenErr
+	}
+
+	mailClient := app.NewMailClient()
+
+	subject, body, err := resolveEmailTemplate(app, authRecord, authRecord.Collection().MagicLink.EmailTemplate, map[string]any{
+		core.EmailPlaceholderToken: token,
+	})
+	if err != nil {
+		return err
+	}
+
+	message := &mailer.Message{
+		From: mail.Address{
+			Name:    app.Settings().Meta.SenderName,
+			Address: app.Settings().Meta.SenderAddress,
+		},
+		To:      []mail.Address{{Address: authRecord.Email()}},
+		Subject: subject,
+		HTML:    body,
+	}
+
+	event := new(core.MailerRecordEvent)
+	event.App = app
+	event.Mailer = mailClient
+	event.Message = message
+	event.Record = authRecord
+	event.Meta = map[string]any{
+		"otpId": otpId,
+		"token": token,
+	}
+
+	return app.OnMailerRecordMagicLinkSend().Trigger(event, func(e *core.MailerRecordEvent) error {
+		err := e.Mailer.Send(e.Message)
+		if err != nil {
+			return err
+		}
+
+		var toAddress string
+		if len(e.Message.To) > 0 {
+			toAddress = e.Message.To[0].Address
+		}
+		if toAddress == "" {
+			return nil
+		}
+
+		otp, err := e.App.FindOTPById(otpId)
+		if err != nil {
+			e.App.Logger().Error(
+				"Failed to find magic link OTP to update its sentTo field",
+				"error", err,
+				"otpId", otpId,
+			)
+			return nil
+		}
+
+		if otp.SentTo() != "" {
+			return nil // was already sent to another target
+		}
+
+		otp.SetSentTo(toAddress)
+		if err = e.App.Save(otp); err != nil {
+			e.App.Logger().Error(
+				"Failed to update magic link OTP sentTo field",
+				"error", err,
+				"otpId", otpId,
+				"to", toAddress,
+			)
+		}
+
+		return nil
+	})
+}
+
 // SendRecordPasswordReset sends a password reset request email to the specified auth record.
 func SendRecordPasswordReset(app core.App, authRecord *core.Record) error {
+	authRecord.SetTokenSigner(app.TokenSigner())
+
 	token, tokenErr := authRecord.NewPasswordResetToken()
 	if tokenErr != nil {
 		return tokenErr
@@ -155,6 +240,8 @@ func SendRecordPasswordReset(app core.App, authRecord *core.Record) error {
 
 // SendRecordVerification sends a verification request email to the specified auth record.
 func SendRecordVerification(app core.App, authRecord *core.Record) error {
+	authRecord.SetTokenSigner(app.TokenSigner())
+
 	token, tokenErr := authRecord.NewVerificationToken()
 	if tokenErr != nil {
 		return tokenErr
@@ -193,6 +280,8 @@ func SendRecordVerification(app core.App, authRecord *core.Record) error {
 
 // SendRecordChangeEmail sends a change email confirmation email to the specified auth record.
 func SendRecordChangeEmail(app core.App, authRecord *core.Record, newEmail string) error {
+	authRecord.SetTokenSigner(app.TokenSigner())
+
 	token, tokenErr := authRecord.NewEmailChangeToken(newEmail)
 	if tokenErr != nil {
 		return tokenErr
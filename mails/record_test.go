@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/mails"
 	"github.com/pocketbase/pocketbase/tests"
 )
@@ -38,6 +39,62 @@ func TestSendRecordAuthAlert(t *testing.T) {
 	}
 }
 
+func TestSendRecordMagicLink(t *testing.T) {
+	t.Parallel()
+
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	user, _ := testApp.FindFirstRecordByData("users", "email", "test@example.com")
+	user.Collection().MagicLinkToken.Duration = 100
+	user.Collection().MagicLink.EmailTemplate = core.EmailTemplate{
+		Subject: "magic link subject",
+		Body:    "magic link {RECORD:name} {TOKEN}",
+	}
+
+	otp := core.NewOTP(testApp)
+	otp.SetCollectionRef(user.Collection().Id)
+	otp.SetRecordRef(user.Id)
+	otp.SetPassword("test")
+	if err := testApp.Save(otp); err != nil {
+		t.Fatal(err)
+	}
+
+	err := mails.SendRecordMagicLink(testApp, user, otp.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if testApp.TestMailer.TotalSend() != 1 {
+		t.Fatalf("Expected one email to be sent, got %d", testApp.TestMailer.TotalSend())
+	}
+
+	html := testApp.TestMailer.LastMessage().HTML
+
+	token := html[strings.LastIndex(html, " ")+1:]
+	token = token[:strings.Index(token, "<")]
+
+	record, err := testApp.FindAuthRecordByToken(token, core.TokenTypeMagicLink)
+	if err != nil {
+		t.Fatalf("Expected a valid magic link token, got %v\n%s", err, html)
+	}
+	if record.Id != user.Id {
+		t.Fatalf("Expected the token to be for record %q, got %q", user.Id, record.Id)
+	}
+
+	if !strings.Contains(html, "magic link "+user.GetString("name")) {
+		t.Fatalf("Couldn't find the resolved template body in\n%s", html)
+	}
+
+	otp, err = testApp.FindOTPById(otp.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otp.SentTo() != user.Email() {
+		t.Fatalf("Expected the OTP sentTo to be updated to %q, got %q", user.Email(), otp.SentTo())
+	}
+}
+
 func TestSendRecordPasswordReset(t *testing.T) {
 	t.Parallel()
 
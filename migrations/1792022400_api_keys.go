@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// create the _apiKeys system collection (if not already)
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		_, err := txApp.FindCollectionByNameOrId(core.CollectionNameAPIKeys)
+		if err == nil {
+			return nil // already exists
+		}
+
+		return createAPIKeysCollection(txApp)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameAPIKeys)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		return txApp.Delete(col)
+	})
+}
+
+func createAPIKeysCollection(txApp core.App) error {
+	col := core.NewBaseCollection(core.CollectionNameAPIKeys)
+	col.System = true
+
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "name",
+		System:   true,
+		Required: true,
+		Max:      255,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "keyHash",
+		System:   true,
+		Hidden:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "hint",
+		System: true,
+		Max:    10,
+	})
+	col.Fields.Add(&core.JSONField{
+		Name:   "scopes",
+		System: true,
+	})
+	col.Fields.Add(&core.DateField{
+		Name:   "expires",
+		System: true,
+	})
+	col.Fields.Add(&core.DateField{
+		Name:   "lastUsed",
+		System: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_apiKeys_collectionRef_recordRef", false, "collectionRef, recordRef", "")
+	col.AddIndex("idx_apiKeys_keyHash", true, "keyHash", "")
+
+	return txApp.Save(col)
+}
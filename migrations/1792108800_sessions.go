@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// create the _sessions system collection (if not already)
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		_, err := txApp.FindCollectionByNameOrId(core.CollectionNameSessions)
+		if err == nil {
+			return nil // already exists
+		}
+
+		return createSessionsCollection(txApp)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameSessions)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		return txApp.Delete(col)
+	})
+}
+
+func createSessionsCollection(txApp core.App) error {
+	col := core.NewBaseCollection(core.CollectionNameSessions)
+	col.System = true
+
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "userAgent",
+		System: true,
+		Max:    500,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "ip",
+		System: true,
+		Max:    100,
+	})
+	col.Fields.Add(&core.DateField{
+		Name:   "lastActive",
+		System: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_sessions_collectionRef_recordRef", false, "collectionRef, recordRef", "")
+
+	return txApp.Save(col)
+}
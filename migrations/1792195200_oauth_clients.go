@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// create the _oauthClients system collection (if not already)
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		_, err := txApp.FindCollectionByNameOrId(core.CollectionNameOAuthClients)
+		if err == nil {
+			return nil // already exists
+		}
+
+		return createOAuthClientsCollection(txApp)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameOAuthClients)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		return txApp.Delete(col)
+	})
+}
+
+func createOAuthClientsCollection(txApp core.App) error {
+	col := core.NewBaseCollection(core.CollectionNameOAuthClients)
+	col.System = true
+
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "name",
+		System:   true,
+		Required: true,
+		Max:      255,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "secretHash",
+		System: true,
+		Hidden: true,
+	})
+	col.Fields.Add(&core.JSONField{
+		Name:     "redirectURIs",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_oauthClients_collectionRef", false, "collectionRef", "")
+
+	return txApp.Save(col)
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// create the _recordChanges table used for the collections change data capture
+func init() {
+	core.SystemMigrations.Add(&core.Migration{
+		Up: func(txApp core.App) error {
+			_, execErr := txApp.DB().NewQuery(`
+				CREATE TABLE IF NOT EXISTS {{_recordChanges}} (
+					[[seq]]           INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+					[[collectionRef]] TEXT NOT NULL,
+					[[recordRef]]     TEXT NOT NULL,
+					[[action]]        TEXT NOT NULL,
+					[[before]]        JSON DEFAULT NULL,
+					[[after]]         JSON DEFAULT NULL,
+					[[created]]       TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_recordChanges_collectionRef_seq on {{_recordChanges}} ([[collectionRef]], [[seq]]);
+			`).Execute()
+
+			return execErr
+		},
+		Down: func(txApp core.App) error {
+			_, err := txApp.DB().DropTable(core.RecordChangesTableName).Execute()
+			return err
+		},
+		ReapplyCondition: func(txApp core.App, runner *core.MigrationsRunner, fileName string) (bool, error) {
+			// reapply only if the _recordChanges table doesn't exist
+			exists := txApp.HasTable(core.RecordChangesTableName)
+			return !exists, nil
+		},
+	})
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"encoding/json"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// initialize the dedicated magic link token options of the existing auth collections
+// (the magic link tokens were previously signed with the auth token secret)
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		collections, err := txApp.FindAllCollections(core.CollectionTypeAuth)
+		if err != nil {
+			return err
+		}
+
+		for _, collection := range collections {
+			if collection.MagicLinkToken.Secret != "" {
+				continue
+			}
+
+			// preserve the previous magicLink.duration option (if any)
+			old := struct {
+				MagicLink struct {
+					Duration int64 `json:"duration"`
+				} `json:"magicLink"`
+			}{}
+			_ = json.Unmarshal(collection.RawOptions, &old)
+
+			collection.MagicLinkToken.Secret = security.RandomString(50)
+			collection.MagicLinkToken.Duration = old.MagicLink.Duration
+			if collection.MagicLinkToken.Duration <= 0 {
+				collection.MagicLinkToken.Duration = 600 // 10min
+			}
+
+			if err := txApp.SaveNoValidate(collection); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, nil)
+}
@@ -0,0 +1,512 @@
+// Package accountdeletion implements a self-service account deletion
+// workflow with a grace period.
+//
+// Requesting a deletion suspends the auth record (all existing tokens
+// are invalidated and new authentications are rejected) and after the
+// grace period the auth record and its owned records are permanently
+// deleted together with their files.
+//
+// The deletion could be canceled during the grace period with the cancel
+// token passed to the [Config.OnRequest] callback (e.g. to send it via email).
+//
+// The plugin registers the following endpoints:
+//
+//	POST /api/collections/{collection}/request-deletion (requires the auth record to be from the path collection)
+//	POST /api/collections/{collection}/cancel-deletion  (body: {"token": "..."})
+//
+// Example usage:
+//
+//	accountdeletion.MustRegister(app, accountdeletion.Config{
+//		Mapping: map[string][]accountdeletion.Source{
+//			"users": {
+//				{Collection: "posts", Field: "author"},
+//			},
+//		},
+//		OnRequest: func(e *accountdeletion.Event) error {
+//			// send e.CancelToken to e.Record.Email()...
+//			return nil
+//		},
+//	})
+package accountdeletion
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameDeletions = "_accountDeletions"
+
+const (
+	DefaultGracePeriod = 30 * 24 * time.Hour
+
+	purgeCronId = "__pbAccountDeletionsPurge__"
+
+	cancelTokenLength = 40
+)
+
+// Source defines a collection with records owned by the deleted auth record.
+type Source struct {
+	// Collection is the name or id of the owned records collection.
+	Collection string
+
+	// Field is the name of the collection relation or text field
+	// that holds the owner auth record id.
+	//
+	// Note that for multiple relation fields all records that
+	// reference the auth record are deleted.
+	Field string
+}
+
+// Event defines the data of a single account deletion event.
+type Event struct {
+	App core.App
+
+	// Record is the auth record scheduled for deletion.
+	Record *core.Record
+
+	// PurgeAt is the date when the auth record will be permanently deleted.
+	PurgeAt types.DateTime
+
+	// CancelToken is the plain deletion cancel token
+	// (available only on deletion request).
+	CancelToken string
+}
+
+// Config defines the config options of the accountdeletion plugin.
+type Config struct {
+	// Mapping is a map with the owned records sources of each auth
+	// collection that supports account deletion, where the key is the
+	// auth collection name or id.
+	//
+	// Use an empty slice if there are no owned records to delete.
+	Mapping map[string][]Source
+
+	// GracePeriod specifies the duration after which the auth record
+	// is permanently deleted (default to 30 days).
+	GracePeriod time.Duration
+
+	// OnRequest is an optional callback that is invoked before
+	// the deletion request is stored.
+	//
+	// Returning an error aborts the deletion request.
+	OnRequest func(e *Event) error
+
+	// OnCancel is an optional callback that is invoked after the
+	// deletion request is canceled.
+	OnCancel func(e *Event) error
+
+	// OnPurge is an optional callback that is invoked inside the purge
+	// transaction right before the auth record deletion.
+	//
+	// Returning an error aborts the purge and it will be retried on the next run.
+	OnPurge func(e *Event) error
+}
+
+// MustRegister registers the accountdeletion plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the accountdeletion plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if len(p.config.Mapping) == 0 {
+		return errors.New("accountdeletion: at least one auth collection mapping must be specified")
+	}
+
+	for authCollection, sources := range p.config.Mapping {
+		for i, s := range sources {
+			if s.Collection == "" || s.Field == "" {
+				return fmt.Errorf("accountdeletion: %q source %d must have Collection and Field", authCollection, i)
+			}
+		}
+	}
+
+	if p.config.GracePeriod <= 0 {
+		p.config.GracePeriod = DefaultGracePeriod
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollection(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollection()
+		})
+	}
+
+	collections := p.authCollections()
+
+	// suspend the login of the auth records scheduled for deletion
+	p.app.OnRecordAuthRequest(collections...).BindFunc(func(e *core.RecordAuthRequestEvent) error {
+		if deletion, _ := findDeletion(e.App, e.Record); deletion != nil {
+			return e.ForbiddenError("The account is scheduled for deletion.", nil)
+		}
+
+		return e.Next()
+	})
+
+	// delete the deletion requests of the deleted auth records
+	p.app.OnRecordDeleteExecute(collections...).Bind(&hook.Handler[*core.RecordEvent]{
+		Func: func(e *core.RecordEvent) error {
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp core.App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				deletion, _ := findDeletion(txApp, e.Record)
+				if deletion == nil {
+					return nil
+				}
+
+				return txApp.Delete(deletion)
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group("/api/collections/{collection}")
+		sub.POST("/request-deletion", p.requestDeletion).Bind(apis.RequireSameCollectionContextAuth(""))
+		sub.POST("/cancel-deletion", p.cancelDeletion)
+
+		return e.Next()
+	})
+
+	return p.app.Cron().Add(purgeCronId, "*/10 * * * *", func() {
+		if err := p.purgeDue(); err != nil {
+			p.app.Logger().Error("Failed to purge the accounts scheduled for deletion", "error", err.Error())
+		}
+	})
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) authCollections() []string {
+	names := make([]string, 0, len(p.config.Mapping))
+	for name := range p.config.Mapping {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func (p *plugin) ensureCollection() error {
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameDeletions); err == nil {
+		return nil // already created
+	}
+
+	col := core.NewBaseCollection(CollectionNameDeletions)
+	col.System = true
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "cancelTokenHash",
+		System:   true,
+		Hidden:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.DateField{
+		Name:     "purgeAt",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_accountDeletions_unique", true, "collectionRef, recordRef", "")
+	col.AddIndex("idx_accountDeletions_cancelTokenHash", true, "cancelTokenHash", "")
+	col.AddIndex("idx_accountDeletions_purgeAt", false, "purgeAt", "")
+
+	return p.app.Save(col)
+}
+
+func (p *plugin) sources(collection *core.Collection) ([]Source, bool) {
+	if sources, ok := p.config.Mapping[collection.Name]; ok {
+		return sources, true
+	}
+
+	sources, ok := p.config.Mapping[collection.Id]
+
+	return sources, ok
+}
+
+// findCollection returns the request path auth collection
+// if it is configured to support account deletion.
+func (p *plugin) findCollection(e *core.RequestEvent) (*core.Collection, error) {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || !collection.IsAuth() {
+		return nil, e.NotFoundError("Missing or invalid auth collection context.", err)
+	}
+
+	if _, ok := p.sources(collection); !ok {
+		return nil, e.NotFoundError("Missing or invalid auth collection context.", nil)
+	}
+
+	return collection, nil
+}
+
+func (p *plugin) requestDeletion(e *core.RequestEvent) error {
+	if _, err := p.findCollection(e); err != nil {
+		return err
+	}
+
+	record := e.Auth
+
+	if deletion, _ := findDeletion(e.App, record); deletion != nil {
+		return e.BadRequestError("The account is already scheduled for deletion.", nil)
+	}
+
+	deletionsCollection, err := e.App.FindCachedCollectionByNameOrId(CollectionNameDeletions)
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	purgeAt, err := types.ParseDateTime(time.Now().Add(p.config.GracePeriod))
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	event := &Event{
+		App:         e.App,
+		Record:      record,
+		PurgeAt:     purgeAt,
+		CancelToken: security.RandomString(cancelTokenLength),
+	}
+
+	if p.config.OnRequest != nil {
+		if err := p.config.OnRequest(event); err != nil {
+			return e.BadRequestError("Failed to request account deletion.", err)
+		}
+	}
+
+	deletion := core.NewRecord(deletionsCollection)
+	deletion.Set("collectionRef", record.Collection().Id)
+	deletion.Set("recordRef", record.Id)
+	deletion.Set("cancelTokenHash", security.SHA256(event.CancelToken))
+	deletion.Set("purgeAt", event.PurgeAt)
+
+	txErr := e.App.RunInTransaction(func(txApp core.App) error {
+		if err := txApp.Save(deletion); err != nil {
+			return err
+		}
+
+		// invalidate all previously issued tokens
+		record.RefreshTokenKey()
+
+		return txApp.Save(record)
+	})
+	if txErr != nil {
+		return e.BadRequestError("Failed to request account deletion.", txErr)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"purgeAt": event.PurgeAt,
+	})
+}
+
+type cancelDeletionForm struct {
+	Token string `form:"token" json:"token"`
+}
+
+func (p *plugin) cancelDeletion(e *core.RequestEvent) error {
+	collection, err := p.findCollection(e)
+	if err != nil {
+		return err
+	}
+
+	form := &cancelDeletionForm{}
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	err = validation.ValidateStruct(form,
+		validation.Field(&form.Token, validation.Required, validation.Length(1, 255)),
+	)
+	if err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	deletion, err := e.App.FindFirstRecordByFilter(
+		CollectionNameDeletions,
+		"collectionRef = {:collectionRef} && cancelTokenHash = {:hash}",
+		dbx.Params{"collectionRef": collection.Id, "hash": security.SHA256(form.Token)},
+	)
+	if err != nil {
+		return invalidTokenError()
+	}
+
+	record, err := e.App.FindRecordById(collection, deletion.GetString("recordRef"))
+	if err != nil {
+		return invalidTokenError()
+	}
+
+	if err := e.App.Delete(deletion); err != nil {
+		return e.BadRequestError("Failed to cancel account deletion.", err)
+	}
+
+	if p.config.OnCancel != nil {
+		err := p.config.OnCancel(&Event{
+			App:     e.App,
+			Record:  record,
+			PurgeAt: deletion.GetDateTime("purgeAt"),
+		})
+		if err != nil {
+			return e.InternalServerError("Failed to process the canceled account deletion.", err)
+		}
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// purgeDue permanently deletes the auth records whose grace period has expired.
+//
+// The purge of each auth record is executed in a separate transaction
+// and the failed purges are retried on the next run.
+func (p *plugin) purgeDue() error {
+	deletions, err := p.app.FindRecordsByFilter(
+		CollectionNameDeletions,
+		"purgeAt <= {:now}",
+		"purgeAt",
+		100,
+		0,
+		dbx.Params{"now": types.NowDateTime()},
+	)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, deletion := range deletions {
+		if err := p.purge(deletion); err != nil {
+			errs = append(errs, fmt.Errorf("failed to purge %q: %w", deletion.GetString("recordRef"), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *plugin) purge(deletion *core.Record) error {
+	return p.app.RunInTransaction(func(txApp core.App) error {
+		record, err := txApp.FindRecordById(deletion.GetString("collectionRef"), deletion.GetString("recordRef"))
+		if err != nil {
+			// the auth record was already deleted
+			return txApp.Delete(deletion)
+		}
+
+		sources, _ := p.sources(record.Collection())
+		for _, s := range sources {
+			if err := deleteOwned(txApp, s, record); err != nil {
+				return fmt.Errorf("failed to delete the owned %q records: %w", s.Collection, err)
+			}
+		}
+
+		if p.config.OnPurge != nil {
+			err := p.config.OnPurge(&Event{
+				App:     txApp,
+				Record:  record,
+				PurgeAt: deletion.GetDateTime("purgeAt"),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := txApp.Delete(deletion); err != nil {
+			return err
+		}
+
+		return txApp.Delete(record)
+	})
+}
+
+func deleteOwned(app core.App, s Source, record *core.Record) error {
+	collection, err := app.FindCachedCollectionByNameOrId(s.Collection)
+	if err != nil {
+		return err
+	}
+
+	field := collection.Fields.GetByName(s.Field)
+	if field == nil {
+		return fmt.Errorf("missing field %q", s.Field)
+	}
+
+	filter := s.Field + " = {:ownerId}"
+	if _, ok := field.(core.MultiValuer); ok {
+		filter = s.Field + ":each ?= {:ownerId}"
+	}
+
+	records, err := app.FindRecordsByFilter(collection, filter, "", 0, 0, dbx.Params{"ownerId": record.Id})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		if err := app.Delete(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func findDeletion(app core.App, record *core.Record) (*core.Record, error) {
+	return app.FindFirstRecordByFilter(
+		CollectionNameDeletions,
+		"collectionRef = {:collectionRef} && recordRef = {:recordRef}",
+		dbx.Params{"collectionRef": record.Collection().Id, "recordRef": record.Id},
+	)
+}
+
+func invalidTokenError() error {
+	return apis.NewBadRequestError("Invalid or expired cancel token.", validation.Errors{
+		"token": validation.NewError("validation_invalid_token", "Invalid or expired cancel token."),
+	})
+}
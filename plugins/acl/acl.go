@@ -0,0 +1,401 @@
+// Package acl implements an optional per-record sharing subsystem.
+//
+// Individual records could be shared with specific auth records
+// with "read" or "write" access level ("write" implies "read").
+//
+// The shares are stored in the "_shares" system collection and
+// could be checked in the collection API rules with the
+// "@request.auth.canAccess(id, level)" rule function, eg.:
+//
+//	// ViewRule
+//	owner = @request.auth.id || @request.auth.canAccess(id, "read") = true
+//
+//	// UpdateRule
+//	owner = @request.auth.id || @request.auth.canAccess(id, "write") = true
+//
+// The shares of a single record could be managed by the users that
+// satisfy the record collection UpdateRule via the following endpoints:
+//
+//	GET    /api/collections/{collection}/records/{id}/shares
+//	POST   /api/collections/{collection}/records/{id}/shares          (body: {"principal": "USER_ID", "level": "read"})
+//	DELETE /api/collections/{collection}/records/{id}/shares/{shareId}
+//
+// Example usage:
+//
+//	acl.MustRegister(app, acl.Config{})
+package acl
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+const CollectionNameShares = "_shares"
+
+// RuleFunctionName is the name of the registered ACL rule function.
+const RuleFunctionName = "@request.auth.canAccess"
+
+const (
+	LevelRead  = "read"
+	LevelWrite = "write"
+)
+
+// Config defines the config options of the acl plugin.
+type Config struct {
+	// Collections is an optional list with the names of the
+	// collections whose records could be shared
+	// (default to all non-system collections).
+	Collections []string
+}
+
+// MustRegister registers the acl plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the acl plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollection(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollection()
+		})
+	}
+
+	core.RegisterRuleFunction(RuleFunctionName, canAccessRuleFunc)
+
+	// delete the shares of the deleted records
+	p.app.OnRecordDeleteExecute().Bind(&hook.Handler[*core.RecordEvent]{
+		Func: func(e *core.RecordEvent) error {
+			if e.Record.Collection().Name == CollectionNameShares {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp core.App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				return deleteShares(txApp, dbx.HashExp{
+					"collectionRef": e.Record.Collection().Id,
+					"recordRef":     e.Record.Id,
+				})
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+
+	// delete the shares of the deleted collections
+	p.app.OnCollectionDeleteExecute().Bind(&hook.Handler[*core.CollectionEvent]{
+		Func: func(e *core.CollectionEvent) error {
+			if e.Collection.Name == CollectionNameShares {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp core.App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				return deleteShares(txApp, dbx.HashExp{"collectionRef": e.Collection.Id})
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group("/api/collections/{collection}/records/{id}/shares")
+		sub.GET("", p.listShares)
+		sub.POST("", p.createShare)
+		sub.DELETE("/{shareId}", p.deleteShare)
+
+		return e.Next()
+	})
+
+	return nil
+}
+
+// Levels returns the list of access levels that satisfy the specified level.
+func Levels(level string) ([]string, error) {
+	switch level {
+	case LevelRead:
+		return []string{LevelRead, LevelWrite}, nil
+	case LevelWrite:
+		return []string{LevelWrite}, nil
+	}
+
+	return nil, fmt.Errorf("invalid access level %q", level)
+}
+
+// canAccessRuleFunc implements the "@request.auth.canAccess(id, level)" rule function.
+func canAccessRuleFunc(ctx *core.RuleFunctionContext, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+	if len(args) != 2 {
+		return nil, errors.New(RuleFunctionName + "() expects exactly 2 arguments")
+	}
+
+	if len(args[1].Params) != 1 {
+		return nil, errors.New(RuleFunctionName + "() expects the access level to be a plain text argument")
+	}
+
+	var level string
+	for _, v := range args[1].Params {
+		level = cast.ToString(v)
+	}
+
+	levels, err := Levels(level)
+	if err != nil {
+		return nil, fmt.Errorf("%s(): %w", RuleFunctionName, err)
+	}
+
+	if ctx.RequestInfo == nil || ctx.RequestInfo.Auth == nil {
+		return &search.ResolverResult{Identifier: "0"}, nil
+	}
+
+	suffix := security.PseudorandomString(5)
+
+	params := search.MergeParams(args[0])
+	params["aclCollection"+suffix] = ctx.Collection.Id
+	params["aclPrincipal"+suffix] = ctx.RequestInfo.Auth.Id
+
+	levelPlaceholders := ""
+	for i, l := range levels {
+		name := fmt.Sprintf("aclLevel%d%s", i, suffix)
+		params[name] = l
+		if i > 0 {
+			levelPlaceholders += ","
+		}
+		levelPlaceholders += "{:" + name + "}"
+	}
+
+	return &search.ResolverResult{
+		Identifier: fmt.Sprintf(
+			"(EXISTS (SELECT 1 FROM [[%s]] [[__acl%s]] WHERE [[__acl%s.collectionRef]] = {:aclCollection%s} AND [[__acl%s.recordRef]] = %s AND [[__acl%s.principal]] = {:aclPrincipal%s} AND [[__acl%s.level]] IN (%s)))",
+			CollectionNameShares, suffix,
+			suffix, suffix,
+			suffix, args[0].Identifier,
+			suffix, suffix,
+			suffix, levelPlaceholders,
+		),
+		Params: params,
+	}, nil
+}
+
+func deleteShares(app core.App, exp dbx.Expression) error {
+	shares, err := app.FindAllRecords(CollectionNameShares, exp)
+	if err != nil {
+		return err
+	}
+
+	for _, share := range shares {
+		if err := app.Delete(share); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) ensureCollection() error {
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameShares); err == nil {
+		return nil // already created
+	}
+
+	col := core.NewBaseCollection(CollectionNameShares)
+	col.System = true
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "principal",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.SelectField{
+		Name:      "level",
+		System:    true,
+		Required:  true,
+		MaxSelect: 1,
+		Values:    []string{LevelRead, LevelWrite},
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_shares_unique", true, "collectionRef, recordRef, principal", "")
+	col.AddIndex("idx_shares_principal", false, "principal", "")
+
+	return p.app.Save(col)
+}
+
+// loadManagedRecord loads the request path record and checks
+// whether the current request auth can manage its shares.
+func (p *plugin) loadManagedRecord(e *core.RequestEvent) (*core.Record, error) {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection.IsView() || collection.System ||
+		(len(p.config.Collections) > 0 && !list.ExistInSlice(collection.Name, p.config.Collections)) {
+		return nil, e.NotFoundError("Missing or invalid collection context.", err)
+	}
+
+	record, err := e.App.FindRecordById(collection, e.Request.PathValue("id"))
+	if err != nil {
+		return nil, e.NotFoundError("", err)
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return nil, e.BadRequestError("", err)
+	}
+
+	canManage, err := e.App.CanAccessRecord(record, requestInfo, collection.UpdateRule)
+	if !canManage {
+		// hide the record existence
+		return nil, e.NotFoundError("", err)
+	}
+
+	return record, nil
+}
+
+func (p *plugin) listShares(e *core.RequestEvent) error {
+	record, err := p.loadManagedRecord(e)
+	if err != nil {
+		return err
+	}
+
+	shares, err := e.App.FindAllRecords(
+		CollectionNameShares,
+		dbx.HashExp{"collectionRef": record.Collection().Id, "recordRef": record.Id},
+	)
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	return e.JSON(http.StatusOK, shares)
+}
+
+type shareForm struct {
+	Principal string `form:"principal" json:"principal"`
+	Level     string `form:"level" json:"level"`
+}
+
+func (p *plugin) createShare(e *core.RequestEvent) error {
+	record, err := p.loadManagedRecord(e)
+	if err != nil {
+		return err
+	}
+
+	form := &shareForm{}
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	err = validation.ValidateStruct(form,
+		validation.Field(&form.Principal, validation.Required, validation.Length(1, 255)),
+		validation.Field(&form.Level, validation.Required, validation.In(LevelRead, LevelWrite)),
+	)
+	if err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	share, err := e.App.FindFirstRecordByFilter(
+		CollectionNameShares,
+		"collectionRef = {:collectionRef} && recordRef = {:recordRef} && principal = {:principal}",
+		dbx.Params{
+			"collectionRef": record.Collection().Id,
+			"recordRef":     record.Id,
+			"principal":     form.Principal,
+		},
+	)
+	if err != nil {
+		collection, err := e.App.FindCachedCollectionByNameOrId(CollectionNameShares)
+		if err != nil {
+			return e.InternalServerError("", err)
+		}
+
+		share = core.NewRecord(collection)
+		share.Set("collectionRef", record.Collection().Id)
+		share.Set("recordRef", record.Id)
+		share.Set("principal", form.Principal)
+	}
+	share.Set("level", form.Level)
+
+	if err := e.App.Save(share); err != nil {
+		return e.BadRequestError("Failed to save the record share.", err)
+	}
+
+	return e.JSON(http.StatusOK, share)
+}
+
+func (p *plugin) deleteShare(e *core.RequestEvent) error {
+	record, err := p.loadManagedRecord(e)
+	if err != nil {
+		return err
+	}
+
+	share, err := e.App.FindRecordById(CollectionNameShares, e.Request.PathValue("shareId"))
+	if err != nil ||
+		share.GetString("collectionRef") != record.Collection().Id ||
+		share.GetString("recordRef") != record.Id {
+		return e.NotFoundError("", err)
+	}
+
+	if err := e.App.Delete(share); err != nil {
+		return e.BadRequestError("Failed to delete the record share.", err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
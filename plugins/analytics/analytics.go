@@ -0,0 +1,606 @@
+// Package analytics implements a lightweight product analytics events
+// ingestion with scheduled hourly and daily rollups.
+//
+// The events submitted to "POST /api/events" are buffered in memory
+// and appended in batches to the "_analyticsEvents" table of the
+// auxiliary app database.
+//
+// An hourly and a daily cron jobs aggregate the raw events into the
+// "_analyticsRollups" table (total events and unique visitors per
+// event name and UTC hour/day) which could be queried by the superusers
+// with "GET /api/events/stats". The daily job also deletes the raw
+// events older than the configured retention.
+//
+// Example usage:
+//
+//	analytics.MustRegister(app, analytics.Config{
+//		RawRetention: 14 * 24 * time.Hour,
+//	})
+//
+// Example request:
+//
+//	POST /api/events
+//	{"events": [{"name": "signup", "sessionId": "abc", "props": {"plan": "pro"}}]}
+package analytics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// EventsTable is the name of the raw events aux db table.
+	EventsTable = "_analyticsEvents"
+
+	// RollupsTable is the name of the aggregated events aux db table.
+	RollupsTable = "_analyticsRollups"
+)
+
+const (
+	PeriodHour = "hour"
+	PeriodDay  = "day"
+)
+
+const (
+	// HourlyCronJobId is the id of the cron job that rollups the hourly stats.
+	HourlyCronJobId = "__pbAnalyticsHourly__"
+
+	// DailyCronJobId is the id of the cron job that rollups the daily
+	// stats and deletes the expired raw events.
+	DailyCronJobId = "__pbAnalyticsDaily__"
+)
+
+const (
+	// DefaultBufferSize is the default max number of buffered
+	// events after which they are written to the db.
+	DefaultBufferSize = 500
+
+	// DefaultFlushInterval is the default interval for writing
+	// the buffered events to the db.
+	DefaultFlushInterval = 2 * time.Second
+
+	// DefaultMaxEvents is the default max number of events per request.
+	DefaultMaxEvents = 100
+
+	// DefaultMaxPropsSize is the default max serialized event props size (in bytes).
+	DefaultMaxPropsSize = 4 << 10
+
+	// DefaultRawRetention is the default max age of the raw events.
+	DefaultRawRetention = 30 * 24 * time.Hour
+
+	// MinRawRetention is the min allowed raw events retention
+	// (so that there is always enough data for recalculating the last daily rollup).
+	MinRawRetention = 48 * time.Hour
+)
+
+// Config defines the config options of the analytics plugin.
+type Config struct {
+	// RequireAuth allows the events ingestion only for authenticated requests.
+	RequireAuth bool
+
+	// BufferSize specifies the max number of buffered events after
+	// which they are written to the db (default to [DefaultBufferSize]).
+	BufferSize int
+
+	// FlushInterval specifies the interval for writing the buffered
+	// events to the db (default to [DefaultFlushInterval]).
+	FlushInterval time.Duration
+
+	// MaxEvents specifies the max number of events per ingestion
+	// request (default to [DefaultMaxEvents]).
+	MaxEvents int
+
+	// MaxPropsSize specifies the max serialized event props size
+	// in bytes (default to [DefaultMaxPropsSize]).
+	MaxPropsSize int
+
+	// RawRetention specifies the max age of the raw events
+	// (default to [DefaultRawRetention], min [MinRawRetention]).
+	RawRetention time.Duration
+}
+
+// Event defines a single submitted analytics event.
+type Event struct {
+	// Name is the event name (e.g. "signup", "page_view").
+	Name string `json:"name"`
+
+	// SessionId is an optional client generated identifier used
+	// for counting the unique visitors of guest requests.
+	SessionId string `json:"sessionId"`
+
+	// Props is an optional map with arbitrary event properties.
+	Props types.JSONMap[any] `json:"props"`
+
+	// UserId is the id of the auth record that submitted the event (if any).
+	UserId string `json:"-"`
+
+	Created time.Time `json:"-"`
+}
+
+func (ev *Event) validate(maxPropsSize int) error {
+	return validation.ValidateStruct(ev,
+		validation.Field(&ev.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&ev.SessionId, validation.Length(0, 100)),
+		validation.Field(&ev.Props, validation.By(func(value any) error {
+			raw, err := json.Marshal(ev.Props)
+			if err != nil {
+				return err
+			}
+
+			if len(raw) > maxPropsSize {
+				return validation.NewError(
+					"validation_props_too_large",
+					fmt.Sprintf("The event props must be less than %d bytes.", maxPropsSize),
+				)
+			}
+
+			return nil
+		})),
+	)
+}
+
+// Stat defines a single rollup stats entry.
+type Stat struct {
+	// Bucket is the start of the UTC hour or day of the stats entry.
+	Bucket types.DateTime `json:"bucket"`
+
+	Name string `json:"name"`
+
+	// Total is the total number of the events.
+	Total int `json:"total"`
+
+	// Visitors is the number of the unique users or sessions.
+	Visitors int `json:"visitors"`
+}
+
+// MustRegister registers the analytics plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the analytics plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if p.config.BufferSize <= 0 {
+		p.config.BufferSize = DefaultBufferSize
+	}
+
+	if p.config.FlushInterval <= 0 {
+		p.config.FlushInterval = DefaultFlushInterval
+	}
+
+	if p.config.MaxEvents <= 0 {
+		p.config.MaxEvents = DefaultMaxEvents
+	}
+
+	if p.config.MaxPropsSize <= 0 {
+		p.config.MaxPropsSize = DefaultMaxPropsSize
+	}
+
+	if p.config.RawRetention == 0 {
+		p.config.RawRetention = DefaultRawRetention
+	}
+
+	if p.config.RawRetention < MinRawRetention {
+		return fmt.Errorf("analytics: RawRetention must be at least %s", MinRawRetention)
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureTables(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureTables()
+		})
+	}
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		e.Router.POST("/api/events", p.ingest).Bind(apis.SkipSuccessActivityLog())
+
+		e.Router.GET("/api/events/stats", p.stats).Bind(
+			apis.RequireSuperuserAuth(),
+			apis.SkipSuccessActivityLog(),
+		)
+
+		p.start()
+
+		return e.Next()
+	})
+
+	p.app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+		p.stop()
+
+		return e.Next()
+	})
+
+	err := p.app.Cron().Add(HourlyCronJobId, "0 * * * *", func() {
+		p.flushAndLog()
+
+		if err := Rollup(p.app, PeriodHour); err != nil {
+			p.app.Logger().Error("Failed to rollup the hourly analytics events", slog.String("error", err.Error()))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return p.app.Cron().Add(DailyCronJobId, "5 0 * * *", func() {
+		p.flushAndLog()
+
+		if err := Rollup(p.app, PeriodDay); err != nil {
+			p.app.Logger().Error("Failed to rollup the daily analytics events", slog.String("error", err.Error()))
+		}
+
+		if err := Prune(p.app, time.Now().Add(-p.config.RawRetention)); err != nil {
+			p.app.Logger().Error("Failed to delete the expired analytics events", slog.String("error", err.Error()))
+		}
+	})
+}
+
+type plugin struct {
+	app    core.App
+	config Config
+
+	mu     sync.Mutex
+	buffer []*Event
+	done   chan struct{}
+}
+
+func (p *plugin) ensureTables() error {
+	_, err := p.app.AuxNonconcurrentDB().NewQuery(`
+		CREATE TABLE IF NOT EXISTS {{` + EventsTable + `}} (
+			[[id]]        INTEGER PRIMARY KEY NOT NULL,
+			[[name]]      TEXT NOT NULL,
+			[[sessionId]] TEXT DEFAULT "" NOT NULL,
+			[[userId]]    TEXT DEFAULT "" NOT NULL,
+			[[props]]     JSON DEFAULT "{}" NOT NULL,
+			[[created]]   INTEGER NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_analyticsEvents_created on {{` + EventsTable + `}} ([[created]]);
+
+		CREATE TABLE IF NOT EXISTS {{` + RollupsTable + `}} (
+			[[period]]   TEXT NOT NULL,
+			[[bucket]]   INTEGER NOT NULL,
+			[[name]]     TEXT NOT NULL,
+			[[total]]    INTEGER NOT NULL,
+			[[visitors]] INTEGER NOT NULL,
+			PRIMARY KEY ([[period]], [[bucket]], [[name]])
+		) WITHOUT ROWID;
+	`).Execute()
+
+	return err
+}
+
+// start starts the background buffered events flush loop.
+func (p *plugin) start() {
+	p.mu.Lock()
+	if p.done != nil {
+		p.mu.Unlock()
+		return // already started
+	}
+	p.done = make(chan struct{})
+	done := p.done
+	p.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(p.config.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				p.flushAndLog()
+			}
+		}
+	}()
+}
+
+// stop stops the flush loop and writes the remaining buffered events.
+func (p *plugin) stop() {
+	p.mu.Lock()
+	if p.done != nil {
+		close(p.done)
+		p.done = nil
+	}
+	p.mu.Unlock()
+
+	p.flushAndLog()
+}
+
+// enqueue adds the provided events to the buffer and writes
+// them immediately if the buffer is full.
+func (p *plugin) enqueue(events []*Event) error {
+	p.mu.Lock()
+	p.buffer = append(p.buffer, events...)
+	full := len(p.buffer) >= p.config.BufferSize
+	p.mu.Unlock()
+
+	if full {
+		return p.flush()
+	}
+
+	return nil
+}
+
+// flush writes all buffered events to the db.
+func (p *plugin) flush() error {
+	p.mu.Lock()
+	events := p.buffer
+	p.buffer = nil
+	p.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	return p.app.AuxRunInTransaction(func(txApp core.App) error {
+		for _, ev := range events {
+			_, err := txApp.AuxDB().Insert(EventsTable, dbx.Params{
+				"name":      ev.Name,
+				"sessionId": ev.SessionId,
+				"userId":    ev.UserId,
+				"props":     ev.Props,
+				"created":   ev.Created.Unix(),
+			}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (p *plugin) flushAndLog() {
+	if err := p.flush(); err != nil {
+		p.app.Logger().Error("Failed to write the analytics events", slog.String("error", err.Error()))
+	}
+}
+
+func (p *plugin) ingest(e *core.RequestEvent) error {
+	if p.config.RequireAuth && e.Auth == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	body := struct {
+		Events []*Event `json:"events"`
+	}{}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	if len(body.Events) == 0 || len(body.Events) > p.config.MaxEvents {
+		return e.BadRequestError(fmt.Sprintf("The request must contain between 1 and %d events.", p.config.MaxEvents), nil)
+	}
+
+	now := time.Now()
+
+	for i, ev := range body.Events {
+		if ev == nil {
+			return e.BadRequestError("Invalid event "+strconv.Itoa(i)+".", nil)
+		}
+
+		if err := ev.validate(p.config.MaxPropsSize); err != nil {
+			return e.BadRequestError("Invalid event "+strconv.Itoa(i)+".", err)
+		}
+
+		if ev.Props == nil {
+			ev.Props = types.JSONMap[any]{}
+		}
+
+		if e.Auth != nil {
+			ev.UserId = e.Auth.Id
+		}
+
+		ev.Created = now
+	}
+
+	if err := p.enqueue(body.Events); err != nil {
+		return e.InternalServerError("Failed to store the events.", err)
+	}
+
+	return e.NoContent(http.StatusAccepted)
+}
+
+func (p *plugin) stats(e *core.RequestEvent) error {
+	query := e.Request.URL.Query()
+
+	period := query.Get("period")
+	if period == "" {
+		period = PeriodHour
+	}
+
+	size, err := periodSize(period)
+	if err != nil {
+		return e.BadRequestError("Invalid period.", err)
+	}
+
+	to := time.Now()
+	if raw := query.Get("to"); raw != "" {
+		dt, err := types.ParseDateTime(raw)
+		if err != nil || dt.IsZero() {
+			return e.BadRequestError("Invalid to date.", err)
+		}
+		to = dt.Time()
+	}
+
+	// default to the last 24 periods
+	from := to.Add(-24 * size)
+	if raw := query.Get("from"); raw != "" {
+		dt, err := types.ParseDateTime(raw)
+		if err != nil || dt.IsZero() {
+			return e.BadRequestError("Invalid from date.", err)
+		}
+		from = dt.Time()
+	}
+
+	var names []string
+	if name := query.Get("name"); name != "" {
+		names = append(names, name)
+	}
+
+	stats, err := FindStats(e.App, period, from, to, names...)
+	if err != nil {
+		return e.BadRequestError("Failed to load the events stats.", err)
+	}
+
+	return e.JSON(http.StatusOK, stats)
+}
+
+// -------------------------------------------------------------------
+
+func periodSize(period string) (time.Duration, error) {
+	switch period {
+	case PeriodHour:
+		return time.Hour, nil
+	case PeriodDay:
+		return 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("unknown period %q", period)
+	}
+}
+
+// Rollup aggregates the raw events into the specified period ([PeriodHour] or [PeriodDay]) stats.
+//
+// The aggregation resumes from the last (possibly partial) rolled up
+// bucket so it is safe to be called multiple times.
+func Rollup(app core.App, period string) error {
+	size, err := periodSize(period)
+	if err != nil {
+		return err
+	}
+	seconds := int64(size / time.Second)
+
+	return app.AuxRunInTransaction(func(txApp core.App) error {
+		var from int64
+		err := txApp.AuxDB().Select("COALESCE(MAX([[bucket]]), -1)").
+			From(RollupsTable).
+			Where(dbx.HashExp{"period": period}).
+			Row(&from)
+		if err != nil {
+			return err
+		}
+
+		if from < 0 {
+			from = 0 // nothing rolled up yet
+		}
+
+		_, err = txApp.AuxDB().Delete(RollupsTable, dbx.And(
+			dbx.HashExp{"period": period},
+			dbx.NewExp("[[bucket]] >= {:from}", dbx.Params{"from": from}),
+		)).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, err = txApp.AuxDB().NewQuery(`
+			INSERT INTO {{` + RollupsTable + `}} ([[period]], [[bucket]], [[name]], [[total]], [[visitors]])
+			SELECT
+				{:period},
+				[[created]] - ([[created]] % {:size}) as [[bucket]],
+				[[name]],
+				COUNT(*),
+				COUNT(DISTINCT COALESCE(NULLIF([[userId]], ''), NULLIF([[sessionId]], '')))
+			FROM {{` + EventsTable + `}}
+			WHERE [[created]] >= {:from}
+			GROUP BY [[bucket]], [[name]]
+		`).Bind(dbx.Params{
+			"period": period,
+			"size":   seconds,
+			"from":   from,
+		}).Execute()
+
+		return err
+	})
+}
+
+// Prune deletes the raw events created before the specified date.
+func Prune(app core.App, before time.Time) error {
+	_, err := app.AuxNonconcurrentDB().Delete(
+		EventsTable,
+		dbx.NewExp("[[created]] < {:before}", dbx.Params{"before": before.Unix()}),
+	).Execute()
+
+	return err
+}
+
+// FindStats returns the rolled up period stats within the specified
+// [from, to) date range, ordered by their bucket and name.
+//
+// If names are specified, only the stats of the matching events are returned.
+func FindStats(app core.App, period string, from time.Time, to time.Time, names ...string) ([]*Stat, error) {
+	size, err := periodSize(period)
+	if err != nil {
+		return nil, err
+	}
+
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+
+	seconds := int64(size / time.Second)
+	fromBucket := from.Unix() - from.Unix()%seconds
+
+	query := app.AuxDB().
+		Select("bucket", "name", "total", "visitors").
+		From(RollupsTable).
+		Where(dbx.HashExp{"period": period}).
+		AndWhere(dbx.NewExp(
+			"[[bucket]] >= {:from} AND [[bucket]] < {:to}",
+			dbx.Params{"from": fromBucket, "to": to.Unix()},
+		)).
+		OrderBy("bucket ASC", "name ASC")
+
+	if len(names) > 0 {
+		query.AndWhere(dbx.In("name", list.ToInterfaceSlice(names)...))
+	}
+
+	rows := []struct {
+		Bucket   int64  `db:"bucket"`
+		Name     string `db:"name"`
+		Total    int    `db:"total"`
+		Visitors int    `db:"visitors"`
+	}{}
+	if err := query.All(&rows); err != nil {
+		return nil, err
+	}
+
+	stats := make([]*Stat, len(rows))
+	for i, row := range rows {
+		bucket, _ := types.ParseDateTime(time.Unix(row.Bucket, 0).UTC())
+
+		stats[i] = &Stat{
+			Bucket:   bucket,
+			Name:     row.Name,
+			Total:    row.Total,
+			Visitors: row.Visitors,
+		}
+	}
+
+	return stats, nil
+}
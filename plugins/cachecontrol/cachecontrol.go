@@ -0,0 +1,254 @@
+// Package cachecontrol adds HTTP caching headers to the public
+// collection records list and view responses so that browsers and
+// CDNs could cache read-heavy public content.
+//
+// The headers are added only for guest requests to collections with
+// public (aka. empty string) ListRule and/or ViewRule and include:
+//
+//	Cache-Control: public, max-age=60, s-maxage=600, stale-while-revalidate=30
+//	ETag:          W/"..."
+//	Vary:          Authorization
+//
+// The ETag value changes on every create, update or delete of a record
+// from the related collection (or of any record if the request has an
+// "expand" query parameter) so that the caches could revalidate their
+// stored responses with "If-None-Match" (a "304 Not Modified" response
+// is returned if the stored response is still fresh).
+//
+// Example usage:
+//
+//	cachecontrol.MustRegister(app, cachecontrol.Config{
+//		Collections: map[string]cachecontrol.CollectionConfig{
+//			"articles": {MaxAge: time.Minute, SMaxAge: 10 * time.Minute},
+//		},
+//	})
+package cachecontrol
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Config defines the config options of the cachecontrol plugin.
+type Config struct {
+	// Collections is a map with the cache options of the collections
+	// whose public responses could be cached (the map key is the
+	// collection name or id).
+	Collections map[string]CollectionConfig
+}
+
+// CollectionConfig defines the cache options of a single collection.
+type CollectionConfig struct {
+	// MaxAge specifies how long the response could be
+	// considered fresh by the browsers ("max-age" directive).
+	MaxAge time.Duration
+
+	// SMaxAge specifies how long the response could be considered
+	// fresh by the shared caches and CDNs ("s-maxage" directive).
+	//
+	// If not set, the shared caches fallback to MaxAge.
+	SMaxAge time.Duration
+
+	// StaleWhileRevalidate specifies how long a stale response could
+	// be served while it is being revalidated in the background
+	// ("stale-while-revalidate" directive).
+	StaleWhileRevalidate time.Duration
+}
+
+// CacheControl returns the Cache-Control header value of the current config.
+func (c CollectionConfig) CacheControl() string {
+	directives := []string{"public", "max-age=" + seconds(c.MaxAge)}
+
+	if c.SMaxAge > 0 {
+		directives = append(directives, "s-maxage="+seconds(c.SMaxAge))
+	}
+
+	if c.StaleWhileRevalidate > 0 {
+		directives = append(directives, "stale-while-revalidate="+seconds(c.StaleWhileRevalidate))
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// MustRegister registers the cachecontrol plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the cachecontrol plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	if len(config.Collections) == 0 {
+		return errors.New("cachecontrol: at least one collection must be configured")
+	}
+
+	names := make([]string, 0, len(config.Collections))
+	for name, c := range config.Collections {
+		if name == "" {
+			return errors.New("cachecontrol: empty collection name")
+		}
+
+		if c.MaxAge < 0 || c.SMaxAge < 0 || c.StaleWhileRevalidate < 0 {
+			return errors.New("cachecontrol: " + name + " cache durations must be positive")
+		}
+
+		names = append(names, name)
+	}
+
+	now := time.Now().UnixNano()
+
+	p := &plugin{
+		app:        app,
+		config:     config,
+		versions:   map[string]int64{},
+		initial:    now,
+		lastChange: now,
+	}
+
+	p.app.OnRecordsListRequest(names...).BindFunc(func(e *core.RecordsListRequestEvent) error {
+		return p.handle(e.RequestEvent, e.Collection, e.Collection.ListRule, e.Next)
+	})
+
+	p.app.OnRecordViewRequest(names...).BindFunc(func(e *core.RecordRequestEvent) error {
+		return p.handle(e.RequestEvent, e.Collection, e.Collection.ViewRule, e.Next)
+	})
+
+	// track the records and collections changes
+	// ---
+	recordChange := func(e *core.RecordEvent) error {
+		p.touch(e.Record.Collection().Id)
+		return e.Next()
+	}
+	p.app.OnRecordAfterCreateSuccess().BindFunc(recordChange)
+	p.app.OnRecordAfterUpdateSuccess().BindFunc(recordChange)
+	p.app.OnRecordAfterDeleteSuccess().BindFunc(recordChange)
+
+	collectionChange := func(e *core.CollectionEvent) error {
+		p.touch(e.Collection.Id)
+		return e.Next()
+	}
+	p.app.OnCollectionAfterUpdateSuccess().BindFunc(collectionChange)
+	p.app.OnCollectionAfterDeleteSuccess().BindFunc(collectionChange)
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+
+	mu sync.RWMutex
+
+	// versions stores the last change unix nano time of each collection
+	versions map[string]int64
+
+	// initial is the plugin registration unix nano time and it is
+	// used as version for the collections without tracked changes
+	initial int64
+
+	// lastChange is the last change unix nano time of any collection
+	lastChange int64
+}
+
+func (p *plugin) handle(e *core.RequestEvent, collection *core.Collection, rule *string, next func() error) error {
+	config, ok := p.collectionConfig(collection)
+
+	// not public or not a guest request
+	if !ok || rule == nil || *rule != "" || e.Auth != nil {
+		return next()
+	}
+
+	etag := `W/"` + strconv.FormatInt(p.version(collection.Id, e.Request.URL.Query().Has("expand")), 36) + `"`
+
+	header := e.Response.Header()
+	header.Set("Cache-Control", config.CacheControl())
+	header.Set("ETag", etag)
+	header.Add("Vary", "Authorization")
+
+	if etagMatch(e.Request.Header.Get("If-None-Match"), etag) {
+		return e.NoContent(http.StatusNotModified)
+	}
+
+	err := next()
+	if err != nil {
+		// don't cache the error responses
+		header.Del("Cache-Control")
+		header.Del("ETag")
+	}
+
+	return err
+}
+
+func (p *plugin) collectionConfig(collection *core.Collection) (CollectionConfig, bool) {
+	config, ok := p.config.Collections[collection.Name]
+	if !ok {
+		config, ok = p.config.Collections[collection.Id]
+	}
+
+	return config, ok
+}
+
+// version returns the current cache version of the specified collection.
+//
+// If withRelations is set, the last change time of any collection is
+// returned because the response could include expanded relations.
+func (p *plugin) version(collectionId string, withRelations bool) int64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if withRelations {
+		return p.lastChange
+	}
+
+	if v, ok := p.versions[collectionId]; ok {
+		return v
+	}
+
+	return p.initial
+}
+
+// touch bumps the cache version of the specified collection.
+func (p *plugin) touch(collectionId string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// ensure that the version always changes
+	// (even in case of low resolution clock)
+	v := max(time.Now().UnixNano(), p.lastChange+1)
+
+	p.versions[collectionId] = v
+	p.lastChange = v
+}
+
+// etagMatch reports whether the If-None-Match header value matches
+// the specified etag (using weak comparison).
+func etagMatch(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, v := range strings.Split(ifNoneMatch, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
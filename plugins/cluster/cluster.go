@@ -0,0 +1,306 @@
+// Package cluster implements a DB lease based leader election for
+// multi-instance deployments sharing the same database (e.g. LiteFS or
+// other replicated setups) so that the singleton background tasks -
+// the cron jobs (including the logs cleanup) and the automigrate snapshots -
+// run only on a single node at a time.
+//
+// Each node periodically tries to acquire (or renew) a named lease stored
+// in the "_leases" table. The node holding a non-expired lease is the leader
+// and it is the only one with started app cron scheduler. The remaining nodes
+// are marked as followers (see [core.IsLeaderNode]) and take over automatically
+// once the leader lease expires.
+//
+// Example usage:
+//
+//	cluster.MustRegister(app, cluster.Config{
+//		NodeId:        os.Getenv("FLY_MACHINE_ID"),
+//		LeaseDuration: 30 * time.Second,
+//		RenewInterval: 10 * time.Second,
+//	})
+package cluster
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const (
+	// DefaultLeaseName is the default name of the leader lease.
+	DefaultLeaseName = "leader"
+
+	// DefaultLeaseDuration is the default duration after which
+	// a non-renewed leader lease expires.
+	DefaultLeaseDuration = 30 * time.Second
+
+	// DefaultRenewInterval is the default interval for acquiring
+	// or renewing the leader lease.
+	DefaultRenewInterval = 10 * time.Second
+
+	// LeasesTable is the name of the db table that stores the leases.
+	LeasesTable = "_leases"
+)
+
+// Config defines the config options of the cluster plugin.
+type Config struct {
+	// NodeId is the unique identifier of the current app instance
+	// (default to the hostname with a random suffix).
+	NodeId string
+
+	// LeaseName is the name of the leader lease (default to [DefaultLeaseName]).
+	//
+	// Could be used to run multiple independent app groups on the same db.
+	LeaseName string
+
+	// LeaseDuration is the duration after which a non-renewed
+	// leader lease expires (default to [DefaultLeaseDuration]).
+	LeaseDuration time.Duration
+
+	// RenewInterval is the interval for acquiring or renewing the
+	// leader lease (default to [DefaultRenewInterval]).
+	//
+	// It must be smaller than LeaseDuration.
+	RenewInterval time.Duration
+
+	// OnLeadershipChange is an optional callback that is invoked
+	// every time the current node becomes leader or follower.
+	OnLeadershipChange func(leader bool)
+}
+
+// MustRegister registers the cluster plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the cluster plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	if config.NodeId == "" {
+		hostname, _ := os.Hostname()
+		config.NodeId = hostname + "_" + security.RandomString(6)
+	}
+
+	if config.LeaseName == "" {
+		config.LeaseName = DefaultLeaseName
+	}
+
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = DefaultLeaseDuration
+	}
+
+	if config.RenewInterval <= 0 {
+		config.RenewInterval = DefaultRenewInterval
+	}
+
+	if config.RenewInterval >= config.LeaseDuration {
+		return errors.New("cluster: RenewInterval must be smaller than LeaseDuration")
+	}
+
+	p := &plugin{app: app, config: config}
+
+	app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		if err := p.ensureTable(); err != nil {
+			return err
+		}
+
+		// start as follower until the lease is acquired
+		core.SetLeaderNode(e.App, false)
+
+		return nil
+	})
+
+	// executed after the default cron start handler
+	app.OnServe().Bind(&hook.Handler[*core.ServeEvent]{
+		Func: func(e *core.ServeEvent) error {
+			p.start()
+
+			return e.Next()
+		},
+		Priority: 1000,
+	})
+
+	app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+		p.stop()
+
+		return e.Next()
+	})
+
+	return nil
+}
+
+type plugin struct {
+	app    core.App
+	config Config
+
+	mu     sync.Mutex
+	leader bool
+	done   chan struct{}
+}
+
+func (p *plugin) ensureTable() error {
+	_, err := p.app.NonconcurrentDB().NewQuery(`
+		CREATE TABLE IF NOT EXISTS {{` + LeasesTable + `}} (
+			[[name]]    TEXT PRIMARY KEY NOT NULL,
+			[[owner]]   TEXT NOT NULL,
+			[[expires]] INTEGER NOT NULL
+		)
+	`).Execute()
+
+	return err
+}
+
+// start syncs the node state with the leader lease and
+// starts the background lease renewal loop.
+func (p *plugin) start() {
+	p.mu.Lock()
+	if p.done != nil {
+		p.mu.Unlock()
+		return // already started
+	}
+	p.done = make(chan struct{})
+	done := p.done
+	p.mu.Unlock()
+
+	p.tick()
+
+	go func() {
+		ticker := time.NewTicker(p.config.RenewInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				p.tick()
+			}
+		}
+	}()
+}
+
+// stop stops the lease renewal loop and releases the lease
+// (if owned) so that another node could take over immediately.
+func (p *plugin) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done != nil {
+		close(p.done)
+		p.done = nil
+	}
+
+	if p.leader {
+		if err := p.release(); err != nil {
+			p.app.Logger().Warn("Failed to release the cluster leader lease", slog.String("error", err.Error()))
+		}
+		p.setLeader(false)
+	}
+}
+
+// tick tries to acquire or renew the leader lease and
+// updates the node state accordingly.
+func (p *plugin) tick() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done == nil {
+		return // stopped
+	}
+
+	leader, err := p.acquire()
+	if err != nil {
+		p.app.Logger().Warn(
+			"Failed to acquire the cluster leader lease",
+			slog.String("nodeId", p.config.NodeId),
+			slog.String("error", err.Error()),
+		)
+		// the lease could have already expired so it is safer to step down
+		leader = false
+	}
+
+	p.setLeader(leader)
+}
+
+// setLeader updates the current node leadership state
+// and starts or stops the app cron scheduler.
+func (p *plugin) setLeader(leader bool) {
+	core.SetLeaderNode(p.app, leader)
+
+	if !leader {
+		// always stop since the cron could have been started by the default app serve handler
+		p.app.Cron().Stop()
+	}
+
+	if p.leader == leader {
+		return
+	}
+
+	p.leader = leader
+
+	if leader {
+		p.app.Cron().Start()
+	}
+
+	p.app.Logger().Info(
+		"Cluster leadership changed",
+		slog.String("nodeId", p.config.NodeId),
+		slog.Bool("leader", leader),
+	)
+
+	if p.config.OnLeadershipChange != nil {
+		p.config.OnLeadershipChange(leader)
+	}
+}
+
+// acquire atomically acquires or renews the leader lease and
+// reports whether it is owned by the current node.
+func (p *plugin) acquire() (bool, error) {
+	now := time.Now()
+
+	result, err := p.app.NonconcurrentDB().NewQuery(`
+		INSERT INTO {{` + LeasesTable + `}} ([[name]], [[owner]], [[expires]])
+		VALUES ({:name}, {:owner}, {:expires})
+		ON CONFLICT([[name]]) DO UPDATE SET
+			[[owner]]   = excluded.[[owner]],
+			[[expires]] = excluded.[[expires]]
+		WHERE {{` + LeasesTable + `}}.[[owner]] = excluded.[[owner]] OR {{` + LeasesTable + `}}.[[expires]] < {:now}
+	`).Bind(dbx.Params{
+		"name":    p.config.LeaseName,
+		"owner":   p.config.NodeId,
+		"expires": now.Add(p.config.LeaseDuration).UnixMilli(),
+		"now":     now.UnixMilli(),
+	}).Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// release deletes the leader lease if it is owned by the current node.
+func (p *plugin) release() error {
+	_, err := p.app.NonconcurrentDB().Delete(LeasesTable, dbx.HashExp{
+		"name":  p.config.LeaseName,
+		"owner": p.config.NodeId,
+	}).Execute()
+
+	return err
+}
@@ -0,0 +1,151 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRegisterErrors(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	err := Register(app, Config{LeaseDuration: time.Second, RenewInterval: time.Second})
+	if err == nil {
+		t.Fatal("Expected RenewInterval error, got nil")
+	}
+
+	err = Register(app, Config{})
+	if err != nil {
+		t.Fatalf("Expected nil error with the default config, got %v", err)
+	}
+}
+
+// newTestNodes creates 2 plugin instances ("nodes") with separate
+// app instances sharing the same data dir.
+func newTestNodes(t *testing.T, leaseDuration time.Duration) (*plugin, *plugin) {
+	app1, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(app1.Cleanup)
+
+	app2 := core.NewBaseApp(core.BaseAppConfig{DataDir: app1.DataDir()})
+	if err := app2.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { app2.ResetBootstrapState() })
+
+	config := Config{
+		LeaseName:     DefaultLeaseName,
+		LeaseDuration: leaseDuration,
+		RenewInterval: leaseDuration / 2,
+	}
+
+	c1 := config
+	c1.NodeId = "node1"
+	p1 := &plugin{app: app1, config: c1}
+
+	c2 := config
+	c2.NodeId = "node2"
+	p2 := &plugin{app: app2, config: c2}
+
+	if err := p1.ensureTable(); err != nil {
+		t.Fatal(err)
+	}
+
+	// should be no-op
+	if err := p2.ensureTable(); err != nil {
+		t.Fatal(err)
+	}
+
+	return p1, p2
+}
+
+func TestAcquire(t *testing.T) {
+	t.Parallel()
+
+	p1, p2 := newTestNodes(t, 200*time.Millisecond)
+
+	scenarios := []struct {
+		name     string
+		node     *plugin
+		sleep    time.Duration
+		expected bool
+	}{
+		{"node1 initial acquire", p1, 0, true},
+		{"node2 acquire while node1 lease is active", p2, 0, false},
+		{"node1 renew", p1, 0, true},
+		{"node2 acquire after node1 lease expiration", p2, 300 * time.Millisecond, true},
+		{"node1 acquire while node2 lease is active", p1, 0, false},
+	}
+
+	for _, s := range scenarios {
+		time.Sleep(s.sleep)
+
+		result, err := s.node.acquire()
+		if err != nil {
+			t.Fatalf("[%s] %v", s.name, err)
+		}
+
+		if result != s.expected {
+			t.Fatalf("[%s] Expected %v, got %v", s.name, s.expected, result)
+		}
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	t.Parallel()
+
+	p1, p2 := newTestNodes(t, time.Minute)
+
+	changes := []bool{}
+	p2.config.OnLeadershipChange = func(leader bool) {
+		changes = append(changes, leader)
+	}
+
+	p1.start()
+	defer p1.stop()
+
+	p2.start()
+	defer p2.stop()
+
+	if !core.IsLeaderNode(p1.app) {
+		t.Fatal("Expected node1 to be leader")
+	}
+
+	if core.IsLeaderNode(p2.app) {
+		t.Fatal("Expected node2 to be follower")
+	}
+
+	if p2.app.Cron().HasStarted() {
+		t.Fatal("Expected node2 cron to be stopped")
+	}
+
+	// stopping node1 should release the lease
+	p1.stop()
+
+	if core.IsLeaderNode(p1.app) {
+		t.Fatal("Expected node1 to be follower after stop")
+	}
+
+	p2.tick()
+
+	if !core.IsLeaderNode(p2.app) {
+		t.Fatal("Expected node2 to take over the leadership")
+	}
+
+	if len(changes) != 1 || !changes[0] {
+		t.Fatalf("Expected a single leadership change callback call, got %v", changes)
+	}
+
+	// a stopped node shouldn't try to acquire the lease
+	p1.tick()
+	if p1.leader {
+		t.Fatal("Expected the stopped node1 to remain follower")
+	}
+}
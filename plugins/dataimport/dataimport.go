@@ -0,0 +1,387 @@
+// Package dataimport implements an "import" command for migrating
+// existing Firestore and Supabase data into PocketBase collections.
+//
+// The target collections must already exist. Each source document/row
+// is imported as a record of its mapped collection by matching the
+// source and collection field names (file fields are not imported).
+//
+// The original ids are preserved when they are valid PocketBase ids.
+// Otherwise a new deterministic id is derived from the original one so
+// that the relation field values could be resolved without a lookup.
+//
+// The auth users are imported in the configured auth collection:
+//   - Supabase users keep their bcrypt password hashes.
+//   - Firebase users are imported with random passwords because their
+//     scrypt password hashes are not compatible, aka. they will have
+//     to reset their password.
+//
+// Example usage:
+//
+//	dataimport.MustRegister(app, app.RootCmd, dataimport.Config{
+//		Collections: map[string]string{
+//			"posts":          "posts",
+//			"posts/comments": "comments",
+//		},
+//	})
+//
+// Once registered, the data could be imported with:
+//
+//	./pocketbase import firestore export.json --users=auth_export.json
+//	./pocketbase import supabase --dsn="postgres://..."
+package dataimport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cobra"
+)
+
+// Config defines the config options of the dataimport plugin.
+type Config struct {
+	// Collections is an optional map with the source collections/tables
+	// to import, where the key is the source name and the value is the
+	// name of the target PocketBase collection.
+	//
+	// Firestore subcollections are specified with their slash separated
+	// path without the document ids (e.g. "posts/comments").
+	//
+	// If not set, all source collections/tables are imported in
+	// collections with the same name (missing collections are skipped).
+	Collections map[string]string
+
+	// UsersCollection is the name of the auth collection where to
+	// import the Firebase/Supabase auth users (default to "users").
+	UsersCollection string
+
+	// ParentField is the name of the target collection field where to
+	// store the parent document id of the Firestore subcollection
+	// documents (default to "parent").
+	//
+	// The parent id is stored only if the target collection has such field.
+	ParentField string
+
+	// SupabaseDriver is the name of the registered [database/sql]
+	// Postgres driver to use for the Supabase connection (default to "pgx").
+	//
+	// Note that the driver is not bundled with PocketBase and it must
+	// be imported separately (e.g. _ "github.com/jackc/pgx/v5/stdlib").
+	SupabaseDriver string
+
+	// SupabaseSchema is the Supabase database schema with the tables
+	// to import (default to "public").
+	SupabaseSchema string
+}
+
+// MustRegister registers the dataimport plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, rootCmd *cobra.Command, config Config) {
+	if err := Register(app, rootCmd, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the dataimport plugin to the provided app instance.
+func Register(app core.App, rootCmd *cobra.Command, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if p.config.UsersCollection == "" {
+		p.config.UsersCollection = "users"
+	}
+
+	if p.config.ParentField == "" {
+		p.config.ParentField = "parent"
+	}
+
+	if p.config.SupabaseDriver == "" {
+		p.config.SupabaseDriver = "pgx"
+	}
+
+	if p.config.SupabaseSchema == "" {
+		p.config.SupabaseSchema = "public"
+	}
+
+	rootCmd.AddCommand(p.importCmd())
+
+	return nil
+}
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) importCmd() *cobra.Command {
+	command := &cobra.Command{
+		Use:       "import",
+		Short:     "Imports Firestore and Supabase data into the existing collections",
+		ValidArgs: []string{"firestore", "supabase"},
+		Run: func(command *cobra.Command, args []string) {
+			command.Help()
+		},
+	}
+
+	command.AddCommand(p.firestoreCmd())
+	command.AddCommand(p.supabaseCmd())
+
+	return command
+}
+
+// errDryRun is used to rollback the import transaction in dry-run mode.
+var errDryRun = errors.New("dry run")
+
+// runImport executes fn in a single transaction and prints the import summary.
+//
+// In dry-run mode the transaction is always rolled back.
+func (p *plugin) runImport(out io.Writer, dryRun bool, fn func(im *importer) error) error {
+	im := &importer{config: p.config}
+
+	err := p.app.RunInTransaction(func(txApp core.App) error {
+		im.app = txApp
+
+		if err := fn(im); err != nil {
+			return err
+		}
+
+		if err := im.resolveRelations(); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return fmt.Errorf("Failed to import the data: %w.", err)
+	}
+
+	for _, w := range im.warnings {
+		color.Yellow(w)
+	}
+
+	for _, line := range im.imported {
+		fmt.Fprintln(out, line)
+	}
+
+	if im.resetPasswords > 0 {
+		color.Yellow("%d auth user(s) were imported with a random password and will have to reset it.", im.resetPasswords)
+	}
+
+	if dryRun {
+		color.Yellow("Dry run - no changes were saved.")
+	} else {
+		color.Green("Successfully imported the data.")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type pendingRelations struct {
+	record   *core.Record
+	sourceId string
+	values   map[string]any
+}
+
+// importer stores the state of a single import run.
+type importer struct {
+	app    core.App
+	config Config
+
+	// records with relation values that are set after all
+	// records are imported because they may reference each other
+	pending []*pendingRelations
+
+	imported       []string
+	warnings       []string
+	resetPasswords int
+}
+
+func (im *importer) warn(format string, args ...any) {
+	im.warnings = append(im.warnings, fmt.Sprintf(format, args...))
+}
+
+func (im *importer) report(source string, target string, total int) {
+	im.imported = append(im.imported, fmt.Sprintf("%s -> %s: %d record(s)", source, target, total))
+}
+
+// targetCollection returns the mapped target collection of the
+// specified source or nil if it shouldn't be imported.
+func (im *importer) targetCollection(source string) *core.Collection {
+	name := strings.ReplaceAll(source, "/", "_")
+
+	if len(im.config.Collections) > 0 {
+		mapped, ok := im.config.Collections[source]
+		if !ok || mapped == "" {
+			return nil
+		}
+		name = mapped
+	}
+
+	collection, err := im.app.FindCollectionByNameOrId(name)
+	if err != nil {
+		im.warn("Skipping %q because collection %q is missing.", source, name)
+		return nil
+	}
+
+	return collection
+}
+
+// usersCollection returns the auth collection for the imported users.
+func (im *importer) usersCollection() (*core.Collection, error) {
+	collection, err := im.app.FindCollectionByNameOrId(im.config.UsersCollection)
+	if err != nil {
+		return nil, fmt.Errorf("missing users collection %q", im.config.UsersCollection)
+	}
+
+	if !collection.IsAuth() {
+		return nil, fmt.Errorf("%q is not an auth collection", im.config.UsersCollection)
+	}
+
+	return collection, nil
+}
+
+// save creates or updates the target collection record with the
+// specified source id and data.
+//
+// The relation field values are deferred to [importer.resolveRelations].
+func (im *importer) save(collection *core.Collection, sourceId string, data map[string]any, optExtend ...func(record *core.Record)) error {
+	id := importedId(sourceId)
+
+	record, err := im.app.FindRecordById(collection, id)
+	if err != nil {
+		record = core.NewRecord(collection)
+		record.Id = id
+		if collection.IsAuth() {
+			record.RefreshTokenKey()
+		}
+	}
+
+	relations := map[string]any{}
+
+	for name, value := range data {
+		field := collection.Fields.GetByName(name)
+		if field == nil || isSkippedField(collection, field) {
+			continue
+		}
+
+		if _, ok := field.(*core.RelationField); ok {
+			relations[name] = relationIds(value)
+			continue
+		}
+
+		record.Set(name, value)
+	}
+
+	for _, fn := range optExtend {
+		fn(record)
+	}
+
+	// the record is fully validated after its relations are set
+	if len(relations) > 0 {
+		err = im.app.SaveNoValidate(record)
+		im.pending = append(im.pending, &pendingRelations{record: record, sourceId: sourceId, values: relations})
+	} else {
+		err = im.app.Save(record)
+	}
+	if err != nil {
+		return fmt.Errorf("%s/%s: %w", collection.Name, sourceId, err)
+	}
+
+	return nil
+}
+
+// resolveRelations sets the deferred relation field values.
+func (im *importer) resolveRelations() error {
+	for _, p := range im.pending {
+		for name, value := range p.values {
+			p.record.Set(name, value)
+		}
+
+		if err := im.app.Save(p.record); err != nil {
+			return fmt.Errorf("%s/%s: %w", p.record.Collection().Name, p.sourceId, err)
+		}
+	}
+
+	im.pending = nil
+
+	return nil
+}
+
+// importedId returns the PocketBase record id of the specified source id.
+//
+// Valid ids are returned as they are, otherwise a deterministic id
+// is derived from the source id hash.
+func importedId(sourceId string) string {
+	if isValidId(sourceId) {
+		return sourceId
+	}
+
+	return security.SHA256(sourceId)[:core.DefaultIdLength]
+}
+
+func isValidId(id string) bool {
+	if len(id) != core.DefaultIdLength {
+		return false
+	}
+
+	for _, c := range id {
+		if !strings.ContainsRune(core.DefaultIdAlphabet, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isSkippedField(collection *core.Collection, field core.Field) bool {
+	if _, ok := field.(*core.FileField); ok {
+		return true
+	}
+
+	switch field.GetName() {
+	case core.FieldNameId:
+		return true
+	case core.FieldNamePassword, core.FieldNameTokenKey, core.FieldNameEmail, core.FieldNameVerified:
+		return collection.IsAuth()
+	}
+
+	return false
+}
+
+// relationIds converts the source relation value id(s) to imported ids.
+func relationIds(value any) any {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return v
+		}
+		return importedId(v)
+	case []any:
+		ids := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := relationIds(item).(string); ok && str != "" {
+				ids = append(ids, str)
+			}
+		}
+		return ids
+	case []string:
+		ids := make([]string, 0, len(v))
+		for _, item := range v {
+			if item != "" {
+				ids = append(ids, importedId(item))
+			}
+		}
+		return ids
+	}
+
+	return value
+}
@@ -0,0 +1,88 @@
+package dataimport_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/dataimport"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/spf13/cobra"
+)
+
+// stubCollections creates the import target collections:
+//   - members (auth with "name" text field)
+//   - posts (title, published, meta and author relation to members)
+//   - comments (body and parent relation to posts)
+func stubCollections(t testing.TB, app core.App) {
+	members := core.NewAuthCollection("members")
+	members.Fields.Add(&core.TextField{Name: "name"})
+	if err := app.Save(members); err != nil {
+		t.Fatal(err)
+	}
+
+	posts := core.NewBaseCollection("posts")
+	posts.Fields.Add(
+		&core.TextField{Name: "title", Required: true},
+		&core.DateField{Name: "published"},
+		&core.JSONField{Name: "meta"},
+		&core.RelationField{Name: "author", CollectionId: members.Id, MaxSelect: 1},
+	)
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	comments := core.NewBaseCollection("comments")
+	comments.Fields.Add(
+		&core.TextField{Name: "body"},
+		&core.RelationField{Name: "parent", CollectionId: posts.Id, MaxSelect: 1, Required: true},
+		&core.RelationField{Name: "likes", CollectionId: members.Id, MaxSelect: 99},
+	)
+	if err := app.Save(comments); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func runImportCommand(t testing.TB, app core.App, config dataimport.Config, args ...string) (string, error) {
+	rootCmd := &cobra.Command{}
+
+	if err := dataimport.Register(app, rootCmd, config); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs(append([]string{"import"}, args...))
+
+	err := rootCmd.Execute()
+
+	return out.String(), err
+}
+
+func TestRegisterDefaults(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	rootCmd := &cobra.Command{}
+
+	dataimport.MustRegister(app, rootCmd, dataimport.Config{})
+
+	command, _, err := rootCmd.Find([]string{"import", "firestore"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if command.Name() != "firestore" {
+		t.Fatalf("Expected the firestore subcommand, got %q", command.Name())
+	}
+
+	command, _, err = rootCmd.Find([]string{"import", "supabase"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if command.Name() != "supabase" {
+		t.Fatalf("Expected the supabase subcommand, got %q", command.Name())
+	}
+}
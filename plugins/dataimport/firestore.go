@@ -0,0 +1,303 @@
+package dataimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+)
+
+// firestoreCollectionsKey is the export document key with the nested subcollections.
+const firestoreCollectionsKey = "__collections__"
+
+func (p *plugin) firestoreCmd() *cobra.Command {
+	var usersFile string
+	var dryRun bool
+
+	command := &cobra.Command{
+		Use:          "firestore [export.json]",
+		Example:      "import firestore export.json --users=auth_export.json",
+		Short:        "Imports a Firestore JSON export and optionally the Firebase auth users",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			collections, err := readFirestoreExport(args[0])
+			if err != nil {
+				return fmt.Errorf("Failed to read the Firestore export: %w.", err)
+			}
+
+			var users []*firebaseUser
+			if usersFile != "" {
+				users, err = readFirebaseUsers(usersFile)
+				if err != nil {
+					return fmt.Errorf("Failed to read the Firebase auth users export: %w.", err)
+				}
+			}
+
+			return p.runImport(command.OutOrStdout(), dryRun, func(im *importer) error {
+				if usersFile != "" {
+					if err := im.importFirebaseUsers(users); err != nil {
+						return err
+					}
+				}
+
+				return im.importFirestoreCollections("", "", collections)
+			})
+		},
+	}
+
+	command.Flags().StringVar(
+		&usersFile,
+		"users",
+		"",
+		"the Firebase auth users JSON export file (firebase auth:export)",
+	)
+
+	command.Flags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"run the import without saving the changes",
+	)
+
+	return command
+}
+
+// readFirestoreExport reads a Firestore JSON export file in the format
+// of the firestore-export tool, aka. an object with collections,
+// where each collection is an object with documents keyed by their id.
+//
+// The collections could be optionally wrapped in a "__collections__" object.
+func readFirestoreExport(file string) (map[string]any, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]any{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	if nested, ok := data[firestoreCollectionsKey].(map[string]any); ok {
+		return nested, nil
+	}
+
+	return data, nil
+}
+
+// importFirestoreCollections imports the documents of the specified
+// collections and recursively their subcollections.
+func (im *importer) importFirestoreCollections(parentPath string, parentId string, collections map[string]any) error {
+	for _, name := range sortedKeys(collections) {
+		docs, ok := collections[name].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		path := name
+		if parentPath != "" {
+			path = parentPath + "/" + name
+		}
+
+		target := im.targetCollection(path)
+
+		var total int
+
+		for _, docId := range sortedKeys(docs) {
+			doc, ok := docs[docId].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			if sub, ok := doc[firestoreCollectionsKey].(map[string]any); ok {
+				if err := im.importFirestoreCollections(path, docId, sub); err != nil {
+					return err
+				}
+			}
+
+			if target == nil {
+				continue
+			}
+
+			data := make(map[string]any, len(doc))
+			for k, v := range doc {
+				if k != firestoreCollectionsKey {
+					data[k] = firestoreValue(v)
+				}
+			}
+
+			if parentId != "" {
+				data[im.config.ParentField] = importedId(parentId)
+			}
+
+			if err := im.save(target, docId, data); err != nil {
+				return err
+			}
+
+			total++
+		}
+
+		if target != nil {
+			im.report(path, target.Name, total)
+		}
+	}
+
+	return nil
+}
+
+// firestoreValue normalizes the special Firestore export values.
+//
+// Timestamps are converted to [types.DateTime], document references
+// to the imported id of the referenced document and geopoints to
+// a {lat, lon} object.
+func firestoreValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if dataType, ok := v["__datatype__"].(string); ok {
+			switch dataType {
+			case "timestamp":
+				return firestoreTimestamp(v["value"])
+			case "documentReference":
+				ref := cast.ToString(v["value"])
+				return importedId(ref[strings.LastIndex(ref, "/")+1:])
+			case "geopoint":
+				point, _ := v["value"].(map[string]any)
+				return map[string]any{
+					"lat": cast.ToFloat64(point["_latitude"]),
+					"lon": cast.ToFloat64(point["_longitude"]),
+				}
+			default:
+				return firestoreValue(v["value"])
+			}
+		}
+
+		// serialized Admin SDK timestamp
+		if _, ok := v["_seconds"]; ok && len(v) == 2 {
+			if _, ok := v["_nanoseconds"]; ok {
+				return firestoreTimestamp(v)
+			}
+		}
+
+		result := make(map[string]any, len(v))
+		for k, item := range v {
+			result[k] = firestoreValue(item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = firestoreValue(item)
+		}
+		return result
+	}
+
+	return value
+}
+
+func firestoreTimestamp(raw any) types.DateTime {
+	ts, _ := raw.(map[string]any)
+
+	dt, _ := types.ParseDateTime(time.Unix(
+		cast.ToInt64(ts["_seconds"]),
+		cast.ToInt64(ts["_nanoseconds"]),
+	))
+
+	return dt
+}
+
+// -------------------------------------------------------------------
+
+// firebaseUser defines a single user of the "firebase auth:export" JSON file.
+type firebaseUser struct {
+	LocalId          string `json:"localId"`
+	Email            string `json:"email"`
+	EmailVerified    bool   `json:"emailVerified"`
+	DisplayName      string `json:"displayName"`
+	PhoneNumber      string `json:"phoneNumber"`
+	CustomAttributes string `json:"customAttributes"`
+}
+
+func readFirebaseUsers(file string) ([]*firebaseUser, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	export := struct {
+		Users []*firebaseUser `json:"users"`
+	}{}
+	if err := json.Unmarshal(raw, &export); err != nil {
+		return nil, err
+	}
+
+	return export.Users, nil
+}
+
+// importFirebaseUsers imports the Firebase auth users with random
+// passwords because their scrypt hashes are not supported.
+//
+// The custom claims, the display name (as "name") and the phone number
+// (as "phone") are stored in the auth collection fields with the same name (if any).
+func (im *importer) importFirebaseUsers(users []*firebaseUser) error {
+	collection, err := im.usersCollection()
+	if err != nil {
+		return err
+	}
+
+	var total int
+
+	for _, u := range users {
+		if u.Email == "" {
+			im.warn("Skipping Firebase user %q because it doesn't have an email.", u.LocalId)
+			continue
+		}
+
+		data := map[string]any{}
+		if u.CustomAttributes != "" {
+			if err := json.Unmarshal([]byte(u.CustomAttributes), &data); err != nil {
+				im.warn("Ignoring the invalid custom claims of Firebase user %q.", u.LocalId)
+			}
+		}
+		data["name"] = u.DisplayName
+		data["phone"] = u.PhoneNumber
+
+		err := im.save(collection, u.LocalId, data, func(record *core.Record) {
+			record.SetEmail(u.Email)
+			record.SetVerified(u.EmailVerified)
+
+			if record.IsNew() {
+				record.SetPassword(security.RandomString(30))
+				im.resetPasswords++
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		total++
+	}
+
+	im.report("firebase auth users", collection.Name, total)
+
+	return nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
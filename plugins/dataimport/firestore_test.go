@@ -0,0 +1,189 @@
+package dataimport_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/dataimport"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const firestoreExport = `{
+	"__collections__": {
+		"posts": {
+			"Fk3zWcYVdPg8GkRcBxUr": {
+				"title": "Hello",
+				"published": {"__datatype__": "timestamp", "value": {"_seconds": 1700000000, "_nanoseconds": 0}},
+				"author": {"__datatype__": "documentReference", "value": "users/AbCdEfGhIjKlMnOp1234"},
+				"meta": {"tags": ["a", "b"], "location": {"__datatype__": "geopoint", "value": {"_latitude": 1.5, "_longitude": 2.5}}},
+				"unknown": "ignored",
+				"__collections__": {
+					"comments": {
+						"c1": {
+							"body": "First!",
+							"likes": ["AbCdEfGhIjKlMnOp1234"]
+						}
+					}
+				}
+			}
+		},
+		"archived": {
+			"x": {"title": "not mapped"}
+		}
+	}
+}`
+
+const firebaseUsersExport = `{
+	"users": [
+		{
+			"localId": "AbCdEfGhIjKlMnOp1234",
+			"email": "firebase@example.com",
+			"emailVerified": true,
+			"displayName": "Firebase User",
+			"passwordHash": "irrelevant",
+			"salt": "irrelevant"
+		},
+		{
+			"localId": "phoneonly",
+			"phoneNumber": "+123456789"
+		}
+	]
+}`
+
+func TestFirestoreImport(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	exportFile := filepath.Join(dir, "export.json")
+	if err := os.WriteFile(exportFile, []byte(firestoreExport), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	usersFile := filepath.Join(dir, "users.json")
+	if err := os.WriteFile(usersFile, []byte(firebaseUsersExport), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := dataimport.Config{
+		UsersCollection: "members",
+		Collections: map[string]string{
+			"posts":          "posts",
+			"posts/comments": "comments",
+		},
+	}
+
+	memberId := security.SHA256("AbCdEfGhIjKlMnOp1234")[:core.DefaultIdLength]
+	postId := security.SHA256("Fk3zWcYVdPg8GkRcBxUr")[:core.DefaultIdLength]
+	commentId := security.SHA256("c1")[:core.DefaultIdLength]
+
+	t.Run("dry-run", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		stubCollections(t, app)
+
+		out, err := runImportCommand(t, app, config, "firestore", exportFile, "--users", usersFile, "--dry-run")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(out, "posts -> posts: 1 record(s)") {
+			t.Fatalf("Expected the posts summary, got\n%s", out)
+		}
+
+		if _, err := app.FindRecordById("posts", postId); err == nil {
+			t.Fatal("Expected the post to not be saved")
+		}
+	})
+
+	t.Run("import", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		stubCollections(t, app)
+
+		out, err := runImportCommand(t, app, config, "firestore", exportFile, "--users", usersFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, line := range []string{
+			"firebase auth users -> members: 1 record(s)",
+			"posts -> posts: 1 record(s)",
+			"posts/comments -> comments: 1 record(s)",
+		} {
+			if !strings.Contains(out, line) {
+				t.Fatalf("Expected %q in the summary, got\n%s", line, out)
+			}
+		}
+
+		member, err := app.FindRecordById("members", memberId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if member.Email() != "firebase@example.com" || !member.Verified() || member.GetString("name") != "Firebase User" {
+			t.Fatalf("Unexpected member data %v", member.PublicExport())
+		}
+		if member.GetString(core.FieldNamePassword+":hash") == "" {
+			t.Fatal("Expected the member to have a random password")
+		}
+
+		post, err := app.FindRecordById("posts", postId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.GetString("title") != "Hello" {
+			t.Fatalf("Expected title %q, got %q", "Hello", post.GetString("title"))
+		}
+		if published := post.GetDateTime("published").String(); published != "2023-11-14 22:13:20.000Z" {
+			t.Fatalf("Expected published date %q, got %q", "2023-11-14 22:13:20.000Z", published)
+		}
+		if post.GetString("author") != memberId {
+			t.Fatalf("Expected author %q, got %q", memberId, post.GetString("author"))
+		}
+		if meta := post.GetString("meta"); meta != `{"location":{"lat":1.5,"lon":2.5},"tags":["a","b"]}` {
+			t.Fatalf("Unexpected meta %s", meta)
+		}
+
+		comment, err := app.FindRecordById("comments", commentId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if comment.GetString("parent") != postId {
+			t.Fatalf("Expected parent %q, got %q", postId, comment.GetString("parent"))
+		}
+		if likes := comment.GetStringSlice("likes"); len(likes) != 1 || likes[0] != memberId {
+			t.Fatalf("Unexpected likes %v", likes)
+		}
+	})
+}
+
+func TestFirestoreImportInvalidRelation(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubCollections(t, app)
+
+	exportFile := filepath.Join(t.TempDir(), "export.json")
+	err := os.WriteFile(exportFile, []byte(`{"posts":{"p1":{"title":"test","author":"missing"}}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = runImportCommand(t, app, dataimport.Config{UsersCollection: "members"}, "firestore", exportFile)
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	total, _ := app.CountRecords("posts")
+	if total != 0 {
+		t.Fatalf("Expected the import to be rolled back, found %d posts", total)
+	}
+}
@@ -0,0 +1,156 @@
+package dataimport_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/dataimport"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"golang.org/x/crypto/bcrypt"
+	"modernc.org/sqlite"
+)
+
+// supabaseTestDriver is a SQLite driver that emulates the Supabase
+// "public" and "auth" Postgres schemas with attached databases
+// located in the same directory as the connection dsn.
+const supabaseTestDriver = "dataimport_supabase_test"
+
+func init() {
+	driver := &sqlite.Driver{}
+
+	driver.RegisterConnectionHook(func(conn sqlite.ExecQuerierContext, dsn string) error {
+		dir := filepath.Dir(dsn)
+
+		for _, schema := range []string{"public", "auth"} {
+			_, err := conn.ExecContext(context.Background(), "ATTACH DATABASE '"+filepath.Join(dir, schema+".db")+"' AS "+schema, nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	sql.Register(supabaseTestDriver, driver)
+}
+
+func stubSupabaseDB(t testing.TB) string {
+	dsn := filepath.Join(t.TempDir(), "main.db")
+
+	db, err := sql.Open(supabaseTestDriver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("1234567890"), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stmts := []string{
+		`CREATE TABLE auth.users (id TEXT PRIMARY KEY, email TEXT, encrypted_password TEXT, email_confirmed_at TEXT, raw_user_meta_data TEXT)`,
+		`INSERT INTO auth.users VALUES ('8a1e6f0c-6a2b-4c44-9d0b-9d4c3a3b2f11', 'supabase@example.com', '` + string(hash) + `', '2024-01-01 00:00:00', '{"name":"Supabase User"}')`,
+		`INSERT INTO auth.users VALUES ('0f1b2d63-3d2a-4b6c-8e5f-1a2b3c4d5e6f', 'oauth@example.com', '', NULL, NULL)`,
+		`INSERT INTO auth.users VALUES ('5b3c0e8e-1111-4c44-9d0b-9d4c3a3b2f11', NULL, NULL, NULL, NULL)`,
+		`CREATE TABLE public.posts (id TEXT PRIMARY KEY, title TEXT, published TEXT, meta TEXT, author TEXT, extra TEXT)`,
+		`INSERT INTO public.posts VALUES ('b7f3e2a1-0000-4000-8000-000000000001', 'Hello', '2024-02-03 04:05:06', '{"a":1}', '8a1e6f0c-6a2b-4c44-9d0b-9d4c3a3b2f11', 'ignored')`,
+		`INSERT INTO public.posts VALUES ('b7f3e2a1-0000-4000-8000-000000000002', 'World', NULL, NULL, NULL, NULL)`,
+		`CREATE TABLE public.logs (id TEXT PRIMARY KEY, message TEXT)`,
+	}
+
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	return dsn
+}
+
+func TestSupabaseImport(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubCollections(t, app)
+
+	dsn := stubSupabaseDB(t)
+
+	out, err := runImportCommand(t, app, dataimport.Config{
+		UsersCollection: "members",
+		SupabaseDriver:  supabaseTestDriver,
+		Collections:     map[string]string{"posts": "posts"},
+	}, "supabase", "--dsn", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"supabase auth users -> members: 2 record(s)",
+		"posts -> posts: 2 record(s)",
+	} {
+		if !strings.Contains(out, line) {
+			t.Fatalf("Expected %q in the summary, got\n%s", line, out)
+		}
+	}
+
+	memberId := security.SHA256("8a1e6f0c-6a2b-4c44-9d0b-9d4c3a3b2f11")[:core.DefaultIdLength]
+
+	member, err := app.FindRecordById("members", memberId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.Email() != "supabase@example.com" || !member.Verified() || member.GetString("name") != "Supabase User" {
+		t.Fatalf("Unexpected member data %v", member.PublicExport())
+	}
+	if !member.ValidatePassword("1234567890") {
+		t.Fatal("Expected the Supabase password hash to be preserved")
+	}
+
+	oauthMember, err := app.FindAuthRecordByEmail("members", "oauth@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oauthMember.Verified() {
+		t.Fatal("Expected the unconfirmed member to be unverified")
+	}
+	if oauthMember.GetString(core.FieldNamePassword+":hash") == "" {
+		t.Fatal("Expected the member without password to have a random one")
+	}
+
+	post, err := app.FindRecordById("posts", security.SHA256("b7f3e2a1-0000-4000-8000-000000000001")[:core.DefaultIdLength])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.GetString("title") != "Hello" {
+		t.Fatalf("Expected title %q, got %q", "Hello", post.GetString("title"))
+	}
+	if published := post.GetDateTime("published").String(); published != "2024-02-03 04:05:06.000Z" {
+		t.Fatalf("Expected published date %q, got %q", "2024-02-03 04:05:06.000Z", published)
+	}
+	if meta := post.GetString("meta"); meta != `{"a":1}` {
+		t.Fatalf("Unexpected meta %s", meta)
+	}
+	if post.GetString("author") != memberId {
+		t.Fatalf("Expected author %q, got %q", memberId, post.GetString("author"))
+	}
+}
+
+func TestSupabaseImportMissingDSN(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	_, err := runImportCommand(t, app, dataimport.Config{}, "supabase")
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
@@ -0,0 +1,347 @@
+// Package domains implements a host based routing layer for serving
+// multiple apps from a single PocketBase instance.
+//
+// The domains are managed by the superusers in the "_domains" system
+// collection and each domain record could specify:
+//
+//   - publicDir - a directory (relative to the Config.PublicDirsRoot)
+//     with the static files that will be served for the domain
+//     (the explicitly registered routes like /api/* and /_/* are not affected)
+//   - namespace - a collection names prefix that is automatically applied
+//     to the collection records and files API requests of the domain
+//     (e.g. for namespace "acme" the "/api/collections/posts/records"
+//     request is handled as "/api/collections/acme_posts/records")
+//   - tenant - a tenant id that could be used with the tenancy plugin
+//     (see [TenantResolver])
+//
+// The domain "host" field could be an exact host name (e.g. "acme.com")
+// or a wildcard subdomain (e.g. "*.acme.com").
+//
+// Example usage:
+//
+//	domains.MustRegister(app, domains.Config{
+//		PublicDirsRoot: "./pb_sites",
+//		IndexFallback:  true,
+//	})
+//
+//	tenancy.MustRegister(app, tenancy.Config{
+//		Resolvers:   []tenancy.Resolver{domains.TenantResolver},
+//		Collections: []string{"posts"},
+//	})
+package domains
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+const CollectionNameDomains = "_domains"
+
+const (
+	// RequestEventKeyDomain is the request event store key with the resolved request [Domain].
+	RequestEventKeyDomain = "pbDomain"
+
+	DefaultResolveMiddlewareId = "pbDomainsResolve"
+	// before the auth token load and the tenancy plugin middlewares
+	DefaultResolveMiddlewarePriority = apis.DefaultLoadAuthTokenMiddlewarePriority - 1
+
+	DefaultStaticMiddlewareId = "pbDomainsStatic"
+	// after the security headers middleware
+	DefaultStaticMiddlewarePriority = apis.DefaultSecurityHeadersMiddlewarePriority + 1
+)
+
+// Domain defines a single resolved domain config.
+type Domain struct {
+	Host      string `json:"host"`
+	PublicDir string `json:"publicDir"`
+	Namespace string `json:"namespace"`
+	Tenant    string `json:"tenant"`
+}
+
+// Config defines the config options of the domains plugin.
+type Config struct {
+	// PublicDirsRoot is the root directory of the domains publicDir
+	// (default to "pb_sites" next to the app data dir).
+	PublicDirsRoot string
+
+	// IndexFallback specifies whether to fallback to the domain
+	// publicDir index.html for the missing files (e.g. for SPA).
+	IndexFallback bool
+}
+
+// MustRegister registers the domains plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the domains plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if p.config.PublicDirsRoot == "" {
+		p.config.PublicDirsRoot = filepath.Join(app.DataDir(), "../pb_sites")
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollection(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollection()
+		})
+	}
+
+	// reload the domains on change
+	resetDomains := func(e *core.RecordEvent) error {
+		p.reset()
+		return e.Next()
+	}
+	p.app.OnRecordAfterCreateSuccess(CollectionNameDomains).BindFunc(resetDomains)
+	p.app.OnRecordAfterUpdateSuccess(CollectionNameDomains).BindFunc(resetDomains)
+	p.app.OnRecordAfterDeleteSuccess(CollectionNameDomains).BindFunc(resetDomains)
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		e.Router.Bind(&hook.Handler[*core.RequestEvent]{
+			Id:       DefaultResolveMiddlewareId,
+			Priority: DefaultResolveMiddlewarePriority,
+			Func:     p.resolveMiddleware,
+		})
+
+		e.Router.Bind(&hook.Handler[*core.RequestEvent]{
+			Id:       DefaultStaticMiddlewareId,
+			Priority: DefaultStaticMiddlewarePriority,
+			Func:     p.staticMiddleware,
+		})
+
+		return e.Next()
+	})
+
+	return nil
+}
+
+// FromRequest returns the domain resolved for the provided request (if any).
+func FromRequest(e *core.RequestEvent) *Domain {
+	domain, _ := e.Get(RequestEventKeyDomain).(*Domain)
+
+	return domain
+}
+
+// TenantResolver is a tenancy plugin compatible resolver that returns
+// the tenant of the domain resolved for the provided request (if any).
+func TenantResolver(e *core.RequestEvent) string {
+	domain := FromRequest(e)
+	if domain == nil {
+		return ""
+	}
+
+	return domain.Tenant
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+
+	mu      sync.RWMutex
+	domains map[string]*Domain // nil if not loaded
+}
+
+func (p *plugin) ensureCollection() error {
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameDomains); err == nil {
+		return nil // already created
+	}
+
+	col := core.NewBaseCollection(CollectionNameDomains)
+	col.System = true
+	col.Fields.Add(&core.TextField{
+		Name:     "host",
+		System:   true,
+		Required: true,
+		Pattern:  `^(\*\.)?[a-z0-9]([a-z0-9\-\.]*[a-z0-9])?$`,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "publicDir",
+		System: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:    "namespace",
+		System:  true,
+		Pattern: `^\w+$`,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "tenant",
+		System: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_domains_host", true, "host", "")
+
+	return p.app.Save(col)
+}
+
+func (p *plugin) reset() {
+	p.mu.Lock()
+	p.domains = nil
+	p.mu.Unlock()
+}
+
+// loadDomains returns the cached domains map (loading it if necessary).
+func (p *plugin) loadDomains() (map[string]*Domain, error) {
+	p.mu.RLock()
+	domains := p.domains
+	p.mu.RUnlock()
+
+	if domains != nil {
+		return domains, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.domains != nil {
+		return p.domains, nil // loaded by another request
+	}
+
+	records, err := p.app.FindAllRecords(CollectionNameDomains)
+	if err != nil {
+		return nil, err
+	}
+
+	domains = make(map[string]*Domain, len(records))
+	for _, r := range records {
+		domains[r.GetString("host")] = &Domain{
+			Host:      r.GetString("host"),
+			PublicDir: r.GetString("publicDir"),
+			Namespace: r.GetString("namespace"),
+			Tenant:    r.GetString("tenant"),
+		}
+	}
+
+	p.domains = domains
+
+	return domains, nil
+}
+
+// findDomain returns the domain matching the specified host
+// (the exact host matches have priority over the wildcard ones).
+func (p *plugin) findDomain(host string) (*Domain, error) {
+	domains, err := p.loadDomains()
+	if err != nil || len(domains) == 0 {
+		return nil, err
+	}
+
+	host = strings.ToLower(host)
+
+	// strip the port (if any)
+	if i := strings.LastIndexByte(host, ':'); i > 0 && !strings.HasSuffix(host, "]") {
+		host = host[:i]
+	}
+
+	if domain, ok := domains[host]; ok {
+		return domain, nil
+	}
+
+	for i := strings.IndexByte(host, '.'); i >= 0; i = strings.IndexByte(host, '.') {
+		host = host[i+1:]
+		if domain, ok := domains["*."+host]; ok {
+			return domain, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (p *plugin) resolveMiddleware(e *core.RequestEvent) error {
+	domain, err := p.findDomain(e.Request.Host)
+	if err != nil {
+		p.app.Logger().Error("Failed to load the domains", "error", err.Error())
+	}
+
+	if domain == nil {
+		return e.Next()
+	}
+
+	e.Set(RequestEventKeyDomain, domain)
+
+	if domain.Namespace != "" && isNamespacedRoute(e) {
+		e.Request.SetPathValue("collection", domain.Namespace+"_"+e.Request.PathValue("collection"))
+	}
+
+	return e.Next()
+}
+
+func (p *plugin) staticMiddleware(e *core.RequestEvent) error {
+	domain := FromRequest(e)
+	if domain == nil || domain.PublicDir == "" ||
+		(e.Request.Method != http.MethodGet && e.Request.Method != http.MethodHead) ||
+		!isCatchAllRoute(e) {
+		return e.Next()
+	}
+
+	// prevent escaping the public dirs root
+	dir := filepath.Join(p.config.PublicDirsRoot, filepath.Clean("/"+domain.PublicDir))
+
+	e.Request.SetPathValue(apis.StaticWildcardParam, strings.TrimPrefix(e.Request.URL.Path, "/"))
+
+	return apis.Static(os.DirFS(dir), p.config.IndexFallback)(e)
+}
+
+// isCatchAllRoute checks whether the request was matched by the router
+// catch-all or the default static route (aka. there is no explicit route
+// for the request path).
+func isCatchAllRoute(e *core.RequestEvent) bool {
+	return e.Request.Pattern == "/" ||
+		e.Request.Pattern == http.MethodGet+" /{"+apis.StaticWildcardParam+"...}"
+}
+
+// isNamespacedRoute checks whether the request is for a collection
+// records, auth or files API route with a not system collection.
+//
+// The collections management routes (e.g. used by the superusers
+// dashboard) are not affected.
+func isNamespacedRoute(e *core.RequestEvent) bool {
+	collection := e.Request.PathValue("collection")
+	if collection == "" || strings.HasPrefix(collection, "_") {
+		return false
+	}
+
+	path := e.Request.URL.Path
+
+	if strings.HasPrefix(path, "/api/files/") {
+		return true
+	}
+
+	subPath, ok := strings.CutPrefix(path, "/api/collections/"+collection)
+	if !ok {
+		return false
+	}
+
+	subPath = strings.Trim(subPath, "/")
+
+	return subPath != "" && subPath != "truncate" && !strings.HasPrefix(subPath, "rules/")
+}
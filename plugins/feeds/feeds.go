@@ -0,0 +1,680 @@
+// Package feeds implements sitemap.xml and per collection RSS 2.0 and
+// Atom feeds generation from the records of the configured collections.
+//
+// The generated documents are public and include only the records that
+// a guest could see in the regular list endpoint, aka. the collection
+// ListRule is evaluated without auth (collections with superusers only
+// ListRule are skipped).
+//
+// The documents are generated on demand and cached until a record or
+// collection change of any of the configured collections.
+//
+//	GET /sitemap.xml
+//	GET /feeds/{name}/rss.xml
+//	GET /feeds/{name}/atom.xml
+//
+// Example usage:
+//
+//	feeds.MustRegister(app, feeds.Config{
+//		SiteURL: "https://example.com",
+//		Pages:   []string{"/", "/about"},
+//		Collections: []feeds.Collection{
+//			{
+//				Collection:   "posts",
+//				Link:         "/blog/{slug}",
+//				Filter:       "published = true",
+//				TitleField:   "title",
+//				SummaryField: "summary",
+//				Title:        "Acme blog",
+//			},
+//		},
+//	})
+package feeds
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+const (
+	// DefaultSitemapPath is the default route path of the sitemap.
+	DefaultSitemapPath = "/sitemap.xml"
+
+	// DefaultFeedsPath is the default route path prefix of the collection feeds.
+	DefaultFeedsPath = "/feeds"
+
+	// DefaultFeedLimit is the default max number of the feed items.
+	DefaultFeedLimit = 50
+)
+
+// sitemapMaxURLs is the max number of urls allowed in a single sitemap file
+// (see https://www.sitemaps.org/protocol.html).
+const sitemapMaxURLs = 50000
+
+var linkPlaceholderRegex = regexp.MustCompile(`\{(\w+)\}`)
+
+// Collection defines the sitemap and feed mapping of a single collection.
+type Collection struct {
+	// Collection is the name or id of the collection (required).
+	Collection string
+
+	// Name is the feed url name (default to the collection name).
+	Name string
+
+	// Link is the site path or absolute url of a single record page
+	// with "{fieldName}" placeholders (required), e.g. "/blog/{slug}".
+	Link string
+
+	// Filter is an optional filter expression to further limit
+	// the included records (e.g. "published = true").
+	Filter string
+
+	// TitleField is the name of the record field used as item title
+	// (required unless DisableFeed is set).
+	TitleField string
+
+	// SummaryField is the name of the optional record field used as
+	// item description (could contain HTML, e.g. an editor field).
+	SummaryField string
+
+	// PublishedField is the name of the record date field used as item
+	// publish date and feed items order (default to "created").
+	PublishedField string
+
+	// UpdatedField is the name of the record date field used as item
+	// last modified date (default to "updated").
+	UpdatedField string
+
+	// Title is the feed title (default to "{appName} - {collectionName}").
+	Title string
+
+	// Description is the optional feed description.
+	Description string
+
+	// Limit is the max number of the feed items (default to [DefaultFeedLimit]).
+	Limit int
+
+	// ChangeFreq is the optional sitemap "changefreq" value of the
+	// collection urls (e.g. "daily", "weekly").
+	ChangeFreq string
+
+	// Priority is the optional sitemap "priority" value of the
+	// collection urls (between 0 and 1).
+	Priority float64
+
+	// ExcludeFromSitemap excludes the collection records from the sitemap.
+	ExcludeFromSitemap bool
+
+	// DisableFeed disables the collection RSS and Atom feeds.
+	DisableFeed bool
+}
+
+// Config defines the config options of the feeds plugin.
+type Config struct {
+	// SiteURL is the public site base url used for the generated
+	// absolute links (default to the app Settings().Meta.AppURL).
+	SiteURL string
+
+	// SitemapPath is the route path of the sitemap (default to [DefaultSitemapPath]).
+	SitemapPath string
+
+	// FeedsPath is the route path prefix of the collection feeds
+	// (default to [DefaultFeedsPath]).
+	FeedsPath string
+
+	// Pages is an optional list with extra site paths or absolute urls
+	// to include in the sitemap (e.g. "/", "/about").
+	Pages []string
+
+	// Collections is a list with the sitemap and feeds collections.
+	Collections []Collection
+}
+
+// MustRegister registers the feeds plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the feeds plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	if len(config.Collections) == 0 && len(config.Pages) == 0 {
+		return errors.New("feeds: at least one collection or page must be specified")
+	}
+
+	if config.SitemapPath == "" {
+		config.SitemapPath = DefaultSitemapPath
+	}
+
+	if config.FeedsPath == "" {
+		config.FeedsPath = DefaultFeedsPath
+	}
+	config.FeedsPath = "/" + strings.Trim(config.FeedsPath, "/")
+
+	names := make([]string, 0, len(config.Collections))
+	feedNames := map[string]struct{}{}
+
+	for i := range config.Collections {
+		c := &config.Collections[i]
+
+		if c.Collection == "" || c.Link == "" {
+			return fmt.Errorf("feeds: invalid collection %d: Collection and Link are required", i)
+		}
+
+		if !c.DisableFeed && c.TitleField == "" {
+			return fmt.Errorf("feeds: invalid collection %d: TitleField is required for the collection feed", i)
+		}
+
+		if c.Priority < 0 || c.Priority > 1 {
+			return fmt.Errorf("feeds: invalid collection %d: Priority must be between 0 and 1", i)
+		}
+
+		if c.Name == "" {
+			c.Name = c.Collection
+		}
+
+		if c.PublishedField == "" {
+			c.PublishedField = "created"
+		}
+
+		if c.UpdatedField == "" {
+			c.UpdatedField = "updated"
+		}
+
+		if c.Limit <= 0 {
+			c.Limit = DefaultFeedLimit
+		}
+
+		if !c.DisableFeed {
+			if _, ok := feedNames[c.Name]; ok {
+				return fmt.Errorf("feeds: duplicated feed name %q", c.Name)
+			}
+			feedNames[c.Name] = struct{}{}
+		}
+
+		names = append(names, c.Collection)
+	}
+
+	p := &plugin{
+		app:    app,
+		config: config,
+		cache:  store.New[[]byte](nil),
+	}
+
+	app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		e.Router.GET(config.SitemapPath, p.sitemap)
+		e.Router.GET(config.FeedsPath+"/{name}/rss.xml", p.rss)
+		e.Router.GET(config.FeedsPath+"/{name}/atom.xml", p.atom)
+
+		return e.Next()
+	})
+
+	// reset the cached documents on changes
+	// ---
+	if len(names) > 0 {
+		recordChange := func(e *core.RecordEvent) error {
+			p.cache.Reset(nil)
+			return e.Next()
+		}
+		app.OnRecordAfterCreateSuccess(names...).BindFunc(recordChange)
+		app.OnRecordAfterUpdateSuccess(names...).BindFunc(recordChange)
+		app.OnRecordAfterDeleteSuccess(names...).BindFunc(recordChange)
+
+		collectionChange := func(e *core.CollectionEvent) error {
+			p.cache.Reset(nil)
+			return e.Next()
+		}
+		app.OnCollectionAfterUpdateSuccess(names...).BindFunc(collectionChange)
+		app.OnCollectionAfterDeleteSuccess(names...).BindFunc(collectionChange)
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+
+	// cache stores the generated documents (keyed by the document type, name and site url)
+	cache *store.Store[[]byte]
+}
+
+func (p *plugin) sitemap(e *core.RequestEvent) error {
+	return p.serve(e, "sitemap", "application/xml; charset=utf-8", p.generateSitemap)
+}
+
+func (p *plugin) rss(e *core.RequestEvent) error {
+	c, ok := p.findFeed(e.Request.PathValue("name"))
+	if !ok {
+		return e.NotFoundError("", nil)
+	}
+
+	return p.serve(e, "rss:"+c.Name, "application/rss+xml; charset=utf-8", func(siteURL string) ([]byte, error) {
+		return p.generateRSS(siteURL, c)
+	})
+}
+
+func (p *plugin) atom(e *core.RequestEvent) error {
+	c, ok := p.findFeed(e.Request.PathValue("name"))
+	if !ok {
+		return e.NotFoundError("", nil)
+	}
+
+	return p.serve(e, "atom:"+c.Name, "application/atom+xml; charset=utf-8", func(siteURL string) ([]byte, error) {
+		return p.generateAtom(siteURL, c)
+	})
+}
+
+func (p *plugin) findFeed(name string) (*Collection, bool) {
+	for i, c := range p.config.Collections {
+		if !c.DisableFeed && c.Name == name {
+			return &p.config.Collections[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// serve writes the cached or newly generated document into the response.
+func (p *plugin) serve(
+	e *core.RequestEvent,
+	key string,
+	contentType string,
+	generate func(siteURL string) ([]byte, error),
+) error {
+	siteURL := p.siteURL()
+
+	key += "@" + siteURL
+
+	doc, ok := p.cache.GetOk(key)
+	if !ok {
+		var err error
+		doc, err = generate(siteURL)
+		if err != nil {
+			if errors.Is(err, errFeedUnavailable) {
+				return e.NotFoundError("", err)
+			}
+			return e.InternalServerError("Failed to generate the document.", err)
+		}
+
+		p.cache.Set(key, doc)
+	}
+
+	hash := sha256.Sum256(doc)
+	etag := `"` + hex.EncodeToString(hash[:16]) + `"`
+
+	e.Response.Header().Set("ETag", etag)
+	e.Response.Header().Set("Cache-Control", "no-cache")
+
+	if e.Request.Header.Get("If-None-Match") == etag {
+		return e.NoContent(http.StatusNotModified)
+	}
+
+	return e.Blob(http.StatusOK, contentType, doc)
+}
+
+func (p *plugin) siteURL() string {
+	siteURL := p.config.SiteURL
+	if siteURL == "" {
+		siteURL = p.app.Settings().Meta.AppURL
+	}
+
+	return strings.TrimRight(siteURL, "/")
+}
+
+// -------------------------------------------------------------------
+
+// errFeedUnavailable is returned when the feed collection is missing or is not public.
+var errFeedUnavailable = errors.New("the feed collection is missing or is not public")
+
+// item is a single generated record entry.
+type item struct {
+	link      string
+	title     string
+	summary   string
+	published time.Time
+	updated   time.Time
+}
+
+// findItems loads the guest visible collection records.
+func (p *plugin) findItems(siteURL string, c *Collection, orderField string, limit int) ([]*item, error) {
+	collection, err := p.app.FindCachedCollectionByNameOrId(c.Collection)
+	if err != nil {
+		return nil, errors.Join(errFeedUnavailable, err)
+	}
+
+	// superusers only
+	if collection.ListRule == nil {
+		return nil, errFeedUnavailable
+	}
+
+	info := &core.RequestInfo{
+		Context: core.RequestInfoContextDefault,
+		Method:  http.MethodGet,
+		Query:   map[string]string{},
+		Headers: map[string]string{},
+		Body:    map[string]any{},
+	}
+
+	resolver := core.NewRecordFieldResolver(p.app, collection, info, true)
+
+	query := p.app.RecordQuery(collection).
+		OrderBy(collection.Name + "." + orderField + " DESC").
+		Limit(int64(limit))
+
+	for _, filter := range []string{*collection.ListRule, c.Filter} {
+		if filter == "" {
+			continue
+		}
+
+		expr, err := search.FilterData(filter).BuildExpr(resolver)
+		if err != nil {
+			return nil, err
+		}
+		query.AndWhere(expr)
+	}
+
+	if err := resolver.UpdateQuery(query); err != nil {
+		return nil, err
+	}
+
+	records := []*core.Record{}
+	if err := query.All(&records); err != nil {
+		return nil, err
+	}
+
+	items := make([]*item, len(records))
+	for i, r := range records {
+		items[i] = &item{
+			link:      absoluteURL(siteURL, recordLink(c.Link, r)),
+			published: r.GetDateTime(c.PublishedField).Time(),
+			updated:   r.GetDateTime(c.UpdatedField).Time(),
+		}
+
+		if c.TitleField != "" {
+			items[i].title = r.GetString(c.TitleField)
+		}
+
+		if c.SummaryField != "" {
+			items[i].summary = r.GetString(c.SummaryField)
+		}
+	}
+
+	return items, nil
+}
+
+// recordLink replaces the link "{fieldName}" placeholders with the escaped record field values.
+func recordLink(link string, record *core.Record) string {
+	return linkPlaceholderRegex.ReplaceAllStringFunc(link, func(match string) string {
+		return url.PathEscape(record.GetString(match[1 : len(match)-1]))
+	})
+}
+
+func absoluteURL(siteURL string, link string) string {
+	if strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") {
+		return link
+	}
+
+	return siteURL + "/" + strings.TrimLeft(link, "/")
+}
+
+func (p *plugin) feedTitle(c *Collection, collectionName string) string {
+	if c.Title != "" {
+		return c.Title
+	}
+
+	return p.app.Settings().Meta.AppName + " - " + collectionName
+}
+
+func encodeXML(v any) ([]byte, error) {
+	buf := bytes.NewBufferString(xml.Header)
+
+	if err := xml.NewEncoder(buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// -------------------------------------------------------------------
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+func (p *plugin) generateSitemap(siteURL string) ([]byte, error) {
+	urlset := &sitemapURLSet{URLs: []sitemapURL{}}
+
+	for _, page := range p.config.Pages {
+		urlset.URLs = append(urlset.URLs, sitemapURL{Loc: absoluteURL(siteURL, page)})
+	}
+
+	for i, c := range p.config.Collections {
+		if c.ExcludeFromSitemap {
+			continue
+		}
+
+		limit := sitemapMaxURLs - len(urlset.URLs)
+		if limit <= 0 {
+			p.app.Logger().Warn("[feeds] the sitemap max urls limit was reached", "limit", sitemapMaxURLs)
+			break
+		}
+
+		items, err := p.findItems(siteURL, &p.config.Collections[i], c.UpdatedField, limit)
+		if err != nil {
+			if errors.Is(err, errFeedUnavailable) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to load the %q sitemap urls: %w", c.Collection, err)
+		}
+
+		var priority string
+		if c.Priority > 0 {
+			priority = fmt.Sprintf("%.1f", c.Priority)
+		}
+
+		for _, item := range items {
+			u := sitemapURL{
+				Loc:        item.link,
+				ChangeFreq: c.ChangeFreq,
+				Priority:   priority,
+			}
+
+			if !item.updated.IsZero() {
+				u.LastMod = item.updated.UTC().Format(time.RFC3339)
+			}
+
+			urlset.URLs = append(urlset.URLs, u)
+		}
+	}
+
+	return encodeXML(urlset)
+}
+
+// -------------------------------------------------------------------
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	Description string  `xml:"description,omitempty"`
+	PubDate     string  `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (p *plugin) generateRSS(siteURL string, c *Collection) ([]byte, error) {
+	items, err := p.findItems(siteURL, c, c.PublishedField, c.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       p.feedTitle(c, c.Name),
+			Link:        siteURL + "/",
+			Description: c.Description,
+			Items:       make([]rssItem, len(items)),
+		},
+	}
+
+	var lastBuild time.Time
+
+	for i, item := range items {
+		feed.Channel.Items[i] = rssItem{
+			Title:       item.title,
+			Link:        item.link,
+			GUID:        rssGUID{IsPermaLink: true, Value: item.link},
+			Description: item.summary,
+		}
+
+		if !item.published.IsZero() {
+			feed.Channel.Items[i].PubDate = item.published.UTC().Format(time.RFC1123Z)
+		}
+
+		if item.updated.After(lastBuild) {
+			lastBuild = item.updated
+		}
+	}
+
+	if !lastBuild.IsZero() {
+		feed.Channel.LastBuildDate = lastBuild.UTC().Format(time.RFC1123Z)
+	}
+
+	return encodeXML(feed)
+}
+
+// -------------------------------------------------------------------
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomAuthor  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title     string       `xml:"title"`
+	ID        string       `xml:"id"`
+	Link      atomLink     `xml:"link"`
+	Published string       `xml:"published,omitempty"`
+	Updated   string       `xml:"updated"`
+	Summary   *atomSummary `xml:"summary,omitempty"`
+}
+
+type atomSummary struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (p *plugin) generateAtom(siteURL string, c *Collection) ([]byte, error) {
+	items, err := p.findItems(siteURL, c, c.PublishedField, c.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	selfURL := siteURL + p.config.FeedsPath + "/" + url.PathEscape(c.Name) + "/atom.xml"
+
+	feed := &atomFeed{
+		Title:    p.feedTitle(c, c.Name),
+		Subtitle: c.Description,
+		ID:       selfURL,
+		Links: []atomLink{
+			{Href: selfURL, Rel: "self"},
+			{Href: siteURL + "/"},
+		},
+		Author:  atomAuthor{Name: p.app.Settings().Meta.AppName},
+		Entries: make([]atomEntry, len(items)),
+	}
+
+	var lastUpdated time.Time
+
+	for i, item := range items {
+		// the Atom entry updated element is required
+		updated := item.updated
+		if updated.IsZero() {
+			updated = item.published
+		}
+
+		entry := atomEntry{
+			Title:   item.title,
+			ID:      item.link,
+			Link:    atomLink{Href: item.link},
+			Updated: updated.UTC().Format(time.RFC3339),
+		}
+
+		if !item.published.IsZero() {
+			entry.Published = item.published.UTC().Format(time.RFC3339)
+		}
+
+		if item.summary != "" {
+			entry.Summary = &atomSummary{Type: "html", Value: item.summary}
+		}
+
+		feed.Entries[i] = entry
+
+		if updated.After(lastUpdated) {
+			lastUpdated = updated
+		}
+	}
+
+	feed.Updated = lastUpdated.UTC().Format(time.RFC3339)
+
+	return encodeXML(feed)
+}
@@ -0,0 +1,326 @@
+package feeds_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/feeds"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+var testConfig = feeds.Config{
+	SiteURL: "https://example.com/",
+	Pages:   []string{"/", "about"},
+	Collections: []feeds.Collection{
+		{
+			Collection:  "demo2",
+			Name:        "news",
+			Link:        "/news/{title}",
+			Filter:      "active = true",
+			TitleField:  "title",
+			Title:       "Test news",
+			Description: "Test description",
+			ChangeFreq:  "weekly",
+			Priority:    0.5,
+		},
+		{
+			// superusers only list rule
+			Collection: "demo1",
+			Link:       "/demo1/{id}",
+			TitleField: "text",
+		},
+		{
+			// guest doesn't satisfy the list rule
+			Collection: "demo3",
+			Link:       "/demo3/{id}",
+			TitleField: "title",
+		},
+		{
+			Collection:  "demo5",
+			Link:        "https://other.example.com/{id}",
+			DisableFeed: true,
+		},
+		{
+			Collection:         "missing",
+			Link:               "/missing/{id}",
+			TitleField:         "title",
+			ExcludeFromSitemap: true,
+		},
+	},
+}
+
+func TestRegisterErrors(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name   string
+		config feeds.Config
+	}{
+		{"no collections and pages", feeds.Config{}},
+		{"missing collection", feeds.Config{Collections: []feeds.Collection{{Link: "/{id}", TitleField: "title"}}}},
+		{"missing link", feeds.Config{Collections: []feeds.Collection{{Collection: "demo2", TitleField: "title"}}}},
+		{"missing title field", feeds.Config{Collections: []feeds.Collection{{Collection: "demo2", Link: "/{id}"}}}},
+		{"invalid priority", feeds.Config{Collections: []feeds.Collection{{Collection: "demo2", Link: "/{id}", TitleField: "title", Priority: 2}}}},
+		{"duplicated feed name", feeds.Config{Collections: []feeds.Collection{
+			{Collection: "demo2", Link: "/{id}", TitleField: "title"},
+			{Collection: "demo3", Name: "demo2", Link: "/{id}", TitleField: "title"},
+		}}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if err := feeds.Register(app, s.config); err == nil {
+				t.Fatal("Expected register error")
+			}
+		})
+	}
+}
+
+func TestSitemap(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:           "sitemap",
+			Method:         http.MethodGet,
+			URL:            "/sitemap.xml",
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`,
+				`<url><loc>https://example.com/</loc></url>`,
+				`<url><loc>https://example.com/about</loc></url>`,
+				`<url><loc>https://example.com/news/test3</loc><lastmod>2022-10-14T10:52:49Z</lastmod><changefreq>weekly</changefreq><priority>0.5</priority></url>`,
+				`<url><loc>https://example.com/news/test2</loc>`,
+				`<url><loc>https://other.example.com/qjeql998mtp1azp</loc>`,
+			},
+			NotExpectedContent: []string{
+				// filter
+				`/news/test1`,
+				// list rules
+				`/demo1/`,
+				`/demo3/`,
+				// not matching the demo5 list rule
+				`/la4y2w4o98acwuj`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+			TestAppFactory: testAppFactory,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if ct := res.Header.Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+					t.Fatalf("Expected xml content type, got %q", ct)
+				}
+
+				if res.Header.Get("ETag") == "" {
+					t.Fatal("Expected ETag header to be set")
+				}
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestFeeds(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "missing feed",
+			Method:          http.MethodGet,
+			URL:             "/feeds/missing/rss.xml",
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+			TestAppFactory:  testAppFactory,
+		},
+		{
+			Name:            "disabled feed",
+			Method:          http.MethodGet,
+			URL:             "/feeds/demo5/rss.xml",
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+			TestAppFactory:  testAppFactory,
+		},
+		{
+			Name:            "superusers only feed",
+			Method:          http.MethodGet,
+			URL:             "/feeds/demo1/atom.xml",
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+			TestAppFactory:  testAppFactory,
+		},
+		{
+			Name:           "feed with list rule failure",
+			Method:         http.MethodGet,
+			URL:            "/feeds/demo3/rss.xml",
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`<title>acme_test - demo3</title>`,
+			},
+			NotExpectedContent: []string{
+				`<item>`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+			TestAppFactory: testAppFactory,
+		},
+		{
+			Name:           "rss feed",
+			Method:         http.MethodGet,
+			URL:            "/feeds/news/rss.xml",
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`<rss version="2.0"><channel>`,
+				`<title>Test news</title><link>https://example.com/</link><description>Test description</description>`,
+				`<lastBuildDate>Fri, 14 Oct 2022 10:52:49 +0000</lastBuildDate>`,
+				`<item><title>test3</title><link>https://example.com/news/test3</link><guid isPermaLink="true">https://example.com/news/test3</guid><pubDate>Wed, 12 Oct 2022 11:42:58 +0000</pubDate></item><item><title>test2</title>`,
+			},
+			NotExpectedContent: []string{
+				`test1`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+			TestAppFactory: testAppFactory,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if ct := res.Header.Get("Content-Type"); ct != "application/rss+xml; charset=utf-8" {
+					t.Fatalf("Expected rss content type, got %q", ct)
+				}
+			},
+		},
+		{
+			Name:           "atom feed",
+			Method:         http.MethodGet,
+			URL:            "/feeds/news/atom.xml",
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`<feed xmlns="http://www.w3.org/2005/Atom">`,
+				`<title>Test news</title><subtitle>Test description</subtitle><id>https://example.com/feeds/news/atom.xml</id>`,
+				`<updated>2022-10-14T10:52:49Z</updated>`,
+				`<link href="https://example.com/feeds/news/atom.xml" rel="self"></link><link href="https://example.com/"></link>`,
+				`<author><name>acme_test</name></author>`,
+				`<entry><title>test3</title><id>https://example.com/news/test3</id><link href="https://example.com/news/test3"></link><published>2022-10-12T11:42:58Z</published><updated>2022-10-14T10:52:49Z</updated></entry>`,
+			},
+			NotExpectedContent: []string{
+				`test1`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+			TestAppFactory: testAppFactory,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if ct := res.Header.Get("Content-Type"); ct != "application/atom+xml; charset=utf-8" {
+					t.Fatalf("Expected atom content type, got %q", ct)
+				}
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name            string
+		change          func(t testing.TB, app *tests.TestApp)
+		expectedStatus  int
+		expectedContent []string
+	}{
+		{
+			"no changes",
+			nil,
+			http.StatusNotModified,
+			nil,
+		},
+		{
+			"change of not configured collection record",
+			func(t testing.TB, app *tests.TestApp) {
+				saveRecord(t, app, "demo4", "i9naidtvr6qsgb4", nil)
+			},
+			http.StatusNotModified,
+			nil,
+		},
+		{
+			"configured collection record change",
+			func(t testing.TB, app *tests.TestApp) {
+				saveRecord(t, app, "demo2", "llvuca81nly1qls", map[string]any{"active": true})
+			},
+			http.StatusOK,
+			[]string{`<item><title>test1</title>`},
+		},
+		{
+			"configured collection list rule change",
+			func(t testing.TB, app *tests.TestApp) {
+				collection, err := app.FindCollectionByNameOrId("demo2")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				collection.ListRule = nil
+
+				if err := app.Save(collection); err != nil {
+					t.Fatal(err)
+				}
+			},
+			http.StatusNotFound,
+			[]string{`"data":{}`},
+		},
+	}
+
+	for _, s := range scenarios {
+		headers := map[string]string{}
+
+		scenario := tests.ApiScenario{
+			Name:            s.name,
+			Method:          http.MethodGet,
+			URL:             "/feeds/news/rss.xml",
+			Headers:         headers,
+			ExpectedStatus:  s.expectedStatus,
+			ExpectedContent: s.expectedContent,
+			TestAppFactory:  testAppFactory,
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				mux, err := e.Router.BuildMux()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feeds/news/rss.xml", nil))
+
+				etag := rec.Result().Header.Get("ETag")
+				if etag == "" {
+					t.Fatal("Expected ETag header to be set")
+				}
+				headers["If-None-Match"] = etag
+
+				if s.change != nil {
+					s.change(t, app)
+				}
+			},
+		}
+
+		scenario.Test(t)
+	}
+}
+
+var testAppFactory = tests.NewTestAppFactory(func(t testing.TB, app *tests.TestApp) {
+	feeds.MustRegister(app, testConfig)
+})
+
+func saveRecord(t testing.TB, app core.App, collection string, id string, data map[string]any) {
+	record, err := app.FindRecordById(collection, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record.Load(data)
+
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+}
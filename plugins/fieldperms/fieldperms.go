@@ -0,0 +1,376 @@
+// Package fieldperms implements field-level read and write permissions
+// for the collection records.
+//
+// Each guarded field could be marked as superusers only (nil rule),
+// owner only (see [OwnerOnly]) or guarded by a regular API rule expression.
+//
+// The read permissions are enforced during the records serialization
+// (the unreadable fields are hidden from the response) and the
+// guarded fields that are not readable by everyone cannot be used in
+// the non-superuser records filter, sort and aggregate query parameters
+// (and in the realtime subscriptions filter).
+//
+// The write permissions are enforced during the create and update
+// record requests submission (the write rule is checked against the
+// stored record state, or against the submitted data in case of a create).
+//
+// Example usage:
+//
+//	fieldperms.MustRegister(app, fieldperms.Config{
+//		Collections: map[string]map[string]fieldperms.Permission{
+//			"users": {
+//				// readable and writable only by superusers
+//				"tac": {},
+//				// publicly readable but writable only by the record owner
+//				"bio": {Read: types.Pointer(""), Write: fieldperms.OwnerOnly("id")},
+//				// readable only by verified users
+//				"phone": {Read: types.Pointer("@request.auth.verified = true")},
+//			},
+//		},
+//	})
+package fieldperms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
+)
+
+const (
+	DefaultMiddlewareId       = "pbFieldPerms"
+	DefaultMiddlewarePriority = apis.DefaultLoadAuthTokenMiddlewarePriority + 1
+)
+
+// Permission defines the read and write access rules of a single collection field.
+//
+// Similar to the collection API rules the Read and Write rules could be:
+//   - nil - only superusers can access the field
+//   - empty string - everyone who has access to the record can access the field
+//   - nonempty string - only users who satisfy the rule can access the field
+type Permission struct {
+	Read  *string
+	Write *string
+}
+
+// IsPublicReadable reports whether the field is readable by everyone
+// who has access to the record.
+func (p Permission) IsPublicReadable() bool {
+	return p.Read != nil && *p.Read == ""
+}
+
+// OwnerOnly returns a rule that is satisfied only by the auth record
+// whose id matches the value of the specified record field
+// (use "id" for the auth collections own records).
+func OwnerOnly(ownerField string) *string {
+	rule := ownerField + " = @request.auth.id"
+
+	return &rule
+}
+
+// Config defines the config options of the fieldperms plugin.
+type Config struct {
+	// Collections maps the collection names to their guarded fields permissions.
+	Collections map[string]map[string]Permission
+}
+
+// MustRegister registers the fieldperms plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the fieldperms plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if len(p.config.Collections) == 0 {
+		return errors.New("fieldperms: at least one guarded collection must be specified")
+	}
+
+	names := make([]string, 0, len(p.config.Collections))
+	for name, fields := range p.config.Collections {
+		if len(fields) == 0 {
+			return fmt.Errorf("fieldperms: missing guarded fields for collection %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		e.Router.Bind(&hook.Handler[*core.RequestEvent]{
+			Id:       DefaultMiddlewareId,
+			Priority: DefaultMiddlewarePriority,
+			Func:     p.middleware,
+		})
+
+		return e.Next()
+	})
+
+	p.app.OnRealtimeSubscribeRequest().BindFunc(p.onRealtimeSubscribe)
+	p.app.OnRecordEnrich(names...).BindFunc(p.onRecordEnrich)
+	p.app.OnRecordCreateRequest(names...).BindFunc(p.onRecordCreate)
+	p.app.OnRecordUpdateRequest(names...).BindFunc(p.onRecordUpdate)
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+var (
+	quotedTextRegex = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	identifierRegex = regexp.MustCompile(`@?[\w][\w\.:]*`)
+)
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) permissions(collection *core.Collection) map[string]Permission {
+	if perms, ok := p.config.Collections[collection.Name]; ok {
+		return perms
+	}
+
+	return p.config.Collections[collection.Id]
+}
+
+// fieldsQueryParams lists the records query parameters that could
+// reference collection fields (filter and sort expressions, aggregate fields).
+var fieldsQueryParams = []string{
+	search.FilterQueryParam,
+	search.SortQueryParam,
+	apis.GroupByQueryParam,
+	apis.SumQueryParam,
+	apis.AvgQueryParam,
+	apis.MinQueryParam,
+	apis.MaxQueryParam,
+}
+
+// middleware forbids the non-superuser collection requests (list, count,
+// aggregate, etc.) that filter, sort or aggregate by guarded fields
+// that are not readable by everyone.
+func (p *plugin) middleware(e *core.RequestEvent) error {
+	if e.Request.PathValue("collection") == "" || e.HasSuperuserAuth() {
+		return e.Next()
+	}
+
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil {
+		return e.Next() // the route handler will take care for the error
+	}
+
+	query := e.Request.URL.Query()
+	for _, param := range fieldsQueryParams {
+		if field := p.findUnreadableField(collection, query.Get(param)); field != "" {
+			return e.ForbiddenError(fmt.Sprintf("Only superusers can use %q in the %s query parameter.", field, param), nil)
+		}
+	}
+
+	return e.Next()
+}
+
+// onRealtimeSubscribe forbids the non-superuser realtime subscriptions
+// with options filter that references guarded fields that are not readable by everyone.
+func (p *plugin) onRealtimeSubscribe(e *core.RealtimeSubscribeRequestEvent) error {
+	if e.HasSuperuserAuth() {
+		return e.Next()
+	}
+
+	for _, sub := range e.Subscriptions {
+		u, err := url.Parse(sub)
+		if err != nil {
+			continue
+		}
+
+		collectionName, _, _ := strings.Cut(u.Path, "/")
+
+		collection, err := e.App.FindCachedCollectionByNameOrId(collectionName)
+		if err != nil {
+			continue // not a collection subscription
+		}
+
+		options := struct {
+			Query map[string]any `json:"query"`
+		}{}
+		if raw := u.Query().Get("options"); raw != "" {
+			json.Unmarshal([]byte(raw), &options)
+		}
+
+		filter := cast.ToString(options.Query[search.FilterQueryParam])
+		if field := p.findUnreadableField(collection, filter); field != "" {
+			return e.ForbiddenError(fmt.Sprintf("Only superusers can use %q in the subscription filter.", field), nil)
+		}
+	}
+
+	return e.Next()
+}
+
+// findUnreadableField returns the first field of the expression that
+// is guarded by a non-public read rule (or empty string if none is found).
+//
+// The single field identifiers are checked against the provided collection while
+// the relation paths (e.g. "author.tac") against all guarded collections.
+func (p *plugin) findUnreadableField(collection *core.Collection, expr string) string {
+	if expr == "" {
+		return ""
+	}
+
+	expr = quotedTextRegex.ReplaceAllString(expr, "")
+
+	for _, identifier := range identifierRegex.FindAllString(expr, -1) {
+		parts := strings.Split(identifier, ".")
+
+		field, _, _ := strings.Cut(parts[len(parts)-1], ":")
+
+		if len(parts) == 1 {
+			if perm, ok := p.permissions(collection)[field]; ok && !perm.IsPublicReadable() {
+				return field
+			}
+			continue
+		}
+
+		for _, perms := range p.config.Collections {
+			if perm, ok := perms[field]; ok && !perm.IsPublicReadable() {
+				return identifier
+			}
+		}
+	}
+
+	return ""
+}
+
+func (p *plugin) onRecordEnrich(e *core.RecordEnrichEvent) error {
+	requestInfo := e.RequestInfo
+	if requestInfo == nil {
+		requestInfo = &core.RequestInfo{}
+	}
+
+	for field, perm := range p.permissions(e.Record.Collection()) {
+		canRead, err := e.App.CanAccessRecord(e.Record, requestInfo, perm.Read)
+		if err != nil {
+			e.App.Logger().Debug(
+				"Failed to check field read permission",
+				"collectionId", e.Record.Collection().Id,
+				"field", field,
+				"error", err.Error(),
+			)
+		}
+
+		if !canRead {
+			e.Record.Hide(field)
+		}
+	}
+
+	return e.Next()
+}
+
+func (p *plugin) onRecordCreate(e *core.RecordRequestEvent) error {
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	submitted := p.submittedFields(e.Collection, requestInfo)
+	if len(submitted) == 0 || requestInfo.HasSuperuserAuth() {
+		return e.Next()
+	}
+
+	// the new record doesn't exist yet so temporary save it
+	// and check the write rules against the submitted data
+	var forbidden []string
+	err = forms.NewRecordUpsert(e.App, e.Record).DrySubmit(func(txApp core.App, drySavedRecord *core.Record) error {
+		var checkErr error
+		forbidden, checkErr = checkWriteAccess(txApp, drySavedRecord, requestInfo, submitted)
+		return checkErr
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to create record.", err)
+	}
+
+	if len(forbidden) > 0 {
+		return forbiddenFieldsError("Failed to create record.", forbidden)
+	}
+
+	return e.Next()
+}
+
+func (p *plugin) onRecordUpdate(e *core.RecordRequestEvent) error {
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	submitted := p.submittedFields(e.Collection, requestInfo)
+	if len(submitted) == 0 || requestInfo.HasSuperuserAuth() {
+		return e.Next()
+	}
+
+	// note: the form data is already loaded in e.Record
+	forbidden, err := checkWriteAccess(e.App, e.Record.Original(), requestInfo, submitted)
+	if err != nil {
+		return e.BadRequestError("Failed to update record.", err)
+	}
+
+	if len(forbidden) > 0 {
+		return forbiddenFieldsError("Failed to update record.", forbidden)
+	}
+
+	return e.Next()
+}
+
+// submittedFields returns the guarded field permissions of the
+// fields that are part of the request body.
+func (p *plugin) submittedFields(collection *core.Collection, requestInfo *core.RequestInfo) map[string]Permission {
+	result := map[string]Permission{}
+
+	for field, perm := range p.permissions(collection) {
+		// note: requestInfo.Body has already the field modifiers replaced
+		if _, ok := requestInfo.Body[field]; ok {
+			result[field] = perm
+		}
+	}
+
+	return result
+}
+
+func checkWriteAccess(app core.App, record *core.Record, requestInfo *core.RequestInfo, perms map[string]Permission) ([]string, error) {
+	var forbidden []string
+
+	for field, perm := range perms {
+		canWrite, err := app.CanAccessRecord(record, requestInfo, perm.Write)
+		if err != nil {
+			return nil, err
+		}
+
+		if !canWrite {
+			forbidden = append(forbidden, field)
+		}
+	}
+
+	sort.Strings(forbidden)
+
+	return forbidden, nil
+}
+
+func forbiddenFieldsError(message string, fields []string) error {
+	errs := validation.Errors{}
+	for _, field := range fields {
+		errs[field] = validation.NewError("validation_field_forbidden", "You are not allowed to change this field.")
+	}
+
+	return apis.NewBadRequestError(message, errs)
+}
@@ -0,0 +1,252 @@
+package geoip_test
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/geoip"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/mmdb"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	bgIP = "1.2.3.4"
+	usIP = "8.8.8.8"
+	// not in the test database
+	unknownIP = "127.0.0.1"
+)
+
+func testDatabase(t testing.TB) []byte {
+	w := mmdb.NewWriter("Test-City")
+
+	entries := []struct {
+		cidr string
+		data map[string]any
+	}{
+		{
+			"1.2.3.0/24",
+			map[string]any{
+				"city":      map[string]any{"names": map[string]any{"en": "Sofia", "bg": "София"}},
+				"continent": map[string]any{"code": "EU"},
+				"country":   map[string]any{"iso_code": "BG", "names": map[string]any{"en": "Bulgaria"}},
+				"location":  map[string]any{"latitude": 42.6975, "longitude": 23.3241},
+			},
+		},
+		{
+			"8.8.8.0/24",
+			map[string]any{
+				"continent":          map[string]any{"code": "NA"},
+				"registered_country": map[string]any{"iso_code": "US", "names": map[string]any{"en": "United States"}},
+			},
+		},
+	}
+
+	for _, entry := range entries {
+		_, network, err := net.ParseCIDR(entry.cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Insert(network, entry.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := w.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func testAppFactory(config geoip.Config) func(t testing.TB) *tests.TestApp {
+	return tests.NewTestAppFactory(func(t testing.TB, app *tests.TestApp) {
+		app.Settings().TrustedProxy.Headers = []string{"X-Forwarded-For"}
+
+		config.DatabasePath = filepath.Join(app.DataDir(), "test.mmdb")
+		if err := os.WriteFile(config.DatabasePath, testDatabase(t), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		geoip.MustRegister(app, config)
+
+		places := core.NewBaseCollection("places")
+		places.ListRule = types.Pointer(`@request.geoip.country = "BG" || @request.geoip.continent = "NA"`)
+		places.ViewRule = types.Pointer(`@request.geoip.city = "Sofia"`)
+		places.Fields.Add(&core.TextField{Name: "title"})
+		if err := app.Save(places); err != nil {
+			t.Fatal(err)
+		}
+
+		record := core.NewRecord(places)
+		record.Id = "place000000test"
+		record.Set("title", "test")
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+
+		app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+			e.Router.GET("/geoip-test", func(e *core.RequestEvent) error {
+				return e.JSON(http.StatusOK, map[string]any{
+					"info":    geoip.FromRequest(e),
+					"meta":    e.Get(apis.RequestEventKeyLogMeta),
+					"country": e.Request.Header.Get(geoip.HeaderCountry),
+				})
+			})
+
+			return e.Next()
+		})
+	})
+}
+
+func TestRegisterErrors(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	err := geoip.Register(app, geoip.Config{UpdateURL: "http://example.com", UpdateSchedule: "invalid"})
+	if err == nil {
+		t.Fatal("Expected register error")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:   "unknown ip with client submitted header",
+			Method: http.MethodGet,
+			URL:    "/geoip-test",
+			Headers: map[string]string{
+				"X-Forwarded-For":   unknownIP,
+				geoip.HeaderCountry: "BG",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"info":null`, `"meta":null`, `"country":""`},
+			TestAppFactory:  testAppFactory(geoip.Config{}),
+		},
+		{
+			Name:   "city entry",
+			Method: http.MethodGet,
+			URL:    "/geoip-test",
+			Headers: map[string]string{
+				"X-Forwarded-For":   bgIP,
+				geoip.HeaderCountry: "US",
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"info":{"countryCode":"BG","countryName":"Bulgaria","city":"Sofia","continent":"EU","latitude":42.6975,"longitude":23.3241}`,
+				`"meta":{"geoip":{"countryCode":"BG"`,
+				`"country":"BG"`,
+			},
+			TestAppFactory: testAppFactory(geoip.Config{}),
+		},
+		{
+			Name:   "localized names with fallback",
+			Method: http.MethodGet,
+			URL:    "/geoip-test",
+			Headers: map[string]string{
+				"X-Forwarded-For": bgIP,
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"countryName":"Bulgaria"`,
+				`"city":"София"`,
+			},
+			TestAppFactory: testAppFactory(geoip.Config{Language: "bg"}),
+		},
+		{
+			Name:   "registered country fallback",
+			Method: http.MethodGet,
+			URL:    "/geoip-test",
+			Headers: map[string]string{
+				"X-Forwarded-For": usIP,
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"info":{"countryCode":"US","countryName":"United States","continent":"NA"}`,
+				`"country":"US"`,
+			},
+			TestAppFactory: testAppFactory(geoip.Config{}),
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRuleMacros(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "list with unknown location",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/places/records",
+			Headers:         map[string]string{"X-Forwarded-For": unknownIP},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":0`},
+			TestAppFactory:  testAppFactory(geoip.Config{}),
+		},
+		{
+			Name:   "list with client submitted header",
+			Method: http.MethodGet,
+			URL:    "/api/collections/places/records",
+			Headers: map[string]string{
+				"X-Forwarded-For":   unknownIP,
+				geoip.HeaderCountry: "BG",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":0`},
+			TestAppFactory:  testAppFactory(geoip.Config{}),
+		},
+		{
+			Name:            "list with matching country",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/places/records",
+			Headers:         map[string]string{"X-Forwarded-For": bgIP},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":1`, `"id":"place000000test"`},
+			TestAppFactory:  testAppFactory(geoip.Config{}),
+		},
+		{
+			Name:            "list with matching continent",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/places/records",
+			Headers:         map[string]string{"X-Forwarded-For": usIP},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":1`},
+			TestAppFactory:  testAppFactory(geoip.Config{}),
+		},
+		{
+			Name:            "view with non-matching city",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/places/records/place000000test",
+			Headers:         map[string]string{"X-Forwarded-For": usIP},
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			TestAppFactory:  testAppFactory(geoip.Config{}),
+		},
+		{
+			Name:            "view with matching city",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/places/records/place000000test",
+			Headers:         map[string]string{"X-Forwarded-For": bgIP},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"id":"place000000test"`},
+			TestAppFactory:  testAppFactory(geoip.Config{}),
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,131 @@
+package geoip
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/mmdb"
+)
+
+// maxDownloadSize is the max allowed size of the downloaded (uncompressed) database.
+const maxDownloadSize = 512 << 20
+
+const downloadTimeout = 10 * time.Minute
+
+// update downloads the database from the configured url,
+// replaces the local database file and reloads it.
+func (p *plugin) update() error {
+	ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.UpdateURL, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("failed to download the database (status %d)", res.StatusCode)
+	}
+
+	raw, err := extractDatabase(res.Body)
+	if err != nil {
+		return err
+	}
+
+	// ensure that the downloaded database is valid before replacing the existing one
+	if _, err := mmdb.FromBytes(raw); err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(p.config.DatabasePath)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".geoip_*.mmdb")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(raw)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), p.config.DatabasePath); err != nil {
+		return err
+	}
+
+	return p.load()
+}
+
+// extractDatabase reads the database from plain .mmdb, gzip or tar.gz stream.
+func extractDatabase(r io.Reader) ([]byte, error) {
+	br := bufio.NewReader(r)
+
+	magic, _ := br.Peek(2)
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+
+		br = bufio.NewReader(gz)
+	}
+
+	// tar archives have the "ustar" magic at offset 257
+	header, _ := br.Peek(262)
+	if len(header) == 262 && bytes.HasPrefix(header[257:], []byte("ustar")) {
+		tr := tar.NewReader(br)
+		for {
+			h, err := tr.Next()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil, errors.New("missing .mmdb file in the downloaded archive")
+				}
+				return nil, err
+			}
+
+			if h.Typeflag == tar.TypeReg && strings.HasSuffix(h.Name, ".mmdb") {
+				return readLimited(tr)
+			}
+		}
+	}
+
+	return readLimited(br)
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	raw, err := io.ReadAll(io.LimitReader(r, maxDownloadSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) > maxDownloadSize {
+		return nil, errors.New("the downloaded database is too large")
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,100 @@
+package geoip_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/plugins/geoip"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/mmdb"
+)
+
+func TestAutoDownload(t *testing.T) {
+	t.Parallel()
+
+	db := testDatabase(t)
+
+	gzipped := func(data []byte) []byte {
+		buf := &bytes.Buffer{}
+		gz := gzip.NewWriter(buf)
+		gz.Write(data)
+		gz.Close()
+		return buf.Bytes()
+	}
+
+	tarball := func(data []byte) []byte {
+		buf := &bytes.Buffer{}
+		tw := tar.NewWriter(buf)
+		tw.WriteHeader(&tar.Header{Name: "GeoLite2-City/README.txt", Mode: 0644, Size: 4, Typeflag: tar.TypeReg})
+		tw.Write([]byte("test"))
+		if data != nil {
+			tw.WriteHeader(&tar.Header{Name: "GeoLite2-City/GeoLite2-City.mmdb", Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg})
+			tw.Write(data)
+		}
+		tw.Close()
+		return buf.Bytes()
+	}
+
+	scenarios := []struct {
+		name        string
+		body        []byte
+		status      int
+		expectValid bool
+	}{
+		{"plain", db, 200, true},
+		{"gzip", gzipped(db), 200, true},
+		{"tar.gz", gzipped(tarball(db)), 200, true},
+		{"tar.gz without mmdb", gzipped(tarball(nil)), 200, false},
+		{"invalid database", []byte("invalid"), 200, false},
+		{"error status", db, 500, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(s.status)
+				w.Write(s.body)
+			}))
+			defer server.Close()
+
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			path := filepath.Join(app.DataDir(), "downloaded", "test.mmdb")
+
+			geoip.MustRegister(app, geoip.Config{
+				DatabasePath: path,
+				UpdateURL:    server.URL,
+			})
+
+			var r *mmdb.Reader
+			var err error
+			for i := 0; i < 20; i++ {
+				r, err = mmdb.Open(path)
+				if err == nil {
+					break
+				}
+				time.Sleep(50 * time.Millisecond)
+			}
+
+			if s.expectValid {
+				if err != nil {
+					t.Fatalf("Expected the database to be downloaded, got %v", err)
+				}
+				if _, err := r.LookupString("1.2.3.4"); err != nil {
+					t.Fatalf("Expected lookup to succeed, got %v", err)
+				}
+			} else if err == nil {
+				t.Fatal("Expected the database to not be downloaded")
+			}
+		})
+	}
+}
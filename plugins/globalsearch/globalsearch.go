@@ -0,0 +1,352 @@
+// Package globalsearch implements an app-wide search endpoint that
+// queries the configured searchable fields of multiple collections
+// and returns the matching records grouped by collection and ranked
+// by relevance (e.g. for a global search bar).
+//
+// The records of each collection are filtered with the collection
+// ListRule, aka. the same records that the user could see in the
+// regular list endpoint (collections with superusers only ListRule
+// are skipped for non-superusers).
+//
+//	GET /api/search?q=john&perCollection=5&collections=users,posts
+//
+// Example usage:
+//
+//	globalsearch.MustRegister(app, globalsearch.Config{
+//		Sources: []globalsearch.Source{
+//			{Collection: "posts", Fields: []string{"title", "summary"}, Weight: 2},
+//			{Collection: "users", Fields: []string{"name", "username"}},
+//		},
+//	})
+package globalsearch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
+)
+
+const (
+	// DefaultPath is the default route path of the search endpoint.
+	DefaultPath = "/api/search"
+
+	// DefaultMaxPerCollection is the default max number of
+	// returned records per collection.
+	DefaultMaxPerCollection = 10
+
+	// DefaultMinQueryLength is the default min search query length.
+	DefaultMinQueryLength = 2
+)
+
+// candidatesMultiplier specifies how many more records than the requested
+// per collection limit are loaded from the db for the relevance ranking.
+const candidatesMultiplier = 5
+
+// Source defines a single searchable collection.
+type Source struct {
+	// Collection is the name or id of the collection to search.
+	Collection string
+
+	// Fields is a list with the names of the searchable collection
+	// fields (e.g. text, email, url, editor fields).
+	//
+	// The order of the fields matters and the matches in the first
+	// fields are ranked slightly higher than the ones in the last fields.
+	//
+	// Hidden fields are searched only for superusers.
+	Fields []string
+
+	// Weight is an optional multiplier of the source records score
+	// (default to 1).
+	Weight float64
+}
+
+// Config defines the config options of the globalsearch plugin.
+type Config struct {
+	// Sources is a list with the searchable collections.
+	Sources []Source
+
+	// Path is the route path of the search endpoint (default to [DefaultPath]).
+	Path string
+
+	// MaxPerCollection specifies the max allowed number of the returned
+	// records per collection (default to [DefaultMaxPerCollection]).
+	MaxPerCollection int
+
+	// MinQueryLength specifies the min allowed search query length
+	// (default to [DefaultMinQueryLength]).
+	MinQueryLength int
+}
+
+// Item defines a single ranked search result item.
+type Item struct {
+	Score  float64      `json:"score"`
+	Record *core.Record `json:"record"`
+}
+
+// Group defines the search result items of a single collection.
+type Group struct {
+	CollectionId   string  `json:"collectionId"`
+	CollectionName string  `json:"collectionName"`
+	Items          []*Item `json:"items"`
+}
+
+// Result defines the search endpoint response.
+type Result struct {
+	Query  string   `json:"query"`
+	Groups []*Group `json:"groups"`
+}
+
+// MustRegister registers the globalsearch plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the globalsearch plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	if len(config.Sources) == 0 {
+		return errors.New("globalsearch: at least one source must be specified")
+	}
+
+	for i, s := range config.Sources {
+		if s.Collection == "" || len(s.Fields) == 0 {
+			return fmt.Errorf("globalsearch: invalid source %d: Collection and Fields are required", i)
+		}
+
+		if s.Weight < 0 {
+			return fmt.Errorf("globalsearch: invalid source %d: Weight must be non-negative", i)
+		}
+	}
+
+	if config.Path == "" {
+		config.Path = DefaultPath
+	}
+
+	if config.MaxPerCollection <= 0 {
+		config.MaxPerCollection = DefaultMaxPerCollection
+	}
+
+	if config.MinQueryLength <= 0 {
+		config.MinQueryLength = DefaultMinQueryLength
+	}
+
+	p := &plugin{app: app, config: config}
+
+	app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		e.Router.GET(config.Path, p.search)
+
+		return e.Next()
+	})
+
+	return nil
+}
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) search(e *core.RequestEvent) error {
+	query := e.Request.URL.Query()
+
+	q := strings.TrimSpace(query.Get("q"))
+	if len([]rune(q)) < p.config.MinQueryLength {
+		return e.BadRequestError(fmt.Sprintf("The search query must be at least %d characters.", p.config.MinQueryLength), nil)
+	}
+
+	limit := p.config.MaxPerCollection
+	if raw := query.Get("perCollection"); raw != "" {
+		limit = min(max(cast.ToInt(raw), 1), p.config.MaxPerCollection)
+	}
+
+	var only []string
+	if raw := query.Get("collections"); raw != "" {
+		only = strings.Split(raw, ",")
+	}
+
+	info, err := e.RequestInfo()
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	result := &Result{
+		Query:  q,
+		Groups: []*Group{},
+	}
+
+	for _, source := range p.config.Sources {
+		collection, err := e.App.FindCachedCollectionByNameOrId(source.Collection)
+		if err != nil {
+			e.App.Logger().Debug("[globalsearch] missing source collection", "collection", source.Collection)
+			continue
+		}
+
+		if len(only) > 0 && !slices.Contains(only, collection.Name) && !slices.Contains(only, collection.Id) {
+			continue
+		}
+
+		// superusers only
+		if collection.ListRule == nil && !info.HasSuperuserAuth() {
+			continue
+		}
+
+		group, err := p.searchCollection(e, info, collection, source, q, limit)
+		if err != nil {
+			return e.BadRequestError("Failed to search collection "+collection.Name+".", err)
+		}
+
+		if len(group.Items) > 0 {
+			result.Groups = append(result.Groups, group)
+		}
+	}
+
+	// order the groups by their best match score
+	// (the stable sort preserves the sources order for equal scores)
+	sort.SliceStable(result.Groups, func(i, j int) bool {
+		return result.Groups[i].Items[0].Score > result.Groups[j].Items[0].Score
+	})
+
+	return e.JSON(http.StatusOK, result)
+}
+
+func (p *plugin) searchCollection(
+	e *core.RequestEvent,
+	info *core.RequestInfo,
+	collection *core.Collection,
+	source Source,
+	q string,
+	limit int,
+) (*Group, error) {
+	group := &Group{
+		CollectionId:   collection.Id,
+		CollectionName: collection.Name,
+		Items:          []*Item{},
+	}
+
+	fields := make([]string, 0, len(source.Fields))
+	likes := make([]dbx.Expression, 0, len(source.Fields))
+	for _, name := range source.Fields {
+		field := collection.Fields.GetByName(name)
+		if field == nil || (field.GetHidden() && !info.HasSuperuserAuth()) {
+			continue
+		}
+
+		fields = append(fields, name)
+		likes = append(likes, dbx.Like(collection.Name+"."+name, q))
+	}
+	if len(likes) == 0 {
+		return group, nil
+	}
+
+	resolver := core.NewRecordFieldResolver(e.App, collection, info, info.HasSuperuserAuth())
+
+	selectQuery := e.App.RecordQuery(collection).
+		AndWhere(dbx.Or(likes...)).
+		Limit(int64(limit * candidatesMultiplier))
+
+	if collection.ListRule != nil && *collection.ListRule != "" && !info.HasSuperuserAuth() {
+		expr, err := search.FilterData(*collection.ListRule).BuildExpr(resolver)
+		if err != nil {
+			return nil, err
+		}
+		selectQuery.AndWhere(expr)
+	}
+
+	if err := resolver.UpdateQuery(selectQuery); err != nil {
+		return nil, err
+	}
+
+	records := []*core.Record{}
+	if err := selectQuery.All(&records); err != nil {
+		return nil, err
+	}
+
+	weight := source.Weight
+	if weight == 0 {
+		weight = 1
+	}
+
+	lowerQ := strings.ToLower(q)
+
+	for _, r := range records {
+		group.Items = append(group.Items, &Item{
+			Score:  score(r, fields, lowerQ) * weight,
+			Record: r,
+		})
+	}
+
+	sort.SliceStable(group.Items, func(i, j int) bool {
+		return group.Items[i].Score > group.Items[j].Score
+	})
+
+	if len(group.Items) > limit {
+		group.Items = group.Items[:limit]
+	}
+
+	records = make([]*core.Record, len(group.Items))
+	for i, item := range group.Items {
+		records[i] = item.Record
+	}
+
+	if err := apis.EnrichRecords(e, records); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// score calculates the relevance score of the record for the provided lowercased query.
+//
+// For each field the match is ranked as follows:
+//   - exact match: 4
+//   - prefix match: 3
+//   - word prefix match: 2
+//   - any other partial match: 1
+//
+// The record score is the best field score, slightly decreased
+// based on the field position, plus a small bonus for each other matching field.
+func score(record *core.Record, fields []string, lowerQ string) float64 {
+	var best, bonus float64
+
+	for i, name := range fields {
+		value := strings.ToLower(record.GetString(name))
+
+		var s float64
+		switch {
+		case value == lowerQ:
+			s = 4
+		case strings.HasPrefix(value, lowerQ):
+			s = 3
+		case strings.Contains(value, " "+lowerQ):
+			s = 2
+		case strings.Contains(value, lowerQ):
+			s = 1
+		default:
+			continue
+		}
+
+		// prefer the matches in the first fields
+		s -= float64(min(i, 5)) * 0.1
+
+		if s > best {
+			bonus += best * 0.1
+			best = s
+		} else {
+			bonus += s * 0.1
+		}
+	}
+
+	return best + bonus
+}
@@ -0,0 +1,595 @@
+// Package groups implements an optional user groups (aka. teams) subsystem.
+//
+// The groups are stored in the "_groups" system collection and each
+// group member (a record from any auth collection) has one of the
+// "owner", "admin" or "member" roles ("owner" implies "admin" and "admin" implies "member").
+//
+// The current request auth groups could be checked in the collection
+// API rules with the "@request.auth.groups" macro (it is satisfied if
+// any of the auth groups matches the condition) and the
+// "@request.auth.inGroup(groupId, role)" rule function, eg.:
+//
+//	// ListRule and ViewRule (where "group" is a regular collection field)
+//	@request.auth.groups ?= group
+//
+//	// UpdateRule
+//	@request.auth.inGroup(group, "admin") = true
+//
+// The groups and their members could be managed with the regular
+// records API (the system collections API rules could be further customized)
+// and the following endpoints (all of them require auth):
+//
+//	POST /api/groups                                (body: {"name": "Acme"})
+//	POST /api/groups/{id}/invites                   (body: {"email": "test@example.com", "role": "member"})
+//	GET  /api/groups/invites                        (the pending invites of the current auth email)
+//	POST /api/groups/invites/{inviteId}/accept
+//	POST /api/groups/invites/{inviteId}/decline
+//
+// Besides the plugin specific Config callbacks, the regular record
+// model hooks are also triggered for the groups system collections.
+//
+// Example usage:
+//
+//	groups.MustRegister(app, groups.Config{
+//		OnInviteCreate: func(e *groups.InviteEvent) error {
+//			// send the invite email...
+//			return nil
+//		},
+//	})
+package groups
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	CollectionNameGroups  = "_groups"
+	CollectionNameMembers = "_groupMembers"
+	CollectionNameInvites = "_groupInvites"
+)
+
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// InviteEvent defines the data of a single group invite event.
+type InviteEvent struct {
+	*core.RequestEvent
+
+	Group  *core.Record
+	Invite *core.Record
+
+	// Member is the created group membership record
+	// (available only on invite accept).
+	Member *core.Record
+}
+
+// Config defines the config options of the groups plugin.
+type Config struct {
+	// OnInviteCreate is an optional callback that is invoked after
+	// a group invite is created (e.g. to send the invite email).
+	OnInviteCreate func(e *InviteEvent) error
+
+	// OnInviteAccept is an optional callback that is invoked after
+	// a group invite is accepted and the membership is created.
+	OnInviteAccept func(e *InviteEvent) error
+}
+
+// MustRegister registers the groups plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the groups plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	// note: must be registered before the collections
+	// creation since they are used in the API rules
+	core.RegisterRuleMacro(RuleMacroGroups, groupsRuleMacro)
+	core.RegisterRuleFunction(RuleFunctionInGroup, inGroupRuleFunc)
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollections(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollections()
+		})
+	}
+
+	// delete the memberships of the deleted auth records
+	p.app.OnRecordDeleteExecute().Bind(&hook.Handler[*core.RecordEvent]{
+		Func: func(e *core.RecordEvent) error {
+			if !e.Record.Collection().IsAuth() {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp core.App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				members, err := txApp.FindAllRecords(CollectionNameMembers, dbx.HashExp{
+					"collectionRef": e.Record.Collection().Id,
+					"user":          e.Record.Id,
+				})
+				if err != nil {
+					return err
+				}
+
+				for _, member := range members {
+					if err := txApp.Delete(member); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group("/api/groups").Bind(apis.RequireAuth())
+		sub.POST("", p.createGroup)
+		sub.POST("/{id}/invites", p.createInvite)
+		sub.GET("/invites", p.listInvites)
+		sub.POST("/invites/{inviteId}/accept", p.acceptInvite)
+		sub.POST("/invites/{inviteId}/decline", p.declineInvite)
+
+		return e.Next()
+	})
+
+	return nil
+}
+
+// Roles returns the list of group roles that satisfy the specified role.
+func Roles(role string) ([]string, error) {
+	switch role {
+	case RoleMember:
+		return []string{RoleMember, RoleAdmin, RoleOwner}, nil
+	case RoleAdmin:
+		return []string{RoleAdmin, RoleOwner}, nil
+	case RoleOwner:
+		return []string{RoleOwner}, nil
+	}
+
+	return nil, fmt.Errorf("invalid group role %q", role)
+}
+
+// FindMember returns the group membership record of the specified auth record.
+func FindMember(app core.App, groupId string, auth *core.Record) (*core.Record, error) {
+	if auth == nil {
+		return nil, errors.New("missing auth record")
+	}
+
+	return app.FindFirstRecordByFilter(
+		CollectionNameMembers,
+		"group = {:group} && collectionRef = {:collectionRef} && user = {:user}",
+		dbx.Params{
+			"group":         groupId,
+			"collectionRef": auth.Collection().Id,
+			"user":          auth.Id,
+		},
+	)
+}
+
+// HasRole reports whether the specified auth record is a group member
+// with at least the specified role.
+func HasRole(app core.App, groupId string, auth *core.Record, role string) bool {
+	roles, err := Roles(role)
+	if err != nil {
+		return false
+	}
+
+	member, err := FindMember(app, groupId, auth)
+	if err != nil {
+		return false
+	}
+
+	for _, r := range roles {
+		if member.GetString("role") == r {
+			return true
+		}
+	}
+
+	return false
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) ensureCollections() error {
+	groups, err := p.app.FindCollectionByNameOrId(CollectionNameGroups)
+	if err != nil {
+		groups = core.NewBaseCollection(CollectionNameGroups)
+		groups.System = true
+		groups.ListRule = types.Pointer(RuleMacroGroups + " ?= id")
+		groups.ViewRule = types.Pointer(RuleMacroGroups + " ?= id")
+		groups.UpdateRule = types.Pointer(RuleFunctionInGroup + `(id, "admin") = true`)
+		groups.DeleteRule = types.Pointer(RuleFunctionInGroup + `(id, "owner") = true`)
+		groups.Fields.Add(&core.TextField{
+			Name:     "name",
+			System:   true,
+			Required: true,
+			Max:      255,
+		})
+		groups.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		groups.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		if err := p.app.Save(groups); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameMembers); err != nil {
+		members := core.NewBaseCollection(CollectionNameMembers)
+		members.System = true
+		members.ListRule = types.Pointer(RuleMacroGroups + " ?= group")
+		members.ViewRule = types.Pointer(RuleMacroGroups + " ?= group")
+		members.UpdateRule = types.Pointer(RuleFunctionInGroup + `(group, "owner") = true`)
+		members.DeleteRule = types.Pointer(
+			`(user = @request.auth.id && collectionRef = @request.auth.collectionId) || ` +
+				`(` + RuleFunctionInGroup + `(group, "admin") = true && role != "owner")`,
+		)
+		members.Fields.Add(&core.RelationField{
+			Name:          "group",
+			System:        true,
+			Required:      true,
+			CollectionId:  groups.Id,
+			CascadeDelete: true,
+			MaxSelect:     1,
+		})
+		members.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		members.Fields.Add(&core.TextField{
+			Name:     "user",
+			System:   true,
+			Required: true,
+		})
+		members.Fields.Add(&core.SelectField{
+			Name:      "role",
+			System:    true,
+			Required:  true,
+			MaxSelect: 1,
+			Values:    []string{RoleOwner, RoleAdmin, RoleMember},
+		})
+		members.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		members.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		members.AddIndex("idx_groupMembers_unique", true, "`group`, collectionRef, user", "")
+		members.AddIndex("idx_groupMembers_user", false, "collectionRef, user", "")
+
+		if err := p.app.Save(members); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameInvites); err != nil {
+		invites := core.NewBaseCollection(CollectionNameInvites)
+		invites.System = true
+		invites.ListRule = types.Pointer(RuleFunctionInGroup + `(group, "admin") = true`)
+		invites.ViewRule = types.Pointer(RuleFunctionInGroup + `(group, "admin") = true`)
+		invites.DeleteRule = types.Pointer(RuleFunctionInGroup + `(group, "admin") = true`)
+		invites.Fields.Add(&core.RelationField{
+			Name:          "group",
+			System:        true,
+			Required:      true,
+			CollectionId:  groups.Id,
+			CascadeDelete: true,
+			MaxSelect:     1,
+		})
+		invites.Fields.Add(&core.EmailField{
+			Name:     "email",
+			System:   true,
+			Required: true,
+		})
+		invites.Fields.Add(&core.SelectField{
+			Name:      "role",
+			System:    true,
+			Required:  true,
+			MaxSelect: 1,
+			Values:    []string{RoleOwner, RoleAdmin, RoleMember},
+		})
+		invites.Fields.Add(&core.TextField{
+			Name:   "invitedBy",
+			System: true,
+		})
+		invites.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		invites.AddIndex("idx_groupInvites_unique", true, "`group`, email", "")
+		invites.AddIndex("idx_groupInvites_email", false, "email", "")
+
+		if err := p.app.Save(invites); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type groupForm struct {
+	Name string `form:"name" json:"name"`
+}
+
+func (p *plugin) createGroup(e *core.RequestEvent) error {
+	form := &groupForm{}
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	err := validation.ValidateStruct(form,
+		validation.Field(&form.Name, validation.Required, validation.Length(1, 255)),
+	)
+	if err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	var group *core.Record
+
+	txErr := e.App.RunInTransaction(func(txApp core.App) error {
+		groupsCollection, err := txApp.FindCachedCollectionByNameOrId(CollectionNameGroups)
+		if err != nil {
+			return err
+		}
+
+		group = core.NewRecord(groupsCollection)
+		group.Set("name", form.Name)
+		if err := txApp.Save(group); err != nil {
+			return err
+		}
+
+		_, err = newMember(txApp, group.Id, e.Auth, RoleOwner)
+
+		return err
+	})
+	if txErr != nil {
+		return e.BadRequestError("Failed to create group.", txErr)
+	}
+
+	return e.JSON(http.StatusOK, group)
+}
+
+type inviteForm struct {
+	Email string `form:"email" json:"email"`
+	Role  string `form:"role" json:"role"`
+}
+
+func (p *plugin) createInvite(e *core.RequestEvent) error {
+	group, err := e.App.FindRecordById(CollectionNameGroups, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	isSuperuser := e.HasSuperuserAuth()
+
+	if !isSuperuser && !HasRole(e.App, group.Id, e.Auth, RoleAdmin) {
+		// hide the group existence
+		return e.NotFoundError("", nil)
+	}
+
+	form := &inviteForm{}
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+	if form.Role == "" {
+		form.Role = RoleMember
+	}
+
+	allowedRoles := []any{RoleAdmin, RoleMember}
+	if isSuperuser || HasRole(e.App, group.Id, e.Auth, RoleOwner) {
+		allowedRoles = append(allowedRoles, RoleOwner)
+	}
+
+	err = validation.ValidateStruct(form,
+		validation.Field(&form.Email, validation.Required, validation.Length(1, 255), is.EmailFormat),
+		validation.Field(&form.Role, validation.Required, validation.In(allowedRoles...)),
+	)
+	if err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	email := strings.ToLower(form.Email)
+
+	invite, err := e.App.FindFirstRecordByFilter(
+		CollectionNameInvites,
+		"group = {:group} && email = {:email}",
+		dbx.Params{"group": group.Id, "email": email},
+	)
+	if err != nil {
+		collection, err := e.App.FindCachedCollectionByNameOrId(CollectionNameInvites)
+		if err != nil {
+			return e.InternalServerError("", err)
+		}
+
+		invite = core.NewRecord(collection)
+		invite.Set("group", group.Id)
+		invite.Set("email", email)
+	}
+	invite.Set("role", form.Role)
+	invite.Set("invitedBy", e.Auth.Id)
+
+	if err := e.App.Save(invite); err != nil {
+		return e.BadRequestError("Failed to create group invite.", err)
+	}
+
+	if p.config.OnInviteCreate != nil {
+		err := p.config.OnInviteCreate(&InviteEvent{RequestEvent: e, Group: group, Invite: invite})
+		if err != nil {
+			return e.InternalServerError("Failed to process the created group invite.", err)
+		}
+	}
+
+	return e.JSON(http.StatusOK, invite)
+}
+
+func (p *plugin) listInvites(e *core.RequestEvent) error {
+	if err := checkInviteeAuth(e); err != nil {
+		return err
+	}
+
+	invites, err := e.App.FindAllRecords(CollectionNameInvites, dbx.HashExp{"email": strings.ToLower(e.Auth.Email())})
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	// expand the invites group so that the invitee could see its name
+	errs := e.App.ExpandRecords(invites, []string{"group"}, nil)
+	if len(errs) > 0 {
+		return e.InternalServerError("Failed to expand the invites group.", nil)
+	}
+
+	return e.JSON(http.StatusOK, invites)
+}
+
+func (p *plugin) acceptInvite(e *core.RequestEvent) error {
+	invite, err := p.loadInvite(e)
+	if err != nil {
+		return err
+	}
+
+	group, err := e.App.FindRecordById(CollectionNameGroups, invite.GetString("group"))
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	var member *core.Record
+
+	txErr := e.App.RunInTransaction(func(txApp core.App) error {
+		var err error
+
+		member, err = FindMember(txApp, group.Id, e.Auth)
+		if err != nil {
+			member, err = newMember(txApp, group.Id, e.Auth, invite.GetString("role"))
+			if err != nil {
+				return err
+			}
+		}
+
+		return txApp.Delete(invite)
+	})
+	if txErr != nil {
+		return e.BadRequestError("Failed to accept group invite.", txErr)
+	}
+
+	if p.config.OnInviteAccept != nil {
+		err := p.config.OnInviteAccept(&InviteEvent{RequestEvent: e, Group: group, Invite: invite, Member: member})
+		if err != nil {
+			return e.InternalServerError("Failed to process the accepted group invite.", err)
+		}
+	}
+
+	return e.JSON(http.StatusOK, member)
+}
+
+func (p *plugin) declineInvite(e *core.RequestEvent) error {
+	invite, err := p.loadInvite(e)
+	if err != nil {
+		return err
+	}
+
+	if err := e.App.Delete(invite); err != nil {
+		return e.BadRequestError("Failed to decline group invite.", err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// loadInvite loads the request path invite and checks
+// whether it belongs to the current request auth.
+func (p *plugin) loadInvite(e *core.RequestEvent) (*core.Record, error) {
+	if err := checkInviteeAuth(e); err != nil {
+		return nil, err
+	}
+
+	invite, err := e.App.FindRecordById(CollectionNameInvites, e.Request.PathValue("inviteId"))
+	if err != nil || !strings.EqualFold(invite.GetString("email"), e.Auth.Email()) {
+		return nil, e.NotFoundError("", err)
+	}
+
+	return invite, nil
+}
+
+// checkInviteeAuth ensures that the current request auth
+// has a verified email so that it could manage its invites.
+func checkInviteeAuth(e *core.RequestEvent) error {
+	if e.Auth.Email() == "" || (!e.Auth.Verified() && !e.Auth.IsSuperuser()) {
+		return e.ForbiddenError("Only verified auth records can access the group invites.", nil)
+	}
+
+	return nil
+}
+
+func newMember(app core.App, groupId string, auth *core.Record, role string) (*core.Record, error) {
+	collection, err := app.FindCachedCollectionByNameOrId(CollectionNameMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	member := core.NewRecord(collection)
+	member.Set("group", groupId)
+	member.Set("collectionRef", auth.Collection().Id)
+	member.Set("user", auth.Id)
+	member.Set("role", role)
+
+	if err := app.Save(member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
@@ -0,0 +1,136 @@
+package groups
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+const (
+	// RuleMacroGroups is the name of the registered rule macro that
+	// resolves to the ids of the groups of the current request auth, eg.:
+	//
+	//	@request.auth.groups ?= group
+	RuleMacroGroups = "@request.auth.groups"
+
+	// RuleFunctionInGroup is the name of the registered rule function that
+	// checks whether the current request auth is a group member with
+	// at least the specified role, eg.:
+	//
+	//	@request.auth.inGroup(group, "admin") = true
+	RuleFunctionInGroup = "@request.auth.inGroup"
+)
+
+// groupsRuleMacro implements the "@request.auth.groups" rule macro.
+//
+// The macro resolves to a single membership group column and the
+// expression in which it is used is wrapped in an EXISTS subquery
+// over the memberships of the current request auth, aka. it is
+// satisfied if any of the auth groups matches the condition.
+//
+// Guests are treated as auth without groups and the expression is never satisfied.
+func groupsRuleMacro(ctx *core.RuleFunctionContext) (*search.ResolverResult, error) {
+	var collectionId, userId string
+	if ctx.RequestInfo != nil && ctx.RequestInfo.Auth != nil {
+		collectionId = ctx.RequestInfo.Auth.Collection().Id
+		userId = ctx.RequestInfo.Auth.Id
+	}
+
+	suffix := security.PseudorandomString(5)
+	alias := "__groups" + suffix
+
+	params := dbx.Params{
+		"groupsCollection" + suffix: collectionId,
+		"groupsUser" + suffix:       userId,
+	}
+
+	return &search.ResolverResult{
+		Identifier: "[[" + alias + ".group]]",
+		NoCoalesce: true,
+		AfterBuild: func(expr dbx.Expression) dbx.Expression {
+			return &groupsExistsExpr{expr: expr, alias: alias, suffix: suffix, params: params}
+		},
+	}, nil
+}
+
+type groupsExistsExpr struct {
+	expr   dbx.Expression
+	params dbx.Params
+	alias  string
+	suffix string
+}
+
+// Build converts the expression into a SQL fragment.
+//
+// Implements [dbx.Expression] interface.
+func (e *groupsExistsExpr) Build(db *dbx.DB, params dbx.Params) string {
+	for k, v := range e.params {
+		params[k] = v
+	}
+
+	return fmt.Sprintf(
+		"EXISTS (SELECT 1 FROM [[%s]] [[%s]] WHERE [[%s.collectionRef]] = {:groupsCollection%s} AND [[%s.user]] = {:groupsUser%s} AND %s)",
+		CollectionNameMembers, e.alias,
+		e.alias, e.suffix,
+		e.alias, e.suffix,
+		e.expr.Build(db, params),
+	)
+}
+
+// inGroupRuleFunc implements the "@request.auth.inGroup(groupId, role)" rule function.
+func inGroupRuleFunc(ctx *core.RuleFunctionContext, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+	if len(args) != 2 {
+		return nil, errors.New(RuleFunctionInGroup + "() expects exactly 2 arguments")
+	}
+
+	if len(args[1].Params) != 1 {
+		return nil, errors.New(RuleFunctionInGroup + "() expects the role to be a plain text argument")
+	}
+
+	var role string
+	for _, v := range args[1].Params {
+		role = cast.ToString(v)
+	}
+
+	roles, err := Roles(role)
+	if err != nil {
+		return nil, fmt.Errorf("%s(): %w", RuleFunctionInGroup, err)
+	}
+
+	if ctx.RequestInfo == nil || ctx.RequestInfo.Auth == nil {
+		return &search.ResolverResult{Identifier: "0"}, nil
+	}
+
+	suffix := security.PseudorandomString(5)
+
+	params := search.MergeParams(args[0])
+	params["inGroupCollection"+suffix] = ctx.RequestInfo.Auth.Collection().Id
+	params["inGroupUser"+suffix] = ctx.RequestInfo.Auth.Id
+
+	rolePlaceholders := ""
+	for i, r := range roles {
+		name := fmt.Sprintf("inGroupRole%d%s", i, suffix)
+		params[name] = r
+		if i > 0 {
+			rolePlaceholders += ","
+		}
+		rolePlaceholders += "{:" + name + "}"
+	}
+
+	return &search.ResolverResult{
+		Identifier: fmt.Sprintf(
+			"(EXISTS (SELECT 1 FROM [[%s]] [[__ing%s]] WHERE [[__ing%s.group]] = %s AND [[__ing%s.collectionRef]] = {:inGroupCollection%s} AND [[__ing%s.user]] = {:inGroupUser%s} AND [[__ing%s.role]] IN (%s)))",
+			CollectionNameMembers, suffix,
+			suffix, args[0].Identifier,
+			suffix, suffix,
+			suffix, suffix,
+			suffix, rolePlaceholders,
+		),
+		Params: params,
+	}, nil
+}
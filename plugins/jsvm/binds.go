@@ -24,6 +24,7 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/mails"
+	"github.com/pocketbase/pocketbase/tools/ai"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/inflector"
@@ -75,7 +76,7 @@ func hooksBinds(app core.App, loader *goja.Runtime, executors *vmsPool) {
 					handlerArgs[i] = arg.Interface()
 				}
 
-				err := executors.run(func(executor *goja.Runtime) error {
+				err := executors.runWithContext(eventContext(handlerArgs), func(executor *goja.Runtime) error {
 					executor.Set("$app", goja.Undefined())
 					executor.Set("__args", handlerArgs)
 					res, err := executor.RunProgram(pr)
@@ -100,6 +101,40 @@ func hooksBinds(app core.App, loader *goja.Runtime, executors *vmsPool) {
 	}
 }
 
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// eventContext returns the context of the first hook handler argument
+// that has a "Context" field (e.g. [core.ModelEvent]) or a "Request" field
+// (e.g. [core.RequestEvent]).
+//
+// Returns nil if no context was found.
+func eventContext(args []any) context.Context {
+	for _, arg := range args {
+		v := reflect.Indirect(reflect.ValueOf(arg))
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+
+		if f, ok := v.Type().FieldByName("Context"); ok && f.Type == contextType {
+			if fv, err := v.FieldByIndexErr(f.Index); err == nil {
+				if ctx, _ := fv.Interface().(context.Context); ctx != nil {
+					return ctx
+				}
+			}
+		}
+
+		if f, ok := v.Type().FieldByName("Request"); ok {
+			if fv, err := v.FieldByIndexErr(f.Index); err == nil {
+				if r, _ := fv.Interface().(*http.Request); r != nil {
+					return r.Context()
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 func cronBinds(app core.App, loader *goja.Runtime, executors *vmsPool) {
 	loader.Set("cronAdd", func(jobId, cronExpr, handler string) {
 		pr := goja.MustCompile("", "{("+handler+").apply(undefined)}", true)
@@ -178,6 +213,35 @@ func routerBinds(app core.App, loader *goja.Runtime, executors *vmsPool) {
 	})
 }
 
+func adminUIBinds(app core.App, loader *goja.Runtime) {
+	type adminUIExtensionConfig struct {
+		Id     string
+		Label  string
+		Icon   string
+		Module string
+
+		// Dir is an optional path to a directory with the bundled extension files.
+		Dir string
+	}
+
+	loader.Set("adminUIExtensionAdd", func(config adminUIExtensionConfig) {
+		ext := apis.AdminUIExtension{
+			Id:     config.Id,
+			Label:  config.Label,
+			Icon:   config.Icon,
+			Module: config.Module,
+		}
+
+		if config.Dir != "" {
+			ext.Assets = os.DirFS(config.Dir)
+		}
+
+		if err := apis.RegisterAdminUIExtension(app, ext); err != nil {
+			panic("[adminUIExtensionAdd] " + err.Error())
+		}
+	})
+}
+
 func wrapHandlerFunc(executors *vmsPool, handler goja.Value) (func(*core.RequestEvent) error, error) {
 	if handler == nil {
 		return nil, errors.New("handler must be non-nil")
@@ -191,7 +255,7 @@ func wrapHandlerFunc(executors *vmsPool, handler goja.Value) (func(*core.Request
 		pr := goja.MustCompile("", "{("+handler.String()+").apply(undefined, __args)}", true)
 
 		wrappedHandler := func(e *core.RequestEvent) error {
-			return executors.run(func(executor *goja.Runtime) error {
+			return executors.runWithContext(e.Request.Context(), func(executor *goja.Runtime) error {
 				executor.Set("$app", e.App) // overwrite the global $app with the hook scoped instance
 				executor.Set("__args", []any{e})
 				res, err := executor.RunProgram(pr)
@@ -248,7 +312,7 @@ func wrapMiddlewares(executors *vmsPool, rawMiddlewares ...goja.Value) ([]*hook.
 				Id:       v.id,
 				Priority: v.priority,
 				Func: func(e *core.RequestEvent) error {
-					return executors.run(func(executor *goja.Runtime) error {
+					return executors.runWithContext(e.Request.Context(), func(executor *goja.Runtime) error {
 						executor.Set("$app", e.App) // overwrite the global $app with the hook scoped instance
 						executor.Set("__args", []any{e})
 						res, err := executor.RunProgram(pr)
@@ -270,7 +334,7 @@ func wrapMiddlewares(executors *vmsPool, rawMiddlewares ...goja.Value) ([]*hook.
 
 			wrappedMiddlewares[i] = &hook.Handler[*core.RequestEvent]{
 				Func: func(e *core.RequestEvent) error {
-					return executors.run(func(executor *goja.Runtime) error {
+					return executors.runWithContext(e.Request.Context(), func(executor *goja.Runtime) error {
 						executor.Set("$app", e.App) // overwrite the global $app with the hook scoped instance
 						executor.Set("__args", []any{e})
 						res, err := executor.RunProgram(pr)
@@ -475,6 +539,18 @@ func baseBinds(vm *goja.Runtime) {
 		instance := &core.PasswordField{}
 		return structConstructorUnmarshal(vm, call, instance)
 	})
+	vm.Set("SecretField", func(call goja.ConstructorCall) *goja.Object {
+		instance := &core.SecretField{}
+		return structConstructorUnmarshal(vm, call, instance)
+	})
+	vm.Set("GeoPointField", func(call goja.ConstructorCall) *goja.Object {
+		instance := &core.GeoPointField{}
+		return structConstructorUnmarshal(vm, call, instance)
+	})
+	vm.Set("ComputedField", func(call goja.ConstructorCall) *goja.Object {
+		instance := &core.ComputedField{}
+		return structConstructorUnmarshal(vm, call, instance)
+	})
 	vm.Set("DateField", func(call goja.ConstructorCall) *goja.Object {
 		instance := &core.DateField{}
 		return structConstructorUnmarshal(vm, call, instance)
@@ -499,6 +575,10 @@ func baseBinds(vm *goja.Runtime) {
 		instance := &core.FileField{}
 		return structConstructorUnmarshal(vm, call, instance)
 	})
+	vm.Set("VectorField", func(call goja.ConstructorCall) *goja.Object {
+		instance := &core.VectorField{}
+		return structConstructorUnmarshal(vm, call, instance)
+	})
 	// ---
 
 	vm.Set("MailerMessage", func(call goja.ConstructorCall) *goja.Object {
@@ -506,6 +586,11 @@ func baseBinds(vm *goja.Runtime) {
 		return structConstructor(vm, call, instance)
 	})
 
+	vm.Set("Notification", func(call goja.ConstructorCall) *goja.Object {
+		instance := &core.Notification{}
+		return structConstructor(vm, call, instance)
+	})
+
 	vm.Set("Command", func(call goja.ConstructorCall) *goja.Object {
 		instance := &cobra.Command{}
 		return structConstructor(vm, call, instance)
@@ -737,6 +822,7 @@ func apisBinds(vm *goja.Runtime) {
 	obj.Set("recordAuthResponse", apis.RecordAuthResponse)
 	obj.Set("enrichRecord", apis.EnrichRecord)
 	obj.Set("enrichRecords", apis.EnrichRecords)
+	obj.Set("isDryRun", apis.IsDryRun)
 
 	// api errors
 	registerFactoryAsConstructor(vm, "ApiError", router.NewApiError)
@@ -897,6 +983,82 @@ func httpClientBinds(vm *goja.Runtime) {
 	})
 }
 
+func aiBinds(app core.App, vm *goja.Runtime) {
+	obj := vm.NewObject()
+	vm.Set("$ai", obj)
+
+	// newClient returns a client based on the app AI settings
+	// or a custom one if the "baseURL" option is set.
+	newClient := func(params map[string]any) (*ai.Client, error) {
+		if baseURL := cast.ToString(params["baseURL"]); baseURL != "" {
+			return &ai.Client{
+				BaseURL: baseURL,
+				APIKey:  cast.ToString(params["apiKey"]),
+			}, nil
+		}
+
+		return app.NewAIClient()
+	}
+
+	newContext := func(params map[string]any) (context.Context, context.CancelFunc) {
+		timeout := cast.ToInt(params["timeout"])
+		if timeout <= 0 {
+			timeout = 120
+		}
+
+		return context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	}
+
+	embeddings := func(texts []string, params map[string]any) ([][]float64, error) {
+		client, err := newClient(params)
+		if err != nil {
+			return nil, err
+		}
+
+		if model := cast.ToString(params["model"]); model != "" {
+			client.EmbeddingModel = model
+		}
+
+		ctx, cancel := newContext(params)
+		defer cancel()
+
+		return client.Embeddings(ctx, texts)
+	}
+
+	obj.Set("embeddings", embeddings)
+
+	obj.Set("embedding", func(text string, params map[string]any) ([]float64, error) {
+		vectors, err := embeddings([]string{text}, params)
+		if err != nil {
+			return nil, err
+		}
+
+		return vectors[0], nil
+	})
+
+	obj.Set("complete", func(params map[string]any) (*ai.CompletionResponse, error) {
+		client, err := newClient(params)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+
+		req := &ai.CompletionRequest{}
+		if err := json.Unmarshal(raw, req); err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := newContext(params)
+		defer cancel()
+
+		return client.Complete(ctx, req)
+	})
+}
+
 // -------------------------------------------------------------------
 
 // registerFactoryAsConstructor registers the factory function as native JS constructor.
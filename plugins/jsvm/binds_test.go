@@ -43,7 +43,7 @@ func TestBaseBindsCount(t *testing.T) {
 	vm := goja.New()
 	baseBinds(vm)
 
-	testBindsCount(vm, "this", 32, t)
+	testBindsCount(vm, "this", 37, t)
 }
 
 func TestBaseBindsSleep(t *testing.T) {
@@ -374,6 +374,18 @@ func TestBaseBindsNamedFields(t *testing.T) {
 			"new PasswordField({name: 'test'})",
 			isType[*core.PasswordField],
 		},
+		{
+			"new SecretField({name: 'test'})",
+			isType[*core.SecretField],
+		},
+		{
+			"new GeoPointField({name: 'test'})",
+			isType[*core.GeoPointField],
+		},
+		{
+			"new ComputedField({name: 'test'})",
+			isType[*core.ComputedField],
+		},
 		{
 			"new DateField({name: 'test'})",
 			isType[*core.DateField],
@@ -398,6 +410,10 @@ func TestBaseBindsNamedFields(t *testing.T) {
 			"new FileField({name: 'test'})",
 			isType[*core.FileField],
 		},
+		{
+			"new VectorField({name: 'test'})",
+			isType[*core.VectorField],
+		},
 	}
 
 	for _, s := range scenarios {
@@ -998,7 +1014,7 @@ func TestApisBindsCount(t *testing.T) {
 	apisBinds(vm)
 
 	testBindsCount(vm, "this", 8, t)
-	testBindsCount(vm, "$apis", 11, t)
+	testBindsCount(vm, "$apis", 12, t)
 }
 
 func TestApisBindsApiError(t *testing.T) {
@@ -1340,6 +1356,109 @@ func TestHttpClientBindsSend(t *testing.T) {
 	}
 }
 
+func TestAiBindsCount(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	vm := goja.New()
+	aiBinds(app, vm)
+
+	testBindsCount(vm, "$ai", 3, t)
+}
+
+func TestAiBinds(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	var lastAuth, lastBody string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lastBody = string(body)
+		lastAuth = r.Header.Get("Authorization")
+
+		switch r.URL.Path {
+		case "/embeddings":
+			w.Write([]byte(`{"data":[{"index":0,"embedding":[0.1,0.2]},{"index":1,"embedding":[0.3,0.4]}]}`))
+		case "/chat/completions":
+			w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}],"usage":{"total_tokens":3}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	vm := goja.New()
+	baseBinds(vm)
+	aiBinds(app, vm)
+	vm.Set("testURL", server.URL)
+
+	// disabled AI settings
+	_, err := vm.RunString(`$ai.embedding("test")`)
+	if err == nil {
+		t.Fatal("Expected error for disabled AI settings")
+	}
+
+	app.Settings().AI.Enabled = true
+	app.Settings().AI.BaseURL = server.URL
+	app.Settings().AI.APIKey = "settings_key"
+	app.Settings().AI.EmbeddingModel = "settings_model"
+
+	t.Run("embeddings", func(t *testing.T) {
+		v, err := vm.RunString(`$ai.embeddings(["a", "b"])`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		raw, _ := json.Marshal(v.Export())
+		if string(raw) != "[[0.1,0.2],[0.3,0.4]]" {
+			t.Fatalf("Unexpected vectors %s", raw)
+		}
+
+		if lastAuth != "Bearer settings_key" || !strings.Contains(lastBody, `"model":"settings_model"`) {
+			t.Fatalf("Expected the app settings to be used, got %q %s", lastAuth, lastBody)
+		}
+	})
+
+	t.Run("embedding with custom client", func(t *testing.T) {
+		_, err := vm.RunString(`$ai.embedding("a", {baseURL: testURL, apiKey: "custom_key", model: "custom_model"})`)
+		// the test server always responds with 2 embeddings
+		if err == nil || !strings.Contains(err.Error(), "expected 1 embeddings") {
+			t.Fatalf("Expected embeddings count mismatch error, got %v", err)
+		}
+
+		if lastAuth != "Bearer custom_key" || !strings.Contains(lastBody, `"model":"custom_model"`) {
+			t.Fatalf("Expected the custom options to be used, got %q %s", lastAuth, lastBody)
+		}
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		_, err := vm.RunString(`
+			const res = $ai.complete({
+				model:       "test_model",
+				messages:    [{role: "user", content: "hi"}],
+				temperature: 0.5,
+				maxTokens:   10,
+				json:        true,
+			})
+
+			if (res.content != "hello" || res.finishReason != "stop" || res.usage.totalTokens != 3) {
+				throw new Error("Unexpected response: " + JSON.stringify(res))
+			}
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedBody := `{"max_tokens":10,"messages":[{"role":"user","content":"hi"}],"model":"test_model","response_format":{"type":"json_object"},"temperature":0.5}`
+		if lastBody != expectedBody {
+			t.Fatalf("Expected body\n%s\ngot\n%s", expectedBody, lastBody)
+		}
+	})
+}
+
 func TestCronBindsCount(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -1365,7 +1484,7 @@ func TestHooksBindsCount(t *testing.T) {
 	vm := goja.New()
 	hooksBinds(app, vm, nil)
 
-	testBindsCount(vm, "this", 82, t)
+	testBindsCount(vm, "this", 98, t)
 }
 
 func TestHooksBinds(t *testing.T) {
@@ -1536,6 +1655,72 @@ func TestRouterBinds(t *testing.T) {
 	}
 }
 
+func TestAdminUIBindsCount(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	vm := goja.New()
+	adminUIBinds(app, vm)
+
+	testBindsCount(vm, "this", 1, t)
+}
+
+func TestAdminUIBinds(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	dir := t.TempDir()
+
+	vm := goja.New()
+	baseBinds(vm)
+	vm.Set("dir", dir)
+	adminUIBinds(app, vm)
+
+	_, err := vm.RunString(`
+		adminUIExtensionAdd({
+			id:     "test1",
+			label:  "Test 1",
+			icon:   "ri-test-line",
+			module: "/custom/test1.js",
+		})
+
+		adminUIExtensionAdd({id: "test2", dir: dir})
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extensions := apis.AdminUIExtensions(app)
+	if len(extensions) != 2 {
+		t.Fatalf("Expected 2 extensions, got %d", len(extensions))
+	}
+
+	if extensions[0].Id != "test1" ||
+		extensions[0].Label != "Test 1" ||
+		extensions[0].Icon != "ri-test-line" ||
+		extensions[0].Module != "/custom/test1.js" ||
+		extensions[0].Assets != nil {
+		t.Fatalf("Unexpected first extension %v", extensions[0])
+	}
+
+	if extensions[1].Id != "test2" ||
+		extensions[1].Module != "/api/adminui/extensions/test2/index.js" ||
+		extensions[1].Assets == nil {
+		t.Fatalf("Unexpected second extension %v", extensions[1])
+	}
+
+	// duplicated id
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("Expected duplicated extension panic")
+			}
+		}()
+
+		vm.RunString(`adminUIExtensionAdd({id: "test1", module: "/test.js"})`)
+	}()
+}
+
 func TestFilepathBindsCount(t *testing.T) {
 	vm := goja.New()
 	filepathBinds(vm)
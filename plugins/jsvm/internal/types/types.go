@@ -106,6 +106,39 @@ declare function routerAdd(
  */
 declare function routerUse(...middlewares: Array<string|((e: core.RequestEvent) => void)|Middleware>): void;
 
+// -------------------------------------------------------------------
+// adminUIBinds
+// -------------------------------------------------------------------
+
+/**
+ * AdminUIExtensionAdd registers a custom admin UI page with its own sidebar menu entry.
+ *
+ * The page is rendered by a JS module that default exports a
+ * ` + "`function(el, ctx)`" + ` mount function.
+ *
+ * Example:
+ *
+ * ` + "```" + `js
+ * adminUIExtensionAdd({
+ *     id:    "dealers",
+ *     label: "Dealers",
+ *     icon:  "ri-store-2-line",
+ *     dir:   __hooks + "/dealers_ui", // served at /api/adminui/extensions/dealers/
+ * })
+ * ` + "```" + `
+ *
+ * _Note that this method is available only in pb_hooks context._
+ *
+ * @group PocketBase
+ */
+declare function adminUIExtensionAdd(config: {
+  id:      string,
+  label?:  string,
+  icon?:   string,
+  module?: string,
+  dir?:    string,
+}): void;
+
 // -------------------------------------------------------------------
 // baseBinds
 // -------------------------------------------------------------------
@@ -422,6 +455,16 @@ declare class PasswordField implements core.PasswordField {
   constructor(data?: Partial<core.PasswordField>)
 }
 
+interface SecretField extends core.SecretField{} // merge
+/**
+ * {@inheritDoc core.SecretField}
+ *
+ * @group PocketBase
+ */
+declare class SecretField implements core.SecretField {
+  constructor(data?: Partial<core.SecretField>)
+}
+
 interface DateField extends core.DateField{} // merge
 /**
  * {@inheritDoc core.DateField}
@@ -482,6 +525,36 @@ declare class FileField implements core.FileField {
   constructor(data?: Partial<core.FileField>)
 }
 
+interface VectorField extends core.VectorField{} // merge
+/**
+ * {@inheritDoc core.VectorField}
+ *
+ * @group PocketBase
+ */
+declare class VectorField implements core.VectorField {
+  constructor(data?: Partial<core.VectorField>)
+}
+
+interface GeoPointField extends core.GeoPointField{} // merge
+/**
+ * {@inheritDoc core.GeoPointField}
+ *
+ * @group PocketBase
+ */
+declare class GeoPointField implements core.GeoPointField {
+  constructor(data?: Partial<core.GeoPointField>)
+}
+
+interface ComputedField extends core.ComputedField{} // merge
+/**
+ * {@inheritDoc core.ComputedField}
+ *
+ * @group PocketBase
+ */
+declare class ComputedField implements core.ComputedField {
+  constructor(data?: Partial<core.ComputedField>)
+}
+
 interface MailerMessage extends mailer.Message{} // merge
 /**
  * MailerMessage defines a single email message.
@@ -1061,6 +1134,74 @@ declare namespace $http {
   };
 }
 
+/**
+ * ` + "`" + `$ai` + "`" + ` defines helpers for working with OpenAI compatible
+ * embeddings and chat completions APIs.
+ *
+ * By default the app AI settings are used. To use a different
+ * service, specify the ` + "`" + `baseURL` + "`" + ` and ` + "`" + `apiKey` + "`" + ` options.
+ *
+ * @group PocketBase
+ */
+declare namespace $ai {
+  interface baseOptions {
+    baseURL?: string,
+    apiKey?:  string,
+    model?:   string,
+    timeout?: number, // in seconds, default to 120
+  }
+
+  /**
+   * Generates the embedding vectors of the provided texts.
+   *
+   * Example:
+   *
+   * ` + "```" + `js
+   * const vectors = $ai.embeddings(["Hello", "World"])
+   * ` + "```" + `
+   */
+  function embeddings(texts: Array<string>, options?: baseOptions): Array<Array<number>>;
+
+  /**
+   * Generates the embedding vector of a single text.
+   *
+   * Example:
+   *
+   * ` + "```" + `js
+   * onRecordCreate((e) => {
+   *     e.record.set("embedding", $ai.embedding(e.record.get("content")))
+   *     e.next()
+   * }, "posts")
+   * ` + "```" + `
+   */
+  function embedding(text: string, options?: baseOptions): Array<number>;
+
+  /**
+   * Generates a chat completion.
+   *
+   * Example:
+   *
+   * ` + "```" + `js
+   * const res = $ai.complete({
+   *     messages: [
+   *         { role: "system", content: "Summarize the provided text in 1 sentence." },
+   *         { role: "user",   content: e.record.get("content") },
+   *     ],
+   * })
+   *
+   * console.log(res.content)      // the generated message
+   * console.log(res.finishReason) // eg. "stop"
+   * console.log(res.usage)        // the tokens usage
+   * ` + "```" + `
+   */
+  function complete(options: baseOptions & {
+    messages:     Array<{ role: string, content: string }>,
+    temperature?: number,
+    maxTokens?:   number,
+    json?:        boolean, // request JSON object response
+  }): ai.CompletionResponse;
+}
+
 // -------------------------------------------------------------------
 // migrate only
 // -------------------------------------------------------------------
@@ -1090,6 +1231,7 @@ func main() {
 			"github.com/pocketbase/pocketbase/tools/security":   {"*"},
 			"github.com/pocketbase/pocketbase/tools/filesystem": {"*"},
 			"github.com/pocketbase/pocketbase/tools/template":   {"*"},
+			"github.com/pocketbase/pocketbase/tools/ai":         {"*"},
 			"github.com/pocketbase/pocketbase/mails":            {"*"},
 			"github.com/pocketbase/pocketbase/apis":             {"*"},
 			"github.com/pocketbase/pocketbase/core":             {"*"},
@@ -268,6 +268,7 @@ func (p *plugin) registerHooks() error {
 		formsBinds(vm)
 		apisBinds(vm)
 		mailsBinds(vm)
+		aiBinds(p.app, vm)
 
 		vm.Set("$app", p.app)
 		vm.Set("$template", templateRegistry)
@@ -291,6 +292,7 @@ func (p *plugin) registerHooks() error {
 	hooksBinds(p.app, loader, executors)
 	cronBinds(p.app, loader, executors)
 	routerBinds(p.app, loader, executors)
+	adminUIBinds(p.app, loader)
 
 	for file, content := range files {
 		func() {
@@ -1,6 +1,7 @@
 package jsvm
 
 import (
+	"context"
 	"sync"
 
 	"github.com/dop251/goja"
@@ -71,3 +72,32 @@ func (p *vmsPool) run(call func(vm *goja.Runtime) error) error {
 
 	return execErr
 }
+
+// runWithContext is similar to run but also interrupts the vm execution
+// when ctx is cancelled (e.g. when the request client disconnects).
+func (p *vmsPool) runWithContext(ctx context.Context, call func(vm *goja.Runtime) error) error {
+	if ctx == nil || ctx.Done() == nil {
+		return p.run(call)
+	}
+
+	return p.run(func(vm *goja.Runtime) error {
+		interrupted := make(chan struct{})
+
+		stop := context.AfterFunc(ctx, func() {
+			vm.Interrupt(context.Cause(ctx))
+			close(interrupted)
+		})
+
+		defer func() {
+			if !stop() {
+				// wait for the interrupt to complete before clearing it
+				<-interrupted
+			}
+
+			// reset the interrupt flag so that the vm could be reused
+			vm.ClearInterrupt()
+		}()
+
+		return call(vm)
+	})
+}
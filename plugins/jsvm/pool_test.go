@@ -0,0 +1,97 @@
+package jsvm
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func TestPoolRunWithContext(t *testing.T) {
+	t.Parallel()
+
+	pool := newPool(1, goja.New)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	err := pool.runWithContext(ctx, func(vm *goja.Runtime) error {
+		_, err := vm.RunString(`while(true) {}`)
+		return err
+	})
+
+	var interruptErr *goja.InterruptedError
+	if !errors.As(err, &interruptErr) {
+		t.Fatalf("Expected InterruptedError, got %v", err)
+	}
+
+	// the pooled vm should be reusable
+	err = pool.runWithContext(context.Background(), func(vm *goja.Runtime) error {
+		_, err := vm.RunString(`1 + 1`)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Expected the vm to be reusable, got %v", err)
+	}
+
+	// nil context
+	//nolint:staticcheck
+	err = pool.runWithContext(nil, func(vm *goja.Runtime) error {
+		_, err := vm.RunString(`1 + 1`)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+}
+
+func TestEventContext(t *testing.T) {
+	t.Parallel()
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "test")
+
+	modelEvent := new(core.ModelEvent)
+	modelEvent.Context = ctx
+
+	recordEvent := new(core.RecordEvent)
+	recordEvent.Context = ctx
+
+	requestEvent := new(core.RequestEvent)
+	requestEvent.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+	recordRequestEvent := new(core.RecordRequestEvent)
+	recordRequestEvent.RequestEvent = requestEvent
+
+	scenarios := []struct {
+		name     string
+		args     []any
+		expected bool
+	}{
+		{"no args", nil, false},
+		{"non-struct args", []any{1, "a"}, false},
+		{"model event", []any{modelEvent}, true},
+		{"record event", []any{recordEvent}, true},
+		{"request event", []any{requestEvent}, true},
+		{"embedded request event", []any{recordRequestEvent}, true},
+		{"nil embedded request event", []any{new(core.RecordRequestEvent)}, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := eventContext(s.args)
+
+			if s.expected {
+				if result == nil || result.Value(ctxKey{}) != "test" {
+					t.Fatalf("Expected the event context, got %v", result)
+				}
+			} else if result != nil {
+				t.Fatalf("Expected nil context, got %v", result)
+			}
+		})
+	}
+}
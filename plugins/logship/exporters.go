@@ -0,0 +1,326 @@
+package logship
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/httpclient"
+	"github.com/spf13/cast"
+)
+
+var (
+	_ Exporter = (*HTTPExporter)(nil)
+	_ Exporter = (*LokiExporter)(nil)
+	_ Exporter = (*SyslogExporter)(nil)
+)
+
+// HTTPExporter sends the logs as JSON array to a generic HTTP endpoint, eg.:
+//
+//	[{"id":"...","created":"...","level":0,"message":"GET /api/health","data":{...}}]
+type HTTPExporter struct {
+	// Client is an optional HTTP client to use (default to [http.DefaultClient]).
+	//
+	// It could be also a [httpclient.Gateway] with retries and circuit breaker.
+	Client httpclient.Client
+
+	// Headers are optional extra request headers (e.g. "Authorization").
+	Headers map[string]string
+
+	// URL is the endpoint to POST the logs to.
+	URL string
+}
+
+// Export implements [Exporter] interface.
+func (exp *HTTPExporter) Export(ctx context.Context, logs []*core.Log) error {
+	body, err := json.Marshal(logs)
+	if err != nil {
+		return err
+	}
+
+	return post(ctx, exp.Client, exp.URL, exp.Headers, body)
+}
+
+// LokiExporter sends the logs to the Grafana Loki push API
+// (e.g. "http://localhost:3100/loki/api/v1/push").
+//
+// The log streams are labeled with the configured static labels,
+// the log level and the log "type" (if any).
+//
+// Each log line is a JSON object with the log message and data.
+type LokiExporter struct {
+	// Client is an optional HTTP client to use (default to [http.DefaultClient]).
+	//
+	// It could be also a [httpclient.Gateway] with retries and circuit breaker.
+	Client httpclient.Client
+
+	// Headers are optional extra request headers (e.g. "X-Scope-OrgID").
+	Headers map[string]string
+
+	// Labels are optional static stream labels (e.g. {"app": "myapp"}).
+	Labels map[string]string
+
+	// URL is the Loki push API endpoint.
+	URL string
+}
+
+// Export implements [Exporter] interface.
+func (exp *LokiExporter) Export(ctx context.Context, logs []*core.Log) error {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+
+	streams := []*stream{}
+	streamsByKey := map[string]*stream{}
+
+	for _, l := range logs {
+		level := levelName(l.Level)
+		logType := cast.ToString(l.Data["type"])
+
+		key := level + "|" + logType
+
+		s, ok := streamsByKey[key]
+		if !ok {
+			labels := make(map[string]string, len(exp.Labels)+2)
+			for k, v := range exp.Labels {
+				labels[k] = v
+			}
+			labels["level"] = level
+			if logType != "" {
+				labels["type"] = logType
+			}
+
+			s = &stream{Stream: labels}
+			streamsByKey[key] = s
+			streams = append(streams, s)
+		}
+
+		line, err := logLine(l)
+		if err != nil {
+			return err
+		}
+
+		s.Values = append(s.Values, [2]string{
+			strconv.FormatInt(l.Created.Time().UnixNano(), 10),
+			line,
+		})
+	}
+
+	body, err := json.Marshal(map[string]any{"streams": streams})
+	if err != nil {
+		return err
+	}
+
+	return post(ctx, exp.Client, exp.URL, exp.Headers, body)
+}
+
+// SyslogExporter sends the logs to a syslog server in RFC 5424 format.
+//
+// The TCP messages are framed with the octet-counting method (RFC 6587).
+type SyslogExporter struct {
+	// Network specifies the connection network ("udp", "tcp", "unix", etc.).
+	Network string
+
+	// Address specifies the syslog server address (e.g. "localhost:514").
+	Address string
+
+	// Tag specifies the syslog APP-NAME (default to the executable name).
+	Tag string
+
+	// Hostname specifies the syslog HOSTNAME (default to [os.Hostname]).
+	Hostname string
+
+	// Facility specifies the syslog facility code (default to 1, aka. user-level messages).
+	Facility int
+
+	conn net.Conn
+	mu   sync.Mutex
+}
+
+// Export implements [Exporter] interface.
+func (exp *SyslogExporter) Export(ctx context.Context, logs []*core.Log) error {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+
+	if exp.conn == nil {
+		dialer := &net.Dialer{}
+		conn, err := dialer.DialContext(ctx, exp.Network, exp.Address)
+		if err != nil {
+			return err
+		}
+		exp.conn = conn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		exp.conn.SetWriteDeadline(deadline)
+	}
+
+	stream := exp.Network != "udp" && exp.Network != "udp4" && exp.Network != "udp6" && exp.Network != "unixgram"
+
+	for _, l := range logs {
+		msg, err := exp.format(l)
+		if err != nil {
+			return err
+		}
+
+		if stream {
+			msg = strconv.Itoa(len(msg)) + " " + msg
+		}
+
+		if _, err := io.WriteString(exp.conn, msg); err != nil {
+			// reconnect on the next export
+			exp.conn.Close()
+			exp.conn = nil
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close closes the underlying syslog connection (if any).
+func (exp *SyslogExporter) Close() error {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+
+	if exp.conn == nil {
+		return nil
+	}
+
+	err := exp.conn.Close()
+	exp.conn = nil
+
+	return err
+}
+
+func (exp *SyslogExporter) format(l *core.Log) (string, error) {
+	facility := exp.Facility
+	if facility <= 0 {
+		facility = 1
+	}
+
+	hostname := exp.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	tag := exp.Tag
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+
+	line, err := logLine(l)
+	if err != nil {
+		return "", err
+	}
+
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	return fmt.Sprintf(
+		"<%d>1 %s %s %s %d - - %s\n",
+		facility*8+syslogSeverity(l.Level),
+		l.Created.Time().UTC().Format(time.RFC3339Nano),
+		syslogValue(hostname),
+		cutStr(syslogValue(tag), 48),
+		os.Getpid(),
+		line,
+	), nil
+}
+
+// -------------------------------------------------------------------
+
+func post(ctx context.Context, client httpclient.Client, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		resBody, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("unexpected response status %d: %s", res.StatusCode, resBody)
+	}
+
+	return nil
+}
+
+// logLine serializes the log message and data as single JSON object.
+func logLine(l *core.Log) (string, error) {
+	line := make(map[string]any, len(l.Data)+1)
+	for k, v := range l.Data {
+		line[k] = v
+	}
+	line["message"] = l.Message
+
+	raw, err := json.Marshal(line)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
+
+func levelName(level int) string {
+	return strings.ToLower(slog.Level(level).String())
+}
+
+func syslogSeverity(level int) int {
+	switch {
+	case level >= int(slog.LevelError):
+		return 3 // error
+	case level >= int(slog.LevelWarn):
+		return 4 // warning
+	case level >= int(slog.LevelInfo):
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
+
+// syslogValue normalizes the header field value to printable US-ASCII without spaces.
+func syslogValue(v string) string {
+	if v == "" {
+		return "-"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, v)
+}
+
+func cutStr(str string, max int) string {
+	if len(str) > max {
+		return str[:max]
+	}
+
+	return str
+}
@@ -0,0 +1,236 @@
+package logship_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/logship"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func testLogs(t *testing.T) []*core.Log {
+	created, err := types.ParseDateTime("2024-01-02 03:04:05.678Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l1 := &core.Log{Created: created, Message: "GET /api/health", Level: 0, Data: map[string]any{"type": "request", "status": 200}}
+	l1.Id = "log1"
+
+	l2 := &core.Log{Created: created, Message: "test error", Level: 8, Data: map[string]any{"error": "oops"}}
+	l2.Id = "log2"
+
+	return []*core.Log{l1, l2}
+}
+
+func TestHTTPExporter(t *testing.T) {
+	t.Parallel()
+
+	var body string
+	var authHeader string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		body = string(raw)
+		authHeader = r.Header.Get("Authorization")
+
+		if strings.Contains(r.URL.Path, "fail") {
+			w.WriteHeader(500)
+		}
+	}))
+	defer server.Close()
+
+	exp := &logship.HTTPExporter{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "test"},
+	}
+
+	if err := exp.Export(context.Background(), testLogs(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if authHeader != "test" {
+		t.Fatalf("Expected Authorization header %q, got %q", "test", authHeader)
+	}
+
+	expectedParts := []string{`"id":"log1"`, `"id":"log2"`, `"message":"GET /api/health"`, `"level":8`, `"data":{"error":"oops"}`}
+	for _, part := range expectedParts {
+		if !strings.Contains(body, part) {
+			t.Fatalf("Missing %s in\n%s", part, body)
+		}
+	}
+
+	failExp := &logship.HTTPExporter{URL: server.URL + "/fail"}
+	if err := failExp.Export(context.Background(), testLogs(t)); err == nil {
+		t.Fatal("Expected error status to fail the export")
+	}
+}
+
+func TestLokiExporter(t *testing.T) {
+	t.Parallel()
+
+	var payload struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	exp := &logship.LokiExporter{
+		URL:    server.URL,
+		Labels: map[string]string{"app": "test"},
+	}
+
+	if err := exp.Export(context.Background(), testLogs(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payload.Streams) != 2 {
+		t.Fatalf("Expected 2 streams, got %v", payload.Streams)
+	}
+
+	first := payload.Streams[0]
+	if first.Stream["app"] != "test" || first.Stream["level"] != "info" || first.Stream["type"] != "request" {
+		t.Fatalf("Invalid first stream labels %v", first.Stream)
+	}
+	if len(first.Values) != 1 || first.Values[0][0] != "1704164645678000000" {
+		t.Fatalf("Invalid first stream values %v", first.Values)
+	}
+	if first.Values[0][1] != `{"message":"GET /api/health","status":200,"type":"request"}` {
+		t.Fatalf("Invalid first stream line %s", first.Values[0][1])
+	}
+
+	second := payload.Streams[1]
+	if second.Stream["level"] != "error" || second.Stream["type"] != "" {
+		t.Fatalf("Invalid second stream labels %v", second.Stream)
+	}
+}
+
+var syslogRegex = regexp.MustCompile(`^<(\d+)>1 2024-01-02T03:04:05\.678Z myhost myapp \d+ - - (.+)$`)
+
+func TestSyslogExporterUDP(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	exp := &logship.SyslogExporter{
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Tag:      "myapp",
+		Hostname: "myhost",
+		Facility: 16,
+	}
+	defer exp.Close()
+
+	if err := exp.Export(context.Background(), testLogs(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		pri  string
+		line string
+	}{
+		{"134", `{"message":"GET /api/health","status":200,"type":"request"}`},
+		{"131", `{"error":"oops","message":"test error"}`},
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	buf := make([]byte, 2048)
+	for _, e := range expected {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		match := syslogRegex.FindStringSubmatch(strings.TrimSpace(string(buf[:n])))
+		if len(match) != 3 || match[1] != e.pri || match[2] != e.line {
+			t.Fatalf("Invalid syslog message %s", buf[:n])
+		}
+	}
+}
+
+func TestSyslogExporterTCP(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		for {
+			size, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+
+			var n int
+			if err := json.Unmarshal([]byte(strings.TrimSpace(size)), &n); err != nil {
+				return
+			}
+
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return
+			}
+
+			received <- string(msg)
+		}
+	}()
+
+	exp := &logship.SyslogExporter{
+		Network:  "tcp",
+		Address:  listener.Addr().String(),
+		Tag:      "myapp",
+		Hostname: "myhost",
+	}
+	defer exp.Close()
+
+	if err := exp.Export(context.Background(), testLogs(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pri := range []string{"14", "11"} {
+		select {
+		case msg := <-received:
+			match := syslogRegex.FindStringSubmatch(strings.TrimSpace(msg))
+			if len(match) != 3 || match[1] != pri {
+				t.Fatalf("Invalid syslog message %s", msg)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timeout waiting for the syslog message")
+		}
+	}
+}
@@ -0,0 +1,312 @@
+// Package logship implements forwarding of the app logs (requests, errors, etc.)
+// to external sinks like Loki, syslog or a generic HTTP endpoint.
+//
+// The logs are collected as they are persisted in the logs database
+// (aka. the logs must be enabled from the app settings) and are sent
+// in batches from a separate bounded queue for each exporter.
+// When a queue is full (e.g. the sink is unavailable) the new logs
+// are discarded instead of blocking the app.
+//
+// Example usage:
+//
+//	logship.MustRegister(app, logship.Config{
+//		Exporters: []logship.Exporter{
+//			&logship.LokiExporter{
+//				URL:    "http://localhost:3100/loki/api/v1/push",
+//				Labels: map[string]string{"app": "myapp"},
+//			},
+//			&logship.SyslogExporter{
+//				Network: "udp",
+//				Address: "localhost:514",
+//			},
+//		},
+//	})
+package logship
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+const (
+	DefaultBatchSize     = 100
+	DefaultQueueSize     = 10000
+	DefaultFlushInterval = 3 * time.Second
+	DefaultMaxRetries    = 3
+	DefaultTimeout       = 10 * time.Second
+)
+
+// Exporter defines a log sink.
+type Exporter interface {
+	// Export sends the provided batch of logs.
+	Export(ctx context.Context, logs []*core.Log) error
+}
+
+// ExporterFunc is an adapter to allow the use of ordinary functions as [Exporter].
+type ExporterFunc func(ctx context.Context, logs []*core.Log) error
+
+// Export implements [Exporter] interface.
+func (f ExporterFunc) Export(ctx context.Context, logs []*core.Log) error {
+	return f(ctx, logs)
+}
+
+// Config defines the config options of the logship plugin.
+type Config struct {
+	// Exporters is a list with the log sinks to forward the logs to.
+	Exporters []Exporter
+
+	// Filter is an optional function to exclude logs from shipping
+	// (e.g. to forward only the request logs or the errors).
+	//
+	// It should return false to skip the log.
+	Filter func(l *core.Log) bool
+
+	// BatchSize specifies the max number of logs to send per single export (default to 100).
+	BatchSize int
+
+	// QueueSize specifies the max number of pending logs per exporter (default to 10000).
+	//
+	// Logs exceeding the queue size are discarded.
+	QueueSize int
+
+	// FlushInterval specifies how often the pending logs are sent
+	// if a full batch is not reached (default to 3s).
+	FlushInterval time.Duration
+
+	// MaxRetries specifies how many times to retry a failed export
+	// with exponential backoff before discarding the batch
+	// (default to 3, set to negative value to disable the retries).
+	MaxRetries int
+
+	// Timeout specifies the max duration of a single export (default to 10s).
+	Timeout time.Duration
+}
+
+// MustRegister registers the logship plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the logship plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	if len(config.Exporters) == 0 {
+		return errors.New("logship: at least one exporter must be specified")
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+
+	if config.QueueSize < config.BatchSize {
+		config.QueueSize = config.BatchSize
+	}
+
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	} else if config.MaxRetries == 0 {
+		config.MaxRetries = DefaultMaxRetries
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	p := &plugin{config: config, done: make(chan struct{})}
+
+	for _, exporter := range config.Exporters {
+		s := &shipper{
+			exporter: exporter,
+			config:   &p.config,
+			notify:   make(chan struct{}, 1),
+		}
+		p.shippers = append(p.shippers, s)
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			s.run(p.done)
+		}()
+	}
+
+	app.OnModelCreate(core.LogsTableName).Bind(&hook.Handler[*core.ModelEvent]{
+		Id: "__pbLogShip__",
+		Func: func(e *core.ModelEvent) error {
+			if l, ok := e.Model.(*core.Log); ok {
+				p.push(l)
+			}
+
+			return e.Next()
+		},
+	})
+
+	// flush the pending logs on terminate
+	// (it is after the app logger handler to ensure that all logs are collected)
+	app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+		p.stop()
+
+		return e.Next()
+	})
+
+	return nil
+}
+
+type plugin struct {
+	done     chan struct{}
+	shippers []*shipper
+	config   Config
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+func (p *plugin) push(l *core.Log) {
+	if p.config.Filter != nil && !p.config.Filter(l) {
+		return
+	}
+
+	// the app logger reuses the same model for the entire batch
+	clone := &core.Log{
+		Created: l.Created,
+		Data:    l.Data,
+		Message: l.Message,
+		Level:   l.Level,
+	}
+	clone.Id = l.Id
+
+	for _, s := range p.shippers {
+		s.push(clone)
+	}
+}
+
+func (p *plugin) stop() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+		p.wg.Wait()
+	})
+}
+
+// -------------------------------------------------------------------
+
+type shipper struct {
+	exporter Exporter
+	config   *Config
+	notify   chan struct{}
+	pending  []*core.Log
+	dropped  int
+	mu       sync.Mutex
+}
+
+func (s *shipper) push(l *core.Log) {
+	s.mu.Lock()
+
+	if len(s.pending) >= s.config.QueueSize {
+		s.dropped++
+		s.mu.Unlock()
+		return
+	}
+
+	s.pending = append(s.pending, l)
+	full := len(s.pending) >= s.config.BatchSize
+
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// next extracts the next batch of pending logs (if any).
+func (s *shipper) next() ([]*core.Log, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := min(len(s.pending), s.config.BatchSize)
+
+	batch := make([]*core.Log, n)
+	copy(batch, s.pending)
+	s.pending = s.pending[n:]
+
+	dropped := s.dropped
+	s.dropped = 0
+
+	return batch, dropped
+}
+
+func (s *shipper) run(done <-chan struct{}) {
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			// last attempt to send the remaining logs
+			s.flush(done, false)
+			return
+		case <-ticker.C:
+			s.flush(done, true)
+		case <-s.notify:
+			s.flush(done, true)
+		}
+	}
+}
+
+func (s *shipper) flush(done <-chan struct{}, retry bool) {
+	for {
+		batch, dropped := s.next()
+
+		// note: the standard logger is used to prevent feedback loops
+		if dropped > 0 {
+			log.Printf("logship: the queue is full, %d log(s) were discarded\n", dropped)
+		}
+
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := s.export(done, batch, retry); err != nil {
+			log.Printf("logship: failed to export %d log(s): %v\n", len(batch), err)
+		}
+	}
+}
+
+func (s *shipper) export(done <-chan struct{}, batch []*core.Log, retry bool) error {
+	var err error
+
+	backoff := 500 * time.Millisecond
+
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+		err = s.exporter.Export(ctx, batch)
+		cancel()
+
+		if err == nil || !retry || attempt >= s.config.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-done:
+			retry = false
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
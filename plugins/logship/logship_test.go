@@ -0,0 +1,234 @@
+package logship_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/logship"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/logger"
+)
+
+type testExporter struct {
+	mu       sync.Mutex
+	logs     []*core.Log
+	calls    int
+	failures int
+	block    chan struct{}
+}
+
+func (exp *testExporter) Export(ctx context.Context, logs []*core.Log) error {
+	if exp.block != nil {
+		<-exp.block
+	}
+
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+
+	exp.calls++
+
+	if exp.failures > 0 {
+		exp.failures--
+		return errors.New("test failure")
+	}
+
+	exp.logs = append(exp.logs, logs...)
+
+	return nil
+}
+
+func (exp *testExporter) messages() []string {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+
+	result := make([]string, len(exp.logs))
+	for i, l := range exp.logs {
+		result[i] = l.Message
+	}
+
+	return result
+}
+
+func (exp *testExporter) totalCalls() int {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+
+	return exp.calls
+}
+
+// writeLogs persists the accumulated app logs.
+func writeLogs(t *testing.T, app core.App) {
+	h, ok := app.Logger().Handler().(*logger.BatchHandler)
+	if !ok {
+		t.Fatalf("Expected BatchHandler, got %T", app.Logger().Handler())
+	}
+
+	if err := h.WriteAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testApp(t *testing.T) *tests.TestApp {
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// enable the logs persistence
+	app.Settings().Logs.MaxDays = 1
+
+	return app
+}
+
+func waitFor(t *testing.T, check func() bool) {
+	for i := 0; i < 100; i++ {
+		if check() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatal("Timeout waiting for the condition")
+}
+
+func TestRegisterErrors(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := logship.Register(app, logship.Config{}); err == nil {
+		t.Fatal("Expected register error")
+	}
+}
+
+func TestShipping(t *testing.T) {
+	t.Parallel()
+
+	app := testApp(t)
+	defer app.Cleanup()
+
+	exp1 := &testExporter{}
+	exp2 := &testExporter{}
+
+	logship.MustRegister(app, logship.Config{
+		Exporters:     []logship.Exporter{exp1, exp2},
+		FlushInterval: 50 * time.Millisecond,
+		BatchSize:     2,
+		Filter: func(l *core.Log) bool {
+			return l.Message != "skip"
+		},
+	})
+
+	app.Logger().Info("a", "type", "request")
+	app.Logger().Error("skip")
+	app.Logger().Error("b", "error", "test")
+	app.Logger().Warn("c")
+	writeLogs(t, app)
+
+	for _, exp := range []*testExporter{exp1, exp2} {
+		waitFor(t, func() bool { return len(exp.messages()) == 3 })
+
+		messages := exp.messages()
+		if messages[0] != "a" || messages[1] != "b" || messages[2] != "c" {
+			t.Fatalf("Expected [a b c], got %v", messages)
+		}
+
+		exp.mu.Lock()
+		l := exp.logs[0]
+		if l.Id == "" || l.Created.IsZero() || l.Data["type"] != "request" || l.Level != 0 {
+			t.Fatalf("Invalid shipped log %#v", l)
+		}
+		if exp.logs[0] == exp.logs[1] {
+			t.Fatal("Expected separate log instances")
+		}
+		exp.mu.Unlock()
+	}
+}
+
+func TestShippingRetries(t *testing.T) {
+	t.Parallel()
+
+	app := testApp(t)
+	defer app.Cleanup()
+
+	exp := &testExporter{failures: 1}
+
+	logship.MustRegister(app, logship.Config{
+		Exporters:     []logship.Exporter{exp},
+		FlushInterval: 50 * time.Millisecond,
+		MaxRetries:    1,
+	})
+
+	app.Logger().Info("a")
+	writeLogs(t, app)
+
+	waitFor(t, func() bool { return len(exp.messages()) == 1 })
+
+	if calls := exp.totalCalls(); calls != 2 {
+		t.Fatalf("Expected 2 export calls, got %d", calls)
+	}
+}
+
+func TestShippingQueueLimit(t *testing.T) {
+	t.Parallel()
+
+	app := testApp(t)
+	defer app.Cleanup()
+
+	exp := &testExporter{block: make(chan struct{})}
+
+	logship.MustRegister(app, logship.Config{
+		Exporters:     []logship.Exporter{exp},
+		FlushInterval: 20 * time.Millisecond,
+		BatchSize:     1,
+		QueueSize:     2,
+	})
+
+	// the first log is taken by the blocked export
+	app.Logger().Info("1")
+	writeLogs(t, app)
+	time.Sleep(100 * time.Millisecond)
+
+	// only 2 of the next logs fit in the queue
+	app.Logger().Info("2")
+	app.Logger().Info("3")
+	app.Logger().Info("4")
+	writeLogs(t, app)
+
+	close(exp.block)
+
+	waitFor(t, func() bool { return len(exp.messages()) == 3 })
+	time.Sleep(100 * time.Millisecond)
+
+	messages := exp.messages()
+	if len(messages) != 3 || messages[0] != "1" || messages[1] != "2" || messages[2] != "3" {
+		t.Fatalf("Expected [1 2 3], got %v", messages)
+	}
+}
+
+func TestShippingOnTerminate(t *testing.T) {
+	t.Parallel()
+
+	app := testApp(t)
+
+	exp := &testExporter{}
+
+	logship.MustRegister(app, logship.Config{
+		Exporters:     []logship.Exporter{exp},
+		FlushInterval: time.Hour,
+	})
+
+	app.Logger().Info("a")
+	app.Logger().Info("b")
+
+	// should write the pending app logs and flush the shipping queue
+	app.Cleanup()
+
+	messages := exp.messages()
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 shipped logs, got %v", messages)
+	}
+}
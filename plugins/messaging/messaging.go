@@ -0,0 +1,530 @@
+// Package messaging implements a conversations and messages helper
+// module for building chat-like features.
+//
+// The plugin creates the following system collections:
+//
+//   - "_conversations" - the conversations with their members (relation to the configured auth collection)
+//   - "_messages" - the conversation messages
+//   - "_messageReceipts" - the last read message of each conversation member
+//
+// The collections API rules allow the auth records to access only the
+// conversations in which they are members and their messages, meaning that
+// the conversations and the new messages could be created and watched for
+// changes with the regular records API, e.g.:
+//
+//	pb.collection("_messages").subscribe("*", ..., { filter: "conversation = 'CONVERSATION_ID'" })
+//
+// The plugin also registers the following endpoints (available only for the conversation members):
+//
+//	GET  /api/messaging/conversations/{id}/messages?before=MESSAGE_ID&limit=50
+//	POST /api/messaging/conversations/{id}/typing
+//	POST /api/messaging/conversations/{id}/read      (body: {"message": "MESSAGE_ID"})
+//
+// The messages endpoint returns the conversation messages sorted from the
+// newest to the oldest and the next (older) page could be loaded by passing
+// the last returned message id as "before" query parameter.
+//
+// The typing and read events are sent as realtime messages to the other
+// conversation members that are subscribed to the "@conversations/{id}" topic, eg.:
+//
+//	{"action": "typing", "member": "MEMBER_ID"}
+//	{"action": "read", "member": "MEMBER_ID", "receipt": {...}}
+//
+// Example usage:
+//
+//	messaging.MustRegister(app, messaging.Config{
+//		AuthCollection: "users",
+//	})
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	CollectionNameConversations = "_conversations"
+	CollectionNameMessages      = "_messages"
+	CollectionNameReceipts      = "_messageReceipts"
+)
+
+// RealtimeTopicPrefix is the prefix of the conversation events realtime topics.
+const RealtimeTopicPrefix = "@conversations/"
+
+// Conversation realtime actions.
+const (
+	ActionTyping = "typing"
+	ActionRead   = "read"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Config defines the config options of the messaging plugin.
+type Config struct {
+	// AuthCollection specifies the name of the auth collection whose
+	// records could be conversation members (default to "users").
+	AuthCollection string
+}
+
+// MustRegister registers the messaging plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the messaging plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if p.config.AuthCollection == "" {
+		p.config.AuthCollection = "users"
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollections(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollections()
+		})
+	}
+
+	// keep the conversation last message date in sync so that
+	// the conversations could be sorted by their latest activity
+	p.app.OnRecordAfterCreateSuccess(CollectionNameMessages).BindFunc(func(e *core.RecordEvent) error {
+		conversation, err := e.App.FindRecordById(CollectionNameConversations, e.Record.GetString("conversation"))
+		if err == nil {
+			conversation.Set("lastMessageAt", e.Record.GetDateTime("created"))
+			err = e.App.Save(conversation)
+		}
+		if err != nil {
+			e.App.Logger().Error("Failed to update the conversation last message date", "messageId", e.Record.Id, "error", err.Error())
+		}
+
+		return e.Next()
+	})
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group("/api/messaging/conversations/{id}").Bind(apis.RequireAuth(p.config.AuthCollection))
+		sub.GET("/messages", p.listMessages)
+		sub.POST("/typing", p.typing)
+		sub.POST("/read", p.read)
+
+		return e.Next()
+	})
+
+	return nil
+}
+
+// IsMember checks whether the provided auth record is a member of the specified conversation.
+func IsMember(conversation *core.Record, auth *core.Record) bool {
+	if conversation == nil || auth == nil {
+		return false
+	}
+
+	membersField, _ := conversation.Collection().Fields.GetByName("members").(*core.RelationField)
+	if membersField == nil || membersField.CollectionId != auth.Collection().Id {
+		return false
+	}
+
+	return list.ExistInSlice(auth.Id, conversation.GetStringSlice("members"))
+}
+
+// SendMessage creates a new conversation message from the specified author
+// and returns the created "_messages" record.
+//
+// The author must be a member of the conversation.
+func SendMessage(app core.App, conversation *core.Record, author *core.Record, text string) (*core.Record, error) {
+	if !IsMember(conversation, author) {
+		return nil, errors.New("the message author must be a conversation member")
+	}
+
+	collection, err := app.FindCachedCollectionByNameOrId(CollectionNameMessages)
+	if err != nil {
+		return nil, err
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("conversation", conversation.Id)
+	record.Set("author", author.Id)
+	record.Set("text", text)
+
+	if err := app.Save(record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// MarkRead updates the last read conversation message of the specified member
+// and returns the related "_messageReceipts" record.
+func MarkRead(app core.App, conversation *core.Record, member *core.Record, message *core.Record) (*core.Record, error) {
+	if !IsMember(conversation, member) {
+		return nil, errors.New("the receipt member must be a conversation member")
+	}
+
+	if message == nil || message.GetString("conversation") != conversation.Id {
+		return nil, errors.New("the read message must be from the same conversation")
+	}
+
+	receipt, err := app.FindFirstRecordByFilter(
+		CollectionNameReceipts,
+		"conversation = {:conversation} && member = {:member}",
+		dbx.Params{"conversation": conversation.Id, "member": member.Id},
+	)
+	if err != nil {
+		collection, err := app.FindCachedCollectionByNameOrId(CollectionNameReceipts)
+		if err != nil {
+			return nil, err
+		}
+
+		receipt = core.NewRecord(collection)
+		receipt.Set("conversation", conversation.Id)
+		receipt.Set("member", member.Id)
+	}
+
+	receipt.Set("message", message.Id)
+	receipt.Set("readAt", types.NowDateTime())
+
+	if err := app.Save(receipt); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) ensureCollections() error {
+	authCollection, err := p.app.FindCollectionByNameOrId(p.config.AuthCollection)
+	if err != nil || !authCollection.IsAuth() {
+		return errors.New("messaging: missing or invalid auth collection " + p.config.AuthCollection)
+	}
+
+	conversations, err := p.app.FindCollectionByNameOrId(CollectionNameConversations)
+	if err != nil {
+		conversations = core.NewBaseCollection(CollectionNameConversations)
+		conversations.System = true
+		conversations.ListRule = types.Pointer("members.id ?= @request.auth.id")
+		conversations.ViewRule = types.Pointer("members.id ?= @request.auth.id")
+		conversations.CreateRule = types.Pointer("@request.auth.id != '' && members.id ?= @request.auth.id")
+		conversations.UpdateRule = types.Pointer("members.id ?= @request.auth.id")
+		conversations.Fields.Add(&core.TextField{
+			Name:   "title",
+			System: true,
+			Max:    255,
+		})
+		conversations.Fields.Add(&core.RelationField{
+			Name:         "members",
+			System:       true,
+			Required:     true,
+			CollectionId: authCollection.Id,
+			MaxSelect:    999,
+		})
+		conversations.Fields.Add(&core.DateField{
+			Name:   "lastMessageAt",
+			System: true,
+		})
+		conversations.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		conversations.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		conversations.AddIndex("idx_conversations_lastMessageAt", false, "lastMessageAt", "")
+
+		if err := p.app.Save(conversations); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameMessages); err != nil {
+		membersRule := "conversation.members.id ?= @request.auth.id"
+
+		messages := core.NewBaseCollection(CollectionNameMessages)
+		messages.System = true
+		messages.ListRule = types.Pointer(membersRule)
+		messages.ViewRule = types.Pointer(membersRule)
+		messages.CreateRule = types.Pointer(membersRule + " && author = @request.auth.id")
+		messages.UpdateRule = types.Pointer(membersRule + " && author = @request.auth.id && (@request.body.conversation:isset = false || @request.body.conversation = conversation) && (@request.body.author:isset = false || @request.body.author = author)")
+		messages.DeleteRule = types.Pointer("author = @request.auth.id")
+		messages.Fields.Add(&core.RelationField{
+			Name:          "conversation",
+			System:        true,
+			Required:      true,
+			CollectionId:  conversations.Id,
+			CascadeDelete: true,
+			MaxSelect:     1,
+		})
+		messages.Fields.Add(&core.RelationField{
+			Name:         "author",
+			System:       true,
+			CollectionId: authCollection.Id,
+			MaxSelect:    1,
+		})
+		messages.Fields.Add(&core.TextField{
+			Name:     "text",
+			System:   true,
+			Required: true,
+			Max:      10000,
+		})
+		messages.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		messages.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		// optimized for loading the latest conversation messages first
+		messages.AddIndex("idx_messages_conversation_created", false, "conversation, created, id", "")
+
+		if err := p.app.Save(messages); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameReceipts); err != nil {
+		receipts := core.NewBaseCollection(CollectionNameReceipts)
+		receipts.System = true
+		receipts.ListRule = types.Pointer("conversation.members.id ?= @request.auth.id")
+		receipts.ViewRule = types.Pointer("conversation.members.id ?= @request.auth.id")
+		receipts.Fields.Add(&core.RelationField{
+			Name:          "conversation",
+			System:        true,
+			Required:      true,
+			CollectionId:  conversations.Id,
+			CascadeDelete: true,
+			MaxSelect:     1,
+		})
+		receipts.Fields.Add(&core.RelationField{
+			Name:          "member",
+			System:        true,
+			Required:      true,
+			CollectionId:  authCollection.Id,
+			CascadeDelete: true,
+			MaxSelect:     1,
+		})
+		receipts.Fields.Add(&core.TextField{
+			Name:   "message",
+			System: true,
+		})
+		receipts.Fields.Add(&core.DateField{
+			Name:   "readAt",
+			System: true,
+		})
+		receipts.AddIndex("idx_messageReceipts_member", true, "conversation, member", "")
+
+		if err := p.app.Save(receipts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findConversation loads the request path conversation and ensures
+// that the current request auth record is a member of it.
+func (p *plugin) findConversation(e *core.RequestEvent) (*core.Record, error) {
+	conversation, err := e.App.FindRecordById(CollectionNameConversations, e.Request.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsMember(conversation, e.Auth) {
+		return nil, errors.New("the auth record is not a conversation member")
+	}
+
+	return conversation, nil
+}
+
+func (p *plugin) listMessages(e *core.RequestEvent) error {
+	conversation, err := p.findConversation(e)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	limit := DefaultLimit
+	if raw := e.Request.URL.Query().Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return e.BadRequestError("Invalid limit value.", err)
+		}
+		limit = min(limit, MaxLimit)
+	}
+
+	query := e.App.RecordQuery(CollectionNameMessages).
+		WithContext(e.Request.Context()).
+		AndWhere(dbx.HashExp{"conversation": conversation.Id}).
+		OrderBy("created DESC", "id DESC").
+		Limit(int64(limit) + 1) // +1 to check for more items
+
+	// keyset pagination based on the last loaded message
+	if before := e.Request.URL.Query().Get("before"); before != "" {
+		cursor, err := e.App.FindRecordById(CollectionNameMessages, before)
+		if err != nil || cursor.GetString("conversation") != conversation.Id {
+			return e.BadRequestError("Invalid before message cursor.", err)
+		}
+
+		query.AndWhere(dbx.NewExp(
+			"[[created]] < {:created} OR ([[created]] = {:created} AND [[id]] < {:id})",
+			dbx.Params{"created": cursor.GetString("created"), "id": cursor.Id},
+		))
+	}
+
+	records := []*core.Record{}
+	if err := query.All(&records); err != nil {
+		return e.BadRequestError("Failed to load the conversation messages.", err)
+	}
+
+	hasMore := len(records) > limit
+	if hasMore {
+		records = records[:limit]
+	}
+
+	if err := apis.EnrichRecords(e, records); err != nil {
+		return e.InternalServerError("Failed to enrich records", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"items":   records,
+		"hasMore": hasMore,
+	})
+}
+
+func (p *plugin) typing(e *core.RequestEvent) error {
+	conversation, err := p.findConversation(e)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	p.broadcast(conversation, e.Auth, map[string]any{
+		"action": ActionTyping,
+		"member": e.Auth.Id,
+	})
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+type readForm struct {
+	Message string `form:"message" json:"message"`
+}
+
+func (p *plugin) read(e *core.RequestEvent) error {
+	conversation, err := p.findConversation(e)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	form := new(readForm)
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	var message *core.Record
+
+	if form.Message == "" {
+		// default to the latest conversation message
+		latest, err := e.App.FindRecordsByFilter(
+			CollectionNameMessages,
+			"conversation = {:conversation}",
+			"-created,-id",
+			1,
+			0,
+			dbx.Params{"conversation": conversation.Id},
+		)
+		if err != nil || len(latest) == 0 {
+			return e.BadRequestError("The conversation doesn't have any messages.", err)
+		}
+		message = latest[0]
+	} else {
+		message, err = e.App.FindRecordById(CollectionNameMessages, form.Message)
+		if err != nil || message.GetString("conversation") != conversation.Id {
+			return e.BadRequestError("An error occurred while validating the submitted data.", validation.Errors{
+				"message": validation.NewError("validation_invalid_message", "Missing or invalid conversation message."),
+			})
+		}
+	}
+
+	receipt, err := MarkRead(e.App, conversation, e.Auth, message)
+	if err != nil {
+		return e.BadRequestError("Failed to mark the conversation as read.", err)
+	}
+
+	p.broadcast(conversation, e.Auth, map[string]any{
+		"action":  ActionRead,
+		"member":  e.Auth.Id,
+		"receipt": receipt,
+	})
+
+	return e.JSON(http.StatusOK, receipt)
+}
+
+// broadcast sends the provided data as realtime message to the
+// conversation topic subscribers (except the sender).
+func (p *plugin) broadcast(conversation *core.Record, sender *core.Record, data any) {
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		p.app.Logger().Error("Failed to serialize the conversation realtime message", "error", err.Error())
+		return
+	}
+
+	prefix := RealtimeTopicPrefix + conversation.Id + "?"
+
+	for _, client := range p.app.SubscriptionsBroker().Clients() {
+		subs := client.Subscriptions(prefix)
+		if len(subs) == 0 {
+			continue
+		}
+
+		auth, _ := client.Get(apis.RealtimeClientAuthKey).(*core.Record)
+		if !IsMember(conversation, auth) || auth.Id == sender.Id {
+			continue
+		}
+
+		for sub := range subs {
+			msg := subscriptions.Message{
+				Name: sub,
+				Data: rawData,
+			}
+
+			routine.FireAndForget(func() {
+				client.Send(msg)
+			})
+		}
+	}
+}
@@ -30,6 +30,15 @@ func (p *plugin) automigrateOnCollectionChange(e *core.CollectionRequestEvent) e
 		return err
 	}
 
+	// in a multi-instance setup only the leader node writes snapshots
+	if !core.IsLeaderNode(p.app) {
+		p.app.Logger().Warn(
+			"Automigrate snapshot skipped because the current node is not the cluster leader",
+			"collection", e.Collection.Name,
+		)
+		return nil
+	}
+
 	new, err := p.app.FindCollectionByNameOrId(e.Collection.Id)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return err
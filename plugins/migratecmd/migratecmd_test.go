@@ -35,9 +35,16 @@ migrate((app) => {
       "enabled": true
     },
     "authRule": "",
+    "authSession": {
+      "maxLifetime": 0,
+      "rotationInterval": 0,
+      "slidingRenewal": false,
+      "track": false
+    },
     "authToken": {
       "duration": 604800
     },
+    "captureChanges": false,
     "confirmEmailChangeTemplate": {
       "body": "<p>Hello,</p>\n<p>Click on the button below to confirm your new email address.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-email-change/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Confirm new email</a>\n</p>\n<p><i>If you didn't ask to change your email address, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
       "subject": "Confirm your {APP_NAME} new email address"
@@ -50,6 +57,7 @@ migrate((app) => {
     "fields": [
       {
         "autogeneratePattern": "[a-z0-9]{15}",
+        "defaultValue": "",
         "hidden": false,
         "id": "text@TEST_RANDOM",
         "max": 15,
@@ -77,6 +85,7 @@ migrate((app) => {
       },
       {
         "autogeneratePattern": "[a-zA-Z0-9]{50}",
+        "defaultValue": "",
         "hidden": true,
         "id": "text@TEST_RANDOM",
         "max": 60,
@@ -90,6 +99,7 @@ migrate((app) => {
         "type": "text"
       },
       {
+        "defaultValue": "",
         "exceptDomains": null,
         "hidden": false,
         "id": "email@TEST_RANDOM",
@@ -101,6 +111,7 @@ migrate((app) => {
         "type": "email"
       },
       {
+        "defaultValue": "",
         "hidden": false,
         "id": "bool@TEST_RANDOM",
         "name": "emailVisibility",
@@ -110,6 +121,7 @@ migrate((app) => {
         "type": "bool"
       },
       {
+        "defaultValue": "",
         "hidden": false,
         "id": "bool@TEST_RANDOM",
         "name": "verified",
@@ -122,6 +134,10 @@ migrate((app) => {
     "fileToken": {
       "duration": 180
     },
+    "guestAuth": {
+      "anonymousField": "",
+      "enabled": false
+    },
     "id": "@TEST_RANDOM",
     "indexes": [
       "create index test on new_name (id)",
@@ -129,6 +145,16 @@ migrate((app) => {
       "CREATE UNIQUE INDEX ` + "`" + `idx_email_@TEST_RANDOM` + "`" + ` ON ` + "`" + `new_name` + "`" + ` (` + "`" + `email` + "`" + `) WHERE ` + "`" + `email` + "`" + ` != ''"
     ],
     "listRule": "@request.auth.id != '' && 1 > 0 || 'backtick` + "`" + `test' = 0",
+    "magicLink": {
+      "emailTemplate": {
+        "body": "<p>Hello,</p>\n<p>Click on the button below to sign in to your {APP_NAME} account.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/auth/magic-link/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Sign in</a>\n</p>\n<p><i>If you didn't ask to sign in, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
+        "subject": "Sign in to {APP_NAME}"
+      },
+      "enabled": false
+    },
+    "magicLinkToken": {
+      "duration": 600
+    },
     "manageRule": "1 != 2",
     "mfa": {
       "duration": 1800,
@@ -167,6 +193,20 @@ migrate((app) => {
       "body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
       "subject": "Reset your {APP_NAME} password"
     },
+    "saml": {
+      "enabled": false,
+      "idpMetadata": "",
+      "mappedFields": null
+    },
+    "searchFields": [],
+    "smsOTP": {
+      "duration": 180,
+      "enabled": false,
+      "length": 6,
+      "maxRequests": 3,
+      "messageTemplate": "Your {APP_NAME} verification code is {OTP}.",
+      "phoneField": ""
+    },
     "system": true,
     "type": "auth",
     "updateRule": null,
@@ -211,9 +251,16 @@ func init() {
 				"enabled": true
 			},
 			"authRule": "",
+			"authSession": {
+				"maxLifetime": 0,
+				"rotationInterval": 0,
+				"slidingRenewal": false,
+				"track": false
+			},
 			"authToken": {
 				"duration": 604800
 			},
+			"captureChanges": false,
 			"confirmEmailChangeTemplate": {
 				"body": "<p>Hello,</p>\n<p>Click on the button below to confirm your new email address.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-email-change/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Confirm new email</a>\n</p>\n<p><i>If you didn't ask to change your email address, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
 				"subject": "Confirm your {APP_NAME} new email address"
@@ -226,6 +273,7 @@ func init() {
 			"fields": [
 				{
 					"autogeneratePattern": "[a-z0-9]{15}",
+					"defaultValue": "",
 					"hidden": false,
 					"id": "text@TEST_RANDOM",
 					"max": 15,
@@ -253,6 +301,7 @@ func init() {
 				},
 				{
 					"autogeneratePattern": "[a-zA-Z0-9]{50}",
+					"defaultValue": "",
 					"hidden": true,
 					"id": "text@TEST_RANDOM",
 					"max": 60,
@@ -266,6 +315,7 @@ func init() {
 					"type": "text"
 				},
 				{
+					"defaultValue": "",
 					"exceptDomains": null,
 					"hidden": false,
 					"id": "email@TEST_RANDOM",
@@ -277,6 +327,7 @@ func init() {
 					"type": "email"
 				},
 				{
+					"defaultValue": "",
 					"hidden": false,
 					"id": "bool@TEST_RANDOM",
 					"name": "emailVisibility",
@@ -286,6 +337,7 @@ func init() {
 					"type": "bool"
 				},
 				{
+					"defaultValue": "",
 					"hidden": false,
 					"id": "bool@TEST_RANDOM",
 					"name": "verified",
@@ -298,6 +350,10 @@ func init() {
 			"fileToken": {
 				"duration": 180
 			},
+			"guestAuth": {
+				"anonymousField": "",
+				"enabled": false
+			},
 			"id": "@TEST_RANDOM",
 			"indexes": [
 				"create index test on new_name (id)",
@@ -305,6 +361,16 @@ func init() {
 				"CREATE UNIQUE INDEX ` + "` + \"`\" + `" + `idx_email_@TEST_RANDOM` + "` + \"`\" + `" + ` ON ` + "` + \"`\" + `" + `new_name` + "` + \"`\" + `" + ` (` + "` + \"`\" + `" + `email` + "` + \"`\" + `" + `) WHERE ` + "` + \"`\" + `" + `email` + "` + \"`\" + `" + ` != ''"
 			],
 			"listRule": "@request.auth.id != '' && 1 > 0 || 'backtick` + "` + \"`\" + `" + `test' = 0",
+			"magicLink": {
+				"emailTemplate": {
+					"body": "<p>Hello,</p>\n<p>Click on the button below to sign in to your {APP_NAME} account.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/auth/magic-link/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Sign in</a>\n</p>\n<p><i>If you didn't ask to sign in, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
+					"subject": "Sign in to {APP_NAME}"
+				},
+				"enabled": false
+			},
+			"magicLinkToken": {
+				"duration": 600
+			},
 			"manageRule": "1 != 2",
 			"mfa": {
 				"duration": 1800,
@@ -343,6 +409,20 @@ func init() {
 				"body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
 				"subject": "Reset your {APP_NAME} password"
 			},
+			"saml": {
+				"enabled": false,
+				"idpMetadata": "",
+				"mappedFields": null
+			},
+			"searchFields": [],
+			"smsOTP": {
+				"duration": 180,
+				"enabled": false,
+				"length": 6,
+				"maxRequests": 3,
+				"messageTemplate": "Your {APP_NAME} verification code is {OTP}.",
+				"phoneField": ""
+			},
 			"system": true,
 			"type": "auth",
 			"updateRule": null,
@@ -476,9 +556,16 @@ migrate((app) => {
       "enabled": true
     },
     "authRule": "",
+    "authSession": {
+      "maxLifetime": 0,
+      "rotationInterval": 0,
+      "slidingRenewal": false,
+      "track": false
+    },
     "authToken": {
       "duration": 604800
     },
+    "captureChanges": false,
     "confirmEmailChangeTemplate": {
       "body": "<p>Hello,</p>\n<p>Click on the button below to confirm your new email address.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-email-change/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Confirm new email</a>\n</p>\n<p><i>If you didn't ask to change your email address, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
       "subject": "Confirm your {APP_NAME} new email address"
@@ -491,6 +578,7 @@ migrate((app) => {
     "fields": [
       {
         "autogeneratePattern": "[a-z0-9]{15}",
+        "defaultValue": "",
         "hidden": false,
         "id": "text@TEST_RANDOM",
         "max": 15,
@@ -518,6 +606,7 @@ migrate((app) => {
       },
       {
         "autogeneratePattern": "[a-zA-Z0-9]{50}",
+        "defaultValue": "",
         "hidden": true,
         "id": "text@TEST_RANDOM",
         "max": 60,
@@ -531,6 +620,7 @@ migrate((app) => {
         "type": "text"
       },
       {
+        "defaultValue": "",
         "exceptDomains": null,
         "hidden": false,
         "id": "email3885137012",
@@ -542,6 +632,7 @@ migrate((app) => {
         "type": "email"
       },
       {
+        "defaultValue": "",
         "hidden": false,
         "id": "bool@TEST_RANDOM",
         "name": "emailVisibility",
@@ -551,6 +642,7 @@ migrate((app) => {
         "type": "bool"
       },
       {
+        "defaultValue": "",
         "hidden": false,
         "id": "bool256245529",
         "name": "verified",
@@ -563,6 +655,10 @@ migrate((app) => {
     "fileToken": {
       "duration": 180
     },
+    "guestAuth": {
+      "anonymousField": "",
+      "enabled": false
+    },
     "id": "@TEST_RANDOM",
     "indexes": [
       "create index test on test123 (id)",
@@ -570,6 +666,16 @@ migrate((app) => {
       "CREATE UNIQUE INDEX ` + "`" + `idx_email_@TEST_RANDOM` + "`" + ` ON ` + "`" + `test123` + "`" + ` (` + "`" + `email` + "`" + `) WHERE ` + "`" + `email` + "`" + ` != ''"
     ],
     "listRule": "@request.auth.id != '' && 1 > 0 || 'backtick` + "`" + `test' = 0",
+    "magicLink": {
+      "emailTemplate": {
+        "body": "<p>Hello,</p>\n<p>Click on the button below to sign in to your {APP_NAME} account.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/auth/magic-link/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Sign in</a>\n</p>\n<p><i>If you didn't ask to sign in, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
+        "subject": "Sign in to {APP_NAME}"
+      },
+      "enabled": false
+    },
+    "magicLinkToken": {
+      "duration": 600
+    },
     "manageRule": "1 != 2",
     "mfa": {
       "duration": 1800,
@@ -608,6 +714,20 @@ migrate((app) => {
       "body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
       "subject": "Reset your {APP_NAME} password"
     },
+    "saml": {
+      "enabled": false,
+      "idpMetadata": "",
+      "mappedFields": null
+    },
+    "searchFields": [],
+    "smsOTP": {
+      "duration": 180,
+      "enabled": false,
+      "length": 6,
+      "maxRequests": 3,
+      "messageTemplate": "Your {APP_NAME} verification code is {OTP}.",
+      "phoneField": ""
+    },
     "system": false,
     "type": "auth",
     "updateRule": null,
@@ -655,9 +775,16 @@ func init() {
 				"enabled": true
 			},
 			"authRule": "",
+			"authSession": {
+				"maxLifetime": 0,
+				"rotationInterval": 0,
+				"slidingRenewal": false,
+				"track": false
+			},
 			"authToken": {
 				"duration": 604800
 			},
+			"captureChanges": false,
 			"confirmEmailChangeTemplate": {
 				"body": "<p>Hello,</p>\n<p>Click on the button below to confirm your new email address.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-email-change/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Confirm new email</a>\n</p>\n<p><i>If you didn't ask to change your email address, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
 				"subject": "Confirm your {APP_NAME} new email address"
@@ -670,6 +797,7 @@ func init() {
 			"fields": [
 				{
 					"autogeneratePattern": "[a-z0-9]{15}",
+					"defaultValue": "",
 					"hidden": false,
 					"id": "text@TEST_RANDOM",
 					"max": 15,
@@ -697,6 +825,7 @@ func init() {
 				},
 				{
 					"autogeneratePattern": "[a-zA-Z0-9]{50}",
+					"defaultValue": "",
 					"hidden": true,
 					"id": "text@TEST_RANDOM",
 					"max": 60,
@@ -710,6 +839,7 @@ func init() {
 					"type": "text"
 				},
 				{
+					"defaultValue": "",
 					"exceptDomains": null,
 					"hidden": false,
 					"id": "email3885137012",
@@ -721,6 +851,7 @@ func init() {
 					"type": "email"
 				},
 				{
+					"defaultValue": "",
 					"hidden": false,
 					"id": "bool@TEST_RANDOM",
 					"name": "emailVisibility",
@@ -730,6 +861,7 @@ func init() {
 					"type": "bool"
 				},
 				{
+					"defaultValue": "",
 					"hidden": false,
 					"id": "bool256245529",
 					"name": "verified",
@@ -742,6 +874,10 @@ func init() {
 			"fileToken": {
 				"duration": 180
 			},
+			"guestAuth": {
+				"anonymousField": "",
+				"enabled": false
+			},
 			"id": "@TEST_RANDOM",
 			"indexes": [
 				"create index test on test123 (id)",
@@ -749,6 +885,16 @@ func init() {
 				"CREATE UNIQUE INDEX ` + "` + \"`\" + `" + `idx_email_@TEST_RANDOM` + "` + \"`\" + `" + ` ON ` + "` + \"`\" + `" + `test123` + "` + \"`\" + `" + ` (` + "` + \"`\" + `" + `email` + "` + \"`\" + `" + `) WHERE ` + "` + \"`\" + `" + `email` + "` + \"`\" + `" + ` != ''"
 			],
 			"listRule": "@request.auth.id != '' && 1 > 0 || 'backtick` + "` + \"`\" + `" + `test' = 0",
+			"magicLink": {
+				"emailTemplate": {
+					"body": "<p>Hello,</p>\n<p>Click on the button below to sign in to your {APP_NAME} account.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/auth/magic-link/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Sign in</a>\n</p>\n<p><i>If you didn't ask to sign in, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
+					"subject": "Sign in to {APP_NAME}"
+				},
+				"enabled": false
+			},
+			"magicLinkToken": {
+				"duration": 600
+			},
 			"manageRule": "1 != 2",
 			"mfa": {
 				"duration": 1800,
@@ -787,6 +933,20 @@ func init() {
 				"body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
 				"subject": "Reset your {APP_NAME} password"
 			},
+			"saml": {
+				"enabled": false,
+				"idpMetadata": "",
+				"mappedFields": null
+			},
+			"searchFields": [],
+			"smsOTP": {
+				"duration": 180,
+				"enabled": false,
+				"length": 6,
+				"maxRequests": 3,
+				"messageTemplate": "Your {APP_NAME} verification code is {OTP}.",
+				"phoneField": ""
+			},
 			"system": false,
 			"type": "auth",
 			"updateRule": null,
@@ -923,6 +1083,7 @@ migrate((app) => {
   // add field
   collection.fields.addAt(8, new Field({
     "autogeneratePattern": "",
+    "defaultValue": "",
     "hidden": false,
     "id": "f4_id",
     "max": 0,
@@ -938,6 +1099,7 @@ migrate((app) => {
 
   // update field
   collection.fields.addAt(7, new Field({
+    "defaultValue": "",
     "hidden": false,
     "id": "f2_id",
     "max": null,
@@ -976,6 +1138,7 @@ migrate((app) => {
 
   // add field
   collection.fields.addAt(8, new Field({
+    "defaultValue": "",
     "hidden": false,
     "id": "f3_id",
     "name": "f3_name",
@@ -990,6 +1153,7 @@ migrate((app) => {
 
   // update field
   collection.fields.addAt(7, new Field({
+    "defaultValue": "",
     "hidden": false,
     "id": "f2_id",
     "max": null,
@@ -1054,6 +1218,7 @@ func init() {
 		// add field
 		if err := collection.Fields.AddMarshaledJSONAt(8, []byte(` + "`" + `{
 			"autogeneratePattern": "",
+			"defaultValue": "",
 			"hidden": false,
 			"id": "f4_id",
 			"max": 0,
@@ -1071,6 +1236,7 @@ func init() {
 
 		// update field
 		if err := collection.Fields.AddMarshaledJSONAt(7, []byte(` + "`" + `{
+			"defaultValue": "",
 			"hidden": false,
 			"id": "f2_id",
 			"max": null,
@@ -1116,6 +1282,7 @@ func init() {
 
 		// add field
 		if err := collection.Fields.AddMarshaledJSONAt(8, []byte(` + "`" + `{
+			"defaultValue": "",
 			"hidden": false,
 			"id": "f3_id",
 			"name": "f3_name",
@@ -1132,6 +1299,7 @@ func init() {
 
 		// update field
 		if err := collection.Fields.AddMarshaledJSONAt(7, []byte(` + "`" + `{
+			"defaultValue": "",
 			"hidden": false,
 			"id": "f2_id",
 			"max": null,
@@ -0,0 +1,466 @@
+// Package moderation implements automated content moderation of the
+// record text and image fields using one or more external providers
+// (OpenAI moderation, Perspective or any other custom [Provider] implementation).
+//
+// The plugin is attached to the [core.App.OnRecordContentModeration] hook,
+// which is triggered before the record save for the new or changed
+// text/editor values and for the newly uploaded files.
+//
+// Based on the highest provider score the content is resolved to one of
+// the following outcomes:
+//   - [core.ModerationOutcomeAllow] - the record is saved as usual
+//   - [core.ModerationOutcomeFlag] - the record is saved but marked as potentially unsafe
+//   - [core.ModerationOutcomeReview] - the record is saved and queued for manual review
+//   - [core.ModerationOutcomeBlock] - the record save fails with a validation error
+//
+// The outcome and the matched categories could be stored on the record
+// with the [Rule.StatusField] and [Rule.ReasonsField] options
+// (e.g. to hide the records queued for review with a "moderation = 'allow'" API rule).
+//
+// Example usage:
+//
+//	moderation.MustRegister(app, moderation.Config{
+//		Providers: []moderation.Provider{
+//			&moderation.OpenAIProvider{APIKey: "..."},
+//		},
+//		Rules: []moderation.Rule{
+//			{
+//				Collection:   "posts",
+//				Fields:       []string{"title", "content", "cover"},
+//				StatusField:  "moderation",
+//				ReasonsField: "moderationReasons",
+//			},
+//		},
+//	})
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+const (
+	// DefaultFlagThreshold is the default min score for the flag outcome.
+	DefaultFlagThreshold = 0.5
+
+	// DefaultReviewThreshold is the default min score for the review outcome.
+	DefaultReviewThreshold = 0.7
+
+	// DefaultBlockThreshold is the default min score for the block outcome.
+	DefaultBlockThreshold = 0.9
+
+	// DefaultTimeout is the default max duration of a single provider request.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultMaxImageSize is the default max size in bytes of an image sent for moderation.
+	DefaultMaxImageSize = 5 << 20
+
+	// ReasonProviderError is the reason added when a provider request fails.
+	ReasonProviderError = "provider_error"
+)
+
+// Image defines a single image sent for moderation.
+type Image struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Data        []byte `json:"data"`
+}
+
+// Content defines the record content sent for moderation.
+type Content struct {
+	// Texts is a map with the text values to moderate keyed by their field name.
+	Texts map[string]string `json:"texts"`
+
+	// Images is a map with the images to moderate keyed by their field name.
+	Images map[string][]*Image `json:"images"`
+}
+
+// IsEmpty reports whether there is no content to moderate.
+func (c *Content) IsEmpty() bool {
+	return len(c.Texts) == 0 && len(c.Images) == 0
+}
+
+// Verdict defines the result of a single provider moderation.
+type Verdict struct {
+	// Scores is a map with the provider categories and their score
+	// in the range [0, 1] (e.g. {"hate": 0.9, "violence": 0.01}).
+	Scores map[string]float64
+}
+
+// Provider defines an external content moderation service.
+type Provider interface {
+	// Moderate checks the provided content and returns its category scores.
+	//
+	// Providers that doesn't support images could ignore the [Content.Images].
+	Moderate(ctx context.Context, content *Content) (*Verdict, error)
+}
+
+// Rule defines how the content of a single collection is moderated.
+type Rule struct {
+	// Collection is the name or id of the collection to moderate.
+	Collection string
+
+	// Fields is an optional list with the text, editor and file fields
+	// to moderate (default to all).
+	//
+	// Only the png, jpeg, gif and webp files are sent for moderation.
+	Fields []string
+
+	// StatusField is an optional record field name where to store
+	// the resolved moderation outcome (usually a text or select field).
+	StatusField string
+
+	// ReasonsField is an optional record field name where to store
+	// the matched categories (usually a json or multiple select field).
+	ReasonsField string
+
+	// FlagThreshold is the min score for the flag outcome
+	// (default to [DefaultFlagThreshold]).
+	//
+	// Categories with score >= FlagThreshold are reported as reasons.
+	FlagThreshold float64
+
+	// ReviewThreshold is the min score for the review outcome
+	// (default to [DefaultReviewThreshold]).
+	ReviewThreshold float64
+
+	// BlockThreshold is the min score for the block outcome
+	// (default to [DefaultBlockThreshold]).
+	BlockThreshold float64
+}
+
+// outcome resolves the moderation outcome of the provided score.
+func (r *Rule) outcome(score float64) string {
+	switch {
+	case score >= r.BlockThreshold:
+		return core.ModerationOutcomeBlock
+	case score >= r.ReviewThreshold:
+		return core.ModerationOutcomeReview
+	case score >= r.FlagThreshold:
+		return core.ModerationOutcomeFlag
+	default:
+		return core.ModerationOutcomeAllow
+	}
+}
+
+// hasField reports whether the provided field should be moderated.
+func (r *Rule) hasField(name string) bool {
+	return len(r.Fields) == 0 || slices.Contains(r.Fields, name)
+}
+
+// Config defines the config options of the moderation plugin.
+type Config struct {
+	// Providers is a list with the moderation services to call.
+	//
+	// When multiple providers are specified, the content is resolved
+	// based on the highest score of all of them.
+	Providers []Provider
+
+	// Rules is a list with the collections to moderate.
+	Rules []Rule
+
+	// Timeout specifies the max duration of a single provider request
+	// (default to [DefaultTimeout]).
+	Timeout time.Duration
+
+	// MaxImageSize specifies the max size in bytes of an image sent
+	// for moderation (default to [DefaultMaxImageSize]).
+	//
+	// Larger images are skipped.
+	MaxImageSize int64
+
+	// OnError specifies the min outcome to use when a provider request fails
+	// (default to [core.ModerationOutcomeReview]).
+	OnError string
+}
+
+// MustRegister registers the moderation plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the moderation plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	if len(config.Providers) == 0 {
+		return errors.New("moderation: at least one provider must be specified")
+	}
+
+	if len(config.Rules) == 0 {
+		return errors.New("moderation: at least one rule must be specified")
+	}
+
+	// normalize a copy to avoid modifying the caller rules
+	config.Rules = slices.Clone(config.Rules)
+
+	for i := range config.Rules {
+		r := &config.Rules[i]
+
+		if r.Collection == "" {
+			return fmt.Errorf("moderation: invalid rule %d: Collection is required", i)
+		}
+
+		if r.FlagThreshold <= 0 {
+			r.FlagThreshold = DefaultFlagThreshold
+		}
+
+		if r.ReviewThreshold <= 0 {
+			r.ReviewThreshold = DefaultReviewThreshold
+		}
+
+		if r.BlockThreshold <= 0 {
+			r.BlockThreshold = DefaultBlockThreshold
+		}
+
+		if r.FlagThreshold > r.ReviewThreshold || r.ReviewThreshold > r.BlockThreshold {
+			return fmt.Errorf("moderation: invalid rule %d: the thresholds must be FlagThreshold <= ReviewThreshold <= BlockThreshold", i)
+		}
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	if config.MaxImageSize <= 0 {
+		config.MaxImageSize = DefaultMaxImageSize
+	}
+
+	if config.OnError == "" {
+		config.OnError = core.ModerationOutcomeReview
+	} else if outcomeWeight(config.OnError) < 0 {
+		return fmt.Errorf("moderation: invalid OnError outcome %q", config.OnError)
+	}
+
+	p := &plugin{app: app, config: config}
+
+	tags := make([]string, len(config.Rules))
+	for i, r := range config.Rules {
+		tags[i] = r.Collection
+	}
+
+	app.OnRecordContentModeration(tags...).Bind(&hook.Handler[*core.RecordContentModerationEvent]{
+		Func:     p.moderate,
+		Priority: -99,
+	})
+
+	return nil
+}
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+// findRule returns the rule of the provided collection (or nil if missing).
+func (p *plugin) findRule(collection *core.Collection) *Rule {
+	for i, r := range p.config.Rules {
+		if r.Collection == collection.Name || r.Collection == collection.Id {
+			return &p.config.Rules[i]
+		}
+	}
+
+	return nil
+}
+
+func (p *plugin) moderate(e *core.RecordContentModerationEvent) error {
+	rule := p.findRule(e.Record.Collection())
+	if rule == nil {
+		return e.Next()
+	}
+
+	content, err := p.content(rule, e)
+	if err != nil {
+		return err
+	}
+
+	if content.IsEmpty() {
+		return e.Next()
+	}
+
+	score, reasons, failed := p.check(e.Context, rule, content)
+
+	outcome := rule.outcome(score)
+	if failed && outcomeWeight(outcome) < outcomeWeight(p.config.OnError) {
+		outcome = p.config.OnError
+	}
+
+	// preserve a more restrictive outcome set by a previous handler
+	if outcomeWeight(outcome) > outcomeWeight(e.Outcome) {
+		e.Outcome = outcome
+	}
+
+	for _, reason := range reasons {
+		if !slices.Contains(e.Reasons, reason) {
+			e.Reasons = append(e.Reasons, reason)
+		}
+	}
+
+	if err := e.Next(); err != nil {
+		return err
+	}
+
+	if rule.StatusField != "" {
+		e.Record.Set(rule.StatusField, e.Outcome)
+	}
+
+	if rule.ReasonsField != "" {
+		e.Record.Set(rule.ReasonsField, e.Reasons)
+	}
+
+	return nil
+}
+
+// check calls the configured providers and returns their max score,
+// the categories with score >= [Rule.FlagThreshold] and whether any of the providers failed.
+func (p *plugin) check(ctx context.Context, rule *Rule, content *Content) (float64, []string, bool) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var maxScore float64
+	var failed bool
+	reasons := []string{}
+
+	for _, provider := range p.config.Providers {
+		verdict, err := p.callProvider(ctx, provider, content)
+		if err != nil {
+			failed = true
+			p.app.Logger().Error(
+				"[moderation] Provider request failed",
+				"provider", fmt.Sprintf("%T", provider),
+				"error", err.Error(),
+			)
+			continue
+		}
+
+		for category, score := range verdict.Scores {
+			maxScore = max(maxScore, score)
+
+			if score >= rule.FlagThreshold && !slices.Contains(reasons, category) {
+				reasons = append(reasons, category)
+			}
+		}
+	}
+
+	slices.Sort(reasons)
+
+	if failed {
+		reasons = append(reasons, ReasonProviderError)
+	}
+
+	return maxScore, reasons, failed
+}
+
+func (p *plugin) callProvider(ctx context.Context, provider Provider, content *Content) (*Verdict, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+
+	verdict, err := provider.Moderate(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if verdict == nil {
+		return &Verdict{}, nil
+	}
+
+	return verdict, nil
+}
+
+// content extracts the rule fields content from the moderation event.
+func (p *plugin) content(rule *Rule, e *core.RecordContentModerationEvent) (*Content, error) {
+	content := &Content{
+		Texts:  map[string]string{},
+		Images: map[string][]*Image{},
+	}
+
+	for name, text := range e.Texts {
+		if rule.hasField(name) {
+			content.Texts[name] = text
+		}
+	}
+
+	for name, files := range e.Files {
+		if !rule.hasField(name) {
+			continue
+		}
+
+		for _, f := range files {
+			img, err := p.readImage(f)
+			if err != nil {
+				return nil, fmt.Errorf("moderation: failed to read file %q: %w", f.OriginalName, err)
+			}
+
+			if img != nil {
+				content.Images[name] = append(content.Images[name], img)
+			}
+		}
+	}
+
+	return content, nil
+}
+
+// readImage loads the provided file content.
+//
+// Returns nil if the file is not a png, jpeg, gif or webp image or if it exceeds the max allowed image size.
+func (p *plugin) readImage(f *filesystem.File) (*Image, error) {
+	if f.Size > p.config.MaxImageSize {
+		return nil, nil
+	}
+
+	r, err := f.Reader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, p.config.MaxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > p.config.MaxImageSize {
+		return nil, nil
+	}
+
+	mt := mimetype.Detect(data)
+	if !mt.Is("image/png") && !mt.Is("image/jpeg") && !mt.Is("image/gif") && !mt.Is("image/webp") {
+		return nil, nil
+	}
+
+	return &Image{
+		Name:        f.Name,
+		ContentType: mt.String(),
+		Data:        data,
+	}, nil
+}
+
+// outcomeWeight returns the severity of the provided outcome
+// (or -1 for unknown outcome).
+func outcomeWeight(outcome string) int {
+	switch outcome {
+	case "":
+		return 0
+	case core.ModerationOutcomeAllow:
+		return 1
+	case core.ModerationOutcomeFlag:
+		return 2
+	case core.ModerationOutcomeReview:
+		return 3
+	case core.ModerationOutcomeBlock:
+		return 4
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,342 @@
+package moderation
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// 1x1 transparent png
+const testPNG = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="
+
+type testProvider struct {
+	mu       sync.Mutex
+	scores   map[string]float64
+	err      error
+	contents []*Content
+}
+
+func (tp *testProvider) Moderate(ctx context.Context, content *Content) (*Verdict, error) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	tp.contents = append(tp.contents, content)
+
+	if tp.err != nil {
+		return nil, tp.err
+	}
+
+	return &Verdict{Scores: tp.scores}, nil
+}
+
+func (tp *testProvider) lastContent() *Content {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	if len(tp.contents) == 0 {
+		return nil
+	}
+
+	return tp.contents[len(tp.contents)-1]
+}
+
+func TestRegisterErrors(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	provider := &testProvider{}
+
+	scenarios := []struct {
+		name   string
+		config Config
+	}{
+		{"missing providers", Config{Rules: []Rule{{Collection: "demo1"}}}},
+		{"missing rules", Config{Providers: []Provider{provider}}},
+		{"rule without collection", Config{Providers: []Provider{provider}, Rules: []Rule{{}}}},
+		{
+			"invalid thresholds order",
+			Config{Providers: []Provider{provider}, Rules: []Rule{{Collection: "demo1", FlagThreshold: 0.8, ReviewThreshold: 0.6}}},
+		},
+		{
+			"invalid OnError",
+			Config{Providers: []Provider{provider}, Rules: []Rule{{Collection: "demo1"}}, OnError: "invalid"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if err := Register(app, s.config); err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestRegisterDefaults(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	rules := []Rule{{Collection: "demo1"}}
+
+	err := Register(app, Config{
+		Providers: []Provider{&testProvider{}},
+		Rules:     rules,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rules[0].FlagThreshold != 0 {
+		t.Fatalf("Expected the caller rules to remain unchanged, got %v", rules[0])
+	}
+
+	if total := app.OnRecordContentModeration().Length(); total != 1 {
+		t.Fatalf("Expected 1 moderation handler, got %d", total)
+	}
+}
+
+func TestRuleOutcome(t *testing.T) {
+	rule := &Rule{FlagThreshold: 0.5, ReviewThreshold: 0.7, BlockThreshold: 0.9}
+
+	scenarios := []struct {
+		score    float64
+		expected string
+	}{
+		{0, core.ModerationOutcomeAllow},
+		{0.49, core.ModerationOutcomeAllow},
+		{0.5, core.ModerationOutcomeFlag},
+		{0.7, core.ModerationOutcomeReview},
+		{0.89, core.ModerationOutcomeReview},
+		{0.9, core.ModerationOutcomeBlock},
+		{1, core.ModerationOutcomeBlock},
+	}
+
+	for _, s := range scenarios {
+		if v := rule.outcome(s.score); v != s.expected {
+			t.Errorf("[%v] Expected outcome %q, got %q", s.score, s.expected, v)
+		}
+	}
+}
+
+func TestModeration(t *testing.T) {
+	png, err := base64.StdEncoding.DecodeString(testPNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name             string
+		scores           map[string]float64
+		providerErr      error
+		files            []string
+		expectError      bool
+		expectStatus     string
+		expectReasons    []string
+		expectTexts      []string
+		expectImages     int
+		expectCallsTotal int
+	}{
+		{
+			name:             "allow",
+			scores:           map[string]float64{"hate": 0.1, "spam": 0.2},
+			expectStatus:     core.ModerationOutcomeAllow,
+			expectReasons:    []string{},
+			expectTexts:      []string{"title"},
+			expectCallsTotal: 1,
+		},
+		{
+			name:             "flag",
+			scores:           map[string]float64{"hate": 0.1, "spam": 0.6},
+			expectStatus:     core.ModerationOutcomeFlag,
+			expectReasons:    []string{"spam"},
+			expectTexts:      []string{"title"},
+			expectCallsTotal: 1,
+		},
+		{
+			name:             "review",
+			scores:           map[string]float64{"hate": 0.75, "spam": 0.6},
+			expectStatus:     core.ModerationOutcomeReview,
+			expectReasons:    []string{"hate", "spam"},
+			expectTexts:      []string{"title"},
+			expectCallsTotal: 1,
+		},
+		{
+			name:             "block",
+			scores:           map[string]float64{"hate": 0.95},
+			expectError:      true,
+			expectTexts:      []string{"title"},
+			expectCallsTotal: 1,
+		},
+		{
+			name:             "provider error",
+			providerErr:      errors.New("test"),
+			expectStatus:     core.ModerationOutcomeReview,
+			expectReasons:    []string{ReasonProviderError},
+			expectTexts:      []string{"title"},
+			expectCallsTotal: 1,
+		},
+		{
+			name:             "image and non-image files",
+			scores:           map[string]float64{},
+			files:            []string{"image.png", "text.txt"},
+			expectStatus:     core.ModerationOutcomeAllow,
+			expectReasons:    []string{},
+			expectTexts:      []string{"title"},
+			expectImages:     1,
+			expectCallsTotal: 1,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			collection := createPostsCollection(t, app)
+
+			provider := &testProvider{scores: s.scores, err: s.providerErr}
+
+			MustRegister(app, Config{
+				Providers: []Provider{provider},
+				Rules: []Rule{{
+					Collection:   "posts",
+					Fields:       []string{"title", "files"},
+					StatusField:  "status",
+					ReasonsField: "reasons",
+				}},
+			})
+
+			record := core.NewRecord(collection)
+			record.Set("title", "test title")
+			record.Set("ignored", "test ignored")
+
+			files := make([]any, 0, len(s.files))
+			for _, name := range s.files {
+				data := []byte("test")
+				if name == "image.png" {
+					data = png
+				}
+
+				f, err := filesystem.NewFileFromBytes(data, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				files = append(files, f)
+			}
+			record.Set("files", files)
+
+			err := app.Save(record)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if len(provider.contents) != s.expectCallsTotal {
+				t.Fatalf("Expected %d provider calls, got %d", s.expectCallsTotal, len(provider.contents))
+			}
+
+			content := provider.lastContent()
+
+			texts := sortedKeys(content.Texts)
+			if !slices.Equal(texts, s.expectTexts) {
+				t.Fatalf("Expected texts %v, got %v", s.expectTexts, texts)
+			}
+
+			if total := len(content.Images["files"]); total != s.expectImages {
+				t.Fatalf("Expected %d images, got %d", s.expectImages, total)
+			}
+
+			if hasErr {
+				return
+			}
+
+			fresh, err := app.FindRecordById(collection, record.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if status := fresh.GetString("status"); status != s.expectStatus {
+				t.Fatalf("Expected status %q, got %q", s.expectStatus, status)
+			}
+
+			var reasons []string
+			if err := fresh.UnmarshalJSONField("reasons", &reasons); err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(reasons, s.expectReasons) {
+				t.Fatalf("Expected reasons %v, got %v", s.expectReasons, reasons)
+			}
+		})
+	}
+}
+
+func TestModerationUnchangedContent(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createPostsCollection(t, app)
+
+	provider := &testProvider{scores: map[string]float64{"spam": 0.6}}
+
+	MustRegister(app, Config{
+		Providers: []Provider{provider},
+		Rules: []Rule{{
+			Collection:  "posts",
+			Fields:      []string{"title"},
+			StatusField: "status",
+		}},
+	})
+
+	created := core.NewRecord(collection)
+	created.Set("title", "test")
+	if err := app.Save(created); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := app.FindRecordById(collection, created.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// not moderated field change
+	record.Set("ignored", "changed")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := len(provider.contents); total != 1 {
+		t.Fatalf("Expected 1 provider call, got %d", total)
+	}
+
+	if status := record.GetString("status"); status != core.ModerationOutcomeFlag {
+		t.Fatalf("Expected status %q, got %q", core.ModerationOutcomeFlag, status)
+	}
+}
+
+func createPostsCollection(t testing.TB, app core.App) *core.Collection {
+	collection := core.NewBaseCollection("posts")
+	collection.ListRule = types.Pointer("")
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.TextField{Name: "ignored"},
+		&core.FileField{Name: "files", MaxSelect: 99, MaxSize: 1 << 20},
+		&core.TextField{Name: "status"},
+		&core.JSONField{Name: "reasons"},
+	)
+
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	return collection
+}
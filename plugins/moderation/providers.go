@@ -0,0 +1,307 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var (
+	_ Provider = (*OpenAIProvider)(nil)
+	_ Provider = (*PerspectiveProvider)(nil)
+	_ Provider = (*HTTPProvider)(nil)
+)
+
+// DefaultOpenAIModel is the default OpenAI moderation model.
+const DefaultOpenAIModel = "omni-moderation-latest"
+
+// OpenAIProvider implements [Provider] for the OpenAI moderation API
+// (https://platform.openai.com/docs/api-reference/moderations).
+//
+// The texts are checked with a single request and
+// each image is checked with a separate request.
+type OpenAIProvider struct {
+	// APIKey is the OpenAI API key.
+	APIKey string
+
+	// Model is the moderation model to use (default to [DefaultOpenAIModel]).
+	Model string
+
+	// BaseURL is an optional custom API base url (default to "https://api.openai.com/v1").
+	BaseURL string
+
+	// Client is an optional custom HTTP client (default to [http.DefaultClient]).
+	Client *http.Client
+}
+
+// Moderate implements [Provider.Moderate].
+func (o *OpenAIProvider) Moderate(ctx context.Context, content *Content) (*Verdict, error) {
+	verdict := &Verdict{Scores: map[string]float64{}}
+
+	if len(content.Texts) > 0 {
+		texts := make([]string, 0, len(content.Texts))
+		for _, name := range sortedKeys(content.Texts) {
+			texts = append(texts, content.Texts[name])
+		}
+
+		if err := o.moderate(ctx, texts, verdict); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range sortedKeys(content.Images) {
+		for _, img := range content.Images[name] {
+			input := []map[string]any{{
+				"type": "image_url",
+				"image_url": map[string]any{
+					"url": "data:" + img.ContentType + ";base64," + base64.StdEncoding.EncodeToString(img.Data),
+				},
+			}}
+
+			if err := o.moderate(ctx, input, verdict); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return verdict, nil
+}
+
+func (o *OpenAIProvider) moderate(ctx context.Context, input any, verdict *Verdict) error {
+	model := o.Model
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+
+	baseURL := o.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+
+	raw, err := json.Marshal(map[string]any{
+		"model": model,
+		"input": input,
+	})
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "Bearer " + o.APIKey,
+	}
+
+	result := struct {
+		Results []struct {
+			CategoryScores map[string]float64 `json:"category_scores"`
+		} `json:"results"`
+	}{}
+
+	err = sendRequest(ctx, o.Client, strings.TrimSuffix(baseURL, "/")+"/moderations", headers, raw, &result)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range result.Results {
+		mergeScores(verdict.Scores, r.CategoryScores)
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+// DefaultPerspectiveAttributes is the default list of the requested Perspective attributes.
+var DefaultPerspectiveAttributes = []string{"TOXICITY"}
+
+// PerspectiveProvider implements [Provider] for the Perspective API
+// (https://developers.perspectiveapi.com/s/about-the-api-methods).
+//
+// Perspective supports only text moderation and each text is checked
+// with a separate request.
+//
+// The returned categories are the lowercased attribute names (e.g. "toxicity").
+type PerspectiveProvider struct {
+	// APIKey is the Google Cloud API key with enabled Perspective API.
+	APIKey string
+
+	// Attributes is a list with the attributes to request
+	// (default to [DefaultPerspectiveAttributes]).
+	Attributes []string
+
+	// BaseURL is an optional custom API base url
+	// (default to "https://commentanalyzer.googleapis.com/v1alpha1").
+	BaseURL string
+
+	// Client is an optional custom HTTP client (default to [http.DefaultClient]).
+	Client *http.Client
+}
+
+// Moderate implements [Provider.Moderate].
+func (p *PerspectiveProvider) Moderate(ctx context.Context, content *Content) (*Verdict, error) {
+	attributes := p.Attributes
+	if len(attributes) == 0 {
+		attributes = DefaultPerspectiveAttributes
+	}
+
+	baseURL := p.BaseURL
+	if baseURL == "" {
+		baseURL = "https://commentanalyzer.googleapis.com/v1alpha1"
+	}
+
+	requested := make(map[string]any, len(attributes))
+	for _, attr := range attributes {
+		requested[attr] = map[string]any{}
+	}
+
+	verdict := &Verdict{Scores: map[string]float64{}}
+
+	for _, name := range sortedKeys(content.Texts) {
+		raw, err := json.Marshal(map[string]any{
+			"comment":             map[string]any{"text": content.Texts[name]},
+			"requestedAttributes": requested,
+			"doNotStore":          true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		result := struct {
+			AttributeScores map[string]struct {
+				SummaryScore struct {
+					Value float64 `json:"value"`
+				} `json:"summaryScore"`
+			} `json:"attributeScores"`
+		}{}
+
+		endpoint := strings.TrimSuffix(baseURL, "/") + "/comments:analyze?key=" + url.QueryEscape(p.APIKey)
+
+		err = sendRequest(ctx, p.Client, endpoint, map[string]string{"Content-Type": "application/json"}, raw, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		scores := make(map[string]float64, len(result.AttributeScores))
+		for attr, score := range result.AttributeScores {
+			scores[strings.ToLower(attr)] = score.SummaryScore.Value
+		}
+		mergeScores(verdict.Scores, scores)
+	}
+
+	return verdict, nil
+}
+
+// -------------------------------------------------------------------
+
+// HTTPProvider implements [Provider] for a custom HTTP moderation service.
+//
+// The [Content] is sent as JSON POST request body, with the image data base64 encoded:
+//
+//	{
+//		"texts":  {"title": "..."},
+//		"images": {"cover": [{"name": "...", "contentType": "image/png", "data": "..."}]}
+//	}
+//
+// The service is expected to respond with the category scores:
+//
+//	{"scores": {"spam": 0.8, "nsfw": 0.1}}
+type HTTPProvider struct {
+	// URL is the moderation service endpoint.
+	URL string
+
+	// Headers is an optional map with extra request headers (e.g. "Authorization").
+	Headers map[string]string
+
+	// Client is an optional custom HTTP client (default to [http.DefaultClient]).
+	Client *http.Client
+}
+
+// Moderate implements [Provider.Moderate].
+func (h *HTTPProvider) Moderate(ctx context.Context, content *Content) (*Verdict, error) {
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	for k, v := range h.Headers {
+		headers[k] = v
+	}
+
+	result := struct {
+		Scores map[string]float64 `json:"scores"`
+	}{}
+
+	if err := sendRequest(ctx, h.Client, h.URL, headers, raw, &result); err != nil {
+		return nil, err
+	}
+
+	verdict := &Verdict{Scores: map[string]float64{}}
+	mergeScores(verdict.Scores, result.Scores)
+
+	return verdict, nil
+}
+
+// -------------------------------------------------------------------
+
+// mergeScores merges src into dst keeping the highest score of each category.
+func mergeScores(dst map[string]float64, src map[string]float64) {
+	for category, score := range src {
+		if current, ok := dst[category]; !ok || score > current {
+			dst[category] = score
+		}
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func sendRequest(
+	ctx context.Context,
+	client *http.Client,
+	endpoint string,
+	headers map[string]string,
+	body []byte,
+	result any,
+) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("POST %s failed with status %d: %s", req.URL.Path, res.StatusCode, msg)
+	}
+
+	return json.NewDecoder(res.Body).Decode(result)
+}
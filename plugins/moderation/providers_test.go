@@ -0,0 +1,169 @@
+package moderation
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type recordedRequest struct {
+	uri    string
+	header http.Header
+	body   string
+}
+
+func newTestServer(t testing.TB, status int, response string) (*httptest.Server, *[]recordedRequest) {
+	requests := []recordedRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		requests = append(requests, recordedRequest{
+			uri:    r.URL.RequestURI(),
+			header: r.Header.Clone(),
+			body:   string(body),
+		})
+
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func testContent() *Content {
+	return &Content{
+		Texts: map[string]string{"b": "text_b", "a": "text_a"},
+		Images: map[string][]*Image{
+			"cover": {{Name: "test.png", ContentType: "image/png", Data: []byte("test")}},
+		},
+	}
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	server, requests := newTestServer(t, 200, `{"results":[
+		{"flagged":false,"category_scores":{"hate":0.1,"violence":0.2}},
+		{"flagged":true,"category_scores":{"hate":0.8,"violence":0.1}}
+	]}`)
+
+	provider := &OpenAIProvider{APIKey: "test", BaseURL: server.URL + "/"}
+
+	verdict, err := provider.Moderate(context.Background(), testContent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("Expected 2 requests (texts + image), got %d", len(*requests))
+	}
+
+	for _, req := range *requests {
+		if req.uri != "/moderations" {
+			t.Fatalf("Unexpected request uri %s", req.uri)
+		}
+		if v := req.header.Get("Authorization"); v != "Bearer test" {
+			t.Fatalf("Expected Bearer authorization header, got %q", v)
+		}
+	}
+
+	textsReq := (*requests)[0]
+	if textsReq.body != `{"input":["text_a","text_b"],"model":"omni-moderation-latest"}` {
+		t.Fatalf("Unexpected texts body %s", textsReq.body)
+	}
+
+	imageReq := (*requests)[1]
+	if !strings.Contains(imageReq.body, `"url":"data:image/png;base64,dGVzdA=="`) {
+		t.Fatalf("Unexpected image body %s", imageReq.body)
+	}
+
+	if verdict.Scores["hate"] != 0.8 || verdict.Scores["violence"] != 0.2 {
+		t.Fatalf("Expected the max category scores, got %v", verdict.Scores)
+	}
+}
+
+func TestPerspectiveProvider(t *testing.T) {
+	server, requests := newTestServer(t, 200, `{"attributeScores":{
+		"TOXICITY":{"summaryScore":{"value":0.6,"type":"PROBABILITY"}},
+		"INSULT":{"summaryScore":{"value":0.3,"type":"PROBABILITY"}}
+	}}`)
+
+	provider := &PerspectiveProvider{
+		APIKey:     "test key",
+		Attributes: []string{"TOXICITY", "INSULT"},
+		BaseURL:    server.URL,
+	}
+
+	verdict, err := provider.Moderate(context.Background(), testContent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// images are ignored
+	if len(*requests) != 2 {
+		t.Fatalf("Expected 2 requests (1 for each text), got %d", len(*requests))
+	}
+
+	req := (*requests)[0]
+	if req.uri != "/comments:analyze?key=test+key" {
+		t.Fatalf("Unexpected request uri %s", req.uri)
+	}
+	for _, part := range []string{`"comment":{"text":"text_a"}`, `"INSULT":{}`, `"TOXICITY":{}`, `"doNotStore":true`} {
+		if !strings.Contains(req.body, part) {
+			t.Fatalf("Missing %s in body %s", part, req.body)
+		}
+	}
+
+	if verdict.Scores["toxicity"] != 0.6 || verdict.Scores["insult"] != 0.3 {
+		t.Fatalf("Unexpected scores %v", verdict.Scores)
+	}
+}
+
+func TestHTTPProvider(t *testing.T) {
+	server, requests := newTestServer(t, 200, `{"scores":{"spam":0.7}}`)
+
+	provider := &HTTPProvider{
+		URL:     server.URL + "/check",
+		Headers: map[string]string{"Authorization": "test"},
+	}
+
+	verdict, err := provider.Moderate(context.Background(), testContent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := (*requests)[0]
+	if req.uri != "/check" {
+		t.Fatalf("Unexpected request uri %s", req.uri)
+	}
+	if v := req.header.Get("Authorization"); v != "test" {
+		t.Fatalf("Expected custom authorization header, got %q", v)
+	}
+
+	expectedBody := `{"texts":{"a":"text_a","b":"text_b"},"images":{"cover":[{"name":"test.png","contentType":"image/png","data":"dGVzdA=="}]}}`
+	if req.body != expectedBody {
+		t.Fatalf("Expected body\n%s\ngot\n%s", expectedBody, req.body)
+	}
+
+	if verdict.Scores["spam"] != 0.7 {
+		t.Fatalf("Unexpected scores %v", verdict.Scores)
+	}
+}
+
+func TestSendRequestErrorStatus(t *testing.T) {
+	server, _ := newTestServer(t, 400, `{"error":"invalid"}`)
+
+	provider := &HTTPProvider{URL: server.URL}
+
+	_, err := provider.Moderate(context.Background(), testContent())
+	if err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Fatalf("Expected status 400 error, got %v", err)
+	}
+}
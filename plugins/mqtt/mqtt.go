@@ -0,0 +1,312 @@
+// Package mqtt implements a bridge between the PocketBase records and a MQTT broker.
+//
+// The record changes of the configured collections are published as
+// JSON messages to the "{prefix}/records/{collection}/{action}" topics.
+//
+// Record writes could be also submitted by publishing a message to the
+// "{prefix}/write/{collection}" topic. The messages are dispatched
+// through the regular records API so all API rules, validations and
+// hooks apply as if the request was sent over HTTP:
+//
+//	{
+//		"method":     "create", // create, update or delete
+//		"id":         "RECORD_ID", // required for update and delete
+//		"token":      "AUTH_RECORD_TOKEN", // optional
+//		"data":       {"title": "example"},
+//		"requestId":  "abc", // optional
+//		"replyTopic": "devices/123/reply" // optional topic to publish the result to
+//	}
+//
+// The plugin doesn't depend on a specific MQTT library.
+// Wrap your preferred MQTT client in the [Client] interface and pass it to the plugin config.
+//
+// Example usage:
+//
+//	mqtt.MustRegister(app, mqtt.Config{
+//		Client:              myClientAdapter,
+//		Collections:         []string{"readings"},
+//		WritableCollections: []string{"readings"},
+//	})
+package mqtt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+// MessageHandler defines a callback for an incoming MQTT message.
+type MessageHandler func(topic string, payload []byte)
+
+// Client defines the minimal MQTT client interface required by the plugin.
+//
+// It is expected that the client is already connected to the broker.
+type Client interface {
+	Publish(topic string, qos byte, retained bool, payload []byte) error
+	Subscribe(topic string, qos byte, handler MessageHandler) error
+	Unsubscribe(topics ...string) error
+}
+
+// Config defines the config options of the mqtt plugin.
+type Config struct {
+	// Client is the MQTT client used to publish and subscribe to topics.
+	Client Client
+
+	// TopicPrefix specifies the root topic level of the bridge (default to "pb").
+	TopicPrefix string
+
+	// QoS specifies the quality of service level of the published
+	// messages and subscriptions (default to 0).
+	QoS byte
+
+	// Collections is a list with the names of the collections whose
+	// record changes will be published.
+	Collections []string
+
+	// WritableCollections is a list with the names of the collections
+	// that accept record writes via MQTT messages.
+	WritableCollections []string
+}
+
+// RecordMessage is the payload of a published record change message.
+type RecordMessage struct {
+	Action string       `json:"action"`
+	Record *core.Record `json:"record"`
+}
+
+// WriteMessage is the payload of an incoming record write message.
+type WriteMessage struct {
+	Data       map[string]any `json:"data"`
+	Method     string         `json:"method"`
+	Id         string         `json:"id"`
+	Token      string         `json:"token"`
+	RequestId  string         `json:"requestId"`
+	ReplyTopic string         `json:"replyTopic"`
+}
+
+// ReplyMessage is the payload of a record write reply message.
+type ReplyMessage struct {
+	Body      json.RawMessage `json:"body"`
+	RequestId string          `json:"requestId"`
+	Status    int             `json:"status"`
+}
+
+// MustRegister registers the mqtt plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the mqtt plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if p.config.Client == nil {
+		return errors.New("mqtt: missing client")
+	}
+
+	if p.config.TopicPrefix == "" {
+		p.config.TopicPrefix = "pb"
+	}
+	p.config.TopicPrefix = strings.TrimSuffix(p.config.TopicPrefix, "/")
+
+	if len(p.config.Collections) > 0 {
+		p.app.OnRecordAfterCreateSuccess(p.config.Collections...).BindFunc(p.onRecordChange)
+		p.app.OnRecordAfterUpdateSuccess(p.config.Collections...).BindFunc(p.onRecordChange)
+		p.app.OnRecordAfterDeleteSuccess(p.config.Collections...).BindFunc(p.onRecordChange)
+	}
+
+	if len(p.config.WritableCollections) > 0 {
+		p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+			p.mu.Lock()
+			p.router = e.Router
+			p.mux = nil
+			p.mu.Unlock()
+
+			if err := p.config.Client.Subscribe(p.writeTopic("+"), p.config.QoS, p.onWriteMessage); err != nil {
+				return err
+			}
+
+			return e.Next()
+		})
+
+		p.app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+			if err := p.config.Client.Unsubscribe(p.writeTopic("+")); err != nil {
+				p.app.Logger().Debug("[mqtt] Failed to unsubscribe", slog.String("error", err.Error()))
+			}
+
+			return e.Next()
+		})
+	}
+
+	return nil
+}
+
+type plugin struct {
+	app    core.App
+	config Config
+	router *router.Router[*core.RequestEvent]
+	mux    http.Handler
+	mu     sync.Mutex
+}
+
+// recordTopic returns the topic of the record change messages.
+func (p *plugin) recordTopic(collection string, action string) string {
+	return p.config.TopicPrefix + "/records/" + collection + "/" + action
+}
+
+func (p *plugin) writeTopic(collection string) string {
+	return p.config.TopicPrefix + "/write/" + collection
+}
+
+func (p *plugin) onRecordChange(e *core.RecordEvent) error {
+	// publish a fresh copy to prevent changing the visibility of the original record
+	record := e.Record.Fresh()
+
+	payload, err := json.Marshal(RecordMessage{Action: e.Type, Record: record})
+	if err == nil {
+		err = p.config.Client.Publish(p.recordTopic(record.Collection().Name, e.Type), p.config.QoS, false, payload)
+	}
+
+	if err != nil {
+		p.app.Logger().Warn(
+			"[mqtt] Failed to publish record change",
+			slog.String("collectionName", record.Collection().Name),
+			slog.String("recordId", record.Id),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	return e.Next()
+}
+
+// handler returns the lazily built app router mux.
+//
+// The mux is built on the first write message to ensure that all
+// OnServe route and middleware registrations have completed.
+func (p *plugin) handler() (http.Handler, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.mux != nil {
+		return p.mux, nil
+	}
+
+	if p.router == nil {
+		return nil, errors.New("the app router is not initialized yet")
+	}
+
+	mux, err := p.router.BuildMux()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mux = mux
+
+	return mux, nil
+}
+
+func (p *plugin) onWriteMessage(topic string, payload []byte) {
+	collection := strings.TrimPrefix(topic, p.writeTopic(""))
+
+	if !list.ExistInSlice(collection, p.config.WritableCollections) {
+		return
+	}
+
+	msg := WriteMessage{}
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		p.app.Logger().Debug("[mqtt] Invalid write message", slog.String("topic", topic), slog.String("error", err.Error()))
+		return
+	}
+
+	status, body := p.dispatch(collection, msg)
+
+	if msg.ReplyTopic == "" {
+		return
+	}
+
+	// e.g. 204 response
+	if len(body) == 0 {
+		body = nil
+	}
+
+	reply, err := json.Marshal(ReplyMessage{
+		Body:      body,
+		RequestId: msg.RequestId,
+		Status:    status,
+	})
+	if err == nil {
+		err = p.config.Client.Publish(msg.ReplyTopic, p.config.QoS, false, reply)
+	}
+	if err != nil {
+		p.app.Logger().Warn("[mqtt] Failed to publish write reply", slog.String("topic", msg.ReplyTopic), slog.String("error", err.Error()))
+	}
+}
+
+// dispatch executes the write message as internal records API request.
+func (p *plugin) dispatch(collection string, msg WriteMessage) (int, []byte) {
+	path := "/api/collections/" + url.PathEscape(collection) + "/records"
+
+	var method string
+	switch msg.Method {
+	case "create":
+		method = http.MethodPost
+	case "update":
+		method = http.MethodPatch
+	case "delete":
+		method = http.MethodDelete
+	default:
+		return errorReply(http.StatusBadRequest, "Invalid or unsupported write method.")
+	}
+
+	if method != http.MethodPost {
+		if msg.Id == "" {
+			return errorReply(http.StatusBadRequest, "Missing record id.")
+		}
+		path += "/" + url.PathEscape(msg.Id)
+	}
+
+	handler, err := p.handler()
+	if err != nil {
+		p.app.Logger().Warn("[mqtt] Failed to build router mux", slog.String("error", err.Error()))
+		return errorReply(http.StatusServiceUnavailable, "The service is not available.")
+	}
+
+	rawData, err := json.Marshal(msg.Data)
+	if err != nil {
+		return errorReply(http.StatusBadRequest, "Invalid write data.")
+	}
+
+	req, err := http.NewRequest(method, path, bytes.NewReader(rawData))
+	if err != nil {
+		return errorReply(http.StatusBadRequest, "Invalid write request.")
+	}
+	req.RemoteAddr = "127.0.0.1:0"
+	req.Header.Set("content-type", "application/json")
+	if msg.Token != "" {
+		req.Header.Set("Authorization", msg.Token)
+	}
+
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	return rec.Code, rec.Body.Bytes()
+}
+
+func errorReply(status int, message string) (int, []byte) {
+	body, _ := json.Marshal(router.NewApiError(status, message, nil))
+	return status, body
+}
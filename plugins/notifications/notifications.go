@@ -0,0 +1,396 @@
+// Package notifications implements a built-in in-app notification center.
+//
+// The notifications are stored in the "_notifications" system collection
+// and each auth record could list, view and delete only its own
+// notifications with the regular records API, including the realtime
+// subscriptions (e.g. pb.collection("_notifications").subscribe("*", ...)).
+//
+// The plugin registers the following endpoints:
+//
+//	POST /api/notifications                 (superusers only)
+//	GET  /api/notifications/unread-count
+//	POST /api/notifications/read-all
+//	POST /api/notifications/{id}/read
+//
+// Example usage:
+//
+//	notifications.MustRegister(app, notifications.Config{
+//		// also send the notifications to the recipient devices
+//		// with app.Notify (e.g. when the push plugin is registered)
+//		Push: true,
+//	})
+//
+//	app.OnRecordAfterCreateSuccess("comments").BindFunc(func(e *core.RecordEvent) error {
+//		author, err := e.App.FindRecordById("users", e.Record.GetString("postAuthor"))
+//		if err == nil {
+//			_, err = notifications.Send(e.App, author, notifications.Message{
+//				Title: "New comment",
+//				Body:  e.Record.GetString("message"),
+//			})
+//		}
+//		if err != nil {
+//			e.App.Logger().Error("Failed to send comment notification", "error", err)
+//		}
+//
+//		return e.Next()
+//	})
+package notifications
+
+import (
+	"errors"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameNotifications = "_notifications"
+
+// maxRecipients is the max number of recipients allowed in a single create request.
+const maxRecipients = 1000
+
+// Config defines the config options of the notifications plugin.
+type Config struct {
+	// Push specifies whether to also deliver the sent notifications
+	// to the recipient devices with [core.App.Notify].
+	Push bool
+}
+
+// Message defines a single in-app notification content.
+type Message struct {
+	// Title is the notification title.
+	Title string `form:"title" json:"title"`
+
+	// Body is the optional notification text content.
+	Body string `form:"body" json:"body"`
+
+	// URL is an optional app url or path to open when the notification is clicked.
+	URL string `form:"url" json:"url"`
+
+	// Data is an optional custom notification data.
+	Data map[string]any `form:"data" json:"data"`
+}
+
+// Validate makes Message validatable by implementing [validation.Validatable] interface.
+func (m Message) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Title, validation.Required, validation.Length(1, 255)),
+		validation.Field(&m.Body, validation.Length(0, 5000)),
+		validation.Field(&m.URL, validation.Length(0, 2000)),
+	)
+}
+
+// MustRegister registers the notifications plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the notifications plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollection(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollection()
+		})
+	}
+
+	if p.config.Push {
+		p.app.OnRecordAfterCreateSuccess(CollectionNameNotifications).BindFunc(p.push)
+	}
+
+	// remove the notifications of the deleted auth records
+	p.app.OnRecordDeleteExecute().BindFunc(func(e *core.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		if !e.Record.Collection().IsAuth() {
+			return nil
+		}
+
+		_, err := e.App.DB().Delete(CollectionNameNotifications, dbx.HashExp{
+			"collectionRef": e.Record.Collection().Id,
+			"recordRef":     e.Record.Id,
+		}).Execute()
+
+		return err
+	})
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group("/api/notifications")
+		sub.POST("", p.create).Bind(apis.RequireSuperuserAuth())
+		sub.GET("/unread-count", p.unreadCount).Bind(apis.RequireAuth())
+		sub.POST("/read-all", p.readAll).Bind(apis.RequireAuth())
+		sub.POST("/{id}/read", p.read).Bind(apis.RequireAuth())
+
+		return e.Next()
+	})
+
+	return nil
+}
+
+// Send creates a new in-app notification for the specified auth record
+// and returns the created "_notifications" record.
+func Send(app core.App, recipient *core.Record, message Message) (*core.Record, error) {
+	if recipient == nil || !recipient.Collection().IsAuth() {
+		return nil, errors.New("the notification recipient must be an auth record")
+	}
+
+	if err := message.Validate(); err != nil {
+		return nil, err
+	}
+
+	collection, err := app.FindCachedCollectionByNameOrId(CollectionNameNotifications)
+	if err != nil {
+		return nil, err
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("collectionRef", recipient.Collection().Id)
+	record.Set("recordRef", recipient.Id)
+	record.Set("title", message.Title)
+	record.Set("body", message.Body)
+	record.Set("url", message.URL)
+	record.Set("data", message.Data)
+
+	if err := app.Save(record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// CountUnread returns the total number of the unread notifications of the specified auth record.
+func CountUnread(app core.App, recipient *core.Record) (int64, error) {
+	return app.CountRecords(CollectionNameNotifications, ownerExp(recipient), dbx.HashExp{"readAt": ""})
+}
+
+// MarkAllRead marks all unread notifications of the specified auth record as read.
+//
+// Note that the notifications are updated with a single query
+// and no record hooks or realtime events are triggered.
+func MarkAllRead(app core.App, recipient *core.Record) error {
+	_, err := app.DB().Update(
+		CollectionNameNotifications,
+		dbx.Params{"readAt": types.NowDateTime().String()},
+		dbx.And(ownerExp(recipient), dbx.HashExp{"readAt": ""}),
+	).Execute()
+
+	return err
+}
+
+func ownerExp(recipient *core.Record) dbx.Expression {
+	return dbx.HashExp{
+		"collectionRef": recipient.Collection().Id,
+		"recordRef":     recipient.Id,
+	}
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) ensureCollection() error {
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameNotifications); err == nil {
+		return nil // already created
+	}
+
+	// the auth record could list, view and delete only its own notifications
+	ownerRule := types.Pointer("@request.auth.id != '' && collectionRef = @request.auth.collectionId && recordRef = @request.auth.id")
+
+	col := core.NewBaseCollection(CollectionNameNotifications)
+	col.System = true
+	col.ListRule = ownerRule
+	col.ViewRule = ownerRule
+	col.DeleteRule = ownerRule
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "title",
+		System:   true,
+		Required: true,
+		Max:      255,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "body",
+		System: true,
+		Max:    5000,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "url",
+		System: true,
+		Max:    2000,
+	})
+	col.Fields.Add(&core.JSONField{
+		Name:   "data",
+		System: true,
+	})
+	col.Fields.Add(&core.DateField{
+		Name:   "readAt",
+		System: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.AddIndex("idx_notifications_owner", false, "collectionRef, recordRef, readAt", "")
+	col.AddIndex("idx_notifications_created", false, "created", "")
+
+	return p.app.Save(col)
+}
+
+// push delivers the created notification to the recipient devices.
+func (p *plugin) push(e *core.RecordEvent) error {
+	recipient, err := e.App.FindRecordById(e.Record.GetString("collectionRef"), e.Record.GetString("recordRef"))
+	if err == nil {
+		data := map[string]string{"notificationId": e.Record.Id}
+		if url := e.Record.GetString("url"); url != "" {
+			data["url"] = url
+		}
+
+		err = e.App.Notify(recipient, &core.Notification{
+			Title: e.Record.GetString("title"),
+			Body:  e.Record.GetString("body"),
+			Data:  data,
+		})
+	}
+	if err != nil && !errors.Is(err, core.ErrMissingNotifier) {
+		e.App.Logger().Error("Failed to push the notification", "id", e.Record.Id, "error", err.Error())
+	}
+
+	return e.Next()
+}
+
+type createForm struct {
+	Message
+
+	Collection string   `form:"collection" json:"collection"`
+	Records    []string `form:"records" json:"records"`
+}
+
+func (p *plugin) create(e *core.RequestEvent) error {
+	form := new(createForm)
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	var collection *core.Collection
+
+	err := validation.ValidateStruct(form,
+		validation.Field(&form.Message),
+		validation.Field(&form.Collection, validation.Required, validation.By(func(value any) error {
+			c, err := e.App.FindCachedCollectionByNameOrId(form.Collection)
+			if err != nil || !c.IsAuth() {
+				return validation.NewError("validation_invalid_auth_collection", "Missing or invalid auth collection.")
+			}
+			collection = c
+			return nil
+		})),
+		validation.Field(&form.Records, validation.Required, validation.Length(1, maxRecipients)),
+	)
+	if err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	recipients, err := e.App.FindRecordsByIds(collection, form.Records)
+	if err != nil {
+		return e.BadRequestError("Failed to load the notification recipients.", err)
+	}
+
+	if len(recipients) == 0 {
+		return e.BadRequestError("Missing notification recipients.", nil)
+	}
+
+	created := make([]*core.Record, 0, len(recipients))
+
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		for _, recipient := range recipients {
+			record, err := Send(txApp, recipient, form.Message)
+			if err != nil {
+				return err
+			}
+			created = append(created, record)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to create the notifications.", err)
+	}
+
+	return e.JSON(http.StatusOK, created)
+}
+
+func (p *plugin) unreadCount(e *core.RequestEvent) error {
+	total, err := CountUnread(e.App, e.Auth)
+	if err != nil {
+		return e.InternalServerError("Failed to count the unread notifications.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{"total": total})
+}
+
+func (p *plugin) readAll(e *core.RequestEvent) error {
+	if err := MarkAllRead(e.App, e.Auth); err != nil {
+		return e.BadRequestError("Failed to mark the notifications as read.", err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+func (p *plugin) read(e *core.RequestEvent) error {
+	record, err := e.App.FindFirstRecordByFilter(
+		CollectionNameNotifications,
+		"id = {:id} && collectionRef = {:collectionId} && recordRef = {:recordId}",
+		dbx.Params{
+			"id":           e.Request.PathValue("id"),
+			"collectionId": e.Auth.Collection().Id,
+			"recordId":     e.Auth.Id,
+		},
+	)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	if record.GetDateTime("readAt").IsZero() {
+		record.Set("readAt", types.NowDateTime())
+
+		// save with hooks so that the change is broadcasted to the realtime subscribers
+		if err := e.App.Save(record); err != nil {
+			return e.BadRequestError("Failed to mark the notification as read.", err)
+		}
+	}
+
+	if err := apis.EnrichRecord(e, record); err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	return e.JSON(http.StatusOK, record)
+}
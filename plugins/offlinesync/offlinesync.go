@@ -0,0 +1,797 @@
+// Package offlinesync implements a change feed and a mutations push API
+// for offline-first clients (e.g. mobile apps with a local database).
+//
+// Every create, update and delete of the synced collections records is
+// stored in the "_syncChanges" system collection journal with a monotonic
+// sequence number (only the latest change per record is kept and the
+// deleted records are kept as tombstones for the configured retention).
+//
+// The plugin registers the following endpoints:
+//
+//	GET  /api/sync/changes?since=0&limit=500&collections=posts,comments
+//	POST /api/sync/push  (body: {"mutations": [{"collection": "posts", "id": "...", "action": "upsert", "data": {...}, "baseSeq": 10}]})
+//
+// The changes feed returns the changes after the "since" checkpoint that
+// the current auth can view (tombstones are always included) and the new
+// checkpoint that the client should store for its next pull.
+// If the response "reset" flag is set, the client checkpoint is older than
+// the purged tombstones and the client must do a full resync (since=0).
+//
+// The pushed mutations are applied one by one with the regular collection
+// API rules checks. A mutation is in conflict if the server record was
+// changed after the mutation "baseSeq" (the record change seq known to
+// the client) and it is resolved with the collection [ConflictPolicy].
+//
+// Example usage:
+//
+//	offlinesync.MustRegister(app, offlinesync.Config{
+//		Collections: []offlinesync.CollectionConfig{
+//			{Name: "notes", Policy: offlinesync.PolicyLastWriteWins},
+//			{Name: "tasks", Policy: offlinesync.PolicyServerWins},
+//		},
+//	})
+package offlinesync
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+const CollectionNameSyncChanges = "_syncChanges"
+
+// ConflictPolicy defines how the conflicting client mutations are resolved.
+type ConflictPolicy string
+
+const (
+	// PolicyServerWins rejects the conflicting client mutations.
+	PolicyServerWins ConflictPolicy = "serverWins"
+
+	// PolicyClientWins always applies the client mutations.
+	PolicyClientWins ConflictPolicy = "clientWins"
+
+	// PolicyLastWriteWins applies the conflicting client mutation only
+	// if its timestamp is newer than the last server change.
+	PolicyLastWriteWins ConflictPolicy = "lastWriteWins"
+)
+
+// Mutation actions.
+const (
+	ActionUpsert = "upsert"
+	ActionDelete = "delete"
+)
+
+// Mutation result statuses.
+const (
+	StatusApplied  = "applied"
+	StatusConflict = "conflict"
+	StatusError    = "error"
+)
+
+const (
+	DefaultMaxMutations       = 100
+	DefaultLimit              = 500
+	MaxLimit                  = 1000
+	DefaultTombstoneRetention = 30 * 24 * time.Hour
+)
+
+const cleanupCronId = "__syncChangesCleanup__"
+
+// purgeMarkerRef is the recordRef of the special journal row that holds
+// the seq of the last purged tombstone.
+const purgeMarkerRef = "__purged__"
+
+// Mutation defines a single client record mutation.
+type Mutation struct {
+	Collection string         `form:"collection" json:"collection"`
+	Id         string         `form:"id" json:"id"`
+	Action     string         `form:"action" json:"action"`
+	Data       map[string]any `form:"data" json:"data"`
+
+	// BaseSeq is the record change seq that the mutation is based on (0 for new records).
+	BaseSeq int64 `form:"baseSeq" json:"baseSeq"`
+
+	// Timestamp is the client time of the mutation (used by [PolicyLastWriteWins]).
+	Timestamp types.DateTime `form:"timestamp" json:"timestamp"`
+}
+
+// Conflict defines the data of a single conflicting mutation.
+type Conflict struct {
+	App        core.App
+	Collection *core.Collection
+	Mutation   *Mutation
+
+	// ServerRecord is the current server record (nil if deleted).
+	ServerRecord *core.Record
+
+	// ServerSeq is the seq of the last server change of the record.
+	ServerSeq int64
+
+	// ServerChanged is the time of the last server change of the record.
+	ServerChanged types.DateTime
+}
+
+// CollectionConfig defines the sync options of a single collection.
+type CollectionConfig struct {
+	// Name is the name of the synced collection.
+	Name string
+
+	// Policy is the conflict resolution policy (default to [PolicyServerWins]).
+	Policy ConflictPolicy
+
+	// Resolve is an optional custom conflict resolver that takes precedence over the Policy.
+	//
+	// It should return the mutation data to apply (e.g. merged client and server fields)
+	// or nil to reject the mutation as conflict.
+	Resolve func(c *Conflict) (map[string]any, error)
+}
+
+// Config defines the config options of the offlinesync plugin.
+type Config struct {
+	// Collections is the list with the synced collections.
+	Collections []CollectionConfig
+
+	// MaxMutations is the max allowed number of mutations in a single push request (default to 100).
+	MaxMutations int
+
+	// TombstoneRetention specifies how long to keep the deleted
+	// records tombstones (default to 30 days).
+	TombstoneRetention time.Duration
+}
+
+// MustRegister registers the offlinesync plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the offlinesync plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config, collections: map[string]*CollectionConfig{}}
+
+	// clone to avoid modifying the original config
+	p.config.Collections = slices.Clone(config.Collections)
+
+	if len(p.config.Collections) == 0 {
+		return errors.New("offlinesync: at least one collection must be specified")
+	}
+
+	names := make([]string, 0, len(p.config.Collections))
+	for i, c := range p.config.Collections {
+		if c.Name == "" {
+			return errors.New("offlinesync: missing collection name")
+		}
+
+		switch c.Policy {
+		case "":
+			p.config.Collections[i].Policy = PolicyServerWins
+		case PolicyServerWins, PolicyClientWins, PolicyLastWriteWins:
+		default:
+			return fmt.Errorf("offlinesync: invalid %q collection policy %q", c.Name, c.Policy)
+		}
+
+		p.collections[c.Name] = &p.config.Collections[i]
+		names = append(names, c.Name)
+	}
+
+	if p.config.MaxMutations <= 0 {
+		p.config.MaxMutations = DefaultMaxMutations
+	}
+
+	if p.config.TombstoneRetention <= 0 {
+		p.config.TombstoneRetention = DefaultTombstoneRetention
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.init(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.init()
+		})
+	}
+
+	// journal the synced collections changes
+	p.app.OnRecordCreateExecute(names...).Bind(&hook.Handler[*core.RecordEvent]{
+		Func:     p.journalHandler(false),
+		Priority: 99,
+	})
+	p.app.OnRecordUpdateExecute(names...).Bind(&hook.Handler[*core.RecordEvent]{
+		Func:     p.journalHandler(false),
+		Priority: 99,
+	})
+	p.app.OnRecordDeleteExecute(names...).Bind(&hook.Handler[*core.RecordEvent]{
+		Func:     p.journalHandler(true),
+		Priority: 99,
+	})
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group("/api/sync")
+		sub.GET("/changes", p.changes)
+		sub.POST("/push", p.push)
+
+		return e.Next()
+	})
+
+	return p.app.Cron().Add(cleanupCronId, "45 * * * *", func() {
+		if err := PurgeTombstones(p.app, time.Now().Add(-p.config.TombstoneRetention)); err != nil {
+			p.app.Logger().Error("Failed to purge sync tombstones", "error", err.Error())
+		}
+	})
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app         core.App
+	config      Config
+	collections map[string]*CollectionConfig
+}
+
+func (p *plugin) init() error {
+	if err := p.ensureCollection(); err != nil {
+		return err
+	}
+
+	return p.backfill()
+}
+
+func (p *plugin) ensureCollection() error {
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameSyncChanges); err == nil {
+		return nil // already created
+	}
+
+	col := core.NewBaseCollection(CollectionNameSyncChanges)
+	col.System = true
+	col.Fields.Add(&core.NumberField{
+		Name:     "seq",
+		System:   true,
+		Required: true,
+		OnlyInt:  true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.BoolField{
+		Name:   "deleted",
+		System: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_syncChanges_seq", true, "seq", "")
+	col.AddIndex("idx_syncChanges_record", true, "collectionRef, recordRef", "")
+
+	return p.app.Save(col)
+}
+
+// backfill journals the existing synced collections records that
+// are not in the journal yet (e.g. created before the plugin registration).
+func (p *plugin) backfill() error {
+	return p.app.RunInTransaction(func(txApp core.App) error {
+		for _, c := range p.config.Collections {
+			collection, err := txApp.FindCollectionByNameOrId(c.Name)
+			if err != nil {
+				continue // not created yet
+			}
+
+			var ids []string
+
+			err = txApp.DB().Select("id").
+				From(collection.Name).
+				Where(dbx.NewExp(
+					"[[id]] NOT IN (SELECT [[recordRef]] FROM {{"+CollectionNameSyncChanges+"}} WHERE [[collectionRef]] = {:collectionId})",
+					dbx.Params{"collectionId": collection.Id},
+				)).
+				OrderBy("rowid ASC").
+				Column(&ids)
+			if err != nil {
+				return err
+			}
+
+			for _, id := range ids {
+				if err := journal(txApp, collection.Id, id, false); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (p *plugin) journalHandler(deleted bool) func(e *core.RecordEvent) error {
+	return func(e *core.RecordEvent) error {
+		originalApp := e.App
+		txErr := e.App.RunInTransaction(func(txApp core.App) error {
+			e.App = txApp
+
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return journal(txApp, e.Record.Collection().Id, e.Record.Id, deleted)
+		})
+		e.App = originalApp
+
+		return txErr
+	}
+}
+
+// journal upserts the record change journal row with a new seq.
+//
+// Expects to be called inside a transaction.
+func journal(txApp core.App, collectionId string, recordId string, deleted bool) error {
+	change, err := findChange(txApp, collectionId, recordId)
+	if err != nil {
+		collection, err := txApp.FindCachedCollectionByNameOrId(CollectionNameSyncChanges)
+		if err != nil {
+			return err
+		}
+
+		change = core.NewRecord(collection)
+		change.Set("collectionRef", collectionId)
+		change.Set("recordRef", recordId)
+	}
+
+	seq, err := lastSeq(txApp)
+	if err != nil {
+		return err
+	}
+
+	change.Set("seq", seq+1)
+	change.Set("deleted", deleted)
+
+	return txApp.Save(change)
+}
+
+func findChange(app core.App, collectionId string, recordId string) (*core.Record, error) {
+	return app.FindFirstRecordByFilter(
+		CollectionNameSyncChanges,
+		"collectionRef = {:collectionRef} && recordRef = {:recordRef}",
+		dbx.Params{"collectionRef": collectionId, "recordRef": recordId},
+	)
+}
+
+// lastSeq returns the highest journaled seq.
+//
+// The purge marker seq is also considered to prevent reusing the seq
+// of an already purged tombstone.
+func lastSeq(app core.App) (int64, error) {
+	var seq int64
+
+	err := app.DB().Select("COALESCE(MAX(MAX([[seq]]), -MIN([[seq]])), 0)").
+		From(CollectionNameSyncChanges).
+		Row(&seq)
+
+	return seq, err
+}
+
+// PurgeTombstones deletes the journaled tombstones older than the specified
+// date and stores the last purged seq in the purge marker row.
+//
+// Clients with a checkpoint before the last purged seq will be instructed
+// to reset their local state on the next changes pull.
+func PurgeTombstones(app core.App, olderThan time.Time) error {
+	return app.RunInTransaction(func(txApp core.App) error {
+		tombstones, err := txApp.FindAllRecords(
+			CollectionNameSyncChanges,
+			dbx.HashExp{"deleted": true},
+			dbx.NewExp("[[updated]] < {:date}", dbx.Params{"date": olderThan.UTC().Format(types.DefaultDateLayout)}),
+			dbx.NewExp("[[recordRef]] != {:marker}", dbx.Params{"marker": purgeMarkerRef}),
+		)
+		if err != nil || len(tombstones) == 0 {
+			return err
+		}
+
+		var purgedSeq int64
+		for _, t := range tombstones {
+			purgedSeq = max(purgedSeq, int64(t.GetInt("seq")))
+
+			if err := txApp.Delete(t); err != nil {
+				return err
+			}
+		}
+
+		marker, err := findChange(txApp, CollectionNameSyncChanges, purgeMarkerRef)
+		if err != nil {
+			collection, err := txApp.FindCachedCollectionByNameOrId(CollectionNameSyncChanges)
+			if err != nil {
+				return err
+			}
+
+			marker = core.NewRecord(collection)
+			marker.Set("collectionRef", CollectionNameSyncChanges)
+			marker.Set("recordRef", purgeMarkerRef)
+			marker.Set("deleted", true)
+		}
+
+		// the seq is stored as negative number to keep the seq index
+		// unique and the marker out of the changes feed
+		if -int64(marker.GetInt("seq")) >= purgedSeq {
+			return nil
+		}
+
+		marker.Set("seq", -purgedSeq)
+
+		return txApp.Save(marker)
+	})
+}
+
+// purgedSeq returns the seq of the last purged tombstone (if any).
+func purgedSeq(app core.App) int64 {
+	marker, err := findChange(app, CollectionNameSyncChanges, purgeMarkerRef)
+	if err != nil {
+		return 0
+	}
+
+	return -int64(marker.GetInt("seq"))
+}
+
+// -------------------------------------------------------------------
+
+// Change defines a single changes feed item.
+type Change struct {
+	Seq        int64  `json:"seq"`
+	Collection string `json:"collection"`
+	Id         string `json:"id"`
+	Action     string `json:"action"`
+
+	// Record is the current record data (nil for tombstones).
+	Record *core.Record `json:"record"`
+}
+
+func (p *plugin) changes(e *core.RequestEvent) error {
+	query := e.Request.URL.Query()
+
+	since := cast.ToInt64(query.Get("since"))
+
+	limit := cast.ToInt(query.Get("limit"))
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	collections := map[string]*core.Collection{} // indexed by id
+	for name := range p.collections {
+		rawFilter := query.Get("collections")
+		if rawFilter != "" && !slices.Contains(strings.Split(rawFilter, ","), name) {
+			continue
+		}
+
+		collection, err := e.App.FindCachedCollectionByNameOrId(name)
+		if err != nil {
+			continue
+		}
+
+		collections[collection.Id] = collection
+	}
+
+	checkpoint, err := lastSeq(e.App)
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	result := map[string]any{
+		"checkpoint": checkpoint,
+		"hasMore":    false,
+		"reset":      since > 0 && since < purgedSeq(e.App),
+		"changes":    []*Change{},
+	}
+
+	if len(collections) == 0 {
+		return e.JSON(http.StatusOK, result)
+	}
+
+	collectionIds := make([]any, 0, len(collections))
+	for id := range collections {
+		collectionIds = append(collectionIds, id)
+	}
+
+	rows := []*core.Record{}
+
+	err = e.App.RecordQuery(CollectionNameSyncChanges).
+		AndWhere(dbx.NewExp("[[seq]] > {:since}", dbx.Params{"since": since})).
+		AndWhere(dbx.In("collectionRef", collectionIds...)).
+		OrderBy("seq ASC").
+		Limit(int64(limit + 1)).
+		All(&rows)
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+		result["hasMore"] = true
+		result["checkpoint"] = int64(rows[len(rows)-1].GetInt("seq"))
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	changes := make([]*Change, 0, len(rows))
+	grouped := map[string][]*core.Record{}
+
+	for _, row := range rows {
+		collection, ok := collections[row.GetString("collectionRef")]
+		if !ok {
+			continue
+		}
+
+		change := &Change{
+			Seq:        int64(row.GetInt("seq")),
+			Collection: collection.Name,
+			Id:         row.GetString("recordRef"),
+			Action:     ActionUpsert,
+		}
+
+		if row.GetBool("deleted") {
+			change.Action = ActionDelete
+			changes = append(changes, change)
+			continue
+		}
+
+		record, err := e.App.FindRecordById(collection, change.Id)
+		if err != nil {
+			continue // deleted in the meantime
+		}
+
+		if ok, _ := e.App.CanAccessRecord(record, requestInfo, collection.ViewRule); !ok {
+			continue
+		}
+
+		change.Record = record
+		changes = append(changes, change)
+		grouped[collection.Id] = append(grouped[collection.Id], record)
+	}
+
+	for _, records := range grouped {
+		if err := apis.EnrichRecords(e, records); err != nil {
+			return e.InternalServerError("Failed to enrich the changed records.", err)
+		}
+	}
+
+	result["changes"] = changes
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// -------------------------------------------------------------------
+
+type pushForm struct {
+	Mutations []*Mutation `form:"mutations" json:"mutations"`
+}
+
+// MutationResult defines the result of a single pushed mutation.
+type MutationResult struct {
+	Collection string `json:"collection"`
+	Id         string `json:"id"`
+	Status     string `json:"status"`
+
+	// Seq is the current record change seq (if any).
+	Seq int64 `json:"seq"`
+
+	// Record is the applied record data or the current server record
+	// data in case of a conflict (nil if deleted or not accessible).
+	Record any `json:"record"`
+
+	// Error is the mutation apply error (if any).
+	Error *router.ApiError `json:"error,omitempty"`
+}
+
+func (p *plugin) push(e *core.RequestEvent) error {
+	form := &pushForm{}
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	err := validation.ValidateStruct(form,
+		validation.Field(&form.Mutations, validation.Required, validation.Length(1, p.config.MaxMutations)),
+	)
+	if err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	results := make([]*MutationResult, len(form.Mutations))
+	for i, m := range form.Mutations {
+		results[i] = p.applyMutation(e, m)
+	}
+
+	checkpoint, err := lastSeq(e.App)
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"checkpoint": checkpoint,
+		"results":    results,
+	})
+}
+
+func (p *plugin) applyMutation(e *core.RequestEvent, m *Mutation) *MutationResult {
+	result := &MutationResult{Collection: m.Collection, Id: m.Id}
+
+	err := validation.ValidateStruct(m,
+		validation.Field(&m.Collection, validation.Required),
+		validation.Field(&m.Id, validation.Required, validation.Length(1, 100)),
+		validation.Field(&m.Action, validation.Required, validation.In(ActionUpsert, ActionDelete)),
+	)
+	if err != nil {
+		return result.fail(e.BadRequestError("Invalid mutation data.", err))
+	}
+
+	config, ok := p.collections[m.Collection]
+	if !ok {
+		return result.fail(e.NotFoundError("Missing or not synced collection.", nil))
+	}
+
+	collection, err := e.App.FindCachedCollectionByNameOrId(m.Collection)
+	if err != nil {
+		return result.fail(e.NotFoundError("Missing or not synced collection.", err))
+	}
+
+	data := m.Data
+
+	change, _ := findChange(e.App, collection.Id, m.Id)
+	if change != nil && int64(change.GetInt("seq")) > m.BaseSeq {
+		conflict := &Conflict{
+			App:           e.App,
+			Collection:    collection,
+			Mutation:      m,
+			ServerSeq:     int64(change.GetInt("seq")),
+			ServerChanged: change.GetDateTime("updated"),
+		}
+		if !change.GetBool("deleted") {
+			conflict.ServerRecord, _ = e.App.FindRecordById(collection, m.Id)
+		}
+
+		data, err = p.resolve(config, conflict)
+		if err != nil {
+			return result.fail(e.BadRequestError("Failed to resolve the mutation conflict.", err))
+		}
+
+		if data == nil {
+			result.Status = StatusConflict
+			result.Seq = conflict.ServerSeq
+			result.Record = p.accessibleRecord(e, conflict.ServerRecord)
+			return result
+		}
+	}
+
+	ir := &core.InternalRequest{}
+
+	switch m.Action {
+	case ActionDelete:
+		if _, err := e.App.FindRecordById(collection, m.Id); err != nil {
+			// already deleted
+			result.Status = StatusApplied
+			if change != nil {
+				result.Seq = int64(change.GetInt("seq"))
+			}
+			return result
+		}
+
+		ir.Method = http.MethodDelete
+		ir.URL = "/api/collections/" + collection.Name + "/records/" + m.Id
+	default:
+		body := make(map[string]any, len(data)+1)
+		for k, v := range data {
+			body[k] = v
+		}
+		body["id"] = m.Id
+
+		ir.Method = http.MethodPut
+		ir.URL = "/api/collections/" + collection.Name + "/records"
+		ir.Body = body
+	}
+
+	var applied *apis.BatchRequestResult
+
+	txErr := e.App.RunInTransaction(func(txApp core.App) error {
+		var err error
+		applied, err = apis.ProcessInternalRequest(txApp, e, ir)
+		return err
+	})
+	if txErr != nil {
+		return result.fail(router.ToApiError(txErr))
+	}
+
+	result.Status = StatusApplied
+	if m.Action == ActionUpsert {
+		result.Record = applied.Body
+	}
+
+	if change, err := findChange(e.App, collection.Id, m.Id); err == nil {
+		result.Seq = int64(change.GetInt("seq"))
+	}
+
+	return result
+}
+
+// resolve resolves the mutation conflict with the collection policy
+// and returns the data to apply (or nil to reject the mutation).
+func (p *plugin) resolve(config *CollectionConfig, c *Conflict) (map[string]any, error) {
+	if config.Resolve != nil {
+		return config.Resolve(c)
+	}
+
+	data := c.Mutation.Data
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	switch config.Policy {
+	case PolicyClientWins:
+		return data, nil
+	case PolicyLastWriteWins:
+		if !c.Mutation.Timestamp.IsZero() && c.Mutation.Timestamp.Time().After(c.ServerChanged.Time()) {
+			return data, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// accessibleRecord returns the enriched record if it is viewable by the request auth.
+func (p *plugin) accessibleRecord(e *core.RequestEvent, record *core.Record) any {
+	if record == nil {
+		return nil
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return nil
+	}
+
+	if ok, _ := e.App.CanAccessRecord(record, requestInfo, record.Collection().ViewRule); !ok {
+		return nil
+	}
+
+	if err := apis.EnrichRecord(e, record); err != nil {
+		return nil
+	}
+
+	return record
+}
+
+func (r *MutationResult) fail(err *router.ApiError) *MutationResult {
+	r.Status = StatusError
+	r.Error = err
+	return r
+}
@@ -0,0 +1,396 @@
+// Package otpdelivery extends the builtin OTP auth with an ordered list
+// of delivery channels per auth collection (e.g. SMS -> WhatsApp -> email).
+//
+// When the OTP is requested, the channels are tried one after another
+// until one of them succeeds. Channels without a recipient for the auth
+// record (e.g. empty phone field) are skipped. Each attempt is stored in
+// the "_otpDeliveries" system collection for delivery status tracking.
+//
+// The builtin [ChannelEmail] channel sends the default OTP email and
+// doesn't need to be registered.
+//
+// Example usage:
+//
+//	otpdelivery.MustRegister(app, otpdelivery.Config{
+//		Channels: map[string]otpdelivery.Channel{
+//			// sent with app.SendSMS (see core.SMSSender)
+//			"sms": &otpdelivery.TextChannel{
+//				Field: "phone",
+//			},
+//			"whatsapp": &otpdelivery.TextChannel{
+//				Field:   "phone",
+//				Deliver: myWhatsAppClient.Send,
+//			},
+//		},
+//		Collections: map[string][]string{
+//			"users": {"sms", "whatsapp", otpdelivery.ChannelEmail},
+//		},
+//	})
+package otpdelivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameOTPDeliveries = "_otpDeliveries"
+
+// ChannelEmail is the name of the builtin OTP email delivery channel.
+const ChannelEmail = "email"
+
+// Delivery attempt statuses.
+const (
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+const (
+	DefaultTimeout   = 30 * time.Second
+	DefaultRetention = 7 * 24 * time.Hour
+
+	DefaultMessageTemplate = "Your " + core.EmailPlaceholderAppName + " one-time password is " + core.EmailPlaceholderOTP + "."
+)
+
+const cleanupCronId = "__otpDeliveriesCleanup__"
+
+// ErrNoRecipient could be returned by a [Channel] to indicate that the
+// auth record doesn't have a recipient for the channel and it should be skipped.
+var ErrNoRecipient = errors.New("otpdelivery: missing channel recipient")
+
+var (
+	_ Channel = (ChannelFunc)(nil)
+	_ Channel = (*TextChannel)(nil)
+)
+
+// Message defines the data of a single OTP delivery.
+type Message struct {
+	App    core.App
+	Record *core.Record
+	OTPId  string
+	OTP    string
+}
+
+// Channel defines an OTP delivery channel (e.g. a SMS gateway client).
+type Channel interface {
+	// Send delivers the OTP message and returns the recipient address
+	// (phone, messenger id, etc.) that will be stored in the delivery attempt sentTo field.
+	//
+	// Note that the OTP sentTo field is not updated because the OTP auth
+	// endpoint accepts only the OTPs sent to the auth record email (or without sentTo).
+	Send(ctx context.Context, msg *Message) (string, error)
+}
+
+// ChannelFunc is an adapter to allow the use of ordinary functions as [Channel].
+type ChannelFunc func(ctx context.Context, msg *Message) (string, error)
+
+// Send implements [Channel] interface.
+func (f ChannelFunc) Send(ctx context.Context, msg *Message) (string, error) {
+	return f(ctx, msg)
+}
+
+// TextChannel is a [Channel] implementation for the text message
+// based services (SMS, WhatsApp, Telegram, etc.) that delivers the OTP
+// to the recipient stored in an auth record field.
+type TextChannel struct {
+	// Deliver is an optional function that delivers the text message
+	// to the recipient (e.g. the Send method of a WhatsApp client).
+	//
+	// If not set, the message is sent as SMS with [core.App.SendSMS].
+	Deliver func(ctx context.Context, to string, message string) error
+
+	// Field is the name of the auth record field with the recipient (e.g. "phone").
+	Field string
+
+	// MessageTemplate is the OTP text message template (default to [DefaultMessageTemplate]).
+	//
+	// The "{APP_NAME}", "{OTP}" and "{OTP_ID}" placeholders are replaced
+	// with the application name, the OTP password and the OTP id.
+	MessageTemplate string
+}
+
+// Send implements [Channel] interface.
+func (c *TextChannel) Send(ctx context.Context, msg *Message) (string, error) {
+	to := strings.TrimSpace(msg.Record.GetString(c.Field))
+	if to == "" {
+		return "", ErrNoRecipient
+	}
+
+	template := c.MessageTemplate
+	if template == "" {
+		template = DefaultMessageTemplate
+	}
+
+	text := strings.NewReplacer(
+		core.EmailPlaceholderAppName, msg.App.Settings().Meta.AppName,
+		core.EmailPlaceholderOTP, msg.OTP,
+		core.EmailPlaceholderOTPId, msg.OTPId,
+	).Replace(template)
+
+	if c.Deliver == nil {
+		return to, msg.App.SendSMS(ctx, &core.SMSMessage{To: to, Body: text})
+	}
+
+	return to, c.Deliver(ctx, to, text)
+}
+
+// Config defines the config options of the otpdelivery plugin.
+type Config struct {
+	// Channels are the custom delivery channels indexed by their name.
+	Channels map[string]Channel
+
+	// Collections maps the auth collection names to their ordered list
+	// of delivery channel names (use [ChannelEmail] for the default OTP email).
+	Collections map[string][]string
+
+	// Timeout is the max duration of a single channel delivery attempt (default to 30 seconds).
+	Timeout time.Duration
+
+	// Retention specifies how long to keep the delivery attempts (default to 7 days).
+	Retention time.Duration
+}
+
+// MustRegister registers the otpdelivery plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the otpdelivery plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if len(p.config.Collections) == 0 {
+		return errors.New("otpdelivery: at least one auth collection must be specified")
+	}
+
+	for collection, channels := range p.config.Collections {
+		if len(channels) == 0 {
+			return fmt.Errorf("otpdelivery: missing %q delivery channels", collection)
+		}
+
+		for _, name := range channels {
+			if name != ChannelEmail && p.config.Channels[name] == nil {
+				return fmt.Errorf("otpdelivery: unknown %q delivery channel", name)
+			}
+		}
+	}
+
+	if p.config.Timeout <= 0 {
+		p.config.Timeout = DefaultTimeout
+	}
+
+	if p.config.Retention <= 0 {
+		p.config.Retention = DefaultRetention
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollection(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollection()
+		})
+	}
+
+	collections := make([]string, 0, len(p.config.Collections))
+	for name := range p.config.Collections {
+		collections = append(collections, name)
+	}
+
+	p.app.OnMailerRecordOTPSend(collections...).BindFunc(p.deliver)
+
+	return p.app.Cron().Add(cleanupCronId, "30 * * * *", func() {
+		_, err := p.app.DB().Delete(CollectionNameOTPDeliveries, dbx.NewExp("[[created]] < {:date}", dbx.Params{
+			"date": time.Now().Add(-p.config.Retention).UTC().Format(types.DefaultDateLayout),
+		})).Execute()
+		if err != nil {
+			p.app.Logger().Error("Failed to delete old OTP deliveries", "error", err.Error())
+		}
+	})
+}
+
+// FindDeliveries returns the delivery attempts of the specified OTP
+// ordered by their attempt number.
+func FindDeliveries(app core.App, otpId string) ([]*core.Record, error) {
+	return app.FindRecordsByFilter(
+		CollectionNameOTPDeliveries,
+		"otpRef = {:otpId}",
+		"attempt",
+		0,
+		0,
+		dbx.Params{"otpId": otpId},
+	)
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) ensureCollection() error {
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameOTPDeliveries); err == nil {
+		return nil // already created
+	}
+
+	col := core.NewBaseCollection(CollectionNameOTPDeliveries)
+	col.System = true
+	col.Fields.Add(&core.TextField{
+		Name:     "otpRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "channel",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.SelectField{
+		Name:      "status",
+		System:    true,
+		Required:  true,
+		MaxSelect: 1,
+		Values:    []string{StatusSent, StatusFailed, StatusSkipped},
+	})
+	col.Fields.Add(&core.NumberField{
+		Name:    "attempt",
+		System:  true,
+		OnlyInt: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "sentTo",
+		System: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "error",
+		System: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.AddIndex("idx_otpDeliveries_otpRef", false, "otpRef", "")
+	col.AddIndex("idx_otpDeliveries_created", false, "created", "")
+
+	return p.app.Save(col)
+}
+
+// deliver tries the configured collection channels in order until
+// one of them succeeds.
+func (p *plugin) deliver(e *core.MailerRecordEvent) error {
+	channels := p.config.Collections[e.Record.Collection().Name]
+	if len(channels) == 0 {
+		channels = p.config.Collections[e.Record.Collection().Id]
+	}
+
+	msg := &Message{
+		App:    e.App,
+		Record: e.Record,
+	}
+	msg.OTPId, _ = e.Meta["otpId"].(string)
+	msg.OTP, _ = e.Meta["password"].(string)
+
+	var errs []error
+
+	for i, name := range channels {
+		var to string
+		var err error
+
+		if name == ChannelEmail {
+			to = e.Record.Email()
+			if to == "" {
+				err = ErrNoRecipient
+			} else {
+				// the default handler sends the email and updates the OTP sentTo field
+				err = e.Next()
+			}
+		} else {
+			to, err = p.send(name, msg)
+		}
+
+		status := StatusSent
+		switch {
+		case errors.Is(err, ErrNoRecipient):
+			status = StatusSkipped
+		case err != nil:
+			status = StatusFailed
+		}
+
+		if trackErr := p.track(e.App, msg, name, i+1, status, to, err); trackErr != nil {
+			e.App.Logger().Warn("Failed to store OTP delivery attempt", "error", trackErr, "otpId", msg.OTPId)
+		}
+
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+
+		if status == StatusFailed {
+			e.App.Logger().Warn(
+				"OTP delivery channel failed - trying the next one",
+				"channel", name,
+				"error", err,
+				"otpId", msg.OTPId,
+			)
+		}
+	}
+
+	return errors.Join(append([]error{errors.New("otpdelivery: all delivery channels failed")}, errs...)...)
+}
+
+// send delivers the message with the specified custom channel.
+func (p *plugin) send(name string, msg *Message) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+
+	return p.config.Channels[name].Send(ctx, msg)
+}
+
+func (p *plugin) track(app core.App, msg *Message, channel string, attempt int, status string, to string, err error) error {
+	collection, findErr := app.FindCachedCollectionByNameOrId(CollectionNameOTPDeliveries)
+	if findErr != nil {
+		return findErr
+	}
+
+	delivery := core.NewRecord(collection)
+	delivery.Set("otpRef", msg.OTPId)
+	delivery.Set("collectionRef", msg.Record.Collection().Id)
+	delivery.Set("recordRef", msg.Record.Id)
+	delivery.Set("channel", channel)
+	delivery.Set("status", status)
+	delivery.Set("attempt", attempt)
+	delivery.Set("sentTo", to)
+	if err != nil && status == StatusFailed {
+		delivery.Set("error", err.Error())
+	}
+
+	return app.Save(delivery)
+}
@@ -0,0 +1,270 @@
+package otpdelivery_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/mails"
+	"github.com/pocketbase/pocketbase/plugins/otpdelivery"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRegisterErrors(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name   string
+		config otpdelivery.Config
+	}{
+		{"missing collections", otpdelivery.Config{}},
+		{"missing collection channels", otpdelivery.Config{Collections: map[string][]string{"users": nil}}},
+		{"unknown channel", otpdelivery.Config{Collections: map[string][]string{"users": {"sms"}}}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if err := otpdelivery.Register(app, s.config); err == nil {
+				t.Fatal("Expected register error")
+			}
+		})
+	}
+}
+
+func TestDeliveryFallback(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name             string
+		phone            string
+		smsErr           error
+		mailerErr        error
+		expectError      bool
+		expectedStatuses []string
+		expectedSentTo   string
+		expectedSMS      int
+		expectedEmails   int
+	}{
+		{
+			"first channel success",
+			"+359888000001",
+			nil,
+			nil,
+			false,
+			[]string{"sms:sent"},
+			"", // the OTP sentTo is updated only for the email channel
+			1,
+			0,
+		},
+		{
+			"fallback to email after failure and skip",
+			"+359888000001",
+			errors.New("sms gateway error"),
+			nil,
+			false,
+			[]string{"sms:failed", "whatsapp:skipped", "email:sent"},
+			"test@example.com",
+			1,
+			1,
+		},
+		{
+			"skipped channel without recipient",
+			"",
+			nil,
+			nil,
+			false,
+			[]string{"sms:skipped", "whatsapp:skipped", "email:sent"},
+			"test@example.com",
+			0,
+			1,
+		},
+		{
+			"all channels failed",
+			"+359888000001",
+			errors.New("sms gateway error"),
+			errors.New("smtp error"),
+			true,
+			[]string{"sms:failed", "whatsapp:skipped", "email:failed"},
+			"",
+			1,
+			0,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			users, err := app.FindCollectionByNameOrId("users")
+			if err != nil {
+				t.Fatal(err)
+			}
+			users.Fields.Add(&core.TextField{Name: "phone"}, &core.TextField{Name: "whatsapp"})
+			if err := app.Save(users); err != nil {
+				t.Fatal(err)
+			}
+
+			user, err := app.FindAuthRecordByEmail(users, "test@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			user.Set("phone", s.phone)
+			if err := app.Save(user); err != nil {
+				t.Fatal(err)
+			}
+
+			var smsMessages []string
+
+			app.OnSMSSend().BindFunc(func(e *core.SMSSendEvent) error {
+				e.Sender = core.SMSSenderFunc(func(ctx context.Context, m *core.SMSMessage) error {
+					if m.To != s.phone {
+						t.Fatalf("Expected sms recipient %q, got %q", s.phone, m.To)
+					}
+					smsMessages = append(smsMessages, m.Body)
+					return s.smsErr
+				})
+				return e.Next()
+			})
+
+			otpdelivery.MustRegister(app, otpdelivery.Config{
+				Channels: map[string]otpdelivery.Channel{
+					"sms": &otpdelivery.TextChannel{
+						Field:           "phone",
+						MessageTemplate: "{APP_NAME}: {OTP}",
+					},
+					"whatsapp": &otpdelivery.TextChannel{
+						Field: "whatsapp",
+						Deliver: func(ctx context.Context, to string, message string) error {
+							t.Fatal("Expected the whatsapp channel to be skipped")
+							return nil
+						},
+					},
+				},
+				Collections: map[string][]string{
+					"users": {"sms", "whatsapp", otpdelivery.ChannelEmail},
+				},
+			})
+
+			if s.mailerErr != nil {
+				app.OnMailerSend().BindFunc(func(e *core.MailerEvent) error {
+					return s.mailerErr
+				})
+			}
+
+			otp := core.NewOTP(app)
+			otp.SetCollectionRef(users.Id)
+			otp.SetRecordRef(user.Id)
+			otp.SetPassword("123456")
+			if err := app.Save(otp); err != nil {
+				t.Fatal(err)
+			}
+
+			err = mails.SendRecordOTP(app, user, otp.Id, "123456")
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if len(smsMessages) != s.expectedSMS {
+				t.Fatalf("Expected %d sms messages, got %d", s.expectedSMS, len(smsMessages))
+			}
+			for _, m := range smsMessages {
+				if m != app.Settings().Meta.AppName+": 123456" {
+					t.Fatalf("Unexpected sms message %q", m)
+				}
+			}
+
+			if total := app.TestMailer.TotalSend(); total != s.expectedEmails {
+				t.Fatalf("Expected %d emails, got %d", s.expectedEmails, total)
+			}
+
+			deliveries, err := otpdelivery.FindDeliveries(app, otp.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			statuses := make([]string, len(deliveries))
+			for i, d := range deliveries {
+				statuses[i] = d.GetString("channel") + ":" + d.GetString("status")
+
+				if d.GetInt("attempt") != i+1 {
+					t.Fatalf("Expected delivery %d attempt %d, got %d", i, i+1, d.GetInt("attempt"))
+				}
+
+				if d.GetString("status") == otpdelivery.StatusFailed && d.GetString("error") == "" {
+					t.Fatalf("Expected delivery %d error message", i)
+				}
+
+				if d.GetString("channel") == "sms" && d.GetString("status") != otpdelivery.StatusSkipped && d.GetString("sentTo") != s.phone {
+					t.Fatalf("Expected delivery %d sentTo %q, got %q", i, s.phone, d.GetString("sentTo"))
+				}
+			}
+
+			if strings.Join(statuses, ",") != strings.Join(s.expectedStatuses, ",") {
+				t.Fatalf("Expected deliveries %v, got %v", s.expectedStatuses, statuses)
+			}
+
+			otp, err = app.FindOTPById(otp.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if otp.SentTo() != s.expectedSentTo {
+				t.Fatalf("Expected OTP sentTo %q, got %q", s.expectedSentTo, otp.SentTo())
+			}
+		})
+	}
+}
+
+func TestDeliveryNotConfiguredCollection(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	otpdelivery.MustRegister(app, otpdelivery.Config{
+		Channels: map[string]otpdelivery.Channel{
+			"sms": otpdelivery.ChannelFunc(func(ctx context.Context, msg *otpdelivery.Message) (string, error) {
+				t.Fatal("Expected the sms channel to not be called")
+				return "", nil
+			}),
+		},
+		Collections: map[string][]string{
+			"clients": {"sms"},
+		},
+	})
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otp := core.NewOTP(app)
+	otp.SetCollectionRef(user.Collection().Id)
+	otp.SetRecordRef(user.Id)
+	otp.SetPassword("123456")
+	if err := app.Save(otp); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mails.SendRecordOTP(app, user, otp.Id, "123456"); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := app.TestMailer.TotalSend(); total != 1 {
+		t.Fatalf("Expected the default OTP email to be sent, got %d emails", total)
+	}
+
+	deliveries, err := otpdelivery.FindDeliveries(app, otp.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 0 {
+		t.Fatalf("Expected no tracked deliveries, got %d", len(deliveries))
+	}
+}
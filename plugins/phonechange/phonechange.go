@@ -0,0 +1,460 @@
+// Package phonechange implements a phone number change flow, similar
+// to the builtin email change, for the auth collections that store the
+// user phone number in a regular text field.
+//
+// The new phone number is verified with a one-time code sent with
+// [core.App.SendSMS] (see [core.SMSSender]) and the old number remains
+// unchanged until the change is confirmed.
+//
+// The plugin registers the following endpoints (both require the
+// auth record to be from the path collection):
+//
+//	POST /api/collections/{collection}/request-phone-change  (body: {"newPhone": "+359888123456"})
+//	POST /api/collections/{collection}/confirm-phone-change  (body: {"code": "123456"})
+//
+// Example usage:
+//
+//	phonechange.MustRegister(app, phonechange.Config{
+//		Collections: []string{"users"},
+//	})
+package phonechange
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const CollectionNamePhoneChanges = "_phoneChanges"
+
+const (
+	DefaultField           = "phone"
+	DefaultCodeLength      = 6
+	DefaultDuration        = 10 * time.Minute
+	DefaultMaxAttempts     = 5
+	DefaultMessageTemplate = "Your {APP_NAME} verification code is {CODE}."
+)
+
+// E.164 formatted phone number
+var phoneRegex = regexp.MustCompile(`^\+[1-9]\d{6,14}$`)
+
+// Event defines the data of a single phone change event.
+type Event struct {
+	*core.RequestEvent
+
+	Record   *core.Record
+	NewPhone string
+
+	// Message is the text message that will be sent to the new phone
+	// (available only on change request and could be modified).
+	Message string
+}
+
+// Config defines the config options of the phonechange plugin.
+type Config struct {
+	// Collections is a list with the names of the auth collections
+	// that support the phone change flow.
+	Collections []string
+
+	// Field specifies the name of the auth collections text field
+	// that holds the record phone number (default to "phone").
+	Field string
+
+	// MessageTemplate is the verification code message template.
+	//
+	// The "{APP_NAME}" and "{CODE}" placeholders are replaced with
+	// the application name and the generated verification code.
+	MessageTemplate string
+
+	// CodeLength specifies the number of digits of the verification code (default to 6).
+	CodeLength int
+
+	// Duration specifies how long the verification code is valid (default to 10 minutes).
+	Duration time.Duration
+
+	// MaxAttempts specifies the max allowed invalid confirmation
+	// attempts before the change request is discarded (default to 5).
+	MaxAttempts int
+
+	// OnRequestChange is an optional callback that is invoked
+	// before the verification code is sent.
+	OnRequestChange func(e *Event) error
+
+	// OnConfirmChange is an optional callback that is invoked
+	// after the new phone number is confirmed and saved.
+	OnConfirmChange func(e *Event) error
+}
+
+// MustRegister registers the phonechange plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the phonechange plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if len(p.config.Collections) == 0 {
+		return errors.New("phonechange: at least one auth collection must be specified")
+	}
+
+	if p.config.Field == "" {
+		p.config.Field = DefaultField
+	}
+
+	if p.config.MessageTemplate == "" {
+		p.config.MessageTemplate = DefaultMessageTemplate
+	}
+
+	if p.config.CodeLength <= 0 {
+		p.config.CodeLength = DefaultCodeLength
+	}
+
+	if p.config.Duration <= 0 {
+		p.config.Duration = DefaultDuration
+	}
+
+	if p.config.MaxAttempts <= 0 {
+		p.config.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollection(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollection()
+		})
+	}
+
+	// delete the change requests of the deleted auth records
+	p.app.OnRecordDeleteExecute(p.config.Collections...).Bind(&hook.Handler[*core.RecordEvent]{
+		Func: func(e *core.RecordEvent) error {
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp core.App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				return deleteChanges(txApp, e.Record)
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group("/api/collections/{collection}").Bind(apis.RequireSameCollectionContextAuth(""))
+		sub.POST("/request-phone-change", p.requestChange)
+		sub.POST("/confirm-phone-change", p.confirmChange)
+
+		return e.Next()
+	})
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) ensureCollection() error {
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNamePhoneChanges); err == nil {
+		return nil // already created
+	}
+
+	col := core.NewBaseCollection(CollectionNamePhoneChanges)
+	col.System = true
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "newPhone",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.PasswordField{
+		Name:     core.FieldNamePassword,
+		System:   true,
+		Hidden:   true,
+		Required: true,
+		Cost:     8, // low cost for better performance and because the code is short-lived
+	})
+	col.Fields.Add(&core.NumberField{
+		Name:    "attempts",
+		System:  true,
+		OnlyInt: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_phoneChanges_unique", true, "collectionRef, recordRef", "")
+
+	return p.app.Save(col)
+}
+
+// findCollection returns the request path auth collection
+// if it is configured to support the phone change flow.
+func (p *plugin) findCollection(e *core.RequestEvent) (*core.Collection, error) {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || !collection.IsAuth() ||
+		(!list.ExistInSlice(collection.Name, p.config.Collections) && !list.ExistInSlice(collection.Id, p.config.Collections)) ||
+		collection.Fields.GetByName(p.config.Field) == nil {
+		return nil, e.NotFoundError("Missing or invalid auth collection context.", err)
+	}
+
+	return collection, nil
+}
+
+type requestChangeForm struct {
+	NewPhone string `form:"newPhone" json:"newPhone"`
+}
+
+func (p *plugin) requestChange(e *core.RequestEvent) error {
+	collection, err := p.findCollection(e)
+	if err != nil {
+		return err
+	}
+
+	record := e.Auth
+
+	form := &requestChangeForm{}
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	form.NewPhone = strings.TrimSpace(form.NewPhone)
+
+	err = validation.ValidateStruct(form,
+		validation.Field(&form.NewPhone,
+			validation.Required,
+			validation.Match(phoneRegex).Error("Must be a valid phone number in E.164 format."),
+			validation.NotIn(record.GetString(p.config.Field)),
+			validation.By(p.checkUniquePhone(record)),
+		),
+	)
+	if err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	code := security.RandomStringWithAlphabet(p.config.CodeLength, "1234567890")
+
+	event := &Event{
+		RequestEvent: e,
+		Record:       record,
+		NewPhone:     form.NewPhone,
+		Message: strings.NewReplacer(
+			"{APP_NAME}", e.App.Settings().Meta.AppName,
+			"{CODE}", code,
+		).Replace(p.config.MessageTemplate),
+	}
+
+	if p.config.OnRequestChange != nil {
+		if err := p.config.OnRequestChange(event); err != nil {
+			return e.BadRequestError("Failed to request phone change.", err)
+		}
+	}
+
+	changesCollection, err := e.App.FindCachedCollectionByNameOrId(CollectionNamePhoneChanges)
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	change := core.NewRecord(changesCollection)
+	change.Set("collectionRef", collection.Id)
+	change.Set("recordRef", record.Id)
+	change.Set("newPhone", event.NewPhone)
+	change.SetPassword(code)
+
+	// replace the previous change request (if any)
+	txErr := e.App.RunInTransaction(func(txApp core.App) error {
+		if err := deleteChanges(txApp, record); err != nil {
+			return err
+		}
+
+		return txApp.Save(change)
+	})
+	if txErr != nil {
+		return e.BadRequestError("Failed to request phone change.", txErr)
+	}
+
+	err = e.App.SendSMS(e.Request.Context(), &core.SMSMessage{
+		To:   event.NewPhone,
+		Body: event.Message,
+	})
+	if err != nil {
+		return e.InternalServerError("Failed to send the verification code.", err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+type confirmChangeForm struct {
+	Code string `form:"code" json:"code"`
+}
+
+func (p *plugin) confirmChange(e *core.RequestEvent) error {
+	if _, err := p.findCollection(e); err != nil {
+		return err
+	}
+
+	record := e.Auth
+
+	form := &confirmChangeForm{}
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	err := validation.ValidateStruct(form,
+		validation.Field(&form.Code, validation.Required, validation.Length(1, 100)),
+	)
+	if err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	change, err := findChange(e.App, record)
+	if err != nil {
+		return invalidCodeError()
+	}
+
+	if time.Since(change.GetDateTime("created").Time()) > p.config.Duration {
+		if err := e.App.Delete(change); err != nil {
+			return e.InternalServerError("", err)
+		}
+
+		return invalidCodeError()
+	}
+
+	if !change.ValidatePassword(form.Code) {
+		attempts := change.GetInt("attempts") + 1
+
+		if attempts >= p.config.MaxAttempts {
+			err = e.App.Delete(change)
+		} else {
+			change.Set("attempts", attempts)
+			err = e.App.Save(change)
+		}
+		if err != nil {
+			return e.InternalServerError("", err)
+		}
+
+		return invalidCodeError()
+	}
+
+	newPhone := change.GetString("newPhone")
+
+	// recheck in case the phone was claimed by another record after the request
+	if err := p.checkUniquePhone(record)(newPhone); err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", validation.Errors{
+			"code": err,
+		})
+	}
+
+	txErr := e.App.RunInTransaction(func(txApp core.App) error {
+		record.Set(p.config.Field, newPhone)
+		if err := txApp.Save(record); err != nil {
+			return err
+		}
+
+		return txApp.Delete(change)
+	})
+	if txErr != nil {
+		return e.BadRequestError("Failed to confirm phone change.", txErr)
+	}
+
+	if p.config.OnConfirmChange != nil {
+		if err := p.config.OnConfirmChange(&Event{RequestEvent: e, Record: record, NewPhone: newPhone}); err != nil {
+			return e.InternalServerError("Failed to process the confirmed phone change.", err)
+		}
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+func (p *plugin) checkUniquePhone(record *core.Record) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+		if v == "" {
+			return nil
+		}
+
+		found, _ := p.app.FindFirstRecordByData(record.Collection(), p.config.Field, v)
+		if found != nil && found.Id != record.Id {
+			return validation.NewError("validation_invalid_new_phone", "Invalid new phone number.")
+		}
+
+		return nil
+	}
+}
+
+func invalidCodeError() error {
+	return apis.NewBadRequestError("Invalid or expired verification code.", validation.Errors{
+		"code": validation.NewError("validation_invalid_code", "Invalid or expired verification code."),
+	})
+}
+
+func findChange(app core.App, record *core.Record) (*core.Record, error) {
+	return app.FindFirstRecordByFilter(
+		CollectionNamePhoneChanges,
+		"collectionRef = {:collectionRef} && recordRef = {:recordRef}",
+		dbx.Params{"collectionRef": record.Collection().Id, "recordRef": record.Id},
+	)
+}
+
+func deleteChanges(app core.App, record *core.Record) error {
+	changes, err := app.FindAllRecords(CollectionNamePhoneChanges, dbx.HashExp{
+		"collectionRef": record.Collection().Id,
+		"recordRef":     record.Id,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if err := app.Delete(change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
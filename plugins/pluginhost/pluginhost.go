@@ -0,0 +1,473 @@
+// Package pluginhost implements a host for out-of-process plugins,
+// aka. external executables (written in any language) that receive
+// the app hook events and serve custom routes over a simple
+// newline delimited JSON protocol through their stdin/stdout.
+//
+// Each plugin process is started with the app server and automatically
+// restarted if it exits, which allows upgrading a plugin by just
+// replacing its executable and terminating the old process.
+//
+// Note that the plugin routes are registered on server start, aka. routes
+// added by an upgraded plugin are available only after the app restart.
+// While a plugin is restarting its subscribed blocking hooks (recordCreate,
+// recordUpdate, recordDelete) fail and its routes respond with 400 error.
+//
+// The protocol is JSON over stdio (and not gRPC) to avoid requiring any
+// code generation or extra dependencies from the plugin authors.
+//
+// # Protocol
+//
+// Every message is a single line JSON object:
+//
+//	{"id": 1, "type": "...", "data": {...}, "error": "..."}
+//
+// On start the plugin must write a "manifest" message describing
+// the hooks it subscribes to and the routes it serves:
+//
+//	{"type": "manifest", "data": {
+//		"hooks":  [{"name": "recordCreate", "collections": ["posts"]}],
+//		"routes": [{"method": "GET", "path": "/api/myplugin/hello/{name}", "auth": ""}]
+//	}}
+//
+// The host then sends "hook" and "request" messages with an unique id
+// and the plugin must answer each of them with a "reply" message with
+// the same id (a non-empty "error" aborts the hook chain or responds with 400).
+//
+// See [HookEvent], [HookReply], [RouteRequest] and [RouteResponse] for the
+// "data" payload of the related messages. The plugin could also write
+// "log" messages ({"type": "log", "data": {"level": "info", "message": "..."}})
+// and anything written to its stderr is forwarded to the app logger.
+//
+// Example usage:
+//
+//	pluginhost.MustRegister(app, pluginhost.Config{
+//		Plugins: []pluginhost.Process{
+//			{Name: "myplugin", Command: "./pb_plugins/myplugin"},
+//		},
+//	})
+package pluginhost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+const (
+	// DefaultTimeout is the default max duration to wait for a plugin reply.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultMaxRestartDelay is the default max delay between the restart
+	// attempts of an exited plugin process.
+	DefaultMaxRestartDelay = 30 * time.Second
+)
+
+// Supported hook subscription names.
+const (
+	HookRecordCreate             = "recordCreate"
+	HookRecordUpdate             = "recordUpdate"
+	HookRecordDelete             = "recordDelete"
+	HookRecordAfterCreateSuccess = "recordAfterCreateSuccess"
+	HookRecordAfterUpdateSuccess = "recordAfterUpdateSuccess"
+	HookRecordAfterDeleteSuccess = "recordAfterDeleteSuccess"
+)
+
+// Supported route auth requirements.
+const (
+	RouteAuthNone      = ""
+	RouteAuthRecord    = "auth"
+	RouteAuthSuperuser = "superuser"
+)
+
+// Process defines a single plugin executable.
+type Process struct {
+	// Name is the unique plugin name used for the logs.
+	Name string
+
+	// Command is the plugin executable path.
+	Command string
+
+	// Args is an optional list with the executable arguments.
+	Args []string
+
+	// Env is an optional list with extra "KEY=value" environment
+	// variables (the plugin inherits the app process environment).
+	Env []string
+
+	// Dir is an optional working directory of the plugin process.
+	Dir string
+}
+
+// Config defines the config options of the pluginhost plugin.
+type Config struct {
+	// Plugins is a list with the plugin processes to start.
+	Plugins []Process
+
+	// Timeout specifies the max duration to wait for the plugin
+	// manifest and for each hook or request reply (default to [DefaultTimeout]).
+	Timeout time.Duration
+
+	// MaxRestartDelay specifies the max delay between the restart attempts
+	// of an exited plugin process (default to [DefaultMaxRestartDelay]).
+	MaxRestartDelay time.Duration
+}
+
+// Manifest describes the hooks and routes of a plugin.
+type Manifest struct {
+	Hooks  []HookSubscription `json:"hooks"`
+	Routes []Route            `json:"routes"`
+}
+
+// HookSubscription defines a single plugin hook subscription.
+type HookSubscription struct {
+	// Name is one of the Hook* constants.
+	Name string `json:"name"`
+
+	// Collections is an optional list with collection names or ids
+	// to limit the subscription to (empty means all).
+	Collections []string `json:"collections"`
+}
+
+// Route defines a single plugin route.
+type Route struct {
+	Method string `json:"method"`
+
+	// Path is the route path pattern (eg. "/api/myplugin/{id}").
+	Path string `json:"path"`
+
+	// Auth is one of the RouteAuth* constants.
+	Auth string `json:"auth"`
+}
+
+// HookEvent is the "data" payload of the host "hook" message.
+type HookEvent struct {
+	Hook       string       `json:"hook"`
+	Collection string       `json:"collection"`
+	Record     *core.Record `json:"record"`
+}
+
+// HookReply is the "data" payload of the plugin "reply" message for a "recordCreate" and "recordUpdate" hook.
+type HookReply struct {
+	// Record is an optional map with record field values to set before persisting the record.
+	Record map[string]any `json:"record"`
+}
+
+// RouteAuth describes the request auth state.
+type RouteAuth struct {
+	Id             string `json:"id"`
+	CollectionId   string `json:"collectionId"`
+	CollectionName string `json:"collectionName"`
+	Superuser      bool   `json:"superuser"`
+}
+
+// RouteRequest is the "data" payload of the host "request" message.
+type RouteRequest struct {
+	Method     string              `json:"method"`
+	Path       string              `json:"path"`
+	Pattern    string              `json:"pattern"`
+	PathParams map[string]string   `json:"pathParams"`
+	Query      string              `json:"query"`
+	Headers    map[string][]string `json:"headers"`
+	Body       []byte              `json:"body"` // base64
+	Auth       *RouteAuth          `json:"auth"`
+}
+
+// RouteResponse is the "data" payload of the plugin "reply" message for a "request".
+type RouteResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+
+	// JSON is the response body value to serialize as JSON.
+	//
+	// If not set, Body is written as it is.
+	JSON any `json:"json"`
+
+	// Body is the raw base64 encoded response body.
+	Body []byte `json:"body"`
+}
+
+// MustRegister registers the pluginhost plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the pluginhost plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	if config.MaxRestartDelay <= 0 {
+		config.MaxRestartDelay = DefaultMaxRestartDelay
+	}
+
+	h := &host{app: app, config: config}
+
+	names := make([]string, 0, len(config.Plugins))
+	for _, p := range config.Plugins {
+		if p.Name == "" || p.Command == "" {
+			return errors.New("pluginhost: Name and Command are required")
+		}
+
+		if slices.Contains(names, p.Name) {
+			return fmt.Errorf("pluginhost: duplicated plugin name %q", p.Name)
+		}
+		names = append(names, p.Name)
+
+		h.processes = append(h.processes, newProcess(app, p, config.Timeout, config.MaxRestartDelay))
+	}
+
+	app.OnServe().BindFunc(h.onServe)
+
+	app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+		h.stop()
+		return e.Next()
+	})
+
+	h.bindHooks()
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type host struct {
+	app       core.App
+	config    Config
+	processes []*process
+}
+
+func (h *host) onServe(e *core.ServeEvent) error {
+	for _, p := range h.processes {
+		if err := p.start(); err != nil {
+			h.stop()
+			return fmt.Errorf("pluginhost: failed to start plugin %q: %w", p.config.Name, err)
+		}
+
+		manifest := p.currentManifest()
+		if manifest == nil {
+			continue
+		}
+
+		for _, route := range manifest.Routes {
+			if err := h.bindRoute(e, p, route); err != nil {
+				h.stop()
+				return fmt.Errorf("pluginhost: invalid plugin %q route %s %s: %w", p.config.Name, route.Method, route.Path, err)
+			}
+		}
+	}
+
+	return e.Next()
+}
+
+func (h *host) stop() {
+	for _, p := range h.processes {
+		p.stop()
+	}
+}
+
+var pathParamRegex = regexp.MustCompile(`\{(\w+)(?:\.\.\.)?\}`)
+
+func (h *host) bindRoute(e *core.ServeEvent, p *process, route Route) error {
+	method := strings.ToUpper(route.Method)
+	if method == "" || !strings.HasPrefix(route.Path, "/") {
+		return errors.New("method and absolute path are required")
+	}
+
+	var middlewares []*hook.Handler[*core.RequestEvent]
+	switch route.Auth {
+	case RouteAuthNone:
+	case RouteAuthRecord:
+		middlewares = append(middlewares, apis.RequireAuth())
+	case RouteAuthSuperuser:
+		middlewares = append(middlewares, apis.RequireSuperuserAuth())
+	default:
+		return fmt.Errorf("unknown auth %q", route.Auth)
+	}
+
+	var params []string
+	for _, match := range pathParamRegex.FindAllStringSubmatch(route.Path, -1) {
+		params = append(params, match[1])
+	}
+
+	e.Router.Route(method, route.Path, func(re *core.RequestEvent) error {
+		// the route could have been removed after a plugin upgrade
+		manifest := p.currentManifest()
+		if manifest == nil || !slices.ContainsFunc(manifest.Routes, func(r Route) bool {
+			return strings.EqualFold(r.Method, method) && r.Path == route.Path
+		}) {
+			return re.NotFoundError("", nil)
+		}
+
+		body, err := io.ReadAll(re.Request.Body)
+		if err != nil {
+			return re.BadRequestError("Failed to read the request body.", err)
+		}
+
+		req := RouteRequest{
+			Method:     re.Request.Method,
+			Path:       re.Request.URL.Path,
+			Pattern:    route.Path,
+			PathParams: make(map[string]string, len(params)),
+			Query:      re.Request.URL.RawQuery,
+			Headers:    re.Request.Header,
+			Body:       body,
+		}
+
+		for _, name := range params {
+			req.PathParams[name] = re.Request.PathValue(name)
+		}
+
+		if re.Auth != nil {
+			req.Auth = &RouteAuth{
+				Id:             re.Auth.Id,
+				CollectionId:   re.Auth.Collection().Id,
+				CollectionName: re.Auth.Collection().Name,
+				Superuser:      re.Auth.IsSuperuser(),
+			}
+		}
+
+		reply, err := p.call(messageTypeRequest, req)
+		if err != nil {
+			return re.BadRequestError("", err)
+		}
+
+		res := RouteResponse{}
+		if len(reply) > 0 {
+			if err := json.Unmarshal(reply, &res); err != nil {
+				return re.InternalServerError("Invalid plugin response.", err)
+			}
+		}
+
+		if res.Status == 0 {
+			res.Status = http.StatusOK
+		}
+
+		for k, v := range res.Headers {
+			re.Response.Header().Set(k, v)
+		}
+
+		if res.JSON != nil {
+			return re.JSON(res.Status, res.JSON)
+		}
+
+		if len(res.Body) == 0 {
+			return re.NoContent(res.Status)
+		}
+
+		return re.Blob(res.Status, re.Response.Header().Get("Content-Type"), res.Body)
+	}).Bind(middlewares...)
+
+	return nil
+}
+
+func (h *host) bindHooks() {
+	h.app.OnRecordCreate().BindFunc(func(e *core.RecordEvent) error {
+		if err := h.dispatchBefore(HookRecordCreate, e.Record); err != nil {
+			return err
+		}
+		return e.Next()
+	})
+
+	h.app.OnRecordUpdate().BindFunc(func(e *core.RecordEvent) error {
+		if err := h.dispatchBefore(HookRecordUpdate, e.Record); err != nil {
+			return err
+		}
+		return e.Next()
+	})
+
+	h.app.OnRecordDelete().BindFunc(func(e *core.RecordEvent) error {
+		if err := h.dispatchBefore(HookRecordDelete, e.Record); err != nil {
+			return err
+		}
+		return e.Next()
+	})
+
+	h.app.OnRecordAfterCreateSuccess().BindFunc(func(e *core.RecordEvent) error {
+		h.dispatchAfter(HookRecordAfterCreateSuccess, e.Record)
+		return e.Next()
+	})
+
+	h.app.OnRecordAfterUpdateSuccess().BindFunc(func(e *core.RecordEvent) error {
+		h.dispatchAfter(HookRecordAfterUpdateSuccess, e.Record)
+		return e.Next()
+	})
+
+	h.app.OnRecordAfterDeleteSuccess().BindFunc(func(e *core.RecordEvent) error {
+		h.dispatchAfter(HookRecordAfterDeleteSuccess, e.Record)
+		return e.Next()
+	})
+}
+
+// dispatchBefore sends the hook event to the subscribed plugins
+// and waits for their replies (a reply error aborts the operation).
+func (h *host) dispatchBefore(hookName string, record *core.Record) error {
+	for _, p := range h.processes {
+		if !p.isSubscribed(hookName, record.Collection()) {
+			continue
+		}
+
+		reply, err := p.call(messageTypeHook, newHookEvent(hookName, record))
+		if err != nil {
+			return fmt.Errorf("plugin %q %s hook failure: %w", p.config.Name, hookName, err)
+		}
+
+		if hookName == HookRecordDelete || len(reply) == 0 {
+			continue
+		}
+
+		data := HookReply{}
+		if err := json.Unmarshal(reply, &data); err != nil {
+			return fmt.Errorf("plugin %q %s hook invalid reply: %w", p.config.Name, hookName, err)
+		}
+
+		for k, v := range data.Record {
+			record.Set(k, v)
+		}
+	}
+
+	return nil
+}
+
+// dispatchAfter notifies the subscribed plugins without waiting for their reply.
+func (h *host) dispatchAfter(hookName string, record *core.Record) {
+	for _, p := range h.processes {
+		if !p.isSubscribed(hookName, record.Collection()) {
+			continue
+		}
+
+		if err := p.notify(messageTypeHook, newHookEvent(hookName, record)); err != nil {
+			h.app.Logger().Warn(
+				"Failed to notify plugin",
+				"plugin", p.config.Name,
+				"hook", hookName,
+				"error", err.Error(),
+			)
+		}
+	}
+}
+
+func newHookEvent(hookName string, record *core.Record) HookEvent {
+	// hidden fields (eg. password hash, tokenKey) are not sent to the plugins
+	clone := record.Clone()
+	clone.IgnoreEmailVisibility(true)
+
+	return HookEvent{
+		Hook:       hookName,
+		Collection: record.Collection().Name,
+		Record:     clone,
+	}
+}
@@ -0,0 +1,469 @@
+package pluginhost
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	messageTypeManifest = "manifest"
+	messageTypeHook     = "hook"
+	messageTypeRequest  = "request"
+	messageTypeReply    = "reply"
+	messageTypeLog      = "log"
+)
+
+// maxMessageSize is the max allowed size of a single plugin message line.
+const maxMessageSize = 32 << 20
+
+// ErrNotRunning is returned when trying to communicate with a stopped plugin process.
+var ErrNotRunning = errors.New("the plugin process is not running")
+
+type message struct {
+	Id    uint64          `json:"id,omitempty"`
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+type logData struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// process manages a single plugin executable and its restarts.
+type process struct {
+	app             core.App
+	config          Process
+	timeout         time.Duration
+	maxRestartDelay time.Duration
+
+	mu       sync.RWMutex
+	conn     *conn
+	manifest *Manifest
+	stopped  bool
+	stopCh   chan struct{}
+
+	nextId atomic.Uint64
+}
+
+func newProcess(app core.App, config Process, timeout time.Duration, maxRestartDelay time.Duration) *process {
+	return &process{
+		app:             app,
+		config:          config,
+		timeout:         timeout,
+		maxRestartDelay: maxRestartDelay,
+		stopCh:          make(chan struct{}),
+	}
+}
+
+// start launches the plugin executable and waits for its manifest.
+func (p *process) start() error {
+	p.mu.RLock()
+	stopped := p.stopped
+	p.mu.RUnlock()
+	if stopped {
+		return ErrNotRunning
+	}
+
+	cmd := exec.Command(p.config.Command, p.config.Args...)
+	cmd.Dir = p.config.Dir
+	cmd.Env = append(os.Environ(), p.config.Env...)
+	cmd.Stderr = &stderrLogger{app: p.app, name: p.config.Name}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	c := &conn{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: map[uint64]chan *message{},
+		ready:   make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		p.readLoop(c, stdout)
+		c.close(cmd.Wait())
+		p.onExit(c)
+	}()
+
+	select {
+	case <-c.ready:
+	case <-c.done:
+		return fmt.Errorf("the plugin process exited before sending its manifest: %v", c.err)
+	case <-time.After(p.timeout):
+		cmd.Process.Kill()
+		return errors.New("timeout waiting for the plugin manifest")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopped {
+		cmd.Process.Kill()
+		return ErrNotRunning
+	}
+
+	p.conn = c
+
+	return nil
+}
+
+// stop terminates the plugin process and prevents its restart.
+//
+// The plugin is expected to exit on stdin EOF, otherwise it is killed after the timeout.
+func (p *process) stop() {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.stopped = true
+	close(p.stopCh)
+	c := p.conn
+	p.conn = nil
+	p.mu.Unlock()
+
+	if c == nil {
+		return
+	}
+
+	c.stdin.Close()
+
+	select {
+	case <-c.done:
+	case <-time.After(p.timeout):
+		c.cmd.Process.Kill()
+		<-c.done
+	}
+}
+
+// onExit restarts the exited plugin process with exponential backoff.
+func (p *process) onExit(c *conn) {
+	p.mu.Lock()
+	if p.conn != c || p.stopped {
+		p.mu.Unlock()
+		return // stopped or hasn't completed the initial start
+	}
+	p.conn = nil
+	p.mu.Unlock()
+
+	p.app.Logger().Warn("Plugin process exited", "plugin", p.config.Name, "error", fmt.Sprint(c.err))
+
+	delay := time.Second
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-time.After(delay):
+		}
+
+		err := p.start()
+		if err == nil {
+			p.app.Logger().Info("Plugin process restarted", "plugin", p.config.Name)
+			return
+		}
+
+		if errors.Is(err, ErrNotRunning) {
+			return
+		}
+
+		p.app.Logger().Error("Failed to restart plugin process", "plugin", p.config.Name, "error", err.Error())
+
+		delay = min(delay*2, p.maxRestartDelay)
+	}
+}
+
+func (p *process) readLoop(c *conn, stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		msg := &message{}
+		if err := json.Unmarshal(line, msg); err != nil {
+			p.app.Logger().Warn("Invalid plugin message", "plugin", p.config.Name, "error", err.Error())
+			continue
+		}
+
+		switch msg.Type {
+		case messageTypeManifest:
+			manifest := &Manifest{}
+			if err := json.Unmarshal(msg.Data, manifest); err != nil {
+				p.app.Logger().Warn("Invalid plugin manifest", "plugin", p.config.Name, "error", err.Error())
+				continue
+			}
+
+			p.mu.Lock()
+			p.manifest = manifest
+			p.mu.Unlock()
+
+			c.readyOnce.Do(func() { close(c.ready) })
+		case messageTypeReply:
+			c.resolve(msg)
+		case messageTypeLog:
+			data := logData{}
+			json.Unmarshal(msg.Data, &data)
+			p.log(data)
+		default:
+			p.app.Logger().Warn("Unknown plugin message type", "plugin", p.config.Name, "type", msg.Type)
+		}
+	}
+
+	// drain any remaining output to avoid blocking the process
+	io.Copy(io.Discard, stdout)
+}
+
+func (p *process) log(data logData) {
+	logger := p.app.Logger().With("plugin", p.config.Name)
+
+	switch strings.ToLower(data.Level) {
+	case "debug":
+		logger.Debug(data.Message)
+	case "warn", "warning":
+		logger.Warn(data.Message)
+	case "error":
+		logger.Error(data.Message)
+	default:
+		logger.Info(data.Message)
+	}
+}
+
+func (p *process) current() *conn {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.conn
+}
+
+// currentManifest returns the manifest of the last started plugin process.
+func (p *process) currentManifest() *Manifest {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.manifest
+}
+
+func (p *process) isSubscribed(hookName string, collection *core.Collection) bool {
+	manifest := p.currentManifest()
+	if manifest == nil {
+		return false
+	}
+
+	for _, sub := range manifest.Hooks {
+		if sub.Name != hookName {
+			continue
+		}
+
+		if len(sub.Collections) == 0 ||
+			slices.Contains(sub.Collections, collection.Name) ||
+			slices.Contains(sub.Collections, collection.Id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// call sends a message to the plugin and waits for its reply data.
+func (p *process) call(msgType string, data any) (json.RawMessage, error) {
+	c := p.current()
+	if c == nil {
+		return nil, ErrNotRunning
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	id := p.nextId.Add(1)
+
+	ch, err := c.addPending(id)
+	if err != nil {
+		return nil, err
+	}
+	defer c.removePending(id)
+
+	if err := c.write(&message{Id: id, Type: msgType, Data: raw}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case reply := <-ch:
+		if reply == nil {
+			return nil, ErrNotRunning
+		}
+		if reply.Error != "" {
+			return nil, errors.New(reply.Error)
+		}
+		return reply.Data, nil
+	case <-time.After(p.timeout):
+		return nil, errors.New("timeout waiting for the plugin reply")
+	}
+}
+
+// notify sends a message to the plugin without waiting for a reply.
+func (p *process) notify(msgType string, data any) error {
+	c := p.current()
+	if c == nil {
+		return ErrNotRunning
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return c.write(&message{Type: msgType, Data: raw})
+}
+
+// -------------------------------------------------------------------
+
+// conn holds the state of a single plugin process run.
+type conn struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[uint64]chan *message
+	closed  bool
+	err     error
+
+	readyOnce sync.Once
+	ready     chan struct{}
+	done      chan struct{}
+}
+
+func (c *conn) write(msg *message) error {
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_, err = c.stdin.Write(append(raw, '\n'))
+
+	return err
+}
+
+func (c *conn) addPending(id uint64) (chan *message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, ErrNotRunning
+	}
+
+	ch := make(chan *message, 1)
+	c.pending[id] = ch
+
+	return ch, nil
+}
+
+func (c *conn) removePending(id uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pending, id)
+}
+
+func (c *conn) resolve(msg *message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ch, ok := c.pending[msg.Id]; ok {
+		ch <- msg
+		delete(c.pending, msg.Id)
+	}
+}
+
+// close marks the connection as closed and unblocks all pending calls.
+func (c *conn) close(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	c.closed = true
+	c.err = err
+
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+
+	close(c.done)
+}
+
+// -------------------------------------------------------------------
+
+// stderrLogger forwards the plugin stderr lines to the app logger.
+type stderrLogger struct {
+	app  core.App
+	name string
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (l *stderrLogger) Write(b []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf = append(l.buf, b...)
+
+	for {
+		i := slices.Index(l.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		if line := strings.TrimSpace(string(l.buf[:i])); line != "" {
+			l.app.Logger().Warn(line, "plugin", l.name)
+		}
+
+		l.buf = l.buf[i+1:]
+	}
+
+	// prevent unbounded growth in case of missing new lines
+	if len(l.buf) > maxMessageSize {
+		l.buf = nil
+	}
+
+	return len(b), nil
+}
@@ -0,0 +1,104 @@
+package pluginhost
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestProcessIsSubscribed(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo2, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := newProcess(app, Process{Name: "test"}, DefaultTimeout, DefaultMaxRestartDelay)
+
+	if p.isSubscribed(HookRecordCreate, demo1) {
+		t.Fatal("Expected no subscriptions without manifest")
+	}
+
+	p.manifest = &Manifest{
+		Hooks: []HookSubscription{
+			{Name: HookRecordCreate},
+			{Name: HookRecordUpdate, Collections: []string{"demo1"}},
+			{Name: HookRecordDelete, Collections: []string{demo2.Id}},
+		},
+	}
+
+	scenarios := []struct {
+		hook       string
+		collection *core.Collection
+		expected   bool
+	}{
+		{HookRecordCreate, demo1, true},
+		{HookRecordCreate, demo2, true},
+		{HookRecordUpdate, demo1, true},
+		{HookRecordUpdate, demo2, false},
+		{HookRecordDelete, demo1, false},
+		{HookRecordDelete, demo2, true},
+		{HookRecordAfterCreateSuccess, demo1, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.hook+"_"+s.collection.Name, func(t *testing.T) {
+			result := p.isSubscribed(s.hook, s.collection)
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestProcessCallNotRunning(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	p := newProcess(app, Process{Name: "test"}, DefaultTimeout, DefaultMaxRestartDelay)
+
+	if _, err := p.call(messageTypeHook, nil); err != ErrNotRunning {
+		t.Fatalf("Expected ErrNotRunning, got %v", err)
+	}
+
+	if err := p.notify(messageTypeHook, nil); err != ErrNotRunning {
+		t.Fatalf("Expected ErrNotRunning, got %v", err)
+	}
+
+	// stopped process shouldn't be started
+	p.stop()
+	if err := p.start(); err != ErrNotRunning {
+		t.Fatalf("Expected ErrNotRunning after stop, got %v", err)
+	}
+}
+
+func TestConnClose(t *testing.T) {
+	c := &conn{
+		pending: map[uint64]chan *message{},
+		done:    make(chan struct{}),
+	}
+
+	ch, err := c.addPending(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.close(nil)
+	c.close(nil) // should be no-op
+
+	if msg := <-ch; msg != nil {
+		t.Fatalf("Expected nil message for the closed pending call, got %v", msg)
+	}
+
+	if _, err := c.addPending(2); err != ErrNotRunning {
+		t.Fatalf("Expected ErrNotRunning for closed conn, got %v", err)
+	}
+}
@@ -0,0 +1,391 @@
+// Package presence implements an optional maintenance of the auth
+// records online status based on their realtime connections.
+//
+// When an auth record realtime client submits its subscriptions, the
+// record is marked as online. The clients are periodically reconciled
+// with the app subscriptions broker and once the last realtime client of
+// the auth record disconnects (or loses its auth state), the record is
+// marked as offline.
+//
+// The changes are written with debounce (at most once per the configured
+// interval for each record) in the regular "lastSeen" (date) and "isOnline"
+// (bool) auth collection fields, which means that they could be used as
+// any other field in the API rules and list filters, e.g.:
+//
+//	isOnline = true || dateAdd(lastSeen, 5, "minutes") > @now
+//
+// Note that the plugin doesn't create the fields and the ones that are
+// missing in the auth collection are ignored.
+//
+// Example usage:
+//
+//	presence.MustRegister(app, presence.Config{
+//		Collections: []string{"users"},
+//	})
+package presence
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// DefaultLastSeenField is the default name of the auth collection
+	// date field that holds the last time the record was seen online.
+	DefaultLastSeenField = "lastSeen"
+
+	// DefaultIsOnlineField is the default name of the auth collection
+	// bool field that holds whether the record is currently online.
+	DefaultIsOnlineField = "isOnline"
+
+	// DefaultDebounce is the default interval between two presence writes.
+	DefaultDebounce = 10 * time.Second
+)
+
+// Config defines the config options of the presence plugin.
+type Config struct {
+	// Collections is a list with the names or ids of the auth
+	// collections whose records presence should be maintained.
+	//
+	// If empty, all auth collections with at least one of the
+	// LastSeenField or IsOnlineField fields are maintained.
+	Collections []string
+
+	// LastSeenField specifies the name of the date field that holds
+	// the last time the record was seen online (default to [DefaultLastSeenField]).
+	LastSeenField string
+
+	// IsOnlineField specifies the name of the bool field that holds
+	// whether the record has at least one active realtime connection
+	// (default to [DefaultIsOnlineField]).
+	IsOnlineField string
+
+	// Debounce specifies the interval at which the pending presence
+	// changes are written to the db (default to [DefaultDebounce]).
+	//
+	// It is also the max delay for detecting a disconnected client.
+	Debounce time.Duration
+}
+
+// MustRegister registers the presence plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the presence plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{
+		app:     app,
+		config:  config,
+		clients: map[string]string{},
+		online:  map[string]int{},
+		pending: map[string]time.Time{},
+	}
+
+	if p.config.LastSeenField == "" {
+		p.config.LastSeenField = DefaultLastSeenField
+	}
+
+	if p.config.IsOnlineField == "" {
+		p.config.IsOnlineField = DefaultIsOnlineField
+	}
+
+	if p.config.Debounce <= 0 {
+		p.config.Debounce = DefaultDebounce
+	}
+
+	p.app.OnRealtimeSubscribeRequest().BindFunc(func(e *core.RealtimeSubscribeRequestEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		if e.Auth != nil && p.isTracked(e.Auth.Collection()) {
+			p.track(e.Client.Id(), e.Auth)
+		}
+
+		return nil
+	})
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		// there are no active realtime connections on start
+		// (e.g. the previous process was not gracefully terminated)
+		if err := p.resetOnline(); err != nil {
+			p.app.Logger().Warn("Failed to reset the presence online state", slog.String("error", err.Error()))
+		}
+
+		p.start()
+
+		return e.Next()
+	})
+
+	p.app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+		p.stop()
+
+		return e.Next()
+	})
+
+	return nil
+}
+
+type plugin struct {
+	app    core.App
+	config Config
+
+	mu      sync.Mutex
+	clients map[string]string    // realtime client id -> presence key
+	online  map[string]int       // presence key -> number of tracked realtime clients
+	pending map[string]time.Time // presence key -> last seen time of the not yet written change
+	done    chan struct{}
+}
+
+// presenceKey returns the key that identifies the presence
+// state of the provided auth record.
+func presenceKey(record *core.Record) string {
+	return record.Collection().Id + "/" + record.Id
+}
+
+// isTracked reports whether the presence of the provided
+// collection records should be maintained.
+func (p *plugin) isTracked(collection *core.Collection) bool {
+	if collection == nil || !collection.IsAuth() {
+		return false
+	}
+
+	if len(p.config.Collections) > 0 &&
+		!slices.Contains(p.config.Collections, collection.Id) &&
+		!slices.Contains(p.config.Collections, collection.Name) {
+		return false
+	}
+
+	return p.lastSeenField(collection) != nil || p.isOnlineField(collection) != nil
+}
+
+func (p *plugin) lastSeenField(collection *core.Collection) core.Field {
+	field := collection.Fields.GetByName(p.config.LastSeenField)
+	if field == nil || field.Type() != core.FieldTypeDate {
+		return nil
+	}
+
+	return field
+}
+
+func (p *plugin) isOnlineField(collection *core.Collection) core.Field {
+	field := collection.Fields.GetByName(p.config.IsOnlineField)
+	if field == nil || field.Type() != core.FieldTypeBool {
+		return nil
+	}
+
+	return field
+}
+
+// track associates the realtime client with the provided auth
+// record and marks the record as online if it wasn't already.
+func (p *plugin) track(clientId string, auth *core.Record) {
+	key := presenceKey(auth)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if oldKey, ok := p.clients[clientId]; ok {
+		if oldKey == key {
+			return // already tracked (e.g. subscriptions change)
+		}
+		p.untrack(clientId, time.Now())
+	}
+
+	p.clients[clientId] = key
+	p.online[key]++
+
+	if p.online[key] == 1 {
+		p.pending[key] = time.Now()
+	}
+}
+
+// untrack removes the realtime client association and marks
+// its auth record as offline if there are no other clients.
+//
+// Note: the caller must hold the plugin lock.
+func (p *plugin) untrack(clientId string, lastSeen time.Time) {
+	key, ok := p.clients[clientId]
+	if !ok {
+		return
+	}
+
+	delete(p.clients, clientId)
+
+	p.online[key]--
+	if p.online[key] <= 0 {
+		delete(p.online, key)
+		p.pending[key] = lastSeen
+	}
+}
+
+// reconcile untracks the realtime clients that are no longer registered
+// in the subscriptions broker or that no longer have the same auth state
+// and marks their auth records as offline if there are no other clients.
+func (p *plugin) reconcile() {
+	broker := p.app.SubscriptionsBroker()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+
+	for clientId, key := range p.clients {
+		client, err := broker.ClientById(clientId)
+		if err == nil {
+			auth, _ := client.Get(apis.RealtimeClientAuthKey).(*core.Record)
+			if auth != nil && presenceKey(auth) == key {
+				continue // still active
+			}
+		}
+
+		p.untrack(clientId, now)
+	}
+}
+
+// flush reconciles the tracked realtime clients and
+// writes all pending presence changes to the db.
+func (p *plugin) flush() error {
+	p.reconcile()
+
+	p.mu.Lock()
+	pending := p.pending
+	p.pending = map[string]time.Time{}
+	online := make(map[string]bool, len(pending))
+	for key := range pending {
+		online[key] = p.online[key] > 0
+	}
+	p.mu.Unlock()
+
+	var errs []error
+
+	for key, lastSeen := range pending {
+		if err := p.write(key, lastSeen, online[key]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *plugin) flushAndLog() {
+	if err := p.flush(); err != nil {
+		p.app.Logger().Error("Failed to write the presence changes", slog.String("error", err.Error()))
+	}
+}
+
+// write updates the presence fields of the auth record with the specified key.
+func (p *plugin) write(key string, lastSeen time.Time, isOnline bool) error {
+	collectionId, recordId, _ := strings.Cut(key, "/")
+
+	collection, err := p.app.FindCachedCollectionByNameOrId(collectionId)
+	if err != nil {
+		return nil // the collection was deleted
+	}
+
+	record, err := p.app.FindRecordById(collection, recordId)
+	if err != nil {
+		return nil // the record was deleted
+	}
+
+	if f := p.lastSeenField(collection); f != nil {
+		lastSeenDate, err := types.ParseDateTime(lastSeen)
+		if err != nil {
+			return err
+		}
+		record.Set(f.GetName(), lastSeenDate)
+	}
+
+	if f := p.isOnlineField(collection); f != nil {
+		record.Set(f.GetName(), isOnline)
+	}
+
+	return p.app.SaveNoValidate(record)
+}
+
+// resetOnline marks all online records of the maintained collections as offline.
+func (p *plugin) resetOnline() error {
+	collections, err := p.app.FindAllCollections(core.CollectionTypeAuth)
+	if err != nil {
+		return err
+	}
+
+	for _, collection := range collections {
+		if !p.isTracked(collection) {
+			continue
+		}
+
+		f := p.isOnlineField(collection)
+		if f == nil {
+			continue
+		}
+
+		_, err := p.app.DB().Update(
+			collection.Name,
+			dbx.Params{f.GetName(): false},
+			dbx.HashExp{f.GetName(): true},
+		).Execute()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// start starts the background presence changes flush loop.
+func (p *plugin) start() {
+	p.mu.Lock()
+	if p.done != nil {
+		p.mu.Unlock()
+		return // already started
+	}
+	p.done = make(chan struct{})
+	done := p.done
+	p.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(p.config.Debounce)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				p.flushAndLog()
+			}
+		}
+	}()
+}
+
+// stop stops the flush loop, marks all tracked
+// records as offline and writes the pending changes.
+func (p *plugin) stop() {
+	p.mu.Lock()
+	if p.done != nil {
+		close(p.done)
+		p.done = nil
+	}
+
+	now := time.Now()
+	for clientId := range p.clients {
+		p.untrack(clientId, now)
+	}
+	p.mu.Unlock()
+
+	p.flushAndLog()
+}
@@ -0,0 +1,227 @@
+package presence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+const userId = "4q1xlclmfloku33"
+
+func TestPresenceFlush(t *testing.T) {
+	app := testApp(t)
+	defer app.Cleanup()
+
+	p := newTestPlugin(app, Config{})
+
+	user, err := app.FindRecordById("users", userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client1 := newTestClient(app, user)
+	client2 := newTestClient(app, user)
+
+	p.track(client1.Id(), user)
+	p.track(client1.Id(), user) // duplicated subscriptions submit
+	p.track(client2.Id(), user)
+
+	if err := p.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	firstSeen := checkPresence(t, app, true)
+	if firstSeen.IsZero() {
+		t.Fatal("Expected lastSeen to be set")
+	}
+
+	// no pending changes
+	if len(p.pending) != 0 {
+		t.Fatalf("Expected no pending changes, got %v", p.pending)
+	}
+
+	// disconnect one of the clients
+	app.SubscriptionsBroker().Unregister(client1.Id())
+
+	if err := p.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkPresence(t, app, true)
+
+	// unset the auth state of the other one (e.g. expired token)
+	client2.Unset(apis.RealtimeClientAuthKey)
+
+	time.Sleep(5 * time.Millisecond)
+
+	if err := p.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	lastSeen := checkPresence(t, app, false)
+	if !lastSeen.After(firstSeen) {
+		t.Fatalf("Expected lastSeen %v to be after %v", lastSeen, firstSeen)
+	}
+
+	if len(p.clients) != 0 || len(p.online) != 0 {
+		t.Fatalf("Expected no tracked clients, got %v (%v)", p.clients, p.online)
+	}
+}
+
+func TestPresenceIsTracked(t *testing.T) {
+	app := testApp(t)
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name       string
+		config     Config
+		collection string
+		expected   bool
+	}{
+		{"non-auth collection", Config{}, "demo1", false},
+		{"auth collection without presence fields", Config{}, core.CollectionNameSuperusers, false},
+		{"auth collection with presence fields", Config{}, "users", true},
+		{"auth collection not in Collections", Config{Collections: []string{"clients"}}, "users", false},
+		{"auth collection in Collections", Config{Collections: []string{"clients", "users"}}, "users", true},
+		{"auth collection with custom fields", Config{LastSeenField: "missing", IsOnlineField: "verified"}, "users", true},
+		{"auth collection with invalid fields type", Config{LastSeenField: "name", IsOnlineField: "email"}, "users", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			collection, err := app.FindCollectionByNameOrId(s.collection)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p := newTestPlugin(app, s.config)
+
+			if v := p.isTracked(collection); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestPresenceResetOnline(t *testing.T) {
+	app := testApp(t)
+	defer app.Cleanup()
+
+	user, err := app.FindRecordById("users", userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Set(DefaultIsOnlineField, true)
+	if err := app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := newTestPlugin(app, Config{}).resetOnline(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkPresence(t, app, false)
+}
+
+func TestPresenceHooks(t *testing.T) {
+	app := testApp(t)
+	defer app.Cleanup()
+
+	MustRegister(app, Config{})
+
+	user, err := app.FindRecordById("users", userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := newTestClient(app, user)
+
+	event := new(core.RealtimeSubscribeRequestEvent)
+	event.RequestEvent = &core.RequestEvent{App: app, Auth: user}
+	event.Client = client
+
+	err = app.OnRealtimeSubscribeRequest().Trigger(event, func(e *core.RealtimeSubscribeRequestEvent) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the pending changes are written on terminate
+	err = app.OnTerminate().Trigger(&core.TerminateEvent{App: app})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lastSeen := checkPresence(t, app, false)
+	if lastSeen.IsZero() {
+		t.Fatal("Expected lastSeen to be set")
+	}
+}
+
+func testApp(t testing.TB) *tests.TestApp {
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users.Fields.Add(
+		&core.DateField{Name: DefaultLastSeenField},
+		&core.BoolField{Name: DefaultIsOnlineField},
+	)
+
+	if err := app.Save(users); err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+func newTestPlugin(app core.App, config Config) *plugin {
+	if config.LastSeenField == "" {
+		config.LastSeenField = DefaultLastSeenField
+	}
+
+	if config.IsOnlineField == "" {
+		config.IsOnlineField = DefaultIsOnlineField
+	}
+
+	return &plugin{
+		app:     app,
+		config:  config,
+		clients: map[string]string{},
+		online:  map[string]int{},
+		pending: map[string]time.Time{},
+	}
+}
+
+func newTestClient(app core.App, auth *core.Record) subscriptions.Client {
+	client := subscriptions.NewDefaultClient()
+	client.Set(apis.RealtimeClientAuthKey, auth)
+
+	app.SubscriptionsBroker().Register(client)
+
+	return client
+}
+
+func checkPresence(t testing.TB, app core.App, expectedOnline bool) time.Time {
+	user, err := app.FindRecordById("users", userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := user.GetBool(DefaultIsOnlineField); v != expectedOnline {
+		t.Fatalf("Expected isOnline %v, got %v", expectedOnline, v)
+	}
+
+	return user.GetDateTime(DefaultLastSeenField).Time()
+}
@@ -0,0 +1,372 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/httpclient"
+)
+
+// ErrInvalidToken is returned by the providers when the device token
+// is no longer valid (e.g. the app was uninstalled) and the device should be removed.
+var ErrInvalidToken = errors.New("invalid or expired device token")
+
+// Provider defines a push service delivery client.
+type Provider interface {
+	// Send delivers the notification to a single device token.
+	//
+	// It should return [ErrInvalidToken] if the push service
+	// rejected the token as no longer valid.
+	Send(ctx context.Context, client httpclient.Client, token string, notification *core.Notification) error
+}
+
+// -------------------------------------------------------------------
+
+// FCM is a Firebase Cloud Messaging (HTTP v1 API) provider.
+//
+// The struct fields have json tags matching the Google service account
+// key file so it could be loaded directly with [json.Unmarshal].
+type FCM struct {
+	// ProjectId is the Firebase project id.
+	ProjectId string `json:"project_id"`
+
+	// ClientEmail is the service account email.
+	ClientEmail string `json:"client_email"`
+
+	// PrivateKey is the service account PEM encoded RSA private key.
+	PrivateKey string `json:"private_key"`
+
+	// TokenURL is the OAuth2 token endpoint (default to "https://oauth2.googleapis.com/token").
+	TokenURL string `json:"token_uri"`
+
+	// APIURL is the FCM API base url (default to "https://fcm.googleapis.com").
+	APIURL string `json:"-"`
+
+	mu          sync.Mutex
+	accessToken string
+	expires     time.Time
+}
+
+// Send implements [Provider.Send] interface method.
+func (p *FCM) Send(ctx context.Context, client httpclient.Client, token string, notification *core.Notification) error {
+	accessToken, err := p.getAccessToken(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	message := map[string]any{
+		"token": token,
+		"notification": map[string]any{
+			"title": notification.Title,
+			"body":  notification.Body,
+		},
+	}
+
+	if len(notification.Data) > 0 {
+		message["data"] = notification.Data
+	}
+
+	android := map[string]any{}
+	if notification.TTL > 0 {
+		android["ttl"] = strconv.Itoa(notification.TTL) + "s"
+	}
+	if notification.Sound != "" {
+		android["notification"] = map[string]any{"sound": notification.Sound}
+	}
+	if len(android) > 0 {
+		message["android"] = android
+	}
+
+	if aps := apsPayload(notification); len(aps) > 0 {
+		message["apns"] = map[string]any{"payload": map[string]any{"aps": aps}}
+	}
+
+	body, err := json.Marshal(map[string]any{"message": message})
+	if err != nil {
+		return err
+	}
+
+	apiURL := p.APIURL
+	if apiURL == "" {
+		apiURL = "https://fcm.googleapis.com"
+	}
+	apiURL = strings.TrimRight(apiURL, "/") + "/v1/projects/" + url.PathEscape(p.ProjectId) + "/messages:send"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	status, resBody, err := do(client, req)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status >= 200 && status < 300:
+		return nil
+	case status == http.StatusNotFound || bytes.Contains(resBody, []byte("UNREGISTERED")):
+		return ErrInvalidToken
+	case status == http.StatusUnauthorized:
+		p.mu.Lock()
+		p.accessToken = "" // force refresh on the next attempt
+		p.mu.Unlock()
+	}
+
+	return fmt.Errorf("FCM request failed with status %d: %s", status, resBody)
+}
+
+// getAccessToken returns a cached or a new OAuth2 access token
+// obtained with the service account signed JWT assertion.
+func (p *FCM) getAccessToken(ctx context.Context, client httpclient.Client) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Before(p.expires) {
+		return p.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(p.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid FCM private key: %w", err)
+	}
+
+	tokenURL := p.TokenURL
+	if tokenURL == "" {
+		tokenURL = "https://oauth2.googleapis.com/token"
+	}
+
+	now := time.Now()
+
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.ClientEmail,
+		"scope": "https://www.googleapis.com/auth/firebase.messaging",
+		"aud":   tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	status, resBody, err := do(client, req)
+	if err != nil {
+		return "", err
+	}
+	if status < 200 || status >= 300 {
+		return "", fmt.Errorf("FCM access token request failed with status %d: %s", status, resBody)
+	}
+
+	result := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}{}
+	if err := json.Unmarshal(resBody, &result); err != nil {
+		return "", err
+	}
+	if result.AccessToken == "" {
+		return "", errors.New("missing FCM access token")
+	}
+
+	p.accessToken = result.AccessToken
+	// refresh a little earlier to account for the request latency
+	p.expires = now.Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+
+	return p.accessToken, nil
+}
+
+// -------------------------------------------------------------------
+
+// APNs is an Apple Push Notification service provider
+// using the token-based (.p8 key) authentication.
+type APNs struct {
+	// KeyId is the 10 characters .p8 signing key id.
+	KeyId string
+
+	// TeamId is the 10 characters Apple developer team id.
+	TeamId string
+
+	// PrivateKey is the PEM encoded .p8 signing key.
+	PrivateKey string
+
+	// Topic is the app bundle id.
+	Topic string
+
+	// Sandbox specifies whether to use the development APNs environment.
+	Sandbox bool
+
+	// APIURL is an optional APNs base url that replaces the
+	// default production or sandbox one (usually used for tests).
+	APIURL string
+
+	mu     sync.Mutex
+	token  string
+	issued time.Time
+}
+
+// Send implements [Provider.Send] interface method.
+func (p *APNs) Send(ctx context.Context, client httpclient.Client, token string, notification *core.Notification) error {
+	authToken, err := p.getAuthToken()
+	if err != nil {
+		return err
+	}
+
+	aps := apsPayload(notification)
+	aps["alert"] = map[string]any{
+		"title": notification.Title,
+		"body":  notification.Body,
+	}
+
+	payload := map[string]any{}
+	for k, v := range notification.Data {
+		payload[k] = v
+	}
+	payload["aps"] = aps
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	apiURL := p.APIURL
+	if apiURL == "" {
+		if p.Sandbox {
+			apiURL = "https://api.sandbox.push.apple.com"
+		} else {
+			apiURL = "https://api.push.apple.com"
+		}
+	}
+	apiURL = strings.TrimRight(apiURL, "/") + "/3/device/" + url.PathEscape(token)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+authToken)
+	req.Header.Set("apns-topic", p.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	if notification.TTL > 0 {
+		req.Header.Set("apns-expiration", strconv.FormatInt(time.Now().Add(time.Duration(notification.TTL)*time.Second).Unix(), 10))
+	}
+
+	status, resBody, err := do(client, req)
+	if err != nil {
+		return err
+	}
+
+	if status >= 200 && status < 300 {
+		return nil
+	}
+
+	result := struct {
+		Reason string `json:"reason"`
+	}{}
+	_ = json.Unmarshal(resBody, &result)
+
+	switch {
+	case status == http.StatusGone,
+		result.Reason == "BadDeviceToken",
+		result.Reason == "Unregistered",
+		result.Reason == "DeviceTokenNotForTopic":
+		return ErrInvalidToken
+	case result.Reason == "ExpiredProviderToken":
+		p.mu.Lock()
+		p.token = "" // force regenerate on the next attempt
+		p.mu.Unlock()
+	}
+
+	return fmt.Errorf("APNs request failed with status %d: %s", status, result.Reason)
+}
+
+// getAuthToken returns a cached or a new provider authentication token.
+//
+// APNs rejects tokens older than 1 hour and too frequent token updates
+// so the token is regenerated every 50 minutes.
+func (p *APNs) getAuthToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Since(p.issued) < 50*time.Minute {
+		return p.token, nil
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM([]byte(p.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid APNs private key: %w", err)
+	}
+
+	now := time.Now()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.TeamId,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.KeyId
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	p.token = signed
+	p.issued = now
+
+	return p.token, nil
+}
+
+// -------------------------------------------------------------------
+
+func apsPayload(notification *core.Notification) map[string]any {
+	aps := map[string]any{}
+
+	if notification.Sound != "" {
+		aps["sound"] = notification.Sound
+	}
+
+	if notification.Badge > 0 {
+		aps["badge"] = notification.Badge
+	}
+
+	return aps
+}
+
+// do sends the request and returns the response status code and body.
+func do(client httpclient.Client, req *http.Request) (int, []byte, error) {
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return res.StatusCode, body, nil
+}
@@ -0,0 +1,314 @@
+package push
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/httpclient"
+	"golang.org/x/crypto/hkdf"
+)
+
+func TestFCMSend(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+
+	var sendStatus int
+
+	mock := httpclient.NewMock(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/token") {
+			r.ParseForm()
+
+			_, err := jwt.Parse(r.PostForm.Get("assertion"), func(token *jwt.Token) (any, error) {
+				return &rsaKey.PublicKey, nil
+			})
+			if err != nil {
+				w.WriteHeader(400)
+				return
+			}
+
+			w.Write([]byte(`{"access_token":"test_access","expires_in":3600}`))
+			return
+		}
+
+		w.WriteHeader(sendStatus)
+	}))
+
+	fcm := &FCM{}
+	err = json.Unmarshal([]byte(`{
+		"project_id": "test_project",
+		"client_email": "test@example.com",
+		"private_key": `+strconv.Quote(string(privateKey))+`,
+		"token_uri": "https://oauth.example.com/token"
+	}`), fcm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcm.APIURL = "https://fcm.example.com"
+
+	notification := &core.Notification{Title: "test", Body: "test_body", Data: map[string]string{"a": "b"}, Badge: 2, TTL: 60}
+
+	sendStatus = 200
+	if err := fcm.Send(context.Background(), mock, "device_token", notification); err != nil {
+		t.Fatal(err)
+	}
+
+	sendStatus = 404
+	if err := fcm.Send(context.Background(), mock, "device_token", notification); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Expected ErrInvalidToken, got %v", err)
+	}
+
+	sendStatus = 500
+	if err := fcm.Send(context.Background(), mock, "device_token", notification); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Expected non ErrInvalidToken error, got %v", err)
+	}
+
+	requests := mock.Requests()
+
+	// 1 token request (reused) + 3 send requests
+	if len(requests) != 4 {
+		t.Fatalf("Expected 4 requests, got %d", len(requests))
+	}
+
+	send := requests[1]
+
+	if send.URL != "https://fcm.example.com/v1/projects/test_project/messages:send" {
+		t.Fatalf("Unexpected send url %q", send.URL)
+	}
+
+	if auth := send.Header.Get("Authorization"); auth != "Bearer test_access" {
+		t.Fatalf("Unexpected Authorization header %q", auth)
+	}
+
+	expectedBody := `{"message":{"android":{"ttl":"60s"},"apns":{"payload":{"aps":{"badge":2}}},"data":{"a":"b"},"notification":{"body":"test_body","title":"test"},"token":"device_token"}}`
+	if string(send.Body) != expectedBody {
+		t.Fatalf("Expected body\n%s\ngot\n%s", expectedBody, send.Body)
+	}
+}
+
+func TestAPNsSend(t *testing.T) {
+	t.Parallel()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apns := &APNs{
+		KeyId:      "test_kid",
+		TeamId:     "test_team",
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		Topic:      "com.example.app",
+		APIURL:     "https://apns.example.com",
+	}
+
+	var status int
+	var body string
+
+	mock := httpclient.NewMock(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+
+	notification := &core.Notification{Title: "test", Body: "test_body", Data: map[string]string{"a": "b"}, Sound: "default"}
+
+	status, body = 200, ""
+	if err := apns.Send(context.Background(), mock, "device_token", notification); err != nil {
+		t.Fatal(err)
+	}
+
+	status, body = 410, `{"reason":"Unregistered"}`
+	if err := apns.Send(context.Background(), mock, "device_token", notification); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Expected ErrInvalidToken, got %v", err)
+	}
+
+	status, body = 400, `{"reason":"BadDeviceToken"}`
+	if err := apns.Send(context.Background(), mock, "device_token", notification); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Expected ErrInvalidToken, got %v", err)
+	}
+
+	status, body = 429, `{"reason":"TooManyRequests"}`
+	if err := apns.Send(context.Background(), mock, "device_token", notification); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Expected non ErrInvalidToken error, got %v", err)
+	}
+
+	req := mock.Requests()[0]
+
+	if req.URL != "https://apns.example.com/3/device/device_token" {
+		t.Fatalf("Unexpected url %q", req.URL)
+	}
+
+	if topic := req.Header.Get("apns-topic"); topic != "com.example.app" {
+		t.Fatalf("Unexpected apns-topic header %q", topic)
+	}
+
+	authToken, err := jwt.Parse(strings.TrimPrefix(req.Header.Get("Authorization"), "bearer "), func(token *jwt.Token) (any, error) {
+		return &ecKey.PublicKey, nil
+	})
+	if err != nil {
+		t.Fatalf("Invalid auth token: %v", err)
+	}
+	if authToken.Header["kid"] != "test_kid" || authToken.Claims.(jwt.MapClaims)["iss"] != "test_team" {
+		t.Fatalf("Unexpected auth token %v %v", authToken.Header, authToken.Claims)
+	}
+
+	expectedBody := `{"a":"b","aps":{"alert":{"body":"test_body","title":"test"},"sound":"default"}}`
+	if string(req.Body) != expectedBody {
+		t.Fatalf("Expected body\n%s\ngot\n%s", expectedBody, req.Body)
+	}
+}
+
+func TestWebPushSend(t *testing.T) {
+	t.Parallel()
+
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	webpush := &WebPush{PublicKey: publicKey, PrivateKey: privateKey, Subject: "mailto:test@example.com"}
+
+	// browser subscription keys
+	uaKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authSecret := make([]byte, 16)
+	rand.Read(authSecret)
+
+	subscription, _ := json.Marshal(map[string]any{
+		"endpoint": "https://push.example.com/send/abc",
+		"keys": map[string]string{
+			"p256dh": base64.RawURLEncoding.EncodeToString(uaKey.PublicKey().Bytes()),
+			"auth":   base64.RawURLEncoding.EncodeToString(authSecret),
+		},
+	})
+
+	var status int
+
+	mock := httpclient.NewMock(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+
+	notification := &core.Notification{Title: "test", Body: "test_body"}
+
+	status = 201
+	if err := webpush.Send(context.Background(), mock, string(subscription), notification); err != nil {
+		t.Fatal(err)
+	}
+
+	status = 410
+	if err := webpush.Send(context.Background(), mock, string(subscription), notification); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Expected ErrInvalidToken, got %v", err)
+	}
+
+	if err := webpush.Send(context.Background(), mock, "invalid", notification); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Expected ErrInvalidToken for invalid subscription, got %v", err)
+	}
+
+	req := mock.Requests()[0]
+
+	if req.URL != "https://push.example.com/send/abc" {
+		t.Fatalf("Unexpected url %q", req.URL)
+	}
+
+	if ttl := req.Header.Get("TTL"); ttl != "86400" {
+		t.Fatalf("Expected default TTL header, got %q", ttl)
+	}
+
+	// verify the VAPID authorization
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "vapid t=") || !strings.HasSuffix(auth, ", k="+publicKey) {
+		t.Fatalf("Unexpected Authorization header %q", auth)
+	}
+	vapidToken := strings.TrimSuffix(strings.TrimPrefix(auth, "vapid t="), ", k="+publicKey)
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(vapidToken, claims, func(token *jwt.Token) (any, error) {
+		pub, _ := base64.RawURLEncoding.DecodeString(publicKey)
+		x, y := elliptic.Unmarshal(elliptic.P256(), pub)
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	})
+	if err != nil {
+		t.Fatalf("Invalid VAPID token: %v", err)
+	}
+	if claims["aud"] != "https://push.example.com" || claims["sub"] != "mailto:test@example.com" {
+		t.Fatalf("Unexpected VAPID claims %v", claims)
+	}
+
+	// decrypt the payload as the browser would do
+	body := req.Body
+	salt := body[:16]
+	if rs := binary.BigEndian.Uint32(body[16:20]); rs != 4096 {
+		t.Fatalf("Expected record size 4096, got %d", rs)
+	}
+	idLen := int(body[20])
+	asPublicBytes := body[21 : 21+idLen]
+	ciphertext := body[21+idLen:]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared, err := uaKey.ECDH(asPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaKey.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublicBytes...)
+	ikm := make([]byte, 32)
+	io.ReadFull(hkdf.New(sha256.New, shared, authSecret, keyInfo), ikm)
+	cek := make([]byte, 16)
+	io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: aes128gcm\x00")), cek)
+	nonce := make([]byte, 12)
+	io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: nonce\x00")), nonce)
+
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("Failed to decrypt the payload: %v", err)
+	}
+
+	if plaintext[len(plaintext)-1] != 0x02 {
+		t.Fatal("Expected last record padding delimiter")
+	}
+
+	decoded := new(core.Notification)
+	if err := json.Unmarshal(plaintext[:len(plaintext)-1], decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Title != "test" || decoded.Body != "test_body" {
+		t.Fatalf("Unexpected decrypted notification %#v", decoded)
+	}
+}
@@ -0,0 +1,535 @@
+// Package push implements a push notifications delivery subsystem
+// with FCM, APNs and Web Push providers.
+//
+// The auth records register their device tokens with
+// "POST /api/push/devices" (the devices are stored in the "_pushDevices"
+// system collection and could be listed and deleted by their owner with
+// the regular records API).
+//
+// Once registered, the plugin handles the [core.App.Notify] calls by
+// fanning out the notification to all devices of the record. Each device
+// delivery is logged in the "_pushDeliveries" system collection and the
+// failed deliveries are retried with exponential backoff by a cron job.
+// Devices with tokens rejected by the push service are removed automatically.
+//
+// Example usage:
+//
+//	push.MustRegister(app, push.Config{
+//		Providers: map[string]push.Provider{
+//			"fcm":     &push.FCM{ProjectId: "...", ClientEmail: "...", PrivateKey: "..."},
+//			"apns":    &push.APNs{KeyId: "...", TeamId: "...", PrivateKey: "...", Topic: "com.example.app"},
+//			"webpush": &push.WebPush{PublicKey: "...", PrivateKey: "...", Subject: "mailto:admin@example.com"},
+//		},
+//	})
+//
+//	app.OnRecordAfterCreateSuccess("messages").BindFunc(func(e *core.RecordEvent) error {
+//		recipient, err := e.App.FindRecordById("users", e.Record.GetString("recipient"))
+//		if err == nil {
+//			err = e.App.Notify(recipient, &core.Notification{Title: "New message", Body: e.Record.GetString("text")})
+//		}
+//		if err != nil {
+//			e.App.Logger().Error("Failed to send message notification", "error", err)
+//		}
+//
+//		return e.Next()
+//	})
+package push
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/httpclient"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	CollectionNameDevices    = "_pushDevices"
+	CollectionNameDeliveries = "_pushDeliveries"
+)
+
+// Delivery statuses.
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+)
+
+const (
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultMaxAttempts is the default max number of delivery attempts per device.
+	DefaultMaxAttempts = 5
+
+	// DefaultRetryBackoff is the default delay before the first delivery retry
+	// (it is doubled after each failed attempt).
+	DefaultRetryBackoff = time.Minute
+
+	// DefaultRetention is the default retention period of the completed delivery logs.
+	DefaultRetention = 7 * 24 * time.Hour
+)
+
+const (
+	retryCronId   = "__pushDeliveriesRetry__"
+	cleanupCronId = "__pushDeliveriesCleanup__"
+
+	// retryBatchSize is the max number of pending deliveries processed per retry run.
+	retryBatchSize = 100
+)
+
+// Config defines the config options of the push plugin.
+type Config struct {
+	// Providers are the enabled push providers keyed by the name
+	// used in the device registration (e.g. "fcm", "apns", "webpush").
+	Providers map[string]Provider
+
+	// AuthCollections is an optional list of auth collections whose
+	// records are allowed to register devices (default to all auth collections).
+	AuthCollections []string
+
+	// Client is an optional HTTP client for the push services calls
+	// (default to [http.Client] with [DefaultTimeout]).
+	//
+	// In tests it could be replaced with [httpclient.Mock].
+	Client httpclient.Client
+
+	// MaxAttempts is the max number of delivery attempts per device
+	// (default to [DefaultMaxAttempts]).
+	MaxAttempts int
+
+	// RetryBackoff is the delay before the first delivery retry
+	// (default to [DefaultRetryBackoff]).
+	RetryBackoff time.Duration
+
+	// Retention is the retention period of the sent and failed
+	// delivery logs (default to [DefaultRetention]).
+	Retention time.Duration
+}
+
+// Validate makes Config validatable by implementing [validation.Validatable] interface.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Providers, validation.Required),
+	)
+}
+
+// MustRegister registers the push plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the push plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	p := &plugin{app: app, config: config}
+
+	if p.config.Client == nil {
+		p.config.Client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	if p.config.MaxAttempts <= 0 {
+		p.config.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if p.config.RetryBackoff <= 0 {
+		p.config.RetryBackoff = DefaultRetryBackoff
+	}
+
+	if p.config.Retention <= 0 {
+		p.config.Retention = DefaultRetention
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollections(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollections()
+		})
+	}
+
+	// register the plugin as the default notifier
+	// (it could be still replaced by the next OnRecordNotify handlers)
+	p.app.OnRecordNotify().BindFunc(func(e *core.RecordNotifyEvent) error {
+		e.Notifier = p
+
+		return e.Next()
+	})
+
+	// remove the devices of the deleted auth records
+	p.app.OnRecordDeleteExecute().BindFunc(func(e *core.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		if !e.Record.Collection().IsAuth() {
+			return nil
+		}
+
+		_, err := e.App.DB().Delete(CollectionNameDevices, dbx.HashExp{
+			"collectionRef": e.Record.Collection().Id,
+			"recordRef":     e.Record.Id,
+		}).Execute()
+
+		return err
+	})
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group("/api/push")
+		sub.POST("/devices", p.registerDevice).Bind(apis.RequireAuth(p.config.AuthCollections...))
+
+		return e.Next()
+	})
+
+	err := p.app.Cron().Add(retryCronId, "* * * * *", func() {
+		if err := p.retryPending(context.Background()); err != nil {
+			p.app.Logger().Error("Failed to retry the pending push deliveries", "error", err.Error())
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return p.app.Cron().Add(cleanupCronId, "15 * * * *", func() {
+		_, err := p.app.DB().Delete(CollectionNameDeliveries, dbx.And(
+			dbx.NewExp("[[status]] != {:status}", dbx.Params{"status": StatusPending}),
+			dbx.NewExp("[[created]] < {:date}", dbx.Params{
+				"date": time.Now().Add(-p.config.Retention).UTC().Format(types.DefaultDateLayout),
+			}),
+		)).Execute()
+		if err != nil {
+			p.app.Logger().Error("Failed to delete old push deliveries", "error", err.Error())
+		}
+	})
+}
+
+// FindDeliveries returns the delivery logs of the specified record
+// ordered by their creation date.
+func FindDeliveries(app core.App, record *core.Record) ([]*core.Record, error) {
+	return app.FindRecordsByFilter(
+		CollectionNameDeliveries,
+		"collectionRef = {:collectionId} && recordRef = {:recordId}",
+		"created",
+		0,
+		0,
+		dbx.Params{"collectionId": record.Collection().Id, "recordId": record.Id},
+	)
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+// ensureCollections creates the plugin system collections (if missing).
+func (p *plugin) ensureCollections() error {
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameDevices); err != nil {
+		// the auth record could list, view and unregister only its own devices
+		ownerRule := types.Pointer("@request.auth.id != '' && collectionRef = @request.auth.collectionId && recordRef = @request.auth.id")
+
+		devices := core.NewBaseCollection(CollectionNameDevices)
+		devices.System = true
+		devices.ListRule = ownerRule
+		devices.ViewRule = ownerRule
+		devices.DeleteRule = ownerRule
+		addOwnerFields(devices)
+		devices.Fields.Add(&core.TextField{
+			Name:     "provider",
+			System:   true,
+			Required: true,
+		})
+		devices.Fields.Add(&core.TextField{
+			Name:     "token",
+			System:   true,
+			Required: true,
+			Max:      5000,
+		})
+		addAutodateFields(devices)
+		devices.AddIndex("idx_pushDevices_token", true, "provider,token", "")
+		devices.AddIndex("idx_pushDevices_owner", false, "collectionRef,recordRef", "")
+
+		if err := p.app.Save(devices); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameDeliveries); err != nil {
+		deliveries := core.NewBaseCollection(CollectionNameDeliveries)
+		deliveries.System = true
+		addOwnerFields(deliveries)
+		deliveries.Fields.Add(&core.TextField{
+			Name:     "device",
+			System:   true,
+			Required: true,
+		})
+		deliveries.Fields.Add(&core.TextField{
+			Name:     "provider",
+			System:   true,
+			Required: true,
+		})
+		deliveries.Fields.Add(&core.JSONField{
+			Name:   "notification",
+			System: true,
+		})
+		deliveries.Fields.Add(&core.SelectField{
+			Name:      "status",
+			System:    true,
+			Required:  true,
+			MaxSelect: 1,
+			Values:    []string{StatusPending, StatusSent, StatusFailed},
+		})
+		deliveries.Fields.Add(&core.NumberField{
+			Name:    "attempts",
+			System:  true,
+			OnlyInt: true,
+		})
+		deliveries.Fields.Add(&core.TextField{
+			Name:   "error",
+			System: true,
+		})
+		deliveries.Fields.Add(&core.DateField{
+			Name:   "nextAttempt",
+			System: true,
+		})
+		addAutodateFields(deliveries)
+		deliveries.AddIndex("idx_pushDeliveries_status", false, "status,nextAttempt", "")
+		deliveries.AddIndex("idx_pushDeliveries_owner", false, "collectionRef,recordRef", "")
+
+		if err := p.app.Save(deliveries); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addOwnerFields(collection *core.Collection) {
+	collection.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	collection.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+}
+
+func addAutodateFields(collection *core.Collection) {
+	collection.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+}
+
+// -------------------------------------------------------------------
+
+type deviceForm struct {
+	Provider string `form:"provider" json:"provider"`
+	Token    string `form:"token" json:"token"`
+}
+
+func (p *plugin) registerDevice(e *core.RequestEvent) error {
+	form := new(deviceForm)
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	err := validation.ValidateStruct(form,
+		validation.Field(&form.Provider, validation.Required, validation.By(func(value any) error {
+			if _, ok := p.config.Providers[form.Provider]; !ok {
+				return validation.NewError("validation_invalid_provider", "Invalid or unsupported push provider.")
+			}
+			return nil
+		})),
+		validation.Field(&form.Token, validation.Required, validation.Length(1, 5000)),
+	)
+	if err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	device, err := e.App.FindFirstRecordByFilter(
+		CollectionNameDevices,
+		"provider = {:provider} && token = {:token}",
+		dbx.Params{"provider": form.Provider, "token": form.Token},
+	)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return e.InternalServerError("Failed to load the device.", err)
+		}
+
+		collection, err := e.App.FindCachedCollectionByNameOrId(CollectionNameDevices)
+		if err != nil {
+			return e.InternalServerError("Missing devices collection.", err)
+		}
+
+		device = core.NewRecord(collection)
+		device.Set("provider", form.Provider)
+		device.Set("token", form.Token)
+	}
+
+	// (re)assign the device to the current auth record
+	// (e.g. in case of a different user login on the same device)
+	device.Set("collectionRef", e.Auth.Collection().Id)
+	device.Set("recordRef", e.Auth.Id)
+
+	if err := e.App.Save(device); err != nil {
+		return e.BadRequestError("Failed to register the device.", err)
+	}
+
+	return e.JSON(http.StatusOK, device)
+}
+
+// -------------------------------------------------------------------
+
+// Notify implements [core.Notifier] interface method.
+//
+// It creates a delivery log for each device of the record and
+// attempts to deliver it immediately. The failed deliveries are
+// retried later by the retry cron job.
+func (p *plugin) Notify(ctx context.Context, record *core.Record, notification *core.Notification) error {
+	devices, err := p.app.FindAllRecords(CollectionNameDevices, dbx.HashExp{
+		"collectionRef": record.Collection().Id,
+		"recordRef":     record.Id,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(devices) == 0 {
+		return nil
+	}
+
+	deliveries, err := p.app.FindCachedCollectionByNameOrId(CollectionNameDeliveries)
+	if err != nil {
+		return err
+	}
+
+	for _, device := range devices {
+		delivery := core.NewRecord(deliveries)
+		delivery.Set("collectionRef", record.Collection().Id)
+		delivery.Set("recordRef", record.Id)
+		delivery.Set("device", device.Id)
+		delivery.Set("provider", device.GetString("provider"))
+		delivery.Set("notification", notification)
+		delivery.Set("status", StatusPending)
+
+		if err := p.deliver(ctx, delivery, device); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retryPending retries the pending deliveries whose next attempt time has passed.
+func (p *plugin) retryPending(ctx context.Context) error {
+	deliveries, err := p.app.FindRecordsByFilter(
+		CollectionNameDeliveries,
+		"status = {:status} && nextAttempt <= {:now}",
+		"nextAttempt",
+		retryBatchSize,
+		0,
+		dbx.Params{"status": StatusPending, "now": types.NowDateTime()},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		device, err := p.app.FindRecordById(CollectionNameDevices, delivery.GetString("device"))
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			device = nil // unregistered in the meantime
+		}
+
+		if err := p.deliver(ctx, delivery, device); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deliver sends a single delivery attempt and persists its result.
+//
+// The returned error is only for the delivery log persistence failures
+// (the push services errors are stored in the delivery log).
+func (p *plugin) deliver(ctx context.Context, delivery *core.Record, device *core.Record) error {
+	sendErr := p.send(ctx, delivery, device)
+
+	attempts := delivery.GetInt("attempts") + 1
+	delivery.Set("attempts", attempts)
+
+	switch {
+	case sendErr == nil:
+		delivery.Set("status", StatusSent)
+		delivery.Set("error", "")
+		delivery.Set("nextAttempt", "")
+	case errors.Is(sendErr, ErrInvalidToken) || attempts >= p.config.MaxAttempts:
+		delivery.Set("status", StatusFailed)
+		delivery.Set("error", sendErr.Error())
+		delivery.Set("nextAttempt", "")
+	default:
+		backoff := p.config.RetryBackoff << (attempts - 1)
+		delivery.Set("status", StatusPending)
+		delivery.Set("error", sendErr.Error())
+		delivery.Set("nextAttempt", types.NowDateTime().Add(backoff))
+	}
+
+	if errors.Is(sendErr, ErrInvalidToken) && device != nil {
+		if err := p.app.Delete(device); err != nil {
+			p.app.Logger().Error("Failed to delete push device with invalid token", "id", device.Id, "error", err.Error())
+		}
+	}
+
+	return p.app.Save(delivery)
+}
+
+func (p *plugin) send(ctx context.Context, delivery *core.Record, device *core.Record) error {
+	if device == nil {
+		return ErrInvalidToken
+	}
+
+	provider, ok := p.config.Providers[delivery.GetString("provider")]
+	if !ok {
+		return errors.New("unsupported push provider " + delivery.GetString("provider"))
+	}
+
+	notification := new(core.Notification)
+	if err := delivery.UnmarshalJSONField("notification", notification); err != nil {
+		return err
+	}
+
+	return provider.Send(ctx, p.config.Client, device.GetString("token"), notification)
+}
@@ -0,0 +1,240 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/httpclient"
+	"golang.org/x/crypto/hkdf"
+)
+
+// DefaultWebPushTTL is the default Web Push message TTL in seconds
+// used when the notification doesn't specify one.
+const DefaultWebPushTTL = 86400
+
+// WebPush is a Web Push provider with VAPID authentication (RFC 8292)
+// and aes128gcm payload encryption (RFC 8291).
+//
+// The device token is expected to be the JSON serialized browser
+// PushSubscription, e.g. JSON.stringify(await registration.pushManager.subscribe(...)).
+type WebPush struct {
+	// PublicKey is the base64url encoded uncompressed P-256 VAPID public key.
+	PublicKey string
+
+	// PrivateKey is the base64url encoded P-256 VAPID private key.
+	PrivateKey string
+
+	// Subject is the VAPID contact url ("mailto:" or "https:").
+	Subject string
+}
+
+// GenerateVAPIDKeys generates a new base64url encoded VAPID key pair.
+func GenerateVAPIDKeys() (publicKey string, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()),
+		nil
+}
+
+// Send implements [Provider.Send] interface method.
+func (p *WebPush) Send(ctx context.Context, client httpclient.Client, token string, notification *core.Notification) error {
+	subscription := struct {
+		Endpoint string `json:"endpoint"`
+		Keys     struct {
+			P256dh string `json:"p256dh"`
+			Auth   string `json:"auth"`
+		} `json:"keys"`
+	}{}
+	if err := json.Unmarshal([]byte(token), &subscription); err != nil || subscription.Endpoint == "" {
+		return ErrInvalidToken
+	}
+
+	endpoint, err := url.Parse(subscription.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return ErrInvalidToken
+	}
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	body, err := encryptWebPushPayload(payload, subscription.Keys.P256dh, subscription.Keys.Auth)
+	if err != nil {
+		return errors.Join(ErrInvalidToken, err)
+	}
+
+	authorization, err := p.authorization(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return err
+	}
+
+	ttl := notification.TTL
+	if ttl <= 0 {
+		ttl = DefaultWebPushTTL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(ttl))
+	req.Header.Set("Authorization", authorization)
+
+	status, resBody, err := do(client, req)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status >= 200 && status < 300:
+		return nil
+	case status == http.StatusNotFound || status == http.StatusGone:
+		return ErrInvalidToken
+	}
+
+	return fmt.Errorf("Web Push request failed with status %d: %s", status, resBody)
+}
+
+// authorization returns the VAPID Authorization header value for the push service origin.
+func (p *WebPush) authorization(audience string) (string, error) {
+	d, err := base64.RawURLEncoding.DecodeString(p.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	ecdhKey, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return "", fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	// uncompressed point format: 0x04 || X || Y
+	pub := ecdhKey.PublicKey().Bytes()
+
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(pub[1:33]),
+			Y:     new(big.Int).SetBytes(pub[33:]),
+		},
+		D: new(big.Int).SetBytes(d),
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": audience,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": p.Subject,
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	return "vapid t=" + signed + ", k=" + base64.RawURLEncoding.EncodeToString(pub), nil
+}
+
+// encryptWebPushPayload encrypts the payload for the subscription keys
+// as a single aes128gcm record (RFC 8291 and RFC 8188).
+func encryptWebPushPayload(payload []byte, p256dh string, auth string) ([]byte, error) {
+	uaPublicBytes, err := decodeBase64URL(p256dh)
+	if err != nil {
+		return nil, err
+	}
+
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	authSecret, err := decodeBase64URL(auth)
+	if err != nil {
+		return nil, err
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublicBytes...)
+	keyInfo = append(keyInfo, asPublic...)
+
+	ikm := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sharedSecret, authSecret, keyInfo), ikm); err != nil {
+		return nil, err
+	}
+
+	cek := make([]byte, 16)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: aes128gcm\x00")), cek); err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: nonce\x00")), nonce); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// the 0x02 padding delimiter marks the last (and only) record
+	plaintext := append(payload, 0x02)
+
+	// header: salt (16) || record size (4) || key id length (1) || key id (as_public)
+	header := make([]byte, 0, 21+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, 4096)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// decodeBase64URL decodes base64url string with or without padding.
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
@@ -0,0 +1,507 @@
+// Package recordlock implements advisory record locks for collaborative
+// editing UIs (e.g. to warn or prevent concurrent edits of the same record).
+//
+// The locks are advisory, meaning that they are not enforced on the
+// record update and it is up to the client to respect them.
+//
+// A lock is held by a single auth record until it is released or its
+// TTL expires (the holder is expected to periodically renew it with
+// a heartbeat request). The locks could be managed by the auth records
+// that satisfy the record collection UpdateRule via the following endpoints:
+//
+//	GET  /api/collections/{collection}/records/{id}/lock            (the current active lock)
+//	POST /api/collections/{collection}/records/{id}/lock/acquire    (body: {"ttl": 60})
+//	POST /api/collections/{collection}/records/{id}/lock/heartbeat
+//	POST /api/collections/{collection}/records/{id}/lock/release
+//
+// Superusers can release the locks of other auth records.
+//
+// The lock changes are sent as realtime messages to the clients that
+// satisfy the record collection ViewRule and are subscribed to the
+// "@locks/{collection}/{id}" or "@locks/{collection}/*" topics, eg.:
+//
+//	{"action": "acquire", "lock": {"collectionRef": "...", "recordRef": "...", "holderRef": "...", "expires": "...", ...}}
+//
+// Example usage:
+//
+//	recordlock.MustRegister(app, recordlock.Config{
+//		Collections: []string{"articles"},
+//	})
+package recordlock
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameLocks = "_recordLocks"
+
+// RealtimeTopicPrefix is the prefix of the lock changes realtime topics.
+const RealtimeTopicPrefix = "@locks/"
+
+// Lock change realtime actions.
+const (
+	ActionAcquire = "acquire"
+	ActionRelease = "release"
+	ActionExpire  = "expire"
+)
+
+const (
+	DefaultTTL    = 60 * time.Second
+	DefaultMaxTTL = 10 * time.Minute
+)
+
+const cleanupCronId = "__recordLocksCleanup__"
+
+// Config defines the config options of the recordlock plugin.
+type Config struct {
+	// Collections is an optional list with the names of the
+	// collections whose records could be locked
+	// (default to all non-system collections).
+	Collections []string
+
+	// TTL is the default lock duration if not specified with the
+	// acquire request (default to 60 seconds).
+	TTL time.Duration
+
+	// MaxTTL is the max allowed lock duration (default to 10 minutes).
+	MaxTTL time.Duration
+}
+
+// MustRegister registers the recordlock plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the recordlock plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if p.config.MaxTTL <= 0 {
+		p.config.MaxTTL = DefaultMaxTTL
+	}
+
+	if p.config.TTL <= 0 {
+		p.config.TTL = min(DefaultTTL, p.config.MaxTTL)
+	}
+
+	if p.config.TTL > p.config.MaxTTL {
+		return errors.New("recordlock: TTL must be less or equal to MaxTTL")
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollection(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollection()
+		})
+	}
+
+	// delete the lock of the deleted records
+	p.app.OnRecordDeleteExecute().Bind(&hook.Handler[*core.RecordEvent]{
+		Func: func(e *core.RecordEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			if e.Record.Collection().Name == CollectionNameLocks {
+				return nil
+			}
+
+			_, err := e.App.DB().Delete(CollectionNameLocks, dbx.HashExp{
+				"collectionRef": e.Record.Collection().Id,
+				"recordRef":     e.Record.Id,
+			}).Execute()
+
+			return err
+		},
+		Priority: 99,
+	})
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group("/api/collections/{collection}/records/{id}/lock").Bind(apis.RequireAuth())
+		sub.GET("", p.view)
+		sub.POST("/acquire", p.acquire)
+		sub.POST("/heartbeat", p.heartbeat)
+		sub.POST("/release", p.release)
+
+		return e.Next()
+	})
+
+	return p.app.Cron().Add(cleanupCronId, "* * * * *", func() {
+		if err := p.deleteExpired(); err != nil {
+			p.app.Logger().Error("Failed to delete expired record locks", "error", err.Error())
+		}
+	})
+}
+
+// FindActiveLock returns the active (aka. not expired) lock of the specified record.
+//
+// Returns [sql.ErrNoRows] if the record is not locked.
+func FindActiveLock(app core.App, record *core.Record) (*core.Record, error) {
+	lock, err := findLock(app, record)
+	if err != nil {
+		return nil, err
+	}
+
+	if isExpired(lock) {
+		return nil, sql.ErrNoRows
+	}
+
+	return lock, nil
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) ensureCollection() error {
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameLocks); err == nil {
+		return nil // already created
+	}
+
+	col := core.NewBaseCollection(CollectionNameLocks)
+	col.System = true
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "holderCollectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "holderRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.NumberField{
+		Name:    "ttl",
+		System:  true,
+		OnlyInt: true,
+	})
+	col.Fields.Add(&core.DateField{
+		Name:     "expires",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_recordLocks_unique", true, "collectionRef, recordRef", "")
+	col.AddIndex("idx_recordLocks_expires", false, "expires", "")
+
+	return p.app.Save(col)
+}
+
+// loadLockableRecord loads the request path record and checks
+// whether the current request auth can manage its lock.
+func (p *plugin) loadLockableRecord(e *core.RequestEvent) (*core.Record, error) {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection.IsView() || collection.System ||
+		(len(p.config.Collections) > 0 && !list.ExistInSlice(collection.Name, p.config.Collections)) {
+		return nil, e.NotFoundError("Missing or invalid collection context.", err)
+	}
+
+	record, err := e.App.FindRecordById(collection, e.Request.PathValue("id"))
+	if err != nil {
+		return nil, e.NotFoundError("", err)
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return nil, e.BadRequestError("", err)
+	}
+
+	canManage, err := e.App.CanAccessRecord(record, requestInfo, collection.UpdateRule)
+	if !canManage {
+		// hide the record existence
+		return nil, e.NotFoundError("", err)
+	}
+
+	return record, nil
+}
+
+func (p *plugin) view(e *core.RequestEvent) error {
+	record, err := p.loadLockableRecord(e)
+	if err != nil {
+		return err
+	}
+
+	lock, err := FindActiveLock(e.App, record)
+	if err != nil {
+		return e.NotFoundError("The record is not locked.", err)
+	}
+
+	return e.JSON(http.StatusOK, lock)
+}
+
+type acquireForm struct {
+	// TTL is the lock duration in seconds.
+	TTL int64 `form:"ttl" json:"ttl"`
+}
+
+func (p *plugin) acquire(e *core.RequestEvent) error {
+	record, err := p.loadLockableRecord(e)
+	if err != nil {
+		return err
+	}
+
+	form := &acquireForm{}
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	err = validation.ValidateStruct(form,
+		validation.Field(&form.TTL, validation.Min(0), validation.Max(int64(p.config.MaxTTL/time.Second))),
+	)
+	if err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	ttl := p.config.TTL
+	if form.TTL > 0 {
+		ttl = time.Duration(form.TTL) * time.Second
+	}
+
+	var lock *core.Record
+	var heldByOther bool
+
+	txErr := e.App.RunInTransaction(func(txApp core.App) error {
+		var findErr error
+
+		lock, findErr = findLock(txApp, record)
+		if findErr != nil {
+			collection, err := txApp.FindCachedCollectionByNameOrId(CollectionNameLocks)
+			if err != nil {
+				return err
+			}
+
+			lock = core.NewRecord(collection)
+			lock.Set("collectionRef", record.Collection().Id)
+			lock.Set("recordRef", record.Id)
+		} else if !isExpired(lock) && !isHolder(lock, e.Auth) {
+			heldByOther = true
+			return nil
+		}
+
+		lock.Set("holderCollectionRef", e.Auth.Collection().Id)
+		lock.Set("holderRef", e.Auth.Id)
+		lock.Set("ttl", int64(ttl/time.Second))
+		lock.Set("expires", time.Now().Add(ttl))
+
+		return txApp.Save(lock)
+	})
+	if txErr != nil {
+		return e.BadRequestError("Failed to acquire the record lock.", txErr)
+	}
+
+	if heldByOther {
+		return lockedError(e)
+	}
+
+	p.broadcast(ActionAcquire, lock)
+
+	return e.JSON(http.StatusOK, lock)
+}
+
+func (p *plugin) heartbeat(e *core.RequestEvent) error {
+	record, err := p.loadLockableRecord(e)
+	if err != nil {
+		return err
+	}
+
+	lock, err := FindActiveLock(e.App, record)
+	if err != nil {
+		return e.NotFoundError("The record is not locked.", err)
+	}
+
+	if !isHolder(lock, e.Auth) {
+		return lockedError(e)
+	}
+
+	ttl := time.Duration(lock.GetInt("ttl")) * time.Second
+	if ttl <= 0 {
+		ttl = p.config.TTL
+	}
+	lock.Set("expires", time.Now().Add(ttl))
+
+	if err := e.App.Save(lock); err != nil {
+		return e.BadRequestError("Failed to renew the record lock.", err)
+	}
+
+	return e.JSON(http.StatusOK, lock)
+}
+
+func (p *plugin) release(e *core.RequestEvent) error {
+	record, err := p.loadLockableRecord(e)
+	if err != nil {
+		return err
+	}
+
+	lock, err := FindActiveLock(e.App, record)
+	if err != nil {
+		return e.NoContent(http.StatusNoContent) // already released or expired
+	}
+
+	if !isHolder(lock, e.Auth) && !e.HasSuperuserAuth() {
+		return lockedError(e)
+	}
+
+	if err := e.App.Delete(lock); err != nil {
+		return e.BadRequestError("Failed to release the record lock.", err)
+	}
+
+	p.broadcast(ActionRelease, lock)
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// deleteExpired deletes the expired locks and notifies the
+// realtime subscribers for their expiration.
+func (p *plugin) deleteExpired() error {
+	locks, err := p.app.FindAllRecords(
+		CollectionNameLocks,
+		dbx.NewExp("[[expires]] < {:now}", dbx.Params{"now": types.NowDateTime().String()}),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, lock := range locks {
+		if err := p.app.Delete(lock); err != nil {
+			return err
+		}
+
+		p.broadcast(ActionExpire, lock)
+	}
+
+	return nil
+}
+
+// broadcast sends the lock change to the subscribed realtime clients
+// that can view the locked record.
+func (p *plugin) broadcast(action string, lock *core.Record) {
+	collection, err := p.app.FindCachedCollectionByNameOrId(lock.GetString("collectionRef"))
+	if err != nil {
+		return
+	}
+
+	recordId := lock.GetString("recordRef")
+
+	prefixes := []string{
+		RealtimeTopicPrefix + collection.Name + "/" + recordId + "?",
+		RealtimeTopicPrefix + collection.Id + "/" + recordId + "?",
+		RealtimeTopicPrefix + collection.Name + "/*?",
+		RealtimeTopicPrefix + collection.Id + "/*?",
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"action": action,
+		"lock":   lock,
+	})
+	if err != nil {
+		return
+	}
+
+	var record *core.Record
+
+	for _, client := range p.app.SubscriptionsBroker().Clients() {
+		subs := client.Subscriptions(prefixes...)
+		if len(subs) == 0 {
+			continue
+		}
+
+		if record == nil {
+			record, err = p.app.FindRecordById(collection, recordId)
+			if err != nil {
+				return // missing or deleted record
+			}
+		}
+
+		auth, _ := client.Get(apis.RealtimeClientAuthKey).(*core.Record)
+
+		requestInfo := &core.RequestInfo{
+			Context: core.RequestInfoContextRealtime,
+			Method:  "GET",
+			Auth:    auth,
+		}
+
+		if ok, _ := p.app.CanAccessRecord(record, requestInfo, collection.ViewRule); !ok {
+			continue
+		}
+
+		for sub := range subs {
+			msg := subscriptions.Message{
+				Name: sub,
+				Data: data,
+			}
+
+			routine.FireAndForget(func() {
+				client.Send(msg)
+			})
+		}
+	}
+}
+
+func findLock(app core.App, record *core.Record) (*core.Record, error) {
+	return app.FindFirstRecordByFilter(
+		CollectionNameLocks,
+		"collectionRef = {:collectionRef} && recordRef = {:recordRef}",
+		dbx.Params{"collectionRef": record.Collection().Id, "recordRef": record.Id},
+	)
+}
+
+func isExpired(lock *core.Record) bool {
+	return lock.GetDateTime("expires").Time().Before(time.Now())
+}
+
+func isHolder(lock *core.Record, auth *core.Record) bool {
+	return auth != nil &&
+		lock.GetString("holderRef") == auth.Id &&
+		lock.GetString("holderCollectionRef") == auth.Collection().Id
+}
+
+func lockedError(e *core.RequestEvent) error {
+	return e.Error(http.StatusConflict, "The record is locked by another user.", nil)
+}
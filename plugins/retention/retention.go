@@ -0,0 +1,384 @@
+// Package retention implements per collection data retention policies,
+// aka. automatic deletion or anonymization of the records older than
+// a specified age.
+//
+// The policies are applied periodically by a cron job and a dry-run
+// report of the records that would be affected is available for the
+// superusers at "GET /api/retention/report".
+//
+// Example usage:
+//
+//	retention.MustRegister(app, retention.Config{
+//		Policies: []retention.Policy{
+//			{
+//				Collection: "logs",
+//				DateField:  "created",
+//				MaxAge:     90 * 24 * time.Hour,
+//				Action:     retention.ActionDelete,
+//			},
+//			{
+//				Collection: "orders",
+//				DateField:  "created",
+//				MaxAge:     365 * 24 * time.Hour,
+//				Action:     retention.ActionAnonymize,
+//				Anonymize:  map[string]any{"name": "anonymous", "address": nil},
+//			},
+//		},
+//	})
+package retention
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	ActionDelete    = "delete"
+	ActionAnonymize = "anonymize"
+)
+
+const (
+	// DefaultCronJobId is the id of the cron job that applies the retention policies.
+	DefaultCronJobId = "__pbRetention__"
+
+	// DefaultCronExpr is the default cron schedule of the retention job (every day at 03:30).
+	DefaultCronExpr = "30 3 * * *"
+
+	// DefaultBatchSize is the default number of records that are loaded and processed at once.
+	DefaultBatchSize = 500
+)
+
+// Policy defines a single collection retention policy.
+type Policy struct {
+	// Collection is the name or id of the collection to apply the policy to.
+	Collection string
+
+	// DateField is the name of the record date or autodate field
+	// based on which the record age is calculated.
+	//
+	// Records with empty DateField value are ignored.
+	DateField string
+
+	// MaxAge is the max allowed record age.
+	MaxAge time.Duration
+
+	// Action is the action to perform with the expired records
+	// ([ActionDelete] or [ActionAnonymize]).
+	Action string
+
+	// Anonymize is a map with the replacement values of the record fields
+	// that should be anonymized when Action is [ActionAnonymize].
+	//
+	// Use nil for resetting the field to its zero value.
+	//
+	// Note that the anonymized records are saved without validations.
+	Anonymize map[string]any
+}
+
+// Report defines the result of a single policy run.
+type Report struct {
+	Collection string `json:"collection"`
+	Action     string `json:"action"`
+
+	// Total is the number of the affected (or the
+	// would be affected in case of dry-run) records.
+	Total int `json:"total"`
+
+	// RecordIds is a list with the ids of the affected records
+	// (in case of dry-run it contains at most BatchSize ids).
+	RecordIds []string `json:"recordIds"`
+
+	DryRun bool `json:"dryRun"`
+}
+
+// Config defines the config options of the retention plugin.
+type Config struct {
+	// Policies is a list with the retention policies to apply.
+	Policies []Policy
+
+	// CronExpr specifies the schedule of the retention job
+	// (default to [DefaultCronExpr]).
+	CronExpr string
+
+	// BatchSize specifies the number of records that are
+	// loaded and processed at once (default to [DefaultBatchSize]).
+	BatchSize int
+
+	// DryRun disables the records deletion and anonymization
+	// and only logs the reports of the scheduled runs.
+	DryRun bool
+}
+
+// MustRegister registers the retention plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the retention plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	if len(config.Policies) == 0 {
+		return errors.New("retention: at least one policy must be specified")
+	}
+
+	for i, p := range config.Policies {
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("retention: invalid policy %d: %w", i, err)
+		}
+	}
+
+	if config.CronExpr == "" {
+		config.CronExpr = DefaultCronExpr
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+
+	app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		e.Router.GET("/api/retention/report", func(e *core.RequestEvent) error {
+			reports := make([]*Report, 0, len(config.Policies))
+
+			for _, p := range config.Policies {
+				report, err := Apply(e.App, p, time.Now(), config.BatchSize, true)
+				if err != nil {
+					return e.BadRequestError("Failed to generate the "+p.Collection+" retention report.", err)
+				}
+				reports = append(reports, report)
+			}
+
+			return e.JSON(http.StatusOK, reports)
+		}).Bind(apis.RequireSuperuserAuth())
+
+		return e.Next()
+	})
+
+	return app.Cron().Add(DefaultCronJobId, config.CronExpr, func() {
+		for _, p := range config.Policies {
+			report, err := Apply(app, p, time.Now(), config.BatchSize, config.DryRun)
+			if err != nil {
+				app.Logger().Error(
+					"Failed to apply retention policy",
+					"collection", p.Collection,
+					"action", p.Action,
+					"error", err.Error(),
+				)
+			}
+
+			if report != nil && report.Total > 0 {
+				app.Logger().Info(
+					"Applied retention policy",
+					"collection", report.Collection,
+					"action", report.Action,
+					"total", report.Total,
+					"dryRun", report.DryRun,
+				)
+			}
+		}
+	})
+}
+
+func (p Policy) validate() error {
+	if p.Collection == "" || p.DateField == "" {
+		return errors.New("Collection and DateField are required")
+	}
+
+	if p.MaxAge <= 0 {
+		return errors.New("MaxAge must be positive")
+	}
+
+	switch p.Action {
+	case ActionDelete:
+	case ActionAnonymize:
+		if len(p.Anonymize) == 0 {
+			return errors.New("Anonymize must have at least one field")
+		}
+	default:
+		return fmt.Errorf("unknown Action %q", p.Action)
+	}
+
+	return nil
+}
+
+// Apply deletes or anonymizes the records matching the provided policy
+// whose DateField value is older than now-policy.MaxAge.
+//
+// If dryRun is true no records are changed and the returned report
+// contains only the number of the matching records and the first batchSize ids.
+//
+// Records that fail to be processed are skipped and the
+// last error is returned together with the partial report.
+func Apply(app core.App, policy Policy, now time.Time, batchSize int, dryRun bool) (*Report, error) {
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+
+	collection, err := app.FindCachedCollectionByNameOrId(policy.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection.IsView() {
+		return nil, errors.New("view collections are not supported")
+	}
+
+	switch collection.Fields.GetByName(policy.DateField).(type) {
+	case *core.DateField, *core.AutodateField:
+	default:
+		return nil, fmt.Errorf("%q is not a date field of collection %q", policy.DateField, collection.Name)
+	}
+
+	where, err := policyExpr(collection, policy, now)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Collection: collection.Name,
+		Action:     policy.Action,
+		RecordIds:  []string{},
+		DryRun:     dryRun,
+	}
+
+	if dryRun {
+		var total int
+		err := app.RecordQuery(collection).Select("count(*)").AndWhere(where).Row(&total)
+		if err != nil {
+			return nil, err
+		}
+		report.Total = total
+
+		err = app.RecordQuery(collection).
+			Select("id").
+			AndWhere(where).
+			OrderBy(policy.DateField + " ASC").
+			Limit(int64(batchSize)).
+			Column(&report.RecordIds)
+		if err != nil {
+			return nil, err
+		}
+
+		return report, nil
+	}
+
+	var lastErr error
+
+	for {
+		records := []*core.Record{}
+		err := app.RecordQuery(collection).
+			AndWhere(where).
+			OrderBy(policy.DateField + " ASC").
+			Limit(int64(batchSize)).
+			All(&records)
+		if err != nil {
+			return report, err
+		}
+
+		var batchErr error
+
+		for _, r := range records {
+			if policy.Action == ActionDelete {
+				err = app.Delete(r)
+			} else {
+				for name, value := range policy.Anonymize {
+					r.Set(name, value)
+				}
+				err = app.SaveNoValidate(r)
+			}
+
+			if err != nil {
+				batchErr = fmt.Errorf("failed to %s record %q: %w", policy.Action, r.Id, err)
+				continue
+			}
+
+			report.Total++
+			report.RecordIds = append(report.RecordIds, r.Id)
+		}
+
+		if batchErr != nil {
+			// stop to prevent reprocessing the failed records
+			lastErr = batchErr
+			break
+		}
+
+		if len(records) < batchSize {
+			break
+		}
+	}
+
+	return report, lastErr
+}
+
+// policyExpr returns the db expression that matches the records of the provided policy.
+func policyExpr(collection *core.Collection, policy Policy, now time.Time) (dbx.Expression, error) {
+	cutoff := now.Add(-policy.MaxAge).UTC().Format(types.DefaultDateLayout)
+
+	exprs := []dbx.Expression{
+		dbx.NewExp(
+			fmt.Sprintf("[[%[1]s]] != '' AND [[%[1]s]] < {:retentionCutoff}", policy.DateField),
+			dbx.Params{"retentionCutoff": cutoff},
+		),
+	}
+
+	if policy.Action == ActionAnonymize {
+		// exclude the already anonymized records
+		anonymized, err := notAnonymizedExpr(collection, policy.Anonymize)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, anonymized)
+	}
+
+	return dbx.And(exprs...), nil
+}
+
+func notAnonymizedExpr(collection *core.Collection, values map[string]any) (dbx.Expression, error) {
+	tmp := core.NewRecord(collection)
+
+	exprs := make([]dbx.Expression, 0, len(values))
+
+	var i int
+	for name, value := range values {
+		field := collection.Fields.GetByName(name)
+		if field == nil {
+			return nil, fmt.Errorf("missing field %q in collection %q", name, collection.Name)
+		}
+
+		// normalize the value in the same way as it will be stored in the db
+		tmp.Set(name, value)
+		var dbValue any = tmp.GetRaw(name)
+		if dv, ok := field.(core.DriverValuer); ok {
+			v, err := dv.DriverValue(tmp)
+			if err != nil {
+				return nil, err
+			}
+			dbValue = v
+		}
+		if v, ok := dbValue.(driver.Valuer); ok {
+			normalized, err := v.Value()
+			if err != nil {
+				return nil, err
+			}
+			dbValue = normalized
+		}
+
+		placeholder := fmt.Sprintf("retentionValue%d", i)
+		exprs = append(exprs, dbx.NewExp(
+			fmt.Sprintf("[[%s]] IS NOT {:%s}", name, placeholder),
+			dbx.Params{placeholder: dbValue},
+		))
+		i++
+	}
+
+	return dbx.Or(exprs...), nil
+}
@@ -0,0 +1,479 @@
+// Package revisions implements an opt-in per collection record
+// version history.
+//
+// Every create and update of a record from the configured collections
+// stores a snapshot of the record data together with the changed fields
+// diff and the authenticated actor into a "_revisions" system collection
+// record. The old revisions are pruned based on the per collection
+// MaxRevisions and MaxAge retention options.
+//
+// Note that only the record data is versioned (the file fields store
+// only the file names and the deleted files are not restored).
+//
+// The plugin registers the following superusers only endpoints
+// (the revisions could be also queried with the regular records API
+// of the "_revisions" collection):
+//
+//	GET  /api/revisions/{collection}/{recordId}
+//	POST /api/revisions/{id}/restore
+//
+// Example usage:
+//
+//	revisions.MustRegister(app, revisions.Config{
+//		Collections: []revisions.CollectionConfig{
+//			{Name: "articles", MaxRevisions: 50},
+//			{Name: "orders", MaxAge: 90 * 24 * time.Hour},
+//		},
+//	})
+package revisions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameRevisions = "_revisions"
+
+// Revision actions.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionRestore = "restore"
+)
+
+const (
+	// DefaultActorMiddlewareId is the id of the middleware that
+	// associates the request auth record with the record changes.
+	DefaultActorMiddlewareId = "pbRevisionsActor"
+
+	// DefaultActorMiddlewarePriority is the default actor middleware
+	// priority (it must run after the auth token is loaded).
+	DefaultActorMiddlewarePriority = apis.DefaultLoadAuthTokenMiddlewarePriority + 1
+
+	cleanupCronId = "__pbRevisionsCleanup__"
+)
+
+// CollectionConfig defines the revisions tracking options of a single collection.
+type CollectionConfig struct {
+	// Name is the name of the tracked collection.
+	Name string
+
+	// MaxRevisions is the max number of the most recent revisions
+	// to keep per record (zero or negative means no limit).
+	MaxRevisions int
+
+	// MaxAge is the max age of the revisions to keep
+	// (zero or negative means no limit).
+	//
+	// Note that the most recent revision of a record is removed
+	// too once it exceeds the MaxAge.
+	MaxAge time.Duration
+}
+
+// Config defines the config options of the revisions plugin.
+type Config struct {
+	// Collections is the list with the tracked collections.
+	Collections []CollectionConfig
+}
+
+// MustRegister registers the revisions plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the revisions plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config, collections: map[string]*CollectionConfig{}}
+
+	names := make([]string, 0, len(p.config.Collections))
+	for i, c := range p.config.Collections {
+		if c.Name == "" {
+			return errors.New("missing revisions collection name")
+		}
+		if c.Name == CollectionNameRevisions {
+			return errors.New("the revisions collection cannot be tracked")
+		}
+
+		p.collections[c.Name] = &p.config.Collections[i]
+		names = append(names, c.Name)
+	}
+
+	if len(names) == 0 {
+		return errors.New("at least one tracked collection is required")
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollection(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollection()
+		})
+	}
+
+	// store the revisions as part of the record save transaction
+	p.app.OnRecordCreateExecute(names...).Bind(&hook.Handler[*core.RecordEvent]{
+		Func:     p.trackHandler,
+		Priority: 99,
+	})
+	p.app.OnRecordUpdateExecute(names...).Bind(&hook.Handler[*core.RecordEvent]{
+		Func:     p.trackHandler,
+		Priority: 99,
+	})
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		e.Router.Bind(&hook.Handler[*core.RequestEvent]{
+			Id:       DefaultActorMiddlewareId,
+			Priority: DefaultActorMiddlewarePriority,
+			Func:     actorMiddleware,
+		})
+
+		sub := e.Router.Group("/api/revisions").Bind(apis.RequireSuperuserAuth())
+		sub.GET("/{collection}/{recordId}", p.list)
+		sub.POST("/{id}/restore", p.restore)
+
+		return e.Next()
+	})
+
+	return p.app.Cron().Add(cleanupCronId, "25 * * * *", func() {
+		if err := p.deleteExpired(); err != nil {
+			p.app.Logger().Error("Failed to delete the expired revisions", "error", err.Error())
+		}
+	})
+}
+
+// WithActor returns a copy of ctx associated with the specified auth
+// record as the actor of the record changes saved with the returned context
+// (e.g. app.SaveWithContext(revisions.WithActor(ctx, auth), record)).
+//
+// The actor of the changes made with the web APIs is assigned automatically.
+func WithActor(ctx context.Context, actor *core.Record) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// FindRevisions returns the revisions of the specified record
+// ordered by their version (from the newest).
+func FindRevisions(app core.App, record *core.Record) ([]*core.Record, error) {
+	return app.FindRecordsByFilter(
+		CollectionNameRevisions,
+		"collectionRef = {:collectionId} && recordRef = {:recordId}",
+		"-version",
+		0,
+		0,
+		dbx.Params{"collectionId": record.Collection().Id, "recordId": record.Id},
+	)
+}
+
+// -------------------------------------------------------------------
+
+type actorContextKey struct{}
+
+type restoreContextKey struct{}
+
+func actorMiddleware(e *core.RequestEvent) error {
+	if e.Auth != nil {
+		e.Request = e.Request.WithContext(WithActor(e.Request.Context(), e.Auth))
+	}
+
+	return e.Next()
+}
+
+type plugin struct {
+	app         core.App
+	config      Config
+	collections map[string]*CollectionConfig
+}
+
+func (p *plugin) ensureCollection() error {
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameRevisions); err == nil {
+		return nil // already created
+	}
+
+	col := core.NewBaseCollection(CollectionNameRevisions)
+	col.System = true
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.NumberField{
+		Name:     "version",
+		System:   true,
+		OnlyInt:  true,
+		Required: true,
+	})
+	col.Fields.Add(&core.SelectField{
+		Name:      "action",
+		System:    true,
+		Required:  true,
+		MaxSelect: 1,
+		Values:    []string{ActionCreate, ActionUpdate, ActionRestore},
+	})
+	col.Fields.Add(&core.JSONField{
+		Name:    "data",
+		System:  true,
+		MaxSize: 1 << 24,
+	})
+	col.Fields.Add(&core.JSONField{
+		Name:    "diff",
+		System:  true,
+		MaxSize: 1 << 24,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "actorCollection",
+		System: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "actorRef",
+		System: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.AddIndex("idx_revisions_version", true, "collectionRef, recordRef, version", "")
+	col.AddIndex("idx_revisions_created", false, "created", "")
+
+	return p.app.Save(col)
+}
+
+// trackHandler stores a new revision with the saved record data.
+func (p *plugin) trackHandler(e *core.RecordEvent) error {
+	collection := e.Record.Collection()
+
+	config := p.collections[collection.Name]
+	if config == nil {
+		config = p.collections[collection.Id]
+	}
+	if config == nil {
+		return e.Next()
+	}
+
+	action := ActionCreate
+
+	var old map[string]any
+	if !e.Record.IsNew() {
+		action = ActionUpdate
+
+		var err error
+		old, err = loadRawData(e.App, collection, e.Record.Id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+
+	if err := e.Next(); err != nil {
+		return err
+	}
+
+	data, err := loadRawData(e.App, collection, e.Record.Id)
+	if err != nil {
+		return err
+	}
+
+	diff := map[string]map[string]any{}
+	for k, v := range data {
+		if oldValue, ok := old[k]; !ok || oldValue != v {
+			diff[k] = map[string]any{"old": old[k], "new": v}
+		}
+	}
+
+	if action == ActionUpdate && len(diff) == 0 {
+		return nil // nothing has changed
+	}
+
+	if _, ok := e.Context.Value(restoreContextKey{}).(string); ok {
+		action = ActionRestore
+	}
+
+	var lastVersion int
+	err = e.App.DB().Select("COALESCE(MAX([[version]]), 0)").
+		From(CollectionNameRevisions).
+		Where(dbx.HashExp{"collectionRef": collection.Id, "recordRef": e.Record.Id}).
+		Row(&lastVersion)
+	if err != nil {
+		return err
+	}
+
+	revisionsCollection, err := e.App.FindCachedCollectionByNameOrId(CollectionNameRevisions)
+	if err != nil {
+		return err
+	}
+
+	revision := core.NewRecord(revisionsCollection)
+	revision.Set("collectionRef", collection.Id)
+	revision.Set("recordRef", e.Record.Id)
+	revision.Set("version", lastVersion+1)
+	revision.Set("action", action)
+	revision.Set("data", data)
+	revision.Set("diff", diff)
+
+	if actor, _ := e.Context.Value(actorContextKey{}).(*core.Record); actor != nil {
+		revision.Set("actorCollection", actor.Collection().Id)
+		revision.Set("actorRef", actor.Id)
+	}
+
+	if err := e.App.Save(revision); err != nil {
+		return err
+	}
+
+	if config.MaxRevisions > 0 {
+		_, err := e.App.DB().Delete(CollectionNameRevisions, dbx.And(
+			dbx.HashExp{"collectionRef": collection.Id, "recordRef": e.Record.Id},
+			dbx.NewExp("[[version]] <= {:version}", dbx.Params{"version": lastVersion + 1 - config.MaxRevisions}),
+		)).Execute()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteExpired deletes the revisions older than their collection MaxAge.
+func (p *plugin) deleteExpired() error {
+	for _, config := range p.config.Collections {
+		if config.MaxAge <= 0 {
+			continue
+		}
+
+		collection, err := p.app.FindCachedCollectionByNameOrId(config.Name)
+		if err != nil {
+			continue // not created yet or deleted
+		}
+
+		_, err = p.app.DB().Delete(CollectionNameRevisions, dbx.And(
+			dbx.HashExp{"collectionRef": collection.Id},
+			dbx.NewExp("[[created]] < {:date}", dbx.Params{
+				"date": time.Now().Add(-config.MaxAge).UTC().Format(types.DefaultDateLayout),
+			}),
+		)).Execute()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *plugin) list(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	revisions, err := e.App.FindRecordsByFilter(
+		CollectionNameRevisions,
+		"collectionRef = {:collectionId} && recordRef = {:recordId}",
+		"-version",
+		0,
+		0,
+		dbx.Params{"collectionId": collection.Id, "recordId": e.Request.PathValue("recordId")},
+	)
+	if err != nil {
+		return e.BadRequestError("Failed to load the record revisions.", err)
+	}
+
+	return e.JSON(http.StatusOK, revisions)
+}
+
+func (p *plugin) restore(e *core.RequestEvent) error {
+	revision, err := e.App.FindRecordById(CollectionNameRevisions, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	collection, err := e.App.FindCollectionByNameOrId(revision.GetString("collectionRef"))
+	if err != nil {
+		return e.BadRequestError("The revision collection no longer exists.", err)
+	}
+
+	data := map[string]any{}
+	if err := json.Unmarshal([]byte(revision.GetString("data")), &data); err != nil {
+		return e.BadRequestError("Invalid revision data.", err)
+	}
+
+	// recreate the record if it was deleted in the meantime
+	record, err := e.App.FindRecordById(collection, revision.GetString("recordRef"))
+	if err != nil {
+		record = core.NewRecord(collection)
+	}
+
+	for _, field := range collection.Fields {
+		name := field.GetName()
+
+		// keep the current autodate values (e.g. "updated" is refreshed on save)
+		if field.Type() == core.FieldTypeAutodate && !record.IsNew() {
+			continue
+		}
+
+		value, err := field.PrepareValue(record, data[name])
+		if err != nil {
+			return e.BadRequestError("Failed to load the revision data.", err)
+		}
+		record.SetRaw(name, value)
+	}
+
+	ctx := context.WithValue(e.Request.Context(), restoreContextKey{}, revision.Id)
+
+	// the data was already validated when the revision was created
+	if err := e.App.SaveNoValidateWithContext(ctx, record); err != nil {
+		return e.BadRequestError("Failed to restore the record revision.", err)
+	}
+
+	if err := apis.EnrichRecord(e, record); err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	return e.JSON(http.StatusOK, record)
+}
+
+// loadRawData loads the raw db data of the specified record
+// (to avoid the fields value normalizations on restore).
+func loadRawData(app core.App, collection *core.Collection, recordId string) (map[string]any, error) {
+	row := dbx.NullStringMap{}
+
+	err := app.DB().Select("*").
+		From(collection.Name).
+		Where(dbx.HashExp{"id": recordId}).
+		Limit(1).
+		One(&row)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]any, len(row))
+	for k, v := range row {
+		if v.Valid {
+			data[k] = v.String
+		} else {
+			data[k] = nil
+		}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,994 @@
+// Package s3api implements a minimal S3-compatible API facade for the
+// record files, allowing existing S3 tools (rclone, the AWS SDKs, etc.)
+// to read and write the record files directly.
+//
+// The collections are exposed as buckets and the record files as
+// "{recordId}/{filename}" objects. Only path-style requests are supported:
+//
+//	GET    /s3/                                (ListBuckets)
+//	HEAD   /s3/{bucket}                        (HeadBucket)
+//	GET    /s3/{bucket}                        (ListObjects, ListObjectsV2, GetBucketLocation)
+//	HEAD   /s3/{bucket}/{recordId}/{filename}  (HeadObject)
+//	GET    /s3/{bucket}/{recordId}/{filename}  (GetObject)
+//	PUT    /s3/{bucket}/{recordId}/{filename}  (PutObject)
+//	DELETE /s3/{bucket}/{recordId}/{filename}  (DeleteObject)
+//
+// The requests must be signed with AWS Signature Version 4 (either with
+// the Authorization header or as presigned url) using the API key
+// credentials of an auth record:
+//   - access key id - the API key id
+//   - secret access key - derived from the API key and the plugin Config.Secret (see [SecretAccessKey])
+//
+// The API key owner could obtain the S3 credentials of their API key with:
+//
+//	GET /api/s3/credentials
+//	Authorization: pbk_...
+//
+// The API key scopes are checked against the bucket collection ("read"
+// for GET and HEAD, "write" for PUT and DELETE) and the API key owner
+// must also satisfy the collection API rules:
+//   - ListRule for listing the bucket objects
+//   - ViewRule for downloading an object
+//   - UpdateRule for uploading or deleting an object
+//
+// Objects can be uploaded only for existing records. If the collection
+// has more than one file field, the target field must be specified with
+// the "x-amz-meta-field" header. Uploading an object with the name of
+// an existing record file replaces the file content.
+//
+// Example usage:
+//
+//	s3api.MustRegister(app, s3api.Config{
+//		Collections: []string{"documents"},
+//	})
+//
+// and the related rclone remote:
+//
+//	[pb]
+//	type = s3
+//	provider = Other
+//	access_key_id = API_KEY_ID
+//	secret_access_key = S3_SECRET_ACCESS_KEY
+//	endpoint = https://example.com/s3
+//	force_path_style = true
+package s3api
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	DefaultBasePath = "/s3"
+	DefaultRegion   = "us-east-1"
+)
+
+// CredentialsPath is the path of the route that returns the S3
+// credentials of the API key used to authenticate the request.
+const CredentialsPath = "/api/s3/credentials"
+
+// FieldMetaHeader is the PutObject request header with the name of the
+// target file field (required only for collections with multiple file fields).
+const FieldMetaHeader = "X-Amz-Meta-Field"
+
+const (
+	xmlns           = "http://s3.amazonaws.com/doc/2006-03-01/"
+	s3TimeFormat    = "2006-01-02T15:04:05.000Z"
+	defaultMaxKeys  = 1000
+	lastUsedTimeout = 1 * time.Minute
+)
+
+// Config defines the config options of the s3api plugin.
+type Config struct {
+	// BasePath is the S3 API routes path prefix (default to "/s3").
+	BasePath string
+
+	// Region is the region reported to the clients with GetBucketLocation
+	// (default to "us-east-1").
+	//
+	// Note that the requests are accepted regardless of their signature region.
+	Region string
+
+	// Collections is an optional list with the names of the collections
+	// that are exposed as buckets (default to all non-system base and auth
+	// collections with at least one file field).
+	Collections []string
+
+	// Secret is the server-side key used to derive the API keys
+	// S3 secret access keys (default to the value of the app encryption env).
+	//
+	// The secret is never stored in the database so the API key hashes
+	// alone are not enough for signing S3 requests.
+	// Changing it invalidates all previously issued S3 secret access keys.
+	Secret string
+}
+
+// SecretAccessKey returns the S3 secret access key of the provided
+// plain API key derived with the plugin Config.Secret.
+func SecretAccessKey(secret string, plainAPIKey string) string {
+	return deriveSecretAccessKey(secret, security.SHA256(plainAPIKey))
+}
+
+// deriveSecretAccessKey derives the S3 secret access key from
+// the stored API key hash (aka. SHA256 of the plain API key).
+func deriveSecretAccessKey(secret string, keyHash string) string {
+	return security.HS256(keyHash, secret)
+}
+
+// MustRegister registers the s3api plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the s3api plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if p.config.BasePath == "" {
+		p.config.BasePath = DefaultBasePath
+	}
+	p.config.BasePath = "/" + strings.Trim(p.config.BasePath, "/")
+	if p.config.BasePath == "/" {
+		return errors.New("s3api: BasePath must not be the root path")
+	}
+
+	if p.config.Region == "" {
+		p.config.Region = DefaultRegion
+	}
+
+	if p.config.Secret == "" {
+		p.config.Secret = os.Getenv(app.EncryptionEnv())
+	}
+	if p.config.Secret == "" {
+		return errors.New("s3api: Secret must be set either with the plugin config or the app encryption env")
+	}
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		e.Router.GET(CredentialsPath, p.credentials)
+
+		sub := e.Router.Group(p.config.BasePath)
+		sub.BindFunc(p.handleErrors)
+		sub.GET("", p.listBuckets)
+		sub.GET("/{$}", p.listBuckets)
+		sub.HEAD("/{bucket}", p.headBucket)
+		sub.GET("/{bucket}", p.listObjects)
+		sub.HEAD("/{bucket}/{key...}", p.getObject)
+		sub.GET("/{bucket}/{key...}", p.getObject)
+		sub.PUT("/{bucket}/{key...}", p.putObject).Unbind(apis.DefaultBodyLimitMiddlewareId) // the limit is based on the target file field
+		sub.DELETE("/{bucket}/{key...}", p.deleteObject)
+
+		return e.Next()
+	})
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+// s3Error defines an S3 XML error response.
+type s3Error struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource,omitempty"`
+
+	status int
+	cause  error
+}
+
+// Error implements the [error] interface.
+func (err *s3Error) Error() string {
+	return err.Code + ": " + err.Message
+}
+
+func newS3Error(status int, code string, message string, cause error) *s3Error {
+	return &s3Error{
+		status:  status,
+		Code:    code,
+		Message: message,
+		cause:   cause,
+	}
+}
+
+func errAccessDenied(cause error) *s3Error {
+	return newS3Error(http.StatusForbidden, "AccessDenied", "Access Denied", cause)
+}
+
+func errNoSuchBucket(cause error) *s3Error {
+	return newS3Error(http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", cause)
+}
+
+func errNoSuchKey(cause error) *s3Error {
+	return newS3Error(http.StatusNotFound, "NoSuchKey", "The specified key does not exist.", cause)
+}
+
+func errInternal(cause error) *s3Error {
+	return newS3Error(http.StatusInternalServerError, "InternalError", "We encountered an internal error. Please try again.", cause)
+}
+
+// handleErrors is a middleware that converts the returned handler
+// errors into S3 XML error responses.
+func (p *plugin) handleErrors(e *core.RequestEvent) error {
+	err := e.Next()
+	if err == nil {
+		return nil
+	}
+
+	var s3Err *s3Error
+	if !errors.As(err, &s3Err) {
+		var apiErr *router.ApiError
+		if errors.As(err, &apiErr) {
+			switch apiErr.Status {
+			case http.StatusRequestEntityTooLarge:
+				s3Err = newS3Error(apiErr.Status, "EntityTooLarge", "Your proposed upload exceeds the maximum allowed size", err)
+			case http.StatusTooManyRequests:
+				s3Err = newS3Error(apiErr.Status, "SlowDown", "Please reduce your request rate.", err)
+			case http.StatusForbidden, http.StatusUnauthorized:
+				s3Err = errAccessDenied(err)
+			case http.StatusNotFound:
+				s3Err = errNoSuchKey(err)
+			case http.StatusBadRequest:
+				s3Err = newS3Error(apiErr.Status, "InvalidRequest", apiErr.Message, err)
+			default:
+				s3Err = errInternal(err)
+			}
+		} else {
+			s3Err = errInternal(err)
+		}
+	}
+
+	if s3Err.cause != nil {
+		e.App.Logger().Debug("S3 API request failure", "error", s3Err.cause, "code", s3Err.Code, "url", e.Request.URL.String())
+	}
+
+	s3Err.Resource = e.Request.URL.Path
+
+	return e.XML(s3Err.status, s3Err)
+}
+
+// authorize verifies the request signature and loads the API key
+// owner as request auth.
+func (p *plugin) authorize(e *core.RequestEvent) (*core.APIKey, error) {
+	signed, err := parseSignedRequest(e.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := e.App.FindAPIKeyById(signed.accessKeyId)
+	if err != nil || apiKey.HasExpired() {
+		return nil, newS3Error(http.StatusForbidden, "InvalidAccessKeyId", "The AWS access key Id you provided does not exist in our records.", err)
+	}
+
+	if err := signed.verify(e.Request, deriveSecretAccessKey(p.config.Secret, apiKey.KeyHash())); err != nil {
+		return nil, err
+	}
+
+	authRecord, err := e.App.FindRecordById(apiKey.CollectionRef(), apiKey.RecordRef())
+	if err != nil {
+		return nil, newS3Error(http.StatusForbidden, "InvalidAccessKeyId", "The AWS access key Id you provided does not exist in our records.", err)
+	}
+
+	e.Auth = authRecord
+	e.Set(core.RequestEventKeyAPIKey, apiKey)
+
+	// note: direct db update to avoid triggering the model hooks on every request
+	if time.Since(apiKey.LastUsed().Time()) > lastUsedTimeout {
+		_, err = e.App.NonconcurrentDB().Update(
+			core.CollectionNameAPIKeys,
+			dbx.Params{"lastUsed": types.NowDateTime().String()},
+			dbx.HashExp{"id": apiKey.Id},
+		).Execute()
+		if err != nil {
+			e.App.Logger().Debug("Failed to update the API key lastUsed date", "error", err, "apiKeyId", apiKey.Id)
+		}
+	}
+
+	return apiKey, nil
+}
+
+// authorizeBucket verifies the request signature and returns the
+// request bucket collection if the API key has the specified access scope.
+func (p *plugin) authorizeBucket(e *core.RequestEvent, access string) (*core.Collection, error) {
+	apiKey, err := p.authorize(e)
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("bucket"))
+	if err != nil || collection.Name != e.Request.PathValue("bucket") || !p.isBucket(collection) {
+		return nil, errNoSuchBucket(err)
+	}
+
+	if !apiKey.HasScope(collection, access) {
+		return nil, errAccessDenied(fmt.Errorf("missing %q API key scope", access))
+	}
+
+	return collection, nil
+}
+
+// isBucket checks whether the collection is exposed as bucket.
+func (p *plugin) isBucket(collection *core.Collection) bool {
+	if collection.System || collection.IsView() {
+		return false
+	}
+
+	if len(p.config.Collections) > 0 && !list.ExistInSlice(collection.Name, p.config.Collections) {
+		return false
+	}
+
+	return len(fileFields(collection)) > 0
+}
+
+func fileFields(collection *core.Collection) []*core.FileField {
+	result := []*core.FileField{}
+
+	for _, f := range collection.Fields {
+		if fileField, ok := f.(*core.FileField); ok {
+			result = append(result, fileField)
+		}
+	}
+
+	return result
+}
+
+// splitKey splits an object key into its record id and filename parts.
+func splitKey(key string) (string, string, bool) {
+	recordId, filename, ok := strings.Cut(key, "/")
+	if !ok || recordId == "" || filename == "" || strings.Contains(filename, "/") {
+		return "", "", false
+	}
+
+	return recordId, filename, true
+}
+
+// -------------------------------------------------------------------
+// Buckets
+// -------------------------------------------------------------------
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name     `xml:"ListAllMyBucketsResult"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Owner   bucketOwner  `xml:"Owner"`
+	Buckets []bucketInfo `xml:"Buckets>Bucket"`
+}
+
+type bucketOwner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+type bucketInfo struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+// credentials returns the S3 credentials of the API key used to
+// authenticate the request (the secret is not stored anywhere and
+// it is derived on each call).
+func (p *plugin) credentials(e *core.RequestEvent) error {
+	apiKey, _ := e.Get(core.RequestEventKeyAPIKey).(*core.APIKey)
+	if apiKey == nil {
+		return e.UnauthorizedError("The request must be authenticated with an API key.", nil)
+	}
+
+	e.Response.Header().Set("Cache-Control", "no-store")
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"accessKeyId":     apiKey.Id,
+		"secretAccessKey": deriveSecretAccessKey(p.config.Secret, apiKey.KeyHash()),
+	})
+}
+
+func (p *plugin) listBuckets(e *core.RequestEvent) error {
+	apiKey, err := p.authorize(e)
+	if err != nil {
+		return err
+	}
+
+	collections, err := e.App.FindAllCollections(core.CollectionTypeBase, core.CollectionTypeAuth)
+	if err != nil {
+		return errInternal(err)
+	}
+
+	result := &listAllMyBucketsResult{
+		Xmlns: xmlns,
+		Owner: bucketOwner{
+			ID:          e.Auth.Id,
+			DisplayName: e.Auth.Email(),
+		},
+		Buckets: []bucketInfo{},
+	}
+
+	for _, collection := range collections {
+		if !p.isBucket(collection) || !apiKey.HasScope(collection, core.APIKeyScopeRead) {
+			continue
+		}
+
+		result.Buckets = append(result.Buckets, bucketInfo{
+			Name:         collection.Name,
+			CreationDate: collection.Created.Time().Format(s3TimeFormat),
+		})
+	}
+
+	slices.SortFunc(result.Buckets, func(a, b bucketInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return e.XML(http.StatusOK, result)
+}
+
+func (p *plugin) headBucket(e *core.RequestEvent) error {
+	_, err := p.authorizeBucket(e, core.APIKeyScopeRead)
+	if err != nil {
+		return err
+	}
+
+	e.Response.Header().Set("X-Amz-Bucket-Region", p.config.Region)
+
+	return e.NoContent(http.StatusOK)
+}
+
+// -------------------------------------------------------------------
+// List objects
+// -------------------------------------------------------------------
+
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"ListBucketResult"`
+	Xmlns                 string         `xml:"xmlns,attr"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter,omitempty"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	Marker                *string        `xml:"Marker"`
+	NextMarker            string         `xml:"NextMarker,omitempty"`
+	KeyCount              *int           `xml:"KeyCount"`
+	ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	StartAfter            string         `xml:"StartAfter,omitempty"`
+	Contents              []objectInfo   `xml:"Contents"`
+	CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+}
+
+type objectInfo struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type locationConstraint struct {
+	XMLName xml.Name `xml:"LocationConstraint"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Region  string   `xml:",chardata"`
+}
+
+func (p *plugin) listObjects(e *core.RequestEvent) error {
+	collection, err := p.authorizeBucket(e, core.APIKeyScopeRead)
+	if err != nil {
+		return err
+	}
+
+	query := e.Request.URL.Query()
+
+	if query.Has("location") {
+		return e.XML(http.StatusOK, &locationConstraint{Xmlns: xmlns, Region: p.config.Region})
+	}
+
+	objects, err := p.bucketObjects(e, collection)
+	if err != nil {
+		return err
+	}
+
+	isV2 := query.Get("list-type") == "2"
+
+	result := &listBucketResult{
+		Xmlns:     xmlns,
+		Name:      collection.Name,
+		Prefix:    query.Get("prefix"),
+		Delimiter: query.Get("delimiter"),
+		MaxKeys:   defaultMaxKeys,
+	}
+
+	if raw := query.Get("max-keys"); raw != "" {
+		maxKeys, err := strconv.Atoi(raw)
+		if err != nil || maxKeys < 0 {
+			return newS3Error(http.StatusBadRequest, "InvalidArgument", "Provided max-keys not an integer or within integer range", err)
+		}
+		result.MaxKeys = min(maxKeys, defaultMaxKeys)
+	}
+
+	var marker string
+	if isV2 {
+		result.StartAfter = query.Get("start-after")
+		marker = result.StartAfter
+
+		if token := query.Get("continuation-token"); token != "" {
+			decoded, err := base64.RawURLEncoding.DecodeString(token)
+			if err != nil {
+				return newS3Error(http.StatusBadRequest, "InvalidArgument", "The continuation token provided is incorrect", err)
+			}
+			result.ContinuationToken = token
+			marker = string(decoded)
+		}
+	} else {
+		marker = query.Get("marker")
+		result.Marker = &marker
+	}
+
+	var lastKey string
+	var total int
+
+	for _, obj := range objects {
+		if !strings.HasPrefix(obj.Key, result.Prefix) {
+			continue
+		}
+
+		// group the keys with the same prefix up to the first delimiter occurrence
+		var groupPrefix string
+		if result.Delimiter != "" {
+			rest := obj.Key[len(result.Prefix):]
+			if i := strings.Index(rest, result.Delimiter); i >= 0 {
+				groupPrefix = result.Prefix + rest[:i+len(result.Delimiter)]
+			}
+		}
+
+		entryKey := obj.Key
+		if groupPrefix != "" {
+			entryKey = groupPrefix
+		}
+
+		if entryKey <= marker || (groupPrefix != "" && groupPrefix == lastKey) {
+			continue
+		}
+
+		if total >= result.MaxKeys {
+			result.IsTruncated = true
+			break
+		}
+
+		if groupPrefix != "" {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: groupPrefix})
+		} else {
+			result.Contents = append(result.Contents, obj)
+		}
+
+		lastKey = entryKey
+		total++
+	}
+
+	if isV2 {
+		result.KeyCount = &total
+		if result.IsTruncated {
+			result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(lastKey))
+		}
+	} else if result.IsTruncated && result.Delimiter != "" {
+		result.NextMarker = lastKey
+	}
+
+	return e.XML(http.StatusOK, result)
+}
+
+// bucketObjects returns the sorted list with the files of all
+// collection records that satisfy the collection ListRule.
+func (p *plugin) bucketObjects(e *core.RequestEvent, collection *core.Collection) ([]objectInfo, error) {
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return nil, errInternal(err)
+	}
+
+	if !requestInfo.HasSuperuserAuth() && collection.ListRule == nil {
+		return nil, errAccessDenied(errors.New("only superusers can list the collection records"))
+	}
+
+	query := e.App.RecordQuery(collection)
+
+	if !requestInfo.HasSuperuserAuth() && *collection.ListRule != "" {
+		resolver := core.NewRecordFieldResolver(e.App, collection, requestInfo, true)
+
+		expr, err := search.FilterData(*collection.ListRule).BuildExpr(resolver)
+		if err != nil {
+			return nil, errInternal(err)
+		}
+		query.AndWhere(expr)
+
+		if err := resolver.UpdateQuery(query); err != nil {
+			return nil, errInternal(err)
+		}
+	}
+
+	records := []*core.Record{}
+	if err := query.All(&records); err != nil {
+		return nil, errInternal(err)
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return nil, errInternal(err)
+	}
+	defer fsys.Close()
+
+	stored, err := fsys.List(collection.Id + "/")
+	if err != nil {
+		return nil, errInternal(err)
+	}
+
+	storedObjects := make(map[string]objectInfo, len(stored))
+	for _, obj := range stored {
+		storedObjects[obj.Key] = objectInfo{
+			LastModified: obj.ModTime.UTC().Format(s3TimeFormat),
+			ETag:         etag(obj.MD5, obj.ModTime, obj.Size),
+			Size:         obj.Size,
+			StorageClass: "STANDARD",
+		}
+	}
+
+	fields := fileFields(collection)
+
+	result := []objectInfo{}
+	for _, record := range records {
+		for _, field := range fields {
+			for _, filename := range record.GetStringSlice(field.Name) {
+				obj, ok := storedObjects[record.BaseFilesPath()+"/"+filename]
+				if !ok {
+					continue // missing file
+				}
+
+				obj.Key = record.Id + "/" + filename
+				result = append(result, obj)
+			}
+		}
+	}
+
+	slices.SortFunc(result, func(a, b objectInfo) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return result, nil
+}
+
+// etag returns a quoted S3 object ETag from the file MD5 checksum
+// (or from its modification date and size if the checksum is not available).
+func etag(md5Sum []byte, modTime time.Time, size int64) string {
+	if len(md5Sum) > 0 {
+		return `"` + hex.EncodeToString(md5Sum) + `"`
+	}
+
+	return fmt.Sprintf(`"%x-%x"`, modTime.UnixNano(), size)
+}
+
+// -------------------------------------------------------------------
+// Objects
+// -------------------------------------------------------------------
+
+func (p *plugin) getObject(e *core.RequestEvent) error {
+	// "/{bucket}/" requests
+	if e.Request.PathValue("key") == "" {
+		if e.Request.Method == http.MethodHead {
+			return p.headBucket(e)
+		}
+		return p.listObjects(e)
+	}
+
+	collection, err := p.authorizeBucket(e, core.APIKeyScopeRead)
+	if err != nil {
+		return err
+	}
+
+	recordId, filename, ok := splitKey(e.Request.PathValue("key"))
+	if !ok {
+		return errNoSuchKey(nil)
+	}
+
+	record, err := e.App.FindRecordById(collection, recordId)
+	if err != nil {
+		return errNoSuchKey(err)
+	}
+
+	fileField := record.FindFileFieldByFile(filename)
+	if fileField == nil {
+		return errNoSuchKey(nil)
+	}
+
+	originalRequestInfo, err := e.RequestInfo()
+	if err != nil {
+		return errInternal(err)
+	}
+
+	// create a shallow copy of the cached request data and adjust its context for the protected files
+	requestInfo := *originalRequestInfo
+	if fileField.Protected {
+		requestInfo.Context = core.RequestInfoContextProtectedFile
+	}
+
+	if ok, _ := e.App.CanAccessRecord(record, &requestInfo, collection.ViewRule); !ok {
+		// hide the record existence
+		return errNoSuchKey(errors.New("insufficient permissions to access the file resource"))
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return errInternal(err)
+	}
+	defer fsys.Close()
+
+	path := record.BaseFilesPath() + "/" + filename
+
+	attrs, err := fsys.Attributes(path)
+	if err != nil {
+		return errNoSuchKey(err)
+	}
+
+	event := new(core.FileDownloadRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Record = record
+	event.FileField = fileField
+	event.ServedPath = path
+	event.ServedName = filename
+
+	e.Response.Header().Del("X-Frame-Options")
+	e.Response.Header().Set("ETag", etag(attrs.MD5, attrs.ModTime, attrs.Size))
+
+	return e.App.OnFileDownloadRequest().Trigger(event, func(e *core.FileDownloadRequestEvent) error {
+		if err := fsys.Serve(e.Response, e.Request, e.ServedPath, e.ServedName); err != nil {
+			return errNoSuchKey(err)
+		}
+
+		return nil
+	})
+}
+
+func (p *plugin) putObject(e *core.RequestEvent) error {
+	collection, err := p.authorizeBucket(e, core.APIKeyScopeWrite)
+	if err != nil {
+		return err
+	}
+
+	if e.Request.Header.Get("X-Amz-Copy-Source") != "" {
+		return newS3Error(http.StatusNotImplemented, "NotImplemented", "CopyObject is not supported.", nil)
+	}
+
+	recordId, filename, ok := splitKey(e.Request.PathValue("key"))
+	if !ok {
+		return newS3Error(http.StatusBadRequest, "InvalidArgument", "The object key must be in the format {recordId}/{filename}.", nil)
+	}
+
+	record, err := e.App.FindRecordById(collection, recordId)
+	if err != nil {
+		return newS3Error(http.StatusNotFound, "NoSuchKey", "The object record doesn't exist.", err)
+	}
+
+	// existing file replacement or a new file upload
+	fileField := record.FindFileFieldByFile(filename)
+	isReplace := fileField != nil
+	if !isReplace {
+		fileField, err = resolveTargetField(collection, e.Request.Header.Get(FieldMetaHeader))
+		if err != nil {
+			return err
+		}
+	}
+
+	maxSize := fileField.MaxSize
+	if maxSize <= 0 {
+		maxSize = core.DefaultFileFieldMaxSize
+	}
+
+	content, err := io.ReadAll(io.LimitReader(e.Request.Body, maxSize+1))
+	if err != nil {
+		return newS3Error(http.StatusBadRequest, "IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header.", err)
+	}
+	if int64(len(content)) > maxSize {
+		return newS3Error(http.StatusRequestEntityTooLarge, "EntityTooLarge", "Your proposed upload exceeds the maximum allowed size", nil)
+	}
+
+	if err := verifyPayloadHash(e.Request, content); err != nil {
+		return err
+	}
+
+	// the raw object content is not part of the @request.body rule fields
+	e.Request.Body = http.NoBody
+	e.Request.ContentLength = 0
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return errInternal(err)
+	}
+
+	if ok, _ := e.App.CanAccessRecord(record, requestInfo, collection.UpdateRule); !ok {
+		return errAccessDenied(errors.New("insufficient permissions to update the record"))
+	}
+
+	file, err := filesystem.NewFileFromBytes(content, filename)
+	if err != nil {
+		return errInternal(err)
+	}
+	file.Name = filename // preserve the object key
+
+	if isReplace {
+		err = p.replaceFile(e, record, fileField, file)
+	} else {
+		if fileField.IsMultiple() {
+			record.Set(fileField.Name+"+", file)
+		} else {
+			record.Set(fileField.Name, file)
+		}
+		err = e.App.Save(record)
+	}
+	if err != nil {
+		return saveError(err)
+	}
+
+	sum := md5.Sum(content)
+	e.Response.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+
+	return e.NoContent(http.StatusOK)
+}
+
+// replaceFile overwrites the content of an existing record file.
+//
+// Because the file key must remain the same, the new file is validated
+// against the file field options and directly uploaded to the storage
+// (the record is then saved to refresh its updated date and to trigger the record hooks).
+func (p *plugin) replaceFile(e *core.RequestEvent, record *core.Record, fileField *core.FileField, file *filesystem.File) error {
+	values := []any{}
+	for _, name := range record.GetStringSlice(fileField.Name) {
+		if name == file.Name {
+			values = append(values, file)
+		} else {
+			values = append(values, name)
+		}
+	}
+
+	check := record.Clone()
+	check.Set(fileField.Name, values)
+	if err := fileField.ValidateValue(e.Request.Context(), e.App, check); err != nil {
+		return validation.Errors{fileField.Name: err}
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	path := record.BaseFilesPath() + "/" + file.Name
+
+	if err := fsys.UploadFile(file, path); err != nil {
+		return err
+	}
+
+	// delete the old file thumbs
+	if errs := fsys.DeletePrefix(record.BaseFilesPath() + "/thumbs_" + file.Name + "/"); len(errs) > 0 {
+		e.App.Logger().Debug("Failed to delete the replaced file thumbs", "error", errors.Join(errs...), "path", path)
+	}
+
+	return e.App.Save(record)
+}
+
+func (p *plugin) deleteObject(e *core.RequestEvent) error {
+	collection, err := p.authorizeBucket(e, core.APIKeyScopeWrite)
+	if err != nil {
+		return err
+	}
+
+	recordId, filename, ok := splitKey(e.Request.PathValue("key"))
+	if !ok {
+		return e.NoContent(http.StatusNoContent)
+	}
+
+	record, err := e.App.FindRecordById(collection, recordId)
+	if err != nil {
+		return e.NoContent(http.StatusNoContent)
+	}
+
+	fileField := record.FindFileFieldByFile(filename)
+	if fileField == nil {
+		return e.NoContent(http.StatusNoContent)
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return errInternal(err)
+	}
+
+	if ok, _ := e.App.CanAccessRecord(record, requestInfo, collection.UpdateRule); !ok {
+		return errAccessDenied(errors.New("insufficient permissions to update the record"))
+	}
+
+	record.Set(fileField.Name+"-", filename)
+
+	if err := e.App.Save(record); err != nil {
+		return saveError(err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// resolveTargetField returns the file field for a new object upload.
+func resolveTargetField(collection *core.Collection, fieldName string) (*core.FileField, error) {
+	fields := fileFields(collection)
+
+	if fieldName == "" {
+		if len(fields) == 1 {
+			return fields[0], nil
+		}
+
+		return nil, newS3Error(
+			http.StatusBadRequest,
+			"InvalidArgument",
+			"The collection has multiple file fields - specify the target one with the "+FieldMetaHeader+" header.",
+			nil,
+		)
+	}
+
+	for _, f := range fields {
+		if f.Name == fieldName {
+			return f, nil
+		}
+	}
+
+	return nil, newS3Error(http.StatusBadRequest, "InvalidArgument", "Invalid or missing file field "+fieldName+".", nil)
+}
+
+// saveError converts a record save error into an S3 error.
+func saveError(err error) *s3Error {
+	var validationErrs validation.Errors
+	if errors.As(err, &validationErrs) {
+		return newS3Error(http.StatusBadRequest, "InvalidArgument", validationErrs.Error(), err)
+	}
+
+	return errInternal(err)
+}
+
+// verifyPayloadHash checks the request "x-amz-content-sha256" header
+// against the request body (if the payload is signed).
+func verifyPayloadHash(r *http.Request, content []byte) error {
+	expected := r.Header.Get("X-Amz-Content-Sha256")
+	if expected == "" || expected == unsignedPayload {
+		return nil
+	}
+
+	sum := sha256.Sum256(content)
+	if !strings.EqualFold(expected, hex.EncodeToString(sum[:])) {
+		return newS3Error(http.StatusBadRequest, "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.", nil)
+	}
+
+	return nil
+}
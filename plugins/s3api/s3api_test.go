@@ -0,0 +1,666 @@
+package s3api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/s3api"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const testSecret = "s3api_test_secret"
+
+const (
+	userId      = "4q1xlclmfloku33"
+	otherUserId = "oap640cot4yru2s"
+	superuserId = "sywbhecnh46rhm0"
+	demo1Record = "84nmscqy84lsi1t"
+)
+
+// testHTTPClient is an [s3.HTTPClient] that sends the requests
+// directly to the app router.
+type testHTTPClient struct {
+	client *tests.TestClient
+}
+
+func (c *testHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	// buffer the body to emulate a real server request
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	} else {
+		req.Body = http.NoBody
+	}
+
+	req.RequestURI = req.URL.RequestURI()
+	if req.Host == "" {
+		req.Host = req.URL.Host
+	}
+
+	return c.client.Do(req), nil
+}
+
+type testEnv struct {
+	app       *tests.TestApp
+	client    *tests.TestClient
+	s3        *s3.Client
+	apiKey    *core.APIKey
+	plainKey  string
+	secretKey string
+}
+
+func newTestEnv(t testing.TB, ownerCollection string, ownerId string, scopes []string) *testEnv {
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s3api.MustRegister(app, s3api.Config{Secret: testSecret})
+
+	owner, err := app.FindRecordById(ownerCollection, ownerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plainKey := core.GenerateAPIKey()
+
+	apiKey := core.NewAPIKey(app)
+	apiKey.SetCollectionRef(owner.Collection().Id)
+	apiKey.SetRecordRef(owner.Id)
+	apiKey.SetName("s3")
+	apiKey.SetKey(plainKey)
+	apiKey.SetScopes(scopes)
+	if err := app.Save(apiKey); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := tests.NewTestClient(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := &testEnv{
+		app:       app,
+		client:    client,
+		apiKey:    apiKey,
+		plainKey:  plainKey,
+		secretKey: s3api.SecretAccessKey(testSecret, plainKey),
+	}
+
+	env.s3 = s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String("http://example.com/s3"),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider(apiKey.Id, env.secretKey, ""),
+		HTTPClient:   &testHTTPClient{client: client},
+	})
+
+	return env
+}
+
+func s3ErrorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return ""
+}
+
+func TestSecretAccessKey(t *testing.T) {
+	t.Parallel()
+
+	expected := security.HS256(security.SHA256("test"), "secret")
+
+	if secret := s3api.SecretAccessKey("secret", "test"); secret != expected {
+		t.Fatalf("Expected %q, got %q", expected, secret)
+	}
+
+	if secret := s3api.SecretAccessKey("secret", "test"); secret == security.SHA256("test") {
+		t.Fatal("Expected the secret access key to differ from the stored API key hash")
+	}
+
+	if a, b := s3api.SecretAccessKey("a", "test"), s3api.SecretAccessKey("b", "test"); a == b {
+		t.Fatalf("Expected different secret access keys for different secrets, got %q", a)
+	}
+}
+
+func TestRegisterErrors(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := s3api.Register(app, s3api.Config{BasePath: "/", Secret: testSecret}); err == nil {
+		t.Fatal("Expected BasePath register error")
+	}
+
+	if err := s3api.Register(app, s3api.Config{}); err == nil {
+		t.Fatal("Expected missing Secret register error")
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, "users", userId, []string{"read"})
+	defer env.app.Cleanup()
+
+	t.Run("guest", func(t *testing.T) {
+		res := env.client.Do(httptest.NewRequest(http.MethodGet, s3api.CredentialsPath, nil))
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Expected status 401, got %d", res.StatusCode)
+		}
+	})
+
+	t.Run("auth token", func(t *testing.T) {
+		user, err := env.app.FindRecordById("users", userId)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		token, err := user.NewAuthToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, s3api.CredentialsPath, nil)
+		req.Header.Set("Authorization", token)
+
+		res := env.client.Do(req)
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Expected status 401, got %d", res.StatusCode)
+		}
+	})
+
+	t.Run("api key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, s3api.CredentialsPath, nil)
+		req.Header.Set("Authorization", env.plainKey)
+
+		res := env.client.Do(req)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", res.StatusCode)
+		}
+
+		result := map[string]string{}
+		if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+
+		if result["accessKeyId"] != env.apiKey.Id {
+			t.Fatalf("Expected accessKeyId %q, got %q", env.apiKey.Id, result["accessKeyId"])
+		}
+
+		if result["secretAccessKey"] != env.secretKey {
+			t.Fatalf("Expected secretAccessKey %q, got %q", env.secretKey, result["secretAccessKey"])
+		}
+
+		if cc := res.Header.Get("Cache-Control"); cc != "no-store" {
+			t.Fatalf("Expected no-store Cache-Control header, got %q", cc)
+		}
+	})
+}
+
+func TestListBuckets(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, core.CollectionNameSuperusers, superuserId, []string{"read", "demo1:write"})
+	defer env.app.Cleanup()
+
+	result, err := env.s3.ListBuckets(context.Background(), &s3.ListBucketsInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, b := range result.Buckets {
+		names = append(names, aws.ToString(b.Name))
+	}
+
+	for _, expected := range []string{"demo1", "demo3", "users"} {
+		if !slices.Contains(names, expected) {
+			t.Fatalf("Expected bucket %q in %v", expected, names)
+		}
+	}
+
+	for _, unexpected := range []string{"demo2", "view1", "_superusers", "_apiKeys"} {
+		if slices.Contains(names, unexpected) {
+			t.Fatalf("Didn't expect bucket %q in %v", unexpected, names)
+		}
+	}
+
+	if _, err := env.s3.HeadBucket(context.Background(), &s3.HeadBucketInput{Bucket: aws.String("demo1")}); err != nil {
+		t.Fatalf("Expected HeadBucket to succeed, got %v", err)
+	}
+
+	if _, err := env.s3.HeadBucket(context.Background(), &s3.HeadBucketInput{Bucket: aws.String("demo2")}); err == nil {
+		t.Fatal("Expected HeadBucket error for a collection without file fields")
+	}
+}
+
+func TestListObjects(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, core.CollectionNameSuperusers, superuserId, []string{"read"})
+	defer env.app.Cleanup()
+
+	ctx := context.Background()
+
+	t.Run("all", func(t *testing.T) {
+		result, err := env.s3.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket: aws.String("demo1"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keys := []string{}
+		for _, obj := range result.Contents {
+			keys = append(keys, aws.ToString(obj.Key))
+			if aws.ToInt64(obj.Size) <= 0 {
+				t.Fatalf("Expected non-empty object size for %q", aws.ToString(obj.Key))
+			}
+		}
+
+		for _, expected := range []string{
+			demo1Record + "/test_d61b33QdDU.txt",
+			demo1Record + "/300_WlbFWSGmW9.png",
+			"al1h9ijdeojtsjy/300_Jsjq7RdBgA.png",
+		} {
+			if !slices.Contains(keys, expected) {
+				t.Fatalf("Expected key %q in %v", expected, keys)
+			}
+		}
+
+		if !slices.IsSorted(keys) {
+			t.Fatalf("Expected sorted keys, got %v", keys)
+		}
+	})
+
+	t.Run("delimiter", func(t *testing.T) {
+		result, err := env.s3.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:    aws.String("demo1"),
+			Delimiter: aws.String("/"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result.Contents) != 0 {
+			t.Fatalf("Expected no direct objects, got %d", len(result.Contents))
+		}
+
+		prefixes := []string{}
+		for _, p := range result.CommonPrefixes {
+			prefixes = append(prefixes, aws.ToString(p.Prefix))
+		}
+
+		if !slices.Contains(prefixes, demo1Record+"/") {
+			t.Fatalf("Expected prefix %q in %v", demo1Record+"/", prefixes)
+		}
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		all := []string{}
+
+		paginator := s3.NewListObjectsV2Paginator(env.s3, &s3.ListObjectsV2Input{
+			Bucket:  aws.String("demo1"),
+			Prefix:  aws.String(demo1Record + "/"),
+			MaxKeys: aws.Int32(2),
+		})
+
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(page.Contents) > 2 {
+				t.Fatalf("Expected max 2 objects per page, got %d", len(page.Contents))
+			}
+
+			for _, obj := range page.Contents {
+				all = append(all, aws.ToString(obj.Key))
+			}
+		}
+
+		if len(all) != 6 {
+			t.Fatalf("Expected 6 objects, got %d: %v", len(all), all)
+		}
+	})
+}
+
+func TestListObjectsRules(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, "users", userId, []string{"read"})
+	defer env.app.Cleanup()
+
+	// users.listRule is nil (superusers only)
+	_, err := env.s3.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{
+		Bucket: aws.String("users"),
+	})
+	if code := s3ErrorCode(err); code != "AccessDenied" {
+		t.Fatalf("Expected AccessDenied error, got %v", err)
+	}
+}
+
+func TestGetObject(t *testing.T) {
+	t.Parallel()
+
+	t.Run("superuser", func(t *testing.T) {
+		env := newTestEnv(t, core.CollectionNameSuperusers, superuserId, []string{"read"})
+		defer env.app.Cleanup()
+
+		result, err := env.s3.GetObject(context.Background(), &s3.GetObjectInput{
+			Bucket: aws.String("demo1"),
+			Key:    aws.String(demo1Record + "/test_d61b33QdDU.txt"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer result.Body.Close()
+
+		body, _ := io.ReadAll(result.Body)
+		if len(body) == 0 {
+			t.Fatal("Expected non-empty object body")
+		}
+
+		if aws.ToString(result.ETag) == "" {
+			t.Fatal("Expected ETag header")
+		}
+
+		_, err = env.s3.GetObject(context.Background(), &s3.GetObjectInput{
+			Bucket: aws.String("demo1"),
+			Key:    aws.String(demo1Record + "/missing.txt"),
+		})
+		if code := s3ErrorCode(err); code != "NoSuchKey" {
+			t.Fatalf("Expected NoSuchKey error, got %v", err)
+		}
+	})
+
+	t.Run("user", func(t *testing.T) {
+		env := newTestEnv(t, "users", userId, []string{"read"})
+		defer env.app.Cleanup()
+
+		head, err := env.s3.HeadObject(context.Background(), &s3.HeadObjectInput{
+			Bucket: aws.String("users"),
+			Key:    aws.String(userId + "/300_1SEi6Q6U72.png"),
+		})
+		if err != nil {
+			t.Fatalf("Expected the own avatar to be accessible, got %v", err)
+		}
+		if aws.ToInt64(head.ContentLength) <= 0 {
+			t.Fatal("Expected non-empty content length")
+		}
+
+		// users.viewRule = "id = @request.auth.id"
+		_, err = env.s3.GetObject(context.Background(), &s3.GetObjectInput{
+			Bucket: aws.String("users"),
+			Key:    aws.String(otherUserId + "/test_kfd2wYLxkz.txt"),
+		})
+		if code := s3ErrorCode(err); code != "NoSuchKey" {
+			t.Fatalf("Expected NoSuchKey error, got %v", err)
+		}
+	})
+}
+
+func TestPutAndDeleteObject(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, core.CollectionNameSuperusers, superuserId, []string{"read", "write"})
+	defer env.app.Cleanup()
+
+	ctx := context.Background()
+
+	t.Run("missing field", func(t *testing.T) {
+		_, err := env.s3.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String("demo1"),
+			Key:    aws.String(demo1Record + "/new.txt"),
+			Body:   strings.NewReader("hello"),
+		})
+		if code := s3ErrorCode(err); code != "InvalidArgument" {
+			t.Fatalf("Expected InvalidArgument error, got %v", err)
+		}
+	})
+
+	t.Run("missing record", func(t *testing.T) {
+		_, err := env.s3.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:   aws.String("demo1"),
+			Key:      aws.String("missing/new.txt"),
+			Body:     strings.NewReader("hello"),
+			Metadata: map[string]string{"field": "file_many"},
+		})
+		if code := s3ErrorCode(err); code != "NoSuchKey" {
+			t.Fatalf("Expected NoSuchKey error, got %v", err)
+		}
+	})
+
+	t.Run("new", func(t *testing.T) {
+		_, err := env.s3.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:   aws.String("demo1"),
+			Key:      aws.String(demo1Record + "/new.txt"),
+			Body:     strings.NewReader("hello"),
+			Metadata: map[string]string{"field": "file_many"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		record, err := env.app.FindRecordById("demo1", demo1Record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files := record.GetStringSlice("file_many")
+		if len(files) != 6 || files[5] != "new.txt" {
+			t.Fatalf("Expected new.txt to be appended, got %v", files)
+		}
+
+		assertObjectContent(t, env, "demo1", demo1Record+"/new.txt", "hello")
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		_, err := env.s3.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String("demo1"),
+			Key:    aws.String(demo1Record + "/new.txt"),
+			Body:   strings.NewReader("replaced"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		record, err := env.app.FindRecordById("demo1", demo1Record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if files := record.GetStringSlice("file_many"); len(files) != 6 {
+			t.Fatalf("Expected the files list to remain the same, got %v", files)
+		}
+
+		assertObjectContent(t, env, "demo1", demo1Record+"/new.txt", "replaced")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		_, err := env.s3.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String("demo1"),
+			Key:    aws.String(demo1Record + "/new.txt"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		record, err := env.app.FindRecordById("demo1", demo1Record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if files := record.GetStringSlice("file_many"); slices.Contains(files, "new.txt") {
+			t.Fatalf("Expected new.txt to be removed, got %v", files)
+		}
+
+		// deleting a missing object is a noop
+		_, err = env.s3.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String("demo1"),
+			Key:    aws.String(demo1Record + "/new.txt"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestPutObjectScope(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, core.CollectionNameSuperusers, superuserId, []string{"read", "demo3:write"})
+	defer env.app.Cleanup()
+
+	_, err := env.s3.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:   aws.String("demo1"),
+		Key:      aws.String(demo1Record + "/new.txt"),
+		Body:     strings.NewReader("hello"),
+		Metadata: map[string]string{"field": "file_many"},
+	})
+	if code := s3ErrorCode(err); code != "AccessDenied" {
+		t.Fatalf("Expected AccessDenied error, got %v", err)
+	}
+}
+
+func TestPresignedGetObject(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, core.CollectionNameSuperusers, superuserId, []string{"read"})
+	defer env.app.Cleanup()
+
+	presigned, err := s3.NewPresignClient(env.s3).PresignGetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String("demo1"),
+		Key:    aws.String(demo1Record + "/test_d61b33QdDU.txt"),
+	}, s3.WithPresignExpires(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, presigned.URL, nil)
+	res := env.client.Do(req)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", res.StatusCode)
+	}
+
+	// tampered url
+	req = httptest.NewRequest(http.MethodGet, strings.Replace(presigned.URL, "test_d61b33QdDU.txt", "300_WlbFWSGmW9.png", 1), nil)
+	res = env.client.Do(req)
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status 403, got %d", res.StatusCode)
+	}
+}
+
+func TestAuthErrors(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, core.CollectionNameSuperusers, superuserId, []string{"read"})
+	defer env.app.Cleanup()
+
+	scenarios := []struct {
+		name         string
+		accessKey    string
+		secretKey    string
+		expectedCode string
+	}{
+		{"unknown access key", "missing", env.secretKey, "InvalidAccessKeyId"},
+		{"invalid secret", env.apiKey.Id, "invalid", "SignatureDoesNotMatch"},
+		{"plain key as secret", env.apiKey.Id, env.plainKey, "SignatureDoesNotMatch"},
+		{"stored key hash as secret", env.apiKey.Id, env.apiKey.KeyHash(), "SignatureDoesNotMatch"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			client := s3.New(s3.Options{
+				Region:       "us-east-1",
+				BaseEndpoint: aws.String("http://example.com/s3"),
+				UsePathStyle: true,
+				Credentials:  credentials.NewStaticCredentialsProvider(s.accessKey, s.secretKey, ""),
+				HTTPClient:   &testHTTPClient{client: env.client},
+			})
+
+			_, err := client.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{
+				Bucket: aws.String("demo1"),
+			})
+			if code := s3ErrorCode(err); code != s.expectedCode {
+				t.Fatalf("Expected %s error, got %v", s.expectedCode, err)
+			}
+		})
+	}
+
+	t.Run("unsigned", func(t *testing.T) {
+		res := env.client.Do(httptest.NewRequest(http.MethodGet, "/s3/demo1", nil))
+		if res.StatusCode != http.StatusForbidden {
+			t.Fatalf("Expected status 403, got %d", res.StatusCode)
+		}
+
+		body, _ := io.ReadAll(res.Body)
+		if !strings.Contains(string(body), "<Code>AccessDenied</Code>") {
+			t.Fatalf("Expected AccessDenied XML error, got %s", body)
+		}
+	})
+
+	t.Run("expired date", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/s3/demo1", nil)
+		req.Header.Set("X-Amz-Content-Sha256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+
+		signer := v4.NewSigner()
+		err := signer.SignHTTP(
+			context.Background(),
+			aws.Credentials{AccessKeyID: env.apiKey.Id, SecretAccessKey: env.secretKey},
+			req,
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			"s3",
+			"us-east-1",
+			time.Now().Add(-1*time.Hour),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res := env.client.Do(req)
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != http.StatusForbidden || !strings.Contains(string(body), "RequestTimeTooSkewed") {
+			t.Fatalf("Expected RequestTimeTooSkewed error, got %d %s", res.StatusCode, body)
+		}
+	})
+}
+
+func assertObjectContent(t testing.TB, env *testEnv, bucket string, key string, expected string) {
+	t.Helper()
+
+	result, err := env.s3.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Body.Close()
+
+	body, _ := io.ReadAll(result.Body)
+	if string(body) != expected {
+		t.Fatalf("Expected object content %q, got %q", expected, body)
+	}
+}
@@ -0,0 +1,298 @@
+package s3api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signatureAlgorithm = "AWS4-HMAC-SHA256"
+	unsignedPayload    = "UNSIGNED-PAYLOAD"
+	amzDateFormat      = "20060102T150405Z"
+
+	// maxClockSkew is the max allowed difference between the signed request date and the server time.
+	maxClockSkew = 15 * time.Minute
+
+	// maxPresignExpires is the max allowed presigned url expiration (7 days).
+	maxPresignExpires = 604800
+)
+
+// signedRequest holds the parsed AWS Signature Version 4 request data.
+type signedRequest struct {
+	accessKeyId   string
+	scope         string
+	scopeDate     string
+	scopeRegion   string
+	scopeService  string
+	date          time.Time
+	rawDate       string
+	signedHeaders []string
+	signature     string
+	payloadHash   string
+	presigned     bool
+}
+
+func errSignature(message string, cause error) *s3Error {
+	return newS3Error(http.StatusForbidden, "SignatureDoesNotMatch", message, cause)
+}
+
+// parseSignedRequest extracts the Signature Version 4 data from the
+// request Authorization header or from the presigned url query parameters.
+func parseSignedRequest(r *http.Request) (*signedRequest, error) {
+	query := r.URL.Query()
+
+	if query.Get("X-Amz-Algorithm") != "" {
+		return parsePresignedRequest(r)
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, errAccessDenied(errors.New("missing Authorization header"))
+	}
+
+	algorithm, rest, _ := strings.Cut(authHeader, " ")
+	if algorithm != signatureAlgorithm {
+		return nil, newS3Error(http.StatusBadRequest, "InvalidArgument", "Unsupported Authorization Type", nil)
+	}
+
+	signed := &signedRequest{}
+
+	for _, part := range strings.Split(rest, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "Credential":
+			if err := signed.parseCredential(value); err != nil {
+				return nil, err
+			}
+		case "SignedHeaders":
+			signed.signedHeaders = strings.Split(value, ";")
+		case "Signature":
+			signed.signature = value
+		}
+	}
+
+	if signed.accessKeyId == "" || len(signed.signedHeaders) == 0 || signed.signature == "" {
+		return nil, newS3Error(http.StatusBadRequest, "AuthorizationHeaderMalformed", "The authorization header is malformed.", nil)
+	}
+
+	signed.rawDate = r.Header.Get("X-Amz-Date")
+	if signed.rawDate == "" {
+		return nil, newS3Error(http.StatusForbidden, "AccessDenied", "AWS authentication requires a valid Date or x-amz-date header", nil)
+	}
+
+	date, err := time.Parse(amzDateFormat, signed.rawDate)
+	if err != nil {
+		return nil, newS3Error(http.StatusForbidden, "AccessDenied", "AWS authentication requires a valid Date or x-amz-date header", err)
+	}
+	signed.date = date
+
+	if skew := time.Since(date); skew > maxClockSkew || skew < -maxClockSkew {
+		return nil, newS3Error(http.StatusForbidden, "RequestTimeTooSkewed", "The difference between the request time and the current time is too large.", nil)
+	}
+
+	signed.payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+	if signed.payloadHash == "" {
+		return nil, newS3Error(http.StatusBadRequest, "InvalidRequest", "Missing required header for this request: x-amz-content-sha256", nil)
+	}
+	if strings.HasPrefix(signed.payloadHash, "STREAMING-") {
+		return nil, newS3Error(http.StatusNotImplemented, "NotImplemented", "Chunked payload signing is not supported.", nil)
+	}
+
+	return signed, nil
+}
+
+func parsePresignedRequest(r *http.Request) (*signedRequest, error) {
+	query := r.URL.Query()
+
+	if query.Get("X-Amz-Algorithm") != signatureAlgorithm {
+		return nil, newS3Error(http.StatusBadRequest, "InvalidArgument", "Unsupported X-Amz-Algorithm", nil)
+	}
+
+	signed := &signedRequest{
+		presigned:     true,
+		payloadHash:   unsignedPayload,
+		signature:     query.Get("X-Amz-Signature"),
+		signedHeaders: strings.Split(query.Get("X-Amz-SignedHeaders"), ";"),
+		rawDate:       query.Get("X-Amz-Date"),
+	}
+
+	if err := signed.parseCredential(query.Get("X-Amz-Credential")); err != nil {
+		return nil, err
+	}
+
+	if signed.signature == "" || query.Get("X-Amz-SignedHeaders") == "" {
+		return nil, newS3Error(http.StatusBadRequest, "AuthorizationQueryParametersError", "Query-string authentication version 4 requires the X-Amz-Algorithm, X-Amz-Credential, X-Amz-Signature, X-Amz-Date, X-Amz-SignedHeaders, and X-Amz-Expires parameters.", nil)
+	}
+
+	date, err := time.Parse(amzDateFormat, signed.rawDate)
+	if err != nil {
+		return nil, newS3Error(http.StatusBadRequest, "AuthorizationQueryParametersError", "X-Amz-Date must be in the ISO8601 Long Format \"yyyyMMdd'T'HHmmss'Z'\"", err)
+	}
+	signed.date = date
+
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || expires < 0 || expires > maxPresignExpires {
+		return nil, newS3Error(http.StatusBadRequest, "AuthorizationQueryParametersError", "X-Amz-Expires must be a non-negative integer less than 604800 seconds", err)
+	}
+
+	if date.After(time.Now().Add(maxClockSkew)) {
+		return nil, newS3Error(http.StatusForbidden, "AccessDenied", "Request is not valid yet", nil)
+	}
+
+	if time.Now().After(date.Add(time.Duration(expires) * time.Second)) {
+		return nil, newS3Error(http.StatusForbidden, "AccessDenied", "Request has expired", nil)
+	}
+
+	if hash := r.Header.Get("X-Amz-Content-Sha256"); hash != "" {
+		signed.payloadHash = hash
+	}
+
+	return signed, nil
+}
+
+// parseCredential parses a "{accessKeyId}/{date}/{region}/{service}/aws4_request" credential string.
+func (signed *signedRequest) parseCredential(credential string) error {
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[0] == "" || parts[3] != "s3" || parts[4] != "aws4_request" {
+		return newS3Error(http.StatusBadRequest, "AuthorizationHeaderMalformed", "The authorization header is malformed; the Credential is mal-formed.", nil)
+	}
+
+	signed.accessKeyId = parts[0]
+	signed.scopeDate = parts[1]
+	signed.scopeRegion = parts[2]
+	signed.scopeService = parts[3]
+	signed.scope = strings.Join(parts[1:], "/")
+
+	return nil
+}
+
+// verify checks the request signature with the provided secret access key.
+func (signed *signedRequest) verify(r *http.Request, secret string) error {
+	if signed.scopeDate != signed.date.Format("20060102") {
+		return errSignature("The credential scope date doesn't match the request date.", nil)
+	}
+
+	if !slices.Contains(signed.signedHeaders, "host") {
+		return errSignature("The host header must be signed.", nil)
+	}
+
+	canonicalRequest := signed.canonicalRequest(r)
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	stringToSign := signatureAlgorithm + "\n" +
+		signed.rawDate + "\n" +
+		signed.scope + "\n" +
+		hex.EncodeToString(canonicalRequestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secret), signed.scopeDate)
+	key = hmacSHA256(key, signed.scopeRegion)
+	key = hmacSHA256(key, signed.scopeService)
+	key = hmacSHA256(key, "aws4_request")
+
+	expected := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signed.signature))) {
+		return errSignature("The request signature we calculated does not match the signature you provided. Check your key and signing method.", nil)
+	}
+
+	return nil
+}
+
+func (signed *signedRequest) canonicalRequest(r *http.Request) string {
+	var sb strings.Builder
+
+	sb.WriteString(r.Method)
+	sb.WriteString("\n")
+	sb.WriteString(uriEncode(r.URL.Path, false))
+	sb.WriteString("\n")
+	sb.WriteString(canonicalQuery(r, signed.presigned))
+	sb.WriteString("\n")
+
+	for _, name := range signed.signedHeaders {
+		sb.WriteString(name)
+		sb.WriteString(":")
+		sb.WriteString(canonicalHeaderValue(r, name))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(strings.Join(signed.signedHeaders, ";"))
+	sb.WriteString("\n")
+	sb.WriteString(signed.payloadHash)
+
+	return sb.String()
+}
+
+func canonicalQuery(r *http.Request, presigned bool) string {
+	query := r.URL.Query()
+
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		if presigned && key == "X-Amz-Signature" {
+			continue
+		}
+
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(v, true))
+		}
+	}
+
+	slices.Sort(pairs)
+
+	return strings.Join(pairs, "&")
+}
+
+func canonicalHeaderValue(r *http.Request, name string) string {
+	var values []string
+
+	switch name {
+	case "host":
+		values = []string{r.Host}
+	case "content-length":
+		values = r.Header.Values(name)
+		if len(values) == 0 {
+			values = []string{strconv.FormatInt(r.ContentLength, 10)}
+		}
+	default:
+		values = r.Header.Values(name)
+	}
+
+	for i, v := range values {
+		values[i] = strings.Join(strings.Fields(v), " ")
+	}
+
+	return strings.Join(values, ",")
+}
+
+// uriEncode encodes the string as described in the Signature Version 4
+// specs (all characters except the unreserved ones are percent-encoded).
+func uriEncode(str string, encodeSlash bool) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			sb.WriteByte(c)
+			continue
+		}
+
+		sb.WriteString("%")
+		sb.WriteString(strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+
+	return sb.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
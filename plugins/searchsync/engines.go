@@ -0,0 +1,384 @@
+package searchsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var (
+	_ Engine = (*MeilisearchEngine)(nil)
+	_ Engine = (*TypesenseEngine)(nil)
+	_ Engine = (*ElasticsearchEngine)(nil)
+)
+
+// MeilisearchEngine implements [Engine] for Meilisearch
+// (https://www.meilisearch.com/docs/reference/api/overview).
+//
+// The indexes are created automatically by Meilisearch on first upsert.
+type MeilisearchEngine struct {
+	// URL is the Meilisearch server base url (e.g. "http://localhost:7700").
+	URL string
+
+	// APIKey is the optional Meilisearch API key.
+	APIKey string
+
+	// Client is an optional custom HTTP client (default to [http.DefaultClient]).
+	Client *http.Client
+}
+
+// Upsert implements [Engine.Upsert].
+func (m *MeilisearchEngine) Upsert(ctx context.Context, index string, docs []Document) error {
+	return m.send(ctx, http.MethodPost, "/indexes/"+url.PathEscape(index)+"/documents?primaryKey=id", docs, nil)
+}
+
+// Delete implements [Engine.Delete].
+func (m *MeilisearchEngine) Delete(ctx context.Context, index string, ids []string) error {
+	return m.send(ctx, http.MethodPost, "/indexes/"+url.PathEscape(index)+"/documents/delete-batch", ids, nil)
+}
+
+// Search implements [Engine.Search].
+func (m *MeilisearchEngine) Search(ctx context.Context, index string, query Query) ([]string, error) {
+	body := map[string]any{
+		"q":                    query.Text,
+		"offset":               (query.Page - 1) * query.PerPage,
+		"limit":                query.PerPage,
+		"attributesToRetrieve": []string{"id"},
+	}
+
+	result := struct {
+		Hits []struct {
+			Id string `json:"id"`
+		} `json:"hits"`
+	}{}
+
+	if err := m.send(ctx, http.MethodPost, "/indexes/"+url.PathEscape(index)+"/search", body, &result); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(result.Hits))
+	for i, hit := range result.Hits {
+		ids[i] = hit.Id
+	}
+
+	return ids, nil
+}
+
+func (m *MeilisearchEngine) send(ctx context.Context, method string, path string, body any, result any) error {
+	headers := map[string]string{"Content-Type": "application/json"}
+	if m.APIKey != "" {
+		headers["Authorization"] = "Bearer " + m.APIKey
+	}
+
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	return sendRequest(ctx, m.Client, method, strings.TrimSuffix(m.URL, "/")+path, headers, raw, result)
+}
+
+// -------------------------------------------------------------------
+
+// TypesenseEngine implements [Engine] for Typesense
+// (https://typesense.org/docs/latest/api/).
+//
+// Note that the Typesense collections (aka. indexes) must be created
+// in advance with a schema matching the mapped documents.
+type TypesenseEngine struct {
+	// URL is the Typesense server base url (e.g. "http://localhost:8108").
+	URL string
+
+	// APIKey is the Typesense API key.
+	APIKey string
+
+	// QueryBy is a comma separated list with the document fields to search
+	// (e.g. "title,description").
+	QueryBy string
+
+	// Client is an optional custom HTTP client (default to [http.DefaultClient]).
+	Client *http.Client
+}
+
+// Upsert implements [Engine.Upsert].
+func (t *TypesenseEngine) Upsert(ctx context.Context, index string, docs []Document) error {
+	var buf bytes.Buffer
+
+	for _, doc := range docs {
+		raw, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		buf.Write(raw)
+		buf.WriteByte('\n')
+	}
+
+	result := bytes.Buffer{}
+	err := t.send(ctx, http.MethodPost, "/collections/"+url.PathEscape(index)+"/documents/import?action=upsert", "text/plain", buf.Bytes(), &result)
+	if err != nil {
+		return err
+	}
+
+	// the import endpoint returns 200 and reports the errors per line
+	for _, line := range strings.Split(strings.TrimSpace(result.String()), "\n") {
+		status := struct {
+			Success bool   `json:"success"`
+			Error   string `json:"error"`
+		}{}
+		if err := json.Unmarshal([]byte(line), &status); err == nil && !status.Success {
+			return fmt.Errorf("typesense import error: %s", status.Error)
+		}
+	}
+
+	return nil
+}
+
+// Delete implements [Engine.Delete].
+func (t *TypesenseEngine) Delete(ctx context.Context, index string, ids []string) error {
+	filter := url.QueryEscape("id:[" + strings.Join(ids, ",") + "]")
+
+	return t.send(ctx, http.MethodDelete, "/collections/"+url.PathEscape(index)+"/documents?filter_by="+filter, "", nil, nil)
+}
+
+// Search implements [Engine.Search].
+func (t *TypesenseEngine) Search(ctx context.Context, index string, query Query) ([]string, error) {
+	params := url.Values{}
+	params.Set("q", query.Text)
+	params.Set("query_by", t.QueryBy)
+	params.Set("page", strconv.Itoa(query.Page))
+	params.Set("per_page", strconv.Itoa(query.PerPage))
+	params.Set("include_fields", "id")
+
+	result := struct {
+		Hits []struct {
+			Document struct {
+				Id string `json:"id"`
+			} `json:"document"`
+		} `json:"hits"`
+	}{}
+
+	if err := t.send(ctx, http.MethodGet, "/collections/"+url.PathEscape(index)+"/documents/search?"+params.Encode(), "", nil, &result); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(result.Hits))
+	for i, hit := range result.Hits {
+		ids[i] = hit.Document.Id
+	}
+
+	return ids, nil
+}
+
+func (t *TypesenseEngine) send(ctx context.Context, method string, path string, contentType string, body []byte, result any) error {
+	headers := map[string]string{"X-TYPESENSE-API-KEY": t.APIKey}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+
+	return sendRequest(ctx, t.Client, method, strings.TrimSuffix(t.URL, "/")+path, headers, body, result)
+}
+
+// -------------------------------------------------------------------
+
+// ElasticsearchEngine implements [Engine] for Elasticsearch and OpenSearch
+// (https://www.elastic.co/guide/en/elasticsearch/reference/current/rest-apis.html).
+type ElasticsearchEngine struct {
+	// URL is the Elasticsearch server base url (e.g. "http://localhost:9200").
+	URL string
+
+	// APIKey is an optional base64 encoded Elasticsearch API key.
+	APIKey string
+
+	// Username and Password are optional basic auth credentials.
+	Username string
+	Password string
+
+	// Client is an optional custom HTTP client (default to [http.DefaultClient]).
+	Client *http.Client
+}
+
+// Upsert implements [Engine.Upsert].
+func (es *ElasticsearchEngine) Upsert(ctx context.Context, index string, docs []Document) error {
+	var buf bytes.Buffer
+
+	for _, doc := range docs {
+		action, _ := json.Marshal(map[string]any{"index": map[string]any{"_index": index, "_id": doc.Id()}})
+		buf.Write(action)
+		buf.WriteByte('\n')
+
+		raw, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		buf.Write(raw)
+		buf.WriteByte('\n')
+	}
+
+	return es.bulk(ctx, buf.Bytes())
+}
+
+// Delete implements [Engine.Delete].
+func (es *ElasticsearchEngine) Delete(ctx context.Context, index string, ids []string) error {
+	var buf bytes.Buffer
+
+	for _, id := range ids {
+		action, _ := json.Marshal(map[string]any{"delete": map[string]any{"_index": index, "_id": id}})
+		buf.Write(action)
+		buf.WriteByte('\n')
+	}
+
+	return es.bulk(ctx, buf.Bytes())
+}
+
+// Search implements [Engine.Search].
+func (es *ElasticsearchEngine) Search(ctx context.Context, index string, query Query) ([]string, error) {
+	body, err := json.Marshal(map[string]any{
+		"query": map[string]any{
+			"simple_query_string": map[string]any{"query": query.Text},
+		},
+		"from":    (query.Page - 1) * query.PerPage,
+		"size":    query.PerPage,
+		"_source": false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := struct {
+		Hits struct {
+			Hits []struct {
+				Id string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}{}
+
+	if err := es.send(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", "application/json", body, &result); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(result.Hits.Hits))
+	for i, hit := range result.Hits.Hits {
+		ids[i] = hit.Id
+	}
+
+	return ids, nil
+}
+
+func (es *ElasticsearchEngine) bulk(ctx context.Context, body []byte) error {
+	result := struct {
+		Errors bool `json:"errors"`
+	}{}
+
+	if err := es.send(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", body, &result); err != nil {
+		return err
+	}
+
+	if result.Errors {
+		return fmt.Errorf("elasticsearch bulk request completed with errors")
+	}
+
+	return nil
+}
+
+func (es *ElasticsearchEngine) send(ctx context.Context, method string, path string, contentType string, body []byte, result any) error {
+	headers := map[string]string{"Content-Type": contentType}
+	if es.APIKey != "" {
+		headers["Authorization"] = "ApiKey " + es.APIKey
+	}
+
+	client := es.Client
+	if es.Username != "" {
+		client = basicAuthClient(client, es.Username, es.Password)
+	}
+
+	return sendRequest(ctx, client, method, strings.TrimSuffix(es.URL, "/")+path, headers, body, result)
+}
+
+// -------------------------------------------------------------------
+
+// sendRequest sends a single HTTP request and decodes the JSON response into result (if not nil).
+//
+// If result is *bytes.Buffer the raw response body is copied into it.
+func sendRequest(
+	ctx context.Context,
+	client *http.Client,
+	method string,
+	url string,
+	headers map[string]string,
+	body []byte,
+	result any,
+) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("%s %s failed with status %d: %s", method, req.URL.Path, res.StatusCode, msg)
+	}
+
+	switch v := result.(type) {
+	case nil:
+		return nil
+	case *bytes.Buffer:
+		_, err = io.Copy(v, res.Body)
+		return err
+	default:
+		return json.NewDecoder(res.Body).Decode(result)
+	}
+}
+
+type basicAuthTransport struct {
+	base     http.RoundTripper
+	username string
+	password string
+}
+
+func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.username, t.password)
+
+	return t.base.RoundTrip(req)
+}
+
+func basicAuthClient(client *http.Client, username string, password string) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	clone := *client
+	clone.Transport = &basicAuthTransport{base: base, username: username, password: password}
+
+	return &clone
+}
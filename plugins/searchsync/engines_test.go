@@ -0,0 +1,278 @@
+package searchsync
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type recordedRequest struct {
+	method string
+	uri    string
+	header http.Header
+	body   string
+}
+
+func newTestServer(t testing.TB, response string) (*httptest.Server, *[]recordedRequest) {
+	requests := []recordedRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		requests = append(requests, recordedRequest{
+			method: r.Method,
+			uri:    r.URL.RequestURI(),
+			header: r.Header.Clone(),
+			body:   string(body),
+		})
+
+		w.Write([]byte(response))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestMeilisearchEngine(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("upsert", func(t *testing.T) {
+		server, requests := newTestServer(t, `{}`)
+
+		engine := &MeilisearchEngine{URL: server.URL + "/", APIKey: "test"}
+
+		err := engine.Upsert(ctx, "posts", []Document{{"id": "a", "title": "A"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := (*requests)[0]
+		if req.method != http.MethodPost || req.uri != "/indexes/posts/documents?primaryKey=id" {
+			t.Fatalf("Unexpected request %s %s", req.method, req.uri)
+		}
+		if v := req.header.Get("Authorization"); v != "Bearer test" {
+			t.Fatalf("Expected Bearer authorization header, got %q", v)
+		}
+		if req.body != `[{"id":"a","title":"A"}]` {
+			t.Fatalf("Unexpected body %s", req.body)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		server, requests := newTestServer(t, `{}`)
+
+		engine := &MeilisearchEngine{URL: server.URL}
+
+		if err := engine.Delete(ctx, "posts", []string{"a", "b"}); err != nil {
+			t.Fatal(err)
+		}
+
+		req := (*requests)[0]
+		if req.method != http.MethodPost || req.uri != "/indexes/posts/documents/delete-batch" {
+			t.Fatalf("Unexpected request %s %s", req.method, req.uri)
+		}
+		if v := req.header.Get("Authorization"); v != "" {
+			t.Fatalf("Expected no authorization header, got %q", v)
+		}
+		if req.body != `["a","b"]` {
+			t.Fatalf("Unexpected body %s", req.body)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		server, requests := newTestServer(t, `{"hits":[{"id":"b"},{"id":"a"}]}`)
+
+		engine := &MeilisearchEngine{URL: server.URL}
+
+		ids, err := engine.Search(ctx, "posts", Query{Text: "test", Page: 2, PerPage: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Join(ids, ",") != "b,a" {
+			t.Fatalf("Expected ids b,a, got %v", ids)
+		}
+
+		req := (*requests)[0]
+		if req.uri != "/indexes/posts/search" {
+			t.Fatalf("Unexpected request uri %s", req.uri)
+		}
+		for _, part := range []string{`"q":"test"`, `"offset":10`, `"limit":10`} {
+			if !strings.Contains(req.body, part) {
+				t.Fatalf("Missing %s in body %s", part, req.body)
+			}
+		}
+	})
+}
+
+func TestTypesenseEngine(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("upsert", func(t *testing.T) {
+		server, requests := newTestServer(t, "{\"success\":true}\n{\"success\":true}")
+
+		engine := &TypesenseEngine{URL: server.URL, APIKey: "test"}
+
+		err := engine.Upsert(ctx, "posts", []Document{{"id": "a"}, {"id": "b"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := (*requests)[0]
+		if req.method != http.MethodPost || req.uri != "/collections/posts/documents/import?action=upsert" {
+			t.Fatalf("Unexpected request %s %s", req.method, req.uri)
+		}
+		if v := req.header.Get("X-TYPESENSE-API-KEY"); v != "test" {
+			t.Fatalf("Expected api key header, got %q", v)
+		}
+		if req.body != "{\"id\":\"a\"}\n{\"id\":\"b\"}\n" {
+			t.Fatalf("Unexpected body %q", req.body)
+		}
+	})
+
+	t.Run("upsert with line error", func(t *testing.T) {
+		server, _ := newTestServer(t, "{\"success\":true}\n{\"success\":false,\"error\":\"invalid\"}")
+
+		engine := &TypesenseEngine{URL: server.URL}
+
+		err := engine.Upsert(ctx, "posts", []Document{{"id": "a"}, {"id": "b"}})
+		if err == nil || !strings.Contains(err.Error(), "invalid") {
+			t.Fatalf("Expected import error, got %v", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		server, requests := newTestServer(t, `{}`)
+
+		engine := &TypesenseEngine{URL: server.URL}
+
+		if err := engine.Delete(ctx, "posts", []string{"a", "b"}); err != nil {
+			t.Fatal(err)
+		}
+
+		req := (*requests)[0]
+		if req.method != http.MethodDelete || req.uri != "/collections/posts/documents?filter_by=id%3A%5Ba%2Cb%5D" {
+			t.Fatalf("Unexpected request %s %s", req.method, req.uri)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		server, requests := newTestServer(t, `{"hits":[{"document":{"id":"b"}},{"document":{"id":"a"}}]}`)
+
+		engine := &TypesenseEngine{URL: server.URL, QueryBy: "title"}
+
+		ids, err := engine.Search(ctx, "posts", Query{Text: "test", Page: 2, PerPage: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Join(ids, ",") != "b,a" {
+			t.Fatalf("Expected ids b,a, got %v", ids)
+		}
+
+		req := (*requests)[0]
+		if req.method != http.MethodGet || req.uri != "/collections/posts/documents/search?include_fields=id&page=2&per_page=10&q=test&query_by=title" {
+			t.Fatalf("Unexpected request %s %s", req.method, req.uri)
+		}
+	})
+}
+
+func TestElasticsearchEngine(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("upsert", func(t *testing.T) {
+		server, requests := newTestServer(t, `{"errors":false}`)
+
+		engine := &ElasticsearchEngine{URL: server.URL, APIKey: "test"}
+
+		err := engine.Upsert(ctx, "posts", []Document{{"id": "a", "title": "A"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := (*requests)[0]
+		if req.method != http.MethodPost || req.uri != "/_bulk" {
+			t.Fatalf("Unexpected request %s %s", req.method, req.uri)
+		}
+		if v := req.header.Get("Authorization"); v != "ApiKey test" {
+			t.Fatalf("Expected ApiKey authorization header, got %q", v)
+		}
+		expectedBody := "{\"index\":{\"_id\":\"a\",\"_index\":\"posts\"}}\n{\"id\":\"a\",\"title\":\"A\"}\n"
+		if req.body != expectedBody {
+			t.Fatalf("Expected body\n%q\ngot\n%q", expectedBody, req.body)
+		}
+	})
+
+	t.Run("delete with basic auth", func(t *testing.T) {
+		server, requests := newTestServer(t, `{"errors":false}`)
+
+		engine := &ElasticsearchEngine{URL: server.URL, Username: "user", Password: "pass"}
+
+		if err := engine.Delete(ctx, "posts", []string{"a"}); err != nil {
+			t.Fatal(err)
+		}
+
+		req := (*requests)[0]
+		if req.body != "{\"delete\":{\"_id\":\"a\",\"_index\":\"posts\"}}\n" {
+			t.Fatalf("Unexpected body %q", req.body)
+		}
+		if v := req.header.Get("Authorization"); v != "Basic dXNlcjpwYXNz" {
+			t.Fatalf("Expected basic authorization header, got %q", v)
+		}
+	})
+
+	t.Run("bulk errors", func(t *testing.T) {
+		server, _ := newTestServer(t, `{"errors":true}`)
+
+		engine := &ElasticsearchEngine{URL: server.URL}
+
+		if err := engine.Delete(ctx, "posts", []string{"a"}); err == nil {
+			t.Fatal("Expected bulk error")
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		server, requests := newTestServer(t, `{"hits":{"hits":[{"_id":"b"},{"_id":"a"}]}}`)
+
+		engine := &ElasticsearchEngine{URL: server.URL}
+
+		ids, err := engine.Search(ctx, "posts", Query{Text: "test", Page: 3, PerPage: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Join(ids, ",") != "b,a" {
+			t.Fatalf("Expected ids b,a, got %v", ids)
+		}
+
+		req := (*requests)[0]
+		if req.uri != "/posts/_search" {
+			t.Fatalf("Unexpected request uri %s", req.uri)
+		}
+		for _, part := range []string{`"query":"test"`, `"from":10`, `"size":5`} {
+			if !strings.Contains(req.body, part) {
+				t.Fatalf("Missing %s in body %s", part, req.body)
+			}
+		}
+	})
+}
+
+func TestSendRequestErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("invalid index"))
+	}))
+	defer server.Close()
+
+	err := sendRequest(context.Background(), nil, http.MethodGet, server.URL+"/test", nil, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid index") || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("Expected status error, got %v", err)
+	}
+}
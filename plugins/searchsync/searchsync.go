@@ -0,0 +1,482 @@
+// Package searchsync implements mirroring of selected collections
+// into an external search engine (Meilisearch, Typesense, Elasticsearch
+// or any other custom [Engine] implementation).
+//
+// The records are synced in the background on create, update and delete
+// and a full reindex could be triggered with the "searchsync reindex" command.
+//
+// The plugin also registers a search proxy endpoint that queries the
+// search engine and returns the found records filtered with the
+// collection ListRule (aka. the engine is used only for the matching and
+// ranking and the regular PocketBase access checks are applied to the results):
+//
+//	GET /api/searchsync/{collection}?q=...&page=1&perPage=30
+//
+// Example usage:
+//
+//	searchsync.MustRegister(app, app.RootCmd, searchsync.Config{
+//		Engine: &searchsync.MeilisearchEngine{
+//			URL:    "http://localhost:7700",
+//			APIKey: "...",
+//		},
+//		Mappings: []searchsync.Mapping{
+//			{Collection: "posts", Fields: []string{"title", "content", "tags"}},
+//			{Collection: "products", Index: "shop_products"},
+//		},
+//	})
+package searchsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// DefaultBatchSize is the default number of records sent at once during reindex.
+	DefaultBatchSize = 500
+
+	// DefaultTimeout is the default max duration of a single engine request.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultPerPage is the default number of the search proxy endpoint results.
+	DefaultPerPage = 30
+
+	// MaxPerPage is the max allowed number of the search proxy endpoint results.
+	MaxPerPage = 100
+)
+
+// Document defines a single search engine document.
+//
+// Each document has an "id" key with the related record id.
+type Document map[string]any
+
+// Id returns the document "id" key value.
+func (d Document) Id() string {
+	return cast.ToString(d["id"])
+}
+
+// Query defines a search engine query.
+type Query struct {
+	Text    string
+	Page    int
+	PerPage int
+}
+
+// Engine defines an external search engine.
+type Engine interface {
+	// Upsert creates or replaces the provided documents in the specified index.
+	Upsert(ctx context.Context, index string, docs []Document) error
+
+	// Delete deletes the documents with the provided ids from the specified index.
+	Delete(ctx context.Context, index string, ids []string) error
+
+	// Search queries the specified index and returns
+	// the ids of the found documents ordered by relevance.
+	Search(ctx context.Context, index string, query Query) ([]string, error)
+}
+
+// Mapping defines how a single collection is mirrored into the search engine.
+type Mapping struct {
+	// Collection is the name or id of the collection to sync.
+	Collection string
+
+	// Index is the name of the search engine index
+	// (default to the collection name).
+	Index string
+
+	// Fields is an optional list with the record fields to include in the document
+	// (default to all non-hidden fields).
+	//
+	// The document "id" key is always included.
+	Fields []string
+
+	// Transform is an optional function to customize the record document
+	// (e.g. to add computed keys).
+	//
+	// The returned document must have an "id" key with the record id.
+	Transform func(record *core.Record, doc Document) (Document, error)
+}
+
+// Config defines the config options of the searchsync plugin.
+type Config struct {
+	// Engine is the search engine to mirror the collections into.
+	Engine Engine
+
+	// Mappings is a list with the collections to sync.
+	Mappings []Mapping
+
+	// BatchSize specifies the number of records that are loaded and
+	// sent at once during reindex (default to [DefaultBatchSize]).
+	BatchSize int
+
+	// Timeout specifies the max duration of a single engine request
+	// (default to [DefaultTimeout]).
+	Timeout time.Duration
+}
+
+// MustRegister registers the searchsync plugin to the provided app instance
+// and panic if it fails.
+//
+// rootCmd is optional and it is used to register the "searchsync" command.
+func MustRegister(app core.App, rootCmd *cobra.Command, config Config) {
+	if err := Register(app, rootCmd, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the searchsync plugin to the provided app instance.
+//
+// rootCmd is optional and it is used to register the "searchsync" command.
+func Register(app core.App, rootCmd *cobra.Command, config Config) error {
+	if config.Engine == nil {
+		return errors.New("searchsync: Engine is required")
+	}
+
+	if len(config.Mappings) == 0 {
+		return errors.New("searchsync: at least one mapping must be specified")
+	}
+
+	for i, m := range config.Mappings {
+		if m.Collection == "" {
+			return fmt.Errorf("searchsync: invalid mapping %d: Collection is required", i)
+		}
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	p := &plugin{app: app, config: config}
+
+	tags := make([]string, len(config.Mappings))
+	for i, m := range config.Mappings {
+		tags[i] = m.Collection
+	}
+
+	app.OnRecordAfterCreateSuccess(tags...).Bind(&hook.Handler[*core.RecordEvent]{
+		Func: func(e *core.RecordEvent) error {
+			p.syncAsync(e.Record, false)
+			return e.Next()
+		},
+		Priority: -99,
+	})
+
+	app.OnRecordAfterUpdateSuccess(tags...).Bind(&hook.Handler[*core.RecordEvent]{
+		Func: func(e *core.RecordEvent) error {
+			p.syncAsync(e.Record, false)
+			return e.Next()
+		},
+		Priority: -99,
+	})
+
+	app.OnRecordAfterDeleteSuccess(tags...).Bind(&hook.Handler[*core.RecordEvent]{
+		Func: func(e *core.RecordEvent) error {
+			p.syncAsync(e.Record, true)
+			return e.Next()
+		},
+		Priority: -99,
+	})
+
+	app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		e.Router.GET("/api/searchsync/{collection}", p.search)
+
+		return e.Next()
+	})
+
+	if rootCmd != nil {
+		rootCmd.AddCommand(p.createCommand())
+	}
+
+	return nil
+}
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+// findMapping returns the mapping of the provided collection (or nil if missing).
+func (p *plugin) findMapping(collection *core.Collection) *Mapping {
+	for i, m := range p.config.Mappings {
+		if m.Collection == collection.Name || m.Collection == collection.Id {
+			return &p.config.Mappings[i]
+		}
+	}
+
+	return nil
+}
+
+func (p *plugin) syncAsync(record *core.Record, isDelete bool) {
+	m := p.findMapping(record.Collection())
+	if m == nil {
+		return
+	}
+
+	// the record could be modified after the hook
+	record = record.Fresh()
+
+	routine.FireAndForget(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+		defer cancel()
+
+		var err error
+		if isDelete {
+			err = p.config.Engine.Delete(ctx, m.index(record.Collection()), []string{record.Id})
+		} else {
+			err = p.upsert(ctx, m, []*core.Record{record})
+		}
+
+		if err != nil {
+			p.app.Logger().Error(
+				"[searchsync] Failed to sync record",
+				"id", record.Id,
+				"collectionName", record.Collection().Name,
+				"delete", isDelete,
+				"error", err.Error(),
+			)
+		}
+	})
+}
+
+func (p *plugin) upsert(ctx context.Context, m *Mapping, records []*core.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	docs := make([]Document, 0, len(records))
+
+	for _, r := range records {
+		doc, err := m.document(r)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+
+	return p.config.Engine.Upsert(ctx, m.index(records[0].Collection()), docs)
+}
+
+func (m *Mapping) index(collection *core.Collection) string {
+	if m.Index != "" {
+		return m.Index
+	}
+
+	return collection.Name
+}
+
+// document converts the provided record into a search engine document.
+func (m *Mapping) document(record *core.Record) (Document, error) {
+	doc := Document{"id": record.Id}
+
+	if len(m.Fields) > 0 {
+		for _, name := range m.Fields {
+			doc[name] = record.Get(name)
+		}
+	} else {
+		for _, f := range record.Collection().Fields {
+			if f.GetHidden() {
+				continue
+			}
+			doc[f.GetName()] = record.Get(f.GetName())
+		}
+	}
+
+	if m.Transform != nil {
+		var err error
+		doc, err = m.Transform(record, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if doc.Id() != record.Id {
+			return nil, fmt.Errorf("the transformed %q document must have an id key with the record id", record.Id)
+		}
+	}
+
+	return doc, nil
+}
+
+// reindex sends all records of the specified mapped collection
+// to the search engine and returns the number of the synced records.
+//
+// Note that the documents of already deleted records are not removed.
+func (p *plugin) reindex(ctx context.Context, collectionNameOrId string) (int, error) {
+	collection, err := p.app.FindCollectionByNameOrId(collectionNameOrId)
+	if err != nil {
+		return 0, err
+	}
+
+	m := p.findMapping(collection)
+	if m == nil {
+		return 0, fmt.Errorf("collection %q is not mapped", collection.Name)
+	}
+
+	var total int
+	var lastId string
+
+	for {
+		records := []*core.Record{}
+
+		err := p.app.RecordQuery(collection).
+			AndWhere(dbx.NewExp("[[id]] > {:lastId}", dbx.Params{"lastId": lastId})).
+			OrderBy("id ASC").
+			Limit(int64(p.config.BatchSize)).
+			All(&records)
+		if err != nil {
+			return total, err
+		}
+
+		if len(records) == 0 {
+			return total, nil
+		}
+
+		batchCtx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+		err = p.upsert(batchCtx, m, records)
+		cancel()
+		if err != nil {
+			return total, err
+		}
+
+		total += len(records)
+		lastId = records[len(records)-1].Id
+
+		if len(records) < p.config.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+func (p *plugin) createCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:          "searchsync",
+		Short:        "Manages the search engine sync",
+		SilenceUsage: true,
+	}
+
+	command.AddCommand(&cobra.Command{
+		Use:          "reindex [collections...]",
+		Short:        "Sends all records of the specified (or all mapped) collections to the search engine",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				for _, m := range p.config.Mappings {
+					args = append(args, m.Collection)
+				}
+			}
+
+			for _, name := range args {
+				total, err := p.reindex(cmd.Context(), name)
+				if err != nil {
+					return fmt.Errorf("failed to reindex %q: %w", name, err)
+				}
+
+				cmd.Printf("Reindexed %d %q records\n", total, name)
+			}
+
+			return nil
+		},
+	})
+
+	return command
+}
+
+func (p *plugin) search(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	m := p.findMapping(collection)
+	if m == nil {
+		return e.NotFoundError("The collection is not searchable.", nil)
+	}
+
+	info, err := e.RequestInfo()
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	if collection.ListRule == nil && !info.HasSuperuserAuth() {
+		return e.ForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	query := Query{
+		Text:    e.Request.URL.Query().Get("q"),
+		Page:    max(cast.ToInt(e.Request.URL.Query().Get("page")), 1),
+		PerPage: cast.ToInt(e.Request.URL.Query().Get("perPage")),
+	}
+	if query.PerPage <= 0 {
+		query.PerPage = DefaultPerPage
+	}
+	query.PerPage = min(query.PerPage, MaxPerPage)
+
+	ctx, cancel := context.WithTimeout(e.Request.Context(), p.config.Timeout)
+	defer cancel()
+
+	ids, err := p.config.Engine.Search(ctx, m.index(collection), query)
+	if err != nil {
+		return e.BadRequestError("Failed to query the search engine.", err)
+	}
+
+	records := []*core.Record{}
+
+	if len(ids) > 0 {
+		selectQuery := e.App.RecordQuery(collection).
+			AndWhere(dbx.In(collection.Name+".id", list.ToInterfaceSlice(ids)...))
+
+		if collection.ListRule != nil && *collection.ListRule != "" && !info.HasSuperuserAuth() {
+			resolver := core.NewRecordFieldResolver(e.App, collection, info, false)
+
+			expr, err := search.FilterData(*collection.ListRule).BuildExpr(resolver)
+			if err != nil {
+				return e.BadRequestError("", err)
+			}
+			selectQuery.AndWhere(expr)
+
+			if err := resolver.UpdateQuery(selectQuery); err != nil {
+				return e.BadRequestError("", err)
+			}
+		}
+
+		if err := selectQuery.All(&records); err != nil {
+			return e.BadRequestError("", err)
+		}
+
+		// restore the search engine relevance order
+		slices.SortStableFunc(records, func(a, b *core.Record) int {
+			return slices.Index(ids, a.Id) - slices.Index(ids, b.Id)
+		})
+
+		if err := apis.EnrichRecords(e, records); err != nil {
+			return e.InternalServerError("Failed to enrich records", err)
+		}
+	}
+
+	// the total counts are not available because the
+	// engine results are additionally filtered by the ListRule
+	return e.JSON(http.StatusOK, &search.Result{
+		Items:      records,
+		Page:       query.Page,
+		PerPage:    query.PerPage,
+		TotalItems: -1,
+		TotalPages: -1,
+	})
+}
@@ -0,0 +1,390 @@
+// Package softdelete implements a per-collection soft delete mode.
+//
+// The records API DELETE requests of the configured collections don't
+// remove the record but only set its "deleted" date field.
+//
+// The soft deleted records are excluded for the non-superusers by
+// extending the collection List, View, Update and Delete API rules
+// (so they are hidden also from the count, aggregate, expand and realtime
+// results) and the soft deleted auth records can no longer authenticate.
+//
+// The records stay in the database until they are permanently purged,
+// so all relation integrity checks, cascade deletes and file cleanups
+// are deferred to the purge. New relations to soft deleted records
+// are rejected.
+//
+// Note that the soft delete is persisted as a regular record update,
+// meaning that it is broadcasted to the realtime subscribers as "update" event.
+//
+// The plugin registers the following superusers only endpoints:
+//
+//	POST   /api/softdelete/{collection}/{id}/restore
+//	DELETE /api/softdelete/{collection}/{id}          (permanent purge)
+//
+// Example usage:
+//
+//	softdelete.MustRegister(app, softdelete.Config{
+//		Collections: []string{"posts", "comments"},
+//		// optionally purge the soft deleted records after 30 days
+//		Retention: 30 * 24 * time.Hour,
+//	})
+package softdelete
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	DefaultField = "deleted"
+
+	DefaultMiddlewareId       = "pbSoftDelete"
+	DefaultMiddlewarePriority = apis.DefaultLoadAuthTokenMiddlewarePriority + 1
+
+	purgeCronId = "__pbSoftDeletePurge__"
+)
+
+// Config defines the config options of the softdelete plugin.
+type Config struct {
+	// Collections is a list with the names of the soft delete enabled collections.
+	Collections []string
+
+	// Field specifies the name of the date field that holds
+	// the record soft delete time (default to "deleted").
+	//
+	// The field is created automatically if missing.
+	Field string
+
+	// Retention is an optional duration after which the soft deleted
+	// records are permanently purged.
+	//
+	// If zero, the soft deleted records are kept until purged manually.
+	Retention time.Duration
+}
+
+// MustRegister registers the softdelete plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the softdelete plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if len(p.config.Collections) == 0 {
+		return errors.New("softdelete: at least one collection must be specified")
+	}
+
+	if p.config.Field == "" {
+		p.config.Field = DefaultField
+	}
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		// ensure the soft delete field after the migrations run
+		if err := p.ensureFields(); err != nil {
+			return err
+		}
+
+		e.Router.Bind(&hook.Handler[*core.RequestEvent]{
+			Id:       DefaultMiddlewareId,
+			Priority: DefaultMiddlewarePriority,
+			Func:     p.middleware,
+		})
+
+		sub := e.Router.Group("/api/softdelete/{collection}").Bind(apis.RequireSuperuserAuth())
+		sub.POST("/{id}/restore", p.restore)
+		sub.DELETE("/{id}", p.purge)
+
+		return e.Next()
+	})
+
+	p.app.OnRecordDeleteRequest(p.config.Collections...).BindFunc(p.onDeleteRequest)
+	p.app.OnRecordUpdateRequest().BindFunc(p.onUpdateRequest)
+	p.app.OnRecordCreateRequest().BindFunc(p.onCreateRequest)
+	p.app.OnRecordAuthRequest(p.config.Collections...).BindFunc(p.onAuthRequest)
+
+	if err := core.AddCollectionRuleScope(p.app, p.ruleScope); err != nil {
+		return err
+	}
+
+	if p.config.Retention <= 0 {
+		return nil
+	}
+
+	return p.app.Cron().Add(purgeCronId, "30 * * * *", func() {
+		if err := p.purgeExpired(); err != nil {
+			p.app.Logger().Error("Failed to purge the expired soft deleted records", "error", err.Error())
+		}
+	})
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) ensureFields() error {
+	for _, name := range p.config.Collections {
+		collection, err := p.app.FindCollectionByNameOrId(name)
+		if err != nil {
+			p.app.Logger().Warn("Missing soft delete collection", "collection", name)
+			continue
+		}
+
+		if collection.IsView() {
+			return errors.New("softdelete: view collection " + collection.Name + " cannot be soft delete enabled")
+		}
+
+		if collection.Fields.GetByName(p.config.Field) != nil {
+			continue // already created
+		}
+
+		collection.Fields.Add(&core.DateField{Name: p.config.Field})
+		collection.AddIndex("idx_"+p.config.Field+"_"+collection.Id, false, "`"+p.config.Field+"`", "")
+
+		if err := p.app.Save(collection); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *plugin) findScoped(collectionNameOrId string) *core.Collection {
+	if collectionNameOrId == "" {
+		return nil
+	}
+
+	collection, err := p.app.FindCachedCollectionByNameOrId(collectionNameOrId)
+	if err != nil {
+		return nil
+	}
+
+	if !list.ExistInSlice(collection.Name, p.config.Collections) &&
+		!list.ExistInSlice(collection.Id, p.config.Collections) {
+		return nil
+	}
+
+	return collection
+}
+
+func (p *plugin) isDeleted(record *core.Record) bool {
+	return p.findScoped(record.Collection().Id) != nil && !record.GetDateTime(p.config.Field).IsZero()
+}
+
+func (p *plugin) middleware(e *core.RequestEvent) error {
+	// the soft deleted auth records are treated as guests
+	if e.Auth != nil && p.isDeleted(e.Auth) {
+		e.Auth = nil
+	}
+
+	return e.Next()
+}
+
+// ruleScope excludes the soft deleted records from the results of the
+// enabled collections API rules.
+//
+// Extending the rules instead of handling the individual endpoints ensures
+// that the soft deleted records are excluded also from the records count,
+// aggregate and expand results, the realtime events, the batch requests, etc.
+func (p *plugin) ruleScope(collection *core.Collection) string {
+	if p.findScoped(collection.Id) == nil || collection.Fields.GetByName(p.config.Field) == nil {
+		return ""
+	}
+
+	return p.config.Field + ` = ""`
+}
+
+func (p *plugin) onDeleteRequest(e *core.RecordRequestEvent) error {
+	// the soft deleted records could be removed only with the purge endpoint
+	if p.isDeleted(e.Record) {
+		return e.NotFoundError("", nil)
+	}
+
+	e.Record.Set(p.config.Field, types.NowDateTime())
+
+	// the record was already validated on its last save
+	if err := e.App.SaveNoValidateWithContext(e.Request.Context(), e.Record); err != nil {
+		return e.BadRequestError("Failed to delete record.", err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+func (p *plugin) onCreateRequest(e *core.RecordRequestEvent) error {
+	if !e.HasSuperuserAuth() && p.findScoped(e.Collection.Id) != nil {
+		e.Record.Set(p.config.Field, "")
+	}
+
+	if err := p.checkRelations(e.Record); err != nil {
+		return e.BadRequestError("Failed to create record.", err)
+	}
+
+	return e.Next()
+}
+
+func (p *plugin) onUpdateRequest(e *core.RecordRequestEvent) error {
+	if !e.HasSuperuserAuth() && p.findScoped(e.Collection.Id) != nil {
+		original := e.Record.Original()
+		if p.isDeleted(original) {
+			return e.NotFoundError("", nil)
+		}
+
+		// prevent soft deleting the record with a regular update
+		e.Record.Set(p.config.Field, original.Get(p.config.Field))
+	}
+
+	if err := p.checkRelations(e.Record); err != nil {
+		return e.BadRequestError("Failed to update record.", err)
+	}
+
+	return e.Next()
+}
+
+func (p *plugin) onAuthRequest(e *core.RecordAuthRequestEvent) error {
+	if p.isDeleted(e.Record) {
+		return e.BadRequestError("Failed to authenticate.", nil)
+	}
+
+	return e.Next()
+}
+
+// checkRelations ensures that the record doesn't link to new soft deleted records.
+func (p *plugin) checkRelations(record *core.Record) error {
+	var original *core.Record
+	if !record.IsNew() {
+		original = record.Original()
+	}
+
+	errs := validation.Errors{}
+
+	for _, field := range record.Collection().Fields {
+		relField, ok := field.(*core.RelationField)
+		if !ok {
+			continue
+		}
+
+		relCollection := p.findScoped(relField.CollectionId)
+		if relCollection == nil {
+			continue
+		}
+
+		ids := record.GetStringSlice(relField.Name)
+		if original != nil {
+			ids = list.SubtractSlice(ids, original.GetStringSlice(relField.Name))
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		var total int
+		err := p.app.DB().
+			Select("count(*)").
+			From(relCollection.Name).
+			AndWhere(dbx.In("id", list.ToInterfaceSlice(ids)...)).
+			AndWhere(dbx.NewExp("[[" + p.config.Field + "]] != ''")).
+			Row(&total)
+		if err != nil {
+			return err
+		}
+
+		if total > 0 {
+			errs[relField.Name] = validation.NewError("validation_missing_rel_records", "Failed to find all relation records with the provided ids")
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (p *plugin) findDeleted(e *core.RequestEvent) (*core.Record, error) {
+	collection := p.findScoped(e.Request.PathValue("collection"))
+	if collection == nil {
+		return nil, errors.New("missing or not soft delete enabled collection")
+	}
+
+	return e.App.FindFirstRecordByFilter(
+		collection,
+		"id = {:id} && "+p.config.Field+" != ''",
+		dbx.Params{"id": e.Request.PathValue("id")},
+	)
+}
+
+func (p *plugin) restore(e *core.RequestEvent) error {
+	record, err := p.findDeleted(e)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	record.Set(p.config.Field, "")
+
+	if err := e.App.SaveNoValidateWithContext(e.Request.Context(), record); err != nil {
+		return e.BadRequestError("Failed to restore record.", err)
+	}
+
+	if err := apis.EnrichRecord(e, record); err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	return e.JSON(http.StatusOK, record)
+}
+
+func (p *plugin) purge(e *core.RequestEvent) error {
+	record, err := p.findDeleted(e)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	if err := e.App.DeleteWithContext(e.Request.Context(), record); err != nil {
+		return e.BadRequestError("Failed to delete record. Make sure that the record is not part of a required relation reference.", err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+func (p *plugin) purgeExpired() error {
+	date := time.Now().Add(-p.config.Retention).UTC().Format(types.DefaultDateLayout)
+
+	var errs []error
+
+	for _, name := range p.config.Collections {
+		collection := p.findScoped(name)
+		if collection == nil {
+			continue
+		}
+
+		records, err := p.app.FindAllRecords(
+			collection,
+			dbx.NewExp("[["+p.config.Field+"]] != '' AND [["+p.config.Field+"]] < {:date}", dbx.Params{"date": date}),
+		)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, record := range records {
+			if err := p.app.Delete(record); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
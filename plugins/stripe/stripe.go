@@ -0,0 +1,569 @@
+// Package stripe implements a Stripe payments integration with
+// checkout session creation, signed webhook ingestion and syncing of the
+// subscription/payment status into the "_stripeSubscriptions" and
+// "_stripePayments" system collections.
+//
+// The checkout sessions are created by the authenticated users with
+// "POST /api/stripe/checkout" for one of the configured prices and the
+// Stripe webhook events should be sent to "POST /api/stripe/webhook".
+//
+// Each auth record could list its own subscriptions and payments
+// with the regular records API.
+//
+// Example usage:
+//
+//	p := stripe.MustRegister(app, stripe.Config{
+//		SecretKey:     os.Getenv("STRIPE_SECRET_KEY"),
+//		WebhookSecret: os.Getenv("STRIPE_WEBHOOK_SECRET"),
+//		SuccessURL:    "https://example.com/checkout/success?session={CHECKOUT_SESSION_ID}",
+//		CancelURL:     "https://example.com/checkout/cancel",
+//		Prices: map[string]string{
+//			"price_pro_monthly": stripe.ModeSubscription,
+//			"price_ebook":       stripe.ModePayment,
+//		},
+//		CustomerField: "stripeCustomer",
+//	})
+//
+//	hook.NewTaggedHook(p.OnWebhookEvent, "invoice.payment_failed").BindFunc(func(e *stripe.WebhookEvent) error {
+//		// notify the customer...
+//
+//		return e.Next()
+//	})
+package stripe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/httpclient"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	CollectionNameSubscriptions = "_stripeSubscriptions"
+	CollectionNamePayments      = "_stripePayments"
+)
+
+// Checkout session modes.
+const (
+	ModePayment      = "payment"
+	ModeSubscription = "subscription"
+)
+
+// Metadata keys assigned to the Stripe checkout sessions, subscriptions
+// and payment intents to associate them with the auth record.
+const (
+	MetadataCollection = "pb_collection"
+	MetadataRecord     = "pb_record"
+)
+
+const (
+	DefaultAPIURL = "https://api.stripe.com"
+
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultMaxPayloadSize is the default max webhook request body size.
+	DefaultMaxPayloadSize int64 = 1 << 20
+
+	// DefaultTolerance is the default max allowed age of a webhook signature timestamp.
+	DefaultTolerance = 5 * time.Minute
+)
+
+// Config defines the config options of the stripe plugin.
+type Config struct {
+	// SecretKey is the Stripe API secret key (e.g. "sk_live_...").
+	SecretKey string
+
+	// WebhookSecret is the Stripe webhook endpoint signing secret (e.g. "whsec_...").
+	WebhookSecret string
+
+	// SuccessURL is the url where the customer will be redirected
+	// after a successful checkout.
+	//
+	// It could contain the Stripe "{CHECKOUT_SESSION_ID}" placeholder.
+	SuccessURL string
+
+	// CancelURL is the url where the customer will be redirected
+	// if the checkout is canceled.
+	CancelURL string
+
+	// Prices are the Stripe price ids allowed for checkout and
+	// their checkout mode ([ModePayment] or [ModeSubscription]).
+	Prices map[string]string
+
+	// AuthCollections is an optional list of auth collections whose
+	// records are allowed to checkout (default to all auth collections).
+	AuthCollections []string
+
+	// CustomerField is an optional auth record text field name that
+	// stores the Stripe customer id.
+	//
+	// If set, the customer id is assigned to the auth record after the
+	// first completed checkout and it is reused for the next ones.
+	CustomerField string
+
+	// Client is an optional HTTP client for the Stripe API calls
+	// (default to [http.Client] with [DefaultTimeout]).
+	//
+	// In tests it could be replaced with [httpclient.Mock].
+	Client httpclient.Client
+
+	// APIURL is the Stripe API base url (default to [DefaultAPIURL]).
+	APIURL string
+
+	// MaxPayloadSize specifies the max allowed webhook request body size
+	// (default to [DefaultMaxPayloadSize]).
+	MaxPayloadSize int64
+
+	// Tolerance is the max allowed age of a webhook signature timestamp
+	// (default to [DefaultTolerance]).
+	Tolerance time.Duration
+}
+
+// Validate makes Config validatable by implementing [validation.Validatable] interface.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.SecretKey, validation.Required),
+		validation.Field(&c.WebhookSecret, validation.Required),
+		validation.Field(&c.SuccessURL, validation.Required),
+		validation.Field(&c.CancelURL, validation.Required),
+		validation.Field(&c.Prices, validation.Required, validation.By(checkPrices)),
+	)
+}
+
+func checkPrices(value any) error {
+	prices, _ := value.(map[string]string)
+
+	for price, mode := range prices {
+		if mode != ModePayment && mode != ModeSubscription {
+			return validation.NewError("validation_invalid_price_mode", fmt.Sprintf("Invalid mode %q for price %q.", mode, price))
+		}
+	}
+
+	return nil
+}
+
+// Plugin is a registered stripe plugin instance that exposes the plugin event hooks.
+type Plugin struct {
+	// OnCheckoutRequest hook is triggered on each Stripe checkout
+	// session create API request.
+	//
+	// Could be used to additionally validate the request or to modify
+	// the Stripe checkout session [CheckoutRequestEvent.Params]
+	// (e.g. to allow promotion codes).
+	//
+	// The event is tagged with the auth collection id and name
+	// (see [hook.NewTaggedHook]).
+	OnCheckoutRequest *hook.Hook[*CheckoutRequestEvent]
+
+	// OnWebhookEvent hook is triggered on each verified Stripe webhook
+	// event, right before syncing the subscription/payment status.
+	//
+	// Skip the e.Next() call to prevent the default sync.
+	//
+	// The event is tagged with the Stripe event type, e.g. "invoice.paid"
+	// (see [hook.NewTaggedHook]).
+	OnWebhookEvent *hook.Hook[*WebhookEvent]
+}
+
+// CheckoutRequestEvent defines the data of a single Stripe checkout session create request.
+type CheckoutRequestEvent struct {
+	hook.Event
+	*core.RequestEvent
+
+	// Collection is the auth collection of the record that initiated the checkout.
+	Collection *core.Collection
+
+	// Record is the auth record that initiated the checkout.
+	Record *core.Record
+
+	// Price is the requested Stripe price id.
+	Price string
+
+	// Mode is the checkout session mode ("payment" or "subscription").
+	Mode string
+
+	// Quantity is the requested price quantity.
+	Quantity int
+
+	// Params are the form encoded Stripe checkout session create parameters.
+	Params url.Values
+}
+
+// Tags implements [hook.Tagger] interface.
+func (e *CheckoutRequestEvent) Tags() []string {
+	if e.Collection == nil {
+		return nil
+	}
+
+	return []string{e.Collection.Id, e.Collection.Name}
+}
+
+// WebhookEvent defines the data of a single verified Stripe webhook event.
+type WebhookEvent struct {
+	hook.Event
+	*core.RequestEvent
+
+	// Id is the Stripe event id (e.g. "evt_...").
+	Id string
+
+	// Type is the Stripe event type (e.g. "checkout.session.completed").
+	Type string
+
+	// Object is the decoded Stripe event data object.
+	Object map[string]any
+
+	// Payload is the raw body of the verified webhook request.
+	Payload []byte
+}
+
+// Tags implements [hook.Tagger] interface.
+func (e *WebhookEvent) Tags() []string {
+	if e.Type == "" {
+		return nil
+	}
+
+	return []string{e.Type}
+}
+
+// MustRegister registers the stripe plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) *Plugin {
+	p, err := Register(app, config)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// Register registers the stripe plugin to the provided app instance.
+func Register(app core.App, config Config) (*Plugin, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	p := &plugin{
+		Plugin: &Plugin{
+			OnCheckoutRequest: &hook.Hook[*CheckoutRequestEvent]{},
+			OnWebhookEvent:    &hook.Hook[*WebhookEvent]{},
+		},
+		app:    app,
+		config: config,
+	}
+
+	if p.config.Client == nil {
+		p.config.Client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	if p.config.APIURL == "" {
+		p.config.APIURL = DefaultAPIURL
+	}
+	p.config.APIURL = strings.TrimRight(p.config.APIURL, "/")
+
+	if p.config.MaxPayloadSize <= 0 {
+		p.config.MaxPayloadSize = DefaultMaxPayloadSize
+	}
+
+	if p.config.Tolerance <= 0 {
+		p.config.Tolerance = DefaultTolerance
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollections(); err != nil {
+			return nil, err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollections()
+		})
+	}
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group("/api/stripe")
+
+		sub.POST("/checkout", p.checkout).Bind(apis.RequireAuth(p.config.AuthCollections...))
+
+		sub.POST("/webhook", p.webhook).
+			Unbind(apis.DefaultBodyLimitMiddlewareId).
+			Bind(apis.BodyLimit(p.config.MaxPayloadSize))
+
+		return e.Next()
+	})
+
+	return p.Plugin, nil
+}
+
+type plugin struct {
+	*Plugin
+
+	app    core.App
+	config Config
+}
+
+// ensureCollections creates the plugin system collections (if missing).
+func (p *plugin) ensureCollections() error {
+	// the auth record could list and view only its own subscriptions and payments
+	ownerRule := types.Pointer("@request.auth.id != '' && collectionRef = @request.auth.collectionId && recordRef = @request.auth.id")
+
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameSubscriptions); err != nil {
+		subscriptions := core.NewBaseCollection(CollectionNameSubscriptions)
+		subscriptions.System = true
+		subscriptions.ListRule = ownerRule
+		subscriptions.ViewRule = ownerRule
+		addOwnerFields(subscriptions)
+		subscriptions.Fields.Add(&core.TextField{
+			Name:     "subscriptionId",
+			System:   true,
+			Required: true,
+		})
+		subscriptions.Fields.Add(&core.TextField{
+			Name:   "customerId",
+			System: true,
+		})
+		subscriptions.Fields.Add(&core.TextField{
+			Name:   "priceId",
+			System: true,
+		})
+		subscriptions.Fields.Add(&core.TextField{
+			Name:   "status",
+			System: true,
+		})
+		subscriptions.Fields.Add(&core.DateField{
+			Name:   "currentPeriodEnd",
+			System: true,
+		})
+		subscriptions.Fields.Add(&core.BoolField{
+			Name:   "cancelAtPeriodEnd",
+			System: true,
+		})
+		addAutodateFields(subscriptions)
+		subscriptions.AddIndex("idx_stripeSubscriptions_subscriptionId", true, "subscriptionId", "")
+		subscriptions.AddIndex("idx_stripeSubscriptions_owner", false, "collectionRef,recordRef", "")
+
+		if err := p.app.Save(subscriptions); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNamePayments); err != nil {
+		payments := core.NewBaseCollection(CollectionNamePayments)
+		payments.System = true
+		payments.ListRule = ownerRule
+		payments.ViewRule = ownerRule
+		addOwnerFields(payments)
+		payments.Fields.Add(&core.TextField{
+			Name:     "sessionId",
+			System:   true,
+			Required: true,
+		})
+		payments.Fields.Add(&core.TextField{
+			Name:   "customerId",
+			System: true,
+		})
+		payments.Fields.Add(&core.TextField{
+			Name:   "paymentIntentId",
+			System: true,
+		})
+		payments.Fields.Add(&core.TextField{
+			Name:   "status",
+			System: true,
+		})
+		payments.Fields.Add(&core.NumberField{
+			Name:    "amount",
+			System:  true,
+			OnlyInt: true,
+		})
+		payments.Fields.Add(&core.TextField{
+			Name:   "currency",
+			System: true,
+		})
+		addAutodateFields(payments)
+		payments.AddIndex("idx_stripePayments_sessionId", true, "sessionId", "")
+		payments.AddIndex("idx_stripePayments_owner", false, "collectionRef,recordRef", "")
+
+		if err := p.app.Save(payments); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addOwnerFields(collection *core.Collection) {
+	collection.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	collection.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+}
+
+func addAutodateFields(collection *core.Collection) {
+	collection.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+}
+
+// -------------------------------------------------------------------
+
+type checkoutForm struct {
+	Price    string `form:"price" json:"price"`
+	Quantity int    `form:"quantity" json:"quantity"`
+}
+
+func (p *plugin) checkout(e *core.RequestEvent) error {
+	form := new(checkoutForm)
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	if form.Quantity == 0 {
+		form.Quantity = 1
+	}
+
+	err := validation.ValidateStruct(form,
+		validation.Field(&form.Price, validation.Required, validation.By(func(value any) error {
+			if _, ok := p.config.Prices[form.Price]; !ok {
+				return validation.NewError("validation_invalid_price", "Invalid or unsupported price.")
+			}
+			return nil
+		})),
+		validation.Field(&form.Quantity, validation.Min(1), validation.Max(100)),
+	)
+	if err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	mode := p.config.Prices[form.Price]
+	collectionId := e.Auth.Collection().Id
+
+	params := url.Values{}
+	params.Set("mode", mode)
+	params.Set("success_url", p.config.SuccessURL)
+	params.Set("cancel_url", p.config.CancelURL)
+	params.Set("line_items[0][price]", form.Price)
+	params.Set("line_items[0][quantity]", strconv.Itoa(form.Quantity))
+	params.Set("client_reference_id", e.Auth.Id)
+	params.Set("metadata["+MetadataCollection+"]", collectionId)
+	params.Set("metadata["+MetadataRecord+"]", e.Auth.Id)
+
+	// propagate the record association to the created subscription/payment intent
+	// so that the status changes could be synced without the checkout session
+	if mode == ModeSubscription {
+		params.Set("subscription_data[metadata]["+MetadataCollection+"]", collectionId)
+		params.Set("subscription_data[metadata]["+MetadataRecord+"]", e.Auth.Id)
+	} else {
+		params.Set("payment_intent_data[metadata]["+MetadataCollection+"]", collectionId)
+		params.Set("payment_intent_data[metadata]["+MetadataRecord+"]", e.Auth.Id)
+	}
+
+	if customerId := p.customerId(e.Auth); customerId != "" {
+		params.Set("customer", customerId)
+	} else if email := e.Auth.Email(); email != "" {
+		params.Set("customer_email", email)
+	}
+
+	event := new(CheckoutRequestEvent)
+	event.RequestEvent = e
+	event.Collection = e.Auth.Collection()
+	event.Record = e.Auth
+	event.Price = form.Price
+	event.Mode = mode
+	event.Quantity = form.Quantity
+	event.Params = params
+
+	return p.OnCheckoutRequest.Trigger(event, func(e *CheckoutRequestEvent) error {
+		session := struct {
+			Id  string `json:"id"`
+			URL string `json:"url"`
+		}{}
+
+		err := p.call(e.Request.Context(), "/v1/checkout/sessions", e.Params, &session)
+		if err != nil {
+			return e.InternalServerError("Failed to create Stripe checkout session.", err)
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"id":  session.Id,
+			"url": session.URL,
+		})
+	})
+}
+
+func (p *plugin) customerId(record *core.Record) string {
+	if p.config.CustomerField == "" {
+		return ""
+	}
+
+	return record.GetString(p.config.CustomerField)
+}
+
+// call sends a form encoded POST request to the Stripe API
+// and decodes its JSON response into result.
+func (p *plugin) call(ctx context.Context, path string, params url.Values, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.APIURL+path, bytes.NewBufferString(params.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+p.config.SecretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := p.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		apiErr := struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}{}
+		_ = json.Unmarshal(body, &apiErr)
+
+		if apiErr.Error.Message != "" {
+			return fmt.Errorf("stripe: %d %s", res.StatusCode, apiErr.Error.Message)
+		}
+
+		return errors.New("stripe: unexpected response status " + strconv.Itoa(res.StatusCode))
+	}
+
+	return json.Unmarshal(body, result)
+}
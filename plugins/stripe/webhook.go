@@ -0,0 +1,289 @@
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+// Sign returns the "Stripe-Signature" header value of the payload
+// for the specified timestamp (usually used for test purposes).
+func Sign(payload []byte, secret string, timestamp time.Time) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+
+	return "t=" + t + ",v1=" + hmacSHA256(append([]byte(t+"."), payload...), secret)
+}
+
+// VerifySignature verifies the "Stripe-Signature" header of the payload.
+func VerifySignature(header string, payload []byte, secret string, tolerance time.Duration) error {
+	var timestamp string
+	var signatures []string
+
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			timestamp = v
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return errors.New("missing or invalid Stripe-Signature header")
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	if time.Since(time.Unix(unix, 0)).Abs() > tolerance {
+		return errors.New("the Stripe-Signature timestamp is outside of the tolerance zone")
+	}
+
+	expected := hmacSHA256(append([]byte(timestamp+"."), payload...), secret)
+
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(expected), []byte(signature)) {
+			return nil
+		}
+	}
+
+	return errors.New("no matching Stripe signature")
+}
+
+func hmacSHA256(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// -------------------------------------------------------------------
+
+func (p *plugin) webhook(e *core.RequestEvent) error {
+	payload, err := io.ReadAll(e.Request.Body)
+	if err != nil {
+		return e.BadRequestError("Failed to read the webhook payload.", err)
+	}
+
+	err = VerifySignature(e.Request.Header.Get("Stripe-Signature"), payload, p.config.WebhookSecret, p.config.Tolerance)
+	if err != nil {
+		e.App.Logger().Debug("Invalid Stripe webhook signature", slog.String("error", err.Error()))
+		return e.UnauthorizedError("Invalid webhook signature.", nil)
+	}
+
+	data := struct {
+		Id   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object map[string]any `json:"object"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(payload, &data); err != nil || data.Type == "" {
+		return e.BadRequestError("Invalid Stripe event payload.", err)
+	}
+
+	event := new(WebhookEvent)
+	event.RequestEvent = e
+	event.Id = data.Id
+	event.Type = data.Type
+	event.Object = data.Data.Object
+	event.Payload = payload
+
+	return p.OnWebhookEvent.Trigger(event, func(e *WebhookEvent) error {
+		if err := p.sync(e.App, e.Type, e.Object); err != nil {
+			// respond with 500 so that Stripe could retry the event delivery later
+			return e.InternalServerError("Failed to process the Stripe event.", err)
+		}
+
+		return e.NoContent(http.StatusNoContent)
+	})
+}
+
+// sync syncs the subscription/payment status from the
+// Stripe event data object (unsupported event types are ignored).
+func (p *plugin) sync(app core.App, eventType string, object map[string]any) error {
+	switch eventType {
+	case "checkout.session.completed",
+		"checkout.session.async_payment_succeeded",
+		"checkout.session.async_payment_failed",
+		"checkout.session.expired":
+		return p.syncCheckoutSession(app, eventType, object)
+	case "customer.subscription.created",
+		"customer.subscription.updated",
+		"customer.subscription.deleted",
+		"customer.subscription.paused",
+		"customer.subscription.resumed":
+		return p.syncSubscription(app, object)
+	}
+
+	return nil
+}
+
+func (p *plugin) syncCheckoutSession(app core.App, eventType string, session map[string]any) error {
+	owner, err := findOwner(app, session)
+	if err != nil {
+		return err
+	}
+	if owner == nil {
+		app.Logger().Debug("Skipping Stripe checkout session without auth record metadata", slog.Any("id", session["id"]))
+		return nil
+	}
+
+	customerId := objectId(session["customer"])
+
+	// remember the customer id for the next checkouts
+	if p.config.CustomerField != "" && customerId != "" && owner.GetString(p.config.CustomerField) == "" {
+		owner.Set(p.config.CustomerField, customerId)
+		if err := app.Save(owner); err != nil {
+			return err
+		}
+	}
+
+	if cast.ToString(session["mode"]) != ModePayment {
+		return nil // the subscription status is synced with the customer.subscription.* events
+	}
+
+	var status string
+	switch eventType {
+	case "checkout.session.expired":
+		status = "expired"
+	case "checkout.session.async_payment_failed":
+		status = "failed"
+	default:
+		status = cast.ToString(session["payment_status"])
+	}
+
+	record, err := findOrNew(app, CollectionNamePayments, "sessionId", cast.ToString(session["id"]))
+	if err != nil {
+		return err
+	}
+
+	record.Set("collectionRef", owner.Collection().Id)
+	record.Set("recordRef", owner.Id)
+	record.Set("customerId", customerId)
+	record.Set("paymentIntentId", objectId(session["payment_intent"]))
+	record.Set("status", status)
+	record.Set("amount", session["amount_total"])
+	record.Set("currency", session["currency"])
+
+	return app.Save(record)
+}
+
+func (p *plugin) syncSubscription(app core.App, subscription map[string]any) error {
+	record, err := findOrNew(app, CollectionNameSubscriptions, "subscriptionId", cast.ToString(subscription["id"]))
+	if err != nil {
+		return err
+	}
+
+	if record.IsNew() {
+		owner, err := findOwner(app, subscription)
+		if err != nil {
+			return err
+		}
+		if owner == nil {
+			app.Logger().Debug("Skipping Stripe subscription without auth record metadata", slog.Any("id", subscription["id"]))
+			return nil
+		}
+
+		record.Set("collectionRef", owner.Collection().Id)
+		record.Set("recordRef", owner.Id)
+	}
+
+	var priceId string
+	if items, ok := subscription["items"].(map[string]any); ok {
+		if list, _ := items["data"].([]any); len(list) > 0 {
+			if item, ok := list[0].(map[string]any); ok {
+				priceId = objectId(item["price"])
+			}
+		}
+	}
+
+	record.Set("customerId", objectId(subscription["customer"]))
+	record.Set("priceId", priceId)
+	record.Set("status", subscription["status"])
+	record.Set("cancelAtPeriodEnd", subscription["cancel_at_period_end"])
+
+	if periodEnd := cast.ToInt64(subscription["current_period_end"]); periodEnd > 0 {
+		date, err := types.ParseDateTime(time.Unix(periodEnd, 0))
+		if err != nil {
+			return err
+		}
+		record.Set("currentPeriodEnd", date)
+	}
+
+	return app.Save(record)
+}
+
+// findOwner returns the auth record associated with the Stripe object
+// metadata or nil if the object doesn't have such metadata.
+func findOwner(app core.App, object map[string]any) (*core.Record, error) {
+	metadata, _ := object["metadata"].(map[string]any)
+
+	collection := cast.ToString(metadata[MetadataCollection])
+	recordId := cast.ToString(metadata[MetadataRecord])
+	if collection == "" || recordId == "" {
+		return nil, nil
+	}
+
+	record, err := app.FindRecordById(collection, recordId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // deleted
+		}
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func findOrNew(app core.App, collectionName string, field string, value string) (*core.Record, error) {
+	if value == "" {
+		return nil, errors.New("missing Stripe object id")
+	}
+
+	record, err := app.FindFirstRecordByFilter(collectionName, field+"={:value}", dbx.Params{"value": value})
+	if err == nil {
+		return record, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	collection, err := app.FindCachedCollectionByNameOrId(collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	record = core.NewRecord(collection)
+	record.Set(field, value)
+
+	return record, nil
+}
+
+// objectId returns the id of an expandable Stripe object field
+// (it could be either the object id or the expanded object).
+func objectId(value any) string {
+	if obj, ok := value.(map[string]any); ok {
+		return cast.ToString(obj["id"])
+	}
+
+	return cast.ToString(value)
+}
@@ -0,0 +1,649 @@
+// Package takeout implements a per-user data export (aka. GDPR takeout)
+// with all records owned by or referencing an auth record and their files.
+//
+// The export archive is generated asynchronously in the background and
+// is stored as a protected file of a "_takeouts" system collection record,
+// from where it could be downloaded until it expires.
+//
+// The plugin registers the following endpoints (all of them require the
+// auth record to be from the path collection):
+//
+//	POST /api/collections/{collection}/request-takeout
+//	GET  /api/collections/{collection}/takeouts/{id}
+//	GET  /api/collections/{collection}/takeouts/{id}/download
+//
+// Example usage:
+//
+//	takeout.MustRegister(app, takeout.Config{
+//		Mapping: map[string][]takeout.Source{
+//			"users": {
+//				{Collection: "posts", Field: "author"},
+//				{Collection: "comments", Field: "user"},
+//			},
+//		},
+//	})
+package takeout
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameTakeouts = "_takeouts"
+
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+const (
+	DefaultDuration = 7 * 24 * time.Hour
+	DefaultMaxSize  = 1 << 30 // 1GB
+
+	cleanupCronId = "__pbTakeoutsCleanup__"
+
+	// number of records to load at once while generating the archive
+	batchSize = 200
+)
+
+// Source defines a collection with records that belong to the exported auth record.
+type Source struct {
+	// Collection is the name or id of the source collection.
+	Collection string
+
+	// Field is the name of the source collection relation or text field
+	// that holds the exported auth record id.
+	Field string
+}
+
+// Event defines the data of a completed takeout.
+type Event struct {
+	App core.App
+
+	// Record is the exported auth record.
+	Record *core.Record
+
+	// Takeout is the related "_takeouts" collection record.
+	Takeout *core.Record
+}
+
+// Config defines the config options of the takeout plugin.
+type Config struct {
+	// Mapping is a map with the sources of each auth collection that
+	// supports takeouts, where the key is the auth collection name or id.
+	//
+	// The exported auth record itself is always included in the archive.
+	Mapping map[string][]Source
+
+	// Duration specifies for how long the generated archive is available
+	// for download (default to 7 days).
+	Duration time.Duration
+
+	// MaxSize specifies the max allowed archive size in bytes (default to 1GB).
+	MaxSize int64
+
+	// OnComplete is an optional callback that is invoked after the
+	// archive generation (e.g. to notify the user via email).
+	//
+	// Check the Takeout record "status" field to determine whether
+	// the generation was successful.
+	OnComplete func(e *Event) error
+}
+
+// MustRegister registers the takeout plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the takeout plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if len(p.config.Mapping) == 0 {
+		return errors.New("takeout: at least one auth collection mapping must be specified")
+	}
+
+	for authCollection, sources := range p.config.Mapping {
+		for i, s := range sources {
+			if s.Collection == "" || s.Field == "" {
+				return fmt.Errorf("takeout: %q source %d must have Collection and Field", authCollection, i)
+			}
+		}
+	}
+
+	if p.config.Duration <= 0 {
+		p.config.Duration = DefaultDuration
+	}
+
+	if p.config.MaxSize <= 0 {
+		p.config.MaxSize = DefaultMaxSize
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollection(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollection()
+		})
+	}
+
+	// delete the takeouts of the deleted auth records
+	p.app.OnRecordDeleteExecute(p.authCollections()...).Bind(&hook.Handler[*core.RecordEvent]{
+		Func: func(e *core.RecordEvent) error {
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp core.App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				return deleteTakeouts(txApp, dbx.HashExp{
+					"collectionRef": e.Record.Collection().Id,
+					"recordRef":     e.Record.Id,
+				})
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group("/api/collections/{collection}").Bind(apis.RequireSameCollectionContextAuth(""))
+		sub.POST("/request-takeout", p.requestTakeout)
+		sub.GET("/takeouts/{id}", p.viewTakeout)
+		sub.GET("/takeouts/{id}/download", p.downloadTakeout)
+
+		return e.Next()
+	})
+
+	return p.app.Cron().Add(cleanupCronId, "15 * * * *", func() {
+		err := deleteTakeouts(p.app, dbx.NewExp("[[created]] < {:date}", dbx.Params{
+			"date": time.Now().Add(-p.config.Duration).UTC().Format(types.DefaultDateLayout),
+		}))
+		if err != nil {
+			p.app.Logger().Error("Failed to delete expired takeouts", "error", err.Error())
+		}
+	})
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) authCollections() []string {
+	names := make([]string, 0, len(p.config.Mapping))
+	for name := range p.config.Mapping {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func (p *plugin) ensureCollection() error {
+	col, err := p.app.FindCollectionByNameOrId(CollectionNameTakeouts)
+	if err != nil {
+		col = core.NewBaseCollection(CollectionNameTakeouts)
+		col.System = true
+		col.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "recordRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.SelectField{
+			Name:      "status",
+			System:    true,
+			Required:  true,
+			MaxSelect: 1,
+			Values:    []string{StatusPending, StatusCompleted, StatusFailed},
+		})
+		col.Fields.Add(&core.TextField{
+			Name:   "error",
+			System: true,
+		})
+		col.Fields.Add(&core.FileField{
+			Name:      "archive",
+			System:    true,
+			Protected: true,
+			MaxSelect: 1,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		col.AddIndex("idx_takeouts_record", false, "collectionRef, recordRef", "")
+	}
+
+	// sync the max archive size
+	if archive, ok := col.Fields.GetByName("archive").(*core.FileField); ok {
+		archive.MaxSize = p.config.MaxSize
+	}
+
+	if err := p.app.Save(col); err != nil {
+		return err
+	}
+
+	// the previous process was terminated before completing the pending takeouts
+	pending, err := p.app.FindAllRecords(CollectionNameTakeouts, dbx.HashExp{"status": StatusPending})
+	if err != nil {
+		return err
+	}
+	for _, t := range pending {
+		t.Set("status", StatusFailed)
+		t.Set("error", "The takeout generation was interrupted.")
+		if err := p.app.Save(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkCollection checks whether the request path auth collection
+// is configured to support takeouts.
+func (p *plugin) checkCollection(e *core.RequestEvent) error {
+	if _, ok := p.sources(e.Auth.Collection()); !ok {
+		return e.NotFoundError("Missing or invalid auth collection context.", nil)
+	}
+
+	return nil
+}
+
+func (p *plugin) sources(collection *core.Collection) ([]Source, bool) {
+	if sources, ok := p.config.Mapping[collection.Name]; ok {
+		return sources, true
+	}
+
+	sources, ok := p.config.Mapping[collection.Id]
+
+	return sources, ok
+}
+
+func (p *plugin) requestTakeout(e *core.RequestEvent) error {
+	if err := p.checkCollection(e); err != nil {
+		return err
+	}
+
+	record := e.Auth
+
+	pending, _ := e.App.FindFirstRecordByFilter(
+		CollectionNameTakeouts,
+		"collectionRef = {:collectionRef} && recordRef = {:recordRef} && status = {:status}",
+		dbx.Params{"collectionRef": record.Collection().Id, "recordRef": record.Id, "status": StatusPending},
+	)
+	if pending != nil {
+		return e.BadRequestError("A takeout is already being generated.", nil)
+	}
+
+	takeoutsCollection, err := e.App.FindCachedCollectionByNameOrId(CollectionNameTakeouts)
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	takeout := core.NewRecord(takeoutsCollection)
+	takeout.Set("collectionRef", record.Collection().Id)
+	takeout.Set("recordRef", record.Id)
+	takeout.Set("status", StatusPending)
+
+	if err := e.App.Save(takeout); err != nil {
+		return e.BadRequestError("Failed to request takeout.", err)
+	}
+
+	authRecord := record.Fresh()
+	takeoutId := takeout.Id
+	routine.FireAndForget(func() {
+		p.generate(authRecord, takeoutId)
+	})
+
+	return e.JSON(http.StatusOK, takeout)
+}
+
+func (p *plugin) viewTakeout(e *core.RequestEvent) error {
+	if err := p.checkCollection(e); err != nil {
+		return err
+	}
+
+	takeout, err := findTakeout(e.App, e.Auth, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	return e.JSON(http.StatusOK, takeout)
+}
+
+func (p *plugin) downloadTakeout(e *core.RequestEvent) error {
+	if err := p.checkCollection(e); err != nil {
+		return err
+	}
+
+	takeout, err := findTakeout(e.App, e.Auth, e.Request.PathValue("id"))
+	if err != nil || takeout.GetString("archive") == "" {
+		return e.NotFoundError("", err)
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return e.InternalServerError("Failed to load the filesystem.", err)
+	}
+	defer fsys.Close()
+
+	fsys.SetContext(e.Request.Context())
+
+	fileKey := takeout.BaseFilesPath() + "/" + takeout.GetString("archive")
+
+	// disable the default file cache-control header
+	e.Response.Header().Set("Cache-Control", "no-store")
+
+	err = fsys.Serve(e.Response, e.Request, fileKey, "takeout_"+takeout.Id+".zip")
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	return nil
+}
+
+// generate creates the takeout archive of the provided auth record
+// and updates the takeout record with the result.
+func (p *plugin) generate(record *core.Record, takeoutId string) {
+	takeout, err := p.app.FindRecordById(CollectionNameTakeouts, takeoutId)
+	if err != nil {
+		p.app.Logger().Error("Failed to load takeout", "takeoutId", takeoutId, "error", err.Error())
+		return
+	}
+
+	genErr := p.createArchive(record, takeout)
+	if genErr != nil {
+		p.app.Logger().Error(
+			"Failed to generate takeout",
+			"takeoutId", takeout.Id,
+			"recordId", record.Id,
+			"error", genErr.Error(),
+		)
+
+		takeout.Set("status", StatusFailed)
+		takeout.Set("error", genErr.Error())
+		if err := p.app.Save(takeout); err != nil {
+			p.app.Logger().Error("Failed to update takeout", "takeoutId", takeout.Id, "error", err.Error())
+		}
+	}
+
+	if p.config.OnComplete != nil {
+		if err := p.config.OnComplete(&Event{App: p.app, Record: record, Takeout: takeout}); err != nil {
+			p.app.Logger().Error("Takeout OnComplete callback failure", "takeoutId", takeout.Id, "error", err.Error())
+		}
+	}
+}
+
+func (p *plugin) createArchive(record *core.Record, takeout *core.Record) error {
+	tmp, err := os.CreateTemp("", "pb_takeout_*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	fsys, err := p.app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	zw := zip.NewWriter(tmp)
+
+	w := &archiveWriter{zw: zw, fsys: fsys}
+
+	// the auth record itself
+	record.IgnoreEmailVisibility(true)
+	if err := w.writeJSON(record.Collection().Name+".json", []*core.Record{record}); err != nil {
+		return err
+	}
+	if err := w.writeFiles(record); err != nil {
+		return err
+	}
+
+	sources, _ := p.sources(record.Collection())
+	for _, s := range sources {
+		if err := p.exportSource(w, s, record); err != nil {
+			return fmt.Errorf("failed to export %q records: %w", s.Collection, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	file, err := filesystem.NewFileFromPath(tmp.Name())
+	if err != nil {
+		return err
+	}
+	file.OriginalName = "takeout.zip"
+	file.Name = "takeout_" + takeout.Id + ".zip"
+
+	takeout.Set("archive", file)
+	takeout.Set("status", StatusCompleted)
+
+	return p.app.Save(takeout)
+}
+
+func (p *plugin) exportSource(w *archiveWriter, s Source, record *core.Record) error {
+	collection, err := p.app.FindCachedCollectionByNameOrId(s.Collection)
+	if err != nil {
+		return err
+	}
+
+	if collection.IsView() {
+		return errors.New("view collections are not supported")
+	}
+
+	field := collection.Fields.GetByName(s.Field)
+	if field == nil {
+		return fmt.Errorf("missing field %q", s.Field)
+	}
+
+	filter := s.Field + " = {:takeoutRecordId}"
+	if _, ok := field.(core.MultiValuer); ok {
+		filter = s.Field + ":each ?= {:takeoutRecordId}"
+	}
+
+	eachRecord := func(fn func(r *core.Record) error) error {
+		for offset := 0; ; offset += batchSize {
+			records, err := p.app.FindRecordsByFilter(
+				collection,
+				filter,
+				"id",
+				batchSize,
+				offset,
+				dbx.Params{"takeoutRecordId": record.Id},
+			)
+			if err != nil {
+				return err
+			}
+
+			for _, r := range records {
+				if err := fn(r); err != nil {
+					return err
+				}
+			}
+
+			if len(records) < batchSize {
+				return nil
+			}
+		}
+	}
+
+	entry, err := w.zw.Create(collection.Name + ".json")
+	if err != nil {
+		return err
+	}
+
+	if _, err := entry.Write([]byte("[")); err != nil {
+		return err
+	}
+
+	var total int
+	err = eachRecord(func(r *core.Record) error {
+		raw, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+
+		if total > 0 {
+			raw = append([]byte(","), raw...)
+		}
+		total++
+
+		_, err = entry.Write(raw)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := entry.Write([]byte("]")); err != nil {
+		return err
+	}
+
+	// the zip entries are written sequentially so the files are
+	// copied in a second pass after the json entry is completed
+	if total == 0 || !hasFileField(collection) {
+		return nil
+	}
+
+	return eachRecord(w.writeFiles)
+}
+
+// -------------------------------------------------------------------
+
+type archiveWriter struct {
+	zw   *zip.Writer
+	fsys *filesystem.System
+}
+
+func (w *archiveWriter) writeJSON(name string, data any) error {
+	entry, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(entry).Encode(data)
+}
+
+// writeFiles copies all record files into the archive
+// as "files/{collectionName}/{recordId}/{filename}".
+func (w *archiveWriter) writeFiles(record *core.Record) error {
+	for _, field := range record.Collection().Fields {
+		if field.Type() != core.FieldTypeFile {
+			continue
+		}
+
+		for _, name := range record.GetStringSlice(field.GetName()) {
+			if err := w.copyFile(record.BaseFilesPath()+"/"+name, path.Join("files", record.Collection().Name, record.Id, name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *archiveWriter) copyFile(fileKey string, name string) error {
+	br, err := w.fsys.GetFile(fileKey)
+	if err != nil {
+		if errors.Is(err, filesystem.ErrNotFound) {
+			return nil // missing file
+		}
+		return err
+	}
+	defer br.Close()
+
+	entry, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(entry, br)
+
+	return err
+}
+
+// -------------------------------------------------------------------
+
+func hasFileField(collection *core.Collection) bool {
+	for _, f := range collection.Fields {
+		if f.Type() == core.FieldTypeFile {
+			return true
+		}
+	}
+
+	return false
+}
+
+func findTakeout(app core.App, record *core.Record, id string) (*core.Record, error) {
+	return app.FindFirstRecordByFilter(
+		CollectionNameTakeouts,
+		"id = {:id} && collectionRef = {:collectionRef} && recordRef = {:recordRef}",
+		dbx.Params{"id": id, "collectionRef": record.Collection().Id, "recordRef": record.Id},
+	)
+}
+
+func deleteTakeouts(app core.App, exp dbx.Expression) error {
+	takeouts, err := app.FindAllRecords(CollectionNameTakeouts, exp)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range takeouts {
+		if err := app.Delete(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,410 @@
+// Package tenancy implements a multi-tenant scoping helper for
+// collections that share a single PocketBase instance.
+//
+// The resolved tenant id is exposed to the collection API rules as the
+// "@request.headers.x_pb_tenant" macro and the records of the configured
+// collections are automatically scoped to the current tenant.
+//
+// The scoping is applied by extending the List, View, Update and Delete
+// API rules of the configured collections, meaning that similar to the
+// regular API rules it doesn't apply for the superusers.
+//
+// Example usage:
+//
+//	tenancy.MustRegister(app, tenancy.Config{
+//		Resolvers: []tenancy.Resolver{
+//			tenancy.FromSubdomain("example.com"),
+//			tenancy.FromAuthField("tenant"),
+//		},
+//		Collections: []string{"posts", "comments"},
+//	})
+package tenancy
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+const (
+	// HeaderName is the name of the internal request header that holds
+	// the resolved tenant id (accessible in the API rules as "@request.headers.x_pb_tenant").
+	//
+	// Any client submitted value of the header is always discarded.
+	HeaderName = "X-Pb-Tenant"
+
+	// RequestEventKeyTenant is the request event store key with the resolved tenant id.
+	RequestEventKeyTenant = "pbTenant"
+
+	DefaultMiddlewareId       = "pbTenancy"
+	DefaultMiddlewarePriority = apis.DefaultLoadAuthTokenMiddlewarePriority + 1
+)
+
+// Resolver defines a function that extracts the tenant id from a request.
+//
+// It should return an empty string if the tenant cannot be resolved.
+//
+// Resolved values that contain characters other than letters, digits,
+// "_", "-" and "." are ignored.
+type Resolver func(e *core.RequestEvent) string
+
+// Config defines the config options of the tenancy plugin.
+type Config struct {
+	// Resolvers is an ordered list of tenant resolvers.
+	//
+	// The first nonempty resolved value is used as tenant id.
+	Resolvers []Resolver
+
+	// Collections is a list with the names of the tenant scoped collections.
+	Collections []string
+
+	// Field specifies the name of the collections field that holds
+	// the record tenant id (default to "tenant").
+	Field string
+
+	// Required rejects the non-superuser requests to the tenant scoped
+	// collections that don't have a resolved tenant.
+	Required bool
+}
+
+// MustRegister registers the tenancy plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the tenancy plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if len(p.config.Resolvers) == 0 {
+		return errors.New("tenancy: at least one tenant resolver must be specified")
+	}
+
+	if len(p.config.Collections) == 0 {
+		return errors.New("tenancy: at least one tenant scoped collection must be specified")
+	}
+
+	if p.config.Field == "" {
+		p.config.Field = "tenant"
+	}
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		e.Router.Bind(&hook.Handler[*core.RequestEvent]{
+			Id:       DefaultMiddlewareId,
+			Priority: DefaultMiddlewarePriority,
+			Func:     p.middleware,
+		})
+
+		return e.Next()
+	})
+
+	p.app.OnRealtimeSubscribeRequest().BindFunc(p.onRealtimeSubscribe)
+
+	p.app.OnRecordViewRequest(p.config.Collections...).BindFunc(p.onRecordAccess)
+	p.app.OnRecordDeleteRequest(p.config.Collections...).BindFunc(p.onRecordAccess)
+	p.app.OnRecordUpdateRequest(p.config.Collections...).BindFunc(p.onRecordUpdate)
+	p.app.OnRecordCreateRequest(p.config.Collections...).BindFunc(p.onRecordCreate)
+
+	return core.AddCollectionRuleScope(p.app, p.ruleScope)
+}
+
+// TenantFromRequest returns the tenant id resolved for the provided request (if any).
+func TenantFromRequest(e *core.RequestEvent) string {
+	tenant, _ := e.Get(RequestEventKeyTenant).(string)
+	if tenant == "" {
+		// e.g. in case of a batch sub-request
+		tenant = e.Request.Header.Get(HeaderName)
+	}
+
+	return tenant
+}
+
+// FromHeader returns a tenant resolver that reads the tenant id from the specified request header.
+//
+// Note that the header value is client controlled and usually should be
+// used only when the app is accessible behind a trusted proxy.
+func FromHeader(name string) Resolver {
+	return func(e *core.RequestEvent) string {
+		return strings.TrimSpace(e.Request.Header.Get(name))
+	}
+}
+
+// FromSubdomain returns a tenant resolver that extracts the tenant id
+// from the first subdomain label of the request host, e.g.
+// "acme.example.com" resolves to "acme" for baseDomain "example.com".
+func FromSubdomain(baseDomain string) Resolver {
+	suffix := "." + strings.ToLower(strings.Trim(baseDomain, "."))
+
+	return func(e *core.RequestEvent) string {
+		host := strings.ToLower(e.Request.Host)
+
+		// strip the port (if any)
+		if i := strings.LastIndexByte(host, ':'); i > 0 && !strings.HasSuffix(host, "]") {
+			host = host[:i]
+		}
+
+		sub, ok := strings.CutSuffix(host, suffix)
+		if !ok || sub == "" {
+			return ""
+		}
+
+		// use only the closest to the base domain label
+		if i := strings.LastIndexByte(sub, '.'); i >= 0 {
+			sub = sub[i+1:]
+		}
+
+		if sub == "www" {
+			return ""
+		}
+
+		return sub
+	}
+}
+
+// FromAuthField returns a tenant resolver that reads the tenant id
+// from the specified field of the current request auth record.
+func FromAuthField(field string) Resolver {
+	return func(e *core.RequestEvent) string {
+		if e.Auth == nil {
+			return ""
+		}
+
+		return e.Auth.GetString(field)
+	}
+}
+
+// -------------------------------------------------------------------
+
+var tenantRegex = regexp.MustCompile(`^[\w\-\.]+$`)
+
+// tenantRuleMacro is the API rules identifier of the tenant header.
+var tenantRuleMacro = "@request.headers." + inflector.Snakecase(HeaderName)
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) isScoped(collectionNameOrId string) bool {
+	if collectionNameOrId == "" {
+		return false
+	}
+
+	collection, err := p.app.FindCachedCollectionByNameOrId(collectionNameOrId)
+	if err != nil {
+		return false
+	}
+
+	return p.isScopedCollection(collection)
+}
+
+// isScopedCollection reports whether the provided collection is one of
+// the configured tenant scoped collections.
+//
+// Note that it doesn't look up the cached collections because it is also
+// used while the collections cache is being reloaded (e.g. for a new collection).
+func (p *plugin) isScopedCollection(collection *core.Collection) bool {
+	return list.ExistInSlice(collection.Name, p.config.Collections) ||
+		list.ExistInSlice(collection.Id, p.config.Collections)
+}
+
+func (p *plugin) middleware(e *core.RequestEvent) error {
+	// never trust the client submitted value
+	e.Request.Header.Del(HeaderName)
+
+	var tenant string
+	for _, resolver := range p.config.Resolvers {
+		tenant = resolver(e)
+		if tenant != "" && tenantRegex.MatchString(tenant) {
+			break
+		}
+		tenant = ""
+	}
+
+	if tenant != "" {
+		e.Set(RequestEventKeyTenant, tenant)
+		e.Request.Header.Set(HeaderName, tenant)
+	}
+
+	if tenant == "" &&
+		p.config.Required &&
+		!e.HasSuperuserAuth() &&
+		p.isScoped(e.Request.PathValue("collection")) {
+		return e.ForbiddenError("Missing or invalid tenant context.", nil)
+	}
+
+	return e.Next()
+}
+
+// ruleScope restricts the records of the scoped collections
+// to the ones that belong to the current request tenant.
+//
+// The scope is applied at the API rules level, so that it affects all
+// places where the rules are evaluated (list, count, view, expand, realtime, etc.).
+func (p *plugin) ruleScope(collection *core.Collection) string {
+	if !p.isScopedCollection(collection) || collection.Fields.GetByName(p.config.Field) == nil {
+		return ""
+	}
+
+	scope := p.config.Field + " = " + tenantRuleMacro
+
+	// the requests without tenant are rejected by the middleware
+	// but the realtime subscriptions could still have an empty one
+	if !p.config.Required {
+		scope = tenantRuleMacro + ` = "" || ` + scope
+	}
+
+	return scope
+}
+
+// onRealtimeSubscribe replaces the tenant header of the
+// subscriptions options with the resolved one because the realtime
+// rule checks use the client submitted subscription headers.
+func (p *plugin) onRealtimeSubscribe(e *core.RealtimeSubscribeRequestEvent) error {
+	tenant := TenantFromRequest(e.RequestEvent)
+
+	for i, sub := range e.Subscriptions {
+		e.Subscriptions[i] = replaceSubscriptionTenant(sub, tenant)
+	}
+
+	return e.Next()
+}
+
+func (p *plugin) onRecordAccess(e *core.RecordRequestEvent) error {
+	tenant := TenantFromRequest(e.RequestEvent)
+
+	if tenant != "" && e.Record.GetString(p.config.Field) != tenant {
+		return e.NotFoundError("", nil)
+	}
+
+	return e.Next()
+}
+
+func (p *plugin) onRecordUpdate(e *core.RecordRequestEvent) error {
+	tenant := TenantFromRequest(e.RequestEvent)
+	if tenant == "" {
+		return e.Next()
+	}
+
+	if e.Record.Original().GetString(p.config.Field) != tenant {
+		return e.NotFoundError("", nil)
+	}
+
+	// prevent moving the record to another tenant
+	e.Record.Set(p.config.Field, tenant)
+
+	if err := p.checkRelations(e.Record, tenant); err != nil {
+		return err
+	}
+
+	return e.Next()
+}
+
+func (p *plugin) onRecordCreate(e *core.RecordRequestEvent) error {
+	tenant := TenantFromRequest(e.RequestEvent)
+	if tenant == "" {
+		return e.Next()
+	}
+
+	e.Record.Set(p.config.Field, tenant)
+
+	if err := p.checkRelations(e.Record, tenant); err != nil {
+		return err
+	}
+
+	return e.Next()
+}
+
+// checkRelations ensures that the record doesn't link to records
+// of a tenant scoped collection that belong to another tenant.
+func (p *plugin) checkRelations(record *core.Record, tenant string) error {
+	for _, field := range record.Collection().Fields {
+		relField, ok := field.(*core.RelationField)
+		if !ok || !p.isScoped(relField.CollectionId) {
+			continue
+		}
+
+		ids := record.GetStringSlice(relField.Name)
+		if len(ids) == 0 {
+			continue
+		}
+
+		rels, err := p.app.FindRecordsByIds(relField.CollectionId, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, rel := range rels {
+			if rel.GetString(p.config.Field) != tenant {
+				return apis.NewBadRequestError("Failed to link the related record(s).", validation.Errors{
+					relField.Name: validation.NewError("validation_cross_tenant_relation", "Cannot link records from another tenant."),
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+// replaceSubscriptionTenant sets the tenant header in the options
+// of the provided realtime subscription (removing any client submitted value).
+func replaceSubscriptionTenant(sub string, tenant string) string {
+	u, err := url.Parse(sub)
+	if err != nil {
+		return sub
+	}
+
+	query := u.Query()
+
+	options := map[string]any{}
+	if raw := query.Get("options"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &options); err != nil {
+			options = map[string]any{}
+		}
+	}
+
+	headers, _ := options["headers"].(map[string]any)
+	if headers == nil {
+		if tenant == "" {
+			return sub // nothing to replace
+		}
+		headers = map[string]any{}
+	}
+
+	key := inflector.Snakecase(HeaderName)
+
+	// the subscription headers names are normalized on subscribe
+	for name := range headers {
+		if inflector.Snakecase(name) == key {
+			delete(headers, name)
+		}
+	}
+
+	if tenant != "" {
+		headers[key] = tenant
+	}
+
+	options["headers"] = headers
+
+	raw, err := json.Marshal(options)
+	if err != nil {
+		return sub
+	}
+
+	query.Set("options", string(raw))
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
@@ -0,0 +1,196 @@
+// Package transitions implements scheduled record state transitions,
+// aka. changing a record field value at a datetime stored on the same record.
+//
+// The due transitions are applied periodically by a cron job and the
+// transitioned records are persisted with [core.App.Save], meaning that
+// the regular record update hooks and realtime events are triggered.
+//
+// Example usage:
+//
+//	transitions.MustRegister(app, transitions.Config{
+//		Transitions: []transitions.Transition{
+//			{
+//				Collection: "posts",
+//				DateField:  "publishAt",
+//				Field:      "status",
+//				Value:      "published",
+//				Filter:     `status = "scheduled"`,
+//			},
+//		},
+//	})
+package transitions
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// DefaultCronJobId is the id of the cron job that applies the due transitions.
+	DefaultCronJobId = "__pbTransitions__"
+
+	// DefaultCronExpr is the default cron schedule of the transitions job (every minute).
+	DefaultCronExpr = "* * * * *"
+
+	// DefaultBatchSize is the default max number of records that
+	// are transitioned per transition and job run.
+	DefaultBatchSize = 500
+)
+
+// Transition defines a single scheduled field value change.
+type Transition struct {
+	// Collection is the name or id of the collection whose records will be transitioned.
+	Collection string
+
+	// DateField is the name of the record date or autodate field
+	// that holds the transition datetime.
+	//
+	// Records with empty DateField value are ignored.
+	DateField string
+
+	// Field is the name of the record field to change.
+	Field string
+
+	// Value is the new Field value.
+	Value any
+
+	// Filter is an optional additional record filter expression
+	// (e.g. `status = "scheduled"`).
+	Filter string
+}
+
+// Config defines the config options of the transitions plugin.
+type Config struct {
+	// Transitions is a list with the scheduled transitions to apply.
+	Transitions []Transition
+
+	// CronExpr specifies the schedule of the transitions job
+	// (default to [DefaultCronExpr]).
+	CronExpr string
+
+	// BatchSize specifies the max number of records that are transitioned
+	// per transition and job run (default to [DefaultBatchSize]).
+	//
+	// The remaining due records are transitioned on the next job run.
+	BatchSize int
+}
+
+// MustRegister registers the transitions plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the transitions plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	if len(config.Transitions) == 0 {
+		return errors.New("transitions: at least one transition must be specified")
+	}
+
+	for i, t := range config.Transitions {
+		if t.Collection == "" || t.DateField == "" || t.Field == "" {
+			return fmt.Errorf("transitions: transition %d must have Collection, DateField and Field", i)
+		}
+	}
+
+	if config.CronExpr == "" {
+		config.CronExpr = DefaultCronExpr
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+
+	return app.Cron().Add(DefaultCronJobId, config.CronExpr, func() {
+		for _, t := range config.Transitions {
+			total, err := Apply(app, t, time.Now(), config.BatchSize)
+			if err != nil {
+				app.Logger().Error(
+					"Failed to apply scheduled transition",
+					"collection", t.Collection,
+					"field", t.Field,
+					"error", err.Error(),
+				)
+			}
+
+			if total > 0 {
+				app.Logger().Debug(
+					"Applied scheduled transition",
+					"collection", t.Collection,
+					"field", t.Field,
+					"total", total,
+				)
+			}
+		}
+	})
+}
+
+// Apply changes the field value of at most limit records whose transition
+// datetime is before or equal to now and returns the number of the
+// successfully transitioned records.
+//
+// Records that fail to save (e.g. because of a validation error) are skipped
+// and the last save error is returned after processing the remaining records.
+func Apply(app core.App, t Transition, now time.Time, limit int) (int, error) {
+	collection, err := app.FindCachedCollectionByNameOrId(t.Collection)
+	if err != nil {
+		return 0, err
+	}
+
+	switch collection.Fields.GetByName(t.DateField).(type) {
+	case *core.DateField, *core.AutodateField:
+	default:
+		return 0, fmt.Errorf("%q is not a date field of collection %q", t.DateField, collection.Name)
+	}
+
+	if collection.Fields.GetByName(t.Field) == nil {
+		return 0, fmt.Errorf("missing field %q in collection %q", t.Field, collection.Name)
+	}
+
+	filter := fmt.Sprintf(
+		"%[1]s != '' && %[1]s <= {:transitionNow} && %[2]s != {:transitionValue}",
+		t.DateField,
+		t.Field,
+	)
+	if t.Filter != "" {
+		filter += " && (" + t.Filter + ")"
+	}
+
+	records, err := app.FindRecordsByFilter(
+		collection,
+		filter,
+		t.DateField,
+		limit,
+		0,
+		dbx.Params{
+			"transitionNow":   now.UTC().Format(types.DefaultDateLayout),
+			"transitionValue": t.Value,
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	var lastErr error
+
+	for _, record := range records {
+		record.Set(t.Field, t.Value)
+
+		if err := app.Save(record); err != nil {
+			lastErr = fmt.Errorf("failed to transition record %q: %w", record.Id, err)
+			continue
+		}
+
+		total++
+	}
+
+	return total, lastErr
+}
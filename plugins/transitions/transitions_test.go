@@ -0,0 +1,149 @@
+package transitions_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/transitions"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRegister(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := transitions.Register(app, transitions.Config{}); err == nil {
+		t.Fatal("Expected error for missing transitions")
+	}
+
+	if err := transitions.Register(app, transitions.Config{
+		Transitions: []transitions.Transition{{Collection: "demo1", Field: "select_one"}},
+	}); err == nil {
+		t.Fatal("Expected error for missing transition DateField")
+	}
+
+	totalJobs := app.Cron().Total()
+
+	err := transitions.Register(app, transitions.Config{
+		Transitions: []transitions.Transition{{Collection: "demo1", DateField: "datetime", Field: "select_one"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total := app.Cron().Total(); total != totalJobs+1 {
+		t.Fatalf("Expected %d cron jobs, got %d", totalJobs+1, total)
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name          string
+		transition    transitions.Transition
+		now           time.Time
+		expectedTotal int
+		expectedError bool
+	}{
+		{
+			"missing collection",
+			transitions.Transition{Collection: "missing", DateField: "datetime", Field: "select_one", Value: "optionA"},
+			time.Now(),
+			0,
+			true,
+		},
+		{
+			"non-date DateField",
+			transitions.Transition{Collection: "demo1", DateField: "text", Field: "select_one", Value: "optionA"},
+			time.Now(),
+			0,
+			true,
+		},
+		{
+			"missing Field",
+			transitions.Transition{Collection: "demo1", DateField: "datetime", Field: "missing", Value: "optionA"},
+			time.Now(),
+			0,
+			true,
+		},
+		{
+			"not due yet",
+			transitions.Transition{Collection: "demo1", DateField: "datetime", Field: "select_one", Value: "optionA"},
+			time.Date(2022, 10, 1, 11, 59, 59, 0, time.UTC),
+			0,
+			false,
+		},
+		{
+			"due",
+			transitions.Transition{Collection: "demo1", DateField: "datetime", Field: "select_one", Value: "optionA"},
+			time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC),
+			1,
+			false,
+		},
+		{
+			"due with already transitioned value",
+			transitions.Transition{Collection: "demo1", DateField: "datetime", Field: "select_one", Value: "optionB"},
+			time.Now(),
+			0,
+			false,
+		},
+		{
+			"due with unmatched filter",
+			transitions.Transition{Collection: "demo1", DateField: "datetime", Field: "select_one", Value: "optionA", Filter: "bool = false"},
+			time.Now(),
+			0,
+			false,
+		},
+		{
+			"due with invalid value",
+			transitions.Transition{Collection: "demo1", DateField: "datetime", Field: "select_one", Value: "invalid"},
+			time.Now(),
+			0,
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			var updated []string
+			app.OnModelAfterUpdateSuccess("demo1").BindFunc(func(e *core.ModelEvent) error {
+				updated = append(updated, e.Model.PK().(string))
+				return e.Next()
+			})
+
+			total, err := transitions.Apply(app, s.transition, s.now, 10)
+
+			hasErr := err != nil
+			if hasErr != s.expectedError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectedError, hasErr, err)
+			}
+
+			if total != s.expectedTotal {
+				t.Fatalf("Expected %d transitioned records, got %d", s.expectedTotal, total)
+			}
+
+			// the model update success event drives the realtime broadcast
+			if len(updated) != s.expectedTotal {
+				t.Fatalf("Expected %d update events, got %v", s.expectedTotal, updated)
+			}
+
+			if s.expectedTotal == 0 {
+				return
+			}
+
+			record, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v := record.GetString(s.transition.Field); v != s.transition.Value {
+				t.Fatalf("Expected %s %q, got %q", s.transition.Field, s.transition.Value, v)
+			}
+		})
+	}
+}
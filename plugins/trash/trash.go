@@ -0,0 +1,375 @@
+// Package trash implements a recoverable trash (aka. recycle bin)
+// for the records deleted by the superusers via the web APIs.
+//
+// The deleted record data and files are moved into a "_trash" system
+// collection record from where they could be restored until the trash
+// retention window expires.
+//
+// Note that the records deleted by a relation cascade delete are not trashed.
+//
+// The plugin registers the following superusers only endpoints
+// (the trashed records could be listed with the regular records list
+// API of the "_trash" collection):
+//
+//	POST   /api/trash/{id}/restore
+//	DELETE /api/trash                (optionally filtered with ?collection=name)
+//
+// Example usage:
+//
+//	trash.MustRegister(app, trash.Config{
+//		Window: 7 * 24 * time.Hour,
+//	})
+package trash
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameTrash = "_trash"
+
+const (
+	DefaultWindow = 30 * 24 * time.Hour
+
+	cleanupCronId = "__pbTrashCleanup__"
+)
+
+// Config defines the config options of the trash plugin.
+type Config struct {
+	// Window specifies for how long the deleted records are kept
+	// in the trash before their permanent deletion (default to 30 days).
+	Window time.Duration
+
+	// Collections is an optional list with the names of the collections
+	// whose deleted records are trashed.
+	//
+	// If empty, the deleted records of all non-system collections are trashed.
+	Collections []string
+}
+
+// MustRegister registers the trash plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the trash plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if p.config.Window <= 0 {
+		p.config.Window = DefaultWindow
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollection(); err != nil {
+			return err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollection()
+		})
+	}
+
+	p.app.OnRecordDeleteRequest(p.config.Collections...).BindFunc(p.onDeleteRequest)
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group("/api/trash").Bind(apis.RequireSuperuserAuth())
+		sub.POST("/{id}/restore", p.restore)
+		sub.DELETE("", p.empty)
+
+		return e.Next()
+	})
+
+	return p.app.Cron().Add(cleanupCronId, "20 * * * *", func() {
+		err := deleteTrash(p.app, dbx.NewExp("[[created]] < {:date}", dbx.Params{
+			"date": time.Now().Add(-p.config.Window).UTC().Format(types.DefaultDateLayout),
+		}))
+		if err != nil {
+			p.app.Logger().Error("Failed to delete the expired trash records", "error", err.Error())
+		}
+	})
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) ensureCollection() error {
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameTrash); err == nil {
+		return nil // already created
+	}
+
+	col := core.NewBaseCollection(CollectionNameTrash)
+	col.System = true
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordCollection",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.JSONField{
+		Name:    "data",
+		System:  true,
+		MaxSize: 1 << 24,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "deletedBy",
+		System: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.AddIndex("idx_trash_record", false, "collectionRef, recordRef", "")
+	col.AddIndex("idx_trash_created", false, "created", "")
+
+	return p.app.Save(col)
+}
+
+// onDeleteRequest moves the superuser deleted record data and files into the trash.
+func (p *plugin) onDeleteRequest(e *core.RecordRequestEvent) error {
+	collection := e.Record.Collection()
+
+	if !e.HasSuperuserAuth() ||
+		collection.Name == CollectionNameTrash ||
+		(len(p.config.Collections) == 0 && collection.System) {
+		return e.Next()
+	}
+
+	trashCollection, err := e.App.FindCachedCollectionByNameOrId(CollectionNameTrash)
+	if err != nil {
+		return err
+	}
+
+	// load the raw db data to avoid the fields value normalizations on restore
+	row := dbx.NullStringMap{}
+	err = e.App.DB().Select("*").
+		From(collection.Name).
+		Where(dbx.HashExp{"id": e.Record.Id}).
+		Limit(1).
+		One(&row)
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string]any, len(row))
+	for k, v := range row {
+		if v.Valid {
+			data[k] = v.String
+		} else {
+			data[k] = nil
+		}
+	}
+
+	trashRecord := core.NewRecord(trashCollection)
+	trashRecord.Set("collectionRef", collection.Id)
+	trashRecord.Set("recordCollection", collection.Name)
+	trashRecord.Set("recordRef", e.Record.Id)
+	trashRecord.Set("data", data)
+	trashRecord.Set("deletedBy", e.Auth.Id)
+
+	if err := e.App.Save(trashRecord); err != nil {
+		return err
+	}
+
+	// rollback the trash record on copy or delete failure
+	// (the copied trash files are deleted together with the trash record)
+	rollback := func(err error) error {
+		if deleteErr := e.App.Delete(trashRecord); deleteErr != nil {
+			e.App.Logger().Error("Failed to delete trash record", "trashId", trashRecord.Id, "error", deleteErr.Error())
+		}
+
+		return err
+	}
+
+	if files := recordFiles(e.Record); len(files) > 0 {
+		err := copyFiles(e.App, files, e.Record.BaseFilesPath(), trashRecord.BaseFilesPath())
+		if err != nil {
+			return rollback(err)
+		}
+	}
+
+	if err := e.Next(); err != nil {
+		return rollback(err)
+	}
+
+	return nil
+}
+
+func (p *plugin) restore(e *core.RequestEvent) error {
+	trashRecord, err := e.App.FindRecordById(CollectionNameTrash, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	collection, err := e.App.FindCollectionByNameOrId(trashRecord.GetString("collectionRef"))
+	if err != nil {
+		return e.BadRequestError("The trashed record collection no longer exists.", err)
+	}
+
+	if _, err := e.App.FindRecordById(collection, trashRecord.GetString("recordRef")); err == nil {
+		return e.BadRequestError("A record with the same id already exists.", nil)
+	}
+
+	data := map[string]any{}
+	if err := json.Unmarshal([]byte(trashRecord.GetString("data")), &data); err != nil {
+		return e.BadRequestError("Invalid trashed record data.", err)
+	}
+
+	record := core.NewRecord(collection)
+	for _, field := range collection.Fields {
+		value, err := field.PrepareValue(record, data[field.GetName()])
+		if err != nil {
+			return e.BadRequestError("Failed to load the trashed record data.", err)
+		}
+		record.SetRaw(field.GetName(), value)
+	}
+
+	files := recordFiles(record)
+
+	if len(files) > 0 {
+		err := copyFiles(e.App, files, trashRecord.BaseFilesPath(), record.BaseFilesPath())
+		if err != nil {
+			return e.BadRequestError("Failed to restore the trashed record files.", err)
+		}
+	}
+
+	txErr := e.App.RunInTransaction(func(txApp core.App) error {
+		// the data was already validated before the deletion
+		if err := txApp.SaveNoValidate(record); err != nil {
+			return err
+		}
+
+		return txApp.Delete(trashRecord)
+	})
+	if txErr != nil {
+		if len(files) > 0 {
+			if err := deleteFiles(e.App, files, record.BaseFilesPath()); err != nil {
+				e.App.Logger().Error("Failed to delete the restored record files", "recordId", record.Id, "error", err.Error())
+			}
+		}
+
+		return e.BadRequestError("Failed to restore the trashed record.", txErr)
+	}
+
+	if err := apis.EnrichRecord(e, record); err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	return e.JSON(http.StatusOK, record)
+}
+
+func (p *plugin) empty(e *core.RequestEvent) error {
+	var exp dbx.Expression
+
+	if name := e.Request.URL.Query().Get("collection"); name != "" {
+		exp = dbx.Or(
+			dbx.HashExp{"recordCollection": name},
+			dbx.HashExp{"collectionRef": name},
+		)
+	}
+
+	if err := deleteTrash(e.App, exp); err != nil {
+		return e.BadRequestError("Failed to empty the trash.", err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// -------------------------------------------------------------------
+
+// recordFiles returns the names of all record files.
+func recordFiles(record *core.Record) []string {
+	var files []string
+
+	for _, field := range record.Collection().Fields {
+		if field.Type() == core.FieldTypeFile {
+			files = append(files, record.GetStringSlice(field.GetName())...)
+		}
+	}
+
+	return list.ToUniqueStringSlice(files)
+}
+
+func copyFiles(app core.App, files []string, srcDir string, dstDir string) error {
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	for _, name := range files {
+		err := fsys.Copy(srcDir+"/"+name, dstDir+"/"+name)
+		if err != nil && !errors.Is(err, filesystem.ErrNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteFiles(app core.App, files []string, dir string) error {
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	var errs []error
+	for _, name := range files {
+		if err := fsys.Delete(dir + "/" + name); err != nil && !errors.Is(err, filesystem.ErrNotFound) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func deleteTrash(app core.App, exp dbx.Expression) error {
+	var exprs []dbx.Expression
+	if exp != nil {
+		exprs = append(exprs, exp)
+	}
+
+	records, err := app.FindAllRecords(CollectionNameTrash, exprs...)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		if err := app.Delete(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
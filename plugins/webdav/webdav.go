@@ -0,0 +1,686 @@
+// Package webdav implements a read-only WebDAV mount of the record files,
+// allowing operators to browse and bulk download the uploaded files with
+// standard WebDAV clients (rclone, Cyberduck, the OS file managers, etc.).
+//
+// The files are exposed with the following hierarchy:
+//
+//	/webdav/                                  (the collections with file fields)
+//	/webdav/{collection}/                     (the collection records with files)
+//	/webdav/{collection}/{recordId}/          (the record files)
+//	/webdav/{collection}/{recordId}/{filename}
+//
+// Only the OPTIONS, PROPFIND (with Depth 0 or 1), GET and HEAD methods
+// are supported. All other (write) methods are rejected with 405.
+//
+// The requests must be authenticated with HTTP Basic auth where the
+// username is ignored and the password is either an API key or an auth
+// token of an auth record. API keys must have the "read" scope for the
+// accessed collection.
+//
+// Superusers can access all files. All other auth records must satisfy
+// the collection API rules:
+//   - ListRule for listing the collection records
+//   - ViewRule for listing and downloading the record files
+//
+// Example usage:
+//
+//	webdav.MustRegister(app, webdav.Config{
+//		Collections: []string{"documents"},
+//	})
+//
+// and the related rclone remote:
+//
+//	[pb]
+//	type = webdav
+//	url = https://example.com/webdav
+//	user = pb
+//	pass = API_KEY (obscured with "rclone obscure")
+package webdav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"gocloud.dev/blob"
+)
+
+// DefaultBasePath is the default WebDAV routes path prefix.
+const DefaultBasePath = "/webdav"
+
+const (
+	realm         = "PocketBase WebDAV"
+	allowedMethod = "OPTIONS, PROPFIND, GET, HEAD"
+)
+
+// Config defines the config options of the webdav plugin.
+type Config struct {
+	// BasePath is the WebDAV mount path prefix (default to "/webdav").
+	BasePath string
+
+	// Collections is an optional list with the names of the exposed
+	// collections (default to all non-system base and auth collections
+	// with at least one file field).
+	Collections []string
+
+	// SuperusersOnly restricts the WebDAV access only to superusers.
+	SuperusersOnly bool
+}
+
+// MustRegister registers the webdav plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the webdav plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if p.config.BasePath == "" {
+		p.config.BasePath = DefaultBasePath
+	}
+	p.config.BasePath = "/" + strings.Trim(p.config.BasePath, "/")
+	if p.config.BasePath == "/" {
+		return errors.New("webdav: BasePath must not be the root path")
+	}
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		sub := e.Router.Group(p.config.BasePath)
+		sub.Any("", p.serve)
+		sub.Any("/{path...}", p.serve)
+
+		return e.Next()
+	})
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+// resource describes a single WebDAV resource (either a directory or a file).
+type resource struct {
+	href        string
+	name        string
+	isDir       bool
+	size        int64
+	contentType string
+	etag        string
+	modified    time.Time
+}
+
+func (p *plugin) serve(e *core.RequestEvent) error {
+	switch e.Request.Method {
+	case http.MethodOptions:
+		e.Response.Header().Set("DAV", "1")
+		e.Response.Header().Set("Allow", allowedMethod)
+		e.Response.Header().Set("MS-Author-Via", "DAV")
+		return e.NoContent(http.StatusOK)
+	case "PROPFIND", http.MethodGet, http.MethodHead:
+		// continue
+	default:
+		e.Response.Header().Set("Allow", allowedMethod)
+		return e.Error(http.StatusMethodNotAllowed, "The WebDAV mount is read-only.", nil)
+	}
+
+	if err := p.authorize(e); err != nil {
+		return err
+	}
+
+	parts := []string{}
+	for _, part := range strings.Split(e.Request.PathValue("path"), "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	if len(parts) > 3 {
+		return e.NotFoundError("", nil)
+	}
+
+	if e.Request.Method != "PROPFIND" {
+		if len(parts) != 3 {
+			e.Response.Header().Set("Allow", allowedMethod)
+			return e.Error(http.StatusMethodNotAllowed, "Only files can be downloaded.", nil)
+		}
+
+		return p.download(e, parts[0], parts[1], parts[2])
+	}
+
+	depth := e.Request.Header.Get("Depth")
+	switch depth {
+	case "0", "1":
+		// supported
+	case "", "infinity":
+		// note: the "infinity" depth (or missing Depth header as per RFC 4918)
+		// is not supported to prevent listing all files with a single request
+		return e.ForbiddenError("Only Depth 0 or 1 PROPFIND requests are supported.", nil)
+	default:
+		return e.BadRequestError("Invalid Depth header value.", nil)
+	}
+
+	var resources []resource
+	var err error
+
+	switch len(parts) {
+	case 0:
+		resources, err = p.rootResources(e, depth == "1")
+	case 1:
+		resources, err = p.collectionResources(e, parts[0], depth == "1")
+	case 2:
+		resources, err = p.recordResources(e, parts[0], parts[1], depth == "1")
+	case 3:
+		resources, err = p.fileResources(e, parts[0], parts[1], parts[2])
+	}
+	if err != nil {
+		return err
+	}
+
+	return e.XML(http.StatusMultiStatus, newMultistatus(resources))
+}
+
+// authorize loads the request auth record from the Basic auth password
+// (either an API key or an auth token).
+func (p *plugin) authorize(e *core.RequestEvent) error {
+	unauthorized := func(err error) error {
+		e.Response.Header().Set("WWW-Authenticate", `Basic realm="`+realm+`", charset="UTF-8"`)
+		return e.UnauthorizedError("Missing or invalid WebDAV credentials.", err)
+	}
+
+	_, password, ok := e.Request.BasicAuth()
+	if !ok || password == "" {
+		return unauthorized(nil)
+	}
+
+	if strings.HasPrefix(password, core.APIKeyPrefix) {
+		record, apiKey, err := e.App.FindAuthRecordByAPIKey(password)
+		if err != nil {
+			return unauthorized(err)
+		}
+
+		e.Auth = record
+		e.Set(core.RequestEventKeyAPIKey, apiKey)
+	} else {
+		record, err := e.App.FindAuthRecordByToken(password, core.TokenTypeAuth)
+		if err != nil {
+			return unauthorized(err)
+		}
+
+		e.Auth = record
+	}
+
+	if p.config.SuperusersOnly && !e.HasSuperuserAuth() {
+		return e.ForbiddenError("Only superusers can access the WebDAV mount.", nil)
+	}
+
+	return nil
+}
+
+// findCollection returns the exposed collection with the specified name
+// if the request API key (if any) has read access to it.
+func (p *plugin) findCollection(e *core.RequestEvent, name string) (*core.Collection, error) {
+	collection, err := e.App.FindCachedCollectionByNameOrId(name)
+	if err != nil || collection.Name != name || !p.isExposed(collection) {
+		return nil, e.NotFoundError("", err)
+	}
+
+	if apiKey, ok := e.Get(core.RequestEventKeyAPIKey).(*core.APIKey); ok && !apiKey.HasScope(collection, core.APIKeyScopeRead) {
+		return nil, e.ForbiddenError(fmt.Sprintf("The API key doesn't have the required %q scope.", core.APIKeyScopeRead), nil)
+	}
+
+	return collection, nil
+}
+
+// findRecord returns the collection record with the specified id
+// if the request auth satisfies the collection ViewRule.
+func (p *plugin) findRecord(e *core.RequestEvent, collection *core.Collection, recordId string) (*core.Record, error) {
+	record, err := e.App.FindRecordById(collection, recordId)
+	if err != nil {
+		return nil, e.NotFoundError("", err)
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return nil, e.InternalServerError("", err)
+	}
+
+	if ok, _ := e.App.CanAccessRecord(record, requestInfo, collection.ViewRule); !ok {
+		// hide the record existence
+		return nil, e.NotFoundError("", errors.New("insufficient permissions to access the record"))
+	}
+
+	return record, nil
+}
+
+// canAccessFile checks whether the request auth can access the files of the specified record field.
+func (p *plugin) canAccessFile(e *core.RequestEvent, record *core.Record, field *core.FileField) bool {
+	if !field.Protected {
+		return true // already checked with findRecord
+	}
+
+	originalRequestInfo, err := e.RequestInfo()
+	if err != nil {
+		return false
+	}
+
+	// create a shallow copy of the cached request data and adjust its context for the protected files
+	requestInfo := *originalRequestInfo
+	requestInfo.Context = core.RequestInfoContextProtectedFile
+
+	ok, _ := e.App.CanAccessRecord(record, &requestInfo, record.Collection().ViewRule)
+
+	return ok
+}
+
+// isExposed checks whether the collection is exposed with the WebDAV mount.
+func (p *plugin) isExposed(collection *core.Collection) bool {
+	if collection.System || collection.IsView() {
+		return false
+	}
+
+	if len(p.config.Collections) > 0 && !list.ExistInSlice(collection.Name, p.config.Collections) {
+		return false
+	}
+
+	return len(fileFields(collection)) > 0
+}
+
+func fileFields(collection *core.Collection) []*core.FileField {
+	result := []*core.FileField{}
+
+	for _, f := range collection.Fields {
+		if fileField, ok := f.(*core.FileField); ok {
+			result = append(result, fileField)
+		}
+	}
+
+	return result
+}
+
+// href returns the escaped absolute href of the specified path segments.
+func (p *plugin) href(isDir bool, segments ...string) string {
+	var sb strings.Builder
+
+	sb.WriteString(p.config.BasePath)
+	sb.WriteString("/")
+
+	for i, s := range segments {
+		if i > 0 {
+			sb.WriteString("/")
+		}
+		sb.WriteString(url.PathEscape(s))
+	}
+
+	if isDir && len(segments) > 0 {
+		sb.WriteString("/")
+	}
+
+	return sb.String()
+}
+
+// etag returns a quoted file ETag from its MD5 checksum
+// (or from its modification date and size if the checksum is not available).
+func etag(attrs *blob.Attributes) string {
+	if len(attrs.MD5) > 0 {
+		return fmt.Sprintf(`"%x"`, attrs.MD5)
+	}
+
+	return fmt.Sprintf(`"%x-%x"`, attrs.ModTime.UnixNano(), attrs.Size)
+}
+
+// -------------------------------------------------------------------
+// PROPFIND
+// -------------------------------------------------------------------
+
+func (p *plugin) rootResources(e *core.RequestEvent, withChildren bool) ([]resource, error) {
+	result := []resource{{href: p.href(true), isDir: true}}
+
+	if !withChildren {
+		return result, nil
+	}
+
+	collections, err := e.App.FindAllCollections(core.CollectionTypeBase, core.CollectionTypeAuth)
+	if err != nil {
+		return nil, e.InternalServerError("", err)
+	}
+
+	apiKey, _ := e.Get(core.RequestEventKeyAPIKey).(*core.APIKey)
+
+	for _, collection := range collections {
+		if !p.isExposed(collection) {
+			continue
+		}
+
+		if apiKey != nil && !apiKey.HasScope(collection, core.APIKeyScopeRead) {
+			continue
+		}
+
+		if !e.HasSuperuserAuth() && collection.ListRule == nil {
+			continue
+		}
+
+		result = append(result, resource{
+			href:     p.href(true, collection.Name),
+			name:     collection.Name,
+			isDir:    true,
+			modified: collection.Updated.Time(),
+		})
+	}
+
+	slices.SortFunc(result[1:], func(a, b resource) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return result, nil
+}
+
+func (p *plugin) collectionResources(e *core.RequestEvent, collectionName string, withChildren bool) ([]resource, error) {
+	collection, err := p.findCollection(e, collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []resource{{
+		href:     p.href(true, collection.Name),
+		name:     collection.Name,
+		isDir:    true,
+		modified: collection.Updated.Time(),
+	}}
+
+	if !withChildren {
+		return result, nil
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return nil, e.InternalServerError("", err)
+	}
+
+	if !requestInfo.HasSuperuserAuth() && collection.ListRule == nil {
+		return nil, e.ForbiddenError("Only superusers can list the collection records.", nil)
+	}
+
+	query := e.App.RecordQuery(collection)
+
+	if !requestInfo.HasSuperuserAuth() && *collection.ListRule != "" {
+		resolver := core.NewRecordFieldResolver(e.App, collection, requestInfo, true)
+
+		expr, err := search.FilterData(*collection.ListRule).BuildExpr(resolver)
+		if err != nil {
+			return nil, e.InternalServerError("", err)
+		}
+		query.AndWhere(expr)
+
+		if err := resolver.UpdateQuery(query); err != nil {
+			return nil, e.InternalServerError("", err)
+		}
+	}
+
+	records := []*core.Record{}
+	if err := query.All(&records); err != nil {
+		return nil, e.InternalServerError("", err)
+	}
+
+	fields := fileFields(collection)
+
+	for _, record := range records {
+		var hasFiles bool
+		for _, field := range fields {
+			if len(record.GetStringSlice(field.Name)) > 0 {
+				hasFiles = true
+				break
+			}
+		}
+		if !hasFiles {
+			continue
+		}
+
+		modified := record.GetDateTime("updated").Time()
+		if modified.IsZero() {
+			modified = collection.Updated.Time()
+		}
+
+		result = append(result, resource{
+			href:     p.href(true, collection.Name, record.Id),
+			name:     record.Id,
+			isDir:    true,
+			modified: modified,
+		})
+	}
+
+	slices.SortFunc(result[1:], func(a, b resource) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return result, nil
+}
+
+func (p *plugin) recordResources(e *core.RequestEvent, collectionName string, recordId string, withChildren bool) ([]resource, error) {
+	collection, err := p.findCollection(e, collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := p.findRecord(e, collection, recordId)
+	if err != nil {
+		return nil, err
+	}
+
+	modified := record.GetDateTime("updated").Time()
+	if modified.IsZero() {
+		modified = collection.Updated.Time()
+	}
+
+	result := []resource{{
+		href:     p.href(true, collection.Name, record.Id),
+		name:     record.Id,
+		isDir:    true,
+		modified: modified,
+	}}
+
+	if !withChildren {
+		return result, nil
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return nil, e.InternalServerError("", err)
+	}
+	defer fsys.Close()
+
+	for _, field := range fileFields(collection) {
+		if !p.canAccessFile(e, record, field) {
+			continue
+		}
+
+		for _, filename := range record.GetStringSlice(field.Name) {
+			attrs, err := fsys.Attributes(record.BaseFilesPath() + "/" + filename)
+			if err != nil {
+				continue // missing file
+			}
+
+			result = append(result, fileResource(p.href(false, collection.Name, record.Id, filename), filename, attrs))
+		}
+	}
+
+	slices.SortFunc(result[1:], func(a, b resource) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return result, nil
+}
+
+func (p *plugin) fileResources(e *core.RequestEvent, collectionName string, recordId string, filename string) ([]resource, error) {
+	collection, err := p.findCollection(e, collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := p.findRecord(e, collection, recordId)
+	if err != nil {
+		return nil, err
+	}
+
+	field := record.FindFileFieldByFile(filename)
+	if field == nil || !p.canAccessFile(e, record, field) {
+		return nil, e.NotFoundError("", nil)
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return nil, e.InternalServerError("", err)
+	}
+	defer fsys.Close()
+
+	attrs, err := fsys.Attributes(record.BaseFilesPath() + "/" + filename)
+	if err != nil {
+		return nil, e.NotFoundError("", err)
+	}
+
+	return []resource{fileResource(p.href(false, collection.Name, record.Id, filename), filename, attrs)}, nil
+}
+
+func fileResource(href string, filename string, attrs *blob.Attributes) resource {
+	return resource{
+		href:        href,
+		name:        filename,
+		size:        attrs.Size,
+		contentType: attrs.ContentType,
+		etag:        etag(attrs),
+		modified:    attrs.ModTime,
+	}
+}
+
+// -------------------------------------------------------------------
+// GET/HEAD
+// -------------------------------------------------------------------
+
+func (p *plugin) download(e *core.RequestEvent, collectionName string, recordId string, filename string) error {
+	collection, err := p.findCollection(e, collectionName)
+	if err != nil {
+		return err
+	}
+
+	record, err := p.findRecord(e, collection, recordId)
+	if err != nil {
+		return err
+	}
+
+	fileField := record.FindFileFieldByFile(filename)
+	if fileField == nil || !p.canAccessFile(e, record, fileField) {
+		return e.NotFoundError("", nil)
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+	defer fsys.Close()
+
+	path := record.BaseFilesPath() + "/" + filename
+
+	attrs, err := fsys.Attributes(path)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	event := new(core.FileDownloadRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Record = record
+	event.FileField = fileField
+	event.ServedPath = path
+	event.ServedName = filename
+
+	e.Response.Header().Set("ETag", etag(attrs))
+
+	return e.App.OnFileDownloadRequest().Trigger(event, func(e *core.FileDownloadRequestEvent) error {
+		if err := fsys.Serve(e.Response, e.Request, e.ServedPath, e.ServedName); err != nil {
+			return e.NotFoundError("", err)
+		}
+
+		return nil
+	})
+}
+
+// -------------------------------------------------------------------
+// XML
+// -------------------------------------------------------------------
+
+type multistatus struct {
+	XMLName   xml.Name           `xml:"DAV: multistatus"`
+	Responses []propfindResponse `xml:"response"`
+}
+
+type propfindResponse struct {
+	Href     string   `xml:"href"`
+	Propstat propstat `xml:"propstat"`
+}
+
+type propstat struct {
+	Prop   prop   `xml:"prop"`
+	Status string `xml:"status"`
+}
+
+type prop struct {
+	DisplayName      string       `xml:"displayname,omitempty"`
+	ResourceType     resourceType `xml:"resourcetype"`
+	GetContentLength *int64       `xml:"getcontentlength,omitempty"`
+	GetContentType   string       `xml:"getcontenttype,omitempty"`
+	GetETag          string       `xml:"getetag,omitempty"`
+	GetLastModified  string       `xml:"getlastmodified,omitempty"`
+}
+
+type resourceType struct {
+	Collection *struct{} `xml:"collection"`
+}
+
+func newMultistatus(resources []resource) *multistatus {
+	result := &multistatus{
+		Responses: make([]propfindResponse, 0, len(resources)),
+	}
+
+	for _, r := range resources {
+		item := propfindResponse{
+			Href: r.href,
+			Propstat: propstat{
+				Status: "HTTP/1.1 200 OK",
+				Prop: prop{
+					DisplayName: r.name,
+				},
+			},
+		}
+
+		if !r.modified.IsZero() {
+			item.Propstat.Prop.GetLastModified = r.modified.UTC().Format(http.TimeFormat)
+		}
+
+		if r.isDir {
+			item.Propstat.Prop.ResourceType.Collection = &struct{}{}
+		} else {
+			size := r.size
+			item.Propstat.Prop.GetContentLength = &size
+			item.Propstat.Prop.GetContentType = r.contentType
+			item.Propstat.Prop.GetETag = r.etag
+		}
+
+		result.Responses = append(result.Responses, item)
+	}
+
+	return result
+}
@@ -0,0 +1,119 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// DefaultGenericSignatureHeader is the default signature header of the "generic" provider.
+const DefaultGenericSignatureHeader = "X-Webhook-Signature"
+
+// StripeTolerance is the max allowed age of a Stripe signature timestamp.
+var StripeTolerance = 5 * time.Minute
+
+// Verifier defines a webhook request signature verification function.
+//
+// The source argument is the matched "_webhookSources" record.
+type Verifier func(r *http.Request, payload []byte, source *core.Record) error
+
+// VerifyGeneric verifies a hex encoded HMAC-SHA256 payload signature
+// (optionally prefixed with "sha256=") submitted with the
+// source "signatureHeader" ([DefaultGenericSignatureHeader] if not set).
+func VerifyGeneric(r *http.Request, payload []byte, source *core.Record) error {
+	header := source.GetString("signatureHeader")
+	if header == "" {
+		header = DefaultGenericSignatureHeader
+	}
+
+	signature := strings.TrimPrefix(r.Header.Get(header), "sha256=")
+
+	return compareSignature(signature, payload, source.GetString("secret"))
+}
+
+// VerifyGitHub verifies the GitHub "X-Hub-Signature-256" payload signature.
+func VerifyGitHub(r *http.Request, payload []byte, source *core.Record) error {
+	signature, ok := strings.CutPrefix(r.Header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return errors.New("missing or invalid X-Hub-Signature-256 header")
+	}
+
+	return compareSignature(signature, payload, source.GetString("secret"))
+}
+
+// VerifyStripe verifies the Stripe "Stripe-Signature" payload signature.
+func VerifyStripe(r *http.Request, payload []byte, source *core.Record) error {
+	var timestamp string
+	var signatures []string
+
+	for _, part := range strings.Split(r.Header.Get("Stripe-Signature"), ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			timestamp = v
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return errors.New("missing or invalid Stripe-Signature header")
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	if time.Since(time.Unix(unix, 0)).Abs() > StripeTolerance {
+		return errors.New("the Stripe-Signature timestamp is outside of the tolerance zone")
+	}
+
+	signedPayload := append([]byte(timestamp+"."), payload...)
+
+	for _, signature := range signatures {
+		if compareSignature(signature, signedPayload, source.GetString("secret")) == nil {
+			return nil
+		}
+	}
+
+	return errors.New("no matching Stripe signature")
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of the payload
+// (usually used for test purposes or when forwarding webhooks).
+func Sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func compareSignature(signature string, payload []byte, secret string) error {
+	if signature == "" || secret == "" {
+		return errors.New("missing signature or secret")
+	}
+
+	expected, err := hex.DecodeString(Sign(payload, secret))
+	if err != nil {
+		return err
+	}
+
+	actual, err := hex.DecodeString(signature)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(expected, actual) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package webhooks_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/webhooks"
+)
+
+func testSource(signatureHeader string) *core.Record {
+	collection := core.NewBaseCollection("test")
+	collection.Fields.Add(
+		&core.TextField{Name: "secret"},
+		&core.TextField{Name: "signatureHeader"},
+	)
+
+	record := core.NewRecord(collection)
+	record.Set("secret", "test_secret")
+	record.Set("signatureHeader", signatureHeader)
+
+	return record
+}
+
+func TestVerifyGeneric(t *testing.T) {
+	t.Parallel()
+
+	payload := []byte(`{"a":123}`)
+	signature := webhooks.Sign(payload, "test_secret")
+
+	scenarios := []struct {
+		name            string
+		signatureHeader string
+		headers         map[string]string
+		expectError     bool
+	}{
+		{"missing signature", "", nil, true},
+		{"invalid signature", "", map[string]string{webhooks.DefaultGenericSignatureHeader: webhooks.Sign(payload, "abc")}, true},
+		{"valid signature", "", map[string]string{webhooks.DefaultGenericSignatureHeader: signature}, false},
+		{"valid prefixed signature", "", map[string]string{webhooks.DefaultGenericSignatureHeader: "sha256=" + signature}, false},
+		{"default header with custom source header", "X-Custom", map[string]string{webhooks.DefaultGenericSignatureHeader: signature}, true},
+		{"custom source header", "X-Custom", map[string]string{"X-Custom": signature}, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			for k, v := range s.headers {
+				req.Header.Set(k, v)
+			}
+
+			err := webhooks.VerifyGeneric(req, payload, testSource(s.signatureHeader))
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifyGitHub(t *testing.T) {
+	t.Parallel()
+
+	payload := []byte(`{"a":123}`)
+
+	scenarios := []struct {
+		name        string
+		header      string
+		expectError bool
+	}{
+		{"missing signature", "", true},
+		{"missing prefix", webhooks.Sign(payload, "test_secret"), true},
+		{"invalid signature", "sha256=" + webhooks.Sign(payload, "abc"), true},
+		{"valid signature", "sha256=" + webhooks.Sign(payload, "test_secret"), false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set("X-Hub-Signature-256", s.header)
+
+			err := webhooks.VerifyGitHub(req, payload, testSource(""))
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifyStripe(t *testing.T) {
+	t.Parallel()
+
+	payload := []byte(`{"a":123}`)
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-1*time.Hour).Unix(), 10)
+
+	scenarios := []struct {
+		name        string
+		header      string
+		expectError bool
+	}{
+		{"missing signature", "", true},
+		{"missing timestamp", "v1=" + webhooks.Sign([]byte(now+"."+string(payload)), "test_secret"), true},
+		{"expired timestamp", "t=" + old + ",v1=" + webhooks.Sign([]byte(old+"."+string(payload)), "test_secret"), true},
+		{"invalid signature", "t=" + now + ",v1=" + webhooks.Sign([]byte(now+"."+string(payload)), "abc"), true},
+		{"valid signature", "t=" + now + ",v1=" + webhooks.Sign([]byte(now+"."+string(payload)), "test_secret"), false},
+		{"valid secondary signature", "t=" + now + ",v1=abc,v1=" + webhooks.Sign([]byte(now+"."+string(payload)), "test_secret") + ",v0=123", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set("Stripe-Signature", s.header)
+
+			err := webhooks.VerifyStripe(req, payload, testSource(""))
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
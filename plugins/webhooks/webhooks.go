@@ -0,0 +1,317 @@
+// Package webhooks implements an inbound webhooks receiver with
+// per source signature verification and payload storage.
+//
+// The webhook sources are managed by the superusers as records of the
+// "_webhookSources" collection and every verified request sent
+// to "POST /api/webhooks/{source}" is stored in the "_webhooks" collection
+// and then passed to the [Plugin.OnReceived] hook.
+//
+// Example usage:
+//
+//	p := webhooks.MustRegister(app, webhooks.Config{})
+//
+//	hook.NewTaggedHook(p.OnReceived, "stripe").BindFunc(func(e *webhooks.ReceivedEvent) error {
+//		// process e.Payload...
+//
+//		return e.Next()
+//	})
+package webhooks
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	CollectionNameSources  = "_webhookSources"
+	CollectionNameWebhooks = "_webhooks"
+)
+
+// DefaultMaxPayloadSize is the default max webhook request body size.
+const DefaultMaxPayloadSize int64 = 1 << 20
+
+// Config defines the config options of the webhooks plugin.
+type Config struct {
+	// Verifiers is a map with additional or overwritten signature
+	// verifiers, where the key is the source provider name
+	// (the built-in providers are "generic", "github" and "stripe").
+	Verifiers map[string]Verifier
+
+	// MaxPayloadSize specifies the max allowed webhook request body size
+	// (default to [DefaultMaxPayloadSize]).
+	MaxPayloadSize int64
+
+	// DisableStorage disables the persistence of the received payloads.
+	DisableStorage bool
+}
+
+// Plugin defines the public hooks of a registered webhooks plugin instance.
+type Plugin struct {
+	// OnReceived hook is triggered on each verified inbound webhook request.
+	//
+	// The event is tagged with the webhook source name
+	// (see [hook.NewTaggedHook]).
+	OnReceived *hook.Hook[*ReceivedEvent]
+}
+
+// ReceivedEvent defines the data of a single verified inbound webhook request.
+type ReceivedEvent struct {
+	hook.Event
+	*core.RequestEvent
+
+	// Source is the name of the webhook source.
+	Source string
+
+	// Payload is the raw body of the verified webhook request.
+	Payload []byte
+
+	// Record is the stored webhook record (could be nil if the storage is disabled).
+	Record *core.Record
+}
+
+// Tags implements [hook.Tagger] interface.
+func (e *ReceivedEvent) Tags() []string {
+	if e.Source == "" {
+		return nil
+	}
+
+	return []string{e.Source}
+}
+
+// MustRegister registers the webhooks plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) *Plugin {
+	p, err := Register(app, config)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// Register registers the webhooks plugin to the provided app instance.
+func Register(app core.App, config Config) (*Plugin, error) {
+	p := &plugin{
+		Plugin: &Plugin{
+			OnReceived: &hook.Hook[*ReceivedEvent]{},
+		},
+		app:    app,
+		config: config,
+	}
+
+	if p.config.MaxPayloadSize <= 0 {
+		p.config.MaxPayloadSize = DefaultMaxPayloadSize
+	}
+
+	p.verifiers = map[string]Verifier{
+		"generic": VerifyGeneric,
+		"github":  VerifyGitHub,
+		"stripe":  VerifyStripe,
+	}
+	for name, verifier := range p.config.Verifiers {
+		p.verifiers[name] = verifier
+	}
+
+	if p.app.IsBootstrapped() {
+		if err := p.ensureCollections(); err != nil {
+			return nil, err
+		}
+	} else {
+		p.app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return p.ensureCollections()
+		})
+	}
+
+	p.app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		e.Router.POST("/api/webhooks/{source}", p.receive).
+			Unbind(apis.DefaultBodyLimitMiddlewareId).
+			Bind(apis.BodyLimit(p.config.MaxPayloadSize))
+
+		return e.Next()
+	})
+
+	return p.Plugin, nil
+}
+
+type plugin struct {
+	*Plugin
+
+	app       core.App
+	verifiers map[string]Verifier
+	config    Config
+}
+
+func (p *plugin) providers() []string {
+	names := make([]string, 0, len(p.verifiers))
+	for name := range p.verifiers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// ensureCollections creates or updates the plugin system collections.
+func (p *plugin) ensureCollections() error {
+	sources, err := p.app.FindCollectionByNameOrId(CollectionNameSources)
+	if err != nil {
+		sources = core.NewBaseCollection(CollectionNameSources)
+		sources.System = true
+		sources.Fields.Add(&core.TextField{
+			Name:     "name",
+			System:   true,
+			Required: true,
+			Pattern:  `^[\w\-]+$`,
+		})
+		sources.Fields.Add(&core.SelectField{
+			Name:      "provider",
+			System:    true,
+			Required:  true,
+			MaxSelect: 1,
+		})
+		sources.Fields.Add(&core.TextField{
+			Name:     "secret",
+			System:   true,
+			Hidden:   true,
+			Required: true,
+		})
+		sources.Fields.Add(&core.TextField{
+			Name:   "signatureHeader",
+			System: true,
+		})
+		sources.Fields.Add(&core.BoolField{
+			Name:   "active",
+			System: true,
+		})
+		sources.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		sources.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		sources.AddIndex("idx_webhookSources_name", true, "name", "")
+	}
+
+	// sync the available providers
+	if provider, ok := sources.Fields.GetByName("provider").(*core.SelectField); ok {
+		provider.Values = p.providers()
+	}
+
+	if err := p.app.Save(sources); err != nil {
+		return err
+	}
+
+	if _, err := p.app.FindCollectionByNameOrId(CollectionNameWebhooks); err == nil {
+		return nil // already created
+	}
+
+	webhooks := core.NewBaseCollection(CollectionNameWebhooks)
+	webhooks.System = true
+	webhooks.Fields.Add(&core.TextField{
+		Name:     "source",
+		System:   true,
+		Required: true,
+	})
+	webhooks.Fields.Add(&core.JSONField{
+		Name:   "headers",
+		System: true,
+	})
+	webhooks.Fields.Add(&core.TextField{
+		Name:   "payload",
+		System: true,
+		Max:    int(p.config.MaxPayloadSize),
+	})
+	webhooks.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	webhooks.AddIndex("idx_webhooks_source", false, "source", "")
+
+	return p.app.Save(webhooks)
+}
+
+func (p *plugin) receive(e *core.RequestEvent) error {
+	sourceName := e.Request.PathValue("source")
+
+	source, err := e.App.FindFirstRecordByData(CollectionNameSources, "name", sourceName)
+	if err != nil || !source.GetBool("active") {
+		return e.NotFoundError("Missing or inactive webhook source.", err)
+	}
+
+	verifier, ok := p.verifiers[source.GetString("provider")]
+	if !ok {
+		return e.NotFoundError("Missing or inactive webhook source.", errors.New("unsupported provider"))
+	}
+
+	payload, err := io.ReadAll(e.Request.Body)
+	if err != nil {
+		return e.BadRequestError("Failed to read the webhook payload.", err)
+	}
+
+	err = verifier(e.Request, payload, source)
+	if err != nil {
+		e.App.Logger().Debug(
+			"Invalid webhook signature",
+			slog.String("source", sourceName),
+			slog.String("error", err.Error()),
+		)
+		return e.UnauthorizedError("Invalid webhook signature.", nil)
+	}
+
+	event := new(ReceivedEvent)
+	event.RequestEvent = e
+	event.Source = sourceName
+	event.Payload = payload
+
+	if !p.config.DisableStorage {
+		collection, err := e.App.FindCachedCollectionByNameOrId(CollectionNameWebhooks)
+		if err != nil {
+			return e.InternalServerError("", err)
+		}
+
+		headers := make(map[string]string, len(e.Request.Header))
+		for name := range e.Request.Header {
+			if name == "Authorization" || name == "Cookie" {
+				continue
+			}
+			headers[name] = e.Request.Header.Get(name)
+		}
+
+		record := core.NewRecord(collection)
+		record.Set("source", sourceName)
+		record.Set("headers", types.JSONMap[string](headers))
+		record.Set("payload", string(payload))
+
+		if err := e.App.Save(record); err != nil {
+			return e.InternalServerError("Failed to store the webhook payload.", err)
+		}
+
+		event.Record = record
+	}
+
+	return p.OnReceived.Trigger(event, func(e *ReceivedEvent) error {
+		if e.Written() {
+			return nil
+		}
+
+		return e.NoContent(http.StatusNoContent)
+	})
+}
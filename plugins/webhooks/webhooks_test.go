@@ -0,0 +1,214 @@
+package webhooks_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/webhooks"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+func TestRegisterCollections(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	webhooks.MustRegister(app, webhooks.Config{
+		Verifiers: map[string]webhooks.Verifier{
+			"custom": webhooks.VerifyGeneric,
+		},
+	})
+
+	sources, err := app.FindCollectionByNameOrId(webhooks.CollectionNameSources)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, ok := sources.Fields.GetByName("provider").(*core.SelectField)
+	if !ok {
+		t.Fatal("Expected provider select field")
+	}
+
+	expected := "custom,generic,github,stripe"
+	if v := strings.Join(provider.Values, ","); v != expected {
+		t.Fatalf("Expected providers %q, got %q", expected, v)
+	}
+
+	if _, err := app.FindCollectionByNameOrId(webhooks.CollectionNameWebhooks); err != nil {
+		t.Fatal(err)
+	}
+
+	// register again to ensure that the existing collections are reused
+	if _, err := webhooks.Register(app, webhooks.Config{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReceive(t *testing.T) {
+	t.Parallel()
+
+	payload := `{"event":"test"}`
+	signature := webhooks.Sign([]byte(payload), "test_secret")
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "missing source",
+			Method:          http.MethodPost,
+			URL:             "/api/webhooks/missing",
+			Body:            strings.NewReader(payload),
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+			TestAppFactory:  testAppFactory(false),
+		},
+		{
+			Name:   "inactive source",
+			Method: http.MethodPost,
+			URL:    "/api/webhooks/inactive",
+			Body:   strings.NewReader(payload),
+			Headers: map[string]string{
+				webhooks.DefaultGenericSignatureHeader: signature,
+			},
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+			TestAppFactory:  testAppFactory(false),
+		},
+		{
+			Name:   "invalid signature",
+			Method: http.MethodPost,
+			URL:    "/api/webhooks/test",
+			Body:   strings.NewReader(payload),
+			Headers: map[string]string{
+				webhooks.DefaultGenericSignatureHeader: webhooks.Sign([]byte(payload), "abc"),
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+			TestAppFactory:  testAppFactory(false),
+		},
+		{
+			Name:   "valid signature",
+			Method: http.MethodPost,
+			URL:    "/api/webhooks/test",
+			Body:   strings.NewReader(payload),
+			Headers: map[string]string{
+				webhooks.DefaultGenericSignatureHeader: signature,
+				"Authorization":                        "test_auth",
+			},
+			ExpectedStatus: 204,
+			ExpectedEvents: map[string]int{
+				"*":                          0,
+				"OnReceived":                 1,
+				"OnModelCreate":              1,
+				"OnModelCreateExecute":       1,
+				"OnModelAfterCreateSuccess":  1,
+				"OnModelValidate":            1,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnRecordValidate":           1,
+			},
+			TestAppFactory: testAppFactory(false),
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				record, err := app.FindFirstRecordByData(webhooks.CollectionNameWebhooks, "source", "test")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if v := record.GetString("payload"); v != payload {
+					t.Fatalf("Expected payload %q, got %q", payload, v)
+				}
+
+				headers := record.GetString("headers")
+				if !strings.Contains(headers, signature) {
+					t.Fatalf("Expected the signature header to be stored, got %s", headers)
+				}
+				if strings.Contains(headers, "test_auth") {
+					t.Fatalf("Expected the Authorization header to be excluded, got %s", headers)
+				}
+			},
+		},
+		{
+			Name:   "valid signature with disabled storage and custom hook response",
+			Method: http.MethodPost,
+			URL:    "/api/webhooks/test",
+			Body:   strings.NewReader(payload),
+			Headers: map[string]string{
+				webhooks.DefaultGenericSignatureHeader: signature,
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`{"received":"test"}`},
+			ExpectedEvents: map[string]int{
+				"*":          0,
+				"OnReceived": 1,
+			},
+			TestAppFactory: testAppFactory(true, func(t testing.TB, p *webhooks.Plugin) {
+				hook.NewTaggedHook(p.OnReceived, "test").BindFunc(func(e *webhooks.ReceivedEvent) error {
+					if e.Record != nil {
+						t.Fatal("Expected nil webhook record")
+					}
+
+					if string(e.Payload) != payload {
+						t.Fatalf("Expected payload %q, got %q", payload, e.Payload)
+					}
+
+					return e.JSON(200, map[string]string{"received": e.Source})
+				})
+
+				hook.NewTaggedHook(p.OnReceived, "other").BindFunc(func(e *webhooks.ReceivedEvent) error {
+					t.Fatal("Expected the other source handler to be skipped")
+					return e.Next()
+				})
+			}),
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func testAppFactory(disableStorage bool, setup ...func(t testing.TB, p *webhooks.Plugin)) func(t testing.TB) *tests.TestApp {
+	return tests.NewTestAppFactory(func(t testing.TB, app *tests.TestApp) {
+		p := webhooks.MustRegister(app, webhooks.Config{DisableStorage: disableStorage})
+
+		p.OnReceived.Bind(&hook.Handler[*webhooks.ReceivedEvent]{
+			Func: func(e *webhooks.ReceivedEvent) error {
+				registerEventCall(app, "OnReceived")
+				return e.Next()
+			},
+			Priority: -99999,
+		})
+
+		for _, fn := range setup {
+			fn(t, p)
+		}
+
+		sources, err := app.FindCollectionByNameOrId(webhooks.CollectionNameSources)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name, active := range map[string]bool{"test": true, "inactive": false} {
+			source := core.NewRecord(sources)
+			source.Set("name", name)
+			source.Set("provider", "generic")
+			source.Set("secret", "test_secret")
+			source.Set("active", active)
+			if err := app.Save(source); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+}
+
+func registerEventCall(app *tests.TestApp, name string) {
+	if app.EventCalls == nil {
+		app.EventCalls = map[string]int{}
+	}
+
+	app.EventCalls[name]++
+}
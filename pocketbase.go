@@ -56,7 +56,16 @@ type Config struct {
 	DataMaxIdleConns int                // default to core.DefaultDataMaxIdleConns
 	AuxMaxOpenConns  int                // default to core.DefaultAuxMaxOpenConns
 	AuxMaxIdleConns  int                // default to core.DefaultAuxMaxIdleConns
+	WriteQueueSize   int                // default to core.DefaultWriteQueueSize
 	DBConnect        core.DBConnectFunc // default to core.dbConnect
+
+	// optional infrastructure overwrites
+	NewFilesystem        core.FilesystemFactoryFunc // default to local or S3 based on the app settings
+	NewBackupsFilesystem core.FilesystemFactoryFunc // default to local or S3 based on the app settings
+	NewMailClient        core.MailClientFactoryFunc // default to SMTP or sendmail based on the app settings
+	TokenSigner          core.TokenSigner           // default to core.HS256TokenSigner
+	Cache                core.Cache                 // default to the settings based Redis or in-memory cache
+	TemplateRenderer     core.TemplateRenderer      // no default (e.g. template.NewViews(...))
 }
 
 // New creates a new PocketBase instance with the default configuration.
@@ -131,7 +140,15 @@ func NewWithConfig(config Config) *PocketBase {
 		DataMaxIdleConns: config.DataMaxIdleConns,
 		AuxMaxOpenConns:  config.AuxMaxOpenConns,
 		AuxMaxIdleConns:  config.AuxMaxIdleConns,
+		WriteQueueSize:   config.WriteQueueSize,
 		DBConnect:        config.DBConnect,
+
+		NewFilesystem:        config.NewFilesystem,
+		NewBackupsFilesystem: config.NewBackupsFilesystem,
+		NewMailClient:        config.NewMailClient,
+		TokenSigner:          config.TokenSigner,
+		Cache:                config.Cache,
+		TemplateRenderer:     config.TemplateRenderer,
 	})
 
 	// hide the default help command (allow only `--help` flag)
@@ -141,11 +158,15 @@ func NewWithConfig(config Config) *PocketBase {
 }
 
 // Start starts the application, aka. registers the default system
-// commands (serve, superuser, version) and executes pb.RootCmd.
+// commands (serve, superuser, diagnose, settings, version) and executes pb.RootCmd.
 func (pb *PocketBase) Start() error {
 	// register system commands
 	pb.RootCmd.AddCommand(cmd.NewSuperuserCommand(pb))
 	pb.RootCmd.AddCommand(cmd.NewServeCommand(pb, !pb.hideStartBanner))
+	pb.RootCmd.AddCommand(cmd.NewDiagnoseCommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewSettingsCommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewFakeCommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewDBCommand(pb))
 
 	return pb.Execute()
 }
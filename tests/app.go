@@ -87,6 +87,28 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		return nil, err
 	}
 
+	return newTestApp(tempDir)
+}
+
+// NewEmptyTestApp creates and initializes an isolated test application
+// instance with empty temp data dir (aka. only with the system
+// collections and settings created by the app migrations).
+//
+// It is usually used to test extensions that define their own
+// collections (e.g. with migrations) and don't need the default test data.
+//
+// It is the caller's responsibility to call `app.Cleanup()`
+// when the app is no longer needed.
+func NewEmptyTestApp() (*TestApp, error) {
+	tempDir, err := os.MkdirTemp("", "pb_test_*")
+	if err != nil {
+		return nil, err
+	}
+
+	return newTestApp(tempDir)
+}
+
+func newTestApp(tempDir string) (*TestApp, error) {
 	app := core.NewBaseApp(core.BaseAppConfig{
 		DataDir:       tempDir,
 		EncryptionEnv: "pb_test_env",
@@ -376,6 +398,14 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnRecordChange().Bind(&hook.Handler[*core.RecordChangeEvent]{
+		Func: func(e *core.RecordChangeEvent) error {
+			t.registerEventCall("OnRecordChange")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnCollectionValidate().Bind(&hook.Handler[*core.CollectionEvent]{
 		Func: func(e *core.CollectionEvent) error {
 			t.registerEventCall("OnCollectionValidate")
@@ -492,6 +522,22 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnSMSSend().Bind(&hook.Handler[*core.SMSSendEvent]{
+		Func: func(e *core.SMSSendEvent) error {
+			t.registerEventCall("OnSMSSend")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordNotify().Bind(&hook.Handler[*core.RecordNotifyEvent]{
+		Func: func(e *core.RecordNotifyEvent) error {
+			t.registerEventCall("OnRecordNotify")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnMailerRecordAuthAlertSend().Bind(&hook.Handler[*core.MailerRecordEvent]{
 		Func: func(e *core.MailerRecordEvent) error {
 			t.registerEventCall("OnMailerRecordAuthAlertSend")
@@ -532,6 +578,14 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnMailerRecordMagicLinkSend().Bind(&hook.Handler[*core.MailerRecordEvent]{
+		Func: func(e *core.MailerRecordEvent) error {
+			t.registerEventCall("OnMailerRecordMagicLinkSend")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnRealtimeConnectRequest().Bind(&hook.Handler[*core.RealtimeConnectRequestEvent]{
 		Func: func(e *core.RealtimeConnectRequestEvent) error {
 			t.registerEventCall("OnRealtimeConnectRequest")
@@ -604,6 +658,14 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnRecordNewDeviceLogin().Bind(&hook.Handler[*core.RecordNewDeviceLoginEvent]{
+		Func: func(e *core.RecordNewDeviceLoginEvent) error {
+			t.registerEventCall("OnRecordNewDeviceLogin")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnRecordAuthWithPasswordRequest().Bind(&hook.Handler[*core.RecordAuthWithPasswordRequestEvent]{
 		Func: func(e *core.RecordAuthWithPasswordRequestEvent) error {
 			t.registerEventCall("OnRecordAuthWithPasswordRequest")
@@ -620,6 +682,30 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnRecordAuthWithSAMLRequest().Bind(&hook.Handler[*core.RecordAuthWithSAMLRequestEvent]{
+		Func: func(e *core.RecordAuthWithSAMLRequestEvent) error {
+			t.registerEventCall("OnRecordAuthWithSAMLRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordAuthAsGuestRequest().Bind(&hook.Handler[*core.RecordAuthAsGuestRequestEvent]{
+		Func: func(e *core.RecordAuthAsGuestRequestEvent) error {
+			t.registerEventCall("OnRecordAuthAsGuestRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordGuestUpgradeRequest().Bind(&hook.Handler[*core.RecordGuestUpgradeRequestEvent]{
+		Func: func(e *core.RecordGuestUpgradeRequestEvent) error {
+			t.registerEventCall("OnRecordGuestUpgradeRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnRecordAuthRefreshRequest().Bind(&hook.Handler[*core.RecordAuthRefreshRequestEvent]{
 		Func: func(e *core.RecordAuthRefreshRequestEvent) error {
 			t.registerEventCall("OnRecordAuthRefreshRequest")
@@ -692,6 +778,54 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnRecordRequestMagicLinkRequest().Bind(&hook.Handler[*core.RecordRequestMagicLinkRequestEvent]{
+		Func: func(e *core.RecordRequestMagicLinkRequestEvent) error {
+			t.registerEventCall("OnRecordRequestMagicLinkRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordAuthWithMagicLinkRequest().Bind(&hook.Handler[*core.RecordAuthWithMagicLinkRequestEvent]{
+		Func: func(e *core.RecordAuthWithMagicLinkRequestEvent) error {
+			t.registerEventCall("OnRecordAuthWithMagicLinkRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordRequestSMSOTPRequest().Bind(&hook.Handler[*core.RecordRequestSMSOTPRequestEvent]{
+		Func: func(e *core.RecordRequestSMSOTPRequestEvent) error {
+			t.registerEventCall("OnRecordRequestSMSOTPRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordAuthWithSMSOTPRequest().Bind(&hook.Handler[*core.RecordAuthWithSMSOTPRequestEvent]{
+		Func: func(e *core.RecordAuthWithSMSOTPRequestEvent) error {
+			t.registerEventCall("OnRecordAuthWithSMSOTPRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordAuthLockout().Bind(&hook.Handler[*core.RecordAuthLockoutEvent]{
+		Func: func(e *core.RecordAuthLockoutEvent) error {
+			t.registerEventCall("OnRecordAuthLockout")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnActionLinkRequest().Bind(&hook.Handler[*core.ActionLinkRequestEvent]{
+		Func: func(e *core.ActionLinkRequestEvent) error {
+			t.registerEventCall("OnActionLinkRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnRecordsListRequest().Bind(&hook.Handler[*core.RecordsListRequestEvent]{
 		Func: func(e *core.RecordsListRequestEvent) error {
 			t.registerEventCall("OnRecordsListRequest")
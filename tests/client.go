@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// TestClient is a helper for sending requests directly to the app
+// router without starting a real server (e.g. for testing multi-step
+// flows with custom routes).
+//
+// Example:
+//
+//	client, err := tests.NewTestClient(app)
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//
+//	client.Headers["Authorization"] = token
+//
+//	res := client.Send(http.MethodPost, "/api/myapp/phone-login", strings.NewReader(`{"phone":"+123456789"}`), nil)
+//	if res.StatusCode != 200 {
+//	    t.Fatalf("Expected status 200, got %d", res.StatusCode)
+//	}
+type TestClient struct {
+	// Headers is a list of default headers sent with every request
+	// (could be overwritten by the request headers).
+	Headers map[string]string
+
+	mux http.Handler
+}
+
+// NewTestClient initializes a new [TestClient] for the provided app.
+//
+// The app OnServe hook is triggered once to ensure that the custom
+// routes and middlewares are registered.
+func NewTestClient(app core.App) (*TestClient, error) {
+	baseRouter, err := apis.NewRouter(app)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &TestClient{Headers: map[string]string{}}
+
+	serveEvent := new(core.ServeEvent)
+	serveEvent.App = app
+	serveEvent.Router = baseRouter
+
+	err = app.OnServe().Trigger(serveEvent, func(e *core.ServeEvent) error {
+		mux, err := e.Router.BuildMux()
+		if err != nil {
+			return err
+		}
+
+		client.mux = mux
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Do sends the provided request and returns its response.
+func (c *TestClient) Do(req *http.Request) *http.Response {
+	for k, v := range c.Headers {
+		if req.Header.Get(k) == "" {
+			req.Header.Set(k, v)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+
+	c.mux.ServeHTTP(recorder, req)
+
+	return recorder.Result()
+}
+
+// Send creates and sends a new request with the provided arguments.
+//
+// The request content-type is default to "application/json".
+func (c *TestClient) Send(method string, url string, body io.Reader, headers map[string]string) *http.Response {
+	req := httptest.NewRequest(method, url, body)
+
+	req.Header.Set("content-type", "application/json")
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	return c.Do(req)
+}
+
+// SendJSON sends a new request with the JSON serialized data as body
+// and unmarshals the response body into result (if not nil).
+func (c *TestClient) SendJSON(method string, url string, data any, result any) (*http.Response, error) {
+	var body io.Reader
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(raw)
+	}
+
+	res := c.Send(method, url, body, nil)
+
+	if result != nil {
+		defer res.Body.Close()
+
+		if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,73 @@
+package tests_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestTestClient(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		e.Router.POST("/custom", func(e *core.RequestEvent) error {
+			data := struct {
+				Name string `json:"name"`
+			}{}
+			if err := e.BindBody(&data); err != nil {
+				return e.BadRequestError("", err)
+			}
+
+			return e.JSON(http.StatusOK, map[string]any{
+				"name":   data.Name,
+				"header": e.Request.Header.Get("X-Test"),
+			})
+		})
+
+		return e.Next()
+	})
+
+	client, err := tests.NewTestClient(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.Headers["X-Test"] = "default"
+
+	t.Run("Send", func(t *testing.T) {
+		res := client.Send(http.MethodPost, "/custom", strings.NewReader(`{"name":"test"}`), map[string]string{"X-Test": "custom"})
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", res.StatusCode)
+		}
+	})
+
+	t.Run("SendJSON", func(t *testing.T) {
+		result := map[string]string{}
+
+		res, err := client.SendJSON(http.MethodPost, "/custom", map[string]any{"name": "test"}, &result)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", res.StatusCode)
+		}
+
+		if result["name"] != "test" || result["header"] != "default" {
+			t.Fatalf("Unexpected result %v", result)
+		}
+	})
+
+	t.Run("default routes", func(t *testing.T) {
+		res := client.Send(http.MethodGet, "/api/health", nil, nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", res.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"testing"
+)
+
+// NewTestAppFactory returns a new [ApiScenario.TestAppFactory] compatible
+// function that creates a new test app with the default test data and calls
+// the optional setup callback with it (e.g. to register a plugin or to create
+// extra test records).
+//
+// Example:
+//
+//	var testAppFactory = tests.NewTestAppFactory(func(t testing.TB, app *tests.TestApp) {
+//		myplugin.MustRegister(app, myplugin.Config{})
+//	})
+func NewTestAppFactory(setup func(t testing.TB, app *TestApp)) func(t testing.TB) *TestApp {
+	return func(t testing.TB) *TestApp {
+		app, err := NewTestApp()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if setup != nil {
+			setup(t, app)
+		}
+
+		return app
+	}
+}
+
+// NewAuthToken returns a new auth token for the specified auth record
+// from the default test data (e.g. for the ApiScenario "Authorization" header).
+func NewAuthToken(t testing.TB, collectionNameOrId string, recordId string) string {
+	app, err := NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+
+	record, err := app.FindRecordById(collectionNameOrId, recordId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := record.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+// CreateRecords creates new records in the specified collection
+// from the provided data items (all in a single transaction).
+//
+// File field values could be specified as [*filesystem.File] or [][*filesystem.File].
+//
+// Example:
+//
+//	records, err := tests.CreateRecords(app, "posts",
+//	    map[string]any{"title": "a"},
+//	    map[string]any{"title": "b", "cover": coverFile},
+//	)
+func CreateRecords(app core.App, collectionNameOrId string, data ...map[string]any) ([]*core.Record, error) {
+	records := make([]*core.Record, 0, len(data))
+
+	err := app.RunInTransaction(func(txApp core.App) error {
+		collection, err := txApp.FindCollectionByNameOrId(collectionNameOrId)
+		if err != nil {
+			return err
+		}
+
+		for i, item := range data {
+			record := core.NewRecord(collection)
+			record.Load(item)
+
+			if err := txApp.Save(record); err != nil {
+				return fmt.Errorf("failed to create %q record %d: %w", collection.Name, i, err)
+			}
+
+			records = append(records, record)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// LoadFixtures creates the records defined in the specified JSON fixtures file
+// (all in a single transaction and in the order of their definition).
+//
+// The fixtures file must contain an array of items in the following format:
+//
+//	[
+//	    {"collection": "authors", "data": {"id": "author000000001", "name": "John"}},
+//	    {"collection": "posts", "data": {"title": "a", "author": "author000000001", "cover": {"@file": "files/cover.png"}}},
+//	    {"collection": "posts", "data": {"title": "b", "gallery": [{"@file": "files/1.png"}, {"@file": "files/2.png"}]}}
+//	]
+//
+// The "@file" paths are relative to the fixtures file directory.
+func LoadFixtures(app core.App, fixturesFile string) ([]*core.Record, error) {
+	raw, err := os.ReadFile(fixturesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []struct {
+		Collection string         `json:"collection"`
+		Data       map[string]any `json:"data"`
+	}{}
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+
+	baseDir := filepath.Dir(fixturesFile)
+
+	records := make([]*core.Record, 0, len(items))
+
+	err = app.RunInTransaction(func(txApp core.App) error {
+		for i, item := range items {
+			data := make(map[string]any, len(item.Data))
+			for k, v := range item.Data {
+				resolved, err := resolveFixtureFiles(baseDir, v)
+				if err != nil {
+					return fmt.Errorf("fixture %d: %w", i, err)
+				}
+				data[k] = resolved
+			}
+
+			created, err := CreateRecords(txApp, item.Collection, data)
+			if err != nil {
+				return fmt.Errorf("fixture %d: %w", i, err)
+			}
+
+			records = append(records, created...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// resolveFixtureFiles replaces the {"@file": "path"} fixture values
+// (or arrays of such values) with their related [*filesystem.File].
+func resolveFixtureFiles(baseDir string, value any) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		path, ok := v["@file"].(string)
+		if !ok || len(v) != 1 {
+			return value, nil
+		}
+
+		return filesystem.NewFileFromPath(filepath.Join(baseDir, path))
+	case []any:
+		files := make([]*filesystem.File, 0, len(v))
+
+		for _, item := range v {
+			file, err := resolveFixtureFiles(baseDir, item)
+			if err != nil {
+				return nil, err
+			}
+
+			f, ok := file.(*filesystem.File)
+			if !ok {
+				return value, nil // not a files list
+			}
+
+			files = append(files, f)
+		}
+
+		if len(files) == 0 {
+			return value, nil
+		}
+
+		return files, nil
+	}
+
+	return value, nil
+}
@@ -0,0 +1,115 @@
+package tests_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestNewEmptyTestApp(t *testing.T) {
+	t.Parallel()
+
+	app, err := tests.NewEmptyTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+
+	if _, err := app.FindCollectionByNameOrId(core.CollectionNameSuperusers); err != nil {
+		t.Fatalf("Expected the system collections to be created: %v", err)
+	}
+
+	if _, err := app.FindCollectionByNameOrId("demo1"); err == nil {
+		t.Fatal("Expected the test data collections to be missing")
+	}
+}
+
+func TestCreateRecords(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	file, err := filesystem.NewFileFromBytes([]byte("test"), "test.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := tests.CreateRecords(app, "demo3",
+		map[string]any{"title": "a"},
+		map[string]any{"title": "b", "files": file},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+
+	if files := records[1].GetStringSlice("files"); len(files) != 1 {
+		t.Fatalf("Expected 1 uploaded file, got %v", files)
+	}
+
+	// invalid data
+	_, err = tests.CreateRecords(app, "demo2", map[string]any{"title": "valid"}, map[string]any{"title": ""})
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+
+	if _, err := app.FindFirstRecordByData("demo2", "title", "valid"); err == nil {
+		t.Fatal("Expected all records creation to be rolled back")
+	}
+}
+
+func TestLoadFixtures(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"files/a.txt": "a",
+		"files/b.txt": "b",
+		"fixtures.json": `[
+			{"collection": "demo3", "data": {"id": "fixture00000001", "title": "fixture", "files": [{"@file": "files/a.txt"}, {"@file": "files/b.txt"}]}},
+			{"collection": "demo4", "data": {"title": "fixture", "rel_one_no_cascade_required": "fixture00000001", "rel_many_no_cascade_required": ["fixture00000001"], "json_object": {"@file": "files/a.txt", "other": 1}}}
+		]`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := tests.LoadFixtures(app, filepath.Join(dir, "fixtures.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+
+	if files := records[0].GetStringSlice("files"); len(files) != 2 {
+		t.Fatalf("Expected 2 uploaded files, got %v", files)
+	}
+
+	if rel := records[1].GetString("rel_one_no_cascade_required"); rel != "fixture00000001" {
+		t.Fatalf("Expected relation %q, got %q", "fixture00000001", rel)
+	}
+
+	if json := records[1].GetString("json_object"); json != `{"@file":"files/a.txt","other":1}` {
+		t.Fatalf("Expected the json value to not be resolved as file, got %s", json)
+	}
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"sync"
+
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+// HookBinder defines the common interface of [hook.Hook] and [hook.TaggedHook].
+type HookBinder[T hook.Resolver] interface {
+	Bind(handler *hook.Handler[T]) string
+}
+
+// HookRecorder records the triggered events of a single hook.
+//
+// It is usually used for inspecting the hooks that are not
+// tracked by the [TestApp.EventCalls] (e.g. custom plugin hooks).
+type HookRecorder[T hook.Resolver] struct {
+	mu     sync.Mutex
+	events []T
+}
+
+// RecordHook binds a new [HookRecorder] to the provided hook.
+//
+// Example:
+//
+//	recorder := tests.RecordHook[*core.RecordEvent](app.OnRecordCreate("posts"))
+//
+//	// ...
+//
+//	if recorder.Total() != 1 {
+//	    t.Fatalf("Expected 1 OnRecordCreate call, got %d", recorder.Total())
+//	}
+func RecordHook[T hook.Resolver](h HookBinder[T]) *HookRecorder[T] {
+	recorder := &HookRecorder[T]{}
+
+	h.Bind(&hook.Handler[T]{
+		Func: func(e T) error {
+			recorder.mu.Lock()
+			recorder.events = append(recorder.events, e)
+			recorder.mu.Unlock()
+
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	return recorder
+}
+
+// Total returns the total number of the recorded events.
+func (r *HookRecorder[T]) Total() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.events)
+}
+
+// Events returns a shallow copy of the recorded events.
+func (r *HookRecorder[T]) Events() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]T, len(r.events))
+	copy(events, r.events)
+
+	return events
+}
+
+// Reset clears the recorded events.
+func (r *HookRecorder[T]) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = nil
+}
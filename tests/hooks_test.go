@@ -0,0 +1,44 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestHookRecorder(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	recorder := tests.RecordHook[*core.RecordEvent](app.OnRecordCreate("demo2"))
+
+	if _, err := tests.CreateRecords(app, "demo3", map[string]any{"title": "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if recorder.Total() != 0 {
+		t.Fatalf("Expected no recorded events, got %d", recorder.Total())
+	}
+
+	if _, err := tests.CreateRecords(app, "demo2", map[string]any{"title": "a1"}, map[string]any{"title": "a2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	events := recorder.Events()
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 recorded events, got %d", len(events))
+	}
+
+	if title := events[1].Record.GetString("title"); title != "a2" {
+		t.Fatalf("Expected the second event record title %q, got %q", "a2", title)
+	}
+
+	recorder.Reset()
+
+	if recorder.Total() != 0 {
+		t.Fatalf("Expected the recorded events to be reset, got %d", recorder.Total())
+	}
+}
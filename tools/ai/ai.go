@@ -0,0 +1,269 @@
+// Package ai implements a minimal client for OpenAI compatible
+// embeddings and chat completions APIs (OpenAI, Azure OpenAI proxies,
+// Ollama, LM Studio, vLLM, OpenRouter, etc.).
+//
+// Example usage:
+//
+//	client := &ai.Client{
+//		BaseURL: "https://api.openai.com/v1",
+//		APIKey:  "...",
+//	}
+//
+//	vector, err := client.Embedding(ctx, "Hello world!")
+//
+//	res, err := client.Complete(ctx, &ai.CompletionRequest{
+//		Messages: []ai.Message{
+//			{Role: ai.RoleSystem, Content: "Summarize the provided text in 1 sentence."},
+//			{Role: ai.RoleUser, Content: "..."},
+//		},
+//	})
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// DefaultBaseURL is the default API base url.
+	DefaultBaseURL = "https://api.openai.com/v1"
+
+	// DefaultCompletionModel is the default chat completions model.
+	DefaultCompletionModel = "gpt-4o-mini"
+
+	// DefaultEmbeddingModel is the default embeddings model.
+	DefaultEmbeddingModel = "text-embedding-3-small"
+)
+
+// Chat message roles.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message defines a single chat completion message.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// CompletionRequest defines the chat completion request options.
+type CompletionRequest struct {
+	// Model is the chat completions model to use (default to [Client.CompletionModel]).
+	Model string `json:"model,omitempty"`
+
+	// Messages is the list with the conversation messages.
+	Messages []Message `json:"messages"`
+
+	// Temperature is an optional sampling temperature.
+	Temperature *float64 `json:"temperature,omitempty"`
+
+	// MaxTokens is an optional max number of the generated tokens.
+	MaxTokens int `json:"maxTokens,omitempty"`
+
+	// JSON instructs the model to respond with a valid JSON object.
+	//
+	// Note that most models also require explicit JSON instructions in the messages.
+	JSON bool `json:"json,omitempty"`
+}
+
+// Usage defines the tokens usage of a single request.
+type Usage struct {
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+	TotalTokens      int `json:"totalTokens"`
+}
+
+// CompletionResponse defines the chat completion result.
+type CompletionResponse struct {
+	// Content is the generated message content.
+	Content string `json:"content"`
+
+	// FinishReason is the reason why the model stopped generating tokens
+	// (e.g. "stop", "length", "content_filter").
+	FinishReason string `json:"finishReason"`
+
+	Usage Usage `json:"usage"`
+}
+
+// Client defines an OpenAI compatible API client.
+type Client struct {
+	// BaseURL is the API base url (default to [DefaultBaseURL]).
+	BaseURL string
+
+	// APIKey is the optional API key sent as Bearer token.
+	APIKey string
+
+	// CompletionModel is the default chat completions model
+	// (default to [DefaultCompletionModel]).
+	CompletionModel string
+
+	// EmbeddingModel is the default embeddings model
+	// (default to [DefaultEmbeddingModel]).
+	EmbeddingModel string
+
+	// HTTPClient is an optional custom HTTP client (default to [http.DefaultClient]).
+	HTTPClient *http.Client
+}
+
+// Embeddings generates the embedding vectors of the provided texts
+// (the returned vectors are in the same order as the texts).
+func (c *Client) Embeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return [][]float64{}, nil
+	}
+
+	model := c.EmbeddingModel
+	if model == "" {
+		model = DefaultEmbeddingModel
+	}
+
+	body := map[string]any{
+		"model": model,
+		"input": texts,
+	}
+
+	result := struct {
+		Data []struct {
+			Embedding []float64 `json:"embedding"`
+			Index     int       `json:"index"`
+		} `json:"data"`
+	}{}
+
+	if err := c.send(ctx, "/embeddings", body, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(result.Data))
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(vectors) {
+			return nil, fmt.Errorf("invalid embedding index %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+
+	return vectors, nil
+}
+
+// Embedding generates the embedding vector of a single text.
+func (c *Client) Embedding(ctx context.Context, text string) ([]float64, error) {
+	vectors, err := c.Embeddings(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+
+	return vectors[0], nil
+}
+
+// Complete generates a chat completion for the provided request.
+func (c *Client) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	if req == nil || len(req.Messages) == 0 {
+		return nil, errors.New("at least one completion message is required")
+	}
+
+	model := req.Model
+	if model == "" {
+		model = c.CompletionModel
+	}
+	if model == "" {
+		model = DefaultCompletionModel
+	}
+
+	body := map[string]any{
+		"model":    model,
+		"messages": req.Messages,
+	}
+
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+
+	if req.MaxTokens > 0 {
+		body["max_tokens"] = req.MaxTokens
+	}
+
+	if req.JSON {
+		body["response_format"] = map[string]any{"type": "json_object"}
+	}
+
+	result := struct {
+		Choices []struct {
+			Message      Message `json:"message"`
+			FinishReason string  `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
+	}{}
+
+	if err := c.send(ctx, "/chat/completions", body, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Choices) == 0 {
+		return nil, errors.New("missing completion choices")
+	}
+
+	return &CompletionResponse{
+		Content:      result.Choices[0].Message.Content,
+		FinishReason: result.Choices[0].FinishReason,
+		Usage: Usage{
+			PromptTokens:     result.Usage.PromptTokens,
+			CompletionTokens: result.Usage.CompletionTokens,
+			TotalTokens:      result.Usage.TotalTokens,
+		},
+	}, nil
+}
+
+func (c *Client) send(ctx context.Context, path string, body any, result any) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+path, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("POST %s failed with status %d: %s", req.URL.Path, res.StatusCode, msg)
+	}
+
+	return json.NewDecoder(res.Body).Decode(result)
+}
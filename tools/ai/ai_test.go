@@ -0,0 +1,227 @@
+package ai_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/ai"
+)
+
+func newTestServer(t testing.TB, status int, response string) (*httptest.Server, *[]*http.Request, *[]string) {
+	requests := []*http.Request{}
+	bodies := []string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		requests = append(requests, r.Clone(context.Background()))
+		bodies = append(bodies, string(body))
+
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &requests, &bodies
+}
+
+func TestClientEmbeddings(t *testing.T) {
+	t.Parallel()
+
+	server, requests, bodies := newTestServer(t, 200, `{"data":[
+		{"index":1,"embedding":[0.3,0.4]},
+		{"index":0,"embedding":[0.1,0.2]}
+	]}`)
+
+	client := &ai.Client{BaseURL: server.URL + "/", APIKey: "test"}
+
+	vectors, err := client.Embeddings(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vectors) != 2 || !slices.Equal(vectors[0], []float64{0.1, 0.2}) || !slices.Equal(vectors[1], []float64{0.3, 0.4}) {
+		t.Fatalf("Expected the vectors to be ordered by index, got %v", vectors)
+	}
+
+	req := (*requests)[0]
+	if req.URL.Path != "/embeddings" {
+		t.Fatalf("Unexpected request path %s", req.URL.Path)
+	}
+	if v := req.Header.Get("Authorization"); v != "Bearer test" {
+		t.Fatalf("Expected Bearer authorization header, got %q", v)
+	}
+
+	expectedBody := `{"input":["a","b"],"model":"text-embedding-3-small"}`
+	if (*bodies)[0] != expectedBody {
+		t.Fatalf("Expected body\n%s\ngot\n%s", expectedBody, (*bodies)[0])
+	}
+}
+
+func TestClientEmbeddingsMismatch(t *testing.T) {
+	t.Parallel()
+
+	server, _, _ := newTestServer(t, 200, `{"data":[{"index":0,"embedding":[0.1]}]}`)
+
+	client := &ai.Client{BaseURL: server.URL}
+
+	_, err := client.Embeddings(context.Background(), []string{"a", "b"})
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
+
+func TestClientEmbeddingsEmpty(t *testing.T) {
+	t.Parallel()
+
+	server, requests, _ := newTestServer(t, 200, `{}`)
+
+	client := &ai.Client{BaseURL: server.URL}
+
+	vectors, err := client.Embeddings(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vectors) != 0 || len(*requests) != 0 {
+		t.Fatalf("Expected no vectors and requests, got %v (%d requests)", vectors, len(*requests))
+	}
+}
+
+func TestClientEmbedding(t *testing.T) {
+	t.Parallel()
+
+	server, _, bodies := newTestServer(t, 200, `{"data":[{"index":0,"embedding":[0.1,0.2]}]}`)
+
+	client := &ai.Client{BaseURL: server.URL, EmbeddingModel: "custom"}
+
+	vector, err := client.Embedding(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(vector, []float64{0.1, 0.2}) {
+		t.Fatalf("Unexpected vector %v", vector)
+	}
+
+	if !strings.Contains((*bodies)[0], `"model":"custom"`) {
+		t.Fatalf("Expected the custom model, got %s", (*bodies)[0])
+	}
+}
+
+func TestClientComplete(t *testing.T) {
+	t.Parallel()
+
+	response := `{
+		"choices":[{"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}],
+		"usage":{"prompt_tokens":1,"completion_tokens":2,"total_tokens":3}
+	}`
+
+	temperature := 0.5
+
+	scenarios := []struct {
+		name         string
+		client       *ai.Client
+		req          *ai.CompletionRequest
+		expectError  bool
+		expectedBody string
+	}{
+		{
+			"nil request",
+			&ai.Client{},
+			nil,
+			true,
+			"",
+		},
+		{
+			"no messages",
+			&ai.Client{},
+			&ai.CompletionRequest{},
+			true,
+			"",
+		},
+		{
+			"default model",
+			&ai.Client{},
+			&ai.CompletionRequest{Messages: []ai.Message{{Role: ai.RoleUser, Content: "hi"}}},
+			false,
+			`{"messages":[{"role":"user","content":"hi"}],"model":"gpt-4o-mini"}`,
+		},
+		{
+			"client model",
+			&ai.Client{CompletionModel: "client"},
+			&ai.CompletionRequest{Messages: []ai.Message{{Role: ai.RoleUser, Content: "hi"}}},
+			false,
+			`{"messages":[{"role":"user","content":"hi"}],"model":"client"}`,
+		},
+		{
+			"all options",
+			&ai.Client{CompletionModel: "client"},
+			&ai.CompletionRequest{
+				Model:       "request",
+				Messages:    []ai.Message{{Role: ai.RoleSystem, Content: "sys"}, {Role: ai.RoleUser, Content: "hi"}},
+				Temperature: &temperature,
+				MaxTokens:   10,
+				JSON:        true,
+			},
+			false,
+			`{"max_tokens":10,"messages":[{"role":"system","content":"sys"},{"role":"user","content":"hi"}],"model":"request","response_format":{"type":"json_object"},"temperature":0.5}`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			server, requests, bodies := newTestServer(t, 200, response)
+
+			s.client.BaseURL = server.URL
+
+			res, err := s.client.Complete(context.Background(), s.req)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				if len(*requests) != 0 {
+					t.Fatalf("Expected no requests, got %d", len(*requests))
+				}
+				return
+			}
+
+			if path := (*requests)[0].URL.Path; path != "/chat/completions" {
+				t.Fatalf("Unexpected request path %s", path)
+			}
+
+			if (*bodies)[0] != s.expectedBody {
+				t.Fatalf("Expected body\n%s\ngot\n%s", s.expectedBody, (*bodies)[0])
+			}
+
+			if res.Content != "hello" || res.FinishReason != "stop" || res.Usage.TotalTokens != 3 {
+				t.Fatalf("Unexpected response %#v", res)
+			}
+		})
+	}
+}
+
+func TestClientErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	server, _, _ := newTestServer(t, 401, `{"error":{"message":"invalid key"}}`)
+
+	client := &ai.Client{BaseURL: server.URL}
+
+	_, err := client.Embedding(context.Background(), "a")
+	if err == nil || !strings.Contains(err.Error(), "status 401") || !strings.Contains(err.Error(), "invalid key") {
+		t.Fatalf("Expected status 401 error, got %v", err)
+	}
+}
@@ -7,11 +7,15 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"regexp"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/pocketbase/pocketbase/tools/inflector"
@@ -123,6 +127,150 @@ func NewFileFromURL(ctx context.Context, url string) (*File, error) {
 	return NewFileFromBytes(buf.Bytes(), path.Base(url))
 }
 
+// ErrRemoteFileTooLarge is returned by [NewFileFromRemoteURL] when
+// the remote file exceeds the configured max size.
+var ErrRemoteFileTooLarge = errors.New("the remote file exceeds the max allowed size")
+
+// ErrRemoteAddressNotAllowed is returned by [NewFileFromRemoteURL] when
+// the remote url resolves to a loopback, private or other non-public address.
+var ErrRemoteAddressNotAllowed = errors.New("the remote url address is not allowed")
+
+// RemoteFileOptions defines the [NewFileFromRemoteURL] download options.
+type RemoteFileOptions struct {
+	// MaxSize specifies the max allowed file size in bytes (0 or negative means no limit).
+	MaxSize int64
+
+	// Timeout specifies the max duration of the download request (default to 30s).
+	Timeout time.Duration
+
+	// AllowPrivateNetworks disables the loopback, private, link-local
+	// and unspecified addresses restriction (e.g. for internal services).
+	AllowPrivateNetworks bool
+}
+
+// NewFileFromRemoteURL is similar to [NewFileFromURL] but is intended
+// for untrusted user submitted urls.
+//
+// It allows only http and https urls, limits the number of redirects and
+// the downloaded file size, and by default rejects connections to
+// non-public addresses (the check is performed on the resolved IP
+// addresses so it also applies for redirects and DNS rebinding).
+func NewFileFromRemoteURL(ctx context.Context, rawURL string, opts RemoteFileOptions) (*File, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported remote url scheme %q", u.Scheme)
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dialer := &net.Dialer{Timeout: timeout}
+	if !opts.AllowPrivateNetworks {
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if !isPublicIP(net.ParseIP(host)) {
+				return ErrRemoteAddressNotAllowed
+			}
+
+			return nil
+		}
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			// note: proxies are intentionally not used so that the address check cannot be bypassed
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("unsupported redirect url scheme %q", req.URL.Scheme)
+			}
+
+			return nil
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrRemoteAddressNotAllowed) {
+			return nil, ErrRemoteAddressNotAllowed
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to download remote url %s (%d)", u.Redacted(), res.StatusCode)
+	}
+
+	var reader io.Reader = res.Body
+
+	if opts.MaxSize > 0 {
+		if res.ContentLength > opts.MaxSize {
+			return nil, ErrRemoteFileTooLarge
+		}
+
+		reader = io.LimitReader(res.Body, opts.MaxSize+1)
+	}
+
+	var buf bytes.Buffer
+
+	if _, err = io.Copy(&buf, reader); err != nil {
+		return nil, err
+	}
+
+	if opts.MaxSize > 0 && int64(buf.Len()) > opts.MaxSize {
+		return nil, ErrRemoteFileTooLarge
+	}
+
+	name := path.Base(u.Path)
+	if name == "" || name == "." || name == "/" {
+		name = "file"
+	}
+
+	return NewFileFromBytes(buf.Bytes(), name)
+}
+
+// isPublicIP reports whether the provided ip is a public unicast address.
+func isPublicIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast()
+}
+
 // -------------------------------------------------------------------
 
 var _ FileReader = (*MultipartReader)(nil)
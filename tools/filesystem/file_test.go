@@ -2,6 +2,7 @@ package filesystem_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -202,6 +203,120 @@ func TestNewFileFromURLTimeout(t *testing.T) {
 	}
 }
 
+func TestNewFileFromRemoteURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/redirect":
+			http.Redirect(w, r, "/test.txt", http.StatusFound)
+			return
+		case "/redirect-file":
+			http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+			return
+		}
+
+		fmt.Fprintf(w, "test")
+	}))
+	defer srv.Close()
+
+	scenarios := []struct {
+		name         string
+		url          string
+		opts         filesystem.RemoteFileOptions
+		expectedErr  error
+		expectError  bool
+		expectedName string
+	}{
+		{
+			"non-http scheme",
+			"file:///etc/passwd",
+			filesystem.RemoteFileOptions{AllowPrivateNetworks: true},
+			nil,
+			true,
+			"",
+		},
+		{
+			"loopback address",
+			srv.URL + "/test.txt",
+			filesystem.RemoteFileOptions{},
+			filesystem.ErrRemoteAddressNotAllowed,
+			true,
+			"",
+		},
+		{
+			"error response",
+			srv.URL + "/error",
+			filesystem.RemoteFileOptions{AllowPrivateNetworks: true},
+			nil,
+			true,
+			"",
+		},
+		{
+			"non-http redirect",
+			srv.URL + "/redirect-file",
+			filesystem.RemoteFileOptions{AllowPrivateNetworks: true},
+			nil,
+			true,
+			"",
+		},
+		{
+			"exceeding max size",
+			srv.URL + "/test.txt",
+			filesystem.RemoteFileOptions{AllowPrivateNetworks: true, MaxSize: 3},
+			filesystem.ErrRemoteFileTooLarge,
+			true,
+			"",
+		},
+		{
+			"valid",
+			srv.URL + "/test.txt",
+			filesystem.RemoteFileOptions{AllowPrivateNetworks: true, MaxSize: 4},
+			nil,
+			false,
+			"test.txt",
+		},
+		{
+			"valid redirect",
+			srv.URL + "/redirect",
+			filesystem.RemoteFileOptions{AllowPrivateNetworks: true},
+			nil,
+			false,
+			"redirect",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			f, err := filesystem.NewFileFromRemoteURL(context.Background(), s.url, s.opts)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if s.expectedErr != nil && !errors.Is(err, s.expectedErr) {
+				t.Fatalf("Expected error %v, got %v", s.expectedErr, err)
+			}
+
+			if hasErr {
+				if f != nil {
+					t.Fatalf("Expected nil file, got %v", f)
+				}
+				return
+			}
+
+			if f.OriginalName != s.expectedName {
+				t.Fatalf("Expected OriginalName %q, got %q", s.expectedName, f.OriginalName)
+			}
+
+			if f.Size != 4 {
+				t.Fatalf("Expected Size %d, got %d", 4, f.Size)
+			}
+		})
+	}
+}
+
 func TestFileNameNormalizations(t *testing.T) {
 	scenarios := []struct {
 		name    string
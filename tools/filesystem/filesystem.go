@@ -5,6 +5,7 @@ import (
 	"errors"
 	"image"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
 	"os"
@@ -107,6 +108,14 @@ func NewLocal(dirPath string) (*System, error) {
 	return &System{ctx: ctx, bucket: bucket}, nil
 }
 
+// NewFromBucket initializes a filesystem instance from an already
+// opened gocloud blob bucket (eg. memblob, gcsblob, azureblob, etc.).
+//
+// NB! The returned filesystem takes ownership of the bucket and closes it on `Close()`.
+func NewFromBucket(bucket *blob.Bucket) *System {
+	return &System{ctx: context.Background(), bucket: bucket}
+}
+
 // SetContext assigns the specified context to the current filesystem.
 func (s *System) SetContext(ctx context.Context) {
 	s.ctx = ctx
@@ -538,8 +547,22 @@ func (s *System) CreateThumb(originalKey string, thumbKey, thumbSize string) err
 		}
 	}
 
+	// try to detect the thumb format based on the thumb file name
+	// (fallbacks to png on error)
+	format, err := imaging.FormatFromFilename(thumbKey)
+	if err != nil {
+		format = imaging.PNG
+	}
+
+	// use the thumb extension content type in case it differs from the original
+	// (e.g. when the thumb is converted to a different format)
+	contentType := mime.TypeByExtension(filepath.Ext(thumbKey))
+	if contentType == "" {
+		contentType = r.ContentType()
+	}
+
 	opts := &blob.WriterOptions{
-		ContentType: r.ContentType(),
+		ContentType: contentType,
 	}
 
 	// open a thumb storage writer (aka. prepare for upload)
@@ -548,13 +571,6 @@ func (s *System) CreateThumb(originalKey string, thumbKey, thumbSize string) err
 		return writerErr
 	}
 
-	// try to detect the thumb format based on the original file name
-	// (fallbacks to png on error)
-	format, err := imaging.FormatFromFilename(thumbKey)
-	if err != nil {
-		format = imaging.PNG
-	}
-
 	// thumb encode (aka. upload)
 	if err := imaging.Encode(w, thumbImg, format); err != nil {
 		w.Close()
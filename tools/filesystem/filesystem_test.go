@@ -14,8 +14,27 @@ import (
 	"testing"
 
 	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"gocloud.dev/blob/memblob"
 )
 
+func TestNewFromBucket(t *testing.T) {
+	fsys := filesystem.NewFromBucket(memblob.OpenBucket(nil))
+	defer fsys.Close()
+
+	if err := fsys.Upload([]byte("test"), "a/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	exists, err := fsys.Exists("a/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !exists {
+		t.Fatal("Expected the uploaded file to exist")
+	}
+}
+
 func TestFileSystemExists(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
@@ -3,6 +3,7 @@ package hook
 import (
 	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/pocketbase/pocketbase/tools/security"
 )
@@ -29,6 +30,10 @@ type Handler[T Resolver] struct {
 	//
 	// If 0, the handler will be executed in the same order it was registered.
 	Priority int
+
+	// source is the original handler function pointer used for the metrics
+	// (it is different from Func when the handler is wrapped, e.g. by [TaggedHook]).
+	source uintptr
 }
 
 // Hook defines a generic concurrent safe structure for managing event hooks.
@@ -54,8 +59,15 @@ type Handler[T Resolver] struct {
 type Hook[T Resolver] struct {
 	handlers []*Handler[T]
 	mu       sync.RWMutex
+
+	// id is the hook instance identifier used for the handlers metrics
+	// (it is assigned on the first Bind call).
+	id uint64
 }
 
+// lastHookId is the last assigned [Hook] instance id.
+var lastHookId atomic.Uint64
+
 // Bind registers the provided handler to the current hooks queue.
 //
 // If handler.Id is empty it is updated with autogenerated value.
@@ -68,6 +80,14 @@ func (h *Hook[T]) Bind(handler *Handler[T]) string {
 
 	var exists bool
 
+	if h.id == 0 {
+		h.id = lastHookId.Add(1)
+	}
+
+	if handler.source == 0 {
+		handler.source = funcPointer(handler.Func)
+	}
+
 	if handler.Id == "" {
 		handler.Id = generateHookId()
 
@@ -85,6 +105,7 @@ func (h *Hook[T]) Bind(handler *Handler[T]) string {
 			if existing.Id == handler.Id {
 				h.handlers[i] = handler
 				exists = true
+				DefaultMetrics.remove(h.id, handler.Id)
 				break
 			}
 		}
@@ -120,6 +141,7 @@ func (h *Hook[T]) Unbind(id string) {
 	for i := len(h.handlers) - 1; i >= 0; i-- {
 		if h.handlers[i].Id == id {
 			h.handlers = append(h.handlers[:i], h.handlers[i+1:]...)
+			DefaultMetrics.remove(h.id, id)
 			break // for now stop on the first occurrence since we don't allow handlers with duplicated ids
 		}
 	}
@@ -131,6 +153,8 @@ func (h *Hook[T]) UnbindAll() {
 	defer h.mu.Unlock()
 
 	h.handlers = nil
+
+	DefaultMetrics.remove(h.id, "")
 }
 
 // Length returns to total number of registered hook handlers.
@@ -149,12 +173,34 @@ func (h *Hook[T]) Length() int {
 //
 // NB! Each hook handler must call event.Next() in order the hook chain to proceed.
 func (h *Hook[T]) Trigger(event T, oneOffHandlerFuncs ...func(T) error) error {
+	metrics := DefaultMetrics
+	if !metrics.Enabled() {
+		metrics = nil
+	}
+
+	var tracker *chainTracker
+	if metrics != nil {
+		tracker = &chainTracker{}
+	}
+
 	h.mu.RLock()
 	handlers := make([]func(T) error, 0, len(h.handlers)+len(oneOffHandlerFuncs))
 	for _, handler := range h.handlers {
-		handlers = append(handlers, handler.Func)
+		if tracker != nil {
+			key := metricsKey{hook: h.id, id: handler.Id, source: handler.source}
+			handlers = append(handlers, trackHandler(tracker, metrics, key, handler.Func))
+		} else {
+			handlers = append(handlers, handler.Func)
+		}
+	}
+	for _, fn := range oneOffHandlerFuncs {
+		if tracker != nil {
+			// tracked only for the "self" time calculation of the previous handler
+			handlers = append(handlers, trackHandler(tracker, metrics, metricsKey{}, fn))
+		} else {
+			handlers = append(handlers, fn)
+		}
 	}
-	handlers = append(handlers, oneOffHandlerFuncs...)
 	h.mu.RUnlock()
 
 	event.setNextFunc(nil) // reset in case the event is being reused
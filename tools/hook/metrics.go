@@ -0,0 +1,242 @@
+package hook
+
+import (
+	"cmp"
+	"errors"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMetrics is the default hook handlers metrics collector
+// used by all [Hook] instances.
+//
+// It is disabled by default because of the extra tracking overhead
+// on every hook trigger and could be enabled with [EnableMetrics].
+var DefaultMetrics = NewMetrics()
+
+// EnableMetrics enables the [DefaultMetrics] handlers metrics collecting.
+func EnableMetrics() {
+	DefaultMetrics.Enable()
+}
+
+// DisableMetrics disables the [DefaultMetrics] handlers metrics collecting
+// (the already collected stats are preserved).
+func DisableMetrics() {
+	DefaultMetrics.Disable()
+}
+
+// HandlerStats defines the execution stats of a single hook handler.
+//
+// The durations are the "self" handler execution time, aka. without
+// the time spent in the next handlers of the chain.
+type HandlerStats struct {
+	// HookId is the unique identifier of the [Hook] instance
+	// the handler is bound to.
+	HookId uint64 `json:"hookId"`
+
+	// Id is the hook handler id.
+	Id string `json:"id"`
+
+	// Func is the fully qualified name of the handler function.
+	Func string `json:"func"`
+
+	// Source is the handler function definition location in "file:line" format.
+	Source string `json:"source"`
+
+	// Calls is the total number of the handler executions.
+	Calls int64 `json:"calls"`
+
+	// Errors is the total number of the errors returned by the handler
+	// (the errors returned by the next handlers of the chain are not counted).
+	Errors int64 `json:"errors"`
+
+	// TotalTime is the total execution time of the handler (in nanoseconds when serialized).
+	TotalTime time.Duration `json:"totalTime"`
+
+	// AvgTime is the average execution time of the handler (in nanoseconds when serialized).
+	AvgTime time.Duration `json:"avgTime"`
+
+	// MaxTime is the slowest execution time of the handler (in nanoseconds when serialized).
+	MaxTime time.Duration `json:"maxTime"`
+}
+
+// Metrics defines a concurrent safe hook handlers metrics collector.
+type Metrics struct {
+	entries sync.Map // map[metricsKey]*metricsEntry
+	enabled atomic.Bool
+}
+
+type metricsKey struct {
+	hook   uint64
+	id     string
+	source uintptr
+}
+
+type metricsEntry struct {
+	calls     atomic.Int64
+	errors    atomic.Int64
+	totalTime atomic.Int64
+	maxTime   atomic.Int64
+}
+
+// NewMetrics creates a new disabled hook handlers [Metrics] collector.
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+// Enable enables the handlers metrics collecting.
+func (m *Metrics) Enable() {
+	m.enabled.Store(true)
+}
+
+// Disable disables the handlers metrics collecting
+// (the already collected stats are preserved).
+func (m *Metrics) Disable() {
+	m.enabled.Store(false)
+}
+
+// Enabled reports whether the handlers metrics collecting is enabled.
+func (m *Metrics) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// Reset clears all collected stats.
+func (m *Metrics) Reset() {
+	m.entries.Clear()
+}
+
+// Stats returns a snapshot of the collected handlers stats
+// sorted by their total execution time (slowest first).
+func (m *Metrics) Stats() []*HandlerStats {
+	result := []*HandlerStats{}
+
+	m.entries.Range(func(k, v any) bool {
+		key := k.(metricsKey)
+		entry := v.(*metricsEntry)
+
+		stats := &HandlerStats{
+			HookId:    key.hook,
+			Id:        key.id,
+			Calls:     entry.calls.Load(),
+			Errors:    entry.errors.Load(),
+			TotalTime: time.Duration(entry.totalTime.Load()),
+			MaxTime:   time.Duration(entry.maxTime.Load()),
+		}
+
+		if stats.Calls > 0 {
+			stats.AvgTime = stats.TotalTime / time.Duration(stats.Calls)
+		}
+
+		if fn := runtime.FuncForPC(key.source); fn != nil {
+			stats.Func = fn.Name()
+			file, line := fn.FileLine(key.source)
+			stats.Source = file + ":" + strconv.Itoa(line)
+		}
+
+		result = append(result, stats)
+
+		return true
+	})
+
+	slices.SortFunc(result, func(a, b *HandlerStats) int {
+		if c := cmp.Compare(b.TotalTime, a.TotalTime); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Calls, a.Calls)
+	})
+
+	return result
+}
+
+// remove deletes the collected stats of the hook handlers matching
+// the provided hook id and handler id (or all hook handlers if handlerId is empty).
+func (m *Metrics) remove(hookId uint64, handlerId string) {
+	if hookId == 0 {
+		return // not bound yet
+	}
+
+	m.entries.Range(func(k, _ any) bool {
+		key := k.(metricsKey)
+		if key.hook == hookId && (handlerId == "" || key.id == handlerId) {
+			m.entries.Delete(k)
+		}
+		return true
+	})
+}
+
+func (m *Metrics) record(key metricsKey, duration time.Duration, isErr bool) {
+	v, ok := m.entries.Load(key)
+	if !ok {
+		v, _ = m.entries.LoadOrStore(key, &metricsEntry{})
+	}
+
+	entry := v.(*metricsEntry)
+
+	entry.calls.Add(1)
+	entry.totalTime.Add(int64(duration))
+
+	if isErr {
+		entry.errors.Add(1)
+	}
+
+	for {
+		current := entry.maxTime.Load()
+		if int64(duration) <= current || entry.maxTime.CompareAndSwap(current, int64(duration)) {
+			break
+		}
+	}
+}
+
+// chainTracker tracks the "self" execution time and errors
+// of the handlers of a single hook chain execution.
+type chainTracker struct {
+	// lastTime is the inclusive execution time of the last completed handler
+	lastTime atomic.Int64
+
+	// lastErr is the error returned by the last completed handler
+	lastErr atomic.Pointer[error]
+}
+
+// trackHandler wraps the provided handler function so that its execution
+// is recorded in m under key (if the key source is not set, the
+// execution is only tracked for the calculation of the previous handler stats).
+func trackHandler[T Resolver](t *chainTracker, m *Metrics, key metricsKey, fn func(T) error) func(T) error {
+	return func(e T) error {
+		t.lastTime.Store(0)
+		t.lastErr.Store(nil)
+
+		start := time.Now()
+		err := fn(e)
+		total := time.Since(start)
+
+		if key.source != 0 {
+			var nextErr error
+			if ptr := t.lastErr.Load(); ptr != nil {
+				nextErr = *ptr
+			}
+
+			isErr := err != nil && (nextErr == nil || !errors.Is(err, nextErr))
+
+			m.record(key, total-time.Duration(t.lastTime.Load()), isErr)
+		}
+
+		t.lastTime.Store(int64(total))
+		t.lastErr.Store(&err)
+
+		return err
+	}
+}
+
+func funcPointer(fn any) uintptr {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return 0
+	}
+
+	return v.Pointer()
+}
@@ -0,0 +1,229 @@
+package hook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	EnableMetrics()
+	defer DisableMetrics()
+
+	DefaultMetrics.Reset()
+	defer DefaultMetrics.Reset()
+
+	testErr := errors.New("test")
+
+	h := Hook[*Event]{}
+	h.Bind(&Handler[*Event]{
+		Id: "metrics_a",
+		Func: func(e *Event) error {
+			time.Sleep(5 * time.Millisecond)
+			return e.Next()
+		},
+	})
+	h.Bind(&Handler[*Event]{
+		Id:   "metrics_b",
+		Func: testMetricsHandler,
+	})
+
+	if err := h.Trigger(&Event{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// one-off handler error should be attributed to none of the handlers
+	h.Trigger(&Event{}, func(e *Event) error {
+		time.Sleep(5 * time.Millisecond)
+		return testErr
+	})
+
+	stats := map[string]*HandlerStats{}
+	for _, s := range DefaultMetrics.Stats() {
+		stats[s.Id] = s
+	}
+
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 handlers stats, got %d", len(stats))
+	}
+
+	a := stats["metrics_a"]
+	if a.Calls != 2 || a.Errors != 0 {
+		t.Fatalf("Expected 2 calls and 0 errors for handler a, got %d and %d", a.Calls, a.Errors)
+	}
+	if a.MaxTime < 5*time.Millisecond || a.AvgTime < 5*time.Millisecond {
+		t.Fatalf("Expected handler a max and avg time >= 5ms, got %v and %v", a.MaxTime, a.AvgTime)
+	}
+	if !strings.HasSuffix(a.Func, "TestMetrics.func1") || !strings.Contains(a.Source, "metrics_test.go:") {
+		t.Fatalf("Unexpected handler a func %q or source %q", a.Func, a.Source)
+	}
+
+	b := stats["metrics_b"]
+	if b.Calls != 2 || b.Errors != 0 {
+		t.Fatalf("Expected 2 calls and 0 errors for handler b, got %d and %d", b.Calls, b.Errors)
+	}
+	if b.MaxTime >= 5*time.Millisecond {
+		t.Fatalf("Expected handler b self time to exclude the next handlers time, got %v", b.MaxTime)
+	}
+	if !strings.HasSuffix(b.Func, "testMetricsHandler") {
+		t.Fatalf("Unexpected handler b func %q", b.Func)
+	}
+
+	if stats := DefaultMetrics.Stats(); stats[0].Id != "metrics_a" {
+		t.Fatalf("Expected the slowest handler to be first, got %q", stats[0].Id)
+	}
+}
+
+func TestMetricsErrors(t *testing.T) {
+	EnableMetrics()
+	defer DisableMetrics()
+
+	DefaultMetrics.Reset()
+	defer DefaultMetrics.Reset()
+
+	testErr := errors.New("test")
+
+	h := Hook[*Event]{}
+	h.Bind(&Handler[*Event]{
+		Id: "metrics_a",
+		Func: func(e *Event) error {
+			e.Next()
+			return testErr
+		},
+	})
+	h.Bind(&Handler[*Event]{
+		Id: "metrics_b",
+		Func: func(e *Event) error {
+			return e.Next()
+		},
+	})
+	h.Bind(&Handler[*Event]{
+		Id: "metrics_c",
+		Func: func(e *Event) error {
+			return testErr
+		},
+	})
+
+	h.Trigger(&Event{})
+
+	expected := map[string]int64{"metrics_a": 0, "metrics_b": 0, "metrics_c": 1}
+
+	for _, s := range DefaultMetrics.Stats() {
+		if s.Errors != expected[s.Id] {
+			t.Fatalf("Expected %d errors for %q, got %d", expected[s.Id], s.Id, s.Errors)
+		}
+	}
+}
+
+func TestMetricsDisabledByDefault(t *testing.T) {
+	DefaultMetrics.Reset()
+	defer DefaultMetrics.Reset()
+
+	if DefaultMetrics.Enabled() {
+		t.Fatal("Expected the default metrics to be disabled")
+	}
+
+	h := Hook[*Event]{}
+	h.BindFunc(func(e *Event) error {
+		return e.Next()
+	})
+	h.Trigger(&Event{})
+
+	if total := len(DefaultMetrics.Stats()); total != 0 {
+		t.Fatalf("Expected no stats, got %d", total)
+	}
+}
+
+func TestMetricsPerHook(t *testing.T) {
+	EnableMetrics()
+	defer DisableMetrics()
+
+	DefaultMetrics.Reset()
+	defer DefaultMetrics.Reset()
+
+	h1 := Hook[*Event]{}
+	h1.Bind(&Handler[*Event]{Id: "metrics_shared", Func: testMetricsHandler})
+
+	h2 := Hook[*Event]{}
+	h2.Bind(&Handler[*Event]{Id: "metrics_shared", Func: testMetricsHandler})
+
+	h1.Trigger(&Event{})
+	h1.Trigger(&Event{})
+	h2.Trigger(&Event{})
+
+	calls := map[uint64]int64{}
+	for _, s := range DefaultMetrics.Stats() {
+		calls[s.HookId] = s.Calls
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("Expected separate stats for each hook, got %v", calls)
+	}
+
+	if calls[h1.id] != 2 || calls[h2.id] != 1 {
+		t.Fatalf("Expected 2 calls for h1 and 1 call for h2, got %v", calls)
+	}
+}
+
+func TestMetricsUnbind(t *testing.T) {
+	EnableMetrics()
+	defer DisableMetrics()
+
+	DefaultMetrics.Reset()
+	defer DefaultMetrics.Reset()
+
+	h := Hook[*Event]{}
+	h.Bind(&Handler[*Event]{Id: "metrics_a", Func: testMetricsHandler})
+	h.Bind(&Handler[*Event]{Id: "metrics_b", Func: testMetricsHandler})
+	h.Bind(&Handler[*Event]{Id: "metrics_c", Func: testMetricsHandler})
+	h.Trigger(&Event{})
+
+	// replacing a handler should drop the stats of the old one
+	h.Bind(&Handler[*Event]{Id: "metrics_c", Func: func(e *Event) error { return e.Next() }})
+
+	h.Unbind("metrics_a")
+
+	ids := []string{}
+	for _, s := range DefaultMetrics.Stats() {
+		ids = append(ids, s.Id)
+	}
+	if len(ids) != 1 || ids[0] != "metrics_b" {
+		t.Fatalf("Expected only metrics_b stats, got %v", ids)
+	}
+
+	h.UnbindAll()
+
+	if total := len(DefaultMetrics.Stats()); total != 0 {
+		t.Fatalf("Expected no stats after UnbindAll, got %d", total)
+	}
+}
+
+func TestTaggedHookMetricsSource(t *testing.T) {
+	EnableMetrics()
+	defer DisableMetrics()
+
+	DefaultMetrics.Reset()
+	defer DefaultMetrics.Reset()
+
+	h := NewTaggedHook(&Hook[*mockTagsEvent]{})
+	h.BindFunc(testTaggedMetricsHandler)
+	h.Trigger(&mockTagsEvent{})
+
+	stats := DefaultMetrics.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("Expected 1 handler stats, got %d", len(stats))
+	}
+
+	if !strings.HasSuffix(stats[0].Func, "testTaggedMetricsHandler") {
+		t.Fatalf("Expected the original handler func, got %q", stats[0].Func)
+	}
+}
+
+func testMetricsHandler(e *Event) error {
+	return e.Next()
+}
+
+func testTaggedMetricsHandler(e *mockTagsEvent) error {
+	return e.Next()
+}
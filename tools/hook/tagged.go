@@ -58,6 +58,10 @@ func (h *TaggedHook[T]) CanTriggerOn(tagsToCheck []string) bool {
 func (h *TaggedHook[T]) Bind(handler *Handler[T]) string {
 	fn := handler.Func
 
+	if handler.source == 0 {
+		handler.source = funcPointer(fn)
+	}
+
 	handler.Func = func(e T) error {
 		if h.CanTriggerOn(e.Tags()) {
 			return fn(e)
@@ -74,11 +78,5 @@ func (h *TaggedHook[T]) Bind(handler *Handler[T]) string {
 // It is similar to [Hook.Bind] with the difference that the handler
 // function is invoked only if the event data tags satisfy h.CanTriggerOn.
 func (h *TaggedHook[T]) BindFunc(fn func(e T) error) string {
-	return h.mainHook.BindFunc(func(e T) error {
-		if h.CanTriggerOn(e.Tags()) {
-			return fn(e)
-		}
-
-		return e.Next()
-	})
+	return h.Bind(&Handler[T]{Func: fn})
 }
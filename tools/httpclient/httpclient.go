@@ -0,0 +1,343 @@
+// Package httpclient implements a small gateway layer for the outgoing
+// HTTP requests to external services (SMS gateways, webhooks, log collectors, etc.).
+//
+// The [Client] interface is implemented by the standard [http.Client],
+// by the default [Gateway] (retries with exponential backoff, circuit
+// breaker and basic metrics) and by the [Mock] test double.
+//
+// Example usage:
+//
+//	client := httpclient.New(httpclient.Options{
+//		Timeout:    10 * time.Second,
+//		MaxRetries: 3,
+//	})
+//
+//	res, err := client.Do(req)
+//
+//	// in tests
+//	mock := httpclient.NewMock(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//		w.Write([]byte(`{"status":"ok"}`))
+//	}))
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the default max duration of a single request attempt.
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultMinBackoff is the default delay before the first retry.
+	DefaultMinBackoff = 200 * time.Millisecond
+
+	// DefaultMaxBackoff is the default max delay between the retries.
+	DefaultMaxBackoff = 5 * time.Second
+
+	// DefaultBreakerCooldown is the default duration for which the
+	// circuit breaker remains open before allowing a trial request.
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned when the request is rejected because
+// of too many consecutive failures.
+var ErrCircuitOpen = errors.New("httpclient: circuit breaker is open")
+
+// Client defines a minimal HTTP client interface.
+//
+// It is implemented by [http.Client], [Gateway] and [Mock].
+type Client interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// ClientFunc is an adapter to allow the use of ordinary functions as [Client].
+type ClientFunc func(req *http.Request) (*http.Response, error)
+
+// Do implements [Client] interface.
+func (f ClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Options defines the [Gateway] client options.
+type Options struct {
+	// Client is the underlying client used to send the requests
+	// (default to a new [http.Client] with the configured Timeout).
+	Client Client
+
+	// Timeout is the max duration of a single request attempt (default to [DefaultTimeout]).
+	//
+	// It is used only if Client is not set.
+	Timeout time.Duration
+
+	// MaxRetries is the max number of retries on network error or
+	// 429, 502, 503, 504 response status (0 means no retries).
+	//
+	// Note that requests with non-replayable body (aka. without GetBody) are never retried.
+	MaxRetries int
+
+	// MinBackoff is the delay before the first retry (default to [DefaultMinBackoff]).
+	//
+	// Each next retry doubles the delay (with a small jitter) up to MaxBackoff.
+	MinBackoff time.Duration
+
+	// MaxBackoff is the max delay between the retries (default to [DefaultMaxBackoff]).
+	MaxBackoff time.Duration
+
+	// BreakerThreshold is the number of consecutive failed requests
+	// after which the circuit breaker opens (0 disables the circuit breaker).
+	BreakerThreshold int
+
+	// BreakerCooldown is the duration for which the circuit breaker remains
+	// open before allowing a trial request (default to [DefaultBreakerCooldown]).
+	BreakerCooldown time.Duration
+}
+
+// Stats defines the [Gateway] client metrics.
+type Stats struct {
+	// Requests is the total number of Do calls.
+	Requests int64 `json:"requests"`
+
+	// Attempts is the total number of sent request attempts (including the retries).
+	Attempts int64 `json:"attempts"`
+
+	// Retries is the total number of retried attempts.
+	Retries int64 `json:"retries"`
+
+	// Failures is the total number of failed requests after all retries
+	// (network errors and 5xx response statuses).
+	Failures int64 `json:"failures"`
+
+	// Rejected is the total number of requests rejected by the open circuit breaker.
+	Rejected int64 `json:"rejected"`
+
+	// CircuitOpen indicates whether the circuit breaker is currently open.
+	CircuitOpen bool `json:"circuitOpen"`
+}
+
+// Gateway is the default [Client] implementation with retries,
+// circuit breaker and basic metrics.
+//
+// It is safe for concurrent use.
+type Gateway struct {
+	opts Options
+
+	requests atomic.Int64
+	attempts atomic.Int64
+	retries  atomic.Int64
+	failures atomic.Int64
+	rejected atomic.Int64
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	openUntil           time.Time
+	trialInProgress     bool
+}
+
+// New creates a new [Gateway] client with the provided options.
+func New(opts Options) *Gateway {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: opts.Timeout}
+	}
+
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = DefaultMinBackoff
+	}
+
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+
+	if opts.BreakerCooldown <= 0 {
+		opts.BreakerCooldown = DefaultBreakerCooldown
+	}
+
+	return &Gateway{opts: opts}
+}
+
+// Stats returns a snapshot of the current client metrics.
+func (g *Gateway) Stats() Stats {
+	g.mu.Lock()
+	open := !g.openUntil.IsZero()
+	g.mu.Unlock()
+
+	return Stats{
+		Requests:    g.requests.Load(),
+		Attempts:    g.attempts.Load(),
+		Retries:     g.retries.Load(),
+		Failures:    g.failures.Load(),
+		Rejected:    g.rejected.Load(),
+		CircuitOpen: open,
+	}
+}
+
+// Do implements [Client] interface.
+//
+// The request is retried (if configured) on network error or on
+// 429, 502, 503, 504 response status as long as its context is not done.
+func (g *Gateway) Do(req *http.Request) (*http.Response, error) {
+	g.requests.Add(1)
+
+	if !g.allow() {
+		g.rejected.Add(1)
+		return nil, ErrCircuitOpen
+	}
+
+	res, err := g.do(req)
+
+	success := err == nil && !isServerError(res.StatusCode)
+	if !success {
+		g.failures.Add(1)
+	}
+
+	g.report(success)
+
+	return res, err
+}
+
+func (g *Gateway) do(req *http.Request) (*http.Response, error) {
+	canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		g.attempts.Add(1)
+
+		res, err := g.opts.Client.Do(req)
+
+		if attempt >= g.opts.MaxRetries || !canRetry || !shouldRetry(res, err) {
+			return res, err
+		}
+
+		delay := g.backoff(attempt, res)
+
+		// discard the response so that the connection could be reused
+		if res != nil {
+			io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+			res.Body.Close()
+		}
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+
+		g.retries.Add(1)
+	}
+}
+
+// backoff returns the delay before the next retry attempt.
+func (g *Gateway) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, g.opts.MaxBackoff)
+		}
+	}
+
+	delay := g.opts.MinBackoff << attempt
+	if delay <= 0 || delay > g.opts.MaxBackoff {
+		delay = g.opts.MaxBackoff
+	}
+
+	// up to 20% jitter to avoid synchronized retries
+	jitter := time.Duration(rand.Int64N(int64(delay)/5 + 1))
+
+	return min(delay+jitter, g.opts.MaxBackoff)
+}
+
+// allow reports whether a new request is allowed by the circuit breaker.
+func (g *Gateway) allow() bool {
+	if g.opts.BreakerThreshold <= 0 {
+		return true
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.openUntil.IsZero() {
+		return true // closed
+	}
+
+	// half-open -> allow a single trial request after the cooldown
+	if time.Now().After(g.openUntil) && !g.trialInProgress {
+		g.trialInProgress = true
+		return true
+	}
+
+	return false
+}
+
+// report updates the circuit breaker state with the request result.
+func (g *Gateway) report(success bool) {
+	if g.opts.BreakerThreshold <= 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.trialInProgress = false
+
+	if success {
+		g.consecutiveFailures = 0
+		g.openUntil = time.Time{}
+		return
+	}
+
+	g.consecutiveFailures++
+
+	if g.consecutiveFailures >= g.opts.BreakerThreshold {
+		g.openUntil = time.Now().Add(g.opts.BreakerCooldown)
+	}
+}
+
+// -------------------------------------------------------------------
+
+func isServerError(status int) bool {
+	return status >= 500
+}
+
+func shouldRetry(res *http.Response, err error) bool {
+	if err != nil {
+		// don't retry canceled or timed out by the caller requests
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,315 @@
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/httpclient"
+)
+
+func statusHandler(statuses ...int) (http.Handler, *atomic.Int32) {
+	calls := &atomic.Int32{}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(calls.Add(1)) - 1
+		if i >= len(statuses) {
+			i = len(statuses) - 1
+		}
+		w.WriteHeader(statuses[i])
+	}), calls
+}
+
+func TestGatewayRetries(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name           string
+		statuses       []int
+		maxRetries     int
+		replayableBody bool
+		expectedStatus int
+		expectedCalls  int32
+	}{
+		{"success without retries", []int{200}, 3, true, 200, 1},
+		{"no retries configured", []int{503, 200}, 0, true, 503, 1},
+		{"retry until success", []int{503, 429, 200}, 3, true, 200, 3},
+		{"retries exhausted", []int{502}, 2, true, 502, 3},
+		{"non-retryable status", []int{400, 200}, 3, true, 400, 1},
+		{"non-replayable body", []int{503, 200}, 3, false, 503, 1},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			handler, calls := statusHandler(s.statuses...)
+			mock := httpclient.NewMock(handler)
+
+			client := httpclient.New(httpclient.Options{
+				Client:     mock,
+				MaxRetries: s.maxRetries,
+				MinBackoff: time.Millisecond,
+				MaxBackoff: 2 * time.Millisecond,
+			})
+
+			var body io.Reader = strings.NewReader("test")
+			if !s.replayableBody {
+				body = io.MultiReader(body) // hide the concrete type to prevent GetBody
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "https://example.com", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+
+			if res.StatusCode != s.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", s.expectedStatus, res.StatusCode)
+			}
+
+			if v := calls.Load(); v != s.expectedCalls {
+				t.Fatalf("Expected %d calls, got %d", s.expectedCalls, v)
+			}
+
+			// the body should be resent with each attempt
+			for i, r := range mock.Requests() {
+				if string(r.Body) != "test" {
+					t.Fatalf("[%d] Expected body %q, got %q", i, "test", r.Body)
+				}
+			}
+
+			stats := client.Stats()
+			if stats.Attempts != int64(s.expectedCalls) {
+				t.Fatalf("Expected %d attempts, got %d", s.expectedCalls, stats.Attempts)
+			}
+			if stats.Retries != int64(s.expectedCalls-1) {
+				t.Fatalf("Expected %d retries, got %d", s.expectedCalls-1, stats.Retries)
+			}
+		})
+	}
+}
+
+func TestGatewayNetworkError(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	client := httpclient.New(httpclient.Options{
+		Client: httpclient.ClientFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return nil, errors.New("connection refused")
+		}),
+		MaxRetries: 2,
+		MinBackoff: time.Millisecond,
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if calls != 3 {
+		t.Fatalf("Expected 3 calls, got %d", calls)
+	}
+
+	if v := client.Stats().Failures; v != 1 {
+		t.Fatalf("Expected 1 failure, got %d", v)
+	}
+}
+
+func TestGatewayRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	handler, _ := statusHandler(429, 200)
+	client := httpclient.New(httpclient.Options{
+		Client: httpclient.NewMock(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "10")
+			handler.ServeHTTP(w, r)
+		})),
+		MaxRetries: 1,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond, // caps the Retry-After delay
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+
+	start := time.Now()
+
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d", res.StatusCode)
+	}
+
+	if d := time.Since(start); d < 50*time.Millisecond || d > 5*time.Second {
+		t.Fatalf("Expected the Retry-After delay to be capped to MaxBackoff, got %v", d)
+	}
+}
+
+func TestGatewayContextCancel(t *testing.T) {
+	t.Parallel()
+
+	handler, calls := statusHandler(503)
+	client := httpclient.New(httpclient.Options{
+		Client:     httpclient.NewMock(handler),
+		MaxRetries: 5,
+		MinBackoff: time.Hour,
+		MaxBackoff: time.Hour,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+
+	_, err := client.Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded error, got %v", err)
+	}
+
+	if v := calls.Load(); v != 1 {
+		t.Fatalf("Expected 1 call, got %d", v)
+	}
+}
+
+func TestGatewayCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	status := &atomic.Int32{}
+	status.Store(500)
+
+	mock := httpclient.NewMock(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+
+	client := httpclient.New(httpclient.Options{
+		Client:           mock,
+		BreakerThreshold: 2,
+		BreakerCooldown:  30 * time.Millisecond,
+	})
+
+	send := func() (int, error) {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+		res, err := client.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		res.Body.Close()
+		return res.StatusCode, nil
+	}
+
+	// reach the failures threshold
+	for i := 0; i < 2; i++ {
+		if code, err := send(); err != nil || code != 500 {
+			t.Fatalf("[%d] Expected 500 response, got %d (%v)", i, code, err)
+		}
+	}
+
+	if !client.Stats().CircuitOpen {
+		t.Fatal("Expected the circuit to be open")
+	}
+
+	// open -> rejected
+	if _, err := send(); !errors.Is(err, httpclient.ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	if total := mock.TotalRequests(); total != 2 {
+		t.Fatalf("Expected the rejected request to not be sent, got %d requests", total)
+	}
+
+	// half-open -> failed trial reopens the circuit
+	time.Sleep(40 * time.Millisecond)
+	if code, err := send(); err != nil || code != 500 {
+		t.Fatalf("Expected 500 trial response, got %d (%v)", code, err)
+	}
+	if _, err := send(); !errors.Is(err, httpclient.ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen after failed trial, got %v", err)
+	}
+
+	// half-open -> successful trial closes the circuit
+	status.Store(200)
+	time.Sleep(40 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if code, err := send(); err != nil || code != 200 {
+			t.Fatalf("[%d] Expected 200 response, got %d (%v)", i, code, err)
+		}
+	}
+
+	stats := client.Stats()
+	if stats.CircuitOpen {
+		t.Fatal("Expected the circuit to be closed")
+	}
+	if stats.Requests != 7 {
+		t.Fatalf("Expected 7 requests, got %d", stats.Requests)
+	}
+	if stats.Rejected != 2 {
+		t.Fatalf("Expected 2 rejected requests, got %d", stats.Rejected)
+	}
+	if stats.Failures != 3 {
+		t.Fatalf("Expected 3 failures, got %d", stats.Failures)
+	}
+}
+
+func TestMock(t *testing.T) {
+	t.Parallel()
+
+	mock := httpclient.NewMock(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Test", r.Header.Get("X-Test"))
+		w.WriteHeader(201)
+		w.Write(bytes.ToUpper(body))
+	}))
+
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com/test?a=1", strings.NewReader("hello"))
+	req.Header.Set("X-Test", "123")
+
+	res, err := mock.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(res.Body)
+
+	if res.StatusCode != 201 || string(body) != "HELLO" || res.Header.Get("X-Test") != "123" {
+		t.Fatalf("Unexpected response %d %q %v", res.StatusCode, body, res.Header)
+	}
+
+	requests := mock.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 recorded request, got %d", len(requests))
+	}
+
+	r := requests[0]
+	if r.Method != http.MethodPost || r.URL != "https://example.com/test?a=1" || string(r.Body) != "hello" || r.Header.Get("X-Test") != "123" {
+		t.Fatalf("Unexpected recorded request %#v", r)
+	}
+
+	mock.Reset()
+	if total := mock.TotalRequests(); total != 0 {
+		t.Fatalf("Expected 0 recorded requests after reset, got %d", total)
+	}
+
+	// canceled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	if _, err := mock.Do(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// MockRequest defines a single request recorded by [Mock].
+type MockRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// Mock is a [Client] test double that serves the requests in-process
+// with the provided handler (no network calls) and records them.
+type Mock struct {
+	Handler http.Handler
+
+	mu       sync.Mutex
+	requests []*MockRequest
+}
+
+// NewMock creates a new [Mock] client that serves the requests with the provided handler.
+//
+// A nil handler responds with 200 and empty body.
+func NewMock(handler http.Handler) *Mock {
+	return &Mock{Handler: handler}
+}
+
+// Do implements [Client] interface.
+func (m *Mock) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+	m.requests = append(m.requests, &MockRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+		Body:   body,
+	})
+	m.mu.Unlock()
+
+	// serve a clone so that the handler could read the body again
+	served := req.Clone(req.Context())
+	served.Body = io.NopCloser(bytes.NewReader(body))
+
+	rec := httptest.NewRecorder()
+
+	if m.Handler != nil {
+		m.Handler.ServeHTTP(rec, served)
+	}
+
+	res := rec.Result()
+	res.Request = req
+
+	return res, nil
+}
+
+// Requests returns a copy of the recorded requests.
+func (m *Mock) Requests() []*MockRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]*MockRequest, len(m.requests))
+	copy(result, m.requests)
+
+	return result
+}
+
+// TotalRequests returns the number of the recorded requests.
+func (m *Mock) TotalRequests() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.requests)
+}
+
+// Reset clears the recorded requests.
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests = nil
+}
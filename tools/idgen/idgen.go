@@ -0,0 +1,158 @@
+// Package idgen implements time-sortable unique identifier generators.
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// UUIDv7 generates a new RFC 9562 version 7 UUID string
+// (48-bit unix milliseconds timestamp followed by 74 random bits).
+//
+// Example: "01932c07-a9b8-7c3e-9d2f-4b6a1e8f0c5d"
+func UUIDv7() (string, error) {
+	var b [16]byte
+
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+
+	putTimestamp(b[:6], time.Now())
+
+	b[6] = (b[6] & 0x0f) | 0x70 // version 7
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 9562 variant
+
+	var dst [36]byte
+	hex.Encode(dst[0:8], b[0:4])
+	dst[8] = '-'
+	hex.Encode(dst[9:13], b[4:6])
+	dst[13] = '-'
+	hex.Encode(dst[14:18], b[6:8])
+	dst[18] = '-'
+	hex.Encode(dst[19:23], b[8:10])
+	dst[23] = '-'
+	hex.Encode(dst[24:], b[10:])
+
+	return string(dst[:]), nil
+}
+
+// crockfordAlphabet is the Crockford's base32 alphabet used by ULID.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID generates a new 26 characters ULID string
+// (48-bit unix milliseconds timestamp followed by 80 random bits).
+//
+// Example: "01JD0E1QDRBZ5GQ2JW3N8XQ4KT"
+func ULID() (string, error) {
+	var b [16]byte
+
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+
+	putTimestamp(b[:6], time.Now())
+
+	// encode the 128 bits as 26 base32 characters (the first one holds only 3 bits)
+	var dst [26]byte
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	for i := 25; i >= 0; i-- {
+		dst[i] = crockfordAlphabet[lo&0x1f]
+		lo = (lo >> 5) | (hi << 59)
+		hi >>= 5
+	}
+
+	return string(dst[:]), nil
+}
+
+func putTimestamp(dst []byte, t time.Time) {
+	ms := uint64(t.UnixMilli())
+
+	dst[0] = byte(ms >> 40)
+	dst[1] = byte(ms >> 32)
+	dst[2] = byte(ms >> 24)
+	dst[3] = byte(ms >> 16)
+	dst[4] = byte(ms >> 8)
+	dst[5] = byte(ms)
+}
+
+// -------------------------------------------------------------------
+
+// SnowflakeEpoch is the custom epoch (2024-01-01 00:00:00 UTC)
+// used for the [Snowflake] timestamps.
+var SnowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxNode      = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSequence  = 1<<snowflakeSequenceBits - 1
+)
+
+// Snowflake is a Twitter-like snowflake identifiers generator.
+//
+// The generated ids are 63-bit integers composed of 41-bit milliseconds
+// timestamp (since [SnowflakeEpoch]), 10-bit node id and 12-bit sequence number.
+//
+// Each node (aka. app instance) generating ids for the same storage
+// must have its own unique node id.
+type Snowflake struct {
+	mu       sync.Mutex
+	node     int64
+	lastTime int64
+	sequence int64
+}
+
+// NewSnowflake creates a new Snowflake generator for the specified node id (0-1023).
+func NewSnowflake(node int64) (*Snowflake, error) {
+	if node < 0 || node > snowflakeMaxNode {
+		return nil, errors.New("the snowflake node id must be between 0 and " + strconv.Itoa(snowflakeMaxNode))
+	}
+
+	return &Snowflake{node: node}, nil
+}
+
+// Next generates a new snowflake id.
+//
+// It blocks until the next millisecond if the sequence for the
+// current one is exhausted or if the system clock moved backwards.
+func (s *Snowflake) Next() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Since(SnowflakeEpoch).Milliseconds()
+
+	if now < s.lastTime {
+		time.Sleep(time.Duration(s.lastTime-now) * time.Millisecond)
+		now = s.lastTime
+	}
+
+	if now == s.lastTime {
+		s.sequence = (s.sequence + 1) & snowflakeMaxSequence
+		if s.sequence == 0 {
+			for now <= s.lastTime {
+				time.Sleep(100 * time.Microsecond)
+				now = time.Since(SnowflakeEpoch).Milliseconds()
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+
+	s.lastTime = now
+
+	return now<<(snowflakeNodeBits+snowflakeSequenceBits) | s.node<<snowflakeSequenceBits | s.sequence
+}
+
+// NextString generates a new snowflake id and returns its
+// 19 characters zero padded decimal string representation
+// (the padding keeps the string ids lexicographically sortable).
+func (s *Snowflake) NextString() string {
+	return fmt.Sprintf("%019d", s.Next())
+}
@@ -0,0 +1,124 @@
+package idgen_test
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/idgen"
+)
+
+func TestUUIDv7(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	testSortable(t, 100, func() string {
+		id, err := idgen.UUIDv7()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !pattern.MatchString(id) {
+			t.Fatalf("Invalid UUIDv7 %q", id)
+		}
+
+		return id
+	})
+}
+
+func TestULID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+
+	testSortable(t, 100, func() string {
+		id, err := idgen.ULID()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !pattern.MatchString(id) {
+			t.Fatalf("Invalid ULID %q", id)
+		}
+
+		return id
+	})
+}
+
+func TestNewSnowflake(t *testing.T) {
+	scenarios := []struct {
+		node        int64
+		expectError bool
+	}{
+		{-1, true},
+		{0, false},
+		{1023, false},
+		{1024, true},
+	}
+
+	for _, s := range scenarios {
+		_, err := idgen.NewSnowflake(s.node)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("[%d] Expected hasErr %v, got %v (%v)", s.node, s.expectError, hasErr, err)
+		}
+	}
+}
+
+func TestSnowflake(t *testing.T) {
+	s, err := idgen.NewSnowflake(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pattern := regexp.MustCompile(`^\d{19}$`)
+
+	// more than the per millisecond sequence to test the overflow
+	ids := testSortable(t, 5000, func() string {
+		id := s.NextString()
+
+		if !pattern.MatchString(id) {
+			t.Fatalf("Invalid snowflake id %q", id)
+		}
+
+		return id
+	})
+
+	unique := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		unique[id] = struct{}{}
+	}
+	if len(unique) != len(ids) {
+		t.Fatalf("Expected %d unique ids, got %d", len(ids), len(unique))
+	}
+
+	if node := (s.Next() >> 12) & 1023; node != 5 {
+		t.Fatalf("Expected node 5, got %d", node)
+	}
+}
+
+// testSortable generates total ids (with a small delay between each
+// 10th of them) and checks whether they are in lexicographical order.
+func testSortable(t *testing.T, total int, generate func() string) []string {
+	ids := make([]string, total)
+
+	for i := range ids {
+		if i%(max(total/10, 1)) == 0 {
+			time.Sleep(2 * time.Millisecond)
+		}
+		ids[i] = generate()
+	}
+
+	if !sort.StringsAreSorted(everyNth(ids, max(total/10, 1))) {
+		t.Fatalf("Expected the ids to be time sortable, got %v", ids)
+	}
+
+	return ids
+}
+
+func everyNth(ids []string, n int) []string {
+	result := make([]string, 0, len(ids)/n+1)
+	for i := 0; i < len(ids); i += n {
+		result = append(result, ids[i])
+	}
+	return result
+}
@@ -4,6 +4,8 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 var columnifyRemoveRegex = regexp.MustCompile(`[^\w\.\*\-\_\@\#]+`)
@@ -83,3 +85,34 @@ func Snakecase(str string) string {
 
 	return strings.ToLower(result.String())
 }
+
+// Slugify converts str into a lowercase URL-safe slug by removing the
+// diacritics and replacing any other non alphanumeric characters with a single dash,
+// eg. "Hello, Wörld!" will become "hello-world".
+func Slugify(str string) string {
+	var result strings.Builder
+
+	pendingDash := false
+
+	for _, c := range norm.NFKD.String(str) {
+		switch {
+		case (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9'):
+		case c >= 'A' && c <= 'Z':
+			c = unicode.ToLower(c)
+		case unicode.Is(unicode.Mn, c):
+			continue // combining mark (eg. diacritic)
+		default:
+			pendingDash = result.Len() > 0
+			continue
+		}
+
+		if pendingDash {
+			result.WriteByte('-')
+			pendingDash = false
+		}
+
+		result.WriteRune(c)
+	}
+
+	return result.String()
+}
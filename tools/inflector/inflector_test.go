@@ -143,3 +143,29 @@ func TestSnakecase(t *testing.T) {
 		})
 	}
 }
+
+func TestSlugify(t *testing.T) {
+	scenarios := []struct {
+		val      string
+		expected string
+	}{
+		{"", ""},
+		{"  ", ""},
+		{"!@#$%^", ""},
+		{"abc", "abc"},
+		{"Hello, Wörld!", "hello-world"},
+		{"  --John   Doe--  ", "john-doe"},
+		{"Crème brûlée 123", "creme-brulee-123"},
+		{"a_b.c/d", "a-b-c-d"},
+		{"ПРИВЕТ test", "test"},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.val), func(t *testing.T) {
+			result := inflector.Slugify(s.val)
+			if result != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, result)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/tools/types"
@@ -31,6 +33,17 @@ type BatchOptions struct {
 	// BatchSize specifies how many logs to accumulate before calling WriteFunc.
 	// If not set or 0, fallback to 100 by default.
 	BatchSize int
+
+	// FlushInterval enables the asynchronous write mode in which the
+	// accumulated logs are written by a background goroutine on every
+	// interval tick (or earlier when BatchSize is reached) instead of
+	// inline as part of the Handle call.
+	//
+	// Call [BatchHandler.Close] to stop the background goroutine and
+	// to write the remaining logs.
+	//
+	// If not set or 0, the logs are written synchronously.
+	FlushInterval time.Duration
 }
 
 // NewBatchHandler creates a slog compatible handler that writes JSON
@@ -69,6 +82,14 @@ func NewBatchHandler(options BatchOptions) *BatchHandler {
 
 	h.logs = make([]*Log, 0, h.options.BatchSize)
 
+	if h.options.FlushInterval > 0 {
+		h.flushCh = make(chan struct{}, 1)
+		h.stopCh = make(chan struct{})
+		h.doneCh = make(chan struct{})
+
+		go h.flushLoop()
+	}
+
 	return h
 }
 
@@ -84,6 +105,13 @@ type BatchHandler struct {
 	group   string
 	attrs   []slog.Attr
 	logs    []*Log
+
+	// async write mode state (available only for the root handler)
+	flushCh   chan struct{}
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	closeOnce sync.Once
+	closed    atomic.Bool
 }
 
 // Enabled reports whether the handler handles records at the given level.
@@ -128,6 +156,9 @@ func (h *BatchHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 //
 // If the batch queue threshold has been reached, the WriteFunc option
 // is invoked with the accumulated logs which in turn will reset the batch queue.
+//
+// In async mode (aka. when [BatchOptions.FlushInterval] is set) the write
+// is delegated to the background flush goroutine and Handle doesn't block.
 func (h *BatchHandler) Handle(ctx context.Context, r slog.Record) error {
 	if h.group != "" {
 		h.mux.Lock()
@@ -182,6 +213,15 @@ func (h *BatchHandler) Handle(ctx context.Context, r slog.Record) error {
 	h.mux.Unlock()
 
 	if totalLogs >= h.options.BatchSize {
+		if h.flushCh != nil && !h.closed.Load() {
+			// notify the flush goroutine (if not already notified)
+			select {
+			case h.flushCh <- struct{}{}:
+			default:
+			}
+			return nil
+		}
+
 		if err := h.WriteAll(ctx); err != nil {
 			return err
 		}
@@ -225,6 +265,55 @@ func (h *BatchHandler) WriteAll(ctx context.Context) error {
 	return h.options.WriteFunc(ctx, logs)
 }
 
+// Close stops the background flush goroutine (if any) and writes
+// all remaining accumulated logs.
+//
+// The handler can still be used after Close but the logs will be
+// written synchronously once the BatchSize threshold is reached.
+func (h *BatchHandler) Close(ctx context.Context) error {
+	if h.parent != nil {
+		return h.parent.Close(ctx)
+	}
+
+	if h.flushCh != nil {
+		h.closeOnce.Do(func() {
+			h.closed.Store(true)
+			close(h.stopCh)
+		})
+
+		// wait for any in-progress flush to complete
+		select {
+		case <-h.doneCh:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return h.WriteAll(ctx)
+}
+
+// flushLoop periodically writes the accumulated logs until the handler is closed.
+func (h *BatchHandler) flushLoop() {
+	defer close(h.doneCh)
+
+	ticker := time.NewTicker(h.options.FlushInterval)
+	defer ticker.Stop()
+
+	ctx := context.Background()
+
+	for {
+		select {
+		case <-h.stopCh:
+			return
+		case <-ticker.C:
+		case <-h.flushCh:
+		}
+
+		// errors are expected to be reported by the WriteFunc itself
+		_ = h.WriteAll(ctx)
+	}
+}
+
 // resolveAttr writes attr into data.
 func (h *BatchHandler) resolveAttr(data map[string]any, attr slog.Attr) error {
 	// ensure that the attr value is resolved before doing anything else
@@ -250,6 +250,112 @@ func TestBatchHandlerWriteAll(t *testing.T) {
 	checkLogMessages([]string{"test1", "test2"}, writeLogs, t)
 }
 
+func TestBatchHandlerAsyncFlushInterval(t *testing.T) {
+	ctx := context.Background()
+
+	writeCh := make(chan []*Log, 10)
+
+	h := NewBatchHandler(BatchOptions{
+		BatchSize:     100,
+		FlushInterval: 10 * time.Millisecond,
+		WriteFunc: func(_ context.Context, logs []*Log) error {
+			writeCh <- logs
+			return nil
+		},
+	})
+	defer h.Close(ctx)
+
+	h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "test1", 0))
+	h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "test2", 0))
+
+	select {
+	case logs := <-writeCh:
+		checkLogMessages([]string{"test1", "test2"}, logs, t)
+	case <-time.After(1 * time.Second):
+		t.Fatal("Expected the logs to be written on the flush interval tick")
+	}
+}
+
+func TestBatchHandlerAsyncBatchSize(t *testing.T) {
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	writeCh := make(chan []*Log, 10)
+
+	h := NewBatchHandler(BatchOptions{
+		BatchSize:     2,
+		FlushInterval: 1 * time.Hour,
+		WriteFunc: func(_ context.Context, logs []*Log) error {
+			<-release
+			writeCh <- logs
+			return nil
+		},
+	})
+
+	done := make(chan struct{})
+	go func() {
+		h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "test1", 0))
+		h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "test2", 0))
+		close(done)
+	}()
+
+	// Handle shouldn't wait for the (blocked) write
+	select {
+	case <-done:
+	case <-time.After(1 * time.Second):
+		t.Fatal("Expected Handle to not block on the batch write")
+	}
+
+	close(release)
+
+	select {
+	case logs := <-writeCh:
+		checkLogMessages([]string{"test1", "test2"}, logs, t)
+	case <-time.After(1 * time.Second):
+		t.Fatal("Expected the logs to be written after reaching the batch size")
+	}
+
+	if err := h.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBatchHandlerClose(t *testing.T) {
+	ctx := context.Background()
+
+	writeLogs := []*Log{}
+
+	h := NewBatchHandler(BatchOptions{
+		BatchSize:     2,
+		FlushInterval: 1 * time.Hour,
+		WriteFunc: func(_ context.Context, logs []*Log) error {
+			writeLogs = append(writeLogs, logs...)
+			return nil
+		},
+	})
+
+	h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "test1", 0))
+
+	if err := h.WithGroup("sub").(*BatchHandler).Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	checkLogMessages([]string{}, h.logs, t)
+	checkLogMessages([]string{"test1"}, writeLogs, t)
+
+	// multiple Close calls should be allowed
+	if err := h.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// after close the logs should be written synchronously
+	h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "test2", 0))
+	h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "test3", 0))
+
+	checkLogMessages([]string{}, h.logs, t)
+	checkLogMessages([]string{"test1", "test2", "test3"}, writeLogs, t)
+}
+
 func TestBatchHandlerAttrsFormat(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,363 @@
+package mmdb_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/mmdb"
+)
+
+func mustParseCIDR(t *testing.T, cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return network
+}
+
+func buildDB(t *testing.T, w *mmdb.Writer) *mmdb.Reader {
+	buf := &bytes.Buffer{}
+	if _, err := w.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := mmdb.FromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+func TestFromBytesInvalid(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"missing marker", []byte("abc")},
+		{"marker with invalid metadata", []byte("\xAB\xCD\xEFMaxMind.com\x01")},
+		{"marker with non-map metadata", []byte("\xAB\xCD\xEFMaxMind.com\x43abc")},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if _, err := mmdb.FromBytes(s.data); err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestReaderLookup(t *testing.T) {
+	t.Parallel()
+
+	for _, recordSize := range []int{24, 28, 32} {
+		for _, ipVersion := range []int{4, 6} {
+			t.Run(fmt.Sprintf("record_%d_ipv%d", recordSize, ipVersion), func(t *testing.T) {
+				w := mmdb.NewWriter("Test-City")
+				w.RecordSize = recordSize
+				w.IPVersion = ipVersion
+				w.Languages = []string{"en"}
+				w.Description = map[string]string{"en": "test db"}
+
+				inserts := []struct {
+					cidr string
+					data any
+				}{
+					{"1.2.3.0/24", map[string]any{"country": map[string]any{"iso_code": "BG"}}},
+					{"1.2.3.4/32", map[string]any{"country": map[string]any{"iso_code": "DE"}}},
+					{"8.8.0.0/16", "google"},
+				}
+				if ipVersion == 6 {
+					inserts = append(inserts, struct {
+						cidr string
+						data any
+					}{"2001:db8::/32", []any{"a", uint16(1), true}})
+				}
+
+				for _, insert := range inserts {
+					if err := w.Insert(mustParseCIDR(t, insert.cidr), insert.data); err != nil {
+						t.Fatalf("Failed to insert %s: %v", insert.cidr, err)
+					}
+				}
+
+				r := buildDB(t, w)
+
+				meta := r.Metadata()
+				if meta.DatabaseType != "Test-City" {
+					t.Fatalf("Expected database type %q, got %q", "Test-City", meta.DatabaseType)
+				}
+				if meta.RecordSize != uint(recordSize) {
+					t.Fatalf("Expected record size %d, got %d", recordSize, meta.RecordSize)
+				}
+				if meta.IPVersion != uint(ipVersion) {
+					t.Fatalf("Expected ip version %d, got %d", ipVersion, meta.IPVersion)
+				}
+				if len(meta.Languages) != 1 || meta.Languages[0] != "en" {
+					t.Fatalf("Expected languages [en], got %v", meta.Languages)
+				}
+				if meta.Description["en"] != "test db" {
+					t.Fatalf("Expected description %q, got %q", "test db", meta.Description["en"])
+				}
+
+				scenarios := []struct {
+					ip       string
+					expected string
+				}{
+					{"1.2.3.1", "map[country:map[iso_code:BG]]"},
+					{"1.2.3.255", "map[country:map[iso_code:BG]]"},
+					{"1.2.3.4", "map[country:map[iso_code:DE]]"},
+					{"8.8.8.8", "google"},
+					{"8.9.8.8", ""},
+					{"127.0.0.1", ""},
+				}
+				if ipVersion == 6 {
+					scenarios = append(scenarios,
+						struct {
+							ip       string
+							expected string
+						}{"2001:db8::1", "[a 1 true]"},
+						struct {
+							ip       string
+							expected string
+						}{"2001:db9::1", ""},
+					)
+				}
+
+				for _, s := range scenarios {
+					result, err := r.LookupString(s.ip)
+					if s.expected == "" {
+						if !errors.Is(err, mmdb.ErrNotFound) {
+							t.Fatalf("[%s] Expected ErrNotFound, got %v (%v)", s.ip, err, result)
+						}
+						continue
+					}
+
+					if err != nil {
+						t.Fatalf("[%s] Unexpected error: %v", s.ip, err)
+					}
+
+					if str := fmt.Sprint(result); str != s.expected {
+						t.Fatalf("[%s] Expected %s, got %s", s.ip, s.expected, str)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestReaderLookupIPv6InIPv4Database(t *testing.T) {
+	t.Parallel()
+
+	w := mmdb.NewWriter("test")
+	w.IPVersion = 4
+	if err := w.Insert(mustParseCIDR(t, "1.0.0.0/8"), "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Insert(mustParseCIDR(t, "2001:db8::/32"), "test"); err == nil {
+		t.Fatal("Expected IPv6 insert error")
+	}
+
+	r := buildDB(t, w)
+
+	if _, err := r.LookupString("2001:db8::1"); err == nil || errors.Is(err, mmdb.ErrNotFound) {
+		t.Fatalf("Expected IPv6 lookup error, got %v", err)
+	}
+
+	if _, err := r.LookupString("invalid"); err == nil {
+		t.Fatal("Expected invalid ip error")
+	}
+}
+
+func TestReaderDataTypes(t *testing.T) {
+	t.Parallel()
+
+	longStr := string(bytes.Repeat([]byte("a"), 300))
+	veryLongStr := string(bytes.Repeat([]byte("b"), 70000))
+
+	data := map[string]any{
+		"string":   "test",
+		"long":     longStr,
+		"veryLong": veryLongStr,
+		"bytes":    []byte{1, 2, 3},
+		"double":   1.5,
+		"float":    float32(2.5),
+		"uint16":   uint16(123),
+		"uint32":   uint32(123456),
+		"uint64":   uint64(1 << 40),
+		"int":      -12,
+		"bool":     true,
+		"false":    false,
+		"zero":     0,
+		"array":    []string{"a", "b"},
+		"nested":   map[string]string{"a": "b"},
+	}
+
+	w := mmdb.NewWriter("test")
+	if err := w.Insert(mustParseCIDR(t, "10.0.0.0/8"), data); err != nil {
+		t.Fatal(err)
+	}
+
+	r := buildDB(t, w)
+
+	raw, err := r.LookupString("10.1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, ok := raw.(map[string]any)
+	if !ok {
+		t.Fatalf("Expected map, got %T", raw)
+	}
+
+	expected := map[string]string{
+		"string":   "test",
+		"long":     longStr,
+		"veryLong": veryLongStr,
+		"bytes":    "[1 2 3]",
+		"double":   "1.5",
+		"float":    "2.5",
+		"uint16":   "123",
+		"uint32":   "123456",
+		"uint64":   "1099511627776",
+		"int":      "-12",
+		"bool":     "true",
+		"false":    "false",
+		"zero":     "0",
+		"array":    "[a b]",
+		"nested":   "map[a:b]",
+	}
+
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d keys, got %d: %v", len(expected), len(result), result)
+	}
+
+	for k, v := range expected {
+		if str := fmt.Sprint(result[k]); str != v {
+			t.Errorf("[%s] Expected %q, got %q", k, v, str)
+		}
+	}
+}
+
+func TestReaderPointers(t *testing.T) {
+	t.Parallel()
+
+	// manually crafted IPv4 database with a single node which left
+	// record points to a map containing a pointer to a string value:
+	//
+	// data section:
+	//   0: "hello"            (0x45 h e l l o)
+	//   6: {"a": <ptr to 0>}  (0xe1 0x41 'a' 0x20 0x00)
+	db := []byte{
+		// search tree (1 node, record size 24)
+		0x00, 0x00, 0x17, // 1 + 16 + 6
+		0x00, 0x00, 0x01, // not found
+	}
+	db = append(db, make([]byte, 16)...)
+	db = append(db, 0x45, 'h', 'e', 'l', 'l', 'o')
+	db = append(db, 0xe1, 0x41, 'a', 0x20, 0x00)
+	db = append(db, []byte("\xAB\xCD\xEFMaxMind.com")...)
+	db = append(db,
+		0xe4,
+		0x4a, 'n', 'o', 'd', 'e', '_', 'c', 'o', 'u', 'n', 't', 0xc1, 0x01,
+		0x4b, 'r', 'e', 'c', 'o', 'r', 'd', '_', 's', 'i', 'z', 'e', 0xa1, 24,
+		0x4a, 'i', 'p', '_', 'v', 'e', 'r', 's', 'i', 'o', 'n', 0xa1, 4,
+		0x5b, 'b', 'i', 'n', 'a', 'r', 'y', '_', 'f', 'o', 'r', 'm', 'a', 't', '_', 'm', 'a', 'j', 'o', 'r', '_', 'v', 'e', 'r', 's', 'i', 'o', 'n', 0xa1, 2,
+	)
+
+	r, err := mmdb.FromBytes(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := r.LookupString("1.2.3.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if str := fmt.Sprint(result); str != "map[a:hello]" {
+		t.Fatalf("Expected map[a:hello], got %s", str)
+	}
+
+	if _, err := r.LookupString("200.2.3.4"); !errors.Is(err, mmdb.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestReaderUint128(t *testing.T) {
+	t.Parallel()
+
+	// data: uint128 (extended type 10 -> 0x03 after the control byte)
+	db := []byte{
+		0x00, 0x00, 0x11, // 1 + 16 + 0
+		0x00, 0x00, 0x01,
+	}
+	db = append(db, make([]byte, 16)...)
+	db = append(db, 0x02, 0x03, 0x01, 0x00)
+	db = append(db, []byte("\xAB\xCD\xEFMaxMind.com")...)
+	db = append(db,
+		0xe4,
+		0x4a, 'n', 'o', 'd', 'e', '_', 'c', 'o', 'u', 'n', 't', 0xc1, 0x01,
+		0x4b, 'r', 'e', 'c', 'o', 'r', 'd', '_', 's', 'i', 'z', 'e', 0xa1, 24,
+		0x4a, 'i', 'p', '_', 'v', 'e', 'r', 's', 'i', 'o', 'n', 0xa1, 4,
+		0x5b, 'b', 'i', 'n', 'a', 'r', 'y', '_', 'f', 'o', 'r', 'm', 'a', 't', '_', 'm', 'a', 'j', 'o', 'r', '_', 'v', 'e', 'r', 's', 'i', 'o', 'n', 0xa1, 2,
+	)
+
+	r, err := mmdb.FromBytes(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := r.LookupString("1.2.3.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, ok := result.(*big.Int)
+	if !ok || n.Int64() != 256 {
+		t.Fatalf("Expected big.Int 256, got %T %v", result, result)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	w := mmdb.NewWriter("test")
+	if err := w.Insert(mustParseCIDR(t, "10.0.0.0/8"), "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := w.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := mmdb.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := r.LookupString("10.0.0.1")
+	if err != nil || result != "test" {
+		t.Fatalf("Expected test, got %v (%v)", result, err)
+	}
+
+	if _, err := mmdb.Open(filepath.Join(t.TempDir(), "missing.mmdb")); err == nil {
+		t.Fatal("Expected missing file error")
+	}
+}
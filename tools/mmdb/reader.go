@@ -0,0 +1,493 @@
+// Package mmdb implements a minimal reader (and writer) for the
+// MaxMind DB file format (e.g. the GeoLite2 and GeoIP2 databases).
+//
+// See https://maxmind.github.io/MaxMind-DB/ for the format specification.
+package mmdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"os"
+)
+
+// metadataStartMarker is the marker that precedes the database metadata section.
+var metadataStartMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparatorSize is the number of the null bytes between the search tree and the data section.
+const dataSectionSeparatorSize = 16
+
+// ErrNotFound is returned when there is no database entry for the looked up ip.
+var ErrNotFound = errors.New("mmdb: no entry found for the specified ip")
+
+// Metadata defines the common MaxMind DB metadata fields.
+type Metadata struct {
+	Description              map[string]string
+	DatabaseType             string
+	Languages                []string
+	NodeCount                uint
+	RecordSize               uint
+	IPVersion                uint
+	BinaryFormatMajorVersion uint
+	BinaryFormatMinorVersion uint
+	BuildEpoch               uint64
+}
+
+// Reader is a MaxMind DB reader.
+//
+// It is safe for concurrent use.
+type Reader struct {
+	buf        []byte
+	data       []byte
+	metadata   Metadata
+	nodeSize   uint
+	ipv4Start  uint
+	ipv4Depth  int
+	searchSize uint
+}
+
+// Open reads and parses the MaxMind DB file at the specified path.
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromBytes(buf)
+}
+
+// FromBytes parses the provided raw MaxMind DB data.
+func FromBytes(buf []byte) (*Reader, error) {
+	markerIndex := bytes.LastIndex(buf, metadataStartMarker)
+	if markerIndex == -1 {
+		return nil, errors.New("mmdb: invalid database (missing metadata marker)")
+	}
+
+	metadataStart := markerIndex + len(metadataStartMarker)
+
+	rawMetadata, _, err := (&decoder{buf: buf[metadataStart:]}).decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("mmdb: failed to decode the database metadata: %w", err)
+	}
+
+	metadataMap, ok := rawMetadata.(map[string]any)
+	if !ok {
+		return nil, errors.New("mmdb: invalid database metadata")
+	}
+
+	r := &Reader{buf: buf}
+
+	r.metadata.NodeCount = toUint(metadataMap["node_count"])
+	r.metadata.RecordSize = toUint(metadataMap["record_size"])
+	r.metadata.IPVersion = toUint(metadataMap["ip_version"])
+	r.metadata.BinaryFormatMajorVersion = toUint(metadataMap["binary_format_major_version"])
+	r.metadata.BinaryFormatMinorVersion = toUint(metadataMap["binary_format_minor_version"])
+	r.metadata.BuildEpoch = uint64(toUint(metadataMap["build_epoch"]))
+	r.metadata.DatabaseType, _ = metadataMap["database_type"].(string)
+
+	if languages, ok := metadataMap["languages"].([]any); ok {
+		for _, lang := range languages {
+			if str, ok := lang.(string); ok {
+				r.metadata.Languages = append(r.metadata.Languages, str)
+			}
+		}
+	}
+
+	if description, ok := metadataMap["description"].(map[string]any); ok {
+		r.metadata.Description = make(map[string]string, len(description))
+		for k, v := range description {
+			r.metadata.Description[k], _ = v.(string)
+		}
+	}
+
+	if r.metadata.BinaryFormatMajorVersion != 2 {
+		return nil, fmt.Errorf("mmdb: unsupported binary format version %d", r.metadata.BinaryFormatMajorVersion)
+	}
+
+	switch r.metadata.RecordSize {
+	case 24, 28, 32:
+		r.nodeSize = r.metadata.RecordSize / 4
+	default:
+		return nil, fmt.Errorf("mmdb: unsupported record size %d", r.metadata.RecordSize)
+	}
+
+	if r.metadata.IPVersion != 4 && r.metadata.IPVersion != 6 {
+		return nil, fmt.Errorf("mmdb: unsupported ip version %d", r.metadata.IPVersion)
+	}
+
+	r.searchSize = r.metadata.NodeCount * r.nodeSize
+
+	dataStart := r.searchSize + dataSectionSeparatorSize
+	if dataStart > uint(markerIndex) {
+		return nil, errors.New("mmdb: invalid database (the search tree is larger than the database)")
+	}
+	r.data = buf[dataStart:markerIndex]
+
+	// locate the IPv4 subtree (aka. ::a.b.c.d) in case of IPv6 database
+	if r.metadata.IPVersion == 6 {
+		node := uint(0)
+		i := 0
+		for ; i < 96 && node < r.metadata.NodeCount; i++ {
+			node, err = r.readNode(node, 0)
+			if err != nil {
+				return nil, err
+			}
+		}
+		r.ipv4Start = node
+		r.ipv4Depth = i
+	}
+
+	return r, nil
+}
+
+// Metadata returns the database metadata.
+func (r *Reader) Metadata() Metadata {
+	return r.metadata
+}
+
+// Lookup returns the database entry of the specified ip.
+//
+// Returns [ErrNotFound] if there is no entry for the ip.
+func (r *Reader) Lookup(ip net.IP) (any, error) {
+	if ip == nil {
+		return nil, errors.New("mmdb: invalid ip")
+	}
+
+	node := uint(0)
+	bitCount := 128
+
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip = ipv4
+		bitCount = 32
+		node = r.ipv4Start
+	} else if r.metadata.IPVersion == 4 {
+		return nil, errors.New("mmdb: cannot lookup an IPv6 address in an IPv4-only database")
+	}
+
+	nodeCount := r.metadata.NodeCount
+
+	var err error
+	for i := 0; i < bitCount && node < nodeCount; i++ {
+		bit := uint(1) & (uint(ip[i>>3]) >> (7 - (uint(i) % 8)))
+
+		node, err = r.readNode(node, bit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if node == nodeCount {
+		return nil, ErrNotFound
+	}
+
+	if node < nodeCount {
+		return nil, errors.New("mmdb: invalid search tree (the lookup ended at a search node)")
+	}
+
+	offset := node - nodeCount - dataSectionSeparatorSize
+	if offset >= uint(len(r.data)) {
+		return nil, errors.New("mmdb: invalid search tree (the data pointer is out of range)")
+	}
+
+	result, _, err := (&decoder{buf: r.data}).decode(offset)
+
+	return result, err
+}
+
+// LookupString is similar to [Reader.Lookup] but accepts the ip as string.
+func (r *Reader) LookupString(ip string) (any, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("mmdb: invalid ip %q", ip)
+	}
+
+	return r.Lookup(parsed)
+}
+
+func (r *Reader) readNode(node uint, bit uint) (uint, error) {
+	offset := node * r.nodeSize
+	if offset+r.nodeSize > r.searchSize {
+		return 0, errors.New("mmdb: invalid search tree (the node is out of range)")
+	}
+
+	b := r.buf[offset : offset+r.nodeSize]
+
+	switch r.metadata.RecordSize {
+	case 24:
+		if bit == 0 {
+			return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return uint(b[3])<<16 | uint(b[4])<<8 | uint(b[5]), nil
+	case 28:
+		if bit == 0 {
+			return (uint(b[3])&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return (uint(b[3])&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6]), nil
+	default: // 32
+		if bit == 0 {
+			return uint(binary.BigEndian.Uint32(b[0:4])), nil
+		}
+		return uint(binary.BigEndian.Uint32(b[4:8])), nil
+	}
+}
+
+// -------------------------------------------------------------------
+
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEndMarker = 13
+	typeBool      = 14
+	typeFloat     = 15
+)
+
+// maxDecodeDepth limits the nested maps and arrays to prevent stack exhaustion on malformed data.
+const maxDecodeDepth = 64
+
+type decoder struct {
+	buf   []byte
+	depth int
+}
+
+// decode decodes the data field at the specified offset and
+// returns its value and the offset of the next field.
+func (d *decoder) decode(offset uint) (any, uint, error) {
+	typeNum, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typeNum == typePointer {
+		pointer, next, err := d.decodePointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// pointers to pointers are not allowed by the spec
+		typeNum, size, valueOffset, err := d.decodeControl(pointer)
+		if err != nil {
+			return nil, 0, err
+		}
+		if typeNum == typePointer {
+			return nil, 0, errors.New("mmdb: invalid pointer to pointer")
+		}
+
+		value, _, err := d.decodeValue(typeNum, size, valueOffset)
+
+		return value, next, err
+	}
+
+	return d.decodeValue(typeNum, size, offset)
+}
+
+func (d *decoder) decodeControl(offset uint) (int, uint, uint, error) {
+	if offset >= uint(len(d.buf)) {
+		return 0, 0, 0, errors.New("mmdb: unexpected end of data")
+	}
+
+	ctrl := d.buf[offset]
+	offset++
+
+	typeNum := int(ctrl >> 5)
+	if typeNum == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return 0, 0, 0, errors.New("mmdb: unexpected end of data")
+		}
+		typeNum = int(d.buf[offset]) + 7
+		offset++
+	}
+
+	if typeNum == typePointer {
+		// the size bits are part of the pointer value
+		return typeNum, uint(ctrl & 0x1f), offset, nil
+	}
+
+	size := uint(ctrl & 0x1f)
+	if size >= 29 {
+		extra := size - 28
+		if offset+extra > uint(len(d.buf)) {
+			return 0, 0, 0, errors.New("mmdb: unexpected end of data")
+		}
+
+		b := d.buf[offset : offset+extra]
+		offset += extra
+
+		switch size {
+		case 29:
+			size = 29 + uint(b[0])
+		case 30:
+			size = 285 + (uint(b[0])<<8 | uint(b[1]))
+		default:
+			size = 65821 + (uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]))
+		}
+	}
+
+	return typeNum, size, offset, nil
+}
+
+func (d *decoder) decodePointer(sizeBits uint, offset uint) (uint, uint, error) {
+	pointerSize := ((sizeBits >> 3) & 0x3) + 1
+	if offset+pointerSize > uint(len(d.buf)) {
+		return 0, 0, errors.New("mmdb: unexpected end of data")
+	}
+
+	b := d.buf[offset : offset+pointerSize]
+
+	var pointer uint
+	switch pointerSize {
+	case 1:
+		pointer = (sizeBits&0x7)<<8 | uint(b[0])
+	case 2:
+		pointer = ((sizeBits&0x7)<<16 | uint(b[0])<<8 | uint(b[1])) + 2048
+	case 3:
+		pointer = ((sizeBits&0x7)<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])) + 526336
+	default:
+		pointer = uint(binary.BigEndian.Uint32(b))
+	}
+
+	return pointer, offset + pointerSize, nil
+}
+
+func (d *decoder) decodeValue(typeNum int, size uint, offset uint) (any, uint, error) {
+	switch typeNum {
+	case typeMap:
+		return d.decodeMap(size, offset)
+	case typeArray:
+		return d.decodeArray(size, offset)
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, errors.New("mmdb: unexpected end of data")
+	}
+
+	b := d.buf[offset : offset+size]
+	next := offset + size
+
+	switch typeNum {
+	case typeString:
+		return string(b), next, nil
+	case typeBytes:
+		return bytes.Clone(b), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("mmdb: invalid double size %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("mmdb: invalid float size %d", size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("mmdb: invalid unsigned integer size %d", size)
+		}
+		var v uint64
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		return v, next, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("mmdb: invalid int32 size %d", size)
+		}
+		var v uint32
+		for _, c := range b {
+			v = v<<8 | uint32(c)
+		}
+		return int64(int32(v)), next, nil
+	case typeUint128:
+		if size > 16 {
+			return nil, 0, fmt.Errorf("mmdb: invalid uint128 size %d", size)
+		}
+		return new(big.Int).SetBytes(b), next, nil
+	case typeContainer, typeEndMarker:
+		return nil, next, nil
+	}
+
+	return nil, 0, fmt.Errorf("mmdb: unknown data type %d", typeNum)
+}
+
+func (d *decoder) decodeMap(size uint, offset uint) (any, uint, error) {
+	d.depth++
+	defer func() { d.depth-- }()
+	if d.depth > maxDecodeDepth {
+		return nil, 0, errors.New("mmdb: max data nesting depth reached")
+	}
+
+	result := make(map[string]any, min(size, 64))
+
+	for i := uint(0); i < size; i++ {
+		rawKey, next, err := d.decode(offset)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		key, ok := rawKey.(string)
+		if !ok {
+			return nil, 0, errors.New("mmdb: invalid non-string map key")
+		}
+
+		value, next, err := d.decode(next)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		result[key] = value
+		offset = next
+	}
+
+	return result, offset, nil
+}
+
+func (d *decoder) decodeArray(size uint, offset uint) (any, uint, error) {
+	d.depth++
+	defer func() { d.depth-- }()
+	if d.depth > maxDecodeDepth {
+		return nil, 0, errors.New("mmdb: max data nesting depth reached")
+	}
+
+	result := make([]any, 0, min(size, 64))
+
+	for i := uint(0); i < size; i++ {
+		value, next, err := d.decode(offset)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		result = append(result, value)
+		offset = next
+	}
+
+	return result, offset, nil
+}
+
+func toUint(v any) uint {
+	switch n := v.(type) {
+	case uint64:
+		return uint(n)
+	case int64:
+		if n > 0 {
+			return uint(n)
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,373 @@
+package mmdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"time"
+)
+
+// Writer is a minimal MaxMind DB writer.
+//
+// It is intended mainly for generating small custom databases and test fixtures
+// (it doesn't deduplicate the data section and keeps the entire tree in memory).
+type Writer struct {
+	// DatabaseType is the database type stored in the metadata (eg. "GeoLite2-City").
+	DatabaseType string
+
+	// Description is an optional localized database description (eg. {"en": "..."}).
+	Description map[string]string
+
+	// Languages is an optional list of the locale codes of the database names.
+	Languages []string
+
+	// RecordSize is the search tree record size in bits (24, 28 or 32).
+	//
+	// Defaults to 28 if not set.
+	RecordSize int
+
+	// IPVersion is the database ip version (4 or 6).
+	//
+	// Defaults to 6 if not set.
+	IPVersion int
+
+	root *writerNode
+}
+
+type writerNode struct {
+	children [2]*writerNode
+	data     any
+	leaf     bool
+}
+
+// NewWriter creates a new IPv6 [Writer] with the specified database type.
+func NewWriter(databaseType string) *Writer {
+	return &Writer{DatabaseType: databaseType}
+}
+
+// Insert associates the specified network with the provided data.
+//
+// The data must be composed only by strings, bools, integers, floats,
+// []byte and maps (with string keys) or slices of those.
+//
+// Networks are applied in insertion order, aka. inserting a subnet of an
+// already inserted network overwrites the data only for the subnet range,
+// while inserting a supernet replaces all previous entries in its range.
+func (w *Writer) Insert(network *net.IPNet, data any) error {
+	if network == nil {
+		return errors.New("mmdb: nil network")
+	}
+
+	ip := network.IP
+	ones, bits := network.Mask.Size()
+	if ones == 0 && bits == 0 {
+		return errors.New("mmdb: invalid network mask")
+	}
+
+	if ipv4 := ip.To4(); ipv4 != nil && bits == 32 {
+		ip = ipv4
+		if w.ipVersion() == 6 {
+			// map IPv4 networks to the ::a.b.c.d subtree
+			ip = append(make(net.IP, 12), ipv4...)
+			ones += 96
+		}
+	} else if w.ipVersion() == 4 {
+		return errors.New("mmdb: cannot insert IPv6 network in an IPv4 database")
+	} else {
+		ip = ip.To16()
+	}
+
+	if ones == 0 {
+		return errors.New("mmdb: the network prefix must be non-empty")
+	}
+
+	if w.root == nil {
+		w.root = &writerNode{}
+	}
+
+	node := w.root
+	for i := 0; i < ones; i++ {
+		if node.leaf {
+			// split the existing network
+			node.children[0] = &writerNode{leaf: true, data: node.data}
+			node.children[1] = &writerNode{leaf: true, data: node.data}
+			node.leaf = false
+			node.data = nil
+		}
+
+		bit := (ip[i>>3] >> (7 - (uint(i) % 8))) & 1
+		if node.children[bit] == nil {
+			node.children[bit] = &writerNode{}
+		}
+		node = node.children[bit]
+	}
+
+	node.leaf = true
+	node.data = data
+	node.children = [2]*writerNode{}
+
+	return nil
+}
+
+// WriteTo serializes the database and writes it into dst.
+//
+// Implements [io.WriterTo] interface.
+func (w *Writer) WriteTo(dst io.Writer) (int64, error) {
+	recordSize := w.RecordSize
+	if recordSize == 0 {
+		recordSize = 28
+	}
+	if recordSize != 24 && recordSize != 28 && recordSize != 32 {
+		return 0, fmt.Errorf("mmdb: unsupported record size %d", recordSize)
+	}
+
+	root := w.root
+	if root == nil {
+		root = &writerNode{}
+	}
+
+	// number the search nodes in BFS order
+	nodes := []*writerNode{root}
+	numbers := map[*writerNode]int{root: 0}
+	for i := 0; i < len(nodes); i++ {
+		for _, child := range nodes[i].children {
+			if child != nil && !child.leaf {
+				numbers[child] = len(nodes)
+				nodes = append(nodes, child)
+			}
+		}
+	}
+
+	nodeCount := len(nodes)
+	maxRecord := uint64(1)<<recordSize - 1
+
+	data := &bytes.Buffer{}
+	tree := make([]byte, 0, nodeCount*recordSize/4)
+
+	for _, n := range nodes {
+		var records [2]uint64
+
+		for i, child := range n.children {
+			switch {
+			case child == nil:
+				records[i] = uint64(nodeCount)
+			case child.leaf:
+				offset := data.Len()
+				if err := encodeValue(data, child.data, 0); err != nil {
+					return 0, err
+				}
+				records[i] = uint64(nodeCount) + dataSectionSeparatorSize + uint64(offset)
+			default:
+				records[i] = uint64(numbers[child])
+			}
+
+			if records[i] > maxRecord {
+				return 0, fmt.Errorf("mmdb: the database is too large for record size %d", recordSize)
+			}
+		}
+
+		left, right := records[0], records[1]
+
+		switch recordSize {
+		case 24:
+			tree = append(tree,
+				byte(left>>16), byte(left>>8), byte(left),
+				byte(right>>16), byte(right>>8), byte(right),
+			)
+		case 28:
+			tree = append(tree,
+				byte(left>>16), byte(left>>8), byte(left),
+				byte((left>>20)&0xF0|(right>>24)&0x0F),
+				byte(right>>16), byte(right>>8), byte(right),
+			)
+		default:
+			tree = binary.BigEndian.AppendUint32(tree, uint32(left))
+			tree = binary.BigEndian.AppendUint32(tree, uint32(right))
+		}
+	}
+
+	metadata := map[string]any{
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(recordSize),
+		"ip_version":                  uint16(w.ipVersion()),
+		"database_type":               w.DatabaseType,
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(time.Now().Unix()),
+	}
+
+	languages := make([]any, len(w.Languages))
+	for i, lang := range w.Languages {
+		languages[i] = lang
+	}
+	metadata["languages"] = languages
+
+	description := make(map[string]any, len(w.Description))
+	for k, v := range w.Description {
+		description[k] = v
+	}
+	metadata["description"] = description
+
+	out := &bytes.Buffer{}
+	out.Write(tree)
+	out.Write(make([]byte, dataSectionSeparatorSize))
+	out.Write(data.Bytes())
+	out.Write(metadataStartMarker)
+	if err := encodeValue(out, metadata, 0); err != nil {
+		return 0, err
+	}
+
+	return out.WriteTo(dst)
+}
+
+func (w *Writer) ipVersion() int {
+	if w.IPVersion == 4 {
+		return 4
+	}
+
+	return 6
+}
+
+func encodeControl(buf *bytes.Buffer, typeNum int, size int) {
+	var sizeBits byte
+	var extra []byte
+
+	switch {
+	case size < 29:
+		sizeBits = byte(size)
+	case size < 285:
+		sizeBits = 29
+		extra = []byte{byte(size - 29)}
+	case size < 65821:
+		sizeBits = 30
+		s := size - 285
+		extra = []byte{byte(s >> 8), byte(s)}
+	default:
+		sizeBits = 31
+		s := size - 65821
+		extra = []byte{byte(s >> 16), byte(s >> 8), byte(s)}
+	}
+
+	if typeNum <= 7 {
+		buf.WriteByte(byte(typeNum)<<5 | sizeBits)
+	} else {
+		buf.WriteByte(sizeBits)
+		buf.WriteByte(byte(typeNum - 7))
+	}
+
+	buf.Write(extra)
+}
+
+func encodeUint(buf *bytes.Buffer, typeNum int, v uint64) {
+	var b []byte
+	for v > 0 {
+		b = append([]byte{byte(v)}, b...)
+		v >>= 8
+	}
+
+	encodeControl(buf, typeNum, len(b))
+	buf.Write(b)
+}
+
+func encodeValue(buf *bytes.Buffer, value any, depth int) error {
+	if depth > maxDecodeDepth {
+		return errors.New("mmdb: max data nesting depth reached")
+	}
+
+	switch v := value.(type) {
+	case nil:
+		// there is no null type so fallback to an empty map
+		encodeControl(buf, typeMap, 0)
+	case string:
+		encodeControl(buf, typeString, len(v))
+		buf.WriteString(v)
+	case []byte:
+		encodeControl(buf, typeBytes, len(v))
+		buf.Write(v)
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		encodeControl(buf, typeBool, size)
+	case float64:
+		encodeControl(buf, typeDouble, 8)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+	case float32:
+		encodeControl(buf, typeFloat, 4)
+		buf.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(v)))
+	case uint16:
+		encodeUint(buf, typeUint16, uint64(v))
+	case uint32:
+		encodeUint(buf, typeUint32, uint64(v))
+	case uint64:
+		encodeUint(buf, typeUint64, v)
+	case uint:
+		encodeUint(buf, typeUint64, uint64(v))
+	case int:
+		return encodeInt(buf, int64(v))
+	case int32:
+		return encodeInt(buf, int64(v))
+	case int64:
+		return encodeInt(buf, v)
+	case map[string]string:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[k] = item
+		}
+		return encodeValue(buf, m, depth)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		encodeControl(buf, typeMap, len(keys))
+		for _, k := range keys {
+			encodeControl(buf, typeString, len(k))
+			buf.WriteString(k)
+			if err := encodeValue(buf, v[k], depth+1); err != nil {
+				return err
+			}
+		}
+	case []string:
+		encodeControl(buf, typeArray, len(v))
+		for _, item := range v {
+			encodeControl(buf, typeString, len(item))
+			buf.WriteString(item)
+		}
+	case []any:
+		encodeControl(buf, typeArray, len(v))
+		for _, item := range v {
+			if err := encodeValue(buf, item, depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("mmdb: unsupported data type %T", value)
+	}
+
+	return nil
+}
+
+func encodeInt(buf *bytes.Buffer, v int64) error {
+	if v >= 0 {
+		encodeUint(buf, typeUint64, uint64(v))
+		return nil
+	}
+
+	if v < math.MinInt32 {
+		return fmt.Errorf("mmdb: negative integer %d is out of the int32 range", v)
+	}
+
+	encodeControl(buf, typeInt32, 4)
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(int32(v))))
+
+	return nil
+}
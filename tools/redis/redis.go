@@ -0,0 +1,424 @@
+// Package redis implements a minimal RESP2 Redis client with a small
+// connection pool and helpers for the most common key-value commands.
+//
+// Example usage:
+//
+//	client := redis.New(redis.Options{
+//		Addr:     "localhost:6379",
+//		Password: "...",
+//	})
+//	defer client.Close()
+//
+//	err := client.Set(ctx, "key", []byte("value"), 5*time.Minute)
+//
+//	value, ok, err := client.Get(ctx, "key")
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDialTimeout is the default max duration for establishing a new connection.
+	DefaultDialTimeout = 5 * time.Second
+
+	// DefaultTimeout is the default max duration of a single command
+	// (used only if the command context doesn't have a deadline).
+	DefaultTimeout = 3 * time.Second
+
+	// DefaultPoolSize is the default max number of idle connections.
+	DefaultPoolSize = 10
+)
+
+// ErrClosed is returned when trying to execute a command with a closed client.
+var ErrClosed = errors.New("redis: client is closed")
+
+// Error defines a Redis error reply (e.g. "WRONGTYPE Operation against a key...").
+type Error string
+
+// Error implements the [error] interface.
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+// Options defines the Redis client options.
+type Options struct {
+	// Addr is the Redis server "host:port" address.
+	Addr string
+
+	// Username is the optional ACL username.
+	Username string
+
+	// Password is the optional auth password.
+	Password string
+
+	// DB is the db number to select after connecting.
+	DB int
+
+	// DialTimeout is the max duration for establishing a new connection
+	// (default to [DefaultDialTimeout]).
+	DialTimeout time.Duration
+
+	// Timeout is the max duration of a single command if the context
+	// doesn't have a deadline (default to [DefaultTimeout]).
+	Timeout time.Duration
+
+	// PoolSize is the max number of idle connections (default to [DefaultPoolSize]).
+	PoolSize int
+}
+
+// Client defines a Redis client that is safe for concurrent use.
+type Client struct {
+	opts Options
+
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+// New creates a new Redis client with the provided options.
+//
+// The connections are established lazily on the first command.
+func New(opts Options) *Client {
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = DefaultDialTimeout
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = DefaultPoolSize
+	}
+
+	return &Client{opts: opts}
+}
+
+// Close closes all idle connections and prevents executing new commands.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+
+	var errs []error
+	for _, cn := range c.idle {
+		errs = append(errs, cn.Close())
+	}
+	c.idle = nil
+
+	return errors.Join(errs...)
+}
+
+// Do executes a single Redis command and returns its raw reply.
+//
+// The reply could be nil, string (for simple strings), []byte (for bulk strings),
+// int64 or []any depending on the command.
+//
+// Redis error replies are returned as [Error].
+func (c *Client) Do(ctx context.Context, args ...any) (any, error) {
+	cn, err := c.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.do(ctx, c.opts.Timeout, args...)
+
+	// the connection state is unknown on I/O errors so it is safer to discard it
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		cn.Close()
+		return nil, err
+	}
+
+	c.putConn(cn)
+
+	return reply, err
+}
+
+// Ping checks the server connectivity.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+
+	return err
+}
+
+// Get returns the value of the specified key and whether it was found.
+func (c *Client) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := c.Do(ctx, "GET", key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if reply == nil {
+		return nil, false, nil
+	}
+
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+
+	return value, true, nil
+}
+
+// Set stores the value under the specified key.
+//
+// The key expires after ttl (0 or negative value means no expiration).
+func (c *Client) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []any{"SET", key, value}
+
+	if ttl > 0 {
+		args = append(args, "PX", max(ttl.Milliseconds(), 1))
+	}
+
+	_, err := c.Do(ctx, args...)
+
+	return err
+}
+
+// Del removes the specified keys.
+//
+// Missing keys are ignored.
+func (c *Client) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, k := range keys {
+		args = append(args, k)
+	}
+
+	_, err := c.Do(ctx, args...)
+
+	return err
+}
+
+func (c *Client) getConn(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+
+	c.mu.Unlock()
+
+	return c.dial(ctx)
+}
+
+func (c *Client) putConn(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed || len(c.idle) >= c.opts.PoolSize {
+		cn.Close()
+		return
+	}
+
+	c.idle = append(c.idle, cn)
+}
+
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	dialer := net.Dialer{Timeout: c.opts.DialTimeout}
+
+	nc, err := dialer.DialContext(ctx, "tcp", c.opts.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	cn := &conn{
+		Conn: nc,
+		r:    bufio.NewReader(nc),
+		w:    bufio.NewWriter(nc),
+	}
+
+	if c.opts.Password != "" {
+		args := []any{"AUTH", c.opts.Password}
+		if c.opts.Username != "" {
+			args = []any{"AUTH", c.opts.Username, c.opts.Password}
+		}
+
+		if _, err := cn.do(ctx, c.opts.Timeout, args...); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+
+	if c.opts.DB > 0 {
+		if _, err := cn.do(ctx, c.opts.Timeout, "SELECT", c.opts.DB); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+
+	return cn, nil
+}
+
+// -------------------------------------------------------------------
+
+type conn struct {
+	net.Conn
+
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func (cn *conn) do(ctx context.Context, timeout time.Duration, args ...any) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(timeout)
+	}
+
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if err := writeCommand(cn.w, args...); err != nil {
+		return nil, err
+	}
+
+	if err := cn.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return readReply(cn.r)
+}
+
+// writeCommand writes the provided args as RESP array of bulk strings.
+func writeCommand(w *bufio.Writer, args ...any) error {
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		var b []byte
+		switch v := arg.(type) {
+		case []byte:
+			b = v
+		case string:
+			b = []byte(v)
+		case int:
+			b = strconv.AppendInt(nil, int64(v), 10)
+		case int64:
+			b = strconv.AppendInt(nil, v, 10)
+		default:
+			return fmt.Errorf("redis: unsupported argument type %T", arg)
+		}
+
+		if _, err := fmt.Fprintf(w, "$%d\r\n", len(b)); err != nil {
+			return err
+		}
+
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+
+		if _, err := w.WriteString("\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readReply reads and parses a single RESP2 reply.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	payload := string(line[1:])
+
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, Error(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		if size < 0 {
+			return nil, nil // nil bulk string
+		}
+
+		b := make([]byte, size+2) // +\r\n
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+
+		return b[:size], nil
+	case '*':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		if size < 0 {
+			return nil, nil // nil array
+		}
+
+		items := make([]any, size)
+		for i := range items {
+			item, err := readReply(r)
+
+			// nested error replies are returned as values
+			var replyErr Error
+			if errors.As(err, &replyErr) {
+				items[i] = replyErr
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			items[i] = item
+		}
+
+		return items, nil
+	}
+
+	return nil, fmt.Errorf("redis: unknown reply type %q", line[0])
+}
+
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: invalid reply line")
+	}
+
+	return line[:len(line)-2], nil
+}
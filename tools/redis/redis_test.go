@@ -0,0 +1,265 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer is a minimal in-memory Redis server used for testing.
+type fakeServer struct {
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	data     map[string][]byte
+	expires  map[string]time.Time
+	commands []string
+}
+
+func newFakeServer(t *testing.T, password string) *fakeServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeServer{
+		listener: l,
+		password: password,
+		data:     map[string][]byte{},
+		expires:  map[string]time.Time{},
+	}
+
+	go func() {
+		for {
+			nc, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(nc)
+		}
+	}()
+
+	t.Cleanup(func() { l.Close() })
+
+	return s
+}
+
+func (s *fakeServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeServer) serve(nc net.Conn) {
+	defer nc.Close()
+
+	r := bufio.NewReader(nc)
+	w := bufio.NewWriter(nc)
+
+	authenticated := s.password == ""
+
+	for {
+		raw, err := readReply(r)
+		if err != nil {
+			return
+		}
+
+		items, _ := raw.([]any)
+		args := make([]string, len(items))
+		for i, item := range items {
+			b, _ := item.([]byte)
+			args[i] = string(b)
+		}
+
+		if len(args) == 0 {
+			return
+		}
+
+		cmd := strings.ToUpper(args[0])
+
+		s.mu.Lock()
+		s.commands = append(s.commands, strings.Join(args, " "))
+
+		switch {
+		case cmd == "AUTH":
+			if args[len(args)-1] == s.password {
+				authenticated = true
+				w.WriteString("+OK\r\n")
+			} else {
+				w.WriteString("-WRONGPASS invalid password\r\n")
+			}
+		case !authenticated:
+			w.WriteString("-NOAUTH Authentication required.\r\n")
+		case cmd == "PING":
+			w.WriteString("+PONG\r\n")
+		case cmd == "SELECT":
+			w.WriteString("+OK\r\n")
+		case cmd == "SET":
+			s.data[args[1]] = []byte(args[2])
+			delete(s.expires, args[1])
+			if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+				ms, _ := strconv.Atoi(args[4])
+				s.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			}
+			w.WriteString("+OK\r\n")
+		case cmd == "GET":
+			v, ok := s.data[args[1]]
+			if exp, hasExp := s.expires[args[1]]; hasExp && time.Now().After(exp) {
+				ok = false
+			}
+			if ok {
+				w.WriteString("$" + strconv.Itoa(len(v)) + "\r\n" + string(v) + "\r\n")
+			} else {
+				w.WriteString("$-1\r\n")
+			}
+		case cmd == "DEL":
+			var total int
+			for _, k := range args[1:] {
+				if _, ok := s.data[k]; ok {
+					delete(s.data, k)
+					total++
+				}
+			}
+			w.WriteString(":" + strconv.Itoa(total) + "\r\n")
+		case cmd == "MIXED":
+			w.WriteString("*4\r\n+a\r\n:1\r\n$-1\r\n-ERR b\r\n")
+		default:
+			w.WriteString("-ERR unknown command '" + args[0] + "'\r\n")
+		}
+		s.mu.Unlock()
+
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func TestClientCommands(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeServer(t, "")
+
+	client := New(Options{Addr: server.addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := client.Get(ctx, "missing"); ok || err != nil {
+		t.Fatalf("Expected missing key, got ok %v (%v)", ok, err)
+	}
+
+	if err := client.Set(ctx, "a", []byte("hello\r\nworld"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	value, ok, err := client.Get(ctx, "a")
+	if err != nil || !ok || string(value) != "hello\r\nworld" {
+		t.Fatalf("Expected the stored value, got %q, %v (%v)", value, ok, err)
+	}
+
+	if err := client.Set(ctx, "b", []byte("b"), 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	if _, ok, _ := client.Get(ctx, "b"); ok {
+		t.Fatal("Expected the key to be expired")
+	}
+
+	if err := client.Del(ctx, "a", "missing"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, _ := client.Get(ctx, "a"); ok {
+		t.Fatal("Expected the key to be deleted")
+	}
+
+	reply, err := client.Do(ctx, "MIXED")
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, _ := reply.([]any)
+	if len(items) != 4 || items[0] != "a" || items[1] != int64(1) || items[2] != nil || items[3] != Error("ERR b") {
+		t.Fatalf("Unexpected array reply %#v", reply)
+	}
+
+	_, err = client.Do(ctx, "UNKNOWN")
+	var replyErr Error
+	if !errors.As(err, &replyErr) {
+		t.Fatalf("Expected Error reply, got %v", err)
+	}
+
+	// the connection should be reused after error replies
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(client.idle) != 1 {
+		t.Fatalf("Expected 1 idle connection, got %d", len(client.idle))
+	}
+
+	expectedCommands := []string{"PING", "GET missing", "SET a hello\r\nworld", "GET a", "SET b b PX 20"}
+	for i, cmd := range expectedCommands {
+		if server.commands[i] != cmd {
+			t.Fatalf("Expected command %d to be %q, got %q", i, cmd, server.commands[i])
+		}
+	}
+}
+
+func TestClientAuth(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeServer(t, "secret")
+
+	ctx := context.Background()
+
+	invalid := New(Options{Addr: server.addr(), Password: "invalid"})
+	defer invalid.Close()
+
+	if err := invalid.Ping(ctx); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Fatalf("Expected WRONGPASS error, got %v", err)
+	}
+
+	valid := New(Options{Addr: server.addr(), Username: "user", Password: "secret", DB: 2})
+	defer valid.Close()
+
+	if err := valid.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	expectedCommands := []string{"AUTH invalid", "AUTH user secret", "SELECT 2", "PING"}
+	if strings.Join(server.commands, ",") != strings.Join(expectedCommands, ",") {
+		t.Fatalf("Expected commands %v, got %v", expectedCommands, server.commands)
+	}
+}
+
+func TestClientClose(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeServer(t, "")
+
+	client := New(Options{Addr: server.addr()})
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Ping(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Expected ErrClosed, got %v", err)
+	}
+}
@@ -1,17 +1,22 @@
 package router
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"io"
 	"io/fs"
+	"iter"
+	"mime/multipart"
 	"net"
 	"net/http"
 	"net/netip"
 	"path/filepath"
+	"reflect"
 	"strings"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/picker"
@@ -286,6 +291,134 @@ func (e *Event) Redirect(status int, url string) error {
 	return nil
 }
 
+// CSV writes a CSV response from the provided records
+// (the first record is usually the columns header).
+func (e *Event) CSV(status int, records [][]string) error {
+	e.setResponseHeaderIfEmpty(headerContentType, "text/csv; charset=utf-8")
+	e.Response.WriteHeader(status)
+
+	w := csv.NewWriter(e.Response)
+	if err := w.WriteAll(records); err != nil {
+		return err
+	}
+
+	return w.Error()
+}
+
+// Problem writes an RFC 9457 "application/problem+json" response.
+//
+// The problem Status, Title and Instance fields are defaulted
+// respectively to 500, the status text and the request path.
+func (e *Event) Problem(problem Problem) error {
+	if problem.Status == 0 {
+		problem.Status = http.StatusInternalServerError
+	}
+
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	if problem.Instance == "" {
+		problem.Instance = e.Request.URL.Path
+	}
+
+	e.setResponseHeaderIfEmpty(headerContentType, "application/problem+json")
+	e.Response.WriteHeader(problem.Status)
+
+	return json.NewEncoder(e.Response).Encode(problem)
+}
+
+// Negotiate writes data in the format that best matches the
+// request Accept header - JSON (default), XML or CSV.
+//
+// CSV is considered only if data is [][]string or implements [CSVMarshaler].
+// For all other cases (including when no supported format is accepted) it fallbacks to JSON.
+func (e *Event) Negotiate(status int, data any) error {
+	var csvMarshaler CSVMarshaler
+	records, isCSV := data.([][]string)
+	if !isCSV {
+		csvMarshaler, isCSV = data.(CSVMarshaler)
+	}
+
+	offers := []string{"application/json", "application/xml", "text/xml"}
+	if isCSV {
+		offers = append(offers, "text/csv")
+	}
+
+	switch NegotiateContentType(e.Request.Header.Get("Accept"), offers...) {
+	case "application/xml", "text/xml":
+		return e.XML(status, data)
+	case "text/csv":
+		if csvMarshaler != nil {
+			var err error
+			records, err = csvMarshaler.MarshalCSV()
+			if err != nil {
+				return err
+			}
+		}
+		return e.CSV(status, records)
+	default:
+		return e.JSON(status, data)
+	}
+}
+
+// StreamJSONLines writes a newline delimited JSON ("application/x-ndjson") response
+// by encoding each item of the provided sequence and flushing it to the client.
+//
+// Note that the iteration error cannot change the already sent response status.
+func (e *Event) StreamJSONLines(status int, items iter.Seq2[any, error]) error {
+	e.setResponseHeaderIfEmpty(headerContentType, "application/x-ndjson")
+	e.Response.WriteHeader(status)
+
+	enc := json.NewEncoder(e.Response)
+
+	for item, err := range items {
+		if err != nil {
+			return err
+		}
+
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+
+		// best-effort (the flushing may not be supported by a custom ResponseWriter)
+		_ = e.Flush()
+	}
+
+	return nil
+}
+
+// StreamCSV writes a CSV response by encoding each record of the provided
+// sequence and flushing it to the client.
+//
+// Note that the iteration error cannot change the already sent response status.
+func (e *Event) StreamCSV(status int, records iter.Seq2[[]string, error]) error {
+	e.setResponseHeaderIfEmpty(headerContentType, "text/csv; charset=utf-8")
+	e.Response.WriteHeader(status)
+
+	w := csv.NewWriter(e.Response)
+
+	for record, err := range records {
+		if err != nil {
+			return err
+		}
+
+		if err := w.Write(record); err != nil {
+			return err
+		}
+
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+
+		// best-effort (the flushing may not be supported by a custom ResponseWriter)
+		_ = e.Flush()
+	}
+
+	return nil
+}
+
 // ApiError helpers
 // -------------------------------------------------------------------
 
@@ -322,7 +455,8 @@ func (e *Event) InternalServerError(message string, errData any) *ApiError {
 
 const DefaultMaxMemory = 32 << 20 // 32mb
 
-// BindBody unmarshal the request body into the provided dst.
+// BindBody unmarshal the request body into the provided dst
+// and validates it (see [ValidateRequestData]).
 //
 // dst must be either a struct pointer or map[string]any.
 //
@@ -338,6 +472,13 @@ const DefaultMaxMemory = 32 << 20 // 32mb
 //   - "xml" (xml body) - uses the builtin Go xml package for unmarshaling.
 //   - "form" (form data) - utilizes the custom [router.UnmarshalRequestData] method.
 //
+// For multipart/form-data requests the uploaded files are loaded
+// in the *filesystem.File and []*filesystem.File struct fields.
+//
+// The struct fields could be also validated with the "validate" tag
+// (required, min/max, email, e164, regex). On failed validation
+// a 400 [ApiError] with the fields errors is returned.
+//
 // NB! When dst is a struct make sure that it doesn't have public fields
 // that shouldn't be bindable and it is advisible such fields to be unexported
 // or have a separate struct just for the binding. For example:
@@ -345,17 +486,45 @@ const DefaultMaxMemory = 32 << 20 // 32mb
 //	data := struct{
 //	   somethingPrivate string
 //
-//	   Title string `json:"title" form:"title"`
-//	   Total int    `json:"total" form:"total"`
+//	   Title  string           `json:"title" form:"title" validate:"required,max=100"`
+//	   Total  int              `json:"total" form:"total" validate:"min=1"`
+//	   Phone  string           `json:"phone" form:"phone" validate:"e164"`
+//	   Avatar *filesystem.File `form:"avatar"`
 //	}
 //	err := e.BindBody(&data)
 func (e *Event) BindBody(dst any) error {
+	contentType := e.Request.Header.Get(headerContentType)
+
+	if err := e.bindBody(dst, contentType); err != nil {
+		return err
+	}
+
+	structTagKey := "json"
+	if strings.HasPrefix(contentType, "multipart/form-data") ||
+		strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		structTagKey = "form"
+	} else if strings.HasPrefix(contentType, "text/xml") ||
+		strings.HasPrefix(contentType, "application/xml") {
+		structTagKey = "xml"
+	}
+
+	err := ValidateRequestData(dst, structTagKey)
+	if err != nil {
+		var validationErrs validation.Errors
+		if errors.As(err, &validationErrs) {
+			return NewBadRequestError("Failed to validate the submitted data.", validationErrs)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (e *Event) bindBody(dst any, contentType string) error {
 	if e.Request.ContentLength == 0 {
 		return nil
 	}
 
-	contentType := e.Request.Header.Get(headerContentType)
-
 	if strings.HasPrefix(contentType, "application/json") {
 		dec := json.NewDecoder(e.Request.Body)
 		err := dec.Decode(dst)
@@ -376,7 +545,11 @@ func (e *Event) BindBody(dst any) error {
 			return err
 		}
 
-		return UnmarshalRequestData(e.Request.Form, dst, "", "")
+		if err := UnmarshalRequestData(e.Request.Form, dst, "", ""); err != nil {
+			return err
+		}
+
+		return bindMultipartFiles(e.Request.MultipartForm, dst)
 	}
 
 	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
@@ -394,3 +567,81 @@ func (e *Event) BindBody(dst any) error {
 
 	return ErrUnsupportedContentType
 }
+
+// bindMultipartFiles loads the multipart form files into the
+// *filesystem.File and []*filesystem.File fields of the dst struct.
+func bindMultipartFiles(form *multipart.Form, dst any) error {
+	if form == nil || len(form.File) == 0 {
+		return nil
+	}
+
+	dstValue := reflect.ValueOf(dst)
+	for dstValue.Kind() == reflect.Pointer {
+		if dstValue.IsNil() {
+			return nil
+		}
+		dstValue = dstValue.Elem()
+	}
+
+	if dstValue.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return bindMultipartFilesInStructValue(form.File, dstValue)
+}
+
+func bindMultipartFilesInStructValue(files map[string][]*multipart.FileHeader, structValue reflect.Value) error {
+	structType := structValue.Type()
+
+	for i := 0; i < structValue.NumField(); i++ {
+		fieldType := structType.Field(i)
+
+		tag := fieldType.Tag.Get("form")
+		if tag == "-" {
+			continue
+		}
+
+		fieldValue := structValue.Field(i)
+
+		// embedded struct
+		if fieldType.Anonymous && tag == "" {
+			if fieldValue.Kind() == reflect.Struct {
+				if err := bindMultipartFilesInStructValue(files, fieldValue); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if !fieldType.IsExported() || !isFileType(fieldType.Type) {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = fieldType.Name
+		}
+
+		headers := files[name]
+		if len(headers) == 0 {
+			continue
+		}
+
+		result := make([]*filesystem.File, 0, len(headers))
+		for _, fh := range headers {
+			file, err := filesystem.NewFileFromMultipart(fh)
+			if err != nil {
+				return err
+			}
+			result = append(result, file)
+		}
+
+		if fieldType.Type == fileSliceType {
+			fieldValue.Set(reflect.ValueOf(result))
+		} else {
+			fieldValue.Set(reflect.ValueOf(result[0]))
+		}
+	}
+
+	return nil
+}
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/router"
 )
 
@@ -520,6 +521,196 @@ func TestEventBlob(t *testing.T) {
 	}
 }
 
+func TestEventCSV(t *testing.T) {
+	scenarios := []testResponseWriteScenario[[][]string]{
+		{
+			name:            "no explicit content-type",
+			status:          234,
+			headers:         nil,
+			body:            [][]string{{"a", "b"}, {"1", "2,3"}},
+			expectedStatus:  234,
+			expectedHeaders: map[string]string{"content-type": "text/csv; charset=utf-8"},
+			expectedBody:    "a,b\n1,\"2,3\"\n",
+		},
+		{
+			name:            "with explicit content-type",
+			status:          234,
+			headers:         map[string]string{"content-type": "text/test"},
+			body:            [][]string{{"a"}},
+			expectedStatus:  234,
+			expectedHeaders: map[string]string{"content-type": "text/test"},
+			expectedBody:    "a\n",
+		},
+	}
+
+	for _, s := range scenarios {
+		testEventResponseWrite(t, s, func(e *router.Event) error {
+			return e.CSV(s.status, s.body)
+		})
+	}
+}
+
+func TestEventProblem(t *testing.T) {
+	scenarios := []testResponseWriteScenario[router.Problem]{
+		{
+			name:            "defaults",
+			body:            router.Problem{},
+			expectedStatus:  500,
+			expectedHeaders: map[string]string{"content-type": "application/problem+json"},
+			expectedBody:    `{"instance":"/","status":500,"title":"Internal Server Error","type":"about:blank"}` + "\n",
+		},
+		{
+			name: "custom fields",
+			body: router.Problem{
+				Type:       "https://example.com/out-of-credit",
+				Title:      "Out of credit",
+				Detail:     "Your balance is 30.",
+				Instance:   "/account/123",
+				Status:     403,
+				Extensions: map[string]any{"balance": 30, "status": 1},
+			},
+			expectedStatus:  403,
+			expectedHeaders: map[string]string{"content-type": "application/problem+json"},
+			expectedBody:    `{"balance":30,"detail":"Your balance is 30.","instance":"/account/123","status":403,"title":"Out of credit","type":"https://example.com/out-of-credit"}` + "\n",
+		},
+	}
+
+	for _, s := range scenarios {
+		testEventResponseWrite(t, s, func(e *router.Event) error {
+			return e.Problem(s.body)
+		})
+	}
+}
+
+type testCSVMarshaler struct{}
+
+func (testCSVMarshaler) MarshalCSV() ([][]string, error) {
+	return [][]string{{"a", "b"}}, nil
+}
+
+func TestEventNegotiate(t *testing.T) {
+	scenarios := []struct {
+		accept          string
+		data            any
+		expectedHeaders map[string]string
+		expectedBody    string
+	}{
+		{
+			"",
+			[][]string{{"a"}},
+			map[string]string{"content-type": "application/json"},
+			`[["a"]]` + "\n",
+		},
+		{
+			"application/xml",
+			"test",
+			map[string]string{"content-type": "application/xml; charset=utf-8"},
+			xml.Header + "<string>test</string>",
+		},
+		{
+			"text/html, text/*;q=0.8",
+			"test",
+			map[string]string{"content-type": "application/xml; charset=utf-8"},
+			xml.Header + "<string>test</string>",
+		},
+		{
+			"text/csv",
+			"test", // not CSV serializable
+			map[string]string{"content-type": "application/json"},
+			`"test"` + "\n",
+		},
+		{
+			"text/csv",
+			[][]string{{"a"}, {"b"}},
+			map[string]string{"content-type": "text/csv; charset=utf-8"},
+			"a\nb\n",
+		},
+		{
+			"application/json;q=0.5, text/csv",
+			testCSVMarshaler{},
+			map[string]string{"content-type": "text/csv; charset=utf-8"},
+			"a,b\n",
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := testResponseWriteScenario[any]{
+			name:            s.accept + "_" + fmt.Sprintf("%T", s.data),
+			status:          234,
+			body:            s.data,
+			expectedStatus:  234,
+			expectedHeaders: s.expectedHeaders,
+			expectedBody:    s.expectedBody,
+		}
+
+		testEventResponseWrite(t, scenario, func(e *router.Event) error {
+			e.Request.Header.Set("Accept", s.accept)
+			return e.Negotiate(scenario.status, scenario.body)
+		})
+	}
+}
+
+func TestEventStreamJSONLines(t *testing.T) {
+	iterErr := errors.New("test")
+
+	scenarios := []testResponseWriteScenario[[]any]{
+		{
+			name:            "items",
+			status:          234,
+			body:            []any{1, "a", map[string]any{"b": true}},
+			expectedStatus:  234,
+			expectedHeaders: map[string]string{"content-type": "application/x-ndjson"},
+			expectedBody:    "1\n\"a\"\n{\"b\":true}\n",
+		},
+		{
+			name:            "iteration error",
+			status:          234,
+			body:            []any{1, iterErr, 2},
+			expectedStatus:  234,
+			expectedHeaders: map[string]string{"content-type": "application/x-ndjson"},
+			expectedBody:    "1\n",
+			expectedError:   iterErr,
+		},
+	}
+
+	for _, s := range scenarios {
+		testEventResponseWrite(t, s, func(e *router.Event) error {
+			return e.StreamJSONLines(s.status, func(yield func(any, error) bool) {
+				for _, item := range s.body {
+					err, _ := item.(error)
+					if err != nil {
+						item = nil
+					}
+					if !yield(item, err) {
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestEventStreamCSV(t *testing.T) {
+	s := testResponseWriteScenario[[][]string]{
+		name:            "records",
+		status:          234,
+		body:            [][]string{{"a", "b"}, {"1", "2"}},
+		expectedStatus:  234,
+		expectedHeaders: map[string]string{"content-type": "text/csv; charset=utf-8"},
+		expectedBody:    "a,b\n1,2\n",
+	}
+
+	testEventResponseWrite(t, s, func(e *router.Event) error {
+		return e.StreamCSV(s.status, func(yield func([]string, error) bool) {
+			for _, record := range s.body {
+				if !yield(record, nil) {
+					return
+				}
+			}
+		})
+	})
+}
+
 func TestEventNoContent(t *testing.T) {
 	s := testResponseWriteScenario[any]{
 		name:            "no content",
@@ -885,6 +1076,115 @@ func TestEventBindBody(t *testing.T) {
 	}
 }
 
+func TestEventBindBodyValidation(t *testing.T) {
+	type testDstStruct struct {
+		Name  string `json:"name" form:"name" validate:"required,min=3"`
+		Phone string `json:"phone" form:"phone" validate:"e164"`
+	}
+
+	scenarios := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedErrors []string
+	}{
+		{"empty body", "application/json", "", []string{"name"}},
+		{"invalid json data", "application/json", `{"name":"ab","phone":"abc"}`, []string{"name", "phone"}},
+		{"valid json data", "application/json", `{"name":"abc","phone":"+359888123456"}`, nil},
+		{"invalid form data", "application/x-www-form-urlencoded", "name=abc&phone=abc", []string{"phone"}},
+		{"valid form data", "application/x-www-form-urlencoded", "name=abc", nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/", strings.NewReader(s.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Add("content-type", s.contentType)
+
+			event := &router.Event{Request: req}
+
+			err = event.BindBody(&testDstStruct{})
+
+			if len(s.expectedErrors) == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var apiErr *router.ApiError
+			if !errors.As(err, &apiErr) || apiErr.Status != http.StatusBadRequest {
+				t.Fatalf("Expected 400 ApiError, got %v", err)
+			}
+
+			if len(apiErr.Data) != len(s.expectedErrors) {
+				t.Fatalf("Expected %d field errors, got %v", len(s.expectedErrors), apiErr.Data)
+			}
+
+			for _, k := range s.expectedErrors {
+				if _, ok := apiErr.Data[k]; !ok {
+					t.Fatalf("Missing %q field error in %v", k, apiErr.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestEventBindBodyMultipartFiles(t *testing.T) {
+	type testDstStruct struct {
+		Title   string             `form:"title"`
+		Avatar  *filesystem.File   `form:"avatar" validate:"required"`
+		Gallery []*filesystem.File `form:"gallery" validate:"max=2"`
+		Missing *filesystem.File   `form:"missing"`
+	}
+
+	body := &bytes.Buffer{}
+	mp := multipart.NewWriter(body)
+	mp.WriteField("title", "test")
+	for _, name := range []string{"avatar", "gallery", "gallery"} {
+		w, err := mp.CreateFormFile(name, name+".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte("test"))
+	}
+	if err := mp.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("content-type", mp.FormDataContentType())
+
+	event := &router.Event{Request: req}
+
+	dst := testDstStruct{}
+
+	if err := event.BindBody(&dst); err != nil {
+		t.Fatal(err)
+	}
+
+	if dst.Title != "test" {
+		t.Fatalf("Expected title %q, got %q", "test", dst.Title)
+	}
+
+	if dst.Avatar == nil || dst.Avatar.OriginalName != "avatar.txt" {
+		t.Fatalf("Expected avatar.txt file, got %v", dst.Avatar)
+	}
+
+	if len(dst.Gallery) != 2 {
+		t.Fatalf("Expected 2 gallery files, got %d", len(dst.Gallery))
+	}
+
+	if dst.Missing != nil {
+		t.Fatalf("Expected nil missing file, got %v", dst.Missing)
+	}
+}
+
 // -------------------------------------------------------------------
 
 type testResponseWriteScenario[T any] struct {
@@ -28,12 +28,19 @@ type RouterGroup[T hook.Resolver] struct {
 // and will be concatenated recursively into the final route path, meaning that
 // only the root level group could have HOST as part of the prefix.
 //
+// Optionally you can also specify one or more group middlewares
+// (it is the same as calling [RouterGroup.Bind] on the new group).
+//
 // Returns the newly created group to allow chaining and registering
 // sub-routes and group specific middlewares.
-func (group *RouterGroup[T]) Group(prefix string) *RouterGroup[T] {
+func (group *RouterGroup[T]) Group(prefix string, middlewares ...*hook.Handler[T]) *RouterGroup[T] {
 	newGroup := &RouterGroup[T]{}
 	newGroup.Prefix = prefix
 
+	if len(middlewares) > 0 {
+		newGroup.Bind(middlewares...)
+	}
+
 	group.children = append(group.children, newGroup)
 
 	return newGroup
@@ -30,6 +30,29 @@ func TestRouterGroupGroup(t *testing.T) {
 	}
 }
 
+func TestRouterGroupGroupWithMiddlewares(t *testing.T) {
+	t.Parallel()
+
+	g0 := RouterGroup[*Event]{}
+
+	g1 := g0.Group("test1",
+		&hook.Handler[*Event]{Id: "a"},
+		&hook.Handler[*Event]{Id: "b"},
+	)
+
+	if total := len(g1.Middlewares); total != 2 {
+		t.Fatalf("Expected %d middlewares, got %d", 2, total)
+	}
+
+	if g1.Middlewares[0].Id != "a" || g1.Middlewares[1].Id != "b" {
+		t.Fatalf("Expected middlewares a and b, got %q and %q", g1.Middlewares[0].Id, g1.Middlewares[1].Id)
+	}
+
+	if total := len(g0.Middlewares); total != 0 {
+		t.Fatalf("Expected no parent group middlewares, got %d", total)
+	}
+}
+
 func TestRouterGroupBindFunc(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,152 @@
+package router
+
+import (
+	"encoding/json"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// CSVMarshaler defines an interface for data that could be
+// serialized as CSV records (see [Event.Negotiate]).
+type CSVMarshaler interface {
+	// MarshalCSV returns the CSV records of the data
+	// (the first record is usually the columns header).
+	MarshalCSV() ([][]string, error)
+}
+
+// Problem defines an RFC 9457 problem details response body.
+type Problem struct {
+	// Extensions specifies additional problem members
+	// (they cannot override the standard ones).
+	Extensions map[string]any
+
+	Type     string
+	Title    string
+	Detail   string
+	Instance string
+	Status   int
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	data := make(map[string]any, len(p.Extensions)+5)
+
+	maps.Copy(data, p.Extensions)
+
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	data["type"] = p.Type
+
+	data["status"] = p.Status
+
+	if p.Title != "" {
+		data["title"] = p.Title
+	}
+
+	if p.Detail != "" {
+		data["detail"] = p.Detail
+	}
+
+	if p.Instance != "" {
+		data["instance"] = p.Instance
+	}
+
+	return json.Marshal(data)
+}
+
+// NewProblemFromError creates a new [Problem] from the provided error.
+//
+// The error is first wrapped with [ToApiError] and its
+// message and data are used as problem detail and "errors" extension.
+func NewProblemFromError(err error) Problem {
+	apiErr := ToApiError(err)
+
+	problem := Problem{
+		Status: apiErr.Status,
+		Detail: apiErr.Message,
+	}
+
+	if len(apiErr.Data) > 0 {
+		problem.Extensions = map[string]any{"errors": apiErr.Data}
+	}
+
+	return problem
+}
+
+// NegotiateContentType returns the offer that best matches the
+// provided Accept header value based on its quality factors.
+//
+// It returns the first offer if the header is empty or
+// if none of the offers are accepted.
+//
+// Example:
+//
+//	router.NegotiateContentType("text/csv;q=0.5, application/xml", "application/json", "application/xml", "text/csv")
+//	// "application/xml"
+func NegotiateContentType(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	best := offers[0]
+	bestQ := -1.0
+	bestSpecificity := -1
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if mediaType == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(k) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q <= 0 {
+			continue // explicitly not acceptable
+		}
+
+		specificity := 2
+		if mediaType == "*/*" {
+			specificity = 0
+		} else if strings.HasSuffix(mediaType, "/*") {
+			specificity = 1
+		}
+
+		for _, offer := range offers {
+			if !matchMediaType(mediaType, offer) {
+				continue
+			}
+
+			if q > bestQ || (q == bestQ && specificity > bestSpecificity) {
+				best = offer
+				bestQ = q
+				bestSpecificity = specificity
+			}
+
+			break // the first matching offer has priority
+		}
+	}
+
+	return best
+}
+
+func matchMediaType(pattern string, offer string) bool {
+	if pattern == "*/*" {
+		return true
+	}
+
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(offer, prefix+"/")
+	}
+
+	return pattern == offer
+}
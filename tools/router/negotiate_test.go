@@ -0,0 +1,81 @@
+package router_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"application/json", "application/xml", "text/csv"}
+
+	scenarios := []struct {
+		accept   string
+		offers   []string
+		expected string
+	}{
+		{"", nil, ""},
+		{"", offers, "application/json"},
+		{"*/*", offers, "application/json"},
+		{"text/html", offers, "application/json"},
+		{"text/csv", offers, "text/csv"},
+		{"TEXT/CSV", offers, "text/csv"},
+		{"text/*", offers, "text/csv"},
+		{"application/*", offers, "application/json"},
+		{"text/csv;q=0.5, application/xml", offers, "application/xml"},
+		{"text/csv;q=0, */*;q=0.1", offers, "application/json"},
+		{"*/*;q=0.8, text/csv;q=0.8", offers, "text/csv"},
+		{"application/xml;q=0.9, text/csv; charset=utf-8; q=1", offers, "text/csv"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.accept, func(t *testing.T) {
+			result := router.NegotiateContentType(s.accept, s.offers...)
+			if result != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestNewProblemFromError(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			"plain error",
+			errors.New("test"),
+			`{"detail":"Something went wrong while processing your request.","extensions":null,"status":400,"title":""}`,
+		},
+		{
+			"ApiError with data",
+			router.NewNotFoundError("missing", validation.Errors{"a": validation.NewError("test_code", "test_message")}),
+			`{"detail":"Missing.","extensions":{"errors":{"a":{"code":"test_code","message":"Test_message."}}},"status":404,"title":""}`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			p := router.NewProblemFromError(s.err)
+
+			raw, err := json.Marshal(map[string]any{
+				"status":     p.Status,
+				"title":      p.Title,
+				"detail":     p.Detail,
+				"extensions": p.Extensions,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if str := string(raw); str != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, str)
+			}
+		})
+	}
+}
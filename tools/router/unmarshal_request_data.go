@@ -7,10 +7,17 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem"
 )
 
 var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
 
+var (
+	fileType      = reflect.TypeOf((*filesystem.File)(nil))
+	fileSliceType = reflect.TypeOf([]*filesystem.File(nil))
+)
+
 // JSONPayloadKey is the key for the special UnmarshalRequestData case
 // used for reading serialized json payload without normalization.
 const JSONPayloadKey string = "@jsonPayload"
@@ -136,6 +143,10 @@ func unmarshalInStructValue(
 			continue // disabled or unexported non-anonymous struct field
 		}
 
+		if isFileType(fieldType.Type) {
+			continue // loaded separately from the multipart form files
+		}
+
 		fieldValue := dereference(dstStructValue.Field(i))
 
 		ft := fieldType.Type
@@ -235,6 +246,11 @@ func unmarshalInStructValue(
 	return nil
 }
 
+// isFileType checks whether t is *filesystem.File or []*filesystem.File.
+func isFileType(t reflect.Type) bool {
+	return t == fileType || t == fileSliceType
+}
+
 // dereference returns the underlying value v points to.
 func dereference(v reflect.Value) reflect.Value {
 	for v.Kind() == reflect.Ptr {
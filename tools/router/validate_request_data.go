@@ -0,0 +1,237 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// ValidateStructTagKey is the struct tag used by [ValidateRequestData]
+// for defining the field validation rules.
+const ValidateStructTagKey string = "validate"
+
+// ValidateRequestData validates the dst struct fields based on their "validate" struct tag.
+//
+// The tag value is a comma separated list with one or more of the following rules:
+//   - required - the field value must not be empty (nil, zero, empty string or slice)
+//   - min=N - the min number value or string/slice length
+//   - max=N - the max number value or string/slice length
+//   - email - the field value must be a valid email address
+//   - e164 - the field value must be a valid E.164 phone number
+//   - regex=PATTERN - the field value must match the regex pattern
+//     (it must be the last rule because the pattern may contain commas)
+//
+// Except "required", all other rules are skipped for empty values.
+//
+// The field errors are keyed by the structTagKey tag name (with fallback
+// to the struct field name) and the errors of the nested struct fields
+// are returned as nested [validation.Errors].
+//
+// Returns a [validation.Errors] error in case of failed validation
+// or nil if dst is not a struct or struct pointer.
+//
+// Example:
+//
+//	data := struct{
+//	    Phone string `json:"phone" validate:"required,e164"`
+//	    Name  string `json:"name" validate:"min=3,max=50,regex=^\\w+$"`
+//	}{}
+//	err := router.ValidateRequestData(&data, "json")
+func ValidateRequestData(dst any, structTagKey string) error {
+	dstValue := reflect.ValueOf(dst)
+	for dstValue.Kind() == reflect.Pointer {
+		if dstValue.IsNil() {
+			return nil
+		}
+		dstValue = dstValue.Elem()
+	}
+
+	if dstValue.Kind() != reflect.Struct {
+		return nil
+	}
+
+	errs, err := validateStructValue(dstValue, structTagKey)
+	if err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// validateStructValue validates the fields of the provided struct reflect.Value.
+func validateStructValue(structValue reflect.Value, structTagKey string) (validation.Errors, error) {
+	errs := validation.Errors{}
+
+	structType := structValue.Type()
+
+	for i := 0; i < structValue.NumField(); i++ {
+		fieldType := structType.Field(i)
+
+		if !fieldType.Anonymous && !fieldType.IsExported() {
+			continue // unexported non-anonymous struct field
+		}
+
+		tag, _, _ := strings.Cut(fieldType.Tag.Get(structTagKey), ",")
+		if tag == "-" {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = fieldType.Name
+		}
+
+		fieldValue := structValue.Field(i)
+
+		rules, err := parseValidateTag(fieldType.Tag.Get(ValidateStructTagKey), fieldType.Type)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q field validate tag: %w", fieldType.Name, err)
+		}
+
+		if len(rules) > 0 && fieldType.IsExported() {
+			if err := validation.Validate(fieldValue.Interface(), rules...); err != nil {
+				errs[name] = err
+				continue
+			}
+		}
+
+		// nested or embedded struct
+		// ---
+		if isFileType(fieldType.Type) {
+			continue
+		}
+
+		for fieldValue.Kind() == reflect.Pointer && !fieldValue.IsNil() {
+			fieldValue = fieldValue.Elem()
+		}
+
+		if fieldValue.Kind() != reflect.Struct {
+			continue
+		}
+
+		nestedErrs, err := validateStructValue(fieldValue, structTagKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if fieldType.Anonymous && tag == "" {
+			maps.Copy(errs, nestedErrs)
+		} else if len(nestedErrs) > 0 {
+			errs[name] = nestedErrs
+		}
+	}
+
+	return errs, nil
+}
+
+// parseValidateTag parses the "validate" struct tag value into a list of validation rules.
+func parseValidateTag(tag string, fieldType reflect.Type) ([]validation.Rule, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	kind := fieldType.Kind()
+	for kind == reflect.Pointer {
+		fieldType = fieldType.Elem()
+		kind = fieldType.Kind()
+	}
+
+	var rules []validation.Rule
+
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "regex=") {
+			rule, tag = tag, "" // the pattern may contain commas
+		} else {
+			rule, tag, _ = strings.Cut(tag, ",")
+		}
+
+		key, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch key {
+		case "":
+			continue
+		case "required":
+			rules = append(rules, validation.Required)
+		case "email":
+			rules = append(rules, is.EmailFormat)
+		case "e164":
+			rules = append(rules, is.E164)
+		case "regex":
+			pattern, err := regexp.Compile(arg)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, validation.Match(pattern))
+		case "min", "max":
+			thresholdRule, err := newThresholdRule(key == "min", arg, kind)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, thresholdRule)
+		default:
+			return nil, fmt.Errorf("unknown rule %q", key)
+		}
+	}
+
+	return rules, nil
+}
+
+// newThresholdRule creates a new min/max number or length validation rule
+// depending on the field kind.
+func newThresholdRule(isMin bool, arg string, kind reflect.Kind) (validation.Rule, error) {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if isMin {
+			return validation.Min(n), nil
+		}
+		return validation.Max(n), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if isMin {
+			return validation.Min(n), nil
+		}
+		return validation.Max(n), nil
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, err
+		}
+		if isMin {
+			return validation.Min(n), nil
+		}
+		return validation.Max(n), nil
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errors.New("the length must be non-negative")
+		}
+		if isMin {
+			return validation.RuneLength(n, 0), nil
+		}
+		return validation.RuneLength(0, n), nil
+	default:
+		return nil, fmt.Errorf("min/max rules are not supported for %s fields", kind)
+	}
+}
@@ -0,0 +1,121 @@
+package router_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+func TestValidateRequestData(t *testing.T) {
+	type nested struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	type Embedded struct {
+		Email string `json:"email" validate:"email"`
+	}
+
+	type testDstStruct struct {
+		Embedded
+
+		Name    string   `json:"name" validate:"required,min=3,max=5"`
+		Age     int      `json:"age" validate:"min=18,max=99"`
+		Score   *float64 `json:"score" validate:"max=1.5"`
+		Slug    string   `json:"slug" validate:"regex=^[a-z]{1,3}(,[a-z]+)?$"`
+		Phone   string   `validate:"e164"`
+		Tags    []string `json:"tags" validate:"required,max=2"`
+		Nested  nested   `json:"nested"`
+		Ignored string   `json:"-" validate:"required"`
+	}
+
+	score := 2.0
+
+	scenarios := []struct {
+		name     string
+		dst      any
+		expected string
+	}{
+		{
+			"nil",
+			nil,
+			`null`,
+		},
+		{
+			"non struct",
+			&map[string]any{},
+			`null`,
+		},
+		{
+			"empty struct",
+			&testDstStruct{},
+			`{"name":"cannot be blank","nested":{"code":"cannot be blank"},"tags":"cannot be blank"}`,
+		},
+		{
+			"invalid struct",
+			&testDstStruct{
+				Embedded: Embedded{Email: "invalid"},
+				Name:     "ab",
+				Age:      17,
+				Score:    &score,
+				Slug:     "abcd",
+				Phone:    "abc",
+				Tags:     []string{"a", "b", "c"},
+				Nested:   nested{Code: "a"},
+			},
+			`{"Phone":"must be a valid E164 number","age":"must be no less than 18","email":"must be a valid email address","name":"the length must be no less than 3","score":"must be no greater than 1.5","slug":"must be in a valid format","tags":"the length must be no more than 2"}`,
+		},
+		{
+			"valid struct",
+			&testDstStruct{
+				Embedded: Embedded{Email: "test@example.com"},
+				Name:     "abc",
+				Age:      18,
+				Slug:     "abc,def",
+				Phone:    "+359888123456",
+				Tags:     []string{"a"},
+				Nested:   nested{Code: "a"},
+			},
+			`null`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := router.ValidateRequestData(s.dst, "json")
+
+			raw, _ := json.Marshal(err)
+			if str := string(raw); str != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, str)
+			}
+		})
+	}
+}
+
+func TestValidateRequestDataInvalidTag(t *testing.T) {
+	scenarios := []struct {
+		name string
+		dst  any
+	}{
+		{"unknown rule", &struct {
+			A string `validate:"missing"`
+		}{}},
+		{"invalid regex", &struct {
+			A string `validate:"regex=(a"`
+		}{}},
+		{"invalid min number", &struct {
+			A int `validate:"min=a"`
+		}{}},
+		{"unsupported min field", &struct {
+			A bool `validate:"min=1"`
+		}{}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if err := router.ValidateRequestData(s.dst, "json"); err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+		})
+	}
+}
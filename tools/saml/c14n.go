@@ -0,0 +1,178 @@
+package saml
+
+import (
+	"slices"
+	"strings"
+)
+
+// canonicalize serializes the element subtree using the Exclusive XML
+// Canonicalization 1.0 (omit comments) rules (https://www.w3.org/TR/xml-exc-c14n/).
+//
+// The exclude element (if set) and its descendants are omitted from
+// the output (used for the enveloped signature transform).
+//
+// inclusivePrefixes is the optional InclusiveNamespaces PrefixList
+// ("#default" for the default namespace).
+func canonicalize(el *element, exclude *element, inclusivePrefixes []string) string {
+	c := &canonicalizer{
+		exclude:           exclude,
+		inclusivePrefixes: make(map[string]struct{}, len(inclusivePrefixes)),
+	}
+
+	for _, p := range inclusivePrefixes {
+		if p == "#default" {
+			p = ""
+		}
+		c.inclusivePrefixes[p] = struct{}{}
+	}
+
+	c.writeElement(el, map[string]string{})
+
+	return c.sb.String()
+}
+
+type canonicalizer struct {
+	sb                strings.Builder
+	exclude           *element
+	inclusivePrefixes map[string]struct{}
+}
+
+func (c *canonicalizer) writeElement(el *element, rendered map[string]string) {
+	// collect the visibly utilized and the inclusive prefixes
+	prefixes := []string{el.prefix}
+	for _, a := range el.attrs {
+		if a.prefix != "" && a.prefix != "xml" && !slices.Contains(prefixes, a.prefix) {
+			prefixes = append(prefixes, a.prefix)
+		}
+	}
+	for p := range c.inclusivePrefixes {
+		if _, ok := el.lookupNamespace(p); ok && !slices.Contains(prefixes, p) {
+			prefixes = append(prefixes, p)
+		}
+	}
+	slices.Sort(prefixes) // the default namespace (empty prefix) is always first
+
+	// determine which namespace declarations need to be rendered
+	var decls []nsDecl
+	for _, p := range prefixes {
+		if p == "xml" {
+			continue
+		}
+
+		uri, _ := el.lookupNamespace(p)
+
+		if renderedURI, ok := rendered[p]; ok {
+			if renderedURI == uri {
+				continue // already rendered by an output ancestor
+			}
+		} else if uri == "" {
+			continue // nothing to undeclare
+		}
+
+		decls = append(decls, nsDecl{prefix: p, uri: uri})
+	}
+
+	if len(decls) > 0 {
+		newRendered := make(map[string]string, len(rendered)+len(decls))
+		for k, v := range rendered {
+			newRendered[k] = v
+		}
+		for _, d := range decls {
+			newRendered[d.prefix] = d.uri
+		}
+		rendered = newRendered
+	}
+
+	// sort the attributes by their namespace uri and local name
+	type resolvedAttr struct {
+		attr
+		uri string
+	}
+	attrs := make([]resolvedAttr, 0, len(el.attrs))
+	for _, a := range el.attrs {
+		uri := ""
+		if a.prefix != "" {
+			uri, _ = el.lookupNamespace(a.prefix)
+		}
+		attrs = append(attrs, resolvedAttr{attr: a, uri: uri})
+	}
+	slices.SortStableFunc(attrs, func(a, b resolvedAttr) int {
+		if a.uri != b.uri {
+			return strings.Compare(a.uri, b.uri)
+		}
+		return strings.Compare(a.local, b.local)
+	})
+
+	name := qualifiedName(el.prefix, el.local)
+
+	c.sb.WriteString("<")
+	c.sb.WriteString(name)
+
+	for _, d := range decls {
+		if d.prefix == "" {
+			c.sb.WriteString(` xmlns="`)
+		} else {
+			c.sb.WriteString(" xmlns:")
+			c.sb.WriteString(d.prefix)
+			c.sb.WriteString(`="`)
+		}
+		c.sb.WriteString(escapeAttrValue(d.uri))
+		c.sb.WriteString(`"`)
+	}
+
+	for _, a := range attrs {
+		c.sb.WriteString(" ")
+		c.sb.WriteString(qualifiedName(a.prefix, a.local))
+		c.sb.WriteString(`="`)
+		c.sb.WriteString(escapeAttrValue(a.value))
+		c.sb.WriteString(`"`)
+	}
+
+	c.sb.WriteString(">")
+
+	for _, child := range el.children {
+		switch v := child.(type) {
+		case *element:
+			if v != c.exclude {
+				c.writeElement(v, rendered)
+			}
+		case string:
+			c.sb.WriteString(escapeText(v))
+		}
+	}
+
+	c.sb.WriteString("</")
+	c.sb.WriteString(name)
+	c.sb.WriteString(">")
+}
+
+func qualifiedName(prefix string, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+var textReplacer = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"\r", "&#xD;",
+)
+
+func escapeText(str string) string {
+	return textReplacer.Replace(str)
+}
+
+var attrValueReplacer = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	`"`, "&quot;",
+	"\t", "&#x9;",
+	"\n", "&#xA;",
+	"\r", "&#xD;",
+)
+
+func escapeAttrValue(str string) string {
+	return attrValueReplacer.Replace(str)
+}
@@ -0,0 +1,137 @@
+package saml
+
+import (
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name      string
+		xml       string
+		path      []int // child element indexes to the canonicalized element
+		exclude   []int // child element indexes (relative to the canonicalized element) of the excluded element
+		inclusive []string
+		expected  string
+	}{
+		{
+			name:     "visibly utilized namespaces and sorted attributes",
+			xml:      `<a:root xmlns:a="urn:a" xmlns:b="urn:b" xmlns="urn:d"><a:child b:attr="1" z="2" a="3"><b:x/></a:child></a:root>`,
+			path:     []int{0},
+			expected: `<a:child xmlns:a="urn:a" xmlns:b="urn:b" a="3" z="2" b:attr="1"><b:x></b:x></a:child>`,
+		},
+		{
+			name:     "default namespace undeclaration",
+			xml:      `<root xmlns="urn:d"><x:a xmlns:x="urn:x"><b xmlns=""/></x:a></root>`,
+			expected: `<root xmlns="urn:d"><x:a xmlns:x="urn:x"><b xmlns=""></b></x:a></root>`,
+		},
+		{
+			name:     "default namespace undeclaration without rendered ancestor",
+			xml:      `<root xmlns="urn:d"><x:a xmlns:x="urn:x"><b xmlns=""/></x:a></root>`,
+			path:     []int{0},
+			expected: `<x:a xmlns:x="urn:x"><b></b></x:a>`,
+		},
+		{
+			name:     "redeclared prefix",
+			xml:      `<p:a xmlns:p="urn:1"><p:b xmlns:p="urn:2"><p:c xmlns:p="urn:2"/></p:b></p:a>`,
+			expected: `<p:a xmlns:p="urn:1"><p:b xmlns:p="urn:2"><p:c></p:c></p:b></p:a>`,
+		},
+		{
+			name:     "escaping",
+			xml:      "<a x=\"&quot;&lt;&#9;&#10;&amp;\">1 &amp; 2 &lt; 3 &gt; 0 \"q\"</a>",
+			expected: "<a x=\"&quot;&lt;&#x9;&#xA;&amp;\">1 &amp; 2 &lt; 3 &gt; 0 \"q\"</a>",
+		},
+		{
+			name:     "comments and cdata",
+			xml:      `<a><!-- comment --><![CDATA[<b>]]>text<!-- other --></a>`,
+			expected: `<a>&lt;b&gt;text</a>`,
+		},
+		{
+			name:     "whitespace preservation",
+			xml:      "<a>\n  <b> x </b>\n</a>",
+			expected: "<a>\n  <b> x </b>\n</a>",
+		},
+		{
+			name:      "inclusive namespaces prefix list",
+			xml:       `<r xmlns:p="urn:p" xmlns:q="urn:q" xmlns="urn:d"><c/></r>`,
+			path:      []int{0},
+			inclusive: []string{"p", "#default", "missing"},
+			expected:  `<c xmlns="urn:d" xmlns:p="urn:p"></c>`,
+		},
+		{
+			name:     "excluded element",
+			xml:      `<a ID="1"><b/><ds:Signature xmlns:ds="urn:ds"><ds:x/></ds:Signature><c/></a>`,
+			exclude:  []int{1},
+			expected: `<a ID="1"><b></b><c></c></a>`,
+		},
+		{
+			name:     "xml prefix",
+			xml:      `<a xml:lang="en"><b/></a>`,
+			expected: `<a xml:lang="en"><b></b></a>`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			root, err := parseXML([]byte(s.xml))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			el := findByPath(t, root, s.path)
+
+			var exclude *element
+			if len(s.exclude) > 0 {
+				exclude = findByPath(t, el, s.exclude)
+			}
+
+			result := canonicalize(el, exclude, s.inclusive)
+			if result != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseXMLErrors(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name string
+		xml  string
+	}{
+		{"empty", ``},
+		{"doctype", `<!DOCTYPE a [<!ENTITY x "y">]><a>&x;</a>`},
+		{"multiple roots", `<a></a><b></b>`},
+		{"unclosed", `<a><b></b>`},
+		{"text outside root", `text<a></a>`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if _, err := parseXML([]byte(s.xml)); err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func findByPath(t testing.TB, el *element, path []int) *element {
+	for _, index := range path {
+		var children []*element
+		for _, c := range el.children {
+			if child, ok := c.(*element); ok {
+				children = append(children, child)
+			}
+		}
+
+		if index >= len(children) {
+			t.Fatalf("Missing child element at index %d", index)
+		}
+
+		el = children[index]
+	}
+
+	return el
+}
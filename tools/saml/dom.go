@@ -0,0 +1,197 @@
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// maxDepth is the max allowed elements nesting level.
+const maxDepth = 100
+
+type attr struct {
+	prefix string
+	local  string
+	value  string
+}
+
+type nsDecl struct {
+	prefix string // empty for the default namespace
+	uri    string
+}
+
+// element is a minimal namespace aware XML element tree node that
+// preserves the original prefixes and namespace declarations (required
+// for the XML signature canonicalization).
+type element struct {
+	parent   *element
+	prefix   string
+	local    string
+	attrs    []attr
+	nsDecls  []nsDecl
+	children []any // *element or string (char data)
+}
+
+// parseXML parses the provided data into an element tree and returns its root element.
+//
+// DTDs and multiple root elements are not allowed.
+// Comments and processing instructions are ignored.
+func parseXML(data []byte) (*element, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var root *element
+	var current *element
+	var depth int
+
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if current == nil && root != nil {
+				return nil, errors.New("multiple root elements")
+			}
+
+			depth++
+			if depth > maxDepth {
+				return nil, errors.New("max elements nesting level reached")
+			}
+
+			el := &element{
+				parent: current,
+				prefix: t.Name.Space,
+				local:  t.Name.Local,
+			}
+
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					el.nsDecls = append(el.nsDecls, nsDecl{uri: a.Value})
+				case a.Name.Space == "xmlns":
+					el.nsDecls = append(el.nsDecls, nsDecl{prefix: a.Name.Local, uri: a.Value})
+				default:
+					el.attrs = append(el.attrs, attr{prefix: a.Name.Space, local: a.Name.Local, value: a.Value})
+				}
+			}
+
+			if current == nil {
+				root = el
+			} else {
+				current.children = append(current.children, el)
+			}
+
+			current = el
+		case xml.EndElement:
+			if current == nil {
+				return nil, errors.New("unexpected end element")
+			}
+			current = current.parent
+			depth--
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, string(t))
+			} else if len(bytes.TrimSpace(t)) > 0 {
+				return nil, errors.New("unexpected char data outside of the root element")
+			}
+		case xml.Directive:
+			return nil, errors.New("DTDs are not allowed")
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("missing root element")
+	}
+
+	if current != nil {
+		return nil, errors.New("unclosed root element")
+	}
+
+	return root, nil
+}
+
+// lookupNamespace returns the in-scope namespace uri of the specified prefix
+// (empty string for the default namespace).
+func (el *element) lookupNamespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return xmlNamespace, true
+	}
+
+	for e := el; e != nil; e = e.parent {
+		for _, ns := range e.nsDecls {
+			if ns.prefix == prefix {
+				return ns.uri, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// namespace returns the resolved namespace uri of the element.
+func (el *element) namespace() string {
+	uri, _ := el.lookupNamespace(el.prefix)
+	return uri
+}
+
+// is checks whether the element has the specified namespace and local name.
+func (el *element) is(namespace string, local string) bool {
+	return el.local == local && el.namespace() == namespace
+}
+
+// attr returns the value of the first unprefixed attribute with the specified name.
+func (el *element) attr(local string) string {
+	for _, a := range el.attrs {
+		if a.prefix == "" && a.local == local {
+			return a.value
+		}
+	}
+
+	return ""
+}
+
+// childElements returns all direct child elements matching the specified namespace and local name.
+func (el *element) childElements(namespace string, local string) []*element {
+	var result []*element
+
+	for _, c := range el.children {
+		if child, ok := c.(*element); ok && child.is(namespace, local) {
+			result = append(result, child)
+		}
+	}
+
+	return result
+}
+
+// childElement returns the first direct child element matching the
+// specified namespace and local name (or nil if not found).
+func (el *element) childElement(namespace string, local string) *element {
+	children := el.childElements(namespace, local)
+	if len(children) == 0 {
+		return nil
+	}
+
+	return children[0]
+}
+
+// text returns the concatenated direct char data of the element.
+func (el *element) text() string {
+	var sb strings.Builder
+
+	for _, c := range el.children {
+		if str, ok := c.(string); ok {
+			sb.WriteString(str)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,229 @@
+package saml
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+const (
+	nsDSig = "http://www.w3.org/2000/09/xmldsig#"
+
+	algExcC14N              = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algEnvelopedSignature   = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algRSASHA1              = "http://www.w3.org/2000/09/xmldsig#rsa-sha1"
+	algRSASHA256            = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algRSASHA512            = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	algECDSASHA256          = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+	algDigestSHA1           = "http://www.w3.org/2000/09/xmldsig#sha1"
+	algDigestSHA256         = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algDigestSHA512         = "http://www.w3.org/2001/04/xmlenc#sha512"
+	nsInclusiveNamespaces   = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	inclusiveNamespacesName = "InclusiveNamespaces"
+)
+
+var signatureHashes = map[string]crypto.Hash{
+	algRSASHA1:     crypto.SHA1,
+	algRSASHA256:   crypto.SHA256,
+	algRSASHA512:   crypto.SHA512,
+	algECDSASHA256: crypto.SHA256,
+}
+
+var digestHashes = map[string]crypto.Hash{
+	algDigestSHA1:   crypto.SHA1,
+	algDigestSHA256: crypto.SHA256,
+	algDigestSHA512: crypto.SHA512,
+}
+
+// errMissingSignature is returned when the element doesn't have an enveloped signature.
+var errMissingSignature = errors.New("missing signature")
+
+// signatureElement returns the direct ds:Signature child of el (if any).
+func signatureElement(el *element) (*element, error) {
+	signatures := el.childElements(nsDSig, "Signature")
+
+	switch len(signatures) {
+	case 0:
+		return nil, errMissingSignature
+	case 1:
+		return signatures[0], nil
+	default:
+		return nil, errors.New("multiple signature elements")
+	}
+}
+
+// verifySignature verifies the enveloped XML signature of el with the
+// provided trusted certificates.
+//
+// Only signatures that reference the element itself (by its ID attribute)
+// and use the exclusive canonicalization are supported.
+// The KeyInfo element of the signature is ignored.
+func verifySignature(el *element, certs []*x509.Certificate) error {
+	if len(certs) == 0 {
+		return errors.New("no trusted certificates")
+	}
+
+	signature, err := signatureElement(el)
+	if err != nil {
+		return err
+	}
+
+	signedInfo, err := singleChild(signature, nsDSig, "SignedInfo")
+	if err != nil {
+		return err
+	}
+
+	// verify the reference digest
+	// ---
+	reference, err := singleChild(signedInfo, nsDSig, "Reference")
+	if err != nil {
+		return err
+	}
+
+	id := el.attr("ID")
+	if id == "" || reference.attr("URI") != "#"+id {
+		return errors.New("the signature reference doesn't match the signed element ID")
+	}
+
+	var hasEnvelopedTransform bool
+	var hasC14NTransform bool
+	var inclusivePrefixes []string
+	if transforms := reference.childElement(nsDSig, "Transforms"); transforms != nil {
+		for _, t := range transforms.childElements(nsDSig, "Transform") {
+			switch alg := t.attr("Algorithm"); alg {
+			case algEnvelopedSignature:
+				hasEnvelopedTransform = true
+			case algExcC14N:
+				hasC14NTransform = true
+				inclusivePrefixes = inclusiveNamespacesPrefixes(t)
+			default:
+				return fmt.Errorf("unsupported transform algorithm %q", alg)
+			}
+		}
+	}
+	if !hasEnvelopedTransform || !hasC14NTransform {
+		return errors.New("the signature reference must have enveloped-signature and exclusive canonicalization transforms")
+	}
+
+	digestMethod, err := singleChild(reference, nsDSig, "DigestMethod")
+	if err != nil {
+		return err
+	}
+
+	digestHash, ok := digestHashes[digestMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported digest algorithm %q", digestMethod.attr("Algorithm"))
+	}
+
+	digestValue, err := singleChild(reference, nsDSig, "DigestValue")
+	if err != nil {
+		return err
+	}
+
+	expectedDigest, err := decodeBase64(digestValue.text())
+	if err != nil {
+		return fmt.Errorf("invalid digest value: %w", err)
+	}
+
+	h := digestHash.New()
+	h.Write([]byte(canonicalize(el, signature, inclusivePrefixes)))
+	if subtle.ConstantTimeCompare(h.Sum(nil), expectedDigest) != 1 {
+		return errors.New("digest mismatch")
+	}
+
+	// verify the SignedInfo signature
+	// ---
+	c14nMethod, err := singleChild(signedInfo, nsDSig, "CanonicalizationMethod")
+	if err != nil {
+		return err
+	}
+	if c14nMethod.attr("Algorithm") != algExcC14N {
+		return fmt.Errorf("unsupported canonicalization algorithm %q", c14nMethod.attr("Algorithm"))
+	}
+
+	signatureMethod, err := singleChild(signedInfo, nsDSig, "SignatureMethod")
+	if err != nil {
+		return err
+	}
+
+	signatureHash, ok := signatureHashes[signatureMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported signature algorithm %q", signatureMethod.attr("Algorithm"))
+	}
+
+	signatureValue, err := singleChild(signature, nsDSig, "SignatureValue")
+	if err != nil {
+		return err
+	}
+
+	rawSignature, err := decodeBase64(signatureValue.text())
+	if err != nil {
+		return fmt.Errorf("invalid signature value: %w", err)
+	}
+
+	h = signatureHash.New()
+	h.Write([]byte(canonicalize(signedInfo, nil, inclusiveNamespacesPrefixes(c14nMethod))))
+	hashed := h.Sum(nil)
+
+	for _, cert := range certs {
+		switch pub := cert.PublicKey.(type) {
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(pub, signatureHash, hashed, rawSignature) == nil {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			if verifyECDSA(pub, hashed, rawSignature) {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("invalid signature")
+}
+
+// verifyECDSA verifies an XML DSig ECDSA signature (the raw r||s concatenation).
+func verifyECDSA(pub *ecdsa.PublicKey, hashed []byte, signature []byte) bool {
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	if len(signature) != 2*size {
+		return false
+	}
+
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+
+	return ecdsa.Verify(pub, hashed, r, s)
+}
+
+func inclusiveNamespacesPrefixes(el *element) []string {
+	inclusive := el.childElement(nsInclusiveNamespaces, inclusiveNamespacesName)
+	if inclusive == nil {
+		return nil
+	}
+
+	return strings.Fields(inclusive.attr("PrefixList"))
+}
+
+func singleChild(el *element, namespace string, local string) (*element, error) {
+	children := el.childElements(namespace, local)
+	if len(children) != 1 {
+		return nil, fmt.Errorf("expected exactly one %s element, found %d", local, len(children))
+	}
+
+	return children[0], nil
+}
+
+// decodeBase64 decodes a standard base64 string ignoring the whitespace characters.
+func decodeBase64(str string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(str), ""))
+}
@@ -0,0 +1,144 @@
+package saml
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	nsMetadata  = "urn:oasis:names:tc:SAML:2.0:metadata"
+	nsProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+
+	BindingHTTPRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	BindingHTTPPOST     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+)
+
+// IdPMetadata holds the Identity Provider (IdP) settings extracted from its metadata XML.
+type IdPMetadata struct {
+	// EntityId is the IdP unique identifier (usually an url).
+	EntityId string `json:"entityId"`
+
+	// SSOURL is the IdP SingleSignOnService endpoint location.
+	SSOURL string `json:"ssoURL"`
+
+	// SSOBinding is the SAML binding of the SSOURL
+	// (either [BindingHTTPRedirect] or [BindingHTTPPOST]).
+	SSOBinding string `json:"ssoBinding"`
+
+	// Certificates is the list with the IdP signing certificates.
+	Certificates []*x509.Certificate `json:"-"`
+}
+
+type metadataEntityDescriptor struct {
+	XMLName          xml.Name
+	EntityId         string                     `xml:"entityID,attr"`
+	IdPSSODescriptor *metadataIdPSSODescriptor  `xml:"urn:oasis:names:tc:SAML:2.0:metadata IDPSSODescriptor"`
+	Entities         []metadataEntityDescriptor `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+}
+
+type metadataIdPSSODescriptor struct {
+	KeyDescriptors      []metadataKeyDescriptor `xml:"urn:oasis:names:tc:SAML:2.0:metadata KeyDescriptor"`
+	SingleSignOnService []metadataEndpoint      `xml:"urn:oasis:names:tc:SAML:2.0:metadata SingleSignOnService"`
+}
+
+type metadataKeyDescriptor struct {
+	Use          string   `xml:"use,attr"`
+	Certificates []string `xml:"http://www.w3.org/2000/09/xmldsig# KeyInfo>X509Data>X509Certificate"`
+}
+
+type metadataEndpoint struct {
+	Binding  string `xml:"Binding,attr"`
+	Location string `xml:"Location,attr"`
+}
+
+// ParseIdPMetadata parses the provided raw IdP metadata XML.
+//
+// The data could be either an EntityDescriptor or an EntitiesDescriptor
+// document (in which case the first entity with IDPSSODescriptor is used).
+//
+// Note that the metadata signature (if any) is not verified.
+func ParseIdPMetadata(data []byte) (*IdPMetadata, error) {
+	if bytes.Contains(data, []byte("<!DOCTYPE")) {
+		return nil, errors.New("DTDs are not allowed")
+	}
+
+	descriptor := metadataEntityDescriptor{}
+	if err := xml.Unmarshal(data, &descriptor); err != nil {
+		return nil, fmt.Errorf("failed to parse the IdP metadata: %w", err)
+	}
+
+	if descriptor.XMLName.Space != nsMetadata {
+		return nil, errors.New("the IdP metadata root element must be EntityDescriptor or EntitiesDescriptor")
+	}
+
+	var entity *metadataEntityDescriptor
+	switch descriptor.XMLName.Local {
+	case "EntityDescriptor":
+		entity = &descriptor
+	case "EntitiesDescriptor":
+		for i := range descriptor.Entities {
+			if descriptor.Entities[i].IdPSSODescriptor != nil {
+				entity = &descriptor.Entities[i]
+				break
+			}
+		}
+	}
+
+	if entity == nil || entity.IdPSSODescriptor == nil {
+		return nil, errors.New("missing IDPSSODescriptor")
+	}
+
+	result := &IdPMetadata{
+		EntityId: strings.TrimSpace(entity.EntityId),
+	}
+	if result.EntityId == "" {
+		return nil, errors.New("missing IdP entityID")
+	}
+
+	// prefer the HTTP-Redirect binding
+	for _, binding := range []string{BindingHTTPRedirect, BindingHTTPPOST} {
+		for _, sso := range entity.IdPSSODescriptor.SingleSignOnService {
+			if sso.Binding == binding && sso.Location != "" {
+				result.SSOURL = sso.Location
+				result.SSOBinding = binding
+				break
+			}
+		}
+		if result.SSOURL != "" {
+			break
+		}
+	}
+	if result.SSOURL == "" {
+		return nil, errors.New("missing HTTP-Redirect or HTTP-POST SingleSignOnService")
+	}
+
+	for _, kd := range entity.IdPSSODescriptor.KeyDescriptors {
+		if kd.Use != "" && kd.Use != "signing" {
+			continue
+		}
+
+		for _, rawCert := range kd.Certificates {
+			certData, err := decodeBase64(rawCert)
+			if err != nil {
+				return nil, fmt.Errorf("invalid IdP certificate encoding: %w", err)
+			}
+
+			cert, err := x509.ParseCertificate(certData)
+			if err != nil {
+				return nil, fmt.Errorf("invalid IdP certificate: %w", err)
+			}
+
+			result.Certificates = append(result.Certificates, cert)
+		}
+	}
+	if len(result.Certificates) == 0 {
+		return nil, errors.New("missing IdP signing certificate")
+	}
+
+	return result, nil
+}
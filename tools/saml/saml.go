@@ -0,0 +1,450 @@
+// Package saml implements a minimal SAML 2.0 Web Browser SSO service
+// provider (SP) with support for:
+//   - IdP metadata parsing
+//   - SP metadata generation
+//   - SP-initiated AuthnRequest (HTTP-Redirect and HTTP-POST bindings)
+//   - signed Response/Assertion validation (HTTP-POST binding)
+//
+// Encrypted assertions and signed AuthnRequests are not supported.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NameIdFormatEmail is the email address NameID format identifier.
+const NameIdFormatEmail = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+
+const (
+	statusSuccess       = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	confirmationBearer  = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	timeFormat          = "2006-01-02T15:04:05Z"
+	defaultMaxClockSkew = 3 * time.Minute
+)
+
+// ServiceProvider defines a SAML service provider (SP) configuration.
+type ServiceProvider struct {
+	// IdP is the trusted Identity Provider settings.
+	IdP *IdPMetadata
+
+	// EntityId is the SP unique identifier (usually the SP metadata url).
+	EntityId string
+
+	// ACSURL is the SP Assertion Consumer Service (HTTP-POST) endpoint url.
+	ACSURL string
+
+	// MaxClockSkew is the max allowed clock difference with the IdP
+	// when validating the assertion conditions (default to 3 minutes).
+	MaxClockSkew time.Duration
+
+	// now is used to mock the current time in tests.
+	now func() time.Time
+}
+
+// Assertion holds the validated SAML assertion data.
+type Assertion struct {
+	// Id is the unique assertion identifier.
+	Id string `json:"id"`
+
+	// InResponseTo is the id of the AuthnRequest the assertion was issued for.
+	InResponseTo string `json:"inResponseTo"`
+
+	// NameId is the assertion subject identifier.
+	NameId string `json:"nameId"`
+
+	// NameIdFormat is the NameId format (eg. [NameIdFormatEmail]).
+	NameIdFormat string `json:"nameIdFormat"`
+
+	// SessionIndex is the IdP session identifier (if any).
+	SessionIndex string `json:"sessionIndex"`
+
+	// Attributes holds the assertion attribute values by their Name
+	// (and FriendlyName if available).
+	Attributes map[string][]string `json:"attributes"`
+}
+
+// Attribute returns the first value of the specified assertion attribute
+// (or empty string if missing).
+func (a *Assertion) Attribute(name string) string {
+	values := a.Attributes[name]
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// NewRequestId generates a new random AuthnRequest ID.
+func NewRequestId() string {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	// note: must be a valid xsd:ID (aka. not starting with a digit)
+	return "id" + hex.EncodeToString(b)
+}
+
+// Metadata returns the SP metadata XML.
+func (sp *ServiceProvider) Metadata() ([]byte, error) {
+	type acs struct {
+		Binding   string `xml:"Binding,attr"`
+		Location  string `xml:"Location,attr"`
+		Index     int    `xml:"index,attr"`
+		IsDefault bool   `xml:"isDefault,attr"`
+	}
+
+	type spDescriptor struct {
+		AuthnRequestsSigned        bool   `xml:"AuthnRequestsSigned,attr"`
+		WantAssertionsSigned       bool   `xml:"WantAssertionsSigned,attr"`
+		ProtocolSupportEnumeration string `xml:"protocolSupportEnumeration,attr"`
+		ACS                        acs    `xml:"AssertionConsumerService"`
+	}
+
+	type entityDescriptor struct {
+		XMLName    xml.Name     `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+		EntityId   string       `xml:"entityID,attr"`
+		Descriptor spDescriptor `xml:"SPSSODescriptor"`
+	}
+
+	raw, err := xml.MarshalIndent(entityDescriptor{
+		EntityId: sp.EntityId,
+		Descriptor: spDescriptor{
+			AuthnRequestsSigned:        false,
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: nsProtocol,
+			ACS: acs{
+				Binding:   BindingHTTPPOST,
+				Location:  sp.ACSURL,
+				Index:     0,
+				IsDefault: true,
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), raw...), nil
+}
+
+// AuthnRequest returns a new AuthnRequest XML with the specified id.
+func (sp *ServiceProvider) AuthnRequest(requestId string) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(`<samlp:AuthnRequest xmlns:samlp="` + nsProtocol + `" xmlns:saml="` + nsAssertion + `"`)
+	buf.WriteString(` ID="` + escapeAttrValue(requestId) + `"`)
+	buf.WriteString(` Version="2.0"`)
+	buf.WriteString(` IssueInstant="` + sp.currentTime().UTC().Format(timeFormat) + `"`)
+	buf.WriteString(` Destination="` + escapeAttrValue(sp.IdP.SSOURL) + `"`)
+	buf.WriteString(` AssertionConsumerServiceURL="` + escapeAttrValue(sp.ACSURL) + `"`)
+	buf.WriteString(` ProtocolBinding="` + BindingHTTPPOST + `">`)
+	buf.WriteString(`<saml:Issuer>` + escapeText(sp.EntityId) + `</saml:Issuer>`)
+	buf.WriteString(`<samlp:NameIDPolicy AllowCreate="true"/>`)
+	buf.WriteString(`</samlp:AuthnRequest>`)
+
+	return buf.Bytes()
+}
+
+// AuthnRequestURL returns the IdP HTTP-Redirect binding url for a new AuthnRequest.
+func (sp *ServiceProvider) AuthnRequestURL(requestId string, relayState string) (string, error) {
+	var buf bytes.Buffer
+
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(sp.AuthnRequest(requestId)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	ssoURL, err := url.Parse(sp.IdP.SSOURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := ssoURL.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	ssoURL.RawQuery = query.Encode()
+
+	return ssoURL.String(), nil
+}
+
+// AuthnRequestForm returns an auto submitting HTML form page for
+// sending a new AuthnRequest with the IdP HTTP-POST binding.
+func (sp *ServiceProvider) AuthnRequestForm(requestId string, relayState string) string {
+	encoded := base64.StdEncoding.EncodeToString(sp.AuthnRequest(requestId))
+
+	return `<!DOCTYPE html><html><body onload="document.forms[0].submit()">` +
+		`<form method="post" action="` + html.EscapeString(sp.IdP.SSOURL) + `">` +
+		`<input type="hidden" name="SAMLRequest" value="` + html.EscapeString(encoded) + `"/>` +
+		`<input type="hidden" name="RelayState" value="` + html.EscapeString(relayState) + `"/>` +
+		`<noscript><button type="submit">Continue</button></noscript>` +
+		`</form></body></html>`
+}
+
+// ParseResponse parses and validates the base64 encoded SAMLResponse
+// (HTTP-POST binding) and returns its assertion.
+//
+// Either the Response or the Assertion must be signed with one of
+// the trusted IdP certificates.
+//
+// Note that it is the caller responsibility to check whether the
+// returned [Assertion.InResponseTo] matches with a pending AuthnRequest
+// and to prevent replays.
+func (sp *ServiceProvider) ParseResponse(encodedResponse string) (*Assertion, error) {
+	if sp.IdP == nil {
+		return nil, errors.New("missing IdP configuration")
+	}
+
+	raw, err := decodeBase64(encodedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAMLResponse encoding: %w", err)
+	}
+
+	response, err := parseXML(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAMLResponse XML: %w", err)
+	}
+
+	if !response.is(nsProtocol, "Response") {
+		return nil, errors.New("the SAMLResponse root element must be Response")
+	}
+
+	if v := response.attr("Version"); v != "2.0" {
+		return nil, fmt.Errorf("unsupported SAML version %q", v)
+	}
+
+	if destination := response.attr("Destination"); destination != "" && destination != sp.ACSURL {
+		return nil, fmt.Errorf("the response destination %q doesn't match the ACS url", destination)
+	}
+
+	if issuer := response.childElement(nsAssertion, "Issuer"); issuer != nil && strings.TrimSpace(issuer.text()) != sp.IdP.EntityId {
+		return nil, errors.New("the response issuer doesn't match the IdP entity id")
+	}
+
+	status := response.childElement(nsProtocol, "Status")
+	if status == nil {
+		return nil, errors.New("missing response status")
+	}
+	statusCode := status.childElement(nsProtocol, "StatusCode")
+	if statusCode == nil || statusCode.attr("Value") != statusSuccess {
+		var code string
+		if statusCode != nil {
+			code = statusCode.attr("Value")
+		}
+		return nil, fmt.Errorf("unsuccessful response status %q", code)
+	}
+
+	if len(response.childElements(nsAssertion, "EncryptedAssertion")) > 0 {
+		return nil, errors.New("encrypted assertions are not supported")
+	}
+
+	assertion, err := singleChild(response, nsAssertion, "Assertion")
+	if err != nil {
+		return nil, err
+	}
+
+	// verify the signatures
+	// ---
+	var signed bool
+
+	if _, err := signatureElement(response); !errors.Is(err, errMissingSignature) {
+		if err := verifySignature(response, sp.IdP.Certificates); err != nil {
+			return nil, fmt.Errorf("invalid response signature: %w", err)
+		}
+		signed = true
+	}
+
+	if _, err := signatureElement(assertion); !errors.Is(err, errMissingSignature) {
+		if err := verifySignature(assertion, sp.IdP.Certificates); err != nil {
+			return nil, fmt.Errorf("invalid assertion signature: %w", err)
+		}
+		signed = true
+	}
+
+	if !signed {
+		return nil, errors.New("either the response or the assertion must be signed")
+	}
+
+	// validate the assertion
+	// ---
+	result := &Assertion{
+		Id:           assertion.attr("ID"),
+		InResponseTo: response.attr("InResponseTo"),
+		Attributes:   map[string][]string{},
+	}
+
+	issuer := assertion.childElement(nsAssertion, "Issuer")
+	if issuer == nil || strings.TrimSpace(issuer.text()) != sp.IdP.EntityId {
+		return nil, errors.New("the assertion issuer doesn't match the IdP entity id")
+	}
+
+	now := sp.currentTime()
+	skew := sp.MaxClockSkew
+	if skew <= 0 {
+		skew = defaultMaxClockSkew
+	}
+
+	if err := sp.validateSubject(assertion, result, now, skew); err != nil {
+		return nil, err
+	}
+
+	if err := sp.validateConditions(assertion, now, skew); err != nil {
+		return nil, err
+	}
+
+	if authnStatement := assertion.childElement(nsAssertion, "AuthnStatement"); authnStatement != nil {
+		result.SessionIndex = authnStatement.attr("SessionIndex")
+	}
+
+	for _, statement := range assertion.childElements(nsAssertion, "AttributeStatement") {
+		for _, attribute := range statement.childElements(nsAssertion, "Attribute") {
+			values := []string{}
+			for _, v := range attribute.childElements(nsAssertion, "AttributeValue") {
+				values = append(values, strings.TrimSpace(v.text()))
+			}
+
+			if name := attribute.attr("Name"); name != "" {
+				result.Attributes[name] = append(result.Attributes[name], values...)
+			}
+
+			if friendlyName := attribute.attr("FriendlyName"); friendlyName != "" {
+				if _, ok := result.Attributes[friendlyName]; !ok {
+					result.Attributes[friendlyName] = values
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (sp *ServiceProvider) validateSubject(assertion *element, result *Assertion, now time.Time, skew time.Duration) error {
+	subject := assertion.childElement(nsAssertion, "Subject")
+	if subject == nil {
+		return errors.New("missing assertion subject")
+	}
+
+	nameId := subject.childElement(nsAssertion, "NameID")
+	if nameId == nil || strings.TrimSpace(nameId.text()) == "" {
+		return errors.New("missing assertion subject NameID")
+	}
+	result.NameId = strings.TrimSpace(nameId.text())
+	result.NameIdFormat = nameId.attr("Format")
+
+	var lastErr error = errors.New("missing bearer subject confirmation")
+
+	for _, confirmation := range subject.childElements(nsAssertion, "SubjectConfirmation") {
+		if confirmation.attr("Method") != confirmationBearer {
+			continue
+		}
+
+		data := confirmation.childElement(nsAssertion, "SubjectConfirmationData")
+		if data == nil {
+			lastErr = errors.New("missing SubjectConfirmationData")
+			continue
+		}
+
+		if data.attr("Recipient") != sp.ACSURL {
+			lastErr = errors.New("the subject confirmation recipient doesn't match the ACS url")
+			continue
+		}
+
+		notOnOrAfter, err := parseTime(data.attr("NotOnOrAfter"))
+		if err != nil || notOnOrAfter.IsZero() || !now.Before(notOnOrAfter.Add(skew)) {
+			lastErr = errors.New("the subject confirmation has expired")
+			continue
+		}
+
+		inResponseTo := data.attr("InResponseTo")
+		if inResponseTo != "" && result.InResponseTo != "" && inResponseTo != result.InResponseTo {
+			lastErr = errors.New("the subject confirmation InResponseTo doesn't match the response")
+			continue
+		}
+
+		if result.InResponseTo == "" {
+			result.InResponseTo = inResponseTo
+		}
+
+		return nil
+	}
+
+	return lastErr
+}
+
+func (sp *ServiceProvider) validateConditions(assertion *element, now time.Time, skew time.Duration) error {
+	conditions := assertion.childElement(nsAssertion, "Conditions")
+	if conditions == nil {
+		return nil
+	}
+
+	notBefore, err := parseTime(conditions.attr("NotBefore"))
+	if err != nil {
+		return err
+	}
+	if !notBefore.IsZero() && now.Add(skew).Before(notBefore) {
+		return errors.New("the assertion is not valid yet")
+	}
+
+	notOnOrAfter, err := parseTime(conditions.attr("NotOnOrAfter"))
+	if err != nil {
+		return err
+	}
+	if !notOnOrAfter.IsZero() && !now.Before(notOnOrAfter.Add(skew)) {
+		return errors.New("the assertion has expired")
+	}
+
+	for _, restriction := range conditions.childElements(nsAssertion, "AudienceRestriction") {
+		var found bool
+		for _, audience := range restriction.childElements(nsAssertion, "Audience") {
+			if strings.TrimSpace(audience.text()) == sp.EntityId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New("the assertion audience doesn't match the SP entity id")
+		}
+	}
+
+	return nil
+}
+
+func (sp *ServiceProvider) currentTime() time.Time {
+	if sp.now != nil {
+		return sp.now()
+	}
+
+	return time.Now()
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time value %q", value)
+	}
+
+	return t, nil
+}
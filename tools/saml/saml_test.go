@@ -0,0 +1,573 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testIdPEntityId = "https://idp.example.com/metadata"
+	testSPEntityId  = "https://sp.example.com/saml/metadata"
+	testACSURL      = "https://sp.example.com/saml/acs"
+	testRequestId   = "id123"
+)
+
+var testNow = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+type testIdP struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestIdP(t testing.TB) *testIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    testNow.Add(-time.Hour),
+		NotAfter:     testNow.Add(time.Hour),
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testIdP{key: key, cert: cert}
+}
+
+func (idp *testIdP) metadata() string {
+	return `<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="` + testIdPEntityId + `">
+	<md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+		<md:KeyDescriptor use="signing">
+			<ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+				<ds:X509Data>
+					<ds:X509Certificate>` + base64.StdEncoding.EncodeToString(idp.cert.Raw) + `</ds:X509Certificate>
+				</ds:X509Data>
+			</ds:KeyInfo>
+		</md:KeyDescriptor>
+		<md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://idp.example.com/sso/post"/>
+		<md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/sso?tenant=1"/>
+	</md:IDPSSODescriptor>
+</md:EntityDescriptor>`
+}
+
+func (idp *testIdP) serviceProvider(t testing.TB) *ServiceProvider {
+	metadata, err := ParseIdPMetadata([]byte(idp.metadata()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &ServiceProvider{
+		IdP:      metadata,
+		EntityId: testSPEntityId,
+		ACSURL:   testACSURL,
+		now:      func() time.Time { return testNow },
+	}
+}
+
+// sign inserts an enveloped signature as first child (after the Issuer)
+// of the element with the specified ID.
+func (idp *testIdP) sign(t testing.TB, doc string, id string) string {
+	root, err := parseXML([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	el := findById(root, id)
+	if el == nil {
+		t.Fatalf("Missing element with ID %q", id)
+	}
+
+	digest := sha256.Sum256([]byte(canonicalize(el, nil, nil)))
+
+	signedInfo := `<ds:SignedInfo>` +
+		`<ds:CanonicalizationMethod Algorithm="` + algExcC14N + `"/>` +
+		`<ds:SignatureMethod Algorithm="` + algRSASHA256 + `"/>` +
+		`<ds:Reference URI="#` + id + `">` +
+		`<ds:Transforms>` +
+		`<ds:Transform Algorithm="` + algEnvelopedSignature + `"/>` +
+		`<ds:Transform Algorithm="` + algExcC14N + `"/>` +
+		`</ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="` + algDigestSHA256 + `"/>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference>` +
+		`</ds:SignedInfo>`
+
+	signature := `<ds:Signature xmlns:ds="` + nsDSig + `">` + signedInfo + `<ds:SignatureValue>SIGNATURE_VALUE</ds:SignatureValue></ds:Signature>`
+
+	// insert the signature after the element Issuer
+	marker := `ID="` + id + `"`
+	start := strings.Index(doc, marker)
+	issuerEnd := strings.Index(doc[start:], "</saml:Issuer>") + start + len("</saml:Issuer>")
+	signedDoc := doc[:issuerEnd] + signature + doc[issuerEnd:]
+
+	// canonicalize the SignedInfo in its final context
+	signedRoot, err := parseXML([]byte(signedDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signatureEl, err := signatureElement(findById(signedRoot, id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedInfoEl := signatureEl.childElement(nsDSig, "SignedInfo")
+
+	hashed := sha256.Sum256([]byte(canonicalize(signedInfoEl, nil, nil)))
+
+	rawSignature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.Replace(signedDoc, "SIGNATURE_VALUE", base64.StdEncoding.EncodeToString(rawSignature), 1)
+}
+
+func findById(el *element, id string) *element {
+	if el.attr("ID") == id {
+		return el
+	}
+
+	for _, c := range el.children {
+		if child, ok := c.(*element); ok {
+			if found := findById(child, id); found != nil {
+				return found
+			}
+		}
+	}
+
+	return nil
+}
+
+type testResponseOptions struct {
+	destination  string
+	issuer       string
+	audience     string
+	recipient    string
+	notOnOrAfter time.Time
+	status       string
+	nameId       string
+}
+
+func testResponse(opts testResponseOptions) string {
+	if opts.destination == "" {
+		opts.destination = testACSURL
+	}
+	if opts.issuer == "" {
+		opts.issuer = testIdPEntityId
+	}
+	if opts.audience == "" {
+		opts.audience = testSPEntityId
+	}
+	if opts.recipient == "" {
+		opts.recipient = testACSURL
+	}
+	if opts.notOnOrAfter.IsZero() {
+		opts.notOnOrAfter = testNow.Add(5 * time.Minute)
+	}
+	if opts.status == "" {
+		opts.status = statusSuccess
+	}
+	if opts.nameId == "" {
+		opts.nameId = "test@example.com"
+	}
+
+	notOnOrAfter := opts.notOnOrAfter.Format(time.RFC3339)
+	notBefore := testNow.Add(-5 * time.Minute).Format(time.RFC3339)
+
+	return `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="r1" Version="2.0" IssueInstant="2024-01-02T03:04:05Z" Destination="` + opts.destination + `" InResponseTo="` + testRequestId + `">` +
+		`<saml:Issuer>` + opts.issuer + `</saml:Issuer>` +
+		`<samlp:Status><samlp:StatusCode Value="` + opts.status + `"/></samlp:Status>` +
+		`<saml:Assertion xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" ID="a1" Version="2.0" IssueInstant="2024-01-02T03:04:05Z">` +
+		"\n  <saml:Issuer>" + opts.issuer + `</saml:Issuer>` +
+		`<saml:Subject>` +
+		`<saml:NameID Format="` + NameIdFormatEmail + `">` + opts.nameId + `</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">` +
+		`<saml:SubjectConfirmationData InResponseTo="` + testRequestId + `" NotOnOrAfter="` + notOnOrAfter + `" Recipient="` + opts.recipient + `"/>` +
+		`</saml:SubjectConfirmation>` +
+		`</saml:Subject>` +
+		`<saml:Conditions NotBefore="` + notBefore + `" NotOnOrAfter="` + notOnOrAfter + `">` +
+		`<saml:AudienceRestriction><saml:Audience>` + opts.audience + `</saml:Audience></saml:AudienceRestriction>` +
+		`</saml:Conditions>` +
+		`<saml:AuthnStatement AuthnInstant="2024-01-02T03:04:05Z" SessionIndex="s1"/>` +
+		`<saml:AttributeStatement>` +
+		`<saml:Attribute Name="urn:oid:2.5.4.42" FriendlyName="givenName"><saml:AttributeValue xsi:type="xs:string">John</saml:AttributeValue></saml:Attribute>` +
+		`<saml:Attribute Name="groups"><saml:AttributeValue>a</saml:AttributeValue><saml:AttributeValue>b</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement>` +
+		"</saml:Assertion>\n" +
+		`</samlp:Response>`
+}
+
+func encode(doc string) string {
+	return base64.StdEncoding.EncodeToString([]byte(doc))
+}
+
+func TestParseResponse(t *testing.T) {
+	t.Parallel()
+
+	idp := newTestIdP(t)
+	otherIdP := newTestIdP(t)
+
+	scenarios := []struct {
+		name        string
+		response    func() string
+		expectError bool
+	}{
+		{
+			name: "signed assertion",
+			response: func() string {
+				return idp.sign(t, testResponse(testResponseOptions{}), "a1")
+			},
+		},
+		{
+			name: "signed response",
+			response: func() string {
+				return idp.sign(t, testResponse(testResponseOptions{}), "r1")
+			},
+		},
+		{
+			name: "signed response and assertion",
+			response: func() string {
+				return idp.sign(t, idp.sign(t, testResponse(testResponseOptions{}), "a1"), "r1")
+			},
+		},
+		{
+			name: "unsigned",
+			response: func() string {
+				return testResponse(testResponseOptions{})
+			},
+			expectError: true,
+		},
+		{
+			name: "signed with untrusted key",
+			response: func() string {
+				return otherIdP.sign(t, testResponse(testResponseOptions{}), "a1")
+			},
+			expectError: true,
+		},
+		{
+			name: "tampered signed assertion",
+			response: func() string {
+				signed := idp.sign(t, testResponse(testResponseOptions{}), "a1")
+				return strings.Replace(signed, "test@example.com", "admin@example.com", 1)
+			},
+			expectError: true,
+		},
+		{
+			name: "wrapped unsigned assertion",
+			response: func() string {
+				signed := idp.sign(t, testResponse(testResponseOptions{}), "r1")
+				evil := strings.Replace(testResponse(testResponseOptions{nameId: "admin@example.com"}), `ID="a1"`, `ID="a2"`, 1)
+				start := strings.Index(evil, "<saml:Assertion")
+				end := strings.Index(evil, "</saml:Assertion>") + len("</saml:Assertion>")
+				return strings.Replace(signed, "</samlp:Response>", evil[start:end]+"</samlp:Response>", 1)
+			},
+			expectError: true,
+		},
+		{
+			name: "signature referencing another element",
+			response: func() string {
+				signed := idp.sign(t, testResponse(testResponseOptions{}), "a1")
+				return strings.Replace(signed, `ID="a1"`, `ID="a2"`, 1)
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid destination",
+			response: func() string {
+				return idp.sign(t, testResponse(testResponseOptions{destination: "https://other.example.com"}), "r1")
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid issuer",
+			response: func() string {
+				return idp.sign(t, testResponse(testResponseOptions{issuer: "https://other.example.com"}), "a1")
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid audience",
+			response: func() string {
+				return idp.sign(t, testResponse(testResponseOptions{audience: "https://other.example.com"}), "a1")
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid recipient",
+			response: func() string {
+				return idp.sign(t, testResponse(testResponseOptions{recipient: "https://other.example.com"}), "a1")
+			},
+			expectError: true,
+		},
+		{
+			name: "expired",
+			response: func() string {
+				return idp.sign(t, testResponse(testResponseOptions{notOnOrAfter: testNow.Add(-10 * time.Minute)}), "a1")
+			},
+			expectError: true,
+		},
+		{
+			name: "unsuccessful status",
+			response: func() string {
+				return idp.sign(t, testResponse(testResponseOptions{status: "urn:oasis:names:tc:SAML:2.0:status:Requester"}), "a1")
+			},
+			expectError: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			sp := idp.serviceProvider(t)
+
+			assertion, err := sp.ParseResponse(encode(s.response()))
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if assertion.NameId != "test@example.com" {
+				t.Fatalf("Expected NameId test@example.com, got %q", assertion.NameId)
+			}
+
+			if assertion.Id != "a1" {
+				t.Fatalf("Expected assertion id a1, got %q", assertion.Id)
+			}
+
+			if assertion.InResponseTo != testRequestId {
+				t.Fatalf("Expected InResponseTo %q, got %q", testRequestId, assertion.InResponseTo)
+			}
+
+			if assertion.NameIdFormat != NameIdFormatEmail {
+				t.Fatalf("Expected NameIdFormat %q, got %q", NameIdFormatEmail, assertion.NameIdFormat)
+			}
+
+			if assertion.SessionIndex != "s1" {
+				t.Fatalf("Expected SessionIndex s1, got %q", assertion.SessionIndex)
+			}
+
+			if v := assertion.Attribute("givenName"); v != "John" {
+				t.Fatalf("Expected givenName John, got %q", v)
+			}
+
+			if v := assertion.Attribute("urn:oid:2.5.4.42"); v != "John" {
+				t.Fatalf("Expected urn:oid:2.5.4.42 John, got %q", v)
+			}
+
+			if v := assertion.Attributes["groups"]; len(v) != 2 || v[0] != "a" || v[1] != "b" {
+				t.Fatalf("Expected groups [a b], got %v", v)
+			}
+		})
+	}
+}
+
+func TestParseResponseCommentInjection(t *testing.T) {
+	t.Parallel()
+
+	idp := newTestIdP(t)
+	sp := idp.serviceProvider(t)
+
+	signed := idp.sign(t, testResponse(testResponseOptions{nameId: "test@example.com.evil"}), "a1")
+	injected := strings.Replace(signed, "test@example.com.evil", "test@example.com<!---->.evil", 1)
+
+	assertion, err := sp.ParseResponse(encode(injected))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assertion.NameId != "test@example.com.evil" {
+		t.Fatalf("Expected the full NameID value, got %q", assertion.NameId)
+	}
+}
+
+func TestParseIdPMetadata(t *testing.T) {
+	t.Parallel()
+
+	idp := newTestIdP(t)
+
+	metadata, err := ParseIdPMetadata([]byte(idp.metadata()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.EntityId != testIdPEntityId {
+		t.Fatalf("Expected entity id %q, got %q", testIdPEntityId, metadata.EntityId)
+	}
+
+	if metadata.SSOURL != "https://idp.example.com/sso?tenant=1" || metadata.SSOBinding != BindingHTTPRedirect {
+		t.Fatalf("Expected the HTTP-Redirect SSO service, got %q (%s)", metadata.SSOURL, metadata.SSOBinding)
+	}
+
+	if len(metadata.Certificates) != 1 || !metadata.Certificates[0].Equal(idp.cert) {
+		t.Fatalf("Expected the IdP certificate, got %v", metadata.Certificates)
+	}
+
+	// wrapped in EntitiesDescriptor
+	wrapped := `<EntitiesDescriptor xmlns="urn:oasis:names:tc:SAML:2.0:metadata"><EntityDescriptor entityID="other"/>` + idp.metadata() + `</EntitiesDescriptor>`
+	metadata, err = ParseIdPMetadata([]byte(wrapped))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.EntityId != testIdPEntityId {
+		t.Fatalf("Expected entity id %q, got %q", testIdPEntityId, metadata.EntityId)
+	}
+
+	invalidScenarios := []string{
+		``,
+		`<invalid/>`,
+		`<EntityDescriptor xmlns="urn:oasis:names:tc:SAML:2.0:metadata" entityID="test"/>`,
+		strings.Replace(idp.metadata(), base64.StdEncoding.EncodeToString(idp.cert.Raw), "invalid", 1),
+		strings.Replace(idp.metadata(), `entityID="`+testIdPEntityId+`"`, "", 1),
+		strings.ReplaceAll(idp.metadata(), "SingleSignOnService", "ArtifactResolutionService"),
+	}
+	for i, data := range invalidScenarios {
+		if _, err := ParseIdPMetadata([]byte(data)); err == nil {
+			t.Fatalf("[%d] Expected error, got nil", i)
+		}
+	}
+}
+
+func TestServiceProviderMetadata(t *testing.T) {
+	t.Parallel()
+
+	sp := newTestIdP(t).serviceProvider(t)
+
+	raw, err := sp.Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := parseXML(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !root.is(nsMetadata, "EntityDescriptor") || root.attr("entityID") != testSPEntityId {
+		t.Fatalf("Invalid metadata root element:\n%s", raw)
+	}
+
+	descriptor := root.childElement(nsMetadata, "SPSSODescriptor")
+	if descriptor == nil {
+		t.Fatalf("Missing SPSSODescriptor:\n%s", raw)
+	}
+
+	acs := descriptor.childElement(nsMetadata, "AssertionConsumerService")
+	if acs == nil || acs.attr("Location") != testACSURL || acs.attr("Binding") != BindingHTTPPOST {
+		t.Fatalf("Invalid AssertionConsumerService:\n%s", raw)
+	}
+}
+
+func TestAuthnRequestURL(t *testing.T) {
+	t.Parallel()
+
+	sp := newTestIdP(t).serviceProvider(t)
+
+	rawURL, err := sp.AuthnRequestURL(testRequestId, "state&1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Host != "idp.example.com" || u.Path != "/sso" || u.Query().Get("tenant") != "1" {
+		t.Fatalf("Unexpected SSO url %q", rawURL)
+	}
+
+	if relayState := u.Query().Get("RelayState"); relayState != "state&1" {
+		t.Fatalf("Expected RelayState %q, got %q", "state&1", relayState)
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, err := parseXML(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !request.is(nsProtocol, "AuthnRequest") ||
+		request.attr("ID") != testRequestId ||
+		request.attr("AssertionConsumerServiceURL") != testACSURL ||
+		request.attr("Destination") != sp.IdP.SSOURL {
+		t.Fatalf("Invalid AuthnRequest:\n%s", raw)
+	}
+
+	issuer := request.childElement(nsAssertion, "Issuer")
+	if issuer == nil || issuer.text() != testSPEntityId {
+		t.Fatalf("Invalid AuthnRequest issuer:\n%s", raw)
+	}
+}
+
+func TestAuthnRequestForm(t *testing.T) {
+	t.Parallel()
+
+	sp := newTestIdP(t).serviceProvider(t)
+	sp.IdP.SSOURL = "https://idp.example.com/sso/post"
+	sp.IdP.SSOBinding = BindingHTTPPOST
+
+	form := sp.AuthnRequestForm(testRequestId, `"state"`)
+
+	if !strings.Contains(form, `action="https://idp.example.com/sso/post"`) {
+		t.Fatalf("Missing form action:\n%s", form)
+	}
+
+	if !strings.Contains(form, `name="RelayState" value="&#34;state&#34;"`) {
+		t.Fatalf("Missing escaped RelayState:\n%s", form)
+	}
+
+	if !strings.Contains(form, `name="SAMLRequest" value="`+base64.StdEncoding.EncodeToString(sp.AuthnRequest(testRequestId))+`"`) {
+		t.Fatalf("Missing SAMLRequest:\n%s", form)
+	}
+}
+
+func TestNewRequestId(t *testing.T) {
+	t.Parallel()
+
+	a := NewRequestId()
+	b := NewRequestId()
+
+	if a == b {
+		t.Fatal("Expected unique request ids")
+	}
+
+	if !strings.HasPrefix(a, "id") || len(a) != 42 {
+		t.Fatalf("Invalid request id %q", a)
+	}
+}
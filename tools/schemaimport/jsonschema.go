@@ -0,0 +1,270 @@
+package schemaimport
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// jsonSchema is a minimal JSON Schema document/subschema representation.
+type jsonSchema struct {
+	Title       string                 `json:"title"`
+	Ref         string                 `json:"$ref"`
+	Type        any                    `json:"type"`
+	Format      string                 `json:"format"`
+	Enum        []any                  `json:"enum"`
+	Items       *jsonSchema            `json:"items"`
+	Properties  map[string]*jsonSchema `json:"properties"`
+	Required    []string               `json:"required"`
+	Default     any                    `json:"default"`
+	UniqueItems bool                   `json:"uniqueItems"`
+	Defs        map[string]*jsonSchema `json:"$defs"`
+	Definitions map[string]*jsonSchema `json:"definitions"`
+
+	// propertiesOrder stores the original document properties order
+	propertiesOrder []string
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface and
+// additionally keeps track of the properties declaration order.
+func (s *jsonSchema) UnmarshalJSON(data []byte) error {
+	type alias jsonSchema
+
+	// boolean schemas (e.g. "additionalProperties": false)
+	if len(data) > 0 && data[0] != '{' {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, (*alias)(s)); err != nil {
+		return err
+	}
+
+	raw := struct {
+		Properties json.RawMessage `json:"properties"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	s.propertiesOrder = jsonObjectKeys(raw.Properties)
+
+	return nil
+}
+
+// ParseJSONSchema parses a JSON Schema document.
+//
+// Each object definition from "$defs" or "definitions" is converted
+// to a table. If the root schema is also an object with properties,
+// it is converted to a table named after its "title".
+//
+// A "$ref" to another object definition is mapped to a relation column.
+func ParseJSONSchema(data []byte) ([]*Table, error) {
+	root := &jsonSchema{}
+	if err := json.Unmarshal(data, root); err != nil {
+		return nil, err
+	}
+
+	defs := map[string]*jsonSchema{}
+	defsOrder := []string{}
+	for _, group := range []map[string]*jsonSchema{root.Definitions, root.Defs} {
+		names := make([]string, 0, len(group))
+		for name := range group {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		for _, name := range names {
+			if group[name] == nil {
+				continue
+			}
+			defs[name] = group[name]
+			defsOrder = append(defsOrder, name)
+		}
+	}
+
+	resolve := func(s *jsonSchema) (*jsonSchema, string) {
+		if s == nil || s.Ref == "" {
+			return s, ""
+		}
+
+		name := s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+		if def, ok := defs[name]; ok {
+			return def, name
+		}
+
+		return s, ""
+	}
+
+	tables := []*Table{}
+
+	addTable := func(name string, schema *jsonSchema) {
+		if len(schema.Properties) == 0 {
+			return
+		}
+
+		table := &Table{Name: name}
+
+		for _, propName := range schema.propertiesOrder {
+			prop := schema.Properties[propName]
+			if prop == nil {
+				continue
+			}
+
+			col := &Column{
+				Name:     propName,
+				Primary:  propName == "id",
+				Required: slices.Contains(schema.Required, propName) && prop.Default == nil && !isJSONSchemaNullable(prop),
+			}
+
+			resolved, refName := resolve(prop)
+
+			switch {
+			case refName != "" && len(resolved.Properties) > 0:
+				col.Kind = KindRelation
+				col.Ref = refName
+			case jsonSchemaType(resolved) == "array" && resolved.Items != nil:
+				items, itemsRefName := resolve(resolved.Items)
+				if itemsRefName != "" && len(items.Properties) > 0 {
+					col.Kind = KindRelation
+					col.Ref = itemsRefName
+					col.Multiple = true
+				} else if values := jsonSchemaEnumValues(items); len(values) > 0 {
+					col.Kind = KindSelect
+					col.Values = values
+					col.Multiple = true
+				} else {
+					col.Kind = KindJSON
+				}
+			default:
+				col.Kind, col.Integer, col.Values = jsonSchemaKind(resolved)
+			}
+
+			if col.Primary {
+				col.Required = false
+			}
+
+			table.Columns = append(table.Columns, col)
+		}
+
+		tables = append(tables, table)
+	}
+
+	if len(root.Properties) > 0 {
+		if root.Title == "" {
+			return nil, errors.New("the root object schema must have a title")
+		}
+		addTable(root.Title, root)
+	}
+
+	for _, name := range defsOrder {
+		addTable(name, defs[name])
+	}
+
+	if len(tables) == 0 {
+		return nil, errNoTables
+	}
+
+	return tables, nil
+}
+
+// jsonSchemaKind maps the specified schema to one of the normalized column kinds.
+func jsonSchemaKind(s *jsonSchema) (kind string, integer bool, values []string) {
+	if values := jsonSchemaEnumValues(s); len(values) > 0 {
+		return KindSelect, false, values
+	}
+
+	switch jsonSchemaType(s) {
+	case "integer":
+		return KindNumber, true, nil
+	case "number":
+		return KindNumber, false, nil
+	case "boolean":
+		return KindBool, false, nil
+	case "object", "array":
+		return KindJSON, false, nil
+	case "string":
+		switch s.Format {
+		case "date", "date-time":
+			return KindDate, false, nil
+		case "email", "idn-email":
+			return KindEmail, false, nil
+		case "uri", "url", "iri":
+			return KindURL, false, nil
+		}
+		return KindText, false, nil
+	default:
+		if len(s.Properties) > 0 {
+			return KindJSON, false, nil
+		}
+		return KindText, false, nil
+	}
+}
+
+// jsonSchemaType returns the first non-null schema type.
+func jsonSchemaType(s *jsonSchema) string {
+	switch v := s.Type.(type) {
+	case string:
+		return v
+	case []any:
+		for _, t := range v {
+			if str, _ := t.(string); str != "" && str != "null" {
+				return str
+			}
+		}
+	}
+
+	return ""
+}
+
+func isJSONSchemaNullable(s *jsonSchema) bool {
+	if list, ok := s.Type.([]any); ok {
+		return slices.Contains(list, any("null"))
+	}
+
+	return false
+}
+
+func jsonSchemaEnumValues(s *jsonSchema) []string {
+	values := make([]string, 0, len(s.Enum))
+
+	for _, v := range s.Enum {
+		if str, ok := v.(string); ok && str != "" {
+			values = append(values, str)
+		}
+	}
+
+	return values
+}
+
+// jsonObjectKeys returns the top level keys of a raw JSON object in their declaration order.
+func jsonObjectKeys(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(string(raw)))
+
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil
+	}
+
+	keys := []string{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return keys
+		}
+
+		key, _ := t.(string)
+		keys = append(keys, key)
+
+		// skip the value
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return keys
+		}
+	}
+
+	return keys
+}
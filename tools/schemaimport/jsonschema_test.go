@@ -0,0 +1,103 @@
+package schemaimport_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/schemaimport"
+)
+
+func TestParseJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	schema := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "order",
+		"type": "object",
+		"required": ["number", "customer", "status"],
+		"properties": {
+			"id": {"type": "string"},
+			"number": {"type": "integer"},
+			"customer": {"$ref": "#/$defs/customer"},
+			"items": {"type": "array", "items": {"$ref": "#/$defs/product"}},
+			"status": {"$ref": "#/$defs/status"},
+			"labels": {"type": "array", "items": {"enum": ["a", "b"]}},
+			"note": {"type": ["string", "null"]},
+			"meta": {"type": "object", "additionalProperties": true}
+		},
+		"additionalProperties": false,
+		"$defs": {
+			"status": {"type": "string", "enum": ["new", "paid"]},
+			"product": {
+				"type": "object",
+				"required": ["price", "active"],
+				"properties": {
+					"price": {"type": "number"},
+					"active": {"type": "boolean", "default": true}
+				}
+			},
+			"customer": {
+				"type": "object",
+				"required": ["email"],
+				"properties": {
+					"email": {"type": "string", "format": "email"},
+					"website": {"type": "string", "format": "uri"},
+					"birthday": {"type": "string", "format": "date"}
+				}
+			}
+		}
+	}`
+
+	tables, err := schemaimport.ParseJSONSchema([]byte(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := json.Marshal(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"Name":"order","Columns":[` +
+		`{"Name":"id","Kind":"text","Primary":true,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"number","Kind":"number","Primary":false,"Required":true,"Unique":false,"Integer":true,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"customer","Kind":"relation","Primary":false,"Required":true,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":"customer"},` +
+		`{"Name":"items","Kind":"relation","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":true,"Values":null,"Ref":"product"},` +
+		`{"Name":"status","Kind":"select","Primary":false,"Required":true,"Unique":false,"Integer":false,"Multiple":false,"Values":["new","paid"],"Ref":""},` +
+		`{"Name":"labels","Kind":"select","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":true,"Values":["a","b"],"Ref":""},` +
+		`{"Name":"note","Kind":"text","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"meta","Kind":"json","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""}]},` +
+		`{"Name":"customer","Columns":[` +
+		`{"Name":"email","Kind":"email","Primary":false,"Required":true,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"website","Kind":"url","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"birthday","Kind":"date","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""}]},` +
+		`{"Name":"product","Columns":[` +
+		`{"Name":"price","Kind":"number","Primary":false,"Required":true,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"active","Kind":"bool","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""}]}]`
+
+	if str := string(raw); str != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, str)
+	}
+}
+
+func TestParseJSONSchemaErrors(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name   string
+		schema string
+	}{
+		{"invalid json", `{`},
+		{"root object without title", `{"type": "object", "properties": {"a": {"type": "string"}}}`},
+		{"no object definitions", `{"$defs": {"status": {"enum": ["a"]}}}`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			_, err := schemaimport.ParseJSONSchema([]byte(s.schema))
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package schemaimport
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+var (
+	prismaBlockStartRegex = regexp.MustCompile(`^(model|enum|type|view|datasource|generator)\s+(\w+)\s*\{`)
+	prismaFieldRegex      = regexp.MustCompile(`^(\w+)\s+(\w+)(\[\])?(\?)?\s*(.*)$`)
+	prismaMapRegex        = regexp.MustCompile(`@@map\(\s*(?:name:\s*)?"([^"]+)"`)
+	prismaUniqueRegex     = regexp.MustCompile(`@@unique\(\s*(?:fields:\s*)?\[\s*(\w+)\s*\]`)
+	prismaRelFieldsRegex  = regexp.MustCompile(`fields:\s*\[([^\]]*)\]`)
+)
+
+type prismaField struct {
+	name      string
+	typ       string
+	list      bool
+	optional  bool
+	attrs     string
+	relFields []string
+}
+
+type prismaModel struct {
+	name   string
+	table  string
+	fields []*prismaField
+	unique []string
+}
+
+// ParsePrisma parses the models and enums of a Prisma schema file.
+//
+// Scalar fields are mapped to their closest column kind, enums to
+// select columns and the model relations to relation columns
+// (the back-relation and foreign key scalar fields are skipped).
+func ParsePrisma(data []byte) ([]*Table, error) {
+	models := []*prismaModel{}
+	modelsMap := map[string]*prismaModel{}
+	enums := map[string][]string{}
+
+	var currentKind string
+	var currentModel *prismaModel
+	var currentEnum string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// strip comments
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if currentKind == "" {
+			matches := prismaBlockStartRegex.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+
+			currentKind = matches[1]
+			switch currentKind {
+			case "model":
+				currentModel = &prismaModel{name: matches[2], table: matches[2]}
+				models = append(models, currentModel)
+				modelsMap[currentModel.name] = currentModel
+			case "enum":
+				currentEnum = matches[2]
+				enums[currentEnum] = []string{}
+			}
+			continue
+		}
+
+		if line == "}" {
+			currentKind = ""
+			currentModel = nil
+			continue
+		}
+
+		switch currentKind {
+		case "enum":
+			if !strings.HasPrefix(line, "@@") {
+				enums[currentEnum] = append(enums[currentEnum], strings.Fields(line)[0])
+			}
+		case "model":
+			if strings.HasPrefix(line, "@@") {
+				if m := prismaMapRegex.FindStringSubmatch(line); m != nil {
+					currentModel.table = m[1]
+				}
+				if m := prismaUniqueRegex.FindStringSubmatch(line); m != nil {
+					currentModel.unique = append(currentModel.unique, m[1])
+				}
+				continue
+			}
+
+			matches := prismaFieldRegex.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+
+			field := &prismaField{
+				name:     matches[1],
+				typ:      matches[2],
+				list:     matches[3] != "",
+				optional: matches[4] != "",
+				attrs:    matches[5],
+			}
+
+			if m := prismaRelFieldsRegex.FindStringSubmatch(field.attrs); m != nil {
+				for _, name := range strings.Split(m[1], ",") {
+					if name = strings.TrimSpace(name); name != "" {
+						field.relFields = append(field.relFields, name)
+					}
+				}
+			}
+
+			currentModel.fields = append(currentModel.fields, field)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(models) == 0 {
+		return nil, errNoTables
+	}
+
+	tables := make([]*Table, 0, len(models))
+
+	for _, model := range models {
+		table := &Table{Name: model.table}
+
+		// the foreign key scalar fields are replaced by their relation field
+		fkFields := map[string]bool{}
+		for _, f := range model.fields {
+			for _, name := range f.relFields {
+				fkFields[name] = true
+			}
+		}
+
+		for _, f := range model.fields {
+			if fkFields[f.name] {
+				continue
+			}
+
+			col := &Column{
+				Name:     f.name,
+				Primary:  strings.Contains(f.attrs, "@id"),
+				Unique:   strings.Contains(f.attrs, "@unique"),
+				Required: !f.optional && !f.list && !strings.Contains(f.attrs, "@default") && !strings.Contains(f.attrs, "@updatedAt"),
+			}
+
+			if related, ok := modelsMap[f.typ]; ok {
+				if !isPrismaOwnRelation(model, f, related) {
+					continue
+				}
+
+				col.Kind = KindRelation
+				col.Ref = related.table
+				col.Multiple = f.list
+				col.Unique = col.Unique || (len(f.relFields) == 1 && isPrismaUnique(model, f.relFields[0]))
+			} else if values, ok := enums[f.typ]; ok {
+				col.Kind = KindSelect
+				col.Values = values
+				col.Multiple = f.list
+			} else {
+				col.Kind, col.Integer = prismaKind(f.typ, f.list)
+			}
+
+			if col.Primary {
+				col.Required = false
+			}
+
+			table.Columns = append(table.Columns, col)
+		}
+
+		for _, name := range model.unique {
+			if c := table.Column(name); c != nil {
+				c.Unique = true
+			}
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+// isPrismaOwnRelation reports whether the relation field should be
+// stored in the model, aka. it is not the back-relation side.
+func isPrismaOwnRelation(model *prismaModel, field *prismaField, related *prismaModel) bool {
+	// explicit foreign key
+	if len(field.relFields) > 0 {
+		return true
+	}
+
+	if !field.list {
+		return false // 1-1 back-relation
+	}
+
+	// implicit many-to-many (both sides are lists)
+	// -> store it only in the first model (or in both for self relations)
+	for _, rf := range related.fields {
+		if rf.typ != model.name || (related == model && rf == field) {
+			continue
+		}
+
+		if !rf.list {
+			return false // 1-n back-relation
+		}
+	}
+
+	return model.name <= related.name
+}
+
+func isPrismaUnique(model *prismaModel, fieldName string) bool {
+	for _, f := range model.fields {
+		if f.name == fieldName {
+			return strings.Contains(f.attrs, "@unique")
+		}
+	}
+
+	return false
+}
+
+// prismaKind maps the specified Prisma scalar type to one of the normalized column kinds.
+func prismaKind(typ string, list bool) (kind string, integer bool) {
+	if list {
+		return KindJSON, false
+	}
+
+	switch typ {
+	case "Int", "BigInt":
+		return KindNumber, true
+	case "Float", "Decimal":
+		return KindNumber, false
+	case "Boolean":
+		return KindBool, false
+	case "DateTime":
+		return KindDate, false
+	case "Json":
+		return KindJSON, false
+	default:
+		return KindText, false
+	}
+}
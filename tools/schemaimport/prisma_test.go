@@ -0,0 +1,107 @@
+package schemaimport_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/schemaimport"
+)
+
+func TestParsePrisma(t *testing.T) {
+	t.Parallel()
+
+	schema := `
+		datasource db {
+			provider = "postgresql"
+			url      = env("DATABASE_URL")
+		}
+
+		enum Role {
+			USER
+			ADMIN
+		}
+
+		// the users model
+		model User {
+			id        Int      @id @default(autoincrement())
+			email     String   @unique
+			name      String?
+			role      Role     @default(USER)
+			roles     Role[]
+			score     Float
+			createdAt DateTime @default(now())
+			posts     Post[]
+			profile   Profile?
+			tags      Tag[]
+		}
+
+		model Profile {
+			id     String @id @default(uuid())
+			bio    String
+			user   User   @relation(fields: [userId], references: [id])
+			userId Int    @unique
+		}
+
+		model Post {
+			id       Int     @id
+			title    String
+			data     Json?
+			author   User    @relation(fields: [authorId], references: [id])
+			authorId Int
+			slug     String
+
+			@@unique([slug])
+			@@map("blog_posts")
+		}
+
+		model Tag {
+			id    Int    @id
+			users User[]
+		}
+	`
+
+	tables, err := schemaimport.ParsePrisma([]byte(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := json.Marshal(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"Name":"User","Columns":[` +
+		`{"Name":"id","Kind":"number","Primary":true,"Required":false,"Unique":false,"Integer":true,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"email","Kind":"text","Primary":false,"Required":true,"Unique":true,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"name","Kind":"text","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"role","Kind":"select","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":["USER","ADMIN"],"Ref":""},` +
+		`{"Name":"roles","Kind":"select","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":true,"Values":["USER","ADMIN"],"Ref":""},` +
+		`{"Name":"score","Kind":"number","Primary":false,"Required":true,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"createdAt","Kind":"date","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""}]},` +
+		`{"Name":"Profile","Columns":[` +
+		`{"Name":"id","Kind":"text","Primary":true,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"bio","Kind":"text","Primary":false,"Required":true,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"user","Kind":"relation","Primary":false,"Required":true,"Unique":true,"Integer":false,"Multiple":false,"Values":null,"Ref":"User"}]},` +
+		`{"Name":"blog_posts","Columns":[` +
+		`{"Name":"id","Kind":"number","Primary":true,"Required":false,"Unique":false,"Integer":true,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"title","Kind":"text","Primary":false,"Required":true,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"data","Kind":"json","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"author","Kind":"relation","Primary":false,"Required":true,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":"User"},` +
+		`{"Name":"slug","Kind":"text","Primary":false,"Required":true,"Unique":true,"Integer":false,"Multiple":false,"Values":null,"Ref":""}]},` +
+		`{"Name":"Tag","Columns":[` +
+		`{"Name":"id","Kind":"number","Primary":true,"Required":false,"Unique":false,"Integer":true,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"users","Kind":"relation","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":true,"Values":null,"Ref":"User"}]}]`
+
+	if str := string(raw); str != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, str)
+	}
+}
+
+func TestParsePrismaNoModels(t *testing.T) {
+	t.Parallel()
+
+	_, err := schemaimport.ParsePrisma([]byte(`enum Role { USER }`))
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
@@ -0,0 +1,139 @@
+// Package schemaimport implements best-effort parsers that convert
+// external schema definitions (SQL DDL dumps, Prisma schemas and
+// JSON Schema documents) into a simplified list of tables.
+//
+// The parsed tables are intentionally storage agnostic and it is up
+// to the caller to map them to the concrete collection fields.
+//
+// Example:
+//
+//	tables, err := schemaimport.Parse(schemaimport.FormatPrisma, data)
+package schemaimport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Supported schema formats.
+const (
+	FormatSQL        = "sql"
+	FormatPrisma     = "prisma"
+	FormatJSONSchema = "jsonschema"
+)
+
+// Normalized column kinds.
+const (
+	KindText     = "text"
+	KindNumber   = "number"
+	KindBool     = "bool"
+	KindDate     = "date"
+	KindJSON     = "json"
+	KindEmail    = "email"
+	KindURL      = "url"
+	KindSelect   = "select"
+	KindRelation = "relation"
+)
+
+// Table describes a single parsed table (aka. model, definition).
+type Table struct {
+	Name    string
+	Columns []*Column
+}
+
+// Column returns the table column with the specified name (if exists).
+func (t *Table) Column(name string) *Column {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Column describes a single parsed table column (aka. field, property).
+type Column struct {
+	Name string
+
+	// Kind is one of the normalized Kind* constants.
+	Kind string
+
+	// Primary indicates that the column is (part of) the table primary key.
+	Primary bool
+
+	// Required indicates that the column is not nullable and doesn't have a default value.
+	Required bool
+
+	// Unique indicates that the column has a single column unique constraint.
+	Unique bool
+
+	// Integer indicates that a KindNumber column accepts only integers.
+	Integer bool
+
+	// Multiple indicates that a KindSelect or KindRelation column accepts more than one value.
+	Multiple bool
+
+	// Values lists the allowed KindSelect column values.
+	Values []string
+
+	// Ref is the name of the referenced table of a KindRelation column.
+	Ref string
+}
+
+// Parse parses the provided schema definition data in the specified format.
+func Parse(format string, data []byte) ([]*Table, error) {
+	switch format {
+	case FormatSQL:
+		return ParseSQL(data)
+	case FormatPrisma:
+		return ParsePrisma(data)
+	case FormatJSONSchema:
+		return ParseJSONSchema(data)
+	default:
+		return nil, fmt.Errorf("unsupported schema format %q", format)
+	}
+}
+
+// DetectFormat tries to detect the schema format based on the
+// filename extension and falls back to sniffing the data content.
+//
+// Returns an empty string if the format couldn't be detected.
+func DetectFormat(filename string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".sql":
+		return FormatSQL
+	case ".prisma":
+		return FormatPrisma
+	case ".json":
+		return FormatJSONSchema
+	}
+
+	trimmed := bytes.TrimSpace(data)
+
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return FormatJSONSchema
+	}
+
+	if prismaBlockRegex.Match(trimmed) {
+		return FormatPrisma
+	}
+
+	if sqlCreateTableRegex.Match(trimmed) {
+		return FormatSQL
+	}
+
+	return ""
+}
+
+var (
+	prismaBlockRegex    = regexp.MustCompile(`(?m)^\s*(model|enum)\s+\w+\s*\{`)
+	sqlCreateTableRegex = regexp.MustCompile(`(?i)create\s+table`)
+)
+
+// errNoTables is returned when the parsed schema doesn't have any tables.
+var errNoTables = errors.New("no tables found")
@@ -0,0 +1,64 @@
+package schemaimport_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/schemaimport"
+)
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		filename string
+		data     string
+		expected string
+	}{
+		{"schema.sql", "", schemaimport.FormatSQL},
+		{"schema.PRISMA", "", schemaimport.FormatPrisma},
+		{"schema.json", "", schemaimport.FormatJSONSchema},
+		{"schema.txt", ` {"type": "object"}`, schemaimport.FormatJSONSchema},
+		{"schema.txt", "// comment\nmodel User {\n}", schemaimport.FormatPrisma},
+		{"schema.txt", "create table test (id int);", schemaimport.FormatSQL},
+		{"schema.txt", "unknown", ""},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filename+"_"+s.expected, func(t *testing.T) {
+			result := schemaimport.DetectFormat(s.filename, []byte(s.data))
+			if result != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		format      string
+		data        string
+		expectError bool
+	}{
+		{"unknown", "create table test (id int);", true},
+		{schemaimport.FormatSQL, "create table test (id int);", false},
+		{schemaimport.FormatPrisma, "model Test {\nid Int @id\n}", false},
+		{schemaimport.FormatJSONSchema, `{"title": "test", "properties": {"id": {"type": "string"}}}`, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.format, func(t *testing.T) {
+			tables, err := schemaimport.Parse(s.format, []byte(s.data))
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && (len(tables) != 1 || tables[0].Name != "test" && tables[0].Name != "Test") {
+				t.Fatalf("Expected a single test table, got %v", tables)
+			}
+		})
+	}
+}
@@ -0,0 +1,629 @@
+package schemaimport
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ParseSQL parses Postgres and MySQL DDL statements
+// (e.g. a "pg_dump --schema-only" or "mysqldump --no-data" output).
+//
+// Only the CREATE TABLE, CREATE TYPE ... AS ENUM, CREATE UNIQUE INDEX
+// and ALTER TABLE ... ADD constraint statements are taken into account.
+// All other statements are ignored.
+func ParseSQL(data []byte) ([]*Table, error) {
+	statements := splitSQLStatements(string(data))
+
+	tokenized := make([][]string, len(statements))
+	for i, stmt := range statements {
+		tokenized[i] = sqlTokenize(stmt)
+	}
+
+	// Postgres enum types
+	enums := map[string][]string{}
+	for _, tokens := range tokenized {
+		if len(tokens) >= 6 &&
+			sqlKeywords(tokens, "create", "type") &&
+			sqlKeywords(tokens[3:], "as", "enum") &&
+			strings.HasPrefix(tokens[5], "(") {
+			enums[strings.ToLower(sqlIdent(tokens[2]))] = sqlStringList(tokens[5])
+		}
+	}
+
+	tables := []*Table{}
+	tablesMap := map[string]*Table{}
+
+	findTable := func(rawName string) *Table {
+		return tablesMap[strings.ToLower(sqlIdent(rawName))]
+	}
+
+	for _, tokens := range tokenized {
+		switch {
+		case sqlKeywords(tokens, "create"):
+			if t := parseSQLCreateTable(tokens, enums); t != nil {
+				tables = append(tables, t)
+				tablesMap[strings.ToLower(t.Name)] = t
+				continue
+			}
+
+			parseSQLCreateUniqueIndex(tokens, findTable)
+		case sqlKeywords(tokens, "alter", "table"):
+			parseSQLAlterTable(tokens, findTable)
+		}
+	}
+
+	if len(tables) == 0 {
+		return nil, errNoTables
+	}
+
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if c.Primary {
+				c.Required = false
+			}
+		}
+	}
+
+	return tables, nil
+}
+
+// parseSQLCreateTable parses a "CREATE TABLE" statement tokens.
+//
+// Returns nil if the tokens are not from a supported CREATE TABLE statement.
+func parseSQLCreateTable(tokens []string, enums map[string][]string) *Table {
+	i := 1
+
+	// skip table modifiers (temporary, unlogged, etc.)
+	for i < len(tokens) && !strings.EqualFold(tokens[i], "table") {
+		if strings.HasPrefix(tokens[i], "(") {
+			return nil
+		}
+		i++
+	}
+	i++ // "table"
+
+	if sqlKeywords(tokens[min(i, len(tokens)):], "if", "not", "exists") {
+		i += 3
+	}
+
+	if i+1 >= len(tokens) || !strings.HasPrefix(tokens[i+1], "(") {
+		return nil // e.g. CREATE TABLE ... AS SELECT
+	}
+
+	table := &Table{Name: sqlIdent(tokens[i])}
+
+	for _, def := range splitSQLTopLevel(sqlParenContent(tokens[i+1]), ',') {
+		defTokens := sqlTokenize(def)
+		if len(defTokens) == 0 {
+			continue
+		}
+
+		if isSQLTableConstraint(defTokens) {
+			applySQLTableConstraint(table, defTokens)
+			continue
+		}
+
+		if col := parseSQLColumn(defTokens, enums); col != nil {
+			table.Columns = append(table.Columns, col)
+		}
+	}
+
+	return table
+}
+
+// parseSQLCreateUniqueIndex parses a single column "CREATE UNIQUE INDEX" statement tokens.
+func parseSQLCreateUniqueIndex(tokens []string, findTable func(string) *Table) {
+	if !sqlKeywords(tokens[1:], "unique") {
+		return
+	}
+
+	for i := 2; i < len(tokens)-1; i++ {
+		if !strings.EqualFold(tokens[i], "on") {
+			continue
+		}
+
+		j := i + 1
+		if strings.EqualFold(tokens[j], "only") {
+			j++
+		}
+
+		table := findTable(tokens[min(j, len(tokens)-1)])
+		if table == nil {
+			return
+		}
+
+		for _, t := range tokens[j+1:] {
+			if strings.HasPrefix(t, "(") {
+				markSQLUnique(table, sqlIdentList(t))
+				return
+			}
+		}
+
+		return
+	}
+}
+
+// parseSQLAlterTable parses an "ALTER TABLE ... ADD constraint" statement tokens.
+func parseSQLAlterTable(tokens []string, findTable func(string) *Table) {
+	i := 2
+	for i < len(tokens) && (strings.EqualFold(tokens[i], "only") || sqlKeywords(tokens[i:], "if", "exists")) {
+		if strings.EqualFold(tokens[i], "if") {
+			i++
+		}
+		i++
+	}
+
+	if i >= len(tokens) {
+		return
+	}
+
+	table := findTable(tokens[i])
+	if table == nil {
+		return
+	}
+
+	// split the comma separated alter clauses
+	var clause []string
+	clauses := [][]string{}
+	for _, t := range tokens[i+1:] {
+		if t == "," {
+			clauses = append(clauses, clause)
+			clause = nil
+			continue
+		}
+		clause = append(clause, t)
+	}
+	clauses = append(clauses, clause)
+
+	for _, c := range clauses {
+		if len(c) < 2 || !strings.EqualFold(c[0], "add") {
+			continue
+		}
+
+		if isSQLTableConstraint(c[1:]) {
+			applySQLTableConstraint(table, c[1:])
+		}
+	}
+}
+
+func isSQLTableConstraint(tokens []string) bool {
+	switch strings.ToLower(tokens[0]) {
+	case "constraint", "primary", "foreign", "unique", "key", "index", "fulltext", "spatial", "check", "exclude", "like":
+		return true
+	default:
+		return false
+	}
+}
+
+// applySQLTableConstraint applies a table constraint definition to the table columns.
+func applySQLTableConstraint(table *Table, tokens []string) {
+	if sqlKeywords(tokens, "constraint") {
+		tokens = tokens[min(2, len(tokens)):]
+	}
+
+	if len(tokens) == 0 {
+		return
+	}
+
+	firstParen := func(tokens []string) (string, int) {
+		for i, t := range tokens {
+			if strings.HasPrefix(t, "(") {
+				return t, i
+			}
+		}
+		return "", -1
+	}
+
+	switch {
+	case sqlKeywords(tokens, "primary", "key"):
+		if cols, _ := firstParen(tokens); cols != "" {
+			for _, name := range sqlIdentList(cols) {
+				if c := table.Column(name); c != nil {
+					c.Primary = true
+				}
+			}
+		}
+	case sqlKeywords(tokens, "unique"):
+		if cols, _ := firstParen(tokens); cols != "" {
+			markSQLUnique(table, sqlIdentList(cols))
+		}
+	case sqlKeywords(tokens, "foreign", "key"):
+		cols, i := firstParen(tokens)
+		if i < 0 {
+			return
+		}
+
+		names := sqlIdentList(cols)
+		if len(names) != 1 || i+2 >= len(tokens) || !strings.EqualFold(tokens[i+1], "references") {
+			return
+		}
+
+		if c := table.Column(names[0]); c != nil {
+			c.Kind = KindRelation
+			c.Ref = sqlIdent(tokens[i+2])
+			c.Integer = false
+			c.Values = nil
+			c.Multiple = false
+		}
+	}
+}
+
+func markSQLUnique(table *Table, names []string) {
+	if len(names) != 1 {
+		return // composite unique constraints are not supported
+	}
+
+	if c := table.Column(names[0]); c != nil {
+		c.Unique = true
+	}
+}
+
+// parseSQLColumn parses a single column definition tokens.
+func parseSQLColumn(tokens []string, enums map[string][]string) *Column {
+	if len(tokens) < 2 {
+		return nil
+	}
+
+	col := &Column{Name: sqlIdent(tokens[0])}
+
+	typeName := strings.ToLower(sqlIdent(tokens[1]))
+	var typeArgs string
+	var isArray bool
+	var notNull, hasDefault bool
+
+	for i := 2; i < len(tokens); i++ {
+		t := tokens[i]
+
+		switch {
+		case i == 2 && strings.HasPrefix(t, "("):
+			typeArgs = t
+		case strings.HasPrefix(t, "[") && strings.HasSuffix(t, "]"):
+			isArray = true
+		case sqlKeywords(tokens[i:], "not", "null"):
+			notNull = true
+			i++
+		case sqlKeywords(tokens[i:], "primary", "key"):
+			col.Primary = true
+			i++
+		case sqlKeywords(tokens[i:], "unique"):
+			col.Unique = true
+		case sqlKeywords(tokens[i:], "default"),
+			sqlKeywords(tokens[i:], "auto_increment"),
+			sqlKeywords(tokens[i:], "autoincrement"),
+			sqlKeywords(tokens[i:], "generated"):
+			hasDefault = true
+		case sqlKeywords(tokens[i:], "references") && i+1 < len(tokens):
+			col.Ref = sqlIdent(tokens[i+1])
+			i++
+		}
+	}
+
+	if strings.HasSuffix(typeName, "[]") || strings.HasPrefix(typeName, "_") {
+		isArray = true
+	}
+	typeName = strings.TrimSuffix(typeName, "[]")
+
+	if strings.Contains(typeName, "serial") {
+		hasDefault = true
+	}
+
+	col.Kind, col.Integer, col.Values, col.Multiple = sqlKind(typeName, typeArgs, isArray, enums)
+	col.Required = notNull && !hasDefault
+
+	if col.Ref != "" {
+		col.Kind = KindRelation
+		col.Integer = false
+		col.Values = nil
+		col.Multiple = false
+	}
+
+	return col
+}
+
+// sqlKind maps the specified SQL type to one of the normalized column kinds.
+func sqlKind(typeName string, typeArgs string, isArray bool, enums map[string][]string) (kind string, integer bool, values []string, multiple bool) {
+	if isArray {
+		return KindJSON, false, nil, false
+	}
+
+	if values, ok := enums[typeName]; ok {
+		return KindSelect, false, values, false
+	}
+
+	switch typeName {
+	case "enum":
+		return KindSelect, false, sqlStringList(typeArgs), false
+	case "set":
+		return KindSelect, false, sqlStringList(typeArgs), true
+	case "bool", "boolean":
+		return KindBool, false, nil, false
+	case "tinyint", "bit":
+		if strings.TrimSpace(sqlParenContent(typeArgs)) == "1" {
+			return KindBool, false, nil, false
+		}
+		return KindNumber, true, nil, false
+	case "int", "integer", "smallint", "mediumint", "bigint", "int2", "int4", "int8",
+		"serial", "smallserial", "bigserial", "serial2", "serial4", "serial8", "year":
+		return KindNumber, true, nil, false
+	case "decimal", "numeric", "dec", "real", "float", "float4", "float8", "double", "money":
+		return KindNumber, false, nil, false
+	case "date", "datetime", "timestamp", "timestamptz", "smalldatetime", "datetime2":
+		return KindDate, false, nil, false
+	case "json", "jsonb":
+		return KindJSON, false, nil, false
+	default:
+		return KindText, false, nil, false
+	}
+}
+
+// -------------------------------------------------------------------
+
+var dollarQuoteRegex = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// splitSQLStatements splits the raw SQL into individual statements
+// by stripping the comments and ignoring the quoted semicolons.
+func splitSQLStatements(sql string) []string {
+	result := []string{}
+
+	var current strings.Builder
+
+	flush := func() {
+		stmt := strings.TrimSpace(current.String())
+		if stmt != "" {
+			result = append(result, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+
+		switch {
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-',
+			c == '#' && (i == 0 || sql[i-1] == '\n'):
+			// line comment
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end
+				current.WriteByte('\n')
+			}
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			// block comment
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 3
+				current.WriteByte(' ')
+			}
+		case c == '\'' || c == '"' || c == '`':
+			end := sqlQuoteEnd(sql, i)
+			current.WriteString(sql[i:end])
+			i = end - 1
+		case c == '$':
+			tag := dollarQuoteRegex.FindString(sql[i:])
+			if tag == "" {
+				current.WriteByte(c)
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				current.WriteString(sql[i:])
+				i = len(sql)
+			} else {
+				end = i + len(tag) + end + len(tag)
+				current.WriteString(sql[i:end])
+				i = end - 1
+			}
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	flush()
+
+	return result
+}
+
+// sqlQuoteEnd returns the index right after the closing quote of the
+// quoted string/identifier starting at sql[start].
+func sqlQuoteEnd(sql string, start int) int {
+	quote := sql[start]
+
+	for i := start + 1; i < len(sql); i++ {
+		if sql[i] == '\\' && quote == '\'' {
+			i++
+			continue
+		}
+
+		if sql[i] == quote {
+			// escaped by doubling
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+
+	return len(sql)
+}
+
+// sqlTokenize splits a single SQL statement into words, quoted strings,
+// parenthesized groups (as a single token) and commas.
+func sqlTokenize(stmt string) []string {
+	tokens := []string{}
+
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == ',':
+			tokens = append(tokens, ",")
+			i++
+		case c == '\'':
+			end := sqlQuoteEnd(stmt, i)
+			tokens = append(tokens, stmt[i:end])
+			i = end
+		case c == '(':
+			end := sqlParenEnd(stmt, i)
+			tokens = append(tokens, stmt[i:end])
+			i = end
+		default:
+			// word (including quoted identifiers parts, e.g. "public"."users")
+			start := i
+			for i < len(stmt) {
+				c = stmt[i]
+				if c == '"' || c == '`' {
+					i = sqlQuoteEnd(stmt, i)
+					continue
+				}
+				if c == '[' {
+					end := strings.IndexByte(stmt[i:], ']')
+					if end < 0 {
+						i = len(stmt)
+					} else {
+						i += end + 1
+					}
+					continue
+				}
+				if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' || c == '(' || c == '\'' {
+					break
+				}
+				i++
+			}
+			tokens = append(tokens, stmt[start:i])
+		}
+	}
+
+	return tokens
+}
+
+// sqlParenEnd returns the index right after the matching closing parenthesis.
+func sqlParenEnd(sql string, start int) int {
+	depth := 0
+
+	for i := start; i < len(sql); i++ {
+		switch sql[i] {
+		case '\'', '"', '`':
+			i = sqlQuoteEnd(sql, i) - 1
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+
+	return len(sql)
+}
+
+// splitSQLTopLevel splits s by sep ignoring the quoted and parenthesized occurrences.
+func splitSQLTopLevel(s string, sep byte) []string {
+	result := []string{}
+
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'', '"', '`':
+			i = sqlQuoteEnd(s, i) - 1
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				result = append(result, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(result, s[start:])
+}
+
+// sqlParenContent returns the content of a "(...)" token.
+func sqlParenContent(token string) string {
+	token = strings.TrimPrefix(token, "(")
+	return strings.TrimSuffix(token, ")")
+}
+
+// sqlKeywords checks whether tokens start with the specified case-insensitive keywords.
+func sqlKeywords(tokens []string, keywords ...string) bool {
+	if len(tokens) < len(keywords) {
+		return false
+	}
+
+	for i, k := range keywords {
+		if !strings.EqualFold(tokens[i], k) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sqlIdent unquotes the specified identifier and strips its schema/database prefix.
+func sqlIdent(raw string) string {
+	parts := splitSQLTopLevel(raw, '.')
+	name := strings.TrimSpace(parts[len(parts)-1])
+
+	if len(name) >= 2 {
+		switch {
+		case name[0] == '"' && name[len(name)-1] == '"',
+			name[0] == '`' && name[len(name)-1] == '`',
+			name[0] == '[' && name[len(name)-1] == ']':
+			name = name[1 : len(name)-1]
+		}
+	}
+
+	return name
+}
+
+// sqlIdentList parses a "(a, b, ...)" identifiers list token.
+func sqlIdentList(token string) []string {
+	result := []string{}
+
+	for _, part := range splitSQLTopLevel(sqlParenContent(token), ',') {
+		words := sqlTokenize(part)
+		if len(words) == 0 || strings.HasPrefix(words[0], "(") {
+			continue
+		}
+		// ignore index expressions (e.g. lower(email))
+		if len(words) > 1 && strings.HasPrefix(words[1], "(") {
+			continue
+		}
+		result = append(result, sqlIdent(words[0]))
+	}
+
+	return result
+}
+
+// sqlStringList parses a "('a', 'b', ...)" string literals list token.
+func sqlStringList(token string) []string {
+	result := []string{}
+
+	for _, part := range splitSQLTopLevel(sqlParenContent(token), ',') {
+		part = strings.TrimSpace(part)
+		if len(part) < 2 || part[0] != '\'' {
+			continue
+		}
+
+		v := part[1 : len(part)-1]
+		v = strings.ReplaceAll(v, "''", "'")
+		v = strings.ReplaceAll(v, `\'`, "'")
+
+		result = append(result, v)
+	}
+
+	return result
+}
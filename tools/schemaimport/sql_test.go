@@ -0,0 +1,135 @@
+package schemaimport_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/schemaimport"
+)
+
+func TestParseSQLPostgres(t *testing.T) {
+	t.Parallel()
+
+	dump := `
+		-- pg_dump output
+		SET statement_timeout = 0;
+
+		CREATE TYPE public.role AS ENUM ('admin', 'user''s');
+
+		CREATE FUNCTION public.touch() RETURNS trigger AS $$
+		BEGIN
+			NEW.updated_at = now(); -- with semicolons
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		CREATE TABLE public.users (
+			id integer NOT NULL,
+			email character varying(255) NOT NULL,
+			role public.role NOT NULL,
+			is_active boolean DEFAULT true NOT NULL,
+			tags text[],
+			meta jsonb,
+			created_at timestamp with time zone DEFAULT now() NOT NULL
+		);
+
+		CREATE TABLE "public"."posts" (
+			"id" bigserial PRIMARY KEY,
+			"title" text NOT NULL,
+			"rating" numeric(5,2),
+			"author_id" integer NOT NULL,
+			"editor_id" integer REFERENCES public.users(id),
+			CONSTRAINT posts_title_key UNIQUE (title)
+		);
+
+		ALTER TABLE ONLY public.users
+			ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+		ALTER TABLE ONLY public.posts
+			ADD CONSTRAINT posts_author_fkey FOREIGN KEY (author_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+		CREATE UNIQUE INDEX users_email_idx ON public.users USING btree (email);
+		CREATE UNIQUE INDEX users_lower_email_idx ON public.users USING btree (lower(email));
+	`
+
+	tables, err := schemaimport.ParseSQL([]byte(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := json.Marshal(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"Name":"users","Columns":[` +
+		`{"Name":"id","Kind":"number","Primary":true,"Required":false,"Unique":false,"Integer":true,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"email","Kind":"text","Primary":false,"Required":true,"Unique":true,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"role","Kind":"select","Primary":false,"Required":true,"Unique":false,"Integer":false,"Multiple":false,"Values":["admin","user's"],"Ref":""},` +
+		`{"Name":"is_active","Kind":"bool","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"tags","Kind":"json","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"meta","Kind":"json","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"created_at","Kind":"date","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""}]},` +
+		`{"Name":"posts","Columns":[` +
+		`{"Name":"id","Kind":"number","Primary":true,"Required":false,"Unique":false,"Integer":true,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"title","Kind":"text","Primary":false,"Required":true,"Unique":true,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"rating","Kind":"number","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"author_id","Kind":"relation","Primary":false,"Required":true,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":"users"},` +
+		`{"Name":"editor_id","Kind":"relation","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":"users"}]}]`
+
+	if str := string(raw); str != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, str)
+	}
+}
+
+func TestParseSQLMySQL(t *testing.T) {
+	t.Parallel()
+
+	dump := "/*!40101 SET NAMES utf8mb4 */;\n" +
+		"# mysqldump comment\n" +
+		"CREATE TABLE IF NOT EXISTS `orders` (\n" +
+		"  `id` int unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `status` enum('new','paid') NOT NULL DEFAULT 'new',\n" +
+		"  `flags` set('a','b','c') DEFAULT NULL,\n" +
+		"  `paid` tinyint(1) NOT NULL,\n" +
+		"  `qty` tinyint NOT NULL,\n" +
+		"  `customer_id` int NOT NULL,\n" +
+		"  `note` varchar(100) COMMENT 'not null',\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `orders_note` (`note`),\n" +
+		"  KEY `orders_customer` (`customer_id`),\n" +
+		"  CONSTRAINT `fk_customer` FOREIGN KEY (`customer_id`) REFERENCES `customers` (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;"
+
+	tables, err := schemaimport.ParseSQL([]byte(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := json.Marshal(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"Name":"orders","Columns":[` +
+		`{"Name":"id","Kind":"number","Primary":true,"Required":false,"Unique":false,"Integer":true,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"status","Kind":"select","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":false,"Values":["new","paid"],"Ref":""},` +
+		`{"Name":"flags","Kind":"select","Primary":false,"Required":false,"Unique":false,"Integer":false,"Multiple":true,"Values":["a","b","c"],"Ref":""},` +
+		`{"Name":"paid","Kind":"bool","Primary":false,"Required":true,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"qty","Kind":"number","Primary":false,"Required":true,"Unique":false,"Integer":true,"Multiple":false,"Values":null,"Ref":""},` +
+		`{"Name":"customer_id","Kind":"relation","Primary":false,"Required":true,"Unique":false,"Integer":false,"Multiple":false,"Values":null,"Ref":"customers"},` +
+		`{"Name":"note","Kind":"text","Primary":false,"Required":false,"Unique":true,"Integer":false,"Multiple":false,"Values":null,"Ref":""}]}]`
+
+	if str := string(raw); str != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, str)
+	}
+}
+
+func TestParseSQLNoTables(t *testing.T) {
+	t.Parallel()
+
+	_, err := schemaimport.ParseSQL([]byte("SELECT 1; CREATE INDEX idx ON test (a);"))
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
@@ -26,9 +26,14 @@ import (
 //	expr, err := filter.BuildExpr(resolver, dbx.Params{"min": 100, "max": 200})
 type FilterData string
 
+type parsedFilter struct {
+	calls map[string]*functionCall
+	data  []fexpr.ExprGroup
+}
+
 // parsedFilterData holds a cache with previously parsed filter data expressions
 // (initialized with some preallocated empty data map)
-var parsedFilterData = store.New(make(map[string][]fexpr.ExprGroup, 50))
+var parsedFilterData = store.New(make(map[string]*parsedFilter, 50))
 
 // BuildExpr parses the current filter data and returns a new db WHERE expression.
 //
@@ -81,8 +86,13 @@ func (f FilterData) BuildExprWithLimit(
 
 	cacheKey := raw + "/" + strconv.Itoa(maxExpressions)
 
-	if data, ok := parsedFilterData.GetOk(cacheKey); ok {
-		return buildParsedFilterExpr(data, fieldResolver, &maxExpressions)
+	if parsed, ok := parsedFilterData.GetOk(cacheKey); ok {
+		return buildParsedFilterExpr(parsed.data, wrapFunctionsResolver(fieldResolver, parsed.calls), &maxExpressions)
+	}
+
+	raw, calls, err := extractFunctionCalls(raw)
+	if err != nil {
+		return nil, err
 	}
 
 	data, err := fexpr.Parse(raw)
@@ -99,9 +109,9 @@ func (f FilterData) BuildExprWithLimit(
 
 	// store in cache
 	// (the limit size is arbitrary and it is there to prevent the cache growing too big)
-	parsedFilterData.SetIfLessThanLimit(cacheKey, data, 500)
+	parsedFilterData.SetIfLessThanLimit(cacheKey, &parsedFilter{data: data, calls: calls}, 500)
 
-	return buildParsedFilterExpr(data, fieldResolver, &maxExpressions)
+	return buildParsedFilterExpr(data, wrapFunctionsResolver(fieldResolver, calls), &maxExpressions)
 }
 
 func buildParsedFilterExpr(data []fexpr.ExprGroup, fieldResolver FieldResolver, maxExpressions *int) (dbx.Expression, error) {
@@ -0,0 +1,297 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+)
+
+// FunctionResolver is an optional [FieldResolver] interface for
+// resolving function call operands, eg. "someFunc(title, 'abc') = true".
+type FunctionResolver interface {
+	// ResolveFunction resolves a single function call with its already resolved arguments.
+	ResolveFunction(name string, args ...*ResolverResult) (*ResolverResult, error)
+}
+
+// functionCall holds a single extracted filter function call.
+type functionCall struct {
+	name string
+	args []fexpr.Token
+}
+
+// functionPlaceholderPrefix is the prefix of the identifiers used to
+// replace the function calls in the raw filter string.
+const functionPlaceholderPrefix = "@__fn"
+
+// extractFunctionCalls replaces the function calls in the raw filter
+// string with placeholder identifiers so that the result could be
+// parsed with the fexpr grammar.
+//
+// Function calls are identifiers immediately followed by "(", eg. "lower(title)".
+// Nested function calls are also supported, eg. "lower(trim(title))".
+func extractFunctionCalls(raw string) (string, map[string]*functionCall, error) {
+	if !strings.Contains(raw, "(") {
+		return raw, nil, nil // fast path
+	}
+
+	calls := map[string]*functionCall{}
+
+	result, err := replaceFunctionCalls(raw, calls)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return result, calls, nil
+}
+
+func replaceFunctionCalls(raw string, calls map[string]*functionCall) (string, error) {
+	rs := []rune(raw)
+
+	out := make([]rune, 0, len(rs))
+
+	identStart := -1
+
+	for i := 0; i < len(rs); i++ {
+		ch := rs[i]
+
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := skipQuoted(rs, i)
+			out = append(out, rs[i:end]...)
+			i = end - 1
+			identStart = -1
+		case ch == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			end := i
+			for end < len(rs) && rs[end] != '\n' {
+				end++
+			}
+			out = append(out, rs[i:end]...)
+			i = end - 1
+			identStart = -1
+		case isFunctionIdentifierRune(ch):
+			if identStart == -1 {
+				identStart = i
+			}
+			out = append(out, ch)
+		case ch == '(' && identStart != -1:
+			name := string(rs[identStart:i])
+
+			end, err := findClosingParen(rs, i)
+			if err != nil {
+				return "", err
+			}
+
+			call, err := parseFunctionCall(name, string(rs[i+1:end]), calls)
+			if err != nil {
+				return "", err
+			}
+
+			placeholder := functionPlaceholderPrefix + strconv.Itoa(len(calls))
+			calls[placeholder] = call
+
+			// replace the already written function name with the placeholder
+			out = append(out[:len(out)-(i-identStart)], []rune(placeholder)...)
+
+			i = end
+			identStart = -1
+		default:
+			identStart = -1
+			out = append(out, ch)
+		}
+	}
+
+	return string(out), nil
+}
+
+func parseFunctionCall(name string, rawArgs string, calls map[string]*functionCall) (*functionCall, error) {
+	args, err := splitFunctionArgs(rawArgs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s() arguments: %w", name, err)
+	}
+
+	call := &functionCall{name: name, args: make([]fexpr.Token, 0, len(args))}
+
+	for _, arg := range args {
+		// resolve nested function calls
+		arg, err = replaceFunctionCalls(arg, calls)
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := fexpr.NewScanner(strings.NewReader(arg))
+
+		token, err := scanner.Scan()
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s() argument %q: %w", name, arg, err)
+		}
+
+		switch token.Type {
+		case fexpr.TokenIdentifier, fexpr.TokenText, fexpr.TokenNumber:
+		default:
+			return nil, fmt.Errorf("unsupported %s() argument %q", name, arg)
+		}
+
+		// each argument must be a single token
+		if next, err := scanner.Scan(); err != nil || next.Type != fexpr.TokenEOF {
+			return nil, fmt.Errorf("invalid %s() argument %q", name, arg)
+		}
+
+		call.args = append(call.args, token)
+	}
+
+	return call, nil
+}
+
+func isFunctionIdentifierRune(ch rune) bool {
+	return (ch >= 'a' && ch <= 'z') ||
+		(ch >= 'A' && ch <= 'Z') ||
+		(ch >= '0' && ch <= '9') ||
+		ch == '_' || ch == '@' || ch == '#' || ch == '.' || ch == ':'
+}
+
+// skipQuoted returns the index right after the closing quote of the
+// quoted text starting at rs[start].
+func skipQuoted(rs []rune, start int) int {
+	quote := rs[start]
+
+	for i := start + 1; i < len(rs); i++ {
+		if rs[i] == quote && rs[i-1] != '\\' {
+			return i + 1
+		}
+	}
+
+	return len(rs)
+}
+
+// findClosingParen returns the index of the closing parenthesis
+// that matches the one at rs[start].
+func findClosingParen(rs []rune, start int) (int, error) {
+	depth := 0
+
+	for i := start; i < len(rs); i++ {
+		switch rs[i] {
+		case '\'', '"', '`':
+			i = skipQuoted(rs, i) - 1
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+
+	return -1, errors.New("unbalanced function call parenthesis")
+}
+
+// splitFunctionArgs splits the raw function arguments string by the top level commas.
+func splitFunctionArgs(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	args := splitTopLevelCommas(raw)
+
+	for _, arg := range args {
+		if arg == "" {
+			return nil, errors.New("empty function argument")
+		}
+	}
+
+	return args, nil
+}
+
+// splitTopLevelCommas splits the raw string by the commas that are
+// not quoted or part of a parenthesized group and trims the result parts.
+func splitTopLevelCommas(raw string) []string {
+	rs := []rune(raw)
+
+	var parts []string
+	var depth int
+	var last int
+
+	for i := 0; i < len(rs); i++ {
+		switch rs[i] {
+		case '\'', '"', '`':
+			i = skipQuoted(rs, i) - 1
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(string(rs[last:i])))
+				last = i + 1
+			}
+		}
+	}
+
+	return append(parts, strings.TrimSpace(string(rs[last:])))
+}
+
+// -------------------------------------------------------------------
+
+var _ FieldResolver = (*functionsFieldResolver)(nil)
+
+// functionsFieldResolver wraps a FieldResolver and resolves the
+// extracted function calls placeholders.
+type functionsFieldResolver struct {
+	FieldResolver
+	calls map[string]*functionCall
+}
+
+func wrapFunctionsResolver(fieldResolver FieldResolver, calls map[string]*functionCall) FieldResolver {
+	if len(calls) == 0 {
+		return fieldResolver
+	}
+
+	return &functionsFieldResolver{FieldResolver: fieldResolver, calls: calls}
+}
+
+func (r *functionsFieldResolver) Resolve(field string) (*ResolverResult, error) {
+	call, ok := r.calls[field]
+	if !ok {
+		return r.FieldResolver.Resolve(field)
+	}
+
+	fr, ok := r.FieldResolver.(FunctionResolver)
+	if !ok {
+		return nil, fmt.Errorf("unsupported function %s()", call.name)
+	}
+
+	args := make([]*ResolverResult, len(call.args))
+	for i, token := range call.args {
+		arg, err := resolveToken(token, r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s() argument %q: %w", call.name, token.Literal, err)
+		}
+		args[i] = arg
+	}
+
+	result, err := fr.ResolveFunction(call.name, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil || result.Identifier == "" {
+		return nil, fmt.Errorf("unresolvable function %s()", call.name)
+	}
+
+	return result, nil
+}
+
+// MergeParams returns a new dbx.Params with the merged params of all provided resolver results
+// (usually used by the [FunctionResolver] implementations to combine the arguments params).
+func MergeParams(results ...*ResolverResult) dbx.Params {
+	params := make([]dbx.Params, 0, len(results))
+	for _, r := range results {
+		params = append(params, r.Params)
+	}
+
+	return mergeParams(params...)
+}
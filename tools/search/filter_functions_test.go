@@ -0,0 +1,162 @@
+package search_test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+type testFunctionsResolver struct {
+	*search.SimpleFieldResolver
+}
+
+func (r *testFunctionsResolver) ResolveFunction(name string, args ...*search.ResolverResult) (*search.ResolverResult, error) {
+	switch name {
+	case "lower":
+		if len(args) != 1 {
+			return nil, errors.New("lower() expects 1 argument")
+		}
+		return &search.ResolverResult{
+			Identifier: "LOWER(" + args[0].Identifier + ")",
+			Params:     args[0].Params,
+		}, nil
+	case "@test.concat":
+		identifiers := make([]string, len(args))
+		for i, arg := range args {
+			identifiers[i] = arg.Identifier
+		}
+		return &search.ResolverResult{
+			Identifier: "(" + strings.Join(identifiers, " || ") + ")",
+			Params:     search.MergeParams(args...),
+		}, nil
+	case "empty":
+		return &search.ResolverResult{}, nil
+	}
+
+	return nil, errors.New("unknown function")
+}
+
+func TestFilterDataBuildExprWithFunctions(t *testing.T) {
+	resolver := &testFunctionsResolver{search.NewSimpleFieldResolver("test1", "test2")}
+
+	scenarios := []struct {
+		name          string
+		filterData    search.FilterData
+		expectError   bool
+		expectPattern string
+	}{
+		{
+			"unknown function",
+			"missing(test1) = 1",
+			true,
+			"",
+		},
+		{
+			"unknown argument field",
+			"lower(unknown) = 1",
+			true,
+			"",
+		},
+		{
+			"empty function result",
+			"empty() = 1",
+			true,
+			"",
+		},
+		{
+			"unbalanced parenthesis",
+			"lower(test1 = 1",
+			true,
+			"",
+		},
+		{
+			"empty argument",
+			"@test.concat(test1,,test2) = 1",
+			true,
+			"",
+		},
+		{
+			"multi-token argument",
+			"lower(test1 test2) = 1",
+			true,
+			"",
+		},
+		{
+			"expression argument",
+			"lower(test1 = 1) = 1",
+			true,
+			"",
+		},
+		{
+			"simple function call",
+			"lower(test1) = 'abc'",
+			false,
+			"LOWER([[test1]]) = {:TEST}",
+		},
+		{
+			"function call in group and on both sides",
+			"(lower(test1) = lower(test2) || test1 > 1)",
+			false,
+			"((COALESCE(LOWER([[test1]]), '') = COALESCE(LOWER([[test2]]), '') OR [[test1]] > {:TEST}))",
+		},
+		{
+			"nested function calls with mixed text arguments",
+			`@test.concat(lower(test1), "a,b(", 'c)', 123) != ""`,
+			false,
+			"(LOWER([[test1]]) || {:TEST} || {:TEST} || {:TEST}) IS NOT ''",
+		},
+		{
+			"function like text and comments are ignored",
+			"test1 = 'lower(test1)' // lower(test2)",
+			false,
+			"[[test1]] = {:TEST}",
+		},
+		{
+			"group after identifier with whitespace is not a function",
+			"test1 = 1 && (test2 = 2)",
+			false,
+			"([[test1]] = {:TEST} AND [[test2]] = {:TEST})",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			expr, err := s.filterData.BuildExpr(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			rawSql := expr.Build(&dbx.DB{}, dbx.Params{})
+
+			expectPattern := strings.ReplaceAll(
+				"^"+regexp.QuoteMeta(s.expectPattern)+"$",
+				"TEST",
+				`\w+`,
+			)
+
+			pattern := regexp.MustCompile(expectPattern)
+			if !pattern.MatchString(rawSql) {
+				t.Fatalf("Pattern %v don't match with expression: \n%v", expectPattern, rawSql)
+			}
+		})
+	}
+}
+
+func TestFilterDataBuildExprWithFunctionsNonFunctionResolver(t *testing.T) {
+	resolver := search.NewSimpleFieldResolver("test1")
+
+	_, err := search.FilterData("lower(test1) = 1").BuildExpr(resolver)
+	if err == nil {
+		t.Fatal("Expected unsupported function error")
+	}
+}
@@ -223,90 +223,99 @@ func (s *Provider) Parse(urlQuery string) error {
 	return nil
 }
 
-// Exec executes the search provider and fills/scans
-// the provided `items` slice with the found models.
-func (s *Provider) Exec(items any) (*Result, error) {
+// BuildQueries builds and returns the paginated models query and
+// the total count query of the search provider
+// (aka. the queries that are executed by [Provider.Exec]).
+func (s *Provider) BuildQueries() (modelsQuery *dbx.SelectQuery, countQuery *dbx.SelectQuery, err error) {
 	if s.query == nil {
-		return nil, ErrEmptyQuery
+		return nil, nil, ErrEmptyQuery
 	}
 
 	// shallow clone the provider's query
-	modelsQuery := *s.query
+	mq := *s.query
 
 	// build filters
 	for _, f := range s.filter {
 		if len(f) > MaxFilterLength {
-			return nil, ErrFilterLengthLimit
+			return nil, nil, ErrFilterLengthLimit
 		}
 		expr, err := f.BuildExprWithLimit(s.fieldResolver, s.maxFilterExprLimit)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if expr != nil {
-			modelsQuery.AndWhere(expr)
+			mq.AndWhere(expr)
 		}
 	}
 
 	// apply sorting
 	if len(s.sort) > s.maxSortExprLimit {
-		return nil, ErrSortExprLimit
+		return nil, nil, ErrSortExprLimit
 	}
 	for _, sortField := range s.sort {
 		if len(sortField.Name) > MaxSortFieldLength {
-			return nil, ErrSortFieldLengthLimit
+			return nil, nil, ErrSortFieldLengthLimit
 		}
-		expr, err := sortField.BuildExpr(s.fieldResolver)
+		expr, params, err := sortField.BuildExprWithParams(s.fieldResolver)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		if len(params) > 0 {
+			mq.AndBind(params)
 		}
 		if expr != "" {
 			// ensure that _rowid_ expressions are always prefixed with the first FROM table
 			if sortField.Name == rowidSortKey && !strings.Contains(expr, ".") {
-				queryInfo := modelsQuery.Info()
+				queryInfo := mq.Info()
 				if len(queryInfo.From) > 0 {
 					expr = "[[" + inflector.Columnify(queryInfo.From[0]) + "]]." + expr
 				}
 			}
 
-			modelsQuery.AndOrderBy(expr)
+			mq.AndOrderBy(expr)
 		}
 	}
 
 	// apply field resolver query modifications (if any)
-	if err := s.fieldResolver.UpdateQuery(&modelsQuery); err != nil {
-		return nil, err
+	if err := s.fieldResolver.UpdateQuery(&mq); err != nil {
+		return nil, nil, err
 	}
 
-	// normalize page
-	if s.page <= 0 {
-		s.page = 1
+	s.page, s.perPage = normalizePagination(s.page, s.perPage)
+
+	// prepare a count query from the base one
+	cq := mq // shallow clone
+	queryInfo := cq.Info()
+	countCol := s.countCol
+	if len(queryInfo.From) > 0 {
+		countCol = queryInfo.From[0] + "." + countCol
 	}
+	// note: cq is shallow cloned and slice/map in-place modifications should be avoided
+	cq.Distinct(false).
+		Select("COUNT(DISTINCT [[" + countCol + "]])").
+		OrderBy( /* reset */ )
 
-	// normalize perPage
-	if s.perPage <= 0 {
-		s.perPage = DefaultPerPage
-	} else if s.perPage > MaxPerPage {
-		s.perPage = MaxPerPage
+	// apply pagination to the models query
+	mq.Limit(int64(s.perPage))
+	mq.Offset(int64(s.perPage * (s.page - 1)))
+
+	return &mq, &cq, nil
+}
+
+// Exec executes the search provider and fills/scans
+// the provided `items` slice with the found models.
+func (s *Provider) Exec(items any) (*Result, error) {
+	modelsQuery, countQuery, err := s.BuildQueries()
+	if err != nil {
+		return nil, err
 	}
 
 	// negative value to differentiate from the zero default
 	totalCount := -1
 	totalPages := -1
 
-	// prepare a count query from the base one
-	countQuery := modelsQuery // shallow clone
 	countExec := func() error {
-		queryInfo := countQuery.Info()
-		countCol := s.countCol
-		if len(queryInfo.From) > 0 {
-			countCol = queryInfo.From[0] + "." + countCol
-		}
-
-		// note: countQuery is shallow cloned and slice/map in-place modifications should be avoided
-		err := countQuery.Distinct(false).
-			Select("COUNT(DISTINCT [[" + countCol + "]])").
-			OrderBy( /* reset */ ).
-			Row(&totalCount)
+		err := countQuery.Row(&totalCount)
 		if err != nil {
 			return err
 		}
@@ -316,11 +325,7 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		return nil
 	}
 
-	// apply pagination to the original query and fetch the models
 	modelsExec := func() error {
-		modelsQuery.Limit(int64(s.perPage))
-		modelsQuery.Offset(int64(s.perPage * (s.page - 1)))
-
 		return modelsQuery.All(items)
 	}
 
@@ -268,6 +268,44 @@ func TestProviderExecEmptyQuery(t *testing.T) {
 	}
 }
 
+func TestProviderBuildQueries(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	p := NewProvider(&testFieldResolver{}).
+		Query(testDB.Select("*").From("test")).
+		Page(2).
+		PerPage(10).
+		Filter([]FilterData{"test1 > 1"}).
+		Sort([]SortField{{"test2", SortDesc}})
+
+	modelsQuery, countQuery, err := p.BuildQueries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modelsSQL := modelsQuery.Build().SQL()
+	if !strings.HasPrefix(modelsSQL, "SELECT * FROM `test` WHERE ") || !strings.HasSuffix(modelsSQL, "ORDER BY `test2` DESC LIMIT 10 OFFSET 10") {
+		t.Fatalf("Expected filtered, sorted and paginated models query, got\n%v", modelsSQL)
+	}
+
+	countSQL := countQuery.Build().SQL()
+	if !strings.HasPrefix(countSQL, "SELECT COUNT(DISTINCT [[test.id]]) FROM `test` WHERE") {
+		t.Fatalf("Expected count query, got\n%v", countSQL)
+	}
+	if strings.Contains(countSQL, "ORDER BY") || strings.Contains(countSQL, "LIMIT") {
+		t.Fatalf("Expected the count query to not be sorted or paginated, got\n%v", countSQL)
+	}
+
+	// no queries should be executed
+	if len(testDB.CalledQueries) != 0 {
+		t.Fatalf("Expected no executed queries, got %v", testDB.CalledQueries)
+	}
+}
+
 func TestProviderExecNonEmptyQuery(t *testing.T) {
 	testDB, err := createTestDB()
 	if err != nil {
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+)
+
+// NewResult creates a new paginated [Result] envelope
+// (the same as the one returned by the [Provider]) for the provided items.
+//
+// page and perPage are normalized the same way as in the [Provider].
+//
+// Negative totalItems could be used to indicate that the total
+// counter was skipped (the result TotalPages will be also -1).
+func NewResult(items any, page int, perPage int, totalItems int) *Result {
+	page, perPage = normalizePagination(page, perPage)
+
+	totalPages := -1
+	if totalItems >= 0 {
+		totalPages = int(math.Ceil(float64(totalItems) / float64(perPage)))
+	} else {
+		totalItems = -1
+	}
+
+	return &Result{
+		Items:      items,
+		Page:       page,
+		PerPage:    perPage,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+	}
+}
+
+// ParsePagination extracts and normalizes the "page" and "perPage"
+// search query parameters from the provided url query values.
+//
+// Example:
+//
+//	page, perPage := search.ParsePagination(e.Request.URL.Query())
+//
+//	items, total := loadMyItems(page, perPage)
+//
+//	return e.JSON(http.StatusOK, search.NewResult(items, page, perPage, total))
+func ParsePagination(query url.Values) (page int, perPage int) {
+	page, _ = strconv.Atoi(query.Get(PageQueryParam))
+	perPage, _ = strconv.Atoi(query.Get(PerPageQueryParam))
+
+	return normalizePagination(page, perPage)
+}
+
+func normalizePagination(page int, perPage int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = DefaultPerPage
+	} else if perPage > MaxPerPage {
+		perPage = MaxPerPage
+	}
+
+	return page, perPage
+}
@@ -0,0 +1,67 @@
+package search_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+func TestNewResult(t *testing.T) {
+	scenarios := []struct {
+		page       int
+		perPage    int
+		totalItems int
+		expected   string
+	}{
+		{0, 0, 0, `{"items":["a"],"page":1,"perPage":30,"totalItems":0,"totalPages":0}`},
+		{2, 10, 21, `{"items":["a"],"page":2,"perPage":10,"totalItems":21,"totalPages":3}`},
+		{1, 9999, 5, `{"items":["a"],"page":1,"perPage":1000,"totalItems":5,"totalPages":1}`},
+		{1, 10, -10, `{"items":["a"],"page":1,"perPage":10,"totalItems":-1,"totalPages":-1}`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.expected, func(t *testing.T) {
+			result := search.NewResult([]string{"a"}, s.page, s.perPage, s.totalItems)
+
+			raw, err := json.Marshal(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if str := string(raw); str != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, str)
+			}
+		})
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	scenarios := []struct {
+		query           string
+		expectedPage    int
+		expectedPerPage int
+	}{
+		{"", 1, 30},
+		{"page=abc&perPage=abc", 1, 30},
+		{"page=-1&perPage=-1", 1, 30},
+		{"page=3&perPage=5", 3, 5},
+		{"page=3&perPage=1001", 3, 1000},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.query, func(t *testing.T) {
+			query, err := url.ParseQuery(s.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			page, perPage := search.ParsePagination(query)
+
+			if page != s.expectedPage || perPage != s.expectedPerPage {
+				t.Fatalf("Expected %d/%d, got %d/%d", s.expectedPage, s.expectedPerPage, page, perPage)
+			}
+		})
+	}
+}
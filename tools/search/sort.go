@@ -3,6 +3,8 @@ package search
 import (
 	"fmt"
 	"strings"
+
+	"github.com/pocketbase/dbx"
 )
 
 const (
@@ -23,25 +25,74 @@ type SortField struct {
 }
 
 // BuildExpr resolves the sort field into a valid db sort expression.
+//
+// Sort function calls that require bound params (e.g. with text arguments)
+// are not supported by BuildExpr and [SortField.BuildExprWithParams] should be used instead.
 func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
+	expr, params, err := s.BuildExprWithParams(fieldResolver)
+	if err != nil {
+		return "", err
+	}
+
+	if len(params) > 0 {
+		return "", fmt.Errorf("invalid sort field %q", s.Name)
+	}
+
+	return expr, nil
+}
+
+// BuildExprWithParams resolves the sort field into a valid db sort expression
+// and returns it together with its params that need to be bound to the query
+// (the params are returned only for function call sort fields, eg. "similarTo(embedding, @request.query.v)").
+func (s *SortField) BuildExprWithParams(fieldResolver FieldResolver) (string, dbx.Params, error) {
 	// special case for random sort
 	if s.Name == randomSortKey {
-		return "RANDOM()", nil
+		return "RANDOM()", nil, nil
 	}
 
 	// special case for the builtin SQLite rowid column
 	if s.Name == rowidSortKey {
-		return fmt.Sprintf("[[_rowid_]] %s", s.Direction), nil
+		return fmt.Sprintf("[[_rowid_]] %s", s.Direction), nil, nil
+	}
+
+	// function call
+	if strings.Contains(s.Name, "(") {
+		return s.buildFunctionExpr(fieldResolver)
 	}
 
 	result, err := fieldResolver.Resolve(s.Name)
 
 	// invalidate empty fields and non-column identifiers
 	if err != nil || len(result.Params) > 0 || result.Identifier == "" || strings.ToLower(result.Identifier) == "null" {
-		return "", fmt.Errorf("invalid sort field %q", s.Name)
+		return "", nil, fmt.Errorf("invalid sort field %q", s.Name)
+	}
+
+	return fmt.Sprintf("%s %s", result.Identifier, s.Direction), nil, nil
+}
+
+func (s *SortField) buildFunctionExpr(fieldResolver FieldResolver) (string, dbx.Params, error) {
+	raw, calls, err := extractFunctionCalls(s.Name)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid sort field %q: %w", s.Name, err)
+	}
+
+	// the sort field must be a single function call
+	raw = strings.TrimSpace(raw)
+	if _, ok := calls[raw]; !ok {
+		return "", nil, fmt.Errorf("invalid sort field %q", s.Name)
+	}
+
+	result, err := wrapFunctionsResolver(fieldResolver, calls).Resolve(raw)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid sort field %q: %w", s.Name, err)
+	}
+
+	// multi-match function results are not supported as sort expressions
+	if result.MultiMatchSubQuery != nil || result.AfterBuild != nil {
+		return "", nil, fmt.Errorf("invalid sort field %q", s.Name)
 	}
 
-	return fmt.Sprintf("%s %s", result.Identifier, s.Direction), nil
+	return fmt.Sprintf("%s %s", result.Identifier, s.Direction), result.Params, nil
 }
 
 // ParseSortFromString parses the provided string expression
@@ -51,11 +102,10 @@ func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
 //
 //	fields := search.ParseSortFromString("-name,+created")
 func ParseSortFromString(str string) (fields []SortField) {
-	data := strings.Split(str, ",")
+	// note: the commas inside function calls are not treated as separators
+	data := splitTopLevelCommas(str)
 
 	for _, field := range data {
-		// trim whitespaces
-		field = strings.TrimSpace(field)
 		if strings.HasPrefix(field, "-") {
 			fields = append(fields, SortField{strings.TrimPrefix(field, "-"), SortDesc})
 		} else {
@@ -3,6 +3,7 @@ package search_test
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/tools/search"
@@ -32,6 +33,8 @@ func TestSortFieldBuildExpr(t *testing.T) {
 		{search.SortField{"@random", search.SortDesc}, false, "RANDOM()"},
 		// special _rowid_ field
 		{search.SortField{"@rowid", search.SortDesc}, false, "[[_rowid_]] DESC"},
+		// function call without a function resolver
+		{search.SortField{"lower(test1)", search.SortAsc}, true, ""},
 	}
 
 	for _, s := range scenarios {
@@ -50,6 +53,59 @@ func TestSortFieldBuildExpr(t *testing.T) {
 	}
 }
 
+func TestSortFieldBuildExprWithParams(t *testing.T) {
+	resolver := &testFunctionsResolver{search.NewSimpleFieldResolver("test1", "test2")}
+
+	scenarios := []struct {
+		sortField        search.SortField
+		expectError      bool
+		expectExpression string
+		expectParams     int
+	}{
+		{search.SortField{"test1", search.SortDesc}, false, "[[test1]] DESC", 0},
+		{search.SortField{"@random", search.SortDesc}, false, "RANDOM()", 0},
+		{search.SortField{"lower(test1)", search.SortAsc}, false, "LOWER([[test1]]) ASC", 0},
+		{search.SortField{"lower(test1", search.SortAsc}, true, "", 0},
+		{search.SortField{"lower(unknown)", search.SortAsc}, true, "", 0},
+		{search.SortField{"missing(test1)", search.SortAsc}, true, "", 0},
+		{search.SortField{"empty()", search.SortAsc}, true, "", 0},
+		{search.SortField{"lower(test1) + 1", search.SortAsc}, true, "", 0},
+		{search.SortField{"lower(test1) lower(test2)", search.SortAsc}, true, "", 0},
+		{search.SortField{"@test.concat(test1, 'abc')", search.SortDesc}, false, "([[test1]] || {:TEST}) DESC", 1},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.sortField.Name, func(t *testing.T) {
+			result, params, err := s.sortField.BuildExprWithParams(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if len(params) != s.expectParams {
+				t.Fatalf("Expected %d params, got %v", s.expectParams, params)
+			}
+
+			// replace the random generated param placeholders
+			for k := range params {
+				result = strings.ReplaceAll(result, "{:"+k+"}", "{:TEST}")
+			}
+
+			if result != s.expectExpression {
+				t.Fatalf("Expected expression %v, got %v", s.expectExpression, result)
+			}
+
+			// the params bound sort expressions are not supported by BuildExpr
+			if !hasErr && len(params) > 0 {
+				if _, err := s.sortField.BuildExpr(resolver); err == nil {
+					t.Fatal("Expected BuildExpr error")
+				}
+			}
+		})
+	}
+}
+
 func TestParseSortFromString(t *testing.T) {
 	scenarios := []struct {
 		value    string
@@ -62,6 +118,7 @@ func TestParseSortFromString(t *testing.T) {
 		{"test1,-test2,+test3", `[{"name":"test1","direction":"ASC"},{"name":"test2","direction":"DESC"},{"name":"test3","direction":"ASC"}]`},
 		{"@random,-test", `[{"name":"@random","direction":"ASC"},{"name":"test","direction":"DESC"}]`},
 		{"-@rowid,-test", `[{"name":"@rowid","direction":"DESC"},{"name":"test","direction":"DESC"}]`},
+		{"-similarTo(a, '[1,2]'),lower(b)", `[{"name":"similarTo(a, '[1,2]')","direction":"DESC"},{"name":"lower(b)","direction":"ASC"}]`},
 	}
 
 	for _, s := range scenarios {
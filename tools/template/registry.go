@@ -123,19 +123,26 @@ func (r *Registry) LoadFS(fsys fs.FS, globPatterns ...string) *Renderer {
 	found := r.cache.Get(key)
 
 	if found == nil {
-		// find the first file to use as template name (it is required when specifying Funcs)
-		var firstFilename string
-		if len(globPatterns) > 0 {
-			list, _ := fs.Glob(fsys, globPatterns[0])
-			if len(list) > 0 {
-				firstFilename = filepath.Base(list[0])
-			}
-		}
-
-		tpl, err := template.New(firstFilename).Funcs(r.funcs).ParseFS(fsys, globPatterns...)
-		found = &Renderer{template: tpl, parseError: err}
+		found = r.parseFS(fsys, globPatterns...)
 		r.cache.Set(key, found)
 	}
 
 	return found
 }
+
+// parseFS parses (without caching) the specified fs and globPatterns
+// pair as single template and returns a ready to use Renderer instance.
+func (r *Registry) parseFS(fsys fs.FS, globPatterns ...string) *Renderer {
+	// find the first file to use as template name (it is required when specifying Funcs)
+	var firstFilename string
+	if len(globPatterns) > 0 {
+		list, _ := fs.Glob(fsys, globPatterns[0])
+		if len(list) > 0 {
+			firstFilename = filepath.Base(list[0])
+		}
+	}
+
+	tpl, err := template.New(firstFilename).Funcs(r.funcs).ParseFS(fsys, globPatterns...)
+
+	return &Renderer{template: tpl, parseError: err}
+}
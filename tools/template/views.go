@@ -0,0 +1,131 @@
+package template
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+// ViewsConfig defines the config options of a [Views] renderer.
+type ViewsConfig struct {
+	// FS is the file system to load the view templates from (required).
+	FS fs.FS
+
+	// Layout is an optional layout template file path (e.g. "layout.html").
+	//
+	// If set, the layout is executed instead of the view template and it
+	// is expected to reference the blocks defined in the view template
+	// (e.g. `{{block "content" .}}{{end}}`).
+	Layout string
+
+	// Partials is an optional list of glob patterns with shared templates
+	// that are loaded together with each view (e.g. "partials/*.html").
+	//
+	// Patterns without matching files are ignored.
+	Partials []string
+
+	// AutoReload disables the parsed templates caching and reloads
+	// the view files on every render (useful during development).
+	AutoReload bool
+}
+
+// Views is a file system based templates renderer with support
+// for shared layout and partials.
+//
+// Example:
+//
+//	views := template.NewViews(template.ViewsConfig{
+//		FS:         os.DirFS("./pb_views"),
+//		Layout:     "layout.html",
+//		Partials:   []string{"partials/*.html"},
+//		AutoReload: true,
+//	})
+//
+//	html, err := views.Render("status.html", map[string]any{"ok": true})
+type Views struct {
+	registry *Registry
+	cache    *store.Store[*Renderer]
+	config   ViewsConfig
+}
+
+// NewViews creates a new [Views] renderer from the provided config.
+func NewViews(config ViewsConfig) *Views {
+	if config.FS == nil {
+		panic("NewViews: the provided config.FS is nil")
+	}
+
+	return &Views{
+		registry: NewRegistry(),
+		cache:    store.New[*Renderer](nil),
+		config:   config,
+	}
+}
+
+// AddFuncs registers new global template functions
+// (see also [Registry.AddFuncs]).
+//
+// Note that the already parsed and cached views are reset.
+func (v *Views) AddFuncs(funcs map[string]any) *Views {
+	v.registry.AddFuncs(funcs)
+	v.cache.Reset(nil)
+
+	return v
+}
+
+// Render renders the view template with the specified name
+// (aka. its FS file path) and data as the dot object.
+func (v *Views) Render(name string, data any) (string, error) {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+
+	// the patterns are treated as globs so disallow any special characters
+	// to ensure that only a single view file is loaded
+	if name == "" || strings.ContainsAny(name, `*?[\`) {
+		return "", errors.New("invalid view name " + name)
+	}
+
+	if v.config.AutoReload {
+		return v.load(name).Render(data)
+	}
+
+	found := v.cache.Get(name)
+	if found == nil {
+		found = v.load(name)
+		v.cache.Set(name, found)
+	}
+
+	return found.Render(data)
+}
+
+// load parses the view template together with the configured layout and partials.
+func (v *Views) load(name string) *Renderer {
+	patterns := make([]string, 0, len(v.config.Partials)+2)
+
+	if v.config.Layout != "" {
+		patterns = append(patterns, v.config.Layout)
+	}
+
+	for _, p := range v.config.Partials {
+		if matches, _ := fs.Glob(v.config.FS, p); len(matches) > 0 {
+			patterns = append(patterns, p)
+		}
+	}
+
+	patterns = append(patterns, name)
+
+	renderer := v.registry.parseFS(v.config.FS, patterns...)
+
+	// execute the layout (if any) or the view template
+	// (the parsed templates are named after the file base name)
+	if renderer.parseError == nil {
+		main := name
+		if v.config.Layout != "" {
+			main = v.config.Layout
+		}
+		renderer.template = renderer.template.Lookup(path.Base(main))
+	}
+
+	return renderer
+}
@@ -0,0 +1,115 @@
+package template
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewViewsNilFS(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("Expected to panic")
+		}
+	}()
+
+	NewViews(ViewsConfig{})
+}
+
+func TestViewsRender(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layout.html":          {Data: []byte(`<main>{{block "content" .}}default{{end}}</main>{{template "footer"}}`)},
+		"partials/footer.html": {Data: []byte(`{{define "footer"}}<footer>{{upper "pb"}}</footer>{{end}}`)},
+		"status.html":          {Data: []byte(`{{define "content"}}status:{{.}}{{end}}`)},
+		"empty.html":           {Data: []byte(``)},
+		"plain.html":           {Data: []byte(`plain:{{.}}{{template "footer"}}`)},
+	}
+
+	funcs := map[string]any{"upper": strings.ToUpper}
+
+	scenarios := []struct {
+		name        string
+		config      ViewsConfig
+		view        string
+		expectError bool
+		expected    string
+	}{
+		{
+			"with layout and partials",
+			ViewsConfig{FS: fsys, Layout: "layout.html", Partials: []string{"partials/*.html", "missing/*.html"}},
+			"status.html",
+			false,
+			"<main>status:ok</main><footer>PB</footer>",
+		},
+		{
+			"with layout and view without blocks",
+			ViewsConfig{FS: fsys, Layout: "layout.html", Partials: []string{"partials/*.html"}},
+			"/empty.html",
+			false,
+			"<main>default</main><footer>PB</footer>",
+		},
+		{
+			"without layout",
+			ViewsConfig{FS: fsys, Partials: []string{"partials/*.html"}},
+			"plain.html",
+			false,
+			"plain:ok<footer>PB</footer>",
+		},
+		{
+			"missing view",
+			ViewsConfig{FS: fsys},
+			"missing.html",
+			true,
+			"",
+		},
+		{
+			"glob view name",
+			ViewsConfig{FS: fsys},
+			"*.html",
+			true,
+			"",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			views := NewViews(s.config).AddFuncs(funcs)
+
+			result, err := views.Render(s.view, "ok")
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if result != s.expected {
+				t.Fatalf("Expected\n%q\ngot\n%q", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestViewsAutoReload(t *testing.T) {
+	for _, autoReload := range []bool{false, true} {
+		fsys := fstest.MapFS{
+			"test.html": {Data: []byte(`v1`)},
+		}
+
+		views := NewViews(ViewsConfig{FS: fsys, AutoReload: autoReload})
+
+		if result, _ := views.Render("test.html", nil); result != "v1" {
+			t.Fatalf("[%v] Expected v1, got %q", autoReload, result)
+		}
+
+		fsys["test.html"] = &fstest.MapFile{Data: []byte(`v2`)}
+
+		expected := "v1"
+		if autoReload {
+			expected = "v2"
+		}
+
+		if result, _ := views.Render("test.html", nil); result != expected {
+			t.Fatalf("[%v] Expected %q, got %q", autoReload, expected, result)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// GeoPoint defines a single geographic coordinate (WGS84 latitude and longitude in degrees)
+// that is safe for json and db read/write.
+type GeoPoint struct {
+	Lat float64 `form:"lat" json:"lat"`
+	Lon float64 `form:"lon" json:"lon"`
+}
+
+// IsZero reports whether the current point is the zero "0,0" coordinate.
+func (p GeoPoint) IsZero() bool {
+	return p.Lat == 0 && p.Lon == 0
+}
+
+// String returns the string representation of the current point.
+func (p GeoPoint) String() string {
+	raw, _ := json.Marshal(p)
+	return string(raw)
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+//
+// For convenience the "lng" key is also accepted as alias of "lon".
+func (p *GeoPoint) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Lat *float64 `json:"lat"`
+		Lon *float64 `json:"lon"`
+		Lng *float64 `json:"lng"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*p = GeoPoint{}
+
+	if raw.Lat != nil {
+		p.Lat = *raw.Lat
+	}
+
+	if raw.Lon != nil {
+		p.Lon = *raw.Lon
+	} else if raw.Lng != nil {
+		p.Lon = *raw.Lng
+	}
+
+	return nil
+}
+
+// Value implements the [driver.Valuer] interface.
+func (p GeoPoint) Value() (driver.Value, error) {
+	data, err := json.Marshal(p)
+
+	return string(data), err
+}
+
+// Scan implements [sql.Scanner] interface to scan the provided value
+// into the current GeoPoint instance.
+func (p *GeoPoint) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		// no cast needed
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("failed to unmarshal GeoPoint value: %q", value)
+	}
+
+	if len(data) == 0 || string(data) == "null" {
+		*p = GeoPoint{}
+		return nil
+	}
+
+	return p.UnmarshalJSON(data)
+}
@@ -0,0 +1,112 @@
+package types_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestGeoPointIsZero(t *testing.T) {
+	scenarios := []struct {
+		point    types.GeoPoint
+		expected bool
+	}{
+		{types.GeoPoint{}, true},
+		{types.GeoPoint{Lat: 1}, false},
+		{types.GeoPoint{Lon: 1}, false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%v", i, s.point), func(t *testing.T) {
+			if v := s.point.IsZero(); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestGeoPointUnmarshalJSON(t *testing.T) {
+	scenarios := []struct {
+		json        string
+		expected    types.GeoPoint
+		expectError bool
+	}{
+		{`{}`, types.GeoPoint{}, false},
+		{`{"lat":1.5,"lon":-2}`, types.GeoPoint{Lat: 1.5, Lon: -2}, false},
+		{`{"lat":1.5,"lng":-2}`, types.GeoPoint{Lat: 1.5, Lon: -2}, false},
+		{`{"lat":1.5,"lon":3,"lng":-2}`, types.GeoPoint{Lat: 1.5, Lon: 3}, false},
+		{`{"lat":"a"}`, types.GeoPoint{}, true},
+		{`[1,2]`, types.GeoPoint{}, true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.json), func(t *testing.T) {
+			point := types.GeoPoint{Lat: 10, Lon: 10}
+
+			err := json.Unmarshal([]byte(s.json), &point)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && point != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, point)
+			}
+		})
+	}
+}
+
+func TestGeoPointValue(t *testing.T) {
+	point := types.GeoPoint{Lat: 3.12, Lon: 101.6}
+
+	v, err := point.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"lat":3.12,"lon":101.6}`
+
+	if v != expected {
+		t.Fatalf("Expected %q, got %q", expected, v)
+	}
+
+	if str := point.String(); str != expected {
+		t.Fatalf("Expected String() %q, got %q", expected, str)
+	}
+}
+
+func TestGeoPointScan(t *testing.T) {
+	scenarios := []struct {
+		value       any
+		expected    types.GeoPoint
+		expectError bool
+	}{
+		{nil, types.GeoPoint{}, false},
+		{"", types.GeoPoint{}, false},
+		{"null", types.GeoPoint{}, false},
+		{`{"lat":1,"lon":2}`, types.GeoPoint{Lat: 1, Lon: 2}, false},
+		{[]byte(`{"lat":1,"lon":2}`), types.GeoPoint{Lat: 1, Lon: 2}, false},
+		{"invalid", types.GeoPoint{}, true},
+		{123, types.GeoPoint{}, true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.value), func(t *testing.T) {
+			point := types.GeoPoint{}
+
+			err := point.Scan(s.value)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && point != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, point)
+			}
+		})
+	}
+}
@@ -87,6 +87,12 @@ const routes = {
         userData: { showAppSidebar: true },
     }),
 
+    "/ext/:id": wrap({
+        asyncComponent: () => import("@/components/extensions/PageExtension.svelte"),
+        conditions: [(_) => ApiClient.authStore.isValid],
+        userData: { showAppSidebar: true },
+    }),
+
     // ---------------------------------------------------------------
     // Records email confirmation actions
     // ---------------------------------------------------------------
@@ -0,0 +1,18 @@
+import { writable } from "svelte/store";
+import ApiClient from "@/utils/ApiClient";
+
+// list with the registered admin UI extensions (aka. custom pages)
+export const extensions = writable([]);
+
+export async function loadExtensions() {
+    try {
+        const result = await ApiClient.send("/api/adminui/extensions", {
+            $cancelKey: "adminUIExtensions",
+        });
+        extensions.set(Array.isArray(result) ? result : []);
+    } catch (err) {
+        if (!err?.isAbort) {
+            console.warn("Failed to load the admin UI extensions.", err);
+        }
+    }
+}
@@ -1077,7 +1077,7 @@ export default class CommonHelper {
         for (const field of fields) {
             if (
                 field.hidden ||
-                (forSubmit && field.primaryKey && field.autogeneratePattern) ||
+                (forSubmit && field.primaryKey && (field.autogeneratePattern || field.autogenerateStrategy)) ||
                 (forSubmit && field.type === "autodate")
             ) {
                 continue
@@ -1178,6 +1178,8 @@ export default class CommonHelper {
                 return "ri-mind-map";
             case "password":
                 return "ri-lock-password-line";
+            case "secret":
+                return "ri-key-2-line";
             case "autodate":
                 return "ri-calendar-check-line";
             default: